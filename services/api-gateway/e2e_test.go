@@ -0,0 +1,124 @@
+//go:build integration
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go_scraping_project/services/api-gateway/models"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/testenv"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// startGateway wires the API Gateway with setup over env and starts it
+func startGateway(t *testing.T, env *testenv.Env) *httptest.Server {
+	t.Helper()
+	c := env.Container(t, "api-gateway")
+	handler, err := setup(c)
+	if err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// call sends a JSON request and decodes the response into out, if set,
+// returning the status code
+func call(t *testing.T, server *httptest.Server, method, path string, body, out interface{}) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// waitForEvent waits for a URL event of the given type on the URL events topic
+func waitForEvent(t *testing.T, env *testenv.Env, urlID, eventType string) {
+	t.Helper()
+	env.WaitForMessage(t, env.Config.Kafka.Topics.URLEvents, func(msg kafka.Message) bool {
+		var event sharedmodels.URLEvent
+		return string(msg.Key) == urlID && json.Unmarshal(msg.Value, &event) == nil && event.Type == eventType
+	})
+}
+
+func TestE2EURLLifecycle(t *testing.T) {
+	env := testenv.New(t)
+	server := startGateway(t, env)
+	address := "https://example.com/e2e/" + uuid.NewString()
+	search := "/urls?q=" + url.QueryEscape(address)
+
+	var created models.CreateURLResponse
+	req := models.CreateURLRequest{URL: address, Frequency: "1h", Tags: []string{"e2e"}, Project: "e2e"}
+	if code := call(t, server, http.MethodPost, "/api/v1/urls", req, &created); code != http.StatusCreated {
+		t.Fatalf("POST /urls = %d, want 201", code)
+	}
+	if created.URL != address || created.Status != sharedmodels.URLStatusPending {
+		t.Errorf("created = %+v, want pending %s", created, address)
+	}
+	waitForEvent(t, env, created.ID, sharedmodels.URLEventCreated)
+	if code := call(t, server, http.MethodPost, "/api/v1/urls", req, nil); code != http.StatusConflict {
+		t.Errorf("POST /urls of an existing URL = %d, want 409", code)
+	}
+
+	var got map[string]interface{}
+	if code := call(t, server, http.MethodPut, "/api/v1/urls/"+created.ID, models.UpdateURLRequest{Frequency: "30m"}, nil); code != http.StatusOK {
+		t.Fatalf("PUT /urls/{id} = %d, want 200", code)
+	}
+	if code := call(t, server, http.MethodGet, "/api/v1/urls/"+created.ID, nil, &got); code != http.StatusOK {
+		t.Fatalf("GET /urls/{id} = %d, want 200", code)
+	}
+	if got["frequency"] != "30m" || got["project"] != "e2e" {
+		t.Errorf("URL = %v, want frequency 30m in project e2e", got)
+	}
+
+	var listed models.ListURLsResponse
+	if call(t, server, http.MethodGet, "/api/v1"+search, nil, &listed); listed.Total != 1 || len(listed.URLs) != 1 {
+		t.Errorf("search found %d URLs, want 1", listed.Total)
+	}
+
+	if code := call(t, server, http.MethodDelete, "/api/v1/urls/"+created.ID, nil, nil); code != http.StatusOK {
+		t.Fatalf("DELETE /urls/{id} = %d, want 200", code)
+	}
+	waitForEvent(t, env, created.ID, sharedmodels.URLEventDeleted)
+	if code := call(t, server, http.MethodDelete, "/api/v1/urls/"+created.ID, nil, nil); code != http.StatusNotFound {
+		t.Errorf("second DELETE /urls/{id} = %d, want 404", code)
+	}
+	listed = models.ListURLsResponse{}
+	if call(t, server, http.MethodGet, "/api/v1"+search, nil, &listed); listed.Total != 0 {
+		t.Errorf("search found %d URLs after the delete, want 0", listed.Total)
+	}
+
+	stored, err := env.Queries().GetURLByID(context.Background(), uuid.MustParse(created.ID))
+	if err != nil || !stored.DeletedAt.Valid {
+		t.Errorf("stored URL = %+v, %v; want soft-deleted", stored, err)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go_scraping_project/shared/config"
+)
+
+// corsPolicy decides which origins may make cross-origin requests, with
+// settings that can be changed at runtime, see config.CORSConfig
+type corsPolicy struct {
+	mu          sync.RWMutex
+	anyOrigin   bool
+	origins     map[string]bool
+	suffixes    []corsWildcard // Wildcard subdomain origins
+	methods     string
+	headers     string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+// corsWildcard is an origin allowing every subdomain of a host, such as
+// https://*.example.com
+type corsWildcard struct {
+	scheme string // With "://"
+	suffix string // The host with a leading dot
+}
+
+// newCORSPolicy creates a policy from the CORS configuration of an environment
+func newCORSPolicy(cfg config.CORSConfig, environment string) *corsPolicy {
+	p := &corsPolicy{}
+	p.Update(cfg, environment)
+	return p
+}
+
+// Update applies new CORS settings
+func (p *corsPolicy) Update(cfg config.CORSConfig, environment string) {
+	cfg = cfg.ForEnvironment(environment)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.anyOrigin = false
+	p.origins = make(map[string]bool)
+	p.suffixes = nil
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*")
+			p.suffixes = append(p.suffixes, corsWildcard{scheme: scheme + "://", suffix: host})
+		default:
+			p.origins[origin] = true
+		}
+	}
+	p.methods = strings.Join(cfg.AllowedMethods, ", ")
+	p.headers = strings.Join(cfg.AllowedHeaders, ", ")
+	p.exposed = strings.Join(cfg.ExposedHeaders, ", ")
+	p.credentials = cfg.AllowCredentials
+	p.maxAge = ""
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+}
+
+// allowed reports whether an origin may make cross-origin requests
+func (p *corsPolicy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.suffixes {
+		if strings.HasPrefix(origin, w.scheme) && strings.HasSuffix(origin, w.suffix) && len(origin) > len(w.scheme)+len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsMiddleware handles Cross-Origin Resource Sharing
+//
+// Purpose: Enables cross-origin requests from the origins allowed by the
+// CORS configuration. It wraps the whole router so that preflight OPTIONS
+// requests are answered before routing, whatever methods a route accepts.
+//
+// Features:
+//   - Allowed origins, methods, headers and preflight max age from configuration
+//   - Every origin allowed in development when none are configured
+//   - Credentialed requests, answered with the request's origin instead of *
+//   - Preflight requests answered with 204, or 403 for other origins
+//
+// Example Usage:
+//
+//	handler := corsMiddleware(newCORSPolicy(cfg.Server.CORS, cfg.Environment))(router)
+//
+// Headers Set:
+//
+//	Access-Control-Allow-Origin: https://app.example.com
+//	Access-Control-Allow-Credentials: true
+//	Access-Control-Allow-Methods: GET, POST, PUT, PATCH, DELETE, OPTIONS
+//	Access-Control-Allow-Headers: Content-Type, Authorization
+//	Access-Control-Max-Age: 600
+//	Vary: Origin
+func corsMiddleware(policy *corsPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy.mu.RLock()
+			allowed := policy.allowed(origin)
+			header := w.Header()
+			if allowed {
+				if policy.anyOrigin && !policy.credentials {
+					header.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					header.Set("Access-Control-Allow-Origin", origin)
+					header.Add("Vary", "Origin")
+				}
+				if policy.credentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if preflight {
+					header.Set("Access-Control-Allow-Methods", policy.methods)
+					header.Set("Access-Control-Allow-Headers", policy.headers)
+					if policy.maxAge != "" {
+						header.Set("Access-Control-Max-Age", policy.maxAge)
+					}
+				} else if policy.exposed != "" {
+					header.Set("Access-Control-Expose-Headers", policy.exposed)
+				}
+			} else {
+				header.Add("Vary", "Origin")
+			}
+			policy.mu.RUnlock()
+
+			if preflight {
+				if !allowed {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server.CORS
+	cfg.AllowedOrigins = []string{"https://app.example.com", "https://*.preview.example.com"}
+	cfg.AllowCredentials = true
+	cfg.ExposedHeaders = []string{"X-Maintenance-Mode"}
+	policy := newCORSPolicy(cfg, "production")
+	handler := corsMiddleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/urls", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantStatus int
+		wantOrigin string
+	}{
+		{"same origin", http.MethodGet, "", false, http.StatusOK, ""},
+		{"allowed origin", http.MethodGet, "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+		{"allowed subdomain", http.MethodGet, "https://pr-12.preview.example.com", false, http.StatusOK, "https://pr-12.preview.example.com"},
+		{"wildcard host itself", http.MethodGet, "https://preview.example.com", false, http.StatusOK, ""},
+		{"other scheme", http.MethodGet, "http://app.example.com", false, http.StatusOK, ""},
+		{"preflight", http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com"},
+		{"preflight of other origin", http.MethodOptions, "https://evil.example", true, http.StatusForbidden, ""},
+		{"plain OPTIONS", http.MethodOptions, "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.method, tt.origin, tt.preflight)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.wantStatus)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", tt.name, got, tt.wantOrigin)
+		}
+	}
+
+	rec := serve(http.MethodOptions, "https://app.example.com", true)
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("preflight Access-Control-Max-Age = %q, want 600", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("preflight Access-Control-Allow-Credentials = %q, want true", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+		t.Errorf("preflight Access-Control-Allow-Methods = %q", got)
+	}
+	rec = serve(http.MethodGet, "https://app.example.com", false)
+	if rec.Header().Get("Access-Control-Expose-Headers") != "X-Maintenance-Mode" || rec.Header().Get("Vary") != "Origin" {
+		t.Errorf("response headers = %v, want exposed headers and Vary: Origin", rec.Header())
+	}
+
+	policy.Update(config.CORSConfig{MaxAge: time.Minute}, "development")
+	rec = serve(http.MethodOptions, "https://evil.example", true)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("development preflight = %d with origin %q, want 204 with *", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
@@ -1,9 +1,21 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/maintenance"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,9 +33,46 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// loggingMiddleware logs all HTTP requests with structured logging
+// requestLogSampler decides which requests are logged, with settings that
+// can be changed at runtime, see config.RequestLogConfig
+type requestLogSampler struct {
+	mu     sync.RWMutex
+	cfg    config.RequestLogConfig
+	random func() float64
+}
+
+// newRequestLogSampler creates a sampler from the request log configuration
+func newRequestLogSampler(cfg config.RequestLogConfig) *requestLogSampler {
+	return &requestLogSampler{cfg: cfg, random: rand.Float64}
+}
+
+// Update applies new sampling settings
+func (s *requestLogSampler) Update(cfg config.RequestLogConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+// Sample reports whether a finished request is logged and the sample rate it
+// was logged at, 1 for requests that are always logged
+func (s *requestLogSampler) Sample(path string, status int, duration time.Duration) (bool, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if status >= http.StatusBadRequest || s.cfg.SlowThreshold > 0 && duration >= s.cfg.SlowThreshold {
+		return true, 1
+	}
+	rate := s.cfg.SampleRateOf(path)
+	if rate >= 1 {
+		return true, 1
+	}
+	return rate > 0 && s.random() < rate, rate
+}
+
+// loggingMiddleware logs sampled HTTP requests with structured logging
 //
-// Purpose: Provides comprehensive request logging for all HTTP requests.
+// Purpose: Provides request logging for HTTP requests without letting
+// polled endpoints such as /health and /metrics dominate the log volume.
 // This middleware captures request details including method, path, status code,
 // response time, user agent, and remote IP address for monitoring and debugging.
 //
@@ -32,10 +81,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 //   - Request duration measurement
 //   - Status code capture
 //   - User agent and IP address logging
+//   - Sampling of successful requests; errors and slow requests are always
+//     logged, and sampled entries carry their sample_rate
 //
 // Example Usage:
 //
-//	router.Use(loggingMiddleware(logger))
+//	router.Use(loggingMiddleware(logger, newRequestLogSampler(cfg.Logging.Requests)))
 //
 // Log Output Example:
 //
@@ -47,9 +98,10 @@ func (rw *responseWriter) WriteHeader(code int) {
 //	  "status": 200,
 //	  "duration": "15.2ms",
 //	  "user_agent": "Mozilla/5.0...",
-//	  "remote_ip": "192.168.1.100"
+//	  "remote_ip": "192.168.1.100",
+//	  "sample_rate": 0.01
 //	}
-func loggingMiddleware(log *logrus.Logger) func(http.Handler) http.Handler {
+func loggingMiddleware(log *logrus.Logger, sampler *requestLogSampler) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now().UTC()
@@ -60,52 +112,60 @@ func loggingMiddleware(log *logrus.Logger) func(http.Handler) http.Handler {
 			next.ServeHTTP(wrapped, r)
 
 			duration := time.Since(start)
+			logged, rate := sampler.Sample(r.URL.Path, wrapped.statusCode, duration)
+			if !logged {
+				return
+			}
 
-			log.WithFields(logrus.Fields{
+			fields := logrus.Fields{
 				"method":     r.Method,
 				"path":       r.URL.Path,
 				"status":     wrapped.statusCode,
 				"duration":   duration,
 				"user_agent": r.UserAgent(),
 				"remote_ip":  r.RemoteAddr,
-			}).Info("HTTP Request")
+			}
+			if rate < 1 {
+				fields["sample_rate"] = rate
+			}
+			log.WithFields(fields).Info("HTTP Request")
 		})
 	}
 }
 
-// corsMiddleware handles Cross-Origin Resource Sharing
-//
-// Purpose: Enables cross-origin requests for web applications.
-// This middleware sets appropriate CORS headers to allow browsers
-// to make requests from different origins to the API Gateway.
+// securityHeadersMiddleware sets security headers on every response
 //
-// Features:
-//   - Allows all origins (*)
-//   - Supports common HTTP methods (GET, POST, PUT, DELETE, OPTIONS)
-//   - Handles preflight OPTIONS requests
-//   - Sets appropriate CORS headers
+// Purpose: Hardens responses for browsers when the gateway is exposed
+// without a proxy. Responses over TLS also carry Strict-Transport-Security,
+// so browsers keep using HTTPS for hsts_max_age.
 //
 // Example Usage:
 //
-//	router.Use(corsMiddleware())
+//	router.Use(securityHeadersMiddleware(cfg.Server.TLS))
 //
 // Headers Set:
 //
-//	Access-Control-Allow-Origin: *
-//	Access-Control-Allow-Methods: GET, POST, PUT, DELETE, OPTIONS
-//	Access-Control-Allow-Headers: Content-Type, Authorization
-func corsMiddleware() func(http.Handler) http.Handler {
+//	X-Content-Type-Options: nosniff
+//	X-Frame-Options: DENY
+//	Referrer-Policy: no-referrer
+//	Strict-Transport-Security: max-age=31536000; includeSubDomains
+func securityHeadersMiddleware(cfg config.ServerTLSConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
+			header := w.Header()
+			header.Set("X-Content-Type-Options", "nosniff")
+			header.Set("X-Frame-Options", "DENY")
+			header.Set("Referrer-Policy", "no-referrer")
+			if r.TLS != nil && hsts != "" {
+				header.Set("Strict-Transport-Security", hsts)
 			}
-
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -181,34 +241,144 @@ func authMiddleware(log *logrus.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-// rateLimitMiddleware handles rate limiting (placeholder for future implementation)
+// rateLimitMiddleware handles rate limiting
 //
 // Purpose: Prevents API abuse by limiting the number of requests per client.
-// This middleware will implement rate limiting based on IP address, user ID,
-// or other identifiers to protect the API from excessive usage.
+// Each client IP address gets a token bucket sized by rate_limit.burst_size
+// and refilled at rate_limit.requests_per_minute. Limits are read from the
+// limiter on every request, so configuration reloads take effect immediately.
 //
-// Current Status: Placeholder implementation that allows all requests
-// Future Implementation: Token bucket algorithm, Redis-based rate limiting
+// Features:
+//   - Per-client token bucket rate limiting
+//   - 429 Too Many Requests with a Retry-After header when exhausted
+//   - Health endpoints are never limited
 //
 // Example Usage:
 //
-//	router.Use(rateLimitMiddleware(logger))
+//	router.Use(rateLimitMiddleware(newRateLimiter(cfg.RateLimit)))
+func rateLimitMiddleware(limiter *rateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := limiter.Allow(clientIP(r))
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// triggerLimitMiddleware limits manual scrape triggers per client
 //
-// Future Features:
-//   - Token bucket rate limiting
-//   - Redis-based distributed rate limiting
-//   - Per-endpoint rate limits
-//   - Rate limit headers in responses
-func rateLimitMiddleware(log *logrus.Logger) func(http.Handler) http.Handler {
+// Purpose: Keeps scripted clients from flooding the scraping task topic
+// through POST /api/v1/urls/{id}/scrape, since each accepted trigger
+// publishes a task. Each client IP address gets a token bucket sized by
+// rate_limit.trigger_burst_size and refilled at rate_limit.triggers_per_minute,
+// on top of the general rate limit. Limits are read from the limiter on every
+// request, so configuration reloads take effect immediately.
+//
+// Example Usage:
+//
+//	urlRoutes.Handle("/{id}/scrape", triggerLimitMiddleware(limiter)(handler))
+//
+// Response Example (429 Too Many Requests, Retry-After: 6):
+//
+//	{
+//	  "error": "Trigger rate limit exceeded",
+//	  "message": "At most 10 scrapes per minute may be triggered per client",
+//	  "retry_after": 6
+//	}
+func triggerLimitMiddleware(limiter *rateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// TODO: Implement rate limiting
-			// For now, just pass through
+			allowed, retryAfter := limiter.Allow(clientIP(r))
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(models.TriggerRejectedResponse{
+					Error:      "Trigger rate limit exceeded",
+					Message:    fmt.Sprintf("At most %d scrapes per minute may be triggered per client", limiter.PerMinute()),
+					RetryAfter: seconds,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maintenancePath is the endpoint that switches maintenance mode, which stays
+// available during maintenance so it can be switched off
+const maintenancePath = "/api/v1/admin/maintenance"
+
+// maintenanceMiddleware rejects changes during maintenance mode
+//
+// Purpose: Keeps the database and Kafka untouched by API clients while
+// maintenance mode is on. Reads keep working and carry an X-Maintenance-Mode
+// header so clients can show a banner; changes are answered with 503.
+//
+// Features:
+//   - 503 Service Unavailable with the maintenance message for POST, PUT, PATCH and DELETE
+//   - X-Maintenance-Mode: enabled header on every API response
+//   - The maintenance endpoint itself is never blocked
+//
+// Example Usage:
+//
+//	router.Use(maintenanceMiddleware(mode))
+//
+// Response Example:
+//
+//	{
+//	  "error": "Service is in maintenance mode",
+//	  "message": "Database migration, back at 14:00 UTC"
+//	}
+func maintenanceMiddleware(mode *maintenance.Mode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := mode.Current()
+			if !state.Enabled || !strings.HasPrefix(r.URL.Path, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-Maintenance-Mode", "enabled")
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+				if r.URL.Path != maintenancePath {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					json.NewEncoder(w).Encode(map[string]string{
+						"error":   "Service is in maintenance mode",
+						"message": state.Message,
+					})
+					return
+				}
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// clientIP returns the request's remote IP address without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // requestIDMiddleware adds a unique request ID to each request
 //
 // Purpose: Provides request tracing and correlation across distributed systems.
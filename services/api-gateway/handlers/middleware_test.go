@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/maintenance"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	mode := maintenance.New(nil, logger)
+	handler := maintenanceMiddleware(mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/api/v1/urls"); rec.Code != http.StatusOK {
+		t.Errorf("POST outside maintenance status = %d, want 200", rec.Code)
+	}
+
+	mode.Set(maintenance.State{Enabled: true, Message: "Kafka upgrade"})
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/urls", http.StatusOK},
+		{http.MethodPost, "/api/v1/urls", http.StatusServiceUnavailable},
+		{http.MethodPut, "/api/v1/urls/123", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/views/prices", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/admin/maintenance", http.StatusOK},
+		{http.MethodGet, "/health", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.method, tt.path)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+	if rec := serve(http.MethodGet, "/api/v1/urls"); rec.Header().Get("X-Maintenance-Mode") != "enabled" {
+		t.Error("read during maintenance has no X-Maintenance-Mode header")
+	}
+}
+
+func TestTriggerLimitMiddleware(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(triggerLimits(config.RateLimitConfig{TriggersPerMinute: 6, TriggerBurstSize: 2}))
+	limiter.now = func() time.Time { return now }
+	handler := triggerLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	trigger := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/urls/123/scrape", nil)
+		r.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := trigger("10.0.0.1:5000"); rec.Code != http.StatusAccepted {
+			t.Fatalf("trigger %d within burst status = %d, want 202", i+1, rec.Code)
+		}
+	}
+	rec := trigger("10.0.0.1:5001")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "10" {
+		t.Fatalf("trigger over the limit status = %d, Retry-After %q; want 429 after 10s", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var rejected models.TriggerRejectedResponse
+	if err := json.NewDecoder(rec.Body).Decode(&rejected); err != nil {
+		t.Fatal(err)
+	}
+	if rejected.RetryAfter != 10 || rejected.Message != "At most 6 scrapes per minute may be triggered per client" {
+		t.Errorf("response = %+v", rejected)
+	}
+	if rec := trigger("10.0.0.2:5000"); rec.Code != http.StatusAccepted {
+		t.Errorf("other client status = %d, want 202", rec.Code)
+	}
+
+	limiter.Update(triggerLimits(config.RateLimitConfig{}))
+	if rec := trigger("10.0.0.1:5000"); rec.Code != http.StatusAccepted {
+		t.Errorf("trigger without a limit status = %d, want 202", rec.Code)
+	}
+}
+
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	sampler := newRequestLogSampler(config.RequestLogConfig{
+		SampleRate:    0.5,
+		SlowThreshold: time.Second,
+		Paths:         []config.PathSampleRate{{Prefix: "/health", SampleRate: 0}},
+	})
+	random := 0.7
+	sampler.random = func() float64 { return random }
+	handler := loggingMiddleware(logger, sampler)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+
+	logged := func(path string) bool {
+		out.Reset()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		return out.Len() > 0
+	}
+
+	if logged("/health") || logged("/api/v1/urls") {
+		t.Error("successful requests outside the sample were logged")
+	}
+	if !logged("/health?fail=1") {
+		t.Error("failed health check was not logged")
+	}
+	random = 0.2
+	if !logged("/api/v1/urls") || !strings.Contains(out.String(), "sample_rate=0.5") {
+		t.Errorf("sampled request log = %q, want an entry with its sample rate", out.String())
+	}
+	if logged("/health") {
+		t.Error("health check with sample rate 0 was logged")
+	}
+	if ok, rate := sampler.Sample("/health", http.StatusOK, 2*time.Second); !ok || rate != 1 {
+		t.Errorf("Sample() of a slow request = %v, %v; want logged at 1", ok, rate)
+	}
+
+	sampler.Update(config.RequestLogConfig{SampleRate: 1})
+	if !logged("/health") || strings.Contains(out.String(), "sample_rate") {
+		t.Errorf("request log without sampling = %q, want an entry without a sample rate", out.String())
+	}
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig().Server.TLS
+	cfg.HSTSIncludeSubdomains = true
+	handler := securityHeadersMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://gateway/api/v1/urls", nil))
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" || rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("headers = %v, want nosniff and DENY", rec.Header())
+	}
+	if hsts := rec.Header().Get("Strict-Transport-Security"); hsts != "" {
+		t.Errorf("plain HTTP Strict-Transport-Security = %q, want none", hsts)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://gateway/api/v1/urls", nil))
+	if hsts := rec.Header().Get("Strict-Transport-Security"); hsts != "max-age=31536000; includeSubDomains" {
+		t.Errorf("HTTPS Strict-Transport-Security = %q", hsts)
+	}
+}
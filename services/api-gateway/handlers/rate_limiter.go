@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// clientIdleTTL is how long an idle client's bucket is kept before it is pruned
+const clientIdleTTL = 10 * time.Minute
+
+// rateLimiter is a per-client token bucket limiter whose limits can be
+// changed at runtime. Each client may burst up to burstSize requests and
+// is then refilled at requestsPerMinute.
+type rateLimiter struct {
+	mu        sync.Mutex
+	enabled   bool
+	perMinute int
+	perSecond float64
+	burst     float64
+	clients   map[string]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// tokenBucket tracks the remaining tokens for a single client
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// newRateLimiter creates a limiter from the rate limit configuration
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{
+		clients: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+	l.Update(cfg)
+	return l
+}
+
+// Update applies new limits. Existing clients keep their remaining tokens,
+// capped at the new burst size.
+func (l *rateLimiter) Update(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.enabled = cfg.Enabled && cfg.RequestsPerMinute > 0
+	l.perMinute = cfg.RequestsPerMinute
+	l.perSecond = float64(cfg.RequestsPerMinute) / 60
+	l.burst = float64(cfg.BurstSize)
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	for _, bucket := range l.clients {
+		bucket.tokens = math.Min(bucket.tokens, l.burst)
+	}
+}
+
+// PerMinute returns the configured requests per minute of each client
+func (l *rateLimiter) PerMinute() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perMinute
+}
+
+// Allow consumes a token for the client. When the client is out of tokens
+// it returns false and how long until the next token is available.
+func (l *rateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled {
+		return true, 0
+	}
+
+	now := l.now()
+	l.prune(now)
+
+	bucket, ok := l.clients[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.clients[client] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.perSecond)
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// triggerLimits returns the limits of manual scrape triggers, which apply
+// whenever TriggersPerMinute is set, see config.RateLimitConfig
+func triggerLimits(cfg config.RateLimitConfig) config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Enabled:           cfg.TriggersPerMinute > 0,
+		RequestsPerMinute: cfg.TriggersPerMinute,
+		BurstSize:         cfg.TriggerBurstSize,
+	}
+}
+
+// prune drops buckets for clients that have been idle for clientIdleTTL
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < clientIdleTTL {
+		return
+	}
+	l.lastPrune = now
+	for client, bucket := range l.clients {
+		if now.Sub(bucket.lastSeen) > clientIdleTTL {
+			delete(l.clients, client)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(config.RateLimitConfig{Enabled: true, RequestsPerMinute: 60, BurstSize: 2})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within burst was rejected", i+1)
+		}
+	}
+	ok, retryAfter := limiter.Allow("10.0.0.1")
+	if ok || retryAfter != time.Second {
+		t.Errorf("Allow() = %v, %v; want rejection with 1s retry", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("10.0.0.2"); !ok {
+		t.Error("other clients should have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.Allow("10.0.0.1"); !ok {
+		t.Error("token was not refilled after one second")
+	}
+
+	limiter.Update(config.RateLimitConfig{Enabled: false})
+	if ok, _ := limiter.Allow("10.0.0.1"); !ok {
+		t.Error("disabled limiter rejected a request")
+	}
+}
@@ -3,8 +3,19 @@ package handlers
 import (
 	"net/http"
 
+	"go_scraping_project/services/api-gateway/repositories"
+	"go_scraping_project/services/api-gateway/services"
 	"go_scraping_project/services/api-gateway/types"
+	"go_scraping_project/shared/cache"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/control"
 	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	"go_scraping_project/shared/events"
+	"go_scraping_project/shared/features"
+	"go_scraping_project/shared/health"
+	"go_scraping_project/shared/maintenance"
+	"go_scraping_project/shared/tracing"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -18,26 +29,80 @@ import (
 // Parameters:
 //   - logger: Structured logger for request logging and error handling
 //   - db: sqlc-generated database queries for data persistence
+//   - cfg: Configuration watcher providing the effective, hot-reloadable configuration
+//   - flags: Feature flags merged from configuration and the database
+//   - urlEvents: Publisher for the URL events topic, notified of URL changes made through the API
+//   - mode: Maintenance mode switch, stored in the database
+//   - checker: Health checks of the components behind GET /api/v1/admin/health
+//   - producer: Kafka producer replaying imported dead letter messages
+//   - responseCache: Redis cache of hot read endpoints, nil when caching is disabled
+//   - pool: Database connection pool, for its statistics and runtime tuning
+//   - keyring: Keys encrypting sensitive fields at rest, nil when none are configured
 //
 // Returns:
 //   - *types.Router: Configured router instance ready for route setup
-func NewRouter(logger *logrus.Logger, db *database.Queries) *types.Router {
+func NewRouter(logger *logrus.Logger, db *database.Queries, cfg *config.Watcher, flags *features.Flags, urlEvents *events.URLEventPublisher, mode *maintenance.Mode, checker *health.Checker, producer events.Sender, responseCache *cache.Cache, pool *database.Pool, keyring *encryption.Keyring) *types.Router {
 	router := mux.NewRouter()
 
+	// URL endpoints go through the URL service and repository
+	dbConfig := cfg.Current().Database
+	queryTimeouts := database.NewQueryTimeouts(dbConfig.QueryTimeout, dbConfig.QueryTimeouts)
+	urlService := services.NewURLService(repositories.NewURLRepository(db, logger, queryTimeouts), urlEvents, logger)
+	urlService.SetCache(responseCache, cfg.Current().Cache)
+
 	// Initialize handlers with database queries
-	urlHandler := types.NewURLHandler(logger, db)
-	dataHandler := types.NewDataHandler(logger)
-	metricsHandler := types.NewMetricsHandler(logger)
-	adminHandler := types.NewAdminHandler(logger)
+	urlHandler := types.NewURLHandler(logger, urlService, cfg, control.NewClient(cfg.Current().Control, nil), keyring)
+	dataHandler := types.NewDataHandler(logger, db, keyring)
+	metricsHandler := types.NewMetricsHandler(logger, db)
+	metricsHandler.Cache = responseCache
+	metricsHandler.CacheTTL = cfg.Current().Cache.MetricsTTL
+	metricsHandler.Config = cfg
+	adminHandler := types.NewAdminHandler(logger, cfg, checker, producer, repositories.NewDeadLetterRepository(db, logger, queryTimeouts))
+	parserHandler := types.NewParserHandler(logger, db)
+	featureHandler := types.NewFeatureHandler(logger, db, flags)
+	domainHandler := types.NewDomainHandler(logger, db, cfg)
+	scheduleHandler := types.NewScheduleHandler(logger, db)
+	costHandler := types.NewCostHandler(logger, db)
+	workerHandler := types.NewWorkerHandler(logger, db, cfg)
+	viewHandler := types.NewViewHandler(logger, db, keyring)
+	notificationHandler := types.NewNotificationHandler(logger, db, keyring)
+	alertHandler := types.NewAlertHandler(logger, db)
+	maintenanceHandler := types.NewMaintenanceHandler(logger, db, mode)
+	databaseHandler := types.NewDatabaseHandler(logger, pool)
+	encryptionHandler := types.NewEncryptionHandler(logger, db, keyring)
+	exportHandler := types.NewExportHandler(logger, db, cfg)
+
+	// Validation, probes and selector suggestions request sites as scrapers do
+	if fetcher, err := types.NewFetcher(cfg.Current().Scraping); err != nil {
+		logger.WithError(err).Warn("Invalid scraping settings, requesting sites with the default client")
+	} else {
+		urlHandler.Fetch = fetcher
+		parserHandler.Fetch = fetcher
+	}
 
 	return &types.Router{
-		Router:         router,
-		Logger:         logger,
-		DB:             db,
-		URLHandler:     urlHandler,
-		DataHandler:    dataHandler,
-		MetricsHandler: metricsHandler,
-		AdminHandler:   adminHandler,
+		Router:              router,
+		Logger:              logger,
+		DB:                  db,
+		Config:              cfg,
+		Flags:               flags,
+		URLHandler:          urlHandler,
+		DataHandler:         dataHandler,
+		MetricsHandler:      metricsHandler,
+		AdminHandler:        adminHandler,
+		ParserHandler:       parserHandler,
+		FeatureHandler:      featureHandler,
+		DomainHandler:       domainHandler,
+		ScheduleHandler:     scheduleHandler,
+		CostHandler:         costHandler,
+		WorkerHandler:       workerHandler,
+		ViewHandler:         viewHandler,
+		NotificationHandler: notificationHandler,
+		AlertHandler:        alertHandler,
+		MaintenanceHandler:  maintenanceHandler,
+		DatabaseHandler:     databaseHandler,
+		EncryptionHandler:   encryptionHandler,
+		ExportHandler:       exportHandler,
 	}
 }
 
@@ -53,37 +118,84 @@ func NewRouter(logger *logrus.Logger, db *database.Queries) *types.Router {
 //
 // Route Structure:
 //   - Health endpoints: /health, /ready, /live
+//   - Prometheus metrics: /metrics
 //   - API v1 endpoints: /api/v1/*
 //   - URL management: /api/v1/urls/*
+//   - Domains: /api/v1/domains
+//   - Schedule: /api/v1/schedule/*
+//   - Costs: /api/v1/costs
 //   - Data retrieval: /api/v1/data/*
+//   - Data views: /api/v1/views/*
 //   - Metrics: /api/v1/metrics/*
 //   - Admin: /api/v1/admin/*
+//   - Workers: /api/v1/admin/workers
+//   - Alert history: /api/v1/admin/alerts
+//   - Maintenance mode: /api/v1/admin/maintenance
+//   - Database pool: /api/v1/admin/database/pool
+//   - Feature flags: /api/v1/admin/features/*
+//   - Notification channels: /api/v1/notification-channels/*
+//   - Parser templates: /api/v1/parser/*
 //
 // Middleware Applied:
-//   - Logging middleware for request tracking
-//   - CORS middleware for cross-origin support
+//   - Logging middleware for request tracking, sampled as configured
+//   - CORS middleware for the configured origins, reconfigured live on config reload
+//   - Security headers middleware, with HSTS for requests over TLS
 //   - Recovery middleware for panic handling
+//   - Rate limiting middleware, reconfigured live on config reload
+//   - Maintenance middleware, rejecting changes during maintenance mode
 func SetupRoutes(router *types.Router) http.Handler {
+	// Rate limits and request log sampling follow the effective configuration
+	limiter := newRateLimiter(router.Config.Current().RateLimit)
+	triggerLimiter := newRateLimiter(triggerLimits(router.Config.Current().RateLimit))
+	logSampler := newRequestLogSampler(router.Config.Current().Logging.Requests)
+	cors := newCORSPolicy(router.Config.Current().Server.CORS, router.Config.Current().Environment)
+	router.Config.OnChange(func(cfg *config.Config) {
+		limiter.Update(cfg.RateLimit)
+		triggerLimiter.Update(triggerLimits(cfg.RateLimit))
+		logSampler.Update(cfg.Logging.Requests)
+		cors.Update(cfg.Server.CORS, cfg.Environment)
+	})
+
 	// Add middleware
-	router.Router.Use(loggingMiddleware(router.Logger))
-	router.Router.Use(corsMiddleware())
+	if router.Tracer != nil {
+		router.Router.Use(tracing.Middleware(router.Tracer))
+	}
+	router.Router.Use(loggingMiddleware(router.Logger, logSampler))
+	router.Router.Use(securityHeadersMiddleware(router.Config.Current().Server.TLS))
 	router.Router.Use(recoveryMiddleware(router.Logger))
+	router.Router.Use(rateLimitMiddleware(limiter))
+	router.Router.Use(maintenanceMiddleware(router.MaintenanceHandler.Mode))
 
 	// Health check endpoints
 	router.Router.HandleFunc("/health", healthHandler).Methods("GET")
 	router.Router.HandleFunc("/ready", readinessHandler).Methods("GET")
 	router.Router.HandleFunc("/live", livenessHandler).Methods("GET")
+	router.Router.HandleFunc("/metrics", router.DatabaseHandler.GetPrometheusMetrics).Methods("GET")
 
 	// API v1 routes
 	apiV1 := router.Router.PathPrefix("/api/v1").Subrouter()
 
 	// Setup route groups
-	setupURLRoutes(apiV1, router.URLHandler)
+	setupURLRoutes(apiV1, router.URLHandler, triggerLimiter)
+	setupDomainRoutes(apiV1, router.DomainHandler)
+	setupScheduleRoutes(apiV1, router.ScheduleHandler)
+	setupCostRoutes(apiV1, router.CostHandler)
+	setupExportRoutes(apiV1, router.ExportHandler) // Before data routes, so exports is not taken as a URL ID
 	setupDataRoutes(apiV1, router.DataHandler)
+	setupViewRoutes(apiV1, router.ViewHandler)
 	setupMetricsRoutes(apiV1, router.MetricsHandler)
 	setupAdminRoutes(apiV1, router.AdminHandler)
+	setupWorkerRoutes(apiV1, router.WorkerHandler)
+	setupAlertRoutes(apiV1, router.AlertHandler)
+	setupMaintenanceRoutes(apiV1, router.MaintenanceHandler)
+	setupDatabaseRoutes(apiV1, router.DatabaseHandler)
+	setupEncryptionRoutes(apiV1, router.EncryptionHandler)
+	setupParserRoutes(apiV1, router.ParserHandler)
+	setupFeatureRoutes(apiV1, router.FeatureHandler)
+	setupNotificationRoutes(apiV1, router.NotificationHandler)
 
-	return router.Router
+	// CORS wraps the router so preflight requests are answered before routing
+	return corsMiddleware(cors)(router.Router)
 }
 
 // setupURLRoutes configures URL management routes
@@ -93,28 +205,131 @@ func SetupRoutes(router *types.Router) http.Handler {
 //
 // Routes Configured:
 //   - POST /api/v1/urls - Create a new URL
-//   - GET /api/v1/urls - List all URLs (with pagination)
+//   - POST /api/v1/urls/validate - Validate a URL configuration and test-fetch its page without creating it
+//   - GET /api/v1/urls - List all URLs (with pagination, free-text search and sorting)
+//   - GET /api/v1/urls/stats - URL counts by status, top domains and project
+//   - GET /api/v1/urls/export - Export all URL configurations (JSON or YAML)
+//   - POST /api/v1/urls/import - Create or update URLs from an exported configuration
+//   - DELETE /api/v1/urls/bulk - Soft-delete URLs by IDs, tag or domain (supports dry run)
+//   - POST /api/v1/urls/bulk/restore - Restore soft-deleted URLs by IDs, tag or domain (supports dry run)
+//   - POST /api/v1/urls/bulk/reset - Move failed URLs back to pending by IDs, tag or domain (supports dry run)
+//   - GET /api/v1/urls/moves - URLs detected as permanently redirected to a new location
+//   - GET /api/v1/urls/scrapes - Scrape history of every URL, filtered by response headers
+//   - GET /api/v1/urls/scrapes/{task_id} - One scrape, e.g. to poll a triggered scrape until it completes
 //   - GET /api/v1/urls/{id} - Get specific URL details
 //   - PUT /api/v1/urls/{id} - Update URL configuration
+//   - PATCH /api/v1/urls/{id} - Change part of a URL's configuration with a JSON Merge Patch
 //   - DELETE /api/v1/urls/{id} - Delete a URL
-//   - POST /api/v1/urls/{id}/scrape - Trigger manual scraping
+//   - POST /api/v1/urls/{id}/clone - Create a new URL with the same configuration
+//   - POST /api/v1/urls/{id}/scrape - Trigger manual scraping, limited per client; 202 with the task and its Location
+//   - POST /api/v1/urls/{id}/probe - Check that the URL answers, without scraping it
+//   - POST /api/v1/urls/{id}/reparse - Re-run the parser config over stored raw HTML
+//   - PUT /api/v1/urls/{id}/parser-candidate - Attach a candidate parser config run in shadow mode
+//   - GET /api/v1/urls/{id}/parser-candidate - Get the candidate parser config
+//   - DELETE /api/v1/urls/{id}/parser-candidate - Discard the candidate and its results
+//   - GET /api/v1/urls/{id}/parser-candidate/comparison - Field-level differences between candidate and active parses
+//   - POST /api/v1/urls/{id}/parser-candidate/promote - Make the candidate the active parser config
+//   - GET /api/v1/urls/{id}/parser-config/versions - Version history of the parser config
+//   - GET /api/v1/urls/{id}/parser-config/diff - Settings changed between two parser config versions
+//   - POST /api/v1/urls/{id}/parser-config/rollback/{version} - Restore an earlier parser config version
+//   - GET /api/v1/urls/{id}/scrapes - Scrape history of the URL, filtered by response headers
+//   - POST /api/v1/urls/{id}/har - Capture the next scrape of the URL as a HAR
+//   - GET /api/v1/urls/{id}/har - Download the captured HAR or see the capture's state
+//   - DELETE /api/v1/urls/{id}/har - Cancel or delete the HAR capture
+//   - GET /api/v1/urls/{id}/render-session - Console log and network requests of the latest rendered scrape
 //   - GET /api/v1/urls/{id}/status - Get URL status information
 //
 // Parameters:
 //   - apiV1: Subrouter for API v1 endpoints
 //   - urlHandler: URL handler instance
-func setupURLRoutes(apiV1 *mux.Router, urlHandler *types.URLHandler) {
+func setupURLRoutes(apiV1 *mux.Router, urlHandler *types.URLHandler, triggerLimiter *rateLimiter) {
 	urlRoutes := apiV1.PathPrefix("/urls").Subrouter()
 
 	urlRoutes.HandleFunc("", urlHandler.CreateURL).Methods("POST")
 	urlRoutes.HandleFunc("", urlHandler.ListURLs).Methods("GET")
+	// Validate, stats, export, import, bulk, moves and scrapes routes are registered before /{id} so they are not taken as an ID
+	urlRoutes.HandleFunc("/validate", urlHandler.ValidateURL).Methods("POST")
+	urlRoutes.HandleFunc("/stats", urlHandler.GetURLStats).Methods("GET")
+	urlRoutes.HandleFunc("/export", urlHandler.ExportURLs).Methods("GET")
+	urlRoutes.HandleFunc("/import", urlHandler.ImportURLs).Methods("POST")
+	urlRoutes.HandleFunc("/bulk", urlHandler.BulkDeleteURLs).Methods("DELETE")
+	urlRoutes.HandleFunc("/bulk/restore", urlHandler.BulkRestoreURLs).Methods("POST")
+	urlRoutes.HandleFunc("/bulk/reset", urlHandler.BulkResetURLs).Methods("POST")
+	urlRoutes.HandleFunc("/moves", urlHandler.ListURLMoves).Methods("GET")
+	urlRoutes.HandleFunc("/scrapes", urlHandler.ListScrapes).Methods("GET")
+	urlRoutes.HandleFunc("/scrapes/{task_id}", urlHandler.GetScrape).Methods("GET")
 	urlRoutes.HandleFunc("/{id}", urlHandler.GetURL).Methods("GET")
 	urlRoutes.HandleFunc("/{id}", urlHandler.UpdateURL).Methods("PUT")
+	urlRoutes.HandleFunc("/{id}", urlHandler.PatchURL).Methods("PATCH")
 	urlRoutes.HandleFunc("/{id}", urlHandler.DeleteURL).Methods("DELETE")
-	urlRoutes.HandleFunc("/{id}/scrape", urlHandler.TriggerScrape).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/clone", urlHandler.CloneURL).Methods("POST")
+	urlRoutes.Handle("/{id}/scrape", triggerLimitMiddleware(triggerLimiter)(http.HandlerFunc(urlHandler.TriggerScrape))).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/probe", urlHandler.ProbeURL).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/reparse", urlHandler.ReparseURL).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/parser-candidate", urlHandler.SetParserCandidate).Methods("PUT")
+	urlRoutes.HandleFunc("/{id}/parser-candidate", urlHandler.GetParserCandidate).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/parser-candidate", urlHandler.DeleteParserCandidate).Methods("DELETE")
+	urlRoutes.HandleFunc("/{id}/parser-candidate/comparison", urlHandler.CompareParserCandidate).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/parser-candidate/promote", urlHandler.PromoteParserCandidate).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/parser-config/versions", urlHandler.ListParserConfigVersions).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/parser-config/diff", urlHandler.DiffParserConfigVersions).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/parser-config/rollback/{version}", urlHandler.RollbackParserConfig).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/scrapes", urlHandler.ListURLScrapes).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/har", urlHandler.RequestHARCapture).Methods("POST")
+	urlRoutes.HandleFunc("/{id}/har", urlHandler.GetHARCapture).Methods("GET")
+	urlRoutes.HandleFunc("/{id}/har", urlHandler.DeleteHARCapture).Methods("DELETE")
+	urlRoutes.HandleFunc("/{id}/render-session", urlHandler.GetRenderSession).Methods("GET")
 	urlRoutes.HandleFunc("/{id}/status", urlHandler.GetURLStatus).Methods("GET")
 }
 
+// setupDomainRoutes configures domain routes
+//
+// Purpose: Sets up the per-site operational overview.
+//
+// Routes Configured:
+//   - GET /api/v1/domains - List hosts with URL counts and scrape outcomes
+//   - GET /api/v1/domains/{domain}/cookies - Show the cookie jar kept for a domain
+//   - DELETE /api/v1/domains/{domain}/cookies - Clear the cookie jar kept for a domain
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - domainHandler: Domain handler instance
+func setupDomainRoutes(apiV1 *mux.Router, domainHandler *types.DomainHandler) {
+	apiV1.HandleFunc("/domains", domainHandler.ListDomains).Methods("GET")
+	apiV1.HandleFunc("/domains/{domain}/cookies", domainHandler.GetDomainCookies).Methods("GET")
+	apiV1.HandleFunc("/domains/{domain}/cookies", domainHandler.DeleteDomainCookies).Methods("DELETE")
+}
+
+// setupScheduleRoutes configures scrape schedule routes
+//
+// Purpose: Sets up the forecast of planned scrapes.
+//
+// Routes Configured:
+//   - GET /api/v1/schedule/upcoming - Timeline of scrapes planned within a window
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - scheduleHandler: Schedule handler instance
+func setupScheduleRoutes(apiV1 *mux.Router, scheduleHandler *types.ScheduleHandler) {
+	scheduleRoutes := apiV1.PathPrefix("/schedule").Subrouter()
+
+	scheduleRoutes.HandleFunc("/upcoming", scheduleHandler.GetUpcomingScrapes).Methods("GET")
+}
+
+// setupCostRoutes configures scrape cost routes
+//
+// Purpose: Sets up the cost accounting of proxies and rendering.
+//
+// Routes Configured:
+//   - GET /api/v1/costs - Proxy egress and render time per URL or project
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - costHandler: Cost handler instance
+func setupCostRoutes(apiV1 *mux.Router, costHandler *types.CostHandler) {
+	apiV1.HandleFunc("/costs", costHandler.GetCosts).Methods("GET")
+}
+
 // setupDataRoutes configures data retrieval routes
 //
 // Purpose: Sets up all routes related to data retrieval and export,
@@ -122,8 +337,17 @@ func setupURLRoutes(apiV1 *mux.Router, urlHandler *types.URLHandler) {
 //
 // Routes Configured:
 //   - GET /api/v1/data - List scraped data (with filtering and pagination)
+//   - DELETE /api/v1/data - Hard-delete the parsed and raw data of a URL or schema, optionally in a date range; 202 with the purge job
+//   - GET /api/v1/data/purges/{id} - Status and report of a purge job
 //   - GET /api/v1/data/{url_id} - Get data for specific URL
 //   - GET /api/v1/data/export - Export data in various formats
+//   - GET /api/v1/data/delta - Records changed since a sync token, for incremental replication
+//   - GET /api/v1/data/aggregate - Group parsed records by a field with count/min/max/avg
+//   - GET /api/v1/data/duplicates - Groups of distinct URLs serving nearly the same content
+//   - GET /api/v1/data/quality - Completeness of each parsed field, from the field errors of partial parses
+//   - GET /api/v1/data/records/{id} - Get a single parsed record
+//   - GET /api/v1/data/records/{id}/versions - List the parsed versions of a record's URL and schema
+//   - GET /api/v1/data/records/{id}/text-diff - Lines of normalized text changed since the previous version
 //
 // Parameters:
 //   - apiV1: Subrouter for API v1 endpoints
@@ -131,9 +355,46 @@ func setupURLRoutes(apiV1 *mux.Router, urlHandler *types.URLHandler) {
 func setupDataRoutes(apiV1 *mux.Router, dataHandler *types.DataHandler) {
 	dataRoutes := apiV1.PathPrefix("/data").Subrouter()
 
+	// Export, delta, aggregate, duplicates, quality and purges are registered before /{url_id} so they are not taken as a URL ID
 	dataRoutes.HandleFunc("", dataHandler.ListData).Methods("GET")
-	dataRoutes.HandleFunc("/{url_id}", dataHandler.GetDataByURL).Methods("GET")
+	dataRoutes.HandleFunc("", dataHandler.PurgeData).Methods("DELETE")
+	dataRoutes.HandleFunc("/purges/{id}", dataHandler.GetPurgeJob).Methods("GET")
 	dataRoutes.HandleFunc("/export", dataHandler.ExportData).Methods("GET")
+	dataRoutes.HandleFunc("/delta", dataHandler.GetDataDelta).Methods("GET")
+	dataRoutes.HandleFunc("/aggregate", dataHandler.AggregateData).Methods("GET")
+	dataRoutes.HandleFunc("/duplicates", dataHandler.FindDuplicates).Methods("GET")
+	dataRoutes.HandleFunc("/quality", dataHandler.GetDataQuality).Methods("GET")
+	dataRoutes.HandleFunc("/records/{id}", dataHandler.GetDataRecord).Methods("GET")
+	dataRoutes.HandleFunc("/records/{id}/versions", dataHandler.ListDataVersions).Methods("GET")
+	dataRoutes.HandleFunc("/records/{id}/text-diff", dataHandler.DiffDataText).Methods("GET")
+	dataRoutes.HandleFunc("/{url_id}", dataHandler.GetDataByURL).Methods("GET")
+}
+
+// setupViewRoutes configures data view routes
+//
+// Purpose: Sets up all routes related to data views, which are saved,
+// named filters over parsed data.
+//
+// Routes Configured:
+//   - GET /api/v1/views - List data views
+//   - POST /api/v1/views - Create a data view
+//   - GET /api/v1/views/{name} - Get a specific view
+//   - PUT /api/v1/views/{name} - Replace a view's filters
+//   - DELETE /api/v1/views/{name} - Delete a view
+//   - GET /api/v1/views/{name}/data - Get the parsed records matching a view
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - viewHandler: View handler instance
+func setupViewRoutes(apiV1 *mux.Router, viewHandler *types.ViewHandler) {
+	viewRoutes := apiV1.PathPrefix("/views").Subrouter()
+
+	viewRoutes.HandleFunc("", viewHandler.ListViews).Methods("GET")
+	viewRoutes.HandleFunc("", viewHandler.CreateView).Methods("POST")
+	viewRoutes.HandleFunc("/{name}", viewHandler.GetView).Methods("GET")
+	viewRoutes.HandleFunc("/{name}", viewHandler.UpdateView).Methods("PUT")
+	viewRoutes.HandleFunc("/{name}", viewHandler.DeleteView).Methods("DELETE")
+	viewRoutes.HandleFunc("/{name}/data", viewHandler.GetViewData).Methods("GET")
 }
 
 // setupMetricsRoutes configures metrics routes
@@ -144,6 +405,9 @@ func setupDataRoutes(apiV1 *mux.Router, dataHandler *types.DataHandler) {
 // Routes Configured:
 //   - GET /api/v1/metrics/urls/{id} - Get metrics for specific URL
 //   - GET /api/v1/metrics/system - Get system-wide metrics
+//   - GET /api/v1/metrics/failures - Get scrape failures by failure class
+//   - GET /api/v1/metrics/status-codes - Get scrapes by HTTP status code
+//   - GET /api/v1/metrics/slos - Get SLO compliance and error budgets
 //
 // Parameters:
 //   - apiV1: Subrouter for API v1 endpoints
@@ -153,6 +417,9 @@ func setupMetricsRoutes(apiV1 *mux.Router, metricsHandler *types.MetricsHandler)
 
 	metricsRoutes.HandleFunc("/urls/{id}", metricsHandler.GetURLMetrics).Methods("GET")
 	metricsRoutes.HandleFunc("/system", metricsHandler.GetSystemMetrics).Methods("GET")
+	metricsRoutes.HandleFunc("/failures", metricsHandler.GetFailureMetrics).Methods("GET")
+	metricsRoutes.HandleFunc("/status-codes", metricsHandler.GetStatusCodeMetrics).Methods("GET")
+	metricsRoutes.HandleFunc("/slos", metricsHandler.GetSLOs).Methods("GET")
 }
 
 // setupAdminRoutes configures admin routes
@@ -162,10 +429,16 @@ func setupMetricsRoutes(apiV1 *mux.Router, metricsHandler *types.MetricsHandler)
 //
 // Routes Configured:
 //   - GET /api/v1/admin/dead-letter - List dead letter messages
+//   - DELETE /api/v1/admin/dead-letter - Purge dead letter messages by topic or age
+//   - GET /api/v1/admin/dead-letter/export - Export dead letter messages as NDJSON
+//   - POST /api/v1/admin/dead-letter/import - Replay dead letter messages from NDJSON
 //   - POST /api/v1/admin/dead-letter/bulk-retry - Bulk retry failed messages
 //   - POST /api/v1/admin/dead-letter/{id}/retry - Retry specific message
 //   - DELETE /api/v1/admin/dead-letter/{id} - Delete dead letter message
-//   - GET /api/v1/admin/health - Get comprehensive system health
+//   - POST /api/v1/admin/consumers/pause - Pause consuming a topic through the control topic
+//   - POST /api/v1/admin/consumers/resume - Resume consuming a paused topic
+//   - GET /api/v1/admin/health - Get per-component system health with check latencies
+//   - GET /api/v1/admin/config - Get the effective configuration
 //
 // Parameters:
 //   - apiV1: Subrouter for API v1 endpoints
@@ -175,10 +448,199 @@ func setupAdminRoutes(apiV1 *mux.Router, adminHandler *types.AdminHandler) {
 
 	// Dead letter queue management
 	adminRoutes.HandleFunc("/dead-letter", adminHandler.ListDeadLetterMessages).Methods("GET")
+	adminRoutes.HandleFunc("/dead-letter", adminHandler.PurgeDeadLetterMessages).Methods("DELETE")
+	adminRoutes.HandleFunc("/dead-letter/export", adminHandler.ExportDeadLetterMessages).Methods("GET")
+	adminRoutes.HandleFunc("/dead-letter/import", adminHandler.ImportDeadLetterMessages).Methods("POST")
 	adminRoutes.HandleFunc("/dead-letter/bulk-retry", adminHandler.BulkRetryDeadLetterMessages).Methods("POST")
 	adminRoutes.HandleFunc("/dead-letter/{id}/retry", adminHandler.RetryDeadLetterMessage).Methods("POST")
 	adminRoutes.HandleFunc("/dead-letter/{id}", adminHandler.DeleteDeadLetterMessage).Methods("DELETE")
 
+	// Consumer pause and resume
+	adminRoutes.HandleFunc("/consumers/pause", adminHandler.PauseConsumers).Methods("POST")
+	adminRoutes.HandleFunc("/consumers/resume", adminHandler.ResumeConsumers).Methods("POST")
+
 	// System health
 	adminRoutes.HandleFunc("/health", adminHandler.GetSystemHealth).Methods("GET")
+
+	// Effective configuration
+	adminRoutes.HandleFunc("/config", adminHandler.GetConfig).Methods("GET")
+}
+
+// setupWorkerRoutes configures fleet status routes
+//
+// Purpose: Sets up the status of the scraper and parser instances.
+//
+// Routes Configured:
+//   - GET /api/v1/admin/workers - Instances with version, uptime, load and last heartbeat
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - workerHandler: Worker handler instance
+func setupWorkerRoutes(apiV1 *mux.Router, workerHandler *types.WorkerHandler) {
+	apiV1.HandleFunc("/admin/workers", workerHandler.ListWorkers).Methods("GET")
+}
+
+// setupAlertRoutes configures alert history routes
+//
+// Purpose: Sets up the history of the system alert rules evaluated by the
+// URL Manager.
+//
+// Routes Configured:
+//   - GET /api/v1/admin/alerts - Events of rules that started firing or resolved
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - alertHandler: Alert handler instance
+func setupAlertRoutes(apiV1 *mux.Router, alertHandler *types.AlertHandler) {
+	apiV1.HandleFunc("/admin/alerts", alertHandler.ListAlerts).Methods("GET")
+}
+
+// setupMaintenanceRoutes configures maintenance mode routes
+//
+// Purpose: Sets up the maintenance mode switch used during database
+// migrations and Kafka maintenance.
+//
+// Routes Configured:
+//   - GET /api/v1/admin/maintenance - Whether maintenance mode is on, with its message
+//   - POST /api/v1/admin/maintenance - Switch maintenance mode on or off
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - maintenanceHandler: Maintenance handler instance
+func setupMaintenanceRoutes(apiV1 *mux.Router, maintenanceHandler *types.MaintenanceHandler) {
+	apiV1.HandleFunc("/admin/maintenance", maintenanceHandler.GetMaintenance).Methods("GET")
+	apiV1.HandleFunc("/admin/maintenance", maintenanceHandler.SetMaintenance).Methods("POST")
+}
+
+// setupDatabaseRoutes configures database connection pool routes
+//
+// Purpose: Sets up the diagnostics and runtime tuning of the connection
+// pool, for pool exhaustion incidents.
+//
+// Routes Configured:
+//   - GET /api/v1/admin/database/pool - Pool limits, connections and waits
+//   - PUT /api/v1/admin/database/pool - Change the pool limits until restart
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - databaseHandler: Database handler instance
+func setupDatabaseRoutes(apiV1 *mux.Router, databaseHandler *types.DatabaseHandler) {
+	apiV1.HandleFunc("/admin/database/pool", databaseHandler.GetPool).Methods("GET")
+	apiV1.HandleFunc("/admin/database/pool", databaseHandler.UpdatePool).Methods("PUT")
+}
+
+// setupExportRoutes configures data export routes
+//
+// Purpose: Sets up the exports of the parsed records of a project to files
+// written by the URL Manager, and the signed links they are downloaded with.
+//
+// Routes Configured:
+//   - POST /api/v1/data/exports - Request an export of a project; 202 with the export job
+//   - GET /api/v1/data/exports - Export jobs of a project
+//   - GET /api/v1/data/exports/{id} - Status of an export job of a project and, once completed, a download link
+//   - GET /api/v1/data/exports/{id}/download - Download the file of a signed link, for exports in a directory
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - exportHandler: Export handler instance
+func setupExportRoutes(apiV1 *mux.Router, exportHandler *types.ExportHandler) {
+	apiV1.HandleFunc("/data/exports", exportHandler.CreateExport).Methods("POST")
+	apiV1.HandleFunc("/data/exports", exportHandler.ListExports).Methods("GET")
+	apiV1.HandleFunc("/data/exports/{id}", exportHandler.GetExport).Methods("GET")
+	apiV1.HandleFunc("/data/exports/{id}/download", exportHandler.DownloadExport).Methods("GET")
+}
+
+// setupEncryptionRoutes configures encryption key routes
+//
+// Purpose: Sets up the rotation of the keys encrypting sensitive fields at
+// rest.
+//
+// Routes Configured:
+//   - POST /api/v1/admin/encryption/rotate - Rewrap encrypted records by the primary key
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - encryptionHandler: Encryption handler instance
+func setupEncryptionRoutes(apiV1 *mux.Router, encryptionHandler *types.EncryptionHandler) {
+	apiV1.HandleFunc("/admin/encryption/rotate", encryptionHandler.RotateKeys).Methods("POST")
+}
+
+// setupParserRoutes configures parser template routes
+//
+// Purpose: Sets up all routes related to parser templates, which are
+// reusable parser configurations that URLs reference by name, and to
+// authoring parser configs.
+//
+// Routes Configured:
+//   - GET /api/v1/parser/templates - List built-in and user-defined templates
+//   - POST /api/v1/parser/templates - Create a user-defined template
+//   - GET /api/v1/parser/templates/{name} - Get a specific template
+//   - PUT /api/v1/parser/templates/{name} - Replace a user-defined template
+//   - DELETE /api/v1/parser/templates/{name} - Delete a user-defined template
+//   - POST /api/v1/parser/suggest - Suggest selectors for the common fields of a page
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - parserHandler: Parser handler instance
+func setupParserRoutes(apiV1 *mux.Router, parserHandler *types.ParserHandler) {
+	parserRoutes := apiV1.PathPrefix("/parser").Subrouter()
+
+	parserRoutes.HandleFunc("/templates", parserHandler.ListTemplates).Methods("GET")
+	parserRoutes.HandleFunc("/templates", parserHandler.CreateTemplate).Methods("POST")
+	parserRoutes.HandleFunc("/templates/{name}", parserHandler.GetTemplate).Methods("GET")
+	parserRoutes.HandleFunc("/templates/{name}", parserHandler.UpdateTemplate).Methods("PUT")
+	parserRoutes.HandleFunc("/templates/{name}", parserHandler.DeleteTemplate).Methods("DELETE")
+	parserRoutes.HandleFunc("/suggest", parserHandler.SuggestSelectors).Methods("POST")
+}
+
+// setupFeatureRoutes configures feature flag routes
+//
+// Purpose: Sets up all routes related to feature flags, which gate new
+// behaviour so it can be rolled out gradually and toggled without a redeploy.
+//
+// Routes Configured:
+//   - GET /api/v1/admin/features - List effective flags (optionally evaluated for ?tenant=)
+//   - PUT /api/v1/admin/features/{name} - Create or replace a stored flag
+//   - DELETE /api/v1/admin/features/{name} - Delete a stored flag
+//   - PUT /api/v1/admin/features/{name}/tenants/{tenant} - Set a tenant override
+//   - DELETE /api/v1/admin/features/{name}/tenants/{tenant} - Remove a tenant override
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - featureHandler: Feature handler instance
+func setupFeatureRoutes(apiV1 *mux.Router, featureHandler *types.FeatureHandler) {
+	featureRoutes := apiV1.PathPrefix("/admin/features").Subrouter()
+
+	featureRoutes.HandleFunc("", featureHandler.ListFlags).Methods("GET")
+	featureRoutes.HandleFunc("/{name}", featureHandler.UpsertFlag).Methods("PUT")
+	featureRoutes.HandleFunc("/{name}", featureHandler.DeleteFlag).Methods("DELETE")
+	featureRoutes.HandleFunc("/{name}/tenants/{tenant}", featureHandler.SetOverride).Methods("PUT")
+	featureRoutes.HandleFunc("/{name}/tenants/{tenant}", featureHandler.DeleteOverride).Methods("DELETE")
+}
+
+// setupNotificationRoutes configures notification channel routes
+//
+// Purpose: Sets up all routes related to notification channels, the
+// destinations alerts are delivered to.
+//
+// Routes Configured:
+//   - GET /api/v1/notification-channels - List channels managed through the API
+//   - POST /api/v1/notification-channels - Add a channel
+//   - GET /api/v1/notification-channels/{name} - Get a specific channel
+//   - PUT /api/v1/notification-channels/{name} - Replace a channel
+//   - DELETE /api/v1/notification-channels/{name} - Delete a channel
+//   - POST /api/v1/notification-channels/{name}/test - Send a test alert to a channel
+//
+// Parameters:
+//   - apiV1: Subrouter for API v1 endpoints
+//   - notificationHandler: Notification handler instance
+func setupNotificationRoutes(apiV1 *mux.Router, notificationHandler *types.NotificationHandler) {
+	channelRoutes := apiV1.PathPrefix("/notification-channels").Subrouter()
+
+	channelRoutes.HandleFunc("", notificationHandler.ListChannels).Methods("GET")
+	channelRoutes.HandleFunc("", notificationHandler.CreateChannel).Methods("POST")
+	channelRoutes.HandleFunc("/{name}", notificationHandler.GetChannel).Methods("GET")
+	channelRoutes.HandleFunc("/{name}", notificationHandler.UpdateChannel).Methods("PUT")
+	channelRoutes.HandleFunc("/{name}", notificationHandler.DeleteChannel).Methods("DELETE")
+	channelRoutes.HandleFunc("/{name}/test", notificationHandler.TestChannel).Methods("POST")
 }
@@ -1,145 +1,93 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"strconv"
-	"syscall"
-	"time"
 
 	"go_scraping_project/services/api-gateway/handlers"
-	"go_scraping_project/shared/config"
-	"go_scraping_project/shared/database"
+	"go_scraping_project/services/api-gateway/types"
+	"go_scraping_project/shared/bootstrap"
+	"go_scraping_project/shared/control"
+	"go_scraping_project/shared/events"
+	"go_scraping_project/shared/health"
+	"go_scraping_project/shared/mtls"
 
 	"github.com/joho/godotenv"
-	"github.com/sirupsen/logrus"
 )
 
-// getDatabaseURL returns the database connection URL, prioritizing environment variables over config
-func getDatabaseURL(cfg *config.Loader) string {
-	// Check if DATABASE_URL is set in environment
-	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
-		return databaseURL
-	}
-
-	// Build from config if not in environment
-	dbHost := cfg.GetString("database.host")
-	dbPort := cfg.GetInt("database.port")
-	dbUser := cfg.GetString("database.user")
-	dbPassword := cfg.GetString("database.password")
-	dbName := cfg.GetString("database.database")
-	dbSSLMode := cfg.GetString("database.ssl_mode")
-
-	// Set defaults
-	if dbName == "" {
-		dbName = "scraping_db"
+// setup wires the API Gateway handlers to the shared database queries, the
+// URL events topic, the optional response cache and the system health checks
+func setup(c *bootstrap.Container) (http.Handler, error) {
+	// Initialize sqlc-generated database queries
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
 	}
-	if dbSSLMode == "" {
-		dbSSLMode = "disable"
+	pool, err := c.DBPool()
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
-}
-
-// getLogger initializes and configures a logger based on configuration
-func getLogger(cfg *config.Loader) *logrus.Logger {
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
-
-	// Set log level from config
-	logLevel := cfg.GetString("logging.level")
-	switch logLevel {
-	case "debug":
-		logger.SetLevel(logrus.DebugLevel)
-	case "info":
-		logger.SetLevel(logrus.InfoLevel)
-	case "warn":
-		logger.SetLevel(logrus.WarnLevel)
-	case "error":
-		logger.SetLevel(logrus.ErrorLevel)
-	default:
-		logger.SetLevel(logrus.InfoLevel)
+	// Initialize feature flags
+	flags, err := c.Features()
+	if err != nil {
+		return nil, err
 	}
 
-	return logger
-}
-
-// getServerConfig returns server configuration with fallbacks
-func getServerConfig(cfg *config.Loader) (int, time.Duration, time.Duration, time.Duration) {
-	// Get server configuration from config
-	port := cfg.GetInt("server.port")
-	if port == 0 {
-		port = 8080 // fallback
+	// Reject changes while maintenance mode is on
+	mode, err := c.Maintenance()
+	if err != nil {
+		return nil, err
 	}
 
-	readTimeout := cfg.GetString("server.read_timeout")
-	writeTimeout := cfg.GetString("server.write_timeout")
-	idleTimeout := cfg.GetString("server.idle_timeout")
-
-	// Parse timeouts (with fallbacks)
-	readTimeoutDuration, _ := time.ParseDuration(readTimeout)
-	if readTimeoutDuration == 0 {
-		readTimeoutDuration = 30 * time.Second
+	// Publish URL changes made through the API to the URL events topic, and
+	// replay imported dead letter messages
+	producer, err := c.KafkaProducer()
+	if err != nil {
+		return nil, err
 	}
+	urlEvents := events.NewURLEventPublisher(producer, c.Config().Kafka.Topics.URLEvents, c.ServiceName(), c.Logger())
 
-	writeTimeoutDuration, _ := time.ParseDuration(writeTimeout)
-	if writeTimeoutDuration == 0 {
-		writeTimeoutDuration = 30 * time.Second
+	// Encrypt credentials and sensitive parsed fields at rest
+	keyring, err := c.Keyring()
+	if err != nil {
+		return nil, err
 	}
 
-	idleTimeoutDuration, _ := time.ParseDuration(idleTimeout)
-	if idleTimeoutDuration == 0 {
-		idleTimeoutDuration = 60 * time.Second
+	// Check the database, Kafka, the other services and the workers for the system health endpoint
+	db, err := c.DB()
+	if err != nil {
+		return nil, err
+	}
+	// Calls to the other services use mutual TLS when it is enabled
+	internal, err := mtls.HTTPClient(c.Config().MTLS, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	return port, readTimeoutDuration, writeTimeoutDuration, idleTimeoutDuration
-}
-
-// createServer creates and configures the HTTP server
-func createServer(handler http.Handler, port int, readTimeout, writeTimeout, idleTimeout time.Duration) *http.Server {
-	return &http.Server{
-		Addr:         ":" + strconv.Itoa(port),
-		Handler:      handler,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+	checker := health.NewChecker(c.Config().Health.Timeout)
+	checker.Add("database", db.PingContext)
+	checker.AddProbe("kafka", producer)
+	checker.Add("workers", types.WorkersHealthCheck(queries, c.ConfigWatcher()))
+	responseCache := c.Cache()
+	if responseCache != nil {
+		checker.AddProbe("cache", responseCache)
+	}
+	for name, url := range c.Config().Health.Services {
+		checker.Add(name, health.HTTPCheck(internal, url))
 	}
-}
 
-// startServer starts the HTTP server in a goroutine
-func startServer(server *http.Server, logger *logrus.Logger, port int) {
-	go func() {
-		logger.Infof("Starting API Gateway server on :%d", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.WithError(err).Fatal("Failed to start server")
+	// Initialize router
+	router := handlers.NewRouter(c.Logger(), queries, c.ConfigWatcher(), flags, urlEvents, mode, checker, producer, responseCache, pool, keyring)
+	router.Tracer = c.Tracer()
+	if c.Config().MTLS.Enabled {
+		client, err := mtls.HTTPClient(c.Config().MTLS, c.Config().Control.Timeout)
+		if err != nil {
+			return nil, err
 		}
-	}()
-}
-
-// waitForShutdown waits for interrupt signal and gracefully shuts down the server
-func waitForShutdown(server *http.Server, logger *logrus.Logger) {
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("Shutting down server...")
-
-	// Create a deadline for server shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Fatal("Server forced to shutdown")
+		router.URLHandler.Control = control.NewClient(c.Config().Control, client)
 	}
-
-	logger.Info("Server exited")
+	return handlers.SetupRoutes(router), nil
 }
 
 func main() {
@@ -148,45 +96,9 @@ func main() {
 		fmt.Println("No .env file found, using system environment variables")
 	}
 
-	// Load configuration
-	cfg := config.NewLoader()
-	if err := cfg.LoadServiceConfig("api-gateway"); err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Enable environment variable overrides
-	cfg.LoadFromEnv()
-
-	// Initialize logger
-	logger := getLogger(cfg)
-
-	// Get database URL and set environment variable
-	databaseURL := getDatabaseURL(cfg)
-	os.Setenv("DATABASE_URL", databaseURL)
-
-	// Initialize database connection
-	db, err := database.Connect()
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to connect to database")
-	}
-	defer db.Close()
-
-	// Initialize sqlc-generated database queries
-	queries := database.New(db)
-
-	// Initialize router
-	router := handlers.NewRouter(logger, queries)
-	handler := handlers.SetupRoutes(router)
-
-	// Get server configuration
-	port, readTimeout, writeTimeout, idleTimeout := getServerConfig(cfg)
-
-	// Create HTTP server
-	server := createServer(handler, port, readTimeout, writeTimeout, idleTimeout)
-
-	// Start server
-	startServer(server, logger, port)
-
-	// Wait for shutdown
-	waitForShutdown(server, logger)
+	bootstrap.Exit(bootstrap.Service{
+		Name:         "api-gateway",
+		Setup:        setup,
+		Dependencies: []string{bootstrap.DependencyDatabase, bootstrap.DependencyKafka},
+	})
 }
@@ -1,26 +1,60 @@
 package models
 
+import (
+	"encoding/json"
+
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/notify"
+)
+
 // CreateURLRequest represents the request body for creating a new URL to be scraped.
 // All fields are validated before processing to ensure data integrity.
 type CreateURLRequest struct {
-	URL          string        `json:"url" validate:"required,url"`   // The URL to be scraped (required)
-	Frequency    string        `json:"frequency" validate:"required"` // Scraping frequency (e.g., "1h", "30m", "1d")
-	ParserConfig *ParserConfig `json:"parser_config,omitempty"`       // Configuration for parsing scraped content
-	UserAgent    string        `json:"user_agent,omitempty"`          // Custom user agent for HTTP requests
-	Timeout      int           `json:"timeout,omitempty"`             // Request timeout in seconds
-	RateLimit    int           `json:"rate_limit,omitempty"`          // Requests per minute limit
-	MaxRetries   int           `json:"max_retries,omitempty"`         // Maximum number of retry attempts
+	URL           string                      `json:"url" validate:"required,url,scheme=http https"`  // The URL to be scraped (required)
+	Frequency     string                      `json:"frequency" validate:"required,frequency"`        // Scraping frequency (e.g., "1h", "30m", "1d")
+	ParserConfig  *sharedmodels.ParserConfig  `json:"parser_config,omitempty"`                        // Configuration for parsing scraped content
+	UserAgent     string                      `json:"user_agent,omitempty"`                           // Custom user agent for HTTP requests
+	Timeout       int                         `json:"timeout,omitempty" validate:"min=0,max=300"`     // Request timeout in seconds
+	RateLimit     int                         `json:"rate_limit,omitempty" validate:"min=0,max=1000"` // Requests per minute limit
+	MaxRetries    int                         `json:"max_retries,omitempty" validate:"min=0,max=10"`  // Maximum number of retry attempts
+	RetryPolicy   *sharedmodels.RetryPolicy   `json:"retry_policy,omitempty"`                         // Per-URL retry policy (overrides max_retries)
+	Tags          []string                    `json:"tags,omitempty"`                                 // Labels for grouping URLs, e.g. for bulk actions
+	Project       string                      `json:"project,omitempty"`                              // Project the URL belongs to
+	Assertions    *sharedmodels.Assertions    `json:"assertions,omitempty"`                           // Checks on the fetched content; failing scrapes are soft-failed
+	Region        string                      `json:"region,omitempty"`                               // Region to scrape from, e.g. "eu-west", for geo-restricted content
+	ArchivePolicy *sharedmodels.ArchivePolicy `json:"archive_policy,omitempty"`                       // Which scrapes keep their raw HTML; every scrape when unset
 }
 
 // UpdateURLRequest represents the request body for updating an existing URL.
 // All fields are optional, allowing partial updates of URL configuration.
 type UpdateURLRequest struct {
-	Frequency    string        `json:"frequency,omitempty"`     // New scraping frequency
-	ParserConfig *ParserConfig `json:"parser_config,omitempty"` // Updated parser configuration
-	UserAgent    string        `json:"user_agent,omitempty"`    // New user agent
-	Timeout      int           `json:"timeout,omitempty"`       // New timeout value
-	RateLimit    int           `json:"rate_limit,omitempty"`    // New rate limit
-	MaxRetries   int           `json:"max_retries,omitempty"`   // New max retries
+	Frequency    string                     `json:"frequency,omitempty" validate:"omitempty,frequency"` // New scraping frequency
+	ParserConfig *sharedmodels.ParserConfig `json:"parser_config,omitempty"`                            // Updated parser configuration
+	UserAgent    string                     `json:"user_agent,omitempty"`                               // New user agent
+	Timeout      int                        `json:"timeout,omitempty" validate:"min=0,max=300"`         // New timeout value
+	RateLimit    int                        `json:"rate_limit,omitempty" validate:"min=0,max=1000"`     // New rate limit
+	MaxRetries   int                        `json:"max_retries,omitempty" validate:"min=0,max=10"`      // New max retries
+}
+
+// CloneURLRequest represents the request body for cloning a URL.
+// The new URL gets the full configuration of the source URL.
+type CloneURLRequest struct {
+	URL string `json:"url" validate:"required,url,scheme=http https"` // The URL to be scraped with the copied configuration (required)
+}
+
+// BulkURLRequest represents the request body for bulk URL actions such as delete, restore and reset.
+// At least one filter must be given; filters are combined, so a URL must match all of them.
+type BulkURLRequest struct {
+	IDs    []string `json:"ids,omitempty"`     // Specific URL IDs to act on
+	Tag    string   `json:"tag,omitempty"`     // Only URLs carrying this tag
+	Domain string   `json:"domain,omitempty"`  // Only URLs on this domain or its subdomains
+	DryRun bool     `json:"dry_run,omitempty"` // Preview the affected count without making changes
+}
+
+// ImportURLsRequest represents the request body for importing URL configurations.
+// It has the same shape as ExportURLsResponse, so an export can be imported unchanged.
+type ImportURLsRequest struct {
+	URLs []CreateURLRequest `json:"urls" validate:"required,min=1,dive"` // URL configurations, matched to existing URLs by address
 }
 
 // ExportDataRequest represents the request body for exporting scraped data.
@@ -33,9 +67,137 @@ type ExportDataRequest struct {
 	Limit     int      `json:"limit,omitempty"`                               // Maximum number of records to export
 }
 
+// CreateDataExportRequest represents the request body for exporting the
+// parsed records of a project to a file downloaded through a signed link.
+type CreateDataExportRequest struct {
+	Project string `json:"project" validate:"required,max=100"`        // Project whose records are exported
+	Schema  string `json:"schema,omitempty" validate:"max=100"`        // Only records of this schema
+	Format  string `json:"format" validate:"required,oneof=jsonl csv"` // File format (jsonl, csv)
+	From    string `json:"from,omitempty"`                             // Only records created at or after this time (RFC 3339)
+	To      string `json:"to,omitempty"`                               // Only records created before this time (RFC 3339)
+}
+
 // BulkRetryRequest represents the request body for bulk retry operations.
 // This struct defines parameters for retrying multiple failed messages.
 type BulkRetryRequest struct {
-	MessageIDs []string `json:"message_ids" validate:"required,min=1"` // Array of message IDs to retry
-	Topic      string   `json:"topic,omitempty"`                       // Target topic for retry (optional)
+	MessageIDs []string `json:"message_ids" validate:"required,min=1,max=100"` // Array of message IDs to retry
+	Topic      string   `json:"topic,omitempty"`                               // Target topic for retry (optional)
+}
+
+// ConsumerControlRequest represents the request body for pausing or
+// resuming the consumption of a topic.
+type ConsumerControlRequest struct {
+	Topic  string `json:"topic" validate:"required"` // Topic to pause or resume, * for every topic
+	Group  string `json:"group,omitempty"`           // Consumer group, empty for every group
+	Reason string `json:"reason,omitempty"`          // Why consumption is paused, for the logs
+}
+
+// DeadLetterRecord represents one line of a dead letter export, and of an
+// import replaying messages to their topic. Value is the original message as
+// JSON, or ValueBase64 holds it when it is not JSON.
+type DeadLetterRecord struct {
+	ID          string          `json:"id"`                     // Dead letter message identifier
+	Topic       string          `json:"topic"`                  // Topic the message was consumed from, and is replayed to
+	Partition   int32           `json:"partition"`              // Kafka partition
+	Offset      int64           `json:"offset"`                 // Message offset
+	Key         string          `json:"key"`                    // Message key
+	MessageType string          `json:"message_type,omitempty"` // Type of the message, e.g. scraping_task
+	Value       json.RawMessage `json:"value,omitempty"`        // Original message
+	ValueBase64 string          `json:"value_base64,omitempty"` // Original message when it is not JSON
+	Error       string          `json:"error,omitempty"`        // Why processing failed
+	RetryCount  int             `json:"retry_count"`            // Number of retry attempts
+	FailedAt    string          `json:"failed_at,omitempty"`    // When the message failed
+}
+
+// CreateParserTemplateRequest represents the request body for registering a parser template.
+// Templates can be referenced by name from a URL's parser configuration.
+type CreateParserTemplateRequest struct {
+	Name        string                     `json:"name" validate:"required"`      // Unique template name (lowercase, digits and hyphens)
+	Description string                     `json:"description,omitempty"`         // Human readable description
+	PageType    string                     `json:"page_type" validate:"required"` // Page type the template targets (article, product, ...)
+	Config      *sharedmodels.ParserConfig `json:"config" validate:"required"`    // Selectors and rules provided by the template
+}
+
+// UpdateParserTemplateRequest represents the request body for replacing a parser template.
+// The template name is taken from the path and cannot be changed.
+type UpdateParserTemplateRequest struct {
+	Description string                     `json:"description,omitempty"`         // Human readable description
+	PageType    string                     `json:"page_type" validate:"required"` // Page type the template targets
+	Config      *sharedmodels.ParserConfig `json:"config" validate:"required"`    // Selectors and rules provided by the template
+}
+
+// SuggestSelectorsRequest represents the request body for suggesting the
+// selectors of a page. The page is fetched from URL unless its HTML is given.
+type SuggestSelectorsRequest struct {
+	URL       string `json:"url"`                  // Page to fetch, or the address of the given HTML
+	UserAgent string `json:"user_agent,omitempty"` // User agent to fetch with (default: the default user agent of URLs)
+	HTML      string `json:"html,omitempty"`       // HTML of the page, e.g. saved from a browser, instead of fetching it
+}
+
+// CreateDataViewRequest represents the request body for saving a data view.
+// A view is a named filter over parsed data; empty filters match everything.
+type CreateDataViewRequest struct {
+	Name        string                        `json:"name" validate:"required"` // Unique view name (lowercase, digits and hyphens)
+	Description string                        `json:"description,omitempty"`    // Human readable description
+	Schema      string                        `json:"schema,omitempty"`         // Data schema the view is limited to
+	URLIDs      []string                      `json:"url_ids,omitempty"`        // URLs the view is limited to
+	Predicates  []sharedmodels.FieldPredicate `json:"predicates,omitempty"`     // Conditions on the parsed fields, all must hold
+}
+
+// UpdateDataViewRequest represents the request body for replacing a data view.
+// The view name is taken from the path and cannot be changed.
+type UpdateDataViewRequest struct {
+	Description string                        `json:"description,omitempty"` // Human readable description
+	Schema      string                        `json:"schema,omitempty"`      // Data schema the view is limited to
+	URLIDs      []string                      `json:"url_ids,omitempty"`     // URLs the view is limited to
+	Predicates  []sharedmodels.FieldPredicate `json:"predicates,omitempty"`  // Conditions on the parsed fields, all must hold
+}
+
+// CreateNotificationChannelRequest represents the request body for adding a
+// notification channel. Settings depend on the type, see notify.ChannelSettings.
+type CreateNotificationChannelRequest struct {
+	Name     string                 `json:"name" validate:"required"` // Unique channel name (lowercase, digits and hyphens)
+	Type     string                 `json:"type" validate:"required"` // webhook, slack, email or pagerduty
+	Enabled  *bool                  `json:"enabled,omitempty"`        // Whether alerts are delivered to the channel (default true)
+	Settings notify.ChannelSettings `json:"settings"`                 // Endpoint, SMTP server or routing key
+}
+
+// UpdateNotificationChannelRequest represents the request body for replacing a
+// notification channel. The name is taken from the path; secrets sent back
+// redacted keep their stored value.
+type UpdateNotificationChannelRequest struct {
+	Type     string                 `json:"type" validate:"required"` // webhook, slack, email or pagerduty
+	Enabled  *bool                  `json:"enabled,omitempty"`        // Whether alerts are delivered to the channel (default true)
+	Settings notify.ChannelSettings `json:"settings"`                 // Endpoint, SMTP server or routing key
+}
+
+// UpsertFeatureFlagRequest represents the request body for creating or replacing a feature flag.
+// The flag name is taken from the path. Stored flags take precedence over configured flags.
+type UpsertFeatureFlagRequest struct {
+	Description    string `json:"description,omitempty"`                                        // Human readable description
+	Enabled        bool   `json:"enabled"`                                                      // Whether the flag is on globally
+	RolloutPercent *int   `json:"rollout_percent,omitempty" validate:"omitempty,min=0,max=100"` // Percentage of tenants the flag is on for (0-100, default 100)
+}
+
+// SetFeatureFlagOverrideRequest represents the request body for a per-tenant feature flag override.
+type SetFeatureFlagOverrideRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"` // Whether the flag is on for the tenant
+}
+
+// SetMaintenanceRequest represents the request body for switching maintenance mode on or off.
+type SetMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" validate:"required"` // Whether the system is in maintenance mode
+	Message string `json:"message,omitempty"`           // Banner shown to API clients, e.g. the reason and expected end
+}
+
+// HARCaptureRequest represents the optional request body for requesting a
+// HAR capture of a URL's next scrape.
+type HARCaptureRequest struct {
+	TTL string `json:"ttl,omitempty"` // How long to wait for a scrape, e.g. 30m; default scraping.har.request_ttl
+}
+
+// ParserCandidateRequest represents the request body for attaching a
+// candidate parser config to a URL.
+type ParserCandidateRequest struct {
+	ParserConfig *sharedmodels.ParserConfig `json:"parser_config" validate:"required"` // Config to run in shadow mode (required)
 }
@@ -1,11 +1,21 @@
 package models
 
+import (
+	"encoding/json"
+
+	"go_scraping_project/shared/config"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/notify"
+	"go_scraping_project/shared/parser"
+	"go_scraping_project/shared/render"
+)
+
 // CreateURLResponse represents the response for a successful URL creation.
 // It includes the generated ID and basic status information.
 type CreateURLResponse struct {
 	ID        string `json:"id"`         // Unique identifier for the created URL
 	URL       string `json:"url"`        // The original URL that was registered
-	Status    string `json:"status"`     // Current status (pending, active, paused, etc.)
+	Status    string `json:"status"`     // Current status (pending, retry, failed, degraded or paused)
 	CreatedAt string `json:"created_at"` // ISO 8601 timestamp of creation
 }
 
@@ -21,13 +31,88 @@ type ListURLsResponse struct {
 // URLListItem represents a URL in the list response.
 // It contains essential information for displaying URLs in a list view.
 type URLListItem struct {
-	ID            string  `json:"id"`                        // Unique identifier
-	URL           string  `json:"url"`                       // The URL being scraped
-	Frequency     string  `json:"frequency"`                 // Scraping frequency
-	Status        string  `json:"status"`                    // Current status
-	LastScrapedAt *string `json:"last_scraped_at,omitempty"` // Last successful scrape time
-	NextScrapeAt  *string `json:"next_scrape_at,omitempty"`  // Next scheduled scrape time
-	CreatedAt     string  `json:"created_at"`                // Creation timestamp
+	ID            string   `json:"id"`                        // Unique identifier
+	URL           string   `json:"url"`                       // The URL being scraped
+	Frequency     string   `json:"frequency"`                 // Scraping frequency
+	Status        string   `json:"status"`                    // Current status
+	LastScrapedAt *string  `json:"last_scraped_at,omitempty"` // Last successful scrape time
+	NextScrapeAt  *string  `json:"next_scrape_at,omitempty"`  // Next scheduled scrape time
+	Tags          []string `json:"tags,omitempty"`            // Labels attached to the URL
+	Project       string   `json:"project,omitempty"`         // Project the URL belongs to
+	Region        string   `json:"region,omitempty"`          // Region the URL is scraped from
+	CreatedAt     string   `json:"created_at"`                // Creation timestamp
+}
+
+// URLStatsResponse represents aggregate URL counts for dashboard summaries.
+// Deleted URLs are not counted.
+type URLStatsResponse struct {
+	Total      int64              `json:"total"`       // Number of URLs
+	ByStatus   map[string]int64   `json:"by_status"`   // Number of URLs per status
+	TopDomains []URLCountResponse `json:"top_domains"` // Most used hosts, highest count first
+	Projects   []URLCountResponse `json:"projects"`    // Number of URLs per project, by name
+}
+
+// URLCountResponse represents the number of URLs sharing a host or project.
+type URLCountResponse struct {
+	Name  string `json:"name"`  // Host or project name, empty for URLs without a project
+	Count int64  `json:"count"` // Number of URLs
+}
+
+// BulkURLResponse represents the result of a bulk URL action.
+// For a dry run, Matched is the number of URLs the action would affect and Affected is 0.
+type BulkURLResponse struct {
+	Action   string `json:"action"`   // Bulk action performed (delete, restore, reset)
+	DryRun   bool   `json:"dry_run"`  // Whether changes were only previewed
+	Matched  int64  `json:"matched"`  // Number of URLs matching the filters
+	Affected int64  `json:"affected"` // Number of URLs changed
+}
+
+// ExportURLsResponse represents an export of all URL configurations.
+// Each entry uses the CreateURLRequest fields, so the export can be re-imported or posted as is.
+type ExportURLsResponse struct {
+	ExportedAt string             `json:"exported_at"` // ISO 8601 timestamp of the export
+	Total      int                `json:"total"`       // Number of exported URLs
+	URLs       []CreateURLRequest `json:"urls"`        // URL configurations, sorted by address
+}
+
+// ImportURLsResponse represents the result of a URL configuration import.
+type ImportURLsResponse struct {
+	Total   int `json:"total"`   // Number of URL configurations in the import
+	Created int `json:"created"` // Number of new URLs
+	Updated int `json:"updated"` // Number of existing URLs whose configuration was replaced
+}
+
+// URLValidationResponse represents the result of validating a URL
+// configuration without creating it. Errors would reject the URL on
+// creation; warnings come from a test fetch of its page and would not.
+type URLValidationResponse struct {
+	Valid          bool              `json:"valid"`                      // Whether the URL would be created
+	Errors         []ValidationError `json:"errors"`                     // Problems that would reject the URL
+	Warnings       []string          `json:"warnings"`                   // Problems that would make its scrapes fail, e.g. "site returns 403 to default user agent"
+	NextScrapeAt   string            `json:"next_scrape_at,omitempty"`   // When the first scrape would be scheduled, if the frequency is valid
+	StatusCode     int               `json:"status_code,omitempty"`      // Status of the test fetch, absent when the site was not reachable
+	ResponseTimeMs int64             `json:"response_time_ms,omitempty"` // Duration of the test fetch in milliseconds
+	DNSMs          int64             `json:"dns_ms,omitempty"`           // Time the test fetch spent resolving host names, 0 when cached
+	RobotsAllowed  *bool             `json:"robots_allowed,omitempty"`   // Whether robots.txt allows the page, absent when it could not be read
+}
+
+// URLProbeResponse represents the result of a liveness probe of a stored
+// URL: a single request made as its scrapes would, whose response is not
+// stored. Fields of the response are absent when the site was not reachable.
+type URLProbeResponse struct {
+	URLID          string                  `json:"url_id"`
+	URL            string                  `json:"url"`
+	Method         string                  `json:"method"`                     // HEAD, or GET when the site does not support HEAD
+	UserAgent      string                  `json:"user_agent"`                 // User agent the probe was made with
+	Reachable      bool                    `json:"reachable"`                  // Whether the site answered, with any status
+	StatusCode     int                     `json:"status_code,omitempty"`      // Status of the final response
+	ResponseTimeMs int64                   `json:"response_time_ms,omitempty"` // Time to the final response headers in milliseconds
+	DNSMs          int64                   `json:"dns_ms,omitempty"`           // Time spent resolving host names, 0 when cached
+	Redirects      []sharedmodels.Redirect `json:"redirects,omitempty"`        // Redirects followed, oldest first
+	FinalURL       string                  `json:"final_url,omitempty"`        // URL of the final response when redirected
+	RobotsAllowed  *bool                   `json:"robots_allowed,omitempty"`   // Whether robots.txt allows the page, absent when it could not be read
+	RobotsError    string                  `json:"robots_error,omitempty"`     // Why robots.txt could not be read
+	Error          string                  `json:"error,omitempty"`            // Why the site was not reachable
 }
 
 // ListDataResponse represents the paginated response for listing scraped data.
@@ -42,12 +127,337 @@ type ListDataResponse struct {
 // DataItem represents a scraped data record in the list response.
 // It contains essential information for displaying scraped data.
 type DataItem struct {
-	ID        string `json:"id"`         // Unique identifier
-	URLID     string `json:"url_id"`     // Associated URL ID
-	URL       string `json:"url"`        // The URL that was scraped
-	Title     string `json:"title"`      // Extracted title
-	Content   string `json:"content"`    // Extracted content
-	CreatedAt string `json:"created_at"` // When the data was scraped
+	ID          string `json:"id"`           // Unique identifier
+	URLID       string `json:"url_id"`       // Associated URL ID
+	URL         string `json:"url"`          // The URL that was scraped
+	Title       string `json:"title"`        // Extracted title
+	Content     string `json:"content"`      // Extracted content
+	ContentHash string `json:"content_hash"` // SHA-256 of the extracted content, equal for identical scrapes
+	CreatedAt   string `json:"created_at"`   // When the data was scraped
+}
+
+// DataRecord represents a parsed data record with its structured fields.
+type DataRecord struct {
+	ID          string            `json:"id"`                     // Unique identifier of this parse
+	URLID       string            `json:"url_id"`                 // Associated URL ID
+	URL         string            `json:"url"`                    // The URL that was parsed
+	Schema      string            `json:"schema"`                 // Data schema, e.g. "article" or "product"
+	Title       string            `json:"title,omitempty"`        // Extracted title
+	Content     string            `json:"content,omitempty"`      // Extracted content
+	Metadata    json.RawMessage   `json:"metadata,omitempty"`     // Extracted metadata
+	Data        json.RawMessage   `json:"data,omitempty"`         // Parsed fields
+	Text        string            `json:"text,omitempty"`         // Normalized visible text, when the parser config sets extract_text
+	FieldErrors map[string]string `json:"field_errors,omitempty"` // Why fields were not extracted, by field name; set for partial parses
+	ContentHash string            `json:"content_hash"`           // SHA-256 of the parsed content, equal for identical parses
+	CreatedAt   string            `json:"created_at"`             // When the record was parsed
+	UpdatedAt   string            `json:"updated_at"`             // When the record last changed
+}
+
+// TextDiffResponse represents the lines of normalized text that changed
+// between two parses of a page.
+type TextDiffResponse struct {
+	RecordID  string              `json:"record_id"`  // Record the diff was requested for, the new text
+	AgainstID string              `json:"against_id"` // Record compared against, the old text
+	Added     int                 `json:"added"`      // Number of added lines
+	Removed   int                 `json:"removed"`    // Number of removed lines
+	Changes   []parser.TextChange `json:"changes"`    // Removed and added lines, in order
+}
+
+// DataVersionsResponse represents the parsed versions of a record: every
+// parse of the record's URL under the record's schema.
+type DataVersionsResponse struct {
+	RecordID string       `json:"record_id"` // Record the versions were requested for
+	URLID    string       `json:"url_id"`    // URL the versions were parsed from
+	Schema   string       `json:"schema"`    // Data schema of the versions
+	Versions []DataRecord `json:"versions"`  // Versions, newest first
+	Total    int64        `json:"total"`     // Total number of versions
+	Page     int          `json:"page"`      // Current page number
+	Limit    int          `json:"limit"`     // Number of versions per page
+}
+
+// ReparseResponse reports a reparse of a URL's stored raw HTML.
+type ReparseResponse struct {
+	URLID            string           `json:"url_id"`                      // URL that was reparsed
+	From             string           `json:"from"`                        // Start of the reparsed scrape times
+	To               string           `json:"to"`                          // End of the reparsed scrape times, exclusive
+	Snapshots        int              `json:"snapshots"`                   // Raw HTML snapshots parsed
+	RecordIDs        []string         `json:"record_ids"`                  // Parsed versions created, oldest scrape first
+	CandidateRecords int              `json:"candidate_records,omitempty"` // Parses made with the URL's candidate parser config
+	Failures         []ReparseFailure `json:"failures"`                    // Snapshots that could not be parsed
+	NextFrom         string           `json:"next_from,omitempty"`         // Set when snapshots remain; pass as from to continue
+}
+
+// ReparseFailure describes a snapshot that could not be reparsed.
+type ReparseFailure struct {
+	SnapshotID string `json:"snapshot_id"` // Raw HTML snapshot ID
+	ScrapedAt  string `json:"scraped_at"`  // When the snapshot was scraped
+	Error      string `json:"error"`       // Why parsing failed
+}
+
+// HARCaptureResponse represents the state of a HAR capture of a URL's next scrape.
+type HARCaptureResponse struct {
+	URLID       string `json:"url_id"`                // URL the capture belongs to
+	Status      string `json:"status"`                // pending, scraping or captured
+	TaskID      string `json:"task_id,omitempty"`     // Scrape the capture was claimed by
+	RequestedAt string `json:"requested_at"`          // When the capture was requested
+	CapturedAt  string `json:"captured_at,omitempty"` // When the HAR arrived
+	ExpiresAt   string `json:"expires_at"`            // When the request lapses or the HAR is deleted
+}
+
+// RenderSessionResponse represents the console log and network requests of
+// a URL's latest rendered scrape.
+type RenderSessionResponse struct {
+	URLID      string           `json:"url_id"`            // URL the session belongs to
+	SnapshotID string           `json:"snapshot_id"`       // Raw HTML snapshot the session was kept with
+	TaskID     string           `json:"task_id,omitempty"` // Scrape the session was recorded for
+	StatusCode int32            `json:"status_code"`       // Status code of the page
+	RecordedAt string           `json:"recorded_at"`       // When the session was stored
+	Session    *render.Session  `json:"session"`           // Console messages and requests, in time order
+	Find       string           `json:"find,omitempty"`    // Text searched for in XHR and fetch requests
+	Matches    []render.Request `json:"matches,omitempty"` // XHR and fetch requests whose URL or body contains find
+}
+
+// ParserCandidateResponse represents a URL's candidate parser config.
+type ParserCandidateResponse struct {
+	URLID        string                     `json:"url_id"`        // URL the candidate belongs to
+	ParserConfig *sharedmodels.ParserConfig `json:"parser_config"` // Candidate config, running in shadow mode
+	CreatedAt    string                     `json:"created_at"`    // When a candidate was first attached
+	UpdatedAt    string                     `json:"updated_at"`    // When the candidate last changed
+}
+
+// ParserComparisonResponse compares the parses made with a URL's candidate
+// parser config with those made with its active config.
+type ParserComparisonResponse struct {
+	URLID     string                   `json:"url_id"`    // URL the parses belong to
+	Compared  int                      `json:"compared"`  // Pages parsed with both configs
+	Identical int                      `json:"identical"` // Pages where both configs extracted the same fields
+	Fields    []FieldComparisonSummary `json:"fields"`    // Per-field counts, sorted by field
+	Pages     []PageComparison         `json:"pages"`     // Pages with differences, most recent scrape first
+}
+
+// FieldComparisonSummary counts how the configs compare on one field.
+type FieldComparisonSummary struct {
+	Field              string `json:"field"`                // Parsed field, or title or content
+	Equal              int    `json:"equal"`                // Pages where both extracted the same value
+	Different          int    `json:"different"`            // Pages where both extracted different values
+	MissingInCandidate int    `json:"missing_in_candidate"` // Pages where only the active config extracted it
+	MissingInActive    int    `json:"missing_in_active"`    // Pages where only the candidate extracted it
+}
+
+// PageComparison lists the fields of one page that the configs disagree on.
+type PageComparison struct {
+	RecordID    string            `json:"record_id"`   // Active parse of the page
+	ScrapedAt   string            `json:"scraped_at"`  // When the page was scraped
+	Differences []FieldDifference `json:"differences"` // Differing fields
+}
+
+// FieldDifference is a field whose value differs between the configs.
+// A value is null when that config did not extract the field.
+type FieldDifference struct {
+	Field     string      `json:"field"`
+	Active    interface{} `json:"active"`
+	Candidate interface{} `json:"candidate"`
+}
+
+// ParserConfigVersionResponse represents one version of a URL's parser config.
+type ParserConfigVersionResponse struct {
+	Version      int                        `json:"version"`          // Version number, starting at 1
+	ParserConfig *sharedmodels.ParserConfig `json:"parser_config"`    // Config of the version, null if the URL had none
+	Author       string                     `json:"author,omitempty"` // Who made the change, from the X-Author header
+	Reason       string                     `json:"reason"`           // How the config changed, e.g. "imported" or "rollback to version 3"
+	CreatedAt    string                     `json:"created_at"`       // When the version was recorded
+}
+
+// ParserConfigVersionsResponse represents the version history of a URL's parser config.
+type ParserConfigVersionsResponse struct {
+	URLID    string                        `json:"url_id"`   // URL the versions belong to
+	Versions []ParserConfigVersionResponse `json:"versions"` // Versions, newest first
+	Total    int64                         `json:"total"`    // Total number of versions
+	Page     int                           `json:"page"`     // Current page number
+	Limit    int                           `json:"limit"`    // Number of versions per page
+}
+
+// URLMoveResponse represents a URL whose scrapes were redirected
+// permanently to the same new location several times in a row.
+type URLMoveResponse struct {
+	URLID       string `json:"url_id"`               // URL that moved
+	FromURL     string `json:"from_url"`             // Address that redirected
+	ToURL       string `json:"to_url"`               // Final URL the scrapes ended up at
+	Hits        int    `json:"hits"`                 // Scrapes in a row moved to ToURL
+	FirstSeenAt string `json:"first_seen_at"`        // First scrape moved to ToURL
+	LastSeenAt  string `json:"last_seen_at"`         // Latest scrape moved to ToURL
+	FlaggedAt   string `json:"flagged_at"`           // When the move was detected
+	Applied     bool   `json:"applied"`              // Whether the stored URL was updated to ToURL
+	AppliedAt   string `json:"applied_at,omitempty"` // When the stored URL was updated
+}
+
+// URLMovesResponse represents a page of detected URL moves.
+type URLMovesResponse struct {
+	Moves []URLMoveResponse `json:"moves"` // Moves, most recently seen first
+	Total int64             `json:"total"` // Total number of matching moves
+	Page  int               `json:"page"`  // Current page number
+	Limit int               `json:"limit"` // Number of moves per page
+}
+
+// ScrapeResponse represents one scrape of a URL.
+type ScrapeResponse struct {
+	TaskID      string            `json:"task_id"`                // Scraping task identifier
+	URLID       string            `json:"url_id"`                 // URL that was scraped
+	Attempt     int               `json:"attempt"`                // Attempt number, starting at 1
+	Status      string            `json:"status"`                 // pending, success, retry, failed, soft_failed or throttled
+	StatusCode  int               `json:"status_code,omitempty"`  // HTTP status code of the response
+	ErrorCode   string            `json:"error_code,omitempty"`   // Failure class of a failed scrape
+	DurationMs  int64             `json:"duration_ms,omitempty"`  // Time taken by the fetch in milliseconds
+	DNSMs       int64             `json:"dns_ms,omitempty"`       // Time spent resolving host names, 0 when cached
+	FinalURL    string            `json:"final_url,omitempty"`    // URL reached after redirects
+	Headers     map[string]string `json:"headers,omitempty"`      // Captured response headers by lowercase name
+	CreatedAt   string            `json:"created_at"`             // When the task was published
+	CompletedAt string            `json:"completed_at,omitempty"` // When the result was recorded
+}
+
+// DataPurgeJobResponse represents a job deleting the data matching a filter
+// and, once completed, its report of what was deleted.
+type DataPurgeJobResponse struct {
+	ID          string          `json:"id"`                     // Purge job identifier
+	Status      string          `json:"status"`                 // pending, running, completed or failed
+	URLID       string          `json:"url_id,omitempty"`       // Filter: only data of this URL
+	Schema      string          `json:"schema,omitempty"`       // Filter: only data of this schema
+	From        string          `json:"from,omitempty"`         // Filter: only data created at or after this time
+	To          string          `json:"to,omitempty"`           // Filter: only data created before this time
+	RequestedBy string          `json:"requested_by,omitempty"` // Who requested the purge
+	Deleted     DataPurgeCounts `json:"deleted"`                // Rows deleted so far
+	Error       string          `json:"error,omitempty"`        // Why a failed purge stopped
+	CreatedAt   string          `json:"created_at"`             // When the purge was requested
+	UpdatedAt   string          `json:"updated_at"`             // When the purge last made progress
+	CompletedAt string          `json:"completed_at,omitempty"` // When the purge completed or failed
+}
+
+// DataPurgeCounts represents the rows deleted by a purge, by kind of data.
+type DataPurgeCounts struct {
+	ParsedData  int64 `json:"parsed_data"`  // Parsed records, with their candidate parses
+	RawHTML     int64 `json:"raw_html"`     // Raw HTML snapshots, with their render sessions
+	HARCaptures int64 `json:"har_captures"` // HAR captures
+}
+
+// EncryptionRotationResponse represents the records rewrapped by the primary
+// encryption key in one rotation call.
+type EncryptionRotationResponse struct {
+	PrimaryKeyID         string `json:"primary_key_id"`        // Key now wrapping the rewrapped records
+	NotificationChannels int    `json:"notification_channels"` // Channels rewrapped or encrypted
+	ParsedRecords        int    `json:"parsed_records"`        // Parsed records rewrapped
+	Remaining            bool   `json:"remaining"`             // Whether parsed records are left for another call
+}
+
+// DataExportJobResponse represents a job exporting the parsed records of a
+// project to a file and, once completed, a signed link to download it.
+type DataExportJobResponse struct {
+	ID                string `json:"id"`                            // Export job identifier
+	Status            string `json:"status"`                        // pending, running, completed, failed or expired
+	Project           string `json:"project"`                       // Project whose records are exported
+	Schema            string `json:"schema,omitempty"`              // Filter: only records of this schema
+	Format            string `json:"format"`                        // File format: jsonl or csv
+	Schedule          string `json:"schedule,omitempty"`            // Schedule that created the job, empty when requested through the API
+	From              string `json:"from,omitempty"`                // Filter: only records created at or after this time
+	To                string `json:"to,omitempty"`                  // Filter: only records created before this time
+	RequestedBy       string `json:"requested_by,omitempty"`        // Who requested the export
+	RecordCount       int64  `json:"record_count"`                  // Records written so far
+	SizeBytes         int64  `json:"size_bytes,omitempty"`          // Size of the completed file
+	DownloadURL       string `json:"download_url,omitempty"`        // Signed link to the file of a completed export
+	DownloadExpiresAt string `json:"download_expires_at,omitempty"` // When DownloadURL stops working
+	Error             string `json:"error,omitempty"`               // Why a failed export stopped
+	CreatedAt         string `json:"created_at"`                    // When the export was requested
+	CompletedAt       string `json:"completed_at,omitempty"`        // When the export completed or failed
+	ExpiresAt         string `json:"expires_at,omitempty"`          // When the file is deleted
+}
+
+// DataExportJobsResponse represents the export jobs of a project.
+type DataExportJobsResponse struct {
+	Exports []DataExportJobResponse `json:"exports"` // Export jobs, newest first
+}
+
+// ScrapesResponse represents a page of scrape history.
+type ScrapesResponse struct {
+	Scrapes []ScrapeResponse `json:"scrapes"` // Scrapes, newest first
+	Total   int64            `json:"total"`   // Total number of matching scrapes
+	Page    int              `json:"page"`    // Current page number
+	Limit   int              `json:"limit"`   // Number of scrapes per page
+}
+
+// ParserConfigDiffResponse represents the changes between two parser config versions.
+type ParserConfigDiffResponse struct {
+	URLID   string                `json:"url_id"`  // URL the versions belong to
+	From    int                   `json:"from"`    // Older version, 0 for an empty config
+	To      int                   `json:"to"`      // Newer version
+	Changes []parser.ConfigChange `json:"changes"` // Changed settings, sorted by path
+}
+
+// DataAggregateResponse represents parsed data grouped by a parsed field.
+type DataAggregateResponse struct {
+	Schema  string               `json:"schema,omitempty"` // Data schema the records were limited to
+	GroupBy string               `json:"group_by"`         // Parsed field the records were grouped by
+	Field   string               `json:"field,omitempty"`  // Parsed field that was aggregated
+	Groups  []DataAggregateGroup `json:"groups"`           // Groups, largest first
+}
+
+// DataAggregateGroup represents the aggregates of one group of parsed records.
+// Min, Max and Avg are only set when the group has numeric values.
+type DataAggregateGroup struct {
+	Value      string   `json:"value"`         // Value of the group field, empty for records without it
+	Count      int64    `json:"count"`         // Number of records in the group
+	ValueCount int64    `json:"value_count"`   // Number of records with a numeric value in the aggregated field
+	Min        *float64 `json:"min,omitempty"` // Smallest value
+	Max        *float64 `json:"max,omitempty"` // Largest value
+	Avg        *float64 `json:"avg,omitempty"` // Average value
+}
+
+// DuplicatesResponse represents groups of distinct URLs whose latest parses
+// have nearly the same content.
+type DuplicatesResponse struct {
+	Schema      string           `json:"schema,omitempty"` // Data schema the records were limited to
+	MaxDistance int              `json:"max_distance"`     // Largest simhash distance between duplicates
+	Scanned     int              `json:"scanned"`          // Number of URLs compared
+	Truncated   bool             `json:"truncated"`        // Whether more URLs exist than were compared
+	Groups      []DuplicateGroup `json:"groups"`           // Groups, in order of their first URL
+	Total       int              `json:"total"`            // Total number of groups
+}
+
+// DuplicateGroup represents URLs serving essentially the same content.
+type DuplicateGroup struct {
+	Records []DuplicateRecord `json:"records"` // Latest parse of each URL, the first being the reference
+}
+
+// DuplicateRecord represents the latest parse of a URL in a duplicate group.
+type DuplicateRecord struct {
+	RecordID string `json:"record_id"` // Parsed record compared
+	URLID    string `json:"url_id"`    // URL the record was parsed from
+	URL      string `json:"url"`       // Address of the URL
+	Schema   string `json:"schema"`    // Data schema of the record
+	Distance int    `json:"distance"`  // Simhash distance from the group's first record
+}
+
+// DataQualityResponse represents how completely the latest parses of URLs
+// extract each field.
+type DataQualityResponse struct {
+	Schema string              `json:"schema,omitempty"` // Data schema the records were limited to
+	Fields []FieldCompleteness `json:"fields"`           // Fields, least complete first
+}
+
+// FieldCompleteness represents how often a field was extracted.
+type FieldCompleteness struct {
+	Field        string  `json:"field"`                  // Field name
+	Extracted    int64   `json:"extracted"`              // Number of records the field was extracted from
+	Failed       int64   `json:"failed"`                 // Number of records whose selector or rule for the field failed
+	Completeness float64 `json:"completeness"`           // Percentage of records the field was extracted from, 0 to 100
+	SampleError  string  `json:"sample_error,omitempty"` // One of the errors of the failed records
+}
+
+// DataDeltaResponse represents the records changed since a sync token.
+// Clients store next_token and pass it on the next call; when has_more is
+// set they can call again right away.
+type DataDeltaResponse struct {
+	Data      []DataRecord `json:"data"`       // Changed records, oldest change first
+	NextToken string       `json:"next_token"` // Sync token to resume from
+	HasMore   bool         `json:"has_more"`   // Whether more changes are waiting
 }
 
 // URLMetricsResponse represents metrics data for a specific URL.
@@ -89,19 +499,205 @@ type SystemMetricsResponse struct {
 	LastUpdated         string  `json:"last_updated"`      // Last metrics update timestamp
 }
 
+// FailureCountResponse represents the number of failed scrapes in one failure class
+type FailureCountResponse struct {
+	ErrorCode string `json:"error_code"` // Failure class, e.g. timeout or http_4xx
+	Retryable bool   `json:"retryable"`  // Whether failures of this class are retried by default
+	Count     int64  `json:"count"`      // Number of failed attempts in the period
+}
+
+// FailureMetricsResponse represents a breakdown of scrape failures by failure class.
+// Every class is listed, including those without failures in the period.
+type FailureMetricsResponse struct {
+	Period   string                 `json:"period"`   // Time period covered (1h, 24h, 7d, 30d)
+	Since    string                 `json:"since"`    // Start of the period
+	Total    int64                  `json:"total"`    // Total number of failed attempts
+	Failures []FailureCountResponse `json:"failures"` // Counts per failure class, most frequent first
+}
+
+// StatusClassCountResponse represents the number of scrapes in one HTTP status class
+type StatusClassCountResponse struct {
+	Class   string  `json:"class"`   // 1xx to 5xx, or none for scrapes that got no response
+	Count   int64   `json:"count"`   // Number of scrapes in the period
+	Percent float64 `json:"percent"` // Share of the scrapes in the period
+}
+
+// StatusCodeCountResponse represents the number of scrapes answered with one
+// HTTP status code, compared with the previous period of the same length
+type StatusCodeCountResponse struct {
+	StatusCode      int     `json:"status_code"`        // 0 for scrapes that got no response
+	Class           string  `json:"class"`              // 1xx to 5xx, or none
+	Count           int64   `json:"count"`              // Number of scrapes in the period
+	Percent         float64 `json:"percent"`            // Share of the scrapes in the period
+	PreviousCount   int64   `json:"previous_count"`     // Number of scrapes in the previous period
+	PreviousPercent float64 `json:"previous_percent"`   // Share of the scrapes in the previous period
+	Blocking        bool    `json:"blocking,omitempty"` // 403 or 429, the usual answers of sites blocking scrapers
+	Spike           bool    `json:"spike,omitempty"`    // An error code whose share at least doubled
+}
+
+// StatusCodeMetricsResponse represents a breakdown of completed scrapes by
+// HTTP status code across all URLs
+type StatusCodeMetricsResponse struct {
+	Period        string                     `json:"period"`         // Time period covered (1h, 24h, 7d, 30d)
+	Since         string                     `json:"since"`          // Start of the period
+	PreviousSince string                     `json:"previous_since"` // Start of the previous period, which ends at since
+	Total         int64                      `json:"total"`          // Scrapes completed in the period
+	PreviousTotal int64                      `json:"previous_total"` // Scrapes completed in the previous period
+	Classes       []StatusClassCountResponse `json:"classes"`        // Counts per status class, in class order
+	Codes         []StatusCodeCountResponse  `json:"codes"`          // Counts per status code, in code order
+	Spikes        []int                      `json:"spikes"`         // Status codes flagged as spikes, blocking codes first
+}
+
+// SLOErrorBudgetResponse represents how much of an objective's error budget,
+// the scrapes allowed to miss it in the period, is left
+type SLOErrorBudgetResponse struct {
+	Allowed          int64   `json:"allowed"`           // Scrapes that may miss the objective
+	Spent            int64   `json:"spent"`             // Scrapes that missed it
+	Remaining        int64   `json:"remaining"`         // Allowed minus spent, negative when overspent
+	RemainingPercent float64 `json:"remaining_percent"` // Share of the budget left, negative when overspent
+}
+
+// SLOResponse represents the compliance of one service level objective
+type SLOResponse struct {
+	Name        string                 `json:"name"`
+	Kind        string                 `json:"kind"`                // scheduling_latency or success
+	Objective   float64                `json:"objective"`           // Percentage of scrapes that must meet the objective
+	MaxDelay    string                 `json:"max_delay,omitempty"` // Of scheduling latency objectives
+	Project     string                 `json:"project,omitempty"`   // Project the objective is limited to
+	Total       int64                  `json:"total"`               // Scrapes counted
+	Good        int64                  `json:"good"`                // Scrapes that met the objective
+	Compliance  float64                `json:"compliance"`          // Percentage of scrapes that met it, 100 without scrapes
+	Met         bool                   `json:"met"`                 // Whether compliance reaches the objective
+	ErrorBudget SLOErrorBudgetResponse `json:"error_budget"`
+}
+
+// SLOsResponse represents the compliance of every configured service level
+// objective over a period
+type SLOsResponse struct {
+	Period string        `json:"period"` // Time period covered (1h, 24h, 7d, 30d)
+	Since  string        `json:"since"`  // Start of the period
+	SLOs   []SLOResponse `json:"slos"`   // In configuration order
+}
+
+// ListDomainsResponse represents the operational overview of all scraped hosts.
+type ListDomainsResponse struct {
+	Period  string           `json:"period"`  // Time period of the attempt statistics (1h, 24h, 7d, 30d)
+	Since   string           `json:"since"`   // Start of the period
+	Domains []DomainResponse `json:"domains"` // Hosts, most URLs first
+}
+
+// DomainResponse represents a scraped host with its URLs and recent scrape outcomes.
+type DomainResponse struct {
+	Domain         string  `json:"domain"`          // Host name
+	URLCount       int64   `json:"url_count"`       // Number of URLs on the host
+	Attempts       int64   `json:"attempts"`        // Scrape attempts completed in the period
+	SuccessRate    float64 `json:"success_rate"`    // Percentage of successful attempts
+	AverageLatency float64 `json:"average_latency"` // Average attempt duration in milliseconds
+	BlockIncidents int64   `json:"block_incidents"` // Attempts that were blocked or rate limited
+	Blocked        int64   `json:"blocked"`         // Attempts denied by the site or a bot protection
+	RateLimited    int64   `json:"rate_limited"`    // Attempts answered with 429 Too Many Requests
+	RobotsDenied   int64   `json:"robots_denied"`   // Attempts disallowed by robots.txt
+	RobotsPolicy   string  `json:"robots_policy"`   // ignored, allowed or restricted
+	ThrottledMs    int64   `json:"throttled_ms"`    // Time attempts waited for URL and domain rate limits
+}
+
+// DomainCookiesResponse represents the cookie jar kept for a domain between scrapes.
+type DomainCookiesResponse struct {
+	Domain      string `json:"domain"`       // Registrable domain the jar is kept under
+	CookieCount int32  `json:"cookie_count"` // Number of stored cookies
+	ExpiresAt   string `json:"expires_at"`   // When the last stored cookie expires
+	UpdatedAt   string `json:"updated_at"`   // Last time a scrape saved the jar
+}
+
+// CostsResponse represents the costs of scrapes aggregated per URL or project.
+type CostsResponse struct {
+	Period  string         `json:"period"`   // Time period of the scrapes (1h, 24h, 7d, 30d)
+	Since   string         `json:"since"`    // Start of the period
+	GroupBy string         `json:"group_by"` // url or project
+	Total   CostResponse   `json:"total"`    // Costs of all scrapes in the period
+	Costs   []CostResponse `json:"costs"`    // Costs per URL or project, most proxy egress first
+}
+
+// CostResponse represents the costs of the scrapes of a URL, a project or all URLs.
+type CostResponse struct {
+	URLID            string  `json:"url_id,omitempty"`   // URL identifier (group_by=url)
+	URL              string  `json:"url,omitempty"`      // The scraped URL (group_by=url)
+	Project          string  `json:"project,omitempty"`  // Project of the URLs
+	Scrapes          int64   `json:"scrapes"`            // Scrape attempts completed in the period
+	ProxyEgressBytes int64   `json:"proxy_egress_bytes"` // Bytes sent and received through proxies
+	RenderSeconds    float64 `json:"render_seconds"`     // Time spent rendering in headless browsers
+}
+
+// WorkersResponse represents the scraper and parser fleet.
+type WorkersResponse struct {
+	HeartbeatInterval string           `json:"heartbeat_interval"` // Expected time between heartbeats
+	Total             int              `json:"total"`              // Number of registered instances
+	Stale             int              `json:"stale"`              // Instances that missed three heartbeats
+	Workers           []WorkerResponse `json:"workers"`            // Instances, most recently seen first
+}
+
+// WorkerResponse represents a scraper or parser instance.
+type WorkerResponse struct {
+	ID              string   `json:"id"`                      // Instance identifier
+	Kind            string   `json:"kind"`                    // scraper or parser
+	Version         string   `json:"version"`                 // Build version
+	Host            string   `json:"host"`                    // Host the instance runs on
+	Region          string   `json:"region,omitempty"`        // Region the instance scrapes from
+	Status          string   `json:"status"`                  // alive or stale
+	StartedAt       string   `json:"started_at"`              // When the instance started
+	UptimeSeconds   int64    `json:"uptime_seconds"`          // Time since start, up to the last heartbeat
+	LastHeartbeatAt string   `json:"last_heartbeat_at"`       // When the instance last reported
+	ActiveTasks     int32    `json:"active_tasks"`            // Tasks running at the last heartbeat
+	Capacity        int32    `json:"capacity"`                // Tasks the instance runs at once
+	QueuedTasks     int32    `json:"queued_tasks"`            // Tasks waiting for a worker
+	Load            float64  `json:"load"`                    // active_tasks / capacity
+	PausedTopics    []string `json:"paused_topics,omitempty"` // Topics the instance stopped consuming, * for all
+}
+
+// UpcomingScrapesResponse represents the scrapes planned within a time window.
+type UpcomingScrapesResponse struct {
+	Window    string                   `json:"window"`    // Forecast window, e.g. 24h0m0s
+	From      string                   `json:"from"`      // Start of the window (now)
+	Until     string                   `json:"until"`     // End of the window
+	Total     int                      `json:"total"`     // Number of planned scrapes in the window
+	Truncated bool                     `json:"truncated"` // Whether scrapes were left out because of limits
+	Scrapes   []UpcomingScrapeResponse `json:"scrapes"`   // Planned scrapes in time order
+	Load      []ScheduleLoadResponse   `json:"load"`      // Number of planned scrapes per hour
+}
+
+// UpcomingScrapeResponse represents a planned scrape of a URL.
+type UpcomingScrapeResponse struct {
+	URLID       string `json:"url_id"`       // URL identifier
+	URL         string `json:"url"`          // The URL to scrape
+	ScheduledAt string `json:"scheduled_at"` // When the scrape is planned
+	Priority    string `json:"priority"`     // high for retries and degraded URLs, normal otherwise
+	Overdue     bool   `json:"overdue"`      // Whether the scrape is past due and will be dispatched now
+}
+
+// ScheduleLoadResponse represents the number of scrapes planned within an hour.
+type ScheduleLoadResponse struct {
+	Start string `json:"start"` // Start of the hour
+	Count int    `json:"count"` // Number of planned scrapes
+}
+
 // DeadLetterMessageResponse represents a single dead letter message.
-// It contains information about a failed message that couldn't be processed.
+// It contains information about a failed message that couldn't be processed,
+// with the original message decoded by its message type.
 type DeadLetterMessageResponse struct {
-	ID         string `json:"id"`          // Unique message identifier
-	Topic      string `json:"topic"`       // Source topic
-	Partition  int32  `json:"partition"`   // Kafka partition
-	Offset     int64  `json:"offset"`      // Message offset
-	Key        string `json:"key"`         // Message key
-	Value      string `json:"value"`       // Message value (truncated)
-	Error      string `json:"error"`       // Error message
-	RetryCount int    `json:"retry_count"` // Number of retry attempts
-	CreatedAt  string `json:"created_at"`  // When the message was created
-	FailedAt   string `json:"failed_at"`   // When the message failed
+	ID         string `json:"id"`              // Unique message identifier
+	Topic      string `json:"topic"`           // Source topic
+	Partition  int32  `json:"partition"`       // Kafka partition
+	Offset     int64  `json:"offset"`          // Message offset
+	Key        string `json:"key"`             // Message key
+	Value      string `json:"value,omitempty"` // Raw message value (truncated) when it could not be decoded
+	Error      string `json:"error"`           // Error message
+	RetryCount int    `json:"retry_count"`     // Number of retry attempts
+	CreatedAt  string `json:"created_at"`      // When the message was created
+	FailedAt   string `json:"failed_at"`       // When the message failed
+
+	MessageType  string      `json:"message_type,omitempty"`  // Type of the original message, e.g. scraping_task
+	Payload      interface{} `json:"payload,omitempty"`       // Original message decoded by its type
+	PayloadError string      `json:"payload_error,omitempty"` // Why the value could not be decoded
 }
 
 // ListDeadLetterMessagesResponse represents the paginated response for dead letter messages.
@@ -113,6 +709,42 @@ type ListDeadLetterMessagesResponse struct {
 	Limit    int                         `json:"limit"`    // Number of items per page
 }
 
+// PurgeDeadLetterResponse represents the outcome of a dead letter purge.
+type PurgeDeadLetterResponse struct {
+	Topic        string `json:"topic,omitempty"`         // Topic filter, empty for every topic
+	FailedBefore string `json:"failed_before,omitempty"` // Age filter: messages that failed before this time
+	DryRun       bool   `json:"dry_run"`                 // Whether messages were only counted
+	Matched      int64  `json:"matched"`                 // Number of messages matching the filters
+	Deleted      int64  `json:"deleted"`                 // Number of messages deleted, 0 for a dry run
+}
+
+// ConsumerControlResponse represents a pause or resume command sent to the
+// consumers.
+type ConsumerControlResponse struct {
+	Action   string `json:"action"`           // pause or resume
+	Topic    string `json:"topic"`            // Topic paused or resumed, * for every topic
+	Group    string `json:"group,omitempty"`  // Consumer group, empty for every group
+	Reason   string `json:"reason,omitempty"` // Why consumption is paused
+	IssuedAt string `json:"issued_at"`        // When the command was sent
+}
+
+// DeadLetterImportResponse represents the outcome of a dead letter import.
+type DeadLetterImportResponse struct {
+	DryRun   bool                    `json:"dry_run"`          // Whether the records were only validated
+	Received int                     `json:"received"`         // Number of records in the import
+	Replayed int                     `json:"replayed"`         // Number of messages sent back to their topic
+	Removed  int                     `json:"removed"`          // Number of replayed messages removed from the dead letter queue
+	Failed   int                     `json:"failed"`           // Number of messages that could not be sent
+	Errors   []DeadLetterImportError `json:"errors,omitempty"` // Messages that could not be sent or removed
+}
+
+// DeadLetterImportError describes a record of an import that could not be replayed.
+type DeadLetterImportError struct {
+	Line  int    `json:"line"`         // Line of the record in the import, starting at 1
+	ID    string `json:"id,omitempty"` // Dead letter message identifier
+	Error string `json:"error"`        // Why the message could not be sent
+}
+
 // HealthResponse represents the health check response.
 // It provides information about the service's health status.
 type HealthResponse struct {
@@ -122,3 +754,159 @@ type HealthResponse struct {
 	Version   string            `json:"version"`          // Service version
 	Checks    map[string]string `json:"checks,omitempty"` // Individual health checks
 }
+
+// SystemHealthResponse represents the health of the whole system.
+// Its status is the worst status of its components.
+type SystemHealthResponse struct {
+	Status     string                    `json:"status"`     // Overall health status (healthy, degraded, unhealthy)
+	Timestamp  string                    `json:"timestamp"`  // Health check timestamp
+	Uptime     string                    `json:"uptime"`     // API Gateway uptime duration
+	Components []ComponentHealthResponse `json:"components"` // Per-component health, sorted by name
+}
+
+// ComponentHealthResponse represents the health of one component, such as
+// the database, Kafka, a service or the worker fleet
+type ComponentHealthResponse struct {
+	Name      string `json:"name"`            // Component name
+	Status    string `json:"status"`          // healthy, degraded or unhealthy
+	LatencyMS int64  `json:"latency_ms"`      // Duration of the check in milliseconds
+	Error     string `json:"error,omitempty"` // Why the component is not healthy
+}
+
+// ParserTemplateResponse represents a single parser template.
+// Built-in templates have no timestamps and cannot be modified.
+type ParserTemplateResponse struct {
+	Name        string                    `json:"name"`                  // Unique template name
+	Description string                    `json:"description,omitempty"` // Human readable description
+	PageType    string                    `json:"page_type"`             // Page type the template targets
+	Config      sharedmodels.ParserConfig `json:"config"`                // Selectors and rules provided by the template
+	BuiltIn     bool                      `json:"built_in"`              // Whether the template ships with the system
+	CreatedAt   string                    `json:"created_at,omitempty"`  // Creation timestamp (user-defined templates only)
+	UpdatedAt   string                    `json:"updated_at,omitempty"`  // Last update timestamp (user-defined templates only)
+}
+
+// SuggestSelectorsResponse represents the selectors suggested for a page,
+// and a parser config made of the best suggestion of each field to start
+// authoring from
+type SuggestSelectorsResponse struct {
+	URL         string                     `json:"url,omitempty"`
+	StatusCode  int                        `json:"status_code,omitempty"` // Status of the fetch, absent when the HTML was given
+	Suggestions []parser.Suggestion        `json:"suggestions"`           // Candidate selectors grouped by field, best first
+	Config      *sharedmodels.ParserConfig `json:"config"`
+}
+
+// ListParserTemplatesResponse represents the response for listing parser templates.
+// Built-in templates are listed first, followed by user-defined templates.
+type ListParserTemplatesResponse struct {
+	Templates []ParserTemplateResponse `json:"templates"` // Array of templates
+	Total     int                      `json:"total"`     // Total number of templates
+}
+
+// DataViewResponse represents a saved data view.
+type DataViewResponse struct {
+	Name        string                        `json:"name"`                  // Unique view name
+	Description string                        `json:"description,omitempty"` // Human readable description
+	Schema      string                        `json:"schema,omitempty"`      // Data schema the view is limited to
+	URLIDs      []string                      `json:"url_ids,omitempty"`     // URLs the view is limited to
+	Predicates  []sharedmodels.FieldPredicate `json:"predicates,omitempty"`  // Conditions on the parsed fields
+	CreatedAt   string                        `json:"created_at"`            // Creation timestamp
+	UpdatedAt   string                        `json:"updated_at"`            // Last update timestamp
+}
+
+// ListDataViewsResponse represents the response for listing data views.
+type ListDataViewsResponse struct {
+	Views []DataViewResponse `json:"views"` // Array of views, by name
+	Total int                `json:"total"` // Total number of views
+}
+
+// DataViewDataResponse represents the parsed records matching a data view.
+// Each URL contributes its latest parse only.
+type DataViewDataResponse struct {
+	View    string       `json:"view"`     // View name
+	Data    []DataRecord `json:"data"`     // Matching records, by URL
+	Page    int          `json:"page"`     // Current page number
+	Limit   int          `json:"limit"`    // Number of records per page
+	HasMore bool         `json:"has_more"` // Whether later pages have records
+}
+
+// NotificationChannelResponse represents a notification channel managed
+// through the API. Secrets in its settings are redacted.
+type NotificationChannelResponse struct {
+	Name      string                 `json:"name"`       // Unique channel name
+	Type      string                 `json:"type"`       // webhook, slack, email or pagerduty
+	Enabled   bool                   `json:"enabled"`    // Whether alerts are delivered to the channel
+	Settings  notify.ChannelSettings `json:"settings"`   // Settings with secrets redacted
+	CreatedAt string                 `json:"created_at"` // Creation timestamp
+	UpdatedAt string                 `json:"updated_at"` // Last update timestamp
+}
+
+// ListNotificationChannelsResponse represents the response for listing notification channels.
+// Channels from the notifications configuration section are not included.
+type ListNotificationChannelsResponse struct {
+	Channels []NotificationChannelResponse `json:"channels"` // Array of channels, by name
+	Total    int                           `json:"total"`    // Total number of channels
+}
+
+// AlertEventResponse represents an entry of the alert history: a system
+// alert rule that started firing or resolved.
+type AlertEventResponse struct {
+	ID        string  `json:"id"`         // Event identifier
+	Rule      string  `json:"rule"`       // Name of the alert rule
+	Kind      string  `json:"kind"`       // dlq_depth, success_rate, consumer_lag or no_scrapes
+	State     string  `json:"state"`      // firing or resolved
+	Severity  string  `json:"severity"`   // Severity the channels were notified with
+	Value     float64 `json:"value"`      // Measurement that changed the state
+	Threshold float64 `json:"threshold"`  // Threshold of the rule at the time
+	Message   string  `json:"message"`    // Human readable description
+	CreatedAt string  `json:"created_at"` // When the state changed
+}
+
+// AlertEventsResponse represents a page of the alert history.
+type AlertEventsResponse struct {
+	Events []AlertEventResponse `json:"events"` // Events, newest first
+	Total  int64                `json:"total"`  // Total number of matching events
+	Page   int                  `json:"page"`   // Current page number
+	Limit  int                  `json:"limit"`  // Number of events per page
+}
+
+// MaintenanceResponse represents the maintenance mode switch.
+type MaintenanceResponse struct {
+	Enabled bool   `json:"enabled"`           // Whether the system is in maintenance mode
+	Message string `json:"message,omitempty"` // Banner shown to API clients
+	Since   string `json:"since,omitempty"`   // When the switch last changed
+}
+
+// EffectiveConfigResponse represents the configuration currently in effect.
+// Secrets such as the database password are never included.
+type EffectiveConfigResponse struct {
+	Service      string         `json:"service"`               // Service the configuration belongs to
+	Config       *config.Config `json:"config"`                // Effective configuration, including hot-reloaded changes
+	LiveSettings []string       `json:"live_settings"`         // Settings applied without a restart
+	ReloadedAt   string         `json:"reloaded_at,omitempty"` // Last successful reload (empty if never reloaded)
+}
+
+// FeatureFlagResponse represents the effective definition of a feature flag.
+type FeatureFlagResponse struct {
+	Name           string          `json:"name"`                  // Flag name
+	Description    string          `json:"description,omitempty"` // Human readable description
+	Enabled        bool            `json:"enabled"`               // Whether the flag is on globally
+	RolloutPercent int             `json:"rollout_percent"`       // Percentage of tenants the flag is on for
+	Tenants        map[string]bool `json:"tenants,omitempty"`     // Per-tenant overrides
+	Source         string          `json:"source"`                // Where the flag is defined (config, database)
+	Active         *bool           `json:"active,omitempty"`      // Evaluation for the requested tenant (only with ?tenant=)
+}
+
+// ListFeatureFlagsResponse represents the response for listing feature flags.
+type ListFeatureFlagsResponse struct {
+	Flags []FeatureFlagResponse `json:"flags"` // Array of flags, sorted by name
+	Total int                   `json:"total"` // Total number of flags
+}
+
+// TriggerRejectedResponse represents a manual scrape trigger answered with
+// 429 Too Many Requests, either by the per-client trigger limit or by a
+// daily scrape budget covering the URL.
+type TriggerRejectedResponse struct {
+	Error      string `json:"error"`       // "Trigger rate limit exceeded" or "Scrape quota exceeded"
+	Message    string `json:"message"`     // Which limit was hit
+	RetryAfter int    `json:"retry_after"` // Seconds until a trigger may be accepted, also sent as Retry-After
+}
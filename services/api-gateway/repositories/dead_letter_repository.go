@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/kafka"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetterFilter selects dead letters. Zero fields match every dead letter.
+type DeadLetterFilter struct {
+	Topic        string    // Topic the messages were consumed from
+	MessageType  string    // Type of the messages, e.g. scraping_task
+	FailedBefore time.Time // Only messages that failed before this time
+}
+
+// DeadLetterRepository defines the interface for the dead letters stored by
+// the Kafka consumers, see kafka.Consumer.SetDeadLetterStore. Queries that
+// time out fail with a domain.ErrUnavailable.
+type DeadLetterRepository interface {
+	// ListDeadLetters retrieves a page of the dead letters of a filter, most recent failures first
+	ListDeadLetters(ctx context.Context, filter DeadLetterFilter, limit, offset int) ([]kafka.DeadLetter, error)
+
+	// CountDeadLetters counts the dead letters of a filter
+	CountDeadLetters(ctx context.Context, filter DeadLetterFilter) (int64, error)
+
+	// ExportDeadLetters calls fn with every dead letter of a filter, oldest
+	// failures first, stopping at the first error fn returns
+	ExportDeadLetters(ctx context.Context, filter DeadLetterFilter, fn func(kafka.DeadLetter) error) error
+
+	// DeleteDeadLetter deletes a dead letter, reporting whether it existed
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// PurgeDeadLetters deletes the dead letters of a topic and/or that
+	// failed before a time, "" and the zero time for any, and returns how
+	// many were deleted
+	PurgeDeadLetters(ctx context.Context, topic string, failedBefore time.Time) (int64, error)
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/kafka"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// deadLetterExportBatch is the number of dead letters an export reads per query
+const deadLetterExportBatch = 500
+
+// DeadLetterRepositoryImpl implements the DeadLetterRepository interface using sqlc-generated queries
+type DeadLetterRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewDeadLetterRepository creates a new dead letter repository instance
+// whose queries are bounded by timeouts
+func NewDeadLetterRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) DeadLetterRepository {
+	return &DeadLetterRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// ListDeadLetters retrieves a page of the dead letters of a filter, most recent failures first
+func (r *DeadLetterRepositoryImpl) ListDeadLetters(ctx context.Context, filter DeadLetterFilter, limit, offset int) ([]kafka.DeadLetter, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListDeadLetters")
+	defer cancel()
+
+	rows, err := r.db.ListDeadLetters(ctx, database.ListDeadLettersParams{
+		Topic:        filter.Topic,
+		MessageType:  filter.MessageType,
+		FailedBefore: nullTime(filter.FailedBefore),
+		Limit:        int32(limit),
+		Offset:       int32(offset),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(filterFields(filter)).Error("Failed to list dead letters")
+		return nil, queryError(err)
+	}
+	deadLetters := make([]kafka.DeadLetter, 0, len(rows))
+	for _, row := range rows {
+		deadLetters = append(deadLetters, deadLetterFromRow(row))
+	}
+	return deadLetters, nil
+}
+
+// CountDeadLetters counts the dead letters of a filter
+func (r *DeadLetterRepositoryImpl) CountDeadLetters(ctx context.Context, filter DeadLetterFilter) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountDeadLetters")
+	defer cancel()
+
+	count, err := r.db.CountDeadLetters(ctx, database.CountDeadLettersParams{
+		Topic:        filter.Topic,
+		MessageType:  filter.MessageType,
+		FailedBefore: nullTime(filter.FailedBefore),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(filterFields(filter)).Error("Failed to count dead letters")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// ExportDeadLetters calls fn with every dead letter of a filter, oldest
+// failures first. Each batch is a query of its own, bounded by its timeout.
+func (r *DeadLetterRepositoryImpl) ExportDeadLetters(ctx context.Context, filter DeadLetterFilter, fn func(kafka.DeadLetter) error) error {
+	params := database.ListDeadLettersForExportParams{
+		Topic:        filter.Topic,
+		MessageType:  filter.MessageType,
+		FailedBefore: nullTime(filter.FailedBefore),
+		BatchSize:    deadLetterExportBatch,
+	}
+	for {
+		rows, err := r.listForExport(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := fn(deadLetterFromRow(row)); err != nil {
+				return err
+			}
+		}
+		if len(rows) < deadLetterExportBatch {
+			return nil
+		}
+		last := rows[len(rows)-1]
+		params.AfterFailedAt = sql.NullTime{Time: last.FailedAt, Valid: true}
+		params.AfterID = last.ID
+	}
+}
+
+// listForExport reads one batch of an export
+func (r *DeadLetterRepositoryImpl) listForExport(ctx context.Context, params database.ListDeadLettersForExportParams) ([]database.DeadLetter, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListDeadLettersForExport")
+	defer cancel()
+
+	rows, err := r.db.ListDeadLettersForExport(ctx, params)
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"topic":        params.Topic,
+			"message_type": params.MessageType,
+		}).Error("Failed to export dead letters")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// DeleteDeadLetter deletes a dead letter, reporting whether it existed
+func (r *DeadLetterRepositoryImpl) DeleteDeadLetter(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "DeleteDeadLetter")
+	defer cancel()
+
+	deleted, err := r.db.DeleteDeadLetter(ctx, id)
+	if err != nil {
+		r.logger.WithError(err).WithField("dead_letter_id", id).Error("Failed to delete dead letter")
+		return false, queryError(err)
+	}
+	return deleted > 0, nil
+}
+
+// PurgeDeadLetters deletes the dead letters of a topic and/or that failed
+// before a time, and returns how many were deleted
+func (r *DeadLetterRepositoryImpl) PurgeDeadLetters(ctx context.Context, topic string, failedBefore time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "PurgeDeadLetters")
+	defer cancel()
+
+	deleted, err := r.db.PurgeDeadLetters(ctx, database.PurgeDeadLettersParams{
+		Topic:        topic,
+		FailedBefore: nullTime(failedBefore),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"topic":         topic,
+			"failed_before": failedBefore,
+		}).Error("Failed to purge dead letters")
+		return 0, queryError(err)
+	}
+	return deleted, nil
+}
+
+// deadLetterFromRow converts a stored dead letter to a kafka.DeadLetter
+func deadLetterFromRow(row database.DeadLetter) kafka.DeadLetter {
+	return kafka.DeadLetter{
+		ID:          row.ID.String(),
+		Topic:       row.Topic,
+		Partition:   int(row.KafkaPartition),
+		Offset:      row.KafkaOffset,
+		Key:         row.Key,
+		Value:       row.Value,
+		MessageType: row.MessageType,
+		Error:       row.Error,
+		RetryCount:  int(row.RetryCount),
+		CreatedAt:   row.CreatedAt,
+		FailedAt:    row.FailedAt,
+	}
+}
+
+// filterFields returns the log fields of a dead letter filter
+func filterFields(filter DeadLetterFilter) logrus.Fields {
+	return logrus.Fields{
+		"topic":         filter.Topic,
+		"message_type":  filter.MessageType,
+		"failed_before": filter.FailedBefore,
+	}
+}
+
+// nullTime returns t as a nullable time, null for the zero time
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
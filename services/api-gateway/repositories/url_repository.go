@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+)
+
+// URLRepository defines the interface for the URL data operations of the API
+// Gateway. Errors are domain errors where the cause is known: a missing URL
+// is domain.ErrURLNotFound, a duplicate address a domain.ErrConflict and a
+// query that timed out a domain.ErrUnavailable.
+type URLRepository interface {
+	// GetURLByID retrieves a URL by its ID, deleted or not. It returns
+	// domain.ErrURLNotFound if the URL does not exist.
+	GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error)
+
+	// ListURLs retrieves a page of the URLs that are not deleted
+	ListURLs(ctx context.Context, arg database.ListURLsParams) ([]database.Url, error)
+
+	// CountURLs counts the URLs that are not deleted and match an ILIKE pattern, "" for all
+	CountURLs(ctx context.Context, pattern string) (int64, error)
+
+	// CountURLsPerStatus counts the URLs that are not deleted by status
+	CountURLsPerStatus(ctx context.Context) ([]database.CountURLsPerStatusRow, error)
+
+	// CountURLsPerDomain counts the URLs that are not deleted by host, most used hosts first
+	CountURLsPerDomain(ctx context.Context, maxResults int32) ([]database.CountURLsPerDomainRow, error)
+
+	// CountURLsPerProject counts the URLs that are not deleted by project
+	CountURLsPerProject(ctx context.Context) ([]database.CountURLsPerProjectRow, error)
+
+	// CreateURL creates a URL. It returns a domain.ErrConflict if a URL with
+	// the same address exists.
+	CreateURL(ctx context.Context, arg database.CreateURLParams) (database.Url, error)
+
+	// DeleteURL soft-deletes a URL. It returns domain.ErrURLNotFound if the
+	// URL does not exist or is already deleted.
+	DeleteURL(ctx context.Context, id uuid.UUID) (database.SoftDeleteURLsRow, error)
+
+	// UpsertURL creates a URL or replaces the configuration of the URL with
+	// the same address, restoring it if it was deleted
+	UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error)
+
+	// ListURLsForExport retrieves the URLs that are not deleted, by address
+	ListURLsForExport(ctx context.Context) ([]database.Url, error)
+
+	// CountURLsForBulkAction counts the URLs a bulk action would change
+	CountURLsForBulkAction(ctx context.Context, arg database.CountURLsForBulkActionParams) (int64, error)
+
+	// DeleteURLs soft-deletes the URLs matching a bulk filter
+	DeleteURLs(ctx context.Context, arg database.SoftDeleteURLsParams) ([]database.SoftDeleteURLsRow, error)
+
+	// RestoreURLs restores the deleted URLs matching a bulk filter
+	RestoreURLs(ctx context.Context, arg database.RestoreURLsParams) ([]database.RestoreURLsRow, error)
+
+	// ResetFailedURLs sets the failed URLs matching a bulk filter back to pending
+	ResetFailedURLs(ctx context.Context, arg database.ResetFailedURLsParams) ([]database.ResetFailedURLsRow, error)
+
+	// GetParserTemplateByName retrieves a stored parser template. It returns
+	// a domain.ErrNotFound, which also matches sql.ErrNoRows, if no template
+	// has the name.
+	GetParserTemplateByName(ctx context.Context, name string) (database.ParserTemplate, error)
+
+	// UpdateURLParserConfig replaces the parser config of a URL
+	UpdateURLParserConfig(ctx context.Context, arg database.UpdateURLParserConfigParams) error
+
+	// RecordParserConfigVersion adds a URL's parser config to its version history
+	RecordParserConfigVersion(ctx context.Context, arg database.RecordParserConfigVersionParams) error
+
+	// ListParserConfigVersions retrieves a page of a URL's parser config versions, newest first
+	ListParserConfigVersions(ctx context.Context, arg database.ListParserConfigVersionsParams) ([]database.ParserConfigVersion, error)
+
+	// CountParserConfigVersions counts the parser config versions of a URL
+	CountParserConfigVersions(ctx context.Context, urlID uuid.UUID) (int64, error)
+
+	// GetParserConfigVersion retrieves a version of a URL's parser config. It
+	// returns a domain.ErrNotFound if the version does not exist.
+	GetParserConfigVersion(ctx context.Context, arg database.GetParserConfigVersionParams) (database.ParserConfigVersion, error)
+
+	// GetLatestParserConfigVersion retrieves the latest version of a URL's
+	// parser config. It returns a domain.ErrNotFound if the URL has none.
+	GetLatestParserConfigVersion(ctx context.Context, urlID uuid.UUID) (database.ParserConfigVersion, error)
+
+	// GetParserCandidate retrieves the candidate parser config of a URL. It
+	// returns a domain.ErrNotFound if the URL has no candidate.
+	GetParserCandidate(ctx context.Context, urlID uuid.UUID) (database.ParserCandidate, error)
+
+	// UpsertParserCandidate sets the candidate parser config of a URL
+	UpsertParserCandidate(ctx context.Context, arg database.UpsertParserCandidateParams) (database.ParserCandidate, error)
+
+	// DeleteParserCandidate deletes the candidate parser config of a URL. It
+	// returns a domain.ErrNotFound if the URL has no candidate.
+	DeleteParserCandidate(ctx context.Context, urlID uuid.UUID) error
+
+	// ListCandidateComparisons retrieves the latest candidate parses of a URL
+	// paired with the active parses of the same pages
+	ListCandidateComparisons(ctx context.Context, arg database.ListCandidateComparisonsParams) ([]database.ListCandidateComparisonsRow, error)
+
+	// ListRawHTMLSnapshotsInRange retrieves the raw HTML snapshots of a URL
+	// taken in a time range, oldest first
+	ListRawHTMLSnapshotsInRange(ctx context.Context, arg database.ListRawHTMLSnapshotsInRangeParams) ([]database.RawHtmlSnapshot, error)
+
+	// CreateParsedData stores a parsed version of a page
+	CreateParsedData(ctx context.Context, arg database.CreateParsedDataParams) (database.ParsedDatum, error)
+
+	// CreateCandidateParsedData stores a page parsed with a URL's candidate parser config
+	CreateCandidateParsedData(ctx context.Context, arg database.CreateCandidateParsedDataParams) error
+
+	// DeleteCandidateParsedData deletes the candidate parses of a URL
+	DeleteCandidateParsedData(ctx context.Context, urlID uuid.UUID) error
+
+	// ListURLMoves retrieves a page of the detected URL moves in a state, "" for all
+	ListURLMoves(ctx context.Context, arg database.ListURLMovesParams) ([]database.UrlMove, error)
+
+	// CountURLMoves counts the detected URL moves in a state, "" for all
+	CountURLMoves(ctx context.Context, state string) (int64, error)
+
+	// ListScrapingTasks retrieves a page of scraping tasks, newest first
+	ListScrapingTasks(ctx context.Context, arg database.ListScrapingTasksParams) ([]database.ScrapingTask, error)
+
+	// CountScrapingTasks counts the scraping tasks matching a filter
+	CountScrapingTasks(ctx context.Context, arg database.CountScrapingTasksParams) (int64, error)
+
+	// GetScrapingTask retrieves a scraping task. It returns a
+	// domain.ErrNotFound if the task does not exist.
+	GetScrapingTask(ctx context.Context, id uuid.UUID) (database.ScrapingTask, error)
+
+	// RequestHARCapture asks for a HAR of the next scrape of a URL
+	RequestHARCapture(ctx context.Context, arg database.RequestHARCaptureParams) (database.HarCapture, error)
+
+	// GetHARCapture retrieves the HAR capture of a URL. It returns a
+	// domain.ErrNotFound if the URL has none.
+	GetHARCapture(ctx context.Context, urlID uuid.UUID) (database.HarCapture, error)
+
+	// DeleteHARCapture deletes the HAR capture of a URL. It returns a
+	// domain.ErrNotFound if the URL has none.
+	DeleteHARCapture(ctx context.Context, urlID uuid.UUID) error
+
+	// GetLatestRenderSession retrieves the render session of a URL's latest
+	// kept rendered scrape. It returns a domain.ErrNotFound if there is none.
+	GetLatestRenderSession(ctx context.Context, urlID uuid.UUID) (database.GetLatestRenderSessionRow, error)
+}
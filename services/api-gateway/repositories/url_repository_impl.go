@@ -0,0 +1,602 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// uniqueViolation is the PostgreSQL error code of unique constraint violations
+const uniqueViolation = "23505"
+
+// Errors of the resources kept with URLs
+var (
+	errVersionNotFound    = domain.NotFound("Parser config version not found")
+	errCandidateNotFound  = domain.NotFound("URL has no parser candidate")
+	errHARCaptureNotFound = domain.NotFound("URL has no HAR capture")
+)
+
+// URLRepositoryImpl implements the URLRepository interface using sqlc-generated queries
+type URLRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewURLRepository creates a new URL repository instance whose queries are
+// bounded by timeouts, so a slow database fails requests with 503 Service
+// Unavailable instead of holding them
+func NewURLRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) URLRepository {
+	return &URLRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// GetURLByID retrieves a URL by its ID, failing with domain.ErrURLNotFound
+// if it does not exist
+func (r *URLRepositoryImpl) GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLByID")
+	defer cancel()
+
+	url, err := r.db.GetURLByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrURLNotFound.Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", id).Error("Failed to get URL by ID")
+		return nil, queryError(err)
+	}
+	return &url, nil
+}
+
+// ListURLs retrieves a page of the URLs that are not deleted
+func (r *URLRepositoryImpl) ListURLs(ctx context.Context, arg database.ListURLsParams) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListURLs")
+	defer cancel()
+
+	urls, err := r.db.ListURLs(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"pattern": arg.Pattern,
+			"sort_by": arg.SortBy,
+			"limit":   arg.Limit,
+			"offset":  arg.Offset,
+		}).Error("Failed to list URLs")
+		return nil, queryError(err)
+	}
+	return urls, nil
+}
+
+// CountURLs counts the URLs that are not deleted and match an ILIKE pattern
+func (r *URLRepositoryImpl) CountURLs(ctx context.Context, pattern string) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLs")
+	defer cancel()
+
+	count, err := r.db.CountURLs(ctx, pattern)
+	if err != nil {
+		r.logger.WithError(err).WithField("pattern", pattern).Error("Failed to count URLs")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// CountURLsPerStatus counts the URLs that are not deleted by status
+func (r *URLRepositoryImpl) CountURLsPerStatus(ctx context.Context) ([]database.CountURLsPerStatusRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLsPerStatus")
+	defer cancel()
+
+	rows, err := r.db.CountURLsPerStatus(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count URLs per status")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// CountURLsPerDomain counts the URLs that are not deleted by host
+func (r *URLRepositoryImpl) CountURLsPerDomain(ctx context.Context, maxResults int32) ([]database.CountURLsPerDomainRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLsPerDomain")
+	defer cancel()
+
+	rows, err := r.db.CountURLsPerDomain(ctx, maxResults)
+	if err != nil {
+		r.logger.WithError(err).WithField("max_results", maxResults).Error("Failed to count URLs per domain")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// CountURLsPerProject counts the URLs that are not deleted by project
+func (r *URLRepositoryImpl) CountURLsPerProject(ctx context.Context) ([]database.CountURLsPerProjectRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLsPerProject")
+	defer cancel()
+
+	rows, err := r.db.CountURLsPerProject(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count URLs per project")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// CreateURL creates a URL, failing with a domain.ErrConflict if a URL with
+// the same address exists
+func (r *URLRepositoryImpl) CreateURL(ctx context.Context, arg database.CreateURLParams) (database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateURL")
+	defer cancel()
+
+	url, err := r.db.CreateURL(ctx, arg)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return database.Url{}, domain.Conflict("URL already exists: %s", arg.Url).Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url", arg.Url).Error("Failed to create URL")
+		return database.Url{}, queryError(err)
+	}
+	return url, nil
+}
+
+// DeleteURL soft-deletes a URL, failing with domain.ErrURLNotFound if it
+// does not exist or is already deleted
+func (r *URLRepositoryImpl) DeleteURL(ctx context.Context, id uuid.UUID) (database.SoftDeleteURLsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "SoftDeleteURLs")
+	defer cancel()
+
+	rows, err := r.db.SoftDeleteURLs(ctx, database.SoftDeleteURLsParams{Ids: []uuid.UUID{id}})
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", id).Error("Failed to delete URL")
+		return database.SoftDeleteURLsRow{}, queryError(err)
+	}
+	if len(rows) == 0 {
+		return database.SoftDeleteURLsRow{}, domain.ErrURLNotFound
+	}
+	return rows[0], nil
+}
+
+// UpsertURL creates a URL or replaces the configuration of the URL with the
+// same address
+func (r *URLRepositoryImpl) UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "UpsertURL")
+	defer cancel()
+
+	row, err := r.db.UpsertURL(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url", arg.Url).Error("Failed to upsert URL")
+		return database.UpsertURLRow{}, queryError(err)
+	}
+	return row, nil
+}
+
+// ListURLsForExport retrieves the URLs that are not deleted, by address
+func (r *URLRepositoryImpl) ListURLsForExport(ctx context.Context) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListURLsForExport")
+	defer cancel()
+
+	urls, err := r.db.ListURLsForExport(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list URLs for export")
+		return nil, queryError(err)
+	}
+	return urls, nil
+}
+
+// CountURLsForBulkAction counts the URLs a bulk action would change
+func (r *URLRepositoryImpl) CountURLsForBulkAction(ctx context.Context, arg database.CountURLsForBulkActionParams) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLsForBulkAction")
+	defer cancel()
+
+	count, err := r.db.CountURLsForBulkAction(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithFields(bulkFields(arg.Ids, arg.Tag, arg.Domain)).Error("Failed to count URLs for bulk action")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// DeleteURLs soft-deletes the URLs matching a bulk filter
+func (r *URLRepositoryImpl) DeleteURLs(ctx context.Context, arg database.SoftDeleteURLsParams) ([]database.SoftDeleteURLsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "SoftDeleteURLs")
+	defer cancel()
+
+	rows, err := r.db.SoftDeleteURLs(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithFields(bulkFields(arg.Ids, arg.Tag, arg.Domain)).Error("Failed to delete URLs")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// RestoreURLs restores the deleted URLs matching a bulk filter
+func (r *URLRepositoryImpl) RestoreURLs(ctx context.Context, arg database.RestoreURLsParams) ([]database.RestoreURLsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "RestoreURLs")
+	defer cancel()
+
+	rows, err := r.db.RestoreURLs(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithFields(bulkFields(arg.Ids, arg.Tag, arg.Domain)).Error("Failed to restore URLs")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// ResetFailedURLs sets the failed URLs matching a bulk filter back to pending
+func (r *URLRepositoryImpl) ResetFailedURLs(ctx context.Context, arg database.ResetFailedURLsParams) ([]database.ResetFailedURLsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ResetFailedURLs")
+	defer cancel()
+
+	rows, err := r.db.ResetFailedURLs(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithFields(bulkFields(arg.Ids, arg.Tag, arg.Domain)).Error("Failed to reset failed URLs")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// bulkFields returns the log fields of a bulk filter
+func bulkFields(ids []uuid.UUID, tag, domain string) logrus.Fields {
+	return logrus.Fields{"ids": len(ids), "tag": tag, "domain": domain}
+}
+
+// GetParserTemplateByName retrieves a stored parser template, failing with
+// a domain.ErrNotFound that also matches sql.ErrNoRows if none has the name
+func (r *URLRepositoryImpl) GetParserTemplateByName(ctx context.Context, name string) (database.ParserTemplate, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetParserTemplateByName")
+	defer cancel()
+
+	tmpl, err := r.db.GetParserTemplateByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ParserTemplate{}, domain.NotFound("Unknown parser template: %s", name).Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("template", name).Error("Failed to get parser template")
+		return database.ParserTemplate{}, queryError(err)
+	}
+	return tmpl, nil
+}
+
+// UpdateURLParserConfig replaces the parser config of a URL
+func (r *URLRepositoryImpl) UpdateURLParserConfig(ctx context.Context, arg database.UpdateURLParserConfigParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "UpdateURLParserConfig")
+	defer cancel()
+
+	if err := r.db.UpdateURLParserConfig(ctx, arg); err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.ID).Error("Failed to update parser config")
+		return queryError(err)
+	}
+	return nil
+}
+
+// RecordParserConfigVersion adds a URL's parser config to its version history
+func (r *URLRepositoryImpl) RecordParserConfigVersion(ctx context.Context, arg database.RecordParserConfigVersionParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "RecordParserConfigVersion")
+	defer cancel()
+
+	if _, err := r.db.RecordParserConfigVersion(ctx, arg); err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to record parser config version")
+		return queryError(err)
+	}
+	return nil
+}
+
+// ListParserConfigVersions retrieves a page of a URL's parser config versions
+func (r *URLRepositoryImpl) ListParserConfigVersions(ctx context.Context, arg database.ListParserConfigVersionsParams) ([]database.ParserConfigVersion, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListParserConfigVersions")
+	defer cancel()
+
+	rows, err := r.db.ListParserConfigVersions(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to list parser config versions")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// CountParserConfigVersions counts the parser config versions of a URL
+func (r *URLRepositoryImpl) CountParserConfigVersions(ctx context.Context, urlID uuid.UUID) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountParserConfigVersions")
+	defer cancel()
+
+	count, err := r.db.CountParserConfigVersions(ctx, urlID)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to count parser config versions")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// GetParserConfigVersion retrieves a version of a URL's parser config,
+// failing with a domain.ErrNotFound if it does not exist
+func (r *URLRepositoryImpl) GetParserConfigVersion(ctx context.Context, arg database.GetParserConfigVersionParams) (database.ParserConfigVersion, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetParserConfigVersion")
+	defer cancel()
+
+	version, err := r.db.GetParserConfigVersion(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ParserConfigVersion{}, errVersionNotFound.Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{"url_id": arg.UrlID, "version": arg.Version}).Error("Failed to get parser config version")
+		return database.ParserConfigVersion{}, queryError(err)
+	}
+	return version, nil
+}
+
+// GetLatestParserConfigVersion retrieves the latest version of a URL's
+// parser config, failing with a domain.ErrNotFound if the URL has none
+func (r *URLRepositoryImpl) GetLatestParserConfigVersion(ctx context.Context, urlID uuid.UUID) (database.ParserConfigVersion, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetLatestParserConfigVersion")
+	defer cancel()
+
+	version, err := r.db.GetLatestParserConfigVersion(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ParserConfigVersion{}, errVersionNotFound.Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to get latest parser config version")
+		return database.ParserConfigVersion{}, queryError(err)
+	}
+	return version, nil
+}
+
+// GetParserCandidate retrieves the candidate parser config of a URL,
+// failing with a domain.ErrNotFound if the URL has no candidate
+func (r *URLRepositoryImpl) GetParserCandidate(ctx context.Context, urlID uuid.UUID) (database.ParserCandidate, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetParserCandidate")
+	defer cancel()
+
+	candidate, err := r.db.GetParserCandidate(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ParserCandidate{}, errCandidateNotFound.Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to get parser candidate")
+		return database.ParserCandidate{}, queryError(err)
+	}
+	return candidate, nil
+}
+
+// UpsertParserCandidate sets the candidate parser config of a URL
+func (r *URLRepositoryImpl) UpsertParserCandidate(ctx context.Context, arg database.UpsertParserCandidateParams) (database.ParserCandidate, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "UpsertParserCandidate")
+	defer cancel()
+
+	candidate, err := r.db.UpsertParserCandidate(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to store parser candidate")
+		return database.ParserCandidate{}, queryError(err)
+	}
+	return candidate, nil
+}
+
+// DeleteParserCandidate deletes the candidate parser config of a URL,
+// failing with a domain.ErrNotFound if the URL has no candidate
+func (r *URLRepositoryImpl) DeleteParserCandidate(ctx context.Context, urlID uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "DeleteParserCandidate")
+	defer cancel()
+
+	deleted, err := r.db.DeleteParserCandidate(ctx, urlID)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to delete parser candidate")
+		return queryError(err)
+	}
+	if deleted == 0 {
+		return errCandidateNotFound
+	}
+	return nil
+}
+
+// ListCandidateComparisons retrieves the latest candidate parses of a URL
+// paired with the active parses of the same pages
+func (r *URLRepositoryImpl) ListCandidateComparisons(ctx context.Context, arg database.ListCandidateComparisonsParams) ([]database.ListCandidateComparisonsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListCandidateComparisons")
+	defer cancel()
+
+	rows, err := r.db.ListCandidateComparisons(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to list candidate parses")
+		return nil, queryError(err)
+	}
+	return rows, nil
+}
+
+// ListRawHTMLSnapshotsInRange retrieves the raw HTML snapshots of a URL
+// taken in a time range
+func (r *URLRepositoryImpl) ListRawHTMLSnapshotsInRange(ctx context.Context, arg database.ListRawHTMLSnapshotsInRangeParams) ([]database.RawHtmlSnapshot, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListRawHTMLSnapshotsInRange")
+	defer cancel()
+
+	snapshots, err := r.db.ListRawHTMLSnapshotsInRange(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to list raw HTML snapshots")
+		return nil, queryError(err)
+	}
+	return snapshots, nil
+}
+
+// CreateParsedData stores a parsed version of a page
+func (r *URLRepositoryImpl) CreateParsedData(ctx context.Context, arg database.CreateParsedDataParams) (database.ParsedDatum, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateParsedData")
+	defer cancel()
+
+	record, err := r.db.CreateParsedData(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to store parsed data")
+		return database.ParsedDatum{}, queryError(err)
+	}
+	return record, nil
+}
+
+// CreateCandidateParsedData stores a page parsed with a URL's candidate parser config
+func (r *URLRepositoryImpl) CreateCandidateParsedData(ctx context.Context, arg database.CreateCandidateParsedDataParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateCandidateParsedData")
+	defer cancel()
+
+	if _, err := r.db.CreateCandidateParsedData(ctx, arg); err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to store candidate parsed data")
+		return queryError(err)
+	}
+	return nil
+}
+
+// DeleteCandidateParsedData deletes the candidate parses of a URL
+func (r *URLRepositoryImpl) DeleteCandidateParsedData(ctx context.Context, urlID uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "DeleteCandidateParsedData")
+	defer cancel()
+
+	if err := r.db.DeleteCandidateParsedData(ctx, urlID); err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to discard candidate parsed data")
+		return queryError(err)
+	}
+	return nil
+}
+
+// ListURLMoves retrieves a page of the detected URL moves in a state
+func (r *URLRepositoryImpl) ListURLMoves(ctx context.Context, arg database.ListURLMovesParams) ([]database.UrlMove, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListURLMoves")
+	defer cancel()
+
+	moves, err := r.db.ListURLMoves(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("state", arg.State).Error("Failed to list URL moves")
+		return nil, queryError(err)
+	}
+	return moves, nil
+}
+
+// CountURLMoves counts the detected URL moves in a state
+func (r *URLRepositoryImpl) CountURLMoves(ctx context.Context, state string) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLMoves")
+	defer cancel()
+
+	count, err := r.db.CountURLMoves(ctx, state)
+	if err != nil {
+		r.logger.WithError(err).WithField("state", state).Error("Failed to count URL moves")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// ListScrapingTasks retrieves a page of scraping tasks
+func (r *URLRepositoryImpl) ListScrapingTasks(ctx context.Context, arg database.ListScrapingTasksParams) ([]database.ScrapingTask, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListScrapingTasks")
+	defer cancel()
+
+	tasks, err := r.db.ListScrapingTasks(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID.UUID).Error("Failed to list scrapes")
+		return nil, queryError(err)
+	}
+	return tasks, nil
+}
+
+// CountScrapingTasks counts the scraping tasks matching a filter
+func (r *URLRepositoryImpl) CountScrapingTasks(ctx context.Context, arg database.CountScrapingTasksParams) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountScrapingTasks")
+	defer cancel()
+
+	count, err := r.db.CountScrapingTasks(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID.UUID).Error("Failed to count scrapes")
+		return 0, queryError(err)
+	}
+	return count, nil
+}
+
+// GetScrapingTask retrieves a scraping task, failing with a
+// domain.ErrNotFound if it does not exist
+func (r *URLRepositoryImpl) GetScrapingTask(ctx context.Context, id uuid.UUID) (database.ScrapingTask, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetScrapingTask")
+	defer cancel()
+
+	task, err := r.db.GetScrapingTask(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ScrapingTask{}, domain.NotFound("Scrape not found").Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("task_id", id).Error("Failed to get scraping task")
+		return database.ScrapingTask{}, queryError(err)
+	}
+	return task, nil
+}
+
+// RequestHARCapture asks for a HAR of the next scrape of a URL
+func (r *URLRepositoryImpl) RequestHARCapture(ctx context.Context, arg database.RequestHARCaptureParams) (database.HarCapture, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "RequestHARCapture")
+	defer cancel()
+
+	capture, err := r.db.RequestHARCapture(ctx, arg)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", arg.UrlID).Error("Failed to request HAR capture")
+		return database.HarCapture{}, queryError(err)
+	}
+	return capture, nil
+}
+
+// GetHARCapture retrieves the HAR capture of a URL, failing with a
+// domain.ErrNotFound if the URL has none
+func (r *URLRepositoryImpl) GetHARCapture(ctx context.Context, urlID uuid.UUID) (database.HarCapture, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetHARCapture")
+	defer cancel()
+
+	capture, err := r.db.GetHARCapture(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.HarCapture{}, errHARCaptureNotFound.Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to get HAR capture")
+		return database.HarCapture{}, queryError(err)
+	}
+	return capture, nil
+}
+
+// DeleteHARCapture deletes the HAR capture of a URL, failing with a
+// domain.ErrNotFound if the URL has none
+func (r *URLRepositoryImpl) DeleteHARCapture(ctx context.Context, urlID uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "DeleteHARCapture")
+	defer cancel()
+
+	deleted, err := r.db.DeleteHARCapture(ctx, urlID)
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to delete HAR capture")
+		return queryError(err)
+	}
+	if deleted == 0 {
+		return errHARCaptureNotFound
+	}
+	return nil
+}
+
+// GetLatestRenderSession retrieves the render session of a URL's latest
+// kept rendered scrape, failing with a domain.ErrNotFound if there is none
+func (r *URLRepositoryImpl) GetLatestRenderSession(ctx context.Context, urlID uuid.UUID) (database.GetLatestRenderSessionRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetLatestRenderSession")
+	defer cancel()
+
+	session, err := r.db.GetLatestRenderSession(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.GetLatestRenderSessionRow{}, domain.NotFound("URL has no render session").Wrap(err)
+	}
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", urlID).Error("Failed to get render session")
+		return database.GetLatestRenderSessionRow{}, queryError(err)
+	}
+	return session, nil
+}
+
+// queryError maps a query that ran out of time to a domain.ErrUnavailable
+func queryError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.Unavailable(err, "Database did not answer in time")
+	}
+	return err
+}
@@ -0,0 +1,745 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/services/api-gateway/repositories"
+	"go_scraping_project/shared/cache"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+	"go_scraping_project/shared/events"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// Pagination and search limits of URL listings
+const (
+	DefaultURLPageSize   = 20
+	MaxURLPageSize       = 100
+	MaxSearchQueryLength = 200
+	DefaultTopDomains    = 10
+	MaxTopDomains        = 100
+)
+
+// urlsCacheGroup is the cache group of URL listings and statistics, dropped
+// whenever a URL changes
+const urlsCacheGroup = "urls"
+
+// likeEscaper escapes the LIKE wildcards so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// URLQuery selects a page of URLs
+type URLQuery struct {
+	Search     string // Free-text search over the URL address and tags, "" for all
+	SortBy     string // Column to sort by, see database.ListURLsParams
+	Descending bool
+	Page       int // 1-based, pages before the first are the first
+	Limit      int // Page size, DefaultURLPageSize when out of range
+}
+
+// URLPage is a page of URLs with the total number of matching URLs
+type URLPage struct {
+	URLs  []database.Url
+	Total int64
+	Page  int
+	Limit int
+}
+
+// URLStats summarizes the URLs that are not deleted
+type URLStats struct {
+	Total      int64
+	ByStatus   []database.CountURLsPerStatusRow
+	TopDomains []database.CountURLsPerDomainRow
+	Projects   []database.CountURLsPerProjectRow
+}
+
+// URLService holds the business logic of the URL endpoints of the API
+// Gateway: pagination and search, lifecycle rules and the URL events of
+// changes. Errors are domain errors handlers map to HTTP status codes.
+//
+// Reads are cached when a cache is set, see SetCache. Changes made through
+// the service drop the entries they affect; changes made elsewhere, such as
+// status changes by the URL Manager, show once the entries expire.
+type URLService struct {
+	urlRepo  repositories.URLRepository
+	events   *events.URLEventPublisher
+	logger   *logrus.Logger
+	cache    *cache.Cache
+	cacheCfg config.CacheConfig
+}
+
+// NewURLService creates a new URL service publishing the changes it makes
+// with publisher, which may be nil
+func NewURLService(urlRepo repositories.URLRepository, publisher *events.URLEventPublisher, logger *logrus.Logger) *URLService {
+	return &URLService{
+		urlRepo: urlRepo,
+		events:  publisher,
+		logger:  logger,
+	}
+}
+
+// SetCache caches reads in c, which may be nil, for the TTLs of cfg: single
+// URLs for url_ttl, listings and statistics for list_ttl
+func (s *URLService) SetCache(c *cache.Cache, cfg config.CacheConfig) {
+	s.cache = c
+	s.cacheCfg = cfg
+}
+
+// Invalidate drops the cached URLs with the given IDs, and every cached
+// listing and statistic
+func (s *URLService) Invalidate(ctx context.Context, ids ...uuid.UUID) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = urlCacheKey(id)
+	}
+	s.cache.Invalidate(ctx, keys...)
+	s.cache.Bump(ctx, urlsCacheGroup)
+}
+
+func urlCacheKey(id uuid.UUID) string {
+	return "url:" + id.String()
+}
+
+// GetURL returns a URL, deleted or not, failing with domain.ErrURLNotFound
+// if it does not exist
+func (s *URLService) GetURL(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	return cache.Fetch(ctx, s.cache, urlCacheKey(id), s.cacheCfg.URLTTL, func(ctx context.Context) (*database.Url, error) {
+		return s.urlRepo.GetURLByID(ctx, id)
+	})
+}
+
+// GetActiveURL returns a URL that is not deleted, failing with
+// domain.ErrURLNotFound if it does not exist or is deleted
+func (s *URLService) GetActiveURL(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	url, err := s.GetURL(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if url.DeletedAt.Valid {
+		return nil, domain.ErrURLNotFound
+	}
+	return url, nil
+}
+
+// ListURLs returns a page of the URLs that are not deleted. Out of range
+// pages and limits are replaced by the first page and the default limit; a
+// search longer than MaxSearchQueryLength is a domain.ErrValidation.
+func (s *URLService) ListURLs(ctx context.Context, query URLQuery) (URLPage, error) {
+	if len(query.Search) > MaxSearchQueryLength {
+		return URLPage{}, domain.Validation("q", "q must be at most %d characters", MaxSearchQueryLength)
+	}
+	page := URLPage{Page: query.Page, Limit: query.Limit}
+	if page.Page <= 0 {
+		page.Page = 1
+	}
+	if page.Limit <= 0 || page.Limit > MaxURLPageSize {
+		page.Limit = DefaultURLPageSize
+	}
+	arg := database.ListURLsParams{
+		Pattern:    SearchPattern(query.Search),
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		Limit:      int32(page.Limit),
+		Offset:     int32((page.Page - 1) * page.Limit),
+	}
+
+	key := fmt.Sprintf("%q|%s|%t|%d|%d", arg.Pattern, arg.SortBy, arg.Descending, arg.Limit, arg.Offset)
+	sum := sha256.Sum256([]byte(key))
+	return cache.FetchInGroup(ctx, s.cache, urlsCacheGroup, "list:"+hex.EncodeToString(sum[:16]), s.cacheCfg.ListTTL, func(ctx context.Context) (URLPage, error) {
+		total, err := s.urlRepo.CountURLs(ctx, arg.Pattern)
+		if err != nil {
+			return URLPage{}, err
+		}
+		urls, err := s.urlRepo.ListURLs(ctx, arg)
+		if err != nil {
+			return URLPage{}, err
+		}
+		page.URLs = urls
+		page.Total = total
+		return page, nil
+	})
+}
+
+// SearchPattern turns a free-text query into an ILIKE pattern matching it
+// anywhere. An empty query gives an empty pattern, which matches everything.
+func SearchPattern(query string) string {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return ""
+	}
+	return "%" + likeEscaper.Replace(query) + "%"
+}
+
+// Stats counts the URLs by status and project, and the domains most URLs are
+// on. domains, the number of top domains, must be between 1 and
+// MaxTopDomains.
+func (s *URLService) Stats(ctx context.Context, domains int) (URLStats, error) {
+	if domains < 1 || domains > MaxTopDomains {
+		return URLStats{}, domain.Validation("domains", "domains must be between 1 and %d", MaxTopDomains)
+	}
+
+	return cache.FetchInGroup(ctx, s.cache, urlsCacheGroup, fmt.Sprintf("stats:%d", domains), s.cacheCfg.ListTTL, func(ctx context.Context) (URLStats, error) {
+		byStatus, err := s.urlRepo.CountURLsPerStatus(ctx)
+		if err != nil {
+			return URLStats{}, err
+		}
+		topDomains, err := s.urlRepo.CountURLsPerDomain(ctx, int32(domains))
+		if err != nil {
+			return URLStats{}, err
+		}
+		projects, err := s.urlRepo.CountURLsPerProject(ctx)
+		if err != nil {
+			return URLStats{}, err
+		}
+
+		stats := URLStats{ByStatus: byStatus, TopDomains: topDomains, Projects: projects}
+		for _, row := range byStatus {
+			stats.Total += row.Count
+		}
+		return stats, nil
+	})
+}
+
+// CreateURL creates a URL and publishes its url.created event. It fails
+// with a domain.ErrConflict if a URL with the same address exists.
+func (s *URLService) CreateURL(ctx context.Context, params database.CreateURLParams) (database.Url, error) {
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
+	url, err := s.urlRepo.CreateURL(ctx, params)
+	if err != nil {
+		return database.Url{}, err
+	}
+	s.cache.Bump(ctx, urlsCacheGroup)
+	s.events.Publish(ctx, sharedmodels.NewURLEvent(sharedmodels.URLEventCreated, url.ID, url.Url))
+	return url, nil
+}
+
+// DeleteURL soft-deletes a URL, so it is no longer listed or scheduled, and
+// publishes its url.deleted event. It fails with domain.ErrURLNotFound if
+// the URL does not exist or is already deleted.
+func (s *URLService) DeleteURL(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.urlRepo.DeleteURL(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.Invalidate(ctx, id)
+	s.logger.WithFields(logrus.Fields{
+		"url_id": deleted.ID,
+		"url":    deleted.Url,
+	}).Info("URL deleted")
+	s.events.Publish(ctx, sharedmodels.NewURLEvent(sharedmodels.URLEventDeleted, deleted.ID, deleted.Url))
+	return nil
+}
+
+// MaxAuthorLength bounds the author recorded with a parser config version
+const MaxAuthorLength = 200
+
+// Change says who changed URLs and why, for the parser config history
+type Change struct {
+	Author string // Person or system making the change, "" if unknown
+	Reason string // Why the config changed, e.g. "patched"
+}
+
+// changed drops the cached copies of the URLs of events and publishes the events
+func (s *URLService) changed(ctx context.Context, urlEvents ...sharedmodels.URLEvent) {
+	if len(urlEvents) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(urlEvents))
+	for i, event := range urlEvents {
+		ids[i] = event.URLID
+	}
+	s.Invalidate(ctx, ids...)
+	s.events.Publish(ctx, urlEvents...)
+}
+
+// UpsertURLs creates the URLs of params whose addresses are new and
+// replaces the configuration of the others, restoring them if they were
+// deleted; status and schedule are kept. The parser config of every URL is
+// recorded in its history and a url.created or url.updated event is
+// published. If a URL fails, the URLs saved before it stay saved and are
+// returned with the error.
+func (s *URLService) UpsertURLs(ctx context.Context, params []database.CreateURLParams, change Change) ([]database.UpsertURLRow, error) {
+	rows := make([]database.UpsertURLRow, 0, len(params))
+	urlEvents := make([]sharedmodels.URLEvent, 0, len(params))
+	defer func() { s.changed(ctx, urlEvents...) }()
+
+	for _, arg := range params {
+		row, err := s.urlRepo.UpsertURL(ctx, upsertURLParams(arg))
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+		eventType := sharedmodels.URLEventUpdated
+		if row.Inserted {
+			eventType = sharedmodels.URLEventCreated
+		}
+		urlEvents = append(urlEvents, sharedmodels.NewURLEvent(eventType, row.ID, arg.Url))
+		s.RecordParserConfigVersion(ctx, row.ID, arg.ParserConfig, change)
+	}
+	return rows, nil
+}
+
+// upsertURLParams converts the parameters of a new URL into the parameters
+// creating it or replacing the configuration of the URL with its address
+func upsertURLParams(params database.CreateURLParams) database.UpsertURLParams {
+	return database.UpsertURLParams{
+		Url:           params.Url,
+		Frequency:     params.Frequency,
+		Status:        params.Status,
+		MaxRetries:    params.MaxRetries,
+		Timeout:       params.Timeout,
+		RateLimit:     params.RateLimit,
+		UserAgent:     params.UserAgent,
+		ParserConfig:  params.ParserConfig,
+		NextScrapeAt:  params.NextScrapeAt,
+		RetryPolicy:   params.RetryPolicy,
+		Tags:          params.Tags,
+		Project:       params.Project,
+		Assertions:    params.Assertions,
+		Region:        params.Region,
+		ArchivePolicy: params.ArchivePolicy,
+	}
+}
+
+// URLConfigCheck validates a URL configuration by the rules of new URLs and
+// converts it into the parameters storing it
+type URLConfigCheck func(ctx context.Context, config *models.CreateURLRequest) (database.CreateURLParams, error)
+
+// Patch changes part of the configuration of a URL that is not deleted with
+// a JSON Merge Patch (RFC 7386): members of the patch replace the URL's
+// values, objects such as parser_config merge recursively and null removes
+// a member. The patched configuration is validated by check and saved like
+// an import, keeping status and schedule, and returned. Patch fails with
+// domain.ErrURLNotFound, a domain.ErrValidation if the patched
+// configuration does not decode or changes the address, or the error of
+// check.
+func (s *URLService) Patch(ctx context.Context, id uuid.UUID, patch map[string]interface{}, check URLConfigCheck, change Change) (models.CreateURLRequest, error) {
+	url, err := s.GetActiveURL(ctx, id)
+	if err != nil {
+		return models.CreateURLRequest{}, err
+	}
+
+	// Apply the patch to the URL's configuration as JSON, so nested objects
+	// merge and null clears a field
+	current, err := json.Marshal(s.URLConfig(*url))
+	if err != nil {
+		return models.CreateURLRequest{}, fmt.Errorf("encode URL configuration: %w", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(current, &document); err != nil {
+		return models.CreateURLRequest{}, fmt.Errorf("decode URL configuration: %w", err)
+	}
+	patched, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return models.CreateURLRequest{}, domain.Validation("", "Invalid request body")
+	}
+
+	var config models.CreateURLRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return models.CreateURLRequest{}, domain.Validation("", "Invalid patch: %v", err)
+	}
+	if config.URL != url.Url {
+		return models.CreateURLRequest{}, domain.Validation("url", "url cannot be changed; clone the URL to scrape another address")
+	}
+	params, err := check(ctx, &config)
+	if err != nil {
+		return models.CreateURLRequest{}, err
+	}
+
+	if _, err := s.UpsertURLs(ctx, []database.CreateURLParams{params}, change); err != nil {
+		return models.CreateURLRequest{}, err
+	}
+	return config, nil
+}
+
+// mergePatch applies a JSON Merge Patch (RFC 7386) to a decoded JSON
+// document: members of an object patch replace or, when null, remove the
+// target's members, recursing into objects; any other patch replaces the
+// target
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// URLConfig converts a stored URL into its configuration, as exported and
+// patched. Stored settings that do not decode are left out.
+func (s *URLService) URLConfig(url database.Url) models.CreateURLRequest {
+	config := models.CreateURLRequest{
+		URL:        url.Url,
+		Frequency:  url.Frequency,
+		UserAgent:  url.UserAgent.String,
+		Timeout:    int(url.Timeout),
+		RateLimit:  int(url.RateLimit),
+		MaxRetries: int(url.MaxRetries),
+		Tags:       url.Tags,
+		Project:    url.Project,
+		Region:     url.Region,
+	}
+
+	if url.ParserConfig.Valid {
+		var parserConfig sharedmodels.ParserConfig
+		if err := json.Unmarshal(url.ParserConfig.RawMessage, &parserConfig); err != nil {
+			s.logger.WithError(err).WithField("url_id", url.ID.String()).Warn("Failed to parse parser config, exporting without it")
+		} else {
+			config.ParserConfig = &parserConfig
+		}
+	}
+
+	if url.RetryPolicy.Valid {
+		var policy sharedmodels.RetryPolicy
+		if err := json.Unmarshal(url.RetryPolicy.RawMessage, &policy); err != nil {
+			s.logger.WithError(err).WithField("url_id", url.ID.String()).Warn("Failed to parse retry policy, exporting without it")
+		} else {
+			config.RetryPolicy = &policy
+		}
+	}
+
+	if url.Assertions.Valid {
+		var assertions sharedmodels.Assertions
+		if err := json.Unmarshal(url.Assertions.RawMessage, &assertions); err != nil {
+			s.logger.WithError(err).WithField("url_id", url.ID.String()).Warn("Failed to parse assertions, exporting without them")
+		} else {
+			config.Assertions = &assertions
+		}
+	}
+
+	if url.ArchivePolicy.Valid {
+		var policy sharedmodels.ArchivePolicy
+		if err := json.Unmarshal(url.ArchivePolicy.RawMessage, &policy); err != nil {
+			s.logger.WithError(err).WithField("url_id", url.ID.String()).Warn("Failed to parse archive policy, exporting without it")
+		} else {
+			config.ArchivePolicy = &policy
+		}
+	}
+
+	return config
+}
+
+// ListURLsForExport returns every URL that is not deleted, by address
+func (s *URLService) ListURLsForExport(ctx context.Context) ([]database.Url, error) {
+	return s.urlRepo.ListURLsForExport(ctx)
+}
+
+// Bulk actions, see BulkFilter
+const (
+	BulkDelete  = "delete"  // Soft-deletes the URLs
+	BulkRestore = "restore" // Restores deleted URLs
+	BulkReset   = "reset"   // Sets failed URLs back to pending
+)
+
+// BulkFilter selects the URLs of a bulk action. Empty fields match every
+// URL; IDs must not be nil, since the queries take an empty list as no
+// filter.
+type BulkFilter struct {
+	IDs    []uuid.UUID
+	Tag    string
+	Domain string
+}
+
+// CountBulkAction counts the URLs a bulk action would change: deleted URLs
+// for BulkRestore, failed URLs for BulkReset and URLs that are not deleted
+// for BulkDelete
+func (s *URLService) CountBulkAction(ctx context.Context, action string, filter BulkFilter) (int64, error) {
+	arg := database.CountURLsForBulkActionParams{
+		Deleted: action == BulkRestore,
+		Ids:     filter.IDs,
+		Tag:     filter.Tag,
+		Domain:  filter.Domain,
+	}
+	if action == BulkReset {
+		arg.Status = sharedmodels.URLStatusFailed
+	}
+	return s.urlRepo.CountURLsForBulkAction(ctx, arg)
+}
+
+// ApplyBulkAction applies a bulk action to the URLs matching filter,
+// publishes an event for every URL it changed and returns their number
+func (s *URLService) ApplyBulkAction(ctx context.Context, action string, filter BulkFilter) (int64, error) {
+	var urlEvents []sharedmodels.URLEvent
+	switch action {
+	case BulkRestore:
+		restored, err := s.urlRepo.RestoreURLs(ctx, database.RestoreURLsParams{Ids: filter.IDs, Tag: filter.Tag, Domain: filter.Domain})
+		if err != nil {
+			return 0, err
+		}
+		for _, url := range restored {
+			urlEvents = append(urlEvents, sharedmodels.NewURLEvent(sharedmodels.URLEventRestored, url.ID, url.Url))
+		}
+	case BulkReset:
+		reset, err := s.urlRepo.ResetFailedURLs(ctx, database.ResetFailedURLsParams{Ids: filter.IDs, Tag: filter.Tag, Domain: filter.Domain})
+		if err != nil {
+			return 0, err
+		}
+		for _, url := range reset {
+			event := sharedmodels.NewURLEvent(sharedmodels.URLEventStatusChanged, url.ID, url.Url)
+			event.FromStatus, event.Status = sharedmodels.URLStatusFailed, sharedmodels.URLStatusPending
+			urlEvents = append(urlEvents, event)
+		}
+	default:
+		deleted, err := s.urlRepo.DeleteURLs(ctx, database.SoftDeleteURLsParams{Ids: filter.IDs, Tag: filter.Tag, Domain: filter.Domain})
+		if err != nil {
+			return 0, err
+		}
+		for _, url := range deleted {
+			urlEvents = append(urlEvents, sharedmodels.NewURLEvent(sharedmodels.URLEventDeleted, url.ID, url.Url))
+		}
+	}
+	s.changed(ctx, urlEvents...)
+	return int64(len(urlEvents)), nil
+}
+
+// GetParserTemplateByName returns a stored parser template, failing with a
+// domain.ErrNotFound that also matches sql.ErrNoRows if none has the name
+func (s *URLService) GetParserTemplateByName(ctx context.Context, name string) (database.ParserTemplate, error) {
+	return s.urlRepo.GetParserTemplateByName(ctx, name)
+}
+
+// RecordParserConfigVersion records a URL's parser config in its version
+// history after a change. A failure is logged by the repository but not
+// returned, since the change itself was already made.
+func (s *URLService) RecordParserConfigVersion(ctx context.Context, urlID uuid.UUID, config pqtype.NullRawMessage, change Change) {
+	author := strings.TrimSpace(change.Author)
+	if len(author) > MaxAuthorLength {
+		author = author[:MaxAuthorLength]
+	}
+	_ = s.urlRepo.RecordParserConfigVersion(ctx, database.RecordParserConfigVersionParams{
+		UrlID:  urlID,
+		Config: config,
+		Author: author,
+		Reason: change.Reason,
+	})
+}
+
+// updateParserConfig replaces the parser config of a URL, records it in its
+// history and publishes the url.updated event
+func (s *URLService) updateParserConfig(ctx context.Context, url *database.Url, config pqtype.NullRawMessage, change Change) error {
+	err := s.urlRepo.UpdateURLParserConfig(ctx, database.UpdateURLParserConfigParams{
+		ID:           url.ID,
+		ParserConfig: config,
+	})
+	if err != nil {
+		return err
+	}
+	s.changed(ctx, sharedmodels.NewURLEvent(sharedmodels.URLEventUpdated, url.ID, url.Url))
+	s.RecordParserConfigVersion(ctx, url.ID, config, change)
+	return nil
+}
+
+// ListParserConfigVersions returns a page of a URL's parser config
+// versions, newest first, with the number of versions it has
+func (s *URLService) ListParserConfigVersions(ctx context.Context, arg database.ListParserConfigVersionsParams) ([]database.ParserConfigVersion, int64, error) {
+	versions, err := s.urlRepo.ListParserConfigVersions(ctx, arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.urlRepo.CountParserConfigVersions(ctx, arg.UrlID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return versions, total, nil
+}
+
+// GetParserConfigVersion returns a version of a URL's parser config,
+// failing with a domain.ErrNotFound if it does not exist
+func (s *URLService) GetParserConfigVersion(ctx context.Context, urlID uuid.UUID, version int32) (database.ParserConfigVersion, error) {
+	return s.urlRepo.GetParserConfigVersion(ctx, database.GetParserConfigVersionParams{UrlID: urlID, Version: version})
+}
+
+// GetLatestParserConfigVersion returns the latest version of a URL's parser
+// config, failing with a domain.ErrNotFound if the URL has none
+func (s *URLService) GetLatestParserConfigVersion(ctx context.Context, urlID uuid.UUID) (database.ParserConfigVersion, error) {
+	return s.urlRepo.GetLatestParserConfigVersion(ctx, urlID)
+}
+
+// RollbackParserConfig restores a version of a URL's parser config, which
+// becomes its latest version, and returns the new version. It fails with a
+// domain.ErrNotFound if the URL or the version does not exist.
+func (s *URLService) RollbackParserConfig(ctx context.Context, urlID uuid.UUID, version int32, change Change) (database.ParserConfigVersion, error) {
+	target, err := s.GetParserConfigVersion(ctx, urlID, version)
+	if err != nil {
+		return database.ParserConfigVersion{}, err
+	}
+	url, err := s.GetURL(ctx, urlID)
+	if err != nil {
+		return database.ParserConfigVersion{}, err
+	}
+	if err := s.updateParserConfig(ctx, url, target.Config, change); err != nil {
+		return database.ParserConfigVersion{}, err
+	}
+	return s.urlRepo.GetLatestParserConfigVersion(ctx, urlID)
+}
+
+// GetParserCandidate returns the candidate parser config of a URL, failing
+// with a domain.ErrNotFound if the URL has no candidate
+func (s *URLService) GetParserCandidate(ctx context.Context, urlID uuid.UUID) (database.ParserCandidate, error) {
+	return s.urlRepo.GetParserCandidate(ctx, urlID)
+}
+
+// SetParserCandidate sets the candidate parser config of a URL, discarding
+// the results of the previous candidate. It fails with
+// domain.ErrURLNotFound if the URL does not exist.
+func (s *URLService) SetParserCandidate(ctx context.Context, urlID uuid.UUID, config json.RawMessage) (database.ParserCandidate, error) {
+	if _, err := s.GetURL(ctx, urlID); err != nil {
+		return database.ParserCandidate{}, err
+	}
+	candidate, err := s.urlRepo.UpsertParserCandidate(ctx, database.UpsertParserCandidateParams{
+		UrlID:  urlID,
+		Config: config,
+	})
+	if err != nil {
+		return database.ParserCandidate{}, err
+	}
+	if err := s.urlRepo.DeleteCandidateParsedData(ctx, urlID); err != nil {
+		return database.ParserCandidate{}, err
+	}
+	return candidate, nil
+}
+
+// DeleteParserCandidate discards the candidate parser config of a URL and
+// its results, failing with a domain.ErrNotFound if the URL has none
+func (s *URLService) DeleteParserCandidate(ctx context.Context, urlID uuid.UUID) error {
+	return s.urlRepo.DeleteParserCandidate(ctx, urlID)
+}
+
+// ListCandidateComparisons returns the latest candidate parses of a URL
+// paired with the active parses of the same pages
+func (s *URLService) ListCandidateComparisons(ctx context.Context, arg database.ListCandidateComparisonsParams) ([]database.ListCandidateComparisonsRow, error) {
+	return s.urlRepo.ListCandidateComparisons(ctx, arg)
+}
+
+// PromoteParserCandidate makes the candidate parser config of a URL its
+// active config and returns the promoted candidate. It fails with a
+// domain.ErrNotFound if the URL has no candidate.
+func (s *URLService) PromoteParserCandidate(ctx context.Context, urlID uuid.UUID, change Change) (database.ParserCandidate, error) {
+	candidate, err := s.urlRepo.GetParserCandidate(ctx, urlID)
+	if err != nil {
+		return database.ParserCandidate{}, err
+	}
+	url, err := s.GetURL(ctx, urlID)
+	if err != nil {
+		return database.ParserCandidate{}, err
+	}
+	if err := s.updateParserConfig(ctx, url, pqtype.NullRawMessage{RawMessage: candidate.Config, Valid: true}, change); err != nil {
+		return database.ParserCandidate{}, err
+	}
+	if err := s.urlRepo.DeleteParserCandidate(ctx, urlID); err != nil {
+		return database.ParserCandidate{}, err
+	}
+	return candidate, nil
+}
+
+// ListRawHTMLSnapshots returns the raw HTML snapshots of a URL taken in a
+// time range, oldest first
+func (s *URLService) ListRawHTMLSnapshots(ctx context.Context, arg database.ListRawHTMLSnapshotsInRangeParams) ([]database.RawHtmlSnapshot, error) {
+	return s.urlRepo.ListRawHTMLSnapshotsInRange(ctx, arg)
+}
+
+// CreateParsedData stores a parsed version of a page of a URL
+func (s *URLService) CreateParsedData(ctx context.Context, arg database.CreateParsedDataParams) (database.ParsedDatum, error) {
+	return s.urlRepo.CreateParsedData(ctx, arg)
+}
+
+// CreateCandidateParsedData stores a page parsed with the candidate parser
+// config of a URL
+func (s *URLService) CreateCandidateParsedData(ctx context.Context, arg database.CreateCandidateParsedDataParams) error {
+	return s.urlRepo.CreateCandidateParsedData(ctx, arg)
+}
+
+// ListURLMoves returns a page of the detected URL moves, with the number of
+// moves matching the state
+func (s *URLService) ListURLMoves(ctx context.Context, arg database.ListURLMovesParams) ([]database.UrlMove, int64, error) {
+	moves, err := s.urlRepo.ListURLMoves(ctx, arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.urlRepo.CountURLMoves(ctx, arg.State)
+	if err != nil {
+		return nil, 0, err
+	}
+	return moves, total, nil
+}
+
+// ListScrapes returns a page of scraping tasks, newest first, with the
+// number of tasks matching the filter
+func (s *URLService) ListScrapes(ctx context.Context, arg database.ListScrapingTasksParams) ([]database.ScrapingTask, int64, error) {
+	tasks, err := s.urlRepo.ListScrapingTasks(ctx, arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.urlRepo.CountScrapingTasks(ctx, database.CountScrapingTasksParams{
+		UrlID:       arg.UrlID,
+		Headers:     arg.Headers,
+		HeaderNames: arg.HeaderNames,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+// GetScrape returns a scraping task, failing with a domain.ErrNotFound if
+// it does not exist
+func (s *URLService) GetScrape(ctx context.Context, taskID uuid.UUID) (database.ScrapingTask, error) {
+	return s.urlRepo.GetScrapingTask(ctx, taskID)
+}
+
+// RequestHARCapture asks for a HAR of the next scrape of a URL, expiring
+// after ttl. It fails with domain.ErrURLNotFound if the URL does not exist
+// or is deleted.
+func (s *URLService) RequestHARCapture(ctx context.Context, urlID uuid.UUID, ttl time.Duration) (database.HarCapture, error) {
+	if _, err := s.GetActiveURL(ctx, urlID); err != nil {
+		return database.HarCapture{}, err
+	}
+	return s.urlRepo.RequestHARCapture(ctx, database.RequestHARCaptureParams{
+		UrlID:     urlID,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	})
+}
+
+// GetHARCapture returns the HAR capture of a URL, failing with a
+// domain.ErrNotFound if the URL has none
+func (s *URLService) GetHARCapture(ctx context.Context, urlID uuid.UUID) (database.HarCapture, error) {
+	return s.urlRepo.GetHARCapture(ctx, urlID)
+}
+
+// DeleteHARCapture cancels or deletes the HAR capture of a URL, failing
+// with a domain.ErrNotFound if the URL has none
+func (s *URLService) DeleteHARCapture(ctx context.Context, urlID uuid.UUID) error {
+	return s.urlRepo.DeleteHARCapture(ctx, urlID)
+}
+
+// GetRenderSession returns the render session of a URL's latest kept
+// rendered scrape, failing with a domain.ErrNotFound if there is none
+func (s *URLService) GetRenderSession(ctx context.Context, urlID uuid.UUID) (database.GetLatestRenderSessionRow, error) {
+	return s.urlRepo.GetLatestRenderSession(ctx, urlID)
+}
@@ -0,0 +1,551 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/services/api-gateway/repositories"
+	"go_scraping_project/shared/cache"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// fakeURLRepository keeps URLs in memory. Methods it does not implement
+// panic through the nil embedded repository.
+type fakeURLRepository struct {
+	repositories.URLRepository
+	urls     map[uuid.UUID]database.Url
+	gets     int
+	listArgs []database.ListURLsParams
+	versions []database.RecordParserConfigVersionParams
+	err      error // Returned by every method when set
+}
+
+func newFakeURLRepository(urls ...database.Url) *fakeURLRepository {
+	repo := &fakeURLRepository{urls: make(map[uuid.UUID]database.Url)}
+	for _, url := range urls {
+		repo.urls[url.ID] = url
+	}
+	return repo
+}
+
+func (f *fakeURLRepository) GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	f.gets++
+	if f.err != nil {
+		return nil, f.err
+	}
+	url, ok := f.urls[id]
+	if !ok {
+		return nil, domain.ErrURLNotFound.Wrap(sql.ErrNoRows)
+	}
+	return &url, nil
+}
+
+func (f *fakeURLRepository) ListURLs(ctx context.Context, arg database.ListURLsParams) ([]database.Url, error) {
+	f.listArgs = append(f.listArgs, arg)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var urls []database.Url
+	for _, url := range f.urls {
+		if !url.DeletedAt.Valid {
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
+
+func (f *fakeURLRepository) CountURLs(ctx context.Context, pattern string) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	return int64(len(f.urls)), nil
+}
+
+func (f *fakeURLRepository) CountURLsPerStatus(ctx context.Context) ([]database.CountURLsPerStatusRow, error) {
+	return []database.CountURLsPerStatusRow{{Status: "pending", Count: 3}, {Status: "failed", Count: 2}}, f.err
+}
+
+func (f *fakeURLRepository) CountURLsPerDomain(ctx context.Context, maxResults int32) ([]database.CountURLsPerDomainRow, error) {
+	return []database.CountURLsPerDomainRow{{Domain: "example.com", Count: 5}}, f.err
+}
+
+func (f *fakeURLRepository) CountURLsPerProject(ctx context.Context) ([]database.CountURLsPerProjectRow, error) {
+	return []database.CountURLsPerProjectRow{{Project: "news", Count: 5}}, f.err
+}
+
+func (f *fakeURLRepository) CreateURL(ctx context.Context, arg database.CreateURLParams) (database.Url, error) {
+	if f.err != nil {
+		return database.Url{}, f.err
+	}
+	for _, url := range f.urls {
+		if url.Url == arg.Url {
+			return database.Url{}, domain.Conflict("URL already exists: %s", arg.Url)
+		}
+	}
+	url := database.Url{ID: uuid.New(), Url: arg.Url, Status: arg.Status, Tags: arg.Tags, CreatedAt: time.Now()}
+	f.urls[url.ID] = url
+	return url, nil
+}
+
+func (f *fakeURLRepository) DeleteURL(ctx context.Context, id uuid.UUID) (database.SoftDeleteURLsRow, error) {
+	if f.err != nil {
+		return database.SoftDeleteURLsRow{}, f.err
+	}
+	url, ok := f.urls[id]
+	if !ok || url.DeletedAt.Valid {
+		return database.SoftDeleteURLsRow{}, domain.ErrURLNotFound
+	}
+	url.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	f.urls[id] = url
+	return database.SoftDeleteURLsRow{ID: id, Url: url.Url}, nil
+}
+
+func (f *fakeURLRepository) UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error) {
+	if f.err != nil {
+		return database.UpsertURLRow{}, f.err
+	}
+	for id, url := range f.urls {
+		if url.Url == arg.Url {
+			url.Frequency, url.DeletedAt = arg.Frequency, sql.NullTime{}
+			f.urls[id] = url
+			return database.UpsertURLRow{ID: id}, nil
+		}
+	}
+	url := database.Url{ID: uuid.New(), Url: arg.Url, Frequency: arg.Frequency, Status: arg.Status}
+	f.urls[url.ID] = url
+	return database.UpsertURLRow{ID: url.ID, Inserted: true}, nil
+}
+
+func (f *fakeURLRepository) RecordParserConfigVersion(ctx context.Context, arg database.RecordParserConfigVersionParams) error {
+	f.versions = append(f.versions, arg)
+	return f.err
+}
+
+func (f *fakeURLRepository) CountURLsForBulkAction(ctx context.Context, arg database.CountURLsForBulkActionParams) (int64, error) {
+	var count int64
+	for _, url := range f.urls {
+		if url.DeletedAt.Valid == arg.Deleted && (arg.Status == "" || url.Status == arg.Status) {
+			count++
+		}
+	}
+	return count, f.err
+}
+
+func (f *fakeURLRepository) DeleteURLs(ctx context.Context, arg database.SoftDeleteURLsParams) ([]database.SoftDeleteURLsRow, error) {
+	var rows []database.SoftDeleteURLsRow
+	for id, url := range f.urls {
+		if !url.DeletedAt.Valid {
+			url.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			f.urls[id] = url
+			rows = append(rows, database.SoftDeleteURLsRow{ID: id, Url: url.Url})
+		}
+	}
+	return rows, f.err
+}
+
+func (f *fakeURLRepository) RestoreURLs(ctx context.Context, arg database.RestoreURLsParams) ([]database.RestoreURLsRow, error) {
+	var rows []database.RestoreURLsRow
+	for id, url := range f.urls {
+		if url.DeletedAt.Valid {
+			url.DeletedAt = sql.NullTime{}
+			f.urls[id] = url
+			rows = append(rows, database.RestoreURLsRow{ID: id, Url: url.Url})
+		}
+	}
+	return rows, f.err
+}
+
+func newTestURLService(repo *fakeURLRepository) *URLService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewURLService(repo, nil, logger)
+}
+
+func TestSearchPattern(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: ""},
+		{query: "   ", want: ""},
+		{query: " example.com/news ", want: "%example.com/news%"},
+		{query: "100%_off", want: `%100\%\_off%`},
+		{query: `C:\path`, want: `%C:\\path%`},
+	}
+
+	for _, tt := range tests {
+		if got := SearchPattern(tt.query); got != tt.want {
+			t.Errorf("SearchPattern(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestListURLsPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      URLQuery
+		wantPage   int
+		wantLimit  int
+		wantOffset int32
+	}{
+		{name: "defaults", query: URLQuery{}, wantPage: 1, wantLimit: DefaultURLPageSize},
+		{name: "third page", query: URLQuery{Page: 3, Limit: 50}, wantPage: 3, wantLimit: 50, wantOffset: 100},
+		{name: "limit too large", query: URLQuery{Page: 2, Limit: 500}, wantPage: 2, wantLimit: DefaultURLPageSize, wantOffset: 20},
+		{name: "negative page", query: URLQuery{Page: -1, Limit: 10}, wantPage: 1, wantLimit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeURLRepository()
+			page, err := newTestURLService(repo).ListURLs(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("ListURLs() error = %v", err)
+			}
+			if page.Page != tt.wantPage || page.Limit != tt.wantLimit {
+				t.Errorf("ListURLs() page = %d, limit = %d, want %d, %d", page.Page, page.Limit, tt.wantPage, tt.wantLimit)
+			}
+			if arg := repo.listArgs[0]; arg.Offset != tt.wantOffset || arg.Limit != int32(tt.wantLimit) {
+				t.Errorf("ListURLs() queried offset %d, limit %d, want %d, %d", arg.Offset, arg.Limit, tt.wantOffset, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestListURLsRejectsLongSearch(t *testing.T) {
+	repo := newFakeURLRepository()
+	query := URLQuery{Search: string(make([]byte, MaxSearchQueryLength+1))}
+	_, err := newTestURLService(repo).ListURLs(context.Background(), query)
+	if !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("ListURLs() error = %v, want a domain.ErrValidation", err)
+	}
+	if len(repo.listArgs) != 0 {
+		t.Error("ListURLs() queried the repository for an invalid search")
+	}
+}
+
+func TestGetActiveURL(t *testing.T) {
+	active := database.Url{ID: uuid.New(), Url: "https://example.com/a"}
+	deleted := database.Url{ID: uuid.New(), Url: "https://example.com/b", DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+	service := newTestURLService(newFakeURLRepository(active, deleted))
+
+	if url, err := service.GetActiveURL(context.Background(), active.ID); err != nil || url.ID != active.ID {
+		t.Errorf("GetActiveURL(active) = %v, %v", url, err)
+	}
+	if _, err := service.GetActiveURL(context.Background(), deleted.ID); !errors.Is(err, domain.ErrURLNotFound) {
+		t.Errorf("GetActiveURL(deleted) error = %v, want domain.ErrURLNotFound", err)
+	}
+	if url, err := service.GetURL(context.Background(), deleted.ID); err != nil || !url.DeletedAt.Valid {
+		t.Errorf("GetURL(deleted) = %v, %v, want the deleted URL", url, err)
+	}
+	if _, err := service.GetURL(context.Background(), uuid.New()); !errors.Is(err, domain.ErrURLNotFound) {
+		t.Errorf("GetURL(missing) error = %v, want domain.ErrURLNotFound", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	service := newTestURLService(newFakeURLRepository())
+
+	stats, err := service.Stats(context.Background(), DefaultTopDomains)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Total != 5 {
+		t.Errorf("Stats().Total = %d, want 5", stats.Total)
+	}
+
+	for _, domains := range []int{0, MaxTopDomains + 1} {
+		if _, err := service.Stats(context.Background(), domains); !errors.Is(err, domain.ErrValidation) {
+			t.Errorf("Stats(%d) error = %v, want a domain.ErrValidation", domains, err)
+		}
+	}
+}
+
+func TestCreateURL(t *testing.T) {
+	service := newTestURLService(newFakeURLRepository())
+
+	url, err := service.CreateURL(context.Background(), database.CreateURLParams{Url: "https://example.com", Status: "pending"})
+	if err != nil {
+		t.Fatalf("CreateURL() error = %v", err)
+	}
+	if url.Tags == nil {
+		t.Error("CreateURL() stored nil tags, want an empty list")
+	}
+
+	_, err = service.CreateURL(context.Background(), database.CreateURLParams{Url: "https://example.com", Status: "pending"})
+	if domain.HTTPStatus(err) != http.StatusConflict {
+		t.Errorf("CreateURL(duplicate) error = %v, want a domain.ErrConflict", err)
+	}
+}
+
+func TestDeleteURL(t *testing.T) {
+	url := database.Url{ID: uuid.New(), Url: "https://example.com"}
+	repo := newFakeURLRepository(url)
+	service := newTestURLService(repo)
+
+	if err := service.DeleteURL(context.Background(), url.ID); err != nil {
+		t.Fatalf("DeleteURL() error = %v", err)
+	}
+	if !repo.urls[url.ID].DeletedAt.Valid {
+		t.Error("DeleteURL() did not delete the URL")
+	}
+	if err := service.DeleteURL(context.Background(), url.ID); !errors.Is(err, domain.ErrURLNotFound) {
+		t.Errorf("DeleteURL(deleted) error = %v, want domain.ErrURLNotFound", err)
+	}
+
+	repo.err = domain.Unavailable(context.DeadlineExceeded, "Database did not answer in time")
+	if err := service.DeleteURL(context.Background(), uuid.New()); domain.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("DeleteURL() error = %v, want a domain.ErrUnavailable", err)
+	}
+}
+
+func newCachedTestURLService(repo *fakeURLRepository) *URLService {
+	service := newTestURLService(repo)
+	service.SetCache(cache.New(cache.NewMemory(), "test:", time.Second, service.logger), config.CacheConfig{
+		URLTTL:  time.Minute,
+		ListTTL: time.Minute,
+	})
+	return service
+}
+
+func TestGetURLCached(t *testing.T) {
+	url := database.Url{
+		ID:           uuid.New(),
+		Url:          "https://example.com",
+		Status:       "pending",
+		ParserConfig: pqtype.NullRawMessage{RawMessage: []byte(`{"title":"h1"}`), Valid: true},
+		NextScrapeAt: sql.NullTime{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+		Tags:         []string{"news"},
+		CreatedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	repo := newFakeURLRepository(url)
+	service := newCachedTestURLService(repo)
+
+	first, err := service.GetURL(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("GetURL() error = %v", err)
+	}
+	second, err := service.GetURL(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("GetURL() error = %v", err)
+	}
+	if repo.gets != 1 {
+		t.Errorf("GetURL() read the repository %d times, want 1", repo.gets)
+	}
+	// Null JSON columns come back as a null message, which is still invalid
+	if second.ID != first.ID || !second.NextScrapeAt.Time.Equal(first.NextScrapeAt.Time) ||
+		string(second.ParserConfig.RawMessage) != string(first.ParserConfig.RawMessage) ||
+		!second.ParserConfig.Valid || second.RetryPolicy.Valid || !reflect.DeepEqual(second.Tags, first.Tags) {
+		t.Errorf("GetURL() from cache = %+v, want %+v", second, first)
+	}
+
+	service.Invalidate(context.Background(), url.ID)
+	if _, err := service.GetURL(context.Background(), url.ID); err != nil {
+		t.Fatalf("GetURL() error = %v", err)
+	}
+	if repo.gets != 2 {
+		t.Errorf("GetURL() after Invalidate read the repository %d times, want 2", repo.gets)
+	}
+}
+
+func TestListURLsCacheDroppedOnChanges(t *testing.T) {
+	url := database.Url{ID: uuid.New(), Url: "https://example.com/a"}
+	repo := newFakeURLRepository(url)
+	service := newCachedTestURLService(repo)
+	ctx := context.Background()
+
+	list := func() URLPage {
+		t.Helper()
+		page, err := service.ListURLs(ctx, URLQuery{})
+		if err != nil {
+			t.Fatalf("ListURLs() error = %v", err)
+		}
+		return page
+	}
+
+	list()
+	list()
+	if len(repo.listArgs) != 1 {
+		t.Fatalf("ListURLs() queried the repository %d times, want 1", len(repo.listArgs))
+	}
+
+	if _, err := service.CreateURL(ctx, database.CreateURLParams{Url: "https://example.com/b", Status: "pending"}); err != nil {
+		t.Fatalf("CreateURL() error = %v", err)
+	}
+	if page := list(); page.Total != 2 {
+		t.Errorf("ListURLs() after CreateURL total = %d, want 2", page.Total)
+	}
+
+	if err := service.DeleteURL(ctx, url.ID); err != nil {
+		t.Fatalf("DeleteURL() error = %v", err)
+	}
+	if page := list(); len(page.URLs) != 1 {
+		t.Errorf("ListURLs() after DeleteURL = %d URLs, want 1", len(page.URLs))
+	}
+	if len(repo.listArgs) != 3 {
+		t.Errorf("ListURLs() queried the repository %d times, want 3", len(repo.listArgs))
+	}
+}
+
+func TestUpsertURLs(t *testing.T) {
+	existing := database.Url{ID: uuid.New(), Url: "https://example.com/a", Frequency: "1h", DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+	repo := newFakeURLRepository(existing)
+	service := newTestURLService(repo)
+
+	rows, err := service.UpsertURLs(context.Background(), []database.CreateURLParams{
+		{Url: "https://example.com/a", Frequency: "2h"},
+		{Url: "https://example.com/b", Frequency: "1h", Status: "pending"},
+	}, Change{Author: "  " + strings.Repeat("a", MaxAuthorLength+10), Reason: "imported"})
+	if err != nil {
+		t.Fatalf("UpsertURLs() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].ID != existing.ID || rows[0].Inserted || !rows[1].Inserted {
+		t.Errorf("UpsertURLs() = %+v, want the existing URL updated and a new one inserted", rows)
+	}
+	if url := repo.urls[existing.ID]; url.Frequency != "2h" || url.DeletedAt.Valid {
+		t.Errorf("UpsertURLs() left the existing URL at %+v", url)
+	}
+	if len(repo.versions) != 2 {
+		t.Fatalf("UpsertURLs() recorded %d parser config versions, want 2", len(repo.versions))
+	}
+	if version := repo.versions[0]; version.Reason != "imported" || len(version.Author) != MaxAuthorLength {
+		t.Errorf("UpsertURLs() recorded reason %q, author of %d characters, want %q, %d", version.Reason, len(version.Author), "imported", MaxAuthorLength)
+	}
+
+	repo.err = domain.Unavailable(context.DeadlineExceeded, "Database did not answer in time")
+	if _, err := service.UpsertURLs(context.Background(), []database.CreateURLParams{{Url: "https://example.com/c"}}, Change{}); domain.HTTPStatus(err) != http.StatusServiceUnavailable {
+		t.Errorf("UpsertURLs() error = %v, want a domain.ErrUnavailable", err)
+	}
+}
+
+func TestBulkActions(t *testing.T) {
+	repo := newFakeURLRepository(
+		database.Url{ID: uuid.New(), Url: "https://example.com/a", Status: "failed"},
+		database.Url{ID: uuid.New(), Url: "https://example.com/b", Status: "pending"},
+	)
+	service := newTestURLService(repo)
+	ctx := context.Background()
+	filter := BulkFilter{IDs: []uuid.UUID{}}
+
+	if matched, err := service.CountBulkAction(ctx, BulkReset, filter); err != nil || matched != 1 {
+		t.Errorf("CountBulkAction(reset) = %d, %v, want 1 failed URL", matched, err)
+	}
+	if matched, err := service.CountBulkAction(ctx, BulkRestore, filter); err != nil || matched != 0 {
+		t.Errorf("CountBulkAction(restore) = %d, %v, want no deleted URLs", matched, err)
+	}
+
+	if affected, err := service.ApplyBulkAction(ctx, BulkDelete, filter); err != nil || affected != 2 {
+		t.Errorf("ApplyBulkAction(delete) = %d, %v, want 2", affected, err)
+	}
+	if matched, err := service.CountBulkAction(ctx, BulkRestore, filter); err != nil || matched != 2 {
+		t.Errorf("CountBulkAction(restore) after delete = %d, %v, want 2", matched, err)
+	}
+	if affected, err := service.ApplyBulkAction(ctx, BulkRestore, filter); err != nil || affected != 2 {
+		t.Errorf("ApplyBulkAction(restore) = %d, %v, want 2", affected, err)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples of RFC 7386, appendix A
+	tests := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch interface{}
+		json.Unmarshal([]byte(tt.target), &target)
+		json.Unmarshal([]byte(tt.patch), &patch)
+		got, _ := json.Marshal(mergePatch(target, patch))
+		if string(got) != tt.want {
+			t.Errorf("mergePatch(%s, %s) = %s, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	url := database.Url{
+		ID:        uuid.New(),
+		Url:       "https://example.com/a",
+		Frequency: "1h",
+		UserAgent: sql.NullString{String: "Custom/1.0", Valid: true},
+		Tags:      []string{"news"},
+	}
+	deleted := database.Url{ID: uuid.New(), Url: "https://example.com/b", DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+	repo := newFakeURLRepository(url, deleted)
+	service := newTestURLService(repo)
+	ctx := context.Background()
+
+	var checked *models.CreateURLRequest
+	check := func(ctx context.Context, config *models.CreateURLRequest) (database.CreateURLParams, error) {
+		checked = config
+		if config.Frequency == "1s" {
+			return database.CreateURLParams{}, &models.ValidationError{Field: "frequency", Message: "too often"}
+		}
+		return database.CreateURLParams{Url: config.URL, Frequency: config.Frequency}, nil
+	}
+
+	config, err := service.Patch(ctx, url.ID, map[string]interface{}{"frequency": "2h", "user_agent": nil}, check, Change{Reason: "patched"})
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if config.Frequency != "2h" || config.UserAgent != "" || !reflect.DeepEqual(config.Tags, url.Tags) || checked == nil {
+		t.Errorf("Patch() = %+v, want the frequency changed, the user agent removed and the tags kept", config)
+	}
+	if got := repo.urls[url.ID].Frequency; got != "2h" {
+		t.Errorf("Patch() saved frequency %q, want 2h", got)
+	}
+	if len(repo.versions) != 1 || repo.versions[0].Reason != "patched" {
+		t.Errorf("Patch() recorded versions %+v, want one patched version", repo.versions)
+	}
+
+	tests := []struct {
+		name  string
+		id    uuid.UUID
+		patch map[string]interface{}
+		want  error
+	}{
+		{"deleted URL", deleted.ID, map[string]interface{}{"frequency": "2h"}, domain.ErrURLNotFound},
+		{"address change", url.ID, map[string]interface{}{"url": "https://example.com/c"}, domain.ErrValidation},
+		{"unknown field", url.ID, map[string]interface{}{"colour": "red"}, domain.ErrValidation},
+		{"wrong type", url.ID, map[string]interface{}{"timeout": "soon"}, domain.ErrValidation},
+	}
+	for _, tt := range tests {
+		if _, err := service.Patch(ctx, tt.id, tt.patch, check, Change{}); !errors.Is(err, tt.want) {
+			t.Errorf("Patch(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	var validationErr *models.ValidationError
+	if _, err := service.Patch(ctx, url.ID, map[string]interface{}{"frequency": "1s"}, check, Change{}); !errors.As(err, &validationErr) {
+		t.Errorf("Patch(invalid frequency) error = %v, want the error of the check", err)
+	}
+}
@@ -1,12 +1,25 @@
 package types
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/services/api-gateway/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/events"
+	"go_scraping_project/shared/health"
+	"go_scraping_project/shared/kafka"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
@@ -15,36 +28,56 @@ import (
 // It provides endpoints for system management, dead letter queue operations,
 // and comprehensive health monitoring.
 type AdminHandler struct {
-	Logger *logrus.Logger
+	Logger      *logrus.Logger
+	Config      *config.Watcher
+	Health      *health.Checker                   // Checks of the database, Kafka, services and workers
+	Producer    events.Sender                     // Replays imported dead letters and sends consumer commands
+	DeadLetters repositories.DeadLetterRepository // Dead letters stored by the Kafka consumers
+	StartedAt   time.Time                         // When the API Gateway started, for its uptime
 }
 
-// NewAdminHandler creates a new admin handler with the provided logger.
-// This function initializes the handler with necessary dependencies.
-func NewAdminHandler(logger *logrus.Logger) *AdminHandler {
+// NewAdminHandler creates a new admin handler with the provided logger, configuration watcher,
+// health checker, Kafka producer and dead letter store. This function initializes the handler
+// with necessary dependencies.
+func NewAdminHandler(logger *logrus.Logger, cfg *config.Watcher, checker *health.Checker, producer events.Sender, deadLetters repositories.DeadLetterRepository) *AdminHandler {
 	return &AdminHandler{
-		Logger: logger,
+		Logger:      logger,
+		Config:      cfg,
+		Health:      checker,
+		Producer:    producer,
+		DeadLetters: deadLetters,
+		StartedAt:   time.Now(),
 	}
 }
 
+// Dead letter import limits
+const (
+	maxDeadLetterImportRecords = 10000
+	maxDeadLetterImportBytes   = 32 << 20
+)
+
 // ListDeadLetterMessages handles GET /api/v1/admin/dead-letter
 //
 // Purpose: Retrieves messages that failed processing and are in the dead letter queue.
 // This endpoint is essential for monitoring system health and debugging processing
 // issues. It allows administrators to review failed messages and understand
-// why they failed.
+// why they failed. Each message's original payload is decoded by its
+// message_type (scraping_task, scrape_result, url_event) and returned as
+// JSON next to the error; values that cannot be decoded are returned raw.
+// Messages are listed most recent failures first.
 //
 // Query Parameters:
 //   - page: Page number (default: 1)
 //   - limit: Items per page, max 100 (default: 20)
 //   - topic: Filter by Kafka topic
-//   - status: Filter by status (pending, retrying, failed)
+//   - message_type: Filter by message type, e.g. scraping_task
 //
-// Response: models.ListDeadLetterMessagesResponse (200 OK) or error (500)
+// Response: models.ListDeadLetterMessagesResponse (200 OK) or error (500/503)
 //
 // Example Usage:
 //
 //	GET /api/v1/admin/dead-letter?page=1&limit=20&topic=scraping-requests
-//	GET /api/v1/admin/dead-letter?status=failed&page=1&limit=50
+//	GET /api/v1/admin/dead-letter?message_type=scrape_result&page=1&limit=50
 func (h *AdminHandler) ListDeadLetterMessages(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
@@ -57,31 +90,32 @@ func (h *AdminHandler) ListDeadLetterMessages(w http.ResponseWriter, r *http.Req
 		limit = 20
 	}
 
-	topic := r.URL.Query().Get("topic")
-	status := r.URL.Query().Get("status") // "pending", "retrying", "failed"
-
+	filter := repositories.DeadLetterFilter{
+		Topic:       strings.TrimSpace(r.URL.Query().Get("topic")),
+		MessageType: strings.TrimSpace(r.URL.Query().Get("message_type")),
+	}
 	offset := (page - 1) * limit
 
-	// TODO: Get dead letter messages from service
-	// messages, err := h.adminService.GetDeadLetterMessages(r.Context(), topic, status, limit, offset)
-	// if err != nil {
-	//     h.Logger.WithError(err).Error("Failed to get dead letter messages")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	messages, err := h.DeadLetters.ListDeadLetters(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to list dead letter messages")
+		return
+	}
+	total, err := h.DeadLetters.CountDeadLetters(r.Context(), filter)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to count dead letter messages")
+		return
+	}
 
-	// For now, return mock data
 	response := models.ListDeadLetterMessagesResponse{
-		Messages: []models.DeadLetterMessageResponse{},
-		Total:    0,
+		Messages: make([]models.DeadLetterMessageResponse, 0, len(messages)),
+		Total:    total,
 		Page:     page,
 		Limit:    limit,
 	}
-
-	// Use variables to avoid unused variable warnings
-	_ = offset
-	_ = topic
-	_ = status
+	for _, message := range messages {
+		response.Messages = append(response.Messages, deadLetterResponse(message))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -134,16 +168,7 @@ func (h *AdminHandler) RetryDeadLetterMessage(w http.ResponseWriter, r *http.Req
 	// TODO: Retry dead letter message using service
 	// err := h.adminService.RetryDeadLetterMessage(r.Context(), id, retryRequest.ForceRetry)
 	// if err != nil {
-	//     if errors.Is(err, domain.ErrMessageNotFound) {
-	//         http.Error(w, "Message not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     if errors.Is(err, domain.ErrMaxRetriesExceeded) {
-	//         http.Error(w, "Max retries exceeded", http.StatusBadRequest)
-	//         return
-	//     }
-	//     h.Logger.WithError(err).Error("Failed to retry dead letter message")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
+	//     writeError(w, h.Logger, err, "Failed to retry dead letter message")
 	//     return
 	// }
 
@@ -179,12 +204,7 @@ func (h *AdminHandler) DeleteDeadLetterMessage(w http.ResponseWriter, r *http.Re
 	// TODO: Delete dead letter message using service
 	// err := h.adminService.DeleteDeadLetterMessage(r.Context(), id)
 	// if err != nil {
-	//     if errors.Is(err, domain.ErrMessageNotFound) {
-	//         http.Error(w, "Message not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.Logger.WithError(err).Error("Failed to delete dead letter message")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
+	//     writeError(w, h.Logger, err, "Failed to delete dead letter message")
 	//     return
 	// }
 
@@ -193,6 +213,363 @@ func (h *AdminHandler) DeleteDeadLetterMessage(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(map[string]string{"message": "Message deleted successfully"})
 }
 
+// ExportDeadLetterMessages handles GET /api/v1/admin/dead-letter/export
+//
+// Purpose: Exports dead letter messages as NDJSON, one models.DeadLetterRecord
+// per line, oldest failures first, for offline analysis or for fixing
+// messages in bulk and replaying them with POST
+// /api/v1/admin/dead-letter/import. Values are exported as the original
+// JSON, so records can be edited with line-based tools such as jq.
+//
+// Query Parameters:
+//   - topic: Only messages from this Kafka topic
+//   - message_type: Only messages of this type, e.g. scraping_task
+//   - older_than: Only messages that failed longer ago than this, e.g. 12h or 7d
+//
+// Response: NDJSON stream (200 OK, application/x-ndjson) or error (400/500/503)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/dead-letter/export?topic=scraping-tasks
+//	GET /api/v1/admin/dead-letter/export?message_type=scrape_result&older_than=1d
+func (h *AdminHandler) ExportDeadLetterMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.DeadLetterFilter{
+		Topic:       strings.TrimSpace(query.Get("topic")),
+		MessageType: strings.TrimSpace(query.Get("message_type")),
+	}
+	olderThan, err := parseOlderThan(query.Get("older_than"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if olderThan > 0 {
+		filter.FailedBefore = time.Now().UTC().Add(-olderThan)
+	}
+
+	// The response starts with the first record, so a store that fails
+	// before it still gets an error status
+	started := false
+	start := func() {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", "attachment; filename=dead-letter.ndjson")
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+	encoder := json.NewEncoder(w)
+	err = h.DeadLetters.ExportDeadLetters(r.Context(), filter, func(message kafka.DeadLetter) error {
+		start()
+		return encoder.Encode(deadLetterRecord(message))
+	})
+	if err != nil && !started {
+		writeError(w, h.Logger, err, "Failed to export dead letter messages")
+		return
+	}
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to write dead letter export")
+		return
+	}
+	start()
+}
+
+// ImportDeadLetterMessages handles POST /api/v1/admin/dead-letter/import
+//
+// Purpose: Replays dead letter messages, typically fixed after an export, by
+// sending each record's value back to its topic with its key. Every record
+// is validated first: it needs a topic and a JSON value, and a value with a
+// message_type must decode as that type. An invalid record rejects the whole
+// import, so a bad edit never leaves it half replayed. With dry_run the
+// records are only validated.
+//
+// A replayed message is removed from the dead letter queue by the record's
+// id, so the next import of the same export does not replay it again.
+//
+// Query Parameters:
+//   - dry_run: Validate the records without sending them (default: false)
+//
+// Request Body: NDJSON, one models.DeadLetterRecord per line (at most 10000)
+//
+// Response: models.DeadLetterImportResponse (200 OK) or error (400/503)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/dead-letter/import?dry_run=true
+//	Content-Type: application/x-ndjson
+//
+//	{"id":"msg-123","topic":"scraping-tasks","key":"...","message_type":"scraping_task","value":{...}}
+func (h *AdminHandler) ImportDeadLetterMessages(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := parseDeadLetterImport(http.MaxBytesReader(w, r.Body, maxDeadLetterImportBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(records) == 0 {
+		http.Error(w, "Import must contain at least one record", http.StatusBadRequest)
+		return
+	}
+
+	response := models.DeadLetterImportResponse{DryRun: dryRun, Received: len(records)}
+	if !dryRun {
+		if h.Producer == nil || h.DeadLetters == nil {
+			http.Error(w, "Replaying messages is not available", http.StatusServiceUnavailable)
+			return
+		}
+		for i, record := range records {
+			headers := map[string]string{"dead_letter_id": record.ID}
+			if record.MessageType != "" {
+				headers["type"] = record.MessageType
+			}
+			if err := h.Producer.SendMessage(r.Context(), record.Topic, record.Key, record.value, headers); err != nil {
+				h.Logger.WithError(err).WithFields(logrus.Fields{"id": record.ID, "topic": record.Topic}).Error("Failed to replay dead letter message")
+				response.Failed++
+				response.Errors = append(response.Errors, models.DeadLetterImportError{Line: i + 1, ID: record.ID, Error: err.Error()})
+				continue
+			}
+			response.Replayed++
+
+			// Records edited into new messages have no stored dead letter
+			id, err := uuid.Parse(record.ID)
+			if err != nil {
+				continue
+			}
+			removed, err := h.DeadLetters.DeleteDeadLetter(r.Context(), id)
+			if err != nil {
+				h.Logger.WithError(err).WithField("id", record.ID).Error("Failed to remove replayed dead letter message")
+				response.Errors = append(response.Errors, models.DeadLetterImportError{Line: i + 1, ID: record.ID, Error: "replayed, but not removed from the dead letter queue"})
+				continue
+			}
+			if removed {
+				response.Removed++
+			}
+		}
+		h.Logger.WithFields(logrus.Fields{
+			"replayed": response.Replayed,
+			"removed":  response.Removed,
+			"failed":   response.Failed,
+		}).Info("Dead letter messages replayed")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// deadLetterImport is a validated record of a dead letter import
+type deadLetterImport struct {
+	models.DeadLetterRecord
+	value json.RawMessage // Original message, decoded from ValueBase64 if needed
+}
+
+// deadLetterRecord converts a dead letter to its export record. Values that
+// are not JSON are exported as base64.
+func deadLetterRecord(message kafka.DeadLetter) models.DeadLetterRecord {
+	record := models.DeadLetterRecord{
+		ID:          message.ID,
+		Topic:       message.Topic,
+		Partition:   int32(message.Partition),
+		Offset:      message.Offset,
+		Key:         message.Key,
+		MessageType: message.MessageType,
+		Error:       message.Error,
+		RetryCount:  message.RetryCount,
+		FailedAt:    message.FailedAt.Format(time.RFC3339),
+	}
+	if json.Valid(message.Value) {
+		record.Value = message.Value
+	} else {
+		record.ValueBase64 = base64.StdEncoding.EncodeToString(message.Value)
+	}
+	return record
+}
+
+// parseDeadLetterImport reads and validates the NDJSON records of an
+// import. Blank lines are skipped; errors name the offending line.
+func parseDeadLetterImport(body io.Reader) ([]deadLetterImport, error) {
+	var records []deadLetterImport
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxDeadLetterImportBytes)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(records) == maxDeadLetterImportRecords {
+			return nil, fmt.Errorf("import cannot contain more than %d records", maxDeadLetterImportRecords)
+		}
+
+		var record deadLetterImport
+		if err := json.Unmarshal(text, &record.DeadLetterRecord); err != nil {
+			return nil, fmt.Errorf("line %d: invalid record: %v", line, err)
+		}
+		record.value = record.Value
+		if len(record.value) == 0 && record.ValueBase64 != "" {
+			value, err := base64.StdEncoding.DecodeString(record.ValueBase64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: value_base64 is not valid base64", line)
+			}
+			record.value = value
+		}
+		switch {
+		case strings.TrimSpace(record.Topic) == "":
+			return nil, fmt.Errorf("line %d: topic is required", line)
+		case len(record.value) == 0:
+			return nil, fmt.Errorf("line %d: value or value_base64 is required", line)
+		case !json.Valid(record.value):
+			return nil, fmt.Errorf("line %d: value is not JSON, only JSON messages can be replayed", line)
+		}
+		if record.MessageType != "" {
+			if _, err := kafka.DecodePayload(record.MessageType, record.value); err != nil {
+				return nil, fmt.Errorf("line %d: value is not a valid %s message: %v", line, record.MessageType, err)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import: %v", err)
+	}
+	return records, nil
+}
+
+// maxDeadLetterValue is the length raw dead letter values are truncated to
+const maxDeadLetterValue = 1024
+
+// deadLetterResponse converts a dead letter to the response format, with its
+// original message decoded by message type. The raw value is only returned,
+// truncated, when it could not be decoded.
+func deadLetterResponse(d kafka.DeadLetter) models.DeadLetterMessageResponse {
+	response := models.DeadLetterMessageResponse{
+		ID:          d.ID,
+		Topic:       d.Topic,
+		Partition:   int32(d.Partition),
+		Offset:      d.Offset,
+		Key:         d.Key,
+		Error:       d.Error,
+		RetryCount:  d.RetryCount,
+		CreatedAt:   d.CreatedAt.Format(time.RFC3339),
+		FailedAt:    d.FailedAt.Format(time.RFC3339),
+		MessageType: d.MessageType,
+	}
+	payload, err := d.Payload()
+	if err != nil {
+		response.PayloadError = err.Error()
+		response.Value = string(d.Value)
+		if len(response.Value) > maxDeadLetterValue {
+			response.Value = strings.ToValidUTF8(response.Value[:maxDeadLetterValue], "")
+		}
+		return response
+	}
+	response.Payload = payload
+	return response
+}
+
+// PurgeDeadLetterMessages handles DELETE /api/v1/admin/dead-letter
+//
+// Purpose: Deletes dead letter messages in bulk, such as the stale messages
+// of a topic after the cause of their failure was fixed. At least one filter
+// is required so the queue is never emptied by accident. With dry_run the
+// matching messages are only counted, to check a purge before running it.
+//
+// Query Parameters:
+//   - topic: Only messages from this Kafka topic
+//   - older_than: Only messages that failed longer ago than this, e.g. 12h or 7d
+//   - dry_run: Count the matching messages without deleting them (default: false)
+//
+// Response: models.PurgeDeadLetterResponse (200 OK) or error (400/500/503)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/admin/dead-letter?topic=scraping-requests&older_than=7d&dry_run=true
+//	DELETE /api/v1/admin/dead-letter?older_than=30d
+func (h *AdminHandler) PurgeDeadLetterMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	topic := strings.TrimSpace(query.Get("topic"))
+	olderThan, err := parseOlderThan(query.Get("older_than"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if topic == "" && olderThan == 0 {
+		http.Error(w, "At least one of topic and older_than is required", http.StatusBadRequest)
+		return
+	}
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := models.PurgeDeadLetterResponse{
+		Topic:  topic,
+		DryRun: dryRun,
+	}
+	var before time.Time
+	if olderThan > 0 {
+		before = time.Now().UTC().Add(-olderThan)
+		response.FailedBefore = before.Format(time.RFC3339)
+	}
+
+	if dryRun {
+		response.Matched, err = h.DeadLetters.CountDeadLetters(r.Context(), repositories.DeadLetterFilter{Topic: topic, FailedBefore: before})
+	} else {
+		response.Deleted, err = h.DeadLetters.PurgeDeadLetters(r.Context(), topic, before)
+		response.Matched = response.Deleted
+	}
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to purge dead letter messages")
+		return
+	}
+
+	if !dryRun {
+		h.Logger.WithFields(logrus.Fields{
+			"topic":         topic,
+			"failed_before": response.FailedBefore,
+			"deleted":       response.Deleted,
+		}).Info("Dead letter messages purged")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseOlderThan parses the age of the older_than filter: a Go duration
+// or a whole number of days such as 7d. An empty value is 0.
+func parseOlderThan(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	var age time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("older_than must be a duration such as 12h or 7d, got %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("older_than must be a duration such as 12h or 7d, got %q", value)
+		}
+		age = d
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("older_than must be positive, got %q", value)
+	}
+	return age, nil
+}
+
 // BulkRetryDeadLetterMessages handles POST /api/v1/admin/dead-letter/bulk-retry
 //
 // Purpose: Retries multiple failed messages from the dead letter queue in bulk.
@@ -226,13 +603,8 @@ func (h *AdminHandler) BulkRetryDeadLetterMessages(w http.ResponseWriter, r *htt
 	}
 
 	// Validate request
-	if len(req.MessageIDs) == 0 {
-		http.Error(w, "At least one message ID is required", http.StatusBadRequest)
-		return
-	}
-
-	if len(req.MessageIDs) > 100 {
-		http.Error(w, "Maximum 100 message IDs allowed per request", http.StatusBadRequest)
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -257,39 +629,168 @@ func (h *AdminHandler) BulkRetryDeadLetterMessages(w http.ResponseWriter, r *htt
 	json.NewEncoder(w).Encode(response)
 }
 
+// PauseConsumers handles POST /api/v1/admin/consumers/pause
+//
+// Purpose: Pauses the consumption of a topic, e.g. to stop parsing while a
+// parser fix is deployed. The command is published to the consumer control
+// topic (kafka.topics.consumer_control), which every consumer follows, so
+// all members of the group stop fetching the topic and messages wait in
+// Kafka until it is resumed. Instances report their paused topics in
+// GET /api/v1/admin/workers.
+//
+// Request Body: models.ConsumerControlRequest (topic * pauses every topic;
+// without group every consumer group is paused)
+//
+// Response: models.ConsumerControlResponse (202 Accepted) or error (400/500/503)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/consumers/pause
+//	{
+//	  "topic": "scraped-data",
+//	  "group": "parser-group",
+//	  "reason": "deploying parser fix"
+//	}
+func (h *AdminHandler) PauseConsumers(w http.ResponseWriter, r *http.Request) {
+	h.sendConsumerCommand(w, r, kafka.ConsumerActionPause)
+}
+
+// ResumeConsumers handles POST /api/v1/admin/consumers/resume
+//
+// Purpose: Resumes the consumption of a topic paused through
+// POST /api/v1/admin/consumers/pause. Resuming topic * clears every pause of
+// the group.
+//
+// Request Body: models.ConsumerControlRequest
+//
+// Response: models.ConsumerControlResponse (202 Accepted) or error (400/500/503)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/consumers/resume
+//	{
+//	  "topic": "scraped-data",
+//	  "group": "parser-group"
+//	}
+func (h *AdminHandler) ResumeConsumers(w http.ResponseWriter, r *http.Request) {
+	h.sendConsumerCommand(w, r, kafka.ConsumerActionResume)
+}
+
+// sendConsumerCommand publishes a pause or resume command to the consumer
+// control topic
+func (h *AdminHandler) sendConsumerCommand(w http.ResponseWriter, r *http.Request, action string) {
+	var req models.ConsumerControlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	command := kafka.ConsumerCommand{
+		Action:   action,
+		Topic:    strings.TrimSpace(req.Topic),
+		Group:    strings.TrimSpace(req.Group),
+		Reason:   req.Reason,
+		IssuedAt: time.Now().UTC(),
+	}
+	if err := command.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	controlTopic := h.Config.Current().Kafka.Topics.ConsumerControl
+	if h.Producer == nil || controlTopic == "" {
+		http.Error(w, "Consumer control is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err := h.Producer.SendMessage(r.Context(), controlTopic, command.CommandKey(), command, nil); err != nil {
+		h.Logger.WithError(err).Error("Failed to send consumer command")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.Logger.WithFields(logrus.Fields{
+		"action": action,
+		"topic":  command.Topic,
+		"group":  command.Group,
+		"reason": command.Reason,
+	}).Info("Consumer command sent")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(models.ConsumerControlResponse{
+		Action:   command.Action,
+		Topic:    command.Topic,
+		Group:    command.Group,
+		Reason:   command.Reason,
+		IssuedAt: command.IssuedAt.Format(time.RFC3339),
+	})
+}
+
 // GetSystemHealth handles GET /api/v1/admin/health
 //
-// Purpose: Retrieves comprehensive system health information including
-// all service components, database connectivity, Kafka connectivity,
-// and overall system status. This endpoint is essential for monitoring
-// and alerting systems to detect issues early.
+// Purpose: Retrieves the health of the whole system. The API Gateway checks
+// the database and Kafka, calls the health endpoint of every service in
+// health.services and reads the heartbeats of the scraper and parser
+// instances. Checks run concurrently, each bounded by health.timeout, and
+// every component is reported with its status and check latency. The overall
+// status is the worst component status, so monitoring and alerting systems
+// can detect issues early.
 //
-// Response: Comprehensive health status (200 OK) or error (500)
+// Response: models.SystemHealthResponse (200 OK when healthy or degraded,
+// 503 Service Unavailable when unhealthy)
 //
 // Example Usage:
 //
 //	GET /api/v1/admin/health
 func (h *AdminHandler) GetSystemHealth(w http.ResponseWriter, r *http.Request) {
-	// TODO: Get comprehensive system health from service
-	// health, err := h.adminService.GetSystemHealth(r.Context())
-	// if err != nil {
-	//     h.Logger.WithError(err).Error("Failed to get system health")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	report := h.Health.Run(r.Context())
+
+	response := models.SystemHealthResponse{
+		Status:     report.Status,
+		Timestamp:  report.CheckedAt.Format(time.RFC3339),
+		Uptime:     time.Since(h.StartedAt).Round(time.Second).String(),
+		Components: make([]models.ComponentHealthResponse, 0, len(report.Components)),
+	}
+	for _, component := range report.Components {
+		response.Components = append(response.Components, models.ComponentHealthResponse{
+			Name:      component.Name,
+			Status:    component.Status,
+			LatencyMS: component.Latency.Milliseconds(),
+			Error:     component.Error,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status == health.StatusUnhealthy {
+		h.Logger.WithField("components", response.Components).Warn("System is unhealthy")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
 
-	// For now, return mock health data
-	response := models.HealthResponse{
-		Status:    "healthy",
-		Timestamp: "2024-01-01T01:00:00Z",
-		Uptime:    "24h30m",
-		Version:   "1.0.0",
-		Checks: map[string]string{
-			"database": "healthy",
-			"kafka":    "healthy",
-			"redis":    "healthy",
-			"workers":  "healthy",
-		},
+// GetConfig handles GET /api/v1/admin/config
+//
+// Purpose: Returns the configuration currently in effect for the API Gateway,
+// including changes picked up by hot-reload (config file edits or SIGHUP).
+// This endpoint lets operators confirm that a change has been applied
+// without restarting the service. Secrets are omitted from the response.
+//
+// Response: models.EffectiveConfigResponse (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/config
+func (h *AdminHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	response := models.EffectiveConfigResponse{
+		Service:      "api-gateway",
+		Config:       h.Config.Current(),
+		LiveSettings: []string{"logging.level", "rate_limit", "scheduler", "features", "watchdog", "alerts", "notifications"},
+	}
+	if reloadedAt := h.Config.ReloadedAt(); !reloadedAt.IsZero() {
+		response.ReloadedAt = reloadedAt.Format(time.RFC3339)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
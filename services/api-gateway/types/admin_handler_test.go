@@ -0,0 +1,487 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/services/api-gateway/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/contract"
+	"go_scraping_project/shared/health"
+	"go_scraping_project/shared/kafka"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetSystemHealth(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	checker := health.NewChecker(0)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("workers", func(context.Context) error { return health.Degraded("1 of 2 instances are stale") })
+	handler := NewAdminHandler(logger, nil, checker, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetSystemHealth(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var response models.SystemHealthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Status != health.StatusDegraded || len(response.Components) != 2 {
+		t.Fatalf("response = %+v, want degraded with 2 components", response)
+	}
+	if response.Components[1].Name != "workers" || response.Components[1].Error == "" {
+		t.Errorf("workers component = %+v", response.Components[1])
+	}
+
+	checker.Add("kafka", func(context.Context) error { return errors.New("connection refused") })
+	rec = httptest.NewRecorder()
+	handler.GetSystemHealth(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d when a component is unhealthy", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestParseOlderThan(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"12h", 12 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"week", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOlderThan(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseOlderThan(%q) = %s, %v; want %s, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// memoryDeadLetters is a repositories.DeadLetterRepository in memory
+type memoryDeadLetters struct {
+	deadLetters []kafka.DeadLetter
+}
+
+// matches reports whether a dead letter matches a filter
+func (m *memoryDeadLetters) matches(d kafka.DeadLetter, filter repositories.DeadLetterFilter) bool {
+	return (filter.Topic == "" || d.Topic == filter.Topic) &&
+		(filter.MessageType == "" || d.MessageType == filter.MessageType) &&
+		(filter.FailedBefore.IsZero() || d.FailedAt.Before(filter.FailedBefore))
+}
+
+func (m *memoryDeadLetters) ListDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter, limit, offset int) ([]kafka.DeadLetter, error) {
+	var page []kafka.DeadLetter
+	for _, d := range m.deadLetters {
+		if m.matches(d, filter) {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if len(page) < limit {
+				page = append(page, d)
+			}
+		}
+	}
+	return page, nil
+}
+
+func (m *memoryDeadLetters) CountDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter) (int64, error) {
+	var count int64
+	for _, d := range m.deadLetters {
+		if m.matches(d, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryDeadLetters) PurgeDeadLetters(ctx context.Context, topic string, failedBefore time.Time) (int64, error) {
+	filter := repositories.DeadLetterFilter{Topic: topic, FailedBefore: failedBefore}
+	kept := m.deadLetters[:0]
+	for _, d := range m.deadLetters {
+		if !m.matches(d, filter) {
+			kept = append(kept, d)
+		}
+	}
+	deleted := int64(len(m.deadLetters) - len(kept))
+	m.deadLetters = kept
+	return deleted, nil
+}
+
+func (m *memoryDeadLetters) ExportDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter, fn func(kafka.DeadLetter) error) error {
+	for _, d := range m.deadLetters {
+		if m.matches(d, filter) {
+			if err := fn(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryDeadLetters) DeleteDeadLetter(ctx context.Context, id uuid.UUID) (bool, error) {
+	for i, d := range m.deadLetters {
+		if d.ID == id.String() {
+			m.deadLetters = append(m.deadLetters[:i], m.deadLetters[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestPurgeDeadLetterMessages(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	now := time.Now()
+	store := &memoryDeadLetters{deadLetters: []kafka.DeadLetter{
+		{ID: "old", Topic: "scraping-requests", FailedAt: now.Add(-10 * 24 * time.Hour)},
+		{ID: "new", Topic: "scraping-requests", FailedAt: now.Add(-time.Hour)},
+		{ID: "other", Topic: "scraping-results", FailedAt: now.Add(-10 * 24 * time.Hour)},
+	}}
+	handler := NewAdminHandler(logger, nil, nil, nil, store)
+
+	rec := httptest.NewRecorder()
+	handler.PurgeDeadLetterMessages(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dead-letter", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status without filters = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.PurgeDeadLetterMessages(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dead-letter?topic=scraping-requests&dry_run=maybe", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status with an invalid dry_run = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// A dry run only counts the messages
+	rec = httptest.NewRecorder()
+	handler.PurgeDeadLetterMessages(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dead-letter?topic=scraping-requests&older_than=7d&dry_run=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var response models.PurgeDeadLetterResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Topic != "scraping-requests" || !response.DryRun || response.FailedBefore == "" || response.Matched != 1 || response.Deleted != 0 {
+		t.Errorf("response = %+v, want a dry run of scraping-requests matching 1 message", response)
+	}
+	if len(store.deadLetters) != 3 {
+		t.Fatalf("dry run deleted messages, %d left", len(store.deadLetters))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.PurgeDeadLetterMessages(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dead-letter?older_than=7d", nil))
+	response = models.PurgeDeadLetterResponse{}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if rec.Code != http.StatusOK || response.DryRun || response.Deleted != 2 || response.Matched != 2 {
+		t.Errorf("status = %d, response = %+v; want 2 messages deleted", rec.Code, response)
+	}
+	if len(store.deadLetters) != 1 || store.deadLetters[0].ID != "new" {
+		t.Errorf("dead letters left = %+v, want the recent one", store.deadLetters)
+	}
+}
+
+func TestDeadLetterResponse(t *testing.T) {
+	decoded := deadLetterResponse(kafka.DeadLetter{
+		ID:          "msg-1",
+		Topic:       "scraping-tasks",
+		MessageType: "scraping_task",
+		Value:       []byte(`{"url":"https://example.com","attempt":3}`),
+		Error:       "scraper unavailable",
+	})
+	task, ok := decoded.Payload.(*sharedmodels.ScrapingTaskMessage)
+	if !ok || task.URL != "https://example.com" || task.Attempt != 3 {
+		t.Errorf("payload = %#v, want the scraping task", decoded.Payload)
+	}
+	if decoded.Value != "" || decoded.PayloadError != "" {
+		t.Errorf("value = %q, payload error = %q; want neither for a decoded payload", decoded.Value, decoded.PayloadError)
+	}
+
+	raw := deadLetterResponse(kafka.DeadLetter{ID: "msg-2", Value: []byte(strings.Repeat("x", 2000))})
+	if raw.Payload != nil || raw.PayloadError == "" || len(raw.Value) != maxDeadLetterValue {
+		t.Errorf("undecodable response = payload %v, error %q, value length %d; want the truncated raw value", raw.Payload, raw.PayloadError, len(raw.Value))
+	}
+}
+
+func TestListDeadLetterMessages(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := &memoryDeadLetters{deadLetters: []kafka.DeadLetter{
+		{ID: "msg-1", Topic: "scraping-tasks", MessageType: "scraping_task", Value: []byte(`{"url":"https://example.com","attempt":3}`), Error: "scraper unavailable"},
+		{ID: "msg-2", Topic: "scraping-tasks", MessageType: "scraping_task", Value: []byte(`not json`)},
+		{ID: "msg-3", Topic: "scraping-results", MessageType: "scrape_result", Value: []byte(`{}`)},
+	}}
+	handler := NewAdminHandler(logger, nil, nil, nil, store)
+
+	rec := httptest.NewRecorder()
+	handler.ListDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter?topic=scraping-tasks&limit=1&page=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var response struct {
+		Messages []struct {
+			ID           string          `json:"id"`
+			Payload      json.RawMessage `json:"payload"`
+			PayloadError string          `json:"payload_error"`
+			Value        string          `json:"value"`
+		} `json:"messages"`
+		Total int64 `json:"total"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Total != 2 || len(response.Messages) != 1 || response.Messages[0].ID != "msg-1" {
+		t.Fatalf("response = %+v, want the first of 2 scraping-tasks messages", response)
+	}
+	var task sharedmodels.ScrapingTaskMessage
+	if err := json.Unmarshal(response.Messages[0].Payload, &task); err != nil || task.URL != "https://example.com" || task.Attempt != 3 {
+		t.Errorf("payload = %s, want the decoded scraping task", response.Messages[0].Payload)
+	}
+
+	// Undecodable values are returned raw with the reason
+	rec = httptest.NewRecorder()
+	handler.ListDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter?topic=scraping-tasks&limit=1&page=2", nil))
+	response.Messages = nil
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Messages) != 1 || response.Messages[0].Value != "not json" || response.Messages[0].PayloadError == "" || len(response.Messages[0].Payload) != 0 {
+		t.Errorf("messages = %+v, want msg-2 with its raw value", response.Messages)
+	}
+}
+
+// fakeSender records the messages sent through it
+type fakeSender struct {
+	sent []string // topic/key=value
+	err  error
+}
+
+func (s *fakeSender) SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error {
+	if s.err != nil {
+		return s.err
+	}
+	data, _ := json.Marshal(value)
+	s.sent = append(s.sent, topic+"/"+key+"="+string(data))
+	return nil
+}
+
+func TestDeadLetterExportRoundTrip(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := &memoryDeadLetters{deadLetters: []kafka.DeadLetter{
+		{ID: "msg-1", Topic: "scraping-tasks", Key: "k1", MessageType: "scraping_task", Value: []byte(`{"url":"https://example.com","attempt":1}`), FailedAt: time.Now()},
+		{ID: "msg-2", Topic: "raw", Key: "k2", Value: []byte{0xff, 0x01}},
+		{ID: "msg-3", Topic: "scraping-results", Value: []byte(`{}`)},
+	}}
+	handler := NewAdminHandler(logger, nil, nil, nil, store)
+
+	rec := httptest.NewRecorder()
+	handler.ExportDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter/export?older_than=soon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status with an invalid older_than = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ExportDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter/export?topic=scraping-results", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status = %d, content type = %q; want an NDJSON export", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if lines := strings.Count(rec.Body.String(), "\n"); lines != 1 {
+		t.Errorf("filtered export has %d lines, want 1:\n%s", lines, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ExportDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter/export", nil))
+	if lines := strings.Count(rec.Body.String(), "\n"); lines != 3 {
+		t.Fatalf("export has %d lines, want 3:\n%s", lines, rec.Body.String())
+	}
+	lines := strings.SplitAfter(rec.Body.String(), "\n")
+	if !strings.Contains(lines[1], `"value_base64":"/wE="`) {
+		t.Errorf("binary value exported as %s, want value_base64", lines[1])
+	}
+	records, err := parseDeadLetterImport(strings.NewReader(lines[0]))
+	if err != nil {
+		t.Fatalf("parseDeadLetterImport() error = %v", err)
+	}
+	if len(records) != 1 || records[0].ID != "msg-1" || string(records[0].value) != `{"url":"https://example.com","attempt":1}` {
+		t.Errorf("records = %+v, want the exported message", records)
+	}
+
+	// A store that fails before the first record still gets an error status
+	handler = NewAdminHandler(logger, nil, nil, nil, &failingDeadLetters{})
+	rec = httptest.NewRecorder()
+	handler.ExportDeadLetterMessages(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dead-letter/export", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status with a failing store = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+// failingDeadLetters is a repositories.DeadLetterRepository that cannot be read
+type failingDeadLetters struct {
+	memoryDeadLetters
+}
+
+func (*failingDeadLetters) ExportDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter, fn func(kafka.DeadLetter) error) error {
+	return errors.New("connection refused")
+}
+
+func TestParseDeadLetterImportRejectsInvalidRecords(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not json", "{\"topic\":\"a\",\"value\":{}}\nnope\n", "line 2: invalid record"},
+		{"missing topic", `{"value":{"a":1}}`, "line 1: topic is required"},
+		{"missing value", `{"topic":"scraping-tasks"}`, "line 1: value or value_base64 is required"},
+		{"bad base64", `{"topic":"scraping-tasks","value_base64":"%%%"}`, "line 1: value_base64 is not valid base64"},
+		{"binary value", `{"topic":"scraping-tasks","value_base64":"/wE="}`, "line 1: value is not JSON"},
+		{"mistyped payload", `{"topic":"scraping-tasks","message_type":"scraping_task","value":{"attempt":"two"}}`, "line 1: value is not a valid scraping_task message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDeadLetterImport(strings.NewReader(tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportDeadLetterMessages(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	sender := &fakeSender{}
+	handler := NewAdminHandler(logger, nil, nil, sender, nil)
+	body := `{"id":"msg-1","topic":"scraping-tasks","key":"k1","message_type":"scraping_task","value":{"attempt":2}}` + "\n" +
+		`{"id":"msg-2","topic":"raw","value_base64":"eyJhIjoxfQ=="}`
+
+	rec := httptest.NewRecorder()
+	handler.ImportDeadLetterMessages(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letter/import?dry_run=true", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || len(sender.sent) != 0 {
+		t.Fatalf("dry run: status = %d, sent = %v; want 200 and nothing sent", rec.Code, sender.sent)
+	}
+	var response models.DeadLetterImportResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.DryRun || response.Received != 2 {
+		t.Errorf("response = %+v, want a dry run of 2 records", response)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ImportDeadLetterMessages(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letter/import", strings.NewReader(body)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("replay without a store: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	// Replayed messages are removed from the dead letter queue
+	id := uuid.New().String()
+	store := &memoryDeadLetters{deadLetters: []kafka.DeadLetter{{ID: id, Topic: "scraping-tasks"}}}
+	handler = NewAdminHandler(logger, nil, nil, sender, store)
+	body = strings.Replace(body, `"msg-1"`, `"`+id+`"`, 1)
+	rec = httptest.NewRecorder()
+	handler.ImportDeadLetterMessages(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letter/import", strings.NewReader(body)))
+	response = models.DeadLetterImportResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Replayed != 2 || response.Removed != 1 || len(store.deadLetters) != 0 {
+		t.Errorf("response = %+v, stored = %d; want 2 replayed and the stored one removed", response, len(store.deadLetters))
+	}
+	if len(sender.sent) != 2 || sender.sent[0] != `scraping-tasks/k1={"attempt":2}` || sender.sent[1] != `raw/={"a":1}` {
+		t.Errorf("sent = %v, want both messages replayed as is", sender.sent)
+	}
+
+	sender.err = errors.New("broker unavailable")
+	rec = httptest.NewRecorder()
+	handler.ImportDeadLetterMessages(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dead-letter/import", strings.NewReader(body)))
+	response = models.DeadLetterImportResponse{}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.Failed != 2 || len(response.Errors) != 2 || response.Errors[1].Line != 2 {
+		t.Errorf("response = %+v, want the failed replays reported", response)
+	}
+}
+
+func TestPauseAndResumeConsumers(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	sender := &fakeSender{}
+	cfg := &config.Config{Kafka: config.KafkaConfig{Topics: config.TopicsConfig{ConsumerControl: "consumer-control"}}}
+	handler := NewAdminHandler(logger, config.NewWatcher(cfg, nil, logger), nil, sender, nil)
+
+	rec := httptest.NewRecorder()
+	body := `{"topic":"scraped-data","group":"parser-group","reason":"deploying parser fix"}`
+	handler.PauseConsumers(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/consumers/pause", strings.NewReader(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var response models.ConsumerControlResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Action != kafka.ConsumerActionPause || response.Topic != "scraped-data" || response.Group != "parser-group" {
+		t.Errorf("response = %+v, want a pause of scraped-data for parser-group", response)
+	}
+	if len(sender.sent) != 1 || !strings.HasPrefix(sender.sent[0], `consumer-control/parser-group/scraped-data={"action":"pause","topic":"scraped-data"`) {
+		t.Errorf("sent = %v, want the command on the control topic", sender.sent)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ResumeConsumers(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/consumers/resume", strings.NewReader(`{"topic":"*"}`)))
+	if rec.Code != http.StatusAccepted || len(sender.sent) != 2 || !strings.HasPrefix(sender.sent[1], `consumer-control//*={"action":"resume","topic":"*"`) {
+		t.Errorf("status = %d, sent = %v; want a resume of every topic", rec.Code, sender.sent)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.PauseConsumers(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/consumers/pause", strings.NewReader(`{"group":"parser-group"}`)))
+	if rec.Code != http.StatusBadRequest || len(sender.sent) != 2 {
+		t.Errorf("status = %d, want %d without a topic", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestConsumerCommandContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	sender := &fakeSender{}
+	cfg := &config.Config{Kafka: config.KafkaConfig{Topics: config.TopicsConfig{ConsumerControl: "consumer-control"}}}
+	handler := NewAdminHandler(logger, config.NewWatcher(cfg, nil, logger), nil, sender, nil)
+
+	body := `{"topic":"scraped-data","group":"parser-group","reason":"deploying parser fix"}`
+	handler.PauseConsumers(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/admin/consumers/pause", strings.NewReader(body)))
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d commands, want 1", len(sender.sent))
+	}
+
+	data := []byte(sender.sent[0][strings.Index(sender.sent[0], "=")+1:])
+	var command kafka.ConsumerCommand
+	if err := contract.DecodeStrict(data, &command); err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+	got, _ := contract.Keys(data)
+	want, _ := contract.Keys(contract.Fixture(contract.ConsumerCommand))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("command fields = %v, want those of the contract %v", got, want)
+	}
+}
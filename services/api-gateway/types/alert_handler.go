@@ -0,0 +1,116 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AlertHandler handles alert history HTTP requests for the web scraping system.
+// The system alert rules are configured in the alerts section of the URL
+// Manager configuration and evaluated there; this handler serves the events
+// recorded when a rule started firing or resolved.
+type AlertHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+}
+
+// NewAlertHandler creates a new alert handler with the provided logger and database queries.
+// This function initializes the handler with necessary dependencies.
+func NewAlertHandler(logger *logrus.Logger, db *database.Queries) *AlertHandler {
+	return &AlertHandler{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// ListAlerts handles GET /api/v1/admin/alerts
+//
+// Purpose: Lists the alert history, newest first. An event is recorded each
+// time a system alert rule (DLQ depth, success rate, consumer lag or no
+// scrapes) starts firing and each time it resolves.
+//
+// Query Parameters:
+//   - rule: Only list events of a rule
+//   - state: Only list firing or resolved events
+//   - page: Page number (default: 1)
+//   - limit: Events per page, max 100 (default: 20)
+//
+// Response: models.AlertEventsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/alerts
+//	GET /api/v1/admin/alerts?state=firing
+//	GET /api/v1/admin/alerts?rule=dead-letter-backlog&limit=50
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state != "" && state != sharedmodels.AlertStateFiring && state != sharedmodels.AlertStateResolved {
+		http.Error(w, "state must be firing or resolved", http.StatusBadRequest)
+		return
+	}
+	rule := query.Get("rule")
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	rows, err := h.DB.ListAlertEvents(r.Context(), database.ListAlertEventsParams{
+		Rule:   rule,
+		State:  state,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list alert events")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := h.DB.CountAlertEvents(r.Context(), database.CountAlertEventsParams{
+		Rule:  rule,
+		State: state,
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to count alert events")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.AlertEventsResponse{
+		Events: make([]models.AlertEventResponse, 0, len(rows)),
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	}
+	for _, row := range rows {
+		response.Events = append(response.Events, models.AlertEventResponse{
+			ID:        row.ID.String(),
+			Rule:      row.Rule,
+			Kind:      row.Kind,
+			State:     row.State,
+			Severity:  row.Severity,
+			Value:     row.Value,
+			Threshold: row.Threshold,
+			Message:   row.Message,
+			CreatedAt: row.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestListAlertsRejectsInvalidState(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewAlertHandler(logger, nil)
+
+	rec := httptest.NewRecorder()
+	handler.ListAlerts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/alerts?state=pending", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Groupings of the costs endpoint
+const (
+	CostGroupByURL     = "url"
+	CostGroupByProject = "project"
+)
+
+// maxCostURLs limits the URLs listed by one costs request
+const maxCostURLs = 1000
+
+// CostHandler handles scrape cost HTTP requests for the web scraping system.
+// It aggregates the proxy egress and headless render time reported with
+// scrape results, so teams can see which scraping configurations are expensive.
+type CostHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+}
+
+// NewCostHandler creates a new cost handler with the provided logger and database queries.
+// This function initializes the handler with necessary dependencies.
+func NewCostHandler(logger *logrus.Logger, db *database.Queries) *CostHandler {
+	return &CostHandler{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// GetCosts handles GET /api/v1/costs
+//
+// Purpose: Returns the costs of the scrapes completed in the period, per URL
+// or per project, together with the total. Costs are the bytes sent through
+// proxies and the time spent rendering pages in headless browsers, as
+// reported by the scrapers; failed attempts count too. The most expensive
+// URLs or projects, by proxy egress and then render time, come first.
+//
+// Query Parameters:
+//   - period: Time period (1h, 24h, 7d, 30d) - default: 24h
+//   - group_by: url or project - default: url
+//   - limit: Maximum number of URLs to list, max 1000 (default: 100)
+//
+// Response: models.CostsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/costs
+//	GET /api/v1/costs?group_by=project&period=30d
+func (h *CostHandler) GetCosts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	period := query.Get("period")
+	if period == "" {
+		period = "24h"
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		http.Error(w, "period must be one of 1h, 24h, 7d, 30d", http.StatusBadRequest)
+		return
+	}
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = CostGroupByURL
+	}
+	if groupBy != CostGroupByURL && groupBy != CostGroupByProject {
+		http.Error(w, "group_by must be url or project", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxCostURLs {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxCostURLs), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	since := time.Now().UTC().Add(-window)
+	projects, err := h.DB.ListProjectCosts(r.Context(), sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list project costs")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.CostsResponse{
+		Period:  period,
+		Since:   since.Format(time.RFC3339),
+		GroupBy: groupBy,
+		Total:   totalCost(projects),
+	}
+	if groupBy == CostGroupByProject {
+		response.Costs = make([]models.CostResponse, len(projects))
+		for i, row := range projects {
+			response.Costs[i] = costResponse(row.Scrapes, row.ProxyEgressBytes, row.RenderMs)
+			response.Costs[i].Project = row.Project
+		}
+	} else {
+		urls, err := h.DB.ListURLCosts(r.Context(), database.ListURLCostsParams{
+			Since:      sql.NullTime{Time: since, Valid: true},
+			MaxResults: int32(limit),
+		})
+		if err != nil {
+			h.Logger.WithError(err).Error("Failed to list URL costs")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Costs = make([]models.CostResponse, len(urls))
+		for i, row := range urls {
+			response.Costs[i] = costResponse(row.Scrapes, row.ProxyEgressBytes, row.RenderMs)
+			response.Costs[i].URLID = row.ID.String()
+			response.Costs[i].URL = row.Url
+			response.Costs[i].Project = row.Project
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// costResponse converts summed cost attributes to the response format
+func costResponse(scrapes, proxyEgressBytes, renderMs int64) models.CostResponse {
+	return models.CostResponse{
+		Scrapes:          scrapes,
+		ProxyEgressBytes: proxyEgressBytes,
+		RenderSeconds:    float64(renderMs) / 1000,
+	}
+}
+
+// totalCost sums the costs of all projects
+func totalCost(projects []database.ListProjectCostsRow) models.CostResponse {
+	var scrapes, proxyEgressBytes, renderMs int64
+	for _, row := range projects {
+		scrapes += row.Scrapes
+		proxyEgressBytes += row.ProxyEgressBytes
+		renderMs += row.RenderMs
+	}
+	return costResponse(scrapes, proxyEgressBytes, renderMs)
+}
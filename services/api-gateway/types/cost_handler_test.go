@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+)
+
+func TestTotalCost(t *testing.T) {
+	projects := []database.ListProjectCostsRow{
+		{Project: "growth", Scrapes: 40, ProxyEgressBytes: 3_000_000, RenderMs: 61_500},
+		{Project: "", Scrapes: 10, ProxyEgressBytes: 250_000, RenderMs: 0},
+	}
+
+	want := models.CostResponse{Scrapes: 50, ProxyEgressBytes: 3_250_000, RenderSeconds: 61.5}
+	if got := totalCost(projects); got != want {
+		t.Errorf("totalCost() = %+v, want %+v", got, want)
+	}
+	if got := totalCost(nil); got != (models.CostResponse{}) {
+		t.Errorf("totalCost(nil) = %+v, want zero costs", got)
+	}
+}
@@ -1,28 +1,78 @@
 package types
 
 import (
+	"database/sql"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	"go_scraping_project/shared/parser"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
 )
 
+// exportFields are the fields of a data record an export can be limited to
+var exportFields = []string{"id", "url_id", "url", "title", "content", "content_hash", "created_at"}
+
+// Limits for the records returned by one delta call
+const (
+	defaultDeltaLimit = 100
+	maxDeltaLimit     = 1000
+)
+
+// deltaSettleTime is how long a change waits before the delta endpoint
+// returns it. Change sequence numbers are taken before a transaction
+// commits, so without the wait a slow transaction could commit a change
+// behind a token that has already moved past it.
+const deltaSettleTime = 5 * time.Second
+
+// Limits for the groups returned by an aggregation
+const (
+	defaultAggregateGroups = 100
+	maxAggregateGroups     = 1000
+)
+
+// Limits of duplicate detection: the simhash distance under which contents
+// are duplicates, and the number of URLs compared
+const (
+	defaultDuplicateDistance = 3
+	maxDuplicateScan         = 20000
+)
+
+// syncTokenVersion prefixes sync tokens so their format can change later
+const syncTokenVersion = "v1:"
+
 // DataHandler handles data-related HTTP requests for the web scraping system.
 // It provides endpoints for retrieving and exporting scraped data with
 // filtering and pagination capabilities.
 type DataHandler struct {
-	Logger *logrus.Logger
+	Logger  *logrus.Logger
+	DB      *database.Queries   // sqlc-generated database queries
+	Keyring *encryption.Keyring // Decrypts sensitive fields, nil without encryption keys
 }
 
-// NewDataHandler creates a new data handler with the provided logger.
-// This function initializes the handler with necessary dependencies.
-func NewDataHandler(logger *logrus.Logger) *DataHandler {
+// NewDataHandler creates a new data handler with the provided logger, database queries
+// and encryption keyring. This function initializes the handler with necessary dependencies.
+func NewDataHandler(logger *logrus.Logger, db *database.Queries, keyring *encryption.Keyring) *DataHandler {
 	return &DataHandler{
-		Logger: logger,
+		Logger:  logger,
+		DB:      db,
+		Keyring: keyring,
 	}
 }
 
@@ -37,13 +87,16 @@ func NewDataHandler(logger *logrus.Logger) *DataHandler {
 //   - limit: Items per page, max 100 (default: 20)
 //   - schema: Filter by data schema (e.g., "article", "product")
 //   - url_id: Filter by specific URL ID
+//   - sort: created_at or url (default: created_at, newest first)
+//   - order: asc or desc (default: asc when sort is given)
 //
-// Response: models.ListDataResponse (200 OK) or error (500)
+// Response: models.ListDataResponse (200 OK) or error (400/500)
 //
 // Example Usage:
 //
 //	GET /api/v1/data?page=1&limit=20&schema=article
 //	GET /api/v1/data?url_id=url-123&page=1&limit=10
+//	GET /api/v1/data?sort=url&order=desc
 func (h *DataHandler) ListData(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
@@ -59,6 +112,12 @@ func (h *DataHandler) ListData(w http.ResponseWriter, r *http.Request) {
 	schema := r.URL.Query().Get("schema")
 	urlID := r.URL.Query().Get("url_id")
 
+	sort, err := parseListSort(r, listSort{Field: "created_at", Descending: true}, "created_at", "url")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	offset := (page - 1) * limit
 
 	// TODO: Get data from service
@@ -91,6 +150,7 @@ func (h *DataHandler) ListData(w http.ResponseWriter, r *http.Request) {
 	_ = offset
 	_ = schema
 	_ = urlID
+	_ = sort
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -159,27 +219,639 @@ func (h *DataHandler) GetDataByURL(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// GetDataRecord handles GET /api/v1/data/records/{id}
+//
+// Purpose: Retrieves a single parsed record by its ID. Every parse of a URL
+// is stored as its own record, so the ID refers to one specific extraction
+// and keeps returning the same data after the URL is parsed again.
+//
+// Path Parameters:
+//   - id: Record identifier (required)
+//
+// Response: models.DataRecord (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/records/123e4567-e89b-12d3-a456-426614174000
+func (h *DataHandler) GetDataRecord(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid record ID", http.StatusBadRequest)
+		return
+	}
+
+	row, err := h.DB.GetParsedData(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Record not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to get data record")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	record, err := dataRecord(row, h.Keyring)
+	if err != nil {
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to decrypt data record")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// ListDataVersions handles GET /api/v1/data/records/{id}/versions
+//
+// Purpose: Lists the versions of a parsed record, that is every parse of the
+// record's URL under the record's schema, including the record itself. This
+// is useful for auditing how the data extracted from a page changed over time.
+//
+// Path Parameters:
+//   - id: Record identifier (required)
+//
+// Query Parameters:
+//   - page: Page number (default: 1)
+//   - limit: Versions per page, max 100 (default: 20)
+//
+// Response: models.DataVersionsResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/records/123e4567-e89b-12d3-a456-426614174000/versions?limit=50
+func (h *DataHandler) ListDataVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid record ID", http.StatusBadRequest)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	record, err := h.DB.GetParsedData(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Record not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to get data record")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := h.DB.ListParsedDataVersions(r.Context(), database.ListParsedDataVersionsParams{
+		UrlID:  record.UrlID,
+		Schema: record.Schema,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to list data versions")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	total, err := h.DB.CountParsedDataVersions(r.Context(), database.CountParsedDataVersionsParams{
+		UrlID:  record.UrlID,
+		Schema: record.Schema,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to count data versions")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.DataVersionsResponse{
+		RecordID: record.ID.String(),
+		URLID:    record.UrlID.String(),
+		Schema:   record.Schema,
+		Versions: make([]models.DataRecord, 0, len(rows)),
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	}
+	for _, row := range rows {
+		version, err := dataRecord(row, h.Keyring)
+		if err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to decrypt data record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Versions = append(response.Versions, version)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DiffDataText handles GET /api/v1/data/records/{id}/text-diff
+//
+// Purpose: Lists the lines of normalized visible text added and removed
+// between a parsed record and an earlier one, by default the previous version
+// of the record's URL and schema. Changes to markup alone, such as class
+// names or scripts, do not show. Both records must have been parsed with the
+// extract_text option.
+//
+// Path Parameters:
+//   - id: Record identifier (required)
+//
+// Query Parameters:
+//   - against: Identifier of the record to compare against (default: the previous version)
+//
+// Response: models.TextDiffResponse (200 OK) or error (400/404/409/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/records/123e4567-e89b-12d3-a456-426614174000/text-diff
+func (h *DataHandler) DiffDataText(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid record ID", http.StatusBadRequest)
+		return
+	}
+	var againstID uuid.UUID
+	if raw := r.URL.Query().Get("against"); raw != "" {
+		if againstID, err = uuid.Parse(raw); err != nil {
+			http.Error(w, "Invalid against record ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	record, err := h.DB.GetParsedData(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Record not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to get data record")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	var against database.ParsedDatum
+	if againstID != uuid.Nil {
+		against, err = h.DB.GetParsedData(r.Context(), againstID)
+	} else {
+		against, err = h.DB.GetPreviousParsedData(r.Context(), database.GetPreviousParsedDataParams{
+			UrlID:  record.UrlID,
+			Schema: record.Schema,
+			Before: record.CreatedAt,
+		})
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Record to compare against not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("record_id", id).Error("Failed to get data record to compare against")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if record.NormalizedText == "" || against.NormalizedText == "" {
+		http.Error(w, "Records have no normalized text; enable extract_text in the parser config", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(textDiff(against, record))
+}
+
+// textDiff returns the normalized text diff from an older record to a newer one
+func textDiff(old, new database.ParsedDatum) models.TextDiffResponse {
+	response := models.TextDiffResponse{
+		RecordID:  new.ID.String(),
+		AgainstID: old.ID.String(),
+		Changes:   parser.DiffText(old.NormalizedText, new.NormalizedText),
+	}
+	for _, change := range response.Changes {
+		if change.Op == parser.TextAdded {
+			response.Added++
+		} else {
+			response.Removed++
+		}
+	}
+	return response
+}
+
+// AggregateData handles GET /api/v1/data/aggregate
+//
+// Purpose: Groups parsed records by a field of their parsed data and
+// computes count, min, max and average of another field per group, such as
+// the average price per category across product pages, so simple analytics
+// don't require a full export. Only the latest parse of each URL is
+// counted. Nested fields are addressed with dots, e.g. "offer.price".
+// Values that are not JSON numbers are left out of min, max and avg.
+//
+// Query Parameters:
+//   - group_by: Parsed field to group by (required)
+//   - field: Numeric parsed field to aggregate (optional, count only without it)
+//   - schema: Filter by data schema (optional)
+//   - limit: Maximum number of groups, max 1000 (default: 100)
+//
+// Response: models.DataAggregateResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/aggregate?schema=product&group_by=category&field=price
+func (h *DataHandler) AggregateData(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	groupPath, err := parseFieldPath("group_by", query.Get("group_by"))
+	if err == nil && len(groupPath) == 0 {
+		err = &models.ValidationError{Field: "group_by", Message: "group_by is required"}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	valuePath, err := parseFieldPath("field", query.Get("field"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > maxAggregateGroups {
+		limit = defaultAggregateGroups
+	}
+
+	rows, err := h.DB.AggregateParsedData(r.Context(), database.AggregateParsedDataParams{
+		GroupPath:  groupPath,
+		ValuePath:  valuePath,
+		Schema:     query.Get("schema"),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("group_by", query.Get("group_by")).Error("Failed to aggregate data")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.DataAggregateResponse{
+		Schema:  query.Get("schema"),
+		GroupBy: query.Get("group_by"),
+		Field:   query.Get("field"),
+		Groups:  make([]models.DataAggregateGroup, 0, len(rows)),
+	}
+	for _, row := range rows {
+		group := models.DataAggregateGroup{
+			Value:      row.GroupValue,
+			Count:      row.Count,
+			ValueCount: row.ValueCount,
+		}
+		if row.ValueCount > 0 {
+			group.Min, group.Max, group.Avg = &row.MinValue, &row.MaxValue, &row.AvgValue
+		}
+		response.Groups = append(response.Groups, group)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// FindDuplicates handles GET /api/v1/data/duplicates
+//
+// Purpose: Finds distinct URLs serving essentially the same content, such as
+// mirrors, tracking-parameter variants or print versions of a page, to help
+// clean up redundant monitors. The latest parse of each URL is compared by
+// the simhash of its content (its normalized text when extract_text is set,
+// else its title and content); contents whose hashes differ in at most
+// max_distance of their 64 bits are duplicates. Parses made before simhashes
+// were stored are compared once their URL is reparsed.
+//
+// Query Parameters:
+//   - schema: Filter by data schema (optional)
+//   - max_distance: Largest simhash distance, 0 for identical content, max 8 (default: 3)
+//   - limit: Maximum number of groups, max 1000 (default: 100)
+//
+// Response: models.DuplicatesResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/duplicates?schema=article&max_distance=2
+func (h *DataHandler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	maxDistance := defaultDuplicateDistance
+	if raw := query.Get("max_distance"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 || parsed > parser.MaxSimhashDistance {
+			http.Error(w, fmt.Sprintf("max_distance must be an integer from 0 to %d", parser.MaxSimhashDistance), http.StatusBadRequest)
+			return
+		}
+		maxDistance = parsed
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > maxAggregateGroups {
+		limit = defaultAggregateGroups
+	}
+
+	rows, err := h.DB.ListParsedDataSimhashes(r.Context(), database.ListParsedDataSimhashesParams{
+		Schema:     query.Get("schema"),
+		MaxResults: maxDuplicateScan,
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list content simhashes")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	groups := duplicateGroups(rows, maxDistance)
+	response := models.DuplicatesResponse{
+		Schema:      query.Get("schema"),
+		MaxDistance: maxDistance,
+		Scanned:     len(rows),
+		Truncated:   len(rows) == maxDuplicateScan,
+		Total:       len(groups),
+		Groups:      groups[:min(len(groups), limit)],
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// duplicateGroups groups records whose simhashes are within maxDistance of
+// each other, keeping the groups of at least two distinct URLs. Each record's
+// distance is measured from the group's first record.
+func duplicateGroups(rows []database.ListParsedDataSimhashesRow, maxDistance int) []models.DuplicateGroup {
+	hashes := make([]uint64, len(rows))
+	for i, row := range rows {
+		hashes[i] = uint64(row.Simhash)
+	}
+
+	groups := []models.DuplicateGroup{}
+	for _, members := range parser.GroupSimilar(hashes, maxDistance) {
+		urls := make(map[uuid.UUID]bool)
+		group := models.DuplicateGroup{Records: make([]models.DuplicateRecord, 0, len(members))}
+		for _, i := range members {
+			urls[rows[i].UrlID] = true
+			group.Records = append(group.Records, models.DuplicateRecord{
+				RecordID: rows[i].ID.String(),
+				URLID:    rows[i].UrlID.String(),
+				URL:      rows[i].Url,
+				Schema:   rows[i].Schema,
+				Distance: parser.SimhashDistance(hashes[members[0]], hashes[i]),
+			})
+		}
+		if len(urls) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// GetDataQuality handles GET /api/v1/data/quality
+//
+// Purpose: Shows how completely parser configs extract their fields, to find
+// selectors that broke on part of the pages. A selector or rule that fails
+// on a page no longer fails the whole parse: the record is stored with the
+// fields that were extracted and an error for each failed field. For every
+// field this counts, over the latest parse of each URL, the records it was
+// extracted from and those it failed on. Parses made before field errors
+// were recorded are counted once their URL is reparsed.
+//
+// Query Parameters:
+//   - schema: Filter by data schema (optional)
+//
+// Response: models.DataQualityResponse (200 OK) or error (500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/quality?schema=product
+func (h *DataHandler) GetDataQuality(w http.ResponseWriter, r *http.Request) {
+	schema := r.URL.Query().Get("schema")
+	rows, err := h.DB.GetParsedDataFieldCompleteness(r.Context(), schema)
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to count field completeness")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.DataQualityResponse{Schema: schema, Fields: fieldCompleteness(rows)})
+}
+
+// fieldCompleteness computes the completeness percentage of each field,
+// least complete first
+func fieldCompleteness(rows []database.GetParsedDataFieldCompletenessRow) []models.FieldCompleteness {
+	fields := make([]models.FieldCompleteness, 0, len(rows))
+	for _, row := range rows {
+		field := models.FieldCompleteness{
+			Field:       row.Field,
+			Extracted:   row.Extracted,
+			Failed:      row.Failed,
+			SampleError: row.SampleError,
+		}
+		if total := row.Extracted + row.Failed; total > 0 {
+			field.Completeness = math.Round(float64(row.Extracted)/float64(total)*1000) / 10
+		}
+		fields = append(fields, field)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Completeness < fields[j].Completeness
+	})
+	return fields
+}
+
+// parseFieldPath splits a dotted parsed field name into its path, returning
+// nil for an empty name
+func parseFieldPath(param, raw string) ([]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	path := strings.Split(raw, ".")
+	if slices.Contains(path, "") {
+		return nil, &models.ValidationError{Field: param, Message: "Invalid field name: " + raw}
+	}
+	return path, nil
+}
+
+// GetDataDelta handles GET /api/v1/data/delta
+//
+// Purpose: Returns the parsed records created or updated since a sync token,
+// together with a new token, so customer systems can replicate parsed data
+// incrementally instead of exporting everything on every run. Call without a
+// token to start from the beginning, store next_token and pass it on the next
+// call; while has_more is set, more changes are waiting. A record changed
+// several times between calls is returned once, in its latest version.
+// Changes are returned a few seconds after they are made.
+//
+// Query Parameters:
+//   - token: Sync token from a previous call (optional, opaque)
+//   - schema: Filter by data schema (optional)
+//   - limit: Maximum number of records, max 1000 (default: 100)
+//
+// Response: models.DataDeltaResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/delta?schema=product
+//	GET /api/v1/data/delta?token=djE6NDI&limit=500
+func (h *DataHandler) GetDataDelta(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var afterSeq int64
+	if token := query.Get("token"); token != "" {
+		seq, err := decodeSyncToken(token)
+		if err != nil {
+			http.Error(w, "Invalid sync token", http.StatusBadRequest)
+			return
+		}
+		afterSeq = seq
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 || limit > maxDeltaLimit {
+		limit = defaultDeltaLimit
+	}
+
+	// One extra record tells whether more changes are waiting
+	rows, err := h.DB.ListParsedDataChanges(r.Context(), database.ListParsedDataChangesParams{
+		AfterSeq:      afterSeq,
+		SettledBefore: time.Now().Add(-deltaSettleTime),
+		Schema:        query.Get("schema"),
+		MaxResults:    int32(limit + 1),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("after_seq", afterSeq).Error("Failed to list changed data")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.DataDeltaResponse{
+		Data:    make([]models.DataRecord, 0, min(len(rows), limit)),
+		HasMore: len(rows) > limit,
+	}
+	for _, row := range rows[:min(len(rows), limit)] {
+		record, err := dataRecord(row, h.Keyring)
+		if err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to decrypt data record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Data = append(response.Data, record)
+		afterSeq = row.ChangeSeq
+	}
+	response.NextToken = encodeSyncToken(afterSeq)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// dataRecord converts a stored parsed record into its response, decrypting
+// its encrypted fields
+func dataRecord(row database.ParsedDatum, keyring *encryption.Keyring) (models.DataRecord, error) {
+	data, err := openData(row, keyring)
+	if err != nil {
+		return models.DataRecord{}, err
+	}
+	return models.DataRecord{
+		ID:          row.ID.String(),
+		URLID:       row.UrlID.String(),
+		URL:         row.Url,
+		Schema:      row.Schema,
+		Title:       row.Title,
+		Content:     row.Content,
+		Metadata:    row.Metadata,
+		Data:        data,
+		Text:        row.NormalizedText,
+		FieldErrors: fieldErrors(row.FieldErrors),
+		ContentHash: row.ContentHash,
+		CreatedAt:   row.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   row.UpdatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// openData returns the data of a stored parsed record with its encrypted
+// fields decrypted
+func openData(row database.ParsedDatum, keyring *encryption.Keyring) (json.RawMessage, error) {
+	if row.EncryptionKeyID == "" {
+		return row.Data, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(row.Data, &data); err != nil {
+		return nil, err
+	}
+	if err := keyring.OpenFields(data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// fieldErrors decodes the field errors stored with a parsed record, nil for
+// records without any
+func fieldErrors(raw pqtype.NullRawMessage) map[string]string {
+	var fields map[string]string
+	if raw.Valid {
+		json.Unmarshal(raw.RawMessage, &fields)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// encodeSyncToken returns the sync token that resumes after a change sequence number
+func encodeSyncToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenVersion + strconv.FormatInt(seq, 10)))
+}
+
+// decodeSyncToken returns the change sequence number a sync token resumes after
+func decodeSyncToken(token string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := strings.CutPrefix(string(raw), syncTokenVersion)
+	if !ok {
+		return 0, errors.New("unknown sync token version")
+	}
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("invalid sync token position %q", value)
+	}
+	return seq, nil
+}
+
 // ExportData handles GET /api/v1/data/export
 //
 // Purpose: Exports scraped data in various formats (JSON, CSV, XML) for
 // external analysis, reporting, or integration with other systems. This
 // endpoint supports comprehensive filtering and can handle large datasets
-// efficiently.
+// efficiently. Consumers that export on a schedule can ask for changed
+// records only and for the fields they use, instead of downloading identical
+// records on every run.
 //
 // Query Parameters:
 //   - format: Export format (json, csv, xml) - default: json
 //   - url_ids: Comma-separated list of URL IDs to filter by
 //   - schema: Filter by data schema
-//   - from: Start date (ISO 8601)
-//   - to: End date (ISO 8601)
-//   - limit: Maximum number of records to export (default: 1000)
+//   - from: Only records scraped at or after this date or time (RFC 3339)
+//   - to: Only records scraped before this date or time (RFC 3339)
+//   - limit: Maximum number of records to export, max 10000 (default: 1000)
+//   - changed_since: Only records scraped at or after this time (RFC 3339) whose
+//     content_hash differs from the previous scrape of the same URL under the
+//     same schema; a URL's first scrape counts as changed
+//   - fields: Comma-separated fields to include (id, url_id, url, title, content,
+//     content_hash, created_at) - default: all
 //
 // Response: Exported data in requested format (200 OK) or error (400/500)
 //
 // Example Usage:
 //
 //	GET /api/v1/data/export?format=csv&schema=article&from=2024-01-01
-//	GET /api/v1/data/export?format=json&url_ids=url-123,url-456&limit=500
+//	GET /api/v1/data/export?format=json&url_ids=123e4567-e89b-12d3-a456-426614174000&limit=500
+//	GET /api/v1/data/export?changed_since=2024-01-01T00:00:00Z&fields=url,title,content_hash
 func (h *DataHandler) ExportData(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	format := r.URL.Query().Get("format")
@@ -193,49 +865,44 @@ func (h *DataHandler) ExportData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	urlIDs := r.URL.Query().Get("url_ids")
-	schema := r.URL.Query().Get("schema")
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
+	params, err := exportDataParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit <= 0 || limit > 10000 {
-		limit = 1000
+	fields, err := h.parseExportFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// TODO: Export data using service
-	// var data []*domain.ParsedData
-	// var err error
-	//
-	// filters := &domain.DataFilters{
-	//     URLIDs: parseCommaSeparated(urlIDs),
-	//     Schema: schema,
-	//     From:   from,
-	//     To:     to,
-	//     Limit:  limit,
-	// }
-	//
-	// data, err = h.dataService.ExportData(r.Context(), filters)
-	// if err != nil {
-	//     h.Logger.WithError(err).Error("Failed to export data")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	rows, err := h.DB.ExportParsedData(r.Context(), params)
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to export data")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := make([]models.DataItem, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, models.DataItem{
+			ID:          row.ID.String(),
+			URLID:       row.UrlID.String(),
+			URL:         row.Url,
+			Title:       row.Title,
+			Content:     row.Content,
+			ContentHash: row.ContentHash,
+			CreatedAt:   row.CreatedAt.Format(time.RFC3339),
+		})
+	}
 
-	// For now, return mock data
 	response := map[string]interface{}{
 		"format": format,
-		"count":  0,
-		"data":   []interface{}{},
+		"count":  len(data),
+		"data":   projectFields(data, fields),
 	}
 
-	// Use variables to avoid unused variable warnings
-	_ = urlIDs
-	_ = schema
-	_ = from
-	_ = to
-	_ = limit
-
 	// Set appropriate content type based on format
 	switch format {
 	case "json":
@@ -251,11 +918,266 @@ func (h *DataHandler) ExportData(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// parseCommaSeparated parses a comma-separated string into a slice of strings
+// PurgeData handles DELETE /api/v1/data
+//
+// Purpose: Hard-deletes the parsed and raw data matching a filter, e.g. to
+// carry out a data subject deletion request. The purge runs asynchronously
+// in the URL Manager: the response is the pending job, whose Location is
+// polled for the completion report with the number of rows deleted. Raw
+// HTML snapshots, their render sessions and HAR captures are deleted with
+// the parsed records; all of them are stored in PostgreSQL, so no other
+// storage needs to be cleaned up. The URLs themselves are kept.
+//
+// Query Parameters:
+//   - url_id: Only data of this URL
+//   - schema: Only parsed records of this schema, and the raw data of the
+//     URLs that have records of it
+//   - from: Only data created at or after this time (RFC 3339)
+//   - to: Only data created before this time (RFC 3339)
+//
+// At least one of url_id and schema is required, so a date range alone
+// cannot delete the data of every URL.
+//
+// Headers:
+//   - X-Author: Who requested the purge, recorded with the job
+//
+// Response: models.DataPurgeJobResponse (202 Accepted) or error (400/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/data?url_id=123e4567-e89b-12d3-a456-426614174000
+//	DELETE /api/v1/data?schema=profile&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z
+func (h *DataHandler) PurgeData(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePurgeFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params.RequestedBy = strings.TrimSpace(r.Header.Get(authorHeader))
+	if len(params.RequestedBy) > 200 {
+		params.RequestedBy = params.RequestedBy[:200]
+	}
+
+	job, err := h.DB.CreateDataPurgeJob(r.Context(), params)
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to create data purge job")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.Logger.WithFields(logrus.Fields{
+		"job_id":       job.ID,
+		"requested_by": job.RequestedBy,
+	}).Info("Data purge requested")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/data/purges/"+job.ID.String())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(purgeJobResponse(job))
+}
+
+// GetPurgeJob handles GET /api/v1/data/purges/{id}
+//
+// Purpose: Returns a data purge job: its filter, its status (pending,
+// running, completed or failed) and the number of rows deleted so far.
+// Once completed, this is the report of what the purge deleted.
+//
+// Path Parameters:
+//   - id: Purge job identifier (required)
+//
+// Response: models.DataPurgeJobResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/purges/5f0c8a2e-7b1d-4c3e-9a4f-2d6e8b0c1a3f
+func (h *DataHandler) GetPurgeJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid purge job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.DB.GetDataPurgeJob(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Purge job not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("job_id", id).Error("Failed to get data purge job")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(purgeJobResponse(job))
+}
+
+// parsePurgeFilter reads the filter of a purge from the query parameters
+func parsePurgeFilter(query url.Values) (database.CreateDataPurgeJobParams, error) {
+	var params database.CreateDataPurgeJobParams
+	if raw := query.Get("url_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return params, errors.New("invalid url_id")
+		}
+		params.UrlID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	params.Schema = strings.TrimSpace(query.Get("schema"))
+	if !params.UrlID.Valid && params.Schema == "" {
+		return params, errors.New("url_id or schema is required")
+	}
+
+	bounds := []struct {
+		name string
+		time *sql.NullTime
+	}{{"from", &params.FromTime}, {"to", &params.ToTime}}
+	for _, bound := range bounds {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return params, fmt.Errorf("invalid %s, use an RFC 3339 time such as 2024-01-01T00:00:00Z", bound.name)
+		}
+		*bound.time = sql.NullTime{Time: t, Valid: true}
+	}
+	if params.FromTime.Valid && params.ToTime.Valid && !params.FromTime.Time.Before(params.ToTime.Time) {
+		return params, errors.New("from must be before to")
+	}
+	return params, nil
+}
+
+// purgeJobResponse converts a stored data purge job into its response
+func purgeJobResponse(job database.DataPurgeJob) models.DataPurgeJobResponse {
+	response := models.DataPurgeJobResponse{
+		ID:          job.ID.String(),
+		Status:      job.Status,
+		Schema:      job.Schema,
+		RequestedBy: job.RequestedBy,
+		Deleted: models.DataPurgeCounts{
+			ParsedData:  job.ParsedDataDeleted,
+			RawHTML:     job.RawHtmlDeleted,
+			HARCaptures: job.HarCapturesDeleted,
+		},
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.UrlID.Valid {
+		response.URLID = job.UrlID.UUID.String()
+	}
+	if job.FromTime.Valid {
+		response.From = job.FromTime.Time.Format(time.RFC3339)
+	}
+	if job.ToTime.Valid {
+		response.To = job.ToTime.Time.Format(time.RFC3339)
+	}
+	if job.CompletedAt.Valid {
+		response.CompletedAt = job.CompletedAt.Time.Format(time.RFC3339)
+	}
+	return response
+}
+
+// parseCommaSeparated parses a comma-separated string into a slice of
+// strings, trimming spaces and dropping empty entries
 func (h *DataHandler) parseCommaSeparated(s string) []string {
-	if s == "" {
-		return nil
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseExportFields parses the fields query parameter of an export. It
+// returns nil, meaning every field, when no fields are given.
+func (h *DataHandler) parseExportFields(raw string) ([]string, error) {
+	var fields []string
+	for _, field := range h.parseCommaSeparated(raw) {
+		if !slices.Contains(exportFields, field) {
+			return nil, &models.ValidationError{Field: "fields", Message: fmt.Sprintf("Invalid field %q. Supported fields: %s", field, strings.Join(exportFields, ", "))}
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// exportDataParams parses the filters of a data export. from and to take an
+// RFC 3339 time or a date; changed_since must be an RFC 3339 time.
+func exportDataParams(query url.Values) (database.ExportParsedDataParams, error) {
+	params := database.ExportParsedDataParams{
+		Schema:     strings.TrimSpace(query.Get("schema")),
+		MaxResults: 1000,
+	}
+	if limit, _ := strconv.Atoi(query.Get("limit")); limit > 0 && limit <= 10000 {
+		params.MaxResults = int32(limit)
+	}
+
+	for _, raw := range strings.Split(query.Get("url_ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return params, fmt.Errorf("invalid URL ID %q in url_ids", raw)
+		}
+		params.UrlIds = append(params.UrlIds, id)
+	}
+
+	bounds := []struct {
+		name string
+		time *sql.NullTime
+	}{{"from", &params.FromTime}, {"to", &params.ToTime}}
+	for _, bound := range bounds {
+		raw := query.Get(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, raw); err != nil {
+				return params, fmt.Errorf("invalid %s, use a date or an RFC 3339 time such as 2024-01-01T00:00:00Z", bound.name)
+			}
+		}
+		*bound.time = sql.NullTime{Time: t, Valid: true}
+	}
+
+	if raw := query.Get("changed_since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return params, errors.New("invalid changed_since, use an RFC 3339 time such as 2024-01-01T00:00:00Z")
+		}
+		params.ChangedSince = sql.NullTime{Time: t, Valid: true}
+	}
+	return params, nil
+}
+
+// projectFields limits records to the given fields, or returns them whole
+// when fields is empty
+func projectFields(items []models.DataItem, fields []string) interface{} {
+	if len(fields) == 0 {
+		return items
+	}
+
+	projected := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		values := map[string]string{
+			"id":           item.ID,
+			"url_id":       item.URLID,
+			"url":          item.URL,
+			"title":        item.Title,
+			"content":      item.Content,
+			"content_hash": item.ContentHash,
+			"created_at":   item.CreatedAt,
+		}
+		record := make(map[string]string, len(fields))
+		for _, field := range fields {
+			record[field] = values[field]
+		}
+		projected = append(projected, record)
 	}
-	// TODO: Implement proper parsing
-	return []string{}
+	return projected
 }
@@ -0,0 +1,344 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+func TestExportDataParams(t *testing.T) {
+	id := uuid.New()
+	params, err := exportDataParams(url.Values{
+		"url_ids":       {id.String() + ", "},
+		"schema":        {" article "},
+		"from":          {"2024-01-01"},
+		"to":            {"2024-02-01T00:00:00Z"},
+		"changed_since": {"2024-01-15T00:00:00Z"},
+		"limit":         {"500"},
+	})
+	if err != nil {
+		t.Fatalf("exportDataParams() error = %v", err)
+	}
+	if len(params.UrlIds) != 1 || params.UrlIds[0] != id || params.Schema != "article" || params.MaxResults != 500 {
+		t.Errorf("exportDataParams() = %+v", params)
+	}
+	if !params.FromTime.Time.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !params.ToTime.Valid {
+		t.Errorf("exportDataParams() range = %v to %v", params.FromTime, params.ToTime)
+	}
+	if want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC); !params.ChangedSince.Valid || !params.ChangedSince.Time.Equal(want) {
+		t.Errorf("exportDataParams() changed_since = %v, want %v", params.ChangedSince, want)
+	}
+
+	params, err = exportDataParams(url.Values{"limit": {"50000"}})
+	if err != nil || params.MaxResults != 1000 || params.ChangedSince.Valid || params.UrlIds != nil {
+		t.Errorf("exportDataParams() defaults = %+v, %v", params, err)
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	items := []models.DataItem{{ID: "1", URL: "https://example.com", Title: "Example", Content: "body"}}
+
+	got := projectFields(items, []string{"url", "title"})
+	want := []map[string]string{{"url": "https://example.com", "title": "Example"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("projectFields() = %v, want %v", got, want)
+	}
+	if got := projectFields(items, nil); !reflect.DeepEqual(got, items) {
+		t.Errorf("projectFields() without fields = %v, want the records", got)
+	}
+}
+
+func TestExportDataValidatesFilters(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDataHandler(logger, nil, nil)
+
+	for _, query := range []string{
+		"changed_since=yesterday",
+		"fields=url,secret",
+		"url_ids=url-123",
+		"from=last%20week",
+		"format=pdf",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ExportData(rec, httptest.NewRequest(http.MethodGet, "/api/v1/data/export?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("ExportData(%q) status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	fields, err := handler.parseExportFields("url, content_hash,url")
+	if err != nil || !reflect.DeepEqual(fields, []string{"url", "content_hash"}) {
+		t.Errorf("parseExportFields() = %v, %v", fields, err)
+	}
+}
+
+func TestSyncToken(t *testing.T) {
+	for _, seq := range []int64{0, 42, 1 << 40} {
+		got, err := decodeSyncToken(encodeSyncToken(seq))
+		if err != nil || got != seq {
+			t.Errorf("decodeSyncToken(encodeSyncToken(%d)) = %d, %v", seq, got, err)
+		}
+	}
+	for _, token := range []string{"not base64!", "djI6NDI", "djE6LTE", "djE6YWJj"} {
+		if _, err := decodeSyncToken(token); err == nil {
+			t.Errorf("decodeSyncToken(%q) succeeded, want an error", token)
+		}
+	}
+}
+
+func TestGetDataDeltaRejectsInvalidToken(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDataHandler(logger, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.GetDataDelta(rec, httptest.NewRequest(http.MethodGet, "/api/v1/data/delta?token=garbage", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDataRecordRejectsInvalidID(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDataHandler(logger, nil, nil)
+
+	for path, serve := range map[string]http.HandlerFunc{
+		"/api/v1/data/records/not-a-uuid":           handler.GetDataRecord,
+		"/api/v1/data/records/not-a-uuid/versions":  handler.ListDataVersions,
+		"/api/v1/data/records/not-a-uuid/text-diff": handler.DiffDataText,
+	} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, path, nil), map[string]string{"id": "not-a-uuid"})
+		rec := httptest.NewRecorder()
+		serve(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestTextDiff(t *testing.T) {
+	old := database.ParsedDatum{ID: uuid.New(), NormalizedText: "Steel kettle\nPrice: 24.90"}
+	new := database.ParsedDatum{ID: uuid.New(), NormalizedText: "Steel kettle\nPrice: 19.90\nFree delivery"}
+
+	got := textDiff(old, new)
+	if got.RecordID != new.ID.String() || got.AgainstID != old.ID.String() || got.Added != 2 || got.Removed != 1 || len(got.Changes) != 3 {
+		t.Errorf("textDiff() = %+v, want 2 added and 1 removed lines", got)
+	}
+}
+
+func TestParsedContentHashUsesText(t *testing.T) {
+	markup := parsedContentHash("Kettle", "<b>Boils</b>", "Kettle\nBoils", []byte(`{}`), []byte(`{"class":"a"}`))
+	restyled := parsedContentHash("Kettle", "<em>Boils</em>", "Kettle\nBoils", []byte(`{}`), []byte(`{"class":"b"}`))
+	if markup != restyled {
+		t.Error("records with the same normalized text hash differently")
+	}
+	if parsedContentHash("Kettle", "<b>Boils</b>", "", nil, nil) == parsedContentHash("Kettle", "<em>Boils</em>", "", nil, nil) {
+		t.Error("records without normalized text hash the same despite different content")
+	}
+}
+
+func TestDuplicateGroups(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	rows := []database.ListParsedDataSimhashesRow{
+		{ID: uuid.New(), UrlID: a, Url: "https://example.com/story", Simhash: 0x7FFF000000000000},
+		{ID: uuid.New(), UrlID: b, Url: "https://example.com/story?print=1", Simhash: 0x7FFF000000000003},
+		{ID: uuid.New(), UrlID: uuid.New(), Url: "https://example.com/other", Simhash: 0x00000000FFFFFFFF},
+		// The same URL under two schemas is not a duplicate of itself
+		{ID: uuid.New(), UrlID: a, Url: "https://example.com/sale", Schema: "product", Simhash: -1},
+		{ID: uuid.New(), UrlID: a, Url: "https://example.com/sale", Schema: "article", Simhash: -1},
+	}
+
+	groups := duplicateGroups(rows, 3)
+	if len(groups) != 1 || len(groups[0].Records) != 2 {
+		t.Fatalf("duplicateGroups() = %+v, want one group of two", groups)
+	}
+	if got := groups[0].Records[1]; got.URLID != b.String() || got.Distance != 2 {
+		t.Errorf("second record = %+v, want URL %s at distance 2", got, b)
+	}
+	if groups := duplicateGroups(rows, 1); len(groups) != 0 {
+		t.Errorf("duplicateGroups() within 1 bit = %+v, want none", groups)
+	}
+}
+
+func TestFindDuplicatesValidatesDistance(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDataHandler(logger, nil, nil)
+
+	for _, distance := range []string{"-1", "9", "near"} {
+		rec := httptest.NewRecorder()
+		handler.FindDuplicates(rec, httptest.NewRequest(http.MethodGet, "/api/v1/data/duplicates?max_distance="+distance, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("max_distance=%s status = %d, want %d", distance, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestFieldCompleteness(t *testing.T) {
+	rows := []database.GetParsedDataFieldCompletenessRow{
+		{Field: "price", Extracted: 2, Failed: 1, SampleError: `selector ".price" matches nothing`},
+		{Field: "sku", Extracted: 0, Failed: 4, SampleError: `selector ".sku" matches nothing`},
+		{Field: "title", Extracted: 3},
+	}
+
+	fields := fieldCompleteness(rows)
+	var order []string
+	for _, field := range fields {
+		order = append(order, field.Field)
+	}
+	if !reflect.DeepEqual(order, []string{"sku", "price", "title"}) {
+		t.Fatalf("fields = %v, want least complete first", order)
+	}
+	if fields[0].Completeness != 0 || fields[1].Completeness != 66.7 || fields[2].Completeness != 100 {
+		t.Errorf("completeness = %v, %v, %v; want 0, 66.7 and 100", fields[0].Completeness, fields[1].Completeness, fields[2].Completeness)
+	}
+}
+
+func TestDataRecordFieldErrors(t *testing.T) {
+	record, _ := dataRecord(database.ParsedDatum{FieldErrors: pqtype.NullRawMessage{RawMessage: []byte(`{"price": "selector \".price\" matches nothing"}`), Valid: true}}, nil)
+	if record.FieldErrors["price"] != `selector ".price" matches nothing` {
+		t.Errorf("field errors = %v", record.FieldErrors)
+	}
+	for _, raw := range []pqtype.NullRawMessage{{}, {RawMessage: []byte(`{}`), Valid: true}} {
+		if record, _ := dataRecord(database.ParsedDatum{FieldErrors: raw}, nil); record.FieldErrors != nil {
+			t.Errorf("field errors of a complete record = %v, want none", record.FieldErrors)
+		}
+	}
+}
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{raw: "", want: nil},
+		{raw: "category", want: []string{"category"}},
+		{raw: "offer.price", want: []string{"offer", "price"}},
+		{raw: "offer.", wantErr: true},
+		{raw: ".price", wantErr: true},
+		{raw: "offer..price", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFieldPath("group_by", tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFieldPath(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFieldPath(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateDataRequiresGroupBy(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewDataHandler(logger, nil, nil)
+
+	for _, target := range []string{"/api/v1/data/aggregate", "/api/v1/data/aggregate?group_by=a..b", "/api/v1/data/aggregate?group_by=category&field=price."} {
+		rec := httptest.NewRecorder()
+		handler.AggregateData(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestParsePurgeFilter(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{query: "url_id=" + id.String()},
+		{query: "schema=profile&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z"},
+		{query: "", wantErr: true},
+		{query: "from=2024-01-01T00:00:00Z", wantErr: true},
+		{query: "url_id=url-123", wantErr: true},
+		{query: "schema=profile&to=yesterday", wantErr: true},
+		{query: "schema=profile&from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/data?"+tt.query, nil)
+		params, err := parsePurgeFilter(req.URL.Query())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePurgeFilter(%q) error = %v, want error %v", tt.query, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if params.UrlID.Valid && params.UrlID.UUID != id {
+			t.Errorf("parsePurgeFilter(%q) url_id = %v, want %v", tt.query, params.UrlID.UUID, id)
+		}
+		if params.FromTime.Valid && !params.FromTime.Time.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("parsePurgeFilter(%q) from = %v", tt.query, params.FromTime.Time)
+		}
+	}
+}
+
+func TestPurgeJobResponse(t *testing.T) {
+	job := database.DataPurgeJob{
+		ID:                 uuid.New(),
+		Status:             "completed",
+		Schema:             "profile",
+		ParsedDataDeleted:  12,
+		RawHtmlDeleted:     3,
+		HarCapturesDeleted: 1,
+		CreatedAt:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:          time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC),
+	}
+	got := purgeJobResponse(job)
+	want := models.DataPurgeCounts{ParsedData: 12, RawHTML: 3, HARCaptures: 1}
+	if got.Deleted != want || got.URLID != "" || got.From != "" || got.CompletedAt != "" {
+		t.Errorf("purgeJobResponse() = %+v", got)
+	}
+}
+
+func TestDataRecordDecryptsSensitiveFields(t *testing.T) {
+	keyring := testKeyring(t, "new")
+	data := map[string]interface{}{"email": "jane@example.com", "price": "19.99"}
+	handler := &URLHandler{Keyring: keyring}
+	keyID, err := handler.sealSensitive(data, []string{"email"})
+	if err != nil || keyID != "new" {
+		t.Fatalf("sealSensitive() = %q, %v", keyID, err)
+	}
+	stored, _ := json.Marshal(data)
+	if bytes.Contains(stored, []byte("jane")) {
+		t.Fatalf("stored data = %s", stored)
+	}
+
+	record, err := dataRecord(database.ParsedDatum{Data: stored, EncryptionKeyID: keyID}, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decrypted map[string]interface{}
+	json.Unmarshal(record.Data, &decrypted)
+	if decrypted["email"] != "jane@example.com" || decrypted["price"] != "19.99" {
+		t.Errorf("data = %s", record.Data)
+	}
+	if _, err := dataRecord(database.ParsedDatum{Data: stored, EncryptionKeyID: keyID}, nil); err == nil {
+		t.Error("dataRecord() of an encrypted record without keyring succeeded")
+	}
+
+	if _, err := (&URLHandler{}).sealSensitive(map[string]interface{}{"email": "x"}, []string{"email"}); err == nil {
+		t.Error("sealSensitive() without keyring succeeded")
+	}
+}
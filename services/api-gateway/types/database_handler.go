@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DatabaseHandler handles the database connection pool HTTP requests: pool
+// diagnostics, runtime tuning of its limits and its Prometheus metrics
+type DatabaseHandler struct {
+	Logger *logrus.Logger
+	Pool   *database.Pool // Connection pool of this service
+}
+
+// NewDatabaseHandler creates a new database handler with the provided logger and connection pool.
+// This function initializes the handler with necessary dependencies.
+func NewDatabaseHandler(logger *logrus.Logger, pool *database.Pool) *DatabaseHandler {
+	return &DatabaseHandler{
+		Logger: logger,
+		Pool:   pool,
+	}
+}
+
+// GetPool handles GET /api/v1/admin/database/pool
+//
+// Purpose: Diagnoses connection pool exhaustion. Reports the pool limits,
+// the open, in-use and idle connections, and how many requests waited for a
+// connection and for how long since the API Gateway started. A growing
+// wait_count with every connection in use means queries hold connections
+// longer than requests can wait.
+//
+// Response: database.PoolStats (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/database/pool
+func (h *DatabaseHandler) GetPool(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Pool.Stats())
+}
+
+// UpdatePool handles PUT /api/v1/admin/database/pool
+//
+// Purpose: Tunes the pool limits at runtime, e.g. to relieve an exhausted
+// pool without a restart. Limits missing from the body are kept. Tuned
+// limits last until the API Gateway restarts or database.max_open_conns or
+// database.max_idle_conns change in the configuration.
+//
+// Request Body: database.PoolLimits as JSON, all fields optional
+//   - max_open_conns: Maximum open connections, 0 for no limit
+//   - max_idle_conns: Maximum idle connections kept, at most max_open_conns
+//   - conn_max_lifetime_ms: Maximum connection age, 0 to keep connections forever
+//   - conn_max_idle_time_ms: Maximum idle time of a connection, 0 for no limit
+//
+// Response: database.PoolStats (200 OK) or error (400 for invalid limits)
+//
+// Example Usage:
+//
+//	PUT /api/v1/admin/database/pool
+//	{
+//	  "max_open_conns": 50,
+//	  "max_idle_conns": 10
+//	}
+func (h *DatabaseHandler) UpdatePool(w http.ResponseWriter, r *http.Request) {
+	var update database.PoolLimitsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limits := update.Apply(h.Pool.Limits())
+	if err := h.Pool.SetLimits(limits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.Logger.WithFields(logrus.Fields{
+		"max_open_conns":    limits.MaxOpenConns,
+		"max_idle_conns":    limits.MaxIdleConns,
+		"conn_max_lifetime": limits.ConnMaxLifetime,
+		"conn_max_idle":     limits.ConnMaxIdleTime,
+	}).Info("Database pool limits changed")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Pool.Stats())
+}
+
+// GetPrometheusMetrics handles GET /metrics
+//
+// Purpose: Exposes the connection pool statistics in the Prometheus text
+// format: the limits and the open, in-use and idle connections as gauges
+// (db_pool_*_connections), and the waits for a connection and the time
+// spent waiting as counters (db_pool_wait_count_total and
+// db_pool_wait_duration_seconds_total).
+//
+// Response: Prometheus text exposition (200 OK)
+//
+// Example Usage:
+//
+//	GET /metrics
+func (h *DatabaseHandler) GetPrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	h.Pool.WritePrometheus(w)
+}
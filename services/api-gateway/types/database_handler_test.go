@@ -0,0 +1,63 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/database"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func TestUpdatePool(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	// Opening does not connect, so no database is needed
+	db, err := sql.Open("postgres", "postgres://localhost/scraping_db?sslmode=disable")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+	handler := NewDatabaseHandler(logger, database.NewPool(db, database.PoolLimits{MaxOpenConns: 25, MaxIdleConns: 5}))
+
+	rec := httptest.NewRecorder()
+	handler.UpdatePool(rec, httptest.NewRequest(http.MethodPut, "/api/v1/admin/database/pool", strings.NewReader(`{"max_open_conns": 50}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var stats struct {
+		Limits struct {
+			MaxOpenConns int `json:"max_open_conns"`
+			MaxIdleConns int `json:"max_idle_conns"`
+		} `json:"limits"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if stats.Limits.MaxOpenConns != 50 || stats.Limits.MaxIdleConns != 5 {
+		t.Errorf("PUT limits = %+v, want 50 open and the 5 idle kept", stats.Limits)
+	}
+
+	for _, body := range []string{`{"max_idle_conns": 100}`, `{"max_open_conns": -1}`, `not json`} {
+		rec := httptest.NewRecorder()
+		handler.UpdatePool(rec, httptest.NewRequest(http.MethodPut, "/api/v1/admin/database/pool", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if got := handler.Pool.Limits().MaxOpenConns; got != 50 {
+		t.Errorf("MaxOpenConns after rejected updates = %d, want 50", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.GetPrometheusMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "db_pool_max_open_connections 50\n") {
+		t.Errorf("GET /metrics = %s, want the tuned limit", rec.Body)
+	}
+}
@@ -0,0 +1,187 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/cookies"
+	"go_scraping_project/shared/database"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Robots policies reported per domain
+const (
+	RobotsPolicyIgnored    = "ignored"    // Scrapers do not honour robots.txt
+	RobotsPolicyAllowed    = "allowed"    // robots.txt denied no attempts in the period
+	RobotsPolicyRestricted = "restricted" // robots.txt denied attempts in the period
+)
+
+// DomainHandler handles per-site HTTP requests for the web scraping system.
+// It provides an operational view of each scraped host, combining its URLs
+// with the outcome of recent scrape attempts.
+type DomainHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Config *config.Watcher
+}
+
+// NewDomainHandler creates a new domain handler with the provided logger, database queries and configuration watcher.
+// This function initializes the handler with necessary dependencies.
+func NewDomainHandler(logger *logrus.Logger, db *database.Queries, cfg *config.Watcher) *DomainHandler {
+	return &DomainHandler{
+		Logger: logger,
+		DB:     db,
+		Config: cfg,
+	}
+}
+
+// ListDomains handles GET /api/v1/domains
+//
+// Purpose: Lists every host with registered URLs together with the outcome
+// of its scrape attempts in the period: success rate, average latency,
+// block incidents (blocked or rate limited) and how robots.txt applies to
+// it. Hosts with the most URLs come first.
+//
+// Query Parameters:
+//   - period: Time period for attempt statistics (1h, 24h, 7d, 30d) - default: 24h
+//
+// Response: models.ListDomainsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/domains
+//	GET /api/v1/domains?period=7d
+func (h *DomainHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "24h"
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		http.Error(w, "period must be one of 1h, 24h, 7d, 30d", http.StatusBadRequest)
+		return
+	}
+
+	since := time.Now().UTC().Add(-window)
+	rows, err := h.DB.ListDomainStats(r.Context(), sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list domain statistics")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respectRobots := h.Config.Current().Scraping.RespectRobotsTxt
+	response := models.ListDomainsResponse{
+		Period:  period,
+		Since:   since.Format(time.RFC3339),
+		Domains: make([]models.DomainResponse, len(rows)),
+	}
+	for i, row := range rows {
+		response.Domains[i] = domainResponse(row, respectRobots)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDomainCookies handles GET /api/v1/domains/{domain}/cookies
+//
+// Purpose: Shows whether scrapers keep a cookie jar for a domain between
+// scrapes (see scraping.cookies), with its cookie count and expiry. The
+// cookies themselves are stored encrypted and never returned. Jars are kept
+// per registrable domain, so www.example.co.uk shows the jar of
+// example.co.uk.
+//
+// Path Parameters:
+//   - domain: Host or registrable domain (required)
+//
+// Response: models.DomainCookiesResponse (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/domains/example.com/cookies
+func (h *DomainHandler) GetDomainCookies(w http.ResponseWriter, r *http.Request) {
+	domain := cookies.Domain("https://" + mux.Vars(r)["domain"])
+	jar, err := h.DB.GetCookieJar(r.Context(), domain)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Domain has no stored cookies", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.Logger.WithError(err).WithField("domain", domain).Error("Failed to get cookie jar")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.DomainCookiesResponse{
+		Domain:      jar.Domain,
+		CookieCount: jar.CookieCount,
+		ExpiresAt:   jar.ExpiresAt.Format(time.RFC3339),
+		UpdatedAt:   jar.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// DeleteDomainCookies handles DELETE /api/v1/domains/{domain}/cookies
+//
+// Purpose: Clears the cookie jar kept for a domain, e.g. after a login
+// expired or a site changed its consent banner, so the next scrape starts
+// without cookies.
+//
+// Path Parameters:
+//   - domain: Host or registrable domain (required)
+//
+// Response: No content (204) or error (404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/domains/example.com/cookies
+func (h *DomainHandler) DeleteDomainCookies(w http.ResponseWriter, r *http.Request) {
+	domain := cookies.Domain("https://" + mux.Vars(r)["domain"])
+	deleted, err := h.DB.DeleteCookieJar(r.Context(), domain)
+	if err != nil {
+		h.Logger.WithError(err).WithField("domain", domain).Error("Failed to delete cookie jar")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Domain has no stored cookies", http.StatusNotFound)
+		return
+	}
+
+	h.Logger.WithField("domain", domain).Info("Cookie jar cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// domainResponse converts the statistics of a host to the response format
+func domainResponse(row database.ListDomainStatsRow, respectRobots bool) models.DomainResponse {
+	domain := models.DomainResponse{
+		Domain:         row.Domain,
+		URLCount:       row.UrlCount,
+		Attempts:       row.Attempts,
+		AverageLatency: math.Round(row.AvgDurationMs*10) / 10,
+		BlockIncidents: row.Blocked + row.RateLimited,
+		Blocked:        row.Blocked,
+		RateLimited:    row.RateLimited,
+		RobotsDenied:   row.RobotsDenied,
+		RobotsPolicy:   RobotsPolicyIgnored,
+		ThrottledMs:    row.ThrottledMs,
+	}
+	if row.Attempts > 0 {
+		domain.SuccessRate = math.Round(float64(row.Successes)/float64(row.Attempts)*1000) / 10
+	}
+	if respectRobots {
+		domain.RobotsPolicy = RobotsPolicyAllowed
+		if row.RobotsDenied > 0 {
+			domain.RobotsPolicy = RobotsPolicyRestricted
+		}
+	}
+	return domain
+}
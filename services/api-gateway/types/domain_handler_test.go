@@ -0,0 +1,54 @@
+package types
+
+import (
+	"testing"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+)
+
+func TestDomainResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		row           database.ListDomainStatsRow
+		respectRobots bool
+		want          models.DomainResponse
+	}{
+		{
+			name: "no attempts",
+			row:  database.ListDomainStatsRow{Domain: "example.com", UrlCount: 2},
+			want: models.DomainResponse{Domain: "example.com", URLCount: 2, RobotsPolicy: RobotsPolicyIgnored},
+		},
+		{
+			name: "blocked and denied by robots.txt",
+			row: database.ListDomainStatsRow{
+				Domain: "shop.example.com", UrlCount: 1, Attempts: 3, Successes: 1, AvgDurationMs: 412.345,
+				Blocked: 1, RateLimited: 0, RobotsDenied: 1, ThrottledMs: 2500,
+			},
+			respectRobots: true,
+			want: models.DomainResponse{
+				Domain: "shop.example.com", URLCount: 1, Attempts: 3, SuccessRate: 33.3, AverageLatency: 412.3,
+				BlockIncidents: 1, Blocked: 1, RobotsDenied: 1, RobotsPolicy: RobotsPolicyRestricted, ThrottledMs: 2500,
+			},
+		},
+		{
+			name: "rate limited",
+			row: database.ListDomainStatsRow{
+				Domain: "api.example.org", UrlCount: 4, Attempts: 8, Successes: 6, AvgDurationMs: 120, RateLimited: 2,
+			},
+			respectRobots: true,
+			want: models.DomainResponse{
+				Domain: "api.example.org", URLCount: 4, Attempts: 8, SuccessRate: 75, AverageLatency: 120,
+				BlockIncidents: 2, RateLimited: 2, RobotsPolicy: RobotsPolicyAllowed,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domainResponse(tt.row, tt.respectRobots); got != tt.want {
+				t.Errorf("domainResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
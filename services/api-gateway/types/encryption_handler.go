@@ -0,0 +1,171 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Limits for the parsed records rewrapped by one rotation call
+const (
+	defaultRotationLimit = 1000
+	maxRotationLimit     = 10000
+)
+
+// EncryptionHandler handles the encryption keys of sensitive fields at rest
+// for the web scraping system: notification channel credentials and parsed
+// fields marked sensitive. After encryption.primary_key changes, records
+// whose data keys are wrapped by an earlier key are rewrapped through this
+// handler, so the earlier key can be removed.
+type EncryptionHandler struct {
+	Logger  *logrus.Logger
+	DB      *database.Queries   // sqlc-generated database queries
+	Keyring *encryption.Keyring // Encryption keys, nil when none are configured
+}
+
+// NewEncryptionHandler creates a new encryption handler with the provided logger, database queries
+// and encryption keyring. This function initializes the handler with necessary dependencies.
+func NewEncryptionHandler(logger *logrus.Logger, db *database.Queries, keyring *encryption.Keyring) *EncryptionHandler {
+	return &EncryptionHandler{
+		Logger:  logger,
+		DB:      db,
+		Keyring: keyring,
+	}
+}
+
+// RotateKeys handles POST /api/v1/admin/encryption/rotate
+//
+// Purpose: Wraps the data keys of encrypted records by the primary key after
+// a key rotation, without re-encrypting the values. Notification channels
+// are all rewrapped, and channels stored before encryption keys were
+// configured are encrypted. Parsed records are rewrapped up to limit per
+// call; call again while remaining is true. Rewrapped records move forward
+// in the delta feed. Cookie jars are not rewrapped: they are sealed by the
+// primary key whenever they are saved and expire after
+// scraping.cookies.max_age. Returns 409 Conflict when no encryption keys
+// are configured.
+//
+// Query Parameters:
+//   - limit: Parsed records to rewrap, max 10000 (default: 1000)
+//
+// Response: models.EncryptionRotationResponse (200 OK) or error (409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/encryption/rotate?limit=5000
+func (h *EncryptionHandler) RotateKeys(w http.ResponseWriter, r *http.Request) {
+	if h.Keyring == nil {
+		http.Error(w, "No encryption keys are configured", http.StatusConflict)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxRotationLimit {
+		limit = defaultRotationLimit
+	}
+	primary := h.Keyring.PrimaryKeyID()
+	response := models.EncryptionRotationResponse{PrimaryKeyID: primary}
+
+	channels, err := h.DB.ListNotificationChannels(r.Context())
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list notification channels")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, channel := range channels {
+		settings, changed, err := rewrapSettings(channel.Settings, h.Keyring)
+		if err != nil {
+			h.Logger.WithError(err).WithField("channel", channel.Name).Error("Failed to rewrap notification channel settings")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !changed {
+			continue
+		}
+		if _, err := h.DB.UpdateNotificationChannel(r.Context(), database.UpdateNotificationChannelParams{
+			Name:     channel.Name,
+			Type:     channel.Type,
+			Settings: settings,
+			Enabled:  channel.Enabled,
+		}); err != nil {
+			h.Logger.WithError(err).WithField("channel", channel.Name).Error("Failed to update notification channel")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.NotificationChannels++
+	}
+
+	// One extra record tells whether more records are waiting
+	rows, err := h.DB.ListParsedDataToRewrap(r.Context(), database.ListParsedDataToRewrapParams{
+		PrimaryKeyID: primary,
+		MaxResults:   int32(limit + 1),
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list parsed records to rewrap")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	response.Remaining = len(rows) > limit
+	for _, row := range rows[:min(len(rows), limit)] {
+		var data map[string]interface{}
+		if err := json.Unmarshal(row.Data, &data); err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to decode parsed record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if _, err := h.Keyring.RewrapFields(data); err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to rewrap parsed record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		rewrapped, err := json.Marshal(data)
+		if err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to encode parsed record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if err := h.DB.UpdateParsedDataEncryption(r.Context(), database.UpdateParsedDataEncryptionParams{
+			ID:              row.ID,
+			Data:            rewrapped,
+			EncryptionKeyID: primary,
+		}); err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to update parsed record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.ParsedRecords++
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"primary_key":           primary,
+		"notification_channels": response.NotificationChannels,
+		"parsed_records":        response.ParsedRecords,
+		"remaining":             response.Remaining,
+	}).Info("Rewrapped encrypted records")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// rewrapSettings returns stored notification channel settings wrapped by
+// the primary key, encrypting settings stored in plain JSON. It reports
+// whether they changed.
+func rewrapSettings(settings []byte, keyring *encryption.Keyring) ([]byte, bool, error) {
+	env, ok := encryption.Parse(settings)
+	if !ok {
+		sealed, err := keyring.SealBytes(settings)
+		return sealed, err == nil, err
+	}
+	changed, err := keyring.Rewrap(env)
+	if err != nil || !changed {
+		return settings, false, err
+	}
+	rewrapped, err := json.Marshal(env)
+	return rewrapped, err == nil, err
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/encryption"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testKeyring returns a keyring with keys old and new, new being primary
+// unless primary says otherwise
+func testKeyring(t *testing.T, primary string) *encryption.Keyring {
+	t.Helper()
+	keyring, err := encryption.New(config.EncryptionConfig{
+		PrimaryKey: primary,
+		Keys: map[string]string{
+			"old": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+			"new": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestRewrapSettings(t *testing.T) {
+	plain := []byte(`{"routing_key":"R0UT1NGK3Y"}`)
+	old, err := testKeyring(t, "old").SealBytes(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := testKeyring(t, "new")
+
+	for name, stored := range map[string][]byte{"plain": plain, "old key": old} {
+		rewrapped, changed, err := rewrapSettings(stored, keyring)
+		if err != nil || !changed {
+			t.Fatalf("rewrapSettings(%s) = %v, %v", name, changed, err)
+		}
+		if env, ok := encryption.Parse(rewrapped); !ok || env.KeyID != "new" {
+			t.Errorf("rewrapSettings(%s) = %s, want an envelope of the new key", name, rewrapped)
+		}
+		if opened, err := keyring.OpenBytes(rewrapped); err != nil || !bytes.Equal(opened, plain) {
+			t.Errorf("rewrapped %s settings = %s, %v", name, opened, err)
+		}
+		if _, changed, _ := rewrapSettings(rewrapped, keyring); changed {
+			t.Errorf("rewrapSettings() of %s settings wrapped by the primary key changed them", name)
+		}
+	}
+}
+
+func TestRotateKeysWithoutKeys(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewEncryptionHandler(logger, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.RotateKeys(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/encryption/rotate", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
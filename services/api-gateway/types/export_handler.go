@@ -0,0 +1,399 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/exports"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Limits for the export jobs returned by one list call
+const (
+	defaultExportJobs = 20
+	maxExportJobs     = 100
+)
+
+// ExportHandler handles the data exports of projects for the web scraping
+// system. Exports are written to files by the URL Manager, so requesting one
+// returns at once however many records it holds, and the file is
+// downloaded from object storage through a short-lived presigned link
+// instead of a long response of the API.
+type ExportHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Config *config.Watcher   // Effective configuration, for the exports settings
+}
+
+// NewExportHandler creates a new export handler with the provided logger, database queries
+// and configuration. This function initializes the handler with necessary dependencies.
+func NewExportHandler(logger *logrus.Logger, db *database.Queries, cfg *config.Watcher) *ExportHandler {
+	return &ExportHandler{
+		Logger: logger,
+		DB:     db,
+		Config: cfg,
+	}
+}
+
+// CreateExport handles POST /api/v1/data/exports
+//
+// Purpose: Requests an export of the parsed records of a project, optionally
+// of one schema and within a date range. The export is written to a file by
+// the URL Manager: the response is the pending job, whose Location is
+// polled until it is completed and carries a download_url. Returns 409
+// Conflict when exports are not configured.
+//
+// Request Body: models.CreateDataExportRequest
+//
+// Headers:
+//   - X-Author: Who requested the export, recorded with the job
+//
+// Response: models.DataExportJobResponse (202 Accepted) or error (400/409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/data/exports
+//	{"project": "crm", "schema": "profile", "format": "csv", "from": "2024-01-01T00:00:00Z"}
+func (h *ExportHandler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.Current().Exports.Enabled() {
+		http.Error(w, "Data exports are not configured", http.StatusConflict)
+		return
+	}
+
+	var req models.CreateDataExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, err := exportJobParams(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params.RequestedBy = strings.TrimSpace(r.Header.Get(authorHeader))
+	if len(params.RequestedBy) > 200 {
+		params.RequestedBy = params.RequestedBy[:200]
+	}
+
+	store, ok := h.store(w)
+	if !ok {
+		return
+	}
+	job, err := h.DB.CreateDataExportJob(r.Context(), params)
+	if err != nil {
+		h.Logger.WithError(err).WithField("project", params.Project).Error("Failed to create data export job")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.Logger.WithFields(logrus.Fields{
+		"job_id":       job.ID,
+		"project":      job.Project,
+		"requested_by": job.RequestedBy,
+	}).Info("Data export requested")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/data/exports/"+job.ID.String())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(exportJobResponse(job, store, h.Config.Current().Exports, time.Now()))
+}
+
+// ListExports handles GET /api/v1/data/exports
+//
+// Purpose: Lists the export jobs of a project, requested through the API or
+// created by its schedules in exports.schedules, with a fresh download link
+// for each completed export.
+//
+// Query Parameters:
+//   - project: Project whose exports are listed (required)
+//   - limit: Number of jobs, max 100 (default: 20)
+//
+// Response: models.DataExportJobsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/exports?project=crm&limit=50
+func (h *ExportHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	project := strings.TrimSpace(r.URL.Query().Get("project"))
+	if project == "" {
+		http.Error(w, "project is required", http.StatusBadRequest)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxExportJobs {
+		limit = defaultExportJobs
+	}
+
+	store, ok := h.store(w)
+	if !ok {
+		return
+	}
+	jobs, err := h.DB.ListDataExportJobs(r.Context(), database.ListDataExportJobsParams{
+		Project: project,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("project", project).Error("Failed to list data export jobs")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	cfg, now := h.Config.Current().Exports, time.Now()
+	response := models.DataExportJobsResponse{Exports: make([]models.DataExportJobResponse, 0, len(jobs))}
+	for _, job := range jobs {
+		response.Exports = append(response.Exports, exportJobResponse(job, store, cfg, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetExport handles GET /api/v1/data/exports/{id}
+//
+// Purpose: Returns a data export job of a project: its filter, its status
+// (pending, running, completed, failed or expired) and the records written
+// so far. Once completed, it carries a download_url valid for
+// exports.link_ttl, or until the file expires if sooner; every call signs a
+// fresh link. Jobs of other projects are not found.
+//
+// Path Parameters:
+//   - id: Export job identifier (required)
+//
+// Query Parameters:
+//   - project: Project of the job (required)
+//
+// Response: models.DataExportJobResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/exports/5f0c8a2e-7b1d-4c3e-9a4f-2d6e8b0c1a3f?project=crm
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	project := strings.TrimSpace(r.URL.Query().Get("project"))
+	if project == "" {
+		http.Error(w, "project is required", http.StatusBadRequest)
+		return
+	}
+	job, ok := h.getJob(w, r)
+	if !ok {
+		return
+	}
+	if project != job.Project {
+		http.Error(w, "Export job not found", http.StatusNotFound)
+		return
+	}
+	store, ok := h.store(w)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exportJobResponse(job, store, h.Config.Current().Exports, time.Now()))
+}
+
+// DownloadExport handles GET /api/v1/data/exports/{id}/download
+//
+// Purpose: Serves the file of a completed export to the holder of a signed
+// link from GetExport, when exports are written to exports.directory rather
+// than to a bucket, whose links point at the bucket itself. The link needs
+// no other credentials and stops working when it expires. Range requests
+// are supported, so an interrupted download of a large export can resume.
+// The server's write timeout does not apply, since large files outlast it.
+// Returns 403 Forbidden for an invalid signature and 410 Gone for an
+// expired link or a deleted file.
+//
+// Path Parameters:
+//   - id: Export job identifier (required)
+//
+// Query Parameters:
+//   - expires: Expiry of the link, in Unix seconds (required)
+//   - signature: Signature of the link (required)
+//
+// Response: The export file (200 OK or 206 Partial Content) or error (400/403/404/410/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/data/exports/5f0c8a2e-7b1d-4c3e-9a4f-2d6e8b0c1a3f/download?expires=1704110400&signature=9c1f...
+func (h *ExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	cfg := h.Config.Current().Exports
+	if cfg.Directory == "" {
+		http.Error(w, "Data exports are not served by the API", http.StatusNotFound)
+		return
+	}
+	query := r.URL.Query()
+	err := exports.NewSigner(cfg.SigningKey).Verify(mux.Vars(r)["id"], query.Get("expires"), query.Get("signature"), time.Now())
+	if errors.Is(err, exports.ErrLinkExpired) {
+		http.Error(w, "Download link expired", http.StatusGone)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invalid download link", http.StatusForbidden)
+		return
+	}
+
+	job, ok := h.getJob(w, r)
+	if !ok {
+		return
+	}
+	if job.Status != sharedmodels.ExportStatusCompleted || job.Artifact == "" {
+		http.Error(w, "Export file is not available", http.StatusGone)
+		return
+	}
+	file, err := os.Open(filepath.Join(cfg.Directory, job.Artifact))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "Export file is not available", http.StatusGone)
+			return
+		}
+		h.Logger.WithError(err).WithField("job_id", job.ID).Error("Failed to open data export file")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.Logger.WithError(err).WithField("job_id", job.ID).Warn("Failed to lift the write deadline of a data export download")
+	}
+	w.Header().Set("Content-Type", exports.ContentType(job.Format))
+	w.Header().Set("Content-Disposition", "attachment; filename="+downloadName(job))
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, job.Artifact, job.CompletedAt.Time, file)
+}
+
+// getJob loads the export job of the id path parameter, writing the error
+// response when there is none
+func (h *ExportHandler) getJob(w http.ResponseWriter, r *http.Request) (database.DataExportJob, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid export job ID", http.StatusBadRequest)
+		return database.DataExportJob{}, false
+	}
+
+	job, err := h.DB.GetDataExportJob(r.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Export job not found", http.StatusNotFound)
+			return job, false
+		}
+		h.Logger.WithError(err).WithField("job_id", id).Error("Failed to get data export job")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return job, false
+	}
+	return job, true
+}
+
+// store returns the export store of the current settings, nil when exports
+// are not configured, writing the error response when it cannot be created
+func (h *ExportHandler) store(w http.ResponseWriter) (exports.Store, bool) {
+	cfg := h.Config.Current().Exports
+	if !cfg.Enabled() {
+		return nil, true
+	}
+	store, err := exports.NewStore(cfg)
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to create data export store")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return store, true
+}
+
+// downloadName returns the file name an export is downloaded as
+func downloadName(job database.DataExportJob) string {
+	return job.Project + "-" + job.Artifact
+}
+
+// exportJobParams reads the job of an export request
+func exportJobParams(req models.CreateDataExportRequest) (database.CreateDataExportJobParams, error) {
+	params := database.CreateDataExportJobParams{
+		Project: strings.TrimSpace(req.Project),
+		Schema:  strings.TrimSpace(req.Schema),
+		Format:  req.Format,
+	}
+	if params.Project == "" {
+		return params, errors.New("project is required")
+	}
+
+	bounds := []struct {
+		name  string
+		value string
+		time  *sql.NullTime
+	}{{"from", req.From, &params.FromTime}, {"to", req.To, &params.ToTime}}
+	for _, bound := range bounds {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return params, fmt.Errorf("invalid %s, use an RFC 3339 time such as 2024-01-01T00:00:00Z", bound.name)
+		}
+		*bound.time = sql.NullTime{Time: t, Valid: true}
+	}
+	if params.FromTime.Valid && params.ToTime.Valid && !params.FromTime.Time.Before(params.ToTime.Time) {
+		return params, errors.New("from must be before to")
+	}
+	return params, nil
+}
+
+// exportJobResponse converts a stored export job into its response. A
+// completed job gets a download link from the store valid for the
+// configured link TTL from now, cut short when its file expires sooner;
+// none without a store.
+func exportJobResponse(job database.DataExportJob, store exports.Store, cfg config.ExportsConfig, now time.Time) models.DataExportJobResponse {
+	response := models.DataExportJobResponse{
+		ID:          job.ID.String(),
+		Status:      job.Status,
+		Project:     job.Project,
+		Schema:      job.Schema,
+		Format:      job.Format,
+		Schedule:    job.Schedule,
+		RequestedBy: job.RequestedBy,
+		RecordCount: job.RecordCount,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+	}
+	if job.FromTime.Valid {
+		response.From = job.FromTime.Time.Format(time.RFC3339)
+	}
+	if job.ToTime.Valid {
+		response.To = job.ToTime.Time.Format(time.RFC3339)
+	}
+	if job.CompletedAt.Valid {
+		response.CompletedAt = job.CompletedAt.Time.Format(time.RFC3339)
+	}
+	if job.Status != sharedmodels.ExportStatusCompleted {
+		return response
+	}
+
+	response.SizeBytes = job.SizeBytes
+	expires := now.Add(cfg.LinkTTL)
+	if job.ExpiresAt.Valid {
+		response.ExpiresAt = job.ExpiresAt.Time.Format(time.RFC3339)
+		if job.ExpiresAt.Time.Before(expires) {
+			expires = job.ExpiresAt.Time
+		}
+	}
+	if store != nil && expires.After(now) {
+		response.DownloadURL = store.DownloadURL(job.Artifact, downloadName(job), expires)
+		response.DownloadExpiresAt = expires.UTC().Format(time.RFC3339)
+	}
+	return response
+}
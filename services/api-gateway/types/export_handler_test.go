@@ -0,0 +1,167 @@
+package types
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/exports"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+var testExportsConfig = config.ExportsConfig{
+	Directory:  "/var/lib/exports",
+	SigningKey: "s3cr3t",
+	BaseURL:    "https://exports.example.com",
+	LinkTTL:    15 * time.Minute,
+	Retention:  24 * time.Hour,
+}
+
+func TestExportJobParams(t *testing.T) {
+	tests := []struct {
+		req     models.CreateDataExportRequest
+		wantErr bool
+	}{
+		{req: models.CreateDataExportRequest{Project: "crm", Format: "csv"}},
+		{req: models.CreateDataExportRequest{Project: "crm", Format: "jsonl", From: "2024-01-01T00:00:00Z", To: "2024-02-01T00:00:00Z"}},
+		{req: models.CreateDataExportRequest{Project: " ", Format: "csv"}, wantErr: true},
+		{req: models.CreateDataExportRequest{Project: "crm", Format: "csv", From: "yesterday"}, wantErr: true},
+		{req: models.CreateDataExportRequest{Project: "crm", Format: "csv", From: "2024-02-01T00:00:00Z", To: "2024-01-01T00:00:00Z"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		params, err := exportJobParams(tt.req)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("exportJobParams(%+v) error = %v, want error %v", tt.req, err, tt.wantErr)
+		}
+		if !tt.wantErr && params.Project != "crm" {
+			t.Errorf("exportJobParams(%+v) project = %q", tt.req, params.Project)
+		}
+	}
+}
+
+func TestExportJobResponse(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	job := database.DataExportJob{
+		ID:          id,
+		Status:      "completed",
+		Project:     "crm",
+		Format:      "csv",
+		RecordCount: 3,
+		SizeBytes:   120,
+		Artifact:    exports.FileName(id.String(), "csv"),
+		CreatedAt:   now.Add(-time.Hour),
+		CompletedAt: sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+		ExpiresAt:   sql.NullTime{Time: now.Add(24 * time.Hour), Valid: true},
+	}
+
+	store, err := exports.NewStore(testExportsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := exportJobResponse(job, store, testExportsConfig, now)
+	if got.DownloadExpiresAt != "2024-01-01T12:15:00Z" || got.SizeBytes != 120 {
+		t.Fatalf("exportJobResponse() = %+v", got)
+	}
+	link, err := url.Parse(got.DownloadURL)
+	if err != nil || link.Host != "exports.example.com" {
+		t.Fatalf("download URL = %s", got.DownloadURL)
+	}
+	signer := exports.NewSigner(testExportsConfig.SigningKey)
+	if err := signer.Verify(job.ID.String(), link.Query().Get("expires"), link.Query().Get("signature"), now); err != nil {
+		t.Errorf("download URL does not verify: %v", err)
+	}
+
+	// The link ends with the file
+	job.ExpiresAt.Time = now.Add(5 * time.Minute)
+	if got := exportJobResponse(job, store, testExportsConfig, now); got.DownloadExpiresAt != "2024-01-01T12:05:00Z" {
+		t.Errorf("download link of a file expiring sooner expires at %s", got.DownloadExpiresAt)
+	}
+
+	// Exports in a bucket are downloaded from the bucket, as the project's file
+	bucketConfig := config.ExportsConfig{
+		Storage: config.ExportStorageConfig{Bucket: "exports", Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		LinkTTL: 15 * time.Minute,
+	}
+	bucket, err := exports.NewStore(bucketConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = exportJobResponse(job, bucket, bucketConfig, now)
+	link, err = url.Parse(got.DownloadURL)
+	if err != nil || link.Host != "exports.s3.eu-west-1.amazonaws.com" || link.Path != "/"+job.Artifact ||
+		link.Query().Get("X-Amz-Signature") == "" || link.Query().Get("response-content-disposition") != "attachment; filename=crm-"+job.Artifact {
+		t.Errorf("bucket download URL = %s", got.DownloadURL)
+	}
+
+	if got := exportJobResponse(job, nil, config.ExportsConfig{}, now); got.DownloadURL != "" {
+		t.Errorf("job without a store has a download link: %+v", got)
+	}
+	job.Status = "running"
+	if got := exportJobResponse(job, store, testExportsConfig, now); got.DownloadURL != "" || got.SizeBytes != 0 {
+		t.Errorf("running job has a download link: %+v", got)
+	}
+}
+
+func TestGetExportRequiresProject(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewExportHandler(logger, nil, config.NewWatcher(&config.Config{Exports: testExportsConfig}, nil, logger))
+	id := uuid.New().String()
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/v1/data/exports/"+id, nil), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	handler.GetExport(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GetExport() without project status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDownloadExportRejectsInvalidLinks(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewExportHandler(logger, nil, config.NewWatcher(&config.Config{Exports: testExportsConfig}, nil, logger))
+	id := uuid.New().String()
+	signer := exports.NewSigner(testExportsConfig.SigningKey)
+
+	tests := []struct {
+		name string
+		link string
+		want int
+	}{
+		{"unsigned", exports.DownloadPath(id), http.StatusForbidden},
+		{"other export", signer.Link("", uuid.New().String(), time.Now().Add(time.Minute)), http.StatusForbidden},
+		{"expired", signer.Link("", id, time.Now().Add(-time.Minute)), http.StatusGone},
+	}
+	// Files in a bucket are not served by the API
+	bucketHandler := NewExportHandler(logger, nil, config.NewWatcher(&config.Config{Exports: config.ExportsConfig{
+		Storage: config.ExportStorageConfig{Bucket: "exports", Region: "eu-west-1"},
+	}}, nil, logger))
+	link, _ := url.Parse(signer.Link("", id, time.Now().Add(time.Minute)))
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, exports.DownloadPath(id)+"?"+link.RawQuery, nil), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	bucketHandler.DownloadExport(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bucket export: DownloadExport() status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	for _, tt := range tests {
+		link, _ := url.Parse(tt.link)
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, exports.DownloadPath(id)+"?"+link.RawQuery, nil), map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.DownloadExport(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: DownloadExport() status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
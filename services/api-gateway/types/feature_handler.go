@@ -0,0 +1,305 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/features"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// featureNamePattern restricts flag names to the snake_case identifiers used in configuration
+var featureNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,62}$`)
+
+// tenantIDPattern restricts tenant IDs to URL-safe identifiers
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,127}$`)
+
+// FeatureHandler handles feature flag HTTP requests for the web scraping system.
+// It provides endpoints for inspecting the effective flags and for managing
+// flags and per-tenant overrides stored in the database, so behaviour can be
+// rolled out gradually and toggled without a redeploy.
+type FeatureHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Flags  *features.Flags   // Effective flags, refreshed after every change
+}
+
+// NewFeatureHandler creates a new feature handler with the provided logger, database queries and flags.
+// This function initializes the handler with necessary dependencies for flag management.
+func NewFeatureHandler(logger *logrus.Logger, db *database.Queries, flags *features.Flags) *FeatureHandler {
+	return &FeatureHandler{
+		Logger: logger,
+		DB:     db,
+		Flags:  flags,
+	}
+}
+
+// ListFlags handles GET /api/v1/admin/features
+//
+// Purpose: Lists every known feature flag with its effective definition,
+// merged from configuration and the database. When a tenant is given, each
+// flag also reports whether it is active for that tenant.
+//
+// Query Parameters:
+//   - tenant: Tenant ID to evaluate the flags for (optional)
+//
+// Response: models.ListFeatureFlagsResponse (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/features
+//	GET /api/v1/admin/features?tenant=acme
+func (h *FeatureHandler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	tenant := r.URL.Query().Get("tenant")
+
+	flags := h.Flags.All()
+	response := models.ListFeatureFlagsResponse{
+		Flags: make([]models.FeatureFlagResponse, 0, len(flags)),
+		Total: len(flags),
+	}
+	for _, flag := range flags {
+		item := featureFlagResponse(flag)
+		if tenant != "" {
+			active := flag.EnabledFor(tenant)
+			item.Active = &active
+		}
+		response.Flags = append(response.Flags, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpsertFlag handles PUT /api/v1/admin/features/{name}
+//
+// Purpose: Creates or replaces a feature flag in the database. A stored flag
+// takes precedence over a configured flag of the same name. Other services
+// pick up the change within features.refresh_interval.
+//
+// Path Parameters:
+//   - name: Flag name (lowercase letters, digits and underscores)
+//
+// Request Body: models.UpsertFeatureFlagRequest
+// Response: models.FeatureFlagResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/admin/features/js_rendering
+//	{
+//	  "description": "Render pages with a headless browser",
+//	  "enabled": true,
+//	  "rollout_percent": 10
+//	}
+func (h *FeatureHandler) UpsertFlag(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !featureNamePattern.MatchString(name) {
+		http.Error(w, "Invalid feature flag name", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpsertFeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rolloutPercent := 100
+	if req.RolloutPercent != nil {
+		rolloutPercent = *req.RolloutPercent
+	}
+
+	_, err := h.DB.UpsertFeatureFlag(r.Context(), database.UpsertFeatureFlagParams{
+		Name:           name,
+		Description:    sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Enabled:        req.Enabled,
+		RolloutPercent: int32(rolloutPercent),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("flag", name).Error("Failed to save feature flag")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"flag":            name,
+		"enabled":         req.Enabled,
+		"rollout_percent": rolloutPercent,
+	}).Info("Feature flag updated")
+	h.writeFlag(w, r, name)
+}
+
+// DeleteFlag handles DELETE /api/v1/admin/features/{name}
+//
+// Purpose: Removes a feature flag from the database. If the flag is also
+// defined in configuration, the configured definition takes effect again.
+// Tenant overrides are kept.
+//
+// Path Parameters:
+//   - name: Flag name (required)
+//
+// Response: 204 No Content or error (404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/admin/features/js_rendering
+func (h *FeatureHandler) DeleteFlag(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	deleted, err := h.DB.DeleteFeatureFlag(r.Context(), name)
+	if err != nil {
+		h.Logger.WithError(err).WithField("flag", name).Error("Failed to delete feature flag")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+
+	h.Logger.WithField("flag", name).Info("Feature flag deleted")
+	h.refresh(r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetOverride handles PUT /api/v1/admin/features/{name}/tenants/{tenant}
+//
+// Purpose: Turns a feature flag on or off for a single tenant, regardless of
+// the flag's global state and rollout percentage. Overrides may be set for
+// flags that are not otherwise defined, which enables them for that tenant only.
+//
+// Path Parameters:
+//   - name: Flag name (required)
+//   - tenant: Tenant ID (required, case-insensitive)
+//
+// Request Body: models.SetFeatureFlagOverrideRequest
+// Response: models.FeatureFlagResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/admin/features/js_rendering/tenants/acme
+//	{
+//	  "enabled": true
+//	}
+func (h *FeatureHandler) SetOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+	tenant := features.NormalizeTenant(vars["tenant"])
+
+	if !featureNamePattern.MatchString(name) {
+		http.Error(w, "Invalid feature flag name", http.StatusBadRequest)
+		return
+	}
+	if !tenantIDPattern.MatchString(tenant) {
+		http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SetFeatureFlagOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.DB.UpsertFeatureFlagOverride(r.Context(), database.UpsertFeatureFlagOverrideParams{
+		FlagName: name,
+		TenantID: tenant,
+		Enabled:  *req.Enabled,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithFields(logrus.Fields{"flag": name, "tenant": tenant}).Error("Failed to save feature flag override")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"flag":    name,
+		"tenant":  tenant,
+		"enabled": *req.Enabled,
+	}).Info("Feature flag override updated")
+	h.writeFlag(w, r, name)
+}
+
+// DeleteOverride handles DELETE /api/v1/admin/features/{name}/tenants/{tenant}
+//
+// Purpose: Removes a tenant override so the tenant follows the flag's global
+// state and rollout percentage again.
+//
+// Path Parameters:
+//   - name: Flag name (required)
+//   - tenant: Tenant ID (required, case-insensitive)
+//
+// Response: 204 No Content or error (404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/admin/features/js_rendering/tenants/acme
+func (h *FeatureHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+	tenant := features.NormalizeTenant(vars["tenant"])
+
+	deleted, err := h.DB.DeleteFeatureFlagOverride(r.Context(), database.DeleteFeatureFlagOverrideParams{
+		FlagName: name,
+		TenantID: tenant,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithFields(logrus.Fields{"flag": name, "tenant": tenant}).Error("Failed to delete feature flag override")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Feature flag override not found", http.StatusNotFound)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{"flag": name, "tenant": tenant}).Info("Feature flag override deleted")
+	h.refresh(r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refresh reloads the flags so the API Gateway reflects a change immediately
+func (h *FeatureHandler) refresh(r *http.Request) {
+	if err := h.Flags.Refresh(r.Context()); err != nil {
+		h.Logger.WithError(err).Warn("Failed to refresh feature flags after update")
+	}
+}
+
+// writeFlag refreshes the flags and writes the effective definition of a flag
+func (h *FeatureHandler) writeFlag(w http.ResponseWriter, r *http.Request, name string) {
+	h.refresh(r)
+
+	flag, ok := h.Flags.Lookup(name)
+	if !ok {
+		flag = features.Flag{Name: name}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(featureFlagResponse(flag))
+}
+
+// featureFlagResponse converts an effective flag to its API representation
+func featureFlagResponse(flag features.Flag) models.FeatureFlagResponse {
+	return models.FeatureFlagResponse{
+		Name:           flag.Name,
+		Description:    flag.Description,
+		Enabled:        flag.Enabled,
+		RolloutPercent: flag.RolloutPercent,
+		Tenants:        flag.Tenants,
+		Source:         flag.Source,
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"net/http"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/dnscache"
+	"go_scraping_project/shared/netprofile"
+	"go_scraping_project/shared/tlsprofile"
+)
+
+// Fetcher requests target sites for URL validation, probes and selector
+// suggestions the way scrapers do: host names are resolved through the DNS
+// cache of scraping.dns, and connections are dialed over the IP family and
+// from the local addresses of the site's domain (scraping.network), and TLS
+// handshakes present the domain's client profile (scraping.tls). The time spent resolving names is recorded in the
+// dnscache.Timing of the request's context, if any. A nil Fetcher uses
+// http.DefaultClient.
+type Fetcher struct {
+	transports *tlsprofile.Transports
+}
+
+// NewFetcher creates a fetcher from the scraping settings
+func NewFetcher(cfg config.ScrapingConfig) (*Fetcher, error) {
+	resolver, err := dnscache.New(cfg.DNS)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := netprofile.New(cfg.Network, resolver, 0)
+	if err != nil {
+		return nil, err
+	}
+	transports, err := tlsprofile.NewTransports(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transports.SetDialContext(dialer.DialContext)
+	return &Fetcher{transports: transports}, nil
+}
+
+// Client returns the client to request a URL with. Redirects to other
+// domains keep the TLS profile of the URL.
+func (f *Fetcher) Client(rawURL string) *http.Client {
+	if f == nil {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: f.transports.ForURL(rawURL)}
+}
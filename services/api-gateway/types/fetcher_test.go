@@ -0,0 +1,83 @@
+package types
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/dnscache"
+)
+
+func TestFetcherResolvesThroughDNSCache(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer site.Close()
+	target := strings.Replace(site.URL, "127.0.0.1", "localhost", 1)
+
+	fetcher, err := NewFetcher(config.ScrapingConfig{})
+	if err != nil {
+		t.Fatalf("NewFetcher() error = %v", err)
+	}
+	ctx, timing := dnscache.WithTiming(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	resp, err := fetcher.Client(target).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if total, cached := timing.Lookups(); total != 1 || cached != 0 {
+		t.Errorf("lookups = %d (%d cached), want 1 uncached", total, cached)
+	}
+
+	// Probes share the cache, so the name is not resolved again
+	got := probe(context.Background(), fetcher, &database.Url{Url: target + "/page"})
+	if !got.Reachable || got.DNSMs != 0 {
+		t.Errorf("probe() = %+v, want a reachable site resolved from the cache", got)
+	}
+}
+
+func TestFetcherDialsOverDomainIPFamily(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer site.Close()
+	target := strings.Replace(site.URL, "127.0.0.1", "localhost", 1)
+
+	// The site only listens on IPv4
+	for family, wantErr := range map[string]bool{config.IPFamilyIPv4: false, config.IPFamilyIPv6: true} {
+		fetcher, err := NewFetcher(config.ScrapingConfig{Network: config.NetworkConfig{
+			Domains: []config.NetworkDomainConfig{{Domain: "localhost", IPFamily: family}},
+		}})
+		if err != nil {
+			t.Fatalf("NewFetcher() error = %v", err)
+		}
+		resp, err := fetcher.Client(target).Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err != nil) != wantErr {
+			t.Errorf("ip_family %s: Get() error = %v, wantErr %v", family, err, wantErr)
+		}
+	}
+}
+
+func TestFetcherUsesDomainTLSProfile(t *testing.T) {
+	fetcher, err := NewFetcher(config.ScrapingConfig{TLS: config.TLSConfig{
+		Domains: []config.TLSDomainProfileConfig{{Domain: "shop.example", Profile: "chrome"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewFetcher() error = %v", err)
+	}
+
+	// Browser profiles do their own handshake, Go's profile leaves it to net/http
+	if transport := fetcher.Client("https://www.shop.example/item").Transport.(*http.Transport); transport.DialTLSContext == nil {
+		t.Error("chrome profile transport does not dial TLS itself")
+	}
+	if transport := fetcher.Client("https://example.com/").Transport.(*http.Transport); transport.DialTLSContext != nil {
+		t.Error("default profile transport dials TLS itself")
+	}
+	if _, err := NewFetcher(config.ScrapingConfig{TLS: config.TLSConfig{DefaultProfile: "netscape"}}); err == nil {
+		t.Error("NewFetcher() with an unknown TLS profile succeeded")
+	}
+}
@@ -1,7 +1,21 @@
 package types
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
 	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+	"go_scraping_project/shared/features"
+	"go_scraping_project/shared/tracing"
+	"go_scraping_project/shared/validation"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -14,10 +28,97 @@ type Router struct {
 	Router *mux.Router
 	Logger *logrus.Logger
 	DB     *database.Queries
+	Config *config.Watcher // Effective configuration, updated by hot-reload
+	Flags  *features.Flags // Feature flags from configuration and the database
+	Tracer *tracing.Tracer // Traces requests, nil when tracing is disabled
 
 	// Handlers
-	URLHandler     *URLHandler     // Handles URL management endpoints
-	DataHandler    *DataHandler    // Handles data retrieval endpoints
-	MetricsHandler *MetricsHandler // Handles metrics and monitoring endpoints
-	AdminHandler   *AdminHandler   // Handles admin and system management endpoints
+	URLHandler          *URLHandler          // Handles URL management endpoints
+	DataHandler         *DataHandler         // Handles data retrieval endpoints
+	MetricsHandler      *MetricsHandler      // Handles metrics and monitoring endpoints
+	AdminHandler        *AdminHandler        // Handles admin and system management endpoints
+	ParserHandler       *ParserHandler       // Handles parser template endpoints
+	FeatureHandler      *FeatureHandler      // Handles feature flag endpoints
+	DomainHandler       *DomainHandler       // Handles per-site overview endpoints
+	ScheduleHandler     *ScheduleHandler     // Handles scrape schedule endpoints
+	CostHandler         *CostHandler         // Handles scrape cost endpoints
+	WorkerHandler       *WorkerHandler       // Handles fleet status endpoints
+	ViewHandler         *ViewHandler         // Handles saved data view endpoints
+	NotificationHandler *NotificationHandler // Handles notification channel endpoints
+	AlertHandler        *AlertHandler        // Handles alert history endpoints
+	MaintenanceHandler  *MaintenanceHandler  // Handles the maintenance mode switch
+	DatabaseHandler     *DatabaseHandler     // Handles connection pool diagnostics and metrics
+	EncryptionHandler   *EncryptionHandler   // Handles the rotation of encryption keys
+	ExportHandler       *ExportHandler       // Handles data export jobs and their download links
+}
+
+// listSort is the sort order requested from a list endpoint
+type listSort struct {
+	Field      string
+	Descending bool
+}
+
+// parseListSort reads the sort and order query parameters of a list
+// endpoint. sort must be one of fields; without it the list is sorted by
+// fallback. order is asc or desc, and defaults to asc when sort is given.
+func parseListSort(r *http.Request, fallback listSort, fields ...string) (listSort, error) {
+	query := r.URL.Query()
+	sort := fallback
+	if field := query.Get("sort"); field != "" {
+		if !slices.Contains(fields, field) {
+			return listSort{}, fmt.Errorf("sort must be one of %s", strings.Join(fields, ", "))
+		}
+		sort = listSort{Field: field}
+	}
+
+	switch query.Get("order") {
+	case "":
+	case "asc":
+		sort.Descending = false
+	case "desc":
+		sort.Descending = true
+	default:
+		return listSort{}, errors.New("order must be asc or desc")
+	}
+	return sort, nil
+}
+
+// writeError answers a request that failed with err. Domain errors (see
+// package domain) are answered with the status of their kind and their
+// message, and a Retry-After header when known. Other errors are logged
+// with logMessage and answered with 500 Internal Server Error, without
+// their details.
+func writeError(w http.ResponseWriter, logger logrus.FieldLogger, err error, logMessage string) {
+	message, ok := domain.Message(err)
+	if !ok {
+		logger.WithError(err).Error(logMessage)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if retryAfter := domain.RetryAfter(err); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	http.Error(w, message, domain.HTTPStatus(err))
+}
+
+// validateRequest checks a decoded request body against the validate tags
+// of its fields (see package validation), returning a
+// *models.ValidationError naming the first invalid field
+func validateRequest(req interface{}) error {
+	return asValidationError(validation.Struct(req))
+}
+
+// validateField checks a single request value against the rules of a tag
+func validateField(name string, value interface{}, tag string) error {
+	return asValidationError(validation.Field(name, value, tag))
+}
+
+// asValidationError converts a failure of package validation to the
+// ValidationError returned by the handlers
+func asValidationError(err error) error {
+	var fieldErr *validation.FieldError
+	if errors.As(err, &fieldErr) {
+		return &models.ValidationError{Field: fieldErr.Field, Message: fieldErr.Message}
+	}
+	return err
 }
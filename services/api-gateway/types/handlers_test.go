@@ -0,0 +1,80 @@
+package types
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseListSort(t *testing.T) {
+	fallback := listSort{Field: "created_at", Descending: true}
+	fields := []string{"created_at", "next_scrape_at", "url"}
+
+	tests := []struct {
+		query   string
+		want    listSort
+		wantErr bool
+	}{
+		{query: "", want: fallback},
+		{query: "order=asc", want: listSort{Field: "created_at"}},
+		{query: "sort=next_scrape_at", want: listSort{Field: "next_scrape_at"}},
+		{query: "sort=url&order=desc", want: listSort{Field: "url", Descending: true}},
+		{query: "sort=retry_count", wantErr: true},
+		{query: "sort=url&order=up", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/v1/urls?"+tt.query, nil)
+		got, err := parseListSort(r, fallback, fields...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseListSort(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseListSort(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+		wantRetry  string
+	}{
+		{name: "not found", err: fmt.Errorf("get url: %w", domain.ErrURLNotFound.Wrap(sql.ErrNoRows)), wantStatus: http.StatusNotFound, wantBody: "URL not found"},
+		{name: "rate limited", err: domain.RateLimited(1500*time.Millisecond, "Too many scrapes"), wantStatus: http.StatusTooManyRequests, wantBody: "Too many scrapes", wantRetry: "2"},
+		{name: "unknown", err: errors.New("pq: connection refused"), wantStatus: http.StatusInternalServerError, wantBody: "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			w := httptest.NewRecorder()
+			writeError(w, logger, tt.err, "Failed to get URL")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+		})
+	}
+}
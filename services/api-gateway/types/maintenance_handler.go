@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/maintenance"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxMaintenanceMessageLength bounds the maintenance banner
+const maxMaintenanceMessageLength = 500
+
+// MaintenanceHandler handles maintenance mode HTTP requests for the web scraping system.
+// While maintenance mode is on the URL Manager stops scheduling scrapes,
+// workers finish their running tasks without taking new ones and the API
+// Gateway rejects changes, which is useful during database migrations and
+// Kafka maintenance.
+type MaintenanceHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Mode   *maintenance.Mode // Switch of this service, updated right after a change
+}
+
+// NewMaintenanceHandler creates a new maintenance handler with the provided logger, database queries and switch.
+// This function initializes the handler with necessary dependencies.
+func NewMaintenanceHandler(logger *logrus.Logger, db *database.Queries, mode *maintenance.Mode) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		Logger: logger,
+		DB:     db,
+		Mode:   mode,
+	}
+}
+
+// GetMaintenance handles GET /api/v1/admin/maintenance
+//
+// Purpose: Reports whether the system is in maintenance mode, with the
+// banner message and when the switch last changed.
+//
+// Response: models.MaintenanceResponse (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/maintenance
+func (h *MaintenanceHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maintenanceResponse(h.Mode.Current()))
+}
+
+// SetMaintenance handles POST /api/v1/admin/maintenance
+//
+// Purpose: Switches maintenance mode on or off. While it is on the URL
+// Manager publishes no scraping tasks, workers finish the tasks they are
+// running and take no new ones, and the API Gateway answers changes (POST,
+// PUT, PATCH and DELETE requests other than this one) with 503 Service
+// Unavailable and the message. The switch takes effect in the API Gateway
+// immediately and in the other services within maintenance.refresh_interval.
+//
+// Request Body: models.SetMaintenanceRequest
+// Response: models.MaintenanceResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/maintenance
+//	{
+//	  "enabled": true,
+//	  "message": "Database migration, back at 14:00 UTC"
+//	}
+func (h *MaintenanceHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req models.SetMaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if len(message) > maxMaintenanceMessageLength {
+		http.Error(w, "message must be at most 500 characters", http.StatusBadRequest)
+		return
+	}
+
+	row, err := h.DB.SetMaintenanceMode(r.Context(), database.SetMaintenanceModeParams{
+		Enabled: *req.Enabled,
+		Message: message,
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to save maintenance mode")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	state := maintenance.StateFromRow(row)
+	h.Mode.Set(state)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maintenanceResponse(state))
+}
+
+// maintenanceResponse converts the switch to the response format
+func maintenanceResponse(state maintenance.State) models.MaintenanceResponse {
+	response := models.MaintenanceResponse{Enabled: state.Enabled, Message: state.Message}
+	if !state.Since.IsZero() {
+		response.Since = state.Since.Format(time.RFC3339)
+	}
+	return response
+}
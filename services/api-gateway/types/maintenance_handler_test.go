@@ -0,0 +1,31 @@
+package types
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/maintenance"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetMaintenanceValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewMaintenanceHandler(logger, nil, maintenance.New(nil, logger))
+
+	for _, body := range []string{
+		`{}`,
+		`{"enabled": "yes"}`,
+		`{"enabled": true, "message": "` + strings.Repeat("x", 501) + `"}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.SetMaintenance(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/maintenance", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %.40s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
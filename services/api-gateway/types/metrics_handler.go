@@ -1,10 +1,20 @@
 package types
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"time"
 
 	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/cache"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -14,14 +24,27 @@ import (
 // It provides endpoints for retrieving performance metrics and monitoring data
 // for both individual URLs and system-wide statistics.
 type MetricsHandler struct {
-	Logger *logrus.Logger
+	Logger   *logrus.Logger
+	DB       *database.Queries // sqlc-generated database queries
+	Cache    *cache.Cache      // Caches metrics for CacheTTL, nil to disable
+	CacheTTL time.Duration
+	Config   *config.Watcher // Service level objectives, nil for none
 }
 
-// NewMetricsHandler creates a new metrics handler with the provided logger.
+// metricsPeriods maps the supported period query values to durations
+var metricsPeriods = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// NewMetricsHandler creates a new metrics handler with the provided logger and database queries.
 // This function initializes the handler with necessary dependencies.
-func NewMetricsHandler(logger *logrus.Logger) *MetricsHandler {
+func NewMetricsHandler(logger *logrus.Logger, db *database.Queries) *MetricsHandler {
 	return &MetricsHandler{
 		Logger: logger,
+		DB:     db,
 	}
 }
 
@@ -65,12 +88,7 @@ func (h *MetricsHandler) GetURLMetrics(w http.ResponseWriter, r *http.Request) {
 	// TODO: Get URL metrics from service
 	// metrics, err := h.metricsService.GetURLMetrics(r.Context(), urlID, period, includeTimeSeries)
 	// if err != nil {
-	//     if errors.Is(err, domain.ErrURLNotFound) {
-	//         http.Error(w, "URL not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.Logger.WithError(err).Error("Failed to get URL metrics")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
+	//     writeError(w, h.Logger, err, "Failed to get URL metrics")
 	//     return
 	// }
 
@@ -164,3 +182,321 @@ func (h *MetricsHandler) GetSystemMetrics(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// GetFailureMetrics handles GET /api/v1/metrics/failures
+//
+// Purpose: Breaks down failed scrape attempts by failure class (DNS, TLS,
+// timeout, 4xx, 5xx, rate limited, blocked, parse error, robots.txt denied).
+// Failure classes are recorded on scraping tasks by the URL Manager when
+// scrape results arrive, and also decide whether an attempt is retried.
+//
+// Query Parameters:
+//   - period: Time period for metrics (1h, 24h, 7d, 30d) - default: 24h
+//
+// Counts are cached for cache.metrics_ttl when the cache is enabled.
+//
+// Response: models.FailureMetricsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/metrics/failures
+//	GET /api/v1/metrics/failures?period=7d
+func (h *MetricsHandler) GetFailureMetrics(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "24h"
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		http.Error(w, "period must be one of 1h, 24h, 7d, 30d", http.StatusBadRequest)
+		return
+	}
+
+	response, err := cache.Fetch(r.Context(), h.Cache, "metrics:failures:"+period, h.CacheTTL, func(ctx context.Context) (models.FailureMetricsResponse, error) {
+		return h.failureMetrics(ctx, period, window)
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to count scrape failures")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// failureMetrics counts the failed scrape attempts of the last window by
+// failure class
+func (h *MetricsHandler) failureMetrics(ctx context.Context, period string, window time.Duration) (models.FailureMetricsResponse, error) {
+	since := time.Now().UTC().Add(-window)
+	rows, err := h.DB.CountScrapingTaskFailuresByErrorCode(ctx, sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		return models.FailureMetricsResponse{}, err
+	}
+
+	counts := make(map[sharedmodels.ErrorCode]int64, len(rows))
+	for _, row := range rows {
+		counts[sharedmodels.ErrorCode(row.ErrorCode)] += row.Count
+	}
+
+	response := models.FailureMetricsResponse{
+		Period:   period,
+		Since:    since.Format(time.RFC3339),
+		Failures: make([]models.FailureCountResponse, 0, len(counts)),
+	}
+	for _, code := range sharedmodels.ErrorCodes() {
+		response.Failures = append(response.Failures, models.FailureCountResponse{
+			ErrorCode: string(code),
+			Retryable: code.Retryable(),
+			Count:     counts[code],
+		})
+		response.Total += counts[code]
+		delete(counts, code)
+	}
+	// Codes recorded by newer scrapers are reported as they are
+	for code, count := range counts {
+		response.Failures = append(response.Failures, models.FailureCountResponse{ErrorCode: string(code), Count: count})
+		response.Total += count
+	}
+	sort.SliceStable(response.Failures, func(i, j int) bool {
+		return response.Failures[i].Count > response.Failures[j].Count
+	})
+	return response, nil
+}
+
+// Thresholds of a status code spike, see statusCodeMetrics
+const (
+	// spikeMinCount is the number of scrapes an error code needs in the
+	// period to be flagged, so a handful of errors on a quiet system is not
+	spikeMinCount = 10
+	// spikeFactor is how many times its share of the previous period an
+	// error code's share must reach
+	spikeFactor = 2
+)
+
+// blockingStatusCodes are the status codes sites answer scrapers with when
+// they block them
+var blockingStatusCodes = map[int]bool{
+	http.StatusForbidden:       true,
+	http.StatusTooManyRequests: true,
+}
+
+// GetStatusCodeMetrics handles GET /api/v1/metrics/status-codes
+//
+// Purpose: Breaks down the scrapes of all URLs completed in the period by
+// the HTTP status code they got, per status class and per code, next to the
+// previous period of the same length. Error codes whose share of scrapes at
+// least doubled are flagged as spikes; a spike in 403 or 429 across many
+// URLs usually means sites started blocking the scrapers.
+//
+// Query Parameters:
+//   - period: Time period for metrics (1h, 24h, 7d, 30d) - default: 24h
+//
+// Counts are cached for cache.metrics_ttl when the cache is enabled.
+//
+// Response: models.StatusCodeMetricsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/metrics/status-codes
+//	GET /api/v1/metrics/status-codes?period=1h
+func (h *MetricsHandler) GetStatusCodeMetrics(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "24h"
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		http.Error(w, "period must be one of 1h, 24h, 7d, 30d", http.StatusBadRequest)
+		return
+	}
+
+	response, err := cache.Fetch(r.Context(), h.Cache, "metrics:status-codes:"+period, h.CacheTTL, func(ctx context.Context) (models.StatusCodeMetricsResponse, error) {
+		since := time.Now().UTC().Add(-window)
+		rows, err := h.DB.CountScrapingTaskStatusCodes(ctx, database.CountScrapingTaskStatusCodesParams{
+			Since:         sql.NullTime{Time: since, Valid: true},
+			PreviousSince: sql.NullTime{Time: since.Add(-window), Valid: true},
+		})
+		if err != nil {
+			return models.StatusCodeMetricsResponse{}, err
+		}
+		return statusCodeMetrics(period, since, window, rows), nil
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to count scrape status codes")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// statusCodeMetrics builds the status code breakdown of the period starting
+// at since from the counts per status code of the period and the one before
+func statusCodeMetrics(period string, since time.Time, window time.Duration, rows []database.CountScrapingTaskStatusCodesRow) models.StatusCodeMetricsResponse {
+	response := models.StatusCodeMetricsResponse{
+		Period:        period,
+		Since:         since.Format(time.RFC3339),
+		PreviousSince: since.Add(-window).Format(time.RFC3339),
+		Classes:       []models.StatusClassCountResponse{},
+		Codes:         []models.StatusCodeCountResponse{},
+		Spikes:        []int{},
+	}
+	for _, row := range rows {
+		response.Total += row.Count
+		response.PreviousTotal += row.PreviousCount
+	}
+
+	classes := make(map[string]int64)
+	for _, row := range rows {
+		code := models.StatusCodeCountResponse{
+			StatusCode:      int(row.StatusCode),
+			Class:           statusClass(int(row.StatusCode)),
+			Count:           row.Count,
+			Percent:         percentOf(row.Count, response.Total),
+			PreviousCount:   row.PreviousCount,
+			PreviousPercent: percentOf(row.PreviousCount, response.PreviousTotal),
+			Blocking:        blockingStatusCodes[int(row.StatusCode)],
+		}
+		code.Spike = code.StatusCode >= 400 && code.Count >= spikeMinCount &&
+			code.Percent >= spikeFactor*code.PreviousPercent
+		if code.Spike {
+			response.Spikes = append(response.Spikes, code.StatusCode)
+		}
+		classes[code.Class] += code.Count
+		response.Codes = append(response.Codes, code)
+	}
+
+	for _, class := range []string{"1xx", "2xx", "3xx", "4xx", "5xx", "none"} {
+		if count, ok := classes[class]; ok {
+			response.Classes = append(response.Classes, models.StatusClassCountResponse{
+				Class:   class,
+				Count:   count,
+				Percent: percentOf(count, response.Total),
+			})
+		}
+	}
+	sort.SliceStable(response.Spikes, func(i, j int) bool {
+		return blockingStatusCodes[response.Spikes[i]] && !blockingStatusCodes[response.Spikes[j]]
+	})
+	return response
+}
+
+// statusClass returns the class of an HTTP status code, e.g. 4xx, or none
+// for 0 and codes outside 100-599
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "none"
+	}
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+// percentOf returns count as a percentage of total, rounded to one decimal
+func percentOf(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*1000) / 10
+}
+
+// GetSLOs handles GET /api/v1/metrics/slos
+//
+// Purpose: Reports the compliance of the service level objectives set in
+// the slos configuration over the period, and how much of their error
+// budget is left. Objectives count the scrapes that were due in the period,
+// as recorded on scraping tasks by the URL Manager: a scheduling latency
+// objective counts the scrapes completed within max_delay of their due time
+// (scrapes due in the last max_delay are not counted yet), a success
+// objective the completed scrapes that succeeded.
+//
+// Query Parameters:
+//   - period: Time period for compliance (1h, 24h, 7d, 30d) - default: 30d
+//
+// Reports are cached for cache.metrics_ttl when the cache is enabled.
+//
+// Response: models.SLOsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/metrics/slos
+//	GET /api/v1/metrics/slos?period=7d
+func (h *MetricsHandler) GetSLOs(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "30d"
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		http.Error(w, "period must be one of 1h, 24h, 7d, 30d", http.StatusBadRequest)
+		return
+	}
+
+	var slos config.SLOs
+	if h.Config != nil {
+		slos = h.Config.Current().SLOs
+	}
+	response, err := cache.Fetch(r.Context(), h.Cache, "metrics:slos:"+period, h.CacheTTL, func(ctx context.Context) (models.SLOsResponse, error) {
+		now := time.Now().UTC()
+		since := now.Add(-window)
+		response := models.SLOsResponse{Period: period, Since: since.Format(time.RFC3339), SLOs: make([]models.SLOResponse, 0, len(slos))}
+		for _, slo := range slos {
+			row, err := h.DB.CountScrapingTaskSLI(ctx, database.CountScrapingTaskSLIParams{
+				MaxDelayMs: slo.MaxDelay.Milliseconds(),
+				Since:      sql.NullTime{Time: since, Valid: true},
+				Until:      sql.NullTime{Time: now.Add(-slo.MaxDelay), Valid: true},
+				Project:    sql.NullString{String: slo.Project, Valid: slo.Project != ""},
+			})
+			if err != nil {
+				return models.SLOsResponse{}, fmt.Errorf("failed to count scrapes of SLO %s: %w", slo.Name, err)
+			}
+			response.SLOs = append(response.SLOs, sloResponse(slo, row))
+		}
+		return response, nil
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to compute SLO compliance")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// sloResponse computes the compliance and error budget of an objective from
+// the counts of the scrapes due in the period
+func sloResponse(slo config.SLOConfig, row database.CountScrapingTaskSLIRow) models.SLOResponse {
+	response := models.SLOResponse{
+		Name:      slo.Name,
+		Kind:      slo.Kind,
+		Objective: slo.Objective,
+		Project:   slo.Project,
+	}
+	switch slo.Kind {
+	case sharedmodels.SLOKindSchedulingLatency:
+		response.MaxDelay = slo.MaxDelay.String()
+		response.Total, response.Good = row.Total, row.OnTime
+	case sharedmodels.SLOKindSuccess:
+		response.Total, response.Good = row.Completed, row.Succeeded
+	}
+
+	bad := response.Total - response.Good
+	allowed := float64(response.Total) * (100 - slo.Objective) / 100
+	response.ErrorBudget = models.SLOErrorBudgetResponse{
+		Allowed:          int64(allowed),
+		Spent:            bad,
+		Remaining:        int64(allowed) - bad,
+		RemainingPercent: 100,
+	}
+	response.Compliance = 100
+	response.Met = true
+	if response.Total > 0 {
+		compliance := float64(response.Good) / float64(response.Total) * 100
+		response.Compliance = math.Round(compliance*100) / 100
+		response.Met = compliance >= slo.Objective
+		// Objectives are below 100%, so some scrapes are always allowed
+		response.ErrorBudget.RemainingPercent = math.Round((1-float64(bad)/allowed)*1000) / 10
+	}
+	return response
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+)
+
+func TestStatusCodeMetrics(t *testing.T) {
+	since := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	rows := []database.CountScrapingTaskStatusCodesRow{
+		{StatusCode: 0, Count: 4, PreviousCount: 10},
+		{StatusCode: 200, Count: 900, PreviousCount: 960},
+		{StatusCode: 301, Count: 6, PreviousCount: 4},
+		{StatusCode: 403, Count: 60, PreviousCount: 6},
+		{StatusCode: 404, Count: 5, PreviousCount: 0},
+		{StatusCode: 429, Count: 10, PreviousCount: 20},
+		{StatusCode: 503, Count: 15, PreviousCount: 0},
+	}
+
+	got := statusCodeMetrics("24h", since, 24*time.Hour, rows)
+	if got.Total != 1000 || got.PreviousTotal != 1000 || got.PreviousSince != "2024-03-01T00:00:00Z" {
+		t.Errorf("totals = %d, %d since %s; want 1000, 1000 since 2024-03-01", got.Total, got.PreviousTotal, got.PreviousSince)
+	}
+	if want := []int{403, 503}; !reflect.DeepEqual(got.Spikes, want) {
+		t.Errorf("spikes = %v, want %v (too few 404s, fewer 429s)", got.Spikes, want)
+	}
+
+	classes := map[string]float64{}
+	var order []string
+	for _, class := range got.Classes {
+		classes[class.Class] = class.Percent
+		order = append(order, class.Class)
+	}
+	if want := []string{"2xx", "3xx", "4xx", "5xx", "none"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("classes = %v, want %v", order, want)
+	}
+	if classes["4xx"] != 7.5 || classes["none"] != 0.4 {
+		t.Errorf("class shares = %v, want 4xx 7.5%% and none 0.4%%", classes)
+	}
+
+	forbidden := got.Codes[3]
+	if forbidden.StatusCode != 403 || !forbidden.Blocking || !forbidden.Spike || forbidden.Percent != 6 || forbidden.PreviousPercent != 0.6 {
+		t.Errorf("403 = %+v, want a blocking spike from 0.6%% to 6%%", forbidden)
+	}
+
+	empty := statusCodeMetrics("1h", since, time.Hour, nil)
+	if empty.Total != 0 || len(empty.Codes) != 0 || empty.Spikes == nil {
+		t.Errorf("empty = %+v, want no scrapes and empty lists", empty)
+	}
+}
+
+func TestSLOResponse(t *testing.T) {
+	latency := config.SLOConfig{Name: "on-time", Kind: sharedmodels.SLOKindSchedulingLatency, Objective: 99, MaxDelay: 5 * time.Minute}
+	success := config.SLOConfig{Name: "shop-success", Kind: sharedmodels.SLOKindSuccess, Objective: 95, Project: "shop"}
+	row := database.CountScrapingTaskSLIRow{Total: 1000, Completed: 990, OnTime: 995, Succeeded: 900}
+
+	got := sloResponse(latency, row)
+	want := models.SLOResponse{
+		Name: "on-time", Kind: sharedmodels.SLOKindSchedulingLatency, Objective: 99, MaxDelay: "5m0s",
+		Total: 1000, Good: 995, Compliance: 99.5, Met: true,
+		ErrorBudget: models.SLOErrorBudgetResponse{Allowed: 10, Spent: 5, Remaining: 5, RemainingPercent: 50},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("latency SLO = %+v, want %+v", got, want)
+	}
+
+	got = sloResponse(success, row)
+	if got.Total != 990 || got.Good != 900 || got.Met || got.Compliance != 90.91 {
+		t.Errorf("success SLO = %+v, want 900 of 990 completed scrapes, missed", got)
+	}
+	if budget := got.ErrorBudget; budget.Spent != 90 || budget.Remaining != 49-90 || budget.RemainingPercent != -81.8 {
+		t.Errorf("error budget = %+v, want overspent by 41 scrapes", budget)
+	}
+
+	got = sloResponse(latency, database.CountScrapingTaskSLIRow{})
+	if !got.Met || got.Compliance != 100 || got.ErrorBudget.RemainingPercent != 100 {
+		t.Errorf("SLO without scrapes = %+v, want met with the full budget", got)
+	}
+}
@@ -0,0 +1,376 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	"go_scraping_project/shared/notify"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// notificationChannelTypes are the channel types that can be managed through the API
+var notificationChannelTypes = map[string]bool{
+	notify.TypeWebhook:   true,
+	notify.TypeSlack:     true,
+	notify.TypeEmail:     true,
+	notify.TypePagerDuty: true,
+}
+
+// NotificationHandler handles notification channel HTTP requests for the web
+// scraping system. Channels stored here are where alerts, such as those of the
+// URL watchdog, are delivered, next to the channels of the notifications
+// configuration section. Channel settings hold credentials, so they are
+// stored encrypted when encryption keys are configured.
+type NotificationHandler struct {
+	Logger  *logrus.Logger
+	DB      *database.Queries   // sqlc-generated database queries
+	Keyring *encryption.Keyring // Encrypts channel settings, nil without encryption keys
+}
+
+// NewNotificationHandler creates a new notification handler with the provided logger, database queries
+// and encryption keyring. This function initializes the handler with necessary dependencies for channel
+// management.
+func NewNotificationHandler(logger *logrus.Logger, db *database.Queries, keyring *encryption.Keyring) *NotificationHandler {
+	return &NotificationHandler{
+		Logger:  logger,
+		DB:      db,
+		Keyring: keyring,
+	}
+}
+
+// ListChannels handles GET /api/v1/notification-channels
+//
+// Purpose: Lists the notification channels managed through the API, ordered
+// by name, with their secrets redacted.
+//
+// Response: models.ListNotificationChannelsResponse (200 OK) or error (500)
+//
+// Example Usage:
+//
+//	GET /api/v1/notification-channels
+func (h *NotificationHandler) ListChannels(w http.ResponseWriter, r *http.Request) {
+	stored, err := h.DB.ListNotificationChannels(r.Context())
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list notification channels")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	channels := make([]models.NotificationChannelResponse, 0, len(stored))
+	for _, channel := range stored {
+		response, err := notificationChannelResponse(channel, h.Keyring)
+		if err != nil {
+			h.Logger.WithError(err).WithField("channel", channel.Name).Warn("Skipping notification channel with invalid settings")
+			continue
+		}
+		channels = append(channels, response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ListNotificationChannelsResponse{
+		Channels: channels,
+		Total:    len(channels),
+	})
+}
+
+// GetChannel handles GET /api/v1/notification-channels/{name}
+//
+// Purpose: Retrieves a single notification channel by name, with its secrets
+// redacted.
+//
+// Path Parameters:
+//   - name: Channel name (required)
+//
+// Response: models.NotificationChannelResponse (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/notification-channels/ops-slack
+func (h *NotificationHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := h.loadChannel(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := notificationChannelResponse(channel, h.Keyring)
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", channel.Name).Error("Failed to decode notification channel settings")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateChannel handles POST /api/v1/notification-channels
+//
+// Purpose: Adds a notification channel. Types are webhook (JSON POST of the
+// alert), slack (incoming webhook), email (SMTP) and pagerduty (Events API
+// v2). A channel with the name of a configured channel replaces it. Services
+// pick up new channels within notifications.refresh_interval.
+//
+// Request Body: models.CreateNotificationChannelRequest
+// Response: models.NotificationChannelResponse (201 Created) or error (400/409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/notification-channels
+//	{
+//	  "name": "ops-pager",
+//	  "type": "pagerduty",
+//	  "settings": {"routing_key": "R0UT1NGK3Y"}
+//	}
+func (h *NotificationHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateNotificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !templateNamePattern.MatchString(req.Name) {
+		http.Error(w, "Channel name must contain only lowercase letters, digits and hyphens", http.StatusBadRequest)
+		return
+	}
+	settings, err := h.validateChannel(req.Name, req.Type, req.Settings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.DB.GetNotificationChannel(r.Context(), req.Name); err == nil {
+		http.Error(w, "Notification channel already exists", http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		h.Logger.WithError(err).WithField("channel", req.Name).Error("Failed to check notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.DB.CreateNotificationChannel(r.Context(), database.CreateNotificationChannelParams{
+		Name:     req.Name,
+		Type:     req.Type,
+		Settings: settings,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", req.Name).Error("Failed to save notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := notificationChannelResponse(created, h.Keyring)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateChannel handles PUT /api/v1/notification-channels/{name}
+//
+// Purpose: Replaces the type, settings and enabled state of a notification
+// channel. Secrets sent back as returned by GET, i.e. redacted, keep their
+// stored value, so a channel can be edited without re-entering them.
+//
+// Path Parameters:
+//   - name: Channel name (required)
+//
+// Request Body: models.UpdateNotificationChannelRequest
+// Response: models.NotificationChannelResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/notification-channels/ops-pager
+//	{"type": "pagerduty", "enabled": false, "settings": {"routing_key": "********"}}
+func (h *NotificationHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateNotificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, ok := h.loadChannel(w, r)
+	if !ok {
+		return
+	}
+	previous, err := notify.DecodeSettings(existing.Settings, h.Keyring)
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", existing.Name).Warn("Replacing notification channel with invalid settings")
+	}
+
+	settings, err := h.validateChannel(existing.Name, req.Type, req.Settings.Unredact(previous))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.DB.UpdateNotificationChannel(r.Context(), database.UpdateNotificationChannelParams{
+		Name:     existing.Name,
+		Type:     req.Type,
+		Settings: settings,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Notification channel not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("channel", existing.Name).Error("Failed to update notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := notificationChannelResponse(updated, h.Keyring)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteChannel handles DELETE /api/v1/notification-channels/{name}
+//
+// Purpose: Removes a notification channel. A configured channel of the same
+// name, if any, is used again.
+//
+// Path Parameters:
+//   - name: Channel name (required)
+//
+// Response: Success message (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/notification-channels/ops-pager
+func (h *NotificationHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	deleted, err := h.DB.DeleteNotificationChannel(r.Context(), name)
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", name).Error("Failed to delete notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Notification channel not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification channel deleted successfully"})
+}
+
+// TestChannel handles POST /api/v1/notification-channels/{name}/test
+//
+// Purpose: Sends a test alert to a notification channel, also when it is
+// disabled, to check its settings before relying on it.
+//
+// Path Parameters:
+//   - name: Channel name (required)
+//
+// Response: Success message (200 OK) or error (404/500, 502 when delivery fails)
+//
+// Example Usage:
+//
+//	POST /api/v1/notification-channels/ops-slack/test
+func (h *NotificationHandler) TestChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := h.loadChannel(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := notify.DecodeSettings(channel.Settings, h.Keyring)
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", channel.Name).Error("Failed to decode notification channel settings")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	notifier, err := notify.NewChannel(settings.Channel(channel.Name, channel.Type), nil, h.Logger)
+	if err != nil {
+		h.Logger.WithError(err).WithField("channel", channel.Name).Error("Invalid notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	alert := notify.Alert{
+		Title:    "Test notification",
+		Message:  "This is a test notification for channel " + channel.Name + ".",
+		Severity: notify.SeverityInfo,
+		Fields:   map[string]string{"channel": channel.Name},
+		Time:     time.Now().UTC(),
+	}
+	if err := notifier.Notify(r.Context(), alert); err != nil {
+		h.Logger.WithError(err).WithField("channel", channel.Name).Warn("Test notification failed")
+		http.Error(w, "Test notification failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Test notification sent successfully"})
+}
+
+// loadChannel loads the channel named in the path, writing the error
+// response if it cannot
+func (h *NotificationHandler) loadChannel(w http.ResponseWriter, r *http.Request) (database.NotificationChannel, bool) {
+	name := mux.Vars(r)["name"]
+
+	channel, err := h.DB.GetNotificationChannel(r.Context(), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Notification channel not found", http.StatusNotFound)
+			return database.NotificationChannel{}, false
+		}
+		h.Logger.WithError(err).WithField("channel", name).Error("Failed to get notification channel")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return database.NotificationChannel{}, false
+	}
+	return channel, true
+}
+
+// validateChannel checks the type and settings of a channel and returns the
+// settings in their stored form, encrypted when encryption keys are configured
+func (h *NotificationHandler) validateChannel(name, channelType string, settings notify.ChannelSettings) ([]byte, error) {
+	if !notificationChannelTypes[channelType] {
+		return nil, &models.ValidationError{Field: "type", Message: "Type must be webhook, slack, email or pagerduty"}
+	}
+	if settings.TimeoutSeconds < 0 {
+		return nil, &models.ValidationError{Field: "settings.timeout_seconds", Message: "Timeout must not be negative"}
+	}
+	if _, err := notify.NewChannel(settings.Channel(name, channelType), nil, h.Logger); err != nil {
+		return nil, &models.ValidationError{Field: "settings", Message: err.Error()}
+	}
+
+	return notify.EncodeSettings(settings, h.Keyring)
+}
+
+// notificationChannelResponse converts a database channel row to its API
+// representation, decrypting its settings and redacting its secrets
+func notificationChannelResponse(channel database.NotificationChannel, keyring *encryption.Keyring) (models.NotificationChannelResponse, error) {
+	settings, err := notify.DecodeSettings(channel.Settings, keyring)
+	if err != nil {
+		return models.NotificationChannelResponse{}, err
+	}
+
+	return models.NotificationChannelResponse{
+		Name:      channel.Name,
+		Type:      channel.Type,
+		Enabled:   channel.Enabled,
+		Settings:  settings.Redacted(),
+		CreatedAt: channel.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: channel.UpdatedAt.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/notify"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestValidateChannel(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewNotificationHandler(logger, nil, nil)
+
+	tests := []struct {
+		name        string
+		channelType string
+		settings    notify.ChannelSettings
+		wantErr     bool
+	}{
+		{name: "slack", channelType: notify.TypeSlack, settings: notify.ChannelSettings{URL: "https://hooks.slack.com/services/T/B/x"}},
+		{name: "pagerduty", channelType: notify.TypePagerDuty, settings: notify.ChannelSettings{RoutingKey: "key"}},
+		{name: "email", channelType: notify.TypeEmail, settings: notify.ChannelSettings{Email: &notify.EmailSettings{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}}}},
+		{name: "email without recipients", channelType: notify.TypeEmail, settings: notify.ChannelSettings{Email: &notify.EmailSettings{Host: "smtp.example.com", From: "a@example.com"}}, wantErr: true},
+		{name: "webhook without url", channelType: notify.TypeWebhook, wantErr: true},
+		{name: "log", channelType: notify.TypeLog, wantErr: true},
+		{name: "negative timeout", channelType: notify.TypePagerDuty, settings: notify.ChannelSettings{RoutingKey: "key", TimeoutSeconds: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.validateChannel("ops", tt.channelType, tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateChannel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateChannelValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewNotificationHandler(logger, nil, nil)
+
+	for _, body := range []string{
+		`{"name": "Ops Slack", "type": "slack", "settings": {"url": "https://hooks.slack.com/x"}}`,
+		`{"name": "ops-sms", "type": "sms", "settings": {}}`,
+		`{"name": "ops-pager", "type": "pagerduty", "settings": {}}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.CreateChannel(rec, httptest.NewRequest(http.MethodPost, "/api/v1/notification-channels", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,501 @@
+package types
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/parser"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// templateNamePattern restricts template names to URL-safe identifiers
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ParserHandler handles parser-related HTTP requests for the web scraping system.
+// It provides endpoints for managing parser templates, which are reusable
+// parser configurations for common page types that URLs can reference by name.
+type ParserHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Fetch  *Fetcher          // Requests pages for selector suggestions, http.DefaultClient when nil
+}
+
+// NewParserHandler creates a new parser handler with the provided logger and database queries.
+// This function initializes the handler with necessary dependencies for template management.
+func NewParserHandler(logger *logrus.Logger, db *database.Queries) *ParserHandler {
+	return &ParserHandler{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// ListTemplates handles GET /api/v1/parser/templates
+//
+// Purpose: Lists all parser templates available for use in URL configurations.
+// Built-in templates (generic-article, product-page, job-listing, rss-item)
+// are always returned first, followed by user-defined templates.
+//
+// Response: models.ListParserTemplatesResponse (200 OK) or error (500)
+//
+// Example Usage:
+//
+//	GET /api/v1/parser/templates
+func (h *ParserHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	stored, err := h.DB.ListParserTemplates(r.Context())
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list parser templates")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	builtins := parser.BuiltinTemplates()
+	templates := make([]models.ParserTemplateResponse, 0, len(builtins)+len(stored))
+	for _, tmpl := range builtins {
+		templates = append(templates, builtinTemplateResponse(tmpl))
+	}
+	for _, tmpl := range stored {
+		response, err := storedTemplateResponse(tmpl)
+		if err != nil {
+			h.Logger.WithError(err).WithField("template", tmpl.Name).Warn("Skipping parser template with invalid config")
+			continue
+		}
+		templates = append(templates, response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ListParserTemplatesResponse{
+		Templates: templates,
+		Total:     len(templates),
+	})
+}
+
+// GetTemplate handles GET /api/v1/parser/templates/{name}
+//
+// Purpose: Retrieves a single parser template by name, including the full
+// selector and rule configuration it contributes to URLs that reference it.
+//
+// Path Parameters:
+//   - name: Template name (required)
+//
+// Response: models.ParserTemplateResponse (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/parser/templates/product-page
+func (h *ParserHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if tmpl, ok := parser.BuiltinTemplate(name); ok {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(builtinTemplateResponse(tmpl))
+		return
+	}
+
+	stored, err := h.DB.GetParserTemplateByName(r.Context(), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Parser template not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("template", name).Error("Failed to get parser template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := storedTemplateResponse(stored)
+	if err != nil {
+		h.Logger.WithError(err).WithField("template", name).Error("Failed to decode parser template config")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateTemplate handles POST /api/v1/parser/templates
+//
+// Purpose: Registers a user-defined parser template. Names of built-in
+// templates are reserved and cannot be reused.
+//
+// Request Body: models.CreateParserTemplateRequest
+// Response: models.ParserTemplateResponse (201 Created) or error (400/409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/parser/templates
+//	{
+//	  "name": "shop-x-product",
+//	  "page_type": "product",
+//	  "config": {"selectors": {"title": "h1.name", "price": ".amount"}}
+//	}
+func (h *ParserHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateParserTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !templateNamePattern.MatchString(req.Name) {
+		http.Error(w, "Template name must contain only lowercase letters, digits and hyphens", http.StatusBadRequest)
+		return
+	}
+	if err := validateTemplateConfig(req.PageType, req.Config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if parser.IsBuiltin(req.Name) {
+		http.Error(w, "Template name is reserved by a built-in template", http.StatusConflict)
+		return
+	}
+
+	if _, err := h.DB.GetParserTemplateByName(r.Context(), req.Name); err == nil {
+		http.Error(w, "Parser template already exists", http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		h.Logger.WithError(err).WithField("template", req.Name).Error("Failed to check parser template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	configJSON, err := json.Marshal(req.Config)
+	if err != nil {
+		http.Error(w, "Invalid parser configuration", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.DB.CreateParserTemplate(r.Context(), database.CreateParserTemplateParams{
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		PageType:    req.PageType,
+		Config:      configJSON,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("template", req.Name).Error("Failed to save parser template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := storedTemplateResponse(created)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateTemplate handles PUT /api/v1/parser/templates/{name}
+//
+// Purpose: Replaces the configuration of a user-defined parser template.
+// URLs referencing the template pick up the change on their next parse.
+// Built-in templates are read-only.
+//
+// Path Parameters:
+//   - name: Template name (required)
+//
+// Request Body: models.UpdateParserTemplateRequest
+// Response: models.ParserTemplateResponse (200 OK) or error (400/404/409/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/parser/templates/shop-x-product
+//	{
+//	  "page_type": "product",
+//	  "config": {"selectors": {"title": "h1.name", "price": ".price-now"}}
+//	}
+func (h *ParserHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if parser.IsBuiltin(name) {
+		http.Error(w, "Built-in templates cannot be modified", http.StatusConflict)
+		return
+	}
+
+	var req models.UpdateParserTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateTemplateConfig(req.PageType, req.Config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	configJSON, err := json.Marshal(req.Config)
+	if err != nil {
+		http.Error(w, "Invalid parser configuration", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.DB.UpdateParserTemplate(r.Context(), database.UpdateParserTemplateParams{
+		Name:        name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		PageType:    req.PageType,
+		Config:      configJSON,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Parser template not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("template", name).Error("Failed to update parser template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := storedTemplateResponse(updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteTemplate handles DELETE /api/v1/parser/templates/{name}
+//
+// Purpose: Removes a user-defined parser template. URLs that still reference
+// the template keep their own selectors but no longer inherit from it.
+// Built-in templates cannot be deleted.
+//
+// Path Parameters:
+//   - name: Template name (required)
+//
+// Response: Success message (200 OK) or error (404/409/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/parser/templates/shop-x-product
+func (h *ParserHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if parser.IsBuiltin(name) {
+		http.Error(w, "Built-in templates cannot be deleted", http.StatusConflict)
+		return
+	}
+
+	deleted, err := h.DB.DeleteParserTemplate(r.Context(), name)
+	if err != nil {
+		h.Logger.WithError(err).WithField("template", name).Error("Failed to delete parser template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Parser template not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Parser template deleted successfully"})
+}
+
+// parserTemplateStore looks up the parser templates stored in the
+// parser_templates table, e.g. *database.Queries or *services.URLService
+type parserTemplateStore interface {
+	GetParserTemplateByName(ctx context.Context, name string) (database.ParserTemplate, error)
+}
+
+// lookupParserTemplate resolves a template name against the built-in
+// templates first and the stored templates second.
+// It returns an error matching sql.ErrNoRows when no template with the name exists.
+func lookupParserTemplate(ctx context.Context, templates parserTemplateStore, name string) (parser.Template, error) {
+	if tmpl, ok := parser.BuiltinTemplate(name); ok {
+		return tmpl, nil
+	}
+
+	stored, err := templates.GetParserTemplateByName(ctx, name)
+	if err != nil {
+		return parser.Template{}, err
+	}
+
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(stored.Config, &config); err != nil {
+		return parser.Template{}, err
+	}
+
+	return parser.Template{
+		Name:        stored.Name,
+		Description: stored.Description.String,
+		PageType:    stored.PageType,
+		Config:      config,
+	}, nil
+}
+
+// compileParserConfig applies the template of a URL's parser config, if
+// any, and compiles the result. It also returns the schema of the records
+// it parses, which is the template's page type. An unknown template or an
+// invalid config is reported as a *models.ValidationError.
+func compileParserConfig(ctx context.Context, templates parserTemplateStore, config *sharedmodels.ParserConfig, field string) (*parser.Parser, string, error) {
+	var schema string
+	if config.Template != "" {
+		tmpl, err := lookupParserTemplate(ctx, templates, config.Template)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", &models.ValidationError{Field: field + ".template", Message: "Unknown parser template: " + config.Template}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		config = parser.Apply(tmpl, config)
+		schema = tmpl.PageType
+	}
+
+	compiled, err := parser.NewParser(config)
+	if err != nil {
+		return nil, "", &models.ValidationError{Field: field, Message: "Invalid parser config: " + err.Error()}
+	}
+	return compiled, schema, nil
+}
+
+// validateTemplateConfig validates the page type and configuration of a template
+func validateTemplateConfig(pageType string, config *sharedmodels.ParserConfig) error {
+	if pageType == "" {
+		return &models.ValidationError{Field: "page_type", Message: "Page type is required"}
+	}
+	if config == nil || (len(config.Selectors) == 0 && len(config.Rules) == 0) {
+		return &models.ValidationError{Field: "config", Message: "Template must define at least one selector or rule"}
+	}
+	if config.Template != "" {
+		return &models.ValidationError{Field: "config.template", Message: "Templates cannot inherit from other templates"}
+	}
+	return nil
+}
+
+// SuggestSelectors handles POST /api/v1/parser/suggest
+//
+// Purpose: Bootstraps parser config authoring. The page is fetched, or
+// taken from the request, and candidate selectors are proposed for its
+// common fields (title, published, price, content, author, image and
+// description) from OpenGraph properties, schema.org microdata, the
+// heading structure, semantic elements and conventional class names. Each
+// suggestion comes with the value it extracts from the page. Nothing is
+// stored.
+//
+// Request Body: models.SuggestSelectorsRequest
+// Response: models.SuggestSelectorsResponse (200 OK) or error (400, 502
+// when the page cannot be fetched or answers with an error)
+//
+// Example Usage:
+//
+//	POST /api/v1/parser/suggest
+//	{
+//	  "url": "https://example.com/products/kettle"
+//	}
+func (h *ParserHandler) SuggestSelectors(w http.ResponseWriter, r *http.Request) {
+	var req models.SuggestSelectorsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := models.SuggestSelectorsResponse{URL: req.URL}
+	doc := parser.Document{URL: req.URL, Body: req.HTML}
+	if req.HTML == "" {
+		if err := validateTargetURL(req.URL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fetched, err := fetchPage(r.Context(), h.Fetch, req.URL, req.UserAgent)
+		if err != nil {
+			http.Error(w, "Failed to fetch page: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		if fetched.StatusCode < 200 || fetched.StatusCode >= 300 {
+			http.Error(w, fmt.Sprintf("Page returned %d", fetched.StatusCode), http.StatusBadGateway)
+			return
+		}
+		doc = fetched
+		response.StatusCode = fetched.StatusCode
+	}
+
+	suggestions, err := parser.Suggest(doc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response.Suggestions = suggestions
+	if response.Suggestions == nil {
+		response.Suggestions = []parser.Suggestion{}
+	}
+	response.Config = parser.SuggestedConfig(suggestions)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// fetchPage fetches a page once, as a scrape would, within the limits of
+// the test fetch of URL validation
+func fetchPage(ctx context.Context, fetch *Fetcher, target, userAgent string) (parser.Document, error) {
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	ctx, cancel := context.WithTimeout(ctx, validationFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return parser.Document{}, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := fetch.Client(target).Do(req)
+	if err != nil {
+		return parser.Document{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, validationMaxBodyBytes))
+	if err != nil {
+		return parser.Document{}, err
+	}
+	return parser.Document{
+		URL:         resp.Request.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}, nil
+}
+
+// builtinTemplateResponse converts a built-in template to its API representation
+func builtinTemplateResponse(tmpl parser.Template) models.ParserTemplateResponse {
+	return models.ParserTemplateResponse{
+		Name:        tmpl.Name,
+		Description: tmpl.Description,
+		PageType:    tmpl.PageType,
+		Config:      tmpl.Config,
+		BuiltIn:     true,
+	}
+}
+
+// storedTemplateResponse converts a database template row to its API representation
+func storedTemplateResponse(tmpl database.ParserTemplate) (models.ParserTemplateResponse, error) {
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(tmpl.Config, &config); err != nil {
+		return models.ParserTemplateResponse{}, err
+	}
+
+	return models.ParserTemplateResponse{
+		Name:        tmpl.Name,
+		Description: tmpl.Description.String,
+		PageType:    tmpl.PageType,
+		Config:      config,
+		CreatedAt:   tmpl.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   tmpl.UpdatedAt.Format(time.RFC3339),
+	}, nil
+}
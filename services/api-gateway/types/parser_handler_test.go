@@ -0,0 +1,71 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go_scraping_project/services/api-gateway/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSuggestSelectors(t *testing.T) {
+	const page = `<html><head><meta property="og:title" content="Steel kettle"></head>
+<body><h1>Steel kettle</h1><span class="product-price">€24.90</span></body></html>`
+	var userAgent string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/kettle" {
+			http.NotFound(w, r)
+			return
+		}
+		userAgent = r.UserAgent()
+		w.Write([]byte(page))
+	}))
+	defer site.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewParserHandler(logger, nil)
+	given, _ := json.Marshal(models.SuggestSelectorsRequest{HTML: page})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFetch  int
+	}{
+		{"fetched", `{"url": "` + site.URL + `/kettle"}`, http.StatusOK, http.StatusOK},
+		{"given HTML", string(given), http.StatusOK, 0},
+		{"page error", `{"url": "` + site.URL + `/missing"}`, http.StatusBadGateway, 0},
+		{"invalid URL", `{"url": "example.com/kettle"}`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.SuggestSelectors(w, httptest.NewRequest(http.MethodPost, "/api/v1/parser/suggest", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response models.SuggestSelectorsResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != tt.wantFetch {
+				t.Errorf("status_code = %d, want %d", response.StatusCode, tt.wantFetch)
+			}
+			if len(response.Suggestions) != 3 || response.Config.Selectors["title"] != "meta[property='og:title']" || response.Config.Selectors["price"] != ".product-price" {
+				t.Errorf("response = %+v, want title and price suggestions", response)
+			}
+		})
+	}
+	if userAgent != defaultUserAgent {
+		t.Errorf("page fetched as %q, want the default user agent", userAgent)
+	}
+}
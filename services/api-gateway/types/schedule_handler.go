@@ -0,0 +1,185 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Forecast limits
+const (
+	maxForecastWindow  = 7 * 24 * time.Hour
+	maxForecastURLs    = 10000 // URLs read from the database for one forecast
+	maxForecastScrapes = 10000 // Scrapes returned by one forecast
+)
+
+// Priorities of planned scrapes
+const (
+	SchedulePriorityHigh   = "high"   // A retry, or the next scrape of a degraded URL
+	SchedulePriorityNormal = "normal" // A regular scrape
+)
+
+// ScheduleHandler handles scrape schedule HTTP requests for the web scraping system.
+// It forecasts the scrapes the URL Manager will dispatch, so operators can
+// anticipate load and check that schedule changes took effect.
+type ScheduleHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+}
+
+// NewScheduleHandler creates a new schedule handler with the provided logger and database queries.
+// This function initializes the handler with necessary dependencies.
+func NewScheduleHandler(logger *logrus.Logger, db *database.Queries) *ScheduleHandler {
+	return &ScheduleHandler{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// GetUpcomingScrapes handles GET /api/v1/schedule/upcoming
+//
+// Purpose: Returns the timeline of scrapes planned within a window, starting
+// now. Each scheduled URL is listed at its next scrape time and then once
+// per frequency until the end of the window; overdue URLs are listed as due
+// now. Retries and degraded URLs are high priority. The number of planned
+// scrapes per hour shows upcoming load spikes.
+//
+// Query Parameters:
+//   - window: How far ahead to look, e.g. 30m, 6h, 24h, at most 168h (default: 24h)
+//   - limit: Maximum number of scrapes to list, max 10000 (default: 1000)
+//
+// Response: models.UpcomingScrapesResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/schedule/upcoming
+//	GET /api/v1/schedule/upcoming?window=6h&limit=200
+func (h *ScheduleHandler) GetUpcomingScrapes(w http.ResponseWriter, r *http.Request) {
+	window := 24 * time.Hour
+	if value := r.URL.Query().Get("window"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 || d > maxForecastWindow {
+			http.Error(w, "window must be a duration between 1s and 168h", http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	limit := 1000
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxForecastScrapes {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxForecastScrapes), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	now := time.Now().UTC()
+	until := now.Add(window)
+	urls, err := h.DB.GetURLsScheduledForScraping(r.Context(), database.GetURLsScheduledForScrapingParams{
+		NextScrapeAt:   sql.NullTime{Time: time.Unix(0, 0), Valid: true},
+		NextScrapeAt_2: sql.NullTime{Time: until, Valid: true},
+		Limit:          maxForecastURLs,
+	})
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to get scheduled URLs")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	scrapes := forecastScrapes(urls, now, until)
+	response := models.UpcomingScrapesResponse{
+		Window:    window.String(),
+		From:      now.Format(time.RFC3339),
+		Until:     until.Format(time.RFC3339),
+		Total:     len(scrapes),
+		Truncated: len(urls) == maxForecastURLs || len(scrapes) > limit,
+		Load:      scheduleLoad(scrapes, now, until),
+		Scrapes:   make([]models.UpcomingScrapeResponse, 0, min(len(scrapes), limit)),
+	}
+	for _, scrape := range scrapes[:min(len(scrapes), limit)] {
+		response.Scrapes = append(response.Scrapes, models.UpcomingScrapeResponse{
+			URLID:       scrape.url.ID.String(),
+			URL:         scrape.url.Url,
+			ScheduledAt: scrape.at.Format(time.RFC3339),
+			Priority:    scrape.priority,
+			Overdue:     scrape.overdue,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// plannedScrape is a scrape forecast for a URL
+type plannedScrape struct {
+	url      database.Url
+	at       time.Time
+	priority string
+	overdue  bool
+}
+
+// forecastScrapes lists the scrapes of the given URLs planned from now until
+// the end of the window, in time order. The first scrape of a URL is at its
+// next scrape time, or now if that has passed; the following ones repeat at
+// its frequency.
+func forecastScrapes(urls []database.Url, now, until time.Time) []plannedScrape {
+	var scrapes []plannedScrape
+	for _, url := range urls {
+		if !url.NextScrapeAt.Valid {
+			continue
+		}
+		first := plannedScrape{url: url, at: url.NextScrapeAt.Time.UTC(), priority: SchedulePriorityNormal}
+		if first.at.Before(now) {
+			first.at = now
+			first.overdue = true
+		}
+		if url.Status == "retry" || url.Status == "degraded" {
+			first.priority = SchedulePriorityHigh
+		}
+		if first.at.After(until) {
+			continue
+		}
+		scrapes = append(scrapes, first)
+
+		interval, err := utils.ParseFrequency(url.Frequency)
+		if err != nil {
+			continue
+		}
+		for at := first.at.Add(interval); !at.After(until); at = at.Add(interval) {
+			scrapes = append(scrapes, plannedScrape{url: url, at: at, priority: SchedulePriorityNormal})
+		}
+	}
+
+	sort.SliceStable(scrapes, func(i, j int) bool {
+		if !scrapes[i].at.Equal(scrapes[j].at) {
+			return scrapes[i].at.Before(scrapes[j].at)
+		}
+		return scrapes[i].url.Url < scrapes[j].url.Url
+	})
+	return scrapes
+}
+
+// scheduleLoad counts the planned scrapes per hour of the window
+func scheduleLoad(scrapes []plannedScrape, now, until time.Time) []models.ScheduleLoadResponse {
+	start := now.Truncate(time.Hour)
+	load := make([]models.ScheduleLoadResponse, int(until.Sub(start)/time.Hour)+1)
+	for i := range load {
+		load[i].Start = start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+	}
+	for _, scrape := range scrapes {
+		load[int(scrape.at.Sub(start)/time.Hour)].Count++
+	}
+	return load
+}
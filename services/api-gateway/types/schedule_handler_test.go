@@ -0,0 +1,69 @@
+package types
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+)
+
+func TestForecastScrapes(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	until := now.Add(3 * time.Hour)
+	scheduled := func(address, frequency, status string, next time.Time) database.Url {
+		return database.Url{
+			ID:           uuid.New(),
+			Url:          address,
+			Frequency:    frequency,
+			Status:       status,
+			NextScrapeAt: sql.NullTime{Time: next, Valid: true},
+		}
+	}
+
+	urls := []database.Url{
+		scheduled("https://example.com/hourly", "1h", "pending", now.Add(15*time.Minute)),
+		scheduled("https://example.com/overdue", "1d", "retry", now.Add(-time.Hour)),
+		scheduled("https://example.com/later", "1w", "pending", until.Add(time.Minute)),
+		{ID: uuid.New(), Url: "https://example.com/unscheduled", Frequency: "1h", Status: "pending"},
+	}
+
+	scrapes := forecastScrapes(urls, now, until)
+
+	type scrape struct {
+		url      string
+		at       time.Time
+		priority string
+		overdue  bool
+	}
+	want := []scrape{
+		{"https://example.com/overdue", now, SchedulePriorityHigh, true},
+		{"https://example.com/hourly", now.Add(15 * time.Minute), SchedulePriorityNormal, false},
+		{"https://example.com/hourly", now.Add(75 * time.Minute), SchedulePriorityNormal, false},
+		{"https://example.com/hourly", now.Add(135 * time.Minute), SchedulePriorityNormal, false},
+	}
+	if len(scrapes) != len(want) {
+		t.Fatalf("forecastScrapes() returned %d scrapes, want %d", len(scrapes), len(want))
+	}
+	for i, w := range want {
+		got := scrape{scrapes[i].url.Url, scrapes[i].at, scrapes[i].priority, scrapes[i].overdue}
+		if got != w {
+			t.Errorf("scrape %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	load := scheduleLoad(scrapes, now, until)
+	counts := make([]int, len(load))
+	for i, hour := range load {
+		counts[i] = hour.Count
+	}
+	if wantCounts := []int{2, 1, 1, 0}; !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("scheduleLoad() counts = %v, want %v", counts, wantCounts)
+	}
+	if load[0].Start != "2024-01-01T10:00:00Z" {
+		t.Errorf("scheduleLoad() starts at %s, want 2024-01-01T10:00:00Z", load[0].Start)
+	}
+}
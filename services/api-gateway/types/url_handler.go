@@ -1,38 +1,89 @@
 package types
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/services/api-gateway/services"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/control"
 	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/dnscache"
+	"go_scraping_project/shared/domain"
+	"go_scraping_project/shared/encryption"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/parser"
+	"go_scraping_project/shared/pii"
+	"go_scraping_project/shared/render"
+	"go_scraping_project/shared/robots"
+	"go_scraping_project/shared/utils"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/sqlc-dev/pqtype"
+	"gopkg.in/yaml.v3"
+)
+
+// domainPattern restricts bulk action domain filters to plain host names
+var domainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// Limits for URL configuration imports
+const (
+	maxImportURLs      = 1000
+	maxImportBodyBytes = 10 << 20
+)
+
+// maxReparseSnapshots bounds the raw HTML snapshots parsed by one reparse call
+const maxReparseSnapshots = 500
+
+// defaultUserAgent is the user agent of URLs created without one
+const defaultUserAgent = "GoScrapingBot/1.0"
+
+// Limits of the test fetch made when validating a URL
+const (
+	validationFetchTimeout = 10 * time.Second
+	validationMaxBodyBytes = 5 << 20
 )
 
 // URLHandler handles URL-related HTTP requests for the web scraping system.
 // It provides endpoints for managing URLs that need to be scraped, including
 // creation, listing, updating, deletion, and status monitoring.
 type URLHandler struct {
-	Logger *logrus.Logger
-	DB     *database.Queries // sqlc-generated database queries
+	Logger  *logrus.Logger
+	URLs    *services.URLService // URL reads and changes
+	Config  *config.Watcher
+	Control *control.Client     // URL Manager control API, for immediate scrapes
+	Keyring *encryption.Keyring // Encrypts sensitive parsed fields, nil without encryption keys
+	Fetch   *Fetcher            // Requests target sites for validation and probes, http.DefaultClient when nil
 }
 
-// NewURLHandler creates a new URL handler with the provided logger and database queries.
-// This function initializes the handler with necessary dependencies for URL management.
-func NewURLHandler(logger *logrus.Logger, db *database.Queries) *URLHandler {
+// NewURLHandler creates a new URL handler with the provided logger, URL service,
+// configuration watcher, URL Manager control client and encryption keyring. This function initializes the handler with necessary dependencies
+// for URL management.
+func NewURLHandler(logger *logrus.Logger, urls *services.URLService, cfg *config.Watcher, ctrl *control.Client, keyring *encryption.Keyring) *URLHandler {
 	return &URLHandler{
-		Logger: logger,
-		DB:     db,
+		Logger:  logger,
+		URLs:    urls,
+		Config:  cfg,
+		Control: ctrl,
+		Keyring: keyring,
 	}
 }
 
@@ -43,7 +94,7 @@ func NewURLHandler(logger *logrus.Logger, db *database.Queries) *URLHandler {
 // and returns the created URL with its generated ID.
 //
 // Request Body: models.CreateURLRequest
-// Response: models.CreateURLResponse (201 Created) or error (400/500)
+// Response: models.CreateURLResponse (201 Created) or error (400/409/500)
 //
 // Example Usage:
 //
@@ -52,8 +103,23 @@ func NewURLHandler(logger *logrus.Logger, db *database.Queries) *URLHandler {
 //	  "url": "https://example.com",
 //	  "frequency": "1h",
 //	  "parser_config": {
-//	    "selectors": {"title": "h1", "content": ".content"}
-//	  }
+//	    "template": "generic-article",
+//	    "selectors": {"title": "h1.headline"}
+//	  },
+//	  "retry_policy": {
+//	    "max_attempts": 5,
+//	    "backoff_base_ms": 2000,
+//	    "backoff_cap_ms": 600000,
+//	    "retry_on_status": [429, 503]
+//	  },
+//	  "tags": ["news", "team:growth"],
+//	  "region": "eu-west",
+//	  "assertions": {
+//	    "status": 200,
+//	    "contains": ["Add to cart"],
+//	    "selectors": [".price"]
+//	  },
+//	  "archive_policy": {"mode": "sample", "sample_every": 10}
 //	}
 func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateURLRequest
@@ -70,12 +136,213 @@ func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Make sure a referenced parser template exists
+	if req.ParserConfig != nil && req.ParserConfig.Template != "" {
+		if _, err := lookupParserTemplate(r.Context(), h.URLs, req.ParserConfig.Template); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, "Unknown parser template: "+req.ParserConfig.Template, http.StatusBadRequest)
+				return
+			}
+			h.Logger.WithError(err).WithField("template", req.ParserConfig.Template).Error("Failed to resolve parser template")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	params, err := h.createURLParams(&req)
+	if err != nil {
+		h.Logger.WithError(err).WithField("url", req.URL).Error("Failed to prepare URL")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	createdURL, err := h.URLs.CreateURL(r.Context(), params)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to save URL to database")
+		return
+	}
+	h.recordParserConfigVersion(r, createdURL.ID, createdURL.ParserConfig, "created")
+
+	// Prepare response
+	response := models.CreateURLResponse{
+		ID:        createdURL.ID.String(),
+		URL:       createdURL.Url,
+		Status:    createdURL.Status,
+		CreatedAt: createdURL.CreatedAt.Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ValidateURL handles POST /api/v1/urls/validate
+//
+// Purpose: Dry run of URL creation. Runs the full validation of a URL
+// configuration without creating anything: the request is validated as by
+// CreateURL, including its frequency and parser config, and the page is
+// fetched once with the URL's user agent to check that it is reachable,
+// allowed by robots.txt, passes the content assertions and has content for
+// every selector and rule. Problems that would reject the URL are returned
+// as errors; problems that would not, such as a site answering 403 to the
+// default user agent, are returned as warnings.
+//
+// Request Body: models.CreateURLRequest
+// Response: models.URLValidationResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/validate
+//	{
+//	  "url": "https://example.com/products/kettle",
+//	  "frequency": "1h",
+//	  "parser_config": {"template": "product-page"}
+//	}
+func (h *URLHandler) ValidateURL(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response := models.URLValidationResponse{
+		Errors:   []models.ValidationError{},
+		Warnings: []string{},
+	}
+	var validationErr *models.ValidationError
+	if err := h.validateCreateURLRequest(&req); errors.As(err, &validationErr) {
+		response.Errors = append(response.Errors, *validationErr)
+	}
+
+	// Compile the parser config unless validation already rejected part of it
+	var compiled *parser.Parser
+	if req.ParserConfig != nil && (validationErr == nil || !strings.HasPrefix(validationErr.Field, "parser_config")) {
+		p, _, err := compileParserConfig(r.Context(), h.URLs, req.ParserConfig, "parser_config")
+		var configErr *models.ValidationError
+		switch {
+		case errors.As(err, &configErr):
+			response.Errors = append(response.Errors, *configErr)
+		case err != nil:
+			h.Logger.WithError(err).WithField("template", req.ParserConfig.Template).Error("Failed to resolve parser template")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		compiled = p
+	}
+
+	if h.validateFrequency(req.Frequency) == nil {
+		if next, err := h.calculateNextScrapeTime(req.Frequency, time.Now().UTC()); err == nil {
+			response.NextScrapeAt = next.Format(time.RFC3339)
+		}
+	}
+	if validateTargetURL(req.URL) == nil {
+		h.probeURL(r.Context(), &req, compiled, &response)
+	}
+	response.Valid = len(response.Errors) == 0
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// probeURL fetches the page of a URL being validated once, as a scrape
+// would, and adds warnings about anything that would keep its scrapes from
+// succeeding
+func (h *URLHandler) probeURL(ctx context.Context, req *models.CreateURLRequest, compiled *parser.Parser, response *models.URLValidationResponse) {
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return
+	}
+	userAgent, agentName := req.UserAgent, fmt.Sprintf("user agent %q", req.UserAgent)
+	if userAgent == "" {
+		userAgent, agentName = defaultUserAgent, fmt.Sprintf("default user agent %q", defaultUserAgent)
+	}
+	timeout := validationFetchTimeout
+	if req.Timeout > 0 {
+		timeout = min(timeout, time.Duration(req.Timeout)*time.Second)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx, dns := dnscache.WithTiming(ctx)
+
+	// robots.txt
+	if rules, err := robots.Fetch(ctx, h.Fetch.Client(req.URL), target, userAgent); err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("robots.txt could not be read: %v", err))
+	} else {
+		allowed := rules.Allowed(userAgent, target.RequestURI())
+		response.RobotsAllowed = &allowed
+		if !allowed {
+			warning := fmt.Sprintf("robots.txt disallows %s for %s", target.RequestURI(), agentName)
+			if h.Config.Current().Scraping.RespectRobotsTxt {
+				warning += "; scrapes will be skipped while respect_robots_txt is enabled"
+			}
+			response.Warnings = append(response.Warnings, warning)
+		}
+	}
+
+	// The page itself
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site could not be requested: %v", err))
+		return
+	}
+	httpReq.Header.Set("User-Agent", userAgent)
+	start := time.Now()
+	resp, err := h.Fetch.Client(req.URL).Do(httpReq)
+	response.DNSMs = dns.Milliseconds()
+	if err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site is not reachable: %v", err))
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, validationMaxBodyBytes))
+	elapsed := time.Since(start)
+	response.StatusCode = resp.StatusCode
+	response.ResponseTimeMs = elapsed.Milliseconds()
+	if err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site response could not be read: %v", err))
+		return
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site returns %d to %s", resp.StatusCode, agentName))
+	}
+	if final := resp.Request.URL; final.Host != target.Host {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site redirects to another host: %s", final.Host))
+	}
+	if elapsed > timeout/2 {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("site took %s to respond, close to the %s timeout", elapsed.Round(time.Millisecond), timeout))
+	}
+
+	doc := parser.Document{
+		URL:         req.URL,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}
+	for _, failure := range parser.EvaluateAssertions(req.Assertions, doc) {
+		response.Warnings = append(response.Warnings, "assertion fails: "+failure)
+	}
+	if compiled == nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+	record, err := compiled.Parse(ctx, doc)
+	if err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("parser config fails on the page: %v", err))
+		return
+	}
+	for _, name := range compiled.MissingFields(record) {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("parser field %q extracts nothing from the page", name))
+	}
+}
+
+// createURLParams converts a validated request into the parameters for
+// storing a new URL, applying defaults and scheduling the first scrape
+func (h *URLHandler) createURLParams(req *models.CreateURLRequest) (database.CreateURLParams, error) {
 	// Calculate next scrape time
 	nextScrape, err := h.calculateNextScrapeTime(req.Frequency, time.Now().UTC())
 	if err != nil {
-		h.Logger.WithError(err).Error("Failed to calculate next scrape time")
-		http.Error(w, "Invalid frequency format", http.StatusBadRequest)
-		return
+		return database.CreateURLParams{}, &models.ValidationError{Field: "frequency", Message: "Invalid frequency format"}
 	}
 
 	// Prepare parser config JSON if provided
@@ -83,9 +350,7 @@ func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 	if req.ParserConfig != nil {
 		configBytes, err := json.Marshal(req.ParserConfig)
 		if err != nil {
-			h.Logger.WithError(err).Error("Failed to marshal parser config")
-			http.Error(w, "Invalid parser configuration", http.StatusBadRequest)
-			return
+			return database.CreateURLParams{}, &models.ValidationError{Field: "parser_config", Message: "Invalid parser configuration"}
 		}
 		parserConfigJSON = pqtype.NullRawMessage{
 			RawMessage: configBytes,
@@ -93,6 +358,45 @@ func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Prepare retry policy JSON if provided
+	var retryPolicyJSON pqtype.NullRawMessage
+	if req.RetryPolicy != nil {
+		policyBytes, err := json.Marshal(req.RetryPolicy)
+		if err != nil {
+			return database.CreateURLParams{}, &models.ValidationError{Field: "retry_policy", Message: "Invalid retry policy"}
+		}
+		retryPolicyJSON = pqtype.NullRawMessage{
+			RawMessage: policyBytes,
+			Valid:      true,
+		}
+	}
+
+	// Prepare assertions JSON if provided
+	var assertionsJSON pqtype.NullRawMessage
+	if req.Assertions != nil {
+		assertionBytes, err := json.Marshal(req.Assertions)
+		if err != nil {
+			return database.CreateURLParams{}, &models.ValidationError{Field: "assertions", Message: "Invalid assertions"}
+		}
+		assertionsJSON = pqtype.NullRawMessage{
+			RawMessage: assertionBytes,
+			Valid:      true,
+		}
+	}
+
+	// Prepare archive policy JSON if provided
+	var archivePolicyJSON pqtype.NullRawMessage
+	if req.ArchivePolicy != nil {
+		policyBytes, err := json.Marshal(req.ArchivePolicy)
+		if err != nil {
+			return database.CreateURLParams{}, &models.ValidationError{Field: "archive_policy", Message: "Invalid archive policy"}
+		}
+		archivePolicyJSON = pqtype.NullRawMessage{
+			RawMessage: policyBytes,
+			Valid:      true,
+		}
+	}
+
 	// Prepare user agent
 	var userAgent sql.NullString
 	if req.UserAgent != "" {
@@ -102,16 +406,15 @@ func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		userAgent = sql.NullString{
-			String: "GoScrapingBot/1.0",
+			String: defaultUserAgent,
 			Valid:  true,
 		}
 	}
 
-	// Create URL using sqlc-generated database queries
-	params := database.CreateURLParams{
+	return database.CreateURLParams{
 		Url:          req.URL,
 		Frequency:    req.Frequency,
-		Status:       "pending",
+		Status:       sharedmodels.URLStatusPending,
 		MaxRetries:   int32(h.getDefaultValue(req.MaxRetries, 3)),
 		Timeout:      int32(h.getDefaultValue(req.Timeout, 30)),
 		RateLimit:    int32(h.getDefaultValue(req.RateLimit, 1)),
@@ -121,128 +424,119 @@ func (h *URLHandler) CreateURL(w http.ResponseWriter, r *http.Request) {
 			Time:  nextScrape,
 			Valid: true,
 		},
-	}
+		RetryPolicy:   retryPolicyJSON,
+		Tags:          req.Tags,
+		Project:       req.Project,
+		Assertions:    assertionsJSON,
+		Region:        req.Region,
+		ArchivePolicy: archivePolicyJSON,
+	}, nil
+}
 
-	createdURL, err := h.DB.CreateURL(r.Context(), params)
-	if err != nil {
-		h.Logger.WithError(err).WithField("url", req.URL).Error("Failed to save URL to database")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+// checkURLConfig validates a URL configuration like CreateURL, including
+// its parser template, and converts it into the parameters storing it.
+// Invalid configurations are reported as a *models.ValidationError.
+func (h *URLHandler) checkURLConfig(ctx context.Context, config *models.CreateURLRequest) (database.CreateURLParams, error) {
+	if err := h.validateCreateURLRequest(config); err != nil {
+		return database.CreateURLParams{}, err
 	}
-
-	// Prepare response
-	response := models.CreateURLResponse{
-		ID:        createdURL.ID.String(),
-		URL:       createdURL.Url,
-		Status:    createdURL.Status,
-		CreatedAt: createdURL.CreatedAt.Format(time.RFC3339),
+	if config.ParserConfig != nil && config.ParserConfig.Template != "" {
+		if _, err := lookupParserTemplate(ctx, h.URLs, config.ParserConfig.Template); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return database.CreateURLParams{}, &models.ValidationError{Field: "parser_config.template", Message: "Unknown parser template: " + config.ParserConfig.Template}
+			}
+			return database.CreateURLParams{}, err
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	return h.createURLParams(config)
 }
 
 // validateCreateURLRequest validates the models.CreateURLRequest
 // This function performs comprehensive validation of the request data
 // including URL format, frequency format, and business rule validation.
 func (h *URLHandler) validateCreateURLRequest(req *models.CreateURLRequest) error {
-	// Validate URL
-	if req.URL == "" {
-		return &models.ValidationError{Field: "url", Message: "URL is required"}
+	// Validate URL, frequency, limits and selectors by the request's tags
+	if err := validateRequest(req); err != nil {
+		return err
 	}
 
-	parsedURL, err := url.Parse(req.URL)
-	if err != nil {
-		return &models.ValidationError{Field: "url", Message: "Invalid URL format"}
-	}
-
-	if parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return &models.ValidationError{Field: "url", Message: "URL must include scheme and host"}
+	// Validate extraction script
+	if req.ParserConfig != nil && req.ParserConfig.Script != nil {
+		if _, err := parser.CompileScript(req.ParserConfig.Script); err != nil {
+			return &models.ValidationError{Field: "parser_config.script", Message: err.Error()}
+		}
 	}
 
-	// Validate frequency
-	if req.Frequency == "" {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency is required"}
+	// Validate transform webhook
+	if req.ParserConfig != nil && req.ParserConfig.Transform != nil {
+		if err := parser.ValidateTransformConfig(req.ParserConfig.Transform); err != nil {
+			return &models.ValidationError{Field: "parser_config.transform", Message: err.Error()}
+		}
 	}
 
-	if err := h.validateFrequency(req.Frequency); err != nil {
-		return &models.ValidationError{Field: "frequency", Message: err.Error()}
+	// Validate retry policy
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			return &models.ValidationError{Field: "retry_policy", Message: err.Error()}
+		}
 	}
 
-	// Validate timeout
-	if req.Timeout < 0 {
-		return &models.ValidationError{Field: "timeout", Message: "Timeout must be non-negative"}
+	// Validate content assertions
+	if req.Assertions != nil {
+		if err := parser.ValidateAssertions(*req.Assertions); err != nil {
+			return &models.ValidationError{Field: "assertions", Message: err.Error()}
+		}
 	}
 
-	if req.Timeout > 300 { // Max 5 minutes
-		return &models.ValidationError{Field: "timeout", Message: "Timeout cannot exceed 300 seconds"}
+	// Validate raw HTML archive policy
+	if req.ArchivePolicy != nil {
+		if err := req.ArchivePolicy.Validate(); err != nil {
+			return &models.ValidationError{Field: "archive_policy", Message: err.Error()}
+		}
 	}
 
-	// Validate rate limit
-	if req.RateLimit < 0 {
-		return &models.ValidationError{Field: "rate_limit", Message: "Rate limit must be non-negative"}
+	// Validate tags and project
+	tags, err := sharedmodels.NormalizeTags(req.Tags)
+	if err != nil {
+		return &models.ValidationError{Field: "tags", Message: err.Error()}
 	}
+	req.Tags = tags
 
-	if req.RateLimit > 1000 { // Max 1000 requests per minute
-		return &models.ValidationError{Field: "rate_limit", Message: "Rate limit cannot exceed 1000 requests per minute"}
+	if !sharedmodels.ValidProject(req.Project) {
+		return &models.ValidationError{Field: "project", Message: "Project must be lowercase letters, digits, '_', '.' or '-'"}
 	}
-
-	// Validate max retries
-	if req.MaxRetries < 0 {
-		return &models.ValidationError{Field: "max_retries", Message: "Max retries must be non-negative"}
+	if !sharedmodels.ValidRegion(req.Region) {
+		return &models.ValidationError{Field: "region", Message: "Region must be lowercase letters, digits or '-'"}
 	}
 
-	if req.MaxRetries > 10 { // Max 10 retries
-		return &models.ValidationError{Field: "max_retries", Message: "Max retries cannot exceed 10"}
+	// Enforce the configured minimum frequency of the URL's domain and project
+	if err := h.checkFrequencyFloor(req.URL, req.Frequency, req.Project); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// validateTargetURL checks that a URL to be scraped is present, absolute
+// and fetched over HTTP or HTTPS
+func validateTargetURL(rawURL string) error {
+	return validateField("url", rawURL, "required,url,scheme=http https")
+}
+
 // validateFrequency validates the frequency string format
-// This function ensures the frequency follows the expected format (e.g., "1h", "30m", "1d").
+// This function ensures the frequency is a number followed by a unit (e.g., "45m", "2h", "3d"),
+// using the same parser the URL Manager schedules with.
 func (h *URLHandler) validateFrequency(frequency string) error {
-	if frequency == "" {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency cannot be empty"}
-	}
-
-	// Check if frequency ends with a valid unit
-	validUnits := []string{"s", "m", "h", "d", "w"}
-	hasValidUnit := false
-
-	for _, unit := range validUnits {
-		if strings.HasSuffix(frequency, unit) {
-			hasValidUnit = true
-			break
-		}
-	}
-
-	if !hasValidUnit {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency must end with a valid unit (s, m, h, d, w)"}
-	}
-
-	// Extract numeric part
-	numericPart := strings.TrimSuffix(frequency, frequency[len(frequency)-1:])
-	if numericPart == "" {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency must include a numeric value"}
-	}
-
-	// Parse numeric value
-	value, err := strconv.Atoi(numericPart)
-	if err != nil {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency must be a valid number"}
-	}
-
-	if value <= 0 {
-		return &models.ValidationError{Field: "frequency", Message: "Frequency value must be positive"}
-	}
+	return validateField("frequency", frequency, "frequency")
+}
 
-	// Validate minimum frequency (at least 30 seconds)
-	if strings.HasSuffix(frequency, "s") && value < 30 {
-		return &models.ValidationError{Field: "frequency", Message: "Minimum frequency is 30 seconds"}
+// checkFrequencyFloor rejects a frequency shorter than the floor the
+// frequency policy sets for a URL's domain and project
+func (h *URLHandler) checkFrequencyFloor(rawURL, frequency, project string) error {
+	floor := h.Config.Current().FrequencyPolicy.Floor(rawURL, project)
+	if interval, err := utils.ParseFrequency(frequency); err == nil && interval < floor {
+		return &models.ValidationError{Field: "frequency", Message: fmt.Sprintf("Frequency must be at least %s for this URL", utils.FormatFrequency(floor))}
 	}
-
 	return nil
 }
 
@@ -264,44 +558,39 @@ func (h *URLHandler) getDefaultValue(value, defaultValue int) int {
 // Query Parameters:
 //   - page: Page number (default: 1)
 //   - limit: Items per page, max 100 (default: 20)
+//   - q: Free-text search, case-insensitive, over the URL address and tags
+//   - sort: created_at, next_scrape_at, last_scraped_at, status or url (default: created_at, newest first)
+//   - order: asc or desc (default: asc when sort is given)
 //
-// Response: models.ListURLsResponse (200 OK) or error (500)
+// Response: models.ListURLsResponse (200 OK) or error (400/500)
 //
 // Example Usage:
 //
 //	GET /api/v1/urls?page=1&limit=20
+//	GET /api/v1/urls?q=example.com/news
+//	GET /api/v1/urls?sort=next_scrape_at&order=asc
 func (h *URLHandler) ListURLs(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page <= 0 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit <= 0 || limit > 100 {
-		limit = 20
-	}
-
-	offset := (page - 1) * limit
-
-	// Get total count for pagination
-	total, err := h.DB.CountURLs(r.Context())
+	sort, err := parseListSort(r, listSort{Field: "created_at", Descending: true},
+		"created_at", "next_scrape_at", "last_scraped_at", "status", "url")
 	if err != nil {
-		h.Logger.WithError(err).Error("Failed to count URLs")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Get URLs from database using sqlc-generated query
-	urls, err := h.DB.ListURLs(r.Context(), database.ListURLsParams{
-		Limit:  int32(limit),
-		Offset: int32(offset),
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	result, err := h.URLs.ListURLs(r.Context(), services.URLQuery{
+		Search:     r.URL.Query().Get("q"),
+		SortBy:     sort.Field,
+		Descending: sort.Descending,
+		Page:       page,
+		Limit:      limit,
 	})
 	if err != nil {
-		h.Logger.WithError(err).Error("Failed to get URLs from database")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, h.Logger, err, "Failed to get URLs from database")
 		return
 	}
+	urls := result.URLs
 
 	// Convert database URLs to response format
 	urlItems := make([]models.URLListItem, len(urls))
@@ -311,6 +600,9 @@ func (h *URLHandler) ListURLs(w http.ResponseWriter, r *http.Request) {
 			URL:       url.Url,
 			Frequency: url.Frequency,
 			Status:    url.Status,
+			Tags:      url.Tags,
+			Project:   url.Project,
+			Region:    url.Region,
 			CreatedAt: url.CreatedAt.Format(time.RFC3339),
 		}
 
@@ -331,9 +623,61 @@ func (h *URLHandler) ListURLs(w http.ResponseWriter, r *http.Request) {
 	// Build response
 	response := models.ListURLsResponse{
 		URLs:  urlItems,
-		Total: total,
-		Page:  page,
-		Limit: limit,
+		Total: result.Total,
+		Page:  result.Page,
+		Limit: result.Limit,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetURLStats handles GET /api/v1/urls/stats
+//
+// Purpose: Summarizes all URLs for dashboard cards: the number of URLs per
+// status, the hosts with the most URLs and the number of URLs per project,
+// so clients do not have to page through the full list to aggregate it.
+//
+// Query Parameters:
+//   - domains: Number of top domains to return, max 100 (default: 10)
+//
+// Response: models.URLStatsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/stats
+//	GET /api/v1/urls/stats?domains=25
+func (h *URLHandler) GetURLStats(w http.ResponseWriter, r *http.Request) {
+	domains := services.DefaultTopDomains
+	if value := r.URL.Query().Get("domains"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("domains must be between 1 and %d", services.MaxTopDomains), http.StatusBadRequest)
+			return
+		}
+		domains = n
+	}
+
+	stats, err := h.URLs.Stats(r.Context(), domains)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to count URLs")
+		return
+	}
+
+	response := models.URLStatsResponse{
+		Total:      stats.Total,
+		ByStatus:   make(map[string]int64, len(stats.ByStatus)),
+		TopDomains: make([]models.URLCountResponse, len(stats.TopDomains)),
+		Projects:   make([]models.URLCountResponse, len(stats.Projects)),
+	}
+	for _, row := range stats.ByStatus {
+		response.ByStatus[row.Status] = row.Count
+	}
+	for i, row := range stats.TopDomains {
+		response.TopDomains[i] = models.URLCountResponse{Name: row.Domain, Count: row.Count}
+	}
+	for i, row := range stats.Projects {
+		response.Projects[i] = models.URLCountResponse{Name: row.Project, Count: row.Count}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -371,23 +715,16 @@ func (h *URLHandler) GetURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get URL from database using sqlc-generated query
-	url, err := h.DB.GetURLByID(r.Context(), urlID)
+	url, err := h.URLs.GetURL(r.Context(), urlID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			h.Logger.WithField("url_id", id).Warn("URL not found")
-			http.Error(w, "URL not found", http.StatusNotFound)
-			return
-		}
-		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to get URL from database")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeError(w, h.Logger, err, "Failed to get URL from database")
 		return
 	}
 
 	// Parse parser config if available
-	var parserConfig *models.ParserConfig
+	var parserConfig *sharedmodels.ParserConfig
 	if url.ParserConfig.Valid {
-		var config models.ParserConfig
+		var config sharedmodels.ParserConfig
 		if err := json.Unmarshal(url.ParserConfig.RawMessage, &config); err != nil {
 			h.Logger.WithError(err).WithField("url_id", id).Warn("Failed to parse parser config")
 			// Don't fail the request if parser config is invalid
@@ -396,18 +733,58 @@ func (h *URLHandler) GetURL(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Parse retry policy if available; URLs without one use the default
+	// policy derived from max_retries
+	var retryPolicy *sharedmodels.RetryPolicy
+	if url.RetryPolicy.Valid {
+		var policy sharedmodels.RetryPolicy
+		if err := json.Unmarshal(url.RetryPolicy.RawMessage, &policy); err != nil {
+			h.Logger.WithError(err).WithField("url_id", id).Warn("Failed to parse retry policy")
+		} else {
+			retryPolicy = &policy
+		}
+	}
+
+	// Parse content assertions if available
+	var assertions *sharedmodels.Assertions
+	if url.Assertions.Valid {
+		var parsed sharedmodels.Assertions
+		if err := json.Unmarshal(url.Assertions.RawMessage, &parsed); err != nil {
+			h.Logger.WithError(err).WithField("url_id", id).Warn("Failed to parse assertions")
+		} else {
+			assertions = &parsed
+		}
+	}
+
+	// Parse raw HTML archive policy if available
+	var archivePolicy *sharedmodels.ArchivePolicy
+	if url.ArchivePolicy.Valid {
+		var parsed sharedmodels.ArchivePolicy
+		if err := json.Unmarshal(url.ArchivePolicy.RawMessage, &parsed); err != nil {
+			h.Logger.WithError(err).WithField("url_id", id).Warn("Failed to parse archive policy")
+		} else {
+			archivePolicy = &parsed
+		}
+	}
+
 	// Build response
 	response := map[string]interface{}{
-		"id":          url.ID.String(),
-		"url":         url.Url,
-		"frequency":   url.Frequency,
-		"status":      url.Status,
-		"max_retries": url.MaxRetries,
-		"timeout":     url.Timeout,
-		"rate_limit":  url.RateLimit,
-		"retry_count": url.RetryCount,
-		"created_at":  url.CreatedAt.Format(time.RFC3339),
-		"updated_at":  url.UpdatedAt.Format(time.RFC3339),
+		"id":             url.ID.String(),
+		"url":            url.Url,
+		"frequency":      url.Frequency,
+		"status":         url.Status,
+		"max_retries":    url.MaxRetries,
+		"timeout":        url.Timeout,
+		"rate_limit":     url.RateLimit,
+		"retry_count":    url.RetryCount,
+		"retry_policy":   sharedmodels.EffectiveRetryPolicy(retryPolicy, int(url.MaxRetries)),
+		"tags":           url.Tags,
+		"project":        url.Project,
+		"assertions":     assertions,
+		"region":         url.Region,
+		"archive_policy": archivePolicy,
+		"created_at":     url.CreatedAt.Format(time.RFC3339),
+		"updated_at":     url.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// Add optional fields if they have values
@@ -435,61 +812,162 @@ func (h *URLHandler) GetURL(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// UpdateURL handles PUT /api/v1/urls/{id}
+// CloneURL handles POST /api/v1/urls/{id}/clone
 //
-// Purpose: Updates configuration for an existing URL. This endpoint supports
-// partial updates, allowing clients to modify only specific fields without
-// providing the complete URL configuration.
+// Purpose: Creates a new URL with the full configuration of an existing one
+// (frequency, limits, user agent, parser config, retry policy and tags) but a
+// different target URL. This makes it easy to monitor many similar pages on
+// the same site without repeating their configuration.
 //
 // Path Parameters:
-//   - id: URL identifier (required)
+//   - id: Identifier of the URL to copy the configuration from (required)
 //
-// Request Body: models.UpdateURLRequest (all fields optional)
-// Response: Success message (200 OK) or error (400/404/500)
+// Request Body: models.CloneURLRequest
+// Response: models.CreateURLResponse (201 Created) or error (400/404/409/500)
 //
 // Example Usage:
 //
-//	PUT /api/v1/urls/url-123
+//	POST /api/v1/urls/3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11/clone
 //	{
-//	  "frequency": "2h",
-//	  "timeout": 45
+//	  "url": "https://example.com/products/42"
 //	}
-func (h *URLHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+func (h *URLHandler) CloneURL(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
 
-	if id == "" {
-		http.Error(w, "URL ID is required", http.StatusBadRequest)
+	sourceID, err := uuid.Parse(id)
+	if err != nil {
+		http.Error(w, "Invalid URL ID format", http.StatusBadRequest)
 		return
 	}
 
-	var req models.UpdateURLRequest
+	var req models.CloneURLRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.WithError(err).Error("Failed to decode request body")
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// TODO: Update URL using service
-	// url, err := h.urlService.GetURL(r.Context(), id)
-	// if err != nil {
-	//     if errors.Is(err, domain.ErrURLNotFound) {
-	//         http.Error(w, "URL not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.logger.WithError(err).Error("Failed to get URL")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
-	//
-	// // Update fields
-	// if req.Frequency != "" {
-	//     url.Frequency = req.Frequency
-	// }
-	// if req.ParserConfig != nil {
-	//     url.ParserConfig = req.ParserConfig
-	// }
-	// if req.UserAgent != "" {
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.URLs.GetActiveURL(r.Context(), sourceID)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to get URL from database")
+		return
+	}
+	// The clone's domain may have a stricter frequency floor than the source's
+	if err := h.checkFrequencyFloor(req.URL, source.Frequency, source.Project); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nextScrape, err := h.calculateNextScrapeTime(source.Frequency, time.Now().UTC())
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to calculate next scrape time")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	createdURL, err := h.URLs.CreateURL(r.Context(), database.CreateURLParams{
+		Url:          req.URL,
+		Frequency:    source.Frequency,
+		Status:       sharedmodels.URLStatusPending,
+		MaxRetries:   source.MaxRetries,
+		Timeout:      source.Timeout,
+		RateLimit:    source.RateLimit,
+		UserAgent:    source.UserAgent,
+		ParserConfig: source.ParserConfig,
+		NextScrapeAt: sql.NullTime{
+			Time:  nextScrape,
+			Valid: true,
+		},
+		RetryPolicy:   source.RetryPolicy,
+		Tags:          source.Tags,
+		Project:       source.Project,
+		Assertions:    source.Assertions,
+		Region:        source.Region,
+		ArchivePolicy: source.ArchivePolicy,
+	})
+	if err != nil {
+		writeError(w, h.Logger.WithFields(logrus.Fields{"url_id": id, "url": req.URL}), err, "Failed to save cloned URL to database")
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"source_id": id,
+		"url_id":    createdURL.ID.String(),
+		"url":       createdURL.Url,
+	}).Info("URL cloned")
+	h.recordParserConfigVersion(r, createdURL.ID, createdURL.ParserConfig, "cloned from "+source.ID.String())
+
+	response := models.CreateURLResponse{
+		ID:        createdURL.ID.String(),
+		URL:       createdURL.Url,
+		Status:    createdURL.Status,
+		CreatedAt: createdURL.CreatedAt.Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateURL handles PUT /api/v1/urls/{id}
+//
+// Purpose: Updates configuration for an existing URL. This endpoint supports
+// partial updates, allowing clients to modify only specific fields without
+// providing the complete URL configuration.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Request Body: models.UpdateURLRequest (all fields optional)
+// Response: Success message (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/urls/url-123
+//	{
+//	  "frequency": "2h",
+//	  "timeout": 45
+//	}
+func (h *URLHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		http.Error(w, "URL ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Update URL using service
+	// url, err := h.URLs.GetActiveURL(r.Context(), id)
+	// if err != nil {
+	//     writeError(w, h.Logger, err, "Failed to get URL")
+	//     return
+	// }
+	//
+	// // Update fields
+	// if req.Frequency != "" {
+	//     url.Frequency = req.Frequency
+	// }
+	// if req.ParserConfig != nil {
+	//     url.ParserConfig = req.ParserConfig
+	// }
+	// if req.UserAgent != "" {
 	//     url.UserAgent = req.UserAgent
 	// }
 	// if req.Timeout > 0 {
@@ -502,9 +980,8 @@ func (h *URLHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
 	//     url.MaxRetries = req.MaxRetries
 	// }
 	//
-	// if err := h.urlService.UpdateURL(r.Context(), url); err != nil {
-	//     h.logger.WithError(err).Error("Failed to update URL")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
+	// if err := h.URLs.UpdateURL(r.Context(), url); err != nil {
+	//     writeError(w, h.Logger, err, "Failed to update URL")
 	//     return
 	// }
 
@@ -513,20 +990,85 @@ func (h *URLHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"message": "URL updated successfully"})
 }
 
+// PatchURL handles PATCH /api/v1/urls/{id}
+//
+// Purpose: Changes part of a URL's configuration with a JSON Merge Patch
+// (RFC 7386). Fields in the patch replace the URL's values, objects such as
+// parser_config are merged recursively, and null removes a field, e.g. a
+// custom user agent, which PUT cannot express. The patched configuration
+// is validated like POST /api/v1/urls. Status and schedule are kept, and
+// the address cannot be changed; clone the URL instead.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Request Body: merge patch of models.CreateURLRequest, with Content-Type
+// application/merge-patch+json (or application/json)
+// Response: models.CreateURLRequest with the patched configuration (200 OK)
+// or error (400/404/415/500)
+//
+// Example Usage:
+//
+//	PATCH /api/v1/urls/3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11
+//	Content-Type: application/merge-patch+json
+//
+//	{
+//	  "user_agent": null,
+//	  "parser_config": {"selectors": {"price": ".amount", "sku": null}}
+//	}
+func (h *URLHandler) PatchURL(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID format", http.StatusBadRequest)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "Invalid request body: a merge patch must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	config, err := h.URLs.Patch(r.Context(), id, patch, h.checkURLConfig, h.change(r, "patched"))
+	var validationErr *models.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to patch URL")
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"url_id": id,
+		"fields": len(patch),
+	}).Info("URL patched")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(config)
+}
+
 // DeleteURL handles DELETE /api/v1/urls/{id}
 //
-// Purpose: Removes a URL from the scraping schedule. This operation is
-// irreversible and will stop all future scraping attempts for this URL.
-// Existing scraped data is preserved unless explicitly configured otherwise.
+// Purpose: Removes a URL from the scraping schedule. The URL is
+// soft-deleted: it is no longer listed or scheduled, its scraped data is
+// preserved and it can be brought back with BulkRestoreURLs.
 //
 // Path Parameters:
 //   - id: URL identifier (required)
 //
-// Response: Success message (200 OK) or error (400/404/500)
+// Response: Success message (200 OK) or error (400/404/500; 404 when the URL
+// does not exist or is already deleted)
 //
 // Example Usage:
 //
-//	DELETE /api/v1/urls/url-123
+//	DELETE /api/v1/urls/3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11
 func (h *URLHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -536,145 +1078,2002 @@ func (h *URLHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Delete URL using service
-	// if err := h.urlService.DeleteURL(r.Context(), id); err != nil {
-	//     if errors.Is(err, domain.ErrURLNotFound) {
-	//         http.Error(w, "URL not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.logger.WithError(err).Error("Failed to delete URL")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	urlID, err := uuid.Parse(id)
+	if err != nil {
+		http.Error(w, "Invalid URL ID format", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.URLs.DeleteURL(r.Context(), urlID); err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to delete URL")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "URL deleted successfully"})
 }
 
-// TriggerScrape handles POST /api/v1/urls/{id}/scrape
+// BulkDeleteURLs handles DELETE /api/v1/urls/bulk
 //
-// Purpose: Manually triggers scraping for a specific URL, bypassing the
-// normal schedule. This is useful for immediate data collection or
-// testing purposes. The scraping will be queued and processed as soon
-// as a worker becomes available.
+// Purpose: Soft-deletes every URL matching the given IDs, tag and/or domain
+// in one request. Deleted URLs are no longer listed or scheduled but can be
+// brought back with BulkRestoreURLs. With dry_run the matching URLs are only
+// counted, so the scope of the action can be checked first.
 //
-// Path Parameters:
-//   - id: URL identifier (required)
+// Query Parameters:
+//   - dry_run: Preview the affected count without deleting (optional, same as dry_run in the body)
 //
-// Response: Success message (200 OK) or error (400/404/500)
+// Request Body: models.BulkURLRequest (at least one of ids, tag, domain)
+// Response: models.BulkURLResponse (200 OK) or error (400/500)
 //
 // Example Usage:
 //
-//	POST /api/v1/urls/url-123/scrape
-func (h *URLHandler) TriggerScrape(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+//	DELETE /api/v1/urls/bulk?dry_run=true
+//	{
+//	  "tag": "campaign-2024",
+//	  "domain": "example.com"
+//	}
+func (h *URLHandler) BulkDeleteURLs(w http.ResponseWriter, r *http.Request) {
+	h.bulkAction(w, r, services.BulkDelete)
+}
 
-	if id == "" {
-		http.Error(w, "URL ID is required", http.StatusBadRequest)
+// BulkRestoreURLs handles POST /api/v1/urls/bulk/restore
+//
+// Purpose: Restores soft-deleted URLs matching the given IDs, tag and/or
+// domain so they are listed and scheduled again. With dry_run the matching
+// deleted URLs are only counted.
+//
+// Query Parameters:
+//   - dry_run: Preview the affected count without restoring (optional, same as dry_run in the body)
+//
+// Request Body: models.BulkURLRequest (at least one of ids, tag, domain)
+// Response: models.BulkURLResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/bulk/restore
+//	{
+//	  "ids": ["3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11"]
+//	}
+func (h *URLHandler) BulkRestoreURLs(w http.ResponseWriter, r *http.Request) {
+	h.bulkAction(w, r, services.BulkRestore)
+}
+
+// BulkResetURLs handles POST /api/v1/urls/bulk/reset
+//
+// Purpose: Recovers URLs that were marked failed, e.g. after a site outage
+// has been fixed. Failed URLs matching the given IDs, tag and/or domain go
+// back to pending with their retry count reset to 0 and are scheduled right
+// away. URLs in any other status are left alone. With dry_run the matching
+// failed URLs are only counted.
+//
+// Query Parameters:
+//   - dry_run: Preview the affected count without resetting (optional, same as dry_run in the body)
+//
+// Request Body: models.BulkURLRequest (at least one of ids, tag, domain)
+// Response: models.BulkURLResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/bulk/reset
+//	{
+//	  "domain": "example.com"
+//	}
+func (h *URLHandler) BulkResetURLs(w http.ResponseWriter, r *http.Request) {
+	h.bulkAction(w, r, services.BulkReset)
+}
+
+// bulkAction validates a bulk request and either counts (dry run) or applies
+// the delete, restore or reset action to the matching URLs
+func (h *URLHandler) bulkAction(w http.ResponseWriter, r *http.Request, action string) {
+	var req models.BulkURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// TODO: Trigger immediate scrape using service
-	// if err := h.urlService.ScheduleScraping(r.Context(), id); err != nil {
-	//     if errors.Is(err, domain.ErrURLNotFound) {
-	//         http.Error(w, "URL not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.logger.WithError(err).Error("Failed to trigger scrape")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	ids, err := validateBulkURLRequest(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run")); err == nil && dryRun {
+		req.DryRun = true
+	}
+
+	logger := h.Logger.WithFields(logrus.Fields{
+		"action":  action,
+		"ids":     len(ids),
+		"tag":     req.Tag,
+		"domain":  req.Domain,
+		"dry_run": req.DryRun,
+	})
+
+	filter := services.BulkFilter{IDs: ids, Tag: req.Tag, Domain: req.Domain}
+	matched, err := h.URLs.CountBulkAction(r.Context(), action, filter)
+	if err != nil {
+		writeError(w, logger, err, "Failed to count URLs for bulk action")
+		return
+	}
+
+	response := models.BulkURLResponse{
+		Action:  action,
+		DryRun:  req.DryRun,
+		Matched: matched,
+	}
+
+	if !req.DryRun {
+		response.Affected, err = h.URLs.ApplyBulkAction(r.Context(), action, filter)
+		if err != nil {
+			writeError(w, logger, err, "Failed to apply bulk action")
+			return
+		}
+		logger.WithField("affected", response.Affected).Info("Bulk URL action applied")
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Scrape triggered successfully"})
+	json.NewEncoder(w).Encode(response)
 }
 
-// GetURLStatus handles GET /api/v1/urls/{id}/status
+// validateBulkURLRequest checks that a bulk request has at least one filter,
+// normalizes the tag and domain and parses the IDs. The returned slice is
+// never nil, since the queries treat an empty ID list as "no ID filter".
+func validateBulkURLRequest(req *models.BulkURLRequest) ([]uuid.UUID, error) {
+	req.Tag = strings.ToLower(strings.TrimSpace(req.Tag))
+	req.Domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(req.Domain)), ".")
+
+	if len(req.IDs) == 0 && req.Tag == "" && req.Domain == "" {
+		return nil, &models.ValidationError{Field: "ids", Message: "At least one of ids, tag or domain is required"}
+	}
+	if req.Tag != "" && !sharedmodels.ValidTag(req.Tag) {
+		return nil, &models.ValidationError{Field: "tag", Message: "Invalid tag: " + req.Tag}
+	}
+	if req.Domain != "" && !domainPattern.MatchString(req.Domain) {
+		return nil, &models.ValidationError{Field: "domain", Message: "Invalid domain: " + req.Domain}
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, &models.ValidationError{Field: "ids", Message: "Invalid URL ID: " + raw}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ExportURLs handles GET /api/v1/urls/export
 //
-// Purpose: Retrieves current status and scheduling information for a URL.
-// This endpoint provides real-time information about the URL's scraping
-// status, including last scrape time, next scheduled scrape, and retry
-// information.
+// Purpose: Exports the configuration of every URL that is not deleted,
+// including parser configs, retry policies and tags, so it can be kept in
+// version control or promoted to another environment with ImportURLs.
+// Runtime state such as status and schedule is not exported.
 //
-// Path Parameters:
-//   - id: URL identifier (required)
+// Query Parameters:
+//   - format: Export format (json, yaml) - default: json
 //
-// Response: URL status details (200 OK) or error (400/404/500)
+// Response: models.ExportURLsResponse (200 OK) or error (400/500)
 //
 // Example Usage:
 //
-//	GET /api/v1/urls/url-123/status
-func (h *URLHandler) GetURLStatus(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
+//	GET /api/v1/urls/export?format=yaml
+func (h *URLHandler) ExportURLs(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format == "yml" {
+		format = "yaml"
+	}
+	if format != "json" && format != "yaml" {
+		http.Error(w, "Invalid format. Supported formats: json, yaml", http.StatusBadRequest)
+		return
+	}
 
-	if id == "" {
-		http.Error(w, "URL ID is required", http.StatusBadRequest)
+	urls, err := h.URLs.ListURLsForExport(r.Context())
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to get URLs for export")
 		return
 	}
 
-	// TODO: Get URL status using service
-	// url, err := h.urlService.GetURL(r.Context(), id)
-	// if err != nil {
-	//     if errors.Is(err, domain.ErrURLNotFound) {
-	//         http.Error(w, "URL not found", http.StatusNotFound)
-	//         return
-	//     }
-	//     h.logger.WithError(err).Error("Failed to get URL status")
-	//     http.Error(w, "Internal server error", http.StatusInternalServerError)
-	//     return
-	// }
+	response := models.ExportURLsResponse{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Total:      len(urls),
+		URLs:       make([]models.CreateURLRequest, 0, len(urls)),
+	}
+	for _, url := range urls {
+		response.URLs = append(response.URLs, h.URLs.URLConfig(url))
+	}
 
-	// For now, return mock data
-	response := map[string]interface{}{
-		"id":              id,
-		"status":          "pending",
-		"last_scraped_at": nil,
-		"next_scrape_at":  "2024-01-01T01:00:00Z",
-		"retry_count":     0,
-		"max_retries":     3,
+	if format == "yaml" {
+		body, err := encodeYAML(response)
+		if err != nil {
+			h.Logger.WithError(err).Error("Failed to encode URL export as YAML")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", "attachment; filename=urls.yaml")
+		w.Write(body)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=urls.json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// calculateNextScrapeTime calculates when the URL should be scraped next
-func (h *URLHandler) calculateNextScrapeTime(frequency string, from time.Time) (time.Time, error) {
-	duration, err := h.parseFrequency(frequency)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return from.Add(duration), nil
-}
-
-// parseFrequency parses frequency string into time.Duration
-func (h *URLHandler) parseFrequency(frequency string) (time.Duration, error) {
-	switch frequency {
-	case "30s":
-		return 30 * time.Second, nil
-	case "1m":
-		return 1 * time.Minute, nil
-	case "5m":
-		return 5 * time.Minute, nil
-	case "15m":
-		return 15 * time.Minute, nil
-	case "30m":
-		return 30 * time.Minute, nil
-	case "1h":
-		return 1 * time.Hour, nil
-	case "6h":
-		return 6 * time.Hour, nil
-	case "12h":
-		return 12 * time.Hour, nil
-	case "1d":
-		return 24 * time.Hour, nil
-	case "1w":
-		return 7 * 24 * time.Hour, nil
-	default:
-		return 0, fmt.Errorf("unsupported frequency: %s", frequency)
+// ImportURLs handles POST /api/v1/urls/import
+//
+// Purpose: Creates or updates URLs from a configuration document such as the
+// output of ExportURLs. URLs are matched by address: new addresses are
+// created, existing ones get their configuration replaced and are restored
+// if they were deleted, keeping their status and schedule. URLs missing from
+// the document are left untouched. Every entry is validated before anything
+// is written.
+//
+// Request Body: models.ImportURLsRequest as JSON, or as YAML when the
+// Content-Type is application/yaml or ?format=yaml is given
+// Response: models.ImportURLsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/import
+//	Content-Type: application/yaml
+//
+//	urls:
+//	  - url: https://example.com/news
+//	    frequency: 1h
+//	    tags: [news]
+func (h *URLHandler) ImportURLs(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBodyBytes))
+	if err != nil {
+		http.Error(w, "Request body is too large or unreadable", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ImportURLsRequest
+	if isYAMLRequest(r) {
+		err = decodeYAML(body, &req)
+	} else {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to decode import document")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.URLs) == 0 {
+		http.Error(w, "Import must contain at least one URL", http.StatusBadRequest)
+		return
+	}
+	if len(req.URLs) > maxImportURLs {
+		http.Error(w, fmt.Sprintf("Import cannot contain more than %d URLs", maxImportURLs), http.StatusBadRequest)
+		return
+	}
+
+	// Validate everything up front so an invalid entry doesn't leave a partial import
+	params := make([]database.CreateURLParams, 0, len(req.URLs))
+	seen := make(map[string]bool, len(req.URLs))
+	for i := range req.URLs {
+		config := &req.URLs[i]
+		if err := h.validateCreateURLRequest(config); err != nil {
+			http.Error(w, fmt.Sprintf("urls[%d]: %s", i, err.Error()), http.StatusBadRequest)
+			return
+		}
+		if seen[config.URL] {
+			http.Error(w, fmt.Sprintf("urls[%d]: duplicate URL %s", i, config.URL), http.StatusBadRequest)
+			return
+		}
+		seen[config.URL] = true
+
+		if config.ParserConfig != nil && config.ParserConfig.Template != "" {
+			if _, err := lookupParserTemplate(r.Context(), h.URLs, config.ParserConfig.Template); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					http.Error(w, fmt.Sprintf("urls[%d]: unknown parser template: %s", i, config.ParserConfig.Template), http.StatusBadRequest)
+					return
+				}
+				h.Logger.WithError(err).WithField("template", config.ParserConfig.Template).Error("Failed to resolve parser template")
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		urlParams, err := h.createURLParams(config)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("urls[%d]: %s", i, err.Error()), http.StatusBadRequest)
+			return
+		}
+		params = append(params, urlParams)
+	}
+
+	response := models.ImportURLsResponse{Total: len(params)}
+	rows, err := h.URLs.UpsertURLs(r.Context(), params, h.change(r, "imported"))
+	for _, row := range rows {
+		if row.Inserted {
+			response.Created++
+		} else {
+			response.Updated++
+		}
+	}
+	if err != nil {
+		writeError(w, h.Logger.WithFields(logrus.Fields{
+			"url":     params[len(rows)].Url,
+			"created": response.Created,
+			"updated": response.Updated,
+		}), err, "Failed to import URL")
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"total":   response.Total,
+		"created": response.Created,
+		"updated": response.Updated,
+	}).Info("URL configurations imported")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// isYAMLRequest reports whether a request body should be decoded as YAML
+func isYAMLRequest(r *http.Request) bool {
+	format := r.URL.Query().Get("format")
+	if format == "yaml" || format == "yml" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeYAML renders v as YAML using its JSON field names, so the YAML and
+// JSON forms of a document share the same keys
+func encodeYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML; parse it into a node tree to keep the field order
+	// and reset the flow styles so it is rendered in block style
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// resetYAMLStyle clears the style of a node tree so it renders as plain block YAML
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// decodeYAML decodes a YAML document into v using v's JSON field names
+func decodeYAML(data []byte, v interface{}) error {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+// TriggerScrape handles POST /api/v1/urls/{id}/scrape
+//
+// Purpose: Manually triggers scraping for a specific URL, bypassing the
+// normal schedule. This is useful for immediate data collection or
+// testing purposes. The URL Manager publishes a scraping task right away
+// through its control API; the task is processed as soon as a worker
+// becomes available. The URL's next scheduled scrape is kept.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Manual triggers are admitted per client by rate_limit.triggers_per_minute
+// (see the router) and per URL by the daily scrape budgets covering it,
+// which triggered scrapes count against like scheduled ones.
+//
+// Response: models.ScrapeResponse of the pending task (202 Accepted) with a
+// Location header pointing at the task, which clients poll until it
+// completes, or error (400/404, 429 with models.TriggerRejectedResponse and
+// a Retry-After header when a limit or budget is used up, 502 when the URL
+// Manager cannot be reached or fails)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/scrape
+func (h *URLHandler) TriggerScrape(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	triggered, err := h.Control.TriggerScrape(r.Context(), id)
+	if errors.Is(err, control.ErrURLNotFound) {
+		http.Error(w, "URL not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, control.ErrQuotaExceeded) {
+		message, _ := domain.Message(err)
+		seconds := int(math.Ceil(domain.RetryAfter(err).Seconds()))
+		if seconds > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(models.TriggerRejectedResponse{
+			Error:      "Scrape quota exceeded",
+			Message:    message,
+			RetryAfter: seconds,
+		})
+		return
+	}
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to trigger scrape")
+		http.Error(w, "URL Manager unavailable", http.StatusBadGateway)
+		return
+	}
+
+	createdAt := triggered.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/urls/scrapes/"+triggered.TaskID.String())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(models.ScrapeResponse{
+		TaskID:    triggered.TaskID.String(),
+		URLID:     triggered.URLID.String(),
+		Attempt:   triggered.Attempt,
+		Status:    sharedmodels.TaskStatusPending,
+		CreatedAt: createdAt.Format(time.RFC3339),
+	})
+}
+
+// ProbeURL handles POST /api/v1/urls/{id}/probe
+//
+// Purpose: Quick "will this even work" check of a URL, typically one just
+// added. The page is requested once with the URL's user agent and timeout,
+// with HEAD, or GET when the site does not support HEAD, and robots.txt is
+// checked for it. Nothing is stored and no scrape is scheduled; unlike a
+// triggered scrape, the answer comes back in the response.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: models.URLProbeResponse (200 OK, also when the site is not
+// reachable) or error (400/404/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/probe
+func (h *URLHandler) ProbeURL(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	url, err := h.URLs.GetActiveURL(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to get URL from database")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(probe(r.Context(), h.Fetch, url))
+}
+
+// probe requests the page of a stored URL once, as its scrapes would, and
+// checks robots.txt for it
+func probe(ctx context.Context, fetch *Fetcher, stored *database.Url) models.URLProbeResponse {
+	response := models.URLProbeResponse{
+		URLID:     stored.ID.String(),
+		URL:       stored.Url,
+		Method:    http.MethodHead,
+		UserAgent: defaultUserAgent,
+	}
+	if stored.UserAgent.Valid && stored.UserAgent.String != "" {
+		response.UserAgent = stored.UserAgent.String
+	}
+	timeout := validationFetchTimeout
+	if stored.Timeout > 0 {
+		timeout = min(timeout, time.Duration(stored.Timeout)*time.Second)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ctx, dns := dnscache.WithTiming(ctx)
+
+	target, err := url.Parse(stored.Url)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	if rules, err := robots.Fetch(ctx, fetch.Client(stored.Url), target, response.UserAgent); err != nil {
+		response.RobotsError = err.Error()
+	} else {
+		allowed := rules.Allowed(response.UserAgent, target.RequestURI())
+		response.RobotsAllowed = &allowed
+	}
+
+	start := time.Now()
+	resp, err := probeRequest(ctx, fetch, http.MethodHead, stored.Url, response.UserAgent)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		response.Method = http.MethodGet
+		start = time.Now()
+		resp, err = probeRequest(ctx, fetch, http.MethodGet, stored.Url, response.UserAgent)
+	}
+	response.DNSMs = dns.Milliseconds()
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	defer resp.Body.Close()
+
+	response.Reachable = true
+	response.StatusCode = resp.StatusCode
+	response.ResponseTimeMs = time.Since(start).Milliseconds()
+	if redirects, finalURL := sharedmodels.RedirectChain(resp); len(redirects) > 0 {
+		response.Redirects = redirects
+		response.FinalURL = finalURL
+	}
+	return response
+}
+
+// probeRequest sends a request of a probe. The body of a GET is not read.
+func probeRequest(ctx context.Context, fetch *Fetcher, method, target, userAgent string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return fetch.Client(target).Do(req)
+}
+
+// ReparseURL handles POST /api/v1/urls/{id}/reparse
+//
+// Purpose: Re-runs the URL's current parser config over the raw HTML stored
+// from past scrapes, producing a new parsed version for each snapshot. This
+// backfills historical data after fixing selectors. New versions are dated
+// at the time of the scrape they were parsed from, so they sort alongside
+// the versions parsed back then. Only scrapes whose raw HTML was kept by the
+// URL's archive policy can be reparsed. At most 500 snapshots are parsed
+// per call; next_from tells where to continue. Personal data in the new
+// versions is flagged, hashed or redacted by the pii policy of the URL's
+// project before they are stored, and the data fields the parser config
+// lists as sensitive are encrypted; reparsing such a config fails with 409
+// Conflict when no encryption keys are configured.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Query Parameters:
+//   - from: Reparse scrapes at or after this time, RFC3339 (default: the first scrape)
+//   - to: Reparse scrapes before this time, RFC3339 (default: now)
+//
+// Response: models.ReparseResponse (200 OK) or error (400/404/409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/reparse?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z
+func (h *URLHandler) ReparseURL(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseReparseRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"), time.Now().UTC())
+	if err != nil {
+		http.Error(w, "Invalid time range: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	url, err := h.URLs.GetURL(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get URL")
+		return
+	}
+	if !url.ParserConfig.Valid {
+		http.Error(w, "URL has no parser config", http.StatusBadRequest)
+		return
+	}
+
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(url.ParserConfig.RawMessage, &config); err != nil {
+		http.Error(w, "Invalid parser config: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, schema, err := compileParserConfig(r.Context(), h.URLs, &config, "parser_config")
+	if err != nil {
+		h.writeParserConfigError(w, id, err)
+		return
+	}
+	if len(config.Sensitive) > 0 && h.Keyring == nil {
+		http.Error(w, "Parser config has sensitive fields, but no encryption keys are configured", http.StatusConflict)
+		return
+	}
+
+	// A candidate config in shadow mode parses the same snapshots
+	candidate, err := h.candidateParser(r.Context(), id)
+	if err != nil {
+		h.writeParserConfigError(w, id, err)
+		return
+	}
+
+	redactor, err := pii.New(h.Config.Current().PII.Policy(url.Project))
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Invalid PII policy")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	snapshots, err := h.URLs.ListRawHTMLSnapshots(r.Context(), database.ListRawHTMLSnapshotsInRangeParams{
+		UrlID:      id,
+		FromTime:   from,
+		ToTime:     to,
+		MaxResults: maxReparseSnapshots + 1,
+	})
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to list raw HTML snapshots")
+		return
+	}
+
+	response := models.ReparseResponse{
+		URLID:     id.String(),
+		From:      from.Format(time.RFC3339),
+		To:        to.Format(time.RFC3339),
+		RecordIDs: []string{},
+		Failures:  []models.ReparseFailure{},
+	}
+	if len(snapshots) > maxReparseSnapshots {
+		response.NextFrom = snapshots[maxReparseSnapshots].CreatedAt.Format(time.RFC3339Nano)
+		snapshots = snapshots[:maxReparseSnapshots]
+	}
+
+	transformer := parser.NewTransformer(nil)
+	for _, snapshot := range snapshots {
+		response.Snapshots++
+		record, err := h.reparseSnapshot(r, p, transformer, config.Transform, redactor, config.Sensitive, url.Url, schema, snapshot)
+		if err != nil {
+			if r.Context().Err() != nil {
+				h.Logger.WithError(err).WithField("url_id", id).Warn("Reparse cancelled")
+				return
+			}
+			response.Failures = append(response.Failures, models.ReparseFailure{
+				SnapshotID: snapshot.ID.String(),
+				ScrapedAt:  snapshot.CreatedAt.Format(time.RFC3339),
+				Error:      err.Error(),
+			})
+			continue
+		}
+		response.RecordIDs = append(response.RecordIDs, record.ID.String())
+
+		if candidate != nil {
+			if err := h.shadowParse(r, candidate, redactor, config.Sensitive, url.Url, snapshot, record.ID); err != nil {
+				h.Logger.WithError(err).WithFields(logrus.Fields{
+					"url_id":      id,
+					"snapshot_id": snapshot.ID,
+				}).Warn("Candidate parser config failed on snapshot")
+				continue
+			}
+			response.CandidateRecords++
+		}
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"url_id":    id,
+		"snapshots": response.Snapshots,
+		"records":   len(response.RecordIDs),
+		"failures":  len(response.Failures),
+	}).Info("Reparsed raw HTML snapshots")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// reparseSnapshot parses one raw HTML snapshot and stores the result as a
+// new parsed version dated at the time of the scrape. The redactor, nil
+// when the project's policy does not look for personal data, runs last so
+// the fields a transform webhook adds are covered as well. The sensitive
+// data fields are encrypted after the content hash is taken, so equal
+// parses keep equal hashes.
+func (h *URLHandler) reparseSnapshot(r *http.Request, p *parser.Parser, transformer *parser.Transformer, transform *sharedmodels.TransformConfig, redactor *pii.Redactor, sensitive []string, pageURL, schema string, snapshot database.RawHtmlSnapshot) (database.ParsedDatum, error) {
+	record, err := p.Parse(r.Context(), parser.Document{
+		URL:         pageURL,
+		StatusCode:  int(snapshot.StatusCode),
+		ContentType: snapshot.ContentType,
+		Body:        snapshot.Content,
+	})
+	if err != nil {
+		return database.ParsedDatum{}, err
+	}
+	record.URLID = snapshot.UrlID
+	record.CreatedAt = snapshot.CreatedAt
+	fieldErrors, err := json.Marshal(record.FieldErrors)
+	if err != nil {
+		return database.ParsedDatum{}, err
+	}
+	if transform != nil {
+		transformed, err := transformer.Transform(r.Context(), transform, record)
+		if transformed == nil {
+			return database.ParsedDatum{}, fmt.Errorf("transform webhook failed: %w", err)
+		}
+		record = transformed
+	}
+	redactor.Apply(record)
+
+	if record.Metadata == nil {
+		record.Metadata = map[string]string{}
+	}
+	if record.Data == nil {
+		record.Data = map[string]interface{}{}
+	}
+	metadata, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return database.ParsedDatum{}, err
+	}
+	data, err := json.Marshal(record.Data)
+	if err != nil {
+		return database.ParsedDatum{}, err
+	}
+	contentHash := parsedContentHash(record.Title, record.Content, record.Text, metadata, data)
+	keyID, err := h.sealSensitive(record.Data, sensitive)
+	if err != nil {
+		return database.ParsedDatum{}, err
+	}
+	if keyID != "" {
+		if data, err = json.Marshal(record.Data); err != nil {
+			return database.ParsedDatum{}, err
+		}
+	}
+
+	return h.URLs.CreateParsedData(r.Context(), database.CreateParsedDataParams{
+		UrlID:           snapshot.UrlID,
+		Url:             pageURL,
+		Schema:          schema,
+		Title:           record.Title,
+		Content:         record.Content,
+		Metadata:        metadata,
+		Data:            data,
+		ContentHash:     contentHash,
+		NormalizedText:  record.Text,
+		Simhash:         contentSimhash(record),
+		FieldErrors:     pqtype.NullRawMessage{RawMessage: fieldErrors, Valid: true},
+		CreatedAt:       snapshot.CreatedAt,
+		EncryptionKeyID: keyID,
+	})
+}
+
+// sealSensitive encrypts the sensitive fields of a parsed record's data and
+// returns the ID of the key wrapping them, empty when none were encrypted
+func (h *URLHandler) sealSensitive(data map[string]interface{}, sensitive []string) (string, error) {
+	if len(sensitive) == 0 || data == nil {
+		return "", nil
+	}
+	if h.Keyring == nil {
+		return "", errors.New("parser config has sensitive fields, but no encryption keys are configured")
+	}
+	sealed, err := h.Keyring.SealFields(data, sensitive)
+	if err != nil || len(sealed) == 0 {
+		return "", err
+	}
+	return h.Keyring.PrimaryKeyID(), nil
+}
+
+// parseReparseRange parses the from and to parameters of a reparse. from
+// defaults to the zero time and to defaults to now.
+func parseReparseRange(rawFrom, rawTo string, now time.Time) (time.Time, time.Time, error) {
+	from, to := time.Time{}, now
+	if rawFrom != "" {
+		parsed, err := time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			return from, to, errors.New("from must be an RFC3339 time, e.g. 2024-01-01T00:00:00Z")
+		}
+		from = parsed
+	}
+	if rawTo != "" {
+		parsed, err := time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			return from, to, errors.New("to must be an RFC3339 time, e.g. 2024-01-01T00:00:00Z")
+		}
+		to = parsed
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
+
+// parsedContentHash returns the SHA-256 of a parsed record's content, equal for identical parses.
+// Records with normalized text are hashed on their title and text alone, so
+// a page whose markup changed but whose visible text did not hashes the same.
+func parsedContentHash(title, content, text string, metadata, data []byte) string {
+	parts := [][]byte{[]byte(title), []byte(content), metadata, data}
+	if text != "" {
+		parts = [][]byte{[]byte(title), []byte(text)}
+	}
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// contentSimhash returns the simhash of a parsed record's normalized text,
+// or of its title and content without one, and is not valid for records
+// without text
+func contentSimhash(record *sharedmodels.ParsedData) sql.NullInt64 {
+	text := record.Text
+	if text == "" {
+		text = record.Title + "\n" + record.Content
+	}
+	hash := parser.Simhash(text)
+	return sql.NullInt64{Int64: int64(hash), Valid: hash != 0}
+}
+
+// writeParserConfigError responds to an error from compileParserConfig
+func (h *URLHandler) writeParserConfigError(w http.ResponseWriter, urlID uuid.UUID, err error) {
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		http.Error(w, validationErr.Error(), http.StatusBadRequest)
+		return
+	}
+	h.Logger.WithError(err).WithField("url_id", urlID).Error("Failed to compile parser config")
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// candidateParser compiles the URL's candidate parser config, or returns
+// nil if the URL has no candidate
+func (h *URLHandler) candidateParser(ctx context.Context, urlID uuid.UUID) (*parser.Parser, error) {
+	candidate, err := h.URLs.GetParserCandidate(ctx, urlID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(candidate.Config, &config); err != nil {
+		return nil, &models.ValidationError{Field: "candidate", Message: "Invalid candidate parser config: " + err.Error()}
+	}
+	compiled, _, err := compileParserConfig(ctx, h.URLs, &config, "candidate")
+	return compiled, err
+}
+
+// shadowParse parses a snapshot with the URL's candidate parser config and
+// stores the result next to the active parse of the same snapshot, with
+// personal data and sensitive fields handled like in the active parse
+func (h *URLHandler) shadowParse(r *http.Request, candidate *parser.Parser, redactor *pii.Redactor, sensitive []string, pageURL string, snapshot database.RawHtmlSnapshot, recordID uuid.UUID) error {
+	record, err := candidate.Parse(r.Context(), parser.Document{
+		URL:         pageURL,
+		StatusCode:  int(snapshot.StatusCode),
+		ContentType: snapshot.ContentType,
+		Body:        snapshot.Content,
+	})
+	if err != nil {
+		return err
+	}
+	redactor.Apply(record)
+	if _, err := h.sealSensitive(record.Data, sensitive); err != nil {
+		return err
+	}
+
+	if record.Metadata == nil {
+		record.Metadata = map[string]string{}
+	}
+	metadata, err := json.Marshal(record.Metadata)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record.Data)
+	if err != nil {
+		return err
+	}
+
+	return h.URLs.CreateCandidateParsedData(r.Context(), database.CreateCandidateParsedDataParams{
+		UrlID:        snapshot.UrlID,
+		ParsedDataID: recordID,
+		Title:        record.Title,
+		Content:      record.Content,
+		Metadata:     metadata,
+		Data:         data,
+	})
+}
+
+// SetParserCandidate handles PUT /api/v1/urls/{id}/parser-candidate
+//
+// Purpose: Attaches a candidate parser config to a URL. The candidate runs
+// in shadow mode: every page parsed with the URL's active config is also
+// parsed with the candidate, and the candidate's results are stored
+// separately without affecting the URL's data. Replacing a candidate
+// discards the results of the previous one.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Request Body: models.ParserCandidateRequest
+// Response: models.ParserCandidateResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-candidate
+//	{
+//	  "parser_config": {
+//	    "template": "product-page",
+//	    "selectors": {"price": ".price-now"}
+//	  }
+//	}
+func (h *URLHandler) SetParserCandidate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ParserCandidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ParserConfig.Transform != nil {
+		if err := parser.ValidateTransformConfig(req.ParserConfig.Transform); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if _, _, err := compileParserConfig(r.Context(), h.URLs, req.ParserConfig, "parser_config"); err != nil {
+		h.writeParserConfigError(w, id, err)
+		return
+	}
+
+	configJSON, err := json.Marshal(req.ParserConfig)
+	if err != nil {
+		http.Error(w, "Invalid parser config", http.StatusBadRequest)
+		return
+	}
+	candidate, err := h.URLs.SetParserCandidate(r.Context(), id, configJSON)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to store parser candidate")
+		return
+	}
+
+	h.Logger.WithField("url_id", id).Info("Parser candidate set")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ParserCandidateResponse{
+		URLID:        id.String(),
+		ParserConfig: req.ParserConfig,
+		CreatedAt:    candidate.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    candidate.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// GetParserCandidate handles GET /api/v1/urls/{id}/parser-candidate
+//
+// Purpose: Returns the candidate parser config running in shadow mode for a URL.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: models.ParserCandidateResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-candidate
+func (h *URLHandler) GetParserCandidate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	candidate, err := h.URLs.GetParserCandidate(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get parser candidate")
+		return
+	}
+
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(candidate.Config, &config); err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to parse candidate parser config")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ParserCandidateResponse{
+		URLID:        id.String(),
+		ParserConfig: &config,
+		CreatedAt:    candidate.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    candidate.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// DeleteParserCandidate handles DELETE /api/v1/urls/{id}/parser-candidate
+//
+// Purpose: Discards a URL's candidate parser config and its shadow results.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: 204 No Content or error (400/404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-candidate
+func (h *URLHandler) DeleteParserCandidate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.URLs.DeleteParserCandidate(r.Context(), id); err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to delete parser candidate")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CompareParserCandidate handles GET /api/v1/urls/{id}/parser-candidate/comparison
+//
+// Purpose: Compares the pages parsed with a URL's candidate parser config
+// with the parses of the same pages made with its active config, field by
+// field. The summary counts, per field, the pages where both configs agree,
+// where they disagree and where only one of them extracted the field; the
+// pages with differences are listed with the differing values. Use it to
+// check a candidate before promoting it.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Query Parameters:
+//   - limit: Most recent pages to compare, max 1000 (default: 100)
+//
+// Response: models.ParserComparisonResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-candidate/comparison?limit=50
+func (h *URLHandler) CompareParserCandidate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	rows, err := h.URLs.ListCandidateComparisons(r.Context(), database.ListCandidateComparisonsParams{
+		UrlID:      id,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to list candidate parses")
+		return
+	}
+
+	response, err := compareCandidateParses(id, rows, h.Keyring)
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to decode candidate parses")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// compareCandidateParses summarizes the field differences of paired active
+// and candidate parses, decrypting their encrypted fields
+func compareCandidateParses(urlID uuid.UUID, rows []database.ListCandidateComparisonsRow, keyring *encryption.Keyring) (models.ParserComparisonResponse, error) {
+	response := models.ParserComparisonResponse{
+		URLID:  urlID.String(),
+		Fields: []models.FieldComparisonSummary{},
+		Pages:  []models.PageComparison{},
+	}
+	summaries := make(map[string]*models.FieldComparisonSummary)
+
+	for _, row := range rows {
+		active := &sharedmodels.ParsedData{Title: row.ActiveTitle, Content: row.ActiveContent}
+		if err := json.Unmarshal(row.ActiveData, &active.Data); err != nil {
+			return response, err
+		}
+		candidate := &sharedmodels.ParsedData{Title: row.CandidateTitle, Content: row.CandidateContent}
+		if err := json.Unmarshal(row.CandidateData, &candidate.Data); err != nil {
+			return response, err
+		}
+		if err := keyring.OpenFields(active.Data); err != nil {
+			return response, err
+		}
+		if err := keyring.OpenFields(candidate.Data); err != nil {
+			return response, err
+		}
+
+		page := models.PageComparison{RecordID: row.RecordID.String(), ScrapedAt: row.ScrapedAt.Format(time.RFC3339)}
+		for _, field := range parser.CompareFields(active, candidate) {
+			summary, ok := summaries[field.Field]
+			if !ok {
+				summary = &models.FieldComparisonSummary{Field: field.Field}
+				summaries[field.Field] = summary
+			}
+			switch {
+			case field.Equal:
+				summary.Equal++
+				continue
+			case field.Candidate == nil:
+				summary.MissingInCandidate++
+			case field.Active == nil:
+				summary.MissingInActive++
+			default:
+				summary.Different++
+			}
+			page.Differences = append(page.Differences, models.FieldDifference{
+				Field:     field.Field,
+				Active:    field.Active,
+				Candidate: field.Candidate,
+			})
+		}
+
+		response.Compared++
+		if len(page.Differences) == 0 {
+			response.Identical++
+		} else {
+			response.Pages = append(response.Pages, page)
+		}
+	}
+
+	for _, summary := range summaries {
+		response.Fields = append(response.Fields, *summary)
+	}
+	sort.Slice(response.Fields, func(i, j int) bool {
+		return response.Fields[i].Field < response.Fields[j].Field
+	})
+	return response, nil
+}
+
+// PromoteParserCandidate handles POST /api/v1/urls/{id}/parser-candidate/promote
+//
+// Purpose: Makes a URL's candidate parser config its active config and
+// removes the candidate along with its shadow results. Earlier parses keep
+// the data extracted by the previous config; reparse the URL to backfill
+// them with the promoted one.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: Promoted parser config (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-candidate/promote
+func (h *URLHandler) PromoteParserCandidate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	candidate, err := h.URLs.PromoteParserCandidate(r.Context(), id, h.change(r, "candidate promoted"))
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to promote parser candidate")
+		return
+	}
+
+	h.Logger.WithField("url_id", id).Info("Parser candidate promoted")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       "Parser candidate promoted",
+		"url_id":        id.String(),
+		"parser_config": candidate.Config,
+	})
+}
+
+// authorHeader names the person or system making a change, recorded in
+// the parser config history
+const authorHeader = "X-Author"
+
+// change describes a change a request makes, made by the author it names
+func (h *URLHandler) change(r *http.Request, reason string) services.Change {
+	return services.Change{Author: r.Header.Get(authorHeader), Reason: reason}
+}
+
+// recordParserConfigVersion records a URL's parser config in its version
+// history after a change. A failure is logged but does not fail the request,
+// since the change itself was already made.
+func (h *URLHandler) recordParserConfigVersion(r *http.Request, urlID uuid.UUID, config pqtype.NullRawMessage, reason string) {
+	h.URLs.RecordParserConfigVersion(r.Context(), urlID, config, h.change(r, reason))
+}
+
+// ListParserConfigVersions handles GET /api/v1/urls/{id}/parser-config/versions
+//
+// Purpose: Lists the version history of a URL's parser config, newest
+// first. A version is recorded whenever the config changes: on creation,
+// clone, import, patch, configuration sync, candidate promotion and rollback. Each
+// version records who made the change (the X-Author request header) and why.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Query Parameters:
+//   - page: Page number (default: 1)
+//   - limit: Versions per page, max 100 (default: 20)
+//
+// Response: models.ParserConfigVersionsResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-config/versions?limit=10
+func (h *URLHandler) ListParserConfigVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	rows, total, err := h.URLs.ListParserConfigVersions(r.Context(), database.ListParserConfigVersionsParams{
+		UrlID:  id,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to list parser config versions")
+		return
+	}
+
+	response := models.ParserConfigVersionsResponse{
+		URLID:    id.String(),
+		Versions: make([]models.ParserConfigVersionResponse, 0, len(rows)),
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	}
+	for _, row := range rows {
+		version, err := parserConfigVersionResponse(row)
+		if err != nil {
+			h.Logger.WithError(err).WithFields(logrus.Fields{"url_id": id, "version": row.Version}).Error("Failed to parse stored parser config version")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Versions = append(response.Versions, version)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parserConfigVersionResponse converts a stored parser config version into its response
+func parserConfigVersionResponse(row database.ParserConfigVersion) (models.ParserConfigVersionResponse, error) {
+	config, err := versionConfig(row)
+	if err != nil {
+		return models.ParserConfigVersionResponse{}, err
+	}
+	return models.ParserConfigVersionResponse{
+		Version:      int(row.Version),
+		ParserConfig: config,
+		Author:       row.Author,
+		Reason:       row.Reason,
+		CreatedAt:    row.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// versionConfig decodes the parser config of a version, nil if the URL had none
+func versionConfig(row database.ParserConfigVersion) (*sharedmodels.ParserConfig, error) {
+	if !row.Config.Valid {
+		return nil, nil
+	}
+	var config sharedmodels.ParserConfig
+	if err := json.Unmarshal(row.Config.RawMessage, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// DiffParserConfigVersions handles GET /api/v1/urls/{id}/parser-config/diff
+//
+// Purpose: Shows the settings that changed between two versions of a URL's
+// parser config: selectors, rules (by name), options, template, script and
+// transform settings, each with its old and new value.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Query Parameters:
+//   - to: Version to compare (default: the latest version)
+//   - from: Version to compare it with (default: the version before to)
+//
+// Response: models.ParserConfigDiffResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-config/diff?from=3&to=5
+func (h *URLHandler) DiffParserConfigVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	var to database.ParserConfigVersion
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil || version <= 0 {
+			http.Error(w, "Invalid to: must be a positive version number", http.StatusBadRequest)
+			return
+		}
+		to, err = h.URLs.GetParserConfigVersion(r.Context(), id, int32(version))
+	} else {
+		to, err = h.URLs.GetLatestParserConfigVersion(r.Context(), id)
+	}
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get parser config version")
+		return
+	}
+
+	fromVersion := int(to.Version) - 1
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		fromVersion, err = strconv.Atoi(raw)
+		if err != nil || fromVersion <= 0 {
+			http.Error(w, "Invalid from: must be a positive version number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Version 1 is compared with an empty config
+	var from database.ParserConfigVersion
+	if fromVersion > 0 {
+		from, err = h.URLs.GetParserConfigVersion(r.Context(), id, int32(fromVersion))
+		if err != nil {
+			writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get parser config version")
+			return
+		}
+	}
+
+	changes, err := diffVersions(from, to)
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to diff parser config versions")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ParserConfigDiffResponse{
+		URLID:   id.String(),
+		From:    fromVersion,
+		To:      int(to.Version),
+		Changes: changes,
+	})
+}
+
+// diffVersions returns the settings that changed from one parser config version to another
+func diffVersions(from, to database.ParserConfigVersion) ([]parser.ConfigChange, error) {
+	fromConfig, err := versionConfig(from)
+	if err != nil {
+		return nil, err
+	}
+	toConfig, err := versionConfig(to)
+	if err != nil {
+		return nil, err
+	}
+	return parser.DiffConfigs(fromConfig, toConfig)
+}
+
+// RollbackParserConfig handles POST /api/v1/urls/{id}/parser-config/rollback/{version}
+//
+// Purpose: Restores an earlier version of a URL's parser config, e.g. to
+// revert an accidental selector change. The restored config becomes the
+// latest version, so the rollback itself shows up in the history and can be
+// undone the same way.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//   - version: Version to restore (required)
+//
+// Response: models.ParserConfigVersionResponse of the new version (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/parser-config/rollback/3
+func (h *URLHandler) RollbackParserConfig(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version <= 0 {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	latest, err := h.URLs.RollbackParserConfig(r.Context(), id, int32(version), h.change(r, fmt.Sprintf("rollback to version %d", version)))
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to roll back parser config")
+		return
+	}
+	response, err := parserConfigVersionResponse(latest)
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to parse stored parser config version")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"url_id":  id,
+		"version": version,
+		"author":  latest.Author,
+	}).Info("Parser config rolled back")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetURLStatus handles GET /api/v1/urls/{id}/status
+//
+// Purpose: Retrieves current status and scheduling information for a URL.
+// This endpoint provides real-time information about the URL's scraping
+// status, including last scrape time, next scheduled scrape, and retry
+// information.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: URL status details (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/url-123/status
+func (h *URLHandler) GetURLStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		http.Error(w, "URL ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// TODO: Get URL status using service
+	// url, err := h.urlService.GetURL(r.Context(), id)
+	// if err != nil {
+	//     writeError(w, h.Logger, err, "Failed to get URL status")
+	//     return
+	// }
+
+	// For now, return mock data
+	response := map[string]interface{}{
+		"id":              id,
+		"status":          "pending",
+		"last_scraped_at": nil,
+		"next_scrape_at":  "2024-01-01T01:00:00Z",
+		"retry_count":     0,
+		"max_retries":     3,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// calculateNextScrapeTime calculates when the URL should be scraped next
+func (h *URLHandler) calculateNextScrapeTime(frequency string, from time.Time) (time.Time, error) {
+	return utils.CalculateNextScrapeTime(frequency, from)
+}
+
+// URL move states accepted by ListURLMoves
+const (
+	URLMoveStatePending = "pending" // Stored URL not updated yet
+	URLMoveStateApplied = "applied" // Stored URL updated to the new location
+)
+
+// ListURLMoves handles GET /api/v1/urls/moves
+//
+// Purpose: Lists URLs detected as moved: their scrapes were redirected
+// permanently (301 or 308) to the same location redirects.move_threshold
+// times in a row. With redirects.auto_update, the URL Manager also updates
+// the stored URL to the new location; pending moves are left to an operator.
+// Every scrape's redirect chain and final URL are stored on its task.
+//
+// Query Parameters:
+//   - state: pending or applied (default: both)
+//   - page: Page number (default: 1)
+//   - limit: Moves per page, max 100 (default: 20)
+//
+// Response: models.URLMovesResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/moves?state=pending
+func (h *URLHandler) ListURLMoves(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	if state != "" && state != URLMoveStatePending && state != URLMoveStateApplied {
+		http.Error(w, "state must be pending or applied", http.StatusBadRequest)
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	rows, total, err := h.URLs.ListURLMoves(r.Context(), database.ListURLMovesParams{
+		State:  state,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		writeError(w, h.Logger, err, "Failed to list URL moves")
+		return
+	}
+
+	response := models.URLMovesResponse{
+		Moves: make([]models.URLMoveResponse, 0, len(rows)),
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+	for _, row := range rows {
+		move := models.URLMoveResponse{
+			URLID:       row.UrlID.String(),
+			FromURL:     row.FromUrl,
+			ToURL:       row.ToUrl,
+			Hits:        int(row.Hits),
+			FirstSeenAt: row.FirstSeenAt.Format(time.RFC3339),
+			LastSeenAt:  row.LastSeenAt.Format(time.RFC3339),
+			FlaggedAt:   row.FlaggedAt.Time.Format(time.RFC3339),
+			Applied:     row.AppliedAt.Valid,
+		}
+		if row.AppliedAt.Valid {
+			move.AppliedAt = row.AppliedAt.Time.Format(time.RFC3339)
+		}
+		response.Moves = append(response.Moves, move)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListScrapes handles GET /api/v1/urls/scrapes
+//
+// Purpose: Searches the scrape history of every URL, newest first, e.g. for
+// the pages served with "x-robots-tag: noindex" or by a given server. Each
+// scrape keeps the response headers named in scraping.capture_headers.
+//
+// Query Parameters:
+//   - header: name:value to match scrapes whose header has exactly that
+//     value, or name to match scrapes that have the header; repeat to match
+//     all of them. Names are case-insensitive, values are not.
+//   - page: Page number (default: 1)
+//   - limit: Scrapes per page, max 100 (default: 20)
+//
+// Response: models.ScrapesResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/scrapes?header=x-robots-tag:noindex&header=server:nginx
+func (h *URLHandler) ListScrapes(w http.ResponseWriter, r *http.Request) {
+	h.listScrapes(w, r, uuid.NullUUID{})
+}
+
+// ListURLScrapes handles GET /api/v1/urls/{id}/scrapes
+//
+// Purpose: Lists the scrape history of a URL, newest first, with each
+// scrape's outcome, final URL and captured response headers. Takes the same
+// header filters as GET /api/v1/urls/scrapes.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: models.ScrapesResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/scrapes?header=cache-control
+func (h *URLHandler) ListURLScrapes(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+	h.listScrapes(w, r, uuid.NullUUID{UUID: id, Valid: true})
+}
+
+// listScrapes serves a page of the scrape history of urlID, or of every URL
+func (h *URLHandler) listScrapes(w http.ResponseWriter, r *http.Request, urlID uuid.NullUUID) {
+	headers, names, err := parseHeaderFilters(r.URL.Query()["header"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	rows, total, err := h.URLs.ListScrapes(r.Context(), database.ListScrapingTasksParams{
+		UrlID:       urlID,
+		Headers:     encodedHeaders,
+		HeaderNames: names,
+		Limit:       int32(limit),
+		Offset:      int32((page - 1) * limit),
+	})
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", urlID.UUID), err, "Failed to list scrapes")
+		return
+	}
+
+	response := models.ScrapesResponse{
+		Scrapes: make([]models.ScrapeResponse, 0, len(rows)),
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}
+	for _, row := range rows {
+		response.Scrapes = append(response.Scrapes, scrapeResponse(row))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseHeaderFilters splits header query parameters into the header values
+// to match, by lowercase name, and the names of headers that must be present
+func parseHeaderFilters(filters []string) (map[string]string, []string, error) {
+	headers := make(map[string]string)
+	names := []string{}
+	for _, filter := range filters {
+		name, value, hasValue := strings.Cut(filter, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || strings.ContainsAny(name, " \t\"") {
+			return nil, nil, fmt.Errorf("invalid header filter %q, want name or name:value", filter)
+		}
+		if hasValue {
+			headers[name] = strings.TrimSpace(value)
+		} else {
+			names = append(names, name)
+		}
+	}
+	return headers, names, nil
+}
+
+// GetScrape handles GET /api/v1/urls/scrapes/{task_id}
+//
+// Purpose: Returns one scrape by its task ID, e.g. the task a manual
+// trigger created (see the Location header of TriggerScrape). The status
+// is pending until a scraper reports the result.
+//
+// Path Parameters:
+//   - task_id: Scraping task identifier (required)
+//
+// Response: models.ScrapeResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/scrapes/8d0e6f52-1c3a-4f7b-9e21-5a6b7c8d9e0f
+func (h *URLHandler) GetScrape(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["task_id"])
+	if err != nil {
+		http.Error(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	row, err := h.URLs.GetScrape(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger.WithField("task_id", id), err, "Failed to get scraping task")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scrapeResponse(row))
+}
+
+// scrapeResponse converts a stored scraping task into its response
+func scrapeResponse(row database.ScrapingTask) models.ScrapeResponse {
+	scrape := models.ScrapeResponse{
+		TaskID:     row.ID.String(),
+		URLID:      row.UrlID.String(),
+		Attempt:    int(row.Attempt),
+		Status:     row.Status,
+		StatusCode: int(row.StatusCode.Int32),
+		ErrorCode:  row.ErrorCode.String,
+		DurationMs: row.DurationMs.Int64,
+		DNSMs:      row.DnsMs,
+		FinalURL:   row.FinalUrl.String,
+		CreatedAt:  row.CreatedAt.Format(time.RFC3339),
+	}
+	if row.CompletedAt.Valid {
+		scrape.CompletedAt = row.CompletedAt.Time.Format(time.RFC3339)
+	}
+	if row.ResponseHeaders.Valid {
+		// Headers are written by the URL Manager as a JSON object of strings
+		json.Unmarshal(row.ResponseHeaders.RawMessage, &scrape.Headers)
+	}
+	return scrape
+}
+
+// HAR capture states reported by the HAR endpoints
+const (
+	HARCapturePending  = "pending"  // Waiting for the URL's next scrape
+	HARCaptureScraping = "scraping" // Claimed by a scrape, waiting for its result
+	HARCaptureCaptured = "captured" // HAR available
+)
+
+// RequestHARCapture handles POST /api/v1/urls/{id}/har
+//
+// Purpose: Asks the scrapers to record the URL's next scrape as a HAR
+// (headers, timings, redirects and a preview of each response body), for
+// investigating why a site returns different content to the scrapers. The
+// request waits for the next scrape for at most its ttl; the captured HAR
+// is kept for scraping.har.retention. A new request replaces any earlier
+// capture of the URL.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Request Body (optional):
+//
+//	{
+//	  "ttl": "2h"  // How long to wait for a scrape, at most scraping.har.request_ttl (the default)
+//	}
+//
+// Response: models.HARCaptureResponse (202 Accepted) or error (400/404/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/har
+//	{"ttl": "30m"}
+func (h *URLHandler) RequestHARCapture(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.HARCaptureRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	ttl, err := harCaptureTTL(req.TTL, h.Config.Current().Scraping.HAR.RequestTTL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	capture, err := h.URLs.RequestHARCapture(r.Context(), id, ttl)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to request HAR capture")
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{"url_id": id, "ttl": ttl.String()}).Info("HAR capture requested")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(harCaptureResponse(capture))
+}
+
+// GetHARCapture handles GET /api/v1/urls/{id}/har
+//
+// Purpose: Returns the HAR captured for a URL as a HAR 1.2 document, which
+// can be opened in a browser's developer tools. While the capture is still
+// waiting for a scrape, its state is returned instead with 202 Accepted.
+// Credentials (Authorization and Cookie headers, cookie values) are
+// redacted by the scrapers.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: HAR document (200 OK), models.HARCaptureResponse (202 Accepted)
+// or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/har
+func (h *URLHandler) GetHARCapture(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	capture, err := h.URLs.GetHARCapture(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get HAR capture")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !capture.Har.Valid {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(harCaptureResponse(capture))
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.har"`, id))
+	w.Write(capture.Har.RawMessage)
+}
+
+// DeleteHARCapture handles DELETE /api/v1/urls/{id}/har
+//
+// Purpose: Cancels a pending HAR capture of a URL or deletes its captured HAR.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Response: 204 No Content or error (400/404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/har
+func (h *URLHandler) DeleteHARCapture(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.URLs.DeleteHARCapture(r.Context(), id); err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to delete HAR capture")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRenderSession handles GET /api/v1/urls/{id}/render-session
+//
+// Purpose: Returns what the browser did while rendering the URL's latest
+// kept rendered scrape: its console log and the network requests the page
+// made, with the first bytes of XHR and fetch responses. When a selector
+// comes back empty on a rendered page, pass the value it should have
+// extracted as find to see which XHR delivered it. Sessions are kept only
+// for URLs whose archive policy sets render_session.
+//
+// Path Parameters:
+//   - id: URL identifier (required)
+//
+// Query Parameters:
+//   - find: Text to look for in the URLs and bodies of XHR and fetch requests (optional)
+//
+// Response: models.RenderSessionResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/urls/123e4567-e89b-12d3-a456-426614174000/render-session?find=24.90
+func (h *URLHandler) GetRenderSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	row, err := h.URLs.GetRenderSession(r.Context(), id)
+	if err != nil {
+		writeError(w, h.Logger.WithField("url_id", id), err, "Failed to get render session")
+		return
+	}
+
+	response, err := renderSessionResponse(id, row, r.URL.Query().Get("find"))
+	if err != nil {
+		h.Logger.WithError(err).WithField("url_id", id).Error("Failed to decode render session")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// renderSessionResponse decodes a stored render session, looking for find
+// in its data requests when given
+func renderSessionResponse(urlID uuid.UUID, row database.GetLatestRenderSessionRow, find string) (models.RenderSessionResponse, error) {
+	var session render.Session
+	if err := json.Unmarshal(row.Session, &session); err != nil {
+		return models.RenderSessionResponse{}, err
+	}
+
+	response := models.RenderSessionResponse{
+		URLID:      urlID.String(),
+		SnapshotID: row.SnapshotID.String(),
+		StatusCode: row.StatusCode,
+		RecordedAt: row.CreatedAt.Format(time.RFC3339),
+		Session:    &session,
+		Find:       find,
+	}
+	if row.TaskID.Valid {
+		response.TaskID = row.TaskID.UUID.String()
+	}
+	if find != "" {
+		response.Matches = session.Find(find)
+	}
+	return response, nil
+}
+
+// harCaptureTTL returns how long a HAR capture request waits for a scrape:
+// the requested ttl, or maxTTL when none is given
+func harCaptureTTL(value string, maxTTL time.Duration) (time.Duration, error) {
+	if value == "" {
+		return maxTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("ttl must be a positive duration such as 30m")
+	}
+	if ttl > maxTTL {
+		return 0, fmt.Errorf("ttl must be at most %s", maxTTL)
+	}
+	return ttl, nil
+}
+
+// harCaptureResponse converts a stored HAR capture into its state
+func harCaptureResponse(capture database.HarCapture) models.HARCaptureResponse {
+	response := models.HARCaptureResponse{
+		URLID:       capture.UrlID.String(),
+		Status:      HARCapturePending,
+		RequestedAt: capture.RequestedAt.Format(time.RFC3339),
+		ExpiresAt:   capture.ExpiresAt.Format(time.RFC3339),
+	}
+	if capture.TaskID.Valid {
+		response.Status = HARCaptureScraping
+		response.TaskID = capture.TaskID.UUID.String()
+	}
+	if capture.Har.Valid {
+		response.Status = HARCaptureCaptured
+	}
+	if capture.CapturedAt.Valid {
+		response.CapturedAt = capture.CapturedAt.Time.Format(time.RFC3339)
 	}
+	return response
 }
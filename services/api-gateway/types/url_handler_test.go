@@ -0,0 +1,562 @@
+package types
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/control"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+func TestURLConfigYAMLRoundTrip(t *testing.T) {
+	export := models.ExportURLsResponse{
+		ExportedAt: "2024-01-01T00:00:00Z",
+		Total:      1,
+		URLs: []models.CreateURLRequest{{
+			URL:        "https://example.com/news",
+			Frequency:  "1h",
+			UserAgent:  "true",
+			MaxRetries: 3,
+			ParserConfig: &sharedmodels.ParserConfig{
+				Version:   sharedmodels.ParserConfigVersion,
+				Selectors: map[string]string{"title": "h1"},
+				Options:   &sharedmodels.ParseOptions{ExtractMetadata: true},
+			},
+			RetryPolicy: &sharedmodels.RetryPolicy{MaxAttempts: 4, RetryOnStatus: []int{429, 503}},
+			Tags:        []string{"news", "team:growth"},
+		}},
+	}
+
+	data, err := encodeYAML(export)
+	if err != nil {
+		t.Fatalf("encodeYAML() error = %v", err)
+	}
+	for _, want := range []string{"exported_at:", "extract_metadata: true", "user_agent: \"true\"", "- team:growth"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("YAML export missing %q:\n%s", want, data)
+		}
+	}
+
+	var imported models.ImportURLsRequest
+	if err := decodeYAML(data, &imported); err != nil {
+		t.Fatalf("decodeYAML() error = %v", err)
+	}
+	got, _ := json.Marshal(imported.URLs)
+	want, _ := json.Marshal(export.URLs)
+	if string(got) != string(want) {
+		t.Errorf("round trip = %s, want %s", got, want)
+	}
+}
+
+func TestValidateBulkURLRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.BulkURLRequest
+		wantIDs int
+		wantErr bool
+	}{
+		{name: "no filters", req: models.BulkURLRequest{}, wantErr: true},
+		{name: "tag only", req: models.BulkURLRequest{Tag: "News"}},
+		{name: "domain only", req: models.BulkURLRequest{Domain: "Example.com."}},
+		{name: "ids", req: models.BulkURLRequest{IDs: []string{"3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11"}}, wantIDs: 1},
+		{name: "invalid id", req: models.BulkURLRequest{IDs: []string{"url-123"}}, wantErr: true},
+		{name: "invalid domain", req: models.BulkURLRequest{Domain: "%.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := validateBulkURLRequest(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateBulkURLRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if ids == nil || len(ids) != tt.wantIDs {
+				t.Errorf("validateBulkURLRequest() ids = %v, want %d non-nil", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestTriggerScrape(t *testing.T) {
+	known := uuid.New()
+	overBudget := uuid.New()
+	taskID := uuid.New()
+	publishedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == control.TriggerPath(overBudget) {
+			w.Header().Set("Retry-After", "3600")
+			http.Error(w, "Daily scrape budget of project shop is used up (500 scrapes)", http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Path != control.TriggerPath(known) {
+			http.Error(w, "URL not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(control.TriggerResponse{TaskID: taskID, URLID: known, Topic: "scraping-tasks", Attempt: 1, CreatedAt: publishedAt})
+	}))
+	defer manager.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewURLHandler(logger, nil, nil, control.NewClient(config.ControlConfig{URLManagerURL: manager.URL}, nil), nil)
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{known.String(), http.StatusAccepted},
+		{uuid.New().String(), http.StatusNotFound},
+		{"url-123", http.StatusBadRequest},
+		{overBudget.String(), http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/v1/urls/"+tt.id+"/scrape", nil), map[string]string{"id": tt.id})
+		w := httptest.NewRecorder()
+		handler.TriggerScrape(w, r)
+		if w.Code != tt.want {
+			t.Errorf("TriggerScrape(%s) status = %d, want %d", tt.id, w.Code, tt.want)
+		}
+		if tt.want == http.StatusAccepted {
+			var task models.ScrapeResponse
+			json.NewDecoder(w.Body).Decode(&task)
+			want := models.ScrapeResponse{TaskID: taskID.String(), URLID: known.String(), Attempt: 1, Status: "pending", CreatedAt: "2024-03-01T12:00:00Z"}
+			if !reflect.DeepEqual(task, want) || w.Header().Get("Location") != "/api/v1/urls/scrapes/"+taskID.String() {
+				t.Errorf("accepted response = %+v, Location %q; want %+v", task, w.Header().Get("Location"), want)
+			}
+		}
+		if tt.want != http.StatusTooManyRequests {
+			continue
+		}
+		var rejected models.TriggerRejectedResponse
+		json.NewDecoder(w.Body).Decode(&rejected)
+		if w.Header().Get("Retry-After") != "3600" || rejected.RetryAfter != 3600 || !strings.Contains(rejected.Message, "project shop") {
+			t.Errorf("over budget response = %+v, Retry-After %q", rejected, w.Header().Get("Retry-After"))
+		}
+	}
+
+	manager.Close()
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", nil), map[string]string{"id": known.String()})
+	w := httptest.NewRecorder()
+	handler.TriggerScrape(w, r)
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("TriggerScrape() with the URL Manager down status = %d, want %d", w.Code, http.StatusBadGateway)
+	}
+}
+
+func TestParseReparseRange(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	from, to, err := parseReparseRange("", "", now)
+	if err != nil || !from.IsZero() || !to.Equal(now) {
+		t.Errorf("defaults = %v, %v, %v; want zero time to now", from, to, err)
+	}
+	from, to, err = parseReparseRange("2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", now)
+	if err != nil || from.Month() != time.January || to.Month() != time.February {
+		t.Errorf("range = %v, %v, %v", from, to, err)
+	}
+
+	for _, tt := range [][2]string{{"yesterday", ""}, {"", "2024-02-01"}, {"2024-02-01T00:00:00Z", "2024-01-01T00:00:00Z"}, {"2024-03-01T00:00:00Z", ""}} {
+		if _, _, err := parseReparseRange(tt[0], tt[1], now); err == nil {
+			t.Errorf("parseReparseRange(%q, %q) accepted an invalid range", tt[0], tt[1])
+		}
+	}
+}
+
+func TestCompareCandidateParses(t *testing.T) {
+	urlID := uuid.New()
+	scraped := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []database.ListCandidateComparisonsRow{
+		{
+			RecordID:       uuid.New(),
+			ScrapedAt:      scraped,
+			ActiveTitle:    "Kettle",
+			ActiveData:     json.RawMessage(`{"price": "24.90", "sku": "K1"}`),
+			CandidateTitle: "Kettle",
+			CandidateData:  json.RawMessage(`{"price": "24.90"}`),
+		},
+		{
+			RecordID:       uuid.New(),
+			ScrapedAt:      scraped,
+			ActiveTitle:    "Kettle",
+			ActiveData:     json.RawMessage(`{"price": "€24.90"}`),
+			CandidateTitle: "Kettle",
+			CandidateData:  json.RawMessage(`{"price": "24.90"}`),
+		},
+		{
+			RecordID:       uuid.New(),
+			ScrapedAt:      scraped,
+			ActiveTitle:    "Kettle",
+			ActiveData:     json.RawMessage(`{}`),
+			CandidateTitle: "Kettle",
+			CandidateData:  json.RawMessage(`{}`),
+		},
+	}
+
+	response, err := compareCandidateParses(urlID, rows, nil)
+	if err != nil {
+		t.Fatalf("compareCandidateParses() error = %v", err)
+	}
+	if response.Compared != 3 || response.Identical != 1 || len(response.Pages) != 2 {
+		t.Fatalf("response = %+v, want 3 compared, 1 identical and 2 pages", response)
+	}
+	want := []models.FieldComparisonSummary{
+		{Field: "price", Equal: 1, Different: 1},
+		{Field: "sku", MissingInCandidate: 1},
+		{Field: "title", Equal: 3},
+	}
+	if !reflect.DeepEqual(response.Fields, want) {
+		t.Errorf("fields = %+v, want %+v", response.Fields, want)
+	}
+	if diff := response.Pages[1].Differences; len(diff) != 1 || diff[0].Active != "€24.90" || diff[0].Candidate != "24.90" {
+		t.Errorf("differences = %+v", diff)
+	}
+}
+
+func TestDiffVersions(t *testing.T) {
+	version := func(n int32, config string) database.ParserConfigVersion {
+		row := database.ParserConfigVersion{Version: n}
+		if config != "" {
+			row.Config = pqtype.NullRawMessage{RawMessage: json.RawMessage(config), Valid: true}
+		}
+		return row
+	}
+
+	changes, err := diffVersions(version(1, `{"selectors": {"price": ".price"}}`), version(2, `{"selectors": {"price": ".price-now"}}`))
+	if err != nil {
+		t.Fatalf("diffVersions() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "selectors.price" || changes[0].Old != ".price" || changes[0].New != ".price-now" {
+		t.Errorf("changes = %+v, want selectors.price changed", changes)
+	}
+
+	// The first version is compared with an empty config
+	changes, err = diffVersions(database.ParserConfigVersion{}, version(1, `{"title_selector": "h1"}`))
+	if err != nil {
+		t.Fatalf("diffVersions() error = %v", err)
+	}
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	if want := []string{"selectors.title", "version"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case r.URL.Path == "/blocked" && r.UserAgent() == defaultUserAgent:
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			w.Write([]byte("<html><body><h1>Steel kettle</h1></body></html>"))
+		}
+	}))
+	defer site.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	watcher := config.NewWatcher(&config.Config{Scraping: config.ScrapingConfig{RespectRobotsTxt: true}}, nil, logger)
+	handler := NewURLHandler(logger, nil, watcher, nil, nil)
+
+	tests := []struct {
+		name     string
+		body     string
+		valid    bool
+		warnings []string
+	}{
+		{"reachable", `{"url": "` + site.URL + `/kettle", "frequency": "1h", "parser_config": {"selectors": {"title": "h1"}}}`, true, nil},
+		{"blocked", `{"url": "` + site.URL + `/blocked", "frequency": "1h"}`, true, []string{`site returns 403 to default user agent "GoScrapingBot/1.0"`}},
+		{"other user agent", `{"url": "` + site.URL + `/blocked", "frequency": "1h", "user_agent": "Mozilla/5.0"}`, true, nil},
+		{"robots", `{"url": "` + site.URL + `/private/kettle", "frequency": "1h"}`, true, []string{`robots.txt disallows /private/kettle for default user agent "GoScrapingBot/1.0"; scrapes will be skipped while respect_robots_txt is enabled`}},
+		{"missing selector", `{"url": "` + site.URL + `/kettle", "frequency": "1h", "parser_config": {"selectors": {"price": ".price"}}}`, true, []string{`parser field "price" extracts nothing from the page`}},
+		{"invalid", `{"url": "` + site.URL + `/kettle", "frequency": "5s", "parser_config": {"selectors": {"title": "h1["}}}`, false, nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ValidateURL(w, httptest.NewRequest(http.MethodPost, "/api/v1/urls/validate", strings.NewReader(tt.body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", tt.name, w.Code, w.Body)
+		}
+		var response models.URLValidationResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Valid != tt.valid || (tt.valid && len(response.Errors) > 0) {
+			t.Errorf("%s: valid = %v, errors = %+v", tt.name, response.Valid, response.Errors)
+		}
+		if !tt.valid && len(response.Errors) != 2 {
+			t.Errorf("%s: errors = %+v, want frequency and parser config errors", tt.name, response.Errors)
+		}
+		if len(response.Warnings) != len(tt.warnings) || (len(tt.warnings) > 0 && !reflect.DeepEqual(response.Warnings, tt.warnings)) {
+			t.Errorf("%s: warnings = %q, want %q", tt.name, response.Warnings, tt.warnings)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	var userAgents []string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/get-only":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/private/page":
+			w.WriteHeader(http.StatusForbidden)
+		}
+		userAgents = append(userAgents, r.Method+" "+r.UserAgent())
+	}))
+	defer site.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name string
+		url  database.Url
+		want models.URLProbeResponse
+	}{
+		{
+			name: "redirected",
+			url:  database.Url{Url: site.URL + "/old"},
+			want: models.URLProbeResponse{Method: "HEAD", UserAgent: defaultUserAgent, Reachable: true, StatusCode: 200,
+				Redirects: []sharedmodels.Redirect{{URL: site.URL + "/old", StatusCode: 301}}, FinalURL: site.URL + "/new"},
+		},
+		{
+			name: "HEAD not allowed",
+			url:  database.Url{Url: site.URL + "/get-only", UserAgent: sql.NullString{String: "Mozilla/5.0", Valid: true}},
+			want: models.URLProbeResponse{Method: "GET", UserAgent: "Mozilla/5.0", Reachable: true, StatusCode: 200},
+		},
+		{
+			name: "disallowed by robots.txt",
+			url:  database.Url{Url: site.URL + "/private/page"},
+			want: models.URLProbeResponse{Method: "HEAD", UserAgent: defaultUserAgent, Reachable: true, StatusCode: 403},
+		},
+		{
+			name: "unreachable",
+			url:  database.Url{Url: closed.URL + "/page", Timeout: 1},
+			want: models.URLProbeResponse{Method: "HEAD", UserAgent: defaultUserAgent},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := probe(context.Background(), nil, &tt.url)
+			wantAllowed := tt.name != "disallowed by robots.txt"
+			if tt.name == "unreachable" {
+				if got.Error == "" || got.RobotsError == "" || got.RobotsAllowed != nil {
+					t.Errorf("probe() = %+v, want errors of the site and robots.txt", got)
+				}
+			} else if got.RobotsAllowed == nil || *got.RobotsAllowed != wantAllowed {
+				t.Errorf("robots_allowed = %v, want %v", got.RobotsAllowed, wantAllowed)
+			}
+			got.URLID, got.URL, got.ResponseTimeMs, got.DNSMs, got.RobotsAllowed, got.RobotsError, got.Error = "", "", 0, 0, nil, "", ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("probe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if want := []string{"HEAD " + defaultUserAgent, "HEAD " + defaultUserAgent, "HEAD Mozilla/5.0", "GET Mozilla/5.0", "HEAD " + defaultUserAgent}; !reflect.DeepEqual(userAgents, want) {
+		t.Errorf("requests = %q, want %q", userAgents, want)
+	}
+}
+
+func TestValidateCreateURLRequest(t *testing.T) {
+	handler := NewURLHandler(logrus.New(), nil, config.NewWatcher(&config.Config{}, nil, nil), nil, nil)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{"valid", `{"url": "https://example.com/news", "frequency": "1h", "parser_config": {"selectors": {"title": "h1"}, "rules": [{"name": "price", "selector": ".price"}]}}`, ""},
+		{"missing url", `{"frequency": "1h"}`, "url"},
+		{"ftp url", `{"url": "ftp://example.com/feed", "frequency": "1h"}`, "url"},
+		{"invalid frequency", `{"url": "https://example.com/news", "frequency": "hourly"}`, "frequency"},
+		{"timeout too long", `{"url": "https://example.com/news", "frequency": "1h", "timeout": 600}`, "timeout"},
+		{"negative retries", `{"url": "https://example.com/news", "frequency": "1h", "max_retries": -1}`, "max_retries"},
+		{"invalid selector", `{"url": "https://example.com/news", "frequency": "1h", "parser_config": {"selectors": {"title": "h1["}}}`, "parser_config.selectors[title]"},
+		{"rule without selector", `{"url": "https://example.com/news", "frequency": "1h", "parser_config": {"rules": [{"name": "price"}]}}`, "parser_config.rules[0].selector"},
+	}
+	for _, tt := range tests {
+		var req models.CreateURLRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatal(err)
+		}
+		err := handler.validateCreateURLRequest(&req)
+		var validationErr *models.ValidationError
+		switch {
+		case tt.wantField == "" && err != nil:
+			t.Errorf("%s: error = %v", tt.name, err)
+		case tt.wantField != "" && (!errors.As(err, &validationErr) || validationErr.Field != tt.wantField):
+			t.Errorf("%s: error = %#v, want a validation error of %s", tt.name, err, tt.wantField)
+		}
+	}
+}
+
+func TestPatchURLRejectsInvalidPatches(t *testing.T) {
+	handler := NewURLHandler(logrus.New(), nil, config.NewWatcher(&config.Config{}, nil, nil), nil, nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/urls/{id}", handler.PatchURL).Methods("PATCH")
+
+	tests := []struct {
+		name        string
+		id          string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"invalid id", "url-123", "application/merge-patch+json", `{}`, http.StatusBadRequest},
+		{"form body", "3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11", "application/x-www-form-urlencoded", `timeout=60`, http.StatusUnsupportedMediaType},
+		{"array patch", "3f1c2a9e-6d1b-4c1e-9a57-2b8e0f4d7c11", "application/merge-patch+json", `[{"op": "remove", "path": "/user_agent"}]`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/urls/"+tt.id, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestCheckFrequencyFloor(t *testing.T) {
+	policy := config.FrequencyPolicyConfig{
+		MinFrequency: 5 * time.Minute,
+		Floors:       []config.FrequencyFloorConfig{{Domain: "partner.example.com", MinFrequency: time.Hour}},
+	}
+	handler := NewURLHandler(logrus.New(), nil, config.NewWatcher(&config.Config{FrequencyPolicy: policy}, nil, nil), nil, nil)
+
+	tests := []struct {
+		url       string
+		frequency string
+		wantErr   bool
+	}{
+		{"https://example.com/news", "5m", false},
+		{"https://example.com/news", "1m", true},
+		{"https://partner.example.com/feed", "45m", true},
+		{"https://partner.example.com/feed", "2h", false},
+	}
+	for _, tt := range tests {
+		err := handler.checkFrequencyFloor(tt.url, tt.frequency, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkFrequencyFloor(%q, %q) error = %v, wantErr %v", tt.url, tt.frequency, err, tt.wantErr)
+		}
+	}
+	if err := handler.checkFrequencyFloor("https://partner.example.com/feed", "45m", ""); err == nil || err.Error() != "Frequency must be at least 1h for this URL" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestParseHeaderFilters(t *testing.T) {
+	headers, names, err := parseHeaderFilters([]string{"X-Robots-Tag: noindex", "Cache-Control", "server:nginx/1.25"})
+	if err != nil {
+		t.Fatalf("parseHeaderFilters() error = %v", err)
+	}
+	if want := map[string]string{"x-robots-tag": "noindex", "server": "nginx/1.25"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+	if !reflect.DeepEqual(names, []string{"cache-control"}) {
+		t.Errorf("names = %v, want [cache-control]", names)
+	}
+
+	for _, filter := range []string{"", ":nginx", "x robots:noindex"} {
+		if _, _, err := parseHeaderFilters([]string{filter}); err == nil {
+			t.Errorf("parseHeaderFilters(%q) accepted an invalid filter", filter)
+		}
+	}
+}
+
+func TestHARCaptureTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 24 * time.Hour, false},
+		{"30m", 30 * time.Minute, false},
+		{"24h", 24 * time.Hour, false},
+		{"25h", 0, true},
+		{"-1m", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := harCaptureTTL(tt.value, 24*time.Hour)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("harCaptureTTL(%q) = %v, %v; want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHARCaptureResponse(t *testing.T) {
+	capture := database.HarCapture{UrlID: uuid.New(), RequestedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	if status := harCaptureResponse(capture).Status; status != HARCapturePending {
+		t.Errorf("status = %s, want pending", status)
+	}
+	capture.TaskID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	if response := harCaptureResponse(capture); response.Status != HARCaptureScraping || response.TaskID != capture.TaskID.UUID.String() {
+		t.Errorf("response = %+v, want scraping with the task", response)
+	}
+	capture.Har = pqtype.NullRawMessage{RawMessage: json.RawMessage(`{"log":{}}`), Valid: true}
+	capture.CapturedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if response := harCaptureResponse(capture); response.Status != HARCaptureCaptured || response.CapturedAt == "" {
+		t.Errorf("response = %+v, want captured", response)
+	}
+}
+
+func TestRenderSessionResponse(t *testing.T) {
+	row := database.GetLatestRenderSessionRow{
+		SnapshotID: uuid.New(),
+		StatusCode: 200,
+		Session:    json.RawMessage(`{"console":[{"level":"error","text":"price is undefined"}],"requests":[{"url":"https://shop.example/api/price","type":"xhr","body":"{\"price\":24.9}"},{"url":"https://shop.example/app.js","type":"script"}]}`),
+		CreatedAt:  time.Now(),
+	}
+	response, err := renderSessionResponse(uuid.New(), row, "")
+	if err != nil {
+		t.Fatalf("renderSessionResponse() error = %v", err)
+	}
+	if response.TaskID != "" || response.Matches != nil || len(response.Session.Requests) != 2 {
+		t.Errorf("response = %+v, want the session without a task or matches", response)
+	}
+
+	row.TaskID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	response, err = renderSessionResponse(uuid.New(), row, "price")
+	if err != nil {
+		t.Fatalf("renderSessionResponse() error = %v", err)
+	}
+	if response.TaskID != row.TaskID.UUID.String() || len(response.Matches) != 1 || response.Matches[0].Type != "xhr" {
+		t.Errorf("response = %+v, want the task and the price XHR", response)
+	}
+
+	row.Session = json.RawMessage(`[]`)
+	if _, err := renderSessionResponse(uuid.New(), row, ""); err == nil {
+		t.Error("renderSessionResponse() of a malformed session returned no error")
+	}
+}
@@ -0,0 +1,408 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Limits for the records returned by one page of a view
+const (
+	defaultViewDataLimit = 100
+	maxViewDataLimit     = 1000
+)
+
+// ViewHandler handles data view HTTP requests for the web scraping system.
+// A data view is a saved, named filter over parsed data (schema, URL set and
+// field predicates) that can be fetched by name instead of repeating long
+// query strings.
+type ViewHandler struct {
+	Logger  *logrus.Logger
+	DB      *database.Queries   // sqlc-generated database queries
+	Keyring *encryption.Keyring // Decrypts sensitive fields, nil without encryption keys
+}
+
+// NewViewHandler creates a new view handler with the provided logger, database queries
+// and encryption keyring. This function initializes the handler with necessary dependencies
+// for view management.
+func NewViewHandler(logger *logrus.Logger, db *database.Queries, keyring *encryption.Keyring) *ViewHandler {
+	return &ViewHandler{
+		Logger:  logger,
+		DB:      db,
+		Keyring: keyring,
+	}
+}
+
+// ListViews handles GET /api/v1/views
+//
+// Purpose: Lists all saved data views, ordered by name.
+//
+// Response: models.ListDataViewsResponse (200 OK) or error (500)
+//
+// Example Usage:
+//
+//	GET /api/v1/views
+func (h *ViewHandler) ListViews(w http.ResponseWriter, r *http.Request) {
+	stored, err := h.DB.ListDataViews(r.Context())
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list data views")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]models.DataViewResponse, 0, len(stored))
+	for _, view := range stored {
+		response, err := dataViewResponse(view)
+		if err != nil {
+			h.Logger.WithError(err).WithField("view", view.Name).Warn("Skipping data view with invalid predicates")
+			continue
+		}
+		views = append(views, response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ListDataViewsResponse{
+		Views: views,
+		Total: len(views),
+	})
+}
+
+// GetView handles GET /api/v1/views/{name}
+//
+// Purpose: Retrieves a single data view by name, including its filters.
+//
+// Path Parameters:
+//   - name: View name (required)
+//
+// Response: models.DataViewResponse (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/views/cheap-books
+func (h *ViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.loadView(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := dataViewResponse(view)
+	if err != nil {
+		h.Logger.WithError(err).WithField("view", view.Name).Error("Failed to decode data view predicates")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateView handles POST /api/v1/views
+//
+// Purpose: Saves a named filter over parsed data. Records match when they
+// have the view's schema, belong to one of its URLs and meet all of its
+// field predicates; an empty schema or URL set matches everything.
+// Predicate operators are eq, ne, gt, gte, lt, lte and exists.
+//
+// Request Body: models.CreateDataViewRequest
+// Response: models.DataViewResponse (201 Created) or error (400/409/500)
+//
+// Example Usage:
+//
+//	POST /api/v1/views
+//	{
+//	  "name": "cheap-books",
+//	  "schema": "product",
+//	  "predicates": [
+//	    {"field": "category", "op": "eq", "value": "books"},
+//	    {"field": "offer.price", "op": "lt", "value": 10}
+//	  ]
+//	}
+func (h *ViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateDataViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !templateNamePattern.MatchString(req.Name) {
+		http.Error(w, "View name must contain only lowercase letters, digits and hyphens", http.StatusBadRequest)
+		return
+	}
+	urlIDs, predicates, err := validateViewFilter(req.URLIDs, req.Predicates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.DB.GetDataView(r.Context(), req.Name); err == nil {
+		http.Error(w, "Data view already exists", http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		h.Logger.WithError(err).WithField("view", req.Name).Error("Failed to check data view")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.DB.CreateDataView(r.Context(), database.CreateDataViewParams{
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Schema:      req.Schema,
+		UrlIds:      urlIDs,
+		Predicates:  predicates,
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("view", req.Name).Error("Failed to save data view")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := dataViewResponse(created)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateView handles PUT /api/v1/views/{name}
+//
+// Purpose: Replaces the filters of a data view. Consumers fetching the view
+// see the new filters on their next call.
+//
+// Path Parameters:
+//   - name: View name (required)
+//
+// Request Body: models.UpdateDataViewRequest
+// Response: models.DataViewResponse (200 OK) or error (400/404/500)
+//
+// Example Usage:
+//
+//	PUT /api/v1/views/cheap-books
+//	{"schema": "product", "predicates": [{"field": "offer.price", "op": "lt", "value": 5}]}
+func (h *ViewHandler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var req models.UpdateDataViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.WithError(err).Error("Failed to decode request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	urlIDs, predicates, err := validateViewFilter(req.URLIDs, req.Predicates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.DB.UpdateDataView(r.Context(), database.UpdateDataViewParams{
+		Name:        name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Schema:      req.Schema,
+		UrlIds:      urlIDs,
+		Predicates:  predicates,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Data view not found", http.StatusNotFound)
+			return
+		}
+		h.Logger.WithError(err).WithField("view", name).Error("Failed to update data view")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, _ := dataViewResponse(updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteView handles DELETE /api/v1/views/{name}
+//
+// Purpose: Removes a data view.
+//
+// Path Parameters:
+//   - name: View name (required)
+//
+// Response: Success message (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	DELETE /api/v1/views/cheap-books
+func (h *ViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	deleted, err := h.DB.DeleteDataView(r.Context(), name)
+	if err != nil {
+		h.Logger.WithError(err).WithField("view", name).Error("Failed to delete data view")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Data view not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Data view deleted successfully"})
+}
+
+// GetViewData handles GET /api/v1/views/{name}/data
+//
+// Purpose: Returns the parsed records matching a data view. Each URL
+// contributes its latest parse, and only when that parse matches the view.
+//
+// Path Parameters:
+//   - name: View name (required)
+//
+// Query Parameters:
+//   - page: Page number (default: 1)
+//   - limit: Records per page, max 1000 (default: 100)
+//
+// Response: models.DataViewDataResponse (200 OK) or error (404/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/views/cheap-books/data?page=2
+func (h *ViewHandler) GetViewData(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.loadView(w, r)
+	if !ok {
+		return
+	}
+
+	var predicates []sharedmodels.FieldPredicate
+	if err := json.Unmarshal(view.Predicates, &predicates); err != nil {
+		h.Logger.WithError(err).WithField("view", view.Name).Error("Failed to decode data view predicates")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxViewDataLimit {
+		limit = defaultViewDataLimit
+	}
+
+	// One extra record tells whether later pages have records
+	rows, err := h.DB.ListParsedDataForView(r.Context(), database.ListParsedDataForViewParams{
+		Schema: view.Schema,
+		UrlIds: view.UrlIds,
+		Filter: sharedmodels.PredicateFilter(predicates),
+		Limit:  int32(limit + 1),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		h.Logger.WithError(err).WithField("view", view.Name).Error("Failed to list data view records")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.DataViewDataResponse{
+		View:    view.Name,
+		Data:    make([]models.DataRecord, 0, min(len(rows), limit)),
+		Page:    page,
+		Limit:   limit,
+		HasMore: len(rows) > limit,
+	}
+	for _, row := range rows[:min(len(rows), limit)] {
+		record, err := dataRecord(row, h.Keyring)
+		if err != nil {
+			h.Logger.WithError(err).WithField("record_id", row.ID).Error("Failed to decrypt data record")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response.Data = append(response.Data, record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// loadView loads the view named in the path, writing the error response if
+// it cannot
+func (h *ViewHandler) loadView(w http.ResponseWriter, r *http.Request) (database.DataView, bool) {
+	name := mux.Vars(r)["name"]
+
+	view, err := h.DB.GetDataView(r.Context(), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Data view not found", http.StatusNotFound)
+			return database.DataView{}, false
+		}
+		h.Logger.WithError(err).WithField("view", name).Error("Failed to get data view")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return database.DataView{}, false
+	}
+	return view, true
+}
+
+// validateViewFilter validates the URL set and predicates of a view and
+// returns them in their stored form
+func validateViewFilter(rawIDs []string, predicates []sharedmodels.FieldPredicate) ([]uuid.UUID, []byte, error) {
+	if len(rawIDs) > sharedmodels.MaxViewURLs {
+		return nil, nil, &models.ValidationError{Field: "url_ids", Message: "A view cannot have more than " + strconv.Itoa(sharedmodels.MaxViewURLs) + " URLs"}
+	}
+	urlIDs := make([]uuid.UUID, 0, len(rawIDs))
+	for _, raw := range rawIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, nil, &models.ValidationError{Field: "url_ids", Message: "Invalid URL ID: " + raw}
+		}
+		urlIDs = append(urlIDs, id)
+	}
+
+	if err := sharedmodels.ValidatePredicates(predicates); err != nil {
+		return nil, nil, &models.ValidationError{Field: "predicates", Message: err.Error()}
+	}
+	if predicates == nil {
+		predicates = []sharedmodels.FieldPredicate{}
+	}
+	predicatesJSON, err := json.Marshal(predicates)
+	if err != nil {
+		return nil, nil, &models.ValidationError{Field: "predicates", Message: err.Error()}
+	}
+	return urlIDs, predicatesJSON, nil
+}
+
+// dataViewResponse converts a database view row to its API representation
+func dataViewResponse(view database.DataView) (models.DataViewResponse, error) {
+	var predicates []sharedmodels.FieldPredicate
+	if err := json.Unmarshal(view.Predicates, &predicates); err != nil {
+		return models.DataViewResponse{}, err
+	}
+
+	urlIDs := make([]string, 0, len(view.UrlIds))
+	for _, id := range view.UrlIds {
+		urlIDs = append(urlIDs, id.String())
+	}
+
+	return models.DataViewResponse{
+		Name:        view.Name,
+		Description: view.Description.String,
+		Schema:      view.Schema,
+		URLIDs:      urlIDs,
+		Predicates:  predicates,
+		CreatedAt:   view.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   view.UpdatedAt.Format(time.RFC3339),
+	}, nil
+}
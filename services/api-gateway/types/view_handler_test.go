@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestValidateViewFilter(t *testing.T) {
+	urlIDs, predicates, err := validateViewFilter(
+		[]string{"123e4567-e89b-12d3-a456-426614174000"},
+		[]sharedmodels.FieldPredicate{{Field: "offer.price", Op: sharedmodels.PredicateLt, Value: 10.0}},
+	)
+	if err != nil {
+		t.Fatalf("validateViewFilter() error = %v", err)
+	}
+	if len(urlIDs) != 1 || urlIDs[0].String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("urlIDs = %v", urlIDs)
+	}
+	var stored []sharedmodels.FieldPredicate
+	if err := json.Unmarshal(predicates, &stored); err != nil || len(stored) != 1 || stored[0].Field != "offer.price" {
+		t.Errorf("predicates = %s, %v", predicates, err)
+	}
+
+	if _, predicates, _ := validateViewFilter(nil, nil); string(predicates) != "[]" {
+		t.Errorf("predicates without filters = %s, want []", predicates)
+	}
+
+	if _, _, err := validateViewFilter([]string{"not-a-uuid"}, nil); err == nil {
+		t.Error("validateViewFilter() accepted an invalid URL ID")
+	}
+	if _, _, err := validateViewFilter(nil, []sharedmodels.FieldPredicate{{Field: "price", Op: "between"}}); err == nil {
+		t.Error("validateViewFilter() accepted an unknown operator")
+	}
+}
+
+func TestCreateViewValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewViewHandler(logger, nil, nil)
+
+	for _, body := range []string{
+		`{"name": "Cheap Books"}`,
+		`{"name": "cheap-books", "predicates": [{"field": "price", "op": "lt", "value": [1]}]}`,
+		`{"name": "cheap-books", "url_ids": ["42"]}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.CreateView(rec, httptest.NewRequest(http.MethodPost, "/api/v1/views", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go_scraping_project/services/api-gateway/models"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/health"
+	"go_scraping_project/shared/worker"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Worker statuses of the fleet status endpoint
+const (
+	WorkerStatusAlive = "alive"
+	WorkerStatusStale = "stale"
+)
+
+// staleHeartbeats is the number of missed heartbeats after which an instance is stale
+const staleHeartbeats = 3
+
+// WorkerHandler handles fleet status HTTP requests for the web scraping system.
+// It lists the scraper and parser instances registered by their heartbeats,
+// so instances that died are visible.
+type WorkerHandler struct {
+	Logger *logrus.Logger
+	DB     *database.Queries // sqlc-generated database queries
+	Config *config.Watcher   // Effective configuration, for the heartbeat interval
+}
+
+// NewWorkerHandler creates a new worker handler with the provided logger, database queries and configuration.
+// This function initializes the handler with necessary dependencies.
+func NewWorkerHandler(logger *logrus.Logger, db *database.Queries, cfg *config.Watcher) *WorkerHandler {
+	return &WorkerHandler{
+		Logger: logger,
+		DB:     db,
+		Config: cfg,
+	}
+}
+
+// ListWorkers handles GET /api/v1/admin/workers
+//
+// Purpose: Lists the scraper and parser instances with their version,
+// uptime, current load, paused topics and last heartbeat. Instances record
+// a heartbeat every workers.heartbeat_interval and unregister on a clean
+// shutdown; an instance that missed three heartbeats is reported as stale.
+// Stale instances are removed after a day. Topics paused through
+// POST /api/v1/admin/consumers/pause show up in paused_topics once the
+// instances applied the command.
+//
+// Query Parameters:
+//   - kind: Only list scraper or parser instances
+//   - status: Only list alive or stale instances
+//   - region: Only list instances of a region
+//
+// Response: models.WorkersResponse (200 OK) or error (400/500)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/workers
+//	GET /api/v1/admin/workers?kind=scraper&status=stale
+//	GET /api/v1/admin/workers?region=eu-west
+func (h *WorkerHandler) ListWorkers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	kind := query.Get("kind")
+	if kind != "" && kind != worker.KindScraper && kind != worker.KindParser {
+		http.Error(w, "kind must be scraper or parser", http.StatusBadRequest)
+		return
+	}
+	status := query.Get("status")
+	if status != "" && status != WorkerStatusAlive && status != WorkerStatusStale {
+		http.Error(w, "status must be alive or stale", http.StatusBadRequest)
+		return
+	}
+
+	region := query.Get("region")
+
+	workers, err := h.DB.ListWorkers(r.Context())
+	if err != nil {
+		h.Logger.WithError(err).Error("Failed to list workers")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	interval := h.Config.Current().Workers.HeartbeatInterval
+	if interval <= 0 {
+		interval = worker.DefaultHeartbeatInterval
+	}
+	now := time.Now()
+	response := models.WorkersResponse{
+		HeartbeatInterval: interval.String(),
+		Workers:           []models.WorkerResponse{},
+	}
+	for _, row := range workers {
+		if kind != "" && row.Kind != kind || region != "" && row.Region != region {
+			continue
+		}
+		item := workerResponse(row, now, interval)
+		if status != "" && item.Status != status {
+			continue
+		}
+		if item.Status == WorkerStatusStale {
+			response.Stale++
+		}
+		response.Workers = append(response.Workers, item)
+	}
+	response.Total = len(response.Workers)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// WorkersHealthCheck checks the scraper and parser fleet by its heartbeats.
+// The fleet is unhealthy when none of the registered instances is alive and
+// degraded when some are stale or none are registered.
+func WorkersHealthCheck(db *database.Queries, cfg *config.Watcher) health.Check {
+	return func(ctx context.Context) error {
+		workers, err := db.ListWorkers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list workers: %w", err)
+		}
+		if len(workers) == 0 {
+			return health.Degraded("no scraper or parser instances registered")
+		}
+
+		interval := cfg.Current().Workers.HeartbeatInterval
+		if interval <= 0 {
+			interval = worker.DefaultHeartbeatInterval
+		}
+		now := time.Now()
+		var stale int
+		for _, row := range workers {
+			if workerResponse(row, now, interval).Status == WorkerStatusStale {
+				stale++
+			}
+		}
+		switch {
+		case stale == len(workers):
+			return fmt.Errorf("all %d instances are stale", stale)
+		case stale > 0:
+			return health.Degraded("%d of %d instances are stale", stale, len(workers))
+		}
+		return nil
+	}
+}
+
+// workerResponse converts a registered instance to the response format
+func workerResponse(row database.Worker, now time.Time, interval time.Duration) models.WorkerResponse {
+	status := WorkerStatusAlive
+	if now.Sub(row.LastHeartbeatAt) > staleHeartbeats*interval {
+		status = WorkerStatusStale
+	}
+
+	var load float64
+	if row.Capacity > 0 {
+		load = float64(row.ActiveTasks) / float64(row.Capacity)
+	}
+
+	return models.WorkerResponse{
+		ID:              row.ID,
+		Kind:            row.Kind,
+		Version:         row.Version,
+		Host:            row.Host,
+		Region:          row.Region,
+		Status:          status,
+		StartedAt:       row.StartedAt.Format(time.RFC3339),
+		UptimeSeconds:   int64(max(row.LastHeartbeatAt.Sub(row.StartedAt), 0) / time.Second),
+		LastHeartbeatAt: row.LastHeartbeatAt.Format(time.RFC3339),
+		ActiveTasks:     row.ActiveTasks,
+		Capacity:        row.Capacity,
+		QueuedTasks:     row.QueuedTasks,
+		Load:            load,
+		PausedTopics:    row.PausedTopics,
+	}
+}
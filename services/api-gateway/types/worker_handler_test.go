@@ -0,0 +1,42 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/database"
+)
+
+func TestWorkerResponse(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	row := database.Worker{
+		ID:              "scraper-a",
+		Kind:            "scraper",
+		Version:         "1.2.0",
+		StartedAt:       now.Add(-2 * time.Hour),
+		LastHeartbeatAt: now.Add(-20 * time.Second),
+		ActiveTasks:     3,
+		Capacity:        4,
+	}
+
+	got := workerResponse(row, now, 15*time.Second)
+	if got.Status != WorkerStatusAlive {
+		t.Errorf("Status = %s, want alive", got.Status)
+	}
+	if got.UptimeSeconds != 7180 {
+		t.Errorf("UptimeSeconds = %d, want 7180", got.UptimeSeconds)
+	}
+	if got.Load != 0.75 {
+		t.Errorf("Load = %v, want 0.75", got.Load)
+	}
+
+	row.LastHeartbeatAt = now.Add(-46 * time.Second)
+	row.Capacity = 0
+	got = workerResponse(row, now, 15*time.Second)
+	if got.Status != WorkerStatusStale {
+		t.Errorf("Status = %s, want stale after three missed heartbeats", got.Status)
+	}
+	if got.Load != 0 {
+		t.Errorf("Load = %v, want 0 without capacity", got.Load)
+	}
+}
@@ -0,0 +1,189 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/url-manager/services"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/control"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/testenv"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// startURLManager wires the URL Manager with setup over env and starts it,
+// returning a server of its admin API
+func startURLManager(t *testing.T, env *testenv.Env) *httptest.Server {
+	t.Helper()
+	c := env.Container(t, "url-manager")
+	handler, err := setup(c)
+	if err != nil {
+		t.Fatalf("setup() error = %v", err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// createURL stores a pending URL scraped every hour, next at nextScrape
+func createURL(t *testing.T, env *testenv.Env, nextScrape time.Time) database.Url {
+	t.Helper()
+	url, err := env.Queries().CreateURL(context.Background(), database.CreateURLParams{
+		Url:          fmt.Sprintf("https://example.com/e2e/%s", uuid.NewString()),
+		Frequency:    "1h",
+		Status:       services.URLStatusPending,
+		MaxRetries:   3,
+		Timeout:      30,
+		NextScrapeAt: sql.NullTime{Time: nextScrape, Valid: true},
+		Tags:         []string{"e2e"},
+	})
+	if err != nil {
+		t.Fatalf("CreateURL() error = %v", err)
+	}
+	return url
+}
+
+// trigger scrapes a URL now through the control API
+func trigger(t *testing.T, server *httptest.Server, urlID uuid.UUID) uuid.UUID {
+	t.Helper()
+	client := control.NewClient(config.ControlConfig{URLManagerURL: server.URL, Timeout: 10 * time.Second}, nil)
+	resp, err := client.TriggerScrape(context.Background(), urlID)
+	if err != nil {
+		t.Fatalf("TriggerScrape() error = %v", err)
+	}
+	return resp.TaskID
+}
+
+// publishResult sends a scrape result as scrapers do
+func publishResult(t *testing.T, env *testenv.Env, result sharedmodels.ScrapeResult) {
+	t.Helper()
+	data, _ := json.Marshal(result)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	env.Publish(t, env.Config.Kafka.Topics.ScrapingResults, result.TaskID.String(), sharedmodels.KafkaMessage{
+		ID:        uuid.NewString(),
+		Type:      sharedmodels.MessageTypeScrapeResult,
+		Data:      fields,
+		Timestamp: time.Now().UTC(),
+		Source:    "scraper",
+	})
+}
+
+// waitForTask waits until a task has the given status
+func waitForTask(t *testing.T, env *testenv.Env, taskID uuid.UUID, status string) database.ScrapingTask {
+	t.Helper()
+	var task database.ScrapingTask
+	testenv.Eventually(t, func() (err error) {
+		task, err = env.Queries().GetScrapingTask(context.Background(), taskID)
+		if err == nil && task.Status != status {
+			err = fmt.Errorf("task status is %s, want %s", task.Status, status)
+		}
+		return err
+	})
+	return task
+}
+
+func TestE2ESchedulerPublishesDueURLs(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Scheduler.CheckInterval = time.Second
+	startURLManager(t, env)
+
+	url := createURL(t, env, time.Now().UTC().Add(-time.Second))
+	msg := env.WaitForMessage(t, services.TopicScrapingTasks, func(msg kafka.Message) bool {
+		var task sharedmodels.ScrapingTaskMessage
+		return json.Unmarshal(msg.Value, &task) == nil && task.URLID == url.ID
+	})
+
+	var task sharedmodels.ScrapingTaskMessage
+	json.Unmarshal(msg.Value, &task)
+	if task.URL != url.Url || task.Attempt != 1 || string(msg.Key) != task.TaskID.String() {
+		t.Errorf("task = %+v with key %s, want attempt 1 of %s keyed by its ID", task, msg.Key, url.Url)
+	}
+	waitForTask(t, env, task.TaskID, services.TaskStatusPending)
+
+	// The next scrape moves a frequency ahead
+	testenv.Eventually(t, func() error {
+		stored, err := env.Queries().GetURLByID(context.Background(), url.ID)
+		if err == nil && !stored.NextScrapeAt.Time.After(time.Now().Add(50*time.Minute)) {
+			err = fmt.Errorf("next scrape at %s, want about an hour ahead", stored.NextScrapeAt.Time)
+		}
+		return err
+	})
+}
+
+func TestE2EScrapeResultsUpdateURLs(t *testing.T) {
+	env := testenv.New(t)
+	server := startURLManager(t, env)
+	url := createURL(t, env, time.Now().UTC().Add(time.Hour))
+
+	// A successful scrape completes its task
+	taskID := trigger(t, server, url.ID)
+	publishResult(t, env, sharedmodels.ScrapeResult{TaskID: taskID, URLID: url.ID, Attempt: 1, Success: true, StatusCode: 200, DurationMs: 120})
+	task := waitForTask(t, env, taskID, services.TaskStatusSuccess)
+	if !task.CompletedAt.Valid || task.StatusCode.Int32 != 200 || task.DurationMs.Int64 != 120 {
+		t.Errorf("task = %+v, want completed with status code 200 in 120ms", task)
+	}
+
+	// A retryable failure puts the URL in retry
+	taskID = trigger(t, server, url.ID)
+	publishResult(t, env, sharedmodels.ScrapeResult{TaskID: taskID, URLID: url.ID, Attempt: 1, StatusCode: 502, Error: "bad gateway"})
+	waitForTask(t, env, taskID, services.TaskStatusRetry)
+	stored, err := env.Queries().GetURLByID(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("GetURLByID() error = %v", err)
+	}
+	if stored.Status != services.URLStatusRetry || stored.RetryCount != 1 {
+		t.Errorf("URL status = %s with %d retries, want retry with 1", stored.Status, stored.RetryCount)
+	}
+}
+
+func TestE2EFailingResultsAreDeadLettered(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Kafka.RetryMaxAttempts = 2
+	server := startURLManager(t, env)
+
+	// A result of an unknown URL fails every attempt
+	unknown := sharedmodels.ScrapeResult{TaskID: uuid.New(), URLID: uuid.New(), Attempt: 1, Success: true}
+	publishResult(t, env, unknown)
+
+	// The consumer moves past it to the next result
+	url := createURL(t, env, time.Now().UTC().Add(time.Hour))
+	taskID := trigger(t, server, url.ID)
+	publishResult(t, env, sharedmodels.ScrapeResult{TaskID: taskID, URLID: url.ID, Attempt: 1, Success: true, StatusCode: 200})
+	waitForTask(t, env, taskID, services.TaskStatusSuccess)
+
+	// The failed result is stored once, with its retries, for the API
+	// Gateway to list and replay
+	deadLetters, err := env.Queries().ListDeadLetters(context.Background(), database.ListDeadLettersParams{
+		Topic: env.Config.Kafka.Topics.ScrapingResults,
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("ListDeadLetters() error = %v", err)
+	}
+	if len(deadLetters) != 1 {
+		t.Fatalf("%d dead letters, want 1", len(deadLetters))
+	}
+	deadLetter := deadLetters[0]
+	if deadLetter.Key != unknown.TaskID.String() || deadLetter.MessageType != sharedmodels.MessageTypeScrapeResult || deadLetter.RetryCount != 2 {
+		t.Errorf("dead letter = key %s, type %s, %d retries; want the unknown result after 2 retries", deadLetter.Key, deadLetter.MessageType, deadLetter.RetryCount)
+	}
+	var message sharedmodels.KafkaMessage
+	if err := json.Unmarshal(deadLetter.Value, &message); err != nil || message.Data["task_id"] != unknown.TaskID.String() {
+		t.Errorf("dead letter value = %s, want the original message", deadLetter.Value)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go_scraping_project/services/url-manager/services"
+	"go_scraping_project/shared/control"
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ControlHandler serves the control API the API Gateway calls, see package control
+type ControlHandler struct {
+	Logger    *logrus.Logger
+	Scheduler *services.URLSchedulerService
+}
+
+// NewControlHandler creates a new control handler with the provided logger and scheduler
+func NewControlHandler(logger *logrus.Logger, scheduler *services.URLSchedulerService) *ControlHandler {
+	return &ControlHandler{
+		Logger:    logger,
+		Scheduler: scheduler,
+	}
+}
+
+// TriggerScrape handles POST /api/v1/admin/urls/{id}/scrape
+//
+// Purpose: Publishes a scraping task for the URL immediately instead of
+// waiting for its next scheduled scrape. The schedule of the URL is kept.
+//
+// Response: control.TriggerResponse (202 Accepted), or error (400 for an
+// invalid ID, 404 when the URL does not exist, 429 with a Retry-After header
+// when a daily scrape budget covering the URL is used up, 503 during
+// maintenance mode)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/urls/123e4567-e89b-12d3-a456-426614174000/scrape
+func (h *ControlHandler) TriggerScrape(w http.ResponseWriter, r *http.Request, id string) {
+	urlID, err := uuid.Parse(id)
+	if err != nil {
+		http.Error(w, "Invalid URL ID", http.StatusBadRequest)
+		return
+	}
+
+	task, topic, err := h.Scheduler.TriggerURL(r.Context(), urlID)
+	if err != nil {
+		if message, ok := domain.Message(err); ok {
+			if retryAfter := domain.RetryAfter(err); retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			}
+			http.Error(w, message, domain.HTTPStatus(err))
+			return
+		}
+		h.Logger.WithError(err).WithField("url_id", urlID).Error("Failed to trigger scrape")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(control.TriggerResponse{
+		TaskID:    task.ID,
+		URLID:     urlID,
+		Topic:     topic,
+		Attempt:   task.Attempt,
+		CreatedAt: task.CreatedAt,
+	})
+}
+
+// serveURLs routes the /api/v1/admin/urls/{id}/... endpoints
+func (h *ControlHandler) serveURLs(w http.ResponseWriter, r *http.Request) {
+	id, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/urls/"), "/")
+	if !ok || action != "scrape" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		h.TriggerScrape(w, r, id)
+	default:
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DatabaseHandler serves the diagnostics and tuning of the database
+// connection pool
+type DatabaseHandler struct {
+	Logger *logrus.Logger
+	Pool   *database.Pool
+}
+
+// NewDatabaseHandler creates a new database handler with the provided logger and pool
+func NewDatabaseHandler(logger *logrus.Logger, pool *database.Pool) *DatabaseHandler {
+	return &DatabaseHandler{
+		Logger: logger,
+		Pool:   pool,
+	}
+}
+
+// GetPool handles GET /api/v1/admin/database/pool
+//
+// Purpose: Diagnoses connection pool exhaustion. Reports the pool limits,
+// the open, in-use and idle connections, and how many requests waited for a
+// connection and for how long since the URL Manager started. A growing
+// wait_count with every connection in use means queries hold connections
+// longer than the scheduler can wait.
+//
+// Response: database.PoolStats (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/database/pool
+func (h *DatabaseHandler) GetPool(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Pool.Stats())
+}
+
+// UpdatePool handles PUT /api/v1/admin/database/pool
+//
+// Purpose: Tunes the pool limits at runtime, e.g. to relieve an exhausted
+// pool without a restart. Limits missing from the body are kept. Tuned
+// limits last until the URL Manager restarts or database.max_open_conns or
+// database.max_idle_conns change in the configuration.
+//
+// Request Body: database.PoolLimits as JSON, all fields optional
+//
+// Response: database.PoolStats (200 OK) or error (400 for invalid limits)
+//
+// Example Usage:
+//
+//	PUT /api/v1/admin/database/pool
+//	{"max_open_conns": 50, "max_idle_conns": 10}
+func (h *DatabaseHandler) UpdatePool(w http.ResponseWriter, r *http.Request) {
+	var update database.PoolLimitsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limits := update.Apply(h.Pool.Limits())
+	if err := h.Pool.SetLimits(limits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.Logger.WithFields(logrus.Fields{
+		"max_open_conns":    limits.MaxOpenConns,
+		"max_idle_conns":    limits.MaxIdleConns,
+		"conn_max_lifetime": limits.ConnMaxLifetime,
+		"conn_max_idle":     limits.ConnMaxIdleTime,
+	}).Info("Database pool limits changed")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Pool.Stats())
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go_scraping_project/services/url-manager/services"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SchedulerHandler serves the admin endpoints of the URL scheduler
+type SchedulerHandler struct {
+	Logger          *logrus.Logger
+	Scheduler       *services.URLSchedulerService
+	ConsumerMetrics *kafka.HandlerMetrics // Scrape result handling, added to /metrics when set
+	Pool            *database.Pool        // Database connection pool, added to /metrics when set
+}
+
+// NewSchedulerHandler creates a new scheduler handler with the provided logger and scheduler
+func NewSchedulerHandler(logger *logrus.Logger, scheduler *services.URLSchedulerService) *SchedulerHandler {
+	return &SchedulerHandler{
+		Logger:    logger,
+		Scheduler: scheduler,
+	}
+}
+
+// GetScheduler handles GET /api/v1/admin/scheduler
+//
+// Purpose: Reports the effective scheduler settings (after defaults), i.e.
+// the check interval, batch size and lookup window, together with the
+// latest scheduling pass, to see what the scheduler is actually doing after
+// a configuration change.
+//
+// Response: services.SchedulerStatus (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/scheduler
+func (h *SchedulerHandler) GetScheduler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Scheduler.Status())
+}
+
+// GetSchedulerStatus handles GET /api/v1/admin/scheduler/status
+//
+// Purpose: Diagnoses "nothing is being scheduled" incidents. Reports the
+// latest scheduling pass (start time, duration, URLs considered, tasks
+// published, URLs deferred by budgets and URLs that failed) and the totals
+// since the URL Manager started. A missing or old last_run means the
+// scheduler is not ticking; a run that considers no URLs points at the
+// lookup window or the URLs' next scrape times.
+//
+// Response: services.SchedulerRunStatus (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/scheduler/status
+func (h *SchedulerHandler) GetSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Scheduler.RunStatus())
+}
+
+// GetMetrics handles GET /metrics
+//
+// Purpose: Exposes the scheduler totals as Prometheus counters, and the
+// start time and duration of the latest pass as gauges, in the Prometheus
+// text format. When set, the outcomes and durations of scrape result
+// handling follow (kafka_messages_handled_total and
+// kafka_message_handling_duration_seconds), and the database connection pool
+// statistics (db_pool_*).
+//
+// Response: Prometheus text exposition (200 OK)
+//
+// Example Usage:
+//
+//	GET /metrics
+func (h *SchedulerHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	status := h.Scheduler.RunStatus()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	counters := []struct {
+		name, help string
+		value      int64
+	}{
+		{"url_scheduler_runs_total", "Scheduling passes run.", status.Totals.Runs},
+		{"url_scheduler_failed_runs_total", "Scheduling passes that could not fetch the scheduled URLs.", status.Totals.FailedRuns},
+		{"url_scheduler_urls_considered_total", "Scheduled URLs fetched by scheduling passes.", status.Totals.URLsConsidered},
+		{"url_scheduler_tasks_published_total", "Scraping tasks published by scheduling passes.", status.Totals.TasksPublished},
+		{"url_scheduler_urls_deferred_total", "URLs deferred to the next day by a scrape budget.", status.Totals.URLsDeferred},
+		{"url_scheduler_errors_total", "URLs that failed to process.", status.Totals.Errors},
+	}
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.value)
+	}
+
+	if run := status.LastRun; run != nil {
+		fmt.Fprintf(w, "# HELP url_scheduler_last_run_timestamp_seconds Start time of the latest scheduling pass.\n# TYPE url_scheduler_last_run_timestamp_seconds gauge\nurl_scheduler_last_run_timestamp_seconds %d\n", run.StartedAt.Unix())
+		fmt.Fprintf(w, "# HELP url_scheduler_last_run_duration_seconds Duration of the latest scheduling pass.\n# TYPE url_scheduler_last_run_duration_seconds gauge\nurl_scheduler_last_run_duration_seconds %g\n", float64(run.DurationMS)/1000)
+	}
+
+	if h.ConsumerMetrics != nil {
+		h.ConsumerMetrics.WritePrometheus(w)
+	}
+	if h.Pool != nil {
+		h.Pool.WritePrometheus(w)
+	}
+}
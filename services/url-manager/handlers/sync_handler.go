@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go_scraping_project/services/url-manager/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SyncHandler serves the admin endpoints of configuration-as-code sync
+type SyncHandler struct {
+	Logger *logrus.Logger
+	Sync   *services.URLSyncService
+}
+
+// NewSyncHandler creates a new sync handler with the provided logger and sync service
+func NewSyncHandler(logger *logrus.Logger, sync *services.URLSyncService) *SyncHandler {
+	return &SyncHandler{
+		Logger: logger,
+		Sync:   sync,
+	}
+}
+
+// GetSyncStatus handles GET /api/v1/admin/sync
+//
+// Purpose: Reports the sync settings and the result of the latest
+// reconciliation, including the drift between the declared URLs and the
+// database: declared URLs that are missing or configured differently, and
+// managed URLs that are no longer declared.
+//
+// Response: services.SyncStatus (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/sync
+func (h *SyncHandler) GetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Sync.Status())
+}
+
+// TriggerSync handles POST /api/v1/admin/sync
+//
+// Purpose: Reconciles the database with the declared URLs immediately
+// instead of waiting for the next interval, e.g. right after a deploy.
+//
+// Response: services.SyncReport (200 OK), or error (409 when sync is
+// disabled, 422 with the report when the declaration is invalid or the
+// reconciliation fails)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/sync
+func (h *SyncHandler) TriggerSync(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Sync.Sync(r.Context())
+	if errors.Is(err, services.ErrSyncDisabled) {
+		http.Error(w, "Configuration sync is disabled", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		h.Logger.WithError(err).Error("Triggered URL sync failed")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// NewRouter creates the URL Manager's HTTP handler
+//
+// Routes Configured:
+//   - GET /health - Liveness check
+//   - GET /metrics - Scheduler, Kafka handler and database pool metrics for Prometheus
+//   - GET /api/v1/admin/sync - Sync status and drift report
+//   - POST /api/v1/admin/sync - Run a reconciliation now
+//   - GET /api/v1/admin/scheduler - Effective scheduler settings and latest pass
+//   - GET /api/v1/admin/scheduler/status - Latest pass and totals since startup
+//   - POST /api/v1/admin/urls/{id}/scrape - Scrape a URL now
+//   - GET /api/v1/admin/database/pool - Database connection pool statistics
+//   - PUT /api/v1/admin/database/pool - Tune the connection pool limits
+func NewRouter(syncHandler *SyncHandler, schedulerHandler *SchedulerHandler, controlHandler *ControlHandler, databaseHandler *DatabaseHandler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	})
+	mux.HandleFunc("/api/v1/admin/sync", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			syncHandler.GetSyncStatus(w, r)
+		case http.MethodPost:
+			syncHandler.TriggerSync(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/v1/admin/scheduler", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			schedulerHandler.GetScheduler(w, r)
+		default:
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/v1/admin/scheduler/status", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			schedulerHandler.GetSchedulerStatus(w, r)
+		default:
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			schedulerHandler.GetMetrics(w, r)
+		default:
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/v1/admin/urls/", controlHandler.serveURLs)
+	mux.HandleFunc("/api/v1/admin/database/pool", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			databaseHandler.GetPool(w, r)
+		case http.MethodPut:
+			databaseHandler.UpdatePool(w, r)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
@@ -58,7 +58,7 @@ func TestKafkaConnection(t *testing.T) {
 	logger.SetLevel(logrus.ErrorLevel) // Reduce noise during test
 
 	// Test Kafka producer connection
-	producer, err := kafka.NewProducer(kafkaBrokers, logger)
+	producer, err := kafka.NewProducer(config.KafkaConfig{Brokers: kafkaBrokers}, logger)
 	if err != nil {
 		t.Fatalf("Failed to create Kafka producer: %v", err)
 	}
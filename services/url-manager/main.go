@@ -3,127 +3,197 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
+	"net/http"
 
+	"go_scraping_project/services/url-manager/handlers"
 	"go_scraping_project/services/url-manager/repositories"
 	"go_scraping_project/services/url-manager/services"
+	"go_scraping_project/shared/bootstrap"
 	"go_scraping_project/shared/config"
-	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/events"
+	"go_scraping_project/shared/exports"
 	"go_scraping_project/shared/kafka"
-
-	"github.com/sirupsen/logrus"
+	sharedmodels "go_scraping_project/shared/models"
 )
 
-// getDatabaseURL returns the database connection URL, prioritizing environment variables over config
-func getDatabaseURL(loader *config.Loader) string {
-	// Check if DATABASE_URL is set in environment
-	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
-		return databaseURL
+// setup wires the URL scheduler to the database and Kafka.
+// The URL Manager serves only admin endpoints, see handlers.NewRouter.
+func setup(c *bootstrap.Container) (http.Handler, error) {
+	// Initialize sqlc-generated database queries
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
 	}
 
-	// Build from config if not in environment
-	dbHost := loader.GetString("database.host")
-	dbPort := loader.GetInt("database.port")
-	dbUser := loader.GetString("database.user")
-	dbPassword := loader.GetString("database.password")
-	dbName := loader.GetString("database.db_name")
-	dbSSLMode := loader.GetString("database.ssl_mode")
-
-	// Set defaults
-	if dbName == "" {
-		dbName = "scraping_db"
-	}
-	if dbSSLMode == "" {
-		dbSSLMode = "disable"
+	// Initialize Kafka producer using config
+	producer, err := c.KafkaProducer()
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
-}
-
-// getLogger initializes and configures a logger based on configuration
-func getLogger(loader *config.Loader) *logrus.Logger {
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Initialize repositories, bounding their queries by database.query_timeout
+	timeouts := c.QueryTimeouts()
+	urlRepo := repositories.NewURLRepository(queries, c.Logger(), timeouts)
+	taskRepo := repositories.NewTaskRepository(queries, c.Logger(), timeouts)
+	budgetRepo := repositories.NewBudgetRepository(queries, c.Logger(), timeouts)
+	workerRepo := repositories.NewWorkerRepository(queries, c.Logger(), timeouts)
 
-	// Set log level from config
-	logLevel := loader.GetString("logging.level")
-	if logLevel == "" {
-		logLevel = "info"
-	}
+	// Publish URL status changes and configuration sync changes to the URL events topic
+	urlEvents := events.NewURLEventPublisher(producer, c.Config().Kafka.Topics.URLEvents, c.ServiceName(), c.Logger())
+	urlRepo.OnStatusChange(urlEvents.StatusChanged)
 
-	level, err := logrus.ParseLevel(logLevel)
+	// Consume scrape results to record failures and schedule retries
+	consumer, err := c.KafkaConsumer(c.Config().Kafka.Topics.ScrapingResults)
 	if err != nil {
-		logger.WithError(err).Warn("Invalid log level, using info")
-		level = logrus.InfoLevel
+		return nil, err
 	}
-	logger.SetLevel(level)
-
-	return logger
-}
-
-func main() {
-	// Load configuration using shared config loader
-	loader := config.NewLoader()
-	if err := loader.LoadServiceConfig("url-manager"); err != nil {
-		logrus.WithError(err).Fatal("Failed to load configuration")
+	results := services.NewTaskResultService(urlRepo, taskRepo, c.Logger())
+	results.Configure(c.Config().Redirects)
+	results.SetCaptureHeaders(c.Config().Scraping.CaptureHeaders)
+	results.SetHARRetention(c.Config().Scraping.HAR.Retention)
+	c.OnConfigChange(func(cfg *config.Config) {
+		results.Configure(cfg.Redirects)
+		results.SetCaptureHeaders(cfg.Scraping.CaptureHeaders)
+		results.SetHARRetention(cfg.Scraping.HAR.Retention)
+	})
+	consumer.RegisterHandler(sharedmodels.MessageTypeScrapeResult, results.HandleMessage)
+
+	// Keep the results that exhaust their retries for the dead letter
+	// endpoints of the API Gateway
+	consumer.SetDeadLetterStore(repositories.NewDeadLetterRepository(queries, c.Logger(), timeouts))
+
+	// Observe the handlers: traces when enabled, metrics on /metrics, logs
+	// with correlation IDs and panic recovery
+	var tracer kafka.Tracer
+	if t := c.Tracer(); t != nil {
+		tracer = t
 	}
-
-	// Load environment variables
-	loader.LoadFromEnv()
-
-	// Initialize logger
-	logger := getLogger(loader)
-
-	// Get database URL and set environment variable
-	databaseURL := getDatabaseURL(loader)
-	os.Setenv("DATABASE_URL", databaseURL)
-
-	// Initialize database connection
-	db, err := database.Connect()
+	consumerMetrics := kafka.NewHandlerMetrics()
+	consumer.Use(kafka.Observability(c.Logger(), consumerMetrics, tracer)...)
+
+	// Initialize URL scheduler service; it is registered after its dependencies so it stops
+	// before the producer and database it depends on are closed
+	scheduler := services.NewURLSchedulerService(urlRepo, taskRepo, budgetRepo, workerRepo, producer, c.Logger())
+	scheduler.Configure(c.Config().Scheduler)
+	scheduler.SetFrequencyPolicy(c.Config().FrequencyPolicy)
+	scheduler.SetProjectShares(c.Config().Scraping.ProjectShares)
+	maintenance, err := c.Maintenance()
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to connect to database")
+		return nil, err
 	}
-	defer db.Close()
-
-	// Initialize sqlc-generated database queries
-	queries := database.New(db)
-
-	// Initialize Kafka producer using config
-	kafkaBrokers := loader.GetStringSlice("kafka.brokers")
-	if len(kafkaBrokers) == 0 {
-		kafkaBrokers = []string{"localhost:9092"} // fallback
+	scheduler.SetMaintenance(maintenance)
+	c.OnConfigChange(func(cfg *config.Config) {
+		scheduler.Configure(cfg.Scheduler)
+		scheduler.SetFrequencyPolicy(cfg.FrequencyPolicy)
+		scheduler.SetProjectShares(cfg.Scraping.ProjectShares)
+	})
+	c.Append(bootstrap.Hook{
+		Name:    "url-scheduler",
+		OnStart: scheduler.Start,
+		OnStop:  func(context.Context) error { return scheduler.Stop() },
+	})
+
+	// Initialize the watchdog that degrades stalled and failing URLs
+	notifier, err := c.Notifier()
+	if err != nil {
+		return nil, err
+	}
+	watchdog := services.NewURLWatchdogService(urlRepo, notifier, c.Logger())
+	watchdog.Configure(c.Config().Watchdog)
+	c.OnConfigChange(func(cfg *config.Config) {
+		watchdog.Configure(cfg.Watchdog)
+	})
+	c.Append(bootstrap.Hook{
+		Name:    "url-watchdog",
+		OnStart: watchdog.Start,
+		OnStop:  func(context.Context) error { return watchdog.Stop() },
+	})
+
+	// Run the data purge jobs requested through the API
+	purges := services.NewDataPurgeService(repositories.NewPurgeRepository(queries, c.Logger(), timeouts), c.Logger())
+	c.Append(bootstrap.Hook{
+		Name:    "data-purge",
+		OnStart: purges.Start,
+		OnStop:  func(context.Context) error { return purges.Stop() },
+	})
+
+	// Write the data exports requested through the API and scheduled in
+	// exports.schedules
+	if c.Config().Exports.Enabled() {
+		keyring, err := c.Keyring()
+		if err != nil {
+			return nil, err
+		}
+		store, err := exports.NewStore(c.Config().Exports)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create export store: %w", err)
+		}
+		dataExports := services.NewDataExportService(repositories.NewExportRepository(queries, c.Logger(), timeouts), store, keyring, c.Config().Exports, c.Logger())
+		c.Append(bootstrap.Hook{
+			Name:    "data-export",
+			OnStart: dataExports.Start,
+			OnStop:  func(context.Context) error { return dataExports.Stop() },
+		})
 	}
 
-	producer, err := kafka.NewProducer(kafkaBrokers, logger)
+	// Initialize the evaluator of the system alert rules
+	kafkaOpts, err := c.KafkaOptions()
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to create Kafka producer")
+		return nil, err
 	}
-	defer producer.Close()
-
-	// Initialize URL repository
-	urlRepo := repositories.NewURLRepository(queries, logger)
-
-	// Initialize URL scheduler service
-	scheduler := services.NewURLSchedulerService(urlRepo, producer, logger)
-
-	// Start scheduler
-	logger.Info("Starting URL scheduler service")
-	if err := scheduler.Start(context.Background()); err != nil {
-		logger.WithError(err).Fatal("Failed to start scheduler")
+	offsets, err := kafka.NewOffsets(c.Config().Kafka.Brokers, kafkaOpts...)
+	if err != nil {
+		return nil, err
 	}
+	alertRepo := repositories.NewAlertRepository(queries, c.Logger(), timeouts)
+	alerts := services.NewAlertEvaluatorService(alertRepo, offsets, notifier, c.Config().Kafka, c.Logger())
+	if err := alerts.Configure(c.Config().Alerts); err != nil {
+		return nil, err
+	}
+	c.OnConfigChange(func(cfg *config.Config) {
+		if err := alerts.Configure(cfg.Alerts); err != nil {
+			c.Logger().WithError(err).Error("Invalid alert rules, keeping the previous rules")
+		}
+	})
+	c.Append(bootstrap.Hook{
+		Name:    "alert-evaluator",
+		OnStart: alerts.Start,
+		OnStop:  func(context.Context) error { return alerts.Stop() },
+	})
+
+	// Initialize configuration-as-code sync; it is a no-op until sync.enabled is set
+	urlSync := services.NewURLSyncService(urlRepo, urlEvents, c.Logger())
+	urlSync.Configure(c.Config().Sync)
+	urlSync.SetFrequencyPolicy(c.Config().FrequencyPolicy)
+	c.OnConfigChange(func(cfg *config.Config) {
+		urlSync.Configure(cfg.Sync)
+		urlSync.SetFrequencyPolicy(cfg.FrequencyPolicy)
+	})
+	c.Append(bootstrap.Hook{
+		Name:    "url-sync",
+		OnStart: urlSync.Start,
+		OnStop:  func(context.Context) error { return urlSync.Stop() },
+	})
+
+	pool, err := c.DBPool()
+	if err != nil {
+		return nil, err
+	}
+	schedulerHandler := handlers.NewSchedulerHandler(c.Logger(), scheduler)
+	schedulerHandler.ConsumerMetrics = consumerMetrics
+	schedulerHandler.Pool = pool
+	return handlers.NewRouter(
+		handlers.NewSyncHandler(c.Logger(), urlSync),
+		schedulerHandler,
+		handlers.NewControlHandler(c.Logger(), scheduler),
+		handlers.NewDatabaseHandler(c.Logger(), pool),
+	), nil
+}
 
-	// Wait for interrupt signal to gracefully shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("Shutting down URL Manager...")
-
-	// Stop scheduler
-	scheduler.Stop()
-
-	logger.Info("URL Manager exited")
+func main() {
+	bootstrap.Exit(bootstrap.Service{
+		Name:         "url-manager",
+		Setup:        setup,
+		Dependencies: []string{bootstrap.DependencyDatabase, bootstrap.DependencyKafka},
+	})
 }
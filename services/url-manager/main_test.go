@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"strings"
 	"testing"
 
 	"go_scraping_project/shared/config"
@@ -44,26 +44,16 @@ func TestDatabaseURLGeneration(t *testing.T) {
 		t.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Test database URL generation
-	dbHost := loader.GetString("database.host")
-	dbPort := loader.GetInt("database.port")
-	dbUser := loader.GetString("database.user")
-	dbPassword := loader.GetString("database.password")
-	dbName := loader.GetString("database.db_name")
-	dbSSLMode := loader.GetString("database.ssl_mode")
-
-	if dbName == "" {
-		dbName = "scraping_db"
-	}
-	if dbSSLMode == "" {
-		dbSSLMode = "disable"
+	cfg, err := loader.Config()
+	if err != nil {
+		t.Fatalf("Failed to decode configuration: %v", err)
 	}
 
-	databaseURL := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
-
-	if databaseURL == "" {
-		t.Fatal("Failed to generate database URL")
+	// Test database URL generation
+	t.Setenv("DATABASE_URL", "")
+	databaseURL := cfg.Database.URL()
+	if !strings.Contains(databaseURL, "/scraping_db?") {
+		t.Fatalf("Database URL %q does not use the configured database name", databaseURL)
 	}
 
 	t.Logf("Generated database URL: %s", databaseURL)
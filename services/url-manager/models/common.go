@@ -1,11 +1,12 @@
 package models
 
 import (
-	"fmt"
 	"time"
+
+	"go_scraping_project/shared/utils"
 )
 
-// Frequency represents a scraping frequency
+// Frequency represents a scraping frequency; the constants are common values
 type Frequency string
 
 const (
@@ -21,41 +22,16 @@ const (
 	Frequency1Week     Frequency = "1w"
 )
 
-// ParseFrequency parses a frequency string into a time.Duration
+// ParseFrequency parses a frequency string into a time.Duration.
+// Any whole number of s, m, h, d or w is accepted, not just the common
+// frequencies above (see utils.ParseFrequency).
 func ParseFrequency(frequency string) (time.Duration, error) {
-	switch Frequency(frequency) {
-	case Frequency30Seconds:
-		return 30 * time.Second, nil
-	case Frequency1Minute:
-		return 1 * time.Minute, nil
-	case Frequency5Minutes:
-		return 5 * time.Minute, nil
-	case Frequency15Minutes:
-		return 15 * time.Minute, nil
-	case Frequency30Minutes:
-		return 30 * time.Minute, nil
-	case Frequency1Hour:
-		return 1 * time.Hour, nil
-	case Frequency6Hours:
-		return 6 * time.Hour, nil
-	case Frequency12Hours:
-		return 12 * time.Hour, nil
-	case Frequency1Day:
-		return 24 * time.Hour, nil
-	case Frequency1Week:
-		return 7 * 24 * time.Hour, nil
-	default:
-		return 0, fmt.Errorf("unsupported frequency: %s", frequency)
-	}
+	return utils.ParseFrequency(frequency)
 }
 
 // CalculateNextScrapeTime calculates the next scrape time based on frequency
 func CalculateNextScrapeTime(frequency string, from time.Time) (time.Time, error) {
-	duration, err := ParseFrequency(frequency)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return from.Add(duration), nil
+	return utils.CalculateNextScrapeTime(frequency, from)
 }
 
 // IsValidFrequency checks if a frequency string is valid
@@ -0,0 +1,48 @@
+package models
+
+import (
+	sharedmodels "go_scraping_project/shared/models"
+)
+
+// SyncDocument is a declarative description of the URLs to scrape, read by
+// configuration-as-code sync. URLs are listed per project, or at the top
+// level for URLs that belong to no project.
+//
+// Example:
+//
+//	projects:
+//	  - name: news
+//	    urls:
+//	      - url: https://example.com/news
+//	        frequency: 1h
+//	        tags: [news]
+//	urls:
+//	  - url: https://example.org/status
+//	    frequency: 5m
+type SyncDocument struct {
+	Projects []ProjectSpec `json:"projects,omitempty"`
+	URLs     []URLSpec     `json:"urls,omitempty"`
+}
+
+// ProjectSpec groups the URLs of a project
+type ProjectSpec struct {
+	Name string    `json:"name"`
+	URLs []URLSpec `json:"urls"`
+}
+
+// URLSpec is the declared configuration of a URL. Its fields match the
+// API's URL creation request; omitted numeric fields use the same defaults.
+type URLSpec struct {
+	URL           string                      `json:"url"`
+	Frequency     string                      `json:"frequency"`
+	UserAgent     string                      `json:"user_agent,omitempty"`
+	Timeout       int                         `json:"timeout,omitempty"`
+	RateLimit     int                         `json:"rate_limit,omitempty"`
+	MaxRetries    int                         `json:"max_retries,omitempty"`
+	ParserConfig  *sharedmodels.ParserConfig  `json:"parser_config,omitempty"`
+	RetryPolicy   *sharedmodels.RetryPolicy   `json:"retry_policy,omitempty"`
+	Tags          []string                    `json:"tags,omitempty"`
+	Assertions    *sharedmodels.Assertions    `json:"assertions,omitempty"`
+	Region        string                      `json:"region,omitempty"`
+	ArchivePolicy *sharedmodels.ArchivePolicy `json:"archive_policy,omitempty"`
+}
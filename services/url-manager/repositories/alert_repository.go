@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+)
+
+// AlertRepository defines the interface for system alert data operations
+type AlertRepository interface {
+	// CountTaskOutcomes counts the scraping tasks completed since a time and how many of them succeeded
+	CountTaskOutcomes(ctx context.Context, since time.Time) (total, succeeded int64, err error)
+
+	// CountDeadLetters counts the stored dead letters, of a source topic if one is given
+	CountDeadLetters(ctx context.Context, topic string) (int64, error)
+
+	// GetLastCompletedAt returns when the last scraping task completed, or false if none did
+	GetLastCompletedAt(ctx context.Context) (time.Time, bool, error)
+
+	// RecordAlertEvent records that an alert rule started firing or resolved
+	RecordAlertEvent(ctx context.Context, arg database.CreateAlertEventParams) error
+}
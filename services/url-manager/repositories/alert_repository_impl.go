@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AlertRepositoryImpl implements the AlertRepository interface using sqlc-generated queries
+type AlertRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewAlertRepository creates a new alert repository instance whose
+// queries are bounded by timeouts
+func NewAlertRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) AlertRepository {
+	return &AlertRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// CountTaskOutcomes counts the scraping tasks completed since a time and how many of them succeeded
+func (r *AlertRepositoryImpl) CountTaskOutcomes(ctx context.Context, since time.Time) (int64, int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountScrapingTaskOutcomes")
+	defer cancel()
+
+	row, err := r.db.CountScrapingTaskOutcomes(ctx, sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count scraping task outcomes")
+		return 0, 0, err
+	}
+	return row.Total, row.Succeeded, nil
+}
+
+// CountDeadLetters counts the stored dead letters, of a source topic if one is given
+func (r *AlertRepositoryImpl) CountDeadLetters(ctx context.Context, topic string) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountDeadLetters")
+	defer cancel()
+
+	count, err := r.db.CountDeadLetters(ctx, database.CountDeadLettersParams{Topic: topic})
+	if err != nil {
+		r.logger.WithError(err).WithField("topic", topic).Error("Failed to count dead letters")
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetLastCompletedAt returns when the last scraping task completed, or false if none did
+func (r *AlertRepositoryImpl) GetLastCompletedAt(ctx context.Context) (time.Time, bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetLastScrapingTaskCompletedAt")
+	defer cancel()
+
+	completedAt, err := r.db.GetLastScrapingTaskCompletedAt(ctx)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get last scraping task completion")
+		return time.Time{}, false, err
+	}
+	return completedAt.Time, completedAt.Valid, nil
+}
+
+// RecordAlertEvent records that an alert rule started firing or resolved
+func (r *AlertRepositoryImpl) RecordAlertEvent(ctx context.Context, arg database.CreateAlertEventParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateAlertEvent")
+	defer cancel()
+
+	if _, err := r.db.CreateAlertEvent(ctx, arg); err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"rule":  arg.Rule,
+			"state": arg.State,
+		}).Error("Failed to record alert event")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// BudgetRepository defines the interface for daily scrape budget data operations
+type BudgetRepository interface {
+	// CountDomainScrapes counts the scrapes published since a time for URLs on a domain or its subdomains
+	CountDomainScrapes(ctx context.Context, domain string, since time.Time) (int64, error)
+
+	// CountProjectScrapes counts the scrapes published since a time for URLs of a project
+	CountProjectScrapes(ctx context.Context, project string, since time.Time) (int64, error)
+
+	// RecordBudgetExhausted records a scrape deferred because a budget was used up on a day.
+	// It reports whether this was the budget's first deferral that day.
+	RecordBudgetExhausted(ctx context.Context, scope, name string, day time.Time, dailyLimit int) (bool, error)
+}
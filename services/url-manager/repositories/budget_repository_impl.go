@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BudgetRepositoryImpl implements the BudgetRepository interface using sqlc-generated queries
+type BudgetRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewBudgetRepository creates a new scrape budget repository instance whose
+// queries are bounded by timeouts
+func NewBudgetRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) BudgetRepository {
+	return &BudgetRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// CountDomainScrapes counts the scrapes published since a time for URLs on a domain or its subdomains
+func (r *BudgetRepositoryImpl) CountDomainScrapes(ctx context.Context, domain string, since time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountScrapingTasksForDomain")
+	defer cancel()
+
+	count, err := r.db.CountScrapingTasksForDomain(ctx, database.CountScrapingTasksForDomainParams{
+		Since:  since,
+		Domain: domain,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("domain", domain).Error("Failed to count scrapes for domain")
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountProjectScrapes counts the scrapes published since a time for URLs of a project
+func (r *BudgetRepositoryImpl) CountProjectScrapes(ctx context.Context, project string, since time.Time) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountScrapingTasksForProject")
+	defer cancel()
+
+	count, err := r.db.CountScrapingTasksForProject(ctx, database.CountScrapingTasksForProjectParams{
+		Since:   since,
+		Project: project,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("project", project).Error("Failed to count scrapes for project")
+		return 0, err
+	}
+	return count, nil
+}
+
+// RecordBudgetExhausted records a scrape deferred because a budget was used up on a day.
+// It reports whether this was the budget's first deferral that day.
+func (r *BudgetRepositoryImpl) RecordBudgetExhausted(ctx context.Context, scope, name string, day time.Time, dailyLimit int) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "RecordScrapeBudgetExhausted")
+	defer cancel()
+
+	first, err := r.db.RecordScrapeBudgetExhausted(ctx, database.RecordScrapeBudgetExhaustedParams{
+		Scope:      scope,
+		Name:       name,
+		Day:        day,
+		DailyLimit: int32(dailyLimit),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"scope": scope,
+			"name":  name,
+		}).Error("Failed to record exhausted scrape budget")
+		return false, err
+	}
+	return first, nil
+}
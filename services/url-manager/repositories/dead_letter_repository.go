@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"go_scraping_project/shared/kafka"
+)
+
+// DeadLetterRepository defines the interface for the dead letter store of
+// the URL Manager's Kafka consumer, see kafka.Consumer.SetDeadLetterStore
+type DeadLetterRepository interface {
+	// SaveDeadLetter stores a message that exhausted its retries, once per
+	// topic, partition and offset
+	SaveDeadLetter(ctx context.Context, d kafka.DeadLetter) error
+}
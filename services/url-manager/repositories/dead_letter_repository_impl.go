@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeadLetterRepositoryImpl implements the DeadLetterRepository interface using sqlc-generated queries
+type DeadLetterRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewDeadLetterRepository creates a new dead letter repository instance
+// whose queries are bounded by timeouts
+func NewDeadLetterRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) DeadLetterRepository {
+	return &DeadLetterRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// SaveDeadLetter stores a message that exhausted its retries. A message
+// already stored for its topic, partition and offset is left as is.
+func (r *DeadLetterRepositoryImpl) SaveDeadLetter(ctx context.Context, d kafka.DeadLetter) error {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateDeadLetter")
+	defer cancel()
+
+	_, err := r.db.CreateDeadLetter(ctx, database.CreateDeadLetterParams{
+		Topic:          d.Topic,
+		KafkaPartition: int32(d.Partition),
+		KafkaOffset:    d.Offset,
+		Key:            d.Key,
+		Value:          d.Value,
+		MessageType:    d.MessageType,
+		Error:          d.Error,
+		RetryCount:     int32(d.RetryCount),
+		CreatedAt:      d.CreatedAt,
+		FailedAt:       d.FailedAt,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"topic":     d.Topic,
+			"partition": d.Partition,
+			"offset":    d.Offset,
+		}).Error("Failed to save dead letter")
+		return err
+	}
+	return nil
+}
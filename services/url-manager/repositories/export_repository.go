@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+)
+
+// ExportRepository defines the interface for data export job operations
+type ExportRepository interface {
+	// ScheduleJob creates the next job of an export schedule unless one was created
+	// within the schedule's interval before now, and reports whether it created one
+	ScheduleJob(ctx context.Context, schedule config.ExportScheduleConfig, now time.Time) (bool, error)
+
+	// ClaimJob marks the oldest pending export job, or a running job not updated since
+	// staleBefore, as running and returns it, or nil if there is none
+	ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataExportJob, error)
+
+	// ListRecords lists up to batchSize parsed records of a job, oldest first, after the
+	// record created at afterCreatedAt with ID afterID; a zero afterCreatedAt starts from the first
+	ListRecords(ctx context.Context, job *database.DataExportJob, afterCreatedAt time.Time, afterID uuid.UUID, batchSize int) ([]database.ListParsedDataForExportRow, error)
+
+	// RecordProgress records the records written so far to a job's file
+	RecordProgress(ctx context.Context, id uuid.UUID, records int64) error
+
+	// FinishJob records a job as completed with its file, or failed with an error message
+	FinishJob(ctx context.Context, arg database.FinishDataExportJobParams) error
+
+	// ListExpiredJobs lists up to limit completed jobs whose files expired before now
+	ListExpiredJobs(ctx context.Context, now time.Time, limit int) ([]database.DataExportJob, error)
+
+	// ExpireJob marks a job whose file was deleted as expired
+	ExpireJob(ctx context.Context, id uuid.UUID) error
+}
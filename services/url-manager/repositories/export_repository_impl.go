@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ExportRepositoryImpl implements the ExportRepository interface using sqlc-generated queries
+type ExportRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewExportRepository creates a new data export repository instance whose
+// queries are bounded by timeouts
+func NewExportRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) ExportRepository {
+	return &ExportRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// ScheduleJob creates the next job of an export schedule unless one was created
+// within the schedule's interval before now, and reports whether it created one
+func (r *ExportRepositoryImpl) ScheduleJob(ctx context.Context, schedule config.ExportScheduleConfig, now time.Time) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ScheduleDataExportJob")
+	defer cancel()
+
+	created, err := r.db.ScheduleDataExportJob(ctx, database.ScheduleDataExportJobParams{
+		Project:  schedule.Project,
+		Schema:   schedule.Schema,
+		Format:   schedule.Format,
+		Schedule: schedule.Name,
+		DueAfter: now.Add(-schedule.Every),
+		Now:      now,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("schedule", schedule.Name).Error("Failed to schedule data export job")
+		return false, err
+	}
+	return created > 0, nil
+}
+
+// ClaimJob marks the oldest pending export job, or a running job not updated since
+// staleBefore, as running and returns it, or nil if there is none
+func (r *ExportRepositoryImpl) ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataExportJob, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ClaimDataExportJob")
+	defer cancel()
+
+	job, err := r.db.ClaimDataExportJob(ctx, staleBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to claim data export job")
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListRecords lists up to batchSize parsed records of a job, oldest first, after the
+// record created at afterCreatedAt with ID afterID; a zero afterCreatedAt starts from the first
+func (r *ExportRepositoryImpl) ListRecords(ctx context.Context, job *database.DataExportJob, afterCreatedAt time.Time, afterID uuid.UUID, batchSize int) ([]database.ListParsedDataForExportRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListParsedDataForExport")
+	defer cancel()
+
+	rows, err := r.db.ListParsedDataForExport(ctx, database.ListParsedDataForExportParams{
+		Project:        job.Project,
+		Schema:         job.Schema,
+		FromTime:       job.FromTime,
+		ToTime:         job.ToTime,
+		AfterCreatedAt: sql.NullTime{Time: afterCreatedAt, Valid: !afterCreatedAt.IsZero()},
+		AfterID:        afterID,
+		BatchSize:      int32(batchSize),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to list parsed data for export")
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RecordProgress records the records written so far to a job's file
+func (r *ExportRepositoryImpl) RecordProgress(ctx context.Context, id uuid.UUID, records int64) error {
+	ctx, cancel := r.timeouts.Context(ctx, "RecordDataExportProgress")
+	defer cancel()
+
+	if err := r.db.RecordDataExportProgress(ctx, database.RecordDataExportProgressParams{ID: id, RecordCount: records}); err != nil {
+		r.logger.WithError(err).WithField("job_id", id).Error("Failed to record data export progress")
+		return err
+	}
+	return nil
+}
+
+// FinishJob records a job as completed with its file, or failed with an error message
+func (r *ExportRepositoryImpl) FinishJob(ctx context.Context, arg database.FinishDataExportJobParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "FinishDataExportJob")
+	defer cancel()
+
+	if err := r.db.FinishDataExportJob(ctx, arg); err != nil {
+		r.logger.WithError(err).WithField("job_id", arg.ID).Error("Failed to finish data export job")
+		return err
+	}
+	return nil
+}
+
+// ListExpiredJobs lists up to limit completed jobs whose files expired before now
+func (r *ExportRepositoryImpl) ListExpiredJobs(ctx context.Context, now time.Time, limit int) ([]database.DataExportJob, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListExpiredDataExportJobs")
+	defer cancel()
+
+	jobs, err := r.db.ListExpiredDataExportJobs(ctx, database.ListExpiredDataExportJobsParams{Now: now, MaxResults: int32(limit)})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list expired data export jobs")
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ExpireJob marks a job whose file was deleted as expired
+func (r *ExportRepositoryImpl) ExpireJob(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "ExpireDataExportJob")
+	defer cancel()
+
+	if err := r.db.ExpireDataExportJob(ctx, id); err != nil {
+		r.logger.WithError(err).WithField("job_id", id).Error("Failed to expire data export job")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+)
+
+// PurgeRepository defines the interface for data purge job operations
+type PurgeRepository interface {
+	// ClaimJob marks the oldest pending purge job, or a running job not updated since
+	// staleBefore, as running and returns it, or nil if there is none
+	ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataPurgeJob, error)
+
+	// PurgeRawHTML deletes up to batchSize raw HTML snapshots matching a job's filter
+	PurgeRawHTML(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error)
+
+	// PurgeHARCaptures deletes the HAR captures matching a job's filter
+	PurgeHARCaptures(ctx context.Context, job *database.DataPurgeJob) (int64, error)
+
+	// PurgeParsedData deletes up to batchSize parsed records matching a job's filter
+	PurgeParsedData(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error)
+
+	// RecordProgress adds the rows deleted by a batch to a job's counters
+	RecordProgress(ctx context.Context, arg database.RecordDataPurgeProgressParams) error
+
+	// FinishJob records a job as completed or failed with an error message
+	FinishJob(ctx context.Context, id uuid.UUID, status, message string) error
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// PurgeRepositoryImpl implements the PurgeRepository interface using sqlc-generated queries
+type PurgeRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewPurgeRepository creates a new data purge repository instance whose
+// queries are bounded by timeouts
+func NewPurgeRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) PurgeRepository {
+	return &PurgeRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// ClaimJob marks the oldest pending purge job, or a running job not updated since
+// staleBefore, as running and returns it, or nil if there is none
+func (r *PurgeRepositoryImpl) ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataPurgeJob, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ClaimDataPurgeJob")
+	defer cancel()
+
+	job, err := r.db.ClaimDataPurgeJob(ctx, staleBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to claim data purge job")
+		return nil, err
+	}
+	return &job, nil
+}
+
+// PurgeRawHTML deletes up to batchSize raw HTML snapshots matching a job's filter
+func (r *PurgeRepositoryImpl) PurgeRawHTML(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "PurgeRawHTMLSnapshots")
+	defer cancel()
+
+	deleted, err := r.db.PurgeRawHTMLSnapshots(ctx, database.PurgeRawHTMLSnapshotsParams{
+		UrlID:     job.UrlID,
+		Schema:    job.Schema,
+		FromTime:  job.FromTime,
+		ToTime:    job.ToTime,
+		BatchSize: int32(batchSize),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to purge raw HTML snapshots")
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// PurgeHARCaptures deletes the HAR captures matching a job's filter
+func (r *PurgeRepositoryImpl) PurgeHARCaptures(ctx context.Context, job *database.DataPurgeJob) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "PurgeHARCaptures")
+	defer cancel()
+
+	deleted, err := r.db.PurgeHARCaptures(ctx, database.PurgeHARCapturesParams{
+		UrlID:    job.UrlID,
+		Schema:   job.Schema,
+		FromTime: job.FromTime,
+		ToTime:   job.ToTime,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to purge HAR captures")
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// PurgeParsedData deletes up to batchSize parsed records matching a job's filter
+func (r *PurgeRepositoryImpl) PurgeParsedData(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "PurgeParsedData")
+	defer cancel()
+
+	deleted, err := r.db.PurgeParsedData(ctx, database.PurgeParsedDataParams{
+		UrlID:     job.UrlID,
+		Schema:    job.Schema,
+		FromTime:  job.FromTime,
+		ToTime:    job.ToTime,
+		BatchSize: int32(batchSize),
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to purge parsed data")
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// RecordProgress adds the rows deleted by a batch to a job's counters
+func (r *PurgeRepositoryImpl) RecordProgress(ctx context.Context, arg database.RecordDataPurgeProgressParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "RecordDataPurgeProgress")
+	defer cancel()
+
+	if err := r.db.RecordDataPurgeProgress(ctx, arg); err != nil {
+		r.logger.WithError(err).WithField("job_id", arg.ID).Error("Failed to record data purge progress")
+		return err
+	}
+	return nil
+}
+
+// FinishJob records a job as completed or failed with an error message
+func (r *PurgeRepositoryImpl) FinishJob(ctx context.Context, id uuid.UUID, status, message string) error {
+	ctx, cancel := r.timeouts.Context(ctx, "FinishDataPurgeJob")
+	defer cancel()
+
+	err := r.db.FinishDataPurgeJob(ctx, database.FinishDataPurgeJobParams{
+		ID:     id,
+		Status: status,
+		Error:  message,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("job_id", id).Error("Failed to finish data purge job")
+		return err
+	}
+	return nil
+}
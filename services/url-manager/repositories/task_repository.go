@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+)
+
+// TaskRepository defines the interface for scraping task data operations
+type TaskRepository interface {
+	// CreateTask records a scraping task published for a URL that was due
+	// at dueAt
+	CreateTask(ctx context.Context, id, urlID uuid.UUID, attempt int, dueAt time.Time) error
+
+	// GetTask retrieves a scraping task by its ID
+	GetTask(ctx context.Context, id uuid.UUID) (*database.ScrapingTask, error)
+
+	// CompleteTask records the outcome of a scraping task
+	CompleteTask(ctx context.Context, arg database.CompleteScrapingTaskParams) error
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// TaskRepositoryImpl implements the TaskRepository interface using sqlc-generated queries
+type TaskRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewTaskRepository creates a new scraping task repository instance whose
+// queries are bounded by timeouts
+func NewTaskRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) TaskRepository {
+	return &TaskRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// CreateTask records a scraping task published for a URL that was due at
+// dueAt
+func (r *TaskRepositoryImpl) CreateTask(ctx context.Context, id, urlID uuid.UUID, attempt int, dueAt time.Time) error {
+	ctx, cancel := r.timeouts.Context(ctx, "CreateScrapingTask")
+	defer cancel()
+
+	_, err := r.db.CreateScrapingTask(ctx, database.CreateScrapingTaskParams{
+		ID:      id,
+		UrlID:   urlID,
+		Attempt: int32(attempt),
+		DueAt:   sql.NullTime{Time: dueAt, Valid: !dueAt.IsZero()},
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"task_id": id,
+			"url_id":  urlID,
+		}).Error("Failed to create scraping task")
+		return err
+	}
+	return nil
+}
+
+// GetTask retrieves a scraping task by its ID
+func (r *TaskRepositoryImpl) GetTask(ctx context.Context, id uuid.UUID) (*database.ScrapingTask, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetScrapingTask")
+	defer cancel()
+
+	task, err := r.db.GetScrapingTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CompleteTask records the outcome of a scraping task
+func (r *TaskRepositoryImpl) CompleteTask(ctx context.Context, arg database.CompleteScrapingTaskParams) error {
+	ctx, cancel := r.timeouts.Context(ctx, "CompleteScrapingTask")
+	defer cancel()
+
+	if err := r.db.CompleteScrapingTask(ctx, arg); err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"task_id": arg.ID,
+			"status":  arg.Status,
+		}).Error("Failed to complete scraping task")
+		return err
+	}
+	return nil
+}
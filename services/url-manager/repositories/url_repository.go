@@ -4,25 +4,35 @@ import (
 	"context"
 	"time"
 
+	"go_scraping_project/shared/config"
 	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
 
 	"github.com/google/uuid"
 )
 
 // URLRepository defines the interface for URL data operations
 type URLRepository interface {
-	// GetURLByID retrieves a URL by its ID
+	// GetURLByID retrieves a URL by its ID. It returns domain.ErrURLNotFound
+	// if the URL does not exist.
 	GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error)
 
-	// GetURLsScheduledForScraping retrieves URLs that are scheduled for scraping within a time range
-	GetURLsScheduledForScraping(ctx context.Context, from, to time.Time, limit int32) ([]database.Url, error)
+	// GetURLsScheduledForScraping retrieves URLs that are scheduled for
+	// scraping within a time range, interleaving projects by their shares
+	GetURLsScheduledForScraping(ctx context.Context, from, to time.Time, limit int32, shares config.ProjectShares) ([]database.Url, error)
 
 	// GetURLsByStatus retrieves URLs by their status
 	GetURLsByStatus(ctx context.Context, status string, limit, offset int32) ([]database.Url, error)
 
-	// UpdateURLStatus updates the status of a URL
+	// UpdateURLStatus moves a URL to a new status. It returns a
+	// *sharedmodels.InvalidTransitionError (a domain.ErrConflict) if the URL
+	// lifecycle does not allow the move, domain.ErrURLNotFound if the URL
+	// does not exist.
 	UpdateURLStatus(ctx context.Context, id uuid.UUID, status string) error
 
+	// OnStatusChange registers fn to be called after each status change made through UpdateURLStatus
+	OnStatusChange(fn func(context.Context, sharedmodels.URLStatusChange))
+
 	// UpdateNextScrapeTime updates the next scrape time for a URL
 	UpdateNextScrapeTime(ctx context.Context, id uuid.UUID, nextScrapeAt time.Time) error
 
@@ -43,4 +53,47 @@ type URLRepository interface {
 
 	// GetURLsByIDs retrieves multiple URLs by their IDs
 	GetURLsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.Url, error)
+
+	// GetOverdueURLs retrieves schedulable URLs whose next scrape is before the given time
+	GetOverdueURLs(ctx context.Context, before time.Time, limit int32) ([]database.Url, error)
+
+	// GetURLsWithConsecutiveFailures retrieves URLs whose most recent scrapes failed failures times in a row
+	GetURLsWithConsecutiveFailures(ctx context.Context, failures, limit int32) ([]database.Url, error)
+
+	// ListURLs retrieves every URL that is not deleted
+	ListURLs(ctx context.Context) ([]database.Url, error)
+
+	// UpsertURL creates a URL or replaces the configuration of the URL with the same address.
+	// It returns the URL's ID and whether a new URL was created.
+	UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error)
+
+	// DeleteURLs soft-deletes the URLs with the given IDs and returns the URLs that were deleted
+	DeleteURLs(ctx context.Context, ids []uuid.UUID) ([]database.SoftDeleteURLsRow, error)
+
+	// RecordMove records a scrape of a URL that was redirected permanently from one address to another
+	RecordMove(ctx context.Context, id uuid.UUID, from, to string, seenAt time.Time) (database.UrlMove, error)
+
+	// ClearPendingMoves forgets the unflagged moves of a URL to addresses other than keep
+	ClearPendingMoves(ctx context.Context, id uuid.UUID, keep string) error
+
+	// FlagMove flags a recorded move as detected
+	FlagMove(ctx context.Context, move database.UrlMove, flaggedAt time.Time) error
+
+	// ApplyMove changes the URL's address to the move's new location. It returns
+	// false if the URL was not changed because another URL has that address.
+	ApplyMove(ctx context.Context, move database.UrlMove, appliedAt time.Time) (bool, error)
+
+	// ClaimHARCapture assigns a pending HAR capture request of a URL to a scrape
+	// about to be published. It returns false if the URL has no pending request.
+	ClaimHARCapture(ctx context.Context, id, taskID uuid.UUID) (bool, error)
+
+	// ReleaseHARCapture returns a claimed HAR capture request to pending
+	ReleaseHARCapture(ctx context.Context, id, taskID uuid.UUID) error
+
+	// SaveHARCapture stores the HAR of the scrape a capture request was claimed
+	// for. It returns false if no request was claimed for the scrape.
+	SaveHARCapture(ctx context.Context, id, taskID uuid.UUID, har []byte, expiresAt time.Time) (bool, error)
+
+	// DeleteExpiredHARCaptures deletes expired HAR captures and unfulfilled requests
+	DeleteExpiredHARCaptures(ctx context.Context) (int64, error)
 }
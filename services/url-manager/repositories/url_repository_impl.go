@@ -3,31 +3,50 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"sync"
 	"time"
 
+	"go_scraping_project/shared/config"
 	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+	sharedmodels "go_scraping_project/shared/models"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
 )
 
 // URLRepositoryImpl implements the URLRepository interface using sqlc-generated queries
 type URLRepositoryImpl struct {
-	db     database.Querier
-	logger *logrus.Logger
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+
+	mu        sync.RWMutex
+	listeners []func(context.Context, sharedmodels.URLStatusChange)
 }
 
-// NewURLRepository creates a new URL repository instance
-func NewURLRepository(db database.Querier, logger *logrus.Logger) URLRepository {
+// NewURLRepository creates a new URL repository instance whose
+// queries are bounded by timeouts
+func NewURLRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) URLRepository {
 	return &URLRepositoryImpl{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
 	}
 }
 
-// GetURLByID retrieves a URL by its ID
+// GetURLByID retrieves a URL by its ID, failing with domain.ErrURLNotFound
+// if it does not exist
 func (r *URLRepositoryImpl) GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLByID")
+	defer cancel()
+
 	url, err := r.db.GetURLByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrURLNotFound.Wrap(err)
+	}
 	if err != nil {
 		r.logger.WithError(err).WithField("url_id", id).Error("Failed to get URL by ID")
 		return nil, err
@@ -35,13 +54,18 @@ func (r *URLRepositoryImpl) GetURLByID(ctx context.Context, id uuid.UUID) (*data
 	return &url, nil
 }
 
-// GetURLsScheduledForScraping retrieves URLs that are scheduled for scraping within a time range
-func (r *URLRepositoryImpl) GetURLsScheduledForScraping(ctx context.Context, from, to time.Time, limit int32) ([]database.Url, error) {
-	urls, err := r.db.GetURLsScheduledForScraping(ctx, database.GetURLsScheduledForScrapingParams{
-		NextScrapeAt:   sql.NullTime{Time: from, Valid: true},
-		NextScrapeAt_2: sql.NullTime{Time: to, Valid: true},
-		Limit:          limit,
-	})
+// GetURLsScheduledForScraping retrieves URLs that are scheduled for
+// scraping within a time range, interleaving projects by their shares
+func (r *URLRepositoryImpl) GetURLsScheduledForScraping(ctx context.Context, from, to time.Time, limit int32, shares config.ProjectShares) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLsScheduledFairly")
+	defer cancel()
+
+	params := database.GetURLsScheduledFairlyParams{FromTime: from, ToTime: to, MaxResults: limit}
+	for project, share := range shares {
+		params.ShareProjects = append(params.ShareProjects, project)
+		params.Shares = append(params.Shares, int32(share))
+	}
+	urls, err := r.db.GetURLsScheduledFairly(ctx, params)
 	if err != nil {
 		r.logger.WithError(err).WithFields(logrus.Fields{
 			"from":  from,
@@ -55,6 +79,9 @@ func (r *URLRepositoryImpl) GetURLsScheduledForScraping(ctx context.Context, fro
 
 // GetURLsByStatus retrieves URLs by their status
 func (r *URLRepositoryImpl) GetURLsByStatus(ctx context.Context, status string, limit, offset int32) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLsByStatus")
+	defer cancel()
+
 	urls, err := r.db.GetURLsByStatus(ctx, database.GetURLsByStatusParams{
 		Status: status,
 		Limit:  limit,
@@ -71,12 +98,23 @@ func (r *URLRepositoryImpl) GetURLsByStatus(ctx context.Context, status string,
 	return urls, nil
 }
 
-// UpdateURLStatus updates the status of a URL
+// UpdateURLStatus moves a URL to a new status. The move is checked against
+// the URL status lifecycle in the same statement that makes it, so a
+// concurrent change cannot slip in between, and a
+// *sharedmodels.InvalidTransitionError is returned if it is not allowed. Listeners are notified when the status
+// actually changes.
 func (r *URLRepositoryImpl) UpdateURLStatus(ctx context.Context, id uuid.UUID, status string) error {
-	err := r.db.UpdateURLStatus(ctx, database.UpdateURLStatusParams{
-		ID:     id,
-		Status: status,
+	// Listeners get ctx, not the query's context
+	queryCtx, cancel := r.timeouts.Context(ctx, "TransitionURLStatus")
+	previous, err := r.db.TransitionURLStatus(queryCtx, database.TransitionURLStatusParams{
+		Status:       status,
+		ID:           id,
+		FromStatuses: sharedmodels.URLStatusesBefore(status),
 	})
+	cancel()
+	if errors.Is(err, sql.ErrNoRows) {
+		err = r.invalidTransition(ctx, id, status)
+	}
 	if err != nil {
 		r.logger.WithError(err).WithFields(logrus.Fields{
 			"url_id": id,
@@ -84,11 +122,55 @@ func (r *URLRepositoryImpl) UpdateURLStatus(ctx context.Context, id uuid.UUID, s
 		}).Error("Failed to update URL status")
 		return err
 	}
+	if previous == status {
+		return nil
+	}
+
+	change := sharedmodels.URLStatusChange{URLID: id, From: previous, To: status, ChangedAt: time.Now().UTC()}
+	r.logger.WithFields(logrus.Fields{
+		"url_id": id,
+		"from":   previous,
+		"to":     status,
+	}).Info("URL status changed")
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+	for _, listener := range listeners {
+		listener(ctx, change)
+	}
 	return nil
 }
 
+// OnStatusChange registers fn to be called after each status change made
+// through UpdateURLStatus
+func (r *URLRepositoryImpl) OnStatusChange(fn func(context.Context, sharedmodels.URLStatusChange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// invalidTransition explains why a status update matched no row: the URL
+// does not exist (domain.ErrURLNotFound) or its current status cannot move
+// to status
+func (r *URLRepositoryImpl) invalidTransition(ctx context.Context, id uuid.UUID, status string) error {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLByID")
+	defer cancel()
+
+	url, err := r.db.GetURLByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrURLNotFound.Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	return &sharedmodels.InvalidTransitionError{From: url.Status, To: status}
+}
+
 // UpdateNextScrapeTime updates the next scrape time for a URL
 func (r *URLRepositoryImpl) UpdateNextScrapeTime(ctx context.Context, id uuid.UUID, nextScrapeAt time.Time) error {
+	ctx, cancel := r.timeouts.Context(ctx, "UpdateNextScrapeTime")
+	defer cancel()
+
 	err := r.db.UpdateNextScrapeTime(ctx, database.UpdateNextScrapeTimeParams{
 		ID:           id,
 		NextScrapeAt: sql.NullTime{Time: nextScrapeAt, Valid: true},
@@ -105,6 +187,9 @@ func (r *URLRepositoryImpl) UpdateNextScrapeTime(ctx context.Context, id uuid.UU
 
 // UpdateLastScrapedTime updates the last scraped time for a URL
 func (r *URLRepositoryImpl) UpdateLastScrapedTime(ctx context.Context, id uuid.UUID, lastScrapedAt time.Time) error {
+	ctx, cancel := r.timeouts.Context(ctx, "UpdateLastScrapedTime")
+	defer cancel()
+
 	err := r.db.UpdateLastScrapedTime(ctx, database.UpdateLastScrapedTimeParams{
 		ID:            id,
 		LastScrapedAt: sql.NullTime{Time: lastScrapedAt, Valid: true},
@@ -121,6 +206,9 @@ func (r *URLRepositoryImpl) UpdateLastScrapedTime(ctx context.Context, id uuid.U
 
 // IncrementRetryCount increments the retry count for a URL
 func (r *URLRepositoryImpl) IncrementRetryCount(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "IncrementRetryCount")
+	defer cancel()
+
 	err := r.db.IncrementRetryCount(ctx, id)
 	if err != nil {
 		r.logger.WithError(err).WithField("url_id", id).Error("Failed to increment retry count")
@@ -131,6 +219,9 @@ func (r *URLRepositoryImpl) IncrementRetryCount(ctx context.Context, id uuid.UUI
 
 // ResetRetryCount resets the retry count for a URL
 func (r *URLRepositoryImpl) ResetRetryCount(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "ResetRetryCount")
+	defer cancel()
+
 	err := r.db.ResetRetryCount(ctx, id)
 	if err != nil {
 		r.logger.WithError(err).WithField("url_id", id).Error("Failed to reset retry count")
@@ -141,6 +232,9 @@ func (r *URLRepositoryImpl) ResetRetryCount(ctx context.Context, id uuid.UUID) e
 
 // GetURLsForImmediateScraping retrieves URLs that should be scraped immediately
 func (r *URLRepositoryImpl) GetURLsForImmediateScraping(ctx context.Context, limit int32) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLsForImmediateScraping")
+	defer cancel()
+
 	urls, err := r.db.GetURLsForImmediateScraping(ctx, database.GetURLsForImmediateScrapingParams{
 		NextScrapeAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
 		Limit:        limit,
@@ -154,6 +248,9 @@ func (r *URLRepositoryImpl) GetURLsForImmediateScraping(ctx context.Context, lim
 
 // CountURLsByStatus counts URLs by their status
 func (r *URLRepositoryImpl) CountURLsByStatus(ctx context.Context, status string) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "CountURLsByStatus")
+	defer cancel()
+
 	count, err := r.db.CountURLsByStatus(ctx, status)
 	if err != nil {
 		r.logger.WithError(err).WithField("status", status).Error("Failed to count URLs by status")
@@ -164,6 +261,9 @@ func (r *URLRepositoryImpl) CountURLsByStatus(ctx context.Context, status string
 
 // GetURLsByIDs retrieves multiple URLs by their IDs
 func (r *URLRepositoryImpl) GetURLsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLsByIDs")
+	defer cancel()
+
 	urls, err := r.db.GetURLsByIDs(ctx, ids)
 	if err != nil {
 		r.logger.WithError(err).WithField("url_ids", ids).Error("Failed to get URLs by IDs")
@@ -171,3 +271,241 @@ func (r *URLRepositoryImpl) GetURLsByIDs(ctx context.Context, ids []uuid.UUID) (
 	}
 	return urls, nil
 }
+
+// GetOverdueURLs retrieves schedulable URLs whose next scrape is before the given time
+func (r *URLRepositoryImpl) GetOverdueURLs(ctx context.Context, before time.Time, limit int32) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetOverdueURLs")
+	defer cancel()
+
+	urls, err := r.db.GetOverdueURLs(ctx, database.GetOverdueURLsParams{
+		NextScrapeAt: sql.NullTime{Time: before, Valid: true},
+		Limit:        limit,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"before": before,
+			"limit":  limit,
+		}).Error("Failed to get overdue URLs")
+		return nil, err
+	}
+	return urls, nil
+}
+
+// GetURLsWithConsecutiveFailures retrieves URLs whose most recent scrapes failed failures times in a row
+func (r *URLRepositoryImpl) GetURLsWithConsecutiveFailures(ctx context.Context, failures, limit int32) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "GetURLsWithConsecutiveFailures")
+	defer cancel()
+
+	urls, err := r.db.GetURLsWithConsecutiveFailures(ctx, database.GetURLsWithConsecutiveFailuresParams{
+		Failures:   failures,
+		MaxResults: limit,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"failures": failures,
+			"limit":    limit,
+		}).Error("Failed to get URLs with consecutive failures")
+		return nil, err
+	}
+	return urls, nil
+}
+
+// ListURLs retrieves every URL that is not deleted
+func (r *URLRepositoryImpl) ListURLs(ctx context.Context) ([]database.Url, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListURLsForExport")
+	defer cancel()
+
+	urls, err := r.db.ListURLsForExport(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list URLs")
+		return nil, err
+	}
+	return urls, nil
+}
+
+// UpsertURL creates a URL or replaces the configuration of the URL with the same address.
+// A changed parser config is recorded in the URL's parser config history.
+func (r *URLRepositoryImpl) UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error) {
+	upsertCtx, cancel := r.timeouts.Context(ctx, "UpsertURL")
+	row, err := r.db.UpsertURL(upsertCtx, arg)
+	cancel()
+	if err != nil {
+		r.logger.WithError(err).WithField("url", arg.Url).Error("Failed to upsert URL")
+		return database.UpsertURLRow{}, err
+	}
+
+	recordCtx, cancel := r.timeouts.Context(ctx, "RecordParserConfigVersion")
+	defer cancel()
+	_, err = r.db.RecordParserConfigVersion(recordCtx, database.RecordParserConfigVersionParams{
+		UrlID:  row.ID,
+		Config: arg.ParserConfig,
+		Author: "url-manager",
+		Reason: "config sync",
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("url", arg.Url).Error("Failed to record parser config version")
+	}
+	return row, nil
+}
+
+// DeleteURLs soft-deletes the URLs with the given IDs
+func (r *URLRepositoryImpl) DeleteURLs(ctx context.Context, ids []uuid.UUID) ([]database.SoftDeleteURLsRow, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "SoftDeleteURLs")
+	defer cancel()
+
+	if len(ids) == 0 {
+		// An empty ID list would match every URL
+		return nil, nil
+	}
+	deleted, err := r.db.SoftDeleteURLs(ctx, database.SoftDeleteURLsParams{Ids: ids})
+	if err != nil {
+		r.logger.WithError(err).WithField("url_ids", ids).Error("Failed to delete URLs")
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// RecordMove records a scrape of a URL that was redirected permanently from one address to another
+func (r *URLRepositoryImpl) RecordMove(ctx context.Context, id uuid.UUID, from, to string, seenAt time.Time) (database.UrlMove, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "RecordURLMove")
+	defer cancel()
+
+	move, err := r.db.RecordURLMove(ctx, database.RecordURLMoveParams{
+		UrlID:   id,
+		FromUrl: from,
+		ToUrl:   to,
+		SeenAt:  seenAt,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"url_id": id,
+			"to_url": to,
+		}).Error("Failed to record URL move")
+		return database.UrlMove{}, err
+	}
+	return move, nil
+}
+
+// ClearPendingMoves forgets the unflagged moves of a URL to addresses other than keep
+func (r *URLRepositoryImpl) ClearPendingMoves(ctx context.Context, id uuid.UUID, keep string) error {
+	ctx, cancel := r.timeouts.Context(ctx, "ClearPendingURLMoves")
+	defer cancel()
+
+	err := r.db.ClearPendingURLMoves(ctx, database.ClearPendingURLMovesParams{UrlID: id, ToUrl: keep})
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", id).Error("Failed to clear pending URL moves")
+		return err
+	}
+	return nil
+}
+
+// FlagMove flags a recorded move as detected
+func (r *URLRepositoryImpl) FlagMove(ctx context.Context, move database.UrlMove, flaggedAt time.Time) error {
+	ctx, cancel := r.timeouts.Context(ctx, "FlagURLMove")
+	defer cancel()
+
+	err := r.db.FlagURLMove(ctx, database.FlagURLMoveParams{
+		FlaggedAt: flaggedAt,
+		UrlID:     move.UrlID,
+		FromUrl:   move.FromUrl,
+		ToUrl:     move.ToUrl,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"url_id": move.UrlID,
+			"to_url": move.ToUrl,
+		}).Error("Failed to flag URL move")
+		return err
+	}
+	return nil
+}
+
+// ApplyMove changes the URL's address to the move's new location. It returns
+// false if the URL was not changed because another URL has that address.
+func (r *URLRepositoryImpl) ApplyMove(ctx context.Context, move database.UrlMove, appliedAt time.Time) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ApplyURLMove")
+	defer cancel()
+
+	applied, err := r.db.ApplyURLMove(ctx, database.ApplyURLMoveParams{
+		ToUrl:     move.ToUrl,
+		UrlID:     move.UrlID,
+		FromUrl:   move.FromUrl,
+		AppliedAt: appliedAt,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"url_id": move.UrlID,
+			"to_url": move.ToUrl,
+		}).Error("Failed to apply URL move")
+		return false, err
+	}
+	return applied > 0, nil
+}
+
+// ClaimHARCapture assigns a pending HAR capture request of a URL to a scrape
+// about to be published. It returns false if the URL has no pending request.
+func (r *URLRepositoryImpl) ClaimHARCapture(ctx context.Context, id, taskID uuid.UUID) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ClaimHARCapture")
+	defer cancel()
+
+	claimed, err := r.db.ClaimHARCapture(ctx, database.ClaimHARCaptureParams{
+		UrlID:  id,
+		TaskID: uuid.NullUUID{UUID: taskID, Valid: true},
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", id).Error("Failed to claim HAR capture")
+		return false, err
+	}
+	return claimed > 0, nil
+}
+
+// ReleaseHARCapture returns a claimed HAR capture request to pending
+func (r *URLRepositoryImpl) ReleaseHARCapture(ctx context.Context, id, taskID uuid.UUID) error {
+	ctx, cancel := r.timeouts.Context(ctx, "ReleaseHARCapture")
+	defer cancel()
+
+	err := r.db.ReleaseHARCapture(ctx, database.ReleaseHARCaptureParams{
+		UrlID:  id,
+		TaskID: uuid.NullUUID{UUID: taskID, Valid: true},
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("url_id", id).Error("Failed to release HAR capture")
+		return err
+	}
+	return nil
+}
+
+// SaveHARCapture stores the HAR of the scrape a capture request was claimed
+// for. It returns false if no request was claimed for the scrape.
+func (r *URLRepositoryImpl) SaveHARCapture(ctx context.Context, id, taskID uuid.UUID, har []byte, expiresAt time.Time) (bool, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "SaveHARCapture")
+	defer cancel()
+
+	saved, err := r.db.SaveHARCapture(ctx, database.SaveHARCaptureParams{
+		UrlID:     id,
+		TaskID:    uuid.NullUUID{UUID: taskID, Valid: true},
+		Har:       pqtype.NullRawMessage{RawMessage: har, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"url_id":  id,
+			"task_id": taskID,
+		}).Error("Failed to save HAR capture")
+		return false, err
+	}
+	return saved > 0, nil
+}
+
+// DeleteExpiredHARCaptures deletes expired HAR captures and unfulfilled requests
+func (r *URLRepositoryImpl) DeleteExpiredHARCaptures(ctx context.Context) (int64, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "DeleteExpiredHARCaptures")
+	defer cancel()
+
+	deleted, err := r.db.DeleteExpiredHARCaptures(ctx)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete expired HAR captures")
+		return 0, err
+	}
+	return deleted, nil
+}
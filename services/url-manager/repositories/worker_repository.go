@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// WorkerRepository defines the interface for scraper fleet data operations
+type WorkerRepository interface {
+	// ListHealthyRegions lists the regions with a scraper that sent a heartbeat since a time
+	ListHealthyRegions(ctx context.Context, since time.Time) ([]string, error)
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WorkerRepositoryImpl implements the WorkerRepository interface using sqlc-generated queries
+type WorkerRepositoryImpl struct {
+	db       database.Querier
+	logger   *logrus.Logger
+	timeouts database.QueryTimeouts
+}
+
+// NewWorkerRepository creates a new worker repository instance whose
+// queries are bounded by timeouts
+func NewWorkerRepository(db database.Querier, logger *logrus.Logger, timeouts database.QueryTimeouts) WorkerRepository {
+	return &WorkerRepositoryImpl{
+		db:       db,
+		logger:   logger,
+		timeouts: timeouts,
+	}
+}
+
+// ListHealthyRegions lists the regions with a scraper that sent a heartbeat since a time
+func (r *WorkerRepositoryImpl) ListHealthyRegions(ctx context.Context, since time.Time) ([]string, error) {
+	ctx, cancel := r.timeouts.Context(ctx, "ListHealthyWorkerRegions")
+	defer cancel()
+
+	regions, err := r.db.ListHealthyWorkerRegions(ctx, since)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list healthy worker regions")
+		return nil, err
+	}
+	return regions, nil
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/notify"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default alert settings used when none are configured
+const (
+	DefaultAlertEvaluationInterval = time.Minute
+	DefaultAlertSuccessRateWindow  = time.Hour
+)
+
+// KafkaOffsets reads consumer lag, see kafka.Offsets
+type KafkaOffsets interface {
+	ConsumerLag(ctx context.Context, groupID, topic string) (int64, error)
+}
+
+// AlertEvaluatorService evaluates the system alert rules, such as the dead
+// letter queue filling up or the success rate dropping. When a rule starts
+// firing it notifies the notification channels and records the event in the
+// alert history; it does the same once when the rule resolves. Firing state
+// is kept in memory, so a rule still firing after a restart fires again.
+type AlertEvaluatorService struct {
+	alertRepo repositories.AlertRepository
+	offsets   KafkaOffsets
+	notifier  Notifier
+	kafka     config.KafkaConfig // Default topics and consumer group of the rules
+	logger    *logrus.Logger
+	ticker    *time.Ticker
+	stopChan  chan struct{}
+	now       func() time.Time
+
+	// Settings that can change at runtime, see Configure
+	mu     sync.Mutex
+	cfg    config.AlertsConfig
+	firing map[string]bool // Names of the rules currently firing
+}
+
+// NewAlertEvaluatorService creates a new alert evaluator service
+func NewAlertEvaluatorService(
+	alertRepo repositories.AlertRepository,
+	offsets KafkaOffsets,
+	notifier Notifier,
+	kafkaCfg config.KafkaConfig,
+	logger *logrus.Logger,
+) *AlertEvaluatorService {
+	return &AlertEvaluatorService{
+		alertRepo: alertRepo,
+		offsets:   offsets,
+		notifier:  notifier,
+		kafka:     kafkaCfg,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+		now:       func() time.Time { return time.Now().UTC() },
+		cfg: config.AlertsConfig{
+			Enabled:            true,
+			EvaluationInterval: DefaultAlertEvaluationInterval,
+		},
+		firing: make(map[string]bool),
+	}
+}
+
+// Configure applies alert settings. It is safe to call while the evaluator
+// is running. If any rule is invalid the previous settings stay in effect;
+// rules that were removed stop firing without a resolved event.
+func (a *AlertEvaluatorService) Configure(cfg config.AlertsConfig) error {
+	if cfg.EvaluationInterval <= 0 {
+		cfg.EvaluationInterval = DefaultAlertEvaluationInterval
+	}
+	rules := make([]config.AlertRuleConfig, 0, len(cfg.Rules))
+	names := make(map[string]bool, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rule, err := a.normalizeRule(rule)
+		if err != nil {
+			return err
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate alert rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, rule)
+	}
+	cfg.Rules = rules
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cfg.EvaluationInterval != a.cfg.EvaluationInterval && a.ticker != nil {
+		a.ticker.Reset(cfg.EvaluationInterval)
+	}
+	for name := range a.firing {
+		if !names[name] {
+			delete(a.firing, name)
+		}
+	}
+	a.logger.WithFields(logrus.Fields{
+		"enabled":             cfg.Enabled,
+		"evaluation_interval": cfg.EvaluationInterval.String(),
+		"rules":               len(cfg.Rules),
+	}).Info("Alert settings updated")
+	a.cfg = cfg
+	return nil
+}
+
+// normalizeRule validates a rule and fills in its defaults
+func (a *AlertEvaluatorService) normalizeRule(rule config.AlertRuleConfig) (config.AlertRuleConfig, error) {
+	if rule.Name == "" {
+		return rule, fmt.Errorf("alert rule of kind %q has no name", rule.Kind)
+	}
+	if rule.Threshold < 0 {
+		return rule, fmt.Errorf("alert rule %q: threshold must not be negative", rule.Name)
+	}
+
+	switch rule.Severity {
+	case "":
+		rule.Severity = notify.SeverityWarning
+	case notify.SeverityInfo, notify.SeverityWarning, notify.SeverityCritical:
+	default:
+		return rule, fmt.Errorf("alert rule %q: severity must be info, warning or critical", rule.Name)
+	}
+
+	switch rule.Kind {
+	case sharedmodels.AlertKindDLQDepth:
+	case sharedmodels.AlertKindConsumerLag:
+		if rule.Topic == "" {
+			rule.Topic = a.kafka.Topics.ScrapingResults
+		}
+		if rule.Group == "" {
+			rule.Group = a.kafka.GroupID
+		}
+	case sharedmodels.AlertKindSuccessRate:
+		if rule.Threshold > 100 {
+			return rule, fmt.Errorf("alert rule %q: success rate threshold must be a percentage", rule.Name)
+		}
+		if rule.Window <= 0 {
+			rule.Window = DefaultAlertSuccessRateWindow
+		}
+	case sharedmodels.AlertKindNoScrapes:
+		if rule.Window <= 0 {
+			return rule, fmt.Errorf("alert rule %q: window is required", rule.Name)
+		}
+		rule.Threshold = rule.Window.Minutes()
+	default:
+		return rule, fmt.Errorf("alert rule %q: unknown kind %q", rule.Name, rule.Kind)
+	}
+	return rule, nil
+}
+
+// Start starts the alert evaluator service
+func (a *AlertEvaluatorService) Start(ctx context.Context) error {
+	a.logger.Info("Starting Alert Evaluator Service")
+
+	a.mu.Lock()
+	a.ticker = time.NewTicker(a.cfg.EvaluationInterval)
+	a.mu.Unlock()
+
+	go a.run(ctx)
+
+	return nil
+}
+
+// Stop stops the alert evaluator service
+func (a *AlertEvaluatorService) Stop() error {
+	a.logger.Info("Stopping Alert Evaluator Service")
+
+	if a.ticker != nil {
+		a.ticker.Stop()
+	}
+
+	close(a.stopChan)
+	return nil
+}
+
+// run runs the evaluation loop
+func (a *AlertEvaluatorService) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stopChan:
+			return
+		case <-a.ticker.C:
+			a.evaluate(ctx)
+		}
+	}
+}
+
+// evaluate evaluates every rule. A rule that cannot be measured is logged
+// and keeps its state.
+func (a *AlertEvaluatorService) evaluate(ctx context.Context) {
+	a.mu.Lock()
+	cfg := a.cfg
+	a.mu.Unlock()
+	if !cfg.Enabled {
+		return
+	}
+
+	for _, rule := range cfg.Rules {
+		value, firing, err := a.measure(ctx, rule)
+		if err != nil {
+			a.logger.WithError(err).WithField("rule", rule.Name).Error("Failed to evaluate alert rule")
+			continue
+		}
+
+		a.mu.Lock()
+		changed := a.firing[rule.Name] != firing
+		a.firing[rule.Name] = firing
+		a.mu.Unlock()
+		if changed {
+			a.transition(ctx, rule, value, firing)
+		}
+	}
+}
+
+// measure returns the rule's current value and whether the rule fires
+func (a *AlertEvaluatorService) measure(ctx context.Context, rule config.AlertRuleConfig) (float64, bool, error) {
+	switch rule.Kind {
+	case sharedmodels.AlertKindDLQDepth:
+		depth, err := a.alertRepo.CountDeadLetters(ctx, rule.Topic)
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(depth), float64(depth) > rule.Threshold, nil
+
+	case sharedmodels.AlertKindConsumerLag:
+		lag, err := a.offsets.ConsumerLag(ctx, rule.Group, rule.Topic)
+		if err != nil {
+			return 0, false, err
+		}
+		return float64(lag), float64(lag) > rule.Threshold, nil
+
+	case sharedmodels.AlertKindSuccessRate:
+		total, succeeded, err := a.alertRepo.CountTaskOutcomes(ctx, a.now().Add(-rule.Window))
+		if err != nil {
+			return 0, false, err
+		}
+		// Without scrapes there is no rate; the no_scrapes rule covers that
+		if total == 0 {
+			return 100, false, nil
+		}
+		rate := float64(succeeded) / float64(total) * 100
+		return rate, rate < rule.Threshold, nil
+
+	case sharedmodels.AlertKindNoScrapes:
+		last, ok, err := a.alertRepo.GetLastCompletedAt(ctx)
+		if err != nil || !ok {
+			return 0, false, err
+		}
+		since := a.now().Sub(last)
+		return since.Minutes(), since >= rule.Window, nil
+	}
+	return 0, false, fmt.Errorf("unknown alert rule kind %q", rule.Kind)
+}
+
+// transition notifies the channels and records the event of a rule that
+// started firing or resolved. Failures are logged; the state is not retried.
+func (a *AlertEvaluatorService) transition(ctx context.Context, rule config.AlertRuleConfig, value float64, firing bool) {
+	state, severity, title := sharedmodels.AlertStateResolved, notify.SeverityInfo, "Alert resolved: "+rule.Name
+	if firing {
+		state, severity, title = sharedmodels.AlertStateFiring, rule.Severity, "Alert firing: "+rule.Name
+	}
+	message := alertMessage(rule, value)
+
+	a.logger.WithFields(logrus.Fields{
+		"rule":  rule.Name,
+		"state": state,
+		"value": value,
+	}).Warn(message)
+
+	alert := notify.Alert{
+		Title:    title,
+		Message:  message,
+		Severity: severity,
+		Fields: map[string]string{
+			"rule":      rule.Name,
+			"kind":      rule.Kind,
+			"state":     state,
+			"value":     strconv.FormatFloat(value, 'f', -1, 64),
+			"threshold": strconv.FormatFloat(rule.Threshold, 'f', -1, 64),
+		},
+		Time: a.now(),
+	}
+	if err := a.notifier.Notify(ctx, alert); err != nil {
+		a.logger.WithError(err).WithField("rule", rule.Name).Error("Failed to send alert")
+	}
+
+	err := a.alertRepo.RecordAlertEvent(ctx, database.CreateAlertEventParams{
+		Rule:      rule.Name,
+		Kind:      rule.Kind,
+		State:     state,
+		Severity:  severity,
+		Value:     value,
+		Threshold: rule.Threshold,
+		Message:   message,
+	})
+	if err != nil {
+		a.logger.WithError(err).WithField("rule", rule.Name).Error("Failed to record alert event")
+	}
+}
+
+// alertMessage describes a rule's current value
+func alertMessage(rule config.AlertRuleConfig, value float64) string {
+	switch rule.Kind {
+	case sharedmodels.AlertKindDLQDepth:
+		if rule.Topic == "" {
+			return fmt.Sprintf("Dead letter queue holds %.0f messages (threshold %g)", value, rule.Threshold)
+		}
+		return fmt.Sprintf("Dead letter queue holds %.0f messages from %s (threshold %g)", value, rule.Topic, rule.Threshold)
+	case sharedmodels.AlertKindConsumerLag:
+		return fmt.Sprintf("Consumer group %s is %.0f messages behind on %s (threshold %g)", rule.Group, value, rule.Topic, rule.Threshold)
+	case sharedmodels.AlertKindSuccessRate:
+		return fmt.Sprintf("%.1f%% of scrapes succeeded over the last %s (threshold %g%%)", value, rule.Window, rule.Threshold)
+	case sharedmodels.AlertKindNoScrapes:
+		return fmt.Sprintf("Last scrape completed %.0f minutes ago (threshold %s)", value, rule.Window)
+	}
+	return fmt.Sprintf("%s is %g (threshold %g)", rule.Kind, value, rule.Threshold)
+}
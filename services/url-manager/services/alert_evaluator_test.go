@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/notify"
+)
+
+// fakeAlertRepository serves fixed task outcomes and records alert events
+type fakeAlertRepository struct {
+	total, succeeded int64
+	deadLetters      map[string]int64 // By source topic
+	since            time.Time
+	lastCompletedAt  time.Time
+	events           []database.CreateAlertEventParams
+}
+
+func (f *fakeAlertRepository) CountTaskOutcomes(ctx context.Context, since time.Time) (int64, int64, error) {
+	f.since = since
+	return f.total, f.succeeded, nil
+}
+
+func (f *fakeAlertRepository) CountDeadLetters(ctx context.Context, topic string) (int64, error) {
+	if topic != "" {
+		return f.deadLetters[topic], nil
+	}
+	var count int64
+	for _, n := range f.deadLetters {
+		count += n
+	}
+	return count, nil
+}
+
+func (f *fakeAlertRepository) GetLastCompletedAt(ctx context.Context) (time.Time, bool, error) {
+	return f.lastCompletedAt, !f.lastCompletedAt.IsZero(), nil
+}
+
+func (f *fakeAlertRepository) RecordAlertEvent(ctx context.Context, arg database.CreateAlertEventParams) error {
+	f.events = append(f.events, arg)
+	return nil
+}
+
+// fakeOffsets serves fixed consumer lag
+type fakeOffsets struct {
+	lag map[string]int64 // By group and topic, joined with a slash
+}
+
+func (f *fakeOffsets) ConsumerLag(ctx context.Context, groupID, topic string) (int64, error) {
+	return f.lag[groupID+"/"+topic], nil
+}
+
+func newTestAlertEvaluator(repo *fakeAlertRepository, offsets *fakeOffsets, notifier *fakeNotifier) *AlertEvaluatorService {
+	kafkaCfg := config.KafkaConfig{
+		GroupID: "url-manager",
+		Topics:  config.TopicsConfig{ScrapingResults: "scraping-results"},
+	}
+	return NewAlertEvaluatorService(repo, offsets, notifier, kafkaCfg, newTestLogger())
+}
+
+func TestAlertEvaluatorFiresAndResolves(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeAlertRepository{
+		total:           10,
+		succeeded:       9,
+		lastCompletedAt: now.Add(-time.Minute),
+		deadLetters:     map[string]int64{"scraping-tasks": 120, "scraping-results": 30},
+	}
+	offsets := &fakeOffsets{lag: map[string]int64{"url-manager/scraping-results": 5}}
+	notifier := &fakeNotifier{}
+
+	evaluator := newTestAlertEvaluator(repo, offsets, notifier)
+	evaluator.now = func() time.Time { return now }
+	err := evaluator.Configure(config.AlertsConfig{Enabled: true, Rules: []config.AlertRuleConfig{
+		{Name: "dlq", Kind: sharedmodels.AlertKindDLQDepth, Threshold: 100, Severity: notify.SeverityCritical},
+		{Name: "dlq-results", Kind: sharedmodels.AlertKindDLQDepth, Topic: "scraping-results", Threshold: 100},
+		{Name: "success", Kind: sharedmodels.AlertKindSuccessRate, Threshold: 80},
+		{Name: "lag", Kind: sharedmodels.AlertKindConsumerLag, Threshold: 1000},
+		{Name: "silence", Kind: sharedmodels.AlertKindNoScrapes, Window: 30 * time.Minute},
+	}})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	evaluator.evaluate(context.Background())
+	if want := now.Add(-time.Hour); !repo.since.Equal(want) {
+		t.Errorf("success rate window starts at %v, want %v", repo.since, want)
+	}
+	if len(repo.events) != 1 || repo.events[0].Rule != "dlq" || repo.events[0].State != sharedmodels.AlertStateFiring {
+		t.Fatalf("events = %+v, want dlq firing", repo.events)
+	}
+	if repo.events[0].Value != 150 || repo.events[0].Threshold != 100 {
+		t.Errorf("event value = %v, threshold = %v", repo.events[0].Value, repo.events[0].Threshold)
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Severity != notify.SeverityCritical {
+		t.Fatalf("alerts = %+v, want one critical alert", notifier.alerts)
+	}
+
+	// Still firing: no repeated alert
+	evaluator.evaluate(context.Background())
+	if len(repo.events) != 1 || len(notifier.alerts) != 1 {
+		t.Fatalf("repeated alert for a rule that keeps firing: %d events", len(repo.events))
+	}
+
+	// The DLQ drains while scrapes fail and stop
+	repo.deadLetters = nil
+	repo.succeeded = 5
+	repo.lastCompletedAt = now.Add(-time.Hour)
+	evaluator.evaluate(context.Background())
+
+	states := make(map[string]string)
+	for _, event := range repo.events[1:] {
+		states[event.Rule] = event.State
+	}
+	want := map[string]string{
+		"dlq":     sharedmodels.AlertStateResolved,
+		"success": sharedmodels.AlertStateFiring,
+		"silence": sharedmodels.AlertStateFiring,
+	}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for rule, state := range want {
+		if states[rule] != state {
+			t.Errorf("rule %s = %q, want %q", rule, states[rule], state)
+		}
+	}
+	if got := notifier.alerts[1].Severity; got != notify.SeverityInfo {
+		t.Errorf("resolved alert severity = %q, want info", got)
+	}
+}
+
+func TestAlertEvaluatorSuccessRateWithoutScrapes(t *testing.T) {
+	repo := &fakeAlertRepository{}
+	evaluator := newTestAlertEvaluator(repo, &fakeOffsets{}, &fakeNotifier{})
+	err := evaluator.Configure(config.AlertsConfig{Enabled: true, Rules: []config.AlertRuleConfig{
+		{Name: "success", Kind: sharedmodels.AlertKindSuccessRate, Threshold: 95},
+		{Name: "silence", Kind: sharedmodels.AlertKindNoScrapes, Window: time.Minute},
+	}})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	evaluator.evaluate(context.Background())
+	if len(repo.events) != 0 {
+		t.Errorf("events = %+v, want none before the first scrape", repo.events)
+	}
+}
+
+func TestAlertEvaluatorRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.AlertRuleConfig
+	}{
+		{"missing name", config.AlertRuleConfig{Kind: sharedmodels.AlertKindDLQDepth}},
+		{"unknown kind", config.AlertRuleConfig{Name: "a", Kind: "disk_full"}},
+		{"negative threshold", config.AlertRuleConfig{Name: "a", Kind: sharedmodels.AlertKindDLQDepth, Threshold: -1}},
+		{"rate over 100", config.AlertRuleConfig{Name: "a", Kind: sharedmodels.AlertKindSuccessRate, Threshold: 120}},
+		{"no window", config.AlertRuleConfig{Name: "a", Kind: sharedmodels.AlertKindNoScrapes}},
+		{"unknown severity", config.AlertRuleConfig{Name: "a", Kind: sharedmodels.AlertKindDLQDepth, Severity: "page"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := newTestAlertEvaluator(&fakeAlertRepository{}, &fakeOffsets{}, &fakeNotifier{})
+			if err := evaluator.Configure(config.AlertsConfig{Rules: []config.AlertRuleConfig{tt.rule}}); err == nil {
+				t.Error("Configure() accepted an invalid rule")
+			}
+		})
+	}
+
+	evaluator := newTestAlertEvaluator(&fakeAlertRepository{}, &fakeOffsets{}, &fakeNotifier{})
+	rule := config.AlertRuleConfig{Name: "a", Kind: sharedmodels.AlertKindDLQDepth}
+	if err := evaluator.Configure(config.AlertsConfig{Rules: []config.AlertRuleConfig{rule, rule}}); err == nil {
+		t.Error("Configure() accepted duplicate rule names")
+	}
+}
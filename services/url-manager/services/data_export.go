@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	"go_scraping_project/shared/exports"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Data export settings
+const (
+	DataExportInterval  = 10 * time.Second
+	DataExportBatchSize = 1000
+	// A running job not updated for this long stopped with its process and is claimed again
+	DataExportStaleAfter = 5 * time.Minute
+	// Expired files deleted per pass
+	DataExportExpireBatch = 100
+)
+
+// DataExportService writes the data exports of projects to files: it
+// creates the jobs of the schedules in exports.schedules when they are due,
+// claims pending jobs, requested through the API Gateway or scheduled, and
+// writes the parsed records of their project in batches to a temporary file,
+// uploaded to the export store once complete. Sensitive fields are
+// decrypted, since the file is handed to the project. Files are deleted once
+// they expire after exports.retention.
+type DataExportService struct {
+	exportRepo repositories.ExportRepository
+	store      exports.Store
+	keyring    *encryption.Keyring
+	cfg        config.ExportsConfig
+	logger     *logrus.Logger
+	ticker     *time.Ticker
+	stopChan   chan struct{}
+	now        func() time.Time
+	batchSize  int
+}
+
+// NewDataExportService creates a new data export service keeping files in
+// store. keyring decrypts sensitive fields and may be nil when no encryption
+// keys are configured.
+func NewDataExportService(exportRepo repositories.ExportRepository, store exports.Store, keyring *encryption.Keyring, cfg config.ExportsConfig, logger *logrus.Logger) *DataExportService {
+	return &DataExportService{
+		exportRepo: exportRepo,
+		store:      store,
+		keyring:    keyring,
+		cfg:        cfg,
+		logger:     logger,
+		stopChan:   make(chan struct{}),
+		now:        func() time.Time { return time.Now().UTC() },
+		batchSize:  DataExportBatchSize,
+	}
+}
+
+// Start starts the data export service
+func (s *DataExportService) Start(ctx context.Context) error {
+	s.logger.Info("Starting Data Export Service")
+
+	s.ticker = time.NewTicker(DataExportInterval)
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the data export service
+func (s *DataExportService) Stop() error {
+	s.logger.Info("Stopping Data Export Service")
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+
+	close(s.stopChan)
+	return nil
+}
+
+// run runs the export loop
+func (s *DataExportService) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-s.ticker.C:
+			s.schedule(ctx)
+			if err := s.runPending(ctx); err != nil {
+				s.logger.WithError(err).Error("Data export failed")
+			}
+			if err := s.expire(ctx); err != nil {
+				s.logger.WithError(err).Error("Failed to delete expired data exports")
+			}
+		}
+	}
+}
+
+// schedule creates the jobs of the schedules that are due. The repository
+// logs failures; one failing schedule does not hold up the others.
+func (s *DataExportService) schedule(ctx context.Context) {
+	for _, schedule := range s.cfg.Schedules {
+		created, err := s.exportRepo.ScheduleJob(ctx, schedule, s.now())
+		if err == nil && created {
+			s.logger.WithFields(logrus.Fields{
+				"schedule": schedule.Name,
+				"project":  schedule.Project,
+			}).Info("Scheduled data export")
+		}
+	}
+}
+
+// runPending runs the claimable jobs one after another until none is left
+func (s *DataExportService) runPending(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := s.exportRepo.ClaimJob(ctx, s.now().Add(-DataExportStaleAfter))
+		if err != nil {
+			return fmt.Errorf("failed to claim data export job: %w", err)
+		}
+		if job == nil {
+			return nil
+		}
+		if err := s.runJob(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runJob writes a job's file and records its outcome. Errors of the export
+// fail the job; the returned error is one recording it.
+func (s *DataExportService) runJob(ctx context.Context, job *database.DataExportJob) error {
+	logger := s.logger.WithFields(logrus.Fields{"job_id": job.ID, "project": job.Project})
+	logger.Info("Exporting data")
+
+	result := database.FinishDataExportJobParams{ID: job.ID, Status: sharedmodels.ExportStatusCompleted}
+	artifact := exports.FileName(job.ID.String(), job.Format)
+	records, size, err := s.export(ctx, job, artifact)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the job running to be claimed again once stale
+			return nil
+		}
+		logger.WithError(err).Error("Data export job failed")
+		result.Status, result.Error = sharedmodels.ExportStatusFailed, err.Error()
+	} else {
+		result.Artifact = artifact
+		result.ExpiresAt.Time, result.ExpiresAt.Valid = s.now().Add(s.cfg.Retention), true
+	}
+	result.RecordCount, result.SizeBytes = records, size
+	if err := s.exportRepo.FinishJob(ctx, result); err != nil {
+		return fmt.Errorf("failed to finish data export job: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"status":  result.Status,
+		"records": records,
+		"bytes":   size,
+	}).Info("Data export job finished")
+	return nil
+}
+
+// export writes a job's records to a temporary file, uploads it to the
+// store as artifact once complete, so a link never serves a partial export,
+// and returns the number of records and the size of the file
+func (s *DataExportService) export(ctx context.Context, job *database.DataExportJob, artifact string) (int64, int64, error) {
+	file, err := os.CreateTemp("", "data-export-*")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	records, err := s.write(ctx, job, file)
+	if err != nil {
+		return records, 0, err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return records, 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return records, 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := s.store.Put(ctx, artifact, exports.ContentType(job.Format), file, size); err != nil {
+		return records, 0, fmt.Errorf("failed to store export file: %w", err)
+	}
+	return records, size, nil
+}
+
+// write writes a job's records to file in batches, recording the records
+// written after each batch, which also keeps the job from going stale
+func (s *DataExportService) write(ctx context.Context, job *database.DataExportJob, file *os.File) (int64, error) {
+	writer, err := exports.NewWriter(file, job.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	var records int64
+	var afterCreatedAt time.Time
+	var afterID uuid.UUID
+	for {
+		rows, err := s.exportRepo.ListRecords(ctx, job, afterCreatedAt, afterID, s.batchSize)
+		if err != nil {
+			return records, fmt.Errorf("failed to list parsed data: %w", err)
+		}
+		for _, row := range rows {
+			record, err := s.record(row)
+			if err != nil {
+				return records, fmt.Errorf("record %s: %w", row.ID, err)
+			}
+			if err := writer.Write(record); err != nil {
+				return records, fmt.Errorf("failed to write export file: %w", err)
+			}
+			records++
+		}
+		if err := writer.Flush(); err != nil {
+			return records, fmt.Errorf("failed to write export file: %w", err)
+		}
+		if err := s.exportRepo.RecordProgress(ctx, job.ID, records); err != nil {
+			return records, fmt.Errorf("failed to record data export progress: %w", err)
+		}
+		if len(rows) < s.batchSize {
+			return records, nil
+		}
+		last := rows[len(rows)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// record converts a parsed record into its export form, decrypting its
+// sensitive fields
+func (s *DataExportService) record(row database.ListParsedDataForExportRow) (exports.Record, error) {
+	data := row.Data
+	if row.EncryptionKeyID != "" {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return exports.Record{}, err
+		}
+		if err := s.keyring.OpenFields(fields); err != nil {
+			return exports.Record{}, err
+		}
+		decrypted, err := json.Marshal(fields)
+		if err != nil {
+			return exports.Record{}, err
+		}
+		data = decrypted
+	}
+	return exports.Record{
+		ID:          row.ID.String(),
+		URLID:       row.UrlID.String(),
+		URL:         row.Url,
+		Schema:      row.Schema,
+		Title:       row.Title,
+		Content:     row.Content,
+		Data:        data,
+		ContentHash: row.ContentHash,
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
+
+// expire deletes the files of expired exports and marks their jobs expired
+func (s *DataExportService) expire(ctx context.Context) error {
+	jobs, err := s.exportRepo.ListExpiredJobs(ctx, s.now(), DataExportExpireBatch)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := s.store.Delete(ctx, job.Artifact); err != nil {
+			return fmt.Errorf("failed to delete export file %s: %w", job.Artifact, err)
+		}
+		if err := s.exportRepo.ExpireJob(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+	if len(jobs) > 0 {
+		s.logger.WithField("exports", len(jobs)).Info("Deleted expired data exports")
+	}
+	return nil
+}
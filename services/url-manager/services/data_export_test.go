@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/exports"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// fakeExportRepository serves queued jobs and pages through fixed records
+type fakeExportRepository struct {
+	jobs      []*database.DataExportJob
+	records   []database.ListParsedDataForExportRow
+	listErr   error
+	scheduled []string
+	progress  []int64
+	finished  database.FinishDataExportJobParams
+	expired   []database.DataExportJob
+	expiredID []uuid.UUID
+}
+
+func (f *fakeExportRepository) ScheduleJob(ctx context.Context, schedule config.ExportScheduleConfig, now time.Time) (bool, error) {
+	f.scheduled = append(f.scheduled, schedule.Name)
+	return true, nil
+}
+
+func (f *fakeExportRepository) ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataExportJob, error) {
+	if len(f.jobs) == 0 {
+		return nil, nil
+	}
+	job := f.jobs[0]
+	f.jobs = f.jobs[1:]
+	return job, nil
+}
+
+func (f *fakeExportRepository) ListRecords(ctx context.Context, job *database.DataExportJob, afterCreatedAt time.Time, afterID uuid.UUID, batchSize int) ([]database.ListParsedDataForExportRow, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	start := 0
+	if !afterCreatedAt.IsZero() {
+		for i, record := range f.records {
+			if record.ID == afterID {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+batchSize, len(f.records))
+	return f.records[start:end], nil
+}
+
+func (f *fakeExportRepository) RecordProgress(ctx context.Context, id uuid.UUID, records int64) error {
+	f.progress = append(f.progress, records)
+	return nil
+}
+
+func (f *fakeExportRepository) FinishJob(ctx context.Context, arg database.FinishDataExportJobParams) error {
+	f.finished = arg
+	return nil
+}
+
+func (f *fakeExportRepository) ListExpiredJobs(ctx context.Context, now time.Time, limit int) ([]database.DataExportJob, error) {
+	return f.expired, nil
+}
+
+func (f *fakeExportRepository) ExpireJob(ctx context.Context, id uuid.UUID) error {
+	f.expiredID = append(f.expiredID, id)
+	return nil
+}
+
+func newTestExports(t *testing.T, repo *fakeExportRepository) *DataExportService {
+	t.Helper()
+	cfg := config.ExportsConfig{Directory: t.TempDir(), SigningKey: "k", LinkTTL: time.Minute, Retention: time.Hour}
+	store := exports.NewDirStore(cfg.Directory, exports.NewSigner(cfg.SigningKey), "")
+	dataExports := NewDataExportService(repo, store, nil, cfg, newTestLogger())
+	dataExports.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	dataExports.batchSize = 2
+	return dataExports
+}
+
+func TestDataExportWritesJobsInBatches(t *testing.T) {
+	job := &database.DataExportJob{ID: uuid.New(), Project: "crm", Format: config.ExportFormatJSONL}
+	repo := &fakeExportRepository{jobs: []*database.DataExportJob{job}}
+	for i := 0; i < 3; i++ {
+		repo.records = append(repo.records, database.ListParsedDataForExportRow{
+			ID:        uuid.New(),
+			Url:       "https://example.com",
+			Data:      json.RawMessage(`{"n":1}`),
+			CreatedAt: time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC),
+		})
+	}
+	dataExports := newTestExports(t, repo)
+
+	if err := dataExports.runPending(context.Background()); err != nil {
+		t.Fatalf("runPending() error = %v", err)
+	}
+
+	got := repo.finished
+	if got.Status != sharedmodels.ExportStatusCompleted || got.RecordCount != 3 || got.Artifact != job.ID.String()+".jsonl" {
+		t.Fatalf("job finished as %+v", got)
+	}
+	if want := dataExports.now().Add(time.Hour); !got.ExpiresAt.Valid || !got.ExpiresAt.Time.Equal(want) {
+		t.Errorf("expires_at = %v, want %v", got.ExpiresAt, want)
+	}
+	if len(repo.progress) != 2 || repo.progress[1] != 3 {
+		t.Errorf("recorded progress = %v, want [2 3]", repo.progress)
+	}
+	content, err := os.ReadFile(filepath.Join(dataExports.cfg.Directory, got.Artifact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 3 || int64(len(content)) != got.SizeBytes {
+		t.Errorf("export file has %d lines and %d bytes, want 3 lines and %d bytes", lines, len(content), got.SizeBytes)
+	}
+	if _, err := os.Stat(filepath.Join(dataExports.cfg.Directory, got.Artifact+".tmp")); !os.IsNotExist(err) {
+		t.Error("temporary export file was left behind")
+	}
+}
+
+func TestDataExportFailsJobOnError(t *testing.T) {
+	job := &database.DataExportJob{ID: uuid.New(), Project: "crm", Format: config.ExportFormatCSV}
+	repo := &fakeExportRepository{jobs: []*database.DataExportJob{job}, listErr: errors.New("connection reset")}
+	dataExports := newTestExports(t, repo)
+
+	if err := dataExports.runPending(context.Background()); err != nil {
+		t.Fatalf("runPending() error = %v", err)
+	}
+	if got := repo.finished; got.Status != sharedmodels.ExportStatusFailed || got.Error != "failed to list parsed data: connection reset" || got.Artifact != "" {
+		t.Errorf("job finished as %+v, want failed with the list error", got)
+	}
+	if entries, _ := os.ReadDir(dataExports.cfg.Directory); len(entries) != 0 {
+		t.Errorf("failed export left %d files", len(entries))
+	}
+}
+
+func TestDataExportSchedulesAndExpires(t *testing.T) {
+	expired := database.DataExportJob{ID: uuid.New(), Artifact: "old.csv"}
+	missing := database.DataExportJob{ID: uuid.New(), Artifact: "gone.csv"}
+	repo := &fakeExportRepository{expired: []database.DataExportJob{expired, missing}}
+	dataExports := newTestExports(t, repo)
+	dataExports.cfg.Schedules = []config.ExportScheduleConfig{{Name: "crm-daily"}, {Name: "leads-hourly"}}
+
+	dataExports.schedule(context.Background())
+	if len(repo.scheduled) != 2 {
+		t.Errorf("scheduled = %v, want both schedules", repo.scheduled)
+	}
+
+	path := filepath.Join(dataExports.cfg.Directory, expired.Artifact)
+	if err := os.WriteFile(path, []byte("id\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataExports.expire(context.Background()); err != nil {
+		t.Fatalf("expire() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expired export file was not deleted")
+	}
+	if len(repo.expiredID) != 2 {
+		t.Errorf("expired jobs = %v, want both, including the one whose file is gone", repo.expiredID)
+	}
+}
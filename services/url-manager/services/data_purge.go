@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Data purge settings
+const (
+	DataPurgeInterval  = 10 * time.Second
+	DataPurgeBatchSize = 1000
+	// A running job not updated for this long stopped with its process and is claimed again
+	DataPurgeStaleAfter = 5 * time.Minute
+)
+
+// DataPurgeService runs the data purge jobs requested through the API
+// Gateway: it claims pending jobs and hard-deletes the raw HTML snapshots,
+// render sessions, HAR captures and parsed data matching their filters in
+// batches, recording the rows deleted as it goes. Deletes are idempotent,
+// so a job interrupted by a restart is simply run again.
+type DataPurgeService struct {
+	purgeRepo repositories.PurgeRepository
+	logger    *logrus.Logger
+	ticker    *time.Ticker
+	stopChan  chan struct{}
+	now       func() time.Time
+	batchSize int
+}
+
+// NewDataPurgeService creates a new data purge service
+func NewDataPurgeService(purgeRepo repositories.PurgeRepository, logger *logrus.Logger) *DataPurgeService {
+	return &DataPurgeService{
+		purgeRepo: purgeRepo,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+		now:       func() time.Time { return time.Now().UTC() },
+		batchSize: DataPurgeBatchSize,
+	}
+}
+
+// Start starts the data purge service
+func (s *DataPurgeService) Start(ctx context.Context) error {
+	s.logger.Info("Starting Data Purge Service")
+
+	s.ticker = time.NewTicker(DataPurgeInterval)
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the data purge service
+func (s *DataPurgeService) Stop() error {
+	s.logger.Info("Stopping Data Purge Service")
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+
+	close(s.stopChan)
+	return nil
+}
+
+// run runs the purge loop
+func (s *DataPurgeService) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-s.ticker.C:
+			if err := s.runPending(ctx); err != nil {
+				s.logger.WithError(err).Error("Data purge failed")
+			}
+		}
+	}
+}
+
+// runPending runs the claimable jobs one after another until none is left
+func (s *DataPurgeService) runPending(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := s.purgeRepo.ClaimJob(ctx, s.now().Add(-DataPurgeStaleAfter))
+		if err != nil {
+			return fmt.Errorf("failed to claim data purge job: %w", err)
+		}
+		if job == nil {
+			return nil
+		}
+		if err := s.runJob(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runJob purges a job's data and records its outcome. Errors of the purge
+// fail the job; the returned error is one recording it.
+func (s *DataPurgeService) runJob(ctx context.Context, job *database.DataPurgeJob) error {
+	logger := s.logger.WithField("job_id", job.ID)
+	logger.Info("Purging data")
+
+	status, message := sharedmodels.PurgeStatusCompleted, ""
+	if err := s.purge(ctx, job); err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the job running to be claimed again once stale
+			return nil
+		}
+		logger.WithError(err).Error("Data purge job failed")
+		status, message = sharedmodels.PurgeStatusFailed, err.Error()
+	}
+	if err := s.purgeRepo.FinishJob(ctx, job.ID, status, message); err != nil {
+		return fmt.Errorf("failed to finish data purge job: %w", err)
+	}
+	logger.WithField("status", status).Info("Data purge job finished")
+	return nil
+}
+
+// purge deletes a job's data. Raw HTML and HAR captures go first, since a
+// schema filter selects them by the URLs' parsed data.
+func (s *DataPurgeService) purge(ctx context.Context, job *database.DataPurgeJob) error {
+	for {
+		deleted, err := s.purgeRepo.PurgeRawHTML(ctx, job, s.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to purge raw HTML: %w", err)
+		}
+		if err := s.record(ctx, database.RecordDataPurgeProgressParams{ID: job.ID, RawHtmlDeleted: deleted}); err != nil {
+			return err
+		}
+		if deleted < int64(s.batchSize) {
+			break
+		}
+	}
+
+	deleted, err := s.purgeRepo.PurgeHARCaptures(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to purge HAR captures: %w", err)
+	}
+	if err := s.record(ctx, database.RecordDataPurgeProgressParams{ID: job.ID, HarCapturesDeleted: deleted}); err != nil {
+		return err
+	}
+
+	for {
+		deleted, err := s.purgeRepo.PurgeParsedData(ctx, job, s.batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to purge parsed data: %w", err)
+		}
+		if err := s.record(ctx, database.RecordDataPurgeProgressParams{ID: job.ID, ParsedDataDeleted: deleted}); err != nil {
+			return err
+		}
+		if deleted < int64(s.batchSize) {
+			return nil
+		}
+	}
+}
+
+// record adds a batch's deletes to the job, which also keeps it from
+// going stale
+func (s *DataPurgeService) record(ctx context.Context, progress database.RecordDataPurgeProgressParams) error {
+	if err := s.purgeRepo.RecordProgress(ctx, progress); err != nil {
+		return fmt.Errorf("failed to record data purge progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+)
+
+// fakePurgeRepository serves queued jobs and deletes from fixed row counts
+type fakePurgeRepository struct {
+	jobs                     []*database.DataPurgeJob
+	staleBefore              time.Time
+	rawHTML, har, parsedData int64
+	parsedDataErr            error
+	calls                    []string
+	progress                 database.RecordDataPurgeProgressParams
+	status, message          string
+}
+
+func (f *fakePurgeRepository) ClaimJob(ctx context.Context, staleBefore time.Time) (*database.DataPurgeJob, error) {
+	f.staleBefore = staleBefore
+	if len(f.jobs) == 0 {
+		return nil, nil
+	}
+	job := f.jobs[0]
+	f.jobs = f.jobs[1:]
+	return job, nil
+}
+
+// take deletes up to limit of the remaining rows
+func take(remaining *int64, limit int64) int64 {
+	n := *remaining
+	if n > limit {
+		n = limit
+	}
+	*remaining -= n
+	return n
+}
+
+func (f *fakePurgeRepository) PurgeRawHTML(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error) {
+	f.calls = append(f.calls, "raw_html")
+	return take(&f.rawHTML, int64(batchSize)), nil
+}
+
+func (f *fakePurgeRepository) PurgeHARCaptures(ctx context.Context, job *database.DataPurgeJob) (int64, error) {
+	f.calls = append(f.calls, "har")
+	return take(&f.har, f.har), nil
+}
+
+func (f *fakePurgeRepository) PurgeParsedData(ctx context.Context, job *database.DataPurgeJob, batchSize int) (int64, error) {
+	f.calls = append(f.calls, "parsed_data")
+	if f.parsedDataErr != nil {
+		return 0, f.parsedDataErr
+	}
+	return take(&f.parsedData, int64(batchSize)), nil
+}
+
+func (f *fakePurgeRepository) RecordProgress(ctx context.Context, arg database.RecordDataPurgeProgressParams) error {
+	f.progress.ParsedDataDeleted += arg.ParsedDataDeleted
+	f.progress.RawHtmlDeleted += arg.RawHtmlDeleted
+	f.progress.HarCapturesDeleted += arg.HarCapturesDeleted
+	return nil
+}
+
+func (f *fakePurgeRepository) FinishJob(ctx context.Context, id uuid.UUID, status, message string) error {
+	f.status, f.message = status, message
+	return nil
+}
+
+func TestDataPurgeRunsJobsInBatches(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakePurgeRepository{
+		jobs:    []*database.DataPurgeJob{{ID: uuid.New(), Schema: "article"}},
+		rawHTML: 5, har: 1, parsedData: 4,
+	}
+	purges := NewDataPurgeService(repo, newTestLogger())
+	purges.now = func() time.Time { return now }
+	purges.batchSize = 2
+
+	if err := purges.runPending(context.Background()); err != nil {
+		t.Fatalf("runPending() error = %v", err)
+	}
+
+	if want := now.Add(-DataPurgeStaleAfter); !repo.staleBefore.Equal(want) {
+		t.Errorf("stale cutoff = %v, want %v", repo.staleBefore, want)
+	}
+	want := []string{"raw_html", "raw_html", "raw_html", "har", "parsed_data", "parsed_data", "parsed_data"}
+	if len(repo.calls) != len(want) {
+		t.Fatalf("purge calls = %v, want %v", repo.calls, want)
+	}
+	for i := range want {
+		if repo.calls[i] != want[i] {
+			t.Fatalf("purge calls = %v, want %v", repo.calls, want)
+		}
+	}
+	if p := repo.progress; p.RawHtmlDeleted != 5 || p.HarCapturesDeleted != 1 || p.ParsedDataDeleted != 4 {
+		t.Errorf("recorded progress = %+v, want 5 raw HTML, 1 HAR and 4 parsed records", p)
+	}
+	if repo.status != sharedmodels.PurgeStatusCompleted || repo.message != "" {
+		t.Errorf("job finished as %q (%q), want completed", repo.status, repo.message)
+	}
+}
+
+func TestDataPurgeFailsJobOnError(t *testing.T) {
+	repo := &fakePurgeRepository{
+		jobs:          []*database.DataPurgeJob{{ID: uuid.New()}},
+		rawHTML:       1,
+		parsedDataErr: errors.New("connection reset"),
+	}
+	purges := NewDataPurgeService(repo, newTestLogger())
+
+	if err := purges.runPending(context.Background()); err != nil {
+		t.Fatalf("runPending() error = %v", err)
+	}
+	if repo.status != sharedmodels.PurgeStatusFailed || repo.message != "failed to purge parsed data: connection reset" {
+		t.Errorf("job finished as %q (%q), want failed with the purge error", repo.status, repo.message)
+	}
+	if repo.progress.RawHtmlDeleted != 1 {
+		t.Errorf("raw HTML deleted = %d, want the rows deleted before the failure", repo.progress.RawHtmlDeleted)
+	}
+}
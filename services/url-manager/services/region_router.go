@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRegionHealthTimeout is the heartbeat age after which a region's
+// scrapers are considered gone, used when none is configured
+const DefaultRegionHealthTimeout = 45 * time.Second
+
+// regionFallbacks converts configured region fallbacks. Invalid entries are
+// logged and skipped.
+func regionFallbacks(configs map[string]string, logger *logrus.Logger) map[string]string {
+	fallbacks := make(map[string]string, len(configs))
+	for region, fallback := range configs {
+		if region == "" || !sharedmodels.ValidRegion(region) || !sharedmodels.ValidRegion(fallback) {
+			logger.WithFields(logrus.Fields{"region": region, "fallback": fallback}).Warn("Ignoring invalid region fallback")
+			continue
+		}
+		fallbacks[region] = fallback
+	}
+	return fallbacks
+}
+
+// regionRouter picks the region whose topic receives a URL's scraping task
+// during one scheduling pass. The healthy regions are loaded from the worker
+// heartbeats the first time a URL with a region is routed.
+type regionRouter struct {
+	repo      repositories.WorkerRepository
+	since     time.Time // Heartbeats before this are stale
+	fallbacks map[string]string
+	logger    *logrus.Logger
+	healthy   map[string]bool
+	warned    map[string]bool // Regions whose fallback was logged in this pass
+}
+
+// newRegionRouter creates a router for a pass starting at now
+func newRegionRouter(repo repositories.WorkerRepository, fallbacks map[string]string, timeout time.Duration, now time.Time, logger *logrus.Logger) *regionRouter {
+	return &regionRouter{
+		repo:      repo,
+		since:     now.Add(-timeout),
+		fallbacks: fallbacks,
+		logger:    logger,
+		warned:    make(map[string]bool),
+	}
+}
+
+// route returns the region to send a task for the requested region to: the
+// region itself while it has a healthy scraper, otherwise the first healthy
+// region along its fallbacks, or "" for the shared topic.
+func (r *regionRouter) route(ctx context.Context, region string) (string, error) {
+	if region == "" {
+		return "", nil
+	}
+	if r.healthy == nil {
+		regions, err := r.repo.ListHealthyRegions(ctx, r.since)
+		if err != nil {
+			return "", fmt.Errorf("failed to list healthy regions: %w", err)
+		}
+		r.healthy = make(map[string]bool, len(regions))
+		for _, healthy := range regions {
+			r.healthy[healthy] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	for candidate := region; candidate != "" && !seen[candidate]; candidate = r.fallbacks[candidate] {
+		seen[candidate] = true
+		if r.healthy[candidate] {
+			if candidate != region {
+				r.warnFallback(region, candidate)
+			}
+			return candidate, nil
+		}
+	}
+	r.warnFallback(region, "")
+	return "", nil
+}
+
+// warnFallback logs, once per pass and region, that tasks are rerouted
+func (r *regionRouter) warnFallback(region, fallback string) {
+	if r.warned[region] {
+		return
+	}
+	r.warned[region] = true
+	if fallback == "" {
+		r.logger.WithField("region", region).Warn("No healthy scrapers in region, sending its tasks to the shared topic")
+		return
+	}
+	r.logger.WithFields(logrus.Fields{"region": region, "fallback": fallback}).Warn("No healthy scrapers in region, sending its tasks to the fallback region")
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Scrape budget scopes, as recorded in budget-exhausted events
+const (
+	BudgetScopeDomain  = "domain"
+	BudgetScopeProject = "project"
+)
+
+// scrapeBudget is a daily limit on the scrapes of a domain's or a project's URLs
+type scrapeBudget struct {
+	scope string
+	name  string
+	limit int
+}
+
+// scrapeBudgets converts configured budgets. Invalid budgets are logged and skipped.
+func scrapeBudgets(configs []config.ScrapeBudgetConfig, logger *logrus.Logger) []scrapeBudget {
+	var budgets []scrapeBudget
+	for i, cfg := range configs {
+		budget, err := newScrapeBudget(cfg)
+		if err != nil {
+			logger.WithError(err).WithField("budget", i).Warn("Ignoring invalid scrape budget")
+			continue
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets
+}
+
+// newScrapeBudget validates a configured budget
+func newScrapeBudget(cfg config.ScrapeBudgetConfig) (scrapeBudget, error) {
+	domain := strings.Trim(strings.ToLower(strings.TrimSpace(cfg.Domain)), ".")
+	project := strings.TrimSpace(cfg.Project)
+	if cfg.DailyLimit < 0 {
+		return scrapeBudget{}, fmt.Errorf("daily_limit must not be negative")
+	}
+
+	switch {
+	case domain != "" && project != "":
+		return scrapeBudget{}, fmt.Errorf("a budget applies to either a domain or a project, not both")
+	case domain != "":
+		if strings.ContainsAny(domain, "/:?# ") {
+			return scrapeBudget{}, fmt.Errorf("domain must be a host name, got %q", cfg.Domain)
+		}
+		return scrapeBudget{scope: BudgetScopeDomain, name: domain, limit: cfg.DailyLimit}, nil
+	case project != "":
+		if !sharedmodels.ValidProject(project) {
+			return scrapeBudget{}, fmt.Errorf("invalid project %q", project)
+		}
+		return scrapeBudget{scope: BudgetScopeProject, name: project, limit: cfg.DailyLimit}, nil
+	default:
+		return scrapeBudget{}, fmt.Errorf("domain or project is required")
+	}
+}
+
+// covers reports whether the budget applies to the URL
+func (b scrapeBudget) covers(u database.Url) bool {
+	if b.scope == BudgetScopeProject {
+		return u.Project == b.name
+	}
+	parsed, err := url.Parse(u.Url)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == b.name || strings.HasSuffix(host, "."+b.name)
+}
+
+// budgetTracker tracks the use of the scrape budgets during one scheduling
+// pass. The scrapes published earlier in the day are counted from the
+// database the first time a budget is checked; scrapes published during the
+// pass are added as they are recorded.
+type budgetTracker struct {
+	repo    repositories.BudgetRepository
+	budgets []scrapeBudget
+	day     time.Time // Start of the current UTC day
+	used    map[scrapeBudget]int64
+}
+
+// newBudgetTracker creates a tracker for the budgets on the day of now
+func newBudgetTracker(repo repositories.BudgetRepository, budgets []scrapeBudget, now time.Time) *budgetTracker {
+	return &budgetTracker{
+		repo:    repo,
+		budgets: budgets,
+		day:     now.UTC().Truncate(24 * time.Hour),
+		used:    make(map[scrapeBudget]int64, len(budgets)),
+	}
+}
+
+// nextDay returns the start of the following UTC day, when budgets reset
+func (t *budgetTracker) nextDay() time.Time {
+	return t.day.Add(24 * time.Hour)
+}
+
+// exhausted returns the first budget covering the URL that is used up, or
+// nil if the URL may be scraped
+func (t *budgetTracker) exhausted(ctx context.Context, u database.Url) (*scrapeBudget, error) {
+	for i := range t.budgets {
+		budget := &t.budgets[i]
+		if !budget.covers(u) {
+			continue
+		}
+		used, err := t.usage(ctx, *budget)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(budget.limit) {
+			return budget, nil
+		}
+	}
+	return nil, nil
+}
+
+// record counts a published scrape against the budgets covering the URL
+func (t *budgetTracker) record(u database.Url) {
+	for _, budget := range t.budgets {
+		if _, counted := t.used[budget]; counted && budget.covers(u) {
+			t.used[budget]++
+		}
+	}
+}
+
+// usage returns the scrapes published today for the budget
+func (t *budgetTracker) usage(ctx context.Context, budget scrapeBudget) (int64, error) {
+	if used, ok := t.used[budget]; ok {
+		return used, nil
+	}
+
+	var used int64
+	var err error
+	if budget.scope == BudgetScopeProject {
+		used, err = t.repo.CountProjectScrapes(ctx, budget.name, t.day)
+	} else {
+		used, err = t.repo.CountDomainScrapes(ctx, budget.name, t.day)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count scrapes for %s budget %s: %w", budget.scope, budget.name, err)
+	}
+	t.used[budget] = used
+	return used, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+)
+
+func TestScrapeBudgetCovers(t *testing.T) {
+	domain, err := newScrapeBudget(config.ScrapeBudgetConfig{Domain: " Example.COM. ", DailyLimit: 100})
+	if err != nil {
+		t.Fatalf("newScrapeBudget() error = %v", err)
+	}
+	project, err := newScrapeBudget(config.ScrapeBudgetConfig{Project: "growth", DailyLimit: 100})
+	if err != nil {
+		t.Fatalf("newScrapeBudget() error = %v", err)
+	}
+
+	tests := []struct {
+		budget scrapeBudget
+		url    database.Url
+		want   bool
+	}{
+		{domain, database.Url{Url: "https://example.com/a"}, true},
+		{domain, database.Url{Url: "https://WWW.Example.com:8443/a"}, true},
+		{domain, database.Url{Url: "https://notexample.com/a"}, false},
+		{domain, database.Url{Url: "https://example.com.evil.org/a"}, false},
+		{project, database.Url{Url: "https://example.com/a", Project: "growth"}, true},
+		{project, database.Url{Url: "https://example.com/a"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.budget.covers(tt.url); got != tt.want {
+			t.Errorf("%s budget %s covers %s (project %q) = %v, want %v", tt.budget.scope, tt.budget.name, tt.url.Url, tt.url.Project, got, tt.want)
+		}
+	}
+}
+
+func TestNewScrapeBudgetRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []config.ScrapeBudgetConfig{
+		{DailyLimit: 10},
+		{Domain: "example.com", Project: "growth", DailyLimit: 10},
+		{Domain: "https://example.com", DailyLimit: 10},
+		{Project: "Growth Team", DailyLimit: 10},
+		{Domain: "example.com", DailyLimit: -1},
+	} {
+		if _, err := newScrapeBudget(cfg); err == nil {
+			t.Errorf("newScrapeBudget(%+v) expected an error", cfg)
+		}
+	}
+}
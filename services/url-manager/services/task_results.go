@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/tracing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// DefaultMoveThreshold is how many scrapes in a row must be redirected
+// permanently to the same location before a URL is flagged as moved
+const DefaultMoveThreshold = 3
+
+// DefaultHARRetention is how long a captured HAR is kept when
+// scraping.har.retention is not set
+const DefaultHARRetention = 24 * time.Hour
+
+// TaskResultService records scraping task results and decides, from the
+// failure class and the URL's retry policy, whether a failed URL is retried.
+// It also tracks URLs whose scrapes are redirected permanently.
+type TaskResultService struct {
+	urlRepo  repositories.URLRepository
+	taskRepo repositories.TaskRepository
+	logger   *logrus.Logger
+	now      func() time.Time
+
+	// Settings that can change at runtime, see Configure, SetCaptureHeaders
+	// and SetHARRetention
+	mu             sync.Mutex
+	redirects      config.RedirectsConfig
+	captureHeaders []string
+	harRetention   time.Duration
+}
+
+// NewTaskResultService creates a new task result service
+func NewTaskResultService(
+	urlRepo repositories.URLRepository,
+	taskRepo repositories.TaskRepository,
+	logger *logrus.Logger,
+) *TaskResultService {
+	return &TaskResultService{
+		urlRepo:  urlRepo,
+		taskRepo: taskRepo,
+		logger:   logger,
+		now:      func() time.Time { return time.Now().UTC() },
+		redirects: config.RedirectsConfig{
+			MoveThreshold: DefaultMoveThreshold,
+		},
+		captureHeaders: sharedmodels.DefaultCaptureHeaders,
+		harRetention:   DefaultHARRetention,
+	}
+}
+
+// Configure applies the settings for URLs that moved. It is safe to call
+// while results are being recorded.
+func (s *TaskResultService) Configure(cfg config.RedirectsConfig) {
+	if cfg.MoveThreshold <= 0 {
+		cfg.MoveThreshold = DefaultMoveThreshold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redirects = cfg
+}
+
+// SetCaptureHeaders sets the response headers stored with each scrape
+// (scraping.capture_headers); other headers a scraper reports are dropped.
+// An empty list keeps sharedmodels.DefaultCaptureHeaders.
+func (s *TaskResultService) SetCaptureHeaders(names []string) {
+	if len(names) == 0 {
+		names = sharedmodels.DefaultCaptureHeaders
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.captureHeaders = names
+}
+
+// SetHARRetention sets how long captured HARs are kept
+// (scraping.har.retention); zero keeps DefaultHARRetention
+func (s *TaskResultService) SetHARRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultHARRetention
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.harRetention = retention
+}
+
+// HandleMessage is a kafka.MessageHandler for scrape result messages
+func (s *TaskResultService) HandleMessage(ctx context.Context, message *sharedmodels.KafkaMessage) error {
+	result, err := decodeScrapeResult(message)
+	if err != nil {
+		return err
+	}
+	return s.RecordResult(ctx, result)
+}
+
+// decodeScrapeResult decodes the scrape result carried by a message, whose
+// data the consumer decoded as generic JSON
+func decodeScrapeResult(message *sharedmodels.KafkaMessage) (sharedmodels.ScrapeResult, error) {
+	var result sharedmodels.ScrapeResult
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return result, fmt.Errorf("failed to encode scrape result: %w", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("failed to decode scrape result: %w", err)
+	}
+	return result, nil
+}
+
+// RecordResult stores the outcome of a scraping task and updates the URL.
+// A failure without a known error code is classified from its status code.
+// Retryable failures put the URL in retry status with its next scrape after
+// the policy's backoff; other failures, and failures after the last allowed
+// attempt, mark the URL as failed. A successful scrape returns a failed,
+// retrying or degraded URL to pending. A scrape that fetched the page but
+// failed the URL's assertions is soft-failed: it is recorded as a failure
+// but not retried, and the URL stays on its regular schedule. A scrape the
+// site throttled (429, or 503 with Retry-After) is not a failure of the URL:
+// it is rescheduled after the site's Retry-After, or the policy's backoff if
+// longer, without using up a retry or changing the URL's status. Fetched
+// pages are checked for a permanent move, see trackMove, and the HAR of a
+// captured scrape is stored whatever its outcome, see saveHAR.
+func (s *TaskResultService) RecordResult(ctx context.Context, result sharedmodels.ScrapeResult) error {
+	// Results may be redelivered; a task is only completed once
+	task, err := s.taskRepo.GetTask(ctx, result.TaskID)
+	switch {
+	case err == nil && task.CompletedAt.Valid:
+		s.logger.WithField("task_id", result.TaskID).Debug("Ignoring result for completed task")
+		return nil
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to get scraping task: %w", err)
+	}
+
+	url, err := s.urlRepo.GetURLByID(ctx, result.URLID)
+	if err != nil {
+		return fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	completedAt := result.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = s.now()
+	}
+
+	if result.HAR != nil {
+		s.saveHAR(ctx, result)
+	}
+	if result.Success {
+		s.trackMove(ctx, url, result, completedAt)
+	}
+	if result.Success && len(result.AssertionFailures) > 0 {
+		return s.recordSoftFailure(ctx, url, result, completedAt)
+	}
+
+	if result.Success {
+		if err := s.completeTask(ctx, result, TaskStatusSuccess, "", completedAt); err != nil {
+			return err
+		}
+		if url.RetryCount > 0 {
+			if err := s.urlRepo.ResetRetryCount(ctx, url.ID); err != nil {
+				return err
+			}
+		}
+		if url.Status != URLStatusPending && url.Status != URLStatusPaused {
+			return s.urlRepo.UpdateURLStatus(ctx, url.ID, URLStatusPending)
+		}
+		return nil
+	}
+
+	code := result.ErrorCode
+	if !code.Valid() {
+		code = sharedmodels.ClassifyFailure(nil, result.StatusCode)
+		if code == "" {
+			code = sharedmodels.ErrorCodeUnknown
+		}
+	}
+
+	attempt := result.Attempt
+	if attempt < 1 {
+		attempt = int(url.RetryCount) + 1
+	}
+	failure := sharedmodels.ScrapeFailure{
+		Code:       code,
+		StatusCode: result.StatusCode,
+		RetryAfter: time.Duration(result.RetryAfterMs) * time.Millisecond,
+	}
+	policy := effectiveRetryPolicy(*url, s.logger)
+	// Keep the trace of the failed scrape when tracing keeps errors
+	tracing.RecordError(ctx, fmt.Errorf("scrape failed with %s", code))
+
+	fields := logrus.Fields{
+		"task_id":     result.TaskID,
+		"url_id":      url.ID,
+		"attempt":     attempt,
+		"error_code":  code,
+		"status_code": result.StatusCode,
+	}
+
+	if failure.Throttled() {
+		if err := s.completeTask(ctx, result, TaskStatusThrottled, code, completedAt); err != nil {
+			return err
+		}
+		nextScrape := s.now().Add(policy.ThrottleDelay(attempt, failure.RetryAfter))
+		s.logger.WithFields(fields).WithField("next_scrape_at", nextScrape.Format(time.RFC3339)).Info("Scrape throttled by the site, rescheduled")
+		return s.urlRepo.UpdateNextScrapeTime(ctx, url.ID, nextScrape)
+	}
+
+	delay, retry := policy.NextRetry(attempt, failure)
+
+	if !retry {
+		if err := s.completeTask(ctx, result, TaskStatusFailed, code, completedAt); err != nil {
+			return err
+		}
+		s.logger.WithFields(fields).Warn("Scrape failed, not retrying")
+		if err := s.urlRepo.ResetRetryCount(ctx, url.ID); err != nil {
+			return err
+		}
+		return s.updateFailedStatus(ctx, url, URLStatusFailed)
+	}
+
+	if err := s.completeTask(ctx, result, TaskStatusRetry, code, completedAt); err != nil {
+		return err
+	}
+	nextScrape := s.now().Add(delay)
+	s.logger.WithFields(fields).WithField("next_scrape_at", nextScrape.Format(time.RFC3339)).Info("Scrape failed, retry scheduled")
+	if err := s.urlRepo.IncrementRetryCount(ctx, url.ID); err != nil {
+		return err
+	}
+	if err := s.updateFailedStatus(ctx, url, URLStatusRetry); err != nil {
+		return err
+	}
+	return s.urlRepo.UpdateNextScrapeTime(ctx, url.ID, nextScrape)
+}
+
+// recordSoftFailure records a scrape whose content failed the URL's
+// assertions. Retrying would fetch the same content, so the retry count is
+// reset and a retrying or failed URL returns to pending; a degraded URL keeps
+// its status until a scrape succeeds.
+func (s *TaskResultService) recordSoftFailure(ctx context.Context, url *database.Url, result sharedmodels.ScrapeResult, completedAt time.Time) error {
+	if result.Error == "" {
+		result.Error = strings.Join(result.AssertionFailures, "; ")
+	}
+	if err := s.completeTask(ctx, result, TaskStatusSoftFailed, sharedmodels.ErrorCodeAssertionFailed, completedAt); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"task_id":  result.TaskID,
+		"url_id":   url.ID,
+		"failures": result.AssertionFailures,
+	}).Warn("Scraped content failed assertions")
+
+	if url.RetryCount > 0 {
+		if err := s.urlRepo.ResetRetryCount(ctx, url.ID); err != nil {
+			return err
+		}
+	}
+	if url.Status == URLStatusRetry || url.Status == URLStatusFailed {
+		return s.urlRepo.UpdateURLStatus(ctx, url.ID, URLStatusPending)
+	}
+	return nil
+}
+
+// trackMove records where a fetched page of url was redirected to. A URL
+// moved permanently to the same location in enough scrapes in a row is
+// flagged, and with redirects.auto_update its address is changed to the new
+// location. A scrape that was not moved there starts the count over.
+// Failures are logged only, the scrape result itself is unaffected.
+func (s *TaskResultService) trackMove(ctx context.Context, url *database.Url, result sharedmodels.ScrapeResult, seenAt time.Time) {
+	s.mu.Lock()
+	cfg := s.redirects
+	s.mu.Unlock()
+
+	to, moved := result.MovedTo()
+	if err := s.urlRepo.ClearPendingMoves(ctx, url.ID, to); err != nil || !moved {
+		return
+	}
+	move, err := s.urlRepo.RecordMove(ctx, url.ID, url.Url, to, seenAt)
+	if err != nil || int(move.Hits) < cfg.MoveThreshold {
+		return
+	}
+
+	fields := logrus.Fields{
+		"url_id":   url.ID,
+		"from_url": move.FromUrl,
+		"to_url":   move.ToUrl,
+		"hits":     move.Hits,
+	}
+	if !move.FlaggedAt.Valid {
+		if err := s.urlRepo.FlagMove(ctx, move, seenAt); err != nil {
+			return
+		}
+		s.logger.WithFields(fields).Warn("URL moved permanently")
+	}
+	if !cfg.AutoUpdate || move.AppliedAt.Valid {
+		return
+	}
+	applied, err := s.urlRepo.ApplyMove(ctx, move, seenAt)
+	switch {
+	case err != nil:
+	case applied:
+		s.logger.WithFields(fields).Info("URL updated to its new location")
+	default:
+		s.logger.WithFields(fields).Warn("URL not updated to its new location, another URL has that address")
+	}
+}
+
+// saveHAR stores the HAR a scraper captured for a requested debug capture,
+// kept for the HAR retention. A HAR of a scrape no capture was claimed for,
+// e.g. because the request was cancelled meanwhile, is dropped. Failures are
+// logged only, the scrape result itself is unaffected.
+func (s *TaskResultService) saveHAR(ctx context.Context, result sharedmodels.ScrapeResult) {
+	s.mu.Lock()
+	retention := s.harRetention
+	s.mu.Unlock()
+
+	fields := logrus.Fields{"task_id": result.TaskID, "url_id": result.URLID}
+	data, err := json.Marshal(result.HAR)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("Failed to encode HAR capture")
+		return
+	}
+	saved, err := s.urlRepo.SaveHARCapture(ctx, result.URLID, result.TaskID, data, s.now().Add(retention))
+	switch {
+	case err != nil:
+	case saved:
+		s.logger.WithFields(fields).WithField("entries", len(result.HAR.Log.Entries)).Info("HAR capture stored")
+	default:
+		s.logger.WithFields(fields).Debug("Dropping HAR of a scrape without a capture request")
+	}
+}
+
+// updateFailedStatus sets the status of a URL after a failed scrape. Degraded
+// URLs keep their status until a scrape succeeds, so the watchdog does not
+// raise the same alert again, and paused URLs stay paused.
+func (s *TaskResultService) updateFailedStatus(ctx context.Context, url *database.Url, status string) error {
+	if url.Status == URLStatusDegraded || url.Status == URLStatusPaused || url.Status == status {
+		return nil
+	}
+	return s.urlRepo.UpdateURLStatus(ctx, url.ID, status)
+}
+
+// completeTask records the task outcome with its failure class, costs,
+// DNS timing, redirects and captured response headers. Costs are recorded for failed
+// attempts too, they were incurred all the same.
+func (s *TaskResultService) completeTask(ctx context.Context, result sharedmodels.ScrapeResult, status string, code sharedmodels.ErrorCode, completedAt time.Time) error {
+	var redirects, headers pqtype.NullRawMessage
+	if len(result.Redirects) > 0 {
+		encoded, err := json.Marshal(result.Redirects)
+		if err != nil {
+			return fmt.Errorf("failed to encode redirects: %w", err)
+		}
+		redirects = pqtype.NullRawMessage{RawMessage: encoded, Valid: true}
+	}
+	if captured := s.capturedHeaders(result.Headers); len(captured) > 0 {
+		encoded, err := json.Marshal(captured)
+		if err != nil {
+			return fmt.Errorf("failed to encode response headers: %w", err)
+		}
+		headers = pqtype.NullRawMessage{RawMessage: encoded, Valid: true}
+	}
+	return s.taskRepo.CompleteTask(ctx, database.CompleteScrapingTaskParams{
+		ID:               result.TaskID,
+		Status:           status,
+		StatusCode:       sql.NullInt32{Int32: int32(result.StatusCode), Valid: result.StatusCode != 0},
+		ErrorCode:        sql.NullString{String: string(code), Valid: code != ""},
+		ErrorMessage:     sql.NullString{String: result.Error, Valid: result.Error != ""},
+		DurationMs:       sql.NullInt64{Int64: result.DurationMs, Valid: result.DurationMs > 0},
+		CompletedAt:      sql.NullTime{Time: completedAt, Valid: true},
+		ProxyEgressBytes: max(result.ProxyEgressBytes, 0),
+		RenderMs:         max(result.RenderMs, 0),
+		ThrottledMs:      max(result.ThrottledMs, 0),
+		FinalUrl:         sql.NullString{String: result.FinalURL, Valid: result.FinalURL != ""},
+		RedirectChain:    redirects,
+		ResponseHeaders:  headers,
+		DnsMs:            max(result.DNSMs, 0),
+	})
+}
+
+// capturedHeaders keeps the reported response headers named in scraping.capture_headers
+func (s *TaskResultService) capturedHeaders(reported map[string]string) map[string]string {
+	s.mu.Lock()
+	names := s.captureHeaders
+	s.mu.Unlock()
+
+	header := make(http.Header, len(reported))
+	for name, value := range reported {
+		header.Set(name, value)
+	}
+	return sharedmodels.CaptureHeaders(header, names)
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/contract"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/har"
+	"go_scraping_project/shared/kafka"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordResult(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		retryCount     int32
+		result         sharedmodels.ScrapeResult
+		wantTaskStatus string
+		wantErrorCode  string
+		wantURLStatus  string
+		wantRetryCount int32
+		wantNextScrape time.Time
+	}{
+		{
+			name:           "success resets retries",
+			retryCount:     2,
+			result:         sharedmodels.ScrapeResult{Attempt: 3, Success: true, StatusCode: 200},
+			wantTaskStatus: TaskStatusSuccess,
+			wantURLStatus:  URLStatusPending,
+		},
+		{
+			name:           "failed assertions soft-fail without retry",
+			retryCount:     1,
+			result:         sharedmodels.ScrapeResult{Attempt: 2, Success: true, StatusCode: 200, AssertionFailures: []string{`selector ".price" matches no element`}},
+			wantTaskStatus: TaskStatusSoftFailed,
+			wantErrorCode:  "assertion_failed",
+			wantURLStatus:  URLStatusPending,
+		},
+		{
+			name:           "timeout is retried with backoff",
+			result:         sharedmodels.ScrapeResult{Attempt: 1, ErrorCode: sharedmodels.ErrorCodeTimeout, Error: "i/o timeout"},
+			wantTaskStatus: TaskStatusRetry,
+			wantErrorCode:  "timeout",
+			wantURLStatus:  URLStatusRetry,
+			wantRetryCount: 1,
+			wantNextScrape: now.Add(time.Second),
+		},
+		{
+			name:           "not found is classified from status and not retried",
+			result:         sharedmodels.ScrapeResult{Attempt: 1, StatusCode: 404},
+			wantTaskStatus: TaskStatusFailed,
+			wantErrorCode:  "http_4xx",
+			wantURLStatus:  URLStatusFailed,
+		},
+		{
+			name:           "rate limited honours retry-after without using a retry",
+			result:         sharedmodels.ScrapeResult{Attempt: 1, StatusCode: 429, ErrorCode: sharedmodels.ErrorCodeRateLimited, RetryAfterMs: 120000},
+			wantTaskStatus: TaskStatusThrottled,
+			wantErrorCode:  "rate_limited",
+			wantURLStatus:  URLStatusPending,
+			wantNextScrape: now.Add(2 * time.Minute),
+		},
+		{
+			name:           "unavailable with retry-after is throttled",
+			retryCount:     3,
+			result:         sharedmodels.ScrapeResult{Attempt: 4, StatusCode: 503, RetryAfterMs: 300000},
+			wantTaskStatus: TaskStatusThrottled,
+			wantErrorCode:  "http_5xx",
+			wantURLStatus:  URLStatusRetry,
+			wantRetryCount: 3,
+			wantNextScrape: now.Add(5 * time.Minute),
+		},
+		{
+			name:           "last attempt fails the URL",
+			retryCount:     3,
+			result:         sharedmodels.ScrapeResult{Attempt: 4, StatusCode: 503},
+			wantTaskStatus: TaskStatusFailed,
+			wantErrorCode:  "http_5xx",
+			wantURLStatus:  URLStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := &database.Url{ID: uuid.New(), Status: URLStatusPending, MaxRetries: 3, RetryCount: tt.retryCount}
+			if tt.retryCount > 0 {
+				url.Status = URLStatusRetry
+			}
+			urlRepo := &fakeURLRepository{
+				urls:          map[uuid.UUID]*database.Url{url.ID: url},
+				nextScrapeAts: make(map[uuid.UUID]time.Time),
+			}
+			taskRepo := newFakeTaskRepository()
+			taskID := uuid.New()
+			taskRepo.CreateTask(context.Background(), taskID, url.ID, tt.result.Attempt, time.Now())
+
+			service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+			service.now = func() time.Time { return now }
+
+			tt.result.TaskID = taskID
+			tt.result.URLID = url.ID
+			if err := service.RecordResult(context.Background(), tt.result); err != nil {
+				t.Fatalf("RecordResult() error = %v", err)
+			}
+
+			task := taskRepo.tasks[taskID]
+			if task.Status != tt.wantTaskStatus || task.ErrorCode.String != tt.wantErrorCode || !task.CompletedAt.Valid {
+				t.Errorf("task = status %q, error_code %q; want %q, %q", task.Status, task.ErrorCode.String, tt.wantTaskStatus, tt.wantErrorCode)
+			}
+			if url.Status != tt.wantURLStatus || url.RetryCount != tt.wantRetryCount {
+				t.Errorf("url = status %q, retry_count %d; want %q, %d", url.Status, url.RetryCount, tt.wantURLStatus, tt.wantRetryCount)
+			}
+			if got := urlRepo.nextScrapeAts[url.ID]; !got.Equal(tt.wantNextScrape) {
+				t.Errorf("next scrape = %v, want %v", got, tt.wantNextScrape)
+			}
+		})
+	}
+}
+
+func TestRecordResultIgnoresCompletedTask(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Status: URLStatusPending, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{urls: map[uuid.UUID]*database.Url{url.ID: url}}
+	taskRepo := newFakeTaskRepository()
+	taskID := uuid.New()
+	taskRepo.CreateTask(context.Background(), taskID, url.ID, 1, time.Now())
+
+	service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+	result := sharedmodels.ScrapeResult{TaskID: taskID, URLID: url.ID, Attempt: 1, StatusCode: 404}
+	if err := service.RecordResult(context.Background(), result); err != nil {
+		t.Fatalf("RecordResult() error = %v", err)
+	}
+	url.Status = URLStatusPending
+	if err := service.RecordResult(context.Background(), result); err != nil {
+		t.Fatalf("RecordResult() error = %v", err)
+	}
+
+	if url.Status != URLStatusPending {
+		t.Errorf("redelivered result changed URL status to %q", url.Status)
+	}
+}
+
+func TestRecordResultSoftFailureKeepsDegradedStatus(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Status: URLStatusDegraded, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{urls: map[uuid.UUID]*database.Url{url.ID: url}}
+	taskRepo := newFakeTaskRepository()
+	taskID := uuid.New()
+	taskRepo.CreateTask(context.Background(), taskID, url.ID, 1, time.Now())
+	service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+
+	result := sharedmodels.ScrapeResult{
+		TaskID:            taskID,
+		URLID:             url.ID,
+		Attempt:           1,
+		Success:           true,
+		StatusCode:        200,
+		AssertionFailures: []string{"status is 200, expected 204", `response does not contain "Price"`},
+	}
+	if err := service.RecordResult(context.Background(), result); err != nil {
+		t.Fatalf("RecordResult() error = %v", err)
+	}
+	if url.Status != URLStatusDegraded {
+		t.Errorf("status after soft failure = %q, want degraded", url.Status)
+	}
+	if want := `status is 200, expected 204; response does not contain "Price"`; taskRepo.tasks[taskID].ErrorMessage.String != want {
+		t.Errorf("error message = %q, want %q", taskRepo.tasks[taskID].ErrorMessage.String, want)
+	}
+}
+
+func TestRecordResultKeepsDegradedStatus(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Status: URLStatusDegraded, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url},
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	service := NewTaskResultService(urlRepo, newFakeTaskRepository(), newTestLogger())
+
+	if err := service.RecordResult(context.Background(), sharedmodels.ScrapeResult{TaskID: uuid.New(), URLID: url.ID, Attempt: 1, StatusCode: 503}); err != nil {
+		t.Fatalf("RecordResult() error = %v", err)
+	}
+	if url.Status != URLStatusDegraded {
+		t.Errorf("status after failure = %q, want degraded", url.Status)
+	}
+
+	if err := service.RecordResult(context.Background(), sharedmodels.ScrapeResult{TaskID: uuid.New(), URLID: url.ID, Attempt: 2, Success: true}); err != nil {
+		t.Fatalf("RecordResult() error = %v", err)
+	}
+	if url.Status != URLStatusPending {
+		t.Errorf("status after success = %q, want pending", url.Status)
+	}
+}
+
+func TestRecordResultKeepsPausedStatus(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Status: URLStatusPaused, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url},
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	service := NewTaskResultService(urlRepo, newFakeTaskRepository(), newTestLogger())
+
+	for attempt, result := range []sharedmodels.ScrapeResult{
+		{StatusCode: 503},
+		{StatusCode: 404},
+		{Success: true},
+	} {
+		result.TaskID, result.URLID, result.Attempt = uuid.New(), url.ID, attempt+1
+		if err := service.RecordResult(context.Background(), result); err != nil {
+			t.Fatalf("RecordResult() error = %v", err)
+		}
+		if url.Status != URLStatusPaused {
+			t.Errorf("status after result %d = %q, want paused", attempt+1, url.Status)
+		}
+	}
+}
+
+func TestRecordResultStoresCostsAndHeaders(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Status: URLStatusPending, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url},
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	taskRepo := newFakeTaskRepository()
+	service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+
+	results := []sharedmodels.ScrapeResult{
+		{TaskID: uuid.New(), URLID: url.ID, Attempt: 1, Success: true, StatusCode: 200, ProxyEgressBytes: 52000, RenderMs: 1800, ThrottledMs: 1200, DNSMs: 14,
+			Headers: map[string]string{"Server": "nginx", "X-Robots-Tag": "noindex", "Set-Cookie": "session=secret"}},
+		{TaskID: uuid.New(), URLID: url.ID, Attempt: 1, StatusCode: 503, ProxyEgressBytes: 900, RenderMs: -1},
+	}
+	for _, result := range results {
+		taskRepo.CreateTask(context.Background(), result.TaskID, url.ID, result.Attempt, time.Now())
+		if err := service.RecordResult(context.Background(), result); err != nil {
+			t.Fatalf("RecordResult() error = %v", err)
+		}
+	}
+
+	if task := taskRepo.tasks[results[0].TaskID]; task.ProxyEgressBytes != 52000 || task.RenderMs != 1800 || task.ThrottledMs != 1200 {
+		t.Errorf("successful task costs = %d bytes, %d ms, throttled %d ms; want 52000, 1800, 1200", task.ProxyEgressBytes, task.RenderMs, task.ThrottledMs)
+	}
+	if task := taskRepo.tasks[results[0].TaskID]; task.DnsMs != 14 {
+		t.Errorf("DNS time = %d ms, want 14", task.DnsMs)
+	}
+	if task := taskRepo.tasks[results[1].TaskID]; task.ProxyEgressBytes != 900 || task.RenderMs != 0 {
+		t.Errorf("failed task costs = %d bytes, %d ms; want 900, 0", task.ProxyEgressBytes, task.RenderMs)
+	}
+	if headers := string(taskRepo.tasks[results[0].TaskID].ResponseHeaders.RawMessage); headers != `{"server":"nginx","x-robots-tag":"noindex"}` {
+		t.Errorf("captured headers = %s, want server and x-robots-tag only", headers)
+	}
+}
+
+func TestRecordResultTracksMoves(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Url: "http://example.com/a", Status: URLStatusPending, MaxRetries: 3}
+	taken := &database.Url{ID: uuid.New(), Url: "https://example.com/taken", Status: URLStatusPending}
+	urlRepo := &fakeURLRepository{urls: map[uuid.UUID]*database.Url{url.ID: url, taken.ID: taken}}
+	taskRepo := newFakeTaskRepository()
+	service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+	service.Configure(config.RedirectsConfig{MoveThreshold: 2, AutoUpdate: true})
+
+	record := func(from, to string, statusCode int) *database.ScrapingTask {
+		t.Helper()
+		result := sharedmodels.ScrapeResult{TaskID: uuid.New(), URLID: url.ID, Attempt: 1, Success: true, StatusCode: 200, FinalURL: to}
+		if statusCode != 0 {
+			result.Redirects = []sharedmodels.Redirect{{URL: from, StatusCode: statusCode}}
+		}
+		taskRepo.CreateTask(context.Background(), result.TaskID, url.ID, 1, time.Now())
+		if err := service.RecordResult(context.Background(), result); err != nil {
+			t.Fatalf("RecordResult() error = %v", err)
+		}
+		return taskRepo.tasks[result.TaskID]
+	}
+
+	// A temporary redirect or an unredirected scrape starts the count over
+	task := record(url.Url, "https://example.com/b", 301)
+	if task.FinalUrl.String != "https://example.com/b" || string(task.RedirectChain.RawMessage) != `[{"url":"http://example.com/a","status_code":301}]` {
+		t.Errorf("task redirects = %q, %s", task.FinalUrl.String, task.RedirectChain.RawMessage)
+	}
+	record(url.Url, "https://example.com/b", 302)
+	record(url.Url, "https://example.com/b", 301)
+	if len(urlRepo.moves) != 1 || urlRepo.moves[0].Hits != 1 || url.Url != "http://example.com/a" {
+		t.Fatalf("moves = %+v, url = %s; want 1 hit after the temporary redirect", urlRepo.moves, url.Url)
+	}
+
+	record(url.Url, "https://example.com/b", 301)
+	if move := urlRepo.moves[0]; !move.FlaggedAt.Valid || !move.AppliedAt.Valid || url.Url != "https://example.com/b" {
+		t.Errorf("move = %+v, url = %s; want it flagged and applied", move, url.Url)
+	}
+
+	// A move to an address another URL has is flagged but not applied
+	record(url.Url, taken.Url, 308)
+	record(url.Url, taken.Url, 308)
+	if move := urlRepo.moves[1]; !move.FlaggedAt.Valid || move.AppliedAt.Valid || url.Url != "https://example.com/b" {
+		t.Errorf("move = %+v, url = %s; want it flagged only", move, url.Url)
+	}
+}
+
+func TestRecordResultStoresHARCapture(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Url: "https://example.com/", Frequency: "1h", Status: URLStatusPending, MaxRetries: 3}
+	urlRepo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+		harCaptures:   map[uuid.UUID]*database.HarCapture{url.ID: {UrlID: url.ID}},
+	}
+	producer := &fakeProducer{}
+	taskRepo := newFakeTaskRepository()
+	scheduler := NewURLSchedulerService(urlRepo, taskRepo, newFakeBudgetRepository(), &fakeWorkerRepository{}, producer, newTestLogger())
+	service := NewTaskResultService(urlRepo, taskRepo, newTestLogger())
+	service.SetHARRetention(2 * time.Hour)
+
+	// Only the next scrape is captured
+	captured, _, err := scheduler.TriggerURL(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("TriggerURL() error = %v", err)
+	}
+	next, _, err := scheduler.TriggerURL(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("TriggerURL() error = %v", err)
+	}
+	if !producer.sent[0].CaptureHAR || producer.sent[1].CaptureHAR {
+		t.Fatalf("capture_har = %v, %v; want the first scrape only", producer.sent[0].CaptureHAR, producer.sent[1].CaptureHAR)
+	}
+
+	doc := &har.HAR{Log: har.Log{Version: "1.2", Entries: []har.Entry{{Request: har.Request{URL: url.Url}}}}}
+	for _, task := range []*ScrapingTask{next, captured} {
+		result := sharedmodels.ScrapeResult{TaskID: task.ID, URLID: url.ID, Attempt: 1, StatusCode: 403, HAR: doc}
+		if err := service.RecordResult(context.Background(), result); err != nil {
+			t.Fatalf("RecordResult() error = %v", err)
+		}
+		if capture := urlRepo.harCaptures[url.ID]; capture.Har.Valid != (task == captured) {
+			t.Errorf("HAR stored = %v after the result of task %s", capture.Har.Valid, task.ID)
+		}
+	}
+	if capture := urlRepo.harCaptures[url.ID]; !capture.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("capture expires at %v, want after the retention", capture.ExpiresAt)
+	}
+}
+
+func TestScrapeResultContract(t *testing.T) {
+	// Scrapers send results as the contract describes
+	var want kafka.ScrapeResultMessage
+	if err := contract.DecodeStrict(contract.Fixture(contract.ScrapeResult), &want); err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+
+	// The consumer decodes their data as generic JSON first
+	var message sharedmodels.KafkaMessage
+	if err := json.Unmarshal(contract.Fixture(contract.ScrapeResult), &message); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if message.Type != sharedmodels.MessageTypeScrapeResult {
+		t.Fatalf("message type = %s, want %s", message.Type, sharedmodels.MessageTypeScrapeResult)
+	}
+	result, err := decodeScrapeResult(&message)
+	if err != nil {
+		t.Fatalf("decodeScrapeResult() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, want.Data) {
+		t.Errorf("decodeScrapeResult() = %+v, want %+v", result, want.Data)
+	}
+}
@@ -2,24 +2,114 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"go_scraping_project/services/url-manager/models"
 	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
 	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+	"go_scraping_project/shared/kafka"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/utils"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+// Default scheduler settings used when none are configured
+const (
+	DefaultSchedulerInterval  = 30 * time.Second
+	DefaultSchedulerBatchSize = 100
+	DefaultSchedulerLookback  = time.Minute     // Include URLs that were due up to a minute ago
+	DefaultSchedulerLookahead = 5 * time.Minute // Include URLs due in the next 5 minutes
+)
+
+// SchedulerSettings are the effective scheduler settings, after defaults
+type SchedulerSettings struct {
+	Enabled       bool   `json:"enabled"`
+	CheckInterval string `json:"check_interval"`
+	BatchSize     int32  `json:"batch_size"`
+	Lookback      string `json:"lookback"`
+	Lookahead     string `json:"lookahead"`
+}
+
+// SchedulerRun describes one scheduling pass
+type SchedulerRun struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	DurationMS     int64     `json:"duration_ms"`
+	URLsConsidered int       `json:"urls_considered"` // Scheduled URLs fetched in the lookup window
+	TasksPublished int       `json:"tasks_published"`
+	URLsDeferred   int       `json:"urls_deferred"` // Deferred to the next day by a scrape budget
+	Errors         int       `json:"errors"`        // URLs that failed to process
+	Error          string    `json:"error,omitempty"`
+}
+
+// SchedulerTotals counts scheduling passes and their results since the
+// URL Manager started
+type SchedulerTotals struct {
+	Runs           int64 `json:"runs"`
+	FailedRuns     int64 `json:"failed_runs"` // Passes that could not fetch the scheduled URLs
+	URLsConsidered int64 `json:"urls_considered"`
+	TasksPublished int64 `json:"tasks_published"`
+	URLsDeferred   int64 `json:"urls_deferred"`
+	Errors         int64 `json:"errors"`
+}
+
+// SchedulerStatus is the effective scheduler settings together with the latest pass
+type SchedulerStatus struct {
+	Settings SchedulerSettings `json:"settings"`
+	LastRun  *SchedulerRun     `json:"last_run,omitempty"`
+}
+
+// SchedulerRunStatus reports whether the scheduler is doing its work: the
+// latest pass and the totals since startup
+type SchedulerRunStatus struct {
+	Enabled     bool            `json:"enabled"`
+	Maintenance bool            `json:"maintenance"` // Passes are skipped during maintenance mode
+	LastRun     *SchedulerRun   `json:"last_run,omitempty"`
+	Totals      SchedulerTotals `json:"totals"`
+}
+
 // URLSchedulerService handles URL scheduling and scraping task creation
 type URLSchedulerService struct {
-	urlRepo   repositories.URLRepository
-	producer  KafkaProducer
-	logger    *logrus.Logger
-	scheduler *time.Ticker
-	stopChan  chan struct{}
+	urlRepo    repositories.URLRepository
+	taskRepo   repositories.TaskRepository
+	budgetRepo repositories.BudgetRepository
+	workerRepo repositories.WorkerRepository
+	producer   KafkaProducer
+	logger     *logrus.Logger
+	scheduler  *time.Ticker
+	stopChan   chan struct{}
+	done       chan struct{} // Closed when the scheduling loop returns
+
+	maintenance MaintenanceMode // Optional, see SetMaintenance
+
+	// Settings that can change at runtime, see Configure
+	mu        sync.Mutex
+	interval  time.Duration
+	batchSize int32
+	lookback  time.Duration
+	lookahead time.Duration
+	disabled  bool
+	budgets   []scrapeBudget
+	lastRun   *SchedulerRun
+	totals    SchedulerTotals
+
+	regionTimeout   time.Duration
+	regionFallbacks map[string]string
+	frequencyPolicy config.FrequencyPolicyConfig
+	projectShares   config.ProjectShares
+}
+
+// MaintenanceMode reports whether the system is in maintenance mode, see maintenance.Mode
+type MaintenanceMode interface {
+	Enabled() bool
 }
 
 // KafkaProducer interface for sending messages to Kafka
@@ -31,22 +121,24 @@ type KafkaProducer interface {
 
 // ScrapingTask represents a scraping task to be sent to Kafka
 type ScrapingTask struct {
-	ID        uuid.UUID `json:"id"`
-	URLID     uuid.UUID `json:"url_id"`
-	URL       string    `json:"url"`
-	Status    string    `json:"status"`
-	Attempt   int       `json:"attempt"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uuid.UUID                   `json:"id"`
+	URLID         uuid.UUID                   `json:"url_id"`
+	URL           string                      `json:"url"`
+	Status        string                      `json:"status"`
+	Attempt       int                         `json:"attempt"`
+	RetryPolicy   sharedmodels.RetryPolicy    `json:"retry_policy"`
+	Assertions    *sharedmodels.Assertions    `json:"assertions,omitempty"`
+	Region        string                      `json:"region,omitempty"`
+	RateLimit     int                         `json:"rate_limit,omitempty"`
+	ArchivePolicy *sharedmodels.ArchivePolicy `json:"archive_policy,omitempty"`
+	CaptureHAR    bool                        `json:"capture_har,omitempty"`
+	Project       string                      `json:"project,omitempty"`
+	CreatedAt     time.Time                   `json:"created_at"`
 }
 
-// ScrapingTaskMessage represents a Kafka message for scraping tasks
-type ScrapingTaskMessage struct {
-	TaskID        uuid.UUID `json:"task_id"`
-	URLID         uuid.UUID `json:"url_id"`
-	URL           string    `json:"url"`
-	CorrelationID string    `json:"correlation_id"`
-	Timestamp     time.Time `json:"timestamp"`
-}
+// ScrapingTaskMessage is the Kafka message for scraping tasks, see
+// sharedmodels.ScrapingTaskMessage
+type ScrapingTaskMessage = sharedmodels.ScrapingTaskMessage
 
 // NewScrapingTaskMessage creates a new scraping task message
 func NewScrapingTaskMessage(task *ScrapingTask, correlationID string) *ScrapingTaskMessage {
@@ -54,57 +146,240 @@ func NewScrapingTaskMessage(task *ScrapingTask, correlationID string) *ScrapingT
 		TaskID:        task.ID,
 		URLID:         task.URLID,
 		URL:           task.URL,
+		Attempt:       task.Attempt,
+		RetryPolicy:   task.RetryPolicy,
+		Assertions:    task.Assertions,
+		Region:        task.Region,
+		RateLimit:     task.RateLimit,
+		ArchivePolicy: task.ArchivePolicy,
+		CaptureHAR:    task.CaptureHAR,
+		Project:       task.Project,
 		CorrelationID: correlationID,
 		Timestamp:     time.Now().UTC(),
 	}
 }
 
-// TopicScrapingTasks is the Kafka topic for scraping tasks
+// ErrURLNotFound is returned when triggering a URL that does not exist or is deleted
+var ErrURLNotFound = domain.ErrURLNotFound
+
+// ErrMaintenance is returned when triggering a URL during maintenance mode
+var ErrMaintenance = domain.ErrMaintenance
+
+// TopicScrapingTasks is the Kafka topic for scraping tasks. Tasks of URLs
+// with a region go to the region's variant, see kafka.RegionTopic.
 const TopicScrapingTasks = "scraping-tasks"
 
-// URLStatusPending represents a pending URL status
-const URLStatusPending = "pending"
+// URL status values used by the scheduler and result handling, see
+// sharedmodels for the transitions between them
+const (
+	URLStatusPending  = sharedmodels.URLStatusPending
+	URLStatusRetry    = sharedmodels.URLStatusRetry
+	URLStatusFailed   = sharedmodels.URLStatusFailed
+	URLStatusDegraded = sharedmodels.URLStatusDegraded
+	URLStatusPaused   = sharedmodels.URLStatusPaused
+)
+
+// Scraping task status values
+const (
+	TaskStatusPending    = sharedmodels.TaskStatusPending
+	TaskStatusSuccess    = sharedmodels.TaskStatusSuccess
+	TaskStatusRetry      = sharedmodels.TaskStatusRetry
+	TaskStatusFailed     = sharedmodels.TaskStatusFailed
+	TaskStatusSoftFailed = sharedmodels.TaskStatusSoftFailed
+	TaskStatusThrottled  = sharedmodels.TaskStatusThrottled
+)
 
 // NewURLSchedulerService creates a new URL scheduler service
 func NewURLSchedulerService(
 	urlRepo repositories.URLRepository,
+	taskRepo repositories.TaskRepository,
+	budgetRepo repositories.BudgetRepository,
+	workerRepo repositories.WorkerRepository,
 	producer KafkaProducer,
 	logger *logrus.Logger,
 ) *URLSchedulerService {
 	return &URLSchedulerService{
-		urlRepo:  urlRepo,
-		producer: producer,
-		logger:   logger,
-		stopChan: make(chan struct{}),
+		urlRepo:       urlRepo,
+		taskRepo:      taskRepo,
+		budgetRepo:    budgetRepo,
+		workerRepo:    workerRepo,
+		producer:      producer,
+		logger:        logger,
+		stopChan:      make(chan struct{}),
+		interval:      DefaultSchedulerInterval,
+		batchSize:     DefaultSchedulerBatchSize,
+		lookback:      DefaultSchedulerLookback,
+		lookahead:     DefaultSchedulerLookahead,
+		regionTimeout: DefaultRegionHealthTimeout,
 	}
 }
 
+// Configure applies scheduler settings, which config.SchedulerConfig.Validate
+// has checked. Zero values select the defaults. It is safe to call while the
+// scheduler is running; a new check interval takes effect on the next tick.
+func (s *URLSchedulerService) Configure(cfg config.SchedulerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := cfg.CheckInterval
+	if interval <= 0 {
+		interval = DefaultSchedulerInterval
+	}
+	batchSize := int32(cfg.BatchSize)
+	if batchSize <= 0 {
+		batchSize = DefaultSchedulerBatchSize
+	}
+	lookback := cfg.Lookback
+	if lookback <= 0 {
+		lookback = DefaultSchedulerLookback
+	}
+	lookahead := cfg.Lookahead
+	if lookahead <= 0 {
+		lookahead = DefaultSchedulerLookahead
+	}
+
+	if interval != s.interval && s.scheduler != nil {
+		s.scheduler.Reset(interval)
+	}
+	if interval != s.interval || batchSize != s.batchSize || lookback != s.lookback || lookahead != s.lookahead {
+		s.logger.WithFields(logrus.Fields{
+			"check_interval": interval.String(),
+			"batch_size":     batchSize,
+			"lookback":       lookback.String(),
+			"lookahead":      lookahead.String(),
+		}).Info("Scheduler settings updated")
+	}
+	if cfg.Enabled == s.disabled {
+		s.logger.WithField("enabled", cfg.Enabled).Info("Scheduler enabled state updated")
+	}
+	budgets := scrapeBudgets(cfg.Budgets, s.logger)
+	if len(budgets) != len(s.budgets) {
+		s.logger.WithField("budgets", len(budgets)).Info("Scrape budgets updated")
+	}
+	regionTimeout := cfg.RegionHealthTimeout
+	if regionTimeout <= 0 {
+		regionTimeout = DefaultRegionHealthTimeout
+	}
+	s.interval = interval
+	s.batchSize = batchSize
+	s.lookback = lookback
+	s.lookahead = lookahead
+	s.disabled = !cfg.Enabled
+	s.budgets = budgets
+	s.regionTimeout = regionTimeout
+	s.regionFallbacks = regionFallbacks(cfg.RegionFallbacks, s.logger)
+}
+
+// SetFrequencyPolicy sets the minimum frequencies URLs are scheduled at,
+// which config.FrequencyPolicyConfig.Validate has checked. It is safe to
+// call while the scheduler is running.
+func (s *URLSchedulerService) SetFrequencyPolicy(policy config.FrequencyPolicyConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frequencyPolicy = policy
+}
+
+// SetProjectShares sets the shares projects get of each scheduling batch,
+// which config.ProjectShares.Validate has checked. It is safe to call while
+// the scheduler is running.
+func (s *URLSchedulerService) SetProjectShares(shares config.ProjectShares) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projectShares = shares
+}
+
+// SetMaintenance makes the scheduler skip its passes and refuse triggered
+// scrapes while mode is enabled. Tasks already published are still scraped.
+// It must be called before Start.
+func (s *URLSchedulerService) SetMaintenance(mode MaintenanceMode) {
+	s.maintenance = mode
+}
+
+// inMaintenance reports whether maintenance mode is enabled
+func (s *URLSchedulerService) inMaintenance() bool {
+	return s.maintenance != nil && s.maintenance.Enabled()
+}
+
+// Status returns the effective settings and the latest scheduling pass
+func (s *URLSchedulerService) Status() SchedulerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SchedulerStatus{
+		Settings: SchedulerSettings{
+			Enabled:       !s.disabled,
+			CheckInterval: s.interval.String(),
+			BatchSize:     s.batchSize,
+			Lookback:      s.lookback.String(),
+			Lookahead:     s.lookahead.String(),
+		},
+		LastRun: s.lastRun,
+	}
+}
+
+// RunStatus returns the latest scheduling pass and the totals since startup
+func (s *URLSchedulerService) RunStatus() SchedulerRunStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SchedulerRunStatus{
+		Enabled:     !s.disabled,
+		Maintenance: s.inMaintenance(),
+		LastRun:     s.lastRun,
+		Totals:      s.totals,
+	}
+}
+
+// recordRun keeps a finished pass for the status endpoints and adds it to the totals
+func (s *URLSchedulerService) recordRun(run *SchedulerRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun = run
+	s.totals.Runs++
+	if run.Error != "" {
+		s.totals.FailedRuns++
+	}
+	s.totals.URLsConsidered += int64(run.URLsConsidered)
+	s.totals.TasksPublished += int64(run.TasksPublished)
+	s.totals.URLsDeferred += int64(run.URLsDeferred)
+	s.totals.Errors += int64(run.Errors)
+}
+
 // Start starts the URL scheduler service
 func (s *URLSchedulerService) Start(ctx context.Context) error {
 	s.logger.Info("Starting URL Scheduler Service")
 
-	// Start the scheduler ticker (check every 30 seconds)
-	s.scheduler = time.NewTicker(30 * time.Second)
+	// Start the scheduler ticker at the configured check interval
+	s.mu.Lock()
+	s.scheduler = time.NewTicker(s.interval)
+	s.done = make(chan struct{})
+	s.mu.Unlock()
 
 	go s.runScheduler(ctx)
 
 	return nil
 }
 
-// Stop stops the URL scheduler service
+// Stop stops the URL scheduler service. It waits for a scheduling pass in
+// progress to finish, so no task is published after Stop returns.
 func (s *URLSchedulerService) Stop() error {
 	s.logger.Info("Stopping URL Scheduler Service")
 
-	if s.scheduler != nil {
-		s.scheduler.Stop()
+	s.mu.Lock()
+	ticker, done := s.scheduler, s.done
+	s.mu.Unlock()
+	if ticker != nil {
+		ticker.Stop()
 	}
 
 	close(s.stopChan)
+	if done != nil {
+		<-done
+	}
 	return nil
 }
 
 // runScheduler runs the main scheduling loop
 func (s *URLSchedulerService) runScheduler(ctx context.Context) {
+	defer close(s.done)
 	for {
 		select {
 		case <-ctx.Done():
@@ -121,17 +396,55 @@ func (s *URLSchedulerService) runScheduler(ctx context.Context) {
 	}
 }
 
-// processScheduledURLs processes URLs that are scheduled for scraping
-func (s *URLSchedulerService) processScheduledURLs(ctx context.Context) error {
+// processScheduledURLs processes URLs that are scheduled for scraping. The
+// pass is kept for Status.
+func (s *URLSchedulerService) processScheduledURLs(ctx context.Context) (err error) {
 	// Use UTC for all time calculations
 	now := time.Now().UTC()
-	from := now.Add(-1 * time.Minute) // Include URLs that were due up to 1 minute ago
-	to := now.Add(5 * time.Minute)    // Include URLs due in the next 5 minutes
+	s.mu.Lock()
+	batchSize, disabled, budgets, shares := s.batchSize, s.disabled, s.budgets, s.projectShares
+	from, to := now.Add(-s.lookback), now.Add(s.lookahead)
+	regions := newRegionRouter(s.workerRepo, s.regionFallbacks, s.regionTimeout, now, s.logger)
+	s.mu.Unlock()
+	if disabled {
+		return nil
+	}
+	if s.inMaintenance() {
+		s.logger.Debug("Maintenance mode enabled, skipping scheduler run")
+		return nil
+	}
+
+	run := &SchedulerRun{StartedAt: now}
+	defer func() {
+		run.FinishedAt = time.Now().UTC()
+		run.DurationMS = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+		if err != nil {
+			run.Error = err.Error()
+		}
+		s.recordRun(run)
+		s.logger.WithFields(logrus.Fields{
+			"urls_considered": run.URLsConsidered,
+			"tasks_published": run.TasksPublished,
+			"urls_deferred":   run.URLsDeferred,
+			"errors":          run.Errors,
+			"duration_ms":     run.DurationMS,
+		}).Debug("Scheduler run finished")
+	}()
+
+	if deleted, err := s.urlRepo.DeleteExpiredHARCaptures(ctx); err != nil {
+		s.logger.WithError(err).Warn("Failed to delete expired HAR captures")
+	} else if deleted > 0 {
+		s.logger.WithField("deleted", deleted).Debug("Deleted expired HAR captures")
+	}
+
 	s.logger.Info("Getting scheduled URLs")
-	urls, err := s.urlRepo.GetURLsScheduledForScraping(ctx, from, to, 100)
+	// URLs of projects are interleaved by their shares, so a project with a
+	// large backlog cannot take every slot of the batch
+	urls, err := s.urlRepo.GetURLsScheduledForScraping(ctx, from, to, batchSize, shares)
 	if err != nil {
 		return fmt.Errorf("failed to get scheduled URLs: %w", err)
 	}
+	run.URLsConsidered = len(urls)
 
 	if len(urls) == 0 {
 		return nil
@@ -139,9 +452,14 @@ func (s *URLSchedulerService) processScheduledURLs(ctx context.Context) error {
 
 	s.logger.WithField("url_count", len(urls)).Info("Processing scheduled URLs")
 
+	var tracker *budgetTracker
+	if len(budgets) > 0 {
+		tracker = newBudgetTracker(s.budgetRepo, budgets, now)
+	}
 	for _, url := range urls {
-		if err := s.processURL(ctx, url); err != nil {
+		if err := s.processURL(ctx, url, tracker, regions, run); err != nil {
 			s.logger.WithError(err).WithField("url_id", url.ID).Error("Failed to process URL")
+			run.Errors++
 			continue
 		}
 	}
@@ -149,51 +467,267 @@ func (s *URLSchedulerService) processScheduledURLs(ctx context.Context) error {
 	return nil
 }
 
-// processURL processes a single URL for scraping
-func (s *URLSchedulerService) processURL(ctx context.Context, url database.Url) error {
+// processURL processes a single URL for scraping. With a budget tracker, a
+// URL whose daily scrape budget is used up is deferred to the next day. The
+// task is sent to the topic of the region picked by the region router. The
+// published task or deferral is counted in run.
+func (s *URLSchedulerService) processURL(ctx context.Context, url database.Url, budgets *budgetTracker, regions *regionRouter, run *SchedulerRun) error {
 	if !url.NextScrapeAt.Valid || url.NextScrapeAt.Time.After(time.Now().UTC()) {
 		s.logger.Printf("URL %s is not due yet", url.Url)
 		return nil // Not actually due yet
 	}
 
+	if budgets != nil {
+		budget, err := budgets.exhausted(ctx, url)
+		if err != nil {
+			return err
+		}
+		if budget != nil {
+			if err := s.deferURL(ctx, url, *budget, budgets); err != nil {
+				return err
+			}
+			run.URLsDeferred++
+			return nil
+		}
+	}
+
+	region, err := regions.route(ctx, url.Region)
+	if err != nil {
+		return err
+	}
+
 	s.logger.Printf("Processing URL: %s (ID: %s)", url.Url, url.ID)
+	if _, err := s.publishTask(ctx, url, region, url.NextScrapeAt.Time); err != nil {
+		return err
+	}
+	run.TasksPublished++
+	if budgets != nil {
+		budgets.record(url)
+	}
+
+	// Update URL status and last scraped time
+	if err := s.urlRepo.UpdateLastScrapedTime(ctx, url.ID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to update last scraped time: %w", err)
+	}
 
-	// Create scraping task struct
+	nextScrape, err := s.nextScrapeTime(url, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to calculate next scrape time: %w", err)
+	}
+
+	if err := s.urlRepo.UpdateNextScrapeTime(ctx, url.ID, nextScrape); err != nil {
+		return fmt.Errorf("failed to update next scrape time: %w", err)
+	}
+
+	s.logger.Printf("Updated URL %s: next scrape at %s", url.ID, nextScrape.UTC().Format(time.RFC3339))
+
+	return nil
+}
+
+// nextScrapeTime returns when a URL is scraped next: one frequency after
+// from, or one floor after from when the frequency policy sets a longer
+// floor for its domain or project, so URLs created before a floor was
+// configured are not polled more often than it allows
+func (s *URLSchedulerService) nextScrapeTime(url database.Url, from time.Time) (time.Time, error) {
+	interval, err := models.ParseFrequency(url.Frequency)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.mu.Lock()
+	floor := s.frequencyPolicy.Floor(url.Url, url.Project)
+	s.mu.Unlock()
+	if interval < floor {
+		s.logger.WithFields(logrus.Fields{
+			"url_id":        url.ID,
+			"frequency":     url.Frequency,
+			"min_frequency": utils.FormatFrequency(floor),
+		}).Debug("Frequency below the configured minimum, scheduling at the minimum")
+		interval = floor
+	}
+	return from.Add(interval), nil
+}
+
+// TriggerURL publishes a scraping task for a URL right away, outside the
+// schedule, and returns the task and the topic it was published to. The
+// URL's next scheduled scrape is kept. The task counts against the daily
+// budgets covering the URL, and a URL whose budget is used up is not
+// triggered: that fails with an ErrRateLimited domain error to retry when the
+// budget resets. Fails with ErrMaintenance during maintenance mode.
+func (s *URLSchedulerService) TriggerURL(ctx context.Context, id uuid.UUID) (*ScrapingTask, string, error) {
+	if s.inMaintenance() {
+		return nil, "", ErrMaintenance
+	}
+
+	url, err := s.urlRepo.GetURLByID(ctx, id)
+	if errors.Is(err, ErrURLNotFound) || err == nil && url.DeletedAt.Valid {
+		return nil, "", ErrURLNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	regions := newRegionRouter(s.workerRepo, s.regionFallbacks, s.regionTimeout, now, s.logger)
+	budgets := s.budgets
+	s.mu.Unlock()
+
+	if len(budgets) > 0 {
+		tracker := newBudgetTracker(s.budgetRepo, budgets, now)
+		budget, err := tracker.exhausted(ctx, *url)
+		if err != nil {
+			return nil, "", err
+		}
+		if budget != nil {
+			s.logger.WithFields(logrus.Fields{"url_id": url.ID, "scope": budget.scope, "budget": budget.name}).Info("Rejected triggered scrape, daily budget exhausted")
+			return nil, "", domain.RateLimited(tracker.nextDay().Sub(now),
+				"Daily scrape budget of %s %s is used up (%d scrapes), resets at %s",
+				budget.scope, budget.name, budget.limit, tracker.nextDay().Format(time.RFC3339))
+		}
+	}
+
+	region, err := regions.route(ctx, url.Region)
+	if err != nil {
+		return nil, "", err
+	}
+
+	task, err := s.publishTask(ctx, *url, region, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.urlRepo.UpdateLastScrapedTime(ctx, url.ID, time.Now().UTC()); err != nil {
+		s.logger.WithError(err).WithField("url_id", url.ID).Warn("Failed to update last scraped time of triggered URL")
+	}
+
+	s.logger.WithFields(logrus.Fields{"url_id": url.ID, "task_id": task.ID}).Info("Triggered immediate scrape")
+	return task, kafka.RegionTopic(TopicScrapingTasks, region), nil
+}
+
+// publishTask sends a scraping task for the URL to the topic of region and
+// records it with the time it was due, for scheduling latency SLOs. A
+// pending HAR capture request of the URL is claimed for the task, so only
+// this scrape is captured.
+func (s *URLSchedulerService) publishTask(ctx context.Context, url database.Url, region string, dueAt time.Time) (*ScrapingTask, error) {
+	// Create scraping task struct. URLs in retry status have already
+	// failed retry_count times.
 	task := &ScrapingTask{
-		ID:        uuid.New(),
-		URLID:     url.ID,
-		URL:       url.Url,
-		Status:    URLStatusPending,
-		Attempt:   1,
-		CreatedAt: time.Now().UTC(),
+		ID:            uuid.New(),
+		URLID:         url.ID,
+		URL:           url.Url,
+		Status:        TaskStatusPending,
+		Attempt:       int(url.RetryCount) + 1,
+		RetryPolicy:   effectiveRetryPolicy(url, s.logger),
+		Assertions:    urlAssertions(url, s.logger),
+		Region:        url.Region,
+		RateLimit:     int(url.RateLimit),
+		ArchivePolicy: urlArchivePolicy(url, s.logger),
+		Project:       url.Project,
+		CreatedAt:     time.Now().UTC(),
 	}
+	captureHAR, err := s.urlRepo.ClaimHARCapture(ctx, url.ID, task.ID)
+	if err != nil {
+		// Scraping goes ahead; the request stays pending for the next scrape
+		s.logger.WithError(err).WithField("url_id", url.ID).Warn("Failed to claim HAR capture")
+	}
+	task.CaptureHAR = captureHAR
 
 	// Create Kafka message using helper
 	correlationID := uuid.New().String()
 	msg := NewScrapingTaskMessage(task, correlationID)
 
 	// Send message to Kafka
-	if err := s.producer.SendMessage(ctx, TopicScrapingTasks, msg.TaskID.String(), msg, nil); err != nil {
-		return fmt.Errorf("failed to send scraping task to Kafka: %w", err)
+	topic := kafka.RegionTopic(TopicScrapingTasks, region)
+	if err := s.producer.SendMessage(ctx, topic, msg.TaskID.String(), msg, nil); err != nil {
+		if captureHAR {
+			if err := s.urlRepo.ReleaseHARCapture(ctx, url.ID, task.ID); err != nil {
+				s.logger.WithError(err).WithField("url_id", url.ID).Warn("Failed to release HAR capture")
+			}
+		}
+		return nil, fmt.Errorf("failed to send scraping task to Kafka: %w", err)
 	}
 
 	s.logger.Printf("Sent scraping task to Kafka: %s", task.ID)
 
-	// Update URL status and last scraped time
-	if err := s.urlRepo.UpdateLastScrapedTime(ctx, url.ID, time.Now().UTC()); err != nil {
-		return fmt.Errorf("failed to update last scraped time: %w", err)
+	// Record the task so its result can be matched and classified. The
+	// task has already been sent, so a failure here is not fatal.
+	if err := s.taskRepo.CreateTask(ctx, task.ID, task.URLID, task.Attempt, dueAt); err != nil {
+		s.logger.WithError(err).WithField("task_id", task.ID).Warn("Failed to record scraping task")
 	}
+	return task, nil
+}
 
-	nextScrape, err := models.CalculateNextScrapeTime(url.Frequency, time.Now().UTC())
+// deferURL moves the next scrape of a URL whose budget is used up to the
+// start of the next day and records a budget-exhausted event. The first
+// deferral of a budget on a day is logged as a warning.
+func (s *URLSchedulerService) deferURL(ctx context.Context, url database.Url, budget scrapeBudget, budgets *budgetTracker) error {
+	nextScrape := budgets.nextDay()
+	if err := s.urlRepo.UpdateNextScrapeTime(ctx, url.ID, nextScrape); err != nil {
+		return fmt.Errorf("failed to defer URL over budget: %w", err)
+	}
+
+	fields := logrus.Fields{
+		"url_id":         url.ID,
+		"scope":          budget.scope,
+		"budget":         budget.name,
+		"daily_limit":    budget.limit,
+		"next_scrape_at": nextScrape.Format(time.RFC3339),
+	}
+	first, err := s.budgetRepo.RecordBudgetExhausted(ctx, budget.scope, budget.name, budgets.day, budget.limit)
 	if err != nil {
-		return fmt.Errorf("failed to calculate next scrape time: %w", err)
+		// The URL is already deferred, so a missing event is not fatal
+		s.logger.WithError(err).WithFields(fields).Warn("Failed to record exhausted scrape budget")
+		return nil
+	}
+	if first {
+		s.logger.WithFields(fields).Warn("Daily scrape budget exhausted, deferring scrapes to the next day")
+	} else {
+		s.logger.WithFields(fields).Debug("Scrape deferred, daily budget exhausted")
 	}
+	return nil
+}
 
-	if err := s.urlRepo.UpdateNextScrapeTime(ctx, url.ID, nextScrape); err != nil {
-		return fmt.Errorf("failed to update next scrape time: %w", err)
+// effectiveRetryPolicy returns the URL's effective retry policy. A stored
+// policy that cannot be decoded is logged and replaced by the default policy.
+func effectiveRetryPolicy(url database.Url, logger *logrus.Logger) sharedmodels.RetryPolicy {
+	if !url.RetryPolicy.Valid {
+		return sharedmodels.DefaultRetryPolicy(int(url.MaxRetries))
 	}
 
-	s.logger.Printf("Updated URL %s: next scrape at %s", url.ID, nextScrape.UTC().Format(time.RFC3339))
+	var policy sharedmodels.RetryPolicy
+	if err := json.Unmarshal(url.RetryPolicy.RawMessage, &policy); err != nil {
+		logger.WithError(err).WithField("url_id", url.ID).Warn("Invalid retry policy, using default")
+		return sharedmodels.DefaultRetryPolicy(int(url.MaxRetries))
+	}
+	return sharedmodels.EffectiveRetryPolicy(&policy, int(url.MaxRetries))
+}
 
-	return nil
+// urlAssertions returns the URL's content assertions, or nil if it has none.
+// Stored assertions that cannot be decoded are logged and skipped.
+func urlAssertions(url database.Url, logger *logrus.Logger) *sharedmodels.Assertions {
+	if !url.Assertions.Valid {
+		return nil
+	}
+
+	var assertions sharedmodels.Assertions
+	if err := json.Unmarshal(url.Assertions.RawMessage, &assertions); err != nil {
+		logger.WithError(err).WithField("url_id", url.ID).Warn("Invalid assertions, scraping without them")
+		return nil
+	}
+	return &assertions
+}
+
+// urlArchivePolicy returns the URL's raw HTML archive policy, or nil if it
+// has none. A stored policy that cannot be decoded is logged and skipped, so
+// every scrape is kept.
+func urlArchivePolicy(url database.Url, logger *logrus.Logger) *sharedmodels.ArchivePolicy {
+	if !url.ArchivePolicy.Valid {
+		return nil
+	}
+
+	var policy sharedmodels.ArchivePolicy
+	if err := json.Unmarshal(url.ArchivePolicy.RawMessage, &policy); err != nil {
+		logger.WithError(err).WithField("url_id", url.ID).Warn("Invalid archive policy, archiving every scrape")
+		return nil
+	}
+	return &policy
 }
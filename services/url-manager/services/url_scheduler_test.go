@@ -0,0 +1,793 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/contract"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/domain"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// fakeURLRepository is an in-memory URLRepository for scheduler tests
+type fakeURLRepository struct {
+	repositories.URLRepository
+	scheduled     []database.Url
+	lastLimit     int32
+	lastFrom      time.Time
+	lastTo        time.Time
+	lastShares    config.ProjectShares
+	lastScraped   map[uuid.UUID]time.Time
+	nextScrapeAts map[uuid.UUID]time.Time
+	urls          map[uuid.UUID]*database.Url
+	overdue       []database.Url
+	failing       []database.Url
+	lastBefore    time.Time
+	lastFailures  int32
+	active        []database.Url
+	upserts       []database.UpsertURLParams
+	deletedIDs    []uuid.UUID
+	moves         []*database.UrlMove
+	harCaptures   map[uuid.UUID]*database.HarCapture
+}
+
+func (f *fakeURLRepository) ListURLs(ctx context.Context) ([]database.Url, error) {
+	return f.active, nil
+}
+
+func (f *fakeURLRepository) UpsertURL(ctx context.Context, arg database.UpsertURLParams) (database.UpsertURLRow, error) {
+	f.upserts = append(f.upserts, arg)
+	for _, url := range f.active {
+		if url.Url == arg.Url {
+			return database.UpsertURLRow{ID: url.ID}, nil
+		}
+	}
+	return database.UpsertURLRow{ID: uuid.New(), Inserted: true}, nil
+}
+
+func (f *fakeURLRepository) DeleteURLs(ctx context.Context, ids []uuid.UUID) ([]database.SoftDeleteURLsRow, error) {
+	f.deletedIDs = append(f.deletedIDs, ids...)
+	deleted := make([]database.SoftDeleteURLsRow, 0, len(ids))
+	for _, url := range f.active {
+		for _, id := range ids {
+			if url.ID == id {
+				deleted = append(deleted, database.SoftDeleteURLsRow{ID: id, Url: url.Url})
+			}
+		}
+	}
+	return deleted, nil
+}
+
+func (f *fakeURLRepository) GetOverdueURLs(ctx context.Context, before time.Time, limit int32) ([]database.Url, error) {
+	f.lastBefore = before
+	return f.overdue, nil
+}
+
+func (f *fakeURLRepository) GetURLsWithConsecutiveFailures(ctx context.Context, failures, limit int32) ([]database.Url, error) {
+	f.lastFailures = failures
+	return f.failing, nil
+}
+
+func (f *fakeURLRepository) GetURLByID(ctx context.Context, id uuid.UUID) (*database.Url, error) {
+	url, ok := f.urls[id]
+	if !ok {
+		return nil, domain.ErrURLNotFound.Wrap(sql.ErrNoRows)
+	}
+	return url, nil
+}
+
+func (f *fakeURLRepository) UpdateURLStatus(ctx context.Context, id uuid.UUID, status string) error {
+	f.urls[id].Status = status
+	return nil
+}
+
+func (f *fakeURLRepository) IncrementRetryCount(ctx context.Context, id uuid.UUID) error {
+	f.urls[id].RetryCount++
+	return nil
+}
+
+func (f *fakeURLRepository) ResetRetryCount(ctx context.Context, id uuid.UUID) error {
+	f.urls[id].RetryCount = 0
+	return nil
+}
+
+func (f *fakeURLRepository) GetURLsScheduledForScraping(ctx context.Context, from, to time.Time, limit int32, shares config.ProjectShares) ([]database.Url, error) {
+	f.lastLimit = limit
+	f.lastFrom, f.lastTo = from, to
+	f.lastShares = shares
+	return f.scheduled, nil
+}
+
+func (f *fakeURLRepository) UpdateLastScrapedTime(ctx context.Context, id uuid.UUID, lastScrapedAt time.Time) error {
+	f.lastScraped[id] = lastScrapedAt
+	return nil
+}
+
+func (f *fakeURLRepository) UpdateNextScrapeTime(ctx context.Context, id uuid.UUID, nextScrapeAt time.Time) error {
+	f.nextScrapeAts[id] = nextScrapeAt
+	return nil
+}
+
+func (f *fakeURLRepository) RecordMove(ctx context.Context, id uuid.UUID, from, to string, seenAt time.Time) (database.UrlMove, error) {
+	for _, move := range f.moves {
+		if move.UrlID == id && move.FromUrl == from && move.ToUrl == to {
+			move.Hits++
+			move.LastSeenAt = seenAt
+			return *move, nil
+		}
+	}
+	move := &database.UrlMove{UrlID: id, FromUrl: from, ToUrl: to, Hits: 1, FirstSeenAt: seenAt, LastSeenAt: seenAt}
+	f.moves = append(f.moves, move)
+	return *move, nil
+}
+
+func (f *fakeURLRepository) ClearPendingMoves(ctx context.Context, id uuid.UUID, keep string) error {
+	kept := f.moves[:0]
+	for _, move := range f.moves {
+		if move.UrlID != id || move.FlaggedAt.Valid || move.ToUrl == keep {
+			kept = append(kept, move)
+		}
+	}
+	f.moves = kept
+	return nil
+}
+
+func (f *fakeURLRepository) FlagMove(ctx context.Context, move database.UrlMove, flaggedAt time.Time) error {
+	for _, m := range f.moves {
+		if m.UrlID == move.UrlID && m.FromUrl == move.FromUrl && m.ToUrl == move.ToUrl {
+			m.FlaggedAt = sql.NullTime{Time: flaggedAt, Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *fakeURLRepository) ApplyMove(ctx context.Context, move database.UrlMove, appliedAt time.Time) (bool, error) {
+	for _, url := range f.urls {
+		if url.Url == move.ToUrl {
+			return false, nil
+		}
+	}
+	f.urls[move.UrlID].Url = move.ToUrl
+	for _, m := range f.moves {
+		if m.UrlID == move.UrlID && m.FromUrl == move.FromUrl && m.ToUrl == move.ToUrl {
+			m.AppliedAt = sql.NullTime{Time: appliedAt, Valid: true}
+		}
+	}
+	return true, nil
+}
+
+func (f *fakeURLRepository) ClaimHARCapture(ctx context.Context, id, taskID uuid.UUID) (bool, error) {
+	capture, ok := f.harCaptures[id]
+	if !ok || capture.TaskID.Valid {
+		return false, nil
+	}
+	capture.TaskID = uuid.NullUUID{UUID: taskID, Valid: true}
+	return true, nil
+}
+
+func (f *fakeURLRepository) ReleaseHARCapture(ctx context.Context, id, taskID uuid.UUID) error {
+	if capture, ok := f.harCaptures[id]; ok && capture.TaskID.UUID == taskID && !capture.Har.Valid {
+		capture.TaskID = uuid.NullUUID{}
+	}
+	return nil
+}
+
+func (f *fakeURLRepository) SaveHARCapture(ctx context.Context, id, taskID uuid.UUID, har []byte, expiresAt time.Time) (bool, error) {
+	capture, ok := f.harCaptures[id]
+	if !ok || capture.TaskID.UUID != taskID || capture.Har.Valid {
+		return false, nil
+	}
+	capture.Har = pqtype.NullRawMessage{RawMessage: har, Valid: true}
+	capture.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (f *fakeURLRepository) DeleteExpiredHARCaptures(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// fakeProducer records the messages sent by the scheduler and their topics
+type fakeProducer struct {
+	sent   []*ScrapingTaskMessage
+	topics []string
+}
+
+func (f *fakeProducer) SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error {
+	f.sent = append(f.sent, value.(*ScrapingTaskMessage))
+	f.topics = append(f.topics, topic)
+	return nil
+}
+
+func (f *fakeProducer) Close() error { return nil }
+
+// fakeTaskRepository is an in-memory TaskRepository
+type fakeTaskRepository struct {
+	tasks map[uuid.UUID]*database.ScrapingTask
+}
+
+func newFakeTaskRepository() *fakeTaskRepository {
+	return &fakeTaskRepository{tasks: make(map[uuid.UUID]*database.ScrapingTask)}
+}
+
+func (f *fakeTaskRepository) CreateTask(ctx context.Context, id, urlID uuid.UUID, attempt int, dueAt time.Time) error {
+	f.tasks[id] = &database.ScrapingTask{ID: id, UrlID: urlID, Attempt: int32(attempt), Status: TaskStatusPending, DueAt: sql.NullTime{Time: dueAt, Valid: true}}
+	return nil
+}
+
+func (f *fakeTaskRepository) GetTask(ctx context.Context, id uuid.UUID) (*database.ScrapingTask, error) {
+	task, ok := f.tasks[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return task, nil
+}
+
+func (f *fakeTaskRepository) CompleteTask(ctx context.Context, arg database.CompleteScrapingTaskParams) error {
+	task, ok := f.tasks[arg.ID]
+	if !ok {
+		return nil
+	}
+	task.Status = arg.Status
+	task.StatusCode = arg.StatusCode
+	task.ErrorCode = arg.ErrorCode
+	task.ErrorMessage = arg.ErrorMessage
+	task.CompletedAt = arg.CompletedAt
+	task.ProxyEgressBytes = arg.ProxyEgressBytes
+	task.RenderMs = arg.RenderMs
+	task.ThrottledMs = arg.ThrottledMs
+	task.FinalUrl = arg.FinalUrl
+	task.RedirectChain = arg.RedirectChain
+	task.ResponseHeaders = arg.ResponseHeaders
+	task.DnsMs = arg.DnsMs
+	return nil
+}
+
+// fakeBudgetRepository is an in-memory BudgetRepository for scheduler tests
+type fakeBudgetRepository struct {
+	domainScrapes  map[string]int64
+	projectScrapes map[string]int64
+	events         map[string]int // Deferred scrapes per "scope/name"
+}
+
+func newFakeBudgetRepository() *fakeBudgetRepository {
+	return &fakeBudgetRepository{
+		domainScrapes:  make(map[string]int64),
+		projectScrapes: make(map[string]int64),
+		events:         make(map[string]int),
+	}
+}
+
+func (f *fakeBudgetRepository) CountDomainScrapes(ctx context.Context, domain string, since time.Time) (int64, error) {
+	return f.domainScrapes[domain], nil
+}
+
+func (f *fakeBudgetRepository) CountProjectScrapes(ctx context.Context, project string, since time.Time) (int64, error) {
+	return f.projectScrapes[project], nil
+}
+
+func (f *fakeBudgetRepository) RecordBudgetExhausted(ctx context.Context, scope, name string, day time.Time, dailyLimit int) (bool, error) {
+	key := scope + "/" + name
+	f.events[key]++
+	return f.events[key] == 1, nil
+}
+
+// fakeWorkerRepository is an in-memory WorkerRepository for scheduler tests
+type fakeWorkerRepository struct {
+	healthy   []string
+	lastSince time.Time
+	calls     int
+}
+
+func (f *fakeWorkerRepository) ListHealthyRegions(ctx context.Context, since time.Time) ([]string, error) {
+	f.lastSince = since
+	f.calls++
+	return f.healthy, nil
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestScheduler(repo *fakeURLRepository, producer *fakeProducer) *URLSchedulerService {
+	return NewURLSchedulerService(repo, newFakeTaskRepository(), newFakeBudgetRepository(), &fakeWorkerRepository{}, producer, newTestLogger())
+}
+
+func TestProcessScheduledURLs(t *testing.T) {
+	now := time.Now().UTC()
+	due := database.Url{ID: uuid.New(), Url: "https://example.com/due", Frequency: "1h",
+		NextScrapeAt: sql.NullTime{Time: now.Add(-30 * time.Second), Valid: true}}
+	notDue := database.Url{ID: uuid.New(), Url: "https://example.com/later", Frequency: "1h",
+		NextScrapeAt: sql.NullTime{Time: now.Add(3 * time.Minute), Valid: true}}
+
+	repo := &fakeURLRepository{
+		scheduled:     []database.Url{due, notDue},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	producer := &fakeProducer{}
+	taskRepo := newFakeTaskRepository()
+	scheduler := NewURLSchedulerService(repo, taskRepo, newFakeBudgetRepository(), &fakeWorkerRepository{}, producer, newTestLogger())
+
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+
+	if len(producer.sent) != 1 || producer.sent[0].URLID != due.ID {
+		t.Fatalf("sent %d tasks, want exactly one for the due URL", len(producer.sent))
+	}
+	if task := taskRepo.tasks[producer.sent[0].TaskID]; task == nil || !task.DueAt.Time.Equal(due.NextScrapeAt.Time) {
+		t.Errorf("recorded task = %+v, want it due at the URL's next scrape time", task)
+	}
+	if _, ok := repo.lastScraped[due.ID]; !ok {
+		t.Error("last scraped time not updated for due URL")
+	}
+	if next := repo.nextScrapeAts[due.ID]; next.Before(now.Add(59 * time.Minute)) {
+		t.Errorf("next scrape time = %v, want about an hour from now", next)
+	}
+	if _, ok := repo.nextScrapeAts[notDue.ID]; ok {
+		t.Error("URL that is not due yet was rescheduled")
+	}
+}
+
+func TestProcessScheduledURLsAppliesFrequencyFloor(t *testing.T) {
+	now := time.Now().UTC()
+	due := sql.NullTime{Time: now.Add(-time.Second), Valid: true}
+	partner := database.Url{ID: uuid.New(), Url: "https://api.partner.example.com/feed", Frequency: "1m", NextScrapeAt: due}
+	slow := database.Url{ID: uuid.New(), Url: "https://example.com/news", Frequency: "1d", NextScrapeAt: due}
+	other := database.Url{ID: uuid.New(), Url: "https://example.com/prices", Frequency: "1m", NextScrapeAt: due}
+
+	repo := &fakeURLRepository{
+		scheduled:     []database.Url{partner, slow, other},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	scheduler := newTestScheduler(repo, &fakeProducer{})
+	scheduler.SetFrequencyPolicy(config.FrequencyPolicyConfig{
+		MinFrequency: 5 * time.Minute,
+		Floors:       []config.FrequencyFloorConfig{{Domain: "partner.example.com", MinFrequency: time.Hour}},
+	})
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+
+	want := map[uuid.UUID]time.Duration{partner.ID: time.Hour, slow.ID: 24 * time.Hour, other.ID: 5 * time.Minute}
+	for id, interval := range want {
+		if next := repo.nextScrapeAts[id]; next.Before(now.Add(interval)) || next.After(time.Now().UTC().Add(interval)) {
+			t.Errorf("URL %s next scrape = %v, want %v from now", id, next, interval)
+		}
+	}
+}
+
+func TestProcessScheduledURLsSharesBatchByProject(t *testing.T) {
+	due := sql.NullTime{Time: time.Now().UTC().Add(-time.Second), Valid: true}
+	url := database.Url{ID: uuid.New(), Url: "https://example.com/news", Frequency: "1h", NextScrapeAt: due, Project: "news"}
+	repo := &fakeURLRepository{
+		scheduled:     []database.Url{url},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	producer := &fakeProducer{}
+	scheduler := newTestScheduler(repo, producer)
+	scheduler.SetProjectShares(config.ProjectShares{"news": 3})
+
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if repo.lastShares.Share("news") != 3 {
+		t.Errorf("shares = %v, want the configured shares", repo.lastShares)
+	}
+	if len(producer.sent) != 1 || producer.sent[0].Project != "news" {
+		t.Errorf("sent = %+v, want a task of project news", producer.sent)
+	}
+}
+
+func TestProcessScheduledURLsCarriesRetryPolicy(t *testing.T) {
+	due := sql.NullTime{Time: time.Now().UTC().Add(-time.Second), Valid: true}
+	custom := database.Url{ID: uuid.New(), Url: "https://example.com/flaky", Frequency: "1h", NextScrapeAt: due,
+		MaxRetries: 3, RetryCount: 2, RateLimit: 30,
+		RetryPolicy: pqtype.NullRawMessage{RawMessage: []byte(`{"max_attempts":8,"retry_on_status":[429]}`), Valid: true}}
+	legacy := database.Url{ID: uuid.New(), Url: "https://example.com/stable", Frequency: "1h", NextScrapeAt: due,
+		MaxRetries: 3}
+
+	repo := &fakeURLRepository{
+		scheduled:     []database.Url{custom, legacy},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	producer := &fakeProducer{}
+
+	if err := newTestScheduler(repo, producer).processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if len(producer.sent) != 2 {
+		t.Fatalf("sent %d tasks, want 2", len(producer.sent))
+	}
+
+	got := producer.sent[0]
+	if got.Attempt != 3 || got.RetryPolicy.MaxAttempts != 8 || len(got.RetryPolicy.RetryOnStatus) != 1 || got.RetryPolicy.BackoffBaseMs == 0 {
+		t.Errorf("custom policy task = attempt %d, policy %+v", got.Attempt, got.RetryPolicy)
+	}
+	if got.RateLimit != 30 {
+		t.Errorf("task rate limit = %d, want the URL's 30", got.RateLimit)
+	}
+	if got := producer.sent[1]; got.Attempt != 1 || got.RetryPolicy.MaxAttempts != 4 {
+		t.Errorf("legacy task = attempt %d, policy %+v; want max_attempts from max_retries", got.Attempt, got.RetryPolicy)
+	}
+}
+
+func TestProcessScheduledURLsDefersURLsOverBudget(t *testing.T) {
+	now := time.Now().UTC()
+	due := sql.NullTime{Time: now.Add(-time.Second), Valid: true}
+	scheduled := func(address, project string) database.Url {
+		return database.Url{ID: uuid.New(), Url: address, Frequency: "1h", NextScrapeAt: due, Project: project}
+	}
+	first := scheduled("https://shop.example.com/a", "")
+	second := scheduled("https://example.com/b", "")
+	third := scheduled("https://api.example.com/c", "")
+	other := scheduled("https://other.org/d", "growth")
+	otherProject := scheduled("https://other.org/e", "growth")
+
+	repo := &fakeURLRepository{
+		scheduled:     []database.Url{first, second, third, other, otherProject},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	budgetRepo := newFakeBudgetRepository()
+	budgetRepo.domainScrapes["example.com"] = 8
+	budgetRepo.projectScrapes["growth"] = 5
+	producer := &fakeProducer{}
+	scheduler := NewURLSchedulerService(repo, newFakeTaskRepository(), budgetRepo, &fakeWorkerRepository{}, producer, newTestLogger())
+	scheduler.Configure(config.SchedulerConfig{Enabled: true, Budgets: []config.ScrapeBudgetConfig{
+		{Domain: "Example.com", DailyLimit: 10},
+		{Project: "growth", DailyLimit: 5},
+		{Domain: "invalid.com", Project: "growth", DailyLimit: 1},
+	}})
+
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+
+	if len(producer.sent) != 2 || producer.sent[0].URLID != first.ID || producer.sent[1].URLID != second.ID {
+		t.Fatalf("sent %d tasks, want the first two example.com URLs", len(producer.sent))
+	}
+	tomorrow := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	for _, url := range []database.Url{third, other, otherProject} {
+		if got := repo.nextScrapeAts[url.ID]; !got.Equal(tomorrow) {
+			t.Errorf("%s next scrape = %v, want deferred to %v", url.Url, got, tomorrow)
+		}
+	}
+	if budgetRepo.events["domain/example.com"] != 1 || budgetRepo.events["project/growth"] != 2 {
+		t.Errorf("budget events = %v, want 1 for example.com and 2 for growth", budgetRepo.events)
+	}
+	if run := scheduler.RunStatus().LastRun; run.TasksPublished != 2 || run.URLsDeferred != 3 {
+		t.Errorf("run published %d and deferred %d, want 2 and 3", run.TasksPublished, run.URLsDeferred)
+	}
+}
+
+func TestSchedulerRunStatus(t *testing.T) {
+	now := time.Now().UTC()
+	due := sql.NullTime{Time: now.Add(-time.Second), Valid: true}
+	repo := &fakeURLRepository{
+		scheduled: []database.Url{
+			{ID: uuid.New(), Url: "https://example.com/due", Frequency: "1h", NextScrapeAt: due},
+			{ID: uuid.New(), Url: "https://example.com/later", Frequency: "1h", NextScrapeAt: sql.NullTime{Time: now.Add(time.Minute), Valid: true}},
+			{ID: uuid.New(), Url: "https://example.com/broken", Frequency: "often", NextScrapeAt: due},
+		},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	scheduler := newTestScheduler(repo, &fakeProducer{})
+
+	if status := scheduler.RunStatus(); status.LastRun != nil || status.Totals.Runs != 0 {
+		t.Errorf("RunStatus() before a pass = %+v, want no runs", status)
+	}
+	for i := 0; i < 2; i++ {
+		if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+			t.Fatalf("processScheduledURLs() error = %v", err)
+		}
+	}
+
+	status := scheduler.RunStatus()
+	run := status.LastRun
+	if !status.Enabled || run == nil {
+		t.Fatalf("RunStatus() = %+v, want an enabled scheduler with a run", status)
+	}
+	if run.URLsConsidered != 3 || run.TasksPublished != 2 || run.Errors != 1 || run.Error != "" {
+		t.Errorf("last run = %+v, want 3 considered, 2 published and 1 error", run)
+	}
+	if run.FinishedAt.Before(run.StartedAt) || run.DurationMS < 0 {
+		t.Errorf("last run timing = %v to %v (%dms)", run.StartedAt, run.FinishedAt, run.DurationMS)
+	}
+	want := SchedulerTotals{Runs: 2, URLsConsidered: 6, TasksPublished: 4, Errors: 2}
+	if status.Totals != want {
+		t.Errorf("totals = %+v, want %+v", status.Totals, want)
+	}
+}
+
+func TestProcessScheduledURLsRoutesByRegion(t *testing.T) {
+	now := time.Now().UTC()
+	due := sql.NullTime{Time: now.Add(-time.Second), Valid: true}
+	scheduled := func(region string) database.Url {
+		return database.Url{ID: uuid.New(), Url: "https://example.com/" + region, Frequency: "1h", NextScrapeAt: due, Region: region}
+	}
+	urls := []database.Url{scheduled(""), scheduled("eu-west"), scheduled("us-east"), scheduled("ap-south"), scheduled("sa-east")}
+
+	repo := &fakeURLRepository{
+		scheduled:     urls,
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	workerRepo := &fakeWorkerRepository{healthy: []string{"eu-west", "eu-central"}}
+	producer := &fakeProducer{}
+	scheduler := NewURLSchedulerService(repo, newFakeTaskRepository(), newFakeBudgetRepository(), workerRepo, producer, newTestLogger())
+	scheduler.Configure(config.SchedulerConfig{
+		Enabled:             true,
+		RegionHealthTimeout: time.Minute,
+		RegionFallbacks:     map[string]string{"us-east": "eu-central", "ap-south": "sa-east", "sa-east": "ap-south", "Bad": "eu-west"},
+	})
+
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+
+	want := []string{"scraping-tasks", "scraping-tasks.eu-west", "scraping-tasks.eu-central", "scraping-tasks", "scraping-tasks"}
+	if len(producer.topics) != len(want) {
+		t.Fatalf("sent %d tasks, want %d", len(producer.topics), len(want))
+	}
+	for i, topic := range want {
+		if producer.topics[i] != topic {
+			t.Errorf("%s task sent to %q, want %q", urls[i].Url, producer.topics[i], topic)
+		}
+		if producer.sent[i].Region != urls[i].Region {
+			t.Errorf("%s task region = %q, want %q", urls[i].Url, producer.sent[i].Region, urls[i].Region)
+		}
+	}
+	if workerRepo.calls != 1 {
+		t.Errorf("healthy regions loaded %d times, want once per pass", workerRepo.calls)
+	}
+	if age := now.Sub(workerRepo.lastSince); age < time.Minute-time.Second || age > time.Minute+time.Second {
+		t.Errorf("healthy since %v ago, want the region health timeout", age)
+	}
+}
+
+func TestTriggerURL(t *testing.T) {
+	next := time.Now().UTC().Add(time.Hour)
+	url := &database.Url{ID: uuid.New(), Url: "https://example.com/now", Frequency: "1h", Status: "disabled", Region: "eu-west",
+		NextScrapeAt: sql.NullTime{Time: next, Valid: true}}
+	deleted := &database.Url{ID: uuid.New(), Url: "https://example.com/gone", DeletedAt: sql.NullTime{Time: next, Valid: true}}
+	repo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url, deleted.ID: deleted},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	producer := &fakeProducer{}
+	taskRepo := newFakeTaskRepository()
+	workerRepo := &fakeWorkerRepository{healthy: []string{"eu-west"}}
+	scheduler := NewURLSchedulerService(repo, taskRepo, newFakeBudgetRepository(), workerRepo, producer, newTestLogger())
+
+	task, topic, err := scheduler.TriggerURL(context.Background(), url.ID)
+	if err != nil {
+		t.Fatalf("TriggerURL() error = %v", err)
+	}
+	if topic != "scraping-tasks.eu-west" || len(producer.topics) != 1 || producer.topics[0] != topic {
+		t.Errorf("task sent to %v, returned topic %q, want scraping-tasks.eu-west", producer.topics, topic)
+	}
+	if task.URLID != url.ID || producer.sent[0].TaskID != task.ID {
+		t.Errorf("TriggerURL() task = %+v, sent %+v", task, producer.sent[0])
+	}
+	if _, ok := taskRepo.tasks[task.ID]; !ok {
+		t.Error("triggered task was not recorded")
+	}
+	if _, ok := repo.lastScraped[url.ID]; !ok {
+		t.Error("last scraped time was not updated")
+	}
+	if len(repo.nextScrapeAts) != 0 {
+		t.Errorf("next scrape times updated = %v, want the schedule kept", repo.nextScrapeAts)
+	}
+
+	for _, id := range []uuid.UUID{deleted.ID, uuid.New()} {
+		if _, _, err := scheduler.TriggerURL(context.Background(), id); !errors.Is(err, ErrURLNotFound) {
+			t.Errorf("TriggerURL(%s) error = %v, want ErrURLNotFound", id, err)
+		}
+	}
+	if len(producer.topics) != 1 {
+		t.Errorf("sent %d tasks, want 1", len(producer.topics))
+	}
+}
+
+func TestTriggerURLChecksBudgets(t *testing.T) {
+	url := &database.Url{ID: uuid.New(), Url: "https://example.com/now", Frequency: "1h", Project: "growth"}
+	repo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{url.ID: url},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	budgetRepo := newFakeBudgetRepository()
+	budgetRepo.projectScrapes["growth"] = 5
+	producer := &fakeProducer{}
+	scheduler := NewURLSchedulerService(repo, newFakeTaskRepository(), budgetRepo, &fakeWorkerRepository{}, producer, newTestLogger())
+	scheduler.Configure(config.SchedulerConfig{Enabled: true, Budgets: []config.ScrapeBudgetConfig{{Project: "growth", DailyLimit: 5}}})
+
+	_, _, err := scheduler.TriggerURL(context.Background(), url.ID)
+	if !errors.Is(err, domain.ErrRateLimited) {
+		t.Fatalf("TriggerURL() error = %v, want ErrRateLimited", err)
+	}
+	if retryAfter := domain.RetryAfter(err); retryAfter <= 0 || retryAfter > 24*time.Hour {
+		t.Errorf("retry after %v, want until the next UTC day", retryAfter)
+	}
+	if len(producer.sent) != 0 {
+		t.Errorf("sent %d tasks over budget, want none", len(producer.sent))
+	}
+
+	budgetRepo.projectScrapes["growth"] = 4
+	if _, _, err := scheduler.TriggerURL(context.Background(), url.ID); err != nil {
+		t.Fatalf("TriggerURL() within budget error = %v", err)
+	}
+	if len(producer.sent) != 1 {
+		t.Errorf("sent %d tasks within budget, want 1", len(producer.sent))
+	}
+}
+
+// fakeMaintenance is a maintenance mode switch
+type fakeMaintenance struct {
+	enabled bool
+}
+
+func (f *fakeMaintenance) Enabled() bool { return f.enabled }
+
+func TestSchedulerSkipsDuringMaintenance(t *testing.T) {
+	now := time.Now().UTC()
+	due := database.Url{ID: uuid.New(), Url: "https://example.com/due", Frequency: "1h",
+		NextScrapeAt: sql.NullTime{Time: now.Add(-30 * time.Second), Valid: true}}
+	repo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{due.ID: &due},
+		scheduled:     []database.Url{due},
+		lastScraped:   make(map[uuid.UUID]time.Time),
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+	}
+	producer := &fakeProducer{}
+	mode := &fakeMaintenance{enabled: true}
+	scheduler := newTestScheduler(repo, producer)
+	scheduler.SetMaintenance(mode)
+
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if _, _, err := scheduler.TriggerURL(context.Background(), due.ID); !errors.Is(err, ErrMaintenance) {
+		t.Errorf("TriggerURL() error = %v, want ErrMaintenance", err)
+	}
+	if len(producer.sent) != 0 {
+		t.Fatalf("sent %d tasks during maintenance, want none", len(producer.sent))
+	}
+	if status := scheduler.RunStatus(); !status.Maintenance || status.LastRun != nil {
+		t.Errorf("RunStatus() = %+v, want maintenance without a recorded run", status)
+	}
+
+	mode.enabled = false
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if len(producer.sent) != 1 {
+		t.Errorf("sent %d tasks after maintenance, want 1", len(producer.sent))
+	}
+}
+
+func TestSchedulerStartStop(t *testing.T) {
+	scheduler := newTestScheduler(&fakeURLRepository{}, &fakeProducer{})
+
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := scheduler.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestSchedulerConfigure(t *testing.T) {
+	repo := &fakeURLRepository{}
+	scheduler := newTestScheduler(repo, &fakeProducer{})
+
+	scheduler.Configure(config.SchedulerConfig{Enabled: true, BatchSize: 25, CheckInterval: time.Minute, Lookback: 2 * time.Minute, Lookahead: 10 * time.Minute})
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if repo.lastLimit != 25 {
+		t.Errorf("batch size = %d, want 25", repo.lastLimit)
+	}
+	if window := repo.lastTo.Sub(repo.lastFrom); window != 12*time.Minute {
+		t.Errorf("lookup window = %s, want 12m", window)
+	}
+
+	status := scheduler.Status()
+	want := SchedulerSettings{Enabled: true, CheckInterval: "1m0s", BatchSize: 25, Lookback: "2m0s", Lookahead: "10m0s"}
+	if status.Settings != want {
+		t.Errorf("Status().Settings = %+v, want %+v", status.Settings, want)
+	}
+	if status.LastRun == nil || status.LastRun.StartedAt.IsZero() || status.LastRun.FinishedAt.Before(status.LastRun.StartedAt) {
+		t.Errorf("Status().LastRun = %+v, want the pass", status.LastRun)
+	}
+
+	scheduler.Configure(config.SchedulerConfig{Enabled: true})
+	if settings := scheduler.Status().Settings; settings.Lookback != "1m0s" || settings.Lookahead != "5m0s" || settings.BatchSize != DefaultSchedulerBatchSize {
+		t.Errorf("Status().Settings without values = %+v, want the defaults", settings)
+	}
+
+	repo.lastLimit = 0
+	scheduler.Configure(config.SchedulerConfig{Enabled: false})
+	if err := scheduler.processScheduledURLs(context.Background()); err != nil {
+		t.Fatalf("processScheduledURLs() error = %v", err)
+	}
+	if repo.lastLimit != 0 {
+		t.Error("disabled scheduler still queried for URLs")
+	}
+}
+
+func TestScrapingTaskMessageContract(t *testing.T) {
+	task := &ScrapingTask{
+		ID:            uuid.New(),
+		URLID:         uuid.New(),
+		URL:           "https://example.com/products/42",
+		Status:        TaskStatusPending,
+		Attempt:       2,
+		RetryPolicy:   sharedmodels.RetryPolicy{MaxAttempts: 4, BackoffBaseMs: 1000, BackoffCapMs: 60000, RetryOnStatus: []int{503}},
+		Assertions:    &sharedmodels.Assertions{Status: 200, Contains: []string{"Add to cart"}, Selectors: []string{".price"}},
+		Region:        "eu",
+		RateLimit:     30,
+		ArchivePolicy: &sharedmodels.ArchivePolicy{Mode: sharedmodels.ArchiveModeSample, SampleEvery: 10, RenderSession: true},
+		CaptureHAR:    true,
+		Project:       "shop",
+		CreatedAt:     time.Now().UTC(),
+	}
+	if unset := contract.UnsetFields(task); len(unset) > 0 {
+		t.Fatalf("task fields %v are not set", unset)
+	}
+
+	data, err := json.Marshal(NewScrapingTaskMessage(task, uuid.NewString()))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got, _ := contract.Keys(data)
+	want, _ := contract.Keys(contract.Fixture(contract.ScrapingTask))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("message fields = %v, want those of the contract %v", got, want)
+	}
+
+	// Scrapers get every field of the task
+	var msg sharedmodels.ScrapingTaskMessage
+	if err := contract.DecodeStrict(data, &msg); err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+	if unset := contract.UnsetFields(&msg); len(unset) > 0 {
+		t.Errorf("message fields %v are not set from the task", unset)
+	}
+	sent := make(map[string]bool)
+	for _, key := range got {
+		sent[key] = true
+	}
+	taskData, _ := json.Marshal(task)
+	taskKeys, _ := contract.Keys(taskData)
+	for _, key := range taskKeys {
+		switch key {
+		case "id", "status", "created_at": // Sent as task_id; not sent
+		default:
+			if !sent[key] {
+				t.Errorf("task field %s is not sent", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,620 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/services/url-manager/models"
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/events"
+	sharedmodels "go_scraping_project/shared/models"
+	"go_scraping_project/shared/parser"
+	"go_scraping_project/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/sqlc-dev/pqtype"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSyncInterval is used when no sync interval is configured
+const DefaultSyncInterval = time.Minute
+
+// Defaults for declared URLs, matching the API's URL creation defaults
+const (
+	defaultURLMaxRetries = 3
+	defaultURLTimeout    = 30
+	defaultURLRateLimit  = 1
+	defaultURLUserAgent  = "GoScrapingBot/1.0"
+)
+
+// ErrSyncDisabled is returned by Sync when configuration-as-code sync is disabled
+var ErrSyncDisabled = errors.New("configuration sync is disabled")
+
+// SyncChange describes a declared URL whose stored configuration differs
+type SyncChange struct {
+	URL    string   `json:"url"`
+	Fields []string `json:"fields"` // Differing fields, e.g. frequency or parser_config
+}
+
+// SyncDrift lists the differences between the declared URLs and the database
+type SyncDrift struct {
+	Missing    []string     `json:"missing"`    // Declared URLs that do not exist or are deleted
+	Changed    []SyncChange `json:"changed"`    // Declared URLs whose configuration differs
+	Undeclared []string     `json:"undeclared"` // Managed URLs that are no longer declared
+}
+
+// SyncReport describes one reconciliation of the database with the declared URLs
+type SyncReport struct {
+	Path       string    `json:"path"`
+	DryRun     bool      `json:"dry_run"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Declared   int       `json:"declared"` // Number of declared URLs
+	InSync     bool      `json:"in_sync"`  // Whether no drift was found
+	Applied    bool      `json:"applied"`  // Whether the drift was corrected
+	Drift      SyncDrift `json:"drift"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// SyncStatus is the sync configuration together with the latest report
+type SyncStatus struct {
+	Config     config.SyncConfig `json:"config"`
+	LastReport *SyncReport       `json:"last_report,omitempty"`
+}
+
+// URLSyncService reconciles the database with URLs declared in YAML files
+// (configuration as code). Declared URLs are created or updated and marked
+// as managed; managed URLs that are no longer declared are soft-deleted when
+// pruning is enabled. URLs created through the API are never deleted, but
+// are adopted when they are declared.
+//
+// The declaration is re-read on every interval rather than watched for file
+// events, so it works the same for a single file and for a git checkout that
+// is updated by replacing the directory.
+type URLSyncService struct {
+	urlRepo  repositories.URLRepository
+	events   *events.URLEventPublisher
+	logger   *logrus.Logger
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	now      func() time.Time
+
+	// runMu serializes reconciliations from the ticker and the admin endpoint
+	runMu sync.Mutex
+
+	// Settings that can change at runtime, see Configure and
+	// SetFrequencyPolicy, and the latest report
+	mu     sync.Mutex
+	cfg    config.SyncConfig
+	policy config.FrequencyPolicyConfig
+	last   *SyncReport
+}
+
+// NewURLSyncService creates a new URL sync service that publishes the URLs
+// it creates, updates and deletes to publisher, if not nil. It does nothing
+// until enabled with Configure.
+func NewURLSyncService(urlRepo repositories.URLRepository, publisher *events.URLEventPublisher, logger *logrus.Logger) *URLSyncService {
+	return &URLSyncService{
+		urlRepo:  urlRepo,
+		events:   publisher,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+		now:      func() time.Time { return time.Now().UTC() },
+		cfg: config.SyncConfig{
+			Interval: DefaultSyncInterval,
+			Prune:    true,
+		},
+	}
+}
+
+// Configure applies sync settings. It is safe to call while the service is
+// running; a new interval takes effect on the next tick.
+func (s *URLSyncService) Configure(cfg config.SyncConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultSyncInterval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cfg.Interval != s.cfg.Interval && s.ticker != nil {
+		s.ticker.Reset(cfg.Interval)
+	}
+	if cfg != s.cfg {
+		s.logger.WithFields(logrus.Fields{
+			"enabled":  cfg.Enabled,
+			"path":     cfg.Path,
+			"interval": cfg.Interval.String(),
+			"prune":    cfg.Prune,
+			"dry_run":  cfg.DryRun,
+		}).Info("Sync settings updated")
+	}
+	s.cfg = cfg
+}
+
+// SetFrequencyPolicy sets the minimum frequencies declared URLs must
+// respect, as the API Gateway enforces for URLs created through the API
+func (s *URLSyncService) SetFrequencyPolicy(policy config.FrequencyPolicyConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+// Start starts the URL sync service
+func (s *URLSyncService) Start(ctx context.Context) error {
+	s.logger.Info("Starting URL Sync Service")
+
+	s.mu.Lock()
+	s.ticker = time.NewTicker(s.cfg.Interval)
+	s.mu.Unlock()
+
+	go s.run(ctx)
+
+	return nil
+}
+
+// Stop stops the URL sync service
+func (s *URLSyncService) Stop() error {
+	s.logger.Info("Stopping URL Sync Service")
+
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+
+	close(s.stopChan)
+	return nil
+}
+
+// run reconciles once at startup and then on every tick
+func (s *URLSyncService) run(ctx context.Context) {
+	s.syncIfEnabled(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-s.ticker.C:
+			s.syncIfEnabled(ctx)
+		}
+	}
+}
+
+// syncIfEnabled runs a reconciliation unless sync is disabled
+func (s *URLSyncService) syncIfEnabled(ctx context.Context) {
+	if _, err := s.Sync(ctx); err != nil && !errors.Is(err, ErrSyncDisabled) {
+		s.logger.WithError(err).Error("URL sync failed")
+	}
+}
+
+// Status returns the sync settings and the latest report
+func (s *URLSyncService) Status() SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SyncStatus{Config: s.cfg, LastReport: s.last}
+}
+
+// Sync reconciles the database with the declared URLs and returns the report,
+// which is also kept for Status. In dry-run mode the drift is only reported.
+func (s *URLSyncService) Sync(ctx context.Context) (*SyncReport, error) {
+	s.mu.Lock()
+	cfg := s.cfg
+	s.mu.Unlock()
+	if !cfg.Enabled {
+		return nil, ErrSyncDisabled
+	}
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	report := &SyncReport{
+		Path:      cfg.Path,
+		DryRun:    cfg.DryRun,
+		StartedAt: s.now(),
+		Drift: SyncDrift{
+			Missing:    []string{},
+			Changed:    []SyncChange{},
+			Undeclared: []string{},
+		},
+	}
+	err := s.reconcile(ctx, cfg, report)
+	report.FinishedAt = s.now()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.last = report
+	s.mu.Unlock()
+
+	if err != nil {
+		return report, err
+	}
+	if !report.InSync {
+		s.logger.WithFields(logrus.Fields{
+			"missing":    len(report.Drift.Missing),
+			"changed":    len(report.Drift.Changed),
+			"undeclared": len(report.Drift.Undeclared),
+			"applied":    report.Applied,
+		}).Warn("URL configuration drift detected")
+	}
+	return report, nil
+}
+
+// reconcile compares the declared URLs with the database, fills in the
+// report's drift and applies it unless running dry
+func (s *URLSyncService) reconcile(ctx context.Context, cfg config.SyncConfig, report *SyncReport) error {
+	if cfg.Path == "" {
+		return fmt.Errorf("sync.path is not set")
+	}
+
+	declared, err := s.declaredURLs(cfg.Path)
+	if err != nil {
+		return err
+	}
+	report.Declared = len(declared)
+
+	current, err := s.urlRepo.ListURLs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list URLs: %w", err)
+	}
+	byURL := make(map[string]database.Url, len(current))
+	for _, url := range current {
+		byURL[url.Url] = url
+	}
+
+	isDeclared := make(map[string]bool, len(declared))
+	var upserts []database.UpsertURLParams
+	for _, want := range declared {
+		isDeclared[want.Url] = true
+
+		have, ok := byURL[want.Url]
+		if !ok {
+			report.Drift.Missing = append(report.Drift.Missing, want.Url)
+			upserts = append(upserts, want)
+			continue
+		}
+		if fields := changedFields(have, want); len(fields) > 0 {
+			report.Drift.Changed = append(report.Drift.Changed, SyncChange{URL: want.Url, Fields: fields})
+			upserts = append(upserts, want)
+		}
+	}
+
+	var undeclared []uuid.UUID
+	for _, have := range current {
+		if have.Managed && !isDeclared[have.Url] {
+			report.Drift.Undeclared = append(report.Drift.Undeclared, have.Url)
+			undeclared = append(undeclared, have.ID)
+		}
+	}
+
+	report.InSync = len(upserts) == 0 && len(undeclared) == 0
+	if report.InSync || cfg.DryRun {
+		return nil
+	}
+
+	for _, params := range upserts {
+		row, err := s.urlRepo.UpsertURL(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to apply %s: %w", params.Url, err)
+		}
+		eventType := sharedmodels.URLEventUpdated
+		if row.Inserted {
+			eventType = sharedmodels.URLEventCreated
+		}
+		s.events.Publish(ctx, sharedmodels.NewURLEvent(eventType, row.ID, params.Url))
+	}
+	if cfg.Prune && len(undeclared) > 0 {
+		deleted, err := s.urlRepo.DeleteURLs(ctx, undeclared)
+		if err != nil {
+			return fmt.Errorf("failed to delete undeclared URLs: %w", err)
+		}
+		for _, url := range deleted {
+			s.events.Publish(ctx, sharedmodels.NewURLEvent(sharedmodels.URLEventDeleted, url.ID, url.Url))
+		}
+	}
+	report.Applied = true
+	return nil
+}
+
+// declaredURLs reads and validates the URLs declared under path, a YAML file
+// or a directory searched recursively for YAML files
+func (s *URLSyncService) declaredURLs(path string) ([]database.UpsertURLParams, error) {
+	files, err := syncFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var declared []database.UpsertURLParams
+	seen := make(map[string]string)
+	add := func(file string, spec models.URLSpec, project string) error {
+		params, err := s.urlParams(spec, project)
+		if err != nil {
+			return fmt.Errorf("%s: url %q: %w", file, spec.URL, err)
+		}
+		if other, ok := seen[params.Url]; ok {
+			return fmt.Errorf("%s: url %q is already declared in %s", file, params.Url, other)
+		}
+		seen[params.Url] = file
+		declared = append(declared, params)
+		return nil
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		doc, err := decodeSyncDocument(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		for _, spec := range doc.URLs {
+			if err := add(file, spec, ""); err != nil {
+				return nil, err
+			}
+		}
+		for _, project := range doc.Projects {
+			if project.Name == "" || !sharedmodels.ValidProject(project.Name) {
+				return nil, fmt.Errorf("%s: invalid project name %q", file, project.Name)
+			}
+			for _, spec := range project.URLs {
+				if err := add(file, spec, project.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return declared, nil
+}
+
+// syncFiles returns the YAML files to read, in a stable order. Hidden
+// directories such as .git are skipped.
+func syncFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != path && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync path: %w", err)
+	}
+	if len(files) == 0 {
+		// Refuse to treat a missing checkout as "no URLs declared"
+		return nil, fmt.Errorf("no YAML files found in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// decodeSyncDocument decodes a YAML sync document. Keys are the JSON field
+// names of the API, and unknown keys are rejected to catch typos.
+func decodeSyncDocument(data []byte) (models.SyncDocument, error) {
+	var doc models.SyncDocument
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return doc, err
+	}
+	if raw == nil {
+		return doc, nil
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return doc, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&doc)
+	return doc, err
+}
+
+// urlParams validates a declared URL and converts it into upsert parameters,
+// applying the same defaults as the API
+func (s *URLSyncService) urlParams(spec models.URLSpec, project string) (database.UpsertURLParams, error) {
+	parsed, err := url.Parse(spec.URL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return database.UpsertURLParams{}, fmt.Errorf("url must include scheme and host")
+	}
+	interval, err := models.ParseFrequency(spec.Frequency)
+	if err != nil {
+		return database.UpsertURLParams{}, fmt.Errorf("invalid frequency %q: %w", spec.Frequency, err)
+	}
+	s.mu.Lock()
+	floor := s.policy.Floor(spec.URL, project)
+	s.mu.Unlock()
+	if interval < floor {
+		return database.UpsertURLParams{}, fmt.Errorf("frequency %q is below the minimum of %s for this URL", spec.Frequency, utils.FormatFrequency(floor))
+	}
+	if spec.Timeout < 0 || spec.Timeout > 300 {
+		return database.UpsertURLParams{}, fmt.Errorf("timeout must be between 0 and 300 seconds")
+	}
+	if spec.RateLimit < 0 || spec.RateLimit > 1000 {
+		return database.UpsertURLParams{}, fmt.Errorf("rate_limit must be between 0 and 1000")
+	}
+	if spec.MaxRetries < 0 || spec.MaxRetries > 10 {
+		return database.UpsertURLParams{}, fmt.Errorf("max_retries must be between 0 and 10")
+	}
+	if !sharedmodels.ValidRegion(spec.Region) {
+		return database.UpsertURLParams{}, fmt.Errorf("invalid region %q", spec.Region)
+	}
+
+	tags, err := sharedmodels.NormalizeTags(spec.Tags)
+	if err != nil {
+		return database.UpsertURLParams{}, err
+	}
+
+	params := database.UpsertURLParams{
+		Url:          spec.URL,
+		Frequency:    spec.Frequency,
+		Status:       URLStatusPending,
+		MaxRetries:   int32(valueOrDefault(spec.MaxRetries, defaultURLMaxRetries)),
+		Timeout:      int32(valueOrDefault(spec.Timeout, defaultURLTimeout)),
+		RateLimit:    int32(valueOrDefault(spec.RateLimit, defaultURLRateLimit)),
+		UserAgent:    sql.NullString{String: defaultURLUserAgent, Valid: true},
+		NextScrapeAt: sql.NullTime{Time: s.now(), Valid: true},
+		Tags:         tags,
+		Project:      project,
+		Managed:      true,
+		Region:       spec.Region,
+	}
+	if spec.UserAgent != "" {
+		params.UserAgent.String = spec.UserAgent
+	}
+
+	if spec.ParserConfig != nil {
+		data, err := json.Marshal(spec.ParserConfig)
+		if err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid parser_config: %w", err)
+		}
+		params.ParserConfig = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+	}
+	if spec.RetryPolicy != nil {
+		if err := spec.RetryPolicy.Validate(); err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid retry_policy: %w", err)
+		}
+		data, err := json.Marshal(spec.RetryPolicy)
+		if err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid retry_policy: %w", err)
+		}
+		params.RetryPolicy = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+	}
+	if spec.Assertions != nil {
+		if err := parser.ValidateAssertions(*spec.Assertions); err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid assertions: %w", err)
+		}
+		data, err := json.Marshal(spec.Assertions)
+		if err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid assertions: %w", err)
+		}
+		params.Assertions = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+	}
+	if spec.ArchivePolicy != nil {
+		if err := spec.ArchivePolicy.Validate(); err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid archive_policy: %w", err)
+		}
+		data, err := json.Marshal(spec.ArchivePolicy)
+		if err != nil {
+			return database.UpsertURLParams{}, fmt.Errorf("invalid archive_policy: %w", err)
+		}
+		params.ArchivePolicy = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+	}
+
+	return params, nil
+}
+
+// valueOrDefault returns defaultValue if value is 0
+func valueOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// changedFields lists the configuration fields in which a stored URL differs
+// from its declaration
+func changedFields(have database.Url, want database.UpsertURLParams) []string {
+	var fields []string
+	if have.Frequency != want.Frequency {
+		fields = append(fields, "frequency")
+	}
+	if have.MaxRetries != want.MaxRetries {
+		fields = append(fields, "max_retries")
+	}
+	if have.Timeout != want.Timeout {
+		fields = append(fields, "timeout")
+	}
+	if have.RateLimit != want.RateLimit {
+		fields = append(fields, "rate_limit")
+	}
+	if have.UserAgent != want.UserAgent {
+		fields = append(fields, "user_agent")
+	}
+	if !jsonEqual(have.ParserConfig, want.ParserConfig) {
+		fields = append(fields, "parser_config")
+	}
+	if !jsonEqual(have.RetryPolicy, want.RetryPolicy) {
+		fields = append(fields, "retry_policy")
+	}
+	if !jsonEqual(have.Assertions, want.Assertions) {
+		fields = append(fields, "assertions")
+	}
+	if !jsonEqual(have.ArchivePolicy, want.ArchivePolicy) {
+		fields = append(fields, "archive_policy")
+	}
+	if !sameTags(have.Tags, want.Tags) {
+		fields = append(fields, "tags")
+	}
+	if have.Project != want.Project {
+		fields = append(fields, "project")
+	}
+	if have.Region != want.Region {
+		fields = append(fields, "region")
+	}
+	if !have.Managed {
+		fields = append(fields, "managed")
+	}
+	return fields
+}
+
+// jsonEqual reports whether two JSON documents are semantically equal,
+// ignoring formatting and key order as normalized by JSONB
+func jsonEqual(a, b pqtype.NullRawMessage) bool {
+	if !a.Valid || !b.Valid {
+		return a.Valid == b.Valid
+	}
+	var left, right interface{}
+	if json.Unmarshal(a.RawMessage, &left) != nil || json.Unmarshal(b.RawMessage, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// sameTags reports whether two tag lists hold the same tags in any order
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
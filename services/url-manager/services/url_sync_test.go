@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/events"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+)
+
+const testSyncDocument = `
+projects:
+  - name: news
+    urls:
+      - url: https://example.com/news
+        frequency: 1h
+        tags: [News]
+      - url: https://example.com/sports
+        frequency: 30m
+urls:
+  - url: https://example.org/status
+    frequency: 5m
+`
+
+// syncedURL returns a stored URL matching a declaration with default settings
+func syncedURL(address, frequency, project string, tags ...string) database.Url {
+	if tags == nil {
+		tags = []string{}
+	}
+	return database.Url{
+		ID:         uuid.New(),
+		Url:        address,
+		Frequency:  frequency,
+		MaxRetries: defaultURLMaxRetries,
+		Timeout:    defaultURLTimeout,
+		RateLimit:  defaultURLRateLimit,
+		UserAgent:  sql.NullString{String: defaultURLUserAgent, Valid: true},
+		Tags:       tags,
+		Project:    project,
+		Managed:    true,
+	}
+}
+
+func writeSyncFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestURLSync(t *testing.T) {
+	stale := syncedURL("https://example.com/news", "2h", "news", "news")
+	inSync := syncedURL("https://example.com/sports", "30m", "news")
+	removed := syncedURL("https://example.com/old", "1h", "news")
+	manual := syncedURL("https://example.com/manual", "1h", "")
+	manual.Managed = false
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		prune       bool
+		wantUpserts []string
+		wantDeleted []uuid.UUID
+		wantApplied bool
+	}{
+		{
+			name:        "applies drift",
+			prune:       true,
+			wantUpserts: []string{"https://example.org/status", "https://example.com/news"},
+			wantDeleted: []uuid.UUID{removed.ID},
+			wantApplied: true,
+		},
+		{
+			name:        "keeps undeclared URLs without pruning",
+			wantUpserts: []string{"https://example.org/status", "https://example.com/news"},
+			wantApplied: true,
+		},
+		{
+			name:   "dry run only reports",
+			dryRun: true,
+			prune:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSyncFile(t, t.TempDir(), "urls.yaml", testSyncDocument)
+			repo := &fakeURLRepository{active: []database.Url{stale, inSync, removed, manual}}
+			urlSync := NewURLSyncService(repo, nil, newTestLogger())
+			urlSync.Configure(config.SyncConfig{Enabled: true, Path: path, Prune: tt.prune, DryRun: tt.dryRun})
+
+			report, err := urlSync.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+
+			if report.Declared != 3 || report.InSync {
+				t.Errorf("report declared = %d, in sync = %v; want 3 URLs out of sync", report.Declared, report.InSync)
+			}
+			wantDrift := SyncDrift{
+				Missing:    []string{"https://example.org/status"},
+				Changed:    []SyncChange{{URL: "https://example.com/news", Fields: []string{"frequency"}}},
+				Undeclared: []string{"https://example.com/old"},
+			}
+			if !reflect.DeepEqual(report.Drift, wantDrift) {
+				t.Errorf("drift = %+v, want %+v", report.Drift, wantDrift)
+			}
+			if report.Applied != tt.wantApplied {
+				t.Errorf("applied = %v, want %v", report.Applied, tt.wantApplied)
+			}
+
+			var upserted []string
+			for _, params := range repo.upserts {
+				upserted = append(upserted, params.Url)
+				if !params.Managed {
+					t.Errorf("%s was not marked as managed", params.Url)
+				}
+			}
+			if !reflect.DeepEqual(upserted, tt.wantUpserts) {
+				t.Errorf("upserted = %v, want %v", upserted, tt.wantUpserts)
+			}
+			if !reflect.DeepEqual(repo.deletedIDs, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", repo.deletedIDs, tt.wantDeleted)
+			}
+			if status := urlSync.Status(); status.LastReport != report {
+				t.Error("Status() does not return the latest report")
+			}
+		})
+	}
+}
+
+// fakeEventSender records the URL events sent to Kafka
+type fakeEventSender struct {
+	events []sharedmodels.URLEvent
+}
+
+func (f *fakeEventSender) SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error {
+	f.events = append(f.events, value.(sharedmodels.URLEvent))
+	return nil
+}
+
+func TestURLSyncPublishesEvents(t *testing.T) {
+	stale := syncedURL("https://example.com/news", "2h", "news", "news")
+	removed := syncedURL("https://example.com/old", "1h", "news")
+	path := writeSyncFile(t, t.TempDir(), "urls.yaml", testSyncDocument)
+	repo := &fakeURLRepository{active: []database.Url{stale, removed}}
+	sender := &fakeEventSender{}
+	urlSync := NewURLSyncService(repo, events.NewURLEventPublisher(sender, "url-events", "url-manager", newTestLogger()), newTestLogger())
+	urlSync.Configure(config.SyncConfig{Enabled: true, Path: path, Prune: true})
+
+	if _, err := urlSync.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, event := range sender.events {
+		got[event.Type+" "+event.URL] = event.URLID.String()
+	}
+	for key, wantID := range map[string]string{
+		"url.created https://example.org/status": "",
+		"url.created https://example.com/sports": "",
+		"url.updated https://example.com/news":   stale.ID.String(),
+		"url.deleted https://example.com/old":    removed.ID.String(),
+	} {
+		id, ok := got[key]
+		if !ok || (wantID != "" && id != wantID) {
+			t.Errorf("event %q = %q, %v; want url_id %q", key, id, ok, wantID)
+		}
+	}
+	if len(sender.events) != 4 {
+		t.Errorf("published %d events, want 4", len(sender.events))
+	}
+}
+
+func TestURLSyncDeclaredURLs(t *testing.T) {
+	dir := t.TempDir()
+	writeSyncFile(t, dir, "news.yaml", `
+projects:
+  - name: news
+    urls:
+      - url: https://example.com/news
+        frequency: 1h
+        tags: [News, news]
+        retry_policy:
+          max_attempts: 4
+`)
+	writeSyncFile(t, dir, "shop/products.yml", `
+urls:
+  - url: https://example.com/products
+    frequency: 1d
+    timeout: 60
+`)
+	writeSyncFile(t, dir, ".git/config.yaml", "not: [a sync document")
+
+	urlSync := NewURLSyncService(&fakeURLRepository{}, nil, newTestLogger())
+	declared, err := urlSync.declaredURLs(dir)
+	if err != nil {
+		t.Fatalf("declaredURLs() error = %v", err)
+	}
+	if len(declared) != 2 {
+		t.Fatalf("declaredURLs() returned %d URLs, want 2", len(declared))
+	}
+
+	news, products := declared[0], declared[1]
+	if news.Project != "news" || !reflect.DeepEqual(news.Tags, []string{"news"}) || !news.RetryPolicy.Valid {
+		t.Errorf("news = %+v, want project news, tags [news] and a retry policy", news)
+	}
+	if products.Project != "" || products.Timeout != 60 || products.MaxRetries != defaultURLMaxRetries {
+		t.Errorf("products = %+v, want no project, timeout 60 and default max retries", products)
+	}
+}
+
+func TestURLSyncRejectsInvalidDeclarations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			files:   map[string]string{"urls.yaml": "urls:\n  - url: https://example.com\n    frequncy: 1h\n"},
+			wantErr: "frequncy",
+		},
+		{
+			name:    "invalid frequency",
+			files:   map[string]string{"urls.yaml": "urls:\n  - url: https://example.com\n    frequency: hourly\n"},
+			wantErr: "invalid frequency",
+		},
+		{
+			name:    "frequency below the minimum",
+			files:   map[string]string{"urls.yaml": "urls:\n  - url: https://example.com\n    frequency: 10s\n"},
+			wantErr: "minimum frequency is 30s",
+		},
+		{
+			name:    "frequency below the domain floor",
+			files:   map[string]string{"urls.yaml": "urls:\n  - url: https://partner.example.com/feed\n    frequency: 10m\n"},
+			wantErr: "below the minimum of 1h",
+		},
+		{
+			name:    "invalid project",
+			files:   map[string]string{"urls.yaml": "projects:\n  - name: My Project\n    urls: []\n"},
+			wantErr: "invalid project name",
+		},
+		{
+			name: "duplicate URL",
+			files: map[string]string{
+				"a.yaml": "urls:\n  - url: https://example.com\n    frequency: 1h\n",
+				"b.yaml": "urls:\n  - url: https://example.com\n    frequency: 2h\n",
+			},
+			wantErr: "already declared",
+		},
+		{
+			name:    "no files",
+			files:   map[string]string{"README.md": "# URLs"},
+			wantErr: "no YAML files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeSyncFile(t, dir, name, content)
+			}
+			repo := &fakeURLRepository{active: []database.Url{syncedURL("https://example.com/old", "1h", "")}}
+			urlSync := NewURLSyncService(repo, nil, newTestLogger())
+			urlSync.Configure(config.SyncConfig{Enabled: true, Path: dir, Prune: true})
+			urlSync.SetFrequencyPolicy(config.FrequencyPolicyConfig{
+				Floors: []config.FrequencyFloorConfig{{Domain: "partner.example.com", MinFrequency: time.Hour}},
+			})
+
+			report, err := urlSync.Sync(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Sync() error = %v, want error containing %q", err, tt.wantErr)
+			}
+			if report.Error == "" || report.Applied {
+				t.Errorf("report = %+v, want an unapplied report with the error", report)
+			}
+			if len(repo.upserts) != 0 || len(repo.deletedIDs) != 0 {
+				t.Error("an invalid declaration must not change the database")
+			}
+		})
+	}
+}
+
+func TestURLSyncDisabled(t *testing.T) {
+	urlSync := NewURLSyncService(&fakeURLRepository{}, nil, newTestLogger())
+	if _, err := urlSync.Sync(context.Background()); !errors.Is(err, ErrSyncDisabled) {
+		t.Errorf("Sync() error = %v, want ErrSyncDisabled", err)
+	}
+}
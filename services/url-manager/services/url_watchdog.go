@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go_scraping_project/services/url-manager/repositories"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/notify"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default watchdog settings used when none are configured
+const (
+	DefaultWatchdogInterval               = time.Minute
+	DefaultWatchdogOverdueAfter           = 15 * time.Minute
+	DefaultWatchdogMaxConsecutiveFailures = 5
+	DefaultWatchdogBatchSize              = 100
+)
+
+// Notifier sends alerts to the configured notification channels
+type Notifier interface {
+	Notify(ctx context.Context, alert notify.Alert) error
+}
+
+// URLWatchdogService detects URLs that silently stopped being scraped or
+// keep failing, marks them as degraded and raises an alert. Overdue URLs
+// are also rescheduled, since the scheduler only picks up URLs that are
+// due within a short window around the current time.
+type URLWatchdogService struct {
+	urlRepo  repositories.URLRepository
+	notifier Notifier
+	logger   *logrus.Logger
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	now      func() time.Time
+
+	// Settings that can change at runtime, see Configure
+	mu  sync.Mutex
+	cfg config.WatchdogConfig
+}
+
+// NewURLWatchdogService creates a new URL watchdog service
+func NewURLWatchdogService(
+	urlRepo repositories.URLRepository,
+	notifier Notifier,
+	logger *logrus.Logger,
+) *URLWatchdogService {
+	return &URLWatchdogService{
+		urlRepo:  urlRepo,
+		notifier: notifier,
+		logger:   logger,
+		stopChan: make(chan struct{}),
+		now:      func() time.Time { return time.Now().UTC() },
+		cfg: config.WatchdogConfig{
+			Enabled:                true,
+			CheckInterval:          DefaultWatchdogInterval,
+			OverdueAfter:           DefaultWatchdogOverdueAfter,
+			MaxConsecutiveFailures: DefaultWatchdogMaxConsecutiveFailures,
+			BatchSize:              DefaultWatchdogBatchSize,
+		},
+	}
+}
+
+// Configure applies watchdog settings. It is safe to call while the
+// watchdog is running; a new check interval takes effect on the next tick.
+func (w *URLWatchdogService) Configure(cfg config.WatchdogConfig) {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultWatchdogInterval
+	}
+	if cfg.OverdueAfter <= 0 {
+		cfg.OverdueAfter = DefaultWatchdogOverdueAfter
+	}
+	if cfg.MaxConsecutiveFailures <= 0 {
+		cfg.MaxConsecutiveFailures = DefaultWatchdogMaxConsecutiveFailures
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultWatchdogBatchSize
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cfg.CheckInterval != w.cfg.CheckInterval && w.ticker != nil {
+		w.ticker.Reset(cfg.CheckInterval)
+	}
+	if cfg != w.cfg {
+		w.logger.WithFields(logrus.Fields{
+			"enabled":                  cfg.Enabled,
+			"check_interval":           cfg.CheckInterval.String(),
+			"overdue_after":            cfg.OverdueAfter.String(),
+			"max_consecutive_failures": cfg.MaxConsecutiveFailures,
+		}).Info("Watchdog settings updated")
+	}
+	w.cfg = cfg
+}
+
+// Start starts the URL watchdog service
+func (w *URLWatchdogService) Start(ctx context.Context) error {
+	w.logger.Info("Starting URL Watchdog Service")
+
+	w.mu.Lock()
+	w.ticker = time.NewTicker(w.cfg.CheckInterval)
+	w.mu.Unlock()
+
+	go w.run(ctx)
+
+	return nil
+}
+
+// Stop stops the URL watchdog service
+func (w *URLWatchdogService) Stop() error {
+	w.logger.Info("Stopping URL Watchdog Service")
+
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+
+	close(w.stopChan)
+	return nil
+}
+
+// run runs the watchdog loop
+func (w *URLWatchdogService) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		case <-w.ticker.C:
+			if err := w.check(ctx); err != nil {
+				w.logger.WithError(err).Error("Watchdog check failed")
+			}
+		}
+	}
+}
+
+// check degrades overdue URLs and URLs that keep failing
+func (w *URLWatchdogService) check(ctx context.Context) error {
+	w.mu.Lock()
+	cfg := w.cfg
+	w.mu.Unlock()
+	if !cfg.Enabled {
+		return nil
+	}
+
+	now := w.now()
+	overdue, err := w.urlRepo.GetOverdueURLs(ctx, now.Add(-cfg.OverdueAfter), int32(cfg.BatchSize))
+	if err != nil {
+		return fmt.Errorf("failed to get overdue URLs: %w", err)
+	}
+	for _, url := range overdue {
+		overdueBy := now.Sub(url.NextScrapeAt.Time).Round(time.Second)
+		alert := notify.Alert{
+			Title:   "URL degraded: scrape overdue",
+			Message: fmt.Sprintf("%s is %s past its scheduled scrape", url.Url, overdueBy),
+			Fields:  alertFields(url, "overdue_by", overdueBy.String()),
+		}
+		if err := w.degrade(ctx, url, alert); err != nil {
+			return err
+		}
+		// Put the URL back into the scheduler's window
+		if err := w.urlRepo.UpdateNextScrapeTime(ctx, url.ID, now); err != nil {
+			return fmt.Errorf("failed to reschedule overdue URL: %w", err)
+		}
+	}
+
+	failing, err := w.urlRepo.GetURLsWithConsecutiveFailures(ctx, int32(cfg.MaxConsecutiveFailures), int32(cfg.BatchSize))
+	if err != nil {
+		return fmt.Errorf("failed to get failing URLs: %w", err)
+	}
+	for _, url := range failing {
+		alert := notify.Alert{
+			Title:   "URL degraded: consecutive failures",
+			Message: fmt.Sprintf("%s failed its last %d scrapes", url.Url, cfg.MaxConsecutiveFailures),
+			Fields:  alertFields(url, "consecutive_failures", strconv.Itoa(cfg.MaxConsecutiveFailures)),
+		}
+		if err := w.degrade(ctx, url, alert); err != nil {
+			return err
+		}
+	}
+
+	if len(overdue) > 0 || len(failing) > 0 {
+		w.logger.WithFields(logrus.Fields{
+			"overdue": len(overdue),
+			"failing": len(failing),
+		}).Warn("Watchdog degraded URLs")
+	}
+	return nil
+}
+
+// degrade marks a URL as degraded and sends the alert. Delivery failures
+// are logged; the URL stays degraded so the alert is not repeated.
+func (w *URLWatchdogService) degrade(ctx context.Context, url database.Url, alert notify.Alert) error {
+	if err := w.urlRepo.UpdateURLStatus(ctx, url.ID, URLStatusDegraded); err != nil {
+		return fmt.Errorf("failed to mark URL as degraded: %w", err)
+	}
+
+	alert.Severity = notify.SeverityWarning
+	if err := w.notifier.Notify(ctx, alert); err != nil {
+		w.logger.WithError(err).WithField("url_id", url.ID).Error("Failed to send watchdog alert")
+	}
+	return nil
+}
+
+// alertFields returns the alert details for a URL plus one extra field
+func alertFields(url database.Url, key, value string) map[string]string {
+	fields := map[string]string{
+		"url_id":          url.ID.String(),
+		"url":             url.Url,
+		"previous_status": url.Status,
+		key:               value,
+	}
+	if url.LastScrapedAt.Valid {
+		fields["last_scraped_at"] = url.LastScrapedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return fields
+}
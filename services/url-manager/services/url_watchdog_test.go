@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/notify"
+
+	"github.com/google/uuid"
+)
+
+// fakeNotifier records the alerts sent by the watchdog
+type fakeNotifier struct {
+	alerts []notify.Alert
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, alert notify.Alert) error {
+	f.alerts = append(f.alerts, alert)
+	return nil
+}
+
+func TestWatchdogCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stalled := &database.Url{ID: uuid.New(), Url: "https://example.com/stalled", Status: URLStatusPending,
+		NextScrapeAt: sql.NullTime{Time: now.Add(-time.Hour), Valid: true}}
+	failing := &database.Url{ID: uuid.New(), Url: "https://example.com/failing", Status: URLStatusFailed}
+
+	repo := &fakeURLRepository{
+		urls:          map[uuid.UUID]*database.Url{stalled.ID: stalled, failing.ID: failing},
+		nextScrapeAts: make(map[uuid.UUID]time.Time),
+		overdue:       []database.Url{*stalled},
+		failing:       []database.Url{*failing},
+	}
+	notifier := &fakeNotifier{}
+
+	watchdog := NewURLWatchdogService(repo, notifier, newTestLogger())
+	watchdog.now = func() time.Time { return now }
+	watchdog.Configure(config.WatchdogConfig{Enabled: true, OverdueAfter: 10 * time.Minute, MaxConsecutiveFailures: 3})
+
+	if err := watchdog.check(context.Background()); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+
+	if want := now.Add(-10 * time.Minute); !repo.lastBefore.Equal(want) {
+		t.Errorf("overdue cutoff = %v, want %v", repo.lastBefore, want)
+	}
+	if repo.lastFailures != 3 {
+		t.Errorf("consecutive failures threshold = %d, want 3", repo.lastFailures)
+	}
+	if stalled.Status != URLStatusDegraded || failing.Status != URLStatusDegraded {
+		t.Errorf("statuses = %q, %q; want both degraded", stalled.Status, failing.Status)
+	}
+	if next := repo.nextScrapeAts[stalled.ID]; !next.Equal(now) {
+		t.Errorf("overdue URL rescheduled at %v, want now", next)
+	}
+	if _, ok := repo.nextScrapeAts[failing.ID]; ok {
+		t.Error("failing URL was rescheduled")
+	}
+
+	if len(notifier.alerts) != 2 {
+		t.Fatalf("sent %d alerts, want 2", len(notifier.alerts))
+	}
+	if got := notifier.alerts[0].Fields["overdue_by"]; got != "1h0m0s" {
+		t.Errorf("overdue_by = %q, want 1h0m0s", got)
+	}
+	if got := notifier.alerts[1].Fields["url_id"]; got != failing.ID.String() {
+		t.Errorf("failing alert url_id = %q", got)
+	}
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	repo := &fakeURLRepository{}
+	watchdog := NewURLWatchdogService(repo, &fakeNotifier{}, newTestLogger())
+	watchdog.Configure(config.WatchdogConfig{Enabled: false})
+
+	if err := watchdog.check(context.Background()); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	if !repo.lastBefore.IsZero() {
+		t.Error("disabled watchdog still queried for overdue URLs")
+	}
+}
@@ -0,0 +1,175 @@
+// Package awssig signs requests to AWS APIs and S3-compatible storage with
+// AWS Signature Version 4, either in the Authorization header (Sign) or in
+// the query of a presigned URL (Presign).
+package awssig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature Version 4 settings
+const (
+	Algorithm  = "AWS4-HMAC-SHA256"
+	DateFormat = "20060102T150405Z"
+	// UnsignedPayload is the payload hash of requests whose body is streamed
+	// and so not hashed; only S3 accepts it
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// Signer signs requests to one service in one region
+type Signer struct {
+	Region          string
+	Service         string // e.g. s3 or secretsmanager
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Sent as X-Amz-Security-Token when set (optional)
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Security-Token and Authorization headers
+// to a request. The host, X-Amz-Date and X-Amz-Security-Token headers are
+// always signed, besides the headers named in headers.
+func (s Signer) Sign(req *http.Request, payloadHash string, now time.Time, headers ...string) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(DateFormat))
+	names := append([]string{"host", "x-amz-date"}, headers...)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+		names = append(names, "x-amz-security-token")
+	}
+
+	// Canonical headers must be lowercase and sorted by name
+	for i, name := range names {
+		names[i] = strings.ToLower(name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonical.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	signature := s.signature(req.Method, req.URL.EscapedPath(), CanonicalQuery(req.URL.Query()), canonical.String(), signedHeaders, payloadHash, now)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		Algorithm, s.AccessKeyID, s.Scope(now), signedHeaders, signature,
+	))
+}
+
+// Presign returns u as a GET valid for ttl from now, signed in its query.
+// The parameters of query are added to the signed query; only the host
+// header is signed and the payload is not.
+func (s Signer) Presign(u url.URL, query url.Values, ttl time.Duration, now time.Time) string {
+	now = now.UTC()
+	signed := url.Values{}
+	for key, values := range query {
+		signed[key] = values
+	}
+	signed.Set("X-Amz-Algorithm", Algorithm)
+	signed.Set("X-Amz-Credential", s.AccessKeyID+"/"+s.Scope(now))
+	signed.Set("X-Amz-Date", now.Format(DateFormat))
+	signed.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	signed.Set("X-Amz-SignedHeaders", "host")
+	if s.SessionToken != "" {
+		signed.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	canonical := CanonicalQuery(signed)
+	signature := s.signature(http.MethodGet, u.EscapedPath(), canonical, "host:"+u.Host+"\n", "host", UnsignedPayload, now)
+	u.RawQuery = canonical + "&X-Amz-Signature=" + signature
+	return u.String()
+}
+
+// Scope returns the credential scope of requests signed at now
+func (s Signer) Scope(now time.Time) string {
+	return now.UTC().Format("20060102") + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+// signature returns the signature of a canonical request
+func (s Signer) signature(method, path, query, headers, signedHeaders, payloadHash string, now time.Time) string {
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		query,
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		Algorithm,
+		now.Format(DateFormat),
+		s.Scope(now),
+		SHA256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// CanonicalQuery returns query with its parameters sorted and encoded as
+// Signature Version 4 requires
+func CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, URIEncode(key, true)+"="+URIEncode(value, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// URIEncode percent-encodes every byte of s but the unreserved characters
+// of RFC 3986, and slashes unless encodeSlash is set
+func URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// SHA256Hex returns the hex-encoded SHA-256 of data, the payload hash of a
+// signed body
+func SHA256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
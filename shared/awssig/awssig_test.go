@@ -0,0 +1,79 @@
+package awssig
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSigner = Signer{
+	Region:          "us-east-1",
+	Service:         "service",
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+func TestSign(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSigner.Sign(req, SHA256Hex(nil), time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s", got)
+	}
+
+	// The session token and extra headers are signed, sorted by name
+	signer := testSigner
+	signer.SessionToken = "token"
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	signer.Sign(req, SHA256Hex(nil), time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC), "X-Amz-Target", "content-type")
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Errorf("X-Amz-Security-Token = %s", got)
+	}
+	const signed = "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,"
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, signed) {
+		t.Errorf("Authorization = %s, want %s", got, signed)
+	}
+}
+
+func TestPresign(t *testing.T) {
+	u, _ := url.Parse("https://example.amazonaws.com/a%20b.txt")
+	signer := testSigner
+	signer.SessionToken = "token"
+	link, err := url.Parse(signer.Presign(*u, url.Values{"response-content-disposition": {"attachment"}}, time.Hour, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := link.Query()
+	if link.EscapedPath() != "/a%20b.txt" || query.Get("X-Amz-Expires") != "3600" || query.Get("X-Amz-Security-Token") != "token" ||
+		query.Get("response-content-disposition") != "attachment" || len(query.Get("X-Amz-Signature")) != 64 {
+		t.Errorf("Presign() = %s", link)
+	}
+	if u.RawQuery != "" {
+		t.Errorf("Presign() changed its URL to %s", u)
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	if got := URIEncode("/a b/c~d+e", false); got != "/a%20b/c~d%2Be" {
+		t.Errorf("URIEncode() = %s", got)
+	}
+	if got := URIEncode("a/b", true); got != "a%2Fb" {
+		t.Errorf("URIEncode() of a slash = %s", got)
+	}
+	if got := CanonicalQuery(url.Values{"b": {"2", "1"}, "a": {"x y"}}); got != "a=x%20y&b=1&b=2" {
+		t.Errorf("CanonicalQuery() = %s", got)
+	}
+}
@@ -0,0 +1,842 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/cache"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+	"go_scraping_project/shared/features"
+	"go_scraping_project/shared/kafka"
+	"go_scraping_project/shared/maintenance"
+	"go_scraping_project/shared/mtls"
+	"go_scraping_project/shared/notify"
+	"go_scraping_project/shared/secrets"
+	"go_scraping_project/shared/tracing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// secretsTimeout bounds how long resolving all secret references may take
+const secretsTimeout = 30 * time.Second
+
+// lateStopGrace is how long Shutdown waits for each hook it stops after the
+// shutdown deadline passed, so that quick cleanup such as closing the
+// database still happens after a component got stuck
+const lateStopGrace = time.Second
+
+// Container holds the dependencies shared by every service: configuration,
+// logger, database, and Kafka. Dependencies are created lazily on first use
+// and their cleanup is registered as a stop hook, so services and tests
+// assemble them the same way and shut them down in a consistent order.
+type Container struct {
+	serviceName string
+	loader      *config.Loader
+	config      *config.Config
+	watcher     *config.Watcher
+	logger      *logrus.Logger
+
+	mu       sync.Mutex
+	db       *sql.DB
+	pool     *database.Pool
+	queries  *database.Queries
+	producer *kafka.Producer
+	consumer *kafka.Consumer
+	features *features.Flags
+	notifier *notify.Dispatcher
+	mode     *maintenance.Mode
+	cache    *cache.Cache
+	tracer   *tracing.Tracer
+	keyring  *encryption.Keyring // Nil without encryption keys, see Keyring
+	keyed    bool                // Whether keyring was created
+	kafkaTLS *tls.Config         // Mutual TLS of Kafka connections, see KafkaOptions
+	hooks    []Hook
+	started  int
+}
+
+// Hook is a named lifecycle step. OnStart hooks run in registration order
+// when the container starts. On shutdown OnStop hooks run stage by stage, see
+// Stage, and in reverse registration order within a stage, so a dependency is
+// always stopped after everything registered after it.
+type Hook struct {
+	Name    string
+	Stage   Stage
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Stage groups hooks for shutdown. Stages stop in the order they are
+// declared: HTTP servers stop taking requests first, then service components
+// such as schedulers, then Kafka consumers and producers, and the database
+// last.
+type Stage int
+
+const (
+	// StageServer is for servers that accept requests
+	StageServer Stage = iota - 1
+	// StageComponent is the default, for schedulers, watchers and other
+	// service components
+	StageComponent
+	// StageConsumer is for Kafka consumers
+	StageConsumer
+	// StageProducer is for Kafka producers
+	StageProducer
+	// StageStorage is for the database
+	StageStorage
+)
+
+// ShutdownError lists the components that failed to stop or did not stop
+// before the shutdown deadline
+type ShutdownError struct {
+	Failed   []string // Components whose OnStop returned an error
+	TimedOut []string // Components still stopping when the deadline passed
+	errs     []error
+}
+
+func (e *ShutdownError) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+// Unwrap returns the error of every component that did not stop cleanly
+func (e *ShutdownError) Unwrap() []error {
+	return e.errs
+}
+
+// Option customizes a Container, typically to inject test doubles
+type Option func(*Container)
+
+// WithConfig uses the given configuration instead of loading configuration files
+func WithConfig(cfg *config.Config) Option {
+	return func(c *Container) {
+		c.config = cfg
+	}
+}
+
+// WithLogger uses the given logger instead of building one from configuration
+func WithLogger(logger *logrus.Logger) Option {
+	return func(c *Container) {
+		c.logger = logger
+	}
+}
+
+// WithDB uses an existing database handle. The container does not close it.
+func WithDB(db *sql.DB) Option {
+	return func(c *Container) {
+		c.db = db
+	}
+}
+
+// NewContainer creates a container for the named service. Unless a
+// configuration is injected with WithConfig, configs/shared.yaml and
+// configs/<serviceName>.yaml are loaded with SCRAPER_* environment overrides.
+// secret:// references in the configuration are resolved in place.
+func NewContainer(serviceName string, opts ...Option) (*Container, error) {
+	c := &Container{serviceName: serviceName}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.config == nil {
+		c.loader = config.NewLoader()
+		if err := c.loader.LoadServiceConfig(serviceName); err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		c.loader.LoadFromEnv()
+
+		cfg, err := c.loader.Config()
+		if err != nil {
+			return nil, err
+		}
+		c.config = cfg
+	}
+	if err := resolveSecrets(c.config); err != nil {
+		return nil, err
+	}
+
+	if c.logger == nil {
+		out, closer, err := OpenLogOutput(c.config.Logging, serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+		c.logger = NewLogger(c.config.Logging)
+		c.logger.SetOutput(out)
+		if closer != nil {
+			// Registered first, so the log file is closed last
+			c.hooks = append(c.hooks, Hook{
+				Name:   "log-file",
+				Stage:  StageStorage,
+				OnStop: func(context.Context) error { return closer.Close() },
+			})
+		}
+	}
+
+	var reload func() (*config.Config, error)
+	if c.loader != nil {
+		reload = func() (*config.Config, error) {
+			loader := config.NewLoader()
+			if err := loader.LoadServiceConfig(serviceName); err != nil {
+				return nil, err
+			}
+			loader.LoadFromEnv()
+			cfg, err := loader.Config()
+			if err != nil {
+				return nil, err
+			}
+			if err := resolveSecrets(cfg); err != nil {
+				return nil, err
+			}
+			return cfg, nil
+		}
+	}
+	c.watcher = config.NewWatcher(c.config, reload, c.logger)
+	c.watcher.OnChange(func(cfg *config.Config) {
+		if level, err := logrus.ParseLevel(cfg.Logging.Level); err == nil {
+			c.logger.SetLevel(level)
+		}
+	})
+
+	if c.loader != nil {
+		files := c.loader.ConfigFiles()
+		watchCtx, cancelWatch := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		c.hooks = append(c.hooks, Hook{
+			Name: "config-watcher",
+			OnStart: func(context.Context) error {
+				go func() {
+					defer close(done)
+					if err := c.watcher.Run(watchCtx, files); err != nil {
+						c.logger.WithError(err).Warn("Configuration hot-reload disabled")
+					}
+				}()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancelWatch()
+				<-done
+				return nil
+			},
+		})
+
+		if interval := c.config.Secrets.RefreshInterval; interval > 0 {
+			c.hooks = append(c.hooks, c.secretsRefreshHook(interval))
+		}
+	}
+
+	return c, nil
+}
+
+// secretsRefreshHook periodically reloads the configuration so that secret
+// references are re-resolved and rotated values reach subscribers
+func (c *Container) secretsRefreshHook(interval time.Duration) Hook {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	return Hook{
+		Name: "secrets-refresh",
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := c.watcher.Reload(); err != nil {
+							c.logger.WithError(err).Error("Failed to refresh secrets, keeping previous values")
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	}
+}
+
+// resolveSecrets replaces secret:// references in cfg with their values
+func resolveSecrets(cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	if err := secrets.NewResolverFromConfig(cfg.Secrets).ResolveStruct(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	return nil
+}
+
+// ServiceName returns the name of the service the container was built for
+func (c *Container) ServiceName() string {
+	return c.serviceName
+}
+
+// Config returns the effective service configuration, including any
+// changes applied by hot-reload since startup
+func (c *Container) Config() *config.Config {
+	return c.watcher.Current()
+}
+
+// ConfigWatcher returns the watcher that reloads configuration at runtime
+func (c *Container) ConfigWatcher() *config.Watcher {
+	return c.watcher
+}
+
+// OnConfigChange registers a function called with the new configuration
+// whenever it is reloaded from file changes or SIGHUP
+func (c *Container) OnConfigChange(fn func(*config.Config)) {
+	c.watcher.OnChange(fn)
+}
+
+// Loader returns the configuration loader for service-specific keys.
+// It is nil when the configuration was injected with WithConfig.
+func (c *Container) Loader() *config.Loader {
+	return c.loader
+}
+
+// Logger returns the service logger
+func (c *Container) Logger() *logrus.Logger {
+	return c.logger
+}
+
+// DB returns the database connection, connecting on first use. New pool
+// connections use the current configuration, so rotated database
+// credentials and a changed statement_timeout take effect without a restart.
+func (c *Container) DB() (*sql.DB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.db != nil {
+		return c.db, nil
+	}
+
+	dbConfig := c.Config().Database
+	databaseURL := func() string {
+		current := c.Config().Database
+		return database.WithStatementTimeout(current.URL(), current.StatementTimeout)
+	}
+	db, err := database.ConnectDynamic(databaseURL, orDefault(dbConfig.MaxOpenConns, 25), orDefault(dbConfig.MaxIdleConns, 5))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	c.db = db
+	c.hooks = append(c.hooks, Hook{
+		Name:   "database",
+		Stage:  StageStorage,
+		OnStop: func(context.Context) error { return db.Close() },
+	})
+	return db, nil
+}
+
+// DBPool returns the database connection pool, connecting on first use, to
+// report its statistics and tune its limits at runtime. Reloaded
+// configuration with changed max_open_conns or max_idle_conns replaces the
+// pool limits, including limits tuned at runtime.
+func (c *Container) DBPool() (*database.Pool, error) {
+	db, err := c.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pool != nil {
+		return c.pool, nil
+	}
+
+	limits := poolLimits(c.Config().Database)
+	pool := database.NewPool(db, limits)
+	c.watcher.OnChange(func(cfg *config.Config) {
+		configured := poolLimits(cfg.Database)
+		if configured == limits {
+			return
+		}
+		limits = configured
+		if err := pool.SetLimits(configured); err != nil {
+			c.logger.WithError(err).Error("Invalid database pool limits, keeping previous limits")
+		}
+	})
+	c.pool = pool
+	return pool, nil
+}
+
+// poolLimits returns the connection pool limits of the database configuration
+func poolLimits(cfg config.DatabaseConfig) database.PoolLimits {
+	return database.PoolLimits{
+		MaxOpenConns:    orDefault(cfg.MaxOpenConns, 25),
+		MaxIdleConns:    orDefault(cfg.MaxIdleConns, 5),
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+}
+
+// QueryTimeouts returns the timeouts of repository queries of the
+// configuration (database.query_timeout and database.query_timeouts)
+func (c *Container) QueryTimeouts() database.QueryTimeouts {
+	dbConfig := c.Config().Database
+	return database.NewQueryTimeouts(dbConfig.QueryTimeout, dbConfig.QueryTimeouts)
+}
+
+// Queries returns the sqlc queries bound to the service database
+func (c *Container) Queries() (*database.Queries, error) {
+	db, err := c.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queries == nil {
+		c.queries = database.New(db)
+	}
+	return c.queries, nil
+}
+
+// KafkaProducer returns the Kafka producer, creating it on first use
+func (c *Container) KafkaProducer() (*kafka.Producer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.producer != nil {
+		return c.producer, nil
+	}
+
+	opts, err := c.kafkaOptions()
+	if err != nil {
+		return nil, err
+	}
+	producer, err := kafka.NewProducer(c.Config().Kafka, c.logger, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	c.producer = producer
+	c.hooks = append(c.hooks, Hook{
+		Name:   "kafka-producer",
+		Stage:  StageProducer,
+		OnStop: func(context.Context) error { return producer.Close() },
+	})
+	return producer, nil
+}
+
+// KafkaOptions returns the options of connections to the Kafka brokers,
+// with mutual TLS when mtls.enabled and mtls.kafka are set
+func (c *Container) KafkaOptions() ([]kafka.Option, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.kafkaOptions()
+}
+
+// kafkaOptions is KafkaOptions for callers holding c.mu
+func (c *Container) kafkaOptions() ([]kafka.Option, error) {
+	cfg := c.Config().MTLS
+	if !cfg.Enabled || !cfg.Kafka {
+		return nil, nil
+	}
+	if c.kafkaTLS == nil {
+		tlsConfig, err := mtls.ClientConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up Kafka mTLS: %w", err)
+		}
+		c.kafkaTLS = tlsConfig
+	}
+	return []kafka.Option{kafka.WithTLS(c.kafkaTLS)}, nil
+}
+
+// KafkaConsumer returns the Kafka consumer, creating it on first use. The
+// consumer starts reading topics when the container starts, so handlers must
+// be registered during setup; topics passed on later calls are ignored.
+func (c *Container) KafkaConsumer(topics ...string) (*kafka.Consumer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.consumer != nil {
+		return c.consumer, nil
+	}
+
+	opts, err := c.kafkaOptions()
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := kafka.NewConsumer(c.Config().Kafka, c.logger, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
+	}
+
+	done := make(chan struct{})
+	c.consumer = consumer
+	c.hooks = append(c.hooks, Hook{
+		Name:  "kafka-consumer",
+		Stage: StageConsumer,
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				if err := consumer.Consume(topics); err != nil && !errors.Is(err, context.Canceled) {
+					c.logger.WithError(err).Error("Kafka consumer stopped")
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			err := consumer.Close()
+			<-done
+			return err
+		},
+	})
+	return consumer, nil
+}
+
+// Features returns the feature flags, backed by the service database and
+// the features section of the configuration. Flags are loaded when the
+// container starts and refreshed every features.refresh_interval.
+func (c *Container) Features() (*features.Flags, error) {
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.features != nil {
+		return c.features, nil
+	}
+
+	flags := features.New(c.Config().Features, features.NewDBStore(queries), c.logger)
+	c.watcher.OnChange(func(cfg *config.Config) {
+		flags.UpdateConfig(cfg.Features)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.features = flags
+	c.hooks = append(c.hooks, Hook{
+		Name: "feature-flags",
+		OnStart: func(startCtx context.Context) error {
+			// Flags fall back to configuration until the database is reachable
+			if err := flags.Refresh(startCtx); err != nil {
+				c.logger.WithError(err).Warn("Failed to load feature flags from database, using configured flags")
+			}
+			go func() {
+				defer close(done)
+				if interval := c.Config().Features.RefreshInterval; interval > 0 {
+					flags.Run(ctx, interval)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return flags, nil
+}
+
+// Notifier returns the dispatcher for the configured notification channels
+// and the channels stored in the service database, creating it on first use.
+// Configured channels follow configuration reloads; an invalid reloaded
+// channel list is logged and the previous one kept. Stored channels are
+// loaded when the container starts and refreshed every
+// notifications.refresh_interval.
+func (c *Container) Notifier() (*notify.Dispatcher, error) {
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := c.Keyring()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.notifier != nil {
+		return c.notifier, nil
+	}
+
+	notifier, err := notify.New(c.Config().Notifications, nil, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure notifications: %w", err)
+	}
+	notifier.SetStore(notify.NewDBStore(queries, keyring))
+	c.watcher.OnChange(func(cfg *config.Config) {
+		if err := notifier.Configure(cfg.Notifications); err != nil {
+			c.logger.WithError(err).Error("Invalid notification channels, keeping previous channels")
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.notifier = notifier
+	c.hooks = append(c.hooks, Hook{
+		Name: "notification-channels",
+		OnStart: func(startCtx context.Context) error {
+			// Alerts still reach the configured channels until the database is reachable
+			if err := notifier.Refresh(startCtx); err != nil {
+				c.logger.WithError(err).Warn("Failed to load notification channels from database, using configured channels")
+			}
+			go func() {
+				defer close(done)
+				if interval := c.Config().Notifications.RefreshInterval; interval > 0 {
+					notifier.Run(ctx, interval)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return notifier, nil
+}
+
+// Maintenance returns the maintenance mode switch stored in the service
+// database, creating it on first use. The switch is loaded when the
+// container starts and refreshed every maintenance.refresh_interval.
+func (c *Container) Maintenance() (*maintenance.Mode, error) {
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.mode != nil {
+		return c.mode, nil
+	}
+
+	mode := maintenance.New(maintenance.NewDBStore(queries), c.logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.mode = mode
+	c.hooks = append(c.hooks, Hook{
+		Name: "maintenance-mode",
+		OnStart: func(startCtx context.Context) error {
+			// Maintenance mode stays off until the database is reachable
+			if err := mode.Refresh(startCtx); err != nil {
+				c.logger.WithError(err).Warn("Failed to load maintenance mode from database")
+			}
+			go func() {
+				defer close(done)
+				if interval := c.Config().Maintenance.RefreshInterval; interval > 0 {
+					mode.Run(ctx, interval)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return mode, nil
+}
+
+// Cache returns the Redis cache of hot read endpoints, creating it on first
+// use, or nil when cache.enabled is off. Redis is connected lazily, so a
+// Redis that is down does not stop the service from starting.
+func (c *Container) Cache() *cache.Cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cfg := c.Config().Cache
+	if c.cache != nil || !cfg.Enabled {
+		return c.cache
+	}
+
+	backend := cache.NewRedis(cfg)
+	c.cache = cache.New(backend, cfg.KeyPrefix, cfg.Timeout, c.logger)
+	c.hooks = append(c.hooks, Hook{
+		Name:   "cache",
+		Stage:  StageStorage,
+		OnStop: func(context.Context) error { return backend.Close() },
+	})
+	return c.cache
+}
+
+// Tracer returns the tracer of the service, creating it on first use, or
+// nil when tracing.enabled is off. Kept traces are logged; the sampling
+// settings follow configuration changes.
+func (c *Container) Tracer() *tracing.Tracer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cfg := c.Config().Tracing
+	if c.tracer != nil || !cfg.Enabled {
+		return c.tracer
+	}
+
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = c.serviceName
+	}
+	tracer := tracing.NewTracer(cfg, tracing.NewLogExporter(c.logger), c.logger)
+	c.watcher.OnChange(func(cfg *config.Config) {
+		tracer.Configure(cfg.Tracing)
+	})
+	c.tracer = tracer
+	return tracer
+}
+
+// Keyring returns the keys encrypting sensitive fields at rest, creating
+// them on first use, or nil when encryption.keys is empty. Keys are read
+// once, so a rotation of encryption.primary_key takes a restart.
+func (c *Container) Keyring() (*encryption.Keyring, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keyed {
+		return c.keyring, nil
+	}
+	keyring, err := encryption.New(c.Config().Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption keys: %w", err)
+	}
+	c.keyring, c.keyed = keyring, true
+	return keyring, nil
+}
+
+// Append registers a lifecycle hook. Components that depend on the database
+// or Kafka should be appended after requesting them from the container so
+// they are stopped before those dependencies are closed.
+func (c *Container) Append(hook Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// Start runs the OnStart hooks in registration order. If a hook fails,
+// the hooks that already started are stopped before the error is returned.
+func (c *Container) Start(ctx context.Context) error {
+	c.mu.Lock()
+	hooks := append([]Hook(nil), c.hooks...)
+	c.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			c.logger.WithField("hook", hook.Name).Debug("Starting component")
+			if err := hook.OnStart(ctx); err != nil {
+				c.setStarted(i)
+				return errors.Join(fmt.Errorf("failed to start %s: %w", hook.Name, err), c.Shutdown(ctx))
+			}
+		}
+	}
+
+	c.setStarted(len(hooks))
+	return nil
+}
+
+// Shutdown runs the OnStop hooks stage by stage, in reverse registration
+// order within a stage. Hooks whose OnStart never ran are skipped; hooks
+// without an OnStart, such as the database, are always stopped. All hooks
+// are run even if some fail or ctx expires. A hook still running when ctx
+// expires is left behind and reported as timed out; the hooks after it are
+// still stopped, each given lateStopGrace. Failures are returned as a
+// *ShutdownError.
+func (c *Container) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	hooks := c.hooks
+	started := c.started
+	c.hooks = nil
+	c.started = 0
+	c.mu.Unlock()
+
+	order := make([]int, 0, len(hooks))
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].OnStop == nil {
+			continue
+		}
+		if hooks[i].OnStart != nil && i >= started {
+			continue
+		}
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return hooks[order[a]].Stage < hooks[order[b]].Stage
+	})
+
+	shutdownErr := &ShutdownError{}
+	for _, i := range order {
+		hook := hooks[i]
+		logger := c.logger.WithField("hook", hook.Name)
+		logger.Debug("Stopping component")
+
+		begin := time.Now()
+		stopped, err := stopHook(ctx, hook)
+		switch {
+		case !stopped:
+			logger.Error("Component did not stop in time")
+			shutdownErr.TimedOut = append(shutdownErr.TimedOut, hook.Name)
+			shutdownErr.errs = append(shutdownErr.errs, fmt.Errorf("%s did not stop in time: %w", hook.Name, ctx.Err()))
+		case err != nil:
+			logger.WithError(err).Error("Failed to stop component")
+			shutdownErr.Failed = append(shutdownErr.Failed, hook.Name)
+			shutdownErr.errs = append(shutdownErr.errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+		default:
+			logger.WithField("duration", time.Since(begin).String()).Debug("Component stopped")
+		}
+	}
+
+	if len(shutdownErr.errs) == 0 {
+		return nil
+	}
+	c.logger.WithFields(logrus.Fields{
+		"failed":    shutdownErr.Failed,
+		"timed_out": shutdownErr.TimedOut,
+	}).Error("Shutdown incomplete")
+	return shutdownErr
+}
+
+// stopHook runs a hook's OnStop and waits for it until ctx expires, or for
+// lateStopGrace if ctx had already expired. stopped is false when the hook
+// was still running at that point.
+func stopHook(ctx context.Context, hook Hook) (stopped bool, err error) {
+	done := make(chan error, 1)
+	go func() { done <- hook.OnStop(ctx) }()
+
+	waitCtx := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(context.Background(), lateStopGrace)
+		defer cancel()
+	}
+
+	select {
+	case err := <-done:
+		return true, err
+	case <-waitCtx.Done():
+		return false, nil
+	}
+}
+
+func (c *Container) setStarted(n int) {
+	c.mu.Lock()
+	c.started = n
+	c.mu.Unlock()
+}
+
+// orDefault returns value, or fallback when value is not positive
+func orDefault(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
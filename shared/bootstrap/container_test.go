@@ -0,0 +1,175 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestContainer(t *testing.T) *Container {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	c, err := NewContainer("test-service", WithConfig(config.DefaultConfig()), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("NewContainer() error = %v", err)
+	}
+	return c
+}
+
+func TestContainerLifecycleOrder(t *testing.T) {
+	c := newTestContainer(t)
+
+	var events []string
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		c.Append(Hook{
+			Name:    name,
+			OnStart: func(context.Context) error { events = append(events, "start "+name); return nil },
+			OnStop:  func(context.Context) error { events = append(events, "stop "+name); return nil },
+		})
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{"start first", "start second", "start third", "stop third", "stop second", "stop first"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestContainerStartFailureStopsStartedHooks(t *testing.T) {
+	c := newTestContainer(t)
+
+	var stopped []string
+	c.Append(Hook{Name: "resource", OnStop: func(context.Context) error { stopped = append(stopped, "resource"); return nil }})
+	c.Append(Hook{
+		Name:    "ok",
+		OnStart: func(context.Context) error { return nil },
+		OnStop:  func(context.Context) error { stopped = append(stopped, "ok"); return nil },
+	})
+	c.Append(Hook{
+		Name:    "broken",
+		OnStart: func(context.Context) error { return errors.New("boom") },
+		OnStop:  func(context.Context) error { stopped = append(stopped, "broken"); return nil },
+	})
+
+	if err := c.Start(context.Background()); err == nil {
+		t.Fatal("expected Start() to fail")
+	}
+
+	want := []string{"ok", "resource"}
+	if !reflect.DeepEqual(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+}
+
+func TestContainerShutdownStageOrder(t *testing.T) {
+	c := newTestContainer(t)
+
+	var stopped []string
+	stop := func(name string) func(context.Context) error {
+		return func(context.Context) error { stopped = append(stopped, name); return nil }
+	}
+	c.Append(Hook{Name: "database", Stage: StageStorage, OnStop: stop("database")})
+	c.Append(Hook{Name: "producer", Stage: StageProducer, OnStop: stop("producer")})
+	c.Append(Hook{Name: "consumer", Stage: StageConsumer, OnStop: stop("consumer")})
+	c.Append(Hook{Name: "scheduler", OnStop: stop("scheduler")})
+	c.Append(Hook{Name: "watchdog", OnStop: stop("watchdog")})
+	c.Append(Hook{Name: "http", Stage: StageServer, OnStop: stop("http")})
+	// Registered late, e.g. a lazily created dependency, but still stopped in its stage
+	c.Append(Hook{Name: "late-producer", Stage: StageProducer, OnStop: stop("late-producer")})
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{"http", "watchdog", "scheduler", "consumer", "late-producer", "producer", "database"}
+	if !reflect.DeepEqual(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+}
+
+func TestContainerShutdownReportsFailures(t *testing.T) {
+	c := newTestContainer(t)
+
+	release := make(chan struct{})
+	defer close(release)
+	var databaseClosed bool
+	c.Append(Hook{Name: "database", Stage: StageStorage, OnStop: func(context.Context) error { databaseClosed = true; return nil }})
+	c.Append(Hook{Name: "stuck", OnStop: func(context.Context) error { <-release; return nil }})
+	c.Append(Hook{Name: "broken", OnStop: func(context.Context) error { return errors.New("boom") }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.Shutdown(ctx)
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("Shutdown() error = %v, want a *ShutdownError", err)
+	}
+	if !reflect.DeepEqual(shutdownErr.Failed, []string{"broken"}) {
+		t.Errorf("Failed = %v, want [broken]", shutdownErr.Failed)
+	}
+	if !reflect.DeepEqual(shutdownErr.TimedOut, []string{"stuck"}) {
+		t.Errorf("TimedOut = %v, want [stuck]", shutdownErr.TimedOut)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if !databaseClosed {
+		t.Error("database was not closed after a component timed out")
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	if got := NewLogger(config.LoggingConfig{Level: "debug"}).GetLevel(); got != logrus.DebugLevel {
+		t.Errorf("level = %v, want debug", got)
+	}
+	if got := NewLogger(config.LoggingConfig{Level: "bogus"}).GetLevel(); got != logrus.InfoLevel {
+		t.Errorf("level = %v, want info fallback", got)
+	}
+}
+
+func TestOpenLogOutput(t *testing.T) {
+	if out, closer, err := OpenLogOutput(config.LoggingConfig{Output: config.LogOutputStdout}, "api-gateway"); err != nil || out != os.Stdout || closer != nil {
+		t.Errorf("stdout output = %v, %v, %v", out, closer, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "logs", "url-manager.log")
+	cfg := config.DefaultConfig().Logging
+	cfg.Output = config.LogOutputFile
+	cfg.File.Path = path
+	out, closer, err := OpenLogOutput(cfg, "url-manager")
+	if err != nil {
+		t.Fatalf("OpenLogOutput() error = %v", err)
+	}
+	defer closer.Close()
+	logger := NewLogger(cfg)
+	logger.SetOutput(out)
+	logger.Info("scheduler started")
+
+	content, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(content), "scheduler started") {
+		t.Errorf("log file = %q, %v; want the entry", content, err)
+	}
+}
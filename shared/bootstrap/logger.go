@@ -0,0 +1,58 @@
+package bootstrap
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/logfile"
+)
+
+// NewLogger creates a logger from the logging configuration.
+// Unknown or empty levels fall back to info; the format defaults to JSON.
+// The logger writes to stderr until its output is set, see OpenLogOutput.
+func NewLogger(cfg config.LoggingConfig) *logrus.Logger {
+	logger := logrus.New()
+
+	if cfg.Format == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	logger.SetLevel(level)
+
+	return logger
+}
+
+// OpenLogOutput opens the output of the logging configuration: stdout,
+// stderr, or the rotated log file of file output, by default
+// logs/<serviceName>.log, and stdout too when file.stdout is set. The
+// closer closes the log file; it is nil for the standard streams.
+func OpenLogOutput(cfg config.LoggingConfig, serviceName string) (io.Writer, io.Closer, error) {
+	switch cfg.Output {
+	case config.LogOutputStderr:
+		return os.Stderr, nil, nil
+	case config.LogOutputFile:
+		fileCfg := cfg.File
+		if fileCfg.Path == "" {
+			fileCfg.Path = filepath.Join("logs", serviceName+".log")
+		}
+		file, err := logfile.Open(fileCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fileCfg.Stdout {
+			return io.MultiWriter(file, os.Stdout), file, nil
+		}
+		return file, file, nil
+	}
+	return os.Stdout, nil, nil
+}
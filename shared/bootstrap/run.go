@@ -0,0 +1,199 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/mtls"
+)
+
+// DefaultShutdownTimeout bounds how long Run waits for components to stop
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Service describes a service started by Run
+type Service struct {
+	// Name selects configs/<Name>.yaml and is used in log messages
+	Name string
+
+	// Setup wires the service's components using the container, registering
+	// lifecycle hooks for anything that must be started or stopped. It returns
+	// the HTTP handler to serve on server.port, or nil for background services.
+	Setup func(c *Container) (http.Handler, error)
+
+	// Options customize the container, e.g. to inject configuration in tests
+	Options []Option
+
+	// Dependencies, such as DependencyDatabase and DependencyKafka, are
+	// probed before Setup until they are reachable, see Container.WaitFor
+	Dependencies []string
+
+	// ShutdownTimeout overrides DefaultShutdownTimeout
+	ShutdownTimeout time.Duration
+}
+
+// Run builds the container, waits for the service's dependencies, sets up
+// the service, starts it (terminating TLS and redirecting plain HTTP when
+// server.tls is enabled, or requiring client certificates with mtls.server),
+// and blocks until SIGINT/SIGTERM or an HTTP server
+// failure, then shuts everything down in dependency order: HTTP server
+// first, then service components, then Kafka consumers and producers, and
+// the database last. Components that fail
+// or do not stop within the shutdown timeout are logged and returned as a
+// *ShutdownError.
+func Run(svc Service) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return run(ctx, svc)
+}
+
+// run is Run with the shutdown signal supplied by the caller
+func run(ctx context.Context, svc Service) error {
+	container, err := NewContainer(svc.Name, svc.Options...)
+	if err != nil {
+		return fmt.Errorf("failed to initialize %s: %w", svc.Name, err)
+	}
+	logger := container.Logger()
+
+	if err := container.WaitFor(ctx, svc.Dependencies...); err != nil {
+		return fmt.Errorf("failed to start %s: %w", svc.Name, err)
+	}
+
+	handler, err := svc.Setup(container)
+	if err != nil {
+		shutdownErr := container.Shutdown(context.Background())
+		return errors.Join(fmt.Errorf("failed to set up %s: %w", svc.Name, err), shutdownErr)
+	}
+
+	serverErrors := make(chan error, 1)
+	if handler != nil {
+		serverCfg := container.Config().Server
+		server := NewHTTPServer(serverCfg, handler)
+		switch mtlsCfg := container.Config().MTLS; {
+		case mtlsCfg.Enabled && mtlsCfg.Server:
+			server.TLSConfig, err = mtls.ServerConfig(mtlsCfg)
+		case serverCfg.TLS.Enabled:
+			server.TLSConfig, err = NewTLSConfig(serverCfg.TLS)
+		}
+		if err != nil {
+			shutdownErr := container.Shutdown(context.Background())
+			return errors.Join(fmt.Errorf("failed to set up %s: %w", svc.Name, err), shutdownErr)
+		}
+		container.Append(Hook{
+			Name:  "http-server",
+			Stage: StageServer,
+			OnStart: func(context.Context) error {
+				listener, err := net.Listen("tcp", server.Addr)
+				if err != nil {
+					return err
+				}
+				serve := server.Serve
+				if server.TLSConfig != nil {
+					serve = func(l net.Listener) error { return server.ServeTLS(l, "", "") }
+					logger.Infof("Starting %s server on %s with TLS", svc.Name, listener.Addr())
+				} else {
+					logger.Infof("Starting %s server on %s", svc.Name, listener.Addr())
+				}
+				go func() {
+					if err := serve(listener); err != nil && err != http.ErrServerClosed {
+						serverErrors <- err
+					}
+				}()
+				return nil
+			},
+			OnStop: server.Shutdown,
+		})
+		if server.TLSConfig != nil && serverCfg.TLS.Enabled && serverCfg.TLS.RedirectPort > 0 {
+			_, port, _ := net.SplitHostPort(server.Addr)
+			httpsPort, _ := strconv.Atoi(port)
+			redirect := NewRedirectServer(serverCfg.TLS.RedirectPort, httpsPort)
+			container.Append(Hook{
+				Name:  "http-redirect",
+				Stage: StageServer,
+				OnStart: func(context.Context) error {
+					listener, err := net.Listen("tcp", redirect.Addr)
+					if err != nil {
+						return err
+					}
+					logger.Infof("Redirecting HTTP on %s to HTTPS", listener.Addr())
+					go func() {
+						if err := redirect.Serve(listener); err != nil && err != http.ErrServerClosed {
+							serverErrors <- err
+						}
+					}()
+					return nil
+				},
+				OnStop: redirect.Shutdown,
+			})
+		}
+	}
+
+	if err := container.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start %s: %w", svc.Name, err)
+	}
+	logger.Infof("%s started", svc.Name)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Infof("Shutting down %s...", svc.Name)
+	case runErr = <-serverErrors:
+		logger.WithError(runErr).Errorf("%s server failed, shutting down", svc.Name)
+	}
+
+	timeout := svc.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := container.Shutdown(shutdownCtx); err != nil {
+		return errors.Join(runErr, err)
+	}
+
+	logger.Infof("%s exited", svc.Name)
+	return runErr
+}
+
+// NewHTTPServer creates an HTTP server from the server configuration,
+// falling back to port 8080 and 30s/30s/60s read/write/idle timeouts
+func NewHTTPServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	if cfg.Port == 0 {
+		cfg.Port = 8080
+	}
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = 30 * time.Second
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = 30 * time.Second
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = 60 * time.Second
+	}
+
+	return &http.Server{
+		Addr:         ":" + strconv.Itoa(cfg.Port),
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// Exit runs the service and exits the process with a non-zero status on failure.
+// It is intended to be the whole body of a service's main function.
+func Exit(svc Service) {
+	if err := Run(svc); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,77 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRunServesAndShutsDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Server.Port = port
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	stopped := make(chan struct{})
+	svc := Service{
+		Name:    "test-service",
+		Options: []Option{WithConfig(cfg), WithLogger(logger)},
+		Setup: func(c *Container) (http.Handler, error) {
+			c.Append(Hook{Name: "worker", OnStop: func(context.Context) error { close(stopped); return nil }})
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}), nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- run(ctx, svc) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/", port)
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get(url); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server never became reachable: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run() did not return after cancellation")
+	}
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("service component was not stopped")
+	}
+}
@@ -0,0 +1,49 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/mtls"
+)
+
+// NewTLSConfig creates the TLS configuration of a server terminating TLS,
+// loading the certificate and key now so that missing or invalid files fail
+// the start, and reloading them when they change
+func NewTLSConfig(cfg config.ServerTLSConfig) (*tls.Config, error) {
+	certs, err := mtls.NewReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	minVersion := uint16(tls.VersionTLS12)
+	if cfg.MinVersion == config.TLSVersion13 {
+		minVersion = tls.VersionTLS13
+	}
+	return &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: certs.GetCertificate,
+	}, nil
+}
+
+// NewRedirectServer creates a plain HTTP server on port that redirects
+// every request to the same URL over HTTPS on httpsPort
+func NewRedirectServer(port, httpsPort int) *http.Server {
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(port),
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if httpsPort != 443 {
+				host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+			}
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+		}),
+	}
+}
@@ -0,0 +1,36 @@
+package bootstrap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go_scraping_project/shared/config"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewTLSConfig(config.ServerTLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}); err == nil {
+		t.Fatal("NewTLSConfig() without certificate files succeeded")
+	}
+}
+
+func TestRedirectServer(t *testing.T) {
+	tests := []struct {
+		httpsPort int
+		url       string
+		want      string
+	}{
+		{443, "http://gateway.example.com/api/v1/urls?limit=5", "https://gateway.example.com/api/v1/urls?limit=5"},
+		{443, "http://gateway.example.com:80/health", "https://gateway.example.com/health"},
+		{8443, "http://gateway.example.com:8080/health", "https://gateway.example.com:8443/health"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		NewRedirectServer(80, tt.httpsPort).Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if rec.Code != http.StatusPermanentRedirect || rec.Header().Get("Location") != tt.want {
+			t.Errorf("%s redirected with %d to %q, want 308 to %q", tt.url, rec.Code, rec.Header().Get("Location"), tt.want)
+		}
+	}
+}
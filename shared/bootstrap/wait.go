@@ -0,0 +1,98 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Dependencies a service can wait for at startup, see Service.Dependencies
+const (
+	DependencyDatabase = "database"
+	DependencyKafka    = "kafka"
+)
+
+// probeTimeout bounds a single probe of a dependency
+const probeTimeout = 5 * time.Second
+
+// WaitFor probes the named dependencies until each is reachable, retrying
+// with exponential backoff as configured in the startup section. It returns
+// an error once startup.max_wait has passed without a dependency becoming
+// reachable, or when ctx is cancelled.
+func (c *Container) WaitFor(ctx context.Context, dependencies ...string) error {
+	cfg := c.Config()
+	for _, name := range dependencies {
+		var probe func(ctx context.Context) error
+		switch name {
+		case DependencyDatabase:
+			probe = func(ctx context.Context) error {
+				return database.Ping(ctx, c.Config().Database.URL())
+			}
+		case DependencyKafka:
+			opts, err := c.KafkaOptions()
+			if err != nil {
+				return err
+			}
+			offsets, err := kafka.NewOffsets(cfg.Kafka.Brokers, opts...)
+			if err != nil {
+				return err
+			}
+			probe = offsets.Ping
+		default:
+			return fmt.Errorf("unknown dependency %q", name)
+		}
+
+		if err := waitFor(ctx, cfg.Startup, c.logger, name, probe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitFor retries probe until it succeeds or cfg.MaxWait has passed
+func waitFor(ctx context.Context, cfg config.StartupConfig, logger *logrus.Logger, name string, probe func(ctx context.Context) error) error {
+	begin := time.Now()
+	deadline := begin.Add(cfg.MaxWait)
+	backoff := max(cfg.InitialBackoff, time.Millisecond)
+	maxBackoff := max(cfg.MaxBackoff, backoff)
+
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				logger.WithFields(logrus.Fields{
+					"dependency": name,
+					"attempts":   attempt,
+					"waited":     time.Since(begin).Round(time.Millisecond).String(),
+				}).Infof("%s is reachable", name)
+			}
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("%s not reachable after %s (%d attempts): %w", name, cfg.MaxWait, attempt, err)
+		}
+		wait := min(backoff, remaining)
+		logger.WithError(err).WithFields(logrus.Fields{
+			"dependency": name,
+			"attempt":    attempt,
+			"retry_in":   wait.String(),
+		}).Warnf("Waiting for %s", name)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for %s: %w", name, ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
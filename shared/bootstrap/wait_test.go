@@ -0,0 +1,57 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWaitForRetriesUntilReachable(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := config.StartupConfig{MaxWait: time.Second, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+	attempts := 0
+	err := waitFor(context.Background(), cfg, logger, "database", func(context.Context) error {
+		attempts++
+		if attempts < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("waitFor() error = %v", err)
+	}
+	if attempts != 4 {
+		t.Errorf("attempts = %d, want 4", attempts)
+	}
+}
+
+func TestWaitForGivesUp(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	refused := errors.New("connection refused")
+
+	cfg := config.StartupConfig{MaxWait: 20 * time.Millisecond, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	begin := time.Now()
+	err := waitFor(context.Background(), cfg, logger, "kafka", func(context.Context) error { return refused })
+	if !errors.Is(err, refused) {
+		t.Fatalf("waitFor() error = %v, want the last probe error", err)
+	}
+	if elapsed := time.Since(begin); elapsed < cfg.MaxWait || elapsed > time.Second {
+		t.Errorf("gave up after %s, want about %s", elapsed, cfg.MaxWait)
+	}
+
+	// Without a max wait the dependency is probed once
+	attempts := 0
+	err = waitFor(context.Background(), config.StartupConfig{}, logger, "kafka", func(context.Context) error { attempts++; return refused })
+	if err == nil || attempts != 1 {
+		t.Errorf("waitFor() error = %v after %d attempts, want an error after 1", err, attempts)
+	}
+}
@@ -0,0 +1,166 @@
+// Package cache is a read-through JSON cache for hot read endpoints, backed
+// by Redis or, in tests, memory. The cache never fails a read: a value that
+// cannot be read from or written to the backend is loaded from its source.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrMiss is returned by Backend.Get for keys that are not cached
+var ErrMiss = errors.New("cache miss")
+
+// Backend stores raw values for a time
+type Backend interface {
+	// Get returns the value of a key, ErrMiss if it is not cached
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set caches the value of a key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete drops keys
+	Delete(ctx context.Context, keys ...string) error
+	// Incr increments the integer value of a key, from 0 if it is not cached,
+	// and keeps it without expiry
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Cache caches JSON encoded values in a backend under a key prefix. A nil
+// Cache caches nothing, so callers need not check whether caching is enabled.
+//
+// Values that depend on many records, such as listings, are cached in a
+// group: Bump drops every value of a group at once, by moving the group to a
+// new generation that is part of the keys of its values.
+type Cache struct {
+	backend Backend
+	prefix  string
+	timeout time.Duration
+	logger  *logrus.Logger
+}
+
+// New creates a cache over backend. Keys are prefixed with prefix, and every
+// backend operation is bounded by timeout.
+func New(backend Backend, prefix string, timeout time.Duration, logger *logrus.Logger) *Cache {
+	return &Cache{
+		backend: backend,
+		prefix:  prefix,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Fetch returns the value cached under key, or loads it with load and caches
+// it for ttl. Errors of load are returned and not cached.
+func Fetch[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	return fetch(ctx, c, c.prefix+key, ttl, load)
+}
+
+// FetchInGroup is Fetch for a value of a group, which Bump drops
+func FetchInGroup[T any](ctx context.Context, c *Cache, group, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+	generation, ok := c.generation(ctx, group)
+	if !ok {
+		// Without the generation a stale value could be read or written
+		return load(ctx)
+	}
+	return fetch(ctx, c, c.prefix+group+":"+generation+":"+key, ttl, load)
+}
+
+func fetch[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	var value T
+	opCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	data, err := c.backend.Get(opCtx, key)
+	cancel()
+	if err == nil {
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+		c.logger.WithField("key", key).Warn("Ignoring undecodable cache entry")
+	} else if !errors.Is(err, ErrMiss) {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to read cache")
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+	data, err = json.Marshal(value)
+	if err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to encode cache entry")
+		return value, nil
+	}
+	opCtx, cancel = context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if err := c.backend.Set(opCtx, key, data, ttl); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to write cache")
+	}
+	return value, nil
+}
+
+// Ping checks that the backend answers, if it can tell, see health.Probe
+func (c *Cache) Ping(ctx context.Context) error {
+	if pinger, ok := c.backend.(interface{ Ping(context.Context) error }); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Invalidate drops the values cached under keys
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if err := c.backend.Delete(ctx, prefixed...); err != nil {
+		c.logger.WithError(err).WithField("keys", keys).Warn("Failed to invalidate cache")
+	}
+}
+
+// Bump drops every value cached in a group
+func (c *Cache) Bump(ctx context.Context, group string) {
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if _, err := c.backend.Incr(ctx, c.generationKey(group)); err != nil {
+		c.logger.WithError(err).WithField("group", group).Warn("Failed to invalidate cache group")
+	}
+}
+
+// generation returns the current generation of a group, false if it cannot
+// be read
+func (c *Cache) generation(ctx context.Context, group string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	data, err := c.backend.Get(ctx, c.generationKey(group))
+	if errors.Is(err, ErrMiss) {
+		return "0", true
+	}
+	if err != nil {
+		c.logger.WithError(err).WithField("group", group).Warn("Failed to read cache group generation")
+		return "", false
+	}
+	if _, err := strconv.ParseInt(string(data), 10, 64); err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (c *Cache) generationKey(group string) string {
+	return c.prefix + "generation:" + group
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// failingBackend fails every operation, like a Redis that is down
+type failingBackend struct{}
+
+var errBackendDown = errors.New("connection refused")
+
+func (failingBackend) Get(context.Context, string) ([]byte, error) { return nil, errBackendDown }
+func (failingBackend) Set(context.Context, string, []byte, time.Duration) error {
+	return errBackendDown
+}
+func (failingBackend) Delete(context.Context, ...string) error     { return errBackendDown }
+func (failingBackend) Incr(context.Context, string) (int64, error) { return 0, errBackendDown }
+
+type item struct {
+	Name  string
+	Count int
+}
+
+func newTestCache(backend Backend) *Cache {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return New(backend, "test:", time.Second, logger)
+}
+
+// counter returns a loader counting its calls
+func counter(calls *int, err error) func(context.Context) (item, error) {
+	return func(context.Context) (item, error) {
+		*calls++
+		return item{Name: "urls", Count: *calls}, err
+	}
+}
+
+func TestFetch(t *testing.T) {
+	memory := NewMemory()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	memory.now = func() time.Time { return now }
+	c := newTestCache(memory)
+	ctx := context.Background()
+	calls := 0
+
+	for i := 0; i < 3; i++ {
+		got, err := Fetch(ctx, c, "stats", time.Minute, counter(&calls, nil))
+		if err != nil || got != (item{Name: "urls", Count: 1}) {
+			t.Fatalf("Fetch() = %+v, %v, want the first loaded value", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loaded %d times, want 1", calls)
+	}
+
+	now = now.Add(time.Minute)
+	if got, _ := Fetch(ctx, c, "stats", time.Minute, counter(&calls, nil)); got.Count != 2 {
+		t.Errorf("Fetch() after the TTL = %+v, want a reload", got)
+	}
+
+	c.Invalidate(ctx, "stats")
+	if got, _ := Fetch(ctx, c, "stats", time.Minute, counter(&calls, nil)); got.Count != 3 {
+		t.Errorf("Fetch() after Invalidate = %+v, want a reload", got)
+	}
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	c := newTestCache(NewMemory())
+	calls := 0
+	loadErr := errors.New("database down")
+
+	if _, err := Fetch(context.Background(), c, "stats", time.Minute, counter(&calls, loadErr)); !errors.Is(err, loadErr) {
+		t.Fatalf("Fetch() error = %v, want the load error", err)
+	}
+	if got, err := Fetch(context.Background(), c, "stats", time.Minute, counter(&calls, nil)); err != nil || got.Count != 2 {
+		t.Errorf("Fetch() = %+v, %v, want a reload after a failed load", got, err)
+	}
+}
+
+func TestFetchInGroup(t *testing.T) {
+	c := newTestCache(NewMemory())
+	ctx := context.Background()
+	calls := 0
+
+	Fetch(ctx, c, "url:1", time.Minute, counter(&calls, nil))
+	FetchInGroup(ctx, c, "urls", "page:1", time.Minute, counter(&calls, nil))
+	FetchInGroup(ctx, c, "urls", "page:1", time.Minute, counter(&calls, nil))
+	if calls != 2 {
+		t.Fatalf("loaded %d times, want 2", calls)
+	}
+
+	c.Bump(ctx, "urls")
+	if got, _ := FetchInGroup(ctx, c, "urls", "page:1", time.Minute, counter(&calls, nil)); got.Count != 3 {
+		t.Errorf("FetchInGroup() after Bump = %+v, want a reload", got)
+	}
+	if got, _ := Fetch(ctx, c, "url:1", time.Minute, counter(&calls, nil)); got.Count != 1 {
+		t.Errorf("Fetch() after Bump = %+v, want the value outside the group kept", got)
+	}
+}
+
+func TestFetchFallsBackWhenBackendFails(t *testing.T) {
+	c := newTestCache(failingBackend{})
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		if _, err := FetchInGroup(context.Background(), c, "urls", "page:1", time.Minute, counter(&calls, nil)); err != nil {
+			t.Fatalf("FetchInGroup() error = %v, want the loaded value", err)
+		}
+		if _, err := Fetch(context.Background(), c, "url:1", time.Minute, counter(&calls, nil)); err != nil {
+			t.Fatalf("Fetch() error = %v, want the loaded value", err)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("loaded %d times, want 4", calls)
+	}
+	c.Invalidate(context.Background(), "url:1")
+	c.Bump(context.Background(), "urls")
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	calls := 0
+	for i := 0; i < 2; i++ {
+		if _, err := FetchInGroup(context.Background(), c, "urls", "page:1", time.Minute, counter(&calls, nil)); err != nil {
+			t.Fatalf("FetchInGroup() error = %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("loaded %d times, want 2", calls)
+	}
+	c.Invalidate(context.Background(), "url:1")
+	c.Bump(context.Background(), "urls")
+}
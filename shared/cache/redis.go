@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Backend storing values in Redis
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a Redis backend for the cache configuration. It connects
+// lazily, so a Redis that is down only makes reads fall back to their source.
+func NewRedis(cfg config.CacheConfig) *Redis {
+	return &Redis{
+		client: redis.NewClient(&redis.Options{
+			Addr:         cfg.Address,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			DialTimeout:  cfg.Timeout,
+			ReadTimeout:  cfg.Timeout,
+			WriteTimeout: cfg.Timeout,
+		}),
+	}
+}
+
+// Get returns the value of a key, ErrMiss if it is not cached
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+// Set caches the value of a key for ttl
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete drops keys
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// Incr increments the integer value of a key
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// Ping checks that Redis answers, see health.Probe
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close closes the connections to Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// gateway calls the API Gateway endpoints the load test drives. Only the
+// fields the report needs are decoded.
+type gateway struct {
+	baseURL string
+	client  *http.Client
+}
+
+type createdURL struct {
+	ID string `json:"id"`
+}
+
+type scrape struct {
+	TaskID      string `json:"task_id"`
+	Status      string `json:"status"`
+	DurationMs  int64  `json:"duration_ms"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at"`
+}
+
+type dataRecord struct {
+	URLID     string `json:"url_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type dataDelta struct {
+	Data      []dataRecord `json:"data"`
+	NextToken string       `json:"next_token"`
+	HasMore   bool         `json:"has_more"`
+}
+
+// CreateURL registers a URL tagged with tag
+func (g *gateway) CreateURL(ctx context.Context, address, frequency, tag string) (string, error) {
+	body := map[string]interface{}{
+		"url":       address,
+		"frequency": frequency,
+		"tags":      []string{tag},
+		"parser_config": map[string]interface{}{
+			"template": "product-page",
+		},
+	}
+	var created createdURL
+	if err := g.do(ctx, http.MethodPost, "/api/v1/urls", body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// TriggerScrape scrapes a URL now
+func (g *gateway) TriggerScrape(ctx context.Context, id string) error {
+	return g.do(ctx, http.MethodPost, "/api/v1/urls/"+id+"/scrape", nil, nil)
+}
+
+// LatestScrape returns the newest scrape of a URL, nil if it has none
+func (g *gateway) LatestScrape(ctx context.Context, id string) (*scrape, error) {
+	var page struct {
+		Scrapes []scrape `json:"scrapes"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/api/v1/urls/"+id+"/scrapes?limit=1", nil, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Scrapes) == 0 {
+		return nil, nil
+	}
+	return &page.Scrapes[0], nil
+}
+
+// Delta returns the parsed records changed since token
+func (g *gateway) Delta(ctx context.Context, token string, limit int) (dataDelta, error) {
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if token != "" {
+		query.Set("token", token)
+	}
+	var delta dataDelta
+	err := g.do(ctx, http.MethodGet, "/api/v1/data/delta?"+query.Encode(), nil, &delta)
+	return delta, err
+}
+
+// DeleteTagged soft-deletes every URL tagged with tag
+func (g *gateway) DeleteTagged(ctx context.Context, tag string) error {
+	return g.do(ctx, http.MethodDelete, "/api/v1/urls/bulk", map[string]string{"tag": tag}, nil)
+}
+
+func (g *gateway) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,304 @@
+// Command loadgen load tests the scraping pipeline end to end. It serves a
+// mock target site, registers N synthetic URLs on it through the API
+// Gateway, has them scraped, either right away or by the scheduler, and
+// follows each one through schedule, scrape and parse, reporting the
+// throughput and latencies of the run.
+//
+// The services and infrastructure must be running, e.g. with make dev-local,
+// and the scrapers must be able to reach the mock target at -target-host.
+//
+// Usage:
+//
+//	go run ./cmd/loadgen -urls 500 -concurrency 20
+//	go run ./cmd/loadgen -mode schedule -frequency 5m -error-rate 0.05
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Final and intermediate scrape statuses of GET /api/v1/urls/{id}/scrapes
+const (
+	scrapeStatusPending = "pending"
+	scrapeStatusSuccess = "success"
+)
+
+// deltaPageSize is the maximum page size of GET /api/v1/data/delta
+const deltaPageSize = 1000
+
+type options struct {
+	gatewayURL  string
+	urls        int
+	concurrency int
+	listen      string
+	targetHost  string
+	latency     time.Duration
+	errorRate   float64
+	mode        string
+	frequency   string
+	timeout     time.Duration
+	poll        time.Duration
+	tag         string
+	keep        bool
+}
+
+// tracked is a synthetic URL followed through the pipeline
+type tracked struct {
+	id       string
+	scrape   *scrape   // First finished scrape
+	parsedAt time.Time // Creation of its parsed record, zero until parsed
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.gatewayURL, "gateway", "http://localhost:8080", "Base URL of the API Gateway")
+	flag.IntVar(&opts.urls, "urls", 100, "Number of synthetic URLs")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "Concurrent requests to the API Gateway")
+	flag.StringVar(&opts.listen, "listen", "127.0.0.1:0", "Listen address of the mock target")
+	flag.StringVar(&opts.targetHost, "target-host", "", "Host:port the scrapers reach the mock target at (default: the listen address)")
+	flag.DurationVar(&opts.latency, "latency", 50*time.Millisecond, "Response latency of the mock target")
+	flag.Float64Var(&opts.errorRate, "error-rate", 0, "Share of pages the mock target fails with 503, between 0 and 1")
+	flag.StringVar(&opts.mode, "mode", "trigger", "trigger to scrape every URL at once, schedule to leave it to the scheduler")
+	flag.StringVar(&opts.frequency, "frequency", "1h", "Scraping frequency of the synthetic URLs")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Minute, "Maximum time to wait for the pipeline")
+	flag.DurationVar(&opts.poll, "poll", 2*time.Second, "Interval between progress checks")
+	flag.StringVar(&opts.tag, "tag", fmt.Sprintf("loadgen-%d", time.Now().Unix()), "Tag of the synthetic URLs")
+	flag.BoolVar(&opts.keep, "keep", false, "Keep the synthetic URLs instead of deleting them afterwards")
+	flag.Parse()
+
+	if opts.urls < 1 || opts.concurrency < 1 || opts.errorRate < 0 || opts.errorRate > 1 || (opts.mode != "trigger" && opts.mode != "schedule") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	listener, err := net.Listen("tcp", opts.listen)
+	if err != nil {
+		return fmt.Errorf("failed to start mock target: %w", err)
+	}
+	site := &target{latency: opts.latency, errorRate: opts.errorRate}
+	server := &http.Server{Handler: site}
+	go server.Serve(listener)
+	defer server.Close()
+	if opts.targetHost == "" {
+		opts.targetHost = listener.Addr().String()
+	}
+	log.Printf("Mock target serving at %s, advertised as %s", listener.Addr(), opts.targetHost)
+
+	gw := &gateway{baseURL: opts.gatewayURL, client: &http.Client{Timeout: 30 * time.Second}}
+
+	// Records parsed before the run are skipped by starting from the
+	// current end of the delta feed
+	token, err := drainDelta(ctx, gw, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to read the data delta feed: %w", err)
+	}
+
+	rep := &report{URLs: opts.urls}
+	urls := make([]*tracked, opts.urls)
+	var mu sync.Mutex
+	createErr := forEach(ctx, opts.concurrency, opts.urls, func(ctx context.Context, i int) error {
+		id, err := gw.CreateURL(ctx, fmt.Sprintf("http://%s/page/%d", opts.targetHost, i), opts.frequency, opts.tag)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		urls[i] = &tracked{id: id}
+		rep.Created++
+		mu.Unlock()
+		return nil
+	})
+	if !opts.keep {
+		defer func() {
+			// The run's context may be cancelled by now
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := gw.DeleteTagged(ctx, opts.tag); err != nil {
+				log.Printf("Failed to delete the synthetic URLs tagged %s: %v", opts.tag, err)
+			}
+		}()
+	}
+	if createErr != nil {
+		return fmt.Errorf("failed to create URLs: %w", createErr)
+	}
+	log.Printf("Created %d URLs tagged %s", rep.Created, opts.tag)
+
+	start := time.Now()
+	if opts.mode == "trigger" {
+		if err := forEach(ctx, opts.concurrency, len(urls), func(ctx context.Context, i int) error {
+			return gw.TriggerScrape(ctx, urls[i].id)
+		}); err != nil {
+			return fmt.Errorf("failed to trigger scrapes: %w", err)
+		}
+	}
+
+	byID := make(map[string]*tracked, len(urls))
+	for _, u := range urls {
+		byID[u.id] = u
+	}
+	last := start
+	deadline := time.After(opts.timeout)
+	ticker := time.NewTicker(opts.poll)
+	defer ticker.Stop()
+	for !finished(urls) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			rep.TimedOut = true
+		case <-ticker.C:
+		}
+		if rep.TimedOut {
+			break
+		}
+
+		if err := forEach(ctx, opts.concurrency, len(urls), func(ctx context.Context, i int) error {
+			u := urls[i]
+			if u.scrape != nil {
+				return nil
+			}
+			s, err := gw.LatestScrape(ctx, u.id)
+			if err != nil || s == nil || s.Status == scrapeStatusPending {
+				return err
+			}
+			mu.Lock()
+			u.scrape = s
+			last = time.Now()
+			mu.Unlock()
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to check scrapes: %w", err)
+		}
+
+		token, err = drainDelta(ctx, gw, token, func(record dataRecord) {
+			if u, ok := byID[record.URLID]; ok && u.parsedAt.IsZero() {
+				if at, err := time.Parse(time.RFC3339Nano, record.CreatedAt); err == nil {
+					u.parsedAt = at
+					last = time.Now()
+				}
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read the data delta feed: %w", err)
+		}
+	}
+
+	rep.Elapsed = last.Sub(start)
+	rep.Served, rep.Failed = site.served.Load(), site.failed.Load()
+	for _, u := range urls {
+		collect(rep, u)
+	}
+	rep.Write(os.Stdout)
+	return nil
+}
+
+// finished reports whether every URL has a finished scrape, and every
+// successful one a parsed record
+func finished(urls []*tracked) bool {
+	for _, u := range urls {
+		if u.scrape == nil || (u.scrape.Status == scrapeStatusSuccess && u.parsedAt.IsZero()) {
+			return false
+		}
+	}
+	return true
+}
+
+// collect adds the outcome of a URL to the report
+func collect(rep *report, u *tracked) {
+	if u.scrape == nil {
+		return
+	}
+	rep.Scraped++
+	if u.scrape.Status == scrapeStatusSuccess {
+		rep.Succeeded++
+	}
+	rep.Fetch = append(rep.Fetch, time.Duration(u.scrape.DurationMs)*time.Millisecond)
+
+	published, err := time.Parse(time.RFC3339Nano, u.scrape.CreatedAt)
+	if err != nil {
+		return
+	}
+	if completed, err := time.Parse(time.RFC3339Nano, u.scrape.CompletedAt); err == nil {
+		rep.Scrape = append(rep.Scrape, completed.Sub(published))
+	}
+	if !u.parsedAt.IsZero() {
+		rep.Parsed++
+		rep.EndToEnd = append(rep.EndToEnd, u.parsedAt.Sub(published))
+	}
+}
+
+// drainDelta reads the data delta feed from token until no more changes are
+// waiting, passing each record to seen if set, and returns the new token
+func drainDelta(ctx context.Context, gw *gateway, token string, seen func(dataRecord)) (string, error) {
+	for {
+		delta, err := gw.Delta(ctx, token, deltaPageSize)
+		if err != nil {
+			return token, err
+		}
+		if seen != nil {
+			for _, record := range delta.Data {
+				seen(record)
+			}
+		}
+		token = delta.NextToken
+		if !delta.HasMore {
+			return token, nil
+		}
+	}
+}
+
+// forEach calls fn for 0 to n-1 with at most concurrency calls at a time,
+// returning the first error once the calls made so far return
+func forEach(ctx context.Context, concurrency, n int, fn func(context.Context, int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr == nil && errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// latencies collects durations and summarizes them as percentiles
+type latencies []time.Duration
+
+// percentile returns the duration at or below which p percent of the
+// durations are, using the nearest rank. It is 0 without durations.
+func (l latencies) percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	sorted := append(latencies(nil), l...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// report is the outcome of a load test run
+type report struct {
+	URLs      int
+	Created   int
+	Scraped   int // Scrapes that reached a final status
+	Succeeded int
+	Parsed    int
+	TimedOut  bool
+	Elapsed   time.Duration // From the first scrape until the last result seen
+	Served    int64         // Requests answered by the mock target
+	Failed    int64         // Of which synthetic failures
+
+	Fetch    latencies // Fetch durations reported by the scrapers
+	Scrape   latencies // Task published to scrape recorded
+	EndToEnd latencies // Task published to parsed record created
+}
+
+// Write prints the report as a table
+func (r *report) Write(w io.Writer) {
+	fmt.Fprintf(w, "URLs:        %d created of %d\n", r.Created, r.URLs)
+	fmt.Fprintf(w, "Scrapes:     %d finished, %d succeeded\n", r.Scraped, r.Succeeded)
+	fmt.Fprintf(w, "Parsed:      %d\n", r.Parsed)
+	fmt.Fprintf(w, "Target:      %d requests, %d synthetic failures\n", r.Served, r.Failed)
+	fmt.Fprintf(w, "Elapsed:     %s", r.Elapsed.Round(time.Millisecond))
+	if r.TimedOut {
+		fmt.Fprint(w, " (timed out)")
+	}
+	fmt.Fprintln(w)
+	if seconds := r.Elapsed.Seconds(); seconds > 0 {
+		fmt.Fprintf(w, "Throughput:  %.2f scrapes/s, %.2f parses/s\n", float64(r.Scraped)/seconds, float64(r.Parsed)/seconds)
+	}
+
+	fmt.Fprintf(w, "\n%-12s %8s %10s %10s %10s %10s\n", "latency", "count", "p50", "p90", "p99", "max")
+	for _, row := range []struct {
+		name   string
+		values latencies
+	}{
+		{"fetch", r.Fetch},
+		{"scrape", r.Scrape},
+		{"end-to-end", r.EndToEnd},
+	} {
+		fmt.Fprintf(w, "%-12s %8d %10s %10s %10s %10s\n", row.name, len(row.values),
+			row.values.percentile(50).Round(time.Millisecond),
+			row.values.percentile(90).Round(time.Millisecond),
+			row.values.percentile(99).Round(time.Millisecond),
+			row.values.percentile(100).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var l latencies
+	if got := l.percentile(50); got != 0 {
+		t.Errorf("percentile of nothing = %s, want 0", got)
+	}
+	for i := 10; i >= 1; i-- {
+		l = append(l, time.Duration(i)*time.Millisecond)
+	}
+	tests := map[float64]time.Duration{
+		0:   time.Millisecond,
+		50:  5 * time.Millisecond,
+		90:  9 * time.Millisecond,
+		99:  10 * time.Millisecond,
+		100: 10 * time.Millisecond,
+	}
+	for p, want := range tests {
+		if got := l.percentile(p); got != want {
+			t.Errorf("percentile(%v) = %s, want %s", p, got, want)
+		}
+	}
+	if l[0] != 10*time.Millisecond {
+		t.Error("percentile sorted the latencies in place")
+	}
+}
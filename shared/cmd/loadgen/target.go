@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// target is the mock site the synthetic URLs point at. Page n is always the
+// same product page, so parses are comparable between runs.
+type target struct {
+	latency   time.Duration // Added to every response
+	errorRate float64       // Share of pages answering 503, chosen by page number
+	served    atomic.Int64
+	failed    atomic.Int64
+}
+
+// ServeHTTP serves /page/{n}
+func (t *target) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page/"))
+	if err != nil || n < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	time.Sleep(t.latency)
+	t.served.Add(1)
+
+	if t.fails(n) {
+		t.failed.Add(1)
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "synthetic failure", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, page(n))
+}
+
+// fails reports whether page n answers with an error. The choice is a hash
+// of n, so the same pages fail on every scrape.
+func (t *target) fails(n int) bool {
+	if t.errorRate <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d", n)
+	return float64(h.Sum32()%10000)/10000 < t.errorRate
+}
+
+// page returns product page n, with the fields of the product-page template
+// and a listing of related products to give it a realistic size
+func page(n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html>
+<head>
+  <title>Product %[1]d</title>
+  <meta name="description" content="Synthetic product %[1]d">
+  <meta property="og:image" content="/images/%[1]d.jpg">
+</head>
+<body>
+  <h1>Product %[1]d</h1>
+  <div class="product-description"><p>Load test product number %[1]d.</p></div>
+  <span itemprop="price" content="%[2]d.90">€%[2]d.90</span>
+  <span itemprop="priceCurrency" content="EUR"></span>
+  <span itemprop="sku">SKU-%06[1]d</span>
+  <link itemprop="availability" href="https://schema.org/InStock">
+  <ul class="related">
+`, n, 10+n%90)
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&b, "    <li class=\"item\"><a href=\"/page/%d\">Product %d</a></li>\n", n+i, n+i)
+	}
+	b.WriteString("  </ul>\n</body>\n</html>\n")
+	return b.String()
+}
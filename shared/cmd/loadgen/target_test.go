@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	site := &target{errorRate: 0.3}
+	var failing int
+	for n := 0; n < 1000; n++ {
+		if site.fails(n) {
+			failing++
+		}
+		if site.fails(n) != site.fails(n) {
+			t.Fatalf("page %d fails at random", n)
+		}
+	}
+	if failing < 250 || failing > 350 {
+		t.Errorf("%d of 1000 pages fail, want about 300", failing)
+	}
+
+	for path, want := range map[string]int{"/page/7": http.StatusOK, "/page/x": http.StatusNotFound, "/": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		(&target{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+	if body := page(7); !strings.Contains(body, "<h1>Product 7</h1>") || page(7) != body {
+		t.Error("page 7 is not the stable product page")
+	}
+}
@@ -0,0 +1,55 @@
+// Command mocksite serves the mock target site of package mocksite, so
+// integration tests and demos can scrape pages with fixed behaviors
+// (delays, 429s, redirects, errors, JavaScript-only content, robots.txt
+// variants) instead of real sites. See package mocksite for the routes.
+//
+// Usage:
+//
+//	go run ./cmd/mocksite -listen :9090 -robots partial
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"go_scraping_project/shared/mocksite"
+)
+
+func main() {
+	listen := flag.String("listen", ":9090", "Listen address")
+	robotsVariant := flag.String("robots", mocksite.RobotsAllowAll, "robots.txt variant: allow, disallow, partial, missing or error")
+	maxDelay := flag.Duration("max-delay", mocksite.DefaultMaxDelay, "Longest delay /delay/{duration} waits")
+	flag.Parse()
+
+	site, err := mocksite.New(mocksite.Options{Robots: *robotsVariant, MaxDelay: *maxDelay})
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := &http.Server{
+		Addr: *listen,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("%s %s", r.Method, r.URL.RequestURI())
+			site.ServeHTTP(w, r)
+		}),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Mock site listening on %s (robots.txt: %s)", *listen, *robotsVariant)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
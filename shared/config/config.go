@@ -1,124 +1,1524 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
 	"time"
+
+	"go_scraping_project/shared/models"
 )
 
-// Config represents the base configuration structure
+// Config represents the base configuration structure.
+// Field tags mirror the keys used in configs/shared.yaml and the service YAML files.
 type Config struct {
-	Environment string         `json:"environment"`
-	LogLevel    string         `json:"log_level"`
-	Database    DatabaseConfig `json:"database"`
-	Kafka       KafkaConfig    `json:"kafka"`
-	HTTP        HTTPConfig     `json:"http"`
-	Scraping    ScrapingConfig `json:"scraping"`
+	Environment string           `mapstructure:"environment" json:"environment"`
+	Logging     LoggingConfig    `mapstructure:"logging" json:"logging"`
+	Database    DatabaseConfig   `mapstructure:"database" json:"database"`
+	Kafka       KafkaConfig      `mapstructure:"kafka" json:"kafka"`
+	Server      ServerConfig     `mapstructure:"server" json:"server"`
+	Scraping    ScrapingConfig   `mapstructure:"scraping" json:"scraping"`
+	Control     ControlConfig    `mapstructure:"control" json:"control"`
+	Health      HealthConfig     `mapstructure:"health" json:"health"`
+	Startup     StartupConfig    `mapstructure:"startup" json:"startup"`
+	Cache       CacheConfig      `mapstructure:"cache" json:"cache"`
+	Tracing     TracingConfig    `mapstructure:"tracing" json:"tracing"`
+	MTLS        MTLSConfig       `mapstructure:"mtls" json:"mtls"`
+	Encryption  EncryptionConfig `mapstructure:"encryption" json:"encryption"`
+	Exports     ExportsConfig    `mapstructure:"exports" json:"exports"`
+
+	// Settings below can be changed at runtime, see Watcher
+	RateLimit RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler" json:"scheduler"`
+	Workers   WorkersConfig   `mapstructure:"workers" json:"workers"`
+	Secrets   SecretsConfig   `mapstructure:"secrets" json:"secrets"`
+	Features  FeaturesConfig  `mapstructure:"features" json:"features"`
+	Watchdog  WatchdogConfig  `mapstructure:"watchdog" json:"watchdog"`
+	Sync      SyncConfig      `mapstructure:"sync" json:"sync"`
+	Alerts    AlertsConfig    `mapstructure:"alerts" json:"alerts"`
+	SLOs      SLOs            `mapstructure:"slos" json:"slos,omitempty"`
+	Redirects RedirectsConfig `mapstructure:"redirects" json:"redirects"`
+
+	FrequencyPolicy FrequencyPolicyConfig `mapstructure:"frequency_policy" json:"frequency_policy"`
+	PII             PIIConfig             `mapstructure:"pii" json:"pii"`
+
+	Notifications NotificationsConfig `mapstructure:"notifications" json:"notifications"`
+	Maintenance   MaintenanceConfig   `mapstructure:"maintenance" json:"maintenance"`
+}
+
+// LoggingConfig represents logging configuration. Output is stdout (the
+// default), stderr or file, which writes to File.Path with rotation for
+// deployments without a log collector. The level and request log sampling
+// change at runtime.
+type LoggingConfig struct {
+	Level    string           `mapstructure:"level" json:"level"`
+	Format   string           `mapstructure:"format" json:"format"`
+	Output   string           `mapstructure:"output" json:"output"`
+	File     LogFileConfig    `mapstructure:"file" json:"file"`
+	Requests RequestLogConfig `mapstructure:"requests" json:"requests"`
+}
+
+// Log outputs of LoggingConfig
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
+
+// Validate checks the output, for file output the rotation settings, and
+// the request log sampling
+func (c LoggingConfig) Validate() error {
+	if err := c.Requests.Validate(); err != nil {
+		return err
+	}
+	switch c.Output {
+	case "", LogOutputStdout, LogOutputStderr:
+		return nil
+	case LogOutputFile:
+		return c.File.Validate()
+	}
+	return fmt.Errorf("output must be %s, %s or %s, got %q", LogOutputStdout, LogOutputStderr, LogOutputFile, c.Output)
+}
+
+// RequestLogConfig represents the sampling of HTTP request logs, so that
+// health checks and metrics polls do not dominate the log volume. Requests
+// answered with an error status (400 and above) or lasting at least
+// SlowThreshold are always logged; others are logged at the sample rate of
+// the longest matching path prefix, or SampleRate.
+type RequestLogConfig struct {
+	SampleRate    float64          `mapstructure:"sample_rate" json:"sample_rate"`       // Share of successful requests logged, from 0 to 1
+	SlowThreshold time.Duration    `mapstructure:"slow_threshold" json:"slow_threshold"` // 0 to sample slow requests too
+	Paths         []PathSampleRate `mapstructure:"paths" json:"paths,omitempty"`
+}
+
+// PathSampleRate is the sample rate of successful requests whose path starts
+// with Prefix
+type PathSampleRate struct {
+	Prefix     string  `mapstructure:"prefix" json:"prefix"`
+	SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate"`
+}
+
+// Validate checks the sample rates and the slow threshold
+func (c RequestLogConfig) Validate() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("requests.sample_rate must be between 0 and 1, got %v", c.SampleRate)
+	}
+	if c.SlowThreshold < 0 {
+		return fmt.Errorf("requests.slow_threshold must not be negative")
+	}
+	for _, p := range c.Paths {
+		if !strings.HasPrefix(p.Prefix, "/") {
+			return fmt.Errorf("requests.paths prefix must start with /, got %q", p.Prefix)
+		}
+		if p.SampleRate < 0 || p.SampleRate > 1 {
+			return fmt.Errorf("requests.paths sample rate of %s must be between 0 and 1, got %v", p.Prefix, p.SampleRate)
+		}
+	}
+	return nil
+}
+
+// SampleRateOf returns the sample rate of successful requests to a path
+func (c RequestLogConfig) SampleRateOf(path string) float64 {
+	rate, longest := c.SampleRate, -1
+	for _, p := range c.Paths {
+		if strings.HasPrefix(path, p.Prefix) && len(p.Prefix) > longest {
+			rate, longest = p.SampleRate, len(p.Prefix)
+		}
+	}
+	return rate
+}
+
+// LogFileConfig represents a log file and its rotation. The file is rotated
+// when it would grow beyond MaxSizeMB and, with RotateEvery, at every
+// multiple of RotateEvery since the Unix epoch in UTC (every midnight UTC
+// for 24h). Rotated files are renamed with their rotation time, e.g.
+// url-manager-2024-03-01T00-00-00.000.log, and optionally gzipped.
+type LogFileConfig struct {
+	Path        string        `mapstructure:"path" json:"path"`                 // Defaults to logs/<service>.log
+	MaxSizeMB   int           `mapstructure:"max_size_mb" json:"max_size_mb"`   // Size that triggers a rotation
+	MaxBackups  int           `mapstructure:"max_backups" json:"max_backups"`   // Rotated files kept, 0 for all
+	MaxAge      time.Duration `mapstructure:"max_age" json:"max_age"`           // Age after which rotated files are deleted, 0 to keep them
+	RotateEvery time.Duration `mapstructure:"rotate_every" json:"rotate_every"` // Time-based rotation, 0 for size only
+	Compress    bool          `mapstructure:"compress" json:"compress"`         // Gzip rotated files
+	Stdout      bool          `mapstructure:"stdout" json:"stdout"`             // Also write every entry to stdout
+}
+
+// Validate checks the rotation settings
+func (c LogFileConfig) Validate() error {
+	switch {
+	case c.MaxSizeMB <= 0:
+		return fmt.Errorf("file.max_size_mb must be positive")
+	case c.MaxBackups < 0:
+		return fmt.Errorf("file.max_backups must not be negative")
+	case c.MaxAge < 0:
+		return fmt.Errorf("file.max_age must not be negative")
+	case c.RotateEvery < 0:
+		return fmt.Errorf("file.rotate_every must not be negative")
+	case c.RotateEvery > 0 && c.RotateEvery < time.Minute:
+		return fmt.Errorf("file.rotate_every must be at least a minute")
+	}
+	return nil
 }
 
 // DatabaseConfig represents database configuration
 type DatabaseConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	DBName   string `json:"db_name"`
-	SSLMode  string `json:"ssl_mode"`
-	MaxConns int    `json:"max_conns"`
+	Host         string `mapstructure:"host" json:"host"`
+	Port         int    `mapstructure:"port" json:"port"`
+	User         string `mapstructure:"user" json:"user"`
+	Password     string `mapstructure:"password" json:"-"`
+	DBName       string `mapstructure:"database" json:"database"`
+	SSLMode      string `mapstructure:"ssl_mode" json:"ssl_mode"`
+	MaxOpenConns int    `mapstructure:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns int    `mapstructure:"max_idle_conns" json:"max_idle_conns"`
+
+	// StatementTimeout is the statement_timeout of every connection: the
+	// server cancels statements running longer, whoever runs them. 0 for none.
+	StatementTimeout time.Duration `mapstructure:"statement_timeout" json:"statement_timeout"`
+	// QueryTimeout bounds each repository query on the client side, so a
+	// query waiting for a pool connection gives up too. 0 for none.
+	QueryTimeout time.Duration `mapstructure:"query_timeout" json:"query_timeout"`
+	// QueryTimeouts overrides QueryTimeout per query, by sqlc query name
+	// (case-insensitive, such as CountURLsPerDomain)
+	QueryTimeouts map[string]time.Duration `mapstructure:"query_timeouts" json:"query_timeouts"`
+}
+
+// Validate checks the statement and query timeouts
+func (c DatabaseConfig) Validate() error {
+	switch {
+	case c.StatementTimeout < 0:
+		return fmt.Errorf("statement_timeout must not be negative")
+	case c.QueryTimeout < 0:
+		return fmt.Errorf("query_timeout must not be negative")
+	}
+	for name, timeout := range c.QueryTimeouts {
+		if timeout <= 0 {
+			return fmt.Errorf("query_timeouts.%s must be positive", name)
+		}
+	}
+	return nil
+}
+
+// URL returns the database connection URL.
+// DATABASE_URL takes precedence over the individual settings when set.
+func (c DatabaseConfig) URL() string {
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		return databaseURL
+	}
+
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		c.User, c.Password, c.Host, c.Port, c.DBName, c.SSLMode)
 }
 
 // KafkaConfig represents Kafka configuration
 type KafkaConfig struct {
-	Brokers        []string       `json:"brokers"`
-	Topics         TopicsConfig   `json:"topics"`
-	ConsumerGroup  string         `json:"consumer_group"`
-	ProducerConfig ProducerConfig `json:"producer_config"`
-	ConsumerConfig ConsumerConfig `json:"consumer_config"`
+	Brokers           []string       `mapstructure:"brokers" json:"brokers"`
+	GroupID           string         `mapstructure:"group_id" json:"group_id"`
+	Topics            TopicsConfig   `mapstructure:"topics" json:"topics"`
+	AutoOffsetReset   string         `mapstructure:"auto_offset_reset" json:"auto_offset_reset"`
+	SessionTimeout    time.Duration  `mapstructure:"session_timeout" json:"session_timeout"`
+	HeartbeatInterval time.Duration  `mapstructure:"heartbeat_interval" json:"heartbeat_interval"`
+	RetryBackoff      time.Duration  `mapstructure:"retry_backoff" json:"retry_backoff"`
+	RetryMaxAttempts  int            `mapstructure:"retry_max_attempts" json:"retry_max_attempts"`
+	Producer          ProducerConfig `mapstructure:"producer" json:"producer"`
+}
+
+// Producer acknowledgement levels
+const (
+	AcksAll    = "all"    // Every in-sync replica has the message
+	AcksLeader = "leader" // The partition leader has the message
+	AcksNone   = "none"   // Messages are not acknowledged
+)
+
+// Default producer settings, used for zero values
+const (
+	DefaultProducerBatchSize = 100
+	DefaultProducerLinger    = 10 * time.Millisecond
+)
+
+// ProducerConfig tunes the shared Kafka producer. Messages are compressed
+// with Compression (none, gzip, snappy, lz4 or zstd) and sent in batches of
+// up to BatchSize messages, waiting at most Linger for a batch to fill.
+// Acks is all, leader or none (or -1, 1 and 0). Zero values use the
+// defaults: no compression, batches of 100, 10ms linger and acks from all
+// in-sync replicas.
+type ProducerConfig struct {
+	Compression string        `mapstructure:"compression" json:"compression"`
+	BatchSize   int           `mapstructure:"batch_size" json:"batch_size"`
+	Linger      time.Duration `mapstructure:"linger" json:"linger"`
+	Acks        string        `mapstructure:"acks" json:"acks"`
+}
+
+// Validate checks the compression codec, acknowledgement level and batching
+func (c ProducerConfig) Validate() error {
+	switch strings.ToLower(c.Compression) {
+	case "", "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		return fmt.Errorf("compression must be none, gzip, snappy, lz4 or zstd, got %q", c.Compression)
+	}
+	if _, err := c.RequiredAcks(); err != nil {
+		return err
+	}
+	switch {
+	case c.BatchSize < 0:
+		return fmt.Errorf("batch_size must not be negative")
+	case c.Linger < 0:
+		return fmt.Errorf("linger must not be negative")
+	}
+	return nil
+}
+
+// RequiredAcks returns the Kafka acks setting: -1 for all, 1 for the
+// leader and 0 for none
+func (c ProducerConfig) RequiredAcks() (int, error) {
+	switch strings.ToLower(c.Acks) {
+	case "", AcksAll, "-1":
+		return -1, nil
+	case AcksLeader, "1":
+		return 1, nil
+	case AcksNone, "0":
+		return 0, nil
+	}
+	return 0, fmt.Errorf("acks must be all, leader or none, got %q", c.Acks)
 }
 
 // TopicsConfig represents Kafka topics configuration
 type TopicsConfig struct {
-	ScrapingTasks string `json:"scraping_tasks"`
-	ScrapedData   string `json:"scraped_data"`
-	ParsedData    string `json:"parsed_data"`
-	DeadLetter    string `json:"dead_letter"`
+	ScrapingTasks   string `mapstructure:"scraping_tasks" json:"scraping_tasks"`
+	ScrapingResults string `mapstructure:"scraping_results" json:"scraping_results"`
+	ScrapedData     string `mapstructure:"scraped_data" json:"scraped_data"`
+	ParsedData      string `mapstructure:"parsed_data" json:"parsed_data"`
+	DeadLetter      string `mapstructure:"dead_letter" json:"dead_letter"`
+	URLEvents       string `mapstructure:"url_events" json:"url_events"`
+	ConsumerControl string `mapstructure:"consumer_control" json:"consumer_control"` // Pause and resume commands for consumers, see kafka.ConsumerCommand
 }
 
-// ProducerConfig represents Kafka producer configuration
-type ProducerConfig struct {
-	RequiredAcks int           `json:"required_acks"`
-	Timeout      time.Duration `json:"timeout"`
-	Retries      int           `json:"retries"`
+// ControlConfig represents the internal control API the API Gateway uses to
+// send commands to the URL Manager, such as immediate scrapes
+type ControlConfig struct {
+	URLManagerURL string        `mapstructure:"url_manager_url" json:"url_manager_url"`
+	Timeout       time.Duration `mapstructure:"timeout" json:"timeout"`
 }
 
-// ConsumerConfig represents Kafka consumer configuration
-type ConsumerConfig struct {
-	AutoOffsetReset   string        `json:"auto_offset_reset"`
-	SessionTimeout    time.Duration `json:"session_timeout"`
-	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+// HealthConfig represents the system health check of the API Gateway. Each
+// check, such as a call to a service's health endpoint, is bounded by Timeout.
+type HealthConfig struct {
+	Timeout  time.Duration     `mapstructure:"timeout" json:"timeout"`
+	Services map[string]string `mapstructure:"services" json:"services"` // Health endpoint URL by service name
 }
 
-// HTTPConfig represents HTTP server configuration
-type HTTPConfig struct {
-	Port         int           `json:"port"`
-	ReadTimeout  time.Duration `json:"read_timeout"`
-	WriteTimeout time.Duration `json:"write_timeout"`
-	IdleTimeout  time.Duration `json:"idle_timeout"`
+// StartupConfig represents how long services wait for Postgres and Kafka
+// to become reachable when they start. Probes are retried with exponential
+// backoff, starting at InitialBackoff and doubling up to MaxBackoff, until
+// MaxWait has passed; a MaxWait of zero probes only once.
+type StartupConfig struct {
+	MaxWait        time.Duration `mapstructure:"max_wait" json:"max_wait"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" json:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" json:"max_backoff"`
+}
+
+// CacheConfig represents the optional Redis cache of the API Gateway's hot
+// read endpoints. Entries live for a short TTL and are dropped when the
+// gateway changes what they hold; with caching disabled every read goes to
+// the database.
+type CacheConfig struct {
+	Enabled    bool          `mapstructure:"enabled" json:"enabled"`
+	Address    string        `mapstructure:"address" json:"address"` // Redis host:port
+	Password   string        `mapstructure:"password" json:"-"`
+	DB         int           `mapstructure:"db" json:"db"`
+	KeyPrefix  string        `mapstructure:"key_prefix" json:"key_prefix"`
+	Timeout    time.Duration `mapstructure:"timeout" json:"timeout"`         // Per operation; slow reads fall back to the database
+	URLTTL     time.Duration `mapstructure:"url_ttl" json:"url_ttl"`         // Single URLs
+	ListTTL    time.Duration `mapstructure:"list_ttl" json:"list_ttl"`       // URL listings and statistics
+	MetricsTTL time.Duration `mapstructure:"metrics_ttl" json:"metrics_ttl"` // Metrics endpoints
+}
+
+// Validate checks the Redis address of an enabled cache and the timeout and
+// TTLs
+func (c CacheConfig) Validate() error {
+	switch {
+	case !c.Enabled:
+		return nil
+	case c.Address == "":
+		return fmt.Errorf("address is required when the cache is enabled")
+	case c.DB < 0:
+		return fmt.Errorf("db must not be negative")
+	case c.Timeout <= 0:
+		return fmt.Errorf("timeout must be positive")
+	case c.URLTTL <= 0 || c.ListTTL <= 0 || c.MetricsTTL <= 0:
+		return fmt.Errorf("url_ttl, list_ttl and metrics_ttl must be positive")
+	}
+	return nil
+}
+
+// ServerConfig represents HTTP server configuration
+type ServerConfig struct {
+	Port         int             `mapstructure:"port" json:"port"`
+	ReadTimeout  time.Duration   `mapstructure:"read_timeout" json:"read_timeout"`
+	WriteTimeout time.Duration   `mapstructure:"write_timeout" json:"write_timeout"`
+	IdleTimeout  time.Duration   `mapstructure:"idle_timeout" json:"idle_timeout"`
+	CORS         CORSConfig      `mapstructure:"cors" json:"cors"` // API Gateway only; changes at runtime
+	TLS          ServerTLSConfig `mapstructure:"tls" json:"tls"`
+}
+
+// ServerTLSConfig represents TLS termination in the HTTP server, so a
+// service can be exposed without a proxy. The certificate and key files are
+// reloaded when they change, so certificates renewed in place, e.g. by
+// certbot for Let's Encrypt, are used without a restart. With RedirectPort,
+// plain HTTP requests on that port are redirected to HTTPS, and responses
+// over TLS carry a Strict-Transport-Security header for HSTSMaxAge.
+type ServerTLSConfig struct {
+	Enabled               bool          `mapstructure:"enabled" json:"enabled"`
+	CertFile              string        `mapstructure:"cert_file" json:"cert_file"`         // PEM certificate chain
+	KeyFile               string        `mapstructure:"key_file" json:"key_file"`           // PEM private key
+	MinVersion            string        `mapstructure:"min_version" json:"min_version"`     // 1.2 or 1.3
+	RedirectPort          int           `mapstructure:"redirect_port" json:"redirect_port"` // Port redirecting HTTP to HTTPS, 0 for none
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age" json:"hsts_max_age"`   // 0 to send no HSTS header
+	HSTSIncludeSubdomains bool          `mapstructure:"hsts_include_subdomains" json:"hsts_include_subdomains"`
+}
+
+// MTLSConfig represents mutual TLS between the services, for zero-trust
+// deployments. Each service presents the certificate of CertFile and
+// verifies its peers against CAFile: on calls to other services (such as
+// the API Gateway's calls to the URL Manager), on Kafka connections with
+// Kafka, and on its own HTTP port with Server, which then requires client
+// certificates. Rotated certificate and key files are reloaded without a
+// restart; a new CA takes a restart.
+type MTLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled" json:"enabled"`
+	CAFile   string `mapstructure:"ca_file" json:"ca_file"`     // PEM CA certificates verifying peers
+	CertFile string `mapstructure:"cert_file" json:"cert_file"` // PEM certificate chain of this service
+	KeyFile  string `mapstructure:"key_file" json:"key_file"`   // PEM private key of this service
+	Server   bool   `mapstructure:"server" json:"server"`       // Serve server.port with TLS, requiring client certificates
+	Kafka    bool   `mapstructure:"kafka" json:"kafka"`         // Connect to the Kafka brokers with TLS
+}
+
+// Validate checks that enabled mutual TLS has its certificate files
+func (c MTLSConfig) Validate() error {
+	if c.Enabled && (c.CAFile == "" || c.CertFile == "" || c.KeyFile == "") {
+		return fmt.Errorf("ca_file, cert_file and key_file are required when mTLS is enabled")
+	}
+	return nil
+}
+
+// EncryptionConfig represents the keys encrypting sensitive fields at rest:
+// notification channel credentials, cookie jars and parsed fields marked
+// sensitive. Keys maps key IDs to base64-encoded 32-byte AES keys, usually
+// secret:// references. Each record is encrypted with a key of its own,
+// which is stored with it wrapped by PrimaryKey; the other keys are kept to
+// decrypt records wrapped before a rotation. Without keys nothing is
+// encrypted.
+type EncryptionConfig struct {
+	PrimaryKey string            `mapstructure:"primary_key" json:"primary_key"`
+	Keys       map[string]string `mapstructure:"keys" json:"-"`
+}
+
+// Validate checks that configured keys have a primary key among them. The
+// keys themselves are checked once secret references are resolved.
+func (c EncryptionConfig) Validate() error {
+	if len(c.Keys) == 0 {
+		if c.PrimaryKey != "" {
+			return fmt.Errorf("primary_key %q is not one of the keys", c.PrimaryKey)
+		}
+		return nil
+	}
+	if c.PrimaryKey == "" {
+		return fmt.Errorf("primary_key is required when keys are configured")
+	}
+	if _, ok := c.Keys[c.PrimaryKey]; !ok {
+		return fmt.Errorf("primary_key %q is not one of the keys", c.PrimaryKey)
+	}
+	return nil
+}
+
+// ExportsConfig represents data exports, which the URL Manager writes as
+// files to Storage, an S3-compatible bucket, and the API Gateway hands out
+// as presigned download links of the bucket. Without a bucket, files can be
+// written to Directory instead, storage shared by both services such as a
+// network volume; the API Gateway then serves them itself through links
+// signed with SigningKey, usually a secret:// reference, and BaseURL is the
+// public origin of those links, relative links when empty. Links are valid
+// for LinkTTL; files are deleted Retention after they are written. Without
+// a bucket or a directory, exports are disabled.
+type ExportsConfig struct {
+	Storage    ExportStorageConfig    `mapstructure:"storage" json:"storage"`
+	Directory  string                 `mapstructure:"directory" json:"directory"`
+	SigningKey string                 `mapstructure:"signing_key" json:"-"`
+	BaseURL    string                 `mapstructure:"base_url" json:"base_url"`
+	LinkTTL    time.Duration          `mapstructure:"link_ttl" json:"link_ttl"`
+	Retention  time.Duration          `mapstructure:"retention" json:"retention"`
+	Schedules  []ExportScheduleConfig `mapstructure:"schedules" json:"schedules,omitempty"`
+}
+
+// ExportStorageConfig represents the S3-compatible bucket of export files:
+// AWS S3, Google Cloud Storage through its XML API with HMAC keys, or
+// MinIO. Endpoint defaults to https://s3.<region>.amazonaws.com; PathStyle
+// addresses the bucket in the path instead of the host name, as MinIO
+// needs. The credentials are usually secret:// references and default to
+// the standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN variables.
+type ExportStorageConfig struct {
+	Bucket          string `mapstructure:"bucket" json:"bucket"`
+	Endpoint        string `mapstructure:"endpoint" json:"endpoint,omitempty"`
+	Region          string `mapstructure:"region" json:"region"`
+	PathStyle       bool   `mapstructure:"path_style" json:"path_style"`
+	AccessKeyID     string `mapstructure:"access_key_id" json:"-"`
+	SecretAccessKey string `mapstructure:"secret_access_key" json:"-"`
+}
+
+// Enabled reports whether export files are written to a bucket
+func (c ExportStorageConfig) Enabled() bool {
+	return c.Bucket != ""
+}
+
+// Validate checks the region and endpoint of a bucket
+func (c ExportStorageConfig) Validate() error {
+	if c.Region == "" {
+		return fmt.Errorf("region is required with a bucket")
+	}
+	if c.Endpoint != "" {
+		if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint %q must be an http or https URL", c.Endpoint)
+		}
+	}
+	return nil
+}
+
+// Export setting limits enforced by ExportsConfig.Validate
+const (
+	MaxExportLinkTTL       = 7 * 24 * time.Hour
+	MaxExportRetention     = 90 * 24 * time.Hour
+	MinExportScheduleEvery = 5 * time.Minute
+)
+
+// Export file formats
+const (
+	ExportFormatJSONL = "jsonl" // One JSON record per line
+	ExportFormatCSV   = "csv"
+)
+
+// Enabled reports whether exports are configured
+func (c ExportsConfig) Enabled() bool {
+	return c.Storage.Enabled() || c.Directory != ""
+}
+
+// Validate checks the bucket, or that a directory comes with a signing key,
+// and checks the link lifetime, the retention and the schedules
+func (c ExportsConfig) Validate() error {
+	if !c.Enabled() {
+		if len(c.Schedules) > 0 {
+			return fmt.Errorf("schedules require a storage bucket or a directory")
+		}
+		return nil
+	}
+	if c.Storage.Enabled() {
+		if c.Directory != "" {
+			return fmt.Errorf("set either storage.bucket or directory, not both")
+		}
+		if err := c.Storage.Validate(); err != nil {
+			return fmt.Errorf("storage: %w", err)
+		}
+	} else if c.SigningKey == "" {
+		return fmt.Errorf("signing_key is required when a directory is configured")
+	}
+	if c.BaseURL != "" {
+		if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("base_url %q must be an http or https URL", c.BaseURL)
+		}
+	}
+	if c.LinkTTL <= 0 || c.LinkTTL > MaxExportLinkTTL {
+		return fmt.Errorf("link_ttl must be between 1s and %s", MaxExportLinkTTL)
+	}
+	if c.Retention < c.LinkTTL || c.Retention > MaxExportRetention {
+		return fmt.Errorf("retention must be between link_ttl (%s) and %s", c.LinkTTL, MaxExportRetention)
+	}
+	names := make(map[string]bool, len(c.Schedules))
+	for i, schedule := range c.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("schedules[%d]: %w", i, err)
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("schedules[%d]: duplicate name %q", i, schedule.Name)
+		}
+		names[schedule.Name] = true
+	}
+	return nil
+}
+
+// ExportScheduleConfig represents an export run every Every for a project.
+// Each run exports the records of the project (optionally of one schema)
+// created since the previous run, so together the files hold every record
+// once.
+type ExportScheduleConfig struct {
+	Name    string        `mapstructure:"name" json:"name"`
+	Project string        `mapstructure:"project" json:"project"`
+	Schema  string        `mapstructure:"schema" json:"schema,omitempty"`
+	Format  string        `mapstructure:"format" json:"format"`
+	Every   time.Duration `mapstructure:"every" json:"every"`
+}
+
+// Validate checks the name, project, format and interval
+func (c ExportScheduleConfig) Validate() error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("name is required")
+	case c.Project == "":
+		return fmt.Errorf("project is required")
+	case c.Format != ExportFormatJSONL && c.Format != ExportFormatCSV:
+		return fmt.Errorf("unsupported format %q, use jsonl or csv", c.Format)
+	case c.Every < MinExportScheduleEvery:
+		return fmt.Errorf("every must be at least %s", MinExportScheduleEvery)
+	}
+	return nil
+}
+
+// TLS versions of ServerTLSConfig.MinVersion
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// Validate checks that enabled TLS has a certificate and key, a known
+// minimum version and a valid redirect port
+func (c ServerTLSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch {
+	case c.CertFile == "" || c.KeyFile == "":
+		return fmt.Errorf("cert_file and key_file are required when TLS is enabled")
+	case c.MinVersion != "" && c.MinVersion != TLSVersion12 && c.MinVersion != TLSVersion13:
+		return fmt.Errorf("min_version must be %s or %s, got %q", TLSVersion12, TLSVersion13, c.MinVersion)
+	case c.RedirectPort < 0 || c.RedirectPort > 65535:
+		return fmt.Errorf("redirect_port must be a port number, got %d", c.RedirectPort)
+	case c.HSTSMaxAge < 0:
+		return fmt.Errorf("hsts_max_age must not be negative")
+	}
+	return nil
 }
 
 // ScrapingConfig represents scraping configuration
 type ScrapingConfig struct {
-	DefaultTimeout    time.Duration `json:"default_timeout"`
-	DefaultUserAgent  string        `json:"default_user_agent"`
-	DefaultMaxRetries int           `json:"default_max_retries"`
-	DefaultRateLimit  int           `json:"default_rate_limit"`
-	Concurrency       int           `json:"concurrency"`
+	DefaultTimeout    time.Duration `mapstructure:"default_timeout" json:"default_timeout"`
+	DefaultUserAgent  string        `mapstructure:"default_user_agent" json:"default_user_agent"`
+	DefaultMaxRetries int           `mapstructure:"max_retries" json:"max_retries"`
+	DefaultRateLimit  int           `mapstructure:"default_rate_limit" json:"default_rate_limit"`
+	DomainRateLimit   int           `mapstructure:"domain_rate_limit" json:"domain_rate_limit"` // Requests per minute per host across its URLs, 0 for no limit
+	Concurrency       int           `mapstructure:"max_concurrent_tasks" json:"max_concurrent_tasks"`
+	RespectRobotsTxt  bool          `mapstructure:"respect_robots_txt" json:"respect_robots_txt"`
+	CaptureHeaders    []string      `mapstructure:"capture_headers" json:"capture_headers"` // Response headers kept with each scrape
+	Cookies           CookiesConfig `mapstructure:"cookies" json:"cookies"`
+	TLS               TLSConfig     `mapstructure:"tls" json:"tls"`
+	HAR               HARConfig     `mapstructure:"har" json:"har"`
+	DNS               DNSConfig     `mapstructure:"dns" json:"dns"`
+	Network           NetworkConfig `mapstructure:"network" json:"network"`
+	ProjectShares     ProjectShares `mapstructure:"project_shares" json:"project_shares,omitempty"`
+}
+
+// ProjectShares are the relative shares of projects in scraping capacity,
+// by project name. The scheduler interleaves the due URLs of projects in
+// proportion to their shares, so one project's backlog cannot fill a
+// scheduling batch, and a worker pool gives a project with a share of 2
+// twice the workers of a project with 1 while both have tasks waiting.
+// Projects not listed, and URLs without a project, have a share of 1.
+type ProjectShares map[string]int
+
+// MaxProjectShare is the largest share ProjectShares.Validate accepts
+const MaxProjectShare = 1000
+
+// Validate checks that every share is between 1 and MaxProjectShare
+func (s ProjectShares) Validate() error {
+	for project, share := range s {
+		if share < 1 || share > MaxProjectShare {
+			return fmt.Errorf("share of project %q must be between 1 and %d", project, MaxProjectShare)
+		}
+	}
+	return nil
+}
+
+// Share returns the share of a project
+func (s ProjectShares) Share(project string) int {
+	if share, ok := s[project]; ok {
+		return share
+	}
+	return 1
+}
+
+// NetworkConfig represents how scrapers connect to sites: the IP family
+// they use and the local addresses they bind to, for targets that treat
+// IPv6 ranges differently or scraper hosts with several addresses. Domains
+// overrides the settings for a domain and its subdomains, the most specific
+// domain winning; settings an entry leaves empty are inherited.
+type NetworkConfig struct {
+	IPFamily       string                `mapstructure:"ip_family" json:"ip_family,omitempty"`             // One of the IPFamily values, empty for any
+	LocalAddresses []string              `mapstructure:"local_addresses" json:"local_addresses,omitempty"` // Source IPs, used in turn; empty lets the system choose
+	Domains        []NetworkDomainConfig `mapstructure:"domains" json:"domains,omitempty"`
+}
+
+// IP families accepted by NetworkConfig
+const (
+	IPFamilyAny        = ""            // Addresses in the resolver's order
+	IPFamilyIPv4       = "ipv4"        // IPv4 addresses only
+	IPFamilyIPv6       = "ipv6"        // IPv6 addresses only
+	IPFamilyPreferIPv4 = "prefer_ipv4" // IPv4 addresses first, IPv6 if they fail
+	IPFamilyPreferIPv6 = "prefer_ipv6" // IPv6 addresses first, IPv4 if they fail
+)
+
+// NetworkDomainConfig overrides the network settings of a domain and its
+// subdomains
+type NetworkDomainConfig struct {
+	Domain         string   `mapstructure:"domain" json:"domain"`
+	IPFamily       string   `mapstructure:"ip_family" json:"ip_family,omitempty"`
+	LocalAddresses []string `mapstructure:"local_addresses" json:"local_addresses,omitempty"`
+}
+
+// Validate checks the IP families, local addresses and domain entries
+func (c NetworkConfig) Validate() error {
+	if err := validateNetworkSettings(c.IPFamily, c.LocalAddresses); err != nil {
+		return err
+	}
+	for i, d := range c.Domains {
+		switch domain := strings.TrimSpace(d.Domain); {
+		case domain == "":
+			return fmt.Errorf("domains[%d]: domain is required", i)
+		case strings.ContainsAny(domain, "/:?# "):
+			return fmt.Errorf("domains[%d]: domain must be a host name, got %q", i, d.Domain)
+		}
+		if err := validateNetworkSettings(d.IPFamily, d.LocalAddresses); err != nil {
+			return fmt.Errorf("domains[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ForHost returns the IP family and local addresses used to connect to a host
+func (c NetworkConfig) ForHost(host string) (family string, localAddresses []string) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	family, localAddresses = c.IPFamily, c.LocalAddresses
+	matched := ""
+	for _, d := range c.Domains {
+		domain := strings.Trim(strings.ToLower(strings.TrimSpace(d.Domain)), ".")
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(matched) {
+			matched = domain
+			family, localAddresses = c.IPFamily, c.LocalAddresses
+			if d.IPFamily != "" {
+				family = d.IPFamily
+			}
+			if len(d.LocalAddresses) > 0 {
+				localAddresses = d.LocalAddresses
+			}
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(family)), localAddresses
+}
+
+// validateNetworkSettings checks an IP family and local addresses. An IPv4
+// or IPv6 only family needs a local address of that family, if any is set.
+func validateNetworkSettings(family string, localAddresses []string) error {
+	family = strings.ToLower(strings.TrimSpace(family))
+	switch family {
+	case IPFamilyAny, IPFamilyIPv4, IPFamilyIPv6, IPFamilyPreferIPv4, IPFamilyPreferIPv6:
+	default:
+		return fmt.Errorf("ip_family must be one of ipv4, ipv6, prefer_ipv4 or prefer_ipv6, got %q", family)
+	}
+	hasV4, hasV6 := false, false
+	for _, addr := range localAddresses {
+		ip := net.ParseIP(strings.TrimSpace(addr))
+		if ip == nil {
+			return fmt.Errorf("local address %q is not an IP address", addr)
+		}
+		if ip.To4() != nil {
+			hasV4 = true
+		} else {
+			hasV6 = true
+		}
+	}
+	switch {
+	case family == IPFamilyIPv4 && hasV6 && !hasV4:
+		return fmt.Errorf("ip_family ipv4 needs an IPv4 local address")
+	case family == IPFamilyIPv6 && hasV4 && !hasV6:
+		return fmt.Errorf("ip_family ipv6 needs an IPv6 local address")
+	}
+	return nil
+}
+
+// DNSConfig represents how scrapers resolve host names. Servers lists the
+// resolvers asked in order, each a host[:port] spoken to over UDP (falling
+// back to TCP for truncated answers) or an https:// URL for DNS over HTTPS;
+// when empty, the system resolver is used. Answers are cached for their TTL
+// clamped to [MinTTL, MaxTTL], or for DefaultTTL when the system resolver
+// does not report one.
+type DNSConfig struct {
+	Servers    []string      `mapstructure:"servers" json:"servers,omitempty"`
+	Timeout    time.Duration `mapstructure:"timeout" json:"timeout"` // Per query and server
+	MinTTL     time.Duration `mapstructure:"min_ttl" json:"min_ttl"`
+	MaxTTL     time.Duration `mapstructure:"max_ttl" json:"max_ttl"`
+	DefaultTTL time.Duration `mapstructure:"default_ttl" json:"default_ttl"`
+}
+
+// Validate checks the resolver addresses and TTL bounds
+func (c DNSConfig) Validate() error {
+	for i, server := range c.Servers {
+		server = strings.TrimSpace(server)
+		if strings.Contains(server, "://") {
+			if u, err := url.Parse(server); err != nil || u.Scheme != "https" || u.Host == "" {
+				return fmt.Errorf("servers[%d]: DNS over HTTPS server must be an https URL, got %q", i, server)
+			}
+			continue
+		}
+		if server == "" || strings.ContainsAny(server, "/?# ") {
+			return fmt.Errorf("servers[%d]: server must be host[:port] or an https URL, got %q", i, server)
+		}
+	}
+	switch {
+	case c.Timeout < 0 || c.MinTTL < 0 || c.MaxTTL < 0 || c.DefaultTTL < 0:
+		return fmt.Errorf("timeout and TTLs must not be negative")
+	case c.MaxTTL > 0 && c.MinTTL > c.MaxTTL:
+		return fmt.Errorf("min_ttl %s exceeds max_ttl %s", c.MinTTL, c.MaxTTL)
+	}
+	return nil
+}
+
+// HARConfig represents debug captures of scrapes as HAR documents, requested
+// per URL through the API. A request waits at most RequestTTL for the URL's
+// next scrape (the API may ask for less), and the captured HAR is kept for
+// Retention. Scrapers keep the first BodyPreview bytes of each response body.
+type HARConfig struct {
+	RequestTTL  time.Duration `mapstructure:"request_ttl" json:"request_ttl"`
+	Retention   time.Duration `mapstructure:"retention" json:"retention"`
+	BodyPreview int           `mapstructure:"body_preview" json:"body_preview"`
+}
+
+// TLSConfig represents the TLS client profiles scrapers present in their
+// handshakes, for sites that block Go's default TLS fingerprint. Domains
+// picks a profile for a domain and its subdomains, the most specific domain
+// winning; other hosts use DefaultProfile ("go" when empty). See package
+// tlsprofile for the available profiles.
+type TLSConfig struct {
+	DefaultProfile string                   `mapstructure:"default_profile" json:"default_profile,omitempty"`
+	Domains        []TLSDomainProfileConfig `mapstructure:"domains" json:"domains,omitempty"`
+}
+
+// Validate checks that every domain entry names a host and a profile
+func (c TLSConfig) Validate() error {
+	for i, d := range c.Domains {
+		switch domain := strings.TrimSpace(d.Domain); {
+		case domain == "":
+			return fmt.Errorf("domains[%d]: domain is required", i)
+		case strings.ContainsAny(domain, "/:?# "):
+			return fmt.Errorf("domains[%d]: domain must be a host name, got %q", i, d.Domain)
+		case strings.TrimSpace(d.Profile) == "":
+			return fmt.Errorf("domains[%d]: profile is required", i)
+		}
+	}
+	return nil
+}
+
+// Profile returns the name of the TLS profile used for a URL
+func (c TLSConfig) Profile(rawURL string) string {
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = strings.ToLower(parsed.Hostname())
+	}
+	profile, matched := c.DefaultProfile, ""
+	for _, d := range c.Domains {
+		domain := strings.Trim(strings.ToLower(strings.TrimSpace(d.Domain)), ".")
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(matched) {
+			profile, matched = d.Profile, domain
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(profile))
+}
+
+// TLSDomainProfileConfig selects the TLS profile of a domain and its subdomains
+type TLSDomainProfileConfig struct {
+	Domain  string `mapstructure:"domain" json:"domain"`
+	Profile string `mapstructure:"profile" json:"profile"`
+}
+
+// CookiesConfig represents the cookies scrapers keep between scrapes, so
+// session cookies set by consent banners or logins survive until they
+// expire. Jars are kept per registrable domain, encrypted by the keys of
+// EncryptionConfig when configured, else with EncryptionKey, a
+// base64-encoded 32-byte AES key (usually a secret:// reference), which
+// still decrypts jars saved before the keys were configured. Domains limits persistence to the listed domains and their
+// subdomains; when empty, every domain keeps its cookies. Cookies are kept
+// for at most MaxAge, which also applies to session cookies.
+type CookiesConfig struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled"`
+	EncryptionKey string        `mapstructure:"encryption_key" json:"-"`
+	Domains       []string      `mapstructure:"domains" json:"domains,omitempty"`
+	MaxAge        time.Duration `mapstructure:"max_age" json:"max_age"`
+}
+
+// RateLimitConfig represents API request rate limiting configuration.
+// Manual scrape triggers are limited separately per client by
+// TriggersPerMinute and TriggerBurstSize, whether or not Enabled is set,
+// since each one publishes a scraping task.
+type RateLimitConfig struct {
+	Enabled           bool `mapstructure:"enabled" json:"enabled"`
+	RequestsPerMinute int  `mapstructure:"requests_per_minute" json:"requests_per_minute"`
+	BurstSize         int  `mapstructure:"burst_size" json:"burst_size"`
+	TriggersPerMinute int  `mapstructure:"triggers_per_minute" json:"triggers_per_minute"` // 0 for no trigger limit
+	TriggerBurstSize  int  `mapstructure:"trigger_burst_size" json:"trigger_burst_size"`
+}
+
+// CORSConfig represents the cross-origin requests the API Gateway allows.
+// Without AllowedOrigins, every origin is allowed in development and none in
+// other environments, see ForEnvironment. An origin of "*" allows every
+// origin but cannot be combined with AllowCredentials, as browsers reject
+// credentialed responses for any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins" json:"allowed_origins,omitempty"` // e.g. https://app.example.com or https://*.example.com
+	AllowedMethods   []string      `mapstructure:"allowed_methods" json:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers" json:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers" json:"exposed_headers,omitempty"` // Response headers readable by scripts
+	AllowCredentials bool          `mapstructure:"allow_credentials" json:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age" json:"max_age"` // How long browsers cache preflight responses, 0 to not cache them
+}
+
+// Validate checks the origins and the preflight max age
+func (c CORSConfig) Validate() error {
+	if c.MaxAge < 0 {
+		return fmt.Errorf("max_age must not be negative")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("allowed origin * cannot be combined with allow_credentials")
+			}
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "*.", "", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("allowed origin %q must be a scheme and host such as https://app.example.com", origin)
+		}
+	}
+	return nil
+}
+
+// ForEnvironment returns the settings with the default origins of an
+// environment when none are configured: every origin in development, none
+// elsewhere
+func (c CORSConfig) ForEnvironment(environment string) CORSConfig {
+	if len(c.AllowedOrigins) == 0 && environment == "development" {
+		c.AllowedOrigins = []string{"*"}
+		c.AllowCredentials = false
+	}
+	return c
+}
+
+// TracingConfig represents tracing settings. Traces are sampled when they
+// end, so the ones worth keeping are kept whatever the sample rate: every
+// trace with a failed span when KeepErrors is set, and every trace lasting
+// KeepSlowerThan or longer. Other traces are kept at SampleRate, decided by
+// their trace ID. With a sample rate below 1 and neither option set, traces
+// that are not sampled are not recorded at all.
+type TracingConfig struct {
+	Enabled        bool          `mapstructure:"enabled" json:"enabled"`
+	ServiceName    string        `mapstructure:"service_name" json:"service_name"` // Defaults to the name of the service
+	JaegerURL      string        `mapstructure:"jaeger_url" json:"jaeger_url"`
+	SampleRate     float64       `mapstructure:"sample_rate" json:"sample_rate"`           // Share of traces kept, from 0 to 1
+	KeepErrors     bool          `mapstructure:"keep_errors" json:"keep_errors"`           // Keep every trace with a failed span
+	KeepSlowerThan time.Duration `mapstructure:"keep_slower_than" json:"keep_slower_than"` // Keep every trace lasting this long, 0 for none
+}
+
+// Validate checks the sample rate and slow trace threshold
+func (c TracingConfig) Validate() error {
+	switch {
+	case c.SampleRate < 0 || c.SampleRate > 1:
+		return fmt.Errorf("sample_rate must be between 0 and 1")
+	case c.KeepSlowerThan < 0:
+		return fmt.Errorf("keep_slower_than must not be negative")
+	}
+	return nil
+}
+
+// SchedulerConfig represents URL scheduler configuration. Every
+// CheckInterval the scheduler fetches up to BatchSize URLs whose next scrape
+// falls between Lookback ago and Lookahead from now. Tasks of URLs
+// with a region go to the region's topic while a scraper in the region sent
+// a heartbeat within RegionHealthTimeout; otherwise they go to the region in
+// RegionFallbacks, if any is healthy, and finally to the shared topic.
+type SchedulerConfig struct {
+	Enabled             bool                 `mapstructure:"enabled" json:"enabled"`
+	CheckInterval       time.Duration        `mapstructure:"check_interval" json:"check_interval"`
+	BatchSize           int                  `mapstructure:"batch_size" json:"batch_size"`
+	Lookback            time.Duration        `mapstructure:"lookback" json:"lookback"`
+	Lookahead           time.Duration        `mapstructure:"lookahead" json:"lookahead"`
+	Budgets             []ScrapeBudgetConfig `mapstructure:"budgets" json:"budgets,omitempty"`
+	RegionHealthTimeout time.Duration        `mapstructure:"region_health_timeout" json:"region_health_timeout"`
+	RegionFallbacks     map[string]string    `mapstructure:"region_fallbacks" json:"region_fallbacks,omitempty"`
+}
+
+// Scheduler setting limits enforced by SchedulerConfig.Validate
+const (
+	MinSchedulerCheckInterval = time.Second
+	MaxSchedulerCheckInterval = time.Hour
+	MaxSchedulerBatchSize     = 10000
+	MaxSchedulerWindow        = 24 * time.Hour // Longest lookback or lookahead
+)
+
+// Validate checks the scheduler timing and batch settings. Zero values are
+// allowed and mean the scheduler's defaults. The lookback must cover the
+// check interval, otherwise URLs that fall due between two runs are missed.
+func (c SchedulerConfig) Validate() error {
+	switch {
+	case c.CheckInterval < 0 || c.CheckInterval > 0 && (c.CheckInterval < MinSchedulerCheckInterval || c.CheckInterval > MaxSchedulerCheckInterval):
+		return fmt.Errorf("check_interval must be between %s and %s", MinSchedulerCheckInterval, MaxSchedulerCheckInterval)
+	case c.BatchSize < 0 || c.BatchSize > MaxSchedulerBatchSize:
+		return fmt.Errorf("batch_size must be between 1 and %d", MaxSchedulerBatchSize)
+	case c.Lookback < 0 || c.Lookback > MaxSchedulerWindow:
+		return fmt.Errorf("lookback must be between 0 and %s", MaxSchedulerWindow)
+	case c.Lookahead < 0 || c.Lookahead > MaxSchedulerWindow:
+		return fmt.Errorf("lookahead must be between 0 and %s", MaxSchedulerWindow)
+	case c.Lookback > 0 && c.CheckInterval > 0 && c.Lookback < c.CheckInterval:
+		return fmt.Errorf("lookback (%s) must be at least check_interval (%s)", c.Lookback, c.CheckInterval)
+	}
+	return nil
+}
+
+// ScrapeBudgetConfig limits the scrapes dispatched per UTC day for the URLs
+// of a domain (including its subdomains) or of a project. Exactly one of
+// Domain and Project is set. Once a budget is used up, further scrapes of its
+// URLs are deferred to the next day.
+type ScrapeBudgetConfig struct {
+	Domain     string `mapstructure:"domain" json:"domain,omitempty"`
+	Project    string `mapstructure:"project" json:"project,omitempty"`
+	DailyLimit int    `mapstructure:"daily_limit" json:"daily_limit"`
+}
+
+// FrequencyPolicyConfig represents the minimum scraping frequencies. The API
+// Gateway rejects URLs created or updated with a shorter frequency and the
+// URL Manager never schedules a URL more often, so clients cannot configure
+// abusive polling. The floor of a URL is the longest of MinFrequency and the
+// floors of its domain and project.
+type FrequencyPolicyConfig struct {
+	MinFrequency time.Duration          `mapstructure:"min_frequency" json:"min_frequency"` // Floor for every URL, 0 for none
+	Floors       []FrequencyFloorConfig `mapstructure:"floors" json:"floors,omitempty"`
+}
+
+// Validate checks the global minimum and every floor
+func (c FrequencyPolicyConfig) Validate() error {
+	if c.MinFrequency < 0 {
+		return fmt.Errorf("min_frequency must not be negative")
+	}
+	for i, floor := range c.Floors {
+		if err := floor.Validate(); err != nil {
+			return fmt.Errorf("floors[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Floor returns the minimum scraping frequency of a URL with the given
+// address and project, or zero when no floor applies
+func (c FrequencyPolicyConfig) Floor(rawURL, project string) time.Duration {
+	floor := c.MinFrequency
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = strings.ToLower(parsed.Hostname())
+	}
+	for _, f := range c.Floors {
+		if f.covers(host, project) {
+			floor = max(floor, f.MinFrequency)
+		}
+	}
+	return floor
+}
+
+// FrequencyFloorConfig is the minimum scraping frequency of the URLs of a
+// domain (including its subdomains) or of a project. Exactly one of Domain
+// and Project is set.
+type FrequencyFloorConfig struct {
+	Domain       string        `mapstructure:"domain" json:"domain,omitempty"`
+	Project      string        `mapstructure:"project" json:"project,omitempty"`
+	MinFrequency time.Duration `mapstructure:"min_frequency" json:"min_frequency"`
+}
+
+// Validate checks that the floor names exactly one domain or project and
+// has a positive frequency
+func (f FrequencyFloorConfig) Validate() error {
+	domain, project := strings.TrimSpace(f.Domain), strings.TrimSpace(f.Project)
+	switch {
+	case domain != "" && project != "":
+		return fmt.Errorf("a frequency floor applies to either a domain or a project, not both")
+	case domain == "" && project == "":
+		return fmt.Errorf("domain or project is required")
+	case strings.ContainsAny(domain, "/:?# "):
+		return fmt.Errorf("domain must be a host name, got %q", f.Domain)
+	case f.MinFrequency <= 0:
+		return fmt.Errorf("min_frequency must be positive")
+	}
+	return nil
+}
+
+// covers reports whether the floor applies to a URL with the given host and project
+func (f FrequencyFloorConfig) covers(host, project string) bool {
+	if name := strings.TrimSpace(f.Project); name != "" {
+		return project == name
+	}
+	domain := strings.Trim(strings.ToLower(strings.TrimSpace(f.Domain)), ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// PIIConfig represents the detection of personal data in parsed records,
+// done at parse time before records are stored (see shared/pii). Default
+// applies to URLs without a project and to projects not in Projects; a
+// project's policy replaces the default rather than extending it.
+type PIIConfig struct {
+	Default  PIIPolicyConfig            `mapstructure:"default" json:"default"`
+	Projects map[string]PIIPolicyConfig `mapstructure:"projects" json:"projects,omitempty"`
+}
+
+// Validate checks the default and every project policy
+func (c PIIConfig) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for project, policy := range c.Projects {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("projects.%s: %w", project, err)
+		}
+	}
+	return nil
+}
+
+// Policy returns the policy of a project
+func (c PIIConfig) Policy(project string) PIIPolicyConfig {
+	if policy, ok := c.Projects[project]; ok && project != "" {
+		return policy
+	}
+	return c.Default
+}
+
+// Actions taken on personal data found by a PIIPolicyConfig
+const (
+	PIIActionNone   = ""       // Personal data is not looked for
+	PIIActionFlag   = "flag"   // Fields with personal data are listed in the record's metadata
+	PIIActionHash   = "hash"   // Matches are replaced by a keyed hash, so equal values stay comparable
+	PIIActionRedact = "redact" // Matches are replaced by the name of their detector
+)
+
+// Built-in PII detectors
+const (
+	PIIDetectorEmail = "email"
+	PIIDetectorPhone = "phone"
+)
+
+// PIIPolicyConfig represents what personal data is looked for in parsed
+// records and what is done with it. Detectors selects built-in detectors,
+// all of them when empty; Patterns adds detectors by name and regular
+// expression, e.g. national ID or account number formats. HashKey, usually
+// a secret:// reference, keys the hashes of the hash action; without it
+// matches are hashed with plain SHA-256, which can be reversed by hashing
+// guesses.
+type PIIPolicyConfig struct {
+	Action    string            `mapstructure:"action" json:"action"`
+	Detectors []string          `mapstructure:"detectors" json:"detectors,omitempty"`
+	Patterns  map[string]string `mapstructure:"patterns" json:"patterns,omitempty"`
+	HashKey   string            `mapstructure:"hash_key" json:"-"`
+}
+
+// Validate checks the action, detector names and patterns
+func (c PIIPolicyConfig) Validate() error {
+	switch c.Action {
+	case PIIActionNone, PIIActionFlag, PIIActionHash, PIIActionRedact:
+	default:
+		return fmt.Errorf("unsupported action %q, use flag, hash or redact", c.Action)
+	}
+	for _, name := range c.Detectors {
+		if name != PIIDetectorEmail && name != PIIDetectorPhone {
+			return fmt.Errorf("unknown detector %q, use email or phone", name)
+		}
+	}
+	for name, pattern := range c.Patterns {
+		if name == "" || strings.ContainsAny(name, ":,[] ") {
+			return fmt.Errorf("invalid pattern name %q", name)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("patterns.%s: %w", name, err)
+		}
+		if re.MatchString("") {
+			return fmt.Errorf("patterns.%s matches the empty string", name)
+		}
+	}
+	return nil
+}
+
+// WatchdogConfig represents settings for detecting stalled or failing URLs.
+// A URL is degraded when its next scrape is more than OverdueAfter past due
+// or its last MaxConsecutiveFailures scrapes all failed.
+type WatchdogConfig struct {
+	Enabled                bool          `mapstructure:"enabled" json:"enabled"`
+	CheckInterval          time.Duration `mapstructure:"check_interval" json:"check_interval"`
+	OverdueAfter           time.Duration `mapstructure:"overdue_after" json:"overdue_after"`
+	MaxConsecutiveFailures int           `mapstructure:"max_consecutive_failures" json:"max_consecutive_failures"`
+	BatchSize              int           `mapstructure:"batch_size" json:"batch_size"`
+}
+
+// MaintenanceConfig represents how often services re-read the maintenance
+// mode switch, set through POST /api/v1/admin/maintenance
+type MaintenanceConfig struct {
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" json:"refresh_interval"`
+}
+
+// AlertsConfig represents the system-level alert rules. Rules are evaluated
+// every EvaluationInterval; a rule notifies the notification channels when
+// it starts firing and again when it resolves.
+type AlertsConfig struct {
+	Enabled            bool              `mapstructure:"enabled" json:"enabled"`
+	EvaluationInterval time.Duration     `mapstructure:"evaluation_interval" json:"evaluation_interval"`
+	Rules              []AlertRuleConfig `mapstructure:"rules" json:"rules"`
+}
+
+// AlertRuleConfig represents a single alert rule. Kind selects what is
+// measured and how Threshold is compared:
+//   - "dlq_depth": messages in the dead letter queue, of Topic if set (the
+//     topic they were consumed from), fires above Threshold
+//   - "success_rate": percentage of scrapes that succeeded over Window
+//     (default 1h), fires below Threshold
+//   - "consumer_lag": messages Group has not consumed from Topic (defaults
+//     to the Kafka group and the scraping results topic), fires above Threshold
+//   - "no_scrapes": fires when no scrape completed for Window
+type AlertRuleConfig struct {
+	Name      string        `mapstructure:"name" json:"name"`
+	Kind      string        `mapstructure:"kind" json:"kind"`
+	Threshold float64       `mapstructure:"threshold" json:"threshold"`
+	Window    time.Duration `mapstructure:"window" json:"window"`
+	Topic     string        `mapstructure:"topic" json:"topic,omitempty"`
+	Group     string        `mapstructure:"group" json:"group,omitempty"`
+	Severity  string        `mapstructure:"severity" json:"severity"` // Defaults to warning
+}
+
+// SLOs are the service level objectives reported by GET /api/v1/metrics/slos
+type SLOs []SLOConfig
+
+// Validate checks every objective and that their names are unique
+func (s SLOs) Validate() error {
+	names := make(map[string]bool, len(s))
+	for i, slo := range s {
+		if err := slo.Validate(); err != nil {
+			return fmt.Errorf("slos[%d]: %w", i, err)
+		}
+		if names[slo.Name] {
+			return fmt.Errorf("slos[%d]: duplicate name %q", i, slo.Name)
+		}
+		names[slo.Name] = true
+	}
+	return nil
+}
+
+// SLOConfig represents a service level objective: Objective percent of the
+// scrapes due in a period, of every URL or of the URLs of Project, must meet
+// the objective's kind:
+//   - "scheduling_latency": the scrape completed within MaxDelay of the time
+//     it was due, whatever its outcome
+//   - "success": the scrape succeeded, out of the completed scrapes
+//
+// The error budget is the share of scrapes allowed to miss the objective.
+type SLOConfig struct {
+	Name      string        `mapstructure:"name" json:"name"`
+	Kind      string        `mapstructure:"kind" json:"kind"`
+	Objective float64       `mapstructure:"objective" json:"objective"` // Percentage, e.g. 99
+	MaxDelay  time.Duration `mapstructure:"max_delay" json:"max_delay,omitempty"`
+	Project   string        `mapstructure:"project" json:"project,omitempty"`
+}
+
+// Validate checks the name, kind and objective, and that a scheduling
+// latency objective has a delay
+func (c SLOConfig) Validate() error {
+	switch {
+	case strings.TrimSpace(c.Name) == "":
+		return fmt.Errorf("name is required")
+	case c.Kind != models.SLOKindSchedulingLatency && c.Kind != models.SLOKindSuccess:
+		return fmt.Errorf("kind must be %s or %s, got %q", models.SLOKindSchedulingLatency, models.SLOKindSuccess, c.Kind)
+	case c.Objective <= 0 || c.Objective >= 100:
+		return fmt.Errorf("objective must be a percentage between 0 and 100, exclusive")
+	case c.Kind == models.SLOKindSchedulingLatency && c.MaxDelay <= 0:
+		return fmt.Errorf("max_delay must be positive")
+	}
+	return nil
+}
+
+// SyncConfig represents configuration-as-code settings. When enabled, the
+// URL Manager reconciles the database with the URLs declared in Path, a YAML
+// file or a directory of YAML files such as a git checkout.
+type SyncConfig struct {
+	Enabled  bool          `mapstructure:"enabled" json:"enabled"`
+	Path     string        `mapstructure:"path" json:"path"`
+	Interval time.Duration `mapstructure:"interval" json:"interval"`
+	Prune    bool          `mapstructure:"prune" json:"prune"`     // Delete managed URLs that are no longer declared
+	DryRun   bool          `mapstructure:"dry_run" json:"dry_run"` // Only report drift, never change the database
+}
+
+// RedirectsConfig represents how the URL Manager tracks URLs that moved. A
+// URL whose scrapes are redirected permanently (301 or 308) to the same
+// location MoveThreshold times in a row is flagged as moved, see GET
+// /api/v1/urls/moves; with AutoUpdate, its stored address is also changed
+// to the new location.
+type RedirectsConfig struct {
+	MoveThreshold int  `mapstructure:"move_threshold" json:"move_threshold"`
+	AutoUpdate    bool `mapstructure:"auto_update" json:"auto_update"`
+}
+
+// NotificationsConfig represents the channels alerts are sent to. Channels
+// stored in the database are added to these and re-read every
+// RefreshInterval.
+type NotificationsConfig struct {
+	RefreshInterval time.Duration               `mapstructure:"refresh_interval" json:"refresh_interval"`
+	Channels        []NotificationChannelConfig `mapstructure:"channels" json:"channels"`
+}
+
+// NotificationChannelConfig represents a single alert channel. Type is
+// "webhook" (JSON POST of the alert), "slack" (incoming webhook), "email"
+// (SMTP), "pagerduty" (Events API v2, URL overrides the endpoint) or "log".
+type NotificationChannelConfig struct {
+	Name       string            `mapstructure:"name" json:"name"`
+	Type       string            `mapstructure:"type" json:"type"`
+	URL        string            `mapstructure:"url" json:"-"`
+	Headers    map[string]string `mapstructure:"headers" json:"-"`
+	RoutingKey string            `mapstructure:"routing_key" json:"-"` // PagerDuty integration key
+	Email      EmailConfig       `mapstructure:"email" json:"email"`
+	Timeout    time.Duration     `mapstructure:"timeout" json:"timeout"`
+}
+
+// EmailConfig represents the SMTP server and recipients of an email channel.
+// The connection is upgraded with STARTTLS when the server offers it.
+type EmailConfig struct {
+	Host     string   `mapstructure:"host" json:"host,omitempty"`
+	Port     int      `mapstructure:"port" json:"port,omitempty"` // Defaults to 587
+	Username string   `mapstructure:"username" json:"username,omitempty"`
+	Password string   `mapstructure:"password" json:"-"`
+	From     string   `mapstructure:"from" json:"from,omitempty"`
+	To       []string `mapstructure:"to" json:"to,omitempty"`
+}
+
+// WorkersConfig represents worker pool configuration. Scraper and parser
+// instances record a heartbeat every HeartbeatInterval; instances silent for
+// three intervals are reported as stale by the fleet status API.
+type WorkersConfig struct {
+	Count             int           `mapstructure:"count" json:"count"`
+	QueueSize         int           `mapstructure:"queue_size" json:"queue_size"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout" json:"idle_timeout"`
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval" json:"heartbeat_interval"`
+}
+
+// FeaturesConfig represents feature flag configuration. Flags defined here
+// are defaults; flags stored in the database take precedence and are
+// re-read every RefreshInterval.
+type FeaturesConfig struct {
+	RefreshInterval time.Duration                `mapstructure:"refresh_interval" json:"refresh_interval"`
+	Flags           map[string]FeatureFlagConfig `mapstructure:"flags" json:"flags"`
+}
+
+// FeatureFlagConfig represents a single feature flag
+type FeatureFlagConfig struct {
+	Enabled        bool            `mapstructure:"enabled" json:"enabled"`
+	RolloutPercent *int            `mapstructure:"rollout_percent" json:"rollout_percent,omitempty"` // Defaults to 100
+	Tenants        map[string]bool `mapstructure:"tenants" json:"tenants,omitempty"`                 // Per-tenant overrides
+}
+
+// Validate checks the rollout percentage of every flag
+func (c FeaturesConfig) Validate() error {
+	for name, flag := range c.Flags {
+		if p := flag.RolloutPercent; p != nil && (*p < 0 || *p > 100) {
+			return fmt.Errorf("flags.%s: rollout_percent must be from 0 to 100, got %d", name, *p)
+		}
+	}
+	return nil
+}
+
+// SecretsConfig configures the backends that resolve secret:// references
+// in configuration values. References are re-resolved every RefreshInterval
+// so rotated secrets are picked up; zero disables periodic refresh.
+type SecretsConfig struct {
+	RefreshInterval time.Duration      `mapstructure:"refresh_interval" json:"refresh_interval"`
+	Vault           VaultSecretsConfig `mapstructure:"vault" json:"vault"`
+	AWS             AWSSecretsConfig   `mapstructure:"aws" json:"aws"`
+}
+
+// VaultSecretsConfig represents HashiCorp Vault configuration.
+// Address and Token fall back to VAULT_ADDR and VAULT_TOKEN.
+type VaultSecretsConfig struct {
+	Address   string `mapstructure:"address" json:"address"`
+	Token     string `mapstructure:"token" json:"-"`
+	Namespace string `mapstructure:"namespace" json:"namespace"`
+}
+
+// AWSSecretsConfig represents AWS Secrets Manager configuration.
+// Region falls back to AWS_REGION; credentials are read from the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables.
+type AWSSecretsConfig struct {
+	Region   string `mapstructure:"region" json:"region"`
+	Endpoint string `mapstructure:"endpoint" json:"endpoint"`
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Environment: "development",
-		LogLevel:    "info",
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+			Output: LogOutputStdout,
+			File: LogFileConfig{
+				MaxSizeMB: 100,
+			},
+			Requests: RequestLogConfig{
+				SampleRate: 1,
+			},
+		},
 		Database: DatabaseConfig{
-			Host:     "localhost",
-			Port:     5432,
-			User:     "scraper",
-			Password: "scraper",
-			DBName:   "scraping_db",
-			SSLMode:  "disable",
-			MaxConns: 10,
+			Host:             "localhost",
+			Port:             5432,
+			User:             "scraper",
+			Password:         "scraper",
+			DBName:           "scraping_db",
+			SSLMode:          "disable",
+			MaxOpenConns:     25,
+			MaxIdleConns:     5,
+			StatementTimeout: 30 * time.Second,
+			QueryTimeout:     5 * time.Second,
 		},
 		Kafka: KafkaConfig{
 			Brokers: []string{"localhost:9092"},
+			GroupID: "scraper-group",
 			Topics: TopicsConfig{
-				ScrapingTasks: "scraping-tasks",
-				ScrapedData:   "scraped-data",
-				ParsedData:    "parsed-data",
-				DeadLetter:    "dead-letter",
+				ScrapingTasks:   "scraping-tasks",
+				ScrapingResults: "scraping-results",
+				ScrapedData:     "scraped-data",
+				ParsedData:      "parsed-data",
+				DeadLetter:      "dead-letter",
+				URLEvents:       "url-events",
+				ConsumerControl: "consumer-control",
 			},
-			ConsumerGroup: "scraping-group",
-			ProducerConfig: ProducerConfig{
-				RequiredAcks: 1,
-				Timeout:      30 * time.Second,
-				Retries:      3,
-			},
-			ConsumerConfig: ConsumerConfig{
-				AutoOffsetReset:   "earliest",
-				SessionTimeout:    30 * time.Second,
-				HeartbeatInterval: 3 * time.Second,
+			AutoOffsetReset:   "earliest",
+			SessionTimeout:    30 * time.Second,
+			HeartbeatInterval: 3 * time.Second,
+			RetryBackoff:      100 * time.Millisecond,
+			RetryMaxAttempts:  3,
+			Producer: ProducerConfig{
+				Compression: "none",
+				BatchSize:   DefaultProducerBatchSize,
+				Linger:      DefaultProducerLinger,
+				Acks:        AcksAll,
 			},
 		},
-		HTTP: HTTPConfig{
+		Server: ServerConfig{
 			Port:         8080,
 			ReadTimeout:  30 * time.Second,
 			WriteTimeout: 30 * time.Second,
 			IdleTimeout:  60 * time.Second,
+			CORS: CORSConfig{
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "Authorization"},
+				MaxAge:         10 * time.Minute,
+			},
+			TLS: ServerTLSConfig{
+				MinVersion: TLSVersion12,
+				HSTSMaxAge: 365 * 24 * time.Hour,
+			},
 		},
 		Scraping: ScrapingConfig{
 			DefaultTimeout:    30 * time.Second,
 			DefaultUserAgent:  "GoScrapingBot/1.0",
 			DefaultMaxRetries: 3,
 			DefaultRateLimit:  1,
+			DomainRateLimit:   60,
 			Concurrency:       10,
+			RespectRobotsTxt:  true,
+			Cookies: CookiesConfig{
+				MaxAge: 30 * 24 * time.Hour,
+			},
+			HAR: HARConfig{
+				RequestTTL:  24 * time.Hour,
+				Retention:   24 * time.Hour,
+				BodyPreview: 64 * 1024,
+			},
+			DNS: DNSConfig{
+				Timeout:    5 * time.Second,
+				MinTTL:     5 * time.Second,
+				MaxTTL:     10 * time.Minute,
+				DefaultTTL: time.Minute,
+			},
+		},
+		Control: ControlConfig{
+			URLManagerURL: "http://localhost:8081",
+			Timeout:       5 * time.Second,
+		},
+		Health: HealthConfig{
+			Timeout: 5 * time.Second,
+		},
+		Startup: StartupConfig{
+			MaxWait:        2 * time.Minute,
+			InitialBackoff: time.Second,
+			MaxBackoff:     15 * time.Second,
+		},
+		Cache: CacheConfig{
+			Address:    "localhost:6379",
+			KeyPrefix:  "scraping:",
+			Timeout:    200 * time.Millisecond,
+			URLTTL:     30 * time.Second,
+			ListTTL:    10 * time.Second,
+			MetricsTTL: 15 * time.Second,
+		},
+		Exports: ExportsConfig{
+			LinkTTL:   15 * time.Minute,
+			Retention: 7 * 24 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			SampleRate: 1,
+			KeepErrors: true,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           false,
+			RequestsPerMinute: 1000,
+			BurstSize:         100,
+			TriggersPerMinute: 10,
+			TriggerBurstSize:  5,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:             true,
+			CheckInterval:       30 * time.Second,
+			BatchSize:           100,
+			Lookback:            time.Minute,
+			Lookahead:           5 * time.Minute,
+			RegionHealthTimeout: 45 * time.Second,
+		},
+		Workers: WorkersConfig{
+			Count:             5,
+			QueueSize:         1000,
+			IdleTimeout:       30 * time.Second,
+			HeartbeatInterval: 15 * time.Second,
+		},
+		Features: FeaturesConfig{
+			RefreshInterval: 30 * time.Second,
+		},
+		Watchdog: WatchdogConfig{
+			Enabled:                true,
+			CheckInterval:          time.Minute,
+			OverdueAfter:           15 * time.Minute,
+			MaxConsecutiveFailures: 5,
+			BatchSize:              100,
+		},
+		Sync: SyncConfig{
+			Enabled:  false,
+			Interval: time.Minute,
+			Prune:    true,
+		},
+		Alerts: AlertsConfig{
+			Enabled:            true,
+			EvaluationInterval: time.Minute,
+		},
+		Redirects: RedirectsConfig{
+			MoveThreshold: 3,
+		},
+		Maintenance: MaintenanceConfig{
+			RefreshInterval: 10 * time.Second,
 		},
 	}
 }
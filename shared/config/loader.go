@@ -9,7 +9,8 @@ import (
 
 // Loader handles loading configuration files with inheritance from shared configs
 type Loader struct {
-	viper *viper.Viper
+	viper      *viper.Viper
+	sharedFile string
 }
 
 // NewLoader creates a new configuration loader
@@ -56,6 +57,8 @@ func (l *Loader) loadSharedConfig() error {
 		return fmt.Errorf("failed to read shared config: %w", err)
 	}
 
+	l.sharedFile = sharedViper.ConfigFileUsed()
+
 	// Merge shared config into main viper instance
 	for _, key := range sharedViper.AllKeys() {
 		l.viper.Set(key, sharedViper.Get(key))
@@ -64,6 +67,90 @@ func (l *Loader) loadSharedConfig() error {
 	return nil
 }
 
+// ConfigFiles returns the shared and service configuration files that were loaded
+func (l *Loader) ConfigFiles() []string {
+	var files []string
+	if l.sharedFile != "" {
+		files = append(files, l.sharedFile)
+	}
+	if file := l.viper.ConfigFileUsed(); file != "" {
+		files = append(files, file)
+	}
+	return files
+}
+
+// Config decodes the loaded configuration into the typed Config structure.
+// Values missing from the configuration files keep their DefaultConfig values.
+// Invalid scheduler settings and frequency policies are rejected, so a bad
+// reload keeps the previous configuration.
+func (l *Loader) Config() (*Config, error) {
+	cfg := DefaultConfig()
+	if err := l.viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	if err := cfg.Logging.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid logging configuration: %w", err)
+	}
+	if err := cfg.Server.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+	if err := cfg.MTLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mTLS configuration: %w", err)
+	}
+	if cfg.MTLS.Enabled && cfg.MTLS.Server && cfg.Server.TLS.Enabled {
+		return nil, fmt.Errorf("invalid mTLS configuration: mtls.server and server.tls cannot both be enabled")
+	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid encryption configuration: %w", err)
+	}
+	if err := cfg.Exports.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid exports configuration: %w", err)
+	}
+	if err := cfg.Server.CORS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	if err := cfg.Scheduler.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scheduler configuration: %w", err)
+	}
+	if err := cfg.FrequencyPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid frequency policy: %w", err)
+	}
+	if err := cfg.PII.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pii configuration: %w", err)
+	}
+	if err := cfg.Kafka.Producer.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka.producer configuration: %w", err)
+	}
+	if err := cfg.Scraping.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scraping.tls configuration: %w", err)
+	}
+	if err := cfg.Scraping.DNS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scraping.dns configuration: %w", err)
+	}
+	if err := cfg.Scraping.Network.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scraping.network configuration: %w", err)
+	}
+	if err := cfg.Scraping.ProjectShares.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scraping.project_shares: %w", err)
+	}
+	if err := cfg.Cache.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cache configuration: %w", err)
+	}
+	if err := cfg.Tracing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tracing configuration: %w", err)
+	}
+	if err := cfg.SLOs.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SLOs: %w", err)
+	}
+	if err := cfg.Features.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid features configuration: %w", err)
+	}
+	return cfg, nil
+}
+
 // GetString retrieves a string value from configuration
 func (l *Loader) GetString(key string) string {
 	return l.viper.GetString(key)
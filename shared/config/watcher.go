@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDebounce coalesces the burst of events editors emit for a single save
+const reloadDebounce = 250 * time.Millisecond
+
+// Watcher holds the effective configuration and reloads it when the
+// configuration files change or the process receives SIGHUP.
+//
+// Only settings that subscribers re-read are applied live: the log level,
+// API rate limits and scheduler settings, plus worker settings for services
+// that subscribe to them. Connection settings such as the database host and
+// Kafka brokers still require a restart; rotated database credentials are
+// used for new connections.
+type Watcher struct {
+	load    func() (*Config, error)
+	logger  *logrus.Logger
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	reloadedAt  time.Time
+}
+
+// NewWatcher creates a watcher starting from the initial configuration.
+// load re-reads the configuration from its sources; it may be nil when the
+// configuration is fixed, e.g. in tests.
+func NewWatcher(initial *Config, load func() (*Config, error), logger *logrus.Logger) *Watcher {
+	w := &Watcher{load: load, logger: logger}
+	w.current.Store(initial)
+	return w
+}
+
+// Current returns the effective configuration. The returned value must not be modified.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// ReloadedAt returns when the configuration was last reloaded, or the zero time
+func (w *Watcher) ReloadedAt() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reloadedAt
+}
+
+// OnChange registers a function called with the new configuration after every
+// successful reload. Subscribers run synchronously and should return quickly.
+func (w *Watcher) OnChange(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload re-reads the configuration and notifies subscribers.
+// On error the previous configuration stays in effect.
+func (w *Watcher) Reload() error {
+	if w.load == nil {
+		return fmt.Errorf("configuration reload is not supported")
+	}
+
+	cfg, err := w.load()
+	if err != nil {
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+	w.current.Store(cfg)
+
+	w.mu.Lock()
+	w.reloadedAt = time.Now().UTC()
+	subscribers := make([]func(*Config), len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(cfg)
+	}
+	return nil
+}
+
+// Run watches the given configuration files and SIGHUP, reloading on change,
+// until ctx is cancelled. The files' directories are watched rather than the
+// files themselves so that atomic renames by editors and ConfigMap updates
+// are picked up.
+func (w *Watcher) Run(ctx context.Context, files []string) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		file = filepath.Clean(file)
+		watched[file] = true
+		if err := fsWatcher.Add(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", file, err)
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			w.logger.Info("Received SIGHUP, reloading configuration")
+			w.reloadAndLog()
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if watched[filepath.Clean(event.Name)] && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			w.logger.Info("Configuration file changed, reloading configuration")
+			w.reloadAndLog()
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.WithError(err).Warn("Configuration file watcher error")
+		}
+	}
+}
+
+func (w *Watcher) reloadAndLog() {
+	if err := w.Reload(); err != nil {
+		w.logger.WithError(err).Error("Keeping previous configuration")
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestWatcherReloadNotifiesSubscribers(t *testing.T) {
+	next := DefaultConfig()
+	next.Logging.Level = "debug"
+
+	w := NewWatcher(DefaultConfig(), func() (*Config, error) { return next, nil }, quietLogger())
+
+	var notified *Config
+	w.OnChange(func(cfg *Config) { notified = cfg })
+
+	if err := w.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if w.Current().Logging.Level != "debug" || notified != next {
+		t.Errorf("reload not applied: current=%q notified=%v", w.Current().Logging.Level, notified)
+	}
+	if w.ReloadedAt().IsZero() {
+		t.Error("ReloadedAt not recorded")
+	}
+}
+
+func TestWatcherKeepsConfigOnFailedReload(t *testing.T) {
+	initial := DefaultConfig()
+	w := NewWatcher(initial, func() (*Config, error) { return nil, errors.New("bad yaml") }, quietLogger())
+
+	if err := w.Reload(); err == nil {
+		t.Fatal("expected Reload() to fail")
+	}
+	if w.Current() != initial {
+		t.Error("configuration replaced after failed reload")
+	}
+}
+
+func TestWatcherReloadsOnFileChange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "service.yaml")
+	if err := os.WriteFile(file, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	w := NewWatcher(DefaultConfig(), func() (*Config, error) { return DefaultConfig(), nil }, quietLogger())
+	w.OnChange(func(*Config) {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, []string{file})
+
+	// Give the watcher time to register before changing the file
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(file, []byte("v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded after the file changed")
+	}
+}
@@ -0,0 +1,139 @@
+// Package contract pins down the wire format of the Kafka messages the
+// services exchange, so a producer and its consumers cannot drift apart
+// silently. Each message type has a JSON fixture of a message with every
+// field set, embedded from fixtures/; to cover every field, fixtures need not
+// be messages a service would send, e.g. a successful scrape result with an
+// error. Contract tests check producers against
+// the fixtures with Keys, and consumer structures against them with
+// DecodeStrict and UnsetFields; run them with make test-contracts.
+//
+// A change of a message format updates its fixture along with the producer
+// and every consumer, in the same change.
+package contract
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Fixture names, one per message type
+const (
+	ScrapingTask    = "scraping_task"    // models.ScrapingTaskMessage, from the URL Manager to scrapers
+	ScrapeResult    = "scrape_result"    // models.KafkaMessage of a models.ScrapeResult, from scrapers to the URL Manager
+	URLEvent        = "url_event"        // models.URLEvent, from the API Gateway and URL Manager
+	ConsumerCommand = "consumer_command" // kafka.ConsumerCommand, from the API Gateway to every consumer
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Names returns the names of every fixture, sorted
+func Names() []string {
+	entries, _ := fixtures.ReadDir("fixtures")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name()[:len(entry.Name())-len(".json")])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fixture returns the message of the fixture with the given name. It panics
+// if there is no such fixture.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		panic(fmt.Sprintf("contract: no fixture %q", name))
+	}
+	return data
+}
+
+// DecodeStrict decodes a message into v like json.Unmarshal, but fails on
+// fields v has no place for, which a consumer would silently drop
+func DecodeStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("data after the message")
+	}
+	return nil
+}
+
+// Keys returns the field names of a JSON object, sorted, with the fields of
+// nested objects as parent.child. Array elements are not looked into.
+func Keys(data []byte) ([]string, error) {
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	var keys []string
+	collectKeys(object, "", &keys)
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func collectKeys(object map[string]any, prefix string, keys *[]string) {
+	for key, value := range object {
+		*keys = append(*keys, prefix+key)
+		if nested, ok := value.(map[string]any); ok {
+			collectKeys(nested, prefix+key+".", keys)
+		}
+	}
+}
+
+// UnsetFields returns the exported fields of the struct v points to that
+// hold their zero value, by Go name, with the fields of nested structs as
+// Parent.Child. Decoding a fixture into a consumer structure must leave no
+// field unset: a field the fixture does not cover is a field no contract
+// test checks.
+func UnsetFields(v any) []string {
+	var unset []string
+	collectUnset(reflect.ValueOf(v), "", &unset)
+	return unset
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func collectUnset(value reflect.Value, prefix string, unset *[]string) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + field.Name
+		fieldValue := value.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type.NumField() == 0 {
+			continue // Nothing to set, e.g. the cache of a HAR entry
+		}
+		if fieldValue.IsZero() {
+			*unset = append(*unset, name)
+			continue
+		}
+		if isStruct(field.Type) {
+			collectUnset(fieldValue, name+".", unset)
+		}
+	}
+}
+
+// isStruct reports whether t is a struct, or a pointer to one, other than
+// time.Time
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
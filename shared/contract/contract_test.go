@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go_scraping_project/shared/kafka"
+	"go_scraping_project/shared/models"
+)
+
+// consumers maps every fixture to the structure its consumers decode it into
+var consumers = map[string]func() any{
+	ScrapingTask:    func() any { return &models.ScrapingTaskMessage{} },
+	ScrapeResult:    func() any { return &kafka.ScrapeResultMessage{} },
+	URLEvent:        func() any { return &models.URLEvent{} },
+	ConsumerCommand: func() any { return &kafka.ConsumerCommand{} },
+}
+
+// deadLetterTypes maps the fixtures of messages that can be dead-lettered to
+// their message type, see kafka.DecodePayload
+var deadLetterTypes = map[string]string{
+	ScrapingTask: models.MessageTypeScrapingTask,
+	ScrapeResult: models.MessageTypeScrapeResult,
+	URLEvent:     models.MessageTypeURLEvent,
+}
+
+func TestContractFixturesDecode(t *testing.T) {
+	names := Names()
+	if len(names) != len(consumers) {
+		t.Fatalf("fixtures = %v, want one per consumer structure", names)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			newValue, ok := consumers[name]
+			if !ok {
+				t.Fatalf("no consumer structure for fixture %s", name)
+			}
+			value := newValue()
+			if err := DecodeStrict(Fixture(name), value); err != nil {
+				t.Fatalf("DecodeStrict() error = %v", err)
+			}
+			if unset := UnsetFields(value); len(unset) > 0 {
+				t.Errorf("fields %v are not in the fixture", unset)
+			}
+
+			// Encoding the decoded message gives the fixture back
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var got, want any
+			json.Unmarshal(encoded, &got)
+			json.Unmarshal(Fixture(name), &want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("encoded = %s, want the fixture", encoded)
+			}
+		})
+	}
+}
+
+func TestContractDeadLetterPayloads(t *testing.T) {
+	for name, messageType := range deadLetterTypes {
+		payload, err := kafka.DecodePayload(messageType, Fixture(name))
+		if err != nil {
+			t.Errorf("DecodePayload(%s) error = %v", messageType, err)
+			continue
+		}
+		if want := consumers[name](); reflect.TypeOf(payload) != reflect.TypeOf(want) {
+			t.Errorf("DecodePayload(%s) = %T, want %T", messageType, payload, want)
+		}
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	var event models.URLEvent
+	if err := DecodeStrict([]byte(`{"type":"url.created","url_ids":[]}`), &event); err == nil {
+		t.Error("DecodeStrict() of an unknown field succeeded")
+	}
+	if err := DecodeStrict([]byte(`{"type":"url.created"} {}`), &event); err == nil {
+		t.Error("DecodeStrict() of two messages succeeded")
+	}
+	if err := DecodeStrict([]byte(`{"type":"url.created"}`), &event); err != nil || event.Type != "url.created" {
+		t.Errorf("DecodeStrict() = %+v, %v", event, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys, err := Keys([]byte(`{"b":1,"a":{"y":[{"z":1}],"x":null}}`))
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	if want := []string{"a", "a.x", "a.y", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+}
+
+func TestUnsetFields(t *testing.T) {
+	task := models.ScrapingTaskMessage{
+		URL:         "https://example.com",
+		RetryPolicy: models.RetryPolicy{MaxAttempts: 3},
+		Assertions:  &models.Assertions{Status: 200},
+	}
+	want := []string{
+		"TaskID", "URLID", "Attempt",
+		"RetryPolicy.BackoffBaseMs", "RetryPolicy.BackoffCapMs", "RetryPolicy.RetryOnStatus",
+		"Assertions.Contains", "Assertions.Selectors",
+		"Region", "RateLimit", "ArchivePolicy", "CaptureHAR", "Project", "CorrelationID", "Timestamp",
+	}
+	if got := UnsetFields(&task); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnsetFields() = %v, want %v", got, want)
+	}
+}
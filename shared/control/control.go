@@ -0,0 +1,95 @@
+// Package control is the internal HTTP API the API Gateway uses to send
+// commands to the URL Manager, such as scraping a URL right away instead of
+// waiting for the next scheduler tick. The URL Manager serves the endpoints
+// (see its handlers.ControlHandler); Client calls them.
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+)
+
+// ErrURLNotFound is returned when the URL to scrape does not exist or is deleted
+var ErrURLNotFound = domain.ErrURLNotFound
+
+// ErrQuotaExceeded is the kind of the error returned when a scrape budget
+// covering the URL is used up. The error's message tells which budget and
+// domain.RetryAfter when it resets.
+var ErrQuotaExceeded = domain.ErrRateLimited
+
+// TriggerResponse is the response of the trigger endpoint
+type TriggerResponse struct {
+	TaskID    uuid.UUID `json:"task_id"` // Scraping task published for the URL
+	URLID     uuid.UUID `json:"url_id"`
+	Topic     string    `json:"topic"`      // Kafka topic the task was published to
+	Attempt   int       `json:"attempt"`    // Attempt number of the task, starting at 1
+	CreatedAt time.Time `json:"created_at"` // When the task was published
+}
+
+// TriggerPath returns the path of the URL Manager endpoint that scrapes a URL immediately
+func TriggerPath(urlID uuid.UUID) string {
+	return "/api/v1/admin/urls/" + urlID.String() + "/scrape"
+}
+
+// Client calls the URL Manager's control API
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a client for the URL Manager at cfg.URLManagerURL. If
+// client is nil, a client with cfg.Timeout is used.
+func NewClient(cfg config.ControlConfig, client *http.Client) *Client {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Client{
+		baseURL: strings.TrimRight(cfg.URLManagerURL, "/"),
+		client:  client,
+	}
+}
+
+// TriggerScrape asks the URL Manager to publish a scraping task for the URL
+// now, and returns the task. Fails with ErrURLNotFound or, when the URL is
+// over its daily scrape budget, an ErrQuotaExceeded error.
+func (c *Client) TriggerScrape(ctx context.Context, urlID uuid.UUID) (*TriggerResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+TriggerPath(urlID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the URL Manager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrURLNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return nil, domain.RateLimited(time.Duration(seconds)*time.Second, "%s", strings.TrimSpace(string(body)))
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("URL Manager returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var triggered TriggerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&triggered); err != nil {
+		return nil, fmt.Errorf("invalid response from the URL Manager: %w", err)
+	}
+	return &triggered, nil
+}
@@ -0,0 +1,81 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestClientTriggerScrape(t *testing.T) {
+	known := uuid.New()
+	taskID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		switch r.URL.Path {
+		case TriggerPath(known):
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(TriggerResponse{TaskID: taskID, URLID: known, Topic: "scraping-tasks"})
+		default:
+			http.Error(w, "URL not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(config.ControlConfig{URLManagerURL: server.URL + "/", Timeout: time.Second}, nil)
+
+	got, err := client.TriggerScrape(context.Background(), known)
+	if err != nil {
+		t.Fatalf("TriggerScrape() error = %v", err)
+	}
+	if got.TaskID != taskID || got.URLID != known || got.Topic != "scraping-tasks" {
+		t.Errorf("TriggerScrape() = %+v", got)
+	}
+
+	if _, err := client.TriggerScrape(context.Background(), uuid.New()); !errors.Is(err, ErrURLNotFound) {
+		t.Errorf("TriggerScrape() for an unknown URL error = %v, want ErrURLNotFound", err)
+	}
+}
+
+func TestClientTriggerScrapeReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(config.ControlConfig{URLManagerURL: server.URL}, nil)
+	_, err := client.TriggerScrape(context.Background(), uuid.New())
+	if err == nil || errors.Is(err, ErrURLNotFound) {
+		t.Errorf("TriggerScrape() error = %v, want a server error", err)
+	}
+}
+
+func TestClientTriggerScrapeReportsQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "Daily scrape budget of project shop is used up (500 scrapes)", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(config.ControlConfig{URLManagerURL: server.URL}, nil)
+	_, err := client.TriggerScrape(context.Background(), uuid.New())
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("TriggerScrape() error = %v, want ErrQuotaExceeded", err)
+	}
+	if message, _ := domain.Message(err); message != "Daily scrape budget of project shop is used up (500 scrapes)" {
+		t.Errorf("message = %q, want the URL Manager's", message)
+	}
+	if retryAfter := domain.RetryAfter(err); retryAfter != time.Hour {
+		t.Errorf("retry after %v, want 1h", retryAfter)
+	}
+}
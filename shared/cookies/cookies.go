@@ -0,0 +1,316 @@
+// Package cookies keeps scrapers' cookies between scheduled scrapes, so
+// session cookies set by consent banners or logins survive until they
+// expire instead of every scrape landing on the same interstitial. Cookies
+// are kept per registrable domain (example.co.uk for www.example.co.uk) and
+// encrypted with AES-GCM before they are stored: in envelopes of the
+// encryption keyring when one is configured, else with the
+// cookies.encryption_key. Jars sealed with the encryption key stay readable
+// after a keyring is configured, until they are saved again.
+package cookies
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/encryption"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Store persists encrypted cookie jars by domain
+type Store interface {
+	// LoadJar returns the jar stored for domain; ok is false if there is none
+	// or it expired
+	LoadJar(ctx context.Context, domain string) (data []byte, ok bool, err error)
+	// SaveJar replaces the jar stored for domain
+	SaveJar(ctx context.Context, domain string, data []byte, count int, expiresAt time.Time) error
+}
+
+// Manager hands out cookie jars for scrapes and saves them afterwards
+type Manager struct {
+	store   Store
+	aead    cipher.AEAD         // cookies.encryption_key, nil without one
+	keyring *encryption.Keyring // Seals new jars when set
+	domains []string
+	maxAge  time.Duration
+	now     func() time.Time
+}
+
+// New creates a manager from the scraping.cookies settings and the
+// encryption keyring, nil when none is configured. It returns an error if
+// cookies are enabled without a keyring or a valid encryption key; when they
+// are disabled, the manager hands out jars that are never saved.
+func New(store Store, cfg config.CookiesConfig, keyring *encryption.Keyring) (*Manager, error) {
+	m := &Manager{store: store, keyring: keyring, maxAge: cfg.MaxAge, now: time.Now}
+	if !cfg.Enabled {
+		return m, nil
+	}
+
+	if cfg.EncryptionKey != "" || keyring == nil {
+		key, err := base64.StdEncoding.DecodeString(cfg.EncryptionKey)
+		if err != nil || len(key) != 32 {
+			return nil, errors.New("cookies.encryption_key must be a base64-encoded 32-byte key")
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if m.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	for _, domain := range cfg.Domains {
+		m.domains = append(m.domains, strings.ToLower(strings.TrimPrefix(domain, ".")))
+	}
+	if m.maxAge <= 0 {
+		m.maxAge = 30 * 24 * time.Hour
+	}
+	return m, nil
+}
+
+// Domain returns the registrable domain a URL's cookies are kept under
+func Domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+// Jar returns the cookie jar for a scrape of rawURL, holding the cookies
+// saved by earlier scrapes of its domain. Use it as the http.Client's Jar
+// and pass it to Save once the scrape is done.
+func (m *Manager) Jar(ctx context.Context, rawURL string) (*Jar, error) {
+	jar, err := newJar(Domain(rawURL), m.persists(rawURL))
+	if err != nil || !jar.persistent {
+		return jar, err
+	}
+
+	data, ok, err := m.store.LoadJar(ctx, jar.domain)
+	if err != nil || !ok {
+		return jar, err
+	}
+	plain, err := m.open(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cookies of %s: %w", jar.domain, err)
+	}
+	var stored []storedCookie
+	if err := json.Unmarshal(plain, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode cookies of %s: %w", jar.domain, err)
+	}
+	now := m.now()
+	for _, c := range stored {
+		if c.Expires.After(now) {
+			jar.restore(c)
+		}
+	}
+	return jar, nil
+}
+
+// Save stores the cookies of a jar returned by Jar for the next scrape of
+// its domain. Cookies keep their own expiry, capped at max_age; session
+// cookies are kept for max_age. Jars of domains that do not keep cookies
+// are not saved.
+func (m *Manager) Save(ctx context.Context, jar *Jar) error {
+	if !jar.persistent {
+		return nil
+	}
+
+	now := m.now()
+	limit := now.Add(m.maxAge)
+	jar.mu.Lock()
+	stored := make([]storedCookie, 0, len(jar.cookies))
+	var expiresAt time.Time
+	for _, c := range jar.cookies {
+		if c.Expires.IsZero() || c.Expires.After(limit) {
+			c.Expires = limit
+		}
+		if !c.Expires.After(now) {
+			continue
+		}
+		stored = append(stored, c)
+		if c.Expires.After(expiresAt) {
+			expiresAt = c.Expires
+		}
+	}
+	jar.mu.Unlock()
+	if len(stored) == 0 {
+		expiresAt = now // Replaces earlier cookies that are now gone
+	}
+
+	plain, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	data, err := m.seal(plain)
+	if err != nil {
+		return err
+	}
+	return m.store.SaveJar(ctx, jar.domain, data, len(stored), expiresAt)
+}
+
+// persists reports whether cookies of rawURL's domain are kept
+func (m *Manager) persists(rawURL string) bool {
+	if m.aead == nil && m.keyring == nil {
+		return false
+	}
+	if len(m.domains) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range m.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// seal encrypts data in an envelope of the keyring, or with a random nonce,
+// which it prepends
+func (m *Manager) seal(data []byte) ([]byte, error) {
+	if m.keyring != nil {
+		return m.keyring.SealBytes(data)
+	}
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return m.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data sealed by seal
+func (m *Manager) open(data []byte) ([]byte, error) {
+	if env, ok := encryption.Parse(data); ok {
+		return m.keyring.Open(env)
+	}
+	if m.aead == nil {
+		return nil, errors.New("jar was sealed with cookies.encryption_key, which is not configured")
+	}
+	size := m.aead.NonceSize()
+	if len(data) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+	return m.aead.Open(nil, data[:size], data[size:], nil)
+}
+
+// storedCookie is a cookie with the URL it was set from, so it can be
+// replayed into a fresh jar
+type storedCookie struct {
+	URL      string        `json:"url"`
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Path     string        `json:"path,omitempty"`
+	Domain   string        `json:"domain,omitempty"`
+	Expires  time.Time     `json:"expires"`
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"http_only,omitempty"`
+	SameSite http.SameSite `json:"same_site,omitempty"`
+}
+
+// Jar is an http.CookieJar that remembers the cookies set on it so they can
+// be saved, see Manager.Save
+type Jar struct {
+	jar        *cookiejar.Jar
+	domain     string
+	persistent bool
+
+	mu      sync.Mutex
+	cookies map[string]storedCookie // By domain, path, name and host
+}
+
+// newJar creates an empty jar
+func newJar(domain string, persistent bool) (*Jar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	return &Jar{jar: jar, domain: domain, persistent: persistent, cookies: make(map[string]storedCookie)}, nil
+}
+
+// Domain returns the registrable domain the jar's cookies are kept under
+func (j *Jar) Domain() string {
+	return j.domain
+}
+
+// Len returns the number of cookies set on the jar, including restored ones
+func (j *Jar) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.cookies)
+}
+
+// SetCookies implements http.CookieJar
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range cookies {
+		stored := storedCookie{
+			URL:      u.String(),
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: c.SameSite,
+		}
+		if c.MaxAge > 0 {
+			stored.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		key := strings.Join([]string{c.Domain, c.Path, c.Name, u.Hostname()}, "\x00")
+		if c.MaxAge < 0 || !c.Expires.IsZero() && c.Expires.Before(time.Now()) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = stored
+	}
+}
+
+// Cookies implements http.CookieJar
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// restore sets a saved cookie on the jar
+func (j *Jar) restore(c storedCookie) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return
+	}
+	j.SetCookies(u, []*http.Cookie{{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+	}})
+}
@@ -0,0 +1,158 @@
+package cookies
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/encryption"
+)
+
+// memoryStore keeps jars in memory
+type memoryStore struct {
+	jars  map[string][]byte
+	count int
+}
+
+func (m *memoryStore) LoadJar(ctx context.Context, domain string) ([]byte, bool, error) {
+	data, ok := m.jars[domain]
+	return data, ok, nil
+}
+
+func (m *memoryStore) SaveJar(ctx context.Context, domain string, data []byte, count int, expiresAt time.Time) error {
+	m.jars[domain] = data
+	m.count = count
+	return nil
+}
+
+func testConfig(domains ...string) config.CookiesConfig {
+	return config.CookiesConfig{
+		Enabled:       true,
+		EncryptionKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)),
+		Domains:       domains,
+		MaxAge:        time.Hour,
+	}
+}
+
+func TestManagerPersistsEncryptedCookies(t *testing.T) {
+	store := &memoryStore{jars: make(map[string][]byte)}
+	manager, err := New(store, testConfig(), nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	jar, err := manager.Jar(ctx, "https://www.example.co.uk/news")
+	if err != nil {
+		t.Fatalf("Jar() error = %v", err)
+	}
+	u, _ := url.Parse("https://www.example.co.uk/consent")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "consent", Value: "yes", Path: "/"},
+		{Name: "gone", Value: "x", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	})
+	if err := manager.Save(ctx, jar); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, ok := store.jars["example.co.uk"]
+	if !ok || store.count != 1 {
+		t.Fatalf("stored jars = %v, count = %d", store.jars, store.count)
+	}
+	if bytes.Contains(data, []byte("consent")) {
+		t.Error("stored jar is not encrypted")
+	}
+
+	next, err := manager.Jar(ctx, "https://shop.example.co.uk/")
+	if err != nil {
+		t.Fatalf("Jar() error = %v", err)
+	}
+	u, _ = url.Parse("https://www.example.co.uk/news")
+	cookies := next.Cookies(u)
+	if len(cookies) != 1 || cookies[0].Name != "consent" || cookies[0].Value != "yes" {
+		t.Errorf("restored cookies = %v", cookies)
+	}
+}
+
+func TestManagerOnlyPersistsConfiguredDomains(t *testing.T) {
+	store := &memoryStore{jars: make(map[string][]byte)}
+	manager, err := New(store, testConfig("example.com"), nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for rawURL, want := range map[string]bool{
+		"https://example.com/":       true,
+		"https://news.example.com/a": true,
+		"https://notexample.com/":    false,
+		"https://example.org/":       false,
+	} {
+		jar, err := manager.Jar(context.Background(), rawURL)
+		if err != nil {
+			t.Fatalf("Jar(%s) error = %v", rawURL, err)
+		}
+		if jar.persistent != want {
+			t.Errorf("Jar(%s) persistent = %v, want %v", rawURL, jar.persistent, want)
+		}
+	}
+
+	disabled, _ := New(store, config.CookiesConfig{}, nil)
+	if jar, _ := disabled.Jar(context.Background(), "https://example.com/"); jar.persistent {
+		t.Error("disabled manager returned a persistent jar")
+	}
+}
+
+func TestNewRejectsInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := New(nil, config.CookiesConfig{Enabled: true, EncryptionKey: key}, nil); err == nil {
+			t.Errorf("New() accepted key %q", key)
+		}
+	}
+}
+
+func TestManagerMovesJarsToKeyring(t *testing.T) {
+	store := &memoryStore{jars: make(map[string][]byte)}
+	ctx := context.Background()
+	u, _ := url.Parse("https://example.com/")
+
+	legacy, _ := New(store, testConfig(), nil)
+	jar, _ := legacy.Jar(ctx, u.String())
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "abc", Path: "/"}})
+	if err := legacy.Save(ctx, jar); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := encryption.New(config.EncryptionConfig{
+		PrimaryKey: "k1",
+		Keys:       map[string]string{"k1": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, 32))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := New(store, testConfig(), keyring)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	jar, err = manager.Jar(ctx, u.String())
+	if err != nil || len(jar.Cookies(u)) != 1 {
+		t.Fatalf("Jar() of a jar sealed with the encryption key = %v, %v", jar, err)
+	}
+	if err := manager.Save(ctx, jar); err != nil {
+		t.Fatal(err)
+	}
+	if env, ok := encryption.Parse(store.jars["example.com"]); !ok || env.KeyID != "k1" {
+		t.Errorf("saved jar is not an envelope of the keyring: %s", store.jars["example.com"])
+	}
+
+	keyringOnly, err := New(store, config.CookiesConfig{Enabled: true}, keyring)
+	if err != nil {
+		t.Fatalf("New() with a keyring and no encryption key: %v", err)
+	}
+	if jar, err := keyringOnly.Jar(ctx, u.String()); err != nil || len(jar.Cookies(u)) != 1 {
+		t.Errorf("Jar() = %v, %v", jar, err)
+	}
+}
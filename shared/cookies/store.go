@@ -0,0 +1,47 @@
+package cookies
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go_scraping_project/shared/database"
+)
+
+// Querier is the subset of database queries used by DBStore
+type Querier interface {
+	GetCookieJar(ctx context.Context, domain string) (database.CookieJar, error)
+	UpsertCookieJar(ctx context.Context, arg database.UpsertCookieJarParams) error
+}
+
+// DBStore keeps cookie jars in the cookie_jars table
+type DBStore struct {
+	db Querier
+}
+
+// NewDBStore creates a store backed by the given queries
+func NewDBStore(db Querier) *DBStore {
+	return &DBStore{db: db}
+}
+
+// LoadJar returns the domain's jar unless it expired
+func (s *DBStore) LoadJar(ctx context.Context, domain string) ([]byte, bool, error) {
+	jar, err := s.db.GetCookieJar(ctx, domain)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return jar.Data, true, nil
+}
+
+// SaveJar inserts or replaces the domain's jar
+func (s *DBStore) SaveJar(ctx context.Context, domain string, data []byte, count int, expiresAt time.Time) error {
+	return s.db.UpsertCookieJar(ctx, database.UpsertCookieJarParams{
+		Domain:      domain,
+		Data:        data,
+		CookieCount: int32(count),
+		ExpiresAt:   expiresAt,
+	})
+}
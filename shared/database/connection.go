@@ -1,13 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"os"
 	"strconv"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // Connect establishes a connection to the PostgreSQL database
@@ -27,6 +29,11 @@ func Connect() (*sql.DB, error) {
 			user, password, host, port, dbName, sslMode)
 	}
 
+	return ConnectURL(databaseURL, 25, 5)
+}
+
+// ConnectURL establishes a connection to the given database URL with the given pool limits
+func ConnectURL(databaseURL string, maxOpenConns, maxIdleConns int) (*sql.DB, error) {
 	// Open database connection
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
@@ -34,18 +41,72 @@ func Connect() (*sql.DB, error) {
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
+
+// ConnectDynamic establishes a connection pool whose new connections use the
+// URL returned by databaseURL at dial time. Pooled connections are recycled
+// after their maximum lifetime, so rotated credentials take effect without
+// reopening the pool.
+func ConnectDynamic(databaseURL func() string, maxOpenConns, maxIdleConns int) (*sql.DB, error) {
+	db := sql.OpenDB(dynamicConnector{url: databaseURL})
+
+	// Configure connection pool
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return db, nil
 }
 
+// Ping opens a single connection to the given database URL and closes it
+// again, to check that the database accepts connections
+func Ping(ctx context.Context, databaseURL string) error {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// dynamicConnector builds a fresh pq connector for every new connection
+type dynamicConnector struct {
+	url func() string
+}
+
+func (c dynamicConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	connector, err := pq.NewConnector(c.url())
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c dynamicConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
 // ConnectWithConfig establishes a connection using configuration
 func ConnectWithConfig(cfg interface{}) (*sql.DB, error) {
 	// Type assertion to get config methods
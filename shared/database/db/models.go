@@ -6,12 +6,243 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AlertEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Rule      string    `json:"rule"`
+	Kind      string    `json:"kind"`
+	State     string    `json:"state"`
+	Severity  string    `json:"severity"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CandidateParsedDatum struct {
+	ID           uuid.UUID       `json:"id"`
+	UrlID        uuid.UUID       `json:"url_id"`
+	ParsedDataID uuid.UUID       `json:"parsed_data_id"`
+	Title        string          `json:"title"`
+	Content      string          `json:"content"`
+	Metadata     json.RawMessage `json:"metadata"`
+	Data         json.RawMessage `json:"data"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type CookieJar struct {
+	Domain      string    `json:"domain"`
+	Data        []byte    `json:"data"`
+	CookieCount int32     `json:"cookie_count"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DataExportJob struct {
+	ID          uuid.UUID    `json:"id"`
+	Project     string       `json:"project"`
+	Schema      string       `json:"schema"`
+	Format      string       `json:"format"`
+	Schedule    string       `json:"schedule"`
+	FromTime    sql.NullTime `json:"from_time"`
+	ToTime      sql.NullTime `json:"to_time"`
+	Status      string       `json:"status"`
+	RequestedBy string       `json:"requested_by"`
+	RecordCount int64        `json:"record_count"`
+	SizeBytes   int64        `json:"size_bytes"`
+	Artifact    string       `json:"artifact"`
+	Error       string       `json:"error"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	CompletedAt sql.NullTime `json:"completed_at"`
+	ExpiresAt   sql.NullTime `json:"expires_at"`
+}
+
+type DataPurgeJob struct {
+	ID                 uuid.UUID     `json:"id"`
+	UrlID              uuid.NullUUID `json:"url_id"`
+	Schema             string        `json:"schema"`
+	FromTime           sql.NullTime  `json:"from_time"`
+	ToTime             sql.NullTime  `json:"to_time"`
+	Status             string        `json:"status"`
+	RequestedBy        string        `json:"requested_by"`
+	ParsedDataDeleted  int64         `json:"parsed_data_deleted"`
+	RawHtmlDeleted     int64         `json:"raw_html_deleted"`
+	HarCapturesDeleted int64         `json:"har_captures_deleted"`
+	Error              string        `json:"error"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+	CompletedAt        sql.NullTime  `json:"completed_at"`
+}
+
+type DataView struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description sql.NullString  `json:"description"`
+	Schema      string          `json:"schema"`
+	UrlIds      []uuid.UUID     `json:"url_ids"`
+	Predicates  json.RawMessage `json:"predicates"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type DeadLetter struct {
+	ID             uuid.UUID `json:"id"`
+	Topic          string    `json:"topic"`
+	KafkaPartition int32     `json:"kafka_partition"`
+	KafkaOffset    int64     `json:"kafka_offset"`
+	Key            string    `json:"key"`
+	Value          []byte    `json:"value"`
+	MessageType    string    `json:"message_type"`
+	Error          string    `json:"error"`
+	RetryCount     int32     `json:"retry_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+type FeatureFlag struct {
+	Name           string         `json:"name"`
+	Description    sql.NullString `json:"description"`
+	Enabled        bool           `json:"enabled"`
+	RolloutPercent int32          `json:"rollout_percent"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type FeatureFlagOverride struct {
+	FlagName  string    `json:"flag_name"`
+	TenantID  string    `json:"tenant_id"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type HarCapture struct {
+	UrlID       uuid.UUID             `json:"url_id"`
+	TaskID      uuid.NullUUID         `json:"task_id"`
+	Har         pqtype.NullRawMessage `json:"har"`
+	RequestedAt time.Time             `json:"requested_at"`
+	CapturedAt  sql.NullTime          `json:"captured_at"`
+	ExpiresAt   time.Time             `json:"expires_at"`
+}
+
+type MaintenanceMode struct {
+	ID        bool      `json:"id"`
+	Enabled   bool      `json:"enabled"`
+	Message   string    `json:"message"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationChannel struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Settings  json.RawMessage `json:"settings"`
+	Enabled   bool            `json:"enabled"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ParsedDatum struct {
+	ID              uuid.UUID             `json:"id"`
+	UrlID           uuid.UUID             `json:"url_id"`
+	Url             string                `json:"url"`
+	Schema          string                `json:"schema"`
+	Title           string                `json:"title"`
+	Content         string                `json:"content"`
+	Metadata        json.RawMessage       `json:"metadata"`
+	Data            json.RawMessage       `json:"data"`
+	ContentHash     string                `json:"content_hash"`
+	ChangeSeq       int64                 `json:"change_seq"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	NormalizedText  string                `json:"normalized_text"`
+	Simhash         sql.NullInt64         `json:"simhash"`
+	FieldErrors     pqtype.NullRawMessage `json:"field_errors"`
+	EncryptionKeyID string                `json:"encryption_key_id"`
+}
+
+type ParserCandidate struct {
+	UrlID     uuid.UUID       `json:"url_id"`
+	Config    json.RawMessage `json:"config"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ParserConfigVersion struct {
+	UrlID     uuid.UUID             `json:"url_id"`
+	Version   int32                 `json:"version"`
+	Config    pqtype.NullRawMessage `json:"config"`
+	Author    string                `json:"author"`
+	Reason    string                `json:"reason"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+type ParserTemplate struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description sql.NullString  `json:"description"`
+	PageType    string          `json:"page_type"`
+	Config      json.RawMessage `json:"config"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type RawHtmlSnapshot struct {
+	ID          uuid.UUID     `json:"id"`
+	UrlID       uuid.UUID     `json:"url_id"`
+	TaskID      uuid.NullUUID `json:"task_id"`
+	StatusCode  int32         `json:"status_code"`
+	ContentType string        `json:"content_type"`
+	Content     string        `json:"content"`
+	ContentHash string        `json:"content_hash"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type RenderSession struct {
+	SnapshotID uuid.UUID       `json:"snapshot_id"`
+	UrlID      uuid.UUID       `json:"url_id"`
+	Session    json.RawMessage `json:"session"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type ScrapeBudgetEvent struct {
+	Scope           string    `json:"scope"`
+	Name            string    `json:"name"`
+	Day             time.Time `json:"day"`
+	DailyLimit      int32     `json:"daily_limit"`
+	DeferredScrapes int32     `json:"deferred_scrapes"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ScrapingTask struct {
+	ID               uuid.UUID             `json:"id"`
+	UrlID            uuid.UUID             `json:"url_id"`
+	Attempt          int32                 `json:"attempt"`
+	Status           string                `json:"status"`
+	StatusCode       sql.NullInt32         `json:"status_code"`
+	ErrorCode        sql.NullString        `json:"error_code"`
+	ErrorMessage     sql.NullString        `json:"error_message"`
+	DurationMs       sql.NullInt64         `json:"duration_ms"`
+	CreatedAt        time.Time             `json:"created_at"`
+	CompletedAt      sql.NullTime          `json:"completed_at"`
+	ProxyEgressBytes int64                 `json:"proxy_egress_bytes"`
+	RenderMs         int64                 `json:"render_ms"`
+	ThrottledMs      int64                 `json:"throttled_ms"`
+	FinalUrl         sql.NullString        `json:"final_url"`
+	RedirectChain    pqtype.NullRawMessage `json:"redirect_chain"`
+	ResponseHeaders  pqtype.NullRawMessage `json:"response_headers"`
+	DnsMs            int64                 `json:"dns_ms"`
+	DueAt            sql.NullTime          `json:"due_at"`
+}
+
 type Url struct {
 	ID            uuid.UUID             `json:"id"`
 	Url           string                `json:"url"`
@@ -28,4 +259,36 @@ type Url struct {
 	CreatedAt     time.Time             `json:"created_at"`
 	UpdatedAt     time.Time             `json:"updated_at"`
 	DeletedAt     sql.NullTime          `json:"deleted_at"`
+	RetryPolicy   pqtype.NullRawMessage `json:"retry_policy"`
+	Tags          []string              `json:"tags"`
+	Project       string                `json:"project"`
+	Managed       bool                  `json:"managed"`
+	Assertions    pqtype.NullRawMessage `json:"assertions"`
+	Region        string                `json:"region"`
+	ArchivePolicy pqtype.NullRawMessage `json:"archive_policy"`
+}
+
+type UrlMove struct {
+	UrlID       uuid.UUID    `json:"url_id"`
+	FromUrl     string       `json:"from_url"`
+	ToUrl       string       `json:"to_url"`
+	Hits        int32        `json:"hits"`
+	FirstSeenAt time.Time    `json:"first_seen_at"`
+	LastSeenAt  time.Time    `json:"last_seen_at"`
+	FlaggedAt   sql.NullTime `json:"flagged_at"`
+	AppliedAt   sql.NullTime `json:"applied_at"`
+}
+
+type Worker struct {
+	ID              string    `json:"id"`
+	Kind            string    `json:"kind"`
+	Version         string    `json:"version"`
+	Host            string    `json:"host"`
+	StartedAt       time.Time `json:"started_at"`
+	LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+	ActiveTasks     int32     `json:"active_tasks"`
+	Capacity        int32     `json:"capacity"`
+	QueuedTasks     int32     `json:"queued_tasks"`
+	Region          string    `json:"region"`
+	PausedTopics    []string  `json:"paused_topics"`
 }
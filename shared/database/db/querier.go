@@ -6,25 +6,288 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
-	CountURLs(ctx context.Context) (int64, error)
+	// Groups the latest parse of each URL by the value at group_path in its data
+	// and aggregates the values at value_path. Records without the group field
+	// form the group with an empty value. Values that are not JSON numbers are
+	// left out of min, max and avg, which are 0 when value_count is 0. An empty
+	// schema matches every schema.
+	AggregateParsedData(ctx context.Context, arg AggregateParsedDataParams) ([]AggregateParsedDataRow, error)
+	// Moves a URL to the location it redirects to, unless another URL already
+	// has that address
+	ApplyURLMove(ctx context.Context, arg ApplyURLMoveParams) (int64, error)
+	// Marks the oldest pending job as running, or a running job not updated
+	// since stale_before, whose export stopped with its process
+	ClaimDataExportJob(ctx context.Context, staleBefore time.Time) (DataExportJob, error)
+	// Marks the oldest pending job as running, or a running job not updated
+	// since stale_before, whose purge stopped with its process
+	ClaimDataPurgeJob(ctx context.Context, staleBefore time.Time) (DataPurgeJob, error)
+	// Assigns a pending capture request of the URL to the scrape about to be published
+	ClaimHARCapture(ctx context.Context, arg ClaimHARCaptureParams) (int64, error)
+	// Forgets the unflagged moves of a URL other than to_url, once a scrape was
+	// not moved there; an empty to_url forgets all of them
+	ClearPendingURLMoves(ctx context.Context, arg ClearPendingURLMovesParams) error
+	CompleteScrapingTask(ctx context.Context, arg CompleteScrapingTaskParams) error
+	CountAlertEvents(ctx context.Context, arg CountAlertEventsParams) (int64, error)
+	CountDeadLetters(ctx context.Context, arg CountDeadLettersParams) (int64, error)
+	CountParsedDataVersions(ctx context.Context, arg CountParsedDataVersionsParams) (int64, error)
+	CountParserConfigVersions(ctx context.Context, urlID uuid.UUID) (int64, error)
+	CountScrapingTaskFailuresByErrorCode(ctx context.Context, completedAt sql.NullTime) ([]CountScrapingTaskFailuresByErrorCodeRow, error)
+	CountScrapingTaskOutcomes(ctx context.Context, completedAt sql.NullTime) (CountScrapingTaskOutcomesRow, error)
+	// Scrapes of URLs (of one project, when given) that were due from
+	// sqlc.arg(since) until sqlc.arg(until): all of them, those completed, those
+	// completed within max_delay_ms of their due time and those that succeeded.
+	CountScrapingTaskSLI(ctx context.Context, arg CountScrapingTaskSLIParams) (CountScrapingTaskSLIRow, error)
+	// Completed scrapes per HTTP status code (0 for scrapes that got no
+	// response) since sqlc.arg(since), and in the period from
+	// sqlc.arg(previous_since) to since for comparison.
+	CountScrapingTaskStatusCodes(ctx context.Context, arg CountScrapingTaskStatusCodesParams) ([]CountScrapingTaskStatusCodesRow, error)
+	CountScrapingTasks(ctx context.Context, arg CountScrapingTasksParams) (int64, error)
+	// Counts the scrape attempts published since a time for URLs on a host or its subdomains.
+	CountScrapingTasksForDomain(ctx context.Context, arg CountScrapingTasksForDomainParams) (int64, error)
+	// Counts the scrape attempts published since a time for URLs of a project.
+	CountScrapingTasksForProject(ctx context.Context, arg CountScrapingTasksForProjectParams) (int64, error)
+	CountURLMoves(ctx context.Context, state string) (int64, error)
+	CountURLScrapingTasksSince(ctx context.Context, arg CountURLScrapingTasksSinceParams) (int64, error)
+	CountURLs(ctx context.Context, pattern string) (int64, error)
 	CountURLsByStatus(ctx context.Context, status string) (int64, error)
+	// Counts the URLs a bulk delete (deleted = false), restore (deleted = true)
+	// or reset (status = 'failed') would affect. Empty filters match everything.
+	CountURLsForBulkAction(ctx context.Context, arg CountURLsForBulkActionParams) (int64, error)
+	// Counts URLs per host, most used hosts first.
+	CountURLsPerDomain(ctx context.Context, maxResults int32) ([]CountURLsPerDomainRow, error)
+	CountURLsPerProject(ctx context.Context) ([]CountURLsPerProjectRow, error)
+	CountURLsPerStatus(ctx context.Context) ([]CountURLsPerStatusRow, error)
+	CreateAlertEvent(ctx context.Context, arg CreateAlertEventParams) (AlertEvent, error)
+	CreateCandidateParsedData(ctx context.Context, arg CreateCandidateParsedDataParams) (CandidateParsedDatum, error)
+	CreateDataExportJob(ctx context.Context, arg CreateDataExportJobParams) (DataExportJob, error)
+	CreateDataPurgeJob(ctx context.Context, arg CreateDataPurgeJobParams) (DataPurgeJob, error)
+	CreateDataView(ctx context.Context, arg CreateDataViewParams) (DataView, error)
+	// Stores a message that exhausted its retries. A message already stored for
+	// its topic, partition and offset is left as is.
+	CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (int64, error)
+	CreateNotificationChannel(ctx context.Context, arg CreateNotificationChannelParams) (NotificationChannel, error)
+	CreateParsedData(ctx context.Context, arg CreateParsedDataParams) (ParsedDatum, error)
+	CreateParserTemplate(ctx context.Context, arg CreateParserTemplateParams) (ParserTemplate, error)
+	CreateRawHTMLSnapshot(ctx context.Context, arg CreateRawHTMLSnapshotParams) (RawHtmlSnapshot, error)
+	CreateRenderSession(ctx context.Context, arg CreateRenderSessionParams) error
+	CreateScrapingTask(ctx context.Context, arg CreateScrapingTaskParams) (ScrapingTask, error)
 	CreateURL(ctx context.Context, arg CreateURLParams) (Url, error)
+	DeleteCandidateParsedData(ctx context.Context, urlID uuid.UUID) error
+	DeleteCookieJar(ctx context.Context, domain string) (int64, error)
+	DeleteDataView(ctx context.Context, name string) (int64, error)
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteExpiredHARCaptures(ctx context.Context) (int64, error)
+	DeleteFeatureFlag(ctx context.Context, name string) (int64, error)
+	DeleteFeatureFlagOverride(ctx context.Context, arg DeleteFeatureFlagOverrideParams) (int64, error)
+	DeleteHARCapture(ctx context.Context, urlID uuid.UUID) (int64, error)
+	DeleteNotificationChannel(ctx context.Context, name string) (int64, error)
+	// Deletes a URL's candidate along with its parses
+	DeleteParserCandidate(ctx context.Context, urlID uuid.UUID) (int64, error)
+	DeleteParserTemplate(ctx context.Context, name string) (int64, error)
+	// Removes instances whose last heartbeat is older than a time.
+	DeleteStaleWorkers(ctx context.Context, lastHeartbeatAt time.Time) (int64, error)
+	// Removes an instance that shut down.
+	DeleteWorker(ctx context.Context, id string) error
+	// Marks a job whose file was deleted as expired
+	ExpireDataExportJob(ctx context.Context, id uuid.UUID) error
+	// Lists parsed records for an export, oldest first. An empty URL set or
+	// schema matches everything. With changed_since, only records created at or
+	// after it whose content_hash differs from the previous parse of the same
+	// URL under the same schema are listed; a URL's first parse counts as changed.
+	ExportParsedData(ctx context.Context, arg ExportParsedDataParams) ([]ExportParsedDataRow, error)
+	FinishDataExportJob(ctx context.Context, arg FinishDataExportJobParams) error
+	FinishDataPurgeJob(ctx context.Context, arg FinishDataPurgeJobParams) error
+	FlagURLMove(ctx context.Context, arg FlagURLMoveParams) error
+	GetCookieJar(ctx context.Context, domain string) (CookieJar, error)
+	GetDataExportJob(ctx context.Context, id uuid.UUID) (DataExportJob, error)
+	GetDataPurgeJob(ctx context.Context, id uuid.UUID) (DataPurgeJob, error)
+	GetDataView(ctx context.Context, name string) (DataView, error)
+	GetHARCapture(ctx context.Context, urlID uuid.UUID) (HarCapture, error)
+	GetLastScrapingTaskCompletedAt(ctx context.Context) (sql.NullTime, error)
+	GetLatestParserConfigVersion(ctx context.Context, urlID uuid.UUID) (ParserConfigVersion, error)
+	// The URL's most recent snapshot, without its content
+	GetLatestRawHTMLSnapshot(ctx context.Context, urlID uuid.UUID) (GetLatestRawHTMLSnapshotRow, error)
+	// The URL's most recent render session, with the scrape it was recorded for
+	GetLatestRenderSession(ctx context.Context, urlID uuid.UUID) (GetLatestRenderSessionRow, error)
+	GetMaintenanceMode(ctx context.Context) (MaintenanceMode, error)
+	GetNotificationChannel(ctx context.Context, name string) (NotificationChannel, error)
+	GetOverdueURLs(ctx context.Context, arg GetOverdueURLsParams) ([]Url, error)
+	GetParsedData(ctx context.Context, id uuid.UUID) (ParsedDatum, error)
+	// Counts, per field, the latest parses of URLs that extracted the field and
+	// those whose selector or rule failed, with one of the errors. Title and
+	// content count when they are not empty, other fields when they are in the
+	// data. Parses made before field errors were recorded are left out. An
+	// empty schema matches every schema.
+	GetParsedDataFieldCompleteness(ctx context.Context, schema string) ([]GetParsedDataFieldCompletenessRow, error)
+	GetParserCandidate(ctx context.Context, urlID uuid.UUID) (ParserCandidate, error)
+	GetParserConfigVersion(ctx context.Context, arg GetParserConfigVersionParams) (ParserConfigVersion, error)
+	GetParserTemplateByName(ctx context.Context, name string) (ParserTemplate, error)
+	// Gets the parse of a URL under one schema made before the given time,
+	// that is the version preceding a record.
+	GetPreviousParsedData(ctx context.Context, arg GetPreviousParsedDataParams) (ParsedDatum, error)
+	GetScrapingTask(ctx context.Context, id uuid.UUID) (ScrapingTask, error)
 	GetURLByID(ctx context.Context, id uuid.UUID) (Url, error)
 	GetURLsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Url, error)
 	GetURLsByStatus(ctx context.Context, arg GetURLsByStatusParams) ([]Url, error)
 	GetURLsForImmediateScraping(ctx context.Context, arg GetURLsForImmediateScrapingParams) ([]Url, error)
+	// Gets the URLs due for scraping in a time range like
+	// GetURLsScheduledForScraping, interleaving projects so one project's
+	// backlog cannot fill the batch: each project's due URLs are ranked by due
+	// time, and the batch takes the URLs of lowest rank divided by their
+	// project's share. Projects missing from share_projects have a share of 1.
+	GetURLsScheduledFairly(ctx context.Context, arg GetURLsScheduledFairlyParams) ([]Url, error)
 	GetURLsScheduledForScraping(ctx context.Context, arg GetURLsScheduledForScrapingParams) ([]Url, error)
+	GetURLsWithConsecutiveFailures(ctx context.Context, arg GetURLsWithConsecutiveFailuresParams) ([]Url, error)
 	IncrementRetryCount(ctx context.Context, id uuid.UUID) error
+	// Lists alert events, newest first. An empty rule or state matches every
+	// rule or state.
+	ListAlertEvents(ctx context.Context, arg ListAlertEventsParams) ([]AlertEvent, error)
+	// Pairs a URL's candidate parses with the active parses of the same pages,
+	// most recent scrape first
+	ListCandidateComparisons(ctx context.Context, arg ListCandidateComparisonsParams) ([]ListCandidateComparisonsRow, error)
+	// Lists the export jobs of a project, newest first
+	ListDataExportJobs(ctx context.Context, arg ListDataExportJobsParams) ([]DataExportJob, error)
+	ListDataViews(ctx context.Context) ([]DataView, error)
+	// Lists dead letters, most recent failures first. An empty topic or
+	// message_type matches every topic or type, a null failed_before every age.
+	ListDeadLetters(ctx context.Context, arg ListDeadLettersParams) ([]DeadLetter, error)
+	// Lists up to batch_size dead letters, oldest failures first, after the
+	// (failed_at, id) of the last dead letter of the previous batch
+	ListDeadLettersForExport(ctx context.Context, arg ListDeadLettersForExportParams) ([]DeadLetter, error)
+	// Summarizes each host: its URLs and the scrape attempts completed since $1.
+	ListDomainStats(ctx context.Context, completedAt sql.NullTime) ([]ListDomainStatsRow, error)
+	// Lists the completed jobs whose files expired before now
+	ListExpiredDataExportJobs(ctx context.Context, arg ListExpiredDataExportJobsParams) ([]DataExportJob, error)
+	ListFeatureFlagOverrides(ctx context.Context) ([]FeatureFlagOverride, error)
+	ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error)
+	// Lists the regions with a scraper instance that sent a heartbeat since a time.
+	ListHealthyWorkerRegions(ctx context.Context, lastHeartbeatAt time.Time) ([]string, error)
+	ListNotificationChannels(ctx context.Context) ([]NotificationChannel, error)
+	// Lists the records inserted or updated after a change sequence number,
+	// oldest change first. Changes made at or after settled_before are left for
+	// a later call, so a transaction that commits after a later change is not
+	// skipped. An empty schema matches every schema.
+	ListParsedDataChanges(ctx context.Context, arg ListParsedDataChangesParams) ([]ParsedDatum, error)
+	// Lists up to batch_size parsed records of a project's URLs, oldest first,
+	// after the (created_at, id) of the last record of the previous batch
+	ListParsedDataForExport(ctx context.Context, arg ListParsedDataForExportParams) ([]ListParsedDataForExportRow, error)
+	// Lists the latest parse of each URL under the view's schema and URL set
+	// whose data matches the view's filter, a SQL/JSON path predicate (see
+	// models.PredicateFilter). An empty schema or URL set matches everything.
+	ListParsedDataForView(ctx context.Context, arg ListParsedDataForViewParams) ([]ParsedDatum, error)
+	// Lists the simhash of the latest parse of each URL, for duplicate
+	// detection. Latest parses without a simhash are left out. An empty schema
+	// matches every schema.
+	ListParsedDataSimhashes(ctx context.Context, arg ListParsedDataSimhashesParams) ([]ListParsedDataSimhashesRow, error)
+	// Lists records with encrypted fields whose data keys are wrapped by another
+	// key than the primary key, for rewrapping after a key rotation.
+	ListParsedDataToRewrap(ctx context.Context, arg ListParsedDataToRewrapParams) ([]ListParsedDataToRewrapRow, error)
+	// Lists the parses of a URL under one schema, newest first. Each parse of
+	// a URL is kept, so these are the versions of the data extracted from it.
+	ListParsedDataVersions(ctx context.Context, arg ListParsedDataVersionsParams) ([]ParsedDatum, error)
+	// Lists a URL's parser config versions, newest first
+	ListParserConfigVersions(ctx context.Context, arg ListParserConfigVersionsParams) ([]ParserConfigVersion, error)
+	ListParserTemplates(ctx context.Context) ([]ParserTemplate, error)
+	// Sums the costs of the scrapes completed since a time per project, the
+	// projects with the most proxy egress, then render time, first.
+	ListProjectCosts(ctx context.Context, since sql.NullTime) ([]ListProjectCostsRow, error)
+	// Lists a URL's snapshots taken from from_time up to but excluding
+	// to_time, oldest first
+	ListRawHTMLSnapshotsInRange(ctx context.Context, arg ListRawHTMLSnapshotsInRangeParams) ([]RawHtmlSnapshot, error)
+	// Lists scrapes, newest first, of one URL or of every URL when url_id is
+	// NULL. headers matches scrapes whose response headers contain all of its
+	// name/value pairs, header_names those that have all of the named headers.
+	ListScrapingTasks(ctx context.Context, arg ListScrapingTasksParams) ([]ScrapingTask, error)
+	// Sums the costs of the scrapes completed since a time per URL, the URLs
+	// with the most proxy egress, then render time, first.
+	ListURLCosts(ctx context.Context, arg ListURLCostsParams) ([]ListURLCostsRow, error)
+	// Lists flagged moves, most recently seen first. state is 'pending' for
+	// moves not applied to the stored URL, 'applied' for applied ones, or empty
+	// for both.
+	ListURLMoves(ctx context.Context, arg ListURLMovesParams) ([]UrlMove, error)
+	// An empty pattern matches every URL, otherwise it is matched with ILIKE
+	// against the address and tags. URLs are sorted by sort_by (created_at,
+	// next_scrape_at, last_scraped_at, status or url), URLs that were never
+	// scheduled or scraped last.
 	ListURLs(ctx context.Context, arg ListURLsParams) ([]Url, error)
+	ListURLsForExport(ctx context.Context) ([]Url, error)
+	// Lists the registered instances, most recently seen first.
+	ListWorkers(ctx context.Context) ([]Worker, error)
+	// Deletes the dead letters of a topic and/or that failed before
+	// failed_before; an empty topic matches every topic, a null failed_before
+	// every age
+	PurgeDeadLetters(ctx context.Context, arg PurgeDeadLettersParams) (int64, error)
+	// Deletes the HAR captures matching a purge filter, by when they were
+	// requested. A schema matches as for PurgeRawHTMLSnapshots.
+	PurgeHARCaptures(ctx context.Context, arg PurgeHARCapturesParams) (int64, error)
+	// Deletes up to batch_size parsed records matching a purge filter, and with
+	// them the candidate parses made next to them
+	PurgeParsedData(ctx context.Context, arg PurgeParsedDataParams) (int64, error)
+	// Deletes up to batch_size raw HTML snapshots matching a purge filter, and
+	// with them their render sessions. A schema matches the URLs that have
+	// parsed data of the schema, so snapshots must be purged before parsed data.
+	PurgeRawHTMLSnapshots(ctx context.Context, arg PurgeRawHTMLSnapshotsParams) (int64, error)
+	// Records the records written so far to a job's file
+	RecordDataExportProgress(ctx context.Context, arg RecordDataExportProgressParams) error
+	// Adds the rows deleted by a batch to a job's counters
+	RecordDataPurgeProgress(ctx context.Context, arg RecordDataPurgeProgressParams) error
+	// Records a URL's parser config as its next version, unless it equals the
+	// latest version. Nothing is recorded for a URL that never had a config.
+	RecordParserConfigVersion(ctx context.Context, arg RecordParserConfigVersionParams) (int64, error)
+	// Records a scrape deferred because a daily budget was used up. There is one
+	// event per budget and day; reports whether this was its first deferral.
+	RecordScrapeBudgetExhausted(ctx context.Context, arg RecordScrapeBudgetExhaustedParams) (bool, error)
+	// Records a permanent redirect seen when scraping a URL, counting the
+	// scrapes in a row that were moved to the same location
+	RecordURLMove(ctx context.Context, arg RecordURLMoveParams) (UrlMove, error)
+	// Registers an instance or refreshes its heartbeat and load.
+	RecordWorkerHeartbeat(ctx context.Context, arg RecordWorkerHeartbeatParams) error
+	// Returns a claimed capture request to pending when its scrape was not published
+	ReleaseHARCapture(ctx context.Context, arg ReleaseHARCaptureParams) error
+	// Requests a HAR capture of the URL's next scrape, replacing any earlier capture
+	RequestHARCapture(ctx context.Context, arg RequestHARCaptureParams) (HarCapture, error)
+	// Moves failed URLs back to pending with a fresh retry budget and schedules
+	// them right away. Returns the URLs that were reset.
+	ResetFailedURLs(ctx context.Context, arg ResetFailedURLsParams) ([]ResetFailedURLsRow, error)
 	ResetRetryCount(ctx context.Context, id uuid.UUID) error
+	RestoreURLs(ctx context.Context, arg RestoreURLsParams) ([]RestoreURLsRow, error)
+	// Stores the HAR of the scrape a capture request was claimed for
+	SaveHARCapture(ctx context.Context, arg SaveHARCaptureParams) (int64, error)
+	// Creates the next job of an export schedule unless one was created after
+	// due_after. The job exports the records created since the end of the
+	// schedule's previous job, or since due_after for its first job, until now.
+	ScheduleDataExportJob(ctx context.Context, arg ScheduleDataExportJobParams) (int64, error)
+	SetMaintenanceMode(ctx context.Context, arg SetMaintenanceModeParams) (MaintenanceMode, error)
+	SoftDeleteURLs(ctx context.Context, arg SoftDeleteURLsParams) ([]SoftDeleteURLsRow, error)
+	// Sets the status of a URL if its current status is one of from_statuses and
+	// returns the status it had. No row is returned if the URL is missing or in
+	// another status.
+	TransitionURLStatus(ctx context.Context, arg TransitionURLStatusParams) (string, error)
+	UpdateDataView(ctx context.Context, arg UpdateDataViewParams) (DataView, error)
 	UpdateLastScrapedTime(ctx context.Context, arg UpdateLastScrapedTimeParams) error
 	UpdateNextScrapeTime(ctx context.Context, arg UpdateNextScrapeTimeParams) error
+	UpdateNotificationChannel(ctx context.Context, arg UpdateNotificationChannelParams) (NotificationChannel, error)
+	// Replaces the data of a record whose encrypted fields were rewrapped. The
+	// record's change_seq moves on, so incremental readers see it again.
+	UpdateParsedDataEncryption(ctx context.Context, arg UpdateParsedDataEncryptionParams) error
+	UpdateParserTemplate(ctx context.Context, arg UpdateParserTemplateParams) (ParserTemplate, error)
+	UpdateURLParserConfig(ctx context.Context, arg UpdateURLParserConfigParams) error
 	UpdateURLStatus(ctx context.Context, arg UpdateURLStatusParams) error
+	UpsertCookieJar(ctx context.Context, arg UpsertCookieJarParams) error
+	UpsertFeatureFlag(ctx context.Context, arg UpsertFeatureFlagParams) (FeatureFlag, error)
+	UpsertFeatureFlagOverride(ctx context.Context, arg UpsertFeatureFlagOverrideParams) (FeatureFlagOverride, error)
+	UpsertParserCandidate(ctx context.Context, arg UpsertParserCandidateParams) (ParserCandidate, error)
+	// Creates a URL or replaces the configuration of the URL with the same
+	// address, restoring it if it was deleted. Status and schedule are kept, and
+	// a URL managed by configuration sync stays managed.
+	UpsertURL(ctx context.Context, arg UpsertURLParams) (UpsertURLRow, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,8 +14,10 @@ type Querier interface {
 	// URL operations
 	GetURLByID(ctx context.Context, id uuid.UUID) (Url, error)
 	GetURLsScheduledForScraping(ctx context.Context, arg GetURLsScheduledForScrapingParams) ([]Url, error)
+	GetURLsScheduledFairly(ctx context.Context, arg GetURLsScheduledFairlyParams) ([]Url, error)
 	GetURLsByStatus(ctx context.Context, arg GetURLsByStatusParams) ([]Url, error)
 	UpdateURLStatus(ctx context.Context, arg UpdateURLStatusParams) error
+	TransitionURLStatus(ctx context.Context, arg TransitionURLStatusParams) (string, error)
 	UpdateNextScrapeTime(ctx context.Context, arg UpdateNextScrapeTimeParams) error
 	UpdateLastScrapedTime(ctx context.Context, arg UpdateLastScrapedTimeParams) error
 	IncrementRetryCount(ctx context.Context, id uuid.UUID) error
@@ -21,4 +25,102 @@ type Querier interface {
 	GetURLsForImmediateScraping(ctx context.Context, arg GetURLsForImmediateScrapingParams) ([]Url, error)
 	CountURLsByStatus(ctx context.Context, status string) (int64, error)
 	GetURLsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Url, error)
+	GetOverdueURLs(ctx context.Context, arg GetOverdueURLsParams) ([]Url, error)
+	GetURLsWithConsecutiveFailures(ctx context.Context, arg GetURLsWithConsecutiveFailuresParams) ([]Url, error)
+	ListURLsForExport(ctx context.Context) ([]Url, error)
+	UpsertURL(ctx context.Context, arg UpsertURLParams) (UpsertURLRow, error)
+	SoftDeleteURLs(ctx context.Context, arg SoftDeleteURLsParams) ([]SoftDeleteURLsRow, error)
+	RecordParserConfigVersion(ctx context.Context, arg RecordParserConfigVersionParams) (int64, error)
+	CountURLsForBulkAction(ctx context.Context, arg CountURLsForBulkActionParams) (int64, error)
+	RestoreURLs(ctx context.Context, arg RestoreURLsParams) ([]RestoreURLsRow, error)
+	ResetFailedURLs(ctx context.Context, arg ResetFailedURLsParams) ([]ResetFailedURLsRow, error)
+	UpdateURLParserConfig(ctx context.Context, arg UpdateURLParserConfigParams) error
+
+	// URL listing operations, used by the API Gateway
+	CreateURL(ctx context.Context, arg CreateURLParams) (Url, error)
+	ListURLs(ctx context.Context, arg ListURLsParams) ([]Url, error)
+	CountURLs(ctx context.Context, pattern string) (int64, error)
+	CountURLsPerStatus(ctx context.Context) ([]CountURLsPerStatusRow, error)
+	CountURLsPerDomain(ctx context.Context, maxResults int32) ([]CountURLsPerDomainRow, error)
+	CountURLsPerProject(ctx context.Context) ([]CountURLsPerProjectRow, error)
+
+	// Parser config operations
+	GetParserTemplateByName(ctx context.Context, name string) (ParserTemplate, error)
+	ListParserConfigVersions(ctx context.Context, arg ListParserConfigVersionsParams) ([]ParserConfigVersion, error)
+	CountParserConfigVersions(ctx context.Context, urlID uuid.UUID) (int64, error)
+	GetParserConfigVersion(ctx context.Context, arg GetParserConfigVersionParams) (ParserConfigVersion, error)
+	GetLatestParserConfigVersion(ctx context.Context, urlID uuid.UUID) (ParserConfigVersion, error)
+	GetParserCandidate(ctx context.Context, urlID uuid.UUID) (ParserCandidate, error)
+	UpsertParserCandidate(ctx context.Context, arg UpsertParserCandidateParams) (ParserCandidate, error)
+	DeleteParserCandidate(ctx context.Context, urlID uuid.UUID) (int64, error)
+	ListCandidateComparisons(ctx context.Context, arg ListCandidateComparisonsParams) ([]ListCandidateComparisonsRow, error)
+
+	// Reparse operations
+	ListRawHTMLSnapshotsInRange(ctx context.Context, arg ListRawHTMLSnapshotsInRangeParams) ([]RawHtmlSnapshot, error)
+	CreateParsedData(ctx context.Context, arg CreateParsedDataParams) (ParsedDatum, error)
+	CreateCandidateParsedData(ctx context.Context, arg CreateCandidateParsedDataParams) (CandidateParsedDatum, error)
+	DeleteCandidateParsedData(ctx context.Context, urlID uuid.UUID) error
+
+	// URL move operations
+	RecordURLMove(ctx context.Context, arg RecordURLMoveParams) (UrlMove, error)
+	ClearPendingURLMoves(ctx context.Context, arg ClearPendingURLMovesParams) error
+	FlagURLMove(ctx context.Context, arg FlagURLMoveParams) error
+	ApplyURLMove(ctx context.Context, arg ApplyURLMoveParams) (int64, error)
+	ListURLMoves(ctx context.Context, arg ListURLMovesParams) ([]UrlMove, error)
+	CountURLMoves(ctx context.Context, state string) (int64, error)
+
+	// HAR capture operations
+	ClaimHARCapture(ctx context.Context, arg ClaimHARCaptureParams) (int64, error)
+	ReleaseHARCapture(ctx context.Context, arg ReleaseHARCaptureParams) error
+	SaveHARCapture(ctx context.Context, arg SaveHARCaptureParams) (int64, error)
+	DeleteExpiredHARCaptures(ctx context.Context) (int64, error)
+	RequestHARCapture(ctx context.Context, arg RequestHARCaptureParams) (HarCapture, error)
+	GetHARCapture(ctx context.Context, urlID uuid.UUID) (HarCapture, error)
+	DeleteHARCapture(ctx context.Context, urlID uuid.UUID) (int64, error)
+	GetLatestRenderSession(ctx context.Context, urlID uuid.UUID) (GetLatestRenderSessionRow, error)
+
+	// Scraping task operations
+	CreateScrapingTask(ctx context.Context, arg CreateScrapingTaskParams) (ScrapingTask, error)
+	GetScrapingTask(ctx context.Context, id uuid.UUID) (ScrapingTask, error)
+	CompleteScrapingTask(ctx context.Context, arg CompleteScrapingTaskParams) error
+	ListScrapingTasks(ctx context.Context, arg ListScrapingTasksParams) ([]ScrapingTask, error)
+	CountScrapingTasks(ctx context.Context, arg CountScrapingTasksParams) (int64, error)
+
+	// Scrape budget operations
+	CountScrapingTasksForDomain(ctx context.Context, arg CountScrapingTasksForDomainParams) (int64, error)
+	CountScrapingTasksForProject(ctx context.Context, arg CountScrapingTasksForProjectParams) (int64, error)
+	RecordScrapeBudgetExhausted(ctx context.Context, arg RecordScrapeBudgetExhaustedParams) (bool, error)
+
+	// Worker operations
+	ListHealthyWorkerRegions(ctx context.Context, lastHeartbeatAt time.Time) ([]string, error)
+
+	// Data purge operations
+	ClaimDataPurgeJob(ctx context.Context, staleBefore time.Time) (DataPurgeJob, error)
+	RecordDataPurgeProgress(ctx context.Context, arg RecordDataPurgeProgressParams) error
+	FinishDataPurgeJob(ctx context.Context, arg FinishDataPurgeJobParams) error
+	PurgeRawHTMLSnapshots(ctx context.Context, arg PurgeRawHTMLSnapshotsParams) (int64, error)
+	PurgeHARCaptures(ctx context.Context, arg PurgeHARCapturesParams) (int64, error)
+	PurgeParsedData(ctx context.Context, arg PurgeParsedDataParams) (int64, error)
+
+	// Data export operations
+	ScheduleDataExportJob(ctx context.Context, arg ScheduleDataExportJobParams) (int64, error)
+	ClaimDataExportJob(ctx context.Context, staleBefore time.Time) (DataExportJob, error)
+	ListParsedDataForExport(ctx context.Context, arg ListParsedDataForExportParams) ([]ListParsedDataForExportRow, error)
+	RecordDataExportProgress(ctx context.Context, arg RecordDataExportProgressParams) error
+	FinishDataExportJob(ctx context.Context, arg FinishDataExportJobParams) error
+	ListExpiredDataExportJobs(ctx context.Context, arg ListExpiredDataExportJobsParams) ([]DataExportJob, error)
+	ExpireDataExportJob(ctx context.Context, id uuid.UUID) error
+
+	// Alert operations
+	CountScrapingTaskOutcomes(ctx context.Context, completedAt sql.NullTime) (CountScrapingTaskOutcomesRow, error)
+	GetLastScrapingTaskCompletedAt(ctx context.Context) (sql.NullTime, error)
+	CreateAlertEvent(ctx context.Context, arg CreateAlertEventParams) (AlertEvent, error)
+
+	// Dead letter operations
+	CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (int64, error)
+	CountDeadLetters(ctx context.Context, arg CountDeadLettersParams) (int64, error)
+	ListDeadLetters(ctx context.Context, arg ListDeadLettersParams) ([]DeadLetter, error)
+	ListDeadLettersForExport(ctx context.Context, arg ListDeadLettersForExportParams) ([]DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) (int64, error)
+	PurgeDeadLetters(ctx context.Context, arg PurgeDeadLettersParams) (int64, error)
 }
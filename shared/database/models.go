@@ -6,12 +6,243 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AlertEvent struct {
+	ID        uuid.UUID
+	Rule      string
+	Kind      string
+	State     string
+	Severity  string
+	Value     float64
+	Threshold float64
+	Message   string
+	CreatedAt time.Time
+}
+
+type CandidateParsedDatum struct {
+	ID           uuid.UUID
+	UrlID        uuid.UUID
+	ParsedDataID uuid.UUID
+	Title        string
+	Content      string
+	Metadata     json.RawMessage
+	Data         json.RawMessage
+	CreatedAt    time.Time
+}
+
+type CookieJar struct {
+	Domain      string
+	Data        []byte
+	CookieCount int32
+	ExpiresAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type DataExportJob struct {
+	ID          uuid.UUID
+	Project     string
+	Schema      string
+	Format      string
+	Schedule    string
+	FromTime    sql.NullTime
+	ToTime      sql.NullTime
+	Status      string
+	RequestedBy string
+	RecordCount int64
+	SizeBytes   int64
+	Artifact    string
+	Error       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt sql.NullTime
+	ExpiresAt   sql.NullTime
+}
+
+type DataPurgeJob struct {
+	ID                 uuid.UUID
+	UrlID              uuid.NullUUID
+	Schema             string
+	FromTime           sql.NullTime
+	ToTime             sql.NullTime
+	Status             string
+	RequestedBy        string
+	ParsedDataDeleted  int64
+	RawHtmlDeleted     int64
+	HarCapturesDeleted int64
+	Error              string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	CompletedAt        sql.NullTime
+}
+
+type DataView struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	Schema      string
+	UrlIds      []uuid.UUID
+	Predicates  json.RawMessage
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type DeadLetter struct {
+	ID             uuid.UUID
+	Topic          string
+	KafkaPartition int32
+	KafkaOffset    int64
+	Key            string
+	Value          []byte
+	MessageType    string
+	Error          string
+	RetryCount     int32
+	CreatedAt      time.Time
+	FailedAt       time.Time
+}
+
+type FeatureFlag struct {
+	Name           string
+	Description    sql.NullString
+	Enabled        bool
+	RolloutPercent int32
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type FeatureFlagOverride struct {
+	FlagName  string
+	TenantID  string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type HarCapture struct {
+	UrlID       uuid.UUID
+	TaskID      uuid.NullUUID
+	Har         pqtype.NullRawMessage
+	RequestedAt time.Time
+	CapturedAt  sql.NullTime
+	ExpiresAt   time.Time
+}
+
+type MaintenanceMode struct {
+	ID        bool
+	Enabled   bool
+	Message   string
+	UpdatedAt time.Time
+}
+
+type NotificationChannel struct {
+	ID        uuid.UUID
+	Name      string
+	Type      string
+	Settings  json.RawMessage
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ParsedDatum struct {
+	ID              uuid.UUID
+	UrlID           uuid.UUID
+	Url             string
+	Schema          string
+	Title           string
+	Content         string
+	Metadata        json.RawMessage
+	Data            json.RawMessage
+	ContentHash     string
+	ChangeSeq       int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	NormalizedText  string
+	Simhash         sql.NullInt64
+	FieldErrors     pqtype.NullRawMessage
+	EncryptionKeyID string
+}
+
+type ParserCandidate struct {
+	UrlID     uuid.UUID
+	Config    json.RawMessage
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ParserConfigVersion struct {
+	UrlID     uuid.UUID
+	Version   int32
+	Config    pqtype.NullRawMessage
+	Author    string
+	Reason    string
+	CreatedAt time.Time
+}
+
+type ParserTemplate struct {
+	ID          uuid.UUID
+	Name        string
+	Description sql.NullString
+	PageType    string
+	Config      json.RawMessage
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RawHtmlSnapshot struct {
+	ID          uuid.UUID
+	UrlID       uuid.UUID
+	TaskID      uuid.NullUUID
+	StatusCode  int32
+	ContentType string
+	Content     string
+	ContentHash string
+	CreatedAt   time.Time
+}
+
+type RenderSession struct {
+	SnapshotID uuid.UUID
+	UrlID      uuid.UUID
+	Session    json.RawMessage
+	CreatedAt  time.Time
+}
+
+type ScrapeBudgetEvent struct {
+	Scope           string
+	Name            string
+	Day             time.Time
+	DailyLimit      int32
+	DeferredScrapes int32
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type ScrapingTask struct {
+	ID               uuid.UUID
+	UrlID            uuid.UUID
+	Attempt          int32
+	Status           string
+	StatusCode       sql.NullInt32
+	ErrorCode        sql.NullString
+	ErrorMessage     sql.NullString
+	DurationMs       sql.NullInt64
+	CreatedAt        time.Time
+	CompletedAt      sql.NullTime
+	ProxyEgressBytes int64
+	RenderMs         int64
+	ThrottledMs      int64
+	FinalUrl         sql.NullString
+	RedirectChain    pqtype.NullRawMessage
+	ResponseHeaders  pqtype.NullRawMessage
+	DnsMs            int64
+	DueAt            sql.NullTime
+}
+
 type Url struct {
 	ID            uuid.UUID
 	Url           string
@@ -28,4 +259,36 @@ type Url struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     sql.NullTime
+	RetryPolicy   pqtype.NullRawMessage
+	Tags          []string
+	Project       string
+	Managed       bool
+	Assertions    pqtype.NullRawMessage
+	Region        string
+	ArchivePolicy pqtype.NullRawMessage
+}
+
+type UrlMove struct {
+	UrlID       uuid.UUID
+	FromUrl     string
+	ToUrl       string
+	Hits        int32
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+	FlaggedAt   sql.NullTime
+	AppliedAt   sql.NullTime
+}
+
+type Worker struct {
+	ID              string
+	Kind            string
+	Version         string
+	Host            string
+	StartedAt       time.Time
+	LastHeartbeatAt time.Time
+	ActiveTasks     int32
+	Capacity        int32
+	QueuedTasks     int32
+	Region          string
+	PausedTopics    []string
 }
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// PoolLimits are the tunable limits of a connection pool. Zero MaxOpenConns
+// means no limit, zero ConnMaxLifetime and ConnMaxIdleTime keep connections
+// forever. In JSON the durations are in milliseconds.
+type PoolLimits struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// poolLimitsJSON is the JSON form of PoolLimits and PoolLimitsUpdate
+type poolLimitsJSON struct {
+	MaxOpenConns      *int   `json:"max_open_conns,omitempty"`
+	MaxIdleConns      *int   `json:"max_idle_conns,omitempty"`
+	ConnMaxLifetimeMS *int64 `json:"conn_max_lifetime_ms,omitempty"`
+	ConnMaxIdleTimeMS *int64 `json:"conn_max_idle_time_ms,omitempty"`
+}
+
+// MarshalJSON encodes the limits with their durations in milliseconds
+func (l PoolLimits) MarshalJSON() ([]byte, error) {
+	lifetime, idleTime := l.ConnMaxLifetime.Milliseconds(), l.ConnMaxIdleTime.Milliseconds()
+	return json.Marshal(poolLimitsJSON{
+		MaxOpenConns:      &l.MaxOpenConns,
+		MaxIdleConns:      &l.MaxIdleConns,
+		ConnMaxLifetimeMS: &lifetime,
+		ConnMaxIdleTimeMS: &idleTime,
+	})
+}
+
+// PoolLimitsUpdate changes some limits of a pool, leaving the limits it does
+// not set unchanged. It decodes from the JSON form of PoolLimits.
+type PoolLimitsUpdate struct {
+	limits poolLimitsJSON
+}
+
+// UnmarshalJSON decodes the limits to change
+func (u *PoolLimitsUpdate) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &u.limits)
+}
+
+// Apply returns limits with the changes of the update
+func (u PoolLimitsUpdate) Apply(limits PoolLimits) PoolLimits {
+	if u.limits.MaxOpenConns != nil {
+		limits.MaxOpenConns = *u.limits.MaxOpenConns
+	}
+	if u.limits.MaxIdleConns != nil {
+		limits.MaxIdleConns = *u.limits.MaxIdleConns
+	}
+	if u.limits.ConnMaxLifetimeMS != nil {
+		limits.ConnMaxLifetime = time.Duration(*u.limits.ConnMaxLifetimeMS) * time.Millisecond
+	}
+	if u.limits.ConnMaxIdleTimeMS != nil {
+		limits.ConnMaxIdleTime = time.Duration(*u.limits.ConnMaxIdleTimeMS) * time.Millisecond
+	}
+	return limits
+}
+
+// Validate checks that the limits are not negative and that no more
+// connections are kept idle than may be open
+func (l PoolLimits) Validate() error {
+	switch {
+	case l.MaxOpenConns < 0:
+		return fmt.Errorf("max_open_conns must not be negative")
+	case l.MaxIdleConns < 0:
+		return fmt.Errorf("max_idle_conns must not be negative")
+	case l.MaxOpenConns > 0 && l.MaxIdleConns > l.MaxOpenConns:
+		return fmt.Errorf("max_idle_conns must not exceed max_open_conns")
+	case l.ConnMaxLifetime < 0 || l.ConnMaxIdleTime < 0:
+		return fmt.Errorf("conn_max_lifetime_ms and conn_max_idle_time_ms must not be negative")
+	}
+	return nil
+}
+
+// PoolStats is a snapshot of a connection pool: its limits and the
+// statistics of sql.DBStats. Waits are requests for a connection that found
+// every connection in use, the first sign of pool exhaustion.
+type PoolStats struct {
+	Limits            PoolLimits `json:"limits"`
+	OpenConnections   int        `json:"open_connections"`
+	InUse             int        `json:"in_use"`
+	Idle              int        `json:"idle"`
+	WaitCount         int64      `json:"wait_count"`
+	WaitDurationMS    int64      `json:"wait_duration_ms"`
+	MaxIdleClosed     int64      `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64      `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64      `json:"max_lifetime_closed"`
+}
+
+// Pool reports the statistics of a database connection pool and changes its
+// limits at runtime
+type Pool struct {
+	db *sql.DB
+
+	mu     sync.Mutex
+	limits PoolLimits
+}
+
+// NewPool applies limits to the connection pool of db
+func NewPool(db *sql.DB, limits PoolLimits) *Pool {
+	p := &Pool{db: db}
+	p.apply(limits)
+	return p
+}
+
+// Limits returns the current limits of the pool
+func (p *Pool) Limits() PoolLimits {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limits
+}
+
+// SetLimits changes the limits of the pool. Open connections beyond the new
+// limits are closed once they are returned to the pool.
+func (p *Pool) SetLimits(limits PoolLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	p.apply(limits)
+	return nil
+}
+
+func (p *Pool) apply(limits PoolLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.db.SetMaxOpenConns(limits.MaxOpenConns)
+	p.db.SetMaxIdleConns(limits.MaxIdleConns)
+	p.db.SetConnMaxLifetime(limits.ConnMaxLifetime)
+	p.db.SetConnMaxIdleTime(limits.ConnMaxIdleTime)
+	p.limits = limits
+}
+
+// Stats returns a snapshot of the pool
+func (p *Pool) Stats() PoolStats {
+	stats := p.db.Stats()
+	return PoolStats{
+		Limits:            p.Limits(),
+		OpenConnections:   stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDurationMS:    stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+}
+
+// WritePrometheus writes the pool statistics in the Prometheus text format
+func (p *Pool) WritePrometheus(w io.Writer) {
+	stats := p.db.Stats()
+	limits := p.Limits()
+
+	gauges := []struct {
+		name, help string
+		value      int
+	}{
+		{"db_pool_max_open_connections", "Maximum number of open connections, 0 for no limit.", limits.MaxOpenConns},
+		{"db_pool_max_idle_connections", "Maximum number of idle connections kept.", limits.MaxIdleConns},
+		{"db_pool_open_connections", "Open connections, in use and idle.", stats.OpenConnections},
+		{"db_pool_in_use_connections", "Connections in use.", stats.InUse},
+		{"db_pool_idle_connections", "Idle connections.", stats.Idle},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.value)
+	}
+
+	counters := []struct {
+		name, help string
+		value      int64
+	}{
+		{"db_pool_wait_count_total", "Connection requests that waited for a connection.", stats.WaitCount},
+		{"db_pool_max_idle_closed_total", "Connections closed because of max_idle_conns.", stats.MaxIdleClosed},
+		{"db_pool_max_idle_time_closed_total", "Connections closed because of conn_max_idle_time.", stats.MaxIdleTimeClosed},
+		{"db_pool_max_lifetime_closed_total", "Connections closed because of conn_max_lifetime.", stats.MaxLifetimeClosed},
+	}
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.value)
+	}
+	fmt.Fprintf(w, "# HELP db_pool_wait_duration_seconds_total Time spent waiting for a connection.\n# TYPE db_pool_wait_duration_seconds_total counter\ndb_pool_wait_duration_seconds_total %g\n", stats.WaitDuration.Seconds())
+}
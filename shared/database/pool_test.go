@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+func TestPool(t *testing.T) {
+	// Opening does not connect, so no database is needed
+	db, err := sql.Open("postgres", "postgres://localhost/scraping_db?sslmode=disable")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	pool := NewPool(db, PoolLimits{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute})
+	if stats := pool.Stats(); stats.Limits.MaxOpenConns != 25 || stats.OpenConnections != 0 {
+		t.Errorf("Stats() = %+v, want 25 max open and no open connections", stats)
+	}
+	if got := db.Stats().MaxOpenConnections; got != 25 {
+		t.Errorf("MaxOpenConnections = %d, want 25", got)
+	}
+
+	if err := pool.SetLimits(PoolLimits{MaxOpenConns: 5, MaxIdleConns: 10}); err == nil {
+		t.Error("SetLimits() accepted more idle than open connections")
+	}
+	if err := pool.SetLimits(PoolLimits{MaxOpenConns: 50, MaxIdleConns: 10}); err != nil {
+		t.Fatalf("SetLimits() error = %v", err)
+	}
+	if got := db.Stats().MaxOpenConnections; got != 50 {
+		t.Errorf("MaxOpenConnections after SetLimits = %d, want 50", got)
+	}
+
+	var out strings.Builder
+	pool.WritePrometheus(&out)
+	for _, want := range []string{"db_pool_max_open_connections 50\n", "db_pool_in_use_connections 0\n", "db_pool_wait_count_total 0\n", "db_pool_wait_duration_seconds_total 0\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WritePrometheus() missing %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPoolLimitsUpdate(t *testing.T) {
+	limits := PoolLimits{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute}
+
+	data, err := json.Marshal(limits)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"max_open_conns":25,"max_idle_conns":5,"conn_max_lifetime_ms":300000,"conn_max_idle_time_ms":0}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var update PoolLimitsUpdate
+	if err := json.Unmarshal([]byte(`{"max_open_conns":50,"conn_max_idle_time_ms":60000}`), &update); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := PoolLimits{MaxOpenConns: 50, MaxIdleConns: 5, ConnMaxLifetime: 5 * time.Minute, ConnMaxIdleTime: time.Minute}
+	if got := update.Apply(limits); got != want {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+}
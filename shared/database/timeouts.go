@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryTimeouts bounds the queries of repositories. Queries time out after
+// Default unless PerQuery has a timeout for their sqlc query name; a zero
+// timeout does not bound them.
+type QueryTimeouts struct {
+	Default  time.Duration
+	PerQuery map[string]time.Duration // By query name, matched case-insensitively
+}
+
+// NewQueryTimeouts creates query timeouts with the given default and
+// per-query overrides
+func NewQueryTimeouts(defaultTimeout time.Duration, perQuery map[string]time.Duration) QueryTimeouts {
+	timeouts := QueryTimeouts{Default: defaultTimeout, PerQuery: make(map[string]time.Duration, len(perQuery))}
+	for name, timeout := range perQuery {
+		// Configuration keys are lowercased when loaded
+		timeouts.PerQuery[strings.ToLower(name)] = timeout
+	}
+	return timeouts
+}
+
+// For returns the timeout of a query
+func (t QueryTimeouts) For(query string) time.Duration {
+	if timeout, ok := t.PerQuery[strings.ToLower(query)]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// Context returns a context for running a query, done when the query's
+// timeout expires
+func (t QueryTimeouts) Context(ctx context.Context, query string) (context.Context, context.CancelFunc) {
+	if timeout := t.For(query); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WithStatementTimeout sets the statement_timeout of the connections opened
+// with databaseURL, a URL or key=value connection string, so the server
+// cancels statements running longer than timeout. A statement_timeout set in
+// databaseURL is kept, and a zero timeout sets none.
+func WithStatementTimeout(databaseURL string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return databaseURL
+	}
+	milliseconds := strconv.FormatInt(timeout.Milliseconds(), 10)
+
+	u, err := url.Parse(databaseURL)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		if strings.Contains(databaseURL, "statement_timeout=") {
+			return databaseURL
+		}
+		return databaseURL + " statement_timeout=" + milliseconds
+	}
+	query := u.Query()
+	if query.Has("statement_timeout") {
+		return databaseURL
+	}
+	query.Set("statement_timeout", milliseconds)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
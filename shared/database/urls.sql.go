@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -16,10 +17,12 @@ import (
 
 const countURLs = `-- name: CountURLs :one
 SELECT COUNT(*) FROM urls
+WHERE deleted_at IS NULL
+AND ($1::text = '' OR url_search_text(url, tags) ILIKE $1::text)
 `
 
-func (q *Queries) CountURLs(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countURLs)
+func (q *Queries) CountURLs(ctx context.Context, pattern string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countURLs, pattern)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -36,25 +39,176 @@ func (q *Queries) CountURLsByStatus(ctx context.Context, status string) (int64,
 	return count, err
 }
 
+const countURLsForBulkAction = `-- name: CountURLsForBulkAction :one
+SELECT COUNT(*) FROM urls
+WHERE (deleted_at IS NOT NULL) = $1::bool
+AND (cardinality($2::uuid[]) = 0 OR id = ANY($2::uuid[]))
+AND ($3::text = '' OR $3::text = ANY(tags))
+AND ($4::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower($4::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower($4::text))
+AND ($5::text = '' OR status = $5::text)
+`
+
+type CountURLsForBulkActionParams struct {
+	Deleted bool
+	Ids     []uuid.UUID
+	Tag     string
+	Domain  string
+	Status  string
+}
+
+// Counts the URLs a bulk delete (deleted = false), restore (deleted = true)
+// or reset (status = 'failed') would affect. Empty filters match everything.
+func (q *Queries) CountURLsForBulkAction(ctx context.Context, arg CountURLsForBulkActionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countURLsForBulkAction,
+		arg.Deleted,
+		pq.Array(arg.Ids),
+		arg.Tag,
+		arg.Domain,
+		arg.Status,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countURLsPerDomain = `-- name: CountURLsPerDomain :many
+SELECT COALESCE(lower(substring(url from '^[^:]+://([^/:?#]+)')), '')::text AS domain, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY domain
+ORDER BY count DESC, domain
+LIMIT $1::int
+`
+
+type CountURLsPerDomainRow struct {
+	Domain string
+	Count  int64
+}
+
+// Counts URLs per host, most used hosts first.
+func (q *Queries) CountURLsPerDomain(ctx context.Context, maxResults int32) ([]CountURLsPerDomainRow, error) {
+	rows, err := q.db.QueryContext(ctx, countURLsPerDomain, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountURLsPerDomainRow
+	for rows.Next() {
+		var i CountURLsPerDomainRow
+		if err := rows.Scan(&i.Domain, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countURLsPerProject = `-- name: CountURLsPerProject :many
+SELECT project, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY project
+ORDER BY project
+`
+
+type CountURLsPerProjectRow struct {
+	Project string
+	Count   int64
+}
+
+func (q *Queries) CountURLsPerProject(ctx context.Context) ([]CountURLsPerProjectRow, error) {
+	rows, err := q.db.QueryContext(ctx, countURLsPerProject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountURLsPerProjectRow
+	for rows.Next() {
+		var i CountURLsPerProjectRow
+		if err := rows.Scan(&i.Project, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countURLsPerStatus = `-- name: CountURLsPerStatus :many
+SELECT status, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY status
+ORDER BY count DESC, status
+`
+
+type CountURLsPerStatusRow struct {
+	Status string
+	Count  int64
+}
+
+func (q *Queries) CountURLsPerStatus(ctx context.Context) ([]CountURLsPerStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, countURLsPerStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountURLsPerStatusRow
+	for rows.Next() {
+		var i CountURLsPerStatusRow
+		if err := rows.Scan(&i.Status, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createURL = `-- name: CreateURL :one
 INSERT INTO urls (
     url, frequency, status, max_retries, timeout, rate_limit, 
-    user_agent, parser_config, next_scrape_at
+    user_agent, parser_config, next_scrape_at, retry_policy, tags, project,
+    assertions, region, archive_policy
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy
 `
 
 type CreateURLParams struct {
-	Url          string
-	Frequency    string
-	Status       string
-	MaxRetries   int32
-	Timeout      int32
-	RateLimit    int32
-	UserAgent    sql.NullString
-	ParserConfig pqtype.NullRawMessage
-	NextScrapeAt sql.NullTime
+	Url           string
+	Frequency     string
+	Status        string
+	MaxRetries    int32
+	Timeout       int32
+	RateLimit     int32
+	UserAgent     sql.NullString
+	ParserConfig  pqtype.NullRawMessage
+	NextScrapeAt  sql.NullTime
+	RetryPolicy   pqtype.NullRawMessage
+	Tags          []string
+	Project       string
+	Assertions    pqtype.NullRawMessage
+	Region        string
+	ArchivePolicy pqtype.NullRawMessage
 }
 
 func (q *Queries) CreateURL(ctx context.Context, arg CreateURLParams) (Url, error) {
@@ -68,6 +222,12 @@ func (q *Queries) CreateURL(ctx context.Context, arg CreateURLParams) (Url, erro
 		arg.UserAgent,
 		arg.ParserConfig,
 		arg.NextScrapeAt,
+		arg.RetryPolicy,
+		pq.Array(arg.Tags),
+		arg.Project,
+		arg.Assertions,
+		arg.Region,
+		arg.ArchivePolicy,
 	)
 	var i Url
 	err := row.Scan(
@@ -86,12 +246,79 @@ func (q *Queries) CreateURL(ctx context.Context, arg CreateURLParams) (Url, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.RetryPolicy,
+		pq.Array(&i.Tags),
+		&i.Project,
+		&i.Managed,
+		&i.Assertions,
+		&i.Region,
+		&i.ArchivePolicy,
 	)
 	return i, err
 }
 
+const getOverdueURLs = `-- name: GetOverdueURLs :many
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls
+WHERE next_scrape_at < $1
+AND status IN ('pending', 'retry')
+AND deleted_at IS NULL
+ORDER BY next_scrape_at ASC
+LIMIT $2
+`
+
+type GetOverdueURLsParams struct {
+	NextScrapeAt sql.NullTime
+	Limit        int32
+}
+
+func (q *Queries) GetOverdueURLs(ctx context.Context, arg GetOverdueURLsParams) ([]Url, error) {
+	rows, err := q.db.QueryContext(ctx, getOverdueURLs, arg.NextScrapeAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Frequency,
+			&i.LastScrapedAt,
+			&i.NextScrapeAt,
+			&i.Status,
+			&i.RetryCount,
+			&i.MaxRetries,
+			&i.ParserConfig,
+			&i.UserAgent,
+			&i.Timeout,
+			&i.RateLimit,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getURLByID = `-- name: GetURLByID :one
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls WHERE id = $1
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls WHERE id = $1
 `
 
 func (q *Queries) GetURLByID(ctx context.Context, id uuid.UUID) (Url, error) {
@@ -113,12 +340,19 @@ func (q *Queries) GetURLByID(ctx context.Context, id uuid.UUID) (Url, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.RetryPolicy,
+		pq.Array(&i.Tags),
+		&i.Project,
+		&i.Managed,
+		&i.Assertions,
+		&i.Region,
+		&i.ArchivePolicy,
 	)
 	return i, err
 }
 
 const getURLsByIDs = `-- name: GetURLsByIDs :many
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls WHERE id = ANY($1::uuid[])
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls WHERE id = ANY($1::uuid[])
 `
 
 func (q *Queries) GetURLsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Url, error) {
@@ -146,6 +380,13 @@ func (q *Queries) GetURLsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Url
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -161,7 +402,7 @@ func (q *Queries) GetURLsByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Url
 }
 
 const getURLsByStatus = `-- name: GetURLsByStatus :many
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls 
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls 
 WHERE status = $1 
 ORDER BY created_at DESC 
 LIMIT $2 OFFSET $3
@@ -198,6 +439,13 @@ func (q *Queries) GetURLsByStatus(ctx context.Context, arg GetURLsByStatusParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -213,9 +461,10 @@ func (q *Queries) GetURLsByStatus(ctx context.Context, arg GetURLsByStatusParams
 }
 
 const getURLsForImmediateScraping = `-- name: GetURLsForImmediateScraping :many
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls 
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls 
 WHERE next_scrape_at <= $1 
-AND status IN ('pending', 'retry')
+AND status IN ('pending', 'retry', 'degraded')
+AND deleted_at IS NULL
 ORDER BY next_scrape_at ASC 
 LIMIT $2
 `
@@ -250,6 +499,96 @@ func (q *Queries) GetURLsForImmediateScraping(ctx context.Context, arg GetURLsFo
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getURLsScheduledFairly = `-- name: GetURLsScheduledFairly :many
+WITH shares AS (
+    SELECT project, share FROM unnest($1::text[], $2::int[]) AS s(project, share)
+), due AS (
+    SELECT u.id,
+        (row_number() OVER (PARTITION BY u.project ORDER BY u.next_scrape_at, u.id))::float8 / COALESCE(s.share, 1) AS turn
+    FROM urls u
+    LEFT JOIN shares s ON s.project = u.project
+    WHERE u.next_scrape_at BETWEEN $3::timestamptz AND $4::timestamptz
+    AND u.status IN ('pending', 'retry', 'degraded')
+    AND u.deleted_at IS NULL
+)
+SELECT urls.id, urls.url, urls.frequency, urls.last_scraped_at, urls.next_scrape_at, urls.status, urls.retry_count, urls.max_retries, urls.parser_config, urls.user_agent, urls.timeout, urls.rate_limit, urls.created_at, urls.updated_at, urls.deleted_at, urls.retry_policy, urls.tags, urls.project, urls.managed, urls.assertions, urls.region, urls.archive_policy FROM urls
+JOIN due ON due.id = urls.id
+ORDER BY due.turn, urls.next_scrape_at, urls.id
+LIMIT $5::int
+`
+
+type GetURLsScheduledFairlyParams struct {
+	ShareProjects []string
+	Shares        []int32
+	FromTime      time.Time
+	ToTime        time.Time
+	MaxResults    int32
+}
+
+// Gets the URLs due for scraping in a time range like
+// GetURLsScheduledForScraping, interleaving projects so one project's
+// backlog cannot fill the batch: each project's due URLs are ranked by due
+// time, and the batch takes the URLs of lowest rank divided by their
+// project's share. Projects missing from share_projects have a share of 1.
+func (q *Queries) GetURLsScheduledFairly(ctx context.Context, arg GetURLsScheduledFairlyParams) ([]Url, error) {
+	rows, err := q.db.QueryContext(ctx, getURLsScheduledFairly,
+		pq.Array(arg.ShareProjects),
+		pq.Array(arg.Shares),
+		arg.FromTime,
+		arg.ToTime,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Frequency,
+			&i.LastScrapedAt,
+			&i.NextScrapeAt,
+			&i.Status,
+			&i.RetryCount,
+			&i.MaxRetries,
+			&i.ParserConfig,
+			&i.UserAgent,
+			&i.Timeout,
+			&i.RateLimit,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -265,9 +604,10 @@ func (q *Queries) GetURLsForImmediateScraping(ctx context.Context, arg GetURLsFo
 }
 
 const getURLsScheduledForScraping = `-- name: GetURLsScheduledForScraping :many
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls 
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls 
 WHERE next_scrape_at BETWEEN $1 AND $2 
-AND status IN ('pending', 'retry')
+AND status IN ('pending', 'retry', 'degraded')
+AND deleted_at IS NULL
 ORDER BY next_scrape_at ASC 
 LIMIT $3
 `
@@ -303,6 +643,81 @@ func (q *Queries) GetURLsScheduledForScraping(ctx context.Context, arg GetURLsSc
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getURLsWithConsecutiveFailures = `-- name: GetURLsWithConsecutiveFailures :many
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls u
+WHERE u.status IN ('pending', 'retry', 'failed')
+AND u.deleted_at IS NULL
+AND (
+    SELECT COUNT(*) FROM (
+        SELECT t.status FROM scraping_tasks t
+        WHERE t.url_id = u.id AND t.completed_at IS NOT NULL
+        ORDER BY t.completed_at DESC
+        LIMIT $1::int
+    ) recent
+    WHERE recent.status <> 'success'
+) >= $1::int
+ORDER BY u.updated_at DESC
+LIMIT $2::int
+`
+
+type GetURLsWithConsecutiveFailuresParams struct {
+	Failures   int32
+	MaxResults int32
+}
+
+func (q *Queries) GetURLsWithConsecutiveFailures(ctx context.Context, arg GetURLsWithConsecutiveFailuresParams) ([]Url, error) {
+	rows, err := q.db.QueryContext(ctx, getURLsWithConsecutiveFailures, arg.Failures, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Frequency,
+			&i.LastScrapedAt,
+			&i.NextScrapeAt,
+			&i.Status,
+			&i.RetryCount,
+			&i.MaxRetries,
+			&i.ParserConfig,
+			&i.UserAgent,
+			&i.Timeout,
+			&i.RateLimit,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -327,16 +742,104 @@ func (q *Queries) IncrementRetryCount(ctx context.Context, id uuid.UUID) error {
 }
 
 const listURLs = `-- name: ListURLs :many
-SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at FROM urls ORDER BY created_at DESC LIMIT $1 OFFSET $2
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls
+WHERE deleted_at IS NULL
+AND ($1::text = '' OR url_search_text(url, tags) ILIKE $1::text)
+ORDER BY
+    CASE WHEN NOT $2::bool THEN
+        CASE $3::text
+            WHEN 'created_at' THEN created_at
+            WHEN 'next_scrape_at' THEN next_scrape_at
+            WHEN 'last_scraped_at' THEN last_scraped_at
+        END
+    END ASC NULLS LAST,
+    CASE WHEN $2::bool THEN
+        CASE $3::text
+            WHEN 'created_at' THEN created_at
+            WHEN 'next_scrape_at' THEN next_scrape_at
+            WHEN 'last_scraped_at' THEN last_scraped_at
+        END
+    END DESC NULLS LAST,
+    CASE WHEN NOT $2::bool THEN
+        CASE $3::text WHEN 'status' THEN status WHEN 'url' THEN url END
+    END ASC,
+    CASE WHEN $2::bool THEN
+        CASE $3::text WHEN 'status' THEN status WHEN 'url' THEN url END
+    END DESC,
+    created_at DESC, id
+LIMIT $4 OFFSET $5
 `
 
 type ListURLsParams struct {
-	Limit  int32
-	Offset int32
+	Pattern    string
+	Descending bool
+	SortBy     string
+	Limit      int32
+	Offset     int32
 }
 
+// An empty pattern matches every URL, otherwise it is matched with ILIKE
+// against the address and tags. URLs are sorted by sort_by (created_at,
+// next_scrape_at, last_scraped_at, status or url), URLs that were never
+// scheduled or scraped last.
 func (q *Queries) ListURLs(ctx context.Context, arg ListURLsParams) ([]Url, error) {
-	rows, err := q.db.QueryContext(ctx, listURLs, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listURLs,
+		arg.Pattern,
+		arg.Descending,
+		arg.SortBy,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Frequency,
+			&i.LastScrapedAt,
+			&i.NextScrapeAt,
+			&i.Status,
+			&i.RetryCount,
+			&i.MaxRetries,
+			&i.ParserConfig,
+			&i.UserAgent,
+			&i.Timeout,
+			&i.RateLimit,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listURLsForExport = `-- name: ListURLsForExport :many
+SELECT id, url, frequency, last_scraped_at, next_scrape_at, status, retry_count, max_retries, parser_config, user_agent, timeout, rate_limit, created_at, updated_at, deleted_at, retry_policy, tags, project, managed, assertions, region, archive_policy FROM urls WHERE deleted_at IS NULL ORDER BY url
+`
+
+func (q *Queries) ListURLsForExport(ctx context.Context) ([]Url, error) {
+	rows, err := q.db.QueryContext(ctx, listURLsForExport)
 	if err != nil {
 		return nil, err
 	}
@@ -360,6 +863,13 @@ func (q *Queries) ListURLs(ctx context.Context, arg ListURLsParams) ([]Url, erro
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.RetryPolicy,
+			pq.Array(&i.Tags),
+			&i.Project,
+			&i.Managed,
+			&i.Assertions,
+			&i.Region,
+			&i.ArchivePolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -374,6 +884,53 @@ func (q *Queries) ListURLs(ctx context.Context, arg ListURLsParams) ([]Url, erro
 	return items, nil
 }
 
+const resetFailedURLs = `-- name: ResetFailedURLs :many
+UPDATE urls SET status = 'pending', retry_count = 0, next_scrape_at = NOW(), updated_at = NOW()
+WHERE status = 'failed' AND deleted_at IS NULL
+AND (cardinality($1::uuid[]) = 0 OR id = ANY($1::uuid[]))
+AND ($2::text = '' OR $2::text = ANY(tags))
+AND ($3::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower($3::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower($3::text))
+RETURNING id, url
+`
+
+type ResetFailedURLsParams struct {
+	Ids    []uuid.UUID
+	Tag    string
+	Domain string
+}
+
+type ResetFailedURLsRow struct {
+	ID  uuid.UUID
+	Url string
+}
+
+// Moves failed URLs back to pending with a fresh retry budget and schedules
+// them right away. Returns the URLs that were reset.
+func (q *Queries) ResetFailedURLs(ctx context.Context, arg ResetFailedURLsParams) ([]ResetFailedURLsRow, error) {
+	rows, err := q.db.QueryContext(ctx, resetFailedURLs, pq.Array(arg.Ids), arg.Tag, arg.Domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ResetFailedURLsRow
+	for rows.Next() {
+		var i ResetFailedURLsRow
+		if err := rows.Scan(&i.ID, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const resetRetryCount = `-- name: ResetRetryCount :exec
 UPDATE urls SET retry_count = 0, updated_at = NOW() WHERE id = $1
 `
@@ -383,6 +940,120 @@ func (q *Queries) ResetRetryCount(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const restoreURLs = `-- name: RestoreURLs :many
+UPDATE urls SET deleted_at = NULL, updated_at = NOW()
+WHERE deleted_at IS NOT NULL
+AND (cardinality($1::uuid[]) = 0 OR id = ANY($1::uuid[]))
+AND ($2::text = '' OR $2::text = ANY(tags))
+AND ($3::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower($3::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower($3::text))
+RETURNING id, url
+`
+
+type RestoreURLsParams struct {
+	Ids    []uuid.UUID
+	Tag    string
+	Domain string
+}
+
+type RestoreURLsRow struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) RestoreURLs(ctx context.Context, arg RestoreURLsParams) ([]RestoreURLsRow, error) {
+	rows, err := q.db.QueryContext(ctx, restoreURLs, pq.Array(arg.Ids), arg.Tag, arg.Domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RestoreURLsRow
+	for rows.Next() {
+		var i RestoreURLsRow
+		if err := rows.Scan(&i.ID, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteURLs = `-- name: SoftDeleteURLs :many
+UPDATE urls SET deleted_at = NOW(), updated_at = NOW()
+WHERE deleted_at IS NULL
+AND (cardinality($1::uuid[]) = 0 OR id = ANY($1::uuid[]))
+AND ($2::text = '' OR $2::text = ANY(tags))
+AND ($3::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower($3::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower($3::text))
+RETURNING id, url
+`
+
+type SoftDeleteURLsParams struct {
+	Ids    []uuid.UUID
+	Tag    string
+	Domain string
+}
+
+type SoftDeleteURLsRow struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) SoftDeleteURLs(ctx context.Context, arg SoftDeleteURLsParams) ([]SoftDeleteURLsRow, error) {
+	rows, err := q.db.QueryContext(ctx, softDeleteURLs, pq.Array(arg.Ids), arg.Tag, arg.Domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SoftDeleteURLsRow
+	for rows.Next() {
+		var i SoftDeleteURLsRow
+		if err := rows.Scan(&i.ID, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const transitionURLStatus = `-- name: TransitionURLStatus :one
+UPDATE urls u SET status = $1, updated_at = NOW()
+FROM (SELECT id, status FROM urls WHERE id = $2 FOR UPDATE) previous
+WHERE u.id = previous.id
+AND previous.status = ANY($3::text[])
+RETURNING previous.status::text AS previous_status
+`
+
+type TransitionURLStatusParams struct {
+	Status       string
+	ID           uuid.UUID
+	FromStatuses []string
+}
+
+// Sets the status of a URL if its current status is one of from_statuses and
+// returns the status it had. No row is returned if the URL is missing or in
+// another status.
+func (q *Queries) TransitionURLStatus(ctx context.Context, arg TransitionURLStatusParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, transitionURLStatus, arg.Status, arg.ID, pq.Array(arg.FromStatuses))
+	var previous_status string
+	err := row.Scan(&previous_status)
+	return previous_status, err
+}
+
 const updateLastScrapedTime = `-- name: UpdateLastScrapedTime :exec
 UPDATE urls SET last_scraped_at = $2, updated_at = NOW() WHERE id = $1
 `
@@ -411,6 +1082,20 @@ func (q *Queries) UpdateNextScrapeTime(ctx context.Context, arg UpdateNextScrape
 	return err
 }
 
+const updateURLParserConfig = `-- name: UpdateURLParserConfig :exec
+UPDATE urls SET parser_config = $2, updated_at = NOW() WHERE id = $1
+`
+
+type UpdateURLParserConfigParams struct {
+	ID           uuid.UUID
+	ParserConfig pqtype.NullRawMessage
+}
+
+func (q *Queries) UpdateURLParserConfig(ctx context.Context, arg UpdateURLParserConfigParams) error {
+	_, err := q.db.ExecContext(ctx, updateURLParserConfig, arg.ID, arg.ParserConfig)
+	return err
+}
+
 const updateURLStatus = `-- name: UpdateURLStatus :exec
 UPDATE urls SET status = $2, updated_at = NOW() WHERE id = $1
 `
@@ -424,3 +1109,82 @@ func (q *Queries) UpdateURLStatus(ctx context.Context, arg UpdateURLStatusParams
 	_, err := q.db.ExecContext(ctx, updateURLStatus, arg.ID, arg.Status)
 	return err
 }
+
+const upsertURL = `-- name: UpsertURL :one
+INSERT INTO urls (
+    url, frequency, status, max_retries, timeout, rate_limit,
+    user_agent, parser_config, next_scrape_at, retry_policy, tags,
+    project, managed, assertions, region, archive_policy
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT (url) DO UPDATE SET
+    frequency = EXCLUDED.frequency,
+    max_retries = EXCLUDED.max_retries,
+    timeout = EXCLUDED.timeout,
+    rate_limit = EXCLUDED.rate_limit,
+    user_agent = EXCLUDED.user_agent,
+    parser_config = EXCLUDED.parser_config,
+    retry_policy = EXCLUDED.retry_policy,
+    tags = EXCLUDED.tags,
+    project = EXCLUDED.project,
+    managed = urls.managed OR EXCLUDED.managed,
+    assertions = EXCLUDED.assertions,
+    region = EXCLUDED.region,
+    archive_policy = EXCLUDED.archive_policy,
+    next_scrape_at = COALESCE(urls.next_scrape_at, EXCLUDED.next_scrape_at),
+    deleted_at = NULL,
+    updated_at = NOW()
+RETURNING id, (xmax = 0)::bool AS inserted
+`
+
+type UpsertURLParams struct {
+	Url           string
+	Frequency     string
+	Status        string
+	MaxRetries    int32
+	Timeout       int32
+	RateLimit     int32
+	UserAgent     sql.NullString
+	ParserConfig  pqtype.NullRawMessage
+	NextScrapeAt  sql.NullTime
+	RetryPolicy   pqtype.NullRawMessage
+	Tags          []string
+	Project       string
+	Managed       bool
+	Assertions    pqtype.NullRawMessage
+	Region        string
+	ArchivePolicy pqtype.NullRawMessage
+}
+
+type UpsertURLRow struct {
+	ID       uuid.UUID
+	Inserted bool
+}
+
+// Creates a URL or replaces the configuration of the URL with the same
+// address, restoring it if it was deleted. Status and schedule are kept, and
+// a URL managed by configuration sync stays managed.
+func (q *Queries) UpsertURL(ctx context.Context, arg UpsertURLParams) (UpsertURLRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertURL,
+		arg.Url,
+		arg.Frequency,
+		arg.Status,
+		arg.MaxRetries,
+		arg.Timeout,
+		arg.RateLimit,
+		arg.UserAgent,
+		arg.ParserConfig,
+		arg.NextScrapeAt,
+		arg.RetryPolicy,
+		pq.Array(arg.Tags),
+		arg.Project,
+		arg.Managed,
+		arg.Assertions,
+		arg.Region,
+		arg.ArchivePolicy,
+	)
+	var i UpsertURLRow
+	err := row.Scan(&i.ID, &i.Inserted)
+	return i, err
+}
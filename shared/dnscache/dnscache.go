@@ -0,0 +1,297 @@
+// Package dnscache resolves host names for scrapers and caches the answers
+// for their TTL (scraping.dns), so high-frequency scrapes of the same hosts
+// do not pay for a lookup each time. Names are resolved by the configured
+// servers, over UDP, TCP or DNS over HTTPS, or by the system resolver.
+//
+// The time a scrape spends resolving names is reported through a Timing
+// attached to its context, see WithTiming.
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// Stats counts the lookups of a resolver
+type Stats struct {
+	Hits   int64 `json:"hits"`   // Lookups answered from the cache
+	Misses int64 `json:"misses"` // Lookups sent to a resolver
+	Errors int64 `json:"errors"` // Lookups that failed
+}
+
+// Resolver resolves host names, caching the answers
+type Resolver struct {
+	cfg     config.DNSConfig
+	servers []string
+	client  *http.Client
+	dialer  *net.Dialer
+	now     func() time.Time
+
+	// lookup resolves a name uncached, returning its addresses and the TTL
+	// of the answer, negative if unknown
+	lookup func(ctx context.Context, host string) ([]net.IP, time.Duration, error)
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	stats   Stats
+}
+
+// entry is a cached answer, or a lookup in flight until done is closed
+type entry struct {
+	done    chan struct{}
+	ips     []net.IP
+	err     error
+	expires time.Time
+}
+
+// New creates a resolver from the scraping.dns settings. It returns an
+// error if a server address is invalid.
+func New(cfg config.DNSConfig) (*Resolver, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	r := &Resolver{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		dialer:  &net.Dialer{Timeout: cfg.Timeout},
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+	for _, server := range cfg.Servers {
+		server = strings.TrimSpace(server)
+		if !strings.Contains(server, "://") {
+			if _, _, err := net.SplitHostPort(server); err != nil {
+				server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+			}
+		}
+		r.servers = append(r.servers, server)
+	}
+	r.lookup = r.query
+	if len(r.servers) == 0 {
+		r.lookup = systemLookup
+	}
+	return r, nil
+}
+
+// LookupIP returns the addresses of a host, from the cache when its answer
+// has not expired. Concurrent lookups of a host share one query. The time
+// spent waiting is added to the Timing of ctx, if any.
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	start := r.now()
+	r.mu.Lock()
+	e, ok := r.entries[host]
+	if ok && e.done == nil && start.Before(e.expires) {
+		r.stats.Hits++
+		r.mu.Unlock()
+		timingFrom(ctx).add(0, true)
+		return e.ips, nil
+	}
+	if !ok || e.done == nil {
+		e = &entry{done: make(chan struct{})}
+		r.entries[host] = e
+		r.stats.Misses++
+		go r.resolve(host, e)
+	}
+	done := e.done
+	r.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	timingFrom(ctx).add(r.now().Sub(start), false)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.ips, nil
+}
+
+// DialContext dials addr with its host resolved through the cache, trying
+// each address in turn. Use it as the DialContext of an http.Transport.
+func (r *Resolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := r.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, ip := range ips {
+		if !matchesNetwork(network, ip) {
+			continue
+		}
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, &net.AddrError{Err: "no suitable address found", Addr: host}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Stats returns the lookup counts since the resolver was created
+func (r *Resolver) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Flush drops every cached answer
+func (r *Resolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for host, e := range r.entries {
+		if e.done == nil {
+			delete(r.entries, host)
+		}
+	}
+}
+
+// resolve runs the lookup of an entry in flight and caches its answer.
+// Failed lookups are not cached.
+func (r *Resolver) resolve(host string, e *entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout*time.Duration(max(len(r.servers), 1)))
+	defer cancel()
+	ips, ttl, err := r.lookup(ctx, host)
+	if err == nil && len(ips) == 0 {
+		err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.ips, e.err, e.expires = ips, err, r.now().Add(r.ttl(ttl))
+	if err != nil {
+		r.stats.Errors++
+		delete(r.entries, host)
+	}
+	close(e.done)
+	e.done = nil
+}
+
+// ttl clamps the TTL of an answer to the configured bounds
+func (r *Resolver) ttl(ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		ttl = r.cfg.DefaultTTL
+	}
+	if ttl < r.cfg.MinTTL {
+		ttl = r.cfg.MinTTL
+	}
+	if r.cfg.MaxTTL > 0 && ttl > r.cfg.MaxTTL {
+		ttl = r.cfg.MaxTTL
+	}
+	return ttl
+}
+
+// systemLookup resolves a name with the system resolver, which does not
+// report TTLs
+func systemLookup(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, -1, nil
+}
+
+// matchesNetwork reports whether ip can be dialed on network
+func matchesNetwork(network string, ip net.IP) bool {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return ip.To4() != nil
+	case strings.HasSuffix(network, "6"):
+		return ip.To4() == nil
+	}
+	return true
+}
+
+// Timing accumulates the time a scrape spent resolving names
+type Timing struct {
+	mu       sync.Mutex
+	duration time.Duration
+	lookups  int
+	cached   int
+}
+
+type timingKey struct{}
+
+// WithTiming returns a context that records the lookups made with it in the
+// returned Timing
+func WithTiming(ctx context.Context) (context.Context, *Timing) {
+	t := &Timing{}
+	return context.WithValue(ctx, timingKey{}, t), t
+}
+
+// timingFrom returns the Timing of ctx, nil if it has none
+func timingFrom(ctx context.Context) *Timing {
+	t, _ := ctx.Value(timingKey{}).(*Timing)
+	return t
+}
+
+// add records a lookup
+func (t *Timing) add(d time.Duration, cached bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.duration += d
+	t.lookups++
+	if cached {
+		t.cached++
+	}
+}
+
+// Duration returns the total time spent resolving names
+func (t *Timing) Duration() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.duration
+}
+
+// Milliseconds returns the total time spent resolving names in
+// milliseconds, as reported in ScrapeResult.DNSMs
+func (t *Timing) Milliseconds() int64 {
+	return t.Duration().Milliseconds()
+}
+
+// Lookups returns the number of lookups and how many the cache answered
+func (t *Timing) Lookups() (total, cached int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lookups, t.cached
+}
+
+// String describes the timing for logs
+func (t *Timing) String() string {
+	total, cached := t.Lookups()
+	return fmt.Sprintf("%d lookups (%d cached) in %s", total, cached, t.Duration())
+}
@@ -0,0 +1,228 @@
+package dnscache
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// answer builds the answer to a query with an A record for TypeA questions
+// and no records otherwise, or NXDOMAIN for missing.example.
+func answer(t *testing.T, query []byte, ttl uint32) []byte {
+	t.Helper()
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+	q, err := p.Question()
+	if err != nil {
+		t.Fatalf("failed to parse question: %v", err)
+	}
+
+	resp := dnsmessage.Header{ID: h.ID, Response: true, RecursionDesired: true}
+	if q.Name.String() == "missing.example." {
+		resp.RCode = dnsmessage.RCodeNameError
+	}
+	b := dnsmessage.NewBuilder(nil, resp)
+	b.StartQuestions()
+	b.Question(q)
+	b.StartAnswers()
+	if q.Type == dnsmessage.TypeA && resp.RCode == dnsmessage.RCodeSuccess {
+		b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl},
+			dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}})
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		t.Fatalf("failed to build answer: %v", err)
+	}
+	return msg
+}
+
+// serveUDP answers queries on a local UDP socket and counts them
+func serveUDP(t *testing.T, ttl uint32) (string, *atomic.Int64) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var queries atomic.Int64
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			queries.Add(1)
+			conn.WriteTo(answer(t, buf[:n], ttl), addr)
+		}
+	}()
+	return conn.LocalAddr().String(), &queries
+}
+
+func TestLookupIPCachesForTTL(t *testing.T) {
+	server, queries := serveUDP(t, 30)
+	r, err := New(config.DNSConfig{Servers: []string{server}, Timeout: time.Second, MinTTL: time.Second, MaxTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	ctx, timing := WithTiming(context.Background())
+	for i := 0; i < 3; i++ {
+		ips, err := r.LookupIP(ctx, "Example.test")
+		if err != nil {
+			t.Fatalf("LookupIP: %v", err)
+		}
+		if len(ips) != 1 || !ips[0].Equal(net.IPv4(127, 0, 0, 1)) {
+			t.Fatalf("ips = %v, want [127.0.0.1]", ips)
+		}
+	}
+	if got := queries.Load(); got != 2 { // One A and one AAAA query
+		t.Errorf("queries = %d, want 2", got)
+	}
+	if total, cached := timing.Lookups(); total != 3 || cached != 2 {
+		t.Errorf("lookups = %d (%d cached), want 3 (2 cached)", total, cached)
+	}
+	if stats := r.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Errors != 0 {
+		t.Errorf("stats = %+v, want 2 hits and 1 miss", stats)
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, err := r.LookupIP(context.Background(), "example.test"); err != nil {
+		t.Fatalf("LookupIP after TTL: %v", err)
+	}
+	if got := queries.Load(); got != 4 {
+		t.Errorf("queries after TTL = %d, want 4", got)
+	}
+}
+
+func TestLookupIPNotFound(t *testing.T) {
+	server, _ := serveUDP(t, 30)
+	r, err := New(config.DNSConfig{Servers: []string{server}, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = r.LookupIP(context.Background(), "missing.example")
+	dnsErr, ok := err.(*net.DNSError)
+	if !ok || !dnsErr.IsNotFound {
+		t.Fatalf("err = %v, want a not found DNS error", err)
+	}
+	if stats := r.Stats(); stats.Errors != 1 {
+		t.Errorf("errors = %d, want 1", stats.Errors)
+	}
+}
+
+func TestLookupIPOverHTTPS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		query, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(answer(t, query, 60))
+	}))
+	defer srv.Close()
+
+	r, err := New(config.DNSConfig{Servers: []string{srv.URL + "/dns-query"}, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.client = srv.Client()
+	ips, err := r.LookupIP(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("LookupIP: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("ips = %v, want [127.0.0.1]", ips)
+	}
+}
+
+func TestLookupIPSharesInFlightQueries(t *testing.T) {
+	r, err := New(config.DNSConfig{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	release := make(chan struct{})
+	var lookups atomic.Int64
+	r.lookup = func(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+		lookups.Add(1)
+		<-release
+		return []net.IP{net.IPv4(10, 0, 0, 1)}, time.Minute, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.LookupIP(context.Background(), "example.test"); err != nil {
+				t.Errorf("LookupIP: %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := lookups.Load(); got != 1 {
+		t.Errorf("lookups = %d, want 1", got)
+	}
+}
+
+func TestTTLBounds(t *testing.T) {
+	r, err := New(config.DNSConfig{MinTTL: 10 * time.Second, MaxTTL: time.Minute, DefaultTTL: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tests := []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{-1, 30 * time.Second},
+		{0, 10 * time.Second},
+		{20 * time.Second, 20 * time.Second},
+		{time.Hour, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := r.ttl(tt.ttl); got != tt.want {
+			t.Errorf("ttl(%s) = %s, want %s", tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestDNSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.DNSConfig
+		wantErr bool
+	}{
+		{"system resolver", config.DNSConfig{}, false},
+		{"udp servers", config.DNSConfig{Servers: []string{"1.1.1.1", "[2606:4700::1111]:53"}}, false},
+		{"doh server", config.DNSConfig{Servers: []string{"https://dns.example/dns-query"}}, false},
+		{"plain http", config.DNSConfig{Servers: []string{"http://dns.example/dns-query"}}, true},
+		{"empty server", config.DNSConfig{Servers: []string{" "}}, true},
+		{"inverted ttls", config.DNSConfig{MinTTL: time.Hour, MaxTTL: time.Minute}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
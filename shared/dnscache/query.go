@@ -0,0 +1,252 @@
+package dnscache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxUDPSize is the UDP payload size advertised with EDNS(0)
+const maxUDPSize = 1232
+
+// errNotFound reports a name that does not exist
+var errNotFound = errors.New("no such host")
+
+// query resolves a name with the configured servers, asking each in turn
+// until one answers or reports that the name does not exist. It returns the
+// A and AAAA addresses and the lowest TTL among them, -1 when there are none.
+func (r *Resolver) query(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(host + ".")
+	if err != nil {
+		return nil, 0, &net.DNSError{Err: "invalid host name", Name: host}
+	}
+
+	var errs []error
+	for _, server := range r.servers {
+		ips, ttl, err := r.queryServer(ctx, server, name)
+		if err == nil {
+			return ips, ttl, nil
+		}
+		if errors.Is(err, errNotFound) {
+			return nil, 0, &net.DNSError{Err: err.Error(), Name: host, IsNotFound: true}
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", server, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, 0, &net.DNSError{Err: errors.Join(errs...).Error(), Name: host}
+}
+
+// queryServer asks one server for the A and AAAA records of a name
+func (r *Resolver) queryServer(ctx context.Context, server string, name dnsmessage.Name) ([]net.IP, time.Duration, error) {
+	var ips []net.IP
+	ttl := time.Duration(-1)
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answer, answerTTL, err := r.exchange(ctx, server, name, qtype)
+		if err != nil {
+			return nil, 0, err
+		}
+		ips = append(ips, answer...)
+		if len(answer) > 0 && (ttl < 0 || answerTTL < ttl) {
+			ttl = answerTTL
+		}
+	}
+	return ips, ttl, nil
+}
+
+// exchange asks a server for the records of one type
+func (r *Resolver) exchange(ctx context.Context, server string, name dnsmessage.Name, qtype dnsmessage.Type) ([]net.IP, time.Duration, error) {
+	id := uint16(0) // DNS over HTTPS uses 0 so responses can be cached
+	if !strings.HasPrefix(server, "https://") {
+		var b [2]byte
+		rand.Read(b[:])
+		id = binary.BigEndian.Uint16(b[:])
+	}
+	msg, err := newQuery(id, name, qtype)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+	var resp []byte
+	if strings.HasPrefix(server, "https://") {
+		resp, err = r.exchangeHTTPS(ctx, server, msg)
+	} else {
+		resp, err = r.exchangeUDP(ctx, server, msg)
+		var truncated *truncatedError
+		if errors.As(err, &truncated) {
+			resp, err = r.exchangeTCP(ctx, server, msg)
+		}
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseAnswer(resp, id)
+}
+
+// exchangeUDP sends a query over UDP. It returns a truncatedError when the
+// answer does not fit and must be asked for over TCP.
+func (r *Resolver) exchangeUDP(ctx context.Context, server string, msg []byte) ([]byte, error) {
+	conn, err := r.dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, maxUDPSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil || h.ID != binary.BigEndian.Uint16(msg) {
+			continue // Not an answer to this query
+		}
+		if h.Truncated {
+			return nil, &truncatedError{}
+		}
+		return buf[:n], nil
+	}
+}
+
+// exchangeTCP sends a query over TCP, each message prefixed by its length
+func (r *Resolver) exchangeTCP(ctx context.Context, server string, msg []byte) ([]byte, error) {
+	conn, err := r.dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(msg)))); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// exchangeHTTPS sends a query over HTTPS (RFC 8484)
+func (r *Resolver) exchangeHTTPS(ctx context.Context, server string, msg []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+}
+
+// newQuery builds a recursive query for the records of one type
+func newQuery(id uint16, name dnsmessage.Name, qtype dnsmessage.Type) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(maxUDPSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// parseAnswer returns the addresses in an answer and their lowest TTL, -1
+// when there are none. A name that does not exist is errNotFound; a name
+// without records of the asked type is not an error.
+func parseAnswer(msg []byte, id uint16) ([]net.IP, time.Duration, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case h.ID != id:
+		return nil, 0, errors.New("answer does not match the query")
+	case h.RCode == dnsmessage.RCodeNameError:
+		return nil, 0, errNotFound
+	case h.RCode != dnsmessage.RCodeSuccess:
+		return nil, 0, fmt.Errorf("server answered %s", h.RCode)
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, 0, err
+	}
+	answers, err := p.AllAnswers()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ips []net.IP
+	ttl := time.Duration(-1)
+	for _, answer := range answers {
+		var ip net.IP
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			ip = net.IP(body.A[:])
+		case *dnsmessage.AAAAResource:
+			ip = net.IP(body.AAAA[:])
+		default:
+			continue // CNAMEs leading to the addresses
+		}
+		ips = append(ips, ip)
+		if answerTTL := time.Duration(answer.Header.TTL) * time.Second; ttl < 0 || answerTTL < ttl {
+			ttl = answerTTL
+		}
+	}
+	return ips, ttl, nil
+}
+
+// truncatedError reports a UDP answer that did not fit in a datagram
+type truncatedError struct{}
+
+func (*truncatedError) Error() string {
+	return "answer truncated"
+}
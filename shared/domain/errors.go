@@ -0,0 +1,138 @@
+// Package domain defines the errors repositories and services return, so
+// handlers can map them to HTTP status codes without knowing where they
+// came from. Every error has a kind (ErrNotFound, ErrConflict,
+// ErrValidation, ErrRateLimited or ErrUnavailable) that errors.Is matches,
+// and a message that is safe to show to clients.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error kinds
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrRateLimited = errors.New("rate limited")
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Errors of specific resources
+var (
+	ErrURLNotFound        = &Error{Kind: ErrNotFound, Message: "URL not found"}
+	ErrMessageNotFound    = &Error{Kind: ErrNotFound, Message: "Message not found"}
+	ErrMaxRetriesExceeded = &Error{Kind: ErrValidation, Message: "Max retries exceeded"}
+	ErrMaintenance        = &Error{Kind: ErrUnavailable, Message: "Maintenance mode is enabled"}
+)
+
+// Error is an error of a kind with a message for clients. Err is the
+// underlying cause, which is logged but not shown.
+type Error struct {
+	Kind       error         // One of the error kinds, e.g. ErrNotFound
+	Message    string        // Safe to show to clients
+	Field      string        // Invalid field of ErrValidation errors, if any
+	RetryAfter time.Duration // When to retry ErrRateLimited and ErrUnavailable errors, 0 if unknown
+	Err        error         // Underlying cause, may be nil
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the kind and the cause, so errors.Is matches both
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Is reports whether target is an *Error of the same kind and message, so
+// errors made with Wrap match the errors they were made from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Message == e.Message
+}
+
+// Wrap returns a copy of the error caused by err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// NotFound returns an ErrNotFound error
+func NotFound(format string, args ...any) *Error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict returns an ErrConflict error, e.g. for a duplicate resource
+func Conflict(format string, args ...any) *Error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation returns an ErrValidation error of a field, "" for none
+func Validation(field, format string, args ...any) *Error {
+	return &Error{Kind: ErrValidation, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// RateLimited returns an ErrRateLimited error to retry after retryAfter
+func RateLimited(retryAfter time.Duration, format string, args ...any) *Error {
+	return &Error{Kind: ErrRateLimited, RetryAfter: retryAfter, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unavailable returns an ErrUnavailable error caused by err, e.g. a
+// dependency that cannot be reached
+func Unavailable(err error, format string, args ...any) *Error {
+	return &Error{Kind: ErrUnavailable, Err: err, Message: fmt.Sprintf(format, args...)}
+}
+
+// HTTPStatus returns the HTTP status code of an error: 404 Not Found,
+// 409 Conflict, 400 Bad Request, 429 Too Many Requests or 503 Service
+// Unavailable by its kind, 500 Internal Server Error for other errors
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// Message returns the message of an error that is safe to show to
+// clients: the Message of an *Error, or the text of another error of a
+// kind. It returns false for errors without a kind, whose details must not
+// be shown.
+func Message(err error) (string, bool) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message, true
+	}
+	if HTTPStatus(err) != http.StatusInternalServerError {
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// RetryAfter returns when to retry after an error, 0 if unknown
+func RetryAfter(err error) time.Duration {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("get url: %w", ErrURLNotFound.Wrap(sql.ErrNoRows))
+
+	if !errors.Is(err, ErrURLNotFound) {
+		t.Error("wrapped error does not match ErrURLNotFound")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("wrapped error does not match its kind")
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Error("wrapped error does not match its cause")
+	}
+	if errors.Is(err, ErrMessageNotFound) {
+		t.Error("ErrURLNotFound matches ErrMessageNotFound")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Error("ErrURLNotFound matches ErrConflict")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: ErrURLNotFound, want: http.StatusNotFound},
+		{err: Conflict("URL already exists"), want: http.StatusConflict},
+		{err: Validation("url", "URL is required"), want: http.StatusBadRequest},
+		{err: RateLimited(time.Minute, "Too many requests"), want: http.StatusTooManyRequests},
+		{err: fmt.Errorf("trigger: %w", ErrMaintenance), want: http.StatusServiceUnavailable},
+		{err: fmt.Errorf("lookup: %w", ErrNotFound), want: http.StatusNotFound},
+		{err: errors.New("connection reset"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := HTTPStatus(tt.err); got != tt.want {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	if got, ok := Message(Unavailable(errors.New("dial tcp: refused"), "Database unavailable")); !ok || got != "Database unavailable" {
+		t.Errorf("Message() = %q, %v, want the message without the cause", got, ok)
+	}
+	if got, ok := Message(fmt.Errorf("status %q: %w", "done", ErrConflict)); !ok || got != `status "done": conflict` {
+		t.Errorf("Message() = %q, %v, want the text of the kinded error", got, ok)
+	}
+	if _, ok := Message(errors.New("pq: password authentication failed")); ok {
+		t.Error("Message() shows an error without a kind")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := RetryAfter(fmt.Errorf("send: %w", RateLimited(30*time.Second, "Slow down"))); got != 30*time.Second {
+		t.Errorf("RetryAfter() = %v, want 30s", got)
+	}
+	if got := RetryAfter(ErrURLNotFound); got != 0 {
+		t.Errorf("RetryAfter() = %v, want 0", got)
+	}
+}
@@ -0,0 +1,225 @@
+// Package encryption encrypts sensitive fields at rest with envelope
+// encryption. Each value is encrypted with AES-256-GCM under a random data
+// key, and the data key is stored with it, encrypted (wrapped) by a key of
+// the keyring configured in encryption.keys. The envelope records the ID of
+// the wrapping key, so rotating encryption.primary_key only re-wraps data
+// keys (see Rewrap) instead of re-encrypting the values, and records wrapped
+// by earlier keys stay readable as long as those keys are configured.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"go_scraping_project/shared/config"
+)
+
+// Version is the envelope format written by Seal
+const Version = 1
+
+// Algorithm is the cipher of envelopes of Version
+const Algorithm = "AES-256-GCM"
+
+// Envelope is an encrypted value with the metadata needed to decrypt it.
+// It is stored as JSON in place of the value.
+type Envelope struct {
+	Version    int    `json:"v"`
+	KeyID      string `json:"kid"` // ID of the key wrapping DataKey
+	Algorithm  string `json:"alg"`
+	DataKey    []byte `json:"dek"` // Data key wrapped by KeyID, nonce prepended
+	Ciphertext []byte `json:"ct"`  // Value encrypted by the data key, nonce prepended
+}
+
+// Keyring holds the key encryption keys by ID
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// New creates a keyring from the encryption settings, or returns nil when no
+// keys are configured and values are stored unencrypted. It returns an error
+// for keys that are not base64-encoded 32-byte keys.
+func New(cfg config.EncryptionConfig) (*Keyring, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{primary: cfg.PrimaryKey, keys: make(map[string]cipher.AEAD, len(cfg.Keys))}
+	for id, encoded := range cfg.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be a base64-encoded 32-byte key", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// PrimaryKeyID returns the ID of the key wrapping new data keys
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// KeyIDs returns the IDs of the keys in the keyring, in order
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Seal encrypts a value under a new data key wrapped by the primary key
+func (k *Keyring) Seal(plaintext []byte) (*Envelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, plaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{Version: Version, KeyID: k.primary, Algorithm: Algorithm, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open decrypts an envelope
+func (k *Keyring) Open(env *Envelope) ([]byte, error) {
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(aead, env.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key of an envelope by the primary key, leaving the
+// encrypted value as it is. It reports whether the envelope changed, false
+// when it was already wrapped by the primary key.
+func (k *Keyring) Rewrap(env *Envelope) (bool, error) {
+	if env.KeyID == k.primary {
+		return false, nil
+	}
+	dataKey, err := k.unwrap(env)
+	if err != nil {
+		return false, err
+	}
+	wrapped, err := seal(k.keys[k.primary], dataKey, []byte(k.primary))
+	if err != nil {
+		return false, err
+	}
+	env.KeyID, env.DataKey = k.primary, wrapped
+	return true, nil
+}
+
+// SealBytes encrypts a value and returns its envelope as JSON
+func (k *Keyring) SealBytes(plaintext []byte) ([]byte, error) {
+	env, err := k.Seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+// OpenBytes decrypts an envelope stored as JSON by SealBytes
+func (k *Keyring) OpenBytes(data []byte) ([]byte, error) {
+	env, ok := Parse(data)
+	if !ok {
+		return nil, errors.New("value is not an encryption envelope")
+	}
+	return k.Open(env)
+}
+
+// unwrap decrypts the data key of an envelope
+func (k *Keyring) unwrap(env *Envelope) ([]byte, error) {
+	if k == nil {
+		return nil, errors.New("value is encrypted but no encryption keys are configured")
+	}
+	if env.Version != Version || env.Algorithm != Algorithm {
+		return nil, fmt.Errorf("unsupported envelope version %d (%s)", env.Version, env.Algorithm)
+	}
+	kek, ok := k.keys[env.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not configured", env.KeyID)
+	}
+	dataKey, err := open(kek, env.DataKey, []byte(env.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %q: %w", env.KeyID, err)
+	}
+	return dataKey, nil
+}
+
+// Parse decodes an envelope stored as JSON; ok is false for other data,
+// such as values stored before encryption was configured
+func Parse(data []byte) (env *Envelope, ok bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' || !bytes.Contains(data, []byte(`"kid"`)) {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || len(fields) != 5 {
+		return nil, false
+	}
+	env = &Envelope{}
+	if err := json.Unmarshal(data, env); err != nil || env.Version == 0 || env.KeyID == "" || len(env.DataKey) == 0 {
+		return nil, false
+	}
+	return env, true
+}
+
+// newAEAD returns AES-GCM with a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts data with a random nonce, which it prepends
+func seal(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, additional), nil
+}
+
+// open decrypts data sealed by seal
+func open(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	size := aead.NonceSize()
+	if len(data) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:size], data[size:], additional)
+}
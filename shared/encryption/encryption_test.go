@@ -0,0 +1,157 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go_scraping_project/shared/config"
+)
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func newKeyring(t *testing.T, primary string, ids ...string) *Keyring {
+	t.Helper()
+	keys := map[string]string{}
+	for i, id := range ids {
+		keys[id] = key(byte(i + 1))
+	}
+	k, err := New(config.EncryptionConfig{PrimaryKey: primary, Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealOpen(t *testing.T) {
+	k := newKeyring(t, "2024-01", "2024-01")
+	data, err := k.SealBytes([]byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("s3cr3t")) {
+		t.Fatalf("envelope contains the plaintext: %s", data)
+	}
+	env, ok := Parse(data)
+	if !ok || env.KeyID != "2024-01" || env.Version != Version || env.Algorithm != Algorithm {
+		t.Fatalf("Parse() = %+v, %v", env, ok)
+	}
+	plaintext, err := k.OpenBytes(data)
+	if err != nil || string(plaintext) != "s3cr3t" {
+		t.Errorf("OpenBytes() = %q, %v", plaintext, err)
+	}
+
+	other, _ := k.SealBytes([]byte("s3cr3t"))
+	if bytes.Equal(data, other) {
+		t.Error("equal values sealed to equal envelopes")
+	}
+
+	env.Ciphertext[len(env.Ciphertext)-1] ^= 1
+	if _, err := k.Open(env); err == nil {
+		t.Error("Open() of a tampered envelope succeeded")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := newKeyring(t, "old", "old")
+	env, err := old.Seal([]byte(`{"password":"hunter2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := append([]byte(nil), env.Ciphertext...)
+
+	rotated := newKeyring(t, "new", "old", "new")
+	if plaintext, err := rotated.Open(env); err != nil || string(plaintext) != `{"password":"hunter2"}` {
+		t.Fatalf("Open() with the old key kept = %q, %v", plaintext, err)
+	}
+	changed, err := rotated.Rewrap(env)
+	if err != nil || !changed || env.KeyID != "new" {
+		t.Fatalf("Rewrap() = %v, %v; key %s", changed, err, env.KeyID)
+	}
+	if !bytes.Equal(env.Ciphertext, ciphertext) {
+		t.Error("Rewrap() re-encrypted the value")
+	}
+	if changed, _ := rotated.Rewrap(env); changed {
+		t.Error("Rewrap() of an envelope wrapped by the primary key changed it")
+	}
+
+	retired, _ := New(config.EncryptionConfig{PrimaryKey: "new", Keys: map[string]string{"new": key(2)}})
+	if _, err := retired.Open(env); err != nil {
+		t.Errorf("Open() after retiring the old key = %v", err)
+	}
+	if _, err := old.Open(env); err == nil {
+		t.Error("Open() without the wrapping key succeeded")
+	}
+}
+
+func TestFields(t *testing.T) {
+	k := newKeyring(t, "a", "a", "b")
+	data := map[string]interface{}{
+		"email": "jane@example.com",
+		"tags":  []interface{}{"vip"},
+		"price": "19.99",
+		"note":  nil,
+	}
+	sealed, err := k.SealFields(data, []string{"tags", "email", "note", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sealed, []string{"email", "tags"}) {
+		t.Errorf("SealFields() = %v", sealed)
+	}
+
+	// Stored as JSON and read back
+	raw, _ := json.Marshal(data)
+	if bytes.Contains(raw, []byte("jane")) || !bytes.Contains(raw, []byte("19.99")) {
+		t.Fatalf("stored data = %s", raw)
+	}
+	var stored map[string]interface{}
+	json.Unmarshal(raw, &stored)
+
+	k.primary = "b"
+	if changed, err := k.RewrapFields(stored); err != nil || !changed {
+		t.Fatalf("RewrapFields() = %v, %v", changed, err)
+	}
+	if env, _ := envelopeOf(stored["email"]); env.KeyID != "b" {
+		t.Errorf("rewrapped field key = %s, want b", env.KeyID)
+	}
+	if err := k.OpenFields(stored); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"email": "jane@example.com", "tags": []interface{}{"vip"}, "price": "19.99", "note": nil}
+	if !reflect.DeepEqual(stored, want) {
+		t.Errorf("OpenFields() = %v, want %v", stored, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if k, err := New(config.EncryptionConfig{}); k != nil || err != nil {
+		t.Errorf("New() without keys = %v, %v; want nil", k, err)
+	}
+	for _, cfg := range []config.EncryptionConfig{
+		{PrimaryKey: "a", Keys: map[string]string{"a": "c2hvcnQ="}},
+		{PrimaryKey: "a", Keys: map[string]string{"a": "secret://vault/keys#a"}},
+		{PrimaryKey: "b", Keys: map[string]string{"a": key(1)}},
+		{Keys: map[string]string{"a": key(1)}},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) expected an error", cfg)
+		}
+	}
+	var k *Keyring
+	if _, err := k.Open(&Envelope{Version: Version, Algorithm: Algorithm, KeyID: "a"}); err == nil {
+		t.Error("Open() without keyring succeeded")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, data := range []string{``, `[]`, `{"url":"https://example.com"}`, `{"kid":"a"}`, `not json`} {
+		if _, ok := Parse([]byte(data)); ok {
+			t.Errorf("Parse(%q) took plain data for an envelope", data)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package encryption
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// SealFields replaces the named fields of a parsed record's data by their
+// envelopes. Missing and null fields are left out. It returns the names of
+// the fields it encrypted, in order.
+func (k *Keyring) SealFields(data map[string]interface{}, names []string) ([]string, error) {
+	var sealed []string
+	for _, name := range names {
+		value, ok := data[name]
+		if !ok || value == nil {
+			continue
+		}
+		if _, ok := envelopeOf(value); ok {
+			continue // Already encrypted, e.g. by an earlier pass
+		}
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		env, err := k.Seal(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt field %s: %w", name, err)
+		}
+		data[name] = env
+		sealed = append(sealed, name)
+	}
+	sort.Strings(sealed)
+	return sealed, nil
+}
+
+// OpenFields replaces the envelopes among the fields of a parsed record's
+// data, as decoded from JSON, by the values they encrypt
+func (k *Keyring) OpenFields(data map[string]interface{}) error {
+	for name, value := range data {
+		env, ok := envelopeOf(value)
+		if !ok {
+			continue
+		}
+		plaintext, err := k.Open(env)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(plaintext, &decoded); err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		data[name] = decoded
+	}
+	return nil
+}
+
+// RewrapFields wraps the data keys of the encrypted fields of a parsed
+// record's data by the primary key, see Rewrap. It reports whether any
+// field changed.
+func (k *Keyring) RewrapFields(data map[string]interface{}) (bool, error) {
+	changed := false
+	for name, value := range data {
+		env, ok := envelopeOf(value)
+		if !ok {
+			continue
+		}
+		rewrapped, err := k.Rewrap(env)
+		if err != nil {
+			return false, fmt.Errorf("field %s: %w", name, err)
+		}
+		if rewrapped {
+			data[name] = env
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// envelopeOf returns the envelope of a field value: an *Envelope, or an
+// envelope decoded from JSON as an object
+func envelopeOf(value interface{}) (*Envelope, bool) {
+	switch v := value.(type) {
+	case *Envelope:
+		return v, true
+	case map[string]interface{}:
+		if _, ok := v["kid"]; !ok {
+			return nil, false
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		return Parse(raw)
+	}
+	return nil, false
+}
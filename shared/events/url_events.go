@@ -0,0 +1,70 @@
+// Package events publishes URL lifecycle events to Kafka so downstream
+// systems, such as search indexers, billing or notifications, can react to
+// URLs being created, changed or deleted without polling the database.
+package events
+
+import (
+	"context"
+	"time"
+
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// publishTimeout bounds how long publishing may hold up the change that
+// caused the events
+const publishTimeout = 5 * time.Second
+
+// Sender sends a message to a Kafka topic, see kafka.Producer
+type Sender interface {
+	SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error
+}
+
+// URLEventPublisher publishes URL events to the URL events topic. Publishing
+// is best effort: the change has already been made, so failures are logged
+// instead of returned. A nil publisher drops events.
+type URLEventPublisher struct {
+	sender Sender
+	topic  string
+	source string
+	logger *logrus.Logger
+}
+
+// NewURLEventPublisher creates a publisher that sends events from the named
+// source service to topic
+func NewURLEventPublisher(sender Sender, topic, source string, logger *logrus.Logger) *URLEventPublisher {
+	return &URLEventPublisher{
+		sender: sender,
+		topic:  topic,
+		source: source,
+		logger: logger,
+	}
+}
+
+// Publish sends events, keyed by URL ID
+func (p *URLEventPublisher) Publish(ctx context.Context, events ...sharedmodels.URLEvent) {
+	if p == nil || len(events) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	for _, event := range events {
+		event.Source = p.source
+		headers := map[string]string{"type": event.Type}
+		if err := p.sender.SendMessage(ctx, p.topic, event.URLID.String(), event, headers); err != nil {
+			p.logger.WithError(err).WithFields(logrus.Fields{
+				"topic":  p.topic,
+				"type":   event.Type,
+				"url_id": event.URLID,
+			}).Error("Failed to publish URL event")
+		}
+	}
+}
+
+// StatusChanged publishes the event for a status change. It can be
+// registered as a status change listener of the URL repository.
+func (p *URLEventPublisher) StatusChanged(ctx context.Context, change sharedmodels.URLStatusChange) {
+	p.Publish(ctx, sharedmodels.NewURLStatusEvent(change))
+}
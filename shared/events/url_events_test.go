@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/contract"
+	sharedmodels "go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type sentMessage struct {
+	topic   string
+	key     string
+	value   interface{}
+	headers map[string]string
+}
+
+type fakeSender struct {
+	sent []sentMessage
+	err  error
+}
+
+func (f *fakeSender) SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error {
+	f.sent = append(f.sent, sentMessage{topic: topic, key: key, value: value, headers: headers})
+	return f.err
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestURLEventPublisherPublish(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewURLEventPublisher(sender, "url-events", "api-gateway", testLogger())
+
+	created := sharedmodels.NewURLEvent(sharedmodels.URLEventCreated, uuid.New(), "https://example.com")
+	publisher.Publish(context.Background(), created)
+	publisher.StatusChanged(context.Background(), sharedmodels.URLStatusChange{URLID: created.URLID, From: "pending", To: "paused"})
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("sent %d messages, want 2", len(sender.sent))
+	}
+	for i, wantType := range []string{sharedmodels.URLEventCreated, sharedmodels.URLEventPaused} {
+		msg := sender.sent[i]
+		event, ok := msg.value.(sharedmodels.URLEvent)
+		if !ok {
+			t.Fatalf("message %d value = %T, want URLEvent", i, msg.value)
+		}
+		if msg.topic != "url-events" || msg.key != created.URLID.String() || msg.headers["type"] != wantType {
+			t.Errorf("message %d = topic %q, key %q, headers %v", i, msg.topic, msg.key, msg.headers)
+		}
+		if event.Type != wantType || event.Source != "api-gateway" {
+			t.Errorf("message %d event = %+v", i, event)
+		}
+	}
+}
+
+func TestURLEventPublisherIgnoresFailures(t *testing.T) {
+	sender := &fakeSender{err: errors.New("broker unavailable")}
+	publisher := NewURLEventPublisher(sender, "url-events", "url-manager", testLogger())
+
+	publisher.Publish(context.Background(),
+		sharedmodels.NewURLEvent(sharedmodels.URLEventDeleted, uuid.New(), ""),
+		sharedmodels.NewURLEvent(sharedmodels.URLEventDeleted, uuid.New(), ""),
+	)
+	if len(sender.sent) != 2 {
+		t.Errorf("sent %d messages, want every event attempted", len(sender.sent))
+	}
+
+	var nilPublisher *URLEventPublisher
+	nilPublisher.Publish(context.Background(), sharedmodels.NewURLEvent(sharedmodels.URLEventCreated, uuid.New(), ""))
+}
+
+func TestURLEventContract(t *testing.T) {
+	sender := &fakeSender{}
+	publisher := NewURLEventPublisher(sender, "url-events", "url-manager", testLogger())
+	urlID := uuid.New()
+	publisher.Publish(context.Background(), sharedmodels.NewURLEvent(sharedmodels.URLEventCreated, urlID, "https://example.com"))
+	publisher.StatusChanged(context.Background(), sharedmodels.URLStatusChange{URLID: urlID, From: "retry", To: "failed", ChangedAt: time.Now()})
+
+	// Between them, the events use every field of the contract and no other
+	fields := make(map[string]bool)
+	for _, msg := range sender.sent {
+		data, err := json.Marshal(msg.value)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var event sharedmodels.URLEvent
+		if err := contract.DecodeStrict(data, &event); err != nil {
+			t.Fatalf("DecodeStrict() error = %v", err)
+		}
+		keys, _ := contract.Keys(data)
+		for _, key := range keys {
+			fields[key] = true
+		}
+	}
+	want, _ := contract.Keys(contract.Fixture(contract.URLEvent))
+	var got []string
+	for _, key := range want {
+		if fields[key] {
+			got = append(got, key)
+		}
+	}
+	if len(got) != len(want) || len(fields) != len(want) {
+		t.Errorf("event fields = %v, want those of the contract %v", fields, want)
+	}
+}
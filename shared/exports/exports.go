@@ -0,0 +1,98 @@
+// Package exports writes parsed records to export files and keeps them in a
+// Store, which hands out the links they are downloaded with. The URL Manager
+// uploads the files to the bucket of exports.storage, and the API Gateway
+// hands out presigned links of the bucket instead of streaming the records
+// itself. Without a bucket, files go to exports.directory, storage shared
+// with the API Gateway, which serves them through links signed with
+// exports.signing_key. A link names one export and expires, so it can be
+// passed to other systems without credentials.
+package exports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// Record is a parsed record as written to export files
+type Record struct {
+	ID          string          `json:"id"`
+	URLID       string          `json:"url_id"`
+	URL         string          `json:"url"`
+	Schema      string          `json:"schema"`
+	Title       string          `json:"title,omitempty"`
+	Content     string          `json:"content,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	ContentHash string          `json:"content_hash"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// csvHeader lists the CSV columns; data holds the parsed fields as JSON
+var csvHeader = []string{"id", "url_id", "url", "schema", "title", "content", "content_hash", "created_at", "data"}
+
+// Writer writes records to an export file in one of the export formats
+type Writer struct {
+	format string
+	json   *json.Encoder
+	csv    *csv.Writer
+}
+
+// NewWriter creates a writer of the format, config.ExportFormatJSONL or
+// config.ExportFormatCSV. CSV files start with a header row.
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	switch format {
+	case config.ExportFormatJSONL:
+		return &Writer{format: format, json: json.NewEncoder(w)}, nil
+	case config.ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &Writer{format: format, csv: cw}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// Write writes a record
+func (w *Writer) Write(record Record) error {
+	if w.json != nil {
+		return w.json.Encode(record)
+	}
+	return w.csv.Write([]string{
+		record.ID,
+		record.URLID,
+		record.URL,
+		record.Schema,
+		record.Title,
+		record.Content,
+		record.ContentHash,
+		record.CreatedAt.UTC().Format(time.RFC3339),
+		string(record.Data),
+	})
+}
+
+// Flush writes any buffered records to the underlying writer
+func (w *Writer) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// FileName returns the name of the file of an export
+func FileName(id, format string) string {
+	return id + "." + format
+}
+
+// ContentType returns the media type of files in an export format
+func ContentType(format string) string {
+	if format == config.ExportFormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
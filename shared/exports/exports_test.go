@@ -0,0 +1,126 @@
+package exports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+var record = Record{
+	ID:          "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+	URLID:       "123e4567-e89b-12d3-a456-426614174000",
+	URL:         "https://example.com/a",
+	Schema:      "article",
+	Title:       "Hello, world",
+	Data:        json.RawMessage(`{"price":"19.99"}`),
+	ContentHash: "abc",
+	CreatedAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, config.ExportFormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,url_id,url,schema,title,content,content_hash,created_at,data\n" +
+		`7c9e6679-7425-40de-944b-e07fc1f90ae7,123e4567-e89b-12d3-a456-426614174000,https://example.com/a,article,"Hello, world",,abc,2024-01-01T12:00:00Z,"{""price"":""19.99""}"` + "\n"
+	if buf.String() != want {
+		t.Errorf("CSV export = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	w, _ = NewWriter(&buf, config.ExportFormatJSONL)
+	w.Write(record)
+	w.Write(record)
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("JSON Lines export has %d lines, want 2", lines)
+	}
+	var got Record
+	if err := json.Unmarshal([]byte(strings.SplitN(buf.String(), "\n", 2)[0]), &got); err != nil || got.Title != record.Title {
+		t.Errorf("JSON Lines record = %+v, %v", got, err)
+	}
+
+	if _, err := NewWriter(&buf, "xml"); err == nil {
+		t.Error("NewWriter() accepted xml")
+	}
+}
+
+func TestSignerLink(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner("s3cr3t")
+	link := signer.Link("https://exports.example.com/", "job-1", now.Add(15*time.Minute))
+	u, err := url.Parse(link)
+	if err != nil || u.Host != "exports.example.com" || u.Path != DownloadPath("job-1") {
+		t.Fatalf("Link() = %s", link)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	if err := signer.Verify("job-1", expires, signature, now); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if err := signer.Verify("job-1", expires, signature, now.Add(15*time.Minute)); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("Verify() at expiry = %v, want ErrLinkExpired", err)
+	}
+	for name, args := range map[string][3]string{
+		"other export":    {"job-2", expires, signature},
+		"extended expiry": {"job-1", "9999999999", signature},
+		"other key":       {"job-1", expires, NewSigner("other").sign("job-1", now.Add(15*time.Minute).Unix())},
+		"missing":         {"job-1", "", ""},
+		"not hex":         {"job-1", expires, "zz"},
+	} {
+		if err := signer.Verify(args[0], args[1], args[2], now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify() = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	if link := signer.Link("", "job-1", now); !strings.HasPrefix(link, "/api/v1/data/exports/job-1/download?") {
+		t.Errorf("Link() without base URL = %s", link)
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "exports")
+	store, err := NewStore(config.ExportsConfig{Directory: dir, SigningKey: "s3cr3t", BaseURL: "https://exports.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := store.Put(ctx, "job-1.csv", "text/csv", strings.NewReader("id\n"), 3); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "job-1.csv")); err != nil || string(content) != "id\n" {
+		t.Fatalf("stored file = %q, %v", content, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d files, want the export alone", len(entries))
+	}
+
+	now := time.Now()
+	u, _ := url.Parse(store.DownloadURL("job-1.csv", "crm-job-1.csv", now.Add(time.Minute)))
+	if err := NewSigner("s3cr3t").Verify("job-1", u.Query().Get("expires"), u.Query().Get("signature"), now); err != nil || u.Path != DownloadPath("job-1") {
+		t.Errorf("DownloadURL() = %s, verify error %v", u, err)
+	}
+
+	if err := store.Delete(ctx, "job-1.csv"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete(ctx, "job-1.csv"); err != nil {
+		t.Errorf("Delete() of a missing file error = %v", err)
+	}
+}
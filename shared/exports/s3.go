@@ -0,0 +1,161 @@
+package exports
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go_scraping_project/shared/awssig"
+	"go_scraping_project/shared/config"
+)
+
+// maxPresignExpiry is the longest lifetime S3 accepts for a presigned URL
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Store keeps export files in an S3-compatible bucket (AWS S3, Google
+// Cloud Storage with HMAC keys, MinIO) and hands out presigned links of the
+// bucket, so downloads never pass through the services. Requests are signed
+// with AWS Signature Version 4.
+type S3Store struct {
+	bucket          string
+	region          string
+	endpoint        *url.URL
+	pathStyle       bool
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	client          *http.Client
+	now             func() time.Time
+}
+
+// NewS3Store creates a store of the bucket of exports.storage. Without
+// credentials in the settings, the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables are used.
+func NewS3Store(cfg config.ExportStorageConfig) (*S3Store, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &S3Store{
+		bucket:          cfg.Bucket,
+		region:          cfg.Region,
+		endpoint:        u,
+		pathStyle:       cfg.PathStyle,
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		client:          &http.Client{}, // Uploads are bounded by their context
+		now:             time.Now,
+	}
+	if s.accessKeyID == "" && s.secretAccessKey == "" {
+		s.accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if s.accessKeyID == "" || s.secretAccessKey == "" {
+		return nil, errors.New("storage credentials are not set, configure access_key_id and secret_access_key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return s, nil
+}
+
+// Put uploads the file of key
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	return s.do(req, http.StatusOK)
+}
+
+// Delete deletes the object of key
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+// DownloadURL returns a presigned GET of the object of key. Its lifetime is
+// cut to the 7 days S3 accepts.
+func (s *S3Store) DownloadURL(key, filename string, expires time.Time) string {
+	now := s.now().UTC()
+	ttl := min(max(expires.Sub(now), time.Second), maxPresignExpiry)
+
+	query := url.Values{}
+	if filename != "" {
+		query.Set("response-content-disposition", "attachment; filename="+filename)
+	}
+	return s.signer().Presign(*s.objectURL(key), query, ttl, now)
+}
+
+// objectURL returns the URL of the object of key, with the bucket in the
+// host name or, for path-style endpoints, in the path
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	path := "/" + key
+	if s.pathStyle {
+		path = "/" + s.bucket + path
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = u.Path + path
+	u.RawPath = awssig.URIEncode(u.Path, false)
+	return &u
+}
+
+// s3Error is the error body of S3 requests
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do signs and sends a request, returning an error unless its status is
+// one of ok
+func (s *S3Store) do(req *http.Request, ok ...int) error {
+	// Uploads are streamed, so their payload is not hashed
+	req.Header.Set("X-Amz-Content-Sha256", awssig.UnsignedPayload)
+	s.signer().Sign(req, awssig.UnsignedPayload, s.now(), "x-amz-content-sha256")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("storage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	for _, status := range ok {
+		if resp.StatusCode == status {
+			io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+	var apiErr s3Error
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+	return fmt.Errorf("storage %s %s returned status %d: %s %s", req.Method, req.URL.Path, resp.StatusCode, apiErr.Code, apiErr.Message)
+}
+
+// signer returns the Signature Version 4 signer of the store's credentials
+func (s *S3Store) signer() awssig.Signer {
+	return awssig.Signer{
+		Region:          s.region,
+		Service:         "s3",
+		AccessKeyID:     s.accessKeyID,
+		SecretAccessKey: s.secretAccessKey,
+		SessionToken:    s.sessionToken,
+	}
+}
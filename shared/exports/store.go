@@ -0,0 +1,91 @@
+package exports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// Store keeps the files of exports under keys, the artifacts of the export
+// jobs, and hands out links to download them
+type Store interface {
+	// Put stores the file of key, size bytes read from body
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// Delete deletes the file of key; a missing file is not an error
+	Delete(ctx context.Context, key string) error
+	// DownloadURL returns a link downloading the file of key as filename
+	// until expires, which needs no other credentials
+	DownloadURL(key, filename string, expires time.Time) string
+}
+
+// NewStore creates the store of the exports settings: the bucket of
+// exports.storage, or else exports.directory
+func NewStore(cfg config.ExportsConfig) (Store, error) {
+	if cfg.Storage.Enabled() {
+		return NewS3Store(cfg.Storage)
+	}
+	if cfg.Directory == "" {
+		return nil, errors.New("data exports are not configured")
+	}
+	return NewDirStore(cfg.Directory, NewSigner(cfg.SigningKey), cfg.BaseURL), nil
+}
+
+// DirStore keeps export files in a directory shared by the URL Manager and
+// the API Gateway, which serves them through links signed by signer
+type DirStore struct {
+	dir     string
+	signer  *Signer
+	baseURL string
+}
+
+// NewDirStore creates a store of the files in dir. Links point at baseURL,
+// or are relative when it is empty.
+func NewDirStore(dir string, signer *Signer, baseURL string) *DirStore {
+	return &DirStore{dir: dir, signer: signer, baseURL: baseURL}
+}
+
+// Put writes the file under a temporary name and renames it once complete,
+// so a link never serves a partial export
+func (s *DirStore) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create exports directory: %w", err)
+	}
+	path := filepath.Join(s.dir, key)
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // No-op once renamed
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Delete removes the file of key
+func (s *DirStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(s.dir, key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// DownloadURL returns a link of the API Gateway signed for the export of
+// key. The gateway names the file itself.
+func (s *DirStore) DownloadURL(key, filename string, expires time.Time) string {
+	return s.signer.Link(s.baseURL, strings.TrimSuffix(key, filepath.Ext(key)), expires)
+}
@@ -0,0 +1,248 @@
+// Package features evaluates feature flags used to roll out new behaviour
+// gradually and toggle it without a redeploy.
+//
+// Flags come from two layers: the features.flags section of the service
+// configuration, and the feature_flags and feature_flag_overrides tables.
+// A flag stored in the database replaces the configured flag of the same
+// name; per-tenant overrides from both layers are merged, with the database
+// taking precedence.
+package features
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sources a flag definition can come from
+const (
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
+
+// Flag is the effective definition of a feature flag
+type Flag struct {
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Enabled        bool            `json:"enabled"`
+	RolloutPercent int             `json:"rollout_percent"`
+	Tenants        map[string]bool `json:"tenants,omitempty"`
+	Source         string          `json:"source"`
+}
+
+// EnabledFor reports whether the flag is on for a tenant. A tenant override
+// wins; otherwise the flag must be enabled and the tenant must fall inside
+// the rollout percentage. Tenants are assigned to rollout buckets by a stable
+// hash, so raising the percentage only ever adds tenants. Without a tenant
+// the flag is only on at a 100% rollout.
+func (f Flag) EnabledFor(tenant string) bool {
+	tenant = NormalizeTenant(tenant)
+	if tenant != "" {
+		if enabled, ok := f.Tenants[tenant]; ok {
+			return enabled
+		}
+	}
+
+	if !f.Enabled {
+		return false
+	}
+	if f.RolloutPercent >= 100 {
+		return true
+	}
+	if tenant == "" || f.RolloutPercent <= 0 {
+		return false
+	}
+	return rolloutBucket(f.Name, tenant) < f.RolloutPercent
+}
+
+// Override enables or disables a flag for a single tenant
+type Override struct {
+	Flag    string
+	Tenant  string
+	Enabled bool
+}
+
+// Store loads flags and tenant overrides persisted outside configuration
+type Store interface {
+	ListFlags(ctx context.Context) ([]Flag, error)
+	ListOverrides(ctx context.Context) ([]Override, error)
+}
+
+// Flags evaluates feature flags from configuration and an optional store
+type Flags struct {
+	store  Store
+	logger *logrus.Logger
+
+	mu         sync.RWMutex
+	configured map[string]Flag
+	stored     []Flag
+	overrides  []Override
+	flags      map[string]Flag
+}
+
+// New creates a flag set from configuration. store may be nil, in which case
+// only configured flags are used.
+func New(cfg config.FeaturesConfig, store Store, logger *logrus.Logger) *Flags {
+	f := &Flags{store: store, logger: logger}
+	f.UpdateConfig(cfg)
+	return f
+}
+
+// UpdateConfig replaces the configured flags, e.g. after a configuration reload
+func (f *Flags) UpdateConfig(cfg config.FeaturesConfig) {
+	configured := make(map[string]Flag, len(cfg.Flags))
+	for name, flagCfg := range cfg.Flags {
+		flag := Flag{
+			Name:           name,
+			Enabled:        flagCfg.Enabled,
+			RolloutPercent: 100,
+			Source:         SourceConfig,
+		}
+		if flagCfg.RolloutPercent != nil {
+			flag.RolloutPercent = *flagCfg.RolloutPercent
+		}
+		if len(flagCfg.Tenants) > 0 {
+			flag.Tenants = make(map[string]bool, len(flagCfg.Tenants))
+			for tenant, enabled := range flagCfg.Tenants {
+				flag.Tenants[NormalizeTenant(tenant)] = enabled
+			}
+		}
+		configured[name] = flag
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configured = configured
+	f.merge()
+}
+
+// Refresh reloads flags and overrides from the store. On error the
+// previously loaded values stay in effect.
+func (f *Flags) Refresh(ctx context.Context) error {
+	if f.store == nil {
+		return nil
+	}
+
+	stored, err := f.store.ListFlags(ctx)
+	if err != nil {
+		return err
+	}
+	overrides, err := f.store.ListOverrides(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stored = stored
+	f.overrides = overrides
+	f.merge()
+	return nil
+}
+
+// Run refreshes flags from the store every interval until ctx is cancelled
+func (f *Flags) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Refresh(ctx); err != nil && ctx.Err() == nil {
+				f.logger.WithError(err).Warn("Failed to refresh feature flags, keeping previous values")
+			}
+		}
+	}
+}
+
+// Enabled reports whether the named flag is on for a tenant.
+// Unknown flags are off.
+func (f *Flags) Enabled(name, tenant string) bool {
+	flag, ok := f.Lookup(name)
+	return ok && flag.EnabledFor(tenant)
+}
+
+// Lookup returns the effective definition of a flag
+func (f *Flags) Lookup(name string) (Flag, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	flag, ok := f.flags[name]
+	return flag, ok
+}
+
+// All returns the effective definition of every known flag, sorted by name
+func (f *Flags) All() []Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	flags := make([]Flag, 0, len(f.flags))
+	for _, flag := range f.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// merge rebuilds the effective flags. The caller must hold f.mu.
+func (f *Flags) merge() {
+	flags := make(map[string]Flag, len(f.configured)+len(f.stored))
+	for name, flag := range f.configured {
+		flag.Tenants = copyTenants(flag.Tenants)
+		flags[name] = flag
+	}
+
+	for _, flag := range f.stored {
+		flag.Source = SourceDatabase
+		flag.Tenants = copyTenants(f.configured[flag.Name].Tenants)
+		flags[flag.Name] = flag
+	}
+
+	// Overrides may target flags that are only defined by them, which
+	// enables a flag for specific tenants without turning it on globally
+	for _, override := range f.overrides {
+		flag, ok := flags[override.Flag]
+		if !ok {
+			flag = Flag{Name: override.Flag, RolloutPercent: 100, Source: SourceDatabase}
+		}
+		if flag.Tenants == nil {
+			flag.Tenants = make(map[string]bool)
+		}
+		flag.Tenants[NormalizeTenant(override.Tenant)] = override.Enabled
+		flags[override.Flag] = flag
+	}
+
+	f.flags = flags
+}
+
+// NormalizeTenant returns the canonical form of a tenant ID. Tenant IDs are
+// case-insensitive because configuration keys are lowercased when loaded.
+func NormalizeTenant(tenant string) string {
+	return strings.ToLower(strings.TrimSpace(tenant))
+}
+
+// rolloutBucket maps a tenant to a stable bucket in [0, 100) for a flag
+func rolloutBucket(flag, tenant string) int {
+	h := fnv.New32a()
+	h.Write([]byte(flag + ":" + tenant))
+	return int(h.Sum32() % 100)
+}
+
+func copyTenants(tenants map[string]bool) map[string]bool {
+	if tenants == nil {
+		return nil
+	}
+	copied := make(map[string]bool, len(tenants))
+	for tenant, enabled := range tenants {
+		copied[tenant] = enabled
+	}
+	return copied
+}
@@ -0,0 +1,153 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Example flag names
+const (
+	jsRendering        = "js_rendering"
+	adaptiveScheduling = "adaptive_scheduling"
+	parserV2           = "parser_v2"
+)
+
+type fakeStore struct {
+	flags     []Flag
+	overrides []Override
+	err       error
+}
+
+func (s *fakeStore) ListFlags(ctx context.Context) ([]Flag, error) {
+	return s.flags, s.err
+}
+
+func (s *fakeStore) ListOverrides(ctx context.Context) ([]Override, error) {
+	return s.overrides, s.err
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func percent(p int) *int { return &p }
+
+func TestFlagEnabledFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		flag   Flag
+		tenant string
+		want   bool
+	}{
+		{name: "disabled", flag: Flag{Name: "f", RolloutPercent: 100}, tenant: "acme", want: false},
+		{name: "enabled", flag: Flag{Name: "f", Enabled: true, RolloutPercent: 100}, tenant: "acme", want: true},
+		{name: "enabled without tenant", flag: Flag{Name: "f", Enabled: true, RolloutPercent: 100}, want: true},
+		{name: "partial rollout without tenant", flag: Flag{Name: "f", Enabled: true, RolloutPercent: 99}, want: false},
+		{name: "zero rollout", flag: Flag{Name: "f", Enabled: true}, tenant: "acme", want: false},
+		{name: "tenant enabled override", flag: Flag{Name: "f", Tenants: map[string]bool{"acme": true}}, tenant: "ACME", want: true},
+		{name: "tenant disabled override", flag: Flag{Name: "f", Enabled: true, RolloutPercent: 100, Tenants: map[string]bool{"acme": false}}, tenant: "acme", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.EnabledFor(tt.tenant); got != tt.want {
+				t.Errorf("EnabledFor(%q) = %v, want %v", tt.tenant, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagRolloutIsStableAndMonotonic(t *testing.T) {
+	half := Flag{Name: jsRendering, Enabled: true, RolloutPercent: 50}
+	more := Flag{Name: jsRendering, Enabled: true, RolloutPercent: 80}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		tenant := fmt.Sprintf("tenant-%d", i)
+		if half.EnabledFor(tenant) {
+			enabled++
+			if !more.EnabledFor(tenant) {
+				t.Fatalf("%s dropped out when rollout increased", tenant)
+			}
+		}
+		if half.EnabledFor(tenant) != half.EnabledFor(tenant) {
+			t.Fatalf("%s evaluated inconsistently", tenant)
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Errorf("50%% rollout enabled %d of 1000 tenants", enabled)
+	}
+}
+
+func TestFlagsLayering(t *testing.T) {
+	cfg := config.FeaturesConfig{Flags: map[string]config.FeatureFlagConfig{
+		jsRendering:        {Enabled: false, Tenants: map[string]bool{"acme": true, "globex": true}},
+		adaptiveScheduling: {Enabled: true, RolloutPercent: percent(0)},
+	}}
+	store := &fakeStore{
+		flags: []Flag{{Name: adaptiveScheduling, Enabled: true, RolloutPercent: 100}},
+		overrides: []Override{
+			{Flag: jsRendering, Tenant: "globex", Enabled: false},
+			{Flag: parserV2, Tenant: "Initech", Enabled: true},
+		},
+	}
+
+	flags := New(cfg, store, quietLogger())
+	if flags.Enabled(adaptiveScheduling, "acme") {
+		t.Error("adaptive_scheduling enabled before the store was loaded")
+	}
+
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		flag   string
+		tenant string
+		want   bool
+	}{
+		{jsRendering, "acme", true},        // config tenant override
+		{jsRendering, "globex", false},     // database override wins over config
+		{jsRendering, "other", false},      // globally disabled
+		{adaptiveScheduling, "acme", true}, // database flag replaces config
+		{parserV2, "initech", true},        // override-only flag
+		{parserV2, "other", false},         // override-only flag is off globally
+		{"unknown_flag", "acme", false},    // unknown flags are off
+	}
+	for _, tt := range tests {
+		if got := flags.Enabled(tt.flag, tt.tenant); got != tt.want {
+			t.Errorf("Enabled(%q, %q) = %v, want %v", tt.flag, tt.tenant, got, tt.want)
+		}
+	}
+
+	if flag, _ := flags.Lookup(adaptiveScheduling); flag.Source != SourceDatabase {
+		t.Errorf("adaptive_scheduling source = %q, want %q", flag.Source, SourceDatabase)
+	}
+	if all := flags.All(); len(all) != 3 || all[0].Name != adaptiveScheduling {
+		t.Errorf("All() = %+v", all)
+	}
+}
+
+func TestFlagsKeepValuesOnFailedRefresh(t *testing.T) {
+	store := &fakeStore{flags: []Flag{{Name: parserV2, Enabled: true, RolloutPercent: 100}}}
+	flags := New(config.FeaturesConfig{}, store, quietLogger())
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	store.err = fmt.Errorf("connection refused")
+	if err := flags.Refresh(context.Background()); err == nil {
+		t.Fatal("expected Refresh() to fail")
+	}
+	if !flags.Enabled(parserV2, "") {
+		t.Error("flag lost after failed refresh")
+	}
+}
@@ -0,0 +1,61 @@
+package features
+
+import (
+	"context"
+
+	"go_scraping_project/shared/database"
+)
+
+// Querier is the subset of database queries used by DBStore
+type Querier interface {
+	ListFeatureFlags(ctx context.Context) ([]database.FeatureFlag, error)
+	ListFeatureFlagOverrides(ctx context.Context) ([]database.FeatureFlagOverride, error)
+}
+
+// DBStore loads flags from the feature_flags and feature_flag_overrides tables
+type DBStore struct {
+	db Querier
+}
+
+// NewDBStore creates a store backed by the given queries
+func NewDBStore(db Querier) *DBStore {
+	return &DBStore{db: db}
+}
+
+// ListFlags returns the flags stored in the database
+func (s *DBStore) ListFlags(ctx context.Context) ([]Flag, error) {
+	rows, err := s.db.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		flags = append(flags, Flag{
+			Name:           row.Name,
+			Description:    row.Description.String,
+			Enabled:        row.Enabled,
+			RolloutPercent: int(row.RolloutPercent),
+			Source:         SourceDatabase,
+		})
+	}
+	return flags, nil
+}
+
+// ListOverrides returns the tenant overrides stored in the database
+func (s *DBStore) ListOverrides(ctx context.Context) ([]Override, error) {
+	rows, err := s.db.ListFeatureFlagOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]Override, 0, len(rows))
+	for _, row := range rows {
+		overrides = append(overrides, Override{
+			Flag:    row.FlagName,
+			Tenant:  row.TenantID,
+			Enabled: row.Enabled,
+		})
+	}
+	return overrides, nil
+}
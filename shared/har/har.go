@@ -0,0 +1,419 @@
+// Package har records the HTTP exchanges of a scrape as a HAR 1.2 document
+// (HTTP Archive), for investigating why a site returns different content to
+// the scrapers than to a browser. Scrapers record a HAR when a task asks for
+// one (capture_har) and send it with the scrape result; the document can be
+// opened in a browser's developer tools or any HAR viewer.
+//
+// Credentials are not recorded: the values of the Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie headers and of cookies are
+// replaced by "redacted".
+package har
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultBodyPreview is the number of bytes of each response body kept when
+// no limit is configured
+const DefaultBodyPreview = 64 * 1024
+
+// redacted replaces the values of credentials
+const redacted = "redacted"
+
+// redactedHeaders are the headers whose values are not recorded
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// HAR is a HAR 1.2 document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the root of a HAR document
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the application that recorded the HAR
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a request and its response; every redirect followed is an entry
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"` // Total time of the exchange in milliseconds
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	Comment         string    `json:"comment,omitempty"` // Error of a request that got no response
+}
+
+// Request is a recorded request
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Response is a recorded response. Status is 0 when none was received.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// Content is the body of a response. Text holds its first bytes, up to the
+// recorder's body preview; binary and compressed bodies are left out.
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"` // Why Text is shortened or missing
+}
+
+// NameValue is a header, cookie or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings breaks the time of an exchange down into its phases, in
+// milliseconds; -1 for phases that did not apply, e.g. DNS and Connect on a
+// reused connection. Connect includes SSL.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Recorder is an http.RoundTripper that records the exchanges made through
+// it. Use it as the scrape's http.Client transport and call HAR once the
+// response bodies are closed.
+type Recorder struct {
+	transport   http.RoundTripper
+	bodyPreview int
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder creates a recorder that sends requests through transport
+// (http.DefaultTransport when nil) and keeps the first bodyPreview bytes of
+// each response body (DefaultBodyPreview when not positive)
+func NewRecorder(transport http.RoundTripper, bodyPreview int) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if bodyPreview <= 0 {
+		bodyPreview = DefaultBodyPreview
+	}
+	return &Recorder{transport: transport, bodyPreview: bodyPreview}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &exchangeTrace{start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		entry := trace.entry(req, nil, time.Now())
+		entry.Comment = err.Error()
+		r.add(entry)
+		return nil, err
+	}
+
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		limit:      r.bodyPreview,
+		done: func(body *recordingBody) {
+			entry := trace.entry(req, resp, time.Now())
+			entry.Response.BodySize = body.size
+			entry.Response.Content = content(resp, body)
+			r.add(entry)
+		},
+	}
+	return resp, nil
+}
+
+// HAR returns the document of the exchanges recorded so far, in the order
+// they started
+func (r *Recorder) HAR() *HAR {
+	r.mu.Lock()
+	entries := append([]Entry{}, r.entries...)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+	return &HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "go-scraping-project", Version: "1.0"},
+		Entries: entries,
+	}}
+}
+
+// add records a finished exchange
+func (r *Recorder) add(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// exchangeTrace collects the timings of one exchange
+type exchangeTrace struct {
+	mu                      sync.Mutex
+	start                   time.Time
+	dnsStart, dnsDone       time.Time
+	connectStart, connected time.Time
+	tlsStart, tlsDone       time.Time
+	gotConn, wroteRequest   time.Time
+	firstByte               time.Time
+	remoteAddr              string
+}
+
+// clientTrace returns the hooks that fill the trace
+func (t *exchangeTrace) clientTrace() *httptrace.ClientTrace {
+	at := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { at(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { at(&t.dnsDone) },
+		ConnectStart:      func(string, string) { at(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { at(&t.connected) },
+		TLSHandshakeStart: func() { at(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { at(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			at(&t.gotConn)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { at(&t.wroteRequest) },
+		GotFirstResponseByte: func() { at(&t.firstByte) },
+	}
+}
+
+// entry builds the entry of the exchange, which ended at end. resp is nil
+// when no response was received.
+func (t *exchangeTrace) entry(req *http.Request, resp *http.Response, end time.Time) Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := Timings{
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, later(t.connected, t.tlsDone)),
+		SSL:     span(t.tlsStart, t.tlsDone),
+		Send:    span(t.gotConn, t.wroteRequest),
+		Wait:    span(t.wroteRequest, t.firstByte),
+		Receive: span(t.firstByte, end),
+	}
+	// Blocked is the time before the connection was looked up or set up,
+	// e.g. waiting for a free connection
+	setup := t.gotConn
+	for _, at := range []time.Time{t.dnsStart, t.connectStart} {
+		if !at.IsZero() && at.Before(setup) {
+			setup = at
+		}
+	}
+	timings.Blocked = span(t.start, setup)
+
+	entry := Entry{
+		StartedDateTime: t.start,
+		Time:            milliseconds(end.Sub(t.start)),
+		Request: Request{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     cookies(req.Cookies()),
+			Headers:     headers(req.Header),
+			QueryString: queryString(req),
+			HeadersSize: -1,
+			BodySize:    max(req.ContentLength, 0),
+		},
+		Response: Response{
+			Cookies: []NameValue{},
+			Headers: []NameValue{},
+		},
+		Timings: timings,
+	}
+	if host, _, err := net.SplitHostPort(t.remoteAddr); err == nil {
+		entry.ServerIPAddress = host
+	}
+	if resp != nil {
+		entry.Response = Response{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Cookies:     cookies(resp.Cookies()),
+			Headers:     headers(resp.Header),
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+		}
+	}
+	return entry
+}
+
+// recordingBody keeps the first bytes of a response body and reports the
+// body once it was read to the end or closed
+type recordingBody struct {
+	io.ReadCloser
+	limit int
+	done  func(*recordingBody)
+
+	preview   bytes.Buffer
+	size      int64
+	truncated bool
+	once      sync.Once
+}
+
+// Read implements io.Reader
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if keep := min(n, b.limit-b.preview.Len()); keep > 0 {
+		b.preview.Write(p[:keep])
+	}
+	if b.size > int64(b.preview.Len()) {
+		b.truncated = true
+	}
+	if err == io.EOF {
+		b.once.Do(func() { b.done(b) })
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b) })
+	return err
+}
+
+// content describes a response body from what was read of it
+func content(resp *http.Response, body *recordingBody) Content {
+	c := Content{Size: body.size, MimeType: resp.Header.Get("Content-Type")}
+	mediaType, _, _ := mime.ParseMediaType(c.MimeType)
+	switch {
+	case resp.Header.Get("Content-Encoding") != "" && !resp.Uncompressed:
+		c.Comment = "compressed body not kept"
+	case !textual(mediaType) || !utf8.Valid(body.preview.Bytes()):
+		if body.size > 0 {
+			c.Comment = "binary body not kept"
+		}
+	default:
+		c.Text = body.preview.String()
+		if body.truncated {
+			c.Comment = "body truncated to the body preview"
+		}
+	}
+	return c
+}
+
+// textual reports whether a media type is text that can be kept in the HAR
+func textual(mediaType string) bool {
+	return mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml") ||
+		strings.Contains(mediaType, "javascript")
+}
+
+// headers converts headers, sorted by name, with credentials redacted
+func headers(header http.Header) []NameValue {
+	values := []NameValue{}
+	for name, list := range header {
+		for _, value := range list {
+			if redactedHeaders[http.CanonicalHeaderKey(name)] {
+				value = redacted
+			}
+			values = append(values, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// cookies lists cookie names with their values redacted
+func cookies(list []*http.Cookie) []NameValue {
+	values := make([]NameValue, 0, len(list))
+	for _, c := range list {
+		values = append(values, NameValue{Name: c.Name, Value: redacted})
+	}
+	return values
+}
+
+// queryString lists the request's query parameters
+func queryString(req *http.Request) []NameValue {
+	values := []NameValue{}
+	for name, list := range req.URL.Query() {
+		for _, value := range list {
+			values = append(values, NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// span returns the milliseconds between two trace points, or -1 if either
+// was not reached
+func span(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	return milliseconds(to.Sub(from))
+}
+
+// later returns the later of two trace points
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
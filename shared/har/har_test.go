@@ -0,0 +1,70 @@
+package har
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRecordsRedirectsAndBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret"})
+			http.Redirect(w, r, "/new?page=2", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html>"+strings.Repeat("x", 100)+"</html>")
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(nil, 16)
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/old", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := (&http.Client{Transport: recorder}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	doc := recorder.HAR()
+	if doc.Log.Version != "1.2" || len(doc.Log.Entries) != 2 {
+		t.Fatalf("HAR = %+v", doc.Log)
+	}
+	redirect, page := doc.Log.Entries[0], doc.Log.Entries[1]
+	if redirect.Response.Status != http.StatusMovedPermanently || redirect.Response.RedirectURL != "/new?page=2" {
+		t.Errorf("redirect response = %+v", redirect.Response)
+	}
+	if page.Response.Status != http.StatusOK || page.Request.QueryString[0] != (NameValue{Name: "page", Value: "2"}) {
+		t.Errorf("page entry = %+v", page)
+	}
+	if page.Response.Content.Text != "<html>xxxxxxxxxx" || page.Response.Content.Size != 113 || page.Response.Content.Comment == "" {
+		t.Errorf("content = %+v", page.Response.Content)
+	}
+	if page.ServerIPAddress != "127.0.0.1" || page.Timings.Wait < 0 || page.Time <= 0 {
+		t.Errorf("server = %q, timings = %+v, time = %v", page.ServerIPAddress, page.Timings, page.Time)
+	}
+
+	encoded, _ := json.Marshal(doc)
+	if strings.Contains(string(encoded), "s3cret") || strings.Contains(string(encoded), "Bearer") {
+		t.Errorf("credentials were recorded: %s", encoded)
+	}
+}
+
+func TestRecorderRecordsFailedRequests(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	recorder := NewRecorder(nil, 0)
+	if _, err := (&http.Client{Transport: recorder}).Get(server.URL); err == nil {
+		t.Fatal("Get() of a closed server succeeded")
+	}
+	entries := recorder.HAR().Log.Entries
+	if len(entries) != 1 || entries[0].Response.Status != 0 || entries[0].Comment == "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,184 @@
+// Package health runs health checks of the components a service depends on,
+// such as the database, Kafka and other services, and combines their results
+// into one report. Checks run concurrently, each bounded by a timeout, and
+// their latencies are reported alongside the result.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Component and overall statuses, from best to worst
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"  // Working, but with reduced capacity or redundancy
+	StatusUnhealthy = "unhealthy" // Not working
+)
+
+// DefaultTimeout bounds a check when the checker has no timeout
+const DefaultTimeout = 5 * time.Second
+
+// ErrDegraded marks a check error as degraded rather than unhealthy, see Degraded
+var ErrDegraded = errors.New("degraded")
+
+// Degraded returns an error that reports a component as degraded
+func Degraded(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrDegraded, fmt.Sprintf(format, args...))
+}
+
+// Check probes a component. It returns nil when the component is healthy,
+// an error made with Degraded when it is degraded and any other error when
+// it is unhealthy.
+type Check func(ctx context.Context) error
+
+// Probe is a component with a dedicated check that has no side effects,
+// such as *kafka.Producer and *kafka.Offsets, which ask the brokers for
+// their metadata and API versions instead of producing a message
+type Probe interface {
+	Ping(ctx context.Context) error
+}
+
+// ComponentHealth is the result of one check
+type ComponentHealth struct {
+	Name    string        `json:"name"`
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Report combines the results of all checks. Its status is the worst status
+// of its components.
+type Report struct {
+	Status     string            `json:"status"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// Checker runs a set of named checks
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewChecker creates a checker whose checks are each bounded by timeout,
+// or DefaultTimeout when timeout is not positive
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, checks: make(map[string]Check)}
+}
+
+// Add registers a check, replacing any check with the same name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// AddProbe registers a probe's Ping as a check
+func (c *Checker) AddProbe(name string, probe Probe) {
+	c.Add(name, probe.Ping)
+}
+
+// Run runs every check concurrently and returns their results sorted by name
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	results := make(chan ComponentHealth, len(checks))
+	for name, check := range checks {
+		go func(name string, check Check) {
+			results <- c.run(ctx, name, check)
+		}(name, check)
+	}
+
+	report := Report{Status: StatusHealthy, CheckedAt: time.Now().UTC(), Components: make([]ComponentHealth, 0, len(checks))}
+	for range checks {
+		result := <-results
+		if rank(result.Status) > rank(report.Status) {
+			report.Status = result.Status
+		}
+		report.Components = append(report.Components, result)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	return report
+}
+
+// run runs one check within the timeout. A check that ignores its context
+// is reported unhealthy once the timeout passes.
+func (c *Checker) run(ctx context.Context, name string, check Check) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	begin := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("no response within %s", c.timeout)
+	}
+
+	result := ComponentHealth{Name: name, Status: StatusHealthy, Latency: time.Since(begin)}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrDegraded):
+		result.Status = StatusDegraded
+		result.Error = err.Error()
+	default:
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// rank orders statuses from best to worst
+func rank(status string) int {
+	switch status {
+	case StatusHealthy:
+		return 0
+	case StatusDegraded:
+		return 1
+	}
+	return 2
+}
+
+// HTTPCheck checks a service by its health endpoint, which must answer GET
+// requests with a 2xx status. client may be nil to use http.DefaultClient.
+func HTTPCheck(client *http.Client, url string) Check {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("health endpoint returned %s", resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckerRun(t *testing.T) {
+	checker := NewChecker(50 * time.Millisecond)
+	checker.Add("database", func(context.Context) error { return nil })
+	checker.Add("workers", func(context.Context) error { return Degraded("1 of 3 scrapers stale") })
+
+	report := checker.Run(context.Background())
+	if report.Status != StatusDegraded {
+		t.Errorf("status = %q, want degraded", report.Status)
+	}
+	if len(report.Components) != 2 || report.Components[0].Name != "database" || report.Components[1].Name != "workers" {
+		t.Fatalf("components = %+v, want database and workers", report.Components)
+	}
+	if report.Components[1].Error != "degraded: 1 of 3 scrapers stale" {
+		t.Errorf("workers error = %q", report.Components[1].Error)
+	}
+
+	// A failing check outranks a degraded one; a stuck check times out
+	release := make(chan struct{})
+	defer close(release)
+	checker.Add("kafka", func(context.Context) error { return errors.New("connection refused") })
+	checker.Add("url-manager", func(context.Context) error { <-release; return nil })
+
+	report = checker.Run(context.Background())
+	if report.Status != StatusUnhealthy {
+		t.Errorf("status = %q, want unhealthy", report.Status)
+	}
+	for _, component := range report.Components {
+		if component.Name == "url-manager" && (component.Status != StatusUnhealthy || component.Latency < 50*time.Millisecond) {
+			t.Errorf("stuck check = %+v, want unhealthy after the timeout", component)
+		}
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	check := HTTPCheck(nil, server.URL+"/health")
+	if err := check(context.Background()); err != nil {
+		t.Errorf("check() error = %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := check(context.Background()); err == nil {
+		t.Error("check() accepted a 503 response")
+	}
+}
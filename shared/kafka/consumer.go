@@ -0,0 +1,350 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// deadLetterTimeout bounds how long storing a dead letter may take, also
+// while the consumer is closing
+const deadLetterTimeout = 10 * time.Second
+
+// MessageHandler is a function type for handling Kafka messages
+type MessageHandler func(ctx context.Context, message *models.KafkaMessage) error
+
+// Consumer represents a Kafka consumer using kafka-go
+type Consumer struct {
+	readers     map[string]*kafka.Reader
+	client      *kafka.Client // Broker requests of Ping
+	dialer      *kafka.Dialer // Connections of readers, nil for the default
+	config      config.KafkaConfig
+	logger      *logrus.Logger
+	handlers    map[string]MessageHandler
+	middleware  []Middleware    // Wraps every handler, see Use
+	deadLetters DeadLetterStore // Keeps messages that exhausted their retries, see SetDeadLetterStore
+	mu          sync.RWMutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	// Topics paused through Pause, see ConsumerCommand; resumed is closed
+	// and replaced whenever a topic is resumed
+	paused    map[string]bool
+	pausedAll bool
+	resumed   chan struct{}
+}
+
+// NewConsumer creates a new Kafka consumer
+func NewConsumer(cfg config.KafkaConfig, log *logrus.Logger, opts ...Option) (*Consumer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("at least one Kafka broker is required")
+	}
+	if cfg.GroupID == "" {
+		return nil, fmt.Errorf("consumer group ID is required")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o := newOptions(opts)
+
+	return &Consumer{
+		readers:  make(map[string]*kafka.Reader),
+		client:   o.client(cfg.Brokers),
+		dialer:   o.dialer(),
+		config:   cfg,
+		logger:   log,
+		handlers: make(map[string]MessageHandler),
+		ctx:      ctx,
+		cancel:   cancel,
+		paused:   make(map[string]bool),
+		resumed:  make(chan struct{}),
+	}, nil
+}
+
+// RegisterHandler registers a message handler for a specific message type
+func (c *Consumer) RegisterHandler(messageType string, handler MessageHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[messageType] = handler
+}
+
+// SetDeadLetterStore makes the consumer store the messages that exhaust
+// their retries, and those that cannot be decoded, in store. Without a
+// store they are only logged. Call it before Consume.
+func (c *Consumer) SetDeadLetterStore(store DeadLetterStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadLetters = store
+}
+
+// getHandler returns the handler for a message type, wrapped with the
+// consumer's middleware
+func (c *Consumer) getHandler(messageType string) (MessageHandler, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	handler, exists := c.handlers[messageType]
+	if !exists {
+		return nil, false
+	}
+	return Chain(handler, c.middleware...), true
+}
+
+// Consume starts consuming messages from the specified topics, following
+// the pause and resume commands of the consumer control topic when one is
+// configured. It blocks until Close is called.
+func (c *Consumer) Consume(topics []string) error {
+	c.logger.WithField("topics", topics).Info("Starting Kafka consumer")
+
+	var wg sync.WaitGroup
+	if control := c.config.Topics.ConsumerControl; control != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.watchControl(control)
+		}()
+	}
+
+	for _, topic := range topics {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:           c.config.Brokers,
+			Topic:             topic,
+			GroupID:           c.config.GroupID,
+			Dialer:            c.dialer,
+			MinBytes:          10e3, // 10KB
+			MaxBytes:          10e6, // 10MB
+			MaxWait:           1 * time.Second,
+			ReadLagInterval:   -1,
+			SessionTimeout:    c.config.SessionTimeout,
+			HeartbeatInterval: c.config.HeartbeatInterval,
+			StartOffset:       startOffset(c.config.AutoOffsetReset),
+			Logger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+				c.logger.Debugf(msg, args...)
+			}),
+		})
+
+		c.mu.Lock()
+		c.readers[topic] = reader
+		c.mu.Unlock()
+
+		wg.Add(1)
+		go func(topic string, reader *kafka.Reader) {
+			defer wg.Done()
+			c.consumeTopic(topic, reader)
+		}(topic, reader)
+	}
+
+	// Wait for context cancellation
+	<-c.ctx.Done()
+
+	// Wait for all goroutines to finish
+	wg.Wait()
+
+	return c.ctx.Err()
+}
+
+// startOffset maps the auto_offset_reset setting to a kafka-go start offset
+func startOffset(autoOffsetReset string) int64 {
+	if autoOffsetReset == "latest" {
+		return kafka.LastOffset
+	}
+	return kafka.FirstOffset
+}
+
+// consumeTopic consumes messages from a specific topic
+func (c *Consumer) consumeTopic(topic string, reader *kafka.Reader) {
+	c.logger.WithField("topic", topic).Info("Starting to consume topic")
+
+	for {
+		msg, err := reader.FetchMessage(c.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || c.ctx.Err() != nil {
+				c.logger.WithField("topic", topic).Info("Stopping topic consumption")
+				return
+			}
+			c.logger.WithError(err).WithField("topic", topic).Error("Failed to read message")
+			time.Sleep(100 * time.Millisecond) // Brief pause before retry
+			continue
+		}
+
+		// Hold the message while the topic is paused
+		if !c.waitWhilePaused(topic) {
+			c.logger.WithField("topic", topic).Info("Stopping topic consumption")
+			return
+		}
+
+		c.logger.WithFields(logrus.Fields{
+			"topic":     topic,
+			"partition": msg.Partition,
+			"offset":    msg.Offset,
+			"key":       string(msg.Key),
+		}).Debug("Received message")
+
+		// Parse the message; one that cannot be decoded will never be handled
+		var kafkaMessage models.KafkaMessage
+		if err := json.Unmarshal(msg.Value, &kafkaMessage); err != nil {
+			c.logger.WithError(err).Error("Failed to unmarshal message")
+			c.sendToDeadLetter(c.ctx, &kafkaMessage, fmt.Errorf("invalid message: %w", err), &msg, 0)
+		} else if err := c.processMessage(c.ctx, &kafkaMessage, &msg); err != nil {
+			c.logger.WithError(err).Error("Failed to process message")
+		}
+
+		// Messages that exhausted their retries have been stored as dead letters,
+		// so the offset is committed either way to avoid blocking the partition.
+		if err := reader.CommitMessages(c.ctx, msg); err != nil && c.ctx.Err() == nil {
+			c.logger.WithError(err).WithField("topic", topic).Error("Failed to commit message")
+		}
+	}
+}
+
+// processMessage processes a single message
+func (c *Consumer) processMessage(ctx context.Context, message *models.KafkaMessage, kafkaMsg *kafka.Message) error {
+	handler, exists := c.getHandler(message.Type)
+	if !exists {
+		return fmt.Errorf("no handler registered for message type: %s", message.Type)
+	}
+
+	// Handlers see where the message came from and its correlation ID
+	delivery := Delivery{
+		Topic:         kafkaMsg.Topic,
+		Partition:     kafkaMsg.Partition,
+		Offset:        kafkaMsg.Offset,
+		Key:           string(kafkaMsg.Key),
+		CorrelationID: message.ID,
+	}
+	for _, header := range kafkaMsg.Headers {
+		if header.Key == CorrelationIDHeader && len(header.Value) > 0 {
+			delivery.CorrelationID = string(header.Value)
+		}
+	}
+	ctx = WithDelivery(ctx, delivery)
+
+	// Process the message with retry logic
+	return c.processWithRetry(ctx, message, handler, kafkaMsg)
+}
+
+// processWithRetry processes a message with retry logic
+func (c *Consumer) processWithRetry(ctx context.Context, message *models.KafkaMessage, handler MessageHandler, kafkaMsg *kafka.Message) error {
+	maxRetries := c.config.RetryMaxAttempts
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err := handler(ctx, message)
+		if err == nil {
+			return nil
+		}
+
+		c.logger.WithFields(logrus.Fields{
+			"message_id":   message.ID,
+			"message_type": message.Type,
+			"attempt":      attempt + 1,
+			"max_retries":  maxRetries,
+			"error":        err.Error(),
+		}).Warn("Message processing failed, retrying...")
+
+		// If this is the last attempt, send to dead letter queue
+		if attempt == maxRetries {
+			return c.sendToDeadLetter(ctx, message, err, kafkaMsg, maxRetries)
+		}
+
+		// Wait before retrying
+		backoff := c.config.RetryBackoff * time.Duration(attempt+1)
+		select {
+		case <-time.After(backoff):
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// sendToDeadLetter records a message that failed after retries attempts in
+// the dead letter store, and returns the error it failed with
+func (c *Consumer) sendToDeadLetter(ctx context.Context, message *models.KafkaMessage, err error, kafkaMsg *kafka.Message, retries int) error {
+	fields := logrus.Fields{
+		"message_id":  message.ID,
+		"topic":       kafkaMsg.Topic,
+		"partition":   kafkaMsg.Partition,
+		"offset":      kafkaMsg.Offset,
+		"error":       err.Error(),
+		"max_retries": c.config.RetryMaxAttempts,
+	}
+
+	c.mu.RLock()
+	store := c.deadLetters
+	c.mu.RUnlock()
+	if store == nil {
+		c.logger.WithFields(fields).Error("Message dropped without a dead letter store")
+		return err
+	}
+
+	// Store it even while the consumer closes, the offset is committed next
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deadLetterTimeout)
+	defer cancel()
+	deadLetter := DeadLetter{
+		Topic:       kafkaMsg.Topic,
+		Partition:   kafkaMsg.Partition,
+		Offset:      kafkaMsg.Offset,
+		Key:         string(kafkaMsg.Key),
+		Value:       kafkaMsg.Value,
+		MessageType: message.Type,
+		Error:       err.Error(),
+		RetryCount:  retries,
+		CreatedAt:   kafkaMsg.Time,
+		FailedAt:    time.Now().UTC(),
+	}
+	if saveErr := store.SaveDeadLetter(ctx, deadLetter); saveErr != nil {
+		c.logger.WithError(saveErr).WithFields(fields).Error("Failed to store dead letter, message dropped")
+		return err
+	}
+
+	c.logger.WithFields(fields).Error("Message sent to dead letter queue")
+	return err
+}
+
+// Close closes the consumer and all readers
+func (c *Consumer) Close() error {
+	c.cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lastErr error
+	for topic, reader := range c.readers {
+		if err := reader.Close(); err != nil {
+			c.logger.WithError(err).WithField("topic", topic).Error("Failed to close reader")
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// Alive reports whether the consumer is still running, for liveness probes.
+// It does not contact the brokers, so an unreachable Kafka cluster does not
+// get the instance restarted; use Ping to check the brokers.
+func (c *Consumer) Alive(ctx context.Context) error {
+	select {
+	case <-c.ctx.Done():
+		return fmt.Errorf("consumer is closed")
+	default:
+		return nil
+	}
+}
+
+// Ping checks that the consumer is running and that the brokers are
+// reachable and support the APIs it uses. Use it as a readiness check.
+func (c *Consumer) Ping(ctx context.Context) error {
+	if err := c.Alive(ctx); err != nil {
+		return err
+	}
+	return ping(ctx, c.client)
+}
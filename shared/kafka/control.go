@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Consumer control actions
+const (
+	ConsumerActionPause  = "pause"
+	ConsumerActionResume = "resume"
+)
+
+// AllTopics names every topic of a consumer in a ConsumerCommand
+const AllTopics = "*"
+
+// ConsumerCommand pauses or resumes the consumption of a topic, e.g. to stop
+// parsing while a parser fix is deployed. Commands are published to the
+// consumer control topic (kafka.topics.consumer_control), which every
+// consumer reads in full, so all members of a group follow them, including
+// members started later. The control topic should have a single partition
+// and be compacted, commands being keyed by CommandKey.
+type ConsumerCommand struct {
+	Action   string    `json:"action"`          // ConsumerActionPause or ConsumerActionResume
+	Topic    string    `json:"topic"`           // Topic to pause or resume, AllTopics for every topic
+	Group    string    `json:"group,omitempty"` // Consumer group the command is for, empty for every group
+	Reason   string    `json:"reason,omitempty"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// Validate checks the action and topic of a command
+func (c ConsumerCommand) Validate() error {
+	switch {
+	case c.Action != ConsumerActionPause && c.Action != ConsumerActionResume:
+		return fmt.Errorf("action must be %s or %s", ConsumerActionPause, ConsumerActionResume)
+	case c.Topic == "":
+		return errors.New("topic is required, use * for every topic")
+	}
+	return nil
+}
+
+// CommandKey returns the message key of a command. Later commands for the
+// same group and topic replace earlier ones when the topic is compacted.
+func (c ConsumerCommand) CommandKey() string {
+	return c.Group + "/" + c.Topic
+}
+
+// Pause stops consuming a topic, or every topic for AllTopics. A message
+// being fetched when the pause starts is held, uncommitted, until the topic
+// is resumed.
+func (c *Consumer) Pause(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if topic == AllTopics {
+		c.pausedAll = true
+	} else {
+		c.paused[topic] = true
+	}
+}
+
+// Resume resumes consuming a topic. Resuming AllTopics clears every pause.
+func (c *Consumer) Resume(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if topic == AllTopics {
+		c.pausedAll = false
+		c.paused = make(map[string]bool)
+	} else {
+		delete(c.paused, topic)
+	}
+	close(c.resumed)
+	c.resumed = make(chan struct{})
+}
+
+// PausedTopics returns the paused topics, sorted; AllTopics when every topic
+// is paused
+func (c *Consumer) PausedTopics() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	topics := []string{}
+	if c.pausedAll {
+		topics = append(topics, AllTopics)
+	}
+	for topic := range c.paused {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Apply carries out a command, ignoring commands for other consumer groups
+func (c *Consumer) Apply(command ConsumerCommand) error {
+	if err := command.Validate(); err != nil {
+		return err
+	}
+	if command.Group != "" && command.Group != c.config.GroupID {
+		return nil
+	}
+	if command.Action == ConsumerActionPause {
+		c.Pause(command.Topic)
+	} else {
+		c.Resume(command.Topic)
+	}
+	c.logger.WithFields(logrus.Fields{
+		"action": command.Action,
+		"topic":  command.Topic,
+		"reason": command.Reason,
+	}).Info("Consumer control command applied")
+	return nil
+}
+
+// waitWhilePaused blocks while a topic is paused. It returns false if the
+// consumer was closed meanwhile.
+func (c *Consumer) waitWhilePaused(topic string) bool {
+	logged := false
+	for {
+		c.mu.RLock()
+		paused := c.pausedAll || c.paused[topic]
+		resumed := c.resumed
+		c.mu.RUnlock()
+		if !paused {
+			if logged {
+				c.logger.WithField("topic", topic).Info("Topic consumption resumed")
+			}
+			return true
+		}
+		if !logged {
+			c.logger.WithField("topic", topic).Info("Topic consumption paused")
+			logged = true
+		}
+
+		select {
+		case <-resumed:
+		case <-c.ctx.Done():
+			return false
+		}
+	}
+}
+
+// watchControl applies the commands of the control topic, reading it from
+// the start so the consumer catches up with pauses issued before it started
+func (c *Consumer) watchControl(topic string) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     c.config.Brokers,
+		Topic:       topic,
+		Dialer:      c.dialer,
+		MaxWait:     1 * time.Second,
+		StartOffset: kafka.FirstOffset,
+		Logger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			c.logger.Debugf(msg, args...)
+		}),
+	})
+	defer reader.Close()
+
+	for {
+		msg, err := reader.ReadMessage(c.ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || c.ctx.Err() != nil {
+				return
+			}
+			c.logger.WithError(err).WithField("topic", topic).Error("Failed to read consumer control message")
+			time.Sleep(time.Second)
+			continue
+		}
+
+		var command ConsumerCommand
+		if err := json.Unmarshal(msg.Value, &command); err != nil {
+			c.logger.WithError(err).Warn("Ignoring invalid consumer control message")
+			continue
+		}
+		if err := c.Apply(command); err != nil {
+			c.logger.WithError(err).Warn("Ignoring invalid consumer control command")
+		}
+	}
+}
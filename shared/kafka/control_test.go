@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestConsumer(t *testing.T) *Consumer {
+	t.Helper()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	c, err := NewConsumer(config.KafkaConfig{Brokers: []string{"localhost:9092"}, GroupID: "parsers"}, log)
+	if err != nil {
+		t.Fatalf("NewConsumer: %v", err)
+	}
+	t.Cleanup(func() { c.cancel() })
+	return c
+}
+
+func TestConsumerApply(t *testing.T) {
+	c := newTestConsumer(t)
+	commands := []ConsumerCommand{
+		{Action: ConsumerActionPause, Topic: "scraped-data"},
+		{Action: ConsumerActionPause, Topic: "parsed-data", Group: "other"},
+		{Action: ConsumerActionPause, Topic: "url-events", Group: "parsers"},
+	}
+	for _, command := range commands {
+		if err := c.Apply(command); err != nil {
+			t.Fatalf("Apply(%+v): %v", command, err)
+		}
+	}
+	if got, want := c.PausedTopics(), []string{"scraped-data", "url-events"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PausedTopics() = %v, want %v", got, want)
+	}
+
+	c.Apply(ConsumerCommand{Action: ConsumerActionResume, Topic: "url-events"})
+	c.Apply(ConsumerCommand{Action: ConsumerActionPause, Topic: AllTopics})
+	if got, want := c.PausedTopics(), []string{AllTopics, "scraped-data"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PausedTopics() = %v, want %v", got, want)
+	}
+
+	c.Apply(ConsumerCommand{Action: ConsumerActionResume, Topic: AllTopics})
+	if got := c.PausedTopics(); len(got) != 0 {
+		t.Errorf("PausedTopics() after resuming all = %v, want none", got)
+	}
+
+	if err := c.Apply(ConsumerCommand{Action: "stop", Topic: "scraped-data"}); err == nil {
+		t.Error("Apply with an unknown action succeeded, want an error")
+	}
+	if err := c.Apply(ConsumerCommand{Action: ConsumerActionPause}); err == nil {
+		t.Error("Apply without a topic succeeded, want an error")
+	}
+}
+
+func TestWaitWhilePaused(t *testing.T) {
+	c := newTestConsumer(t)
+	c.Pause("scraped-data")
+
+	done := make(chan bool)
+	go func() { done <- c.waitWhilePaused("scraped-data") }()
+	if !c.waitWhilePaused("url-events") {
+		t.Error("waitWhilePaused on an unpaused topic = false, want true")
+	}
+
+	select {
+	case <-done:
+		t.Fatal("waitWhilePaused returned while the topic was paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.Resume("url-events")
+	select {
+	case <-done:
+		t.Fatal("waitWhilePaused returned when another topic was resumed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.Resume("scraped-data")
+	select {
+	case resumed := <-done:
+		if !resumed {
+			t.Error("waitWhilePaused = false after resume, want true")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitWhilePaused did not return after resume")
+	}
+
+	c.Pause(AllTopics)
+	go func() { done <- c.waitWhilePaused("scraped-data") }()
+	c.cancel()
+	select {
+	case resumed := <-done:
+		if resumed {
+			t.Error("waitWhilePaused = true after close, want false")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitWhilePaused did not return after close")
+	}
+}
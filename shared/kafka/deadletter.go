@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/models"
+)
+
+// DeadLetter is a message that exhausted its retries, as kept in the
+// DeadLetterStore of the consumer. Value holds the original message as it was consumed and
+// MessageType says how to decode it, see DecodePayload.
+type DeadLetter struct {
+	ID          string    `json:"id"`
+	Topic       string    `json:"topic"` // Topic the message was consumed from
+	Partition   int       `json:"partition"`
+	Offset      int64     `json:"offset"`
+	Key         string    `json:"key"`
+	Value       []byte    `json:"value"`
+	MessageType string    `json:"message_type,omitempty"`
+	Error       string    `json:"error"`
+	RetryCount  int       `json:"retry_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	FailedAt    time.Time `json:"failed_at"`
+}
+
+// DeadLetterStore keeps the messages that exhausted their retries, see
+// Consumer.SetDeadLetterStore
+type DeadLetterStore interface {
+	// SaveDeadLetter stores a dead letter. Saving a message of the same
+	// topic, partition and offset again must not store it twice.
+	SaveDeadLetter(ctx context.Context, d DeadLetter) error
+}
+
+// ScrapeResultMessage is the KafkaMessage of a scrape result with its data
+// decoded
+type ScrapeResultMessage struct {
+	ID        string              `json:"id"`
+	Type      string              `json:"type"`
+	Data      models.ScrapeResult `json:"data"`
+	Timestamp time.Time           `json:"timestamp"`
+	Source    string              `json:"source"`
+}
+
+// payloadTypes maps message types to the structure their values decode into
+var (
+	payloadTypesMu sync.RWMutex
+	payloadTypes   = map[string]func() any{
+		models.MessageTypeScrapeResult: func() any { return &ScrapeResultMessage{} },
+		models.MessageTypeScrapingTask: func() any { return &models.ScrapingTaskMessage{} },
+		models.MessageTypeURLEvent:     func() any { return &models.URLEvent{} },
+	}
+)
+
+// RegisterPayloadType makes DecodePayload decode messages of a type into the
+// value returned by newPayload, a pointer to a struct
+func RegisterPayloadType(messageType string, newPayload func() any) {
+	payloadTypesMu.Lock()
+	defer payloadTypesMu.Unlock()
+	payloadTypes[messageType] = newPayload
+}
+
+// ErrPayloadNotJSON is returned by DecodePayload for values that are not JSON
+var ErrPayloadNotJSON = errors.New("message value is not JSON")
+
+// DecodePayload decodes a message value by its type into the structure it
+// was published as, such as a models.ScrapingTaskMessage. Values of types
+// without a registered structure are decoded as generic JSON.
+func DecodePayload(messageType string, value []byte) (any, error) {
+	if !json.Valid(value) {
+		return nil, ErrPayloadNotJSON
+	}
+	payloadTypesMu.RLock()
+	newPayload, ok := payloadTypes[messageType]
+	payloadTypesMu.RUnlock()
+	if !ok {
+		var payload any
+		err := json.Unmarshal(value, &payload)
+		return payload, err
+	}
+
+	payload := newPayload()
+	if err := json.Unmarshal(value, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Payload decodes the dead letter's original message, see DecodePayload
+func (d DeadLetter) Payload() (any, error) {
+	return DecodePayload(d.MessageType, d.Value)
+}
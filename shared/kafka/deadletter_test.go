@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryDeadLetters is a DeadLetterStore in memory
+type memoryDeadLetters struct {
+	saved []DeadLetter
+}
+
+func (s *memoryDeadLetters) SaveDeadLetter(ctx context.Context, d DeadLetter) error {
+	s.saved = append(s.saved, d)
+	return nil
+}
+
+func TestConsumerStoresDeadLetters(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	consumer, err := NewConsumer(config.KafkaConfig{Brokers: []string{"localhost:9092"}, GroupID: "test", RetryMaxAttempts: 2}, log)
+	if err != nil {
+		t.Fatalf("NewConsumer() error = %v", err)
+	}
+	store := &memoryDeadLetters{}
+	consumer.SetDeadLetterStore(store)
+
+	calls := 0
+	consumer.RegisterHandler(models.MessageTypeScrapeResult, func(ctx context.Context, message *models.KafkaMessage) error {
+		calls++
+		return errors.New("unknown URL")
+	})
+	produced := time.Now().Add(-time.Minute).UTC()
+	msg := &kafka.Message{Topic: "scraping-results", Partition: 2, Offset: 41, Key: []byte("k1"), Value: []byte(`{"id":"m1","type":"scrape_result"}`), Time: produced}
+	err = consumer.processMessage(context.Background(), &models.KafkaMessage{ID: "m1", Type: models.MessageTypeScrapeResult}, msg)
+	if err == nil || calls != 3 {
+		t.Fatalf("processMessage() error = %v after %d calls, want the handler error after 3", err, calls)
+	}
+	if len(store.saved) != 1 {
+		t.Fatalf("stored %d dead letters, want 1", len(store.saved))
+	}
+	d := store.saved[0]
+	if d.Topic != "scraping-results" || d.Partition != 2 || d.Offset != 41 || d.Key != "k1" || string(d.Value) != string(msg.Value) ||
+		d.MessageType != models.MessageTypeScrapeResult || d.Error != "unknown URL" || d.RetryCount != 2 || !d.CreatedAt.Equal(produced) || d.FailedAt.IsZero() {
+		t.Errorf("dead letter = %+v", d)
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	taskID := uuid.New()
+	payload, err := DecodePayload(models.MessageTypeScrapingTask,
+		[]byte(`{"task_id":"`+taskID.String()+`","url":"https://example.com","attempt":2,"retry_policy":{"max_attempts":3}}`))
+	if err != nil {
+		t.Fatalf("DecodePayload(scraping_task) error = %v", err)
+	}
+	task, ok := payload.(*models.ScrapingTaskMessage)
+	if !ok || task.TaskID != taskID || task.Attempt != 2 || task.URL != "https://example.com" {
+		t.Errorf("scraping task payload = %#v", payload)
+	}
+
+	payload, err = DecodePayload(models.MessageTypeScrapeResult,
+		[]byte(`{"id":"m1","type":"scrape_result","data":{"success":true,"status_code":200}}`))
+	if err != nil {
+		t.Fatalf("DecodePayload(scrape_result) error = %v", err)
+	}
+	if result, ok := payload.(*ScrapeResultMessage); !ok || !result.Data.Success || result.Data.StatusCode != 200 {
+		t.Errorf("scrape result payload = %#v", payload)
+	}
+
+	payload, err = DecodePayload("custom", []byte(`{"answer":42}`))
+	if err != nil {
+		t.Fatalf("DecodePayload(custom) error = %v", err)
+	}
+	if generic, ok := payload.(map[string]any); !ok || generic["answer"] != float64(42) {
+		t.Errorf("generic payload = %#v", payload)
+	}
+
+	if _, err := DecodePayload(models.MessageTypeScrapingTask, []byte{0xff, 0x00}); !errors.Is(err, ErrPayloadNotJSON) {
+		t.Errorf("DecodePayload(binary) error = %v, want ErrPayloadNotJSON", err)
+	}
+	if _, err := DecodePayload(models.MessageTypeScrapingTask, []byte(`{"attempt":"two"}`)); err == nil {
+		t.Error("DecodePayload(mistyped field) succeeded, want an error")
+	}
+}
@@ -0,0 +1,116 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/models"
+)
+
+// handlerDurationBuckets are the upper bounds, in seconds, of the handling
+// duration histogram
+var handlerDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HandlerMetrics counts handled messages by type and outcome and records
+// their handling duration, see Middleware and WritePrometheus
+type HandlerMetrics struct {
+	mu       sync.Mutex
+	outcomes map[[2]string]int64           // By message type and outcome
+	duration map[string]*durationHistogram // By message type
+}
+
+// durationHistogram is a Prometheus histogram of handling durations
+type durationHistogram struct {
+	buckets []int64 // Cumulative counts by handlerDurationBuckets
+	count   int64
+	sum     float64
+}
+
+// NewHandlerMetrics creates empty handler metrics
+func NewHandlerMetrics() *HandlerMetrics {
+	return &HandlerMetrics{
+		outcomes: make(map[[2]string]int64),
+		duration: make(map[string]*durationHistogram),
+	}
+}
+
+// Middleware records the outcome and duration of every handled message
+func (m *HandlerMetrics) Middleware() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, message *models.KafkaMessage) error {
+			start := time.Now()
+			err := next(ctx, message)
+			m.Observe(message.Type, outcome(err), time.Since(start))
+			return err
+		}
+	}
+}
+
+// Observe records a handled message
+func (m *HandlerMetrics) Observe(messageType, outcome string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[[2]string{messageType, outcome}]++
+
+	h, ok := m.duration[messageType]
+	if !ok {
+		h = &durationHistogram{buckets: make([]int64, len(handlerDurationBuckets))}
+		m.duration[messageType] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range handlerDurationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Handled returns the number of messages of a type handled with an outcome
+func (m *HandlerMetrics) Handled(messageType, outcome string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.outcomes[[2]string{messageType, outcome}]
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format
+func (m *HandlerMetrics) WritePrometheus(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([][2]string, 0, len(m.outcomes))
+	for key := range m.outcomes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	fmt.Fprintf(w, "# HELP kafka_messages_handled_total Kafka messages handled, by message type and outcome (success, error, panic).\n# TYPE kafka_messages_handled_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(w, "kafka_messages_handled_total{type=%q,outcome=%q} %d\n", key[0], key[1], m.outcomes[key])
+	}
+
+	types := make([]string, 0, len(m.duration))
+	for messageType := range m.duration {
+		types = append(types, messageType)
+	}
+	sort.Strings(types)
+	fmt.Fprintf(w, "# HELP kafka_message_handling_duration_seconds Time spent handling Kafka messages, by message type.\n# TYPE kafka_message_handling_duration_seconds histogram\n")
+	for _, messageType := range types {
+		h := m.duration[messageType]
+		for i, bound := range handlerDurationBuckets {
+			fmt.Fprintf(w, "kafka_message_handling_duration_seconds_bucket{type=%q,le=\"%g\"} %d\n", messageType, bound, h.buckets[i])
+		}
+		fmt.Fprintf(w, "kafka_message_handling_duration_seconds_bucket{type=%q,le=\"+Inf\"} %d\n", messageType, h.count)
+		fmt.Fprintf(w, "kafka_message_handling_duration_seconds_sum{type=%q} %g\n", messageType, h.sum)
+		fmt.Fprintf(w, "kafka_message_handling_duration_seconds_count{type=%q} %d\n", messageType, h.count)
+	}
+}
@@ -0,0 +1,205 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CorrelationIDHeader is the message header carrying the correlation ID
+const CorrelationIDHeader = "correlation_id"
+
+// Middleware wraps a MessageHandler, like HTTP middleware wraps handlers
+type Middleware func(next MessageHandler) MessageHandler
+
+// Use adds middleware to every handler of the consumer, including handlers
+// registered later. The first middleware is the outermost. Middleware runs
+// for every attempt of a message, retries included.
+func (c *Consumer) Use(middleware ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// Chain wraps handler with middleware, the first being the outermost
+func Chain(handler MessageHandler, middleware ...Middleware) MessageHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Delivery describes where a message being handled was consumed from
+type Delivery struct {
+	Topic         string
+	Partition     int
+	Offset        int64
+	Key           string
+	CorrelationID string // From the correlation_id header, else the message ID
+}
+
+type deliveryKey struct{}
+
+type correlationIDKey struct{}
+
+// WithDelivery returns a context carrying the delivery of a message, and
+// its correlation ID
+func WithDelivery(ctx context.Context, delivery Delivery) context.Context {
+	ctx = context.WithValue(ctx, deliveryKey{}, delivery)
+	return WithCorrelationID(ctx, delivery.CorrelationID)
+}
+
+// DeliveryFromContext returns the delivery of the message being handled
+func DeliveryFromContext(ctx context.Context) (Delivery, bool) {
+	delivery, ok := ctx.Value(deliveryKey{}).(Delivery)
+	return delivery, ok
+}
+
+// WithCorrelationID returns a context carrying a correlation ID. Messages
+// sent with the context carry it in their correlation_id header, so the
+// handling of a message can be followed across services.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of a context, "" if it has none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// ErrHandlerPanic marks the errors of handlers that panicked, see Recovery
+var ErrHandlerPanic = errors.New("message handler panicked")
+
+// Recovery turns handler panics into errors, so the message is retried and
+// dead-lettered like any failure instead of crashing the consumer
+func Recovery(logger *logrus.Logger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, message *models.KafkaMessage) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.WithFields(logrus.Fields{
+						"message_id":     message.ID,
+						"message_type":   message.Type,
+						"correlation_id": CorrelationID(ctx),
+						"panic":          fmt.Sprint(r),
+						"stack":          string(debug.Stack()),
+					}).Error("Message handler panicked")
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+			return next(ctx, message)
+		}
+	}
+}
+
+// Logging logs every handled message with its correlation ID, delivery,
+// duration and outcome
+func Logging(logger *logrus.Logger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, message *models.KafkaMessage) error {
+			start := time.Now()
+			err := next(ctx, message)
+
+			fields := logrus.Fields{
+				"message_id":     message.ID,
+				"message_type":   message.Type,
+				"correlation_id": CorrelationID(ctx),
+				"duration":       time.Since(start),
+				"outcome":        outcome(err),
+			}
+			if delivery, ok := DeliveryFromContext(ctx); ok {
+				fields["topic"] = delivery.Topic
+				fields["partition"] = delivery.Partition
+				fields["offset"] = delivery.Offset
+			}
+			if err != nil {
+				logger.WithFields(fields).WithError(err).Warn("Kafka message handling failed")
+			} else {
+				logger.WithFields(fields).Info("Kafka message handled")
+			}
+			return err
+		}
+	}
+}
+
+// Handling outcomes, as reported by Logging and HandlerMetrics
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomePanic   = "panic"
+)
+
+// outcome classifies the result of a handler
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrHandlerPanic):
+		return OutcomePanic
+	}
+	return OutcomeError
+}
+
+// Tracer starts spans, e.g. an adapter for an OpenTelemetry tracer
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// Tracing runs every handler in a span named after the message type, with
+// the messaging attributes of the delivery
+func Tracing(tracer Tracer) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, message *models.KafkaMessage) error {
+			ctx, span := tracer.Start(ctx, "kafka.handle "+message.Type)
+			defer span.End()
+			span.SetAttribute("messaging.system", "kafka")
+			span.SetAttribute("messaging.message.id", message.ID)
+			span.SetAttribute("messaging.message.type", message.Type)
+			if id := CorrelationID(ctx); id != "" {
+				span.SetAttribute("messaging.message.conversation_id", id)
+			}
+			if delivery, ok := DeliveryFromContext(ctx); ok {
+				span.SetAttribute("messaging.destination.name", delivery.Topic)
+				span.SetAttribute("messaging.kafka.destination.partition", fmt.Sprint(delivery.Partition))
+				span.SetAttribute("messaging.kafka.message.offset", fmt.Sprint(delivery.Offset))
+			}
+
+			err := next(ctx, message)
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		}
+	}
+}
+
+// Observability returns the standard middleware of a consumer: tracing
+// when tracer is not nil, metrics when metrics is not nil, logging and
+// panic recovery
+func Observability(logger *logrus.Logger, metrics *HandlerMetrics, tracer Tracer) []Middleware {
+	var middleware []Middleware
+	if tracer != nil {
+		middleware = append(middleware, Tracing(tracer))
+	}
+	if metrics != nil {
+		middleware = append(middleware, metrics.Middleware())
+	}
+	return append(middleware, Logging(logger), Recovery(logger))
+}
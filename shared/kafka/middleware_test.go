@@ -0,0 +1,144 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingTracer records the spans it starts
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{name: name, attributes: make(map[string]string)}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *recordingSpan) SetAttribute(key, value string) { s.attributes[key] = value }
+func (s *recordingSpan) RecordError(err error)          { s.err = err }
+func (s *recordingSpan) End()                           { s.ended = true }
+
+func TestObservabilityMiddleware(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	metrics := NewHandlerMetrics()
+	tracer := &recordingTracer{}
+
+	var seen string
+	handler := Chain(func(ctx context.Context, message *models.KafkaMessage) error {
+		seen = CorrelationID(ctx)
+		switch message.ID {
+		case "panics":
+			panic("nil map")
+		case "fails":
+			return errors.New("database unavailable")
+		}
+		return nil
+	}, Observability(log, metrics, tracer)...)
+
+	ctx := WithDelivery(context.Background(), Delivery{Topic: "scraping-results", Partition: 2, Offset: 42, CorrelationID: "req-1"})
+	if err := handler(ctx, &models.KafkaMessage{ID: "ok", Type: models.MessageTypeScrapeResult}); err != nil {
+		t.Fatalf("handler() = %v, want nil", err)
+	}
+	if seen != "req-1" {
+		t.Errorf("correlation ID = %q, want req-1", seen)
+	}
+	if err := handler(ctx, &models.KafkaMessage{ID: "fails", Type: models.MessageTypeScrapeResult}); err == nil {
+		t.Error("handler() of a failing message = nil, want its error")
+	}
+	err := handler(ctx, &models.KafkaMessage{ID: "panics", Type: models.MessageTypeScrapeResult})
+	if !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("handler() of a panicking message = %v, want ErrHandlerPanic", err)
+	}
+
+	for _, outcome := range []string{OutcomeSuccess, OutcomeError, OutcomePanic} {
+		if got := metrics.Handled(models.MessageTypeScrapeResult, outcome); got != 1 {
+			t.Errorf("Handled(%s) = %d, want 1", outcome, got)
+		}
+	}
+	if len(tracer.spans) != 3 {
+		t.Fatalf("spans = %d, want 3", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "kafka.handle scrape_result" || !span.ended || span.attributes["messaging.destination.name"] != "scraping-results" || span.attributes["messaging.kafka.message.offset"] != "42" {
+		t.Errorf("span = %+v", span)
+	}
+	if tracer.spans[2].err == nil {
+		t.Error("span of the panicking message has no error")
+	}
+
+	var out strings.Builder
+	metrics.WritePrometheus(&out)
+	for _, want := range []string{
+		`kafka_messages_handled_total{type="scrape_result",outcome="panic"} 1`,
+		`kafka_message_handling_duration_seconds_bucket{type="scrape_result",le="+Inf"} 3`,
+		`kafka_message_handling_duration_seconds_count{type="scrape_result"} 3`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestHandlerMetricsBuckets(t *testing.T) {
+	metrics := NewHandlerMetrics()
+	metrics.Observe("url_event", OutcomeSuccess, 30*time.Millisecond)
+	metrics.Observe("url_event", OutcomeSuccess, 3*time.Second)
+
+	var out strings.Builder
+	metrics.WritePrometheus(&out)
+	for _, want := range []string{
+		`kafka_message_handling_duration_seconds_bucket{type="url_event",le="0.025"} 0`,
+		`kafka_message_handling_duration_seconds_bucket{type="url_event",le="0.05"} 1`,
+		`kafka_message_handling_duration_seconds_bucket{type="url_event",le="5"} 2`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConsumerUseWrapsHandlers(t *testing.T) {
+	c := newTestConsumer(t)
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next MessageHandler) MessageHandler {
+			return func(ctx context.Context, message *models.KafkaMessage) error {
+				order = append(order, name)
+				return next(ctx, message)
+			}
+		}
+	}
+	c.Use(trace("outer"))
+	c.RegisterHandler("url_event", func(ctx context.Context, message *models.KafkaMessage) error {
+		order = append(order, "handler")
+		return nil
+	})
+	c.Use(trace("inner"))
+
+	handler, ok := c.getHandler("url_event")
+	if !ok {
+		t.Fatal("getHandler() found no handler")
+	}
+	handler(context.Background(), &models.KafkaMessage{ID: "1", Type: "url_event"})
+	if got := strings.Join(order, ","); got != "outer,inner,handler" {
+		t.Errorf("order = %s, want outer,inner,handler", got)
+	}
+}
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Offsets reads consumer group lag from the brokers and checks that they
+// are reachable
+type Offsets struct {
+	client *kafka.Client
+}
+
+// NewOffsets creates an offset reader for the given brokers
+func NewOffsets(brokers []string, opts ...Option) (*Offsets, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("at least one Kafka broker is required")
+	}
+	return &Offsets{client: newOptions(opts).client(brokers)}, nil
+}
+
+// Ping checks that the brokers are reachable and support the APIs the
+// producer and consumers use
+func (o *Offsets) Ping(ctx context.Context) error {
+	return ping(ctx, o.client)
+}
+
+// ConsumerLag returns the number of messages in a topic the consumer group
+// has not committed yet, summed over the topic's partitions. Partitions the
+// group never committed count in full.
+func (o *Offsets) ConsumerLag(ctx context.Context, groupID, topic string) (int64, error) {
+	offsets, err := o.partitionOffsets(ctx, topic)
+	if err != nil {
+		return 0, err
+	}
+
+	partitions := make([]int, 0, len(offsets))
+	for _, partition := range offsets {
+		partitions = append(partitions, partition.Partition)
+	}
+	committed, err := o.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: groupID,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch offsets of group %s: %w", groupID, err)
+	}
+	if committed.Error != nil {
+		return 0, fmt.Errorf("failed to fetch offsets of group %s: %w", groupID, committed.Error)
+	}
+
+	committedOffsets := make(map[int]int64, len(partitions))
+	for _, partition := range committed.Topics[topic] {
+		if partition.Error == nil && partition.CommittedOffset >= 0 {
+			committedOffsets[partition.Partition] = partition.CommittedOffset
+		}
+	}
+
+	var lag int64
+	for _, partition := range offsets {
+		position, ok := committedOffsets[partition.Partition]
+		if !ok || position < partition.FirstOffset {
+			position = partition.FirstOffset
+		}
+		lag += max(partition.LastOffset-position, 0)
+	}
+	return lag, nil
+}
+
+// partitionOffsets returns the first and last offset of every partition of a topic
+func (o *Offsets) partitionOffsets(ctx context.Context, topic string) ([]kafka.PartitionOffsets, error) {
+	metadata, err := o.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of topic %s: %w", topic, err)
+	}
+	if len(metadata.Topics) != 1 {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+	if err := metadata.Topics[0].Error; err != nil {
+		return nil, fmt.Errorf("failed to read metadata of topic %s: %w", topic, err)
+	}
+
+	requests := make([]kafka.OffsetRequest, 0, 2*len(metadata.Topics[0].Partitions))
+	for _, partition := range metadata.Topics[0].Partitions {
+		requests = append(requests, kafka.FirstOffsetOf(partition.ID), kafka.LastOffsetOf(partition.ID))
+	}
+	listed, err := o.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets of topic %s: %w", topic, err)
+	}
+
+	offsets := listed.Topics[topic]
+	for _, partition := range offsets {
+		if partition.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets of topic %s partition %d: %w", topic, partition.Partition, partition.Error)
+		}
+	}
+	return offsets, nil
+}
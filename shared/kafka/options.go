@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Option configures the broker connections of a Producer, Consumer or Offsets
+type Option func(*options)
+
+// options are the settings applied by Options
+type options struct {
+	tls *tls.Config
+}
+
+// WithTLS connects to the brokers with TLS, such as the mutual TLS of
+// mtls.ClientConfig
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) { o.tls = cfg }
+}
+
+// newOptions applies opts to the default settings
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// dialer returns the dialer of readers and writers, nil for the default
+func (o options) dialer() *kafka.Dialer {
+	if o.tls == nil {
+		return nil
+	}
+	return &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true, TLS: o.tls}
+}
+
+// client returns a client for requests to the brokers
+func (o options) client(brokers []string) *kafka.Client {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: clientTimeout}
+	if o.tls != nil {
+		client.Transport = &kafka.Transport{TLS: o.tls}
+	}
+	return client
+}
@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+)
+
+// clientTimeout bounds a single request to the brokers
+const clientTimeout = 10 * time.Second
+
+// requiredAPIs are the broker APIs the producer and consumers use
+var requiredAPIs = []protocol.ApiKey{
+	protocol.Produce,
+	protocol.Fetch,
+	protocol.ListOffsets,
+	protocol.Metadata,
+	protocol.OffsetCommit,
+	protocol.OffsetFetch,
+	protocol.JoinGroup,
+	protocol.Heartbeat,
+}
+
+// ping checks the brokers without producing or consuming messages: they
+// must answer a metadata request listing at least one broker, and an
+// API versions request listing every API in requiredAPIs
+func ping(ctx context.Context, client *kafka.Client) error {
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to reach Kafka: %w", err)
+	}
+	if len(metadata.Brokers) == 0 {
+		return fmt.Errorf("no brokers in the Kafka cluster metadata")
+	}
+
+	versions, err := client.ApiVersions(ctx, &kafka.ApiVersionsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to read Kafka API versions: %w", err)
+	}
+	if versions.Error != nil {
+		return fmt.Errorf("failed to read Kafka API versions: %w", versions.Error)
+	}
+	return checkAPIs(versions.ApiKeys)
+}
+
+// checkAPIs returns an error naming the required APIs a broker lacks
+func checkAPIs(supported []kafka.ApiVersionsResponseApiKey) error {
+	available := make(map[int]bool, len(supported))
+	for _, api := range supported {
+		available[api.ApiKey] = true
+	}
+	var missing []string
+	for _, api := range requiredAPIs {
+		if !available[int(api)] {
+			missing = append(missing, api.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Kafka broker does not support the %s APIs", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCheckAPIs(t *testing.T) {
+	var supported []kafka.ApiVersionsResponseApiKey
+	for _, api := range requiredAPIs {
+		supported = append(supported, kafka.ApiVersionsResponseApiKey{ApiKey: int(api), ApiName: api.String()})
+	}
+	if err := checkAPIs(supported); err != nil {
+		t.Errorf("checkAPIs() with every API = %v, want nil", err)
+	}
+
+	err := checkAPIs(supported[1:])
+	if err == nil || !strings.Contains(err.Error(), "Produce") {
+		t.Errorf("checkAPIs() without Produce = %v, want an error naming it", err)
+	}
+}
+
+func TestPingWithoutBrokers(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	p, err := NewProducer(config.KafkaConfig{}, log)
+	if err != nil {
+		t.Fatalf("NewProducer: %v", err)
+	}
+	if err := p.Ping(context.Background()); err == nil {
+		t.Error("Ping() without brokers succeeded, want an error")
+	}
+}
+
+func TestConsumerAlive(t *testing.T) {
+	c := newTestConsumer(t)
+	if err := c.Alive(context.Background()); err != nil {
+		t.Errorf("Alive() = %v, want nil while running", err)
+	}
+	c.Close()
+	if err := c.Alive(context.Background()); err == nil {
+		t.Error("Alive() after Close succeeded, want an error")
+	}
+}
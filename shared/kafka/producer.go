@@ -6,8 +6,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
-	"time"
+
+	"go_scraping_project/shared/config"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
@@ -16,18 +18,40 @@ import (
 // Producer represents a Kafka producer using kafka-go
 // (domain and config dependencies should be refactored to shared or injected)
 type Producer struct {
-	writers map[string]*kafka.Writer
-	brokers []string
-	logger  *logrus.Logger
-	mu      sync.RWMutex
+	writers  map[string]*kafka.Writer
+	brokers  []string
+	client   *kafka.Client         // Broker requests of Ping
+	dialer   *kafka.Dialer         // Connections of writers, nil for the default
+	settings config.ProducerConfig // Compression, batching and acks (kafka.producer)
+	acks     int
+	logger   *logrus.Logger
+	mu       sync.RWMutex
 }
 
-// NewProducer creates a new Kafka producer
-func NewProducer(brokers []string, log *logrus.Logger) (*Producer, error) {
+// NewProducer creates a new Kafka producer with the brokers and the
+// kafka.producer settings of cfg
+func NewProducer(cfg config.KafkaConfig, log *logrus.Logger, opts ...Option) (*Producer, error) {
+	if err := cfg.Producer.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid producer configuration: %w", err)
+	}
+	acks, _ := cfg.Producer.RequiredAcks()
+
+	settings := cfg.Producer
+	if settings.BatchSize == 0 {
+		settings.BatchSize = config.DefaultProducerBatchSize
+	}
+	if settings.Linger == 0 {
+		settings.Linger = config.DefaultProducerLinger
+	}
+	o := newOptions(opts)
 	return &Producer{
-		writers: make(map[string]*kafka.Writer),
-		brokers: brokers,
-		logger:  log,
+		writers:  make(map[string]*kafka.Writer),
+		brokers:  cfg.Brokers,
+		client:   o.client(cfg.Brokers),
+		dialer:   o.dialer(),
+		settings: settings,
+		acks:     acks,
+		logger:   log,
 	}, nil
 }
 
@@ -52,37 +76,45 @@ func (p *Producer) getWriter(topic string) *kafka.Writer {
 	writer = kafka.NewWriter(kafka.WriterConfig{
 		Brokers:      p.brokers,
 		Topic:        topic,
-		BatchSize:    100,
-		BatchTimeout: 10 * time.Millisecond,
-		RequiredAcks: -1,    // Require all replicas to acknowledge
+		Dialer:       p.dialer,
+		BatchSize:    p.settings.BatchSize,
+		BatchTimeout: p.settings.Linger,
 		Async:        false, // Use sync for reliability
 		Logger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
 			p.logger.Debugf(msg, args...)
 		}),
 	})
+	// Set after NewWriter, which takes zero acks for the default of all
+	writer.RequiredAcks = kafka.RequiredAcks(p.acks)
+	writer.Compression = compressionCodec(p.settings.Compression)
 
 	p.writers[topic] = writer
 	return writer
 }
 
-// SendMessage sends a message to a Kafka topic
+// compressionCodec returns the codec of a kafka.producer compression name
+func compressionCodec(name string) kafka.Compression {
+	switch strings.ToLower(name) {
+	case "gzip":
+		return kafka.Gzip
+	case "snappy":
+		return kafka.Snappy
+	case "lz4":
+		return kafka.Lz4
+	case "zstd":
+		return kafka.Zstd
+	}
+	return 0 // No compression
+}
+
+// SendMessage sends a message to a Kafka topic. The correlation ID of ctx,
+// see WithCorrelationID, is sent in the correlation_id header.
 func (p *Producer) SendMessage(ctx context.Context, topic string, key string, value interface{}, headers map[string]string) error {
 	writer := p.getWriter(topic)
 
-	data, err := json.Marshal(value)
+	kafkaMsg, err := newMessage(ctx, key, value, headers)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
-	}
-
-	var kafkaHeaders []kafka.Header
-	for k, v := range headers {
-		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: k, Value: []byte(v)})
-	}
-
-	kafkaMsg := kafka.Message{
-		Key:     []byte(key),
-		Value:   data,
-		Headers: kafkaHeaders,
+		return err
 	}
 
 	err = writer.WriteMessages(ctx, kafkaMsg)
@@ -98,6 +130,41 @@ func (p *Producer) SendMessage(ctx context.Context, topic string, key string, va
 	return nil
 }
 
+// newMessage encodes value as JSON in a message with the given key and
+// headers, and the correlation ID of ctx unless headers set one
+func newMessage(ctx context.Context, key string, value interface{}, headers map[string]string) (kafka.Message, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	var kafkaHeaders []kafka.Header
+	for k, v := range headers {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	// Carry the correlation ID of the message being handled, if any
+	if id := CorrelationID(ctx); id != "" {
+		if _, set := headers[CorrelationIDHeader]; !set {
+			kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: CorrelationIDHeader, Value: []byte(id)})
+		}
+	}
+
+	return kafka.Message{
+		Key:     []byte(key),
+		Value:   data,
+		Headers: kafkaHeaders,
+	}, nil
+}
+
+// Ping checks that the brokers are reachable and support the APIs the
+// producer uses, without sending a message. Use it as a health check.
+func (p *Producer) Ping(ctx context.Context) error {
+	if len(p.brokers) == 0 {
+		return fmt.Errorf("no Kafka brokers configured")
+	}
+	return ping(ctx, p.client)
+}
+
 // Close closes all writers
 func (p *Producer) Close() error {
 	p.mu.Lock()
@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+func TestProducerWriterSettings(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	tests := []struct {
+		name        string
+		settings    config.ProducerConfig
+		compression kafka.Compression
+		batchSize   int
+		linger      time.Duration
+		acks        kafka.RequiredAcks
+	}{
+		{"defaults", config.ProducerConfig{}, 0, 100, 10 * time.Millisecond, kafka.RequireAll},
+		{"zstd", config.ProducerConfig{Compression: "zstd", BatchSize: 500, Linger: 50 * time.Millisecond, Acks: "leader"}, kafka.Zstd, 500, 50 * time.Millisecond, kafka.RequireOne},
+		{"snappy", config.ProducerConfig{Compression: "Snappy", Acks: "0"}, kafka.Snappy, 100, 10 * time.Millisecond, kafka.RequireNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProducer(config.KafkaConfig{Brokers: []string{"localhost:9092"}, Producer: tt.settings}, log)
+			if err != nil {
+				t.Fatalf("NewProducer: %v", err)
+			}
+			defer p.Close()
+			w := p.getWriter("scraping-tasks")
+			if w.Compression != tt.compression || w.BatchSize != tt.batchSize || w.BatchTimeout != tt.linger || w.RequiredAcks != tt.acks {
+				t.Errorf("writer = compression %v, batch %d, linger %s, acks %v; want %v, %d, %s, %v",
+					w.Compression, w.BatchSize, w.BatchTimeout, w.RequiredAcks, tt.compression, tt.batchSize, tt.linger, tt.acks)
+			}
+		})
+	}
+
+	if _, err := NewProducer(config.KafkaConfig{Producer: config.ProducerConfig{Compression: "brotli"}}, log); err == nil {
+		t.Error("NewProducer with an unknown codec succeeded, want an error")
+	}
+	if _, err := NewProducer(config.KafkaConfig{Producer: config.ProducerConfig{Acks: "2"}}, log); err == nil {
+		t.Error("NewProducer with acks 2 succeeded, want an error")
+	}
+}
+
+// benchmarkTask is a scraping task as the URL Manager publishes it
+var benchmarkTask = models.ScrapingTask{
+	ID:         uuid.MustParse("6f1c2a4e-8d1b-4c3e-9a57-0f2d5b7c9e11"),
+	URLID:      uuid.MustParse("2b8e4f6a-1c3d-4e5f-8a9b-0c1d2e3f4a5b"),
+	URL:        "https://shop.example.com/catalog/kitchen/kettles/steel-kettle-1.7l?ref=sitemap",
+	UserAgent:  "go-scraping-bot/1.0",
+	Timeout:    30,
+	MaxRetries: 3,
+	Retry:      models.RetryPolicy{MaxAttempts: 4, BackoffBaseMs: 1000, BackoffCapMs: 60000, RetryOnStatus: []int{429, 502, 503}},
+	CreatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// BenchmarkNewMessage measures encoding a scraping task into a message, the
+// part of SendMessage that runs before the writer batches it
+func BenchmarkNewMessage(b *testing.B) {
+	ctx := WithCorrelationID(context.Background(), "7d9f3c1e-5a2b-4c8d-9e0f-1a2b3c4d5e6f")
+	headers := map[string]string{"message_type": "scraping_task"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := newMessage(ctx, benchmarkTask.ID.String(), benchmarkTask, headers); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCompression measures compressing a batch of 100 scraping tasks
+// with each kafka.producer compression codec
+func BenchmarkCompression(b *testing.B) {
+	msg, err := newMessage(context.Background(), benchmarkTask.ID.String(), benchmarkTask, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var batch []byte
+	for i := 0; i < 100; i++ {
+		batch = append(batch, msg.Value...)
+	}
+
+	for _, name := range []string{"gzip", "snappy", "lz4", "zstd"} {
+		b.Run(name, func(b *testing.B) {
+			codec := compressionCodec(name).Codec()
+			b.SetBytes(int64(len(batch)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := codec.NewWriter(io.Discard)
+				if _, err := w.Write(batch); err != nil {
+					b.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
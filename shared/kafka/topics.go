@@ -0,0 +1,11 @@
+package kafka
+
+// RegionTopic returns the region-specific variant of a topic, e.g.
+// "scraping-tasks.eu-west". Scrapers running in a region consume that topic
+// in addition to the shared one; the empty region returns the topic itself.
+func RegionTopic(topic, region string) string {
+	if region == "" {
+		return topic
+	}
+	return topic + "." + region
+}
@@ -0,0 +1,246 @@
+// Package logfile writes logs to a file that is rotated by size and time,
+// for deployments without a log collector. Rotated files are renamed with
+// their rotation time and, as configured, gzipped and deleted once there are
+// too many or they are too old; see config.LogFileConfig.
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// timeFormat is the format of the rotation time in rotated file names
+const timeFormat = "2006-01-02T15-04-05.000"
+
+// Writer is an io.WriteCloser appending to a log file that it rotates
+type Writer struct {
+	cfg config.LogFileConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time // Next time-based rotation, zero for none
+
+	millMu sync.Mutex // Serializes compressing and deleting rotated files
+}
+
+// Open opens the log file of cfg for appending, creating it and its
+// directory when needed
+func Open(cfg config.LogFileConfig) (*Writer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	w := &Writer{cfg: cfg, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes an entry to the log file, first rotating it when the entry
+// would make it exceed the maximum size or a rotation time has passed. A
+// closed writer reopens the file.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	due := !w.rotateAt.IsZero() && !w.now().Before(w.rotateAt)
+	if due || w.size > 0 && w.size+int64(len(p)) > w.maxSize() {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Rotate rotates the log file now
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	return w.rotate()
+}
+
+// maxSize returns the size in bytes that triggers a rotation
+func (w *Writer) maxSize() int64 {
+	return int64(w.cfg.MaxSizeMB) * 1024 * 1024
+}
+
+// open opens the log file for appending and schedules the next time-based
+// rotation. Callers hold w.mu.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(w.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	w.file, w.size = file, info.Size()
+	w.rotateAt = time.Time{}
+	if w.cfg.RotateEvery > 0 {
+		w.rotateAt = w.now().UTC().Truncate(w.cfg.RotateEvery).Add(w.cfg.RotateEvery)
+	}
+	return nil
+}
+
+// rotate renames the open log file with the rotation time, opens a new one
+// and cleans up rotated files in the background. Callers hold w.mu.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	rotated := w.backupName(w.now())
+	if err := os.Rename(w.cfg.Path, rotated); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	go w.mill()
+	return nil
+}
+
+// backupName returns the name of the log file rotated at t
+func (w *Writer) backupName(t time.Time) string {
+	dir, base := filepath.Split(w.cfg.Path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, strings.TrimSuffix(base, ext)+"-"+t.UTC().Format(timeFormat)+ext)
+}
+
+// backup is a rotated log file
+type backup struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backups returns the rotated log files, newest first
+func (w *Writer) backups() ([]backup, error) {
+	dir, base := filepath.Split(w.cfg.Path)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		rotatedAt, err := time.Parse(timeFormat, strings.TrimPrefix(stamp, prefix))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), rotatedAt: rotatedAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.After(backups[j].rotatedAt)
+	})
+	return backups, nil
+}
+
+// mill deletes the rotated files beyond the maximum number of backups or
+// older than the maximum age and compresses the others. Errors are written
+// to stderr, as the log is what failed.
+func (w *Writer) mill() {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	backups, err := w.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logfile: failed to list rotated logs: %v\n", err)
+		return
+	}
+	for i, b := range backups {
+		tooMany := w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups
+		tooOld := w.cfg.MaxAge > 0 && w.now().Sub(b.rotatedAt) > w.cfg.MaxAge
+		switch {
+		case tooMany || tooOld:
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "logfile: failed to delete rotated log: %v\n", err)
+			}
+		case w.cfg.Compress && !strings.HasSuffix(b.path, ".gz"):
+			if err := compress(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "logfile: failed to compress rotated log: %v\n", err)
+			}
+		}
+	}
+}
+
+// compress gzips a file to path.gz and removes it
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
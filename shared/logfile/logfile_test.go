@@ -0,0 +1,119 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// files returns the names of the files in dir, sorted
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriterRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(config.LogFileConfig{Path: filepath.Join(dir, "logs", "api-gateway.log"), MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer w.Close()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	entry := []byte(strings.Repeat("x", 600*1024) + "\n")
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write(entry); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	names := files(t, filepath.Join(dir, "logs"))
+	want := []string{"api-gateway-2024-03-01T12-00-01.000.log", "api-gateway-2024-03-01T12-00-02.000.log", "api-gateway.log"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "logs", "api-gateway.log")); info.Size() != int64(len(entry)) {
+		t.Errorf("log file size = %d, want one entry", info.Size())
+	}
+}
+
+func TestWriterRotatesByTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "url-manager.log")
+	now := time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC)
+	w := &Writer{cfg: config.LogFileConfig{Path: path, MaxSizeMB: 100, RotateEvery: 24 * time.Hour}, now: func() time.Time { return now }}
+	if err := w.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("before midnight\n"))
+	now = now.Add(2 * time.Minute)
+	w.Write([]byte("after midnight\n"))
+	w.Write([]byte("still the same day\n"))
+
+	rotated, err := os.ReadFile(filepath.Join(dir, "url-manager-2024-03-02T00-01-00.000.log"))
+	if err != nil || string(rotated) != "before midnight\n" {
+		t.Errorf("rotated file = %q, %v; want the entry before midnight", rotated, err)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != "after midnight\nstill the same day\n" {
+		t.Errorf("log file = %q, want the entries after midnight", current)
+	}
+}
+
+func TestWriterCleansUpBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	w := &Writer{cfg: config.LogFileConfig{Path: path, MaxSizeMB: 1, MaxBackups: 2, MaxAge: 48 * time.Hour, Compress: true}, now: func() time.Time { return now }}
+
+	// An old backup, past the maximum age, and an unrelated file
+	os.WriteFile(filepath.Join(dir, "app-2024-03-01T00-00-00.000.log.gz"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "other.log"), nil, 0o644)
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Hour)
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+		w.Write([]byte("entry\n"))
+	}
+	w.Close()
+	w.mill()
+
+	want := []string{"app-2024-03-10T02-00-00.000.log.gz", "app-2024-03-10T03-00-00.000.log.gz", "app.log", "other.log"}
+	if names := files(t, dir); strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	f, err := os.Open(filepath.Join(dir, want[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(gz); string(content) != "entry\n" {
+		t.Errorf("compressed backup = %q, want the rotated entry", content)
+	}
+}
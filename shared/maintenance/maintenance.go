@@ -0,0 +1,138 @@
+// Package maintenance implements the maintenance mode switch used during
+// database migrations and Kafka maintenance. While it is on the URL Manager
+// stops scheduling scrapes, workers finish their running tasks without
+// taking new ones and the API Gateway rejects changes. The switch is stored
+// in the maintenance_mode table and each service re-reads it every
+// maintenance.refresh_interval.
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// State is the maintenance mode switch
+type State struct {
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"` // Shown to API clients, e.g. the reason and expected end
+	Since   time.Time `json:"since,omitempty"`   // When the switch last changed
+}
+
+// Store loads the switch persisted outside the service
+type Store interface {
+	GetState(ctx context.Context) (State, error)
+}
+
+// Pausable is a component that takes no new work while paused and finishes
+// the work it is doing, such as a worker.Pool
+type Pausable interface {
+	Pause()
+	Resume()
+}
+
+// Mode tracks the maintenance mode switch of a service
+type Mode struct {
+	store  Store
+	logger *logrus.Logger
+
+	mu          sync.RWMutex
+	state       State
+	subscribers []func(State)
+}
+
+// New creates a switch that is off until the first Refresh. store may be
+// nil, in which case the switch only changes through Set.
+func New(store Store, logger *logrus.Logger) *Mode {
+	return &Mode{store: store, logger: logger}
+}
+
+// Current returns the switch as last read
+func (m *Mode) Current() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Enabled reports whether the service is in maintenance mode
+func (m *Mode) Enabled() bool {
+	return m.Current().Enabled
+}
+
+// OnChange registers a function called with the new state whenever the
+// switch is turned on or off or its message changes
+func (m *Mode) OnChange(fn func(State)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// PauseDuring pauses p while maintenance mode is on
+func (m *Mode) PauseDuring(p Pausable) {
+	m.OnChange(func(state State) {
+		if state.Enabled {
+			p.Pause()
+		} else {
+			p.Resume()
+		}
+	})
+	if m.Enabled() {
+		p.Pause()
+	}
+}
+
+// Set applies a state, e.g. right after it was stored, without waiting for
+// the next Refresh
+func (m *Mode) Set(state State) {
+	m.mu.Lock()
+	previous := m.state
+	m.state = state
+	subscribers := make([]func(State), len(m.subscribers))
+	copy(subscribers, m.subscribers)
+	m.mu.Unlock()
+
+	if state.Enabled == previous.Enabled && state.Message == previous.Message {
+		return
+	}
+	if state.Enabled {
+		m.logger.WithField("message", state.Message).Warn("Maintenance mode enabled")
+	} else if previous.Enabled {
+		m.logger.Info("Maintenance mode disabled")
+	}
+	for _, fn := range subscribers {
+		fn(state)
+	}
+}
+
+// Refresh reloads the switch from the store. On error the previous state
+// stays in effect.
+func (m *Mode) Refresh(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	state, err := m.store.GetState(ctx)
+	if err != nil {
+		return err
+	}
+	m.Set(state)
+	return nil
+}
+
+// Run refreshes the switch from the store every interval until ctx is cancelled
+func (m *Mode) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Refresh(ctx); err != nil && ctx.Err() == nil {
+				m.logger.WithError(err).Warn("Failed to refresh maintenance mode, keeping previous state")
+			}
+		}
+	}
+}
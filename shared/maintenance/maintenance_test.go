@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeStore struct {
+	state State
+	err   error
+}
+
+func (s *fakeStore) GetState(ctx context.Context) (State, error) {
+	return s.state, s.err
+}
+
+// fakePool records whether it is paused
+type fakePool struct {
+	paused bool
+}
+
+func (p *fakePool) Pause()  { p.paused = true }
+func (p *fakePool) Resume() { p.paused = false }
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestModeRefresh(t *testing.T) {
+	store := &fakeStore{}
+	mode := New(store, quietLogger())
+
+	var changes []State
+	mode.OnChange(func(state State) { changes = append(changes, state) })
+	pool := &fakePool{}
+	mode.PauseDuring(pool)
+
+	store.state = State{Enabled: true, Message: "Database migration until 14:00 UTC"}
+	if err := mode.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if !mode.Enabled() || !pool.paused {
+		t.Fatalf("Enabled() = %v, pool paused = %v; want both true", mode.Enabled(), pool.paused)
+	}
+
+	// Unchanged state: no notification
+	if err := mode.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// A failing store keeps the previous state
+	store.err = errors.New("connection refused")
+	if err := mode.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh() error = nil, want store error")
+	}
+	if !mode.Enabled() {
+		t.Error("store error turned maintenance mode off")
+	}
+
+	store.err = nil
+	store.state = State{}
+	if err := mode.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if mode.Enabled() || pool.paused {
+		t.Errorf("Enabled() = %v, pool paused = %v; want both false", mode.Enabled(), pool.paused)
+	}
+	if len(changes) != 2 {
+		t.Errorf("got %d change notifications, want 2", len(changes))
+	}
+}
+
+func TestPauseDuringActiveMaintenance(t *testing.T) {
+	mode := New(nil, quietLogger())
+	mode.Set(State{Enabled: true})
+
+	pool := &fakePool{}
+	mode.PauseDuring(pool)
+	if !pool.paused {
+		t.Error("pool registered during maintenance was not paused")
+	}
+}
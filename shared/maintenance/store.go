@@ -0,0 +1,41 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+
+	"go_scraping_project/shared/database"
+)
+
+// Querier is the subset of database queries used by DBStore
+type Querier interface {
+	GetMaintenanceMode(ctx context.Context) (database.MaintenanceMode, error)
+}
+
+// DBStore loads the switch from the maintenance_mode table
+type DBStore struct {
+	db Querier
+}
+
+// NewDBStore creates a store backed by the given queries
+func NewDBStore(db Querier) *DBStore {
+	return &DBStore{db: db}
+}
+
+// GetState returns the stored switch; maintenance mode is off if it was
+// never set
+func (s *DBStore) GetState(ctx context.Context) (State, error) {
+	row, err := s.db.GetMaintenanceMode(ctx)
+	if err == sql.ErrNoRows {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return StateFromRow(row), nil
+}
+
+// StateFromRow converts a maintenance_mode row to a State
+func StateFromRow(row database.MaintenanceMode) State {
+	return State{Enabled: row.Enabled, Message: row.Message, Since: row.UpdatedAt}
+}
@@ -0,0 +1,235 @@
+// Package mocksite is a target site with fixed behaviors for integration
+// tests and demos: static pages for the built-in parser templates, delayed
+// responses, rate limiting with 429s, redirect chains, error statuses,
+// JavaScript-only content and robots.txt variants. Every response depends
+// only on the request and the site's options, so scrapes are reproducible
+// without hitting real sites.
+//
+// Routes:
+//
+//	/                       Index linking every fixture
+//	/pages/{name}           Static page: product, article or job
+//	/delay/{duration}       Product page after a delay, e.g. /delay/1500ms
+//	/status/{code}          Empty page with the status code, e.g. /status/503
+//	/throttle/{n}           429 with Retry-After for the first n requests, then the product page
+//	/redirect/{n}           Chain of n redirects ending at the product page
+//	/redirect/loop          Redirect to itself
+//	/js-only                Page rendering its content with JavaScript
+//	/private/{anything}     Product page, disallowed by the partial robots.txt
+//	/robots.txt             The robots.txt variant of the site's options
+//
+// The requests counted by /throttle/{n} are those to the same path and
+// query, so tests can use a query such as ?key=test-name to get their own
+// count. Retry-After is 1 second, or the retry_after query parameter.
+package mocksite
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of the request-controlled behaviors
+const (
+	DefaultMaxDelay = 30 * time.Second
+	MaxRedirects    = 20
+)
+
+// Options configures a site
+type Options struct {
+	Robots   string        // robots.txt variant, RobotsAllowAll when empty
+	MaxDelay time.Duration // Longest delay /delay waits, DefaultMaxDelay when 0
+}
+
+// Site is the handler of a mock target site
+type Site struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu        sync.Mutex
+	throttled map[string]int // Requests seen by /throttle URL
+}
+
+// New creates a site, failing if the robots.txt variant is unknown
+func New(opts Options) (*Site, error) {
+	if opts.Robots == "" {
+		opts.Robots = RobotsAllowAll
+	}
+	if _, ok := robotsFiles[opts.Robots]; !ok && opts.Robots != RobotsMissing && opts.Robots != RobotsError {
+		return nil, fmt.Errorf("unknown robots.txt variant %q", opts.Robots)
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = DefaultMaxDelay
+	}
+
+	s := &Site{opts: opts, mux: http.NewServeMux(), throttled: make(map[string]int)}
+	s.mux.HandleFunc("/", s.index)
+	s.mux.HandleFunc("/pages/", s.page)
+	s.mux.HandleFunc("/delay/", s.delay)
+	s.mux.HandleFunc("/status/", s.status)
+	s.mux.HandleFunc("/throttle/", s.throttle)
+	s.mux.HandleFunc("/redirect/", s.redirect)
+	s.mux.HandleFunc("/js-only", s.jsOnly)
+	s.mux.HandleFunc("/private/", s.private)
+	s.mux.HandleFunc("/robots.txt", s.robots)
+	return s, nil
+}
+
+// ServeHTTP serves the site
+func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Reset forgets the requests counted by /throttle
+func (s *Site) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttled = make(map[string]int)
+}
+
+func (s *Site) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	writeHTML(w, http.StatusOK, `<!DOCTYPE html>
+<html>
+<head><title>Mock site</title></head>
+<body>
+  <h1>Mock site</h1>
+  <ul>
+    <li><a href="/pages/product">Product page</a></li>
+    <li><a href="/pages/article">Article page</a></li>
+    <li><a href="/pages/job">Job listing</a></li>
+    <li><a href="/delay/2s">Delayed page</a></li>
+    <li><a href="/status/500">Server error</a></li>
+    <li><a href="/throttle/2">Rate limited page</a></li>
+    <li><a href="/redirect/3">Redirect chain</a></li>
+    <li><a href="/redirect/loop">Redirect loop</a></li>
+    <li><a href="/js-only">JavaScript-only page</a></li>
+    <li><a href="/private/page">Private page</a></li>
+  </ul>
+</body>
+</html>
+`)
+}
+
+func (s *Site) page(w http.ResponseWriter, r *http.Request) {
+	body, ok := pages[strings.TrimPrefix(r.URL.Path, "/pages/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeHTML(w, http.StatusOK, body)
+}
+
+func (s *Site) delay(w http.ResponseWriter, r *http.Request) {
+	d, err := time.ParseDuration(strings.TrimPrefix(r.URL.Path, "/delay/"))
+	if err != nil || d < 0 {
+		http.Error(w, "invalid delay, use a duration such as 500ms", http.StatusBadRequest)
+		return
+	}
+	if d > s.opts.MaxDelay {
+		d = s.opts.MaxDelay
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		writeHTML(w, http.StatusOK, productPage)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *Site) status(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+	if err != nil || code < 200 || code > 599 {
+		http.Error(w, "invalid status code", http.StatusBadRequest)
+		return
+	}
+	if code >= 300 && code < 400 {
+		w.Header().Set("Location", "/pages/product")
+	}
+	writeHTML(w, code, fmt.Sprintf("<!DOCTYPE html>\n<html><head><title>%d</title></head><body><h1>%d %s</h1></body></html>\n", code, code, http.StatusText(code)))
+}
+
+func (s *Site) throttle(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/throttle/"))
+	if err != nil || limit < 0 {
+		http.Error(w, "invalid request count", http.StatusBadRequest)
+		return
+	}
+	retryAfter := r.URL.Query().Get("retry_after")
+	if retryAfter == "" {
+		retryAfter = "1"
+	} else if seconds, err := strconv.Atoi(retryAfter); err != nil || seconds < 0 {
+		http.Error(w, "invalid retry_after, use seconds", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	key := r.URL.RequestURI()
+	s.throttled[key]++
+	seen := s.throttled[key]
+	s.mu.Unlock()
+
+	if seen <= limit {
+		w.Header().Set("Retry-After", retryAfter)
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+	writeHTML(w, http.StatusOK, productPage)
+}
+
+func (s *Site) redirect(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/redirect/")
+	if rest == "loop" {
+		http.Redirect(w, r, "/redirect/loop", http.StatusFound)
+		return
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 0 || n > MaxRedirects {
+		http.Error(w, fmt.Sprintf("invalid redirect count, use 0 to %d", MaxRedirects), http.StatusBadRequest)
+		return
+	}
+	if n == 0 {
+		writeHTML(w, http.StatusOK, productPage)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+}
+
+func (s *Site) jsOnly(w http.ResponseWriter, r *http.Request) {
+	writeHTML(w, http.StatusOK, jsOnlyPage)
+}
+
+func (s *Site) private(w http.ResponseWriter, r *http.Request) {
+	writeHTML(w, http.StatusOK, productPage)
+}
+
+func (s *Site) robots(w http.ResponseWriter, r *http.Request) {
+	switch s.opts.Robots {
+	case RobotsMissing:
+		http.NotFound(w, r)
+	case RobotsError:
+		http.Error(w, "robots.txt unavailable", http.StatusServiceUnavailable)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, robotsFiles[s.opts.Robots])
+	}
+}
+
+func writeHTML(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	fmt.Fprint(w, body)
+}
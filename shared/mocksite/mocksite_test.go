@@ -0,0 +1,169 @@
+package mocksite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/parser"
+	"go_scraping_project/shared/robots"
+)
+
+func newServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	site, err := New(opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	server := httptest.NewServer(site)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *http.Client, address string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(address)
+	if err != nil {
+		t.Fatalf("GET %s: %v", address, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestSiteRoutes(t *testing.T) {
+	server := newServer(t, Options{MaxDelay: 50 * time.Millisecond})
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/", http.StatusOK, "Mock site"},
+		{"/pages/article", http.StatusOK, "How to descale a kettle"},
+		{"/pages/unknown", http.StatusNotFound, ""},
+		{"/status/503", http.StatusServiceUnavailable, "503 Service Unavailable"},
+		{"/status/99", http.StatusBadRequest, ""},
+		{"/delay/1h", http.StatusOK, "Steel kettle"},
+		{"/delay/soon", http.StatusBadRequest, ""},
+		{"/redirect/3", http.StatusOK, "Steel kettle"},
+		{"/redirect/21", http.StatusBadRequest, ""},
+		{"/js-only", http.StatusOK, "<div id=\"app\"></div>"},
+		{"/nowhere", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		resp, body := get(t, server.Client(), server.URL+tt.path)
+		if resp.StatusCode != tt.code || !strings.Contains(body, tt.body) {
+			t.Errorf("GET %s = %d %q, want %d containing %q", tt.path, resp.StatusCode, body, tt.code, tt.body)
+		}
+	}
+
+	start := time.Now()
+	get(t, server.Client(), server.URL+"/delay/1h")
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("/delay/1h took %s, want the 50ms maximum delay", elapsed)
+	}
+
+	resp, err := server.Client().Post(server.URL+"/pages/product", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestSiteThrottle(t *testing.T) {
+	site, _ := New(Options{})
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, _ := get(t, server.Client(), server.URL+"/throttle/2?key=a&retry_after=7")
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "7" {
+			t.Fatalf("request %d = %d, Retry-After %q; want 429, 7", i+1, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+	if resp, _ := get(t, server.Client(), server.URL+"/throttle/2?key=a&retry_after=7"); resp.StatusCode != http.StatusOK {
+		t.Errorf("third request = %d, want 200", resp.StatusCode)
+	}
+	// Other keys have their own count
+	if resp, _ := get(t, server.Client(), server.URL+"/throttle/2?key=b"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("first request of key b = %d, want 429", resp.StatusCode)
+	}
+
+	site.Reset()
+	if resp, _ := get(t, server.Client(), server.URL+"/throttle/2?key=a&retry_after=7"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("request after Reset = %d, want 429", resp.StatusCode)
+	}
+}
+
+func TestSiteRedirectLoop(t *testing.T) {
+	server := newServer(t, Options{})
+	_, err := server.Client().Get(server.URL + "/redirect/loop")
+	if err == nil || !strings.Contains(err.Error(), "stopped after 10 redirects") {
+		t.Errorf("GET /redirect/loop error = %v, want a redirect loop", err)
+	}
+}
+
+func TestSiteRobots(t *testing.T) {
+	tests := []struct {
+		variant string
+		allowed map[string]bool // By path, for a generic crawler
+	}{
+		{RobotsAllowAll, map[string]bool{"/pages/product": true, "/private/page": true}},
+		{RobotsDisallowAll, map[string]bool{"/pages/product": false}},
+		{RobotsPartial, map[string]bool{"/pages/product": true, "/private/page": false, "/private/public": true}},
+		{RobotsMissing, map[string]bool{"/pages/product": true}},
+	}
+	for _, tt := range tests {
+		server := newServer(t, Options{Robots: tt.variant})
+		page, _ := url.Parse(server.URL + "/")
+		rules, err := robots.Fetch(context.Background(), server.Client(), page, "testbot/1.0")
+		if err != nil {
+			t.Fatalf("%s: Fetch() error = %v", tt.variant, err)
+		}
+		for path, want := range tt.allowed {
+			if got := rules.Allowed("testbot/1.0", path); got != want {
+				t.Errorf("%s: Allowed(%s) = %t, want %t", tt.variant, path, got, want)
+			}
+		}
+	}
+
+	server := newServer(t, Options{Robots: RobotsError})
+	page, _ := url.Parse(server.URL + "/")
+	if _, err := robots.Fetch(context.Background(), server.Client(), page, "testbot/1.0"); err == nil {
+		t.Error("Fetch() of the error variant succeeded, want an error")
+	}
+
+	if _, err := New(Options{Robots: "sometimes"}); err == nil {
+		t.Error("New() accepted an unknown robots.txt variant")
+	}
+}
+
+func TestPagesMatchParserTemplates(t *testing.T) {
+	tests := map[string]string{
+		"product": "product-page",
+		"article": "generic-article",
+		"job":     "job-listing",
+	}
+	for name, template := range tests {
+		tmpl, _ := parser.BuiltinTemplate(template)
+		p, err := parser.NewParser(parser.Apply(tmpl, nil))
+		if err != nil {
+			t.Fatalf("%s: NewParser() error = %v", template, err)
+		}
+		record, err := p.Parse(context.Background(), parser.Document{URL: "http://mocksite/pages/" + name, StatusCode: 200, Body: pages[name]})
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", name, err)
+		}
+		if missing := p.MissingFields(record); len(missing) > 0 {
+			t.Errorf("page %s misses the %s fields %v", name, template, missing)
+		}
+	}
+}
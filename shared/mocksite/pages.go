@@ -0,0 +1,99 @@
+package mocksite
+
+// Pages served at /pages/{name}, shaped like the sites the built-in parser
+// templates are made for
+var pages = map[string]string{
+	"product": productPage,
+	"article": articlePage,
+	"job":     jobPage,
+}
+
+const productPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Steel kettle</title>
+  <meta name="description" content="A sturdy 1.7 l steel kettle">
+  <meta property="og:image" content="/images/kettle.jpg">
+</head>
+<body>
+  <h1>Steel kettle</h1>
+  <div class="product-description"><p>Boils 1.7 litres in under three minutes.</p></div>
+  <span itemprop="price" content="24.90">€24.90</span>
+  <meta itemprop="priceCurrency" content="EUR">
+  <span itemprop="availability">In stock</span>
+  <span itemprop="sku">KT-1700</span>
+  <a href="/pages/article">Kettle care guide</a>
+</body>
+</html>
+`
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>How to descale a kettle</title>
+  <meta property="og:image" content="/images/descale.jpg">
+  <meta property="article:published_time" content="2024-03-01T08:00:00Z">
+</head>
+<body>
+  <article>
+    <h1>How to descale a kettle</h1>
+    <a rel="author" href="/authors/1">Dana Levi</a>
+    <time datetime="2024-03-01T08:00:00Z">1 March 2024</time>
+    <p>Fill the kettle with equal parts water and vinegar, boil and leave it for an hour.</p>
+    <p>Rinse twice before use.</p>
+  </article>
+  <a href="/pages/product">Shop kettles</a>
+</body>
+</html>
+`
+
+const jobPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Backend engineer</title>
+</head>
+<body>
+  <h1 itemprop="title">Backend engineer</h1>
+  <span itemprop="hiringOrganization">Kettle Works</span>
+  <span itemprop="jobLocation">Tel Aviv</span>
+  <span itemprop="baseSalary">Competitive</span>
+  <span itemprop="employmentType">Full-time</span>
+  <meta itemprop="datePosted" content="2024-04-15">
+  <div class="job-description"><p>Build the services behind our kettles.</p></div>
+</body>
+</html>
+`
+
+// jsOnlyPage renders its content with JavaScript, so scrapers that do not
+// run scripts see an empty shell
+const jsOnlyPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Loading…</title>
+</head>
+<body>
+  <div id="app"></div>
+  <noscript>This page requires JavaScript.</noscript>
+  <script>
+    document.getElementById("app").innerHTML =
+      '<h1>Steel kettle</h1><span itemprop="price" content="24.90">€24.90</span>';
+    document.title = "Steel kettle";
+  </script>
+</body>
+</html>
+`
+
+// Robots.txt variants served at /robots.txt
+const (
+	RobotsAllowAll    = "allow"    // Everything may be fetched
+	RobotsDisallowAll = "disallow" // Nothing may be fetched
+	RobotsPartial     = "partial"  // /private/ is disallowed, and everything for the badbot agent
+	RobotsMissing     = "missing"  // 404, which allows everything
+	RobotsError       = "error"    // 503, which disallows everything
+)
+
+var robotsFiles = map[string]string{
+	RobotsAllowAll:    "User-agent: *\nAllow: /\n",
+	RobotsDisallowAll: "User-agent: *\nDisallow: /\n",
+	RobotsPartial:     "User-agent: badbot\nDisallow: /\n\nUser-agent: *\nDisallow: /private/\nAllow: /private/public$\n",
+}
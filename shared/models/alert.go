@@ -0,0 +1,15 @@
+package models
+
+// Kinds of system alert rules, see config.AlertRuleConfig
+const (
+	AlertKindDLQDepth    = "dlq_depth"    // Messages in the dead letter queue
+	AlertKindSuccessRate = "success_rate" // Percentage of scrapes that succeeded over a window
+	AlertKindConsumerLag = "consumer_lag" // Messages a consumer group has not consumed yet
+	AlertKindNoScrapes   = "no_scrapes"   // Minutes since the last scrape completed
+)
+
+// States of the events in the alert history
+const (
+	AlertStateFiring   = "firing"   // The rule's condition started to hold
+	AlertStateResolved = "resolved" // The rule's condition stopped holding
+)
@@ -0,0 +1,47 @@
+package models
+
+import "fmt"
+
+// Archive policy modes
+const (
+	ArchiveModeAll     = "all"     // Keep the raw HTML of every scrape
+	ArchiveModeSample  = "sample"  // Keep the raw HTML of 1 in every SampleEvery scrapes
+	ArchiveModeChanged = "changed" // Keep the raw HTML only when it differs from the last kept snapshot
+)
+
+// MaxArchiveSampleEvery limits the sampling interval of an archive policy
+const MaxArchiveSampleEvery = 10000
+
+// ArchivePolicy controls which scrapes of a URL keep their raw HTML in
+// raw_html_snapshots. Scrapers receive it with each task. URLs without a
+// policy keep every scrape, as does the first scrape of a URL under any
+// policy. Mode changed compares the SHA-256 of the content with the
+// content_hash of the latest snapshot. Mode sample keeps a scrape once
+// SampleEvery scrapes have started since the latest snapshot. Sampling and
+// keeping only changed content reduce storage for high-frequency monitors
+// while keeping raw samples for debugging. RenderSession also keeps the
+// browser console log and network requests of rendered scrapes with their
+// raw HTML (see package render); it is off by default since sessions are
+// larger than the HTML itself.
+type ArchivePolicy struct {
+	Mode          string `json:"mode"`                     // all, sample or changed
+	SampleEvery   int    `json:"sample_every,omitempty"`   // With mode sample: keep 1 in every N scrapes
+	RenderSession bool   `json:"render_session,omitempty"` // Keep the render session of kept rendered scrapes
+}
+
+// Validate checks that the policy is well-formed
+func (p ArchivePolicy) Validate() error {
+	switch p.Mode {
+	case ArchiveModeSample:
+		if p.SampleEvery < 1 || p.SampleEvery > MaxArchiveSampleEvery {
+			return fmt.Errorf("sample_every must be between 1 and %d", MaxArchiveSampleEvery)
+		}
+	case ArchiveModeAll, ArchiveModeChanged:
+		if p.SampleEvery != 0 {
+			return fmt.Errorf("sample_every is only used with mode %s", ArchiveModeSample)
+		}
+	default:
+		return fmt.Errorf("mode must be %s, %s or %s", ArchiveModeAll, ArchiveModeSample, ArchiveModeChanged)
+	}
+	return nil
+}
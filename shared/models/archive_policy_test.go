@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestArchivePolicyValidate(t *testing.T) {
+	valid := []ArchivePolicy{
+		{Mode: ArchiveModeAll},
+		{Mode: ArchiveModeChanged},
+		{Mode: ArchiveModeSample, SampleEvery: 10},
+	}
+	for _, policy := range valid {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", policy, err)
+		}
+	}
+
+	invalid := []ArchivePolicy{
+		{},
+		{Mode: "never"},
+		{Mode: ArchiveModeSample},
+		{Mode: ArchiveModeChanged, SampleEvery: 5},
+	}
+	for _, policy := range invalid {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Validate(%+v) accepted an invalid policy", policy)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxAssertionChecks limits the number of texts and selectors of a URL's assertions
+const MaxAssertionChecks = 20
+
+// Assertions are checks on the content of a fetched page, evaluated after
+// each scrape (see parser.EvaluateAssertions). A scrape that fetched the page
+// but fails an assertion is soft-failed: it is not retried, but it counts as
+// a failed scrape for metrics and alerting. This catches error pages served
+// with 200 OK and layout changes that break extraction.
+type Assertions struct {
+	Status    int      `json:"status,omitempty"`    // Required HTTP status code, e.g. 200
+	Contains  []string `json:"contains,omitempty"`  // Texts the response body must contain
+	Selectors []string `json:"selectors,omitempty"` // CSS selectors that must each match an element
+}
+
+// Validate checks that the assertions are well-formed. Selector syntax is
+// checked by the parser, see parser.ValidateAssertions.
+func (a Assertions) Validate() error {
+	if a.Status == 0 && len(a.Contains) == 0 && len(a.Selectors) == 0 {
+		return fmt.Errorf("at least one of status, contains or selectors is required")
+	}
+	if a.Status != 0 && (a.Status < 100 || a.Status > 599) {
+		return fmt.Errorf("status must be an HTTP status code")
+	}
+	if len(a.Contains)+len(a.Selectors) > MaxAssertionChecks {
+		return fmt.Errorf("at most %d contains and selectors entries are allowed", MaxAssertionChecks)
+	}
+	for _, text := range a.Contains {
+		if text == "" {
+			return fmt.Errorf("contains entries must not be empty")
+		}
+	}
+	for _, selector := range a.Selectors {
+		if strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("selectors must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limits of a data view's filter
+const (
+	MaxViewURLs       = 1000 // URLs in a view's URL set
+	MaxViewPredicates = 20   // Field predicates of a view
+)
+
+// Field predicate operators
+const (
+	PredicateEq     = "eq"     // Field equals the value
+	PredicateNe     = "ne"     // Field is missing or differs from the value
+	PredicateGt     = "gt"     // Field is greater than the value
+	PredicateGte    = "gte"    // Field is greater than or equal to the value
+	PredicateLt     = "lt"     // Field is less than the value
+	PredicateLte    = "lte"    // Field is less than or equal to the value
+	PredicateExists = "exists" // Field is present, the value is ignored
+)
+
+// predicateOperators maps predicate operators to SQL/JSON path operators
+var predicateOperators = map[string]string{
+	PredicateEq:  "==",
+	PredicateNe:  "!=",
+	PredicateGt:  ">",
+	PredicateGte: ">=",
+	PredicateLt:  "<",
+	PredicateLte: "<=",
+}
+
+// FieldPredicate is a condition on a field of a record's parsed data.
+// Nested fields are addressed with dots, e.g. "offer.price". Values are
+// compared as JSON: a number only matches numbers and a string only strings.
+type FieldPredicate struct {
+	Field string      `json:"field"`           // Parsed field, dotted for nested fields
+	Op    string      `json:"op"`              // Operator, see the Predicate constants
+	Value interface{} `json:"value,omitempty"` // Value to compare with: a string, number, boolean or null
+}
+
+// Validate checks that the predicate has a field, a known operator and,
+// unless it tests for existence, a value that can be compared
+func (p FieldPredicate) Validate() error {
+	if p.Field == "" || strings.Contains("."+p.Field+".", "..") {
+		return fmt.Errorf("invalid field %q", p.Field)
+	}
+	if p.Op == PredicateExists {
+		return nil
+	}
+	op, ok := predicateOperators[p.Op]
+	if !ok {
+		return fmt.Errorf("unknown operator %q for field %s", p.Op, p.Field)
+	}
+	switch p.Value.(type) {
+	case string, float64:
+	case bool, nil:
+		if op != "==" && op != "!=" {
+			return fmt.Errorf("operator %q of field %s needs a number or string", p.Op, p.Field)
+		}
+	default:
+		return fmt.Errorf("value of field %s must be a string, number, boolean or null", p.Field)
+	}
+	return nil
+}
+
+// PredicateFilter compiles predicates into a SQL/JSON path filter over the
+// parsed data that matches the records meeting all of them, for use with
+// jsonb_path_match. Without predicates it matches every record. The
+// predicates must be valid.
+func PredicateFilter(predicates []FieldPredicate) string {
+	if len(predicates) == 0 {
+		return "true"
+	}
+
+	conditions := make([]string, 0, len(predicates))
+	for _, p := range predicates {
+		path := "$"
+		for _, key := range strings.Split(p.Field, ".") {
+			quoted, _ := json.Marshal(key)
+			path += "." + string(quoted)
+		}
+		if p.Op == PredicateExists {
+			conditions = append(conditions, "exists("+path+")")
+			continue
+		}
+		value, _ := json.Marshal(p.Value)
+		condition := path + " " + predicateOperators[p.Op] + " " + string(value)
+		if p.Op == PredicateNe {
+			// A missing field compares as unknown, yet it differs from any value
+			condition = "(" + condition + " || !exists(" + path + "))"
+		}
+		conditions = append(conditions, condition)
+	}
+	return strings.Join(conditions, " && ")
+}
+
+// ValidatePredicates checks the predicates of a data view
+func ValidatePredicates(predicates []FieldPredicate) error {
+	if len(predicates) > MaxViewPredicates {
+		return fmt.Errorf("a view cannot have more than %d predicates", MaxViewPredicates)
+	}
+	for _, p := range predicates {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestFieldPredicateValidate(t *testing.T) {
+	tests := []struct {
+		predicate FieldPredicate
+		wantErr   bool
+	}{
+		{predicate: FieldPredicate{Field: "price", Op: PredicateLt, Value: 100.0}},
+		{predicate: FieldPredicate{Field: "offer.currency", Op: PredicateEq, Value: "EUR"}},
+		{predicate: FieldPredicate{Field: "in_stock", Op: PredicateEq, Value: true}},
+		{predicate: FieldPredicate{Field: "discount", Op: PredicateExists}},
+		{predicate: FieldPredicate{Field: "", Op: PredicateEq, Value: "x"}, wantErr: true},
+		{predicate: FieldPredicate{Field: "offer..price", Op: PredicateEq, Value: 1.0}, wantErr: true},
+		{predicate: FieldPredicate{Field: "price", Op: "like", Value: "x"}, wantErr: true},
+		{predicate: FieldPredicate{Field: "in_stock", Op: PredicateGt, Value: true}, wantErr: true},
+		{predicate: FieldPredicate{Field: "tags", Op: PredicateEq, Value: []interface{}{"a"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.predicate.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.predicate, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPredicateFilter(t *testing.T) {
+	tests := []struct {
+		predicates []FieldPredicate
+		want       string
+	}{
+		{predicates: nil, want: "true"},
+		{
+			predicates: []FieldPredicate{
+				{Field: "category", Op: PredicateEq, Value: `say "hi"`},
+				{Field: "offer.price", Op: PredicateLte, Value: 9.5},
+			},
+			want: `$."category" == "say \"hi\"" && $."offer"."price" <= 9.5`,
+		},
+		{
+			predicates: []FieldPredicate{
+				{Field: "discount", Op: PredicateExists},
+				{Field: "status", Op: PredicateNe, Value: "sold"},
+			},
+			want: `exists($."discount") && ($."status" != "sold" || !exists($."status"))`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := PredicateFilter(tt.predicates); got != tt.want {
+			t.Errorf("PredicateFilter(%+v) = %s, want %s", tt.predicates, got, tt.want)
+		}
+	}
+}
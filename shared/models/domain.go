@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"go_scraping_project/shared/har"
+
 	"github.com/google/uuid"
 )
 
@@ -10,58 +12,145 @@ import (
 
 // URL represents a URL to be scraped
 type URL struct {
-	ID            uuid.UUID     `json:"id"`
-	URL           string        `json:"url"`
-	Frequency     string        `json:"frequency"`
-	Status        string        `json:"status"`
-	MaxRetries    int           `json:"max_retries"`
-	Timeout       int           `json:"timeout"`
-	RateLimit     int           `json:"rate_limit"`
-	UserAgent     string        `json:"user_agent,omitempty"`
-	ParserConfig  *ParserConfig `json:"parser_config,omitempty"`
-	NextScrapeAt  *time.Time    `json:"next_scrape_at,omitempty"`
-	LastScrapedAt *time.Time    `json:"last_scraped_at,omitempty"`
-	RetryCount    int           `json:"retry_count"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID            uuid.UUID      `json:"id"`
+	URL           string         `json:"url"`
+	Frequency     string         `json:"frequency"`
+	Status        string         `json:"status"`
+	MaxRetries    int            `json:"max_retries"`
+	Timeout       int            `json:"timeout"`
+	RateLimit     int            `json:"rate_limit"`
+	UserAgent     string         `json:"user_agent,omitempty"`
+	ParserConfig  *ParserConfig  `json:"parser_config,omitempty"`
+	NextScrapeAt  *time.Time     `json:"next_scrape_at,omitempty"`
+	LastScrapedAt *time.Time     `json:"last_scraped_at,omitempty"`
+	RetryCount    int            `json:"retry_count"`
+	RetryPolicy   *RetryPolicy   `json:"retry_policy,omitempty"`
+	Assertions    *Assertions    `json:"assertions,omitempty"`
+	Tags          []string       `json:"tags,omitempty"`
+	Project       string         `json:"project,omitempty"`
+	Region        string         `json:"region,omitempty"`
+	ArchivePolicy *ArchivePolicy `json:"archive_policy,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
-// ParserConfig represents configuration for parsing scraped content
+// ParserConfig represents configuration for parsing scraped content.
+// It is the single definition used by the API, the database and the parser;
+// legacy shapes are converted on decode (see parser_config.go).
 type ParserConfig struct {
-	Selectors map[string]string `json:"selectors"`       // CSS selectors for different content types
-	Rules     []ParseRule       `json:"rules,omitempty"` // Custom parsing rules
+	Version   int               `json:"version"`                                   // Schema version, see ParserConfigVersion
+	Template  string            `json:"template,omitempty"`                        // Name of a parser template to inherit selectors and rules from
+	Selectors map[string]string `json:"selectors" validate:"dive,selector"`        // CSS selectors for different content types
+	Rules     []ParseRule       `json:"rules,omitempty" validate:"dive"`           // Custom parsing rules
+	Options   *ParseOptions     `json:"options,omitempty"`                         // Content extraction and cleanup options
+	Script    *ScriptConfig     `json:"script,omitempty"`                          // User-defined extraction script run after selectors
+	Transform *TransformConfig  `json:"transform,omitempty"`                       // Webhook that post-processes the parsed record
+	Sensitive []string          `json:"sensitive,omitempty" validate:"dive,min=1"` // Data fields encrypted at rest, see encryption.Keyring
+}
+
+// ParseOptions controls what is extracted besides the selectors and how HTML is cleaned
+type ParseOptions struct {
+	ExtractMetadata bool `json:"extract_metadata,omitempty"`
+	ExtractLinks    bool `json:"extract_links,omitempty"`
+	ExtractImages   bool `json:"extract_images,omitempty"`
+	RemoveScripts   bool `json:"remove_scripts,omitempty"`
+	RemoveStyles    bool `json:"remove_styles,omitempty"`
+	CleanHTML       bool `json:"clean_html,omitempty"`
+	ExtractText     bool `json:"extract_text,omitempty"` // Store the page's normalized visible text, used for change detection and diffing
 }
 
 // ParseRule represents a custom parsing rule
 type ParseRule struct {
 	Name     string `json:"name"`
-	Selector string `json:"selector"`
+	Selector string `json:"selector" validate:"required,selector"`
 	Type     string `json:"type"`           // text, attr, html, etc.
 	Attr     string `json:"attr,omitempty"` // attribute name for attr type
 }
 
+// TransformConfig represents a post-processing webhook attached to a parser config.
+// After parsing, the parsed record is POSTed to URL and the response replaces it.
+type TransformConfig struct {
+	URL       string            `json:"url"`                  // Webhook endpoint (http or https)
+	Headers   map[string]string `json:"headers,omitempty"`    // Extra request headers, e.g. for authentication
+	TimeoutMs int               `json:"timeout_ms,omitempty"` // Request timeout in milliseconds
+	FailOpen  bool              `json:"fail_open,omitempty"`  // Keep the original record when the webhook fails
+}
+
+// ScriptConfig represents a sandboxed extraction script attached to a parser config.
+// The script must define `extract(doc)` returning a dict of extracted fields.
+type ScriptConfig struct {
+	Language       string `json:"language"`                   // Script language (currently only "starlark")
+	Source         string `json:"source"`                     // Script source code
+	MaxSteps       uint64 `json:"max_steps,omitempty"`        // Maximum interpreter steps (CPU limit)
+	TimeoutMs      int    `json:"timeout_ms,omitempty"`       // Wall-clock execution limit in milliseconds
+	MaxInputBytes  int    `json:"max_input_bytes,omitempty"`  // Maximum document size passed to the script
+	MaxOutputBytes int    `json:"max_output_bytes,omitempty"` // Maximum size of the JSON-encoded result
+	MaxAllocBytes  int    `json:"max_alloc_bytes,omitempty"`  // Maximum bytes of strings, lists and ints the script may allocate
+}
+
 // ScrapingTask represents a task to scrape a URL
 type ScrapingTask struct {
-	ID         uuid.UUID `json:"id"`
-	URLID      uuid.UUID `json:"url_id"`
-	URL        string    `json:"url"`
-	UserAgent  string    `json:"user_agent"`
-	Timeout    int       `json:"timeout"`
-	MaxRetries int       `json:"max_retries"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uuid.UUID   `json:"id"`
+	URLID      uuid.UUID   `json:"url_id"`
+	URL        string      `json:"url"`
+	UserAgent  string      `json:"user_agent"`
+	Timeout    int         `json:"timeout"`
+	MaxRetries int         `json:"max_retries"`
+	Retry      RetryPolicy `json:"retry"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// ScrapeResult reports the outcome of a scraping task. Scrapers publish it
+// as a KafkaMessage of type MessageTypeScrapeResult to the scraping results
+// topic, where the URL Manager records it and schedules any retry.
+type ScrapeResult struct {
+	TaskID       uuid.UUID `json:"task_id"`
+	URLID        uuid.UUID `json:"url_id"`
+	Attempt      int       `json:"attempt"`
+	Success      bool      `json:"success"`
+	StatusCode   int       `json:"status_code,omitempty"`    // HTTP status code, 0 if no response was received
+	ErrorCode    ErrorCode `json:"error_code,omitempty"`     // Failure class, see ClassifyFailure
+	Error        string    `json:"error,omitempty"`          // Error message of a failed attempt
+	RetryAfterMs int       `json:"retry_after_ms,omitempty"` // Retry-After sent by the server in milliseconds
+	DurationMs   int64     `json:"duration_ms,omitempty"`    // Time taken by the attempt in milliseconds, excluding ThrottledMs
+	ThrottledMs  int64     `json:"throttled_ms,omitempty"`   // Time spent waiting for the URL's and domain's rate limits in milliseconds
+	DNSMs        int64     `json:"dns_ms,omitempty"`         // Time spent resolving host names in milliseconds, see dnscache.Timing
+	CompletedAt  time.Time `json:"completed_at"`
+
+	// AssertionFailures describes the content assertions a fetched page
+	// failed. A successful result with failures is a soft failure.
+	AssertionFailures []string `json:"assertion_failures,omitempty"`
+
+	// Redirects followed to reach FinalURL, oldest first, see RedirectChain.
+	// Both are empty when the page was not redirected.
+	Redirects []Redirect `json:"redirects,omitempty"`
+	FinalURL  string     `json:"final_url,omitempty"`
+
+	// Headers are the response headers named in scraping.capture_headers,
+	// keyed by lowercase name, see CaptureHeaders
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// HAR records the attempt's requests and responses when the task asked
+	// for a capture (capture_har), see har.Recorder
+	HAR *har.HAR `json:"har,omitempty"`
+
+	// Cost attributes of the attempt, aggregated by the costs endpoint
+	ProxyEgressBytes int64 `json:"proxy_egress_bytes,omitempty"` // Bytes sent and received through the proxy
+	RenderMs         int64 `json:"render_ms,omitempty"`          // Time spent rendering in a headless browser in milliseconds
 }
 
 // ScrapedData represents raw scraped data
 type ScrapedData struct {
-	ID          uuid.UUID `json:"id"`
-	URLID       uuid.UUID `json:"url_id"`
-	URL         string    `json:"url"`
-	StatusCode  int       `json:"status_code"`
-	Content     string    `json:"content"`
-	ContentType string    `json:"content_type"`
-	Size        int64     `json:"size"`
-	Duration    float64   `json:"duration"` // in milliseconds
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uuid.UUID         `json:"id"`
+	URLID       uuid.UUID         `json:"url_id"`
+	URL         string            `json:"url"`
+	StatusCode  int               `json:"status_code"`
+	Content     string            `json:"content"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers,omitempty"` // Captured response headers, see CaptureHeaders
+	Size        int64             `json:"size"`
+	Duration    float64           `json:"duration"` // in milliseconds
+	CreatedAt   time.Time         `json:"created_at"`
 }
 
 // ParsedData represents parsed/structured data
@@ -73,7 +162,14 @@ type ParsedData struct {
 	Content   string                 `json:"content,omitempty"`
 	Metadata  map[string]string      `json:"metadata,omitempty"`
 	Data      map[string]interface{} `json:"data,omitempty"`
+	Text      string                 `json:"text,omitempty"` // Normalized visible text, see ParseOptions.ExtractText
 	CreatedAt time.Time              `json:"created_at"`
+
+	// FieldErrors tells, by field name, why selectors and rules extracted
+	// nothing, e.g. because a selector no longer matches the page. A record
+	// with field errors is a partial result; the fields that were extracted
+	// are kept.
+	FieldErrors map[string]string `json:"field_errors,omitempty"`
 }
 
 // KafkaMessage represents a generic Kafka message
@@ -95,13 +191,45 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-// Common status values
+// Scraping task statuses. A task is pending from when it is published until
+// the URL Manager stores its result; URL statuses and their transitions are
+// defined by the URLStatus constants.
+const (
+	TaskStatusPending = "pending" // Published, no result yet
+	TaskStatusSuccess = "success" // Scraped successfully
+	TaskStatusRetry   = "retry"   // Failed, the URL is scheduled for another attempt
+	TaskStatusFailed  = "failed"  // Failed without another attempt
+	// Fetched, but the content failed the URL's assertions; not retried
+	TaskStatusSoftFailed = "soft_failed"
+	// The site asked the scraper to slow down; rescheduled without counting a failure
+	TaskStatusThrottled = "throttled"
+)
+
+// Data purge job statuses: a job is pending until the URL Manager claims
+// it, running while it deletes, then completed or failed
+const (
+	PurgeStatusPending   = "pending"
+	PurgeStatusRunning   = "running"
+	PurgeStatusCompleted = "completed"
+	PurgeStatusFailed    = "failed"
+)
+
+// Data export job statuses: a job is pending until the URL Manager claims
+// it, running while it writes the file, then completed or failed. A
+// completed job is expired once its file is deleted.
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired"
+)
+
+// Kafka message types
 const (
-	StatusPending = "pending"
-	StatusActive  = "active"
-	StatusPaused  = "paused"
-	StatusFailed  = "failed"
-	StatusSuccess = "success"
+	MessageTypeScrapeResult = "scrape_result" // KafkaMessage whose data is a ScrapeResult
+	MessageTypeScrapingTask = "scraping_task" // ScrapingTaskMessage
+	MessageTypeURLEvent     = "url_event"     // URLEvent
 )
 
 // Common frequency values
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrorCode classifies why a scrape failed. It is stored on scraping tasks
+// and drives retry decisions and failure metrics.
+type ErrorCode string
+
+// Failure classes of the scrape error taxonomy
+const (
+	ErrorCodeDNS             ErrorCode = "dns_error"        // Host name could not be resolved
+	ErrorCodeTLS             ErrorCode = "tls_error"        // TLS handshake or certificate verification failed
+	ErrorCodeTimeout         ErrorCode = "timeout"          // Connection or response timed out
+	ErrorCodeConnection      ErrorCode = "connection_error" // Connection refused, reset or closed early
+	ErrorCodeClientError     ErrorCode = "http_4xx"         // Client error response, e.g. 404 Not Found
+	ErrorCodeServerError     ErrorCode = "http_5xx"         // Server error response
+	ErrorCodeRateLimited     ErrorCode = "rate_limited"     // 429 Too Many Requests
+	ErrorCodeBlocked         ErrorCode = "blocked"          // Access denied by the site or a bot protection
+	ErrorCodeParseError      ErrorCode = "parse_error"      // Response was fetched but could not be parsed
+	ErrorCodeRobotsDenied    ErrorCode = "robots_denied"    // Fetching is disallowed by robots.txt
+	ErrorCodeAssertionFailed ErrorCode = "assertion_failed" // Response was fetched but failed a content assertion
+	ErrorCodeUnknown         ErrorCode = "unknown"          // Failure that fits no other class
+)
+
+// Errors a scraper returns for failures that cannot be recognised from the
+// error type or HTTP status alone
+var (
+	ErrBlocked      = errors.New("request blocked")
+	ErrRobotsDenied = errors.New("disallowed by robots.txt")
+	ErrParseFailed  = errors.New("failed to parse response")
+	ErrRateLimited  = errors.New("rate limited") // E.g. the host is backed off after earlier 429 responses
+)
+
+// ErrorCodes returns every failure class, e.g. for metrics breakdowns
+func ErrorCodes() []ErrorCode {
+	return []ErrorCode{
+		ErrorCodeDNS,
+		ErrorCodeTLS,
+		ErrorCodeTimeout,
+		ErrorCodeConnection,
+		ErrorCodeClientError,
+		ErrorCodeServerError,
+		ErrorCodeRateLimited,
+		ErrorCodeBlocked,
+		ErrorCodeParseError,
+		ErrorCodeRobotsDenied,
+		ErrorCodeAssertionFailed,
+		ErrorCodeUnknown,
+	}
+}
+
+// Valid reports whether c is a known failure class
+func (c ErrorCode) Valid() bool {
+	for _, code := range ErrorCodes() {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Retryable reports whether failures of this class are worth retrying.
+// Network failures and server errors are usually transient; client errors,
+// blocks, robots.txt denials, certificate problems and parse errors recur on
+// every attempt.
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorCodeDNS, ErrorCodeTimeout, ErrorCodeConnection,
+		ErrorCodeServerError, ErrorCodeRateLimited, ErrorCodeUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// FromStatus reports whether the class is derived from the HTTP status code.
+// Retries for these classes follow the policy's retry_on_status list.
+func (c ErrorCode) FromStatus() bool {
+	switch c {
+	case ErrorCodeClientError, ErrorCodeServerError, ErrorCodeRateLimited, ErrorCodeBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClassifyFailure maps a scrape error and HTTP status code to a failure
+// class. statusCode is 0 when no response was received. It returns an empty
+// code when neither indicates a failure.
+func ClassifyFailure(err error, statusCode int) ErrorCode {
+	switch {
+	case errors.Is(err, ErrRobotsDenied):
+		return ErrorCodeRobotsDenied
+	case errors.Is(err, ErrBlocked):
+		return ErrorCodeBlocked
+	case errors.Is(err, ErrParseFailed):
+		return ErrorCodeParseError
+	case errors.Is(err, ErrRateLimited):
+		return ErrorCodeRateLimited
+	}
+
+	if code := classifyStatus(statusCode); code != "" {
+		return code
+	}
+	if err == nil {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ErrorCodeTimeout
+		}
+		return ErrorCodeDNS
+	}
+	if isTLSError(err) {
+		return ErrorCodeTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorCodeTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorCodeConnection
+	}
+
+	return ErrorCodeUnknown
+}
+
+// classifyStatus maps an HTTP error status to a failure class
+func classifyStatus(statusCode int) ErrorCode {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case statusCode == http.StatusForbidden, statusCode == http.StatusUnavailableForLegalReasons:
+		return ErrorCodeBlocked
+	case statusCode >= 400 && statusCode < 500:
+		return ErrorCodeClientError
+	case statusCode >= 500 && statusCode < 600:
+		return ErrorCodeServerError
+	default:
+		return ""
+	}
+}
+
+// isTLSError reports whether err comes from the TLS handshake or certificate verification
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		headerErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &headerErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
+
+// ScrapeFailure describes a failed scrape attempt
+type ScrapeFailure struct {
+	Code       ErrorCode     // Failure class
+	StatusCode int           // HTTP status code, 0 if no response was received
+	RetryAfter time.Duration // Delay requested by the server, e.g. from Retry-After
+}
+
+// Throttled reports whether the server asked the scraper to slow down: a
+// rate limited attempt, or a 503 Service Unavailable with a Retry-After
+// delay. Throttled attempts are rescheduled after ThrottleDelay rather than
+// counted against the retry policy.
+func (f ScrapeFailure) Throttled() bool {
+	return f.Code == ErrorCodeRateLimited || (f.StatusCode == http.StatusServiceUnavailable && f.RetryAfter > 0)
+}
+
+// ThrottleDelay returns how long to wait before scraping a URL again after a
+// throttled attempt: one backoff step more than a regular retry and at least
+// retryAfter, capped at MaxRetryBackoffCap
+func (p RetryPolicy) ThrottleDelay(attempts int, retryAfter time.Duration) time.Duration {
+	delay := p.Backoff(attempts + 1)
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if delay > MaxRetryBackoffCap {
+		delay = MaxRetryBackoffCap
+	}
+	return delay
+}
+
+// NextRetry decides whether a failure after the given number of attempts is
+// retried and how long to wait first. Failures classified from the HTTP
+// status are retried when the status is in RetryOnStatus, so a policy can opt
+// in to retrying e.g. 404s; other classes follow ErrorCode.Retryable. Rate
+// limited attempts wait one extra backoff step and at least RetryAfter.
+func (p RetryPolicy) NextRetry(attempts int, failure ScrapeFailure) (time.Duration, bool) {
+	if attempts >= p.MaxAttempts {
+		return 0, false
+	}
+
+	retry := failure.Code.Retryable()
+	if failure.Code.FromStatus() && failure.StatusCode != 0 {
+		retry = p.ShouldRetry(attempts, failure.StatusCode)
+	}
+	if !retry {
+		return 0, false
+	}
+
+	if failure.Code != ErrorCodeRateLimited {
+		return p.Backoff(attempts), true
+	}
+	return p.ThrottleDelay(attempts, failure.RetryAfter), true
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		want       ErrorCode
+	}{
+		{name: "success", statusCode: 200, want: ""},
+		{name: "dns", err: &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, want: ErrorCodeDNS},
+		{name: "dns timeout", err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, want: ErrorCodeTimeout},
+		{name: "tls", err: fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), want: ErrorCodeTLS},
+		{name: "deadline", err: fmt.Errorf("get: %w", context.DeadlineExceeded), want: ErrorCodeTimeout},
+		{name: "refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: ErrorCodeConnection},
+		{name: "not found", statusCode: 404, want: ErrorCodeClientError},
+		{name: "forbidden", statusCode: 403, want: ErrorCodeBlocked},
+		{name: "rate limited", statusCode: 429, want: ErrorCodeRateLimited},
+		{name: "server error", statusCode: 502, want: ErrorCodeServerError},
+		{name: "robots", err: fmt.Errorf("fetch: %w", ErrRobotsDenied), want: ErrorCodeRobotsDenied},
+		{name: "blocked challenge page", err: ErrBlocked, statusCode: 200, want: ErrorCodeBlocked},
+		{name: "parse", err: ErrParseFailed, statusCode: 200, want: ErrorCodeParseError},
+		{name: "host backed off", err: fmt.Errorf("wait: %w", ErrRateLimited), want: ErrorCodeRateLimited},
+		{name: "other", err: errors.New("boom"), want: ErrorCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFailure(tt.err, tt.statusCode); got != tt.want {
+				t.Errorf("ClassifyFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyNextRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BackoffBaseMs: 1000, BackoffCapMs: 60000}.WithDefaults()
+
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		attempts  int
+		failure   ScrapeFailure
+		wantRetry bool
+		wantDelay time.Duration
+	}{
+		{name: "timeout", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeTimeout}, wantRetry: true, wantDelay: time.Second},
+		{name: "server error", policy: policy, attempts: 2, failure: ScrapeFailure{Code: ErrorCodeServerError, StatusCode: 503}, wantRetry: true, wantDelay: 2 * time.Second},
+		{name: "not found", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeClientError, StatusCode: 404}},
+		{name: "tls", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeTLS}},
+		{name: "robots", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeRobotsDenied}},
+		{name: "exhausted", policy: policy, attempts: 3, failure: ScrapeFailure{Code: ErrorCodeTimeout}},
+		{name: "rate limited backs off further", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeRateLimited, StatusCode: 429}, wantRetry: true, wantDelay: 2 * time.Second},
+		{name: "rate limited honours retry-after", policy: policy, attempts: 1, failure: ScrapeFailure{Code: ErrorCodeRateLimited, StatusCode: 429, RetryAfter: time.Minute}, wantRetry: true, wantDelay: time.Minute},
+		{
+			name:      "policy opts in to 404",
+			policy:    RetryPolicy{MaxAttempts: 3, RetryOnStatus: []int{404}}.WithDefaults(),
+			attempts:  1,
+			failure:   ScrapeFailure{Code: ErrorCodeClientError, StatusCode: 404},
+			wantRetry: true,
+			wantDelay: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := tt.policy.NextRetry(tt.attempts, tt.failure)
+			if retry != tt.wantRetry || delay != tt.wantDelay {
+				t.Errorf("NextRetry() = (%s, %v), want (%s, %v)", delay, retry, tt.wantDelay, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestScrapeFailureThrottled(t *testing.T) {
+	tests := []struct {
+		failure ScrapeFailure
+		want    bool
+	}{
+		{ScrapeFailure{Code: ErrorCodeRateLimited, StatusCode: 429}, true},
+		{ScrapeFailure{Code: ErrorCodeRateLimited}, true},
+		{ScrapeFailure{Code: ErrorCodeServerError, StatusCode: 503, RetryAfter: time.Minute}, true},
+		{ScrapeFailure{Code: ErrorCodeServerError, StatusCode: 503}, false},
+		{ScrapeFailure{Code: ErrorCodeServerError, StatusCode: 500, RetryAfter: time.Minute}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.failure.Throttled(); got != tt.want {
+			t.Errorf("%+v.Throttled() = %v, want %v", tt.failure, got, tt.want)
+		}
+	}
+
+	policy := RetryPolicy{BackoffBaseMs: 1000, BackoffCapMs: 60000}
+	if got := policy.ThrottleDelay(1, 0); got != 2*time.Second {
+		t.Errorf("ThrottleDelay() without Retry-After = %v, want one extra backoff step", got)
+	}
+	if got := policy.ThrottleDelay(1, 24*time.Hour); got != MaxRetryBackoffCap {
+		t.Errorf("ThrottleDelay() = %v, want the cap %v", got, MaxRetryBackoffCap)
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultCaptureHeaders are the response headers kept with each scrape when
+// scraping.capture_headers is not set
+var DefaultCaptureHeaders = []string{
+	"cache-control",
+	"content-type",
+	"etag",
+	"last-modified",
+	"server",
+	"x-robots-tag",
+}
+
+// CaptureHeaders returns the response headers named in names, keyed by
+// their lowercase name. Repeated headers are joined with ", " and headers
+// missing from the response are left out.
+func CaptureHeaders(header http.Header, names []string) map[string]string {
+	captured := make(map[string]string)
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			captured[strings.ToLower(name)] = strings.Join(values, ", ")
+		}
+	}
+	return captured
+}
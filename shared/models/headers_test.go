@@ -0,0 +1,21 @@
+package models
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCaptureHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Server", "nginx")
+	header.Add("Cache-Control", "no-cache")
+	header.Add("Cache-Control", "max-age=0")
+	header.Set("Set-Cookie", "session=secret")
+
+	got := CaptureHeaders(header, []string{"server", "Cache-Control", "x-robots-tag"})
+	want := map[string]string{"server": "nginx", "cache-control": "no-cache, max-age=0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CaptureHeaders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxURLTags is the maximum number of tags a URL can carry
+const MaxURLTags = 20
+
+var (
+	// tagPattern restricts URL tags to short lowercase labels
+	tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+
+	// projectPattern restricts project names to URL-safe identifiers
+	projectPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+	// regionPattern restricts region names to identifiers usable in topic names
+	regionPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+)
+
+// ValidTag reports whether tag is a valid, already normalized URL tag
+func ValidTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// ValidProject reports whether name is a valid project name. The empty
+// name is valid and means the URL belongs to no project.
+func ValidProject(name string) bool {
+	return name == "" || projectPattern.MatchString(name)
+}
+
+// ValidRegion reports whether name is a valid region name, e.g. "eu-west".
+// The empty name is valid and means any region.
+func ValidRegion(name string) bool {
+	return name == "" || regionPattern.MatchString(name)
+}
+
+// NormalizeTags lowercases and de-duplicates tags, keeping their order, and
+// checks them against the tag format. It never returns a nil slice, since
+// the tags column does not accept NULL.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) > MaxURLTags {
+		return nil, fmt.Errorf("a URL cannot have more than %d tags", MaxURLTags)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !ValidTag(tag) {
+			return nil, fmt.Errorf("invalid tag: %q", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
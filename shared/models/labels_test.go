@@ -0,0 +1,63 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "none", tags: nil, want: []string{}},
+		{name: "lowercased and deduplicated", tags: []string{"News", " news ", "team:growth"}, want: []string{"news", "team:growth"}},
+		{name: "invalid character", tags: []string{"bad tag"}, wantErr: true},
+		{name: "empty", tags: []string{""}, wantErr: true},
+		{name: "too many", tags: make([]string, MaxURLTags+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidProject(t *testing.T) {
+	for name, want := range map[string]bool{
+		"":          true,
+		"news":      true,
+		"shop-eu.2": true,
+		"News":      false,
+		"-news":     false,
+		"a b":       false,
+	} {
+		if got := ValidProject(name); got != want {
+			t.Errorf("ValidProject(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestValidRegion(t *testing.T) {
+	for name, want := range map[string]bool{
+		"":        true,
+		"eu-west": true,
+		"us2":     true,
+		"EU":      false,
+		"eu.west": false,
+		"-eu":     false,
+	} {
+		if got := ValidRegion(name); got != want {
+			t.Errorf("ValidRegion(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
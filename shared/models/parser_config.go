@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Parser config schema versions
+const (
+	// ParserConfigVersionLegacy is the flat API Gateway shape with per-field
+	// selectors (title_selector, content_selector, ...) and top-level flags
+	ParserConfigVersionLegacy = 1
+
+	// ParserConfigVersion is the current schema: a selectors map, rules and options
+	ParserConfigVersion = 2
+)
+
+// legacySelectorFields maps the legacy per-field selectors to canonical selector names.
+// The names match those used by the built-in parser templates.
+var legacySelectorFields = []struct {
+	name string
+	get  func(*legacyParserConfig) string
+}{
+	{"title", func(l *legacyParserConfig) string { return l.TitleSelector }},
+	{"content", func(l *legacyParserConfig) string { return l.ContentSelector }},
+	{"author", func(l *legacyParserConfig) string { return l.AuthorSelector }},
+	{"published", func(l *legacyParserConfig) string { return l.DateSelector }},
+	{"image", func(l *legacyParserConfig) string { return l.ImageSelector }},
+	{"price", func(l *legacyParserConfig) string { return l.PriceSelector }},
+}
+
+// legacyParserConfig holds the fields of the version 1 shape
+type legacyParserConfig struct {
+	TitleSelector   string            `json:"title_selector,omitempty"`
+	ContentSelector string            `json:"content_selector,omitempty"`
+	AuthorSelector  string            `json:"author_selector,omitempty"`
+	DateSelector    string            `json:"date_selector,omitempty"`
+	ImageSelector   string            `json:"image_selector,omitempty"`
+	PriceSelector   string            `json:"price_selector,omitempty"`
+	CustomSelectors map[string]string `json:"custom_selectors,omitempty"`
+	ExtractMetadata bool              `json:"extract_metadata,omitempty"`
+	ExtractLinks    bool              `json:"extract_links,omitempty"`
+	ExtractImages   bool              `json:"extract_images,omitempty"`
+	RemoveScripts   bool              `json:"remove_scripts,omitempty"`
+	RemoveStyles    bool              `json:"remove_styles,omitempty"`
+	CleanHTML       bool              `json:"clean_html,omitempty"`
+}
+
+// UnmarshalJSON decodes a parser config of any supported version and
+// upgrades it to the current schema, so stored configs written by older
+// API versions are understood everywhere.
+func (c *ParserConfig) UnmarshalJSON(data []byte) error {
+	type parserConfig ParserConfig // avoids recursing into UnmarshalJSON
+	var raw struct {
+		parserConfig
+		legacyParserConfig
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.Version > ParserConfigVersion {
+		return fmt.Errorf("unsupported parser config version %d (latest is %d)", raw.Version, ParserConfigVersion)
+	}
+
+	*c = ParserConfig(raw.parserConfig)
+	c.upgradeLegacy(&raw.legacyParserConfig)
+	c.Version = ParserConfigVersion
+	return nil
+}
+
+// upgradeLegacy folds version 1 fields into the canonical shape.
+// Selectors already present in the canonical map take precedence.
+func (c *ParserConfig) upgradeLegacy(legacy *legacyParserConfig) {
+	for _, field := range legacySelectorFields {
+		if selector := field.get(legacy); selector != "" {
+			c.setSelectorIfMissing(field.name, selector)
+		}
+	}
+	for name, selector := range legacy.CustomSelectors {
+		c.setSelectorIfMissing(name, selector)
+	}
+
+	options := ParseOptions{
+		ExtractMetadata: legacy.ExtractMetadata,
+		ExtractLinks:    legacy.ExtractLinks,
+		ExtractImages:   legacy.ExtractImages,
+		RemoveScripts:   legacy.RemoveScripts,
+		RemoveStyles:    legacy.RemoveStyles,
+		CleanHTML:       legacy.CleanHTML,
+	}
+	if c.Options == nil && options != (ParseOptions{}) {
+		c.Options = &options
+	}
+}
+
+func (c *ParserConfig) setSelectorIfMissing(name, selector string) {
+	if c.Selectors == nil {
+		c.Selectors = make(map[string]string)
+	}
+	if _, exists := c.Selectors[name]; !exists {
+		c.Selectors[name] = selector
+	}
+}
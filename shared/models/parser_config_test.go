@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParserConfigUpgradesLegacyShape(t *testing.T) {
+	legacy := `{
+		"template": "generic-article",
+		"title_selector": "h1.headline",
+		"date_selector": "time.published",
+		"custom_selectors": {"tags": ".tag"},
+		"remove_scripts": true
+	}`
+
+	var cfg ParserConfig
+	if err := json.Unmarshal([]byte(legacy), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if cfg.Version != ParserConfigVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, ParserConfigVersion)
+	}
+	if cfg.Template != "generic-article" {
+		t.Errorf("Template = %q, want generic-article", cfg.Template)
+	}
+	want := map[string]string{"title": "h1.headline", "published": "time.published", "tags": ".tag"}
+	for name, selector := range want {
+		if cfg.Selectors[name] != selector {
+			t.Errorf("Selectors[%q] = %q, want %q", name, cfg.Selectors[name], selector)
+		}
+	}
+	if cfg.Options == nil || !cfg.Options.RemoveScripts {
+		t.Errorf("Options = %+v, want remove_scripts carried over", cfg.Options)
+	}
+}
+
+func TestParserConfigCanonicalSelectorsWin(t *testing.T) {
+	var cfg ParserConfig
+	data := `{"selectors": {"title": ".canonical"}, "title_selector": ".legacy"}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if cfg.Selectors["title"] != ".canonical" {
+		t.Errorf("Selectors[title] = %q, want canonical selector", cfg.Selectors["title"])
+	}
+	if cfg.Options != nil {
+		t.Errorf("Options = %+v, want nil when no flags are set", cfg.Options)
+	}
+}
+
+func TestParserConfigRoundTrip(t *testing.T) {
+	var cfg ParserConfig
+	if err := json.Unmarshal([]byte(`{"title_selector": "h1"}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded ParserConfig
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() of encoded config error = %v", err)
+	}
+	if decoded.Selectors["title"] != "h1" || decoded.Version != ParserConfigVersion {
+		t.Errorf("round trip lost data: %s", encoded)
+	}
+}
+
+func TestParserConfigRejectsFutureVersion(t *testing.T) {
+	var cfg ParserConfig
+	if err := json.Unmarshal([]byte(`{"version": 99, "selectors": {}}`), &cfg); err == nil {
+		t.Fatal("expected an error for an unknown future version")
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import "net/http"
+
+// Redirect is a redirect a scrape followed on its way to the final URL
+type Redirect struct {
+	URL        string `json:"url"`         // URL that answered with the redirect
+	StatusCode int    `json:"status_code"` // 301, 302, 303, 307 or 308
+}
+
+// Permanent reports whether the redirect is permanent (301 or 308)
+func (r Redirect) Permanent() bool {
+	return r.StatusCode == http.StatusMovedPermanently || r.StatusCode == http.StatusPermanentRedirect
+}
+
+// RedirectChain returns the redirects the client followed to get resp,
+// oldest first, and the final URL, from the responses net/http keeps on
+// each redirected request. The chain is empty when resp was not redirected.
+func RedirectChain(resp *http.Response) ([]Redirect, string) {
+	if resp == nil || resp.Request == nil {
+		return nil, ""
+	}
+	var chain []Redirect
+	for prev := resp.Request.Response; prev != nil && prev.Request != nil; prev = prev.Request.Response {
+		chain = append([]Redirect{{URL: prev.Request.URL.String(), StatusCode: prev.StatusCode}}, chain...)
+	}
+	return chain, resp.Request.URL.String()
+}
+
+// MovedTo returns the final URL of a scrape that was only redirected
+// permanently, i.e. the location the scraped URL has moved to. It returns
+// false when the scrape was not redirected, or followed a temporary
+// redirect on the way.
+func (r ScrapeResult) MovedTo() (string, bool) {
+	if len(r.Redirects) == 0 || r.FinalURL == "" {
+		return "", false
+	}
+	for _, redirect := range r.Redirects {
+		if !redirect.Permanent() {
+			return "", false
+		}
+	}
+	if r.FinalURL == r.Redirects[0].URL {
+		return "", false
+	}
+	return r.FinalURL, true
+}
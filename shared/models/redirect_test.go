@@ -0,0 +1,57 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/moved", http.StatusMovedPermanently))
+	mux.Handle("/moved", http.RedirectHandler("/new", http.StatusFound))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/old")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	chain, final := RedirectChain(resp)
+	want := []Redirect{{URL: server.URL + "/old", StatusCode: 301}, {URL: server.URL + "/moved", StatusCode: 302}}
+	if !reflect.DeepEqual(chain, want) || final != server.URL+"/new" {
+		t.Errorf("RedirectChain() = %v, %q; want %v, %q", chain, final, want, server.URL+"/new")
+	}
+}
+
+func TestScrapeResultMovedTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ScrapeResult
+		want   string
+	}{
+		{"not redirected", ScrapeResult{FinalURL: "https://example.com/a"}, ""},
+		{"permanent", ScrapeResult{
+			Redirects: []Redirect{{URL: "http://example.com/a", StatusCode: 301}, {URL: "https://example.com/a", StatusCode: 308}},
+			FinalURL:  "https://example.com/b",
+		}, "https://example.com/b"},
+		{"temporary on the way", ScrapeResult{
+			Redirects: []Redirect{{URL: "https://example.com/a", StatusCode: 301}, {URL: "https://example.com/b", StatusCode: 302}},
+			FinalURL:  "https://example.com/c",
+		}, ""},
+		{"back to itself", ScrapeResult{
+			Redirects: []Redirect{{URL: "https://example.com/a", StatusCode: 301}},
+			FinalURL:  "https://example.com/a",
+		}, ""},
+	}
+	for _, tt := range tests {
+		got, ok := tt.result.MovedTo()
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s: MovedTo() = %q, %v; want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
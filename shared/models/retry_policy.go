@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retry policy defaults and limits
+const (
+	DefaultRetryBackoffBase = 1 * time.Second
+	DefaultRetryBackoffCap  = 5 * time.Minute
+	MaxRetryAttempts        = 20
+	MaxRetryBackoffCap      = 24 * time.Hour
+)
+
+// DefaultRetryOnStatus lists the HTTP status codes retried when a policy does
+// not specify its own: timeouts, rate limiting and transient server errors
+var DefaultRetryOnStatus = []int{408, 425, 429, 500, 502, 503, 504}
+
+// RetryPolicy controls how a failed scrape of a URL is retried. Attempts are
+// spaced by exponential backoff starting at BackoffBaseMs and capped at
+// BackoffCapMs. HTTP error responses are retried when their status is in
+// RetryOnStatus; other failures are retried according to their ErrorCode
+// (see NextRetry).
+type RetryPolicy struct {
+	MaxAttempts   int   `json:"max_attempts"`              // Total attempts, including the first
+	BackoffBaseMs int   `json:"backoff_base_ms,omitempty"` // Delay before the first retry in milliseconds
+	BackoffCapMs  int   `json:"backoff_cap_ms,omitempty"`  // Maximum delay between attempts in milliseconds
+	RetryOnStatus []int `json:"retry_on_status,omitempty"` // HTTP status codes that are retried
+}
+
+// DefaultRetryPolicy returns the policy used for URLs without their own,
+// based on the URL's legacy max_retries setting
+func DefaultRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: maxRetries + 1}.WithDefaults()
+}
+
+// EffectiveRetryPolicy returns the URL's policy with defaults applied, or the
+// default policy when the URL has none
+func EffectiveRetryPolicy(policy *RetryPolicy, maxRetries int) RetryPolicy {
+	if policy == nil {
+		return DefaultRetryPolicy(maxRetries)
+	}
+	return policy.WithDefaults()
+}
+
+// WithDefaults returns a copy of the policy with unset fields filled in
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	if p.BackoffBaseMs <= 0 {
+		p.BackoffBaseMs = int(DefaultRetryBackoffBase / time.Millisecond)
+	}
+	if p.BackoffCapMs <= 0 {
+		p.BackoffCapMs = int(DefaultRetryBackoffCap / time.Millisecond)
+	}
+	if p.BackoffCapMs < p.BackoffBaseMs {
+		p.BackoffCapMs = p.BackoffBaseMs
+	}
+	if len(p.RetryOnStatus) == 0 {
+		p.RetryOnStatus = append([]int(nil), DefaultRetryOnStatus...)
+	}
+	return p
+}
+
+// Validate checks that the policy is within the supported limits
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > MaxRetryAttempts {
+		return fmt.Errorf("max_attempts must be between 1 and %d", MaxRetryAttempts)
+	}
+	if p.BackoffBaseMs < 0 || p.BackoffCapMs < 0 {
+		return fmt.Errorf("backoff_base_ms and backoff_cap_ms must be non-negative")
+	}
+	if p.BackoffCapMs > 0 && p.BackoffCapMs < p.BackoffBaseMs {
+		return fmt.Errorf("backoff_cap_ms must not be less than backoff_base_ms")
+	}
+	if time.Duration(p.BackoffCapMs)*time.Millisecond > MaxRetryBackoffCap ||
+		time.Duration(p.BackoffBaseMs)*time.Millisecond > MaxRetryBackoffCap {
+		return fmt.Errorf("backoff cannot exceed %s", MaxRetryBackoffCap)
+	}
+	for _, status := range p.RetryOnStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("retry_on_status contains invalid HTTP status %d", status)
+		}
+	}
+	return nil
+}
+
+// ShouldRetry reports whether another attempt should be made after the given
+// number of attempts failed with statusCode. A statusCode of 0 means the
+// request failed without a response.
+func (p RetryPolicy) ShouldRetry(attempts, statusCode int) bool {
+	if attempts >= p.MaxAttempts {
+		return false
+	}
+	if statusCode == 0 {
+		return true
+	}
+	for _, status := range p.RetryOnStatus {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the next attempt after the given number
+// of failed attempts: BackoffBaseMs doubled for each prior retry, capped at
+// BackoffCapMs
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	base := time.Duration(p.BackoffBaseMs) * time.Millisecond
+	limit := time.Duration(p.BackoffCapMs) * time.Millisecond
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BackoffBaseMs: 500, BackoffCapMs: 3000}.WithDefaults()
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 500 * time.Millisecond},
+		{attempts: 1, want: 500 * time.Millisecond},
+		{attempts: 2, want: time.Second},
+		{attempts: 3, want: 2 * time.Second},
+		{attempts: 4, want: 3 * time.Second},
+		{attempts: 50, want: 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, RetryOnStatus: []int{429, 503}}.WithDefaults()
+
+	tests := []struct {
+		name       string
+		attempts   int
+		statusCode int
+		want       bool
+	}{
+		{name: "listed status", attempts: 1, statusCode: 503, want: true},
+		{name: "unlisted status", attempts: 1, statusCode: 500, want: false},
+		{name: "no response", attempts: 2, statusCode: 0, want: true},
+		{name: "attempts exhausted", attempts: 3, statusCode: 429, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.ShouldRetry(tt.attempts, tt.statusCode); got != tt.want {
+				t.Errorf("ShouldRetry(%d, %d) = %v, want %v", tt.attempts, tt.statusCode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveRetryPolicy(t *testing.T) {
+	legacy := EffectiveRetryPolicy(nil, 3)
+	if legacy.MaxAttempts != 4 || legacy.BackoffBaseMs != 1000 || len(legacy.RetryOnStatus) != len(DefaultRetryOnStatus) {
+		t.Errorf("EffectiveRetryPolicy(nil, 3) = %+v", legacy)
+	}
+
+	custom := EffectiveRetryPolicy(&RetryPolicy{MaxAttempts: 6, BackoffCapMs: 60000}, 3)
+	if custom.MaxAttempts != 6 || custom.BackoffCapMs != 60000 || custom.BackoffBaseMs != 1000 {
+		t.Errorf("EffectiveRetryPolicy(custom, 3) = %+v", custom)
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		wantErr bool
+	}{
+		{name: "valid", policy: RetryPolicy{MaxAttempts: 5, BackoffBaseMs: 1000, BackoffCapMs: 60000, RetryOnStatus: []int{429}}},
+		{name: "zero attempts", policy: RetryPolicy{}, wantErr: true},
+		{name: "too many attempts", policy: RetryPolicy{MaxAttempts: 21}, wantErr: true},
+		{name: "cap below base", policy: RetryPolicy{MaxAttempts: 2, BackoffBaseMs: 5000, BackoffCapMs: 1000}, wantErr: true},
+		{name: "cap too large", policy: RetryPolicy{MaxAttempts: 2, BackoffCapMs: 25 * 3600 * 1000}, wantErr: true},
+		{name: "invalid status", policy: RetryPolicy{MaxAttempts: 2, RetryOnStatus: []int{42}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScrapingTaskMessage represents a Kafka message for scraping tasks, which
+// the URL Manager publishes to the scraping tasks topic. It carries the
+// URL's effective retry policy so the scraper and retry handling follow
+// per-URL settings, the URL's content assertions for the scraper to evaluate,
+// and the region the URL must be fetched from. The region is set even when
+// the task was rerouted to a fallback region or the shared topic, so the
+// scraper can still pick a matching proxy. RateLimit is the URL's limit in
+// requests per minute, which the scraper enforces with worker.Throttle along
+// with the domain limit. ArchivePolicy selects the scrapes whose raw HTML
+// the scraper keeps, see ArchivePolicy. CaptureHAR asks the scraper to
+// record the scrape with har.Recorder and send the HAR with its result,
+// because a HAR capture of the URL was requested. Project is the URL's
+// project, which scrapers submit the task for with worker.Pool.SubmitFor so
+// projects share the workers fairly.
+type ScrapingTaskMessage struct {
+	TaskID        uuid.UUID      `json:"task_id"`
+	URLID         uuid.UUID      `json:"url_id"`
+	URL           string         `json:"url"`
+	Attempt       int            `json:"attempt"`
+	RetryPolicy   RetryPolicy    `json:"retry_policy"`
+	Assertions    *Assertions    `json:"assertions,omitempty"`
+	Region        string         `json:"region,omitempty"`
+	RateLimit     int            `json:"rate_limit,omitempty"`
+	ArchivePolicy *ArchivePolicy `json:"archive_policy,omitempty"`
+	CaptureHAR    bool           `json:"capture_har,omitempty"`
+	Project       string         `json:"project,omitempty"`
+	CorrelationID string         `json:"correlation_id"`
+	Timestamp     time.Time      `json:"timestamp"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Kinds of service level objectives, see config.SLOConfig
+const (
+	SLOKindSchedulingLatency = "scheduling_latency" // Scrapes completed within a delay of their due time
+	SLOKindSuccess           = "success"            // Completed scrapes that succeeded
+)
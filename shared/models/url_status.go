@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+)
+
+// URL lifecycle statuses. The scheduler picks up pending, retry and degraded
+// URLs; failed and paused URLs wait for an operator.
+const (
+	URLStatusPending  = "pending"  // Waiting for its next scheduled scrape
+	URLStatusRetry    = "retry"    // Last attempt failed, a retry is scheduled
+	URLStatusFailed   = "failed"   // Retries exhausted or the failure is not retryable
+	URLStatusDegraded = "degraded" // Flagged by the watchdog, still scheduled until a scrape succeeds
+	URLStatusPaused   = "paused"   // Paused by an operator, not scheduled
+)
+
+// urlTransitions lists the statuses each URL status may move to. Setting a
+// URL to the status it already has is always allowed and changes nothing.
+// A failed URL only moves to retry when a scrape triggered by an operator
+// fails again; paused URLs are left alone until they are resumed.
+var urlTransitions = map[string][]string{
+	URLStatusPending:  {URLStatusRetry, URLStatusFailed, URLStatusDegraded, URLStatusPaused},
+	URLStatusRetry:    {URLStatusPending, URLStatusFailed, URLStatusDegraded, URLStatusPaused},
+	URLStatusFailed:   {URLStatusPending, URLStatusRetry, URLStatusDegraded, URLStatusPaused},
+	URLStatusDegraded: {URLStatusPending, URLStatusFailed, URLStatusPaused},
+	URLStatusPaused:   {URLStatusPending},
+}
+
+// InvalidTransitionError is returned when a URL cannot move from its current
+// status to the requested one
+type InvalidTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("invalid URL status transition from %q to %q", e.From, e.To)
+}
+
+// Is makes invalid transitions domain.ErrConflict errors
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == domain.ErrConflict
+}
+
+// URLStatusChange describes a URL moving from one status to another
+type URLStatusChange struct {
+	URLID     uuid.UUID `json:"url_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ValidURLStatus reports whether status is a URL lifecycle status
+func ValidURLStatus(status string) bool {
+	_, ok := urlTransitions[status]
+	return ok
+}
+
+// ValidateURLTransition returns an *InvalidTransitionError unless a URL in
+// status from may move to status to
+func ValidateURLTransition(from, to string) error {
+	if ValidURLStatus(to) {
+		if from == to {
+			return nil
+		}
+		for _, next := range urlTransitions[from] {
+			if next == to {
+				return nil
+			}
+		}
+	}
+	return &InvalidTransitionError{From: from, To: to}
+}
+
+// URLStatusesBefore returns the statuses a URL may be in to move to status,
+// including status itself. It is empty for an unknown status.
+func URLStatusesBefore(status string) []string {
+	if !ValidURLStatus(status) {
+		return nil
+	}
+	statuses := []string{status}
+	for _, from := range []string{URLStatusPending, URLStatusRetry, URLStatusFailed, URLStatusDegraded, URLStatusPaused} {
+		if from != status && ValidateURLTransition(from, status) == nil {
+			statuses = append(statuses, from)
+		}
+	}
+	return statuses
+}
+
+// URL event types published to the URL events topic
+const (
+	URLEventCreated       = "url.created"
+	URLEventUpdated       = "url.updated"
+	URLEventDeleted       = "url.deleted"
+	URLEventRestored      = "url.restored"
+	URLEventPaused        = "url.paused"
+	URLEventStatusChanged = "url.status_changed" // Any status change other than pausing
+)
+
+// URLEvent tells downstream systems that a URL was created, changed or
+// deleted. Events are keyed by URL ID, so the events of one URL are consumed
+// in order.
+type URLEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	URLID      uuid.UUID `json:"url_id"`
+	URL        string    `json:"url,omitempty"`
+	FromStatus string    `json:"from_status,omitempty"` // Status before a status change
+	Status     string    `json:"status,omitempty"`      // Status after a status change
+	Source     string    `json:"source"`                // Service that made the change
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewURLEvent creates an event of the given type for a URL
+func NewURLEvent(eventType string, urlID uuid.UUID, address string) URLEvent {
+	return URLEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		URLID:      urlID,
+		URL:        address,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// NewURLStatusEvent creates the event for a status change: url.paused when
+// the URL was paused, url.status_changed otherwise
+func NewURLStatusEvent(change URLStatusChange) URLEvent {
+	eventType := URLEventStatusChanged
+	if change.To == URLStatusPaused {
+		eventType = URLEventPaused
+	}
+	event := NewURLEvent(eventType, change.URLID, "")
+	event.FromStatus = change.From
+	event.Status = change.To
+	if !change.ChangedAt.IsZero() {
+		event.OccurredAt = change.ChangedAt
+	}
+	return event
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateURLTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{from: URLStatusPending, to: URLStatusRetry},
+		{from: URLStatusRetry, to: URLStatusFailed},
+		{from: URLStatusFailed, to: URLStatusPending},
+		{from: URLStatusFailed, to: URLStatusDegraded},
+		{from: URLStatusDegraded, to: URLStatusPending},
+		{from: URLStatusPaused, to: URLStatusPending},
+		{from: URLStatusPaused, to: URLStatusPaused},
+		{from: URLStatusFailed, to: URLStatusRetry},
+		{from: URLStatusDegraded, to: URLStatusRetry, wantErr: true},
+		{from: URLStatusPaused, to: URLStatusRetry, wantErr: true},
+		{from: URLStatusPaused, to: URLStatusFailed, wantErr: true},
+		{from: URLStatusPending, to: "completed", wantErr: true},
+		{from: "active", to: URLStatusPending, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateURLTransition(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateURLTransition(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+		var invalid *InvalidTransitionError
+		if tt.wantErr && (!errors.As(err, &invalid) || invalid.From != tt.from || invalid.To != tt.to) {
+			t.Errorf("ValidateURLTransition(%q, %q) error = %v, want an *InvalidTransitionError", tt.from, tt.to, err)
+		}
+		if tt.wantErr && domain.HTTPStatus(err) != http.StatusConflict {
+			t.Errorf("ValidateURLTransition(%q, %q) error = %v, want a domain.ErrConflict", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestURLStatusesBefore(t *testing.T) {
+	tests := map[string][]string{
+		URLStatusPending: {URLStatusPending, URLStatusRetry, URLStatusFailed, URLStatusDegraded, URLStatusPaused},
+		URLStatusRetry:   {URLStatusRetry, URLStatusPending, URLStatusFailed},
+		URLStatusPaused:  {URLStatusPaused, URLStatusPending, URLStatusRetry, URLStatusFailed, URLStatusDegraded},
+		"completed":      nil,
+	}
+	for status, want := range tests {
+		if got := URLStatusesBefore(status); !reflect.DeepEqual(got, want) {
+			t.Errorf("URLStatusesBefore(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestNewURLStatusEvent(t *testing.T) {
+	changedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	change := URLStatusChange{URLID: uuid.New(), From: URLStatusPending, To: URLStatusPaused, ChangedAt: changedAt}
+
+	event := NewURLStatusEvent(change)
+	if event.Type != URLEventPaused || event.URLID != change.URLID || event.FromStatus != URLStatusPending || event.Status != URLStatusPaused {
+		t.Errorf("NewURLStatusEvent() = %+v", event)
+	}
+	if !event.OccurredAt.Equal(changedAt) || event.ID == uuid.Nil {
+		t.Errorf("NewURLStatusEvent() id = %s, occurred_at = %s", event.ID, event.OccurredAt)
+	}
+
+	change.From, change.To = URLStatusFailed, URLStatusPending
+	if event := NewURLStatusEvent(change); event.Type != URLEventStatusChanged {
+		t.Errorf("NewURLStatusEvent() type = %q, want %q", event.Type, URLEventStatusChanged)
+	}
+}
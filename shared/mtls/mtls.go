@@ -0,0 +1,160 @@
+// Package mtls builds the TLS configurations of mutual TLS between the
+// services (see config.MTLSConfig) and serves certificates from files that
+// are reloaded when they are rotated, so short-lived certificates can be
+// renewed in place without restarting the services.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// CheckInterval bounds how often the certificate files are checked for changes
+const CheckInterval = 10 * time.Second
+
+// Reloader serves a certificate from files, reloading it when the files'
+// modification times change
+type Reloader struct {
+	certFile, keyFile string
+	now               func() time.Time
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time // Latest modification time of the loaded files
+	checkedAt time.Time
+}
+
+// NewReloader loads the certificate and key files, failing when they cannot
+// be read, and returns a reloader serving them
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, now: time.Now}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Certificate returns the current certificate, first reloading it when the
+// files changed. A failed reload keeps the previous certificate, as the
+// files may be in the middle of being replaced.
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checkedAt) >= CheckInterval {
+		r.checkedAt = now
+		if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+			r.reload()
+		}
+	}
+	return r.cert
+}
+
+// GetCertificate returns the current certificate, for tls.Config of servers
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate returns the current certificate, for tls.Config of clients
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// reload loads the certificate and key files. Callers hold r.mu.
+func (r *Reloader) reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	r.cert, r.modTime, r.checkedAt = &cert, modTime, r.now()
+	return nil
+}
+
+// latestModTime returns the later modification time of the two files
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// ServerConfig returns the TLS configuration of a server that requires
+// client certificates signed by the CA
+func ServerConfig(cfg config.MTLSConfig) (*tls.Config, error) {
+	certs, pool, err := load(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+		ClientCAs:      pool,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// ClientConfig returns the TLS configuration of a client that presents the
+// service's certificate and verifies servers against the CA
+func ClientConfig(cfg config.MTLSConfig) (*tls.Config, error) {
+	certs, pool, err := load(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: certs.GetClientCertificate,
+		RootCAs:              pool,
+	}, nil
+}
+
+// HTTPClient returns a client for calls to other services with timeout,
+// using mutual TLS when it is enabled
+func HTTPClient(cfg config.MTLSConfig, timeout time.Duration) (*http.Client, error) {
+	if !cfg.Enabled {
+		return &http.Client{Timeout: timeout}, nil
+	}
+	tlsConfig, err := ClientConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// load loads the service's certificate and the CA certificates
+func load(cfg config.MTLSConfig) (*Reloader, *x509.CertPool, error) {
+	certs, err := NewReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	pem, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load CA certificates: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, nil, fmt.Errorf("failed to load CA certificates: no certificates in %s", cfg.CAFile)
+	}
+	return certs, pool, nil
+}
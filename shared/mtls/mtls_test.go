@@ -0,0 +1,208 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// authority is a test CA issuing certificates
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+// newAuthority creates a CA and writes its certificate to dir/name.pem
+func newAuthority(t *testing.T, dir, name string) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	file := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &authority{cert: cert, key: key, file: file}
+}
+
+// issue writes a certificate for name signed by the CA, valid for
+// localhost, and its key
+func (a *authority) issue(t *testing.T, certFile, keyFile, name string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, dir, "internal-ca")
+	ca.issue(t, filepath.Join(dir, "url-manager.pem"), filepath.Join(dir, "url-manager-key.pem"), "url-manager")
+	ca.issue(t, filepath.Join(dir, "api-gateway.pem"), filepath.Join(dir, "api-gateway-key.pem"), "api-gateway")
+
+	serverTLS, err := ServerConfig(config.MTLSConfig{
+		Enabled:  true,
+		CAFile:   ca.file,
+		CertFile: filepath.Join(dir, "url-manager.pem"),
+		KeyFile:  filepath.Join(dir, "url-manager-key.pem"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = serverTLS
+	server.StartTLS()
+	defer server.Close()
+	// Connect by name: without SNI the test server presents its own certificate
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	client, err := HTTPClient(config.MTLSConfig{
+		Enabled:  true,
+		CAFile:   ca.file,
+		CertFile: filepath.Join(dir, "api-gateway.pem"),
+		KeyFile:  filepath.Join(dir, "api-gateway-key.pem"),
+	}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("mutual TLS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	// A client without a certificate of the CA is rejected
+	other := newAuthority(t, dir, "other-ca")
+	other.issue(t, filepath.Join(dir, "intruder.pem"), filepath.Join(dir, "intruder-key.pem"), "intruder")
+	intruder, err := HTTPClient(config.MTLSConfig{
+		Enabled:  true,
+		CAFile:   ca.file,
+		CertFile: filepath.Join(dir, "intruder.pem"),
+		KeyFile:  filepath.Join(dir, "intruder-key.pem"),
+	}, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := intruder.Get(url); err == nil {
+		resp.Body.Close()
+		t.Error("request with a certificate of another CA succeeded")
+	}
+	if resp, err := http.Get(url); err == nil {
+		resp.Body.Close()
+		t.Error("request without TLS client certificate succeeded")
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t, dir, "internal-ca")
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	if _, err := NewReloader(certFile, keyFile); err == nil {
+		t.Fatal("NewReloader() without certificate files succeeded")
+	}
+
+	ca.issue(t, certFile, keyFile, "old")
+	certs, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	certs.now = func() time.Time { return now }
+	commonName := func() string {
+		leaf, err := x509.ParseCertificate(certs.Certificate().Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	ca.issue(t, certFile, keyFile, "new")
+	renewed := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, renewed, renewed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name := commonName(); name != "old" {
+		t.Errorf("certificate before the check interval = %s, want old", name)
+	}
+	now = now.Add(CheckInterval)
+	if name := commonName(); name != "new" {
+		t.Errorf("certificate after rotation = %s, want new", name)
+	}
+
+	os.WriteFile(keyFile, []byte("half-written"), 0o600)
+	later := renewed.Add(time.Minute)
+	os.Chtimes(keyFile, later, later)
+	now = now.Add(CheckInterval)
+	if name := commonName(); name != "new" {
+		t.Errorf("certificate after a failed reload = %s, want the previous one", name)
+	}
+}
+
+func TestHTTPClientWithoutMTLS(t *testing.T) {
+	client, err := HTTPClient(config.MTLSConfig{CAFile: "missing.pem"}, time.Second)
+	if err != nil || client.Transport != nil || client.Timeout != time.Second {
+		t.Errorf("HTTPClient() of disabled mTLS = %+v, %v; want a plain client", client, err)
+	}
+}
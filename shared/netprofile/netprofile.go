@@ -0,0 +1,161 @@
+// Package netprofile dials scrapers' connections with the network settings
+// of the target's domain (scraping.network): the IP family to connect over
+// and the local addresses to bind to. Host names are resolved through a
+// Resolver, usually the shared DNS cache.
+package netprofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+)
+
+// Resolver resolves host names, see dnscache.Resolver
+type Resolver interface {
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// Dialer dials connections by the scraping.network settings
+type Dialer struct {
+	cfg      config.NetworkConfig
+	resolver Resolver
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	next map[string]int // Next local address by address list
+}
+
+// New creates a dialer from the scraping.network settings, resolving names
+// with resolver or, when nil, the system resolver. Each connection attempt
+// times out after timeout, 30s when 0.
+func New(cfg config.NetworkConfig, resolver Resolver, timeout time.Duration) (*Dialer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if resolver == nil {
+		resolver = systemResolver{}
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Dialer{cfg: cfg, resolver: resolver, timeout: timeout, next: make(map[string]int)}, nil
+}
+
+// DialContext dials addr over the IP family of its host's domain, bound to
+// one of the domain's local addresses, trying each resolved address in turn.
+// Use it as the DialContext of an http.Transport.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	family, localAddresses := d.cfg.ForHost(host)
+	ips, err := d.resolver.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, ip := range Order(ips, family) {
+		if !matchesNetwork(network, ip) {
+			continue
+		}
+		dialer := &net.Dialer{Timeout: d.timeout}
+		if len(localAddresses) > 0 {
+			local := d.localAddress(localAddresses, ip.To4() != nil)
+			if local == nil {
+				continue // No local address of the IP's family to bind to
+			}
+			dialer.LocalAddr = localAddr(network, local)
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, &net.AddrError{Err: fmt.Sprintf("no address usable with ip_family %q and the local addresses", family), Addr: host}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Order returns the addresses an IP family connects to, in the order they
+// are tried. The resolver's order is kept within each family.
+func Order(ips []net.IP, family string) []net.IP {
+	var v4, v6 []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+	switch family {
+	case config.IPFamilyIPv4:
+		return v4
+	case config.IPFamilyIPv6:
+		return v6
+	case config.IPFamilyPreferIPv4:
+		return append(v4, v6...)
+	case config.IPFamilyPreferIPv6:
+		return append(v6, v4...)
+	}
+	return ips
+}
+
+// localAddress returns the next of the local addresses with the given
+// family, rotating between them; nil if there is none
+func (d *Dialer) localAddress(addresses []string, ipv4 bool) net.IP {
+	var candidates []net.IP
+	for _, addr := range addresses {
+		if ip := net.ParseIP(strings.TrimSpace(addr)); ip != nil && (ip.To4() != nil) == ipv4 {
+			candidates = append(candidates, ip)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	key := fmt.Sprint(ipv4, addresses)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := d.next[key] % len(candidates)
+	d.next[key] = i + 1
+	return candidates[i]
+}
+
+// localAddr returns the address to bind to for a network
+func localAddr(network string, ip net.IP) net.Addr {
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: ip}
+	}
+	return &net.TCPAddr{IP: ip}
+}
+
+// matchesNetwork reports whether ip can be dialed on network
+func matchesNetwork(network string, ip net.IP) bool {
+	switch {
+	case strings.HasSuffix(network, "4"):
+		return ip.To4() != nil
+	case strings.HasSuffix(network, "6"):
+		return ip.To4() == nil
+	}
+	return true
+}
+
+// systemResolver resolves names with the system resolver
+type systemResolver struct{}
+
+func (systemResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
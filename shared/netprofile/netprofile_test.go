@@ -0,0 +1,145 @@
+package netprofile
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"go_scraping_project/shared/config"
+)
+
+// staticResolver resolves every name to the same addresses
+type staticResolver []net.IP
+
+func (r staticResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return r, nil
+}
+
+func TestOrder(t *testing.T) {
+	v4a, v4b := net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2")
+	v6 := net.ParseIP("2001:db8::1")
+	ips := []net.IP{v6, v4a, v4b}
+	tests := []struct {
+		family string
+		want   []net.IP
+	}{
+		{config.IPFamilyAny, []net.IP{v6, v4a, v4b}},
+		{config.IPFamilyIPv4, []net.IP{v4a, v4b}},
+		{config.IPFamilyIPv6, []net.IP{v6}},
+		{config.IPFamilyPreferIPv4, []net.IP{v4a, v4b, v6}},
+		{config.IPFamilyPreferIPv6, []net.IP{v6, v4a, v4b}},
+	}
+	for _, tt := range tests {
+		if got := Order(ips, tt.family); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Order(%q) = %v, want %v", tt.family, got, tt.want)
+		}
+	}
+}
+
+func TestForHost(t *testing.T) {
+	cfg := config.NetworkConfig{
+		IPFamily:       config.IPFamilyPreferIPv4,
+		LocalAddresses: []string{"10.0.0.1"},
+		Domains: []config.NetworkDomainConfig{
+			{Domain: "example.com", IPFamily: config.IPFamilyIPv6},
+			{Domain: "shop.example.com", LocalAddresses: []string{"10.0.0.2"}},
+		},
+	}
+	tests := []struct {
+		host       string
+		wantFamily string
+		wantLocal  []string
+	}{
+		{"other.org", config.IPFamilyPreferIPv4, []string{"10.0.0.1"}},
+		{"www.example.com", config.IPFamilyIPv6, []string{"10.0.0.1"}},
+		{"shop.example.com", config.IPFamilyPreferIPv4, []string{"10.0.0.2"}},
+	}
+	for _, tt := range tests {
+		family, local := cfg.ForHost(tt.host)
+		if family != tt.wantFamily || !reflect.DeepEqual(local, tt.wantLocal) {
+			t.Errorf("ForHost(%q) = %q, %v; want %q, %v", tt.host, family, local, tt.wantFamily, tt.wantLocal)
+		}
+	}
+}
+
+func TestNetworkConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NetworkConfig
+		wantErr bool
+	}{
+		{"defaults", config.NetworkConfig{}, false},
+		{"dual stack", config.NetworkConfig{IPFamily: "prefer_ipv6", LocalAddresses: []string{"10.0.0.1", "2001:db8::1"}}, false},
+		{"unknown family", config.NetworkConfig{IPFamily: "ipv5"}, true},
+		{"bad address", config.NetworkConfig{LocalAddresses: []string{"eth0"}}, true},
+		{"family without address", config.NetworkConfig{IPFamily: "ipv6", LocalAddresses: []string{"10.0.0.1"}}, true},
+		{"domain without name", config.NetworkConfig{Domains: []config.NetworkDomainConfig{{IPFamily: "ipv4"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialContextBindsLocalAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	resolver := staticResolver{net.ParseIP("127.0.0.1")}
+	dialer, err := New(config.NetworkConfig{LocalAddresses: []string{"127.0.0.1"}}, resolver, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	conn, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("example.test", port))
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	defer conn.Close()
+	if local := conn.LocalAddr().(*net.TCPAddr); !local.IP.Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("local address = %s, want 127.0.0.1", local.IP)
+	}
+
+	ipv6Only, err := New(config.NetworkConfig{IPFamily: config.IPFamilyIPv6}, resolver, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if conn, err := ipv6Only.DialContext(context.Background(), "tcp", net.JoinHostPort("example.test", port)); err == nil {
+		conn.Close()
+		t.Error("DialContext over IPv6 to an IPv4-only host succeeded, want an error")
+	}
+}
+
+func TestLocalAddressRotates(t *testing.T) {
+	dialer, err := New(config.NetworkConfig{}, staticResolver{}, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	addresses := []string{"10.0.0.1", "2001:db8::1", "10.0.0.2"}
+	var got []string
+	for i := 0; i < 3; i++ {
+		got = append(got, dialer.localAddress(addresses, true).String())
+	}
+	if want := []string{"10.0.0.1", "10.0.0.2", "10.0.0.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IPv4 local addresses = %v, want %v", got, want)
+	}
+	if ip := dialer.localAddress([]string{"10.0.0.1"}, false); ip != nil {
+		t.Errorf("IPv6 local address = %s, want none", ip)
+	}
+}
@@ -0,0 +1,248 @@
+// Package notify sends operational alerts to notification channels: generic
+// JSON webhooks, Slack incoming webhooks, email, PagerDuty and the service
+// log. Channels come from the notifications section of the configuration
+// and from the notification_channels table, which is managed through the
+// API; a stored channel replaces the configured channel of the same name.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Alert severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Channel types
+const (
+	TypeWebhook   = "webhook"
+	TypeSlack     = "slack"
+	TypeEmail     = "email"
+	TypePagerDuty = "pagerduty"
+	TypeLog       = "log"
+)
+
+// DefaultTimeout bounds a single delivery when a channel sets no timeout
+const DefaultTimeout = 10 * time.Second
+
+// Alert is a notification about a condition that needs attention
+type Alert struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity string            `json:"severity"`
+	Fields   map[string]string `json:"fields,omitempty"` // Details such as the affected URL
+	Time     time.Time         `json:"time"`
+}
+
+// Notifier delivers alerts to a single channel
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Store loads the channels managed through the API
+type Store interface {
+	ListChannels(ctx context.Context) ([]config.NotificationChannelConfig, error)
+}
+
+// Dispatcher sends alerts to every configured and stored channel
+type Dispatcher struct {
+	client *http.Client
+	logger *logrus.Logger
+	store  Store
+
+	mu         sync.RWMutex
+	configured map[string]Notifier
+	stored     map[string]Notifier
+	channels   map[string]Notifier // Configured channels, replaced by stored channels of the same name
+}
+
+// New creates a dispatcher for the configured channels. A nil client uses
+// http.DefaultClient.
+func New(cfg config.NotificationsConfig, client *http.Client, logger *logrus.Logger) (*Dispatcher, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	d := &Dispatcher{client: client, logger: logger}
+	if err := d.Configure(cfg); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Configure replaces the channels, e.g. after a configuration reload. If any
+// channel is invalid the previous channels stay in effect.
+func (d *Dispatcher) Configure(cfg config.NotificationsConfig) error {
+	channels := make(map[string]Notifier, len(cfg.Channels))
+	for i, channelCfg := range cfg.Channels {
+		name := channelCfg.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", channelCfg.Type, i)
+		}
+		if _, exists := channels[name]; exists {
+			return fmt.Errorf("duplicate notification channel %q", name)
+		}
+		notifier, err := NewChannel(channelCfg, d.client, d.logger)
+		if err != nil {
+			return fmt.Errorf("notification channel %q: %w", name, err)
+		}
+		channels[name] = notifier
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.configured = channels
+	d.merge()
+	return nil
+}
+
+// SetStore sets the store the dispatcher loads channels managed through the
+// API from, see Refresh
+func (d *Dispatcher) SetStore(store Store) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.store = store
+}
+
+// Refresh reloads the stored channels. Invalid stored channels are logged
+// and skipped; if the store cannot be read the previous channels are kept.
+func (d *Dispatcher) Refresh(ctx context.Context) error {
+	d.mu.RLock()
+	store := d.store
+	d.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	stored, err := store.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+	channels := make(map[string]Notifier, len(stored))
+	for _, channelCfg := range stored {
+		notifier, err := NewChannel(channelCfg, d.client, d.logger)
+		if err != nil {
+			d.logger.WithError(err).WithField("channel", channelCfg.Name).Warn("Skipping invalid notification channel")
+			continue
+		}
+		channels[channelCfg.Name] = notifier
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stored = channels
+	d.merge()
+	return nil
+}
+
+// Run refreshes the stored channels every interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Refresh(ctx); err != nil && ctx.Err() == nil {
+				d.logger.WithError(err).Warn("Failed to refresh notification channels, keeping previous channels")
+			}
+		}
+	}
+}
+
+// merge combines the configured and stored channels. The caller must hold mu.
+func (d *Dispatcher) merge() {
+	channels := make(map[string]Notifier, len(d.configured)+len(d.stored))
+	for name, notifier := range d.configured {
+		channels[name] = notifier
+	}
+	for name, notifier := range d.stored {
+		channels[name] = notifier
+	}
+	d.channels = channels
+}
+
+// Notify sends the alert to every channel. Delivery failures are joined so
+// one unreachable channel does not prevent delivery to the others.
+func (d *Dispatcher) Notify(ctx context.Context, alert Alert) error {
+	if alert.Time.IsZero() {
+		alert.Time = time.Now().UTC()
+	}
+	if alert.Severity == "" {
+		alert.Severity = SeverityWarning
+	}
+
+	d.mu.RLock()
+	channels := d.channels
+	d.mu.RUnlock()
+
+	var errs []error
+	for name, notifier := range channels {
+		if err := notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewChannel creates the notifier for a single channel. A nil client uses
+// http.DefaultClient.
+func NewChannel(cfg config.NotificationChannelConfig, client *http.Client, logger *logrus.Logger) (Notifier, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	switch cfg.Type {
+	case TypeWebhook, TypeSlack:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url is required for %s channels", cfg.Type)
+		}
+		if cfg.Type == TypeSlack {
+			return &Slack{URL: cfg.URL, Timeout: timeout, Client: client}, nil
+		}
+		return &Webhook{URL: cfg.URL, Headers: cfg.Headers, Timeout: timeout, Client: client}, nil
+	case TypeEmail:
+		if cfg.Email.Host == "" || cfg.Email.From == "" || len(cfg.Email.To) == 0 {
+			return nil, fmt.Errorf("email.host, email.from and email.to are required for email channels")
+		}
+		return &Email{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+			Timeout:  timeout,
+		}, nil
+	case TypePagerDuty:
+		if cfg.RoutingKey == "" {
+			return nil, fmt.Errorf("routing_key is required for pagerduty channels")
+		}
+		url := cfg.URL
+		if url == "" {
+			url = PagerDutyEventsURL
+		}
+		return &PagerDuty{URL: url, RoutingKey: cfg.RoutingKey, Timeout: timeout, Client: client}, nil
+	case TypeLog:
+		return &Log{Logger: logger}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", cfg.Type)
+	}
+}
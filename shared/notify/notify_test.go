@@ -0,0 +1,243 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/encryption"
+
+	"github.com/sirupsen/logrus"
+)
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestDispatcherNotify(t *testing.T) {
+	var webhookAlert Alert
+	var webhookAuth string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&webhookAlert)
+	}))
+	defer webhook.Close()
+
+	var slackMessage map[string]string
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&slackMessage)
+	}))
+	defer slack.Close()
+
+	dispatcher, err := New(config.NotificationsConfig{Channels: []config.NotificationChannelConfig{
+		{Name: "ops", Type: TypeWebhook, URL: webhook.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+		{Name: "team", Type: TypeSlack, URL: slack.URL},
+		{Type: TypeLog},
+	}}, nil, testLogger())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	alert := Alert{Title: "URL degraded", Message: "3 consecutive failures", Fields: map[string]string{"url": "https://example.com"}}
+	if err := dispatcher.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if webhookAlert.Title != "URL degraded" || webhookAlert.Severity != SeverityWarning || webhookAlert.Time.IsZero() {
+		t.Errorf("webhook alert = %+v", webhookAlert)
+	}
+	if webhookAuth != "Bearer token" {
+		t.Errorf("webhook Authorization = %q", webhookAuth)
+	}
+	if text := slackMessage["text"]; !strings.Contains(text, "*[WARNING] URL degraded*") || !strings.Contains(text, "url: https://example.com") {
+		t.Errorf("slack text = %q", text)
+	}
+}
+
+func TestDispatcherNotifyReportsFailedChannels(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	dispatcher, err := New(config.NotificationsConfig{Channels: []config.NotificationChannelConfig{
+		{Name: "broken", Type: TypeWebhook, URL: failing.URL},
+		{Name: "log", Type: TypeLog},
+	}}, nil, testLogger())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = dispatcher.Notify(context.Background(), Alert{Title: "test"})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Notify() error = %v, want failure for the broken channel", err)
+	}
+}
+
+func TestConfigureRejectsInvalidChannels(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel config.NotificationChannelConfig
+	}{
+		{name: "unknown type", channel: config.NotificationChannelConfig{Type: "pager"}},
+		{name: "missing url", channel: config.NotificationChannelConfig{Type: TypeSlack}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, err := New(config.NotificationsConfig{}, nil, testLogger())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := dispatcher.Configure(config.NotificationsConfig{Channels: []config.NotificationChannelConfig{tt.channel}}); err == nil {
+				t.Error("Configure() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestPagerDutyNotify(t *testing.T) {
+	var event pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier, err := NewChannel(config.NotificationChannelConfig{Type: TypePagerDuty, URL: server.URL, RoutingKey: "key"}, nil, testLogger())
+	if err != nil {
+		t.Fatalf("NewChannel() error = %v", err)
+	}
+	alert := Alert{Title: "DLQ growing", Message: "120 messages", Severity: SeverityCritical, Fields: map[string]string{"topic": "dead-letter"}}
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if event.RoutingKey != "key" || event.EventAction != "trigger" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Payload.Summary != "DLQ growing" || event.Payload.Severity != SeverityCritical || event.Payload.CustomDetails["topic"] != "dead-letter" || event.Payload.CustomDetails["message"] != "120 messages" {
+		t.Errorf("payload = %+v", event.Payload)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	email := &Email{From: "alerts@example.com", To: []string{"a@example.com", "b@example.com"}}
+	msg := string(email.message(Alert{
+		Title:    "URL degraded\r\nBcc: x@example.com",
+		Message:  "3 consecutive failures",
+		Severity: SeverityWarning,
+		Fields:   map[string]string{"url": "https://example.com"},
+	}))
+
+	for _, want := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: [WARNING] URL degraded  Bcc: x@example.com\r\n",
+		"\r\n\r\n3 consecutive failures\r\nurl: https://example.com\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message = %q, want it to contain %q", msg, want)
+		}
+	}
+}
+
+type fakeStore struct {
+	channels []config.NotificationChannelConfig
+}
+
+func (s *fakeStore) ListChannels(ctx context.Context) ([]config.NotificationChannelConfig, error) {
+	return s.channels, nil
+}
+
+func TestDispatcherRefreshAddsStoredChannels(t *testing.T) {
+	var hits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, r.URL.Path)
+	}))
+	defer server.Close()
+
+	dispatcher, err := New(config.NotificationsConfig{Channels: []config.NotificationChannelConfig{
+		{Name: "ops", Type: TypeWebhook, URL: server.URL + "/configured"},
+	}}, nil, testLogger())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	dispatcher.SetStore(&fakeStore{channels: []config.NotificationChannelConfig{
+		{Name: "ops", Type: TypeWebhook, URL: server.URL + "/stored"},
+		{Name: "team", Type: TypeSlack, URL: server.URL + "/team"},
+		{Name: "broken", Type: TypeEmail},
+	}})
+	if err := dispatcher.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if err := dispatcher.Notify(context.Background(), Alert{Title: "test"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	sort.Strings(hits)
+	if strings.Join(hits, ",") != "/stored,/team" {
+		t.Errorf("delivered to %v, want the stored channels replacing the configured one", hits)
+	}
+}
+
+func TestChannelSettingsRedaction(t *testing.T) {
+	stored := ChannelSettings{
+		URL:     "https://hooks.slack.com/services/T/B/secret",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Email:   &EmailSettings{Host: "smtp.example.com", Password: "hunter2"},
+	}
+
+	redacted := stored.Redacted()
+	if redacted.URL != RedactedValue || redacted.Headers["Authorization"] != RedactedValue || redacted.Email.Password != RedactedValue || redacted.RoutingKey != "" {
+		t.Errorf("Redacted() = %+v", redacted)
+	}
+	if stored.Email.Password != "hunter2" || stored.Headers["Authorization"] != "Bearer token" {
+		t.Error("Redacted() modified the original settings")
+	}
+
+	redacted.Email.Host = "smtp2.example.com"
+	restored := redacted.Unredact(stored)
+	if restored.URL != stored.URL || restored.Headers["Authorization"] != "Bearer token" || restored.Email.Password != "hunter2" || restored.Email.Host != "smtp2.example.com" {
+		t.Errorf("Unredact() = %+v", restored)
+	}
+}
+
+func TestSettingsEncryption(t *testing.T) {
+	settings := ChannelSettings{RoutingKey: "R0UT1NGK3Y"}
+	keyring, err := encryption.New(config.EncryptionConfig{
+		PrimaryKey: "k1",
+		Keys:       map[string]string{"k1": base64.StdEncoding.EncodeToString(make([]byte, 32))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := EncodeSettings(settings, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(stored), "R0UT1NGK3Y") || !json.Valid(stored) {
+		t.Fatalf("stored settings = %s, want a JSON envelope", stored)
+	}
+	if decoded, err := DecodeSettings(stored, keyring); err != nil || decoded.RoutingKey != "R0UT1NGK3Y" {
+		t.Errorf("DecodeSettings() = %+v, %v", decoded, err)
+	}
+	if _, err := DecodeSettings(stored, nil); err == nil {
+		t.Error("DecodeSettings() of encrypted settings without keyring succeeded")
+	}
+
+	// Settings stored before keys were configured are still read
+	plain, _ := EncodeSettings(settings, nil)
+	if decoded, err := DecodeSettings(plain, keyring); err != nil || decoded.RoutingKey != "R0UT1NGK3Y" {
+		t.Errorf("DecodeSettings() of plain settings = %+v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySource names this system as the source of PagerDuty events
+const pagerDutySource = "go-scraping-system"
+
+// PagerDuty triggers PagerDuty incidents through the Events API v2
+type PagerDuty struct {
+	URL        string
+	RoutingKey string
+	Timeout    time.Duration
+	Client     *http.Client
+}
+
+// pagerDutyEvent is a trigger event of the Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Notify triggers an incident for the alert. Alert severities map to the
+// PagerDuty severities of the same name.
+func (p *PagerDuty) Notify(ctx context.Context, alert Alert) error {
+	details := make(map[string]string, len(alert.Fields)+1)
+	for key, value := range alert.Fields {
+		details[key] = value
+	}
+	if alert.Message != "" {
+		details["message"] = alert.Message
+	}
+
+	return post(ctx, p.Client, p.URL, nil, p.Timeout, pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyPayload{
+			Summary:       alert.Title,
+			Source:        pagerDutySource,
+			Severity:      alert.Severity,
+			Timestamp:     alert.Time.Format(time.RFC3339),
+			CustomDetails: details,
+		},
+	})
+}
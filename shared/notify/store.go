@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	"go_scraping_project/shared/encryption"
+)
+
+// RedactedValue replaces secrets in channel settings returned by the API.
+// Settings sent back with it keep the stored secret, see Unredact.
+const RedactedValue = "********"
+
+// ChannelSettings are the settings of a channel managed through the API, as
+// stored in the settings column of the notification_channels table, in an
+// encryption envelope when encryption keys are configured (see
+// EncodeSettings). Which settings are required depends on the channel type,
+// see NewChannel.
+type ChannelSettings struct {
+	URL            string            `json:"url,omitempty"`             // Webhook, Slack or PagerDuty endpoint
+	Headers        map[string]string `json:"headers,omitempty"`         // Extra webhook request headers
+	RoutingKey     string            `json:"routing_key,omitempty"`     // PagerDuty integration key
+	Email          *EmailSettings    `json:"email,omitempty"`           // SMTP server and recipients
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"` // Delivery timeout, 0 for DefaultTimeout
+}
+
+// EmailSettings are the SMTP settings of an email channel
+type EmailSettings struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Channel returns the channel configuration for the settings
+func (s ChannelSettings) Channel(name, channelType string) config.NotificationChannelConfig {
+	cfg := config.NotificationChannelConfig{
+		Name:       name,
+		Type:       channelType,
+		URL:        s.URL,
+		Headers:    s.Headers,
+		RoutingKey: s.RoutingKey,
+		Timeout:    time.Duration(s.TimeoutSeconds) * time.Second,
+	}
+	if s.Email != nil {
+		cfg.Email = config.EmailConfig{
+			Host:     s.Email.Host,
+			Port:     s.Email.Port,
+			Username: s.Email.Username,
+			Password: s.Email.Password,
+			From:     s.Email.From,
+			To:       s.Email.To,
+		}
+	}
+	return cfg
+}
+
+// Redacted returns the settings with the URL, header values, routing key
+// and password replaced by RedactedValue. Webhook URLs often embed a token,
+// so they are treated as secrets too.
+func (s ChannelSettings) Redacted() ChannelSettings {
+	s.URL = redact(s.URL)
+	s.RoutingKey = redact(s.RoutingKey)
+	if s.Headers != nil {
+		headers := make(map[string]string, len(s.Headers))
+		for key, value := range s.Headers {
+			headers[key] = redact(value)
+		}
+		s.Headers = headers
+	}
+	if s.Email != nil {
+		email := *s.Email
+		email.Password = redact(email.Password)
+		s.Email = &email
+	}
+	return s
+}
+
+// Unredact replaces values equal to RedactedValue with the corresponding
+// value of previous, so settings read from the API can be sent back unchanged
+func (s ChannelSettings) Unredact(previous ChannelSettings) ChannelSettings {
+	if s.URL == RedactedValue {
+		s.URL = previous.URL
+	}
+	if s.RoutingKey == RedactedValue {
+		s.RoutingKey = previous.RoutingKey
+	}
+	if s.Headers != nil {
+		headers := make(map[string]string, len(s.Headers))
+		for key, value := range s.Headers {
+			if value == RedactedValue {
+				value = previous.Headers[key]
+			}
+			headers[key] = value
+		}
+		s.Headers = headers
+	}
+	if s.Email != nil && s.Email.Password == RedactedValue {
+		email := *s.Email
+		email.Password = ""
+		if previous.Email != nil {
+			email.Password = previous.Email.Password
+		}
+		s.Email = &email
+	}
+	return s
+}
+
+// EncodeSettings returns settings in their stored form: JSON, encrypted in
+// an envelope of the keyring unless it is nil
+func EncodeSettings(settings ChannelSettings, keyring *encryption.Keyring) ([]byte, error) {
+	data, err := json.Marshal(settings)
+	if err != nil || keyring == nil {
+		return data, err
+	}
+	return keyring.SealBytes(data)
+}
+
+// DecodeSettings decodes stored settings, decrypting them when they are
+// encrypted. Settings stored before encryption keys were configured are
+// read as they are.
+func DecodeSettings(data []byte, keyring *encryption.Keyring) (ChannelSettings, error) {
+	var settings ChannelSettings
+	if env, ok := encryption.Parse(data); ok {
+		plain, err := keyring.Open(env)
+		if err != nil {
+			return settings, err
+		}
+		data = plain
+	}
+	err := json.Unmarshal(data, &settings)
+	return settings, err
+}
+
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// Querier is the subset of database queries used by DBStore
+type Querier interface {
+	ListNotificationChannels(ctx context.Context) ([]database.NotificationChannel, error)
+}
+
+// DBStore loads channels from the notification_channels table
+type DBStore struct {
+	db      Querier
+	keyring *encryption.Keyring
+}
+
+// NewDBStore creates a store backed by the given queries, decrypting
+// settings with the keyring, nil when no encryption keys are configured
+func NewDBStore(db Querier, keyring *encryption.Keyring) *DBStore {
+	return &DBStore{db: db, keyring: keyring}
+}
+
+// ListChannels returns the enabled channels stored in the database
+func (s *DBStore) ListChannels(ctx context.Context) ([]config.NotificationChannelConfig, error) {
+	rows, err := s.db.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]config.NotificationChannelConfig, 0, len(rows))
+	for _, row := range rows {
+		if !row.Enabled {
+			continue
+		}
+		settings, err := DecodeSettings(row.Settings, s.keyring)
+		if err != nil {
+			return nil, fmt.Errorf("notification channel %q has invalid settings: %w", row.Name, err)
+		}
+		channels = append(channels, settings.Channel(row.Name, row.Type))
+	}
+	return channels, nil
+}
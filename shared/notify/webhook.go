@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Webhook POSTs alerts as JSON to an HTTP endpoint
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Notify POSTs the alert to the webhook
+func (w *Webhook) Notify(ctx context.Context, alert Alert) error {
+	return post(ctx, w.Client, w.URL, w.Headers, w.Timeout, alert)
+}
+
+// Slack posts alerts to a Slack incoming webhook
+type Slack struct {
+	URL     string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Notify posts the alert as a Slack message
+func (s *Slack) Notify(ctx context.Context, alert Alert) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*[%s] %s*", strings.ToUpper(alert.Severity), alert.Title)
+	if alert.Message != "" {
+		fmt.Fprintf(&text, "\n%s", alert.Message)
+	}
+	for _, key := range sortedKeys(alert.Fields) {
+		fmt.Fprintf(&text, "\n• %s: %s", key, alert.Fields[key])
+	}
+
+	return post(ctx, s.Client, s.URL, nil, s.Timeout, map[string]string{"text": text.String()})
+}
+
+// Log writes alerts to the service log
+type Log struct {
+	Logger *logrus.Logger
+}
+
+// Notify logs the alert as a warning, or an error for critical alerts
+func (l *Log) Notify(ctx context.Context, alert Alert) error {
+	fields := logrus.Fields{"alert": alert.Title, "severity": alert.Severity}
+	for key, value := range alert.Fields {
+		fields[key] = value
+	}
+
+	entry := l.Logger.WithFields(fields)
+	if alert.Severity == SeverityCritical {
+		entry.Error(alert.Message)
+	} else {
+		entry.Warn(alert.Message)
+	}
+	return nil
+}
+
+// post sends body as JSON and treats any non-2xx response as a failure
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, timeout time.Duration, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
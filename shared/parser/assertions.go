@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"go_scraping_project/shared/models"
+
+	"golang.org/x/net/html"
+)
+
+// ValidateAssertions checks content assertions, including the syntax of their selectors
+func ValidateAssertions(assertions models.Assertions) error {
+	if err := assertions.Validate(); err != nil {
+		return err
+	}
+	for _, selector := range assertions.Selectors {
+		if _, err := CompileSelector(selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EvaluateAssertions checks a fetched document against content assertions
+// and describes each failed assertion. It returns nil when all pass.
+// Scrapers report the failures in ScrapeResult.AssertionFailures.
+func EvaluateAssertions(assertions *models.Assertions, doc Document) []string {
+	if assertions == nil {
+		return nil
+	}
+
+	var failures []string
+	if assertions.Status != 0 && doc.StatusCode != assertions.Status {
+		failures = append(failures, fmt.Sprintf("status is %d, expected %d", doc.StatusCode, assertions.Status))
+	}
+	for _, text := range assertions.Contains {
+		if !strings.Contains(doc.Body, text) {
+			failures = append(failures, fmt.Sprintf("response does not contain %q", text))
+		}
+	}
+	if len(assertions.Selectors) == 0 {
+		return failures
+	}
+
+	root, err := html.Parse(strings.NewReader(doc.Body))
+	if err != nil {
+		return append(failures, fmt.Sprintf("response is not valid HTML: %v", err))
+	}
+	for _, selector := range assertions.Selectors {
+		compiled, err := CompileSelector(selector)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if !compiled.MatchAny(root) {
+			failures = append(failures, fmt.Sprintf("selector %q matches no element", selector))
+		}
+	}
+	return failures
+}
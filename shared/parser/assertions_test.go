@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/models"
+
+	"golang.org/x/net/html"
+)
+
+const assertionPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta property="og:title" content="Spring sale">
+</head>
+<body>
+  <div id="main" class="page product">
+    <h1 class="title">Spring sale</h1>
+    <ul class="prices"><li><span class="price" data-currency="EUR">10</span></li></ul>
+  </div>
+  <footer><a rel="author" href="/about">About</a></footer>
+</body>
+</html>`
+
+func TestSelectorMatching(t *testing.T) {
+	root, err := html.Parse(strings.NewReader(assertionPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"h1", true},
+		{"h2", false},
+		{"#main", true},
+		{"div.page.product", true},
+		{"div.page.article", false},
+		{"meta[property='og:title']", true},
+		{`meta[property="og:image"]`, false},
+		{"[data-currency]", true},
+		{"span[data-currency^=EU]", true},
+		{"[class~=price]", true},
+		{"a[href$='/about']", true},
+		{"#main .price", true},
+		{"#main > .price", false},
+		{"ul.prices > li > span.price", true},
+		{"footer h1", false},
+		{"h2, footer a", true},
+		{"*", true},
+	}
+
+	for _, tt := range tests {
+		selector, err := CompileSelector(tt.selector)
+		if err != nil {
+			t.Errorf("CompileSelector(%q) error = %v", tt.selector, err)
+			continue
+		}
+		if got := selector.MatchAny(root); got != tt.want {
+			t.Errorf("%q matches = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestCompileSelectorRejectsUnsupportedSyntax(t *testing.T) {
+	for _, selector := range []string{"", " ", "a:hover", "div >", "> p", "a,", "[href", "p + p", "div.", "[=x]", "[a=b c]"} {
+		if _, err := CompileSelector(selector); err == nil {
+			t.Errorf("CompileSelector(%q) expected an error", selector)
+		}
+	}
+}
+
+func TestEvaluateAssertions(t *testing.T) {
+	doc := Document{URL: "https://example.com/sale", StatusCode: 200, Body: assertionPage}
+
+	passing := &models.Assertions{Status: 200, Contains: []string{"Spring sale"}, Selectors: []string{".price", "h1.title"}}
+	if failures := EvaluateAssertions(passing, doc); failures != nil {
+		t.Errorf("EvaluateAssertions() = %v, want no failures", failures)
+	}
+	if failures := EvaluateAssertions(nil, doc); failures != nil {
+		t.Errorf("EvaluateAssertions(nil) = %v, want no failures", failures)
+	}
+
+	failing := &models.Assertions{Status: 200, Contains: []string{"Add to cart"}, Selectors: []string{".price", ".stock"}}
+	doc.StatusCode = 203
+	want := []string{
+		"status is 203, expected 200",
+		`response does not contain "Add to cart"`,
+		`selector ".stock" matches no element`,
+	}
+	if failures := EvaluateAssertions(failing, doc); !reflect.DeepEqual(failures, want) {
+		t.Errorf("EvaluateAssertions() = %q, want %q", failures, want)
+	}
+}
+
+func TestValidateAssertions(t *testing.T) {
+	tests := []struct {
+		name       string
+		assertions models.Assertions
+		wantErr    bool
+	}{
+		{name: "status only", assertions: models.Assertions{Status: 200}},
+		{name: "text and selector", assertions: models.Assertions{Contains: []string{"Price"}, Selectors: []string{"#main .price"}}},
+		{name: "empty", assertions: models.Assertions{}, wantErr: true},
+		{name: "invalid status", assertions: models.Assertions{Status: 42}, wantErr: true},
+		{name: "empty text", assertions: models.Assertions{Contains: []string{""}}, wantErr: true},
+		{name: "unsupported selector", assertions: models.Assertions{Selectors: []string{"a:first-child"}}, wantErr: true},
+		{name: "too many checks", assertions: models.Assertions{Contains: make([]string, models.MaxAssertionChecks+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAssertions(tt.assertions); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAssertions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileSelectorSupportsTemplateSelectors(t *testing.T) {
+	for _, tmpl := range BuiltinTemplates() {
+		for field, selector := range tmpl.Config.Selectors {
+			if _, err := CompileSelector(selector); err != nil {
+				t.Errorf("template %s, field %s: %v", tmpl.Name, field, err)
+			}
+		}
+	}
+}
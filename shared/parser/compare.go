@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"go_scraping_project/shared/models"
+)
+
+// FieldComparison compares one field of two parses of the same page
+type FieldComparison struct {
+	Field     string      `json:"field"`
+	Equal     bool        `json:"equal"`
+	Active    interface{} `json:"active"`    // nil when the active parse lacks the field
+	Candidate interface{} `json:"candidate"` // nil when the candidate parse lacks the field
+}
+
+// CompareFields compares the parse of a page made with a URL's active
+// parser config with the one made with its candidate config. Title and
+// content are compared as the fields "title" and "content" and data fields
+// by name. Every field set in either parse is returned, sorted by name.
+// Values are equal when their JSON encodings are.
+func CompareFields(active, candidate *models.ParsedData) []FieldComparison {
+	activeFields, candidateFields := recordFields(active), recordFields(candidate)
+	names := make([]string, 0, len(activeFields)+len(candidateFields))
+	for name := range activeFields {
+		names = append(names, name)
+	}
+	for name := range candidateFields {
+		if _, ok := activeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	comparisons := make([]FieldComparison, 0, len(names))
+	for _, name := range names {
+		a, inActive := activeFields[name]
+		c, inCandidate := candidateFields[name]
+		comparisons = append(comparisons, FieldComparison{
+			Field:     name,
+			Equal:     inActive && inCandidate && jsonEqual(a, c),
+			Active:    a,
+			Candidate: c,
+		})
+	}
+	return comparisons
+}
+
+// recordFields flattens a parse into its title, content and data fields
+func recordFields(record *models.ParsedData) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if record == nil {
+		return fields
+	}
+	for name, value := range record.Data {
+		fields[name] = value
+	}
+	if record.Title != "" {
+		fields[titleField] = record.Title
+	}
+	if record.Content != "" {
+		fields[contentField] = record.Content
+	}
+	return fields
+}
+
+// jsonEqual reports whether two values have the same JSON encoding
+func jsonEqual(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+// ConfigChange is a setting that differs between two parser configs
+type ConfigChange struct {
+	Path string      `json:"path"`          // Dotted path, e.g. selectors.price or rules.image_url
+	Old  interface{} `json:"old,omitempty"` // nil when the setting was added
+	New  interface{} `json:"new,omitempty"` // nil when the setting was removed
+}
+
+// DiffConfigs returns the settings that differ between two parser configs,
+// sorted by path. Nested settings are compared one by one, rules by name
+// and other lists as a whole. Either config may be nil.
+func DiffConfigs(from, to *models.ParserConfig) ([]ConfigChange, error) {
+	fromSettings, err := configSettings(from)
+	if err != nil {
+		return nil, err
+	}
+	toSettings, err := configSettings(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []ConfigChange{}
+	for path, old := range fromSettings {
+		if value, ok := toSettings[path]; !ok || !jsonEqual(old, value) {
+			changes = append(changes, ConfigChange{Path: path, Old: old, New: value})
+		}
+	}
+	for path, value := range toSettings {
+		if _, ok := fromSettings[path]; !ok {
+			changes = append(changes, ConfigChange{Path: path, New: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// configSettings flattens a parser config into its settings by dotted path
+func configSettings(cfg *models.ParserConfig) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if cfg == nil {
+		return settings, nil
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(encoded, &tree); err != nil {
+		return nil, err
+	}
+	delete(tree, "rules")
+	rules := make(map[string]interface{}, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rules[rule.Name] = rule
+	}
+	tree["rules"] = rules
+
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for key, child := range nested {
+				flatten(prefix+"."+key, child)
+			}
+			return
+		}
+		if value != nil {
+			settings[prefix] = value
+		}
+	}
+	for key, value := range tree {
+		flatten(key, value)
+	}
+	return settings, nil
+}
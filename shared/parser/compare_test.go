@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"go_scraping_project/shared/models"
+)
+
+func TestCompareFields(t *testing.T) {
+	active := &models.ParsedData{Title: "Kettle", Data: map[string]interface{}{"price": "24.90", "sku": "K1"}}
+	candidate := &models.ParsedData{Title: "Kettle", Data: map[string]interface{}{"price": 24.9, "stock": "in stock"}}
+
+	got := CompareFields(active, candidate)
+	want := []FieldComparison{
+		{Field: "price", Active: "24.90", Candidate: 24.9},
+		{Field: "sku", Active: "K1"},
+		{Field: "stock", Candidate: "in stock"},
+		{Field: "title", Equal: true, Active: "Kettle", Candidate: "Kettle"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareFields() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	from := &models.ParserConfig{
+		Version:   models.ParserConfigVersion,
+		Selectors: map[string]string{"title": "h1", "price": ".price"},
+		Rules:     []models.ParseRule{{Name: "image_url", Selector: "img", Type: RuleTypeAttr, Attr: "src"}},
+	}
+	to := &models.ParserConfig{
+		Version:   models.ParserConfigVersion,
+		Selectors: map[string]string{"title": "h1", "price": ".price-now", "sku": ".sku"},
+		Options:   &models.ParseOptions{ExtractLinks: true},
+	}
+
+	changes, err := DiffConfigs(from, to)
+	if err != nil {
+		t.Fatalf("DiffConfigs() error = %v", err)
+	}
+	paths := make([]string, 0, len(changes))
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	want := []string{"options.extract_links", "rules.image_url", "selectors.price", "selectors.sku"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("changed paths = %v, want %v", paths, want)
+	}
+	if changes[2].Old != ".price" || changes[2].New != ".price-now" {
+		t.Errorf("selectors.price change = %+v", changes[2])
+	}
+	if changes[1].New != nil {
+		t.Errorf("removed rule change = %+v, want no new value", changes[1])
+	}
+
+	if changes, _ := DiffConfigs(from, from); len(changes) != 0 {
+		t.Errorf("DiffConfigs() of equal configs = %+v", changes)
+	}
+}
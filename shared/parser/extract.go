@@ -0,0 +1,307 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go_scraping_project/shared/models"
+
+	"golang.org/x/net/html"
+)
+
+// Rule types supported by Parser
+const (
+	RuleTypeText = "text" // Text of the element (the default)
+	RuleTypeAttr = "attr" // Value of the rule's attribute
+	RuleTypeHTML = "html" // Inner HTML of the element
+)
+
+// Selector names that fill ParsedData.Title and ParsedData.Content
+const (
+	titleField   = "title"
+	contentField = "content"
+)
+
+// field is a compiled selector or rule
+type field struct {
+	name     string
+	source   string // Selector as written in the config
+	selector *Selector
+	kind     string
+	attr     string
+}
+
+// Parser is a compiled, reusable parser config
+type Parser struct {
+	selectors []field
+	rules     []field
+	options   models.ParseOptions
+	script    *Script
+}
+
+// NewParser compiles a parser config whose template, if any, is already
+// applied (see Apply). Selectors and rules are compiled once, so a parser
+// can be run over many documents.
+func NewParser(cfg *models.ParserConfig) (*Parser, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("parser config is required")
+	}
+
+	p := &Parser{}
+	names := make([]string, 0, len(cfg.Selectors))
+	for name := range cfg.Selectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		selector, err := CompileSelector(cfg.Selectors[name])
+		if err != nil {
+			return nil, fmt.Errorf("selector %s: %w", name, err)
+		}
+		p.selectors = append(p.selectors, field{name: name, source: cfg.Selectors[name], selector: selector, kind: RuleTypeText})
+	}
+
+	for _, rule := range cfg.Rules {
+		selector, err := CompileSelector(rule.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		kind := rule.Type
+		if kind == "" {
+			kind = RuleTypeText
+		}
+		switch kind {
+		case RuleTypeText, RuleTypeHTML:
+		case RuleTypeAttr:
+			if rule.Attr == "" {
+				return nil, fmt.Errorf("rule %s: attr is required for type attr", rule.Name)
+			}
+		default:
+			return nil, fmt.Errorf("rule %s: unsupported type %q", rule.Name, rule.Type)
+		}
+		p.rules = append(p.rules, field{name: rule.Name, source: rule.Selector, selector: selector, kind: kind, attr: rule.Attr})
+	}
+
+	if cfg.Options != nil {
+		p.options = *cfg.Options
+	}
+	if cfg.Script != nil {
+		script, err := CompileScript(cfg.Script)
+		if err != nil {
+			return nil, err
+		}
+		p.script = script
+	}
+	return p, nil
+}
+
+// Parse extracts a record from a fetched document. A selector takes the
+// text of the first element it matches, or the content attribute of a meta
+// element; the title and content selectors fill the record's Title and
+// Content and the others its Data. Rules then add their fields to Data,
+// followed by the fields returned by the script.
+//
+// A selector or rule that fails, because it matches nothing or the matched
+// element has no value, does not fail the parse: the field is left out and
+// its error recorded in the record's FieldErrors, so a page that changed
+// in part still yields the fields that can be extracted. A failing script
+// fails the parse, since the fields it would return are not known.
+//
+// The record's identity (ID, URLID, URL and CreatedAt) is left to the
+// caller, and transform webhooks are not called (see Transformer).
+func (p *Parser) Parse(ctx context.Context, doc Document) (*models.ParsedData, error) {
+	root, err := html.Parse(strings.NewReader(doc.Body))
+	if err != nil {
+		return nil, fmt.Errorf("document is not valid HTML: %w", err)
+	}
+	if p.options.RemoveScripts {
+		removeElements(root, "script", "noscript")
+	}
+	if p.options.RemoveStyles {
+		removeElements(root, "style")
+	}
+
+	record := &models.ParsedData{URL: doc.URL, Data: make(map[string]interface{}), FieldErrors: make(map[string]string)}
+	for _, f := range p.selectors {
+		value, err := f.extract(root)
+		if err != nil {
+			record.FieldErrors[f.name] = err.Error()
+			continue
+		}
+		switch f.name {
+		case titleField:
+			record.Title = value
+		case contentField:
+			record.Content = value
+		default:
+			record.Data[f.name] = value
+		}
+	}
+	for _, f := range p.rules {
+		value, err := f.extract(root)
+		if err != nil {
+			record.FieldErrors[f.name] = err.Error()
+			continue
+		}
+		record.Data[f.name] = value
+	}
+
+	if p.options.ExtractMetadata {
+		record.Metadata = extractMetadata(root)
+	}
+	if p.options.ExtractLinks {
+		record.Data["links"] = collectAttr(root, "a", "href")
+	}
+	if p.options.ExtractImages {
+		record.Data["images"] = collectAttr(root, "img", "src")
+	}
+	if p.options.ExtractText {
+		record.Text = normalizedText(root)
+	}
+
+	if p.script != nil {
+		fields, err := p.script.Run(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fields {
+			record.Data[name] = value
+		}
+	}
+	return record, nil
+}
+
+// MissingFields returns the names of the parser's selectors and rules that
+// extracted nothing from the document a record was parsed from, e.g.
+// because a selector no longer matches the page
+func (p *Parser) MissingFields(record *models.ParsedData) []string {
+	var missing []string
+	for _, f := range append(append([]field{}, p.selectors...), p.rules...) {
+		var ok bool
+		switch f.name {
+		case titleField:
+			ok = record.Title != ""
+		case contentField:
+			ok = record.Content != ""
+		default:
+			_, ok = record.Data[f.name]
+		}
+		if !ok {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+// extract returns the value of the field in the first element it matches.
+// It fails when the selector matches nothing or the value is empty.
+func (f field) extract(root *html.Node) (string, error) {
+	n := f.selector.First(root)
+	if n == nil {
+		return "", fmt.Errorf("selector %q matches nothing", f.source)
+	}
+
+	var value, what string
+	switch {
+	case f.kind == RuleTypeAttr:
+		value, what = attrValue(n, f.attr), f.attr+" attribute"
+	case f.kind == RuleTypeHTML:
+		var buf bytes.Buffer
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if err := html.Render(&buf, c); err != nil {
+				return "", fmt.Errorf("matched element cannot be rendered: %w", err)
+			}
+		}
+		value, what = buf.String(), "inner HTML"
+	case strings.EqualFold(n.Data, "meta"):
+		value, what = attrValue(n, "content"), "content attribute"
+	default:
+		value, what = nodeText(n), "text"
+	}
+	if value == "" {
+		return "", fmt.Errorf("matched %s element has no %s", strings.ToLower(n.Data), what)
+	}
+	return value, nil
+}
+
+// nodeText returns the text below n with runs of whitespace collapsed
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// removeElements detaches every element with one of the given tags
+func removeElements(root *html.Node, tags ...string) {
+	var remove []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode {
+				for _, tag := range tags {
+					if strings.EqualFold(c.Data, tag) {
+						remove = append(remove, c)
+					}
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	for _, n := range remove {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// extractMetadata collects the meta elements with a name or property and content
+func extractMetadata(root *html.Node) map[string]string {
+	metadata := make(map[string]string)
+	forEachElement(root, "meta", func(n *html.Node) {
+		key := attrValue(n, "name")
+		if key == "" {
+			key = attrValue(n, "property")
+		}
+		if content := attrValue(n, "content"); key != "" && content != "" {
+			metadata[key] = content
+		}
+	})
+	return metadata
+}
+
+// collectAttr returns the non-empty values of an attribute on every element with the tag
+func collectAttr(root *html.Node, tag, attr string) []string {
+	values := []string{}
+	forEachElement(root, tag, func(n *html.Node) {
+		if value := attrValue(n, attr); value != "" {
+			values = append(values, value)
+		}
+	})
+	return values
+}
+
+// forEachElement calls fn for every element with the tag, in document order
+func forEachElement(root *html.Node, tag string, fn func(n *html.Node)) {
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && strings.EqualFold(c.Data, tag) {
+				fn(c)
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+}
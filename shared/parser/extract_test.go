@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/models"
+)
+
+const productPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta name="description" content="A sturdy kettle">
+  <meta property="og:image" content="https://example.com/kettle.jpg">
+  <script>var tracking = "ignored";</script>
+</head>
+<body>
+  <h1>  Steel
+    kettle </h1>
+  <div class="product-description"><p>Boils <b>fast</b></p></div>
+  <span itemprop="price" content="24.90">€24.90</span>
+  <a href="/kettles">More kettles</a>
+</body>
+</html>`
+
+func TestParserParse(t *testing.T) {
+	tmpl, _ := BuiltinTemplate("product-page")
+	cfg := Apply(tmpl, &models.ParserConfig{
+		Selectors: map[string]string{"content": ".product-description", "missing": ".none"},
+		Rules:     []models.ParseRule{{Name: "description_html", Selector: ".product-description", Type: RuleTypeHTML}},
+		Options:   &models.ParseOptions{ExtractMetadata: true, ExtractLinks: true, RemoveScripts: true},
+		Script: &models.ScriptConfig{
+			Language: ScriptLanguageStarlark,
+			Source:   "def extract(doc):\n    return {\"length\": len(doc[\"body\"]) > 0}\n",
+		},
+	})
+
+	p, err := NewParser(cfg)
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	record, err := p.Parse(context.Background(), Document{URL: "https://example.com/kettle", Body: productPage})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if record.Title != "Steel kettle" || record.Content != "Boils fast" {
+		t.Errorf("title = %q, content = %q", record.Title, record.Content)
+	}
+	want := map[string]interface{}{
+		"price":            "€24.90",
+		"price_amount":     "24.90",
+		"image":            "https://example.com/kettle.jpg",
+		"image_url":        "https://example.com/kettle.jpg",
+		"description":      "Boils fast",
+		"description_html": "<p>Boils <b>fast</b></p>",
+		"links":            []string{"/kettles"},
+		"length":           true,
+	}
+	if !reflect.DeepEqual(record.Data, want) {
+		t.Errorf("data = %#v, want %#v", record.Data, want)
+	}
+	if record.Metadata["description"] != "A sturdy kettle" {
+		t.Errorf("metadata = %v", record.Metadata)
+	}
+	if missing := p.MissingFields(record); !reflect.DeepEqual(missing, []string{"availability", "currency", "missing", "sku"}) {
+		t.Errorf("MissingFields() = %v", missing)
+	}
+}
+
+func TestParseRecordsFieldErrors(t *testing.T) {
+	p, err := NewParser(&models.ParserConfig{
+		Selectors: map[string]string{"title": "h1", "price": ".price"},
+		Rules: []models.ParseRule{
+			{Name: "price_amount", Selector: "[itemprop=price]", Type: RuleTypeAttr, Attr: "content"},
+			{Name: "currency", Selector: "[itemprop=price]", Type: RuleTypeAttr, Attr: "data-currency"},
+			{Name: "link", Selector: "a", Type: RuleTypeAttr, Attr: "href"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	record, err := p.Parse(context.Background(), Document{Body: productPage})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if record.Title != "Steel kettle" || record.Data["price_amount"] != "24.90" || record.Data["link"] != "/kettles" {
+		t.Errorf("record = %+v, want the fields that match kept", record)
+	}
+	want := map[string]string{
+		"price":    `selector ".price" matches nothing`,
+		"currency": "matched span element has no data-currency attribute",
+	}
+	if !reflect.DeepEqual(record.FieldErrors, want) {
+		t.Errorf("field errors = %v, want %v", record.FieldErrors, want)
+	}
+	if _, ok := record.Data["currency"]; ok {
+		t.Error("failed field is in the data")
+	}
+}
+
+func TestNewParserRejectsInvalidRules(t *testing.T) {
+	configs := []*models.ParserConfig{
+		nil,
+		{Selectors: map[string]string{"title": "h1["}},
+		{Rules: []models.ParseRule{{Name: "a", Selector: "a", Type: "xpath"}}},
+		{Rules: []models.ParseRule{{Name: "a", Selector: "a", Type: RuleTypeAttr}}},
+	}
+	for _, cfg := range configs {
+		if _, err := NewParser(cfg); err == nil {
+			t.Errorf("NewParser(%+v) accepted an invalid config", cfg)
+		}
+	}
+}
+
+// catalogPage returns productPage followed by a listing of n related
+// products, the size of a typical catalog page
+func catalogPage(n int) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(productPage, "</body>\n</html>"))
+	b.WriteString("<ul class=\"related\">\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "<li class=\"item\"><a href=\"/kettles/%d\">Kettle %d</a> <span class=\"price\">€%d.90</span></li>\n", i, i, 20+i%30)
+	}
+	b.WriteString("</ul>\n</body>\n</html>")
+	return b.String()
+}
+
+// BenchmarkParse measures parsing a catalog page with the product-page
+// template, with and without a script
+func BenchmarkParse(b *testing.B) {
+	tmpl, _ := BuiltinTemplate("product-page")
+	base := &models.ParserConfig{
+		Selectors: map[string]string{"content": ".product-description"},
+		Rules:     []models.ParseRule{{Name: "first_related", Selector: ".related .item a", Type: RuleTypeAttr, Attr: "href"}},
+		Options:   &models.ParseOptions{ExtractMetadata: true, ExtractLinks: true, RemoveScripts: true},
+	}
+	withScript := *base
+	withScript.Script = &models.ScriptConfig{
+		Language: ScriptLanguageStarlark,
+		Source:   "def extract(doc):\n    return {\"items\": doc[\"body\"].count(\"class=\\\"item\\\"\")}\n",
+	}
+	doc := Document{URL: "https://example.com/kettle", StatusCode: 200, ContentType: "text/html", Body: catalogPage(200)}
+
+	for _, bc := range []struct {
+		name string
+		cfg  *models.ParserConfig
+	}{
+		{"selectors", base},
+		{"script", &withScript},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p, err := NewParser(Apply(tmpl, bc.cfg))
+			if err != nil {
+				b.Fatalf("NewParser() error = %v", err)
+			}
+			b.SetBytes(int64(len(doc.Body)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Parse(context.Background(), doc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go_scraping_project/shared/models"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// ScriptLanguageStarlark is the only supported extraction script language
+const ScriptLanguageStarlark = "starlark"
+
+// Default sandbox limits applied when a ScriptConfig leaves them unset
+const (
+	DefaultScriptMaxSteps       = 1_000_000
+	DefaultScriptTimeout        = 2 * time.Second
+	DefaultScriptMaxInputBytes  = 5 << 20  // 5MB
+	DefaultScriptMaxOutputBytes = 1 << 20  // 1MB
+	DefaultScriptMaxAllocBytes  = 64 << 20 // 64MB
+
+	maxScriptSteps      = 50_000_000
+	maxScriptTimeout    = 30 * time.Second
+	maxScriptAllocBytes = 1 << 30 // 1GB
+
+	// maxScriptResultDepth bounds the nesting of lists and dicts in a
+	// result, which also stops self-referential ones
+	maxScriptResultDepth = 64
+)
+
+// scriptEntrypoint is the function every script must define
+const scriptEntrypoint = "extract"
+
+// ErrScriptLimitExceeded is returned when a script exceeds one of its sandbox limits
+var ErrScriptLimitExceeded = errors.New("script limit exceeded")
+
+// Document is the fetched page handed to an extraction script
+type Document struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+	Headers     map[string]string
+	Body        string
+}
+
+// Script is a compiled, reusable extraction script
+type Script struct {
+	program        *starlark.Program
+	maxSteps       uint64
+	timeout        time.Duration
+	maxInputBytes  int
+	maxOutputBytes int
+	maxAllocBytes  int
+}
+
+// CompileScript validates and compiles an extraction script.
+// Scripts run without access to load(), the filesystem or the network;
+// only the document passed to extract(doc) is visible to them.
+func CompileScript(cfg *models.ScriptConfig) (*Script, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("script config is required")
+	}
+	if cfg.Language != ScriptLanguageStarlark {
+		return nil, fmt.Errorf("unsupported script language: %q", cfg.Language)
+	}
+	if cfg.Source == "" {
+		return nil, fmt.Errorf("script source is required")
+	}
+	if cfg.MaxSteps > maxScriptSteps {
+		return nil, fmt.Errorf("max_steps cannot exceed %d", maxScriptSteps)
+	}
+	if time.Duration(cfg.TimeoutMs)*time.Millisecond > maxScriptTimeout {
+		return nil, fmt.Errorf("timeout_ms cannot exceed %d", maxScriptTimeout.Milliseconds())
+	}
+	if cfg.MaxAllocBytes > maxScriptAllocBytes {
+		return nil, fmt.Errorf("max_alloc_bytes cannot exceed %d", maxScriptAllocBytes)
+	}
+
+	file, err := (&syntax.FileOptions{}).Parse("extract.star", cfg.Source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile script: %w", err)
+	}
+	for _, stmt := range file.Stmts {
+		if _, ok := stmt.(*syntax.LoadStmt); ok {
+			return nil, fmt.Errorf("scripts cannot use load()")
+		}
+	}
+	guardScript(file)
+	program, err := starlark.FileProgram(file, scriptPredeclared().Has)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile script: %w", err)
+	}
+
+	script := &Script{
+		program:        program,
+		maxSteps:       cfg.MaxSteps,
+		timeout:        time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		maxInputBytes:  cfg.MaxInputBytes,
+		maxOutputBytes: cfg.MaxOutputBytes,
+		maxAllocBytes:  cfg.MaxAllocBytes,
+	}
+	if script.maxSteps == 0 {
+		script.maxSteps = DefaultScriptMaxSteps
+	}
+	if script.timeout <= 0 {
+		script.timeout = DefaultScriptTimeout
+	}
+	if script.maxInputBytes <= 0 {
+		script.maxInputBytes = DefaultScriptMaxInputBytes
+	}
+	if script.maxOutputBytes <= 0 {
+		script.maxOutputBytes = DefaultScriptMaxOutputBytes
+	}
+	if script.maxAllocBytes <= 0 {
+		script.maxAllocBytes = DefaultScriptMaxAllocBytes
+	}
+
+	return script, nil
+}
+
+// Run executes the script against a document and returns the extracted fields
+func (s *Script) Run(ctx context.Context, doc Document) (map[string]interface{}, error) {
+	if len(doc.Body) > s.maxInputBytes {
+		return nil, fmt.Errorf("%w: document is %d bytes, limit is %d", ErrScriptLimitExceeded, len(doc.Body), s.maxInputBytes)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	thread := &starlark.Thread{
+		Name:  "extract",
+		Print: func(*starlark.Thread, string) {}, // scripts cannot write to service logs
+	}
+	thread.SetMaxExecutionSteps(s.maxSteps)
+	thread.SetLocal(allocatorKey, &allocator{limit: int64(s.maxAllocBytes)})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	globals, err := s.program.Init(thread, scriptPredeclared())
+	if err != nil {
+		return nil, s.runError(ctx, thread, err)
+	}
+
+	extract, ok := globals[scriptEntrypoint].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script must define an %s(doc) function", scriptEntrypoint)
+	}
+
+	result, err := starlark.Call(thread, extract, starlark.Tuple{documentValue(doc)}, nil)
+	if err != nil {
+		return nil, s.runError(ctx, thread, err)
+	}
+
+	dict, ok := result.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s must return a dict, got %s", scriptEntrypoint, result.Type())
+	}
+
+	conv := &converter{remaining: s.maxOutputBytes}
+	converted, err := conv.fromStarlark(dict, 0)
+	if err != nil {
+		return nil, err
+	}
+	fields := converted.(map[string]interface{})
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("script result is not serializable: %w", err)
+	}
+	if len(encoded) > s.maxOutputBytes {
+		return nil, fmt.Errorf("%w: result is %d bytes, limit is %d", ErrScriptLimitExceeded, len(encoded), s.maxOutputBytes)
+	}
+
+	return fields, nil
+}
+
+// runError distinguishes sandbox limit violations from script errors
+func (s *Script) runError(ctx context.Context, thread *starlark.Thread, err error) error {
+	if thread.ExecutionSteps() >= s.maxSteps {
+		return fmt.Errorf("%w: more than %d steps", ErrScriptLimitExceeded, s.maxSteps)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: ran longer than %s", ErrScriptLimitExceeded, s.timeout)
+	}
+	if errors.Is(err, ErrScriptLimitExceeded) {
+		return err
+	}
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return fmt.Errorf("script failed: %s", evalErr.Backtrace())
+	}
+	return fmt.Errorf("script failed: %w", err)
+}
+
+// documentValue exposes a Document to Starlark as a frozen dict
+func documentValue(doc Document) starlark.Value {
+	headers := starlark.NewDict(len(doc.Headers))
+	keys := make([]string, 0, len(doc.Headers))
+	for k := range doc.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		headers.SetKey(starlark.String(k), starlark.String(doc.Headers[k]))
+	}
+
+	d := starlark.NewDict(5)
+	d.SetKey(starlark.String("url"), starlark.String(doc.URL))
+	d.SetKey(starlark.String("status_code"), starlark.MakeInt(doc.StatusCode))
+	d.SetKey(starlark.String("content_type"), starlark.String(doc.ContentType))
+	d.SetKey(starlark.String("headers"), headers)
+	d.SetKey(starlark.String("body"), starlark.String(doc.Body))
+	d.Freeze()
+	return d
+}
+
+// converter turns a script result into plain Go values. Every value takes
+// at least one byte of JSON, so a result holding more values than the
+// output limit allows is cut short before it is walked to the end, as is
+// one nested deeper than maxScriptResultDepth: a list that contains itself
+// would otherwise recurse until the stack overflows.
+type converter struct {
+	remaining int
+}
+
+// fromStarlark converts a Starlark value at nesting depth into plain Go
+// values suitable for JSON
+func (c *converter) fromStarlark(v starlark.Value, depth int) (interface{}, error) {
+	if depth > maxScriptResultDepth {
+		return nil, fmt.Errorf("%w: result is nested deeper than %d levels", ErrScriptLimitExceeded, maxScriptResultDepth)
+	}
+	if c.remaining--; c.remaining < 0 {
+		return nil, fmt.Errorf("%w: result holds too many values", ErrScriptLimitExceeded)
+	}
+
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		return v.String(), nil
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.List:
+		out := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := c.fromStarlark(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, item)
+		}
+		return out, nil
+	case starlark.Tuple:
+		out := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			item, err := c.fromStarlark(elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, item)
+		}
+		return out, nil
+	case *starlark.Dict:
+		out := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+			value, err := c.fromStarlark(item[1], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out[string(key)] = value
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type in script result: %s", v.Type())
+	}
+}
@@ -0,0 +1,474 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Starlark has no allocation hooks, and a single operation such as
+// "a" * 1000000000 allocates a gigabyte in one step. Before compiling, the
+// operators and method calls that create values are rewritten into calls to
+// the guard builtins below, which check the size of the value they are about
+// to create against the script's allocation budget. The builtins are bound
+// to names that are not valid identifiers, so scripts cannot shadow them.
+const (
+	guardMethod     = "$method"
+	guardAugmentAdd = "$+="
+
+	// Estimated size of a reference held by a list, tuple or dict
+	valueSize = 16
+)
+
+// guardedOps are the binary operators whose result can outgrow their operands
+var guardedOps = map[syntax.Token]bool{
+	syntax.PLUS:    true,
+	syntax.STAR:    true,
+	syntax.PERCENT: true,
+	syntax.LTLT:    true,
+}
+
+// guardedBuiltins are the universe builtins that copy or render their
+// arguments; they are shadowed by versions that charge the budget
+var guardedBuiltins = []string{"dict", "enumerate", "list", "range", "repr", "sorted", "str", "tuple", "zip"}
+
+// allocatorKey is the thread-local key of a run's allocator
+const allocatorKey = "allocator"
+
+// allocator tracks the bytes a script run has allocated
+type allocator struct {
+	limit int64
+	used  int64
+}
+
+// charge records n more bytes, failing once the budget is spent
+func (a *allocator) charge(n int64) error {
+	if n < 0 || a.used+n > a.limit {
+		return fmt.Errorf("%w: allocated more than %d bytes", ErrScriptLimitExceeded, a.limit)
+	}
+	a.used += n
+	return nil
+}
+
+// remaining is the number of bytes left in the budget
+func (a *allocator) remaining() int64 {
+	return a.limit - a.used
+}
+
+func threadAllocator(thread *starlark.Thread) *allocator {
+	return thread.Local(allocatorKey).(*allocator)
+}
+
+// scriptPredeclared returns the guard builtins every script is compiled against
+func scriptPredeclared() starlark.StringDict {
+	predeclared := starlark.StringDict{
+		guardMethod:     starlark.NewBuiltin(guardMethod, guardedMethod),
+		guardAugmentAdd: starlark.NewBuiltin(guardAugmentAdd, guardedAugmentAdd),
+	}
+	for op := range guardedOps {
+		predeclared[op.String()] = starlark.NewBuiltin(op.String(), guardedBinary(op))
+	}
+	for _, name := range guardedBuiltins {
+		predeclared[name] = starlark.NewBuiltin(name, guardedBuiltin(starlark.Universe[name].(*starlark.Builtin)))
+	}
+	return predeclared
+}
+
+// guardedBinary evaluates x op y once its result fits the budget
+func guardedBinary(op syntax.Token) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+		x, y := args[0], args[1]
+		alloc := threadAllocator(thread)
+		if err := alloc.charge(binarySize(op, x, y, alloc.remaining())); err != nil {
+			return nil, err
+		}
+		return starlark.Binary(op, x, y)
+	}
+}
+
+// guardedAugmentAdd implements x += y, which extends lists in place
+func guardedAugmentAdd(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	x, y := args[0], args[1]
+	list, ok := x.(*starlark.List)
+	if !ok {
+		return guardedBinary(syntax.PLUS)(thread, nil, args, nil)
+	}
+	if err := threadAllocator(thread).charge(valueSize * int64(max(starlark.Len(y), 0))); err != nil {
+		return nil, err
+	}
+	iter := starlark.Iterate(y)
+	if iter == nil {
+		return nil, fmt.Errorf("unknown binary op: list += %s", y.Type())
+	}
+	defer iter.Done()
+	var elem starlark.Value
+	for iter.Next(&elem) {
+		if err := list.Append(elem); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// guardedMethod calls recv.name(args...), checking the size of the methods
+// that grow a value beforehand and charging the values they create
+func guardedMethod(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	recv, name := args[0], string(args[1].(starlark.String))
+	var method starlark.Value
+	if x, ok := recv.(starlark.HasAttrs); ok {
+		attr, err := x.Attr(name)
+		if err != nil {
+			return nil, err
+		}
+		method = attr
+	}
+	if method == nil {
+		return nil, fmt.Errorf("%s has no .%s field or method", recv.Type(), name)
+	}
+	args = args[2:]
+
+	alloc := threadAllocator(thread)
+	if err := alloc.charge(methodSize(recv, name, args, kwargs, alloc.remaining())); err != nil {
+		return nil, err
+	}
+	result, err := starlark.Call(thread, method, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	switch recv.(type) {
+	case starlark.String, starlark.Bytes, *starlark.Dict:
+		// Their methods return new values; list methods return existing ones
+		if err := alloc.charge(shallowSize(result)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// guardedBuiltin wraps a universe builtin, checking the size of the value it
+// creates from its arguments beforehand
+func guardedBuiltin(builtin *starlark.Builtin) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		alloc := threadAllocator(thread)
+		switch builtin.Name() {
+		case "str", "repr":
+			for _, arg := range args {
+				if err := alloc.charge(deepSize(arg, alloc.remaining())); err != nil {
+					return nil, err
+				}
+			}
+		case "range":
+			// A range is lazy, but list(range(n)) is not
+		default:
+			for _, arg := range args {
+				if n := starlark.Len(arg); n > 0 {
+					if err := alloc.charge(valueSize * int64(n)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		result, err := starlark.Call(thread, builtin, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := result.(starlark.Sequence); ok && builtin.Name() == "range" && int64(r.Len()) > alloc.limit/valueSize {
+			return nil, fmt.Errorf("%w: range of %d elements", ErrScriptLimitExceeded, r.Len())
+		}
+		return result, nil
+	}
+}
+
+// binarySize estimates the bytes allocated by x op y
+func binarySize(op syntax.Token, x, y starlark.Value, limit int64) int64 {
+	switch op {
+	case syntax.STAR:
+		n, xInt := x.(starlark.Int)
+		m, yInt := y.(starlark.Int)
+		switch {
+		case xInt && yInt:
+			return int64(n.BigInt().BitLen()+m.BigInt().BitLen()) / 8
+		case yInt:
+			return repeatSize(x, m)
+		case xInt:
+			return repeatSize(y, n)
+		}
+	case syntax.LTLT:
+		if x, ok := x.(starlark.Int); ok {
+			return int64(x.BigInt().BitLen())/8 + 64
+		}
+	case syntax.PERCENT:
+		if format, ok := x.(starlark.String); ok {
+			if _, ok := y.(*starlark.Dict); ok {
+				// Named conversions may render the same value repeatedly
+				return int64(len(format)) + int64(strings.Count(string(format), "%"))*deepSize(y, limit)
+			}
+			return int64(len(format)) + deepSize(y, limit)
+		}
+	case syntax.PLUS:
+		return shallowSize(x) + shallowSize(y)
+	}
+	return 0
+}
+
+// repeatSize is the size of x repeated n times, or -1 when it overflows
+func repeatSize(x starlark.Value, n starlark.Int) int64 {
+	count, ok := n.Int64()
+	if !ok {
+		return -1
+	}
+	size := shallowSize(x)
+	if count <= 0 || size == 0 {
+		return 0
+	}
+	if size > (1<<62)/count {
+		return -1
+	}
+	return size * count
+}
+
+// methodSize estimates the bytes allocated by the string methods that can
+// return a value larger than their receiver, and by list.extend
+func methodSize(recv starlark.Value, name string, args starlark.Tuple, kwargs []starlark.Tuple, limit int64) int64 {
+	switch recv := recv.(type) {
+	case starlark.String:
+		switch name {
+		case "replace":
+			if len(args) < 2 {
+				return 0
+			}
+			old, _ := args[0].(starlark.String)
+			repl, _ := args[1].(starlark.String)
+			if grow := int64(len(repl) - len(old)); grow > 0 {
+				// An empty old string matches between every character
+				return int64(strings.Count(string(recv), string(old))) * grow
+			}
+		case "join":
+			if len(args) < 1 {
+				return 0
+			}
+			n := starlark.Len(args[0])
+			return int64(max(n-1, 0))*int64(len(recv)) + deepSize(args[0], limit)
+		case "format":
+			// Each field renders one argument, at worst the largest
+			var largest int64
+			for _, arg := range args {
+				largest = max(largest, deepSize(arg, limit))
+			}
+			for _, kwarg := range kwargs {
+				largest = max(largest, deepSize(kwarg[1], limit))
+			}
+			return int64(len(recv)) + int64(strings.Count(string(recv), "{"))*largest
+		}
+	case *starlark.List:
+		if name == "extend" && len(args) == 1 {
+			return valueSize * int64(max(starlark.Len(args[0]), 0))
+		}
+	}
+	return 0
+}
+
+// shallowSize estimates the bytes of a value, counting references for
+// containers but not the values they refer to
+func shallowSize(v starlark.Value) int64 {
+	switch v := v.(type) {
+	case starlark.String:
+		return int64(len(v))
+	case starlark.Bytes:
+		return int64(len(v))
+	case starlark.Int:
+		return int64(v.BigInt().BitLen() / 8)
+	}
+	if n := starlark.Len(v); n > 0 {
+		return valueSize * int64(n)
+	}
+	return 0
+}
+
+// deepSize estimates the bytes of a value rendered as a string, giving up
+// once it exceeds limit; a list referring to one string a thousand times
+// renders it a thousand times
+func deepSize(v starlark.Value, limit int64) int64 {
+	var size int64
+	var visit func(v starlark.Value, depth int)
+	visit = func(v starlark.Value, depth int) {
+		if size > limit || depth > 64 {
+			return
+		}
+		switch v := v.(type) {
+		case starlark.String:
+			size += int64(len(v)) + 2
+		case starlark.Bytes:
+			size += int64(len(v)) + 3
+		case *starlark.Dict:
+			for _, item := range v.Items() {
+				visit(item[0], depth+1)
+				visit(item[1], depth+1)
+			}
+		case starlark.Iterable:
+			if n := starlark.Len(v); n > 0 && int64(n) > limit/2 {
+				size += int64(n) * 2
+				return
+			}
+			iter := v.Iterate()
+			defer iter.Done()
+			var elem starlark.Value
+			for iter.Next(&elem) && size <= limit {
+				size += 2
+				visit(elem, depth+1)
+			}
+		default:
+			size += shallowSize(v) + 8
+		}
+	}
+	visit(v, 0)
+	return size
+}
+
+// guardScript rewrites a parsed script so that everything that creates values
+// goes through the guard builtins
+func guardScript(f *syntax.File) {
+	f.Stmts = guardStmts(f.Stmts)
+}
+
+func guardStmts(stmts []syntax.Stmt) []syntax.Stmt {
+	for _, stmt := range stmts {
+		guardStmt(stmt)
+	}
+	return stmts
+}
+
+func guardStmt(stmt syntax.Stmt) {
+	switch s := stmt.(type) {
+	case *syntax.ExprStmt:
+		s.X = guardExpr(s.X)
+	case *syntax.IfStmt:
+		s.Cond = guardExpr(s.Cond)
+		guardStmts(s.True)
+		guardStmts(s.False)
+	case *syntax.AssignStmt:
+		s.LHS, s.RHS = guardTarget(s.LHS), guardExpr(s.RHS)
+		if s.Op != syntax.EQ {
+			op := s.Op - syntax.PLUS_EQ + syntax.PLUS // x op= y is x = x op y
+			if guardedOps[op] {
+				name := op.String()
+				if op == syntax.PLUS {
+					name = guardAugmentAdd
+				}
+				s.RHS = guardCall(name, s.OpPos, s.LHS, s.RHS)
+				s.Op = syntax.EQ
+			}
+		}
+	case *syntax.DefStmt:
+		s.Params = guardExprs(s.Params)
+		guardStmts(s.Body)
+	case *syntax.ForStmt:
+		s.X = guardExpr(s.X)
+		s.Vars = guardTarget(s.Vars)
+		guardStmts(s.Body)
+	case *syntax.WhileStmt:
+		s.Cond = guardExpr(s.Cond)
+		guardStmts(s.Body)
+	case *syntax.ReturnStmt:
+		if s.Result != nil {
+			s.Result = guardExpr(s.Result)
+		}
+	}
+}
+
+// guardTarget guards the expressions evaluated while assigning to a target,
+// such as the index of x[i]
+func guardTarget(target syntax.Expr) syntax.Expr {
+	switch t := target.(type) {
+	case *syntax.IndexExpr:
+		t.X, t.Y = guardExpr(t.X), guardExpr(t.Y)
+	case *syntax.DotExpr:
+		t.X = guardExpr(t.X)
+	case *syntax.ParenExpr:
+		t.X = guardTarget(t.X)
+	case *syntax.ListExpr:
+		for i, elem := range t.List {
+			t.List[i] = guardTarget(elem)
+		}
+	case *syntax.TupleExpr:
+		for i, elem := range t.List {
+			t.List[i] = guardTarget(elem)
+		}
+	}
+	return target
+}
+
+func guardExprs(exprs []syntax.Expr) []syntax.Expr {
+	for i, expr := range exprs {
+		exprs[i] = guardExpr(expr)
+	}
+	return exprs
+}
+
+func guardExpr(expr syntax.Expr) syntax.Expr {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *syntax.BinaryExpr:
+		e.X, e.Y = guardExpr(e.X), guardExpr(e.Y)
+		if guardedOps[e.Op] {
+			return guardCall(e.Op.String(), e.OpPos, e.X, e.Y)
+		}
+	case *syntax.CallExpr:
+		e.Args = guardExprs(e.Args)
+		if dot, ok := e.Fn.(*syntax.DotExpr); ok {
+			name := &syntax.Literal{Token: syntax.STRING, TokenPos: dot.NamePos, Raw: dot.Name.Name, Value: dot.Name.Name}
+			e.Args = append([]syntax.Expr{guardExpr(dot.X), name}, e.Args...)
+			e.Fn = &syntax.Ident{NamePos: dot.NamePos, Name: guardMethod}
+		} else {
+			e.Fn = guardExpr(e.Fn)
+		}
+	case *syntax.UnaryExpr:
+		e.X = guardExpr(e.X)
+	case *syntax.ParenExpr:
+		e.X = guardExpr(e.X)
+	case *syntax.CondExpr:
+		e.Cond, e.True, e.False = guardExpr(e.Cond), guardExpr(e.True), guardExpr(e.False)
+	case *syntax.IndexExpr:
+		e.X, e.Y = guardExpr(e.X), guardExpr(e.Y)
+	case *syntax.SliceExpr:
+		e.X, e.Lo, e.Hi, e.Step = guardExpr(e.X), guardExpr(e.Lo), guardExpr(e.Hi), guardExpr(e.Step)
+	case *syntax.DotExpr:
+		e.X = guardExpr(e.X)
+	case *syntax.ListExpr:
+		e.List = guardExprs(e.List)
+	case *syntax.TupleExpr:
+		e.List = guardExprs(e.List)
+	case *syntax.DictExpr:
+		e.List = guardExprs(e.List)
+	case *syntax.DictEntry:
+		e.Key, e.Value = guardExpr(e.Key), guardExpr(e.Value)
+	case *syntax.Comprehension:
+		e.Body = guardExpr(e.Body)
+		for _, clause := range e.Clauses {
+			switch c := clause.(type) {
+			case *syntax.ForClause:
+				c.X = guardExpr(c.X)
+				c.Vars = guardTarget(c.Vars)
+			case *syntax.IfClause:
+				c.Cond = guardExpr(c.Cond)
+			}
+		}
+	case *syntax.LambdaExpr:
+		e.Params = guardExprs(e.Params)
+		e.Body = guardExpr(e.Body)
+	}
+	return expr
+}
+
+// guardCall builds a call of the named guard builtin
+func guardCall(name string, pos syntax.Position, args ...syntax.Expr) *syntax.CallExpr {
+	return &syntax.CallExpr{
+		Fn:     &syntax.Ident{NamePos: pos, Name: name},
+		Lparen: pos,
+		Args:   args,
+		Rparen: pos,
+	}
+}
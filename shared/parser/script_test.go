@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/models"
+)
+
+func TestScriptExtractsFields(t *testing.T) {
+	script, err := CompileScript(&models.ScriptConfig{
+		Language: ScriptLanguageStarlark,
+		Source: `
+def extract(doc):
+    lines = doc["body"].split("\n")
+    return {"first_line": lines[0], "line_count": len(lines), "status": doc["status_code"]}
+`,
+	})
+	if err != nil {
+		t.Fatalf("CompileScript() error = %v", err)
+	}
+
+	fields, err := script.Run(context.Background(), Document{StatusCode: 200, Body: "hello\nworld"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if fields["first_line"] != "hello" || fields["line_count"] != int64(2) || fields["status"] != int64(200) {
+		t.Errorf("unexpected fields: %#v", fields)
+	}
+}
+
+func TestCompileScriptRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *models.ScriptConfig
+	}{
+		{"nil config", nil},
+		{"unsupported language", &models.ScriptConfig{Language: "lua", Source: "x = 1"}},
+		{"empty source", &models.ScriptConfig{Language: ScriptLanguageStarlark}},
+		{"syntax error", &models.ScriptConfig{Language: ScriptLanguageStarlark, Source: "def extract(doc)\n  return {}"}},
+		{"load not allowed", &models.ScriptConfig{Language: ScriptLanguageStarlark, Source: `load("x.star", "y")`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CompileScript(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestScriptStepLimit(t *testing.T) {
+	script, err := CompileScript(&models.ScriptConfig{
+		Language: ScriptLanguageStarlark,
+		MaxSteps: 1000,
+		Source: `
+def extract(doc):
+    total = 0
+    for i in range(1000000):
+        total += i
+    return {"total": total}
+`,
+	})
+	if err != nil {
+		t.Fatalf("CompileScript() error = %v", err)
+	}
+
+	_, err = script.Run(context.Background(), Document{})
+	if !errors.Is(err, ErrScriptLimitExceeded) {
+		t.Fatalf("Run() error = %v, want ErrScriptLimitExceeded", err)
+	}
+}
+
+func TestScriptInputAndOutputLimits(t *testing.T) {
+	script, err := CompileScript(&models.ScriptConfig{
+		Language:       ScriptLanguageStarlark,
+		MaxInputBytes:  8,
+		MaxOutputBytes: 16,
+		Source:         `def extract(doc): return {"body": doc["body"] * 10}`,
+	})
+	if err != nil {
+		t.Fatalf("CompileScript() error = %v", err)
+	}
+
+	if _, err := script.Run(context.Background(), Document{Body: "too large body"}); !errors.Is(err, ErrScriptLimitExceeded) {
+		t.Errorf("oversized input: error = %v, want ErrScriptLimitExceeded", err)
+	}
+	if _, err := script.Run(context.Background(), Document{Body: "abc"}); !errors.Is(err, ErrScriptLimitExceeded) {
+		t.Errorf("oversized output: error = %v, want ErrScriptLimitExceeded", err)
+	}
+}
+
+func TestScriptAllocationLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"repeat", `def extract(doc): return {"n": len("a" * 1000000000)}`},
+		{"doubling", `
+def extract(doc):
+    s = doc["body"]
+    for i in range(40):
+        s += s
+    return {"n": len(s)}
+`},
+		{"list of range", `def extract(doc): return {"n": len(list(range(100000000)))}`},
+		{"join of repeated references", `def extract(doc): return {"n": len("".join([doc["body"] * 1000] * 100000))}`},
+		{"replace", `def extract(doc): return {"n": len((doc["body"] * 100000).replace("b", "b" * 1000))}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := CompileScript(&models.ScriptConfig{Language: ScriptLanguageStarlark, MaxAllocBytes: 1 << 20, Source: tt.source})
+			if err != nil {
+				t.Fatalf("CompileScript() error = %v", err)
+			}
+			if _, err := script.Run(context.Background(), Document{Body: "abc"}); !errors.Is(err, ErrScriptLimitExceeded) {
+				t.Errorf("Run() error = %v, want ErrScriptLimitExceeded", err)
+			}
+		})
+	}
+
+	script, err := CompileScript(&models.ScriptConfig{
+		Language:      ScriptLanguageStarlark,
+		MaxAllocBytes: 1 << 20,
+		Source: `
+def extract(doc):
+    items = [1]
+    alias = items
+    items += [2, 3]
+    return {"items": alias, "upper": doc["body"].upper(), "label": "%s-%d" % ("n", len(items))}
+`,
+	})
+	if err != nil {
+		t.Fatalf("CompileScript() error = %v", err)
+	}
+	fields, err := script.Run(context.Background(), Document{Body: "abc"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(fields["items"].([]interface{})) != 3 || fields["upper"] != "ABC" || fields["label"] != "n-3" {
+		t.Errorf("unexpected fields: %#v", fields)
+	}
+}
+
+func TestScriptResultLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"cyclic list", `
+def extract(doc):
+    l = []
+    l.append(l)
+    return {"x": l}
+`},
+		{"cyclic dict", `
+def extract(doc):
+    d = {}
+    d["self"] = d
+    return d
+`},
+		{"shared references", `
+def extract(doc):
+    l = [1]
+    for i in range(40):
+        l = [l, l]
+    return {"x": l}
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := CompileScript(&models.ScriptConfig{Language: ScriptLanguageStarlark, Source: tt.source})
+			if err != nil {
+				t.Fatalf("CompileScript() error = %v", err)
+			}
+			if _, err := script.Run(context.Background(), Document{}); !errors.Is(err, ErrScriptLimitExceeded) {
+				t.Errorf("Run() error = %v, want ErrScriptLimitExceeded", err)
+			}
+		})
+	}
+}
+
+func TestScriptTimeout(t *testing.T) {
+	script, err := CompileScript(&models.ScriptConfig{
+		Language:  ScriptLanguageStarlark,
+		MaxSteps:  maxScriptSteps,
+		TimeoutMs: 1,
+		Source: `
+def extract(doc):
+    total = 0
+    for i in range(1000):
+        for j in range(1000):
+            total += j
+    return {"total": total}
+`,
+	})
+	if err != nil {
+		t.Fatalf("CompileScript() error = %v", err)
+	}
+
+	if _, err := script.Run(context.Background(), Document{}); !errors.Is(err, ErrScriptLimitExceeded) || !strings.Contains(err.Error(), "ran longer than") {
+		t.Errorf("Run() error = %v, want ErrScriptLimitExceeded for the timeout", err)
+	}
+}
@@ -0,0 +1,344 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector is a compiled CSS selector. It supports the subset used by parser
+// configs: type, universal, #id, .class and attribute selectors ([attr],
+// [attr=value], [attr~=value], [attr^=value], [attr$=value], [attr*=value]),
+// compounds of those, descendant and child (>) combinators, and selector
+// groups separated by commas.
+type Selector struct {
+	groups [][]selectorStep
+}
+
+// selectorStep is a compound selector and the combinator joining it to the previous step
+type selectorStep struct {
+	child   bool // Joined with ">" rather than whitespace
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrMatcher
+}
+
+// attrMatcher matches an attribute selector
+type attrMatcher struct {
+	name  string
+	op    string // "" for presence, or =, ~=, ^=, $=, *=
+	value string
+}
+
+// CompileSelector parses a CSS selector
+func CompileSelector(selector string) (*Selector, error) {
+	compiled := &Selector{}
+	for _, group := range splitSelectorGroups(selector) {
+		steps, err := parseSelectorGroup(group)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		compiled.groups = append(compiled.groups, steps)
+	}
+	if len(compiled.groups) == 0 {
+		return nil, fmt.Errorf("invalid selector %q: empty selector", selector)
+	}
+	return compiled, nil
+}
+
+// MatchAny reports whether any element below root matches the selector
+func (s *Selector) MatchAny(root *html.Node) bool {
+	return s.First(root) != nil
+}
+
+// First returns the first element below root, in document order, that
+// matches the selector, or nil if none does
+func (s *Selector) First(root *html.Node) *html.Node {
+	var found *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && found == nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && s.matches(c) {
+				found = c
+				return
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// matches reports whether the element matches any selector group
+func (s *Selector) matches(n *html.Node) bool {
+	for _, steps := range s.groups {
+		if matchSteps(n, steps) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSteps matches the last step against n and the earlier steps against its ancestors
+func matchSteps(n *html.Node, steps []selectorStep) bool {
+	last := steps[len(steps)-1]
+	if !last.matches(n) {
+		return false
+	}
+	if len(steps) == 1 {
+		return true
+	}
+	for parent := n.Parent; parent != nil && parent.Type == html.ElementNode; parent = parent.Parent {
+		if matchSteps(parent, steps[:len(steps)-1]) {
+			return true
+		}
+		if last.child {
+			return false
+		}
+	}
+	return false
+}
+
+// matches reports whether the element matches the compound selector
+func (step selectorStep) matches(n *html.Node) bool {
+	if step.tag != "" && step.tag != "*" && !strings.EqualFold(n.Data, step.tag) {
+		return false
+	}
+	if step.id != "" && attrValue(n, "id") != step.id {
+		return false
+	}
+	for _, class := range step.classes {
+		if !containsWord(attrValue(n, "class"), class) {
+			return false
+		}
+	}
+	for _, attr := range step.attrs {
+		if !attr.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the element's attribute satisfies the matcher
+func (m attrMatcher) matches(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if !strings.EqualFold(a.Key, m.name) {
+			continue
+		}
+		switch m.op {
+		case "":
+			return true
+		case "=":
+			return a.Val == m.value
+		case "~=":
+			return containsWord(a.Val, m.value)
+		case "^=":
+			return m.value != "" && strings.HasPrefix(a.Val, m.value)
+		case "$=":
+			return m.value != "" && strings.HasSuffix(a.Val, m.value)
+		case "*=":
+			return m.value != "" && strings.Contains(a.Val, m.value)
+		}
+	}
+	return false
+}
+
+// attrValue returns the value of an attribute, or "" if the element has none
+func attrValue(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// containsWord reports whether the whitespace-separated list contains word
+func containsWord(list, word string) bool {
+	for _, w := range strings.Fields(list) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSelectorGroups splits a selector list at commas outside attribute selectors
+func splitSelectorGroups(selector string) []string {
+	var groups []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range selector {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			groups = append(groups, selector[start:i])
+			start = i + 1
+		}
+	}
+	groups = append(groups, selector[start:])
+
+	var nonEmpty []string
+	for _, group := range groups {
+		if strings.TrimSpace(group) != "" {
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+	if len(nonEmpty) != len(groups) {
+		return nil
+	}
+	return nonEmpty
+}
+
+// parseSelectorGroup parses a complex selector into its steps
+func parseSelectorGroup(group string) ([]selectorStep, error) {
+	var steps []selectorStep
+	input := strings.TrimSpace(group)
+	child := false
+	for input != "" {
+		if input[0] == '>' {
+			if child || len(steps) == 0 {
+				return nil, fmt.Errorf("unexpected >")
+			}
+			child = true
+			input = strings.TrimSpace(input[1:])
+			continue
+		}
+
+		step, rest, err := parseCompound(input)
+		if err != nil {
+			return nil, err
+		}
+		step.child = child
+		steps = append(steps, step)
+		child = false
+		input = strings.TrimSpace(rest)
+	}
+	if child || len(steps) == 0 {
+		return nil, fmt.Errorf("incomplete selector")
+	}
+	return steps, nil
+}
+
+// parseCompound parses a compound selector at the start of input and returns the remaining input
+func parseCompound(input string) (selectorStep, string, error) {
+	var step selectorStep
+	i := 0
+	if input[0] == '*' {
+		step.tag = "*"
+		i = 1
+	} else {
+		step.tag, i = readIdent(input, 0)
+	}
+
+	for i < len(input) {
+		switch input[i] {
+		case '#':
+			step.id, i = readIdent(input, i+1)
+			if step.id == "" {
+				return step, "", fmt.Errorf("empty id")
+			}
+		case '.':
+			var class string
+			class, i = readIdent(input, i+1)
+			if class == "" {
+				return step, "", fmt.Errorf("empty class")
+			}
+			step.classes = append(step.classes, class)
+		case '[':
+			end := closingBracket(input, i)
+			if end < 0 {
+				return step, "", fmt.Errorf("unterminated attribute selector")
+			}
+			attr, err := parseAttr(input[i+1 : end])
+			if err != nil {
+				return step, "", err
+			}
+			step.attrs = append(step.attrs, attr)
+			i = end + 1
+		case ' ', '\t', '\n', '>':
+			return step, input[i:], nil
+		default:
+			return step, "", fmt.Errorf("unsupported syntax at %q", input[i:])
+		}
+	}
+	if i == 0 {
+		return step, "", fmt.Errorf("unsupported syntax at %q", input)
+	}
+	return step, "", nil
+}
+
+// parseAttr parses the inside of an attribute selector
+func parseAttr(inside string) (attrMatcher, error) {
+	name, i := readIdent(inside, 0)
+	if name == "" {
+		return attrMatcher{}, fmt.Errorf("empty attribute name")
+	}
+	rest := strings.TrimSpace(inside[i:])
+	if rest == "" {
+		return attrMatcher{name: name}, nil
+	}
+
+	m := attrMatcher{name: name}
+	for _, op := range []string{"~=", "^=", "$=", "*=", "="} {
+		if strings.HasPrefix(rest, op) {
+			m.op = op
+			rest = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if m.op == "" {
+		return attrMatcher{}, fmt.Errorf("unsupported attribute operator in [%s]", inside)
+	}
+	if len(rest) >= 2 && (rest[0] == '\'' || rest[0] == '"') && rest[len(rest)-1] == rest[0] {
+		rest = rest[1 : len(rest)-1]
+	} else if strings.ContainsAny(rest, " '\"") {
+		return attrMatcher{}, fmt.Errorf("invalid attribute value in [%s]", inside)
+	}
+	m.value = rest
+	return m, nil
+}
+
+// readIdent reads a CSS identifier starting at i
+func readIdent(input string, i int) (string, int) {
+	start := i
+	for i < len(input) {
+		c := input[i]
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80 {
+			i++
+			continue
+		}
+		break
+	}
+	return input[start:i], i
+}
+
+// closingBracket returns the index of the ] closing the attribute selector opened at open
+func closingBracket(input string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(input); i++ {
+		switch c := input[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
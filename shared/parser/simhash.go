@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// simhashShingle is the number of consecutive words hashed together as one
+// feature of a text
+const simhashShingle = 3
+
+// MaxSimhashDistance bounds the distance GroupSimilar accepts: it splits
+// hashes into one band more than the distance, and shorter bands put more
+// unrelated hashes in the same bucket
+const MaxSimhashDistance = 8
+
+// Simhash returns the 64-bit simhash of a text, computed over its lowercased
+// three-word shingles. Texts sharing most of their shingles have hashes that
+// differ in few bits (see SimhashDistance), so near-duplicates, such as the
+// same article with a different footer, are found by comparing hashes.
+// A text without words hashes to 0.
+func Simhash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	shingles := max(len(words)-simhashShingle+1, 1)
+	for i := 0; i < shingles; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+simhashShingle, len(words))], " ")))
+		feature := h.Sum64()
+		for bit := range weights {
+			if feature&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
+
+// SimhashDistance returns the number of bits two simhashes differ in
+func SimhashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// GroupSimilar groups the indexes of hashes within maxDistance bits of each
+// other, transitively, and returns the groups of two or more in the order of
+// their first index. Only hashes sharing one of maxDistance+1 bands of bits
+// are compared, which by the pigeonhole principle finds every such pair.
+// maxDistance is clamped to [0, MaxSimhashDistance].
+func GroupSimilar(hashes []uint64, maxDistance int) [][]int {
+	maxDistance = min(max(maxDistance, 0), MaxSimhashDistance)
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bands := maxDistance + 1
+	for band := 0; band < bands; band++ {
+		start, end := band*64/bands, (band+1)*64/bands
+		mask := uint64(1)<<(end-start) - 1
+		buckets := make(map[uint64][]int)
+		for i, hash := range hashes {
+			key := hash >> start & mask
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					i, j := bucket[x], bucket[y]
+					if SimhashDistance(hashes[i], hashes[j]) <= maxDistance {
+						if a, b := find(i), find(j); a != b {
+							parent[max(a, b)] = min(a, b)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range hashes {
+		root := find(i)
+		if len(members[root]) == 0 {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+	var groups [][]int
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
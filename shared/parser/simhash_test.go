@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const article = `The central bank kept interest rates unchanged on Thursday, citing
+steady inflation and a resilient labour market. Markets had widely expected
+the decision, and bond yields barely moved after the announcement. The next
+meeting is scheduled for April, when new economic projections are due.`
+
+func TestSimhash(t *testing.T) {
+	same := Simhash(strings.ToUpper(article) + "\n\n")
+	mirrored := Simhash(article + " Copyright Daily News.")
+	unrelated := Simhash("Steel kettle with a 1.7 litre capacity, boils water in under three minutes and switches off automatically.")
+
+	if d := SimhashDistance(Simhash(article), same); d != 0 {
+		t.Errorf("distance to the same words = %d, want 0", d)
+	}
+	if d := SimhashDistance(Simhash(article), mirrored); d > 6 {
+		t.Errorf("distance to a near-duplicate = %d, want at most 6", d)
+	}
+	if d := SimhashDistance(Simhash(article), unrelated); d < 12 {
+		t.Errorf("distance to unrelated text = %d, want at least 12", d)
+	}
+	if Simhash(" \n…") != 0 {
+		t.Error("Simhash() of a text without words is not 0")
+	}
+}
+
+func TestGroupSimilar(t *testing.T) {
+	hashes := []uint64{
+		0xFFFF000000000000,
+		0x0F0F0F0F0F0F0F0F,
+		0xFFFF000000000007, // 3 bits from 0
+		0x0F0F0F0F0F0F0F0E, // 1 bit from 1
+		0x0000FFFF0000FFFF,
+		0xFFFF00000000003F, // 3 bits from 2, 6 from 0
+	}
+	tests := []struct {
+		maxDistance int
+		want        [][]int
+	}{
+		{0, nil},
+		{1, [][]int{{1, 3}}},
+		{3, [][]int{{0, 2, 5}, {1, 3}}},
+	}
+	for _, tt := range tests {
+		if got := GroupSimilar(hashes, tt.maxDistance); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GroupSimilar(%d) = %v, want %v", tt.maxDistance, got, tt.want)
+		}
+	}
+}
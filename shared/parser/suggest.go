@@ -0,0 +1,317 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go_scraping_project/shared/models"
+
+	"golang.org/x/net/html"
+)
+
+// Heuristics a Suggestion comes from
+const (
+	SuggestionSourceOpenGraph = "opengraph"  // OpenGraph and article/product meta properties
+	SuggestionSourceSchemaOrg = "schema.org" // schema.org microdata (itemprop)
+	SuggestionSourceMeta      = "meta"       // Standard meta elements and the document title
+	SuggestionSourceHeading   = "heading"    // Heading structure
+	SuggestionSourceSemantic  = "semantic"   // Semantic HTML elements, such as time and article
+	SuggestionSourceClass     = "class"      // Conventional class names, such as price and byline
+	SuggestionSourceDensity   = "density"    // The element holding most of the page's paragraphs
+)
+
+// SuggestionSampleLength is the number of characters of a Suggestion's sample
+const SuggestionSampleLength = 200
+
+// Suggestion is a candidate selector for a common field of a page, see
+// Suggest. Field names match those of the built-in templates.
+type Suggestion struct {
+	Field      string  `json:"field"`          // title, published, price, content, author, image or description
+	Selector   string  `json:"selector"`       // CSS selector
+	Type       string  `json:"type"`           // RuleTypeText, or RuleTypeAttr to read Attr
+	Attr       string  `json:"attr,omitempty"` // Attribute holding the value of attr suggestions
+	Source     string  `json:"source"`         // Heuristic the suggestion comes from, see SuggestionSourceOpenGraph
+	Confidence float64 `json:"confidence"`     // From 0 to 1; suggestions of a field are sorted by it
+	Sample     string  `json:"sample"`         // Value extracted from the page, shortened to SuggestionSampleLength
+}
+
+// suggestionCandidate is a selector tried for a field. Candidates matching
+// an element with a value become suggestions.
+type suggestionCandidate struct {
+	field      string
+	selector   string
+	source     string
+	confidence float64
+}
+
+// suggestionCandidates are the fixed selectors Suggest tries, besides those
+// it derives from the page's headings, class names and paragraphs
+var suggestionCandidates = []suggestionCandidate{
+	{"title", "meta[property='og:title']", SuggestionSourceOpenGraph, 0.9},
+	{"title", "[itemprop='headline']", SuggestionSourceSchemaOrg, 0.85},
+	{"title", "[itemprop='name']", SuggestionSourceSchemaOrg, 0.7},
+	{"title", "title", SuggestionSourceMeta, 0.4},
+	{"published", "meta[property='article:published_time']", SuggestionSourceOpenGraph, 0.9},
+	{"published", "[itemprop='datePublished']", SuggestionSourceSchemaOrg, 0.85},
+	{"published", "time[datetime]", SuggestionSourceSemantic, 0.7},
+	{"price", "meta[property='product:price:amount']", SuggestionSourceOpenGraph, 0.9},
+	{"price", "[itemprop='price']", SuggestionSourceSchemaOrg, 0.85},
+	{"content", "[itemprop='articleBody']", SuggestionSourceSchemaOrg, 0.85},
+	{"content", "article", SuggestionSourceSemantic, 0.7},
+	{"content", "main", SuggestionSourceSemantic, 0.6},
+	{"author", "meta[name='author']", SuggestionSourceMeta, 0.8},
+	{"author", "[itemprop='author']", SuggestionSourceSchemaOrg, 0.8},
+	{"author", "[rel='author']", SuggestionSourceSemantic, 0.7},
+	{"image", "meta[property='og:image']", SuggestionSourceOpenGraph, 0.9},
+	{"image", "[itemprop='image']", SuggestionSourceSchemaOrg, 0.8},
+	{"description", "meta[property='og:description']", SuggestionSourceOpenGraph, 0.9},
+	{"description", "meta[name='description']", SuggestionSourceMeta, 0.8},
+	{"description", "[itemprop='description']", SuggestionSourceSchemaOrg, 0.8},
+}
+
+// suggestionClasses are the class name fragments of fields that pages
+// commonly mark with a class
+var suggestionClasses = []struct {
+	field     string
+	fragments []string
+	digits    bool // The value must contain a digit
+}{
+	{"price", []string{"price"}, true},
+	{"author", []string{"author", "byline"}, false},
+	{"published", []string{"date", "published"}, true},
+}
+
+// cssIdentifier matches class names and IDs usable in a selector as is
+var cssIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// minDenseParagraphs is the number of paragraphs an element needs for the
+// density heuristic to suggest it as the content
+const minDenseParagraphs = 3
+
+// Suggest proposes selectors for the common fields of a page (title, date,
+// price, main content, author, image and description), as a starting point
+// for a parser config. It tries OpenGraph properties, schema.org microdata,
+// the heading structure, semantic elements, conventional class names and the
+// element holding most of the paragraphs, keeping the selectors that extract
+// a value from the page. Suggestions are grouped by field, best first.
+// JSON-LD is not selectable and is left to scripts.
+func Suggest(doc Document) ([]Suggestion, error) {
+	root, err := html.Parse(strings.NewReader(doc.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	candidates := append([]suggestionCandidate{}, suggestionCandidates...)
+	candidates = append(candidates, headingCandidates(root)...)
+	candidates = append(candidates, classCandidates(root)...)
+	if selector, ok := denseContentSelector(root); ok {
+		candidates = append(candidates, suggestionCandidate{"content", selector, SuggestionSourceDensity, 0.5})
+	}
+
+	var suggestions []Suggestion
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		key := candidate.field + "\x00" + candidate.selector
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if suggestion, ok := evaluateCandidate(root, candidate); ok {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	order := make(map[string]int)
+	for _, candidate := range suggestionCandidates {
+		if _, ok := order[candidate.field]; !ok {
+			order[candidate.field] = len(order)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Field != b.Field {
+			return order[a.Field] < order[b.Field]
+		}
+		return a.Confidence > b.Confidence
+	})
+	return suggestions, nil
+}
+
+// SuggestedConfig builds a parser config from the best suggestion of each
+// field: text suggestions become selectors, attr suggestions rules
+func SuggestedConfig(suggestions []Suggestion) *models.ParserConfig {
+	cfg := &models.ParserConfig{Version: models.ParserConfigVersion, Selectors: map[string]string{}}
+	best := make(map[string]bool)
+	for _, suggestion := range suggestions {
+		if best[suggestion.Field] {
+			continue
+		}
+		best[suggestion.Field] = true
+		if suggestion.Type == RuleTypeAttr {
+			cfg.Rules = append(cfg.Rules, models.ParseRule{Name: suggestion.Field, Selector: suggestion.Selector, Type: RuleTypeAttr, Attr: suggestion.Attr})
+		} else {
+			cfg.Selectors[suggestion.Field] = suggestion.Selector
+		}
+	}
+	return cfg
+}
+
+// evaluateCandidate extracts the candidate's field from the page. Values of
+// elements that keep them in an attribute, such as time and img, are read
+// from the attribute.
+func evaluateCandidate(root *html.Node, candidate suggestionCandidate) (Suggestion, bool) {
+	selector, err := CompileSelector(candidate.selector)
+	if err != nil {
+		return Suggestion{}, false
+	}
+	n := selector.First(root)
+	if n == nil {
+		return Suggestion{}, false
+	}
+
+	suggestion := Suggestion{
+		Field:      candidate.field,
+		Selector:   candidate.selector,
+		Type:       RuleTypeText,
+		Source:     candidate.source,
+		Confidence: candidate.confidence,
+	}
+	switch {
+	case strings.EqualFold(n.Data, "meta"):
+	case attrValue(n, "datetime") != "":
+		suggestion.Type, suggestion.Attr = RuleTypeAttr, "datetime"
+	case strings.EqualFold(n.Data, "img"):
+		suggestion.Type, suggestion.Attr = RuleTypeAttr, "src"
+	case attrValue(n, "content") != "":
+		suggestion.Type, suggestion.Attr = RuleTypeAttr, "content"
+	}
+	value, _ := field{selector: selector, kind: suggestion.Type, attr: suggestion.Attr}.extract(root)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Suggestion{}, false
+	}
+	suggestion.Sample = shorten(value, SuggestionSampleLength)
+	return suggestion, true
+}
+
+// headingCandidates suggests the page's h1 as its title: with confidence
+// when it is the only one, else the first h1 with a class
+func headingCandidates(root *html.Node) []suggestionCandidate {
+	var headings []*html.Node
+	forEachElement(root, "h1", func(n *html.Node) {
+		if nodeText(n) != "" {
+			headings = append(headings, n)
+		}
+	})
+	switch {
+	case len(headings) == 1:
+		return []suggestionCandidate{{"title", "h1", SuggestionSourceHeading, 0.8}}
+	case len(headings) > 1:
+		for _, n := range headings {
+			if class := firstClass(n); class != "" {
+				return []suggestionCandidate{{"title", "h1." + class, SuggestionSourceHeading, 0.6}}
+			}
+		}
+		return []suggestionCandidate{{"title", "h1", SuggestionSourceHeading, 0.5}}
+	}
+	return nil
+}
+
+// classCandidates suggests the first element of each field whose class
+// names it by convention, e.g. class="product-price"
+func classCandidates(root *html.Node) []suggestionCandidate {
+	var candidates []suggestionCandidate
+	for _, convention := range suggestionClasses {
+		found := false
+		var walk func(n *html.Node)
+		walk = func(n *html.Node) {
+			for c := n.FirstChild; c != nil && !found; c = c.NextSibling {
+				if c.Type == html.ElementNode {
+					for _, class := range strings.Fields(attrValue(c, "class")) {
+						if !cssIdentifier.MatchString(class) || !containsAny(strings.ToLower(class), convention.fragments) {
+							continue
+						}
+						text := nodeText(c)
+						if text == "" || (convention.digits && !strings.ContainsAny(text, "0123456789")) {
+							continue
+						}
+						candidates = append(candidates, suggestionCandidate{convention.field, "." + class, SuggestionSourceClass, 0.6})
+						found = true
+						break
+					}
+				}
+				walk(c)
+			}
+		}
+		walk(root)
+	}
+	return candidates
+}
+
+// denseContentSelector returns a selector of the element with the most
+// paragraph children, if it has at least minDenseParagraphs and can be
+// selected by its ID or tag and class
+func denseContentSelector(root *html.Node) (string, bool) {
+	var best *html.Node
+	bestCount := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			count := 0
+			for p := c.FirstChild; p != nil; p = p.NextSibling {
+				if p.Type == html.ElementNode && strings.EqualFold(p.Data, "p") {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = c, count
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	if best == nil || bestCount < minDenseParagraphs {
+		return "", false
+	}
+	if id := attrValue(best, "id"); cssIdentifier.MatchString(id) {
+		return "#" + id, true
+	}
+	if class := firstClass(best); class != "" {
+		return strings.ToLower(best.Data) + "." + class, true
+	}
+	return "", false
+}
+
+// firstClass returns the first class of an element usable in a selector
+func firstClass(n *html.Node) string {
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		if cssIdentifier.MatchString(class) {
+			return class
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// shorten cuts s to at most n characters, marking the cut with an ellipsis
+func shorten(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Rates hold steady | Daily News</title>
+  <meta property="og:title" content="Rates hold steady">
+  <meta property="article:published_time" content="2024-03-01T08:00:00Z">
+</head>
+<body>
+  <h1 class="site-name">Daily News</h1>
+  <h1 class="headline">Rates hold steady</h1>
+  <span class="byline">By Dana Reyes</span>
+  <time datetime="2024-03-01T08:00:00Z">1 March</time>
+  <div id="story">
+    <p>The central bank kept rates unchanged.</p>
+    <p>Markets had expected the decision.</p>
+    <p>The next meeting is in April.</p>
+  </div>
+</body>
+</html>`
+
+// bestSuggestions returns the best suggestion of each field as
+// field=selector (type attr)
+func bestSuggestions(suggestions []Suggestion) []string {
+	var best []string
+	seen := make(map[string]bool)
+	for _, s := range suggestions {
+		if !seen[s.Field] {
+			seen[s.Field] = true
+			best = append(best, s.Field+"="+s.Selector+" ("+s.Type+" "+s.Attr+")")
+		}
+	}
+	return best
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []string
+	}{
+		{
+			name: "product",
+			page: productPage,
+			want: []string{
+				"title=h1 (text )",
+				"price=[itemprop='price'] (attr content)",
+				"image=meta[property='og:image'] (text )",
+				"description=meta[name='description'] (text )",
+			},
+		},
+		{
+			name: "article",
+			page: articlePage,
+			want: []string{
+				"title=meta[property='og:title'] (text )",
+				"published=meta[property='article:published_time'] (text )",
+				"content=#story (text )",
+				"author=.byline (text )",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, err := Suggest(Document{Body: tt.page})
+			if err != nil {
+				t.Fatalf("Suggest() error = %v", err)
+			}
+			if got := bestSuggestions(suggestions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("best suggestions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestAlternatives(t *testing.T) {
+	suggestions, err := Suggest(Document{Body: articlePage})
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	var titles, dates []Suggestion
+	for _, s := range suggestions {
+		switch s.Field {
+		case "title":
+			titles = append(titles, s)
+		case "published":
+			dates = append(dates, s)
+		}
+	}
+
+	// The first of several h1s is the site name; the one with a class wins
+	if len(titles) != 3 || titles[1].Selector != "h1.site-name" || titles[2].Sample != "Rates hold steady | Daily News" {
+		t.Errorf("title suggestions = %+v", titles)
+	}
+	if len(dates) != 2 || dates[1].Selector != "time[datetime]" || dates[1].Attr != "datetime" || dates[1].Sample != "2024-03-01T08:00:00Z" {
+		t.Errorf("date suggestions = %+v", dates)
+	}
+}
+
+func TestSuggestedConfig(t *testing.T) {
+	suggestions, err := Suggest(Document{Body: productPage})
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	cfg := SuggestedConfig(suggestions)
+	p, err := NewParser(cfg)
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	record, err := p.Parse(context.Background(), Document{Body: productPage})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if record.Title != "Steel kettle" || record.Data["price"] != "24.90" || record.Data["description"] != "A sturdy kettle" {
+		t.Errorf("record = %+v, want the page's title, price and description", record)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	if got := shorten("kettle", 6); got != "kettle" {
+		t.Errorf("shorten() = %q", got)
+	}
+	if got := shorten(strings.Repeat("é", 10), 5); got != "éééé…" {
+		t.Errorf("shorten() = %q, want 4 characters and an ellipsis", got)
+	}
+}
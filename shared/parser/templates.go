@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"sort"
+
+	"go_scraping_project/shared/models"
+)
+
+// Page types covered by the built-in templates
+const (
+	PageTypeArticle    = "article"
+	PageTypeProduct    = "product"
+	PageTypeJobListing = "job_listing"
+	PageTypeRSSItem    = "rss_item"
+)
+
+// Template is a named, reusable parser configuration for a common page type
+type Template struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	PageType    string              `json:"page_type"`
+	Config      models.ParserConfig `json:"config"`
+	BuiltIn     bool                `json:"built_in"`
+}
+
+// builtinTemplates holds the templates shipped with the system.
+// They are read-only and cannot be overridden by user-defined templates.
+var builtinTemplates = map[string]Template{
+	"generic-article": {
+		Name:        "generic-article",
+		Description: "News and blog articles with headline, byline, date and body",
+		PageType:    PageTypeArticle,
+		Config: models.ParserConfig{
+			Selectors: map[string]string{
+				"title":     "h1, article h1, meta[property='og:title']",
+				"author":    "[rel='author'], .author, .byline, meta[name='author']",
+				"published": "time[datetime], meta[property='article:published_time']",
+				"content":   "article, [itemprop='articleBody'], .post-content, .entry-content",
+				"image":     "meta[property='og:image']",
+			},
+			Rules: []models.ParseRule{
+				{Name: "published_at", Selector: "time[datetime]", Type: "attr", Attr: "datetime"},
+				{Name: "image_url", Selector: "meta[property='og:image']", Type: "attr", Attr: "content"},
+			},
+		},
+		BuiltIn: true,
+	},
+	"product-page": {
+		Name:        "product-page",
+		Description: "E-commerce product detail pages with price, availability and SKU",
+		PageType:    PageTypeProduct,
+		Config: models.ParserConfig{
+			Selectors: map[string]string{
+				"title":        "h1, [itemprop='name'], meta[property='og:title']",
+				"price":        "[itemprop='price'], .price, meta[property='product:price:amount']",
+				"currency":     "[itemprop='priceCurrency'], meta[property='product:price:currency']",
+				"availability": "[itemprop='availability'], .availability, .stock",
+				"sku":          "[itemprop='sku'], .sku",
+				"description":  "[itemprop='description'], .product-description",
+				"image":        "[itemprop='image'], meta[property='og:image']",
+			},
+			Rules: []models.ParseRule{
+				{Name: "price_amount", Selector: "[itemprop='price']", Type: "attr", Attr: "content"},
+				{Name: "image_url", Selector: "meta[property='og:image']", Type: "attr", Attr: "content"},
+			},
+		},
+		BuiltIn: true,
+	},
+	"job-listing": {
+		Name:        "job-listing",
+		Description: "Job postings with title, company, location and salary",
+		PageType:    PageTypeJobListing,
+		Config: models.ParserConfig{
+			Selectors: map[string]string{
+				"title":           "h1, [itemprop='title']",
+				"company":         "[itemprop='hiringOrganization'], .company, .employer",
+				"location":        "[itemprop='jobLocation'], .location",
+				"salary":          "[itemprop='baseSalary'], .salary",
+				"employment_type": "[itemprop='employmentType'], .employment-type",
+				"description":     "[itemprop='description'], .job-description",
+			},
+			Rules: []models.ParseRule{
+				{Name: "posted_at", Selector: "[itemprop='datePosted']", Type: "attr", Attr: "content"},
+			},
+		},
+		BuiltIn: true,
+	},
+	"rss-item": {
+		Name:        "rss-item",
+		Description: "Items of an RSS 2.0 or Atom feed",
+		PageType:    PageTypeRSSItem,
+		Config: models.ParserConfig{
+			Selectors: map[string]string{
+				"title":       "item > title, entry > title",
+				"link":        "item > link, entry > link",
+				"published":   "item > pubDate, entry > published, entry > updated",
+				"description": "item > description, entry > summary, entry > content",
+				"guid":        "item > guid, entry > id",
+			},
+			Rules: []models.ParseRule{
+				{Name: "link_href", Selector: "entry > link", Type: "attr", Attr: "href"},
+			},
+		},
+		BuiltIn: true,
+	},
+}
+
+// BuiltinTemplate returns the built-in template with the given name
+func BuiltinTemplate(name string) (Template, bool) {
+	tmpl, ok := builtinTemplates[name]
+	if !ok {
+		return Template{}, false
+	}
+	return tmpl.clone(), true
+}
+
+// BuiltinTemplates returns all built-in templates sorted by name
+func BuiltinTemplates() []Template {
+	templates := make([]Template, 0, len(builtinTemplates))
+	for _, tmpl := range builtinTemplates {
+		templates = append(templates, tmpl.clone())
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// IsBuiltin reports whether a template name is reserved by a built-in template
+func IsBuiltin(name string) bool {
+	_, ok := builtinTemplates[name]
+	return ok
+}
+
+// Apply merges a template into a URL's parser configuration.
+// Selectors and rules defined on the URL take precedence over those
+// inherited from the template, so a URL only needs to declare the
+// fields it wants to change.
+func Apply(tmpl Template, cfg *models.ParserConfig) *models.ParserConfig {
+	merged := tmpl.clone().Config
+	merged.Version = models.ParserConfigVersion
+	merged.Template = tmpl.Name
+	if merged.Selectors == nil {
+		merged.Selectors = make(map[string]string)
+	}
+	if cfg == nil {
+		return &merged
+	}
+
+	for field, selector := range cfg.Selectors {
+		merged.Selectors[field] = selector
+	}
+
+	overridden := make(map[string]bool, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		overridden[rule.Name] = true
+	}
+	rules := make([]models.ParseRule, 0, len(merged.Rules)+len(cfg.Rules))
+	for _, rule := range merged.Rules {
+		if !overridden[rule.Name] {
+			rules = append(rules, rule)
+		}
+	}
+	merged.Rules = append(rules, cfg.Rules...)
+	if cfg.Options != nil {
+		merged.Options = cfg.Options
+	}
+	merged.Script = cfg.Script
+	merged.Transform = cfg.Transform
+
+	return &merged
+}
+
+// clone returns a deep copy so callers cannot mutate the built-in definitions
+func (t Template) clone() Template {
+	cloned := t
+	if t.Config.Selectors != nil {
+		cloned.Config.Selectors = make(map[string]string, len(t.Config.Selectors))
+		for k, v := range t.Config.Selectors {
+			cloned.Config.Selectors[k] = v
+		}
+	}
+	if t.Config.Rules != nil {
+		cloned.Config.Rules = append([]models.ParseRule(nil), t.Config.Rules...)
+	}
+	if t.Config.Options != nil {
+		options := *t.Config.Options
+		cloned.Config.Options = &options
+	}
+	return cloned
+}
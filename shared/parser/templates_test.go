@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"testing"
+
+	"go_scraping_project/shared/models"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, name := range []string{"generic-article", "product-page", "job-listing", "rss-item"} {
+		tmpl, ok := BuiltinTemplate(name)
+		if !ok {
+			t.Fatalf("expected built-in template %q", name)
+		}
+		if !tmpl.BuiltIn || len(tmpl.Config.Selectors) == 0 {
+			t.Errorf("template %q is not a usable built-in", name)
+		}
+	}
+
+	if _, ok := BuiltinTemplate("does-not-exist"); ok {
+		t.Error("unexpected template for unknown name")
+	}
+}
+
+func TestApplyOverridesTemplate(t *testing.T) {
+	tmpl, _ := BuiltinTemplate("generic-article")
+	cfg := &models.ParserConfig{
+		Template:  "generic-article",
+		Selectors: map[string]string{"title": ".headline", "summary": ".dek"},
+		Rules: []models.ParseRule{
+			{Name: "published_at", Selector: ".date", Type: "text"},
+		},
+	}
+
+	merged := Apply(tmpl, cfg)
+
+	if merged.Selectors["title"] != ".headline" {
+		t.Errorf("title selector = %q, want URL override", merged.Selectors["title"])
+	}
+	if merged.Selectors["summary"] != ".dek" {
+		t.Errorf("summary selector not added")
+	}
+	if merged.Selectors["content"] == "" {
+		t.Errorf("content selector not inherited from template")
+	}
+
+	var published []models.ParseRule
+	for _, rule := range merged.Rules {
+		if rule.Name == "published_at" {
+			published = append(published, rule)
+		}
+	}
+	if len(published) != 1 || published[0].Selector != ".date" {
+		t.Errorf("published_at rule = %+v, want single URL override", published)
+	}
+
+	withScript := Apply(tmpl, &models.ParserConfig{Script: &models.ScriptConfig{Language: ScriptLanguageStarlark}})
+	if withScript.Script == nil {
+		t.Error("script not carried over from URL config")
+	}
+}
+
+func TestApplyDoesNotMutateBuiltin(t *testing.T) {
+	tmpl, _ := BuiltinTemplate("product-page")
+	Apply(tmpl, &models.ParserConfig{Selectors: map[string]string{"price": ".sale-price"}})
+
+	fresh, _ := BuiltinTemplate("product-page")
+	if fresh.Config.Selectors["price"] == ".sale-price" {
+		t.Fatal("Apply mutated the built-in template")
+	}
+}
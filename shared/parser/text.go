@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// invisibleElements are left out of normalized text
+var invisibleElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true, "canvas": true,
+}
+
+// blockElements start a line of normalized text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"details": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "option": true, "p": true, "pre": true, "section": true, "summary": true,
+	"table": true, "tr": true, "ul": true, "td": true, "th": true, "caption": true,
+}
+
+// NormalizedText returns the visible text of an HTML page in a form meant
+// for change detection and diffing: scripts, styles, the head and hidden
+// elements are left out, each block element starts a line, runs of
+// whitespace (including non-breaking spaces) collapse to a space and empty
+// lines are dropped. Pages that differ only in markup, such as attributes,
+// inline formatting or indentation, have the same normalized text.
+func NormalizedText(body string) (string, error) {
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("document is not valid HTML: %w", err)
+	}
+	return normalizedText(root), nil
+}
+
+// normalizedText returns the normalized text below root, see NormalizedText
+func normalizedText(root *html.Node) string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		if text := strings.Join(strings.Fields(line.String()), " "); text != "" {
+			lines = append(lines, text)
+		}
+		line.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			line.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			tag := strings.ToLower(n.Data)
+			if invisibleElements[tag] || hidden(n) {
+				return
+			}
+			if blockElements[tag] {
+				flush()
+				defer flush()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	flush()
+	return strings.Join(lines, "\n")
+}
+
+// hidden reports whether an element is hidden with the hidden attribute,
+// aria-hidden or an inline display:none
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch strings.ToLower(a.Key) {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if strings.EqualFold(a.Val, "true") {
+				return true
+			}
+		case "style":
+			style := strings.ToLower(strings.Join(strings.Fields(a.Val), ""))
+			if strings.Contains(style, "display:none") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TextChange is a line added or removed between two normalized texts, see
+// DiffText
+type TextChange struct {
+	Op   string `json:"op"`   // TextAdded or TextRemoved
+	Line int    `json:"line"` // 1-based line number in the text the line is in: the new text for added lines, the old one for removed lines
+	Text string `json:"text"`
+}
+
+// Operations of a TextChange
+const (
+	TextAdded   = "added"
+	TextRemoved = "removed"
+)
+
+// MaxDiffLines bounds the size of the texts DiffText compares, whose cost
+// grows with the product of their line counts
+const MaxDiffLines = 5000
+
+// DiffText returns the lines removed from old and added in new, in order, as
+// computed from their longest common subsequence of lines. Texts of more
+// than MaxDiffLines lines are compared on their first MaxDiffLines lines.
+func DiffText(old, new string) []TextChange {
+	a, b := splitLines(old), splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := []TextChange{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			changes = append(changes, TextChange{Op: TextRemoved, Line: i + 1, Text: a[i]})
+			i++
+		default:
+			changes = append(changes, TextChange{Op: TextAdded, Line: j + 1, Text: b[j]})
+			j++
+		}
+	}
+	return changes
+}
+
+// splitLines splits text into at most MaxDiffLines lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > MaxDiffLines {
+		lines = lines[:MaxDiffLines]
+	}
+	return lines
+}
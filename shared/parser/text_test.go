@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/models"
+)
+
+func TestNormalizedText(t *testing.T) {
+	got, err := NormalizedText(productPage)
+	if err != nil {
+		t.Fatalf("NormalizedText() error = %v", err)
+	}
+	if want := "Steel kettle\nBoils fast\n€24.90 More kettles"; got != want {
+		t.Errorf("NormalizedText() = %q, want %q", got, want)
+	}
+
+	// Attributes, inline formatting, indentation, scripts and hidden
+	// elements change the markup but not the text
+	restyled := `<html><head><title>Kettle</title><style>h1 { color: red }</style></head>
+<body class="v2">
+<h1 id="name">Steel&nbsp;kettle</h1>
+<div class="description"><p>Boils <em>fast</em></p><p hidden>Out of stock</p></div>
+<span>€24.90</span> <a href="/kettles?ref=nav" class="nav">More kettles</a>
+<div style="display: none">Sale!</div><span aria-hidden="true">★</span>
+<script>render()</script>
+</body></html>`
+	if text, _ := NormalizedText(restyled); text != got {
+		t.Errorf("NormalizedText() of a restyled page = %q, want %q", text, got)
+	}
+}
+
+func TestParseExtractText(t *testing.T) {
+	p, err := NewParser(&models.ParserConfig{Options: &models.ParseOptions{ExtractText: true}})
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+	record, err := p.Parse(context.Background(), Document{Body: productPage})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !strings.HasPrefix(record.Text, "Steel kettle\n") {
+		t.Errorf("text = %q, want the page's normalized text", record.Text)
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []TextChange
+	}{
+		{"same", "a\nb", "a\nb", []TextChange{}},
+		{"from empty", "", "a", []TextChange{{TextAdded, 1, "a"}}},
+		{"to empty", "a", "", []TextChange{{TextRemoved, 1, "a"}}},
+		{
+			name: "changed line",
+			old:  "Steel kettle\nPrice: 24.90\nIn stock",
+			new:  "Steel kettle\nPrice: 19.90\nIn stock\nFree delivery",
+			want: []TextChange{
+				{TextRemoved, 2, "Price: 24.90"},
+				{TextAdded, 2, "Price: 19.90"},
+				{TextAdded, 4, "Free delivery"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffText(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffText() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go_scraping_project/shared/models"
+)
+
+// Transform webhook limits
+const (
+	DefaultTransformTimeout = 10 * time.Second
+	maxTransformTimeout     = 60 * time.Second
+	maxTransformResponse    = 5 << 20 // 5MB
+)
+
+// ValidateTransformConfig checks that a transform webhook is usable
+func ValidateTransformConfig(cfg *models.TransformConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("transform config is required")
+	}
+	if cfg.URL == "" {
+		return fmt.Errorf("transform url is required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("transform url must be an absolute http or https URL")
+	}
+	if cfg.TimeoutMs < 0 {
+		return fmt.Errorf("timeout_ms cannot be negative")
+	}
+	if time.Duration(cfg.TimeoutMs)*time.Millisecond > maxTransformTimeout {
+		return fmt.Errorf("timeout_ms cannot exceed %d", maxTransformTimeout.Milliseconds())
+	}
+	return nil
+}
+
+// Transformer posts parsed records to per-URL transform webhooks
+type Transformer struct {
+	client *http.Client
+}
+
+// NewTransformer creates a new transformer. A nil client uses http.DefaultClient.
+func NewTransformer(client *http.Client) *Transformer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Transformer{client: client}
+}
+
+// Transform POSTs the parsed record to the webhook and returns the record
+// from its response. A 204 No Content response keeps the record unchanged.
+// The webhook may enrich or rewrite any field except the record's identity
+// (ID, URLID, URL and CreatedAt), which is always preserved.
+// When FailOpen is set, webhook failures return the original record alongside the error.
+func (t *Transformer) Transform(ctx context.Context, cfg *models.TransformConfig, record *models.ParsedData) (*models.ParsedData, error) {
+	if err := ValidateTransformConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	transformed, err := t.call(ctx, cfg, record)
+	if err != nil {
+		if cfg.FailOpen {
+			return record, err
+		}
+		return nil, err
+	}
+	return transformed, nil
+}
+
+func (t *Transformer) call(ctx context.Context, cfg *models.TransformConfig, record *models.ParsedData) (*models.ParsedData, error) {
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = DefaultTransformTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parsed record: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transform request: %w", err)
+	}
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("transform webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return record, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("transform webhook returned status %d", resp.StatusCode)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, maxTransformResponse+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transform response: %w", err)
+	}
+	if len(payload) > maxTransformResponse {
+		return nil, fmt.Errorf("transform response exceeds %d bytes", maxTransformResponse)
+	}
+
+	var transformed models.ParsedData
+	if err := json.Unmarshal(payload, &transformed); err != nil {
+		return nil, fmt.Errorf("invalid transform response: %w", err)
+	}
+
+	transformed.ID = record.ID
+	transformed.URLID = record.URLID
+	transformed.URL = record.URL
+	transformed.CreatedAt = record.CreatedAt
+
+	return &transformed, nil
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go_scraping_project/shared/models"
+
+	"github.com/google/uuid"
+)
+
+func TestTransformEnrichesRecord(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing configured header")
+		}
+		var record models.ParsedData
+		json.NewDecoder(r.Body).Decode(&record)
+		record.Title = record.Title + " (enriched)"
+		record.URL = "https://attacker.example"
+		json.NewEncoder(w).Encode(record)
+	}))
+	defer server.Close()
+
+	record := &models.ParsedData{ID: uuid.New(), URLID: uuid.New(), URL: "https://example.com", Title: "Hello"}
+	cfg := &models.TransformConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}
+
+	got, err := NewTransformer(nil).Transform(context.Background(), cfg, record)
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	if got.Title != "Hello (enriched)" {
+		t.Errorf("Title = %q, want enriched title", got.Title)
+	}
+	if got.ID != record.ID || got.URL != record.URL {
+		t.Errorf("record identity was not preserved: %+v", got)
+	}
+}
+
+func TestTransformFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	record := &models.ParsedData{Title: "Hello"}
+	transformer := NewTransformer(nil)
+
+	if got, err := transformer.Transform(context.Background(), &models.TransformConfig{URL: server.URL}, record); err == nil || got != nil {
+		t.Errorf("fail closed: got %v, %v; want nil record and error", got, err)
+	}
+	if got, err := transformer.Transform(context.Background(), &models.TransformConfig{URL: server.URL, FailOpen: true}, record); err == nil || got != record {
+		t.Errorf("fail open: got %v, %v; want original record and error", got, err)
+	}
+}
+
+func TestValidateTransformConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *models.TransformConfig
+		wantErr bool
+	}{
+		{"valid", &models.TransformConfig{URL: "https://enrich.example.com/hook"}, false},
+		{"missing url", &models.TransformConfig{}, true},
+		{"relative url", &models.TransformConfig{URL: "/hook"}, true},
+		{"unsupported scheme", &models.TransformConfig{URL: "ftp://example.com"}, true},
+		{"timeout too large", &models.TransformConfig{URL: "https://example.com", TimeoutMs: 120000}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTransformConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTransformConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
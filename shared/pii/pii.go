@@ -0,0 +1,279 @@
+// Package pii finds personal data in parsed records before they are stored.
+// A project's policy (see config.PIIConfig) selects the detectors, built-in
+// ones for email addresses and phone numbers and regular expressions of its
+// own, and the action taken on matches: flag lists the fields with personal
+// data in the record's metadata, hash replaces each match by a keyed hash
+// so equal values stay comparable across records, and redact replaces it by
+// the detector's name. Every action lists the fields it found personal
+// data in under the record's "pii" metadata key.
+package pii
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+)
+
+// MetadataKey is the metadata key listing the fields with personal data of
+// a record, as comma-separated field:detector pairs, e.g.
+// content:email,data.contact:phone
+const MetadataKey = "pii"
+
+// Built-in detectors. Phone numbers must have 9 to 15 digits and a leading
+// + or separators between digit groups, so bare numbers such as IDs and
+// timestamps are not taken for phone numbers.
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)[\s.-]?)?\d{2,4}(?:[\s.-]\d{2,4}){1,4}|\+\d{9,15}`)
+	datePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+)
+
+// detector finds one kind of personal data
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	accept  func(match string) bool // Optional check of each match
+}
+
+// Finding is a field in which personal data was found
+type Finding struct {
+	Field    string // Field path, e.g. title, data.contact or metadata.author
+	Detector string // Name of the detector that matched, e.g. email
+	Count    int    // Number of matches
+}
+
+// Redactor applies a policy to parsed records
+type Redactor struct {
+	action    string
+	hashKey   []byte
+	detectors []detector
+}
+
+// New creates a redactor for a policy, or returns nil when the policy does
+// not look for personal data. Policies are validated when the configuration
+// is loaded, so errors are only returned for policies that were not.
+func New(policy config.PIIPolicyConfig) (*Redactor, error) {
+	if policy.Action == config.PIIActionNone {
+		return nil, nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{action: policy.Action, hashKey: []byte(policy.HashKey)}
+	builtins := policy.Detectors
+	if len(builtins) == 0 {
+		builtins = []string{config.PIIDetectorEmail, config.PIIDetectorPhone}
+	}
+	for _, name := range builtins {
+		switch name {
+		case config.PIIDetectorEmail:
+			r.detectors = append(r.detectors, detector{name: name, pattern: emailPattern})
+		case config.PIIDetectorPhone:
+			r.detectors = append(r.detectors, detector{name: name, pattern: phonePattern, accept: isPhoneNumber})
+		}
+	}
+	names := make([]string, 0, len(policy.Patterns))
+	for name := range policy.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.detectors = append(r.detectors, detector{name: name, pattern: regexp.MustCompile(policy.Patterns[name])})
+	}
+	return r, nil
+}
+
+// Apply looks for personal data in the title, content, text, data and
+// metadata of a record, changes the matches as the policy's action says,
+// and lists the fields it was found in under MetadataKey. It returns the
+// findings, sorted by field and detector.
+func (r *Redactor) Apply(record *models.ParsedData) []Finding {
+	if r == nil || record == nil {
+		return nil
+	}
+
+	var findings []Finding
+	scan := func(field, value string) string {
+		changed, found := r.scan(field, value)
+		findings = append(findings, found...)
+		return changed
+	}
+	record.Title = scan("title", record.Title)
+	record.Content = scan("content", record.Content)
+	record.Text = scan("text", record.Text)
+	for _, name := range sortedKeys(record.Data) {
+		record.Data[name] = r.walk("data."+name, record.Data[name], scan)
+	}
+	metadataKeys := make([]string, 0, len(record.Metadata))
+	for key := range record.Metadata {
+		if key != MetadataKey {
+			metadataKeys = append(metadataKeys, key)
+		}
+	}
+	sort.Strings(metadataKeys)
+	for _, key := range metadataKeys {
+		record.Metadata[key] = scan("metadata."+key, record.Metadata[key])
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Field != findings[j].Field {
+			return findings[i].Field < findings[j].Field
+		}
+		return findings[i].Detector < findings[j].Detector
+	})
+	flags := make([]string, len(findings))
+	for i, finding := range findings {
+		flags[i] = finding.Field + ":" + finding.Detector
+	}
+	if record.Metadata == nil {
+		record.Metadata = map[string]string{}
+	}
+	record.Metadata[MetadataKey] = strings.Join(flags, ",")
+	return findings
+}
+
+// walk scans the strings of a data value: a string, or a list or object
+// of values as decoded from JSON or extracted by the parser
+func (r *Redactor) walk(field string, value interface{}, scan func(field, value string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return scan(field, v)
+	case []string:
+		for i := range v {
+			v[i] = scan(fmt.Sprintf("%s[%d]", field, i), v[i])
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = r.walk(fmt.Sprintf("%s[%d]", field, i), v[i], scan)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			v[key] = r.walk(field+"."+key, v[key], scan)
+		}
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			v[key] = scan(field+"."+key, v[key])
+		}
+	}
+	return value
+}
+
+// match is a piece of personal data in a value
+type match struct {
+	start, end int
+	detector   string
+}
+
+// scan finds the personal data in a value and returns the value changed
+// by the action. Where matches of detectors overlap, the one starting
+// first, or the longer one, wins, so a value is never changed twice.
+func (r *Redactor) scan(field, value string) (string, []Finding) {
+	if value == "" {
+		return value, nil
+	}
+	var matches []match
+	for _, d := range r.detectors {
+		for _, loc := range d.pattern.FindAllStringIndex(value, -1) {
+			if d.accept == nil || d.accept(value[loc[0]:loc[1]]) {
+				matches = append(matches, match{start: loc[0], end: loc[1], detector: d.name})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return value, nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	var b strings.Builder
+	counts := map[string]int{}
+	last := 0
+	for _, m := range matches {
+		if m.start < last {
+			continue
+		}
+		counts[m.detector]++
+		b.WriteString(value[last:m.start])
+		b.WriteString(r.replace(m.detector, value[m.start:m.end]))
+		last = m.end
+	}
+	b.WriteString(value[last:])
+
+	findings := make([]Finding, 0, len(counts))
+	for name, count := range counts {
+		findings = append(findings, Finding{Field: field, Detector: name, Count: count})
+	}
+	return b.String(), findings
+}
+
+// replace returns what a match is replaced with
+func (r *Redactor) replace(detector, value string) string {
+	switch r.action {
+	case config.PIIActionHash:
+		return "[" + detector + ":" + r.hash(value) + "]"
+	case config.PIIActionRedact:
+		return "[redacted:" + detector + "]"
+	}
+	return value
+}
+
+// hash returns the first 16 hex digits of the HMAC-SHA256 of a value under
+// the policy's key, or of its SHA-256 without a key
+func (r *Redactor) hash(value string) string {
+	var sum []byte
+	if len(r.hashKey) > 0 {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(value))
+		sum = digest[:]
+	}
+	return hex.EncodeToString(sum)[:16]
+}
+
+// isPhoneNumber checks a match of the phone pattern: 9 to 15 digits, and
+// not a date such as 2024-01-01 12:30
+func isPhoneNumber(match string) bool {
+	if datePattern.MatchString(match) {
+		return false
+	}
+	digits := 0
+	for _, c := range match {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+// sortedKeys returns the keys of a map in order, so findings are reported
+// in a stable order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
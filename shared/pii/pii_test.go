@@ -0,0 +1,120 @@
+package pii
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+)
+
+func newRecord() *models.ParsedData {
+	return &models.ParsedData{
+		Title:   "Contact us",
+		Content: "Mail jane.doe@example.com or call +1 415-555-0134. Published 2024-01-01 12:30, order 1234567890.",
+		Data: map[string]interface{}{
+			"price":  "19.99",
+			"links":  []string{"mailto:sales@example.com", "/about"},
+			"author": map[string]interface{}{"name": "Jane", "phone": "(020) 7946 0958"},
+		},
+		Metadata: map[string]string{"author": "jane.doe@example.com"},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	redactor, err := New(config.PIIPolicyConfig{Action: config.PIIActionRedact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := newRecord()
+	findings := redactor.Apply(record)
+
+	wantContent := "Mail [redacted:email] or call [redacted:phone]. Published 2024-01-01 12:30, order 1234567890."
+	if record.Content != wantContent {
+		t.Errorf("content = %q, want %q", record.Content, wantContent)
+	}
+	if links := record.Data["links"].([]string); links[0] != "mailto:[redacted:email]" || links[1] != "/about" {
+		t.Errorf("links = %v", links)
+	}
+	if phone := record.Data["author"].(map[string]interface{})["phone"]; phone != "[redacted:phone]" {
+		t.Errorf("nested phone = %v", phone)
+	}
+	if record.Data["price"] != "19.99" || record.Title != "Contact us" {
+		t.Errorf("values without personal data changed: %q, %v", record.Title, record.Data["price"])
+	}
+
+	want := []Finding{
+		{Field: "content", Detector: "email", Count: 1},
+		{Field: "content", Detector: "phone", Count: 1},
+		{Field: "data.author.phone", Detector: "phone", Count: 1},
+		{Field: "data.links[0]", Detector: "email", Count: 1},
+		{Field: "metadata.author", Detector: "email", Count: 1},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("findings = %+v, want %+v", findings, want)
+	}
+	if flags := record.Metadata[MetadataKey]; flags != "content:email,content:phone,data.author.phone:phone,data.links[0]:email,metadata.author:email" {
+		t.Errorf("metadata %s = %q", MetadataKey, flags)
+	}
+}
+
+func TestFlagKeepsValues(t *testing.T) {
+	redactor, _ := New(config.PIIPolicyConfig{Action: config.PIIActionFlag, Detectors: []string{config.PIIDetectorEmail}})
+	record := newRecord()
+	redactor.Apply(record)
+	if record.Content != newRecord().Content {
+		t.Errorf("flag changed the content to %q", record.Content)
+	}
+	if flags := record.Metadata[MetadataKey]; flags != "content:email,data.links[0]:email,metadata.author:email" {
+		t.Errorf("metadata %s = %q, want only email findings", MetadataKey, flags)
+	}
+}
+
+func TestHashIsKeyedAndStable(t *testing.T) {
+	hashed := func(key string) string {
+		redactor, _ := New(config.PIIPolicyConfig{Action: config.PIIActionHash, Detectors: []string{config.PIIDetectorEmail}, HashKey: key})
+		record := newRecord()
+		redactor.Apply(record)
+		if record.Metadata["author"] != strings.Fields(record.Content)[1] {
+			t.Errorf("equal emails hashed differently: %q, %q", record.Metadata["author"], record.Content)
+		}
+		return record.Metadata["author"]
+	}
+	first := hashed("key-1")
+	if !strings.HasPrefix(first, "[email:") || len(first) != len("[email:]")+16 || strings.Contains(first, "jane") {
+		t.Errorf("hashed email = %q", first)
+	}
+	if hashed("key-1") != first || hashed("key-2") == first {
+		t.Error("hashes do not depend on the key alone")
+	}
+}
+
+func TestCustomPatterns(t *testing.T) {
+	redactor, err := New(config.PIIPolicyConfig{
+		Action:    config.PIIActionRedact,
+		Detectors: []string{config.PIIDetectorEmail},
+		Patterns:  map[string]string{"ssn": `\b\d{3}-\d{2}-\d{4}\b`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &models.ParsedData{Content: "SSN 123-45-6789, call 415-555-0134"}
+	redactor.Apply(record)
+	if record.Content != "SSN [redacted:ssn], call 415-555-0134" {
+		t.Errorf("content = %q", record.Content)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if redactor, err := New(config.PIIPolicyConfig{}); redactor != nil || err != nil {
+		t.Errorf("New() of a policy without action = %v, %v; want nil", redactor, err)
+	}
+	if _, err := New(config.PIIPolicyConfig{Action: config.PIIActionRedact, Patterns: map[string]string{"id": "("}}); err == nil {
+		t.Error("New() with an invalid pattern succeeded")
+	}
+	var redactor *Redactor
+	if findings := redactor.Apply(newRecord()); findings != nil {
+		t.Errorf("nil redactor found %v", findings)
+	}
+}
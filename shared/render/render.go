@@ -0,0 +1,161 @@
+// Package render records what a headless browser did while rendering a
+// page: the messages it logged to the console and the network requests the
+// page made. When a selector comes back empty on a rendered page, the
+// session shows which XHR or fetch request delivered the data instead, and
+// whether a script failed before it could be inserted. Scrapers record a
+// session for rendered scrapes of URLs whose archive policy asks for one
+// (render_session) and keep it with the raw HTML, see models.ArchivePolicy.
+package render
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of a recorded session
+const (
+	// MaxConsoleMessages is the number of console messages kept; later ones are counted
+	MaxConsoleMessages = 500
+	// MaxRequests is the number of network requests kept; later ones are counted
+	MaxRequests = 1000
+	// DefaultBodyPreview is the number of bytes of XHR and fetch response
+	// bodies kept when no limit is configured
+	DefaultBodyPreview = 16 * 1024
+)
+
+// Resource types of the requests a page makes, as reported by the browser
+const (
+	ResourceDocument = "document"
+	ResourceScript   = "script"
+	ResourceXHR      = "xhr"
+	ResourceFetch    = "fetch"
+	ResourceOther    = "other"
+)
+
+// Session is what a browser did while rendering a page
+type Session struct {
+	Console         []ConsoleMessage `json:"console"`
+	Requests        []Request        `json:"requests"`                   // In the order they started
+	DroppedConsole  int              `json:"dropped_console,omitempty"`  // Messages left out beyond MaxConsoleMessages
+	DroppedRequests int              `json:"dropped_requests,omitempty"` // Requests left out beyond MaxRequests
+}
+
+// ConsoleMessage is a message the page logged, or an uncaught exception
+// (level "error")
+type ConsoleMessage struct {
+	Time   time.Time `json:"time"`
+	Level  string    `json:"level"` // log, info, warning, error or debug
+	Text   string    `json:"text"`
+	Source string    `json:"source,omitempty"` // Script URL and line, when known
+}
+
+// Request is a network request the page made
+type Request struct {
+	StartedAt  time.Time `json:"started_at"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Type       string    `json:"type"`                  // One of the resource types, e.g. ResourceXHR
+	Status     int       `json:"status,omitempty"`      // 0 when no response was received
+	MimeType   string    `json:"mime_type,omitempty"`   // Of the response
+	Size       int64     `json:"size,omitempty"`        // Response body size in bytes
+	DurationMs int64     `json:"duration_ms,omitempty"` // Time until the response was complete
+	Error      string    `json:"error,omitempty"`       // Why the request failed, e.g. blocked or aborted
+	Body       string    `json:"body,omitempty"`        // First bytes of XHR and fetch response bodies
+}
+
+// Data reports whether the request fetched data for the page's scripts,
+// that is an XHR or fetch request
+func (r Request) Data() bool {
+	return r.Type == ResourceXHR || r.Type == ResourceFetch
+}
+
+// Find returns the XHR and fetch requests whose URL or response body
+// contains text, ignoring case, e.g. the value a selector was expected to
+// extract
+func (s *Session) Find(text string) []Request {
+	text = strings.ToLower(text)
+	matches := []Request{}
+	for _, r := range s.Requests {
+		if r.Data() && (strings.Contains(strings.ToLower(r.Body), text) || strings.Contains(strings.ToLower(r.URL), text)) {
+			matches = append(matches, r)
+		}
+	}
+	return matches
+}
+
+// Recorder collects the console messages and requests of one render. The
+// browser driver calls Console and Request from its event handlers, which
+// may run concurrently; Session returns the result.
+type Recorder struct {
+	bodyPreview int
+
+	mu      sync.Mutex
+	session Session
+}
+
+// NewRecorder creates a recorder that keeps the first bodyPreview bytes of
+// XHR and fetch response bodies (DefaultBodyPreview when not positive)
+func NewRecorder(bodyPreview int) *Recorder {
+	if bodyPreview <= 0 {
+		bodyPreview = DefaultBodyPreview
+	}
+	return &Recorder{bodyPreview: bodyPreview}
+}
+
+// Console records a console message
+func (r *Recorder) Console(msg ConsoleMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.session.Console) >= MaxConsoleMessages {
+		r.session.DroppedConsole++
+		return
+	}
+	r.session.Console = append(r.session.Console, msg)
+}
+
+// Request records a finished or failed request. Bodies of other requests
+// than XHR and fetch are dropped, and kept ones shortened to the body
+// preview.
+func (r *Recorder) Request(req Request) {
+	if !req.Data() {
+		req.Body = ""
+	} else if len(req.Body) > r.bodyPreview {
+		cut := r.bodyPreview
+		for cut > 0 && !utf8.RuneStart(req.Body[cut]) {
+			cut--
+		}
+		req.Body = req.Body[:cut]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.session.Requests) >= MaxRequests {
+		r.session.DroppedRequests++
+		return
+	}
+	r.session.Requests = append(r.session.Requests, req)
+}
+
+// Session returns the session recorded so far, with console messages and
+// requests in time order
+func (r *Recorder) Session() *Session {
+	r.mu.Lock()
+	session := Session{
+		Console:         append([]ConsoleMessage{}, r.session.Console...),
+		Requests:        append([]Request{}, r.session.Requests...),
+		DroppedConsole:  r.session.DroppedConsole,
+		DroppedRequests: r.session.DroppedRequests,
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(session.Console, func(i, j int) bool {
+		return session.Console[i].Time.Before(session.Console[j].Time)
+	})
+	sort.SliceStable(session.Requests, func(i, j int) bool {
+		return session.Requests[i].StartedAt.Before(session.Requests[j].StartedAt)
+	})
+	return &session
+}
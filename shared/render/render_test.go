@@ -0,0 +1,71 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRecorder(8)
+
+	r.Request(Request{StartedAt: start.Add(time.Second), Method: "GET", URL: "https://shop.example/api/price?id=7", Type: ResourceXHR, Status: 200, Body: `{"price":"24.90"}`})
+	r.Request(Request{StartedAt: start, Method: "GET", URL: "https://shop.example/kettle", Type: ResourceDocument, Status: 200, Body: "<html>"})
+	r.Request(Request{StartedAt: start.Add(2 * time.Second), Method: "POST", URL: "https://tracker.example/collect", Type: ResourceFetch, Error: "net::ERR_BLOCKED_BY_CLIENT"})
+	r.Console(ConsoleMessage{Time: start.Add(3 * time.Second), Level: "error", Text: "Uncaught TypeError: price is undefined"})
+	r.Console(ConsoleMessage{Time: start, Level: "log", Text: "app started"})
+
+	session := r.Session()
+	if len(session.Requests) != 3 || session.Requests[0].Type != ResourceDocument {
+		t.Fatalf("requests = %+v, want three in start order", session.Requests)
+	}
+	if session.Requests[0].Body != "" {
+		t.Errorf("document body = %q, want bodies kept for XHR and fetch only", session.Requests[0].Body)
+	}
+	if session.Requests[1].Body != `{"price"` {
+		t.Errorf("XHR body = %q, want the 8 byte preview", session.Requests[1].Body)
+	}
+	if len(session.Console) != 2 || session.Console[0].Text != "app started" {
+		t.Errorf("console = %+v, want two messages in time order", session.Console)
+	}
+
+	if matches := session.Find("PRICE"); len(matches) != 1 || !strings.Contains(matches[0].URL, "/api/price") {
+		t.Errorf("Find(PRICE) = %+v, want the price XHR", matches)
+	}
+	if matches := session.Find("kettle"); len(matches) != 0 {
+		t.Errorf("Find(kettle) = %+v, want documents left out", matches)
+	}
+}
+
+func TestRecorderLimits(t *testing.T) {
+	r := NewRecorder(0)
+	for i := 0; i < MaxRequests+3; i++ {
+		r.Request(Request{URL: "https://shop.example/pixel.gif", Type: ResourceOther})
+	}
+	for i := 0; i < MaxConsoleMessages+2; i++ {
+		r.Console(ConsoleMessage{Level: "log", Text: "tick"})
+	}
+	r.Request(Request{Type: ResourceFetch})
+
+	session := r.Session()
+	if len(session.Requests) != MaxRequests || session.DroppedRequests != 4 {
+		t.Errorf("kept %d requests and dropped %d, want %d and 4", len(session.Requests), session.DroppedRequests, MaxRequests)
+	}
+	if len(session.Console) != MaxConsoleMessages || session.DroppedConsole != 2 {
+		t.Errorf("kept %d console messages and dropped %d, want %d and 2", len(session.Console), session.DroppedConsole, MaxConsoleMessages)
+	}
+}
+
+func TestRecorderKeepsRunesWhole(t *testing.T) {
+	r := NewRecorder(3)
+	r.Request(Request{Type: ResourceXHR, Body: "aéb"})
+	if body := r.Session().Requests[0].Body; body != "aé" {
+		t.Errorf("body = %q, want the preview cut before a split rune", body)
+	}
+	r = NewRecorder(2)
+	r.Request(Request{Type: ResourceXHR, Body: "aéb"})
+	if body := r.Session().Requests[0].Body; body != "a" {
+		t.Errorf("body = %q, want the preview cut before a split rune", body)
+	}
+}
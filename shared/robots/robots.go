@@ -0,0 +1,177 @@
+// Package robots parses robots.txt files and decides whether a crawler may
+// fetch a path, following RFC 9309: rules are grouped by user agent, the
+// group naming the crawler's product token applies (or the * group when
+// none does), and the longest matching allow or disallow rule wins, with
+// allow winning ties. Patterns support the * wildcard and the $ end anchor.
+package robots
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxBodyBytes is the part of a robots.txt file that is parsed, as RFC 9309
+// lets crawlers ignore anything past 500 KiB
+const maxBodyBytes = 500 << 10
+
+// rule is an allow or disallow line
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// Rules are the parsed rules of a robots.txt file
+type Rules struct {
+	groups map[string][]rule // By lowercase user agent token
+}
+
+// Parse parses the contents of a robots.txt file. Lines it does not
+// understand are ignored.
+func Parse(body string) *Rules {
+	rules := &Rules{groups: make(map[string][]rule)}
+
+	var agents []string
+	inRules := false // Whether the current group's user-agent lines have ended
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agent := strings.ToLower(value)
+			agents = append(agents, agent)
+			if _, ok := rules.groups[agent]; !ok {
+				rules.groups[agent] = nil
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty disallow allows everything, which is the default
+				continue
+			}
+			for _, agent := range agents {
+				rules.groups[agent] = append(rules.groups[agent], rule{allow: key == "allow", pattern: value})
+			}
+		}
+	}
+	return rules
+}
+
+// Allowed reports whether a crawler with the given user agent may fetch
+// path, which includes any query string
+func (r *Rules) Allowed(userAgent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	group, ok := r.groups[ProductToken(userAgent)]
+	if !ok {
+		group = r.groups["*"]
+	}
+
+	allowed, longest := true, -1
+	for _, rule := range group {
+		if !match(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allowed, longest = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// ProductToken returns the lowercase product token of a user agent, the
+// part robots.txt groups are matched against, e.g. "goscrapingbot" for
+// "GoScrapingBot/1.0 (+https://example.com/bot)"
+func ProductToken(userAgent string) string {
+	token := strings.TrimSpace(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+	return strings.ToLower(token)
+}
+
+// match reports whether a rule pattern matches a path
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+	return strings.Contains(rest, last)
+}
+
+// URL returns the address of the robots.txt file that applies to a page
+func URL(page *url.URL) string {
+	return (&url.URL{Scheme: page.Scheme, Host: page.Host, Path: "/robots.txt"}).String()
+}
+
+// Fetch downloads and parses the robots.txt file that applies to a page.
+// A missing file (any 4xx response) allows everything. Server errors and
+// unreachable hosts are returned as errors; RFC 9309 has crawlers treat
+// them as disallowing everything. client may be nil to use
+// http.DefaultClient.
+func Fetch(ctx context.Context, client *http.Client, page *url.URL, userAgent string) (*Rules, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL(page), nil)
+	if err != nil {
+		return nil, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		return Parse(string(body)), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return Parse(""), nil
+	}
+	return nil, fmt.Errorf("robots.txt returned %s", resp.Status)
+}
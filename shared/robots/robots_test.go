@@ -0,0 +1,81 @@
+package robots
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const robotsFile = `# Example robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public-*.html$
+Disallow: /*.pdf$
+
+User-agent: GoScrapingBot
+User-agent: OtherBot
+Disallow: /search
+Disallow:
+
+User-agent: BlockedBot
+Disallow: /
+`
+
+func TestRulesAllowed(t *testing.T) {
+	rules := Parse(robotsFile)
+
+	tests := []struct {
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{"SomeBot/2.0", "/", true},
+		{"SomeBot/2.0", "/private/data", false},
+		{"SomeBot/2.0", "/private/public-page.html", true},
+		{"SomeBot/2.0", "/private/public-page.html?x=1", false},
+		{"SomeBot/2.0", "/files/report.pdf", false},
+		{"SomeBot/2.0", "/files/report.pdf.txt", true},
+		{"GoScrapingBot/1.0", "/private/data", true},
+		{"GoScrapingBot/1.0", "/search?q=kettle", false},
+		{"otherbot", "/search", false},
+		{"BlockedBot", "/anything", false},
+	}
+	for _, tt := range tests {
+		if got := rules.Allowed(tt.userAgent, tt.path); got != tt.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tt.userAgent, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			t.Errorf("fetched %s, want /robots.txt", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+	}))
+	defer server.Close()
+
+	page, _ := url.Parse(server.URL + "/admin/users?page=2")
+	rules, err := Fetch(context.Background(), nil, page, "GoScrapingBot/1.0")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if rules.Allowed("GoScrapingBot/1.0", page.RequestURI()) {
+		t.Error("Fetch() rules allow a disallowed path")
+	}
+
+	status = http.StatusNotFound
+	if rules, err := Fetch(context.Background(), nil, page, ""); err != nil || !rules.Allowed("GoScrapingBot/1.0", "/admin") {
+		t.Errorf("missing robots.txt = %v, %v; want everything allowed", rules, err)
+	}
+
+	status = http.StatusServiceUnavailable
+	if _, err := Fetch(context.Background(), nil, page, ""); err == nil {
+		t.Error("Fetch() accepted a 503 response")
+	}
+}
@@ -0,0 +1,126 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go_scraping_project/shared/awssig"
+)
+
+// AWSProvider reads secrets from AWS Secrets Manager. The reference path is
+// the secret name or ARN; if a key is given the secret string is decoded as
+// a JSON object and that field is returned, so
+// secret://aws/prod/scraper/db#password reads the "password" field of the
+// secret "prod/scraper/db".
+type AWSProvider struct {
+	Region          string
+	Endpoint        string // Overrides https://secretsmanager.<region>.amazonaws.com (optional)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Client          *http.Client
+	now             func() time.Time
+}
+
+// NewAWSProvider creates a Secrets Manager provider using the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables
+func NewAWSProvider(region, endpoint string) *AWSProvider {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+	return &AWSProvider{
+		Region:          region,
+		Endpoint:        strings.TrimRight(endpoint, "/"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          &http.Client{Timeout: 10 * time.Second},
+		now:             time.Now,
+	}
+}
+
+// awsGetSecretValueResponse is the subset of the GetSecretValue response we use
+type awsGetSecretValueResponse struct {
+	SecretString string `json:"SecretString"`
+}
+
+// awsErrorResponse is the error body returned by AWS JSON protocol APIs
+type awsErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Get fetches the secret with GetSecretValue
+func (p *AWSProvider) Get(ctx context.Context, ref Reference) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create secrets manager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, body)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr awsErrorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		if strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secrets manager returned status %d: %s %s", resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	var secret awsGetSecretValueResponse
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+
+	if ref.Key == "" {
+		return secret.SecretString, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, cannot read key %q", ref.Path, ref.Key)
+	}
+	value, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrNotFound, ref.Key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (p *AWSProvider) sign(req *http.Request, body []byte) {
+	signer := awssig.Signer{
+		Region:          p.Region,
+		Service:         "secretsmanager",
+		AccessKeyID:     p.AccessKeyID,
+		SecretAccessKey: p.SecretAccessKey,
+		SessionToken:    p.SessionToken,
+	}
+	signer.Sign(req, awssig.SHA256Hex(body), p.now(), "content-type", "x-amz-target")
+}
@@ -0,0 +1,20 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvProvider reads secrets from environment variables, e.g.
+// secret://env/DB_PASSWORD. It is intended for local development and tests.
+type EnvProvider struct{}
+
+// Get returns the value of the environment variable named by the reference path
+func (EnvProvider) Get(ctx context.Context, ref Reference) (string, error) {
+	value, ok := os.LookupEnv(ref.Path)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrNotFound, ref.Path)
+	}
+	return value, nil
+}
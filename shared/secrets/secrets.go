@@ -0,0 +1,227 @@
+// Package secrets resolves secret references in configuration.
+//
+// Any string configuration value may be a reference of the form
+//
+//	secret://<backend>/<path>#<key>
+//
+// for example secret://vault/kv/scraper/db#password or
+// secret://aws/prod/scraper/db#password. References are resolved at
+// startup and again on every configuration reload, so rotated secrets
+// are picked up without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"go_scraping_project/shared/config"
+)
+
+// Scheme is the URI scheme that marks a configuration value as a secret reference
+const Scheme = "secret://"
+
+// ErrNotFound is returned when a secret or key does not exist in the backend
+var ErrNotFound = errors.New("secret not found")
+
+// Reference identifies a secret value in a backend
+type Reference struct {
+	Backend string // Provider name, e.g. "vault", "aws" or "env"
+	Path    string // Backend-specific secret path or ID
+	Key     string // Field within the secret; empty for plain string secrets
+}
+
+// String returns the reference in its URI form
+func (r Reference) String() string {
+	uri := Scheme + r.Backend + "/" + r.Path
+	if r.Key != "" {
+		uri += "#" + r.Key
+	}
+	return uri
+}
+
+// IsReference reports whether a configuration value is a secret reference
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// ParseReference parses a secret://<backend>/<path>#<key> URI
+func ParseReference(uri string) (Reference, error) {
+	if !IsReference(uri) {
+		return Reference{}, fmt.Errorf("not a secret reference: missing %s prefix", Scheme)
+	}
+
+	rest := strings.TrimPrefix(uri, Scheme)
+	var ref Reference
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Key = rest[i+1:]
+		rest = rest[:i]
+	}
+
+	backend, path, ok := strings.Cut(rest, "/")
+	if !ok || backend == "" || path == "" {
+		return Reference{}, fmt.Errorf("invalid secret reference %q: expected %s<backend>/<path>[#key]", uri, Scheme)
+	}
+	ref.Backend = backend
+	ref.Path = path
+	return ref, nil
+}
+
+// Provider fetches secrets from a backend
+type Provider interface {
+	// Get returns the value of the referenced secret
+	Get(ctx context.Context, ref Reference) (string, error)
+}
+
+// Resolver resolves secret references using the registered providers
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver with the given providers keyed by backend name
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// Resolve returns the secret value for a reference, or the value unchanged
+// if it is not a secret reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	provider, ok := r.providers[ref.Backend]
+	if !ok {
+		return "", fmt.Errorf("no secrets provider configured for backend %q", ref.Backend)
+	}
+
+	secret, err := provider.Get(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// ResolveStruct replaces every secret reference found in the string fields,
+// slices and string maps of the struct pointed to by v, recursing into
+// nested structs, slice elements, struct values of maps and pointers. Errors for all unresolved references
+// are joined so a misconfiguration is reported in one go.
+func (r *Resolver) ResolveStruct(ctx context.Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("ResolveStruct requires a non-nil pointer, got %T", v)
+	}
+	return r.resolveValue(ctx, rv.Elem(), "")
+}
+
+func (r *Resolver) resolveValue(ctx context.Context, v reflect.Value, field string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return r.resolveValue(ctx, v.Elem(), field)
+
+	case reflect.Struct:
+		var errs []error
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			errs = append(errs, r.resolveValue(ctx, v.Field(i), join(field, t.Field(i).Name)))
+		}
+		return errors.Join(errs...)
+
+	case reflect.String:
+		if !IsReference(v.String()) || !v.CanSet() {
+			return nil
+		}
+		secret, err := r.Resolve(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		v.SetString(secret)
+
+	case reflect.Slice:
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, r.resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", field, i)))
+		}
+		return errors.Join(errs...)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Struct {
+			// Map values cannot be set in place: resolve a copy and store it back
+			var errs []error
+			for _, key := range v.MapKeys() {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				elem.Set(v.MapIndex(key))
+				errs = append(errs, r.resolveValue(ctx, elem, fmt.Sprintf("%s[%s]", field, key.String())))
+				v.SetMapIndex(key, elem)
+			}
+			return errors.Join(errs...)
+		}
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		var errs []error
+		for _, key := range v.MapKeys() {
+			value := v.MapIndex(key).String()
+			if !IsReference(value) {
+				continue
+			}
+			secret, err := r.Resolve(ctx, value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s[%s]: %w", field, key.String(), err))
+				continue
+			}
+			v.SetMapIndex(key, reflect.ValueOf(secret).Convert(v.Type().Elem()))
+		}
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// NewResolverFromConfig creates a resolver with the env provider and, when
+// configured, the Vault and AWS Secrets Manager providers
+func NewResolverFromConfig(cfg config.SecretsConfig) *Resolver {
+	providers := map[string]Provider{"env": EnvProvider{}}
+
+	address := cfg.Vault.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address != "" {
+		token := cfg.Vault.Token
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		providers["vault"] = NewVaultProvider(address, token, cfg.Vault.Namespace)
+	}
+
+	if cfg.AWS.Region != "" || cfg.AWS.Endpoint != "" || os.Getenv("AWS_REGION") != "" {
+		providers["aws"] = NewAWSProvider(cfg.AWS.Region, cfg.AWS.Endpoint)
+	}
+
+	return NewResolver(providers)
+}
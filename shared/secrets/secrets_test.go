@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go_scraping_project/shared/config"
+)
+
+// staticProvider returns values from a map keyed by reference URI
+type staticProvider map[string]string
+
+func (p staticProvider) Get(ctx context.Context, ref Reference) (string, error) {
+	value, ok := p[ref.String()]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    Reference
+		wantErr bool
+	}{
+		{uri: "secret://vault/kv/scraper/db#password", want: Reference{Backend: "vault", Path: "kv/scraper/db", Key: "password"}},
+		{uri: "secret://aws/prod/db", want: Reference{Backend: "aws", Path: "prod/db"}},
+		{uri: "secret://env/DB_PASSWORD", want: Reference{Backend: "env", Path: "DB_PASSWORD"}},
+		{uri: "secret://vault", wantErr: true},
+		{uri: "secret:///path", wantErr: true},
+		{uri: "vault/kv/db", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := ParseReference(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseReference() = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.uri {
+				t.Errorf("String() = %q, want %q", got.String(), tt.uri)
+			}
+		})
+	}
+}
+
+func TestResolveStruct(t *testing.T) {
+	resolver := NewResolver(map[string]Provider{
+		"vault": staticProvider{
+			"secret://vault/kv/db#password":   "s3cret",
+			"secret://vault/kv/kafka#broker":  "kafka-1:9092",
+			"secret://vault/kv/slack#webhook": "https://hooks.slack.com/services/T/B/X",
+			"secret://vault/kv/pii#hash_key":  "pii-key",
+		},
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Database.Password = "secret://vault/kv/db#password"
+	cfg.Kafka.Brokers = []string{"secret://vault/kv/kafka#broker", "kafka-2:9092"}
+	cfg.Notifications.Channels = []config.NotificationChannelConfig{{Name: "ops", Type: "slack", URL: "secret://vault/kv/slack#webhook"}}
+	cfg.PII.Projects = map[string]config.PIIPolicyConfig{"crm": {Action: config.PIIActionHash, HashKey: "secret://vault/kv/pii#hash_key"}}
+
+	if err := resolver.ResolveStruct(context.Background(), cfg); err != nil {
+		t.Fatalf("ResolveStruct() error = %v", err)
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("Database.Password = %q, want resolved secret", cfg.Database.Password)
+	}
+	if cfg.Kafka.Brokers[0] != "kafka-1:9092" || cfg.Kafka.Brokers[1] != "kafka-2:9092" {
+		t.Errorf("Kafka.Brokers = %v", cfg.Kafka.Brokers)
+	}
+	if got := cfg.Notifications.Channels[0].URL; got != "https://hooks.slack.com/services/T/B/X" {
+		t.Errorf("Notifications.Channels[0].URL = %q, want resolved secret", got)
+	}
+	if got := cfg.PII.Projects["crm"]; got.HashKey != "pii-key" || got.Action != config.PIIActionHash {
+		t.Errorf("PII.Projects[crm] = %+v, want the hash key resolved", got)
+	}
+	if cfg.Database.User != "scraper" {
+		t.Errorf("plain value changed: Database.User = %q", cfg.Database.User)
+	}
+}
+
+func TestResolveStructReportsAllFailures(t *testing.T) {
+	resolver := NewResolver(map[string]Provider{"vault": staticProvider{}})
+
+	cfg := config.DefaultConfig()
+	cfg.Database.Password = "secret://vault/kv/db#password"
+	cfg.Database.User = "secret://aws/prod/db#user"
+
+	err := resolver.ResolveStruct(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected ResolveStruct() to fail")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error does not wrap ErrNotFound: %v", err)
+	}
+	for _, field := range []string{"Database.Password", "Database.User"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q does not mention %s", err, field)
+		}
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("SCRAPER_TEST_SECRET", "from-env")
+	resolver := NewResolverFromConfig(config.SecretsConfig{})
+
+	got, err := resolver.Resolve(context.Background(), "secret://env/SCRAPER_TEST_SECRET")
+	if err != nil || got != "from-env" {
+		t.Errorf("Resolve() = %q, %v; want from-env", got, err)
+	}
+}
@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from HashiCorp Vault's KV version 2 engine.
+// The reference path is <mount>/<secret path>, so
+// secret://vault/kv/scraper/db#password reads the "password" field of
+// the secret "scraper/db" in the KV engine mounted at "kv".
+type VaultProvider struct {
+	Address   string // Vault address, e.g. https://vault.internal:8200
+	Token     string // Vault token
+	Namespace string // Vault Enterprise namespace (optional)
+	Client    *http.Client
+}
+
+// NewVaultProvider creates a Vault provider with a default HTTP client
+func NewVaultProvider(address, token, namespace string) *VaultProvider {
+	return &VaultProvider{
+		Address:   strings.TrimRight(address, "/"),
+		Token:     token,
+		Namespace: namespace,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultKVResponse is the KV v2 read response
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// Get reads a field of a KV v2 secret
+func (p *VaultProvider) Get(ctx context.Context, ref Reference) (string, error) {
+	if ref.Key == "" {
+		return "", fmt.Errorf("vault references must name a key, e.g. %s#password", ref)
+	}
+
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || path == "" {
+		return "", fmt.Errorf("vault reference path must be <mount>/<path>, got %q", ref.Path)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.Address, mount, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	value, ok := secret.Data.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrNotFound, ref.Key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
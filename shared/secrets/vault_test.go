@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultProviderGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/scraper/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"s3cret","port":5432},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	provider := NewVaultProvider(server.URL, "test-token", "")
+
+	tests := []struct {
+		uri     string
+		want    string
+		wantErr error
+	}{
+		{uri: "secret://vault/kv/scraper/db#password", want: "s3cret"},
+		{uri: "secret://vault/kv/scraper/db#port", want: "5432"},
+		{uri: "secret://vault/kv/scraper/db#missing", wantErr: ErrNotFound},
+		{uri: "secret://vault/kv/scraper/other#password", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			ref, err := ParseReference(tt.uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := provider.Get(context.Background(), ref)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Get() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package testenv
+
+import (
+	"context"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// Images of the servers started for the tests
+const (
+	postgresImage = "postgres:15-alpine"
+	kafkaImage    = "confluentinc/confluent-local:7.5.0"
+)
+
+// The servers are started once per test binary and shared by its tests,
+// which isolate themselves with databases and topics of their own.
+// Testcontainers' reaper removes the containers when the binary exits.
+var (
+	postgresOnce sync.Once
+	postgresURL  string
+	postgresErr  error
+
+	kafkaOnce    sync.Once
+	kafkaBrokers []string
+	kafkaErr     error
+)
+
+// startPostgres starts the Postgres container on first use and returns the
+// connection URL of its superuser
+func startPostgres() (string, error) {
+	postgresOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+		defer cancel()
+		container, err := tcpostgres.Run(ctx, postgresImage,
+			tcpostgres.WithDatabase("postgres"),
+			tcpostgres.WithUsername("scraper"),
+			tcpostgres.WithPassword("scraper"),
+			tcpostgres.BasicWaitStrategies(),
+		)
+		if err != nil {
+			testcontainers.TerminateContainer(container)
+			postgresErr = err
+			return
+		}
+		postgresURL, postgresErr = container.ConnectionString(ctx, "sslmode=disable")
+	})
+	return postgresURL, postgresErr
+}
+
+// startKafka starts the single-broker Kafka container on first use and
+// returns its bootstrap servers
+func startKafka() ([]string, error) {
+	kafkaOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+		defer cancel()
+		container, err := tckafka.Run(ctx, kafkaImage, tckafka.WithClusterID("scraping-it"))
+		if err != nil {
+			testcontainers.TerminateContainer(container)
+			kafkaErr = err
+			return
+		}
+		kafkaBrokers, kafkaErr = container.Brokers(ctx)
+	})
+	return kafkaBrokers, kafkaErr
+}
@@ -0,0 +1,7 @@
+//go:build !integration
+
+package testenv
+
+func startPostgres() (string, error) { return "", errNoContainers }
+
+func startKafka() ([]string, error) { return nil, errNoContainers }
@@ -0,0 +1,390 @@
+// Package testenv runs integration tests against real Postgres and Kafka.
+// Each test gets a database of its own, migrated from sql/schema, and Kafka
+// topics and a consumer group of its own, so tests neither see each other's
+// data nor need a clean cluster. Services are wired by their own setup
+// function in a bootstrap.Container built from the test's configuration,
+// see Env.Container.
+//
+// Postgres and Kafka run in containers started with testcontainers on the
+// first call to New, so Docker is all the tests need. TEST_DATABASE_URL, a
+// connection URL of a user allowed to create databases, and
+// TEST_KAFKA_BROKERS, a comma-separated broker list, use existing servers
+// instead. Integration tests are built with the integration tag, so go test
+// ./... without it never needs the servers.
+package testenv
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/bootstrap"
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/database"
+	sharedkafka "go_scraping_project/shared/kafka"
+
+	"github.com/pressly/goose/v3"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Environment variables naming existing servers, overriding the containers
+const (
+	EnvDatabaseURL  = "TEST_DATABASE_URL"
+	EnvKafkaBrokers = "TEST_KAFKA_BROKERS"
+	EnvLogLevel     = "TEST_LOG_LEVEL" // Level of service logs written to stderr, none when unset
+)
+
+// Timeouts of the harness
+const (
+	// StartTimeout bounds starting a container and waiting for the servers
+	StartTimeout = time.Minute
+	// WaitTimeout bounds waiting for a message or condition
+	WaitTimeout = 30 * time.Second
+)
+
+// errNoContainers is returned in builds without the integration tag, which
+// keep testcontainers and Docker out of ordinary test runs
+var errNoContainers = errors.New("servers are only started in builds with the integration tag")
+
+// SharedTopics are topics the services use under fixed names instead of
+// configured ones, which tests share: the scraping tasks topic of the URL
+// Manager scheduler
+var SharedTopics = []string{"scraping-tasks"}
+
+// Env is the isolated environment of a test
+type Env struct {
+	Config *config.Config // Default configuration pointing at the test's database and topics
+	DB     *sql.DB        // Connection to the test's database
+	Logger *logrus.Logger
+}
+
+// New creates the database and Kafka topics of a test, starting the
+// servers it was not given, and drops the database when the test ends.
+// Topics are left to the cluster, which is thrown away with its container.
+func New(t testing.TB) *Env {
+	t.Helper()
+	adminURL, brokers := servers(t)
+	// DatabaseConfig.URL prefers DATABASE_URL over the test's database
+	if os.Getenv("DATABASE_URL") != "" {
+		t.Setenv("DATABASE_URL", "")
+	}
+
+	suffix := randomSuffix()
+	env := &Env{Config: config.DefaultConfig(), Logger: newLogger()}
+
+	dbConfig, err := databaseConfig(adminURL, "it_"+suffix)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", EnvDatabaseURL, err)
+	}
+	dbConfig.StatementTimeout = env.Config.Database.StatementTimeout
+	dbConfig.QueryTimeout = env.Config.Database.QueryTimeout
+	env.Config.Database = dbConfig
+	env.DB = createDatabase(t, adminURL, dbConfig)
+	migrate(t, env.DB)
+
+	kafkaConfig := &env.Config.Kafka
+	kafkaConfig.Brokers = brokers
+	kafkaConfig.GroupID = "it-" + suffix
+	kafkaConfig.AutoOffsetReset = "earliest"
+	kafkaConfig.RetryBackoff = 10 * time.Millisecond
+	topics := &kafkaConfig.Topics
+	for _, topic := range []*string{&topics.ScrapingTasks, &topics.ScrapingResults, &topics.ScrapedData, &topics.ParsedData, &topics.DeadLetter, &topics.URLEvents, &topics.ConsumerControl} {
+		*topic += "-" + suffix
+	}
+	createTopics(t, kafkaConfig.Brokers, append([]string{
+		topics.ScrapingTasks, topics.ScrapingResults, topics.ScrapedData, topics.ParsedData,
+		topics.DeadLetter, topics.URLEvents, topics.ConsumerControl,
+	}, SharedTopics...))
+
+	return env
+}
+
+// servers returns the connection URL of Postgres and the brokers of Kafka,
+// from the environment or else from the containers
+func servers(t testing.TB) (string, []string) {
+	t.Helper()
+	adminURL := os.Getenv(EnvDatabaseURL)
+	if adminURL == "" {
+		var err error
+		if adminURL, err = startPostgres(); errors.Is(err, errNoContainers) {
+			t.Skipf("%s is not set: %v", EnvDatabaseURL, err)
+		} else if err != nil {
+			t.Fatalf("failed to start Postgres: %v", err)
+		}
+	}
+	if brokerList := os.Getenv(EnvKafkaBrokers); brokerList != "" {
+		return adminURL, strings.Split(brokerList, ",")
+	}
+	brokers, err := startKafka()
+	if errors.Is(err, errNoContainers) {
+		t.Skipf("%s is not set: %v", EnvKafkaBrokers, err)
+	} else if err != nil {
+		t.Fatalf("failed to start Kafka: %v", err)
+	}
+	return adminURL, brokers
+}
+
+// Container creates the dependency container of a service over the test's
+// configuration, database and logger. It is shut down when the test ends;
+// the test wires it with the service's setup function and starts it.
+func (e *Env) Container(t testing.TB, serviceName string) *bootstrap.Container {
+	t.Helper()
+	c, err := bootstrap.NewContainer(serviceName, bootstrap.WithConfig(e.Config), bootstrap.WithDB(e.DB), bootstrap.WithLogger(e.Logger))
+	if err != nil {
+		t.Fatalf("NewContainer() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+		defer cancel()
+		if err := c.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+	})
+	return c
+}
+
+// Queries returns the sqlc queries of the test's database
+func (e *Env) Queries() *database.Queries {
+	return database.New(e.DB)
+}
+
+// Publish sends value to a topic as JSON, as the services' producers do
+func (e *Env) Publish(t testing.TB, topic, key string, value interface{}) {
+	t.Helper()
+	producer, err := sharedkafka.NewProducer(e.Config.Kafka, e.Logger)
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+	defer cancel()
+	if err := producer.SendMessage(ctx, topic, key, value, nil); err != nil {
+		t.Fatalf("failed to publish to %s: %v", topic, err)
+	}
+}
+
+// WaitForMessage reads a topic from its first message until match accepts
+// one, failing the test after WaitTimeout
+func (e *Env) WaitForMessage(t testing.TB, topic string, match func(kafka.Message) bool) kafka.Message {
+	t.Helper()
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     e.Config.Kafka.Brokers,
+		Topic:       topic,
+		GroupID:     "it-reader-" + randomSuffix(),
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     100 * time.Millisecond,
+	})
+	defer reader.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+	defer cancel()
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			t.Fatalf("no matching message on %s: %v", topic, err)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+// Eventually calls check until it returns nil, failing the test with its
+// last error after WaitTimeout
+func Eventually(t testing.TB, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(WaitTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met after %s: %v", WaitTimeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// databaseConfig returns the configuration of database name on the server
+// of a connection URL
+func databaseConfig(databaseURL, name string) (config.DatabaseConfig, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return config.DatabaseConfig{}, err
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return config.DatabaseConfig{}, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	port := 5432
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return config.DatabaseConfig{}, fmt.Errorf("invalid port %q", p)
+		}
+	}
+	password, _ := u.User.Password()
+	sslMode := u.Query().Get("sslmode")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	return config.DatabaseConfig{
+		Host:         u.Hostname(),
+		Port:         port,
+		User:         u.User.Username(),
+		Password:     password,
+		DBName:       name,
+		SSLMode:      sslMode,
+		MaxOpenConns: 10,
+		MaxIdleConns: 5,
+	}, nil
+}
+
+// createDatabase creates the test's database once the server accepts
+// connections, and drops it when the test ends
+func createDatabase(t testing.TB, adminURL string, cfg config.DatabaseConfig) *sql.DB {
+	t.Helper()
+	admin, err := sql.Open("postgres", adminURL)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", EnvDatabaseURL, err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	retry(t, "Postgres", func(ctx context.Context) error {
+		_, err := admin.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %q", cfg.DBName))
+		return err
+	})
+
+	db, err := database.ConnectURL(cfg.URL(), cfg.MaxOpenConns, cfg.MaxIdleConns)
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	// Cleanups run last in first out: the connections are closed first
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), WaitTimeout)
+		defer cancel()
+		if _, err := admin.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %q WITH (FORCE)", cfg.DBName)); err != nil {
+			t.Logf("failed to drop test database %s: %v", cfg.DBName, err)
+		}
+	})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// migrate applies the migrations of sql/schema, found in the closest
+// parent directory of the test's package that has one
+func migrate(t testing.TB, db *sql.DB) {
+	t.Helper()
+	dir, err := schemaDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	goose.SetLogger(goose.NopLogger())
+	t.Setenv("MIGRATIONS_DIR", dir)
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func schemaDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, "sql", "schema")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("sql/schema not found in any parent directory")
+		}
+		dir = parent
+	}
+}
+
+// createTopics creates topics with one partition once the cluster accepts
+// connections. Existing topics are kept.
+func createTopics(t testing.TB, brokers []string, topics []string) {
+	t.Helper()
+	configs := make([]kafka.TopicConfig, len(topics))
+	for i, topic := range topics {
+		configs[i] = kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1}
+	}
+
+	retry(t, "Kafka", func(ctx context.Context) error {
+		conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		controller, err := conn.Controller()
+		if err != nil {
+			return err
+		}
+		controllerConn, err := kafka.DialContext(ctx, "tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+		if err != nil {
+			return err
+		}
+		defer controllerConn.Close()
+		for _, cfg := range configs {
+			if err := controllerConn.CreateTopics(cfg); err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// retry calls fn until it succeeds, failing the test after StartTimeout
+func retry(t testing.TB, server string, fn func(ctx context.Context) error) {
+	t.Helper()
+	deadline := time.Now().Add(StartTimeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := fn(ctx)
+		cancel()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s not ready after %s: %v", server, StartTimeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// newLogger returns the logger of the services under test, writing to
+// stderr at EnvLogLevel, or nowhere
+func newLogger() *logrus.Logger {
+	logger := logrus.New()
+	level, err := logrus.ParseLevel(os.Getenv(EnvLogLevel))
+	if err != nil {
+		logger.SetOutput(io.Discard)
+		return logger
+	}
+	logger.SetOutput(os.Stderr)
+	logger.SetLevel(level)
+	return logger
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,203 @@
+// Package tlsprofile provides the TLS client profiles scrapers present to
+// sites that block Go's default TLS fingerprint (scraping.tls). A browser
+// profile sends the ClientHello of that browser, built by utls
+// (github.com/refraction-networking/utls): its cipher suites, extensions
+// and key shares in the browser's order, GREASE values included.
+//
+// Requests sent through an HTTP proxy are encrypted by net/http itself and
+// present Go's fingerprint.
+package tlsprofile
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	utls "github.com/refraction-networking/utls"
+)
+
+// Available profiles
+const (
+	ProfileGo      = "go"      // Go's defaults
+	ProfileChrome  = "chrome"  // Recent Chrome and Chromium-based browsers
+	ProfileFirefox = "firefox" // Recent Firefox
+	ProfileSafari  = "safari"  // Recent Safari on macOS and iOS
+)
+
+// profiles maps the browser profiles to the ClientHello utls parrots for
+// them. The _Auto IDs follow the latest browser version utls knows.
+var profiles = map[string]utls.ClientHelloID{
+	ProfileChrome:  utls.HelloChrome_Auto,
+	ProfileFirefox: utls.HelloFirefox_Auto,
+	ProfileSafari:  utls.HelloSafari_Auto,
+}
+
+// Names returns the names of the available profiles, sorted
+func Names() []string {
+	names := []string{ProfileGo}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// helloSpec returns the ClientHello of a browser profile. A spec holds the
+// state of one handshake, so every connection needs a fresh one.
+//
+// Browsers offer h2 first in ALPN, but net/http only speaks HTTP/2 over
+// crypto/tls connections, so the profiles offer http/1.1 alone. This is the
+// one place where the handshake differs from the browser's.
+func helloSpec(name string) (*utls.ClientHelloSpec, error) {
+	id, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS profile %q, expected one of %v", name, Names())
+	}
+	spec, err := utls.UTLSIdToSpec(id)
+	if err != nil {
+		return nil, fmt.Errorf("TLS profile %s: %w", name, err)
+	}
+	for _, ext := range spec.Extensions {
+		if alpn, ok := ext.(*utls.ALPNExtension); ok {
+			alpn.AlpnProtocols = []string{"http/1.1"}
+		}
+	}
+	return &spec, nil
+}
+
+// Transports hands out an HTTP transport per profile, choosing the profile
+// of each URL by the scraping.tls settings
+type Transports struct {
+	cfg     config.TLSConfig
+	rootCAs *x509.CertPool // Verifies servers of browser profiles, the system roots when nil
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+	dial       func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// NewTransports creates the transports for the scraping.tls settings. It
+// returns an error if a setting names an unknown profile.
+func NewTransports(cfg config.TLSConfig) (*Transports, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	t := &Transports{cfg: cfg, transports: make(map[string]*http.Transport)}
+	names := []string{cfg.DefaultProfile}
+	for _, d := range cfg.Domains {
+		names = append(names, d.Profile)
+	}
+	for _, name := range names {
+		if _, err := t.transport(strings.ToLower(strings.TrimSpace(name))); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Profile returns the name of the profile used for a URL
+func (t *Transports) Profile(rawURL string) string {
+	if name := t.cfg.Profile(rawURL); name != "" {
+		return name
+	}
+	return ProfileGo
+}
+
+// ForURL returns the transport to fetch a URL with
+func (t *Transports) ForURL(rawURL string) *http.Transport {
+	transport, _ := t.transport(t.Profile(rawURL)) // Profiles were checked by NewTransports
+	return transport
+}
+
+// SetDialContext makes every transport dial connections with dial, such as
+// the DialContext of a netprofile.Dialer or dnscache.Resolver
+func (t *Transports) SetDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dial = dial
+	for _, transport := range t.transports {
+		transport.DialContext = dial
+	}
+}
+
+// CloseIdleConnections closes the idle connections of every transport
+func (t *Transports) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// transport returns the transport of a profile, creating it on first use
+func (t *Transports) transport(name string) (*http.Transport, error) {
+	if name == "" {
+		name = ProfileGo
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if transport, ok := t.transports[name]; ok {
+		return transport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{}
+	if name != ProfileGo {
+		if _, err := helloSpec(name); err != nil {
+			return nil, err
+		}
+		transport.DialTLSContext = t.dialTLS(name)
+		transport.ForceAttemptHTTP2 = false
+	}
+	if t.dial != nil {
+		transport.DialContext = t.dial
+	}
+	t.transports[name] = transport
+	return transport, nil
+}
+
+// dialTLS returns a function dialing TLS connections that open with the
+// ClientHello of a browser profile
+func (t *Transports) dialTLS(name string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		spec, err := helloSpec(name)
+		if err != nil {
+			return nil, err
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		t.mu.Lock()
+		dial := t.dial
+		t.mu.Unlock()
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := utls.UClient(conn, &utls.Config{ServerName: host, RootCAs: t.rootCAs}, utls.HelloCustom)
+		if err := tlsConn.ApplyPreset(spec); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS profile %s: %w", name, err)
+		}
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
@@ -0,0 +1,98 @@
+package tlsprofile
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"go_scraping_project/shared/config"
+)
+
+func TestTransportsSelectProfileByDomain(t *testing.T) {
+	transports, err := NewTransports(config.TLSConfig{
+		DefaultProfile: "Chrome",
+		Domains: []config.TLSDomainProfileConfig{
+			{Domain: "example.com", Profile: "firefox"},
+			{Domain: "shop.example.com", Profile: "safari"},
+			{Domain: "api.example.org", Profile: "go"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTransports() error = %v", err)
+	}
+
+	for rawURL, want := range map[string]string{
+		"https://example.com/":         ProfileFirefox,
+		"https://www.example.com/":     ProfileFirefox,
+		"https://eu.shop.example.com/": ProfileSafari,
+		"https://api.example.org/v1":   ProfileGo,
+		"https://example.org/":         ProfileChrome,
+	} {
+		if got := transports.Profile(rawURL); got != want {
+			t.Errorf("Profile(%s) = %s, want %s", rawURL, got, want)
+		}
+	}
+	if transports.ForURL("https://example.com/") != transports.ForURL("https://www.example.com/") {
+		t.Error("URLs with the same profile got different transports")
+	}
+
+	invalid := []config.TLSConfig{
+		{DefaultProfile: "netscape"},
+		{Domains: []config.TLSDomainProfileConfig{{Domain: "example.com", Profile: "opera"}}},
+		{Domains: []config.TLSDomainProfileConfig{{Domain: "https://example.com", Profile: "chrome"}}},
+		{Domains: []config.TLSDomainProfileConfig{{Domain: "example.com"}}},
+	}
+	for _, cfg := range invalid {
+		if _, err := NewTransports(cfg); err == nil {
+			t.Errorf("NewTransports(%+v) accepted an invalid config", cfg)
+		}
+	}
+}
+
+func TestProfileHandshake(t *testing.T) {
+	hellos := make(chan *tls.ClientHelloInfo, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		hellos <- hello
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+
+	transports, err := NewTransports(config.TLSConfig{DefaultProfile: ProfileChrome})
+	if err != nil {
+		t.Fatalf("NewTransports() error = %v", err)
+	}
+	transports.rootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	resp, err := (&http.Client{Transport: transports.ForURL(server.URL)}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// crypto/tls would reorder the suites; utls sends the browser's order
+	spec, _ := helloSpec(ProfileChrome)
+	hello := <-hellos
+	if got, want := withoutGREASE(hello.CipherSuites), withoutGREASE(spec.CipherSuites); !reflect.DeepEqual(got, want) {
+		t.Errorf("cipher suites = %x, want %x", got, want)
+	}
+	if len(hello.CipherSuites) == len(withoutGREASE(hello.CipherSuites)) {
+		t.Error("ClientHello has no GREASE cipher suite")
+	}
+	if !reflect.DeepEqual(hello.SupportedProtos, []string{"http/1.1"}) {
+		t.Errorf("ALPN = %v", hello.SupportedProtos)
+	}
+}
+
+// withoutGREASE drops the GREASE values, which are random per handshake
+func withoutGREASE(values []uint16) []uint16 {
+	var kept []uint16
+	for _, v := range values {
+		if v&0x0f0f != 0x0a0a {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
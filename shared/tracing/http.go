@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// statusRecorder records the status code a handler writes
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware runs every HTTP request in a root span named after its method
+// and path. Requests answered with a 5xx status are failed spans, so their
+// traces are kept when errors are kept.
+func Middleware(tracer *Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := tracer.Start(r.Context(), "HTTP "+r.Method+" "+r.URL.Path)
+			defer span.End()
+			span.SetAttribute("http.request.method", r.Method)
+			span.SetAttribute("url.path", r.URL.Path)
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttribute("http.response.status_code", fmt.Sprint(recorder.status))
+			if recorder.status >= http.StatusInternalServerError {
+				span.RecordError(fmt.Errorf("request answered with status %d", recorder.status))
+			}
+		})
+	}
+}
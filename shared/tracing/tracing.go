@@ -0,0 +1,296 @@
+// Package tracing records traces of message handlers and HTTP requests and
+// samples them when they end (tail-based sampling), so tracing can run in
+// production at a low sample rate while keeping every trace worth looking
+// at: those with a failed span and those that were slow. Tracer implements
+// kafka.Tracer, see kafka.Observability, and Middleware traces HTTP
+// requests. Kept traces go to an Exporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/kafka"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxSpansPerTrace bounds the spans buffered for a trace until it ends;
+// later spans are counted in the root span's tracing.dropped_spans attribute
+const MaxSpansPerTrace = 512
+
+// SpanData is a finished span
+type SpanData struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_id,omitempty"` // Empty for the root span
+	Service    string            `json:"service"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	Duration   time.Duration     `json:"duration"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"` // The last error recorded
+}
+
+// Exporter receives the spans of kept traces, root span last
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Sampler decides which traces are kept, see config.TracingConfig
+type Sampler struct {
+	Rate           float64
+	KeepErrors     bool
+	KeepSlowerThan time.Duration
+}
+
+// NewSampler creates the sampler of a tracing configuration
+func NewSampler(cfg config.TracingConfig) Sampler {
+	return Sampler{Rate: cfg.SampleRate, KeepErrors: cfg.KeepErrors, KeepSlowerThan: cfg.KeepSlowerThan}
+}
+
+// Sampled reports whether a trace is kept at the sample rate. The decision
+// depends on the trace ID only, so services sharing a trace agree on it.
+func (s Sampler) Sampled(traceID string) bool {
+	if s.Rate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(traceID))
+	// Mix the hash, whose high bits barely change with the last characters
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return float64(x>>11)/(1<<53) < s.Rate
+}
+
+// tailBased reports whether traces that are not sampled must still be
+// recorded, because they may be kept when they end
+func (s Sampler) tailBased() bool {
+	return s.Rate < 1 && (s.KeepErrors || s.KeepSlowerThan > 0)
+}
+
+// Keep reports whether a finished trace is kept: it failed and errors are
+// kept, it lasted long enough, or it is sampled
+func (s Sampler) Keep(traceID string, failed bool, duration time.Duration) bool {
+	switch {
+	case failed && s.KeepErrors:
+		return true
+	case s.KeepSlowerThan > 0 && duration >= s.KeepSlowerThan:
+		return true
+	}
+	return s.Sampled(traceID)
+}
+
+// Tracer records spans and exports the traces its sampler keeps when their
+// root span ends. Spans ending after their root span are left out.
+type Tracer struct {
+	service  string
+	exporter Exporter
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	sampler Sampler
+	traces  map[string]*trace // Recorded traces whose root span has not ended
+}
+
+// trace is a recorded trace whose root span has not ended
+type trace struct {
+	spans   []SpanData
+	dropped int
+	failed  bool
+}
+
+// NewTracer creates a tracer of a service that samples traces as configured
+// and exports the kept ones
+func NewTracer(cfg config.TracingConfig, exporter Exporter, logger *logrus.Logger) *Tracer {
+	return &Tracer{
+		service:  cfg.ServiceName,
+		exporter: exporter,
+		logger:   logger,
+		sampler:  NewSampler(cfg),
+		traces:   make(map[string]*trace),
+	}
+}
+
+// Configure applies the sampling settings of a new configuration to the
+// traces started from now on
+func (t *Tracer) Configure(cfg config.TracingConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sampler = NewSampler(cfg)
+}
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// Start starts a span, a child of the span in ctx or the root of a new trace
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, kafka.Span) {
+	s := &span{tracer: t, data: SpanData{SpanID: newID(8), Service: t.service, Name: name, Start: time.Now()}}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.data.TraceID = parent.data.TraceID
+		s.data.ParentID = parent.data.SpanID
+		s.recorded = parent.recorded
+	} else {
+		s.data.TraceID = newID(16)
+		t.mu.Lock()
+		if t.sampler.Sampled(s.data.TraceID) || t.sampler.tailBased() {
+			s.recorded = true
+			t.traces[s.data.TraceID] = &trace{}
+		}
+		t.mu.Unlock()
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// end records a finished span and, for a root span, decides whether its
+// trace is kept and exports it
+func (t *Tracer) end(data SpanData) {
+	t.mu.Lock()
+	tr, ok := t.traces[data.TraceID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	tr.failed = tr.failed || data.Error != ""
+	root := data.ParentID == ""
+	if root && tr.dropped > 0 {
+		data.Attributes["tracing.dropped_spans"] = strconv.Itoa(tr.dropped)
+	}
+	if len(tr.spans) < MaxSpansPerTrace || root {
+		tr.spans = append(tr.spans, data)
+	} else {
+		tr.dropped++
+	}
+	if !root {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.traces, data.TraceID)
+	keep := t.sampler.Keep(data.TraceID, tr.failed, data.Duration)
+	t.mu.Unlock()
+
+	if !keep {
+		return
+	}
+	if err := t.exporter.Export(context.Background(), tr.spans); err != nil {
+		t.logger.WithError(err).WithField("trace_id", data.TraceID).Warn("Failed to export trace")
+	}
+}
+
+// span is a span started by a Tracer
+type span struct {
+	tracer   *Tracer
+	recorded bool // Whether the span's trace is recorded
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SetAttribute sets an attribute of the span
+func (s *span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed, which keeps its trace when errors
+// are kept
+func (s *span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span; later calls do nothing
+func (s *span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.Duration = time.Since(s.data.Start)
+	data := s.data
+	data.Attributes = make(map[string]string, len(s.data.Attributes))
+	for key, value := range s.data.Attributes {
+		data.Attributes[key] = value
+	}
+	s.mu.Unlock()
+
+	if s.recorded {
+		s.tracer.end(data)
+	}
+}
+
+// RecordError marks the current span of ctx as failed, for failures that
+// are handled without returning an error, such as a failed scrape whose
+// result was recorded. It does nothing when ctx has no span.
+func RecordError(ctx context.Context, err error) {
+	if s, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.RecordError(err)
+	}
+}
+
+// TraceID returns the ID of the trace of the current span of ctx, or an
+// empty string
+func TraceID(ctx context.Context) string {
+	if s, ok := ctx.Value(spanKey{}).(*span); ok {
+		return s.data.TraceID
+	}
+	return ""
+}
+
+// LogExporter exports spans as log entries, one per span
+type LogExporter struct {
+	logger *logrus.Logger
+}
+
+// NewLogExporter creates an exporter that logs spans at info level
+func NewLogExporter(logger *logrus.Logger) *LogExporter {
+	return &LogExporter{logger: logger}
+}
+
+// Export logs every span with its trace, parent, duration and attributes
+func (e *LogExporter) Export(ctx context.Context, spans []SpanData) error {
+	for _, s := range spans {
+		fields := logrus.Fields{
+			"trace_id":    s.TraceID,
+			"span_id":     s.SpanID,
+			"service":     s.Service,
+			"duration_ms": s.Duration.Milliseconds(),
+		}
+		if s.ParentID != "" {
+			fields["parent_id"] = s.ParentID
+		}
+		if s.Error != "" {
+			fields["error"] = s.Error
+		}
+		for key, value := range s.Attributes {
+			fields[key] = value
+		}
+		e.logger.WithFields(fields).Info("span " + s.Name)
+	}
+	return nil
+}
+
+// newID returns a random hexadecimal ID of n bytes
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
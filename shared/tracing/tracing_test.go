@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingExporter keeps the traces it exports
+type recordingExporter struct {
+	mu     sync.Mutex
+	traces [][]SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.traces = append(e.traces, spans)
+	return nil
+}
+
+func newTestTracer(cfg config.TracingConfig) (*Tracer, *recordingExporter) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	exporter := &recordingExporter{}
+	cfg.ServiceName = "url-manager"
+	return NewTracer(cfg, exporter, logger), exporter
+}
+
+func TestSampler(t *testing.T) {
+	sampler := Sampler{Rate: 0.1}
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("%032x", i)
+		if sampler.Sampled(id) != sampler.Sampled(id) {
+			t.Fatal("Sampled() is not deterministic")
+		}
+		if sampler.Sampled(id) {
+			sampled++
+		}
+	}
+	if sampled < 800 || sampled > 1200 {
+		t.Errorf("sampled %d of 10000 traces at rate 0.1", sampled)
+	}
+	if (Sampler{Rate: 0}).Sampled("a") || !(Sampler{Rate: 1}).Sampled("a") {
+		t.Error("rates 0 and 1 do not drop and keep every trace")
+	}
+
+	tail := Sampler{KeepErrors: true, KeepSlowerThan: time.Second}
+	if !tail.Keep("a", true, 0) || !tail.Keep("a", false, 2*time.Second) || tail.Keep("a", false, time.Millisecond) {
+		t.Error("Keep() does not keep failed and slow traces only")
+	}
+}
+
+func TestTracerKeepsFailedTraces(t *testing.T) {
+	tracer, exporter := newTestTracer(config.TracingConfig{SampleRate: 0, KeepErrors: true})
+
+	for _, fail := range []bool{false, true} {
+		ctx, root := tracer.Start(context.Background(), "kafka.handle scrape_result")
+		root.SetAttribute("messaging.system", "kafka")
+		_, child := tracer.Start(ctx, "db.complete_task")
+		child.End()
+		if fail {
+			RecordError(ctx, errors.New("scrape failed: timeout"))
+		}
+		root.End()
+		root.End()
+	}
+
+	if len(exporter.traces) != 1 {
+		t.Fatalf("exported %d traces, want only the failed one", len(exporter.traces))
+	}
+	spans := exporter.traces[0]
+	if len(spans) != 2 || spans[1].Name != "kafka.handle scrape_result" || spans[1].Error != "scrape failed: timeout" {
+		t.Fatalf("spans = %+v, want the child and the failed root last", spans)
+	}
+	if spans[0].TraceID != spans[1].TraceID || spans[0].ParentID != spans[1].SpanID || spans[1].Service != "url-manager" {
+		t.Errorf("spans = %+v, want one trace with the child under the root", spans)
+	}
+	if len(tracer.traces) != 0 {
+		t.Errorf("%d traces still buffered", len(tracer.traces))
+	}
+}
+
+func TestTracerHeadSampling(t *testing.T) {
+	tracer, exporter := newTestTracer(config.TracingConfig{SampleRate: 0})
+	ctx, root := tracer.Start(context.Background(), "kafka.handle scrape_result")
+	root.RecordError(errors.New("failed"))
+	if len(tracer.traces) != 0 {
+		t.Error("trace recorded though it can never be kept")
+	}
+	if TraceID(ctx) == "" {
+		t.Error("unrecorded span has no trace ID")
+	}
+	root.End()
+
+	tracer.Configure(config.TracingConfig{SampleRate: 1})
+	_, root = tracer.Start(context.Background(), "kafka.handle scrape_result")
+	root.End()
+	if len(exporter.traces) != 1 {
+		t.Errorf("exported %d traces, want the one started at rate 1", len(exporter.traces))
+	}
+}
+
+func TestTracerBoundsSpans(t *testing.T) {
+	tracer, exporter := newTestTracer(config.TracingConfig{SampleRate: 1})
+	ctx, root := tracer.Start(context.Background(), "import")
+	for i := 0; i < MaxSpansPerTrace+5; i++ {
+		_, span := tracer.Start(ctx, "row")
+		span.End()
+	}
+	root.End()
+
+	spans := exporter.traces[0]
+	if len(spans) != MaxSpansPerTrace+1 || spans[len(spans)-1].Attributes["tracing.dropped_spans"] != "5" {
+		t.Errorf("exported %d spans, root attributes %v; want %d and 5 dropped", len(spans), spans[len(spans)-1].Attributes, MaxSpansPerTrace+1)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tracer, exporter := newTestTracer(config.TracingConfig{SampleRate: 0, KeepErrors: true})
+	handler := Middleware(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if TraceID(r.Context()) == "" {
+			t.Error("request context has no span")
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+
+	for _, path := range []string{"/api/v1/urls", "/broken"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if len(exporter.traces) != 1 {
+		t.Fatalf("exported %d traces, want the failed request only", len(exporter.traces))
+	}
+	span := exporter.traces[0][0]
+	if span.Name != "HTTP GET /broken" || span.Attributes["http.response.status_code"] != "502" {
+		t.Errorf("span = %+v, want the 502 request", span)
+	}
+}
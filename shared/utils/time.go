@@ -2,9 +2,23 @@ package utils
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
+// MinFrequency is the shortest scraping frequency a URL may have
+const MinFrequency = 30 * time.Second
+
+// frequencyUnits are the units of a scraping frequency
+var frequencyUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
 // Time utilities for consistent timezone handling across services
 
 // Now returns the current time in UTC
@@ -35,9 +49,56 @@ func ParseDuration(durationStr string) (time.Duration, error) {
 	return duration, nil
 }
 
+// ParseFrequency parses a scraping frequency: a positive whole number
+// followed by one of the units s, m, h, d (24 hours) or w (7 days), such as
+// "45m" or "3d". Frequencies shorter than MinFrequency are rejected. The
+// API Gateway validates and the URL Manager schedules with this one parser,
+// so every frequency accepted on creation can be scheduled.
+func ParseFrequency(frequency string) (time.Duration, error) {
+	if frequency == "" {
+		return 0, fmt.Errorf("frequency is required")
+	}
+	unit, ok := frequencyUnits[frequency[len(frequency)-1]]
+	if !ok {
+		return 0, fmt.Errorf("frequency must end with a valid unit (s, m, h, d, w)")
+	}
+	number := frequency[:len(frequency)-1]
+	if number == "" {
+		return 0, fmt.Errorf("frequency must include a numeric value")
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("frequency must be a whole number followed by a unit")
+		}
+	}
+	value, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || value > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("frequency is too long")
+	}
+	if value == 0 {
+		return 0, fmt.Errorf("frequency value must be positive")
+	}
+	duration := time.Duration(value) * unit
+	if duration < MinFrequency {
+		return 0, fmt.Errorf("minimum frequency is %s", MinFrequency)
+	}
+	return duration, nil
+}
+
+// FormatFrequency formats a duration as a frequency in the largest unit
+// that divides it, e.g. "3d" for 72 hours
+func FormatFrequency(d time.Duration) string {
+	for _, suffix := range []byte{'w', 'd', 'h', 'm'} {
+		if unit := frequencyUnits[suffix]; d != 0 && d%unit == 0 {
+			return strconv.FormatInt(int64(d/unit), 10) + string(suffix)
+		}
+	}
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+}
+
 // CalculateNextScrapeTime calculates the next scrape time based on frequency
 func CalculateNextScrapeTime(frequency string, from time.Time) (time.Time, error) {
-	duration, err := ParseDuration(frequency)
+	duration, err := ParseFrequency(frequency)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFrequency(t *testing.T) {
+	valid := map[string]time.Duration{
+		"30s":  30 * time.Second,
+		"90s":  90 * time.Second,
+		"45m":  45 * time.Minute,
+		"2h":   2 * time.Hour,
+		"3d":   72 * time.Hour,
+		"1w":   7 * 24 * time.Hour,
+		"007m": 7 * time.Minute,
+	}
+	for frequency, want := range valid {
+		if got, err := ParseFrequency(frequency); err != nil || got != want {
+			t.Errorf("ParseFrequency(%q) = %v, %v; want %v", frequency, got, err, want)
+		}
+	}
+
+	for _, frequency := range []string{"", "h", "10", "1y", "0m", "-5m", "+5m", "1.5h", "29s", "1h30m", "99999999999999w"} {
+		if _, err := ParseFrequency(frequency); err == nil {
+			t.Errorf("ParseFrequency(%q) accepted an invalid frequency", frequency)
+		}
+	}
+}
+
+func TestFormatFrequency(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:    "30s",
+		90 * time.Minute:    "90m",
+		72 * time.Hour:      "3d",
+		14 * 24 * time.Hour: "2w",
+	}
+	for d, want := range tests {
+		if got := FormatFrequency(d); got != want {
+			t.Errorf("FormatFrequency(%v) = %q, want %q", d, got, want)
+		}
+		if parsed, err := ParseFrequency(want); err != nil || parsed != d {
+			t.Errorf("ParseFrequency(%q) = %v, %v; want %v", want, parsed, err, d)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package validation checks request structs by their validate struct tags,
+// e.g. `validate:"required,url,scheme=http https"`, with
+// go-playground/validator, so handlers do not repeat presence and format
+// checks. Besides the validator's built-in rules the package registers the
+// rules of this project: frequency, scheme and selector. Failures name the
+// field by its JSON path, e.g. parser_config.rules[0].selector.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"go_scraping_project/shared/parser"
+	"go_scraping_project/shared/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a failed rule of a field
+type FieldError struct {
+	Field   string // Path of the field by JSON names, e.g. parser_config.rules[0].selector
+	Rule    string // The rule that failed, e.g. required
+	Message string // Human-readable message naming the field
+}
+
+// Error returns the message
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// validate is the validator with the rules of this project, safe for
+// concurrent use
+var validate = New()
+
+// New creates a validator that names fields by their JSON names and knows
+// the rules of this project
+func New() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	for name, fn := range map[string]validator.Func{
+		"frequency": isFrequency,
+		"scheme":    hasScheme,
+		"selector":  isSelector,
+	} {
+		if err := v.RegisterValidation(name, fn); err != nil {
+			panic(fmt.Sprintf("validation: register %s: %v", name, err))
+		}
+	}
+	return v
+}
+
+// Struct checks the validate tags of a struct, or a pointer to one, and
+// returns the first failure as a *FieldError
+func Struct(v interface{}) error {
+	return fieldError("", validate.Struct(v))
+}
+
+// Field checks a single value, reported as the named field, against the
+// rules of a tag, e.g. Field("url", raw, "required,url")
+func Field(name string, value interface{}, tag string) error {
+	return fieldError(name, validate.Var(value, tag))
+}
+
+// fieldError converts the first failure of a validator error to a
+// *FieldError. Fields checked on their own have no path and are named name.
+func fieldError(name string, err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return err
+	}
+	fe := errs[0]
+
+	// The namespace starts with the name of the struct type
+	path := name
+	if _, field, ok := strings.Cut(fe.Namespace(), "."); ok {
+		path = field
+	}
+	return &FieldError{Field: path, Rule: fe.Tag(), Message: message(path, fe)}
+}
+
+// message describes a failed rule, naming the field
+func message(path string, fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return path + " is required"
+	case "url":
+		return path + " must be an absolute URL with scheme and host"
+	case "min":
+		return path + " must be at least " + bound(fe.Kind(), param)
+	case "max":
+		return path + " must be at most " + bound(fe.Kind(), param)
+	case "oneof":
+		return path + " must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "scheme":
+		return path + " scheme must be " + strings.Join(schemes(param), " or ")
+	case "frequency":
+		_, err := utils.ParseFrequency(fmt.Sprint(fe.Value()))
+		return path + " is invalid: " + errorText(err)
+	case "selector":
+		_, err := parser.CompileSelector(fmt.Sprint(fe.Value()))
+		return path + " is invalid: " + errorText(err)
+	}
+	return fmt.Sprintf("%s failed the %s rule", path, fe.Tag())
+}
+
+// errorText returns the text of an error, if any
+func errorText(err error) string {
+	if err == nil {
+		return "invalid value"
+	}
+	return err.Error()
+}
+
+// bound describes a min or max limit of a value of a kind
+func bound(kind reflect.Kind, limit string) string {
+	switch kind {
+	case reflect.String:
+		return limit + " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return limit + " items"
+	}
+	return limit
+}
+
+// schemes returns the space-separated schemes of a scheme rule's
+// parameter, http and https when empty
+func schemes(param string) []string {
+	if allowed := strings.Fields(param); len(allowed) > 0 {
+		return allowed
+	}
+	return []string{"http", "https"}
+}
+
+// hasScheme checks that a URL's scheme is one of the schemes of the rule's
+// parameter
+func hasScheme(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	for _, scheme := range schemes(fl.Param()) {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFrequency checks that a string is a scraping frequency such as 30m or
+// 1d, as parsed by the URL Manager's scheduler
+func isFrequency(fl validator.FieldLevel) bool {
+	_, err := utils.ParseFrequency(fl.Field().String())
+	return err == nil
+}
+
+// isSelector checks that a string is a CSS selector supported by the parser
+func isSelector(fl validator.FieldLevel) bool {
+	_, err := parser.CompileSelector(fl.Field().String())
+	return err == nil
+}
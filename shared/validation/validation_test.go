@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type rule struct {
+	Name     string `json:"name" validate:"required"`
+	Selector string `json:"selector" validate:"required,selector"`
+}
+
+type request struct {
+	URL       string            `json:"url" validate:"required,url,scheme=http https"`
+	Frequency string            `json:"frequency,omitempty" validate:"omitempty,frequency"`
+	Format    string            `json:"format,omitempty" validate:"omitempty,oneof=json csv"`
+	Timeout   int               `json:"timeout" validate:"min=0,max=300"`
+	Tags      []string          `json:"tags" validate:"max=2,dive,min=1"`
+	Selectors map[string]string `json:"selectors" validate:"dive,selector"`
+	Rules     []rule            `json:"rules" validate:"dive"`
+	Retry     *struct {
+		Attempts int `json:"attempts" validate:"max=10"`
+	} `json:"retry,omitempty"`
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+func TestStruct(t *testing.T) {
+	enabled := false
+	valid := func() request {
+		return request{URL: "https://example.com/news", Frequency: "1h", Enabled: &enabled}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(r *request)
+		wantField string
+		wantRule  string
+	}{
+		{"valid", func(r *request) {}, "", ""},
+		{"missing url", func(r *request) { r.URL = "" }, "url", "required"},
+		{"relative url", func(r *request) { r.URL = "/news" }, "url", "url"},
+		{"scheme not allowed", func(r *request) { r.URL = "file://example.com/etc/passwd" }, "url", "scheme"},
+		{"invalid frequency", func(r *request) { r.Frequency = "hourly" }, "frequency", "frequency"},
+		{"empty frequency", func(r *request) { r.Frequency = "" }, "", ""},
+		{"format not allowed", func(r *request) { r.Format = "xml" }, "format", "oneof"},
+		{"negative timeout", func(r *request) { r.Timeout = -1 }, "timeout", "min"},
+		{"timeout too long", func(r *request) { r.Timeout = 301 }, "timeout", "max"},
+		{"too many tags", func(r *request) { r.Tags = []string{"a", "b", "c"} }, "tags", "max"},
+		{"empty tag", func(r *request) { r.Tags = []string{"a", ""} }, "tags[1]", "min"},
+		{"invalid selector", func(r *request) { r.Selectors = map[string]string{"title": "h1", "price": "["} }, "selectors[price]", "selector"},
+		{"nested rule", func(r *request) { r.Rules = []rule{{Name: "price", Selector: ".price"}, {Name: "title"}} }, "rules[1].selector", "required"},
+		{"nested pointer", func(r *request) {
+			r.Retry = &struct {
+				Attempts int `json:"attempts" validate:"max=10"`
+			}{Attempts: 11}
+		}, "retry.attempts", "max"},
+		{"missing pointer", func(r *request) { r.Enabled = nil }, "enabled", "required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			err := Struct(&req)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Struct() error = %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField || fieldErr.Rule != tt.wantRule {
+				t.Fatalf("Struct() error = %#v, want %s failing %s", err, tt.wantField, tt.wantRule)
+			}
+			if !strings.HasPrefix(fieldErr.Error(), tt.wantField+" ") {
+				t.Errorf("message %q does not name the field", fieldErr.Error())
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	if err := Field("url", "https://example.com", "required,url,scheme=https"); err != nil {
+		t.Errorf("Field() error = %v", err)
+	}
+	err := Field("url", "http://example.com", "required,url,scheme=https")
+	if err == nil || err.Error() != "url scheme must be https" {
+		t.Errorf("Field() error = %v, want the scheme error", err)
+	}
+	err = Field("frequency", "hourly", "frequency")
+	if err == nil || !strings.HasPrefix(err.Error(), "frequency is invalid: ") || err.Error() == "frequency is invalid: invalid value" {
+		t.Errorf("Field() error = %v, want the frequency parse error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler serves the admin endpoints of a worker pool
+type Handler struct {
+	Logger   *logrus.Logger
+	Pool     *Pool
+	Throttle *Throttle // Optional; its stats are served when set
+}
+
+// NewHandler creates a new worker pool handler with the provided logger, pool
+// and throttle. Instances that do not throttle pass a nil throttle.
+func NewHandler(logger *logrus.Logger, pool *Pool, throttle *Throttle) *Handler {
+	return &Handler{
+		Logger:   logger,
+		Pool:     pool,
+		Throttle: throttle,
+	}
+}
+
+// ResizeRequest is the body of PUT /api/v1/admin/pool/size
+type ResizeRequest struct {
+	Size int `json:"size"`
+}
+
+// GetStatus handles GET /api/v1/admin/pool
+//
+// Purpose: Reports the pool size, whether it is paused, the queued tasks
+// and each worker's ID, state and task counters.
+//
+// Response: Status (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/pool
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Pool.Status())
+}
+
+// Pause handles POST /api/v1/admin/pool/pause
+//
+// Purpose: Stops workers from taking new tasks, e.g. while a target site
+// is struggling. Running fetches finish; queued tasks wait.
+//
+// Response: Status (200 OK)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/pool/pause
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.Pool.Pause()
+	h.GetStatus(w, r)
+}
+
+// Resume handles POST /api/v1/admin/pool/resume
+//
+// Purpose: Lets workers take tasks again after a pause.
+//
+// Response: Status (200 OK)
+//
+// Example Usage:
+//
+//	POST /api/v1/admin/pool/resume
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.Pool.Resume()
+	h.GetStatus(w, r)
+}
+
+// Resize handles PUT /api/v1/admin/pool/size
+//
+// Purpose: Changes the number of workers until the next restart or
+// configuration reload. Removed workers finish their current fetch first.
+//
+// Request Body: ResizeRequest
+//
+// Response: Status (200 OK), or error (400 for an invalid size)
+//
+// Example Usage:
+//
+//	PUT /api/v1/admin/pool/size
+//	{"size": 20}
+func (h *Handler) Resize(w http.ResponseWriter, r *http.Request) {
+	var req ResizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.Pool.Resize(req.Size); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.GetStatus(w, r)
+}
+
+// GetThrottle handles GET /api/v1/admin/pool/throttle
+//
+// Purpose: Reports the domain rate limit and how many requests waited for
+// URL and domain rate limits, and for how long in total, to tell a slow
+// scraper from a throttled one, along with the hosts backed off after 429
+// and 503 responses.
+//
+// Response: ThrottleStats (200 OK)
+//
+// Example Usage:
+//
+//	GET /api/v1/admin/pool/throttle
+func (h *Handler) GetThrottle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Throttle.Stats())
+}
+
+// Register adds the pool's admin routes to mux
+//
+// Routes Configured:
+//   - GET /api/v1/admin/pool - Pool and worker status
+//   - POST /api/v1/admin/pool/pause - Stop taking new tasks
+//   - POST /api/v1/admin/pool/resume - Take tasks again
+//   - PUT /api/v1/admin/pool/size - Change the number of workers
+//   - GET /api/v1/admin/pool/throttle - Rate limit waits, when a throttle is set
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/admin/pool", h.route(http.MethodGet, h.GetStatus))
+	mux.HandleFunc("/api/v1/admin/pool/pause", h.route(http.MethodPost, h.Pause))
+	mux.HandleFunc("/api/v1/admin/pool/resume", h.route(http.MethodPost, h.Resume))
+	mux.HandleFunc("/api/v1/admin/pool/size", h.route(http.MethodPut, h.Resize))
+	if h.Throttle != nil {
+		mux.HandleFunc("/api/v1/admin/pool/throttle", h.route(http.MethodGet, h.GetThrottle))
+	}
+}
+
+// route rejects requests with a method other than method
+func (h *Handler) route(method string, handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handle(w, r)
+	}
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Instance kinds recorded in the workers table
+const (
+	KindScraper = "scraper"
+	KindParser  = "parser"
+)
+
+// Heartbeat timing
+const (
+	DefaultHeartbeatInterval = 15 * time.Second
+	// StaleWorkerRetention is how long the rows of instances that died are
+	// kept, so they stay visible in the fleet status for a while
+	StaleWorkerRetention = 24 * time.Hour
+)
+
+// HeartbeatStore persists instance heartbeats. *database.Queries implements it.
+type HeartbeatStore interface {
+	RecordWorkerHeartbeat(ctx context.Context, arg database.RecordWorkerHeartbeatParams) error
+	DeleteWorker(ctx context.Context, id string) error
+	DeleteStaleWorkers(ctx context.Context, lastHeartbeatAt time.Time) (int64, error)
+}
+
+// PausedTopicsSource reports the topics an instance stopped consuming.
+// *kafka.Consumer implements it.
+type PausedTopicsSource interface {
+	PausedTopics() []string
+}
+
+// Instance identifies a scraper or parser instance
+type Instance struct {
+	ID      string // Unique per running instance, e.g. the host or pod name
+	Kind    string // KindScraper or KindParser
+	Version string // Build version
+	Host    string
+	Region  string // Region the instance scrapes from, "" for none
+}
+
+// Heartbeat registers an instance in the workers table and refreshes its
+// heartbeat and current load every interval, so the fleet status API shows
+// which instances are alive. The row is removed on a clean shutdown; an
+// instance that dies leaves a row whose heartbeat grows old.
+type Heartbeat struct {
+	store     HeartbeatStore
+	instance  Instance
+	pool      *Pool
+	consumer  PausedTopicsSource
+	interval  time.Duration
+	logger    *logrus.Logger
+	startedAt time.Time
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewHeartbeat creates a heartbeat for the instance. The load reported is
+// that of pool; instances without a pool pass nil and report no load.
+func NewHeartbeat(store HeartbeatStore, instance Instance, pool *Pool, interval time.Duration, logger *logrus.Logger) *Heartbeat {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	return &Heartbeat{
+		store:    store,
+		instance: instance,
+		pool:     pool,
+		interval: interval,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// ReportPausedTopics makes the heartbeat report the topics paused on the
+// instance's consumer. Call it before Start.
+func (h *Heartbeat) ReportPausedTopics(consumer PausedTopicsSource) {
+	h.consumer = consumer
+}
+
+// Start registers the instance, removes instances that have been dead for
+// longer than StaleWorkerRetention and starts the heartbeat loop. Failing
+// heartbeats are logged; they do not stop the instance.
+func (h *Heartbeat) Start(ctx context.Context) error {
+	h.startedAt = time.Now().UTC()
+	h.beat(ctx)
+
+	removed, err := h.store.DeleteStaleWorkers(ctx, h.startedAt.Add(-StaleWorkerRetention))
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to remove stale workers")
+	} else if removed > 0 {
+		h.logger.WithField("removed", removed).Info("Removed stale workers")
+	}
+
+	go h.run()
+	return nil
+}
+
+// Stop ends the heartbeat loop and unregisters the instance
+func (h *Heartbeat) Stop(ctx context.Context) error {
+	close(h.stop)
+	<-h.done
+	return h.store.DeleteWorker(ctx, h.instance.ID)
+}
+
+// run records a heartbeat every interval until Stop
+func (h *Heartbeat) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+			h.beat(ctx)
+			cancel()
+		}
+	}
+}
+
+// beat records the instance's heartbeat, load and paused topics
+func (h *Heartbeat) beat(ctx context.Context) {
+	params := database.RecordWorkerHeartbeatParams{
+		ID:        h.instance.ID,
+		Kind:      h.instance.Kind,
+		Version:   h.instance.Version,
+		Host:      h.instance.Host,
+		Region:    h.instance.Region,
+		StartedAt: h.startedAt,
+	}
+	if h.pool != nil {
+		status := h.pool.Status()
+		params.ActiveTasks = int32(status.Busy)
+		params.Capacity = int32(status.Size)
+		params.QueuedTasks = int32(status.Queued)
+	}
+	if h.consumer != nil {
+		params.PausedTopics = h.consumer.PausedTopics()
+	}
+
+	if err := h.store.RecordWorkerHeartbeat(ctx, params); err != nil {
+		h.logger.WithError(err).WithField("worker", h.instance.ID).Warn("Failed to record worker heartbeat")
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeHeartbeatStore struct {
+	mu         sync.Mutex
+	heartbeats []database.RecordWorkerHeartbeatParams
+	deleted    []string
+	staleSince time.Time
+}
+
+func (s *fakeHeartbeatStore) RecordWorkerHeartbeat(ctx context.Context, arg database.RecordWorkerHeartbeatParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeats = append(s.heartbeats, arg)
+	return nil
+}
+
+func (s *fakeHeartbeatStore) DeleteWorker(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *fakeHeartbeatStore) DeleteStaleWorkers(ctx context.Context, lastHeartbeatAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.staleSince = lastHeartbeatAt
+	return 0, nil
+}
+
+type pausedTopics []string
+
+func (p pausedTopics) PausedTopics() []string { return p }
+
+func (s *fakeHeartbeatStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.heartbeats)
+}
+
+func TestHeartbeatReportsLoadAndUnregisters(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	pool := newTestPool(t, 4)
+	release := make(chan struct{})
+	defer close(release)
+	pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+		<-release
+		return nil
+	})
+	waitFor(t, "a busy worker", func() bool { return pool.Status().Busy == 1 })
+
+	store := &fakeHeartbeatStore{}
+	instance := Instance{ID: "scraper-a", Kind: KindScraper, Version: "1.2.0", Host: "node-1", Region: "eu-west"}
+	heartbeat := NewHeartbeat(store, instance, pool, 10*time.Millisecond, logger)
+	heartbeat.ReportPausedTopics(pausedTopics{"scraped-data"})
+	if err := heartbeat.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitFor(t, "repeated heartbeats", func() bool { return store.count() >= 3 })
+	if err := heartbeat.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	first := store.heartbeats[0]
+	if first.ID != "scraper-a" || first.Kind != KindScraper || first.Version != "1.2.0" || first.Host != "node-1" || first.Region != "eu-west" {
+		t.Errorf("heartbeat identity = %+v", first)
+	}
+	if first.ActiveTasks != 1 || first.Capacity != 4 || first.QueuedTasks != 0 {
+		t.Errorf("heartbeat load = %d/%d (%d queued), want 1/4 (0 queued)", first.ActiveTasks, first.Capacity, first.QueuedTasks)
+	}
+	if len(first.PausedTopics) != 1 || first.PausedTopics[0] != "scraped-data" {
+		t.Errorf("heartbeat paused topics = %v, want [scraped-data]", first.PausedTopics)
+	}
+	if first.StartedAt.IsZero() || !store.heartbeats[len(store.heartbeats)-1].StartedAt.Equal(first.StartedAt) {
+		t.Error("heartbeats should report a constant start time")
+	}
+	if want := first.StartedAt.Add(-StaleWorkerRetention); !store.staleSince.Equal(want) {
+		t.Errorf("stale workers removed before %v, want %v", store.staleSince, want)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "scraper-a" {
+		t.Errorf("deleted = %v, want [scraper-a]", store.deleted)
+	}
+}
@@ -0,0 +1,531 @@
+// Package worker runs scraping tasks on a bounded pool of workers. It backs
+// the scraping.max_concurrent_tasks setting of the scraper services: at most
+// that many tasks run at once, each worker has an ID that is attached to the
+// logs of its tasks and reported in the pool status, and the pool can be
+// paused, resumed and resized at runtime. Scaling down and stopping let
+// workers finish the fetch they are running. maintenance.Mode.PauseDuring
+// keeps a pool paused during maintenance mode.
+//
+// Tasks are submitted for a group, the project of the URL they scrape. When
+// a worker is free it takes the oldest task of the group with the fewest
+// running tasks for its share (see config.ProjectShares), so a project with
+// thousands of queued scrapes cannot starve the others, while a project
+// alone gets every worker.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Pool limits
+const (
+	DefaultQueueSize = 100
+	MaxSize          = 1000
+)
+
+// ErrStopped is returned when submitting a task to a stopped pool
+var ErrStopped = errors.New("worker pool is stopped")
+
+// Task is a unit of work run by a worker. The logger carries the worker ID.
+type Task func(ctx context.Context, logger *logrus.Entry) error
+
+// Status describes the pool and its workers
+type Status struct {
+	Size    int            `json:"size"`             // Number of workers taking tasks
+	Paused  bool           `json:"paused"`           // Whether workers take no new tasks
+	Queued  int            `json:"queued"`           // Tasks waiting for a worker
+	Busy    int            `json:"busy"`             // Workers running a task
+	Workers []WorkerStatus `json:"workers"`          // Workers in start order
+	Groups  []GroupStatus  `json:"groups,omitempty"` // Groups with queued or running tasks, by name
+}
+
+// GroupStatus describes the tasks of a group
+type GroupStatus struct {
+	Name    string `json:"name"`    // Project, empty for tasks without one
+	Share   int    `json:"share"`   // Relative share of the workers
+	Queued  int    `json:"queued"`  // Tasks waiting for a worker
+	Running int    `json:"running"` // Tasks running or being handed to a worker
+}
+
+// WorkerStatus describes a single worker
+type WorkerStatus struct {
+	ID        string    `json:"id"`
+	Busy      bool      `json:"busy"`
+	Retiring  bool      `json:"retiring"`  // Scaled down, exits after its current task
+	Processed int64     `json:"processed"` // Tasks completed, including failed ones
+	Failed    int64     `json:"failed"`    // Tasks that returned an error
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Pool is a resizable pool of workers taking tasks from a bounded queue
+type Pool struct {
+	name   string
+	logger *logrus.Logger
+	queue  chan Task     // Hands tasks from the dispatcher to workers
+	space  chan struct{} // Holds a token per queued task, full when the queue is
+	wake   chan struct{} // Tells the dispatcher a task was queued or finished
+	done   chan struct{} // Closed by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	workers  []*worker
+	nextID   int
+	resumed  chan struct{} // Closed while the pool is not paused
+	paused   bool
+	started  bool
+	stopOnce sync.Once
+	groups   map[string]*group
+	shares   config.ProjectShares
+	served   uint64 // Number of tasks dispatched, see group.servedAt
+}
+
+// group holds the queued tasks of a project
+type group struct {
+	tasks    []Task
+	running  int
+	servedAt uint64 // Value of Pool.served when the group last had a task dispatched
+}
+
+// worker is a goroutine of the pool
+type worker struct {
+	id        string
+	quit      chan struct{} // Closed to retire the worker
+	startedAt time.Time
+	retiring  atomic.Bool
+	busy      atomic.Bool
+	processed atomic.Int64
+	failed    atomic.Int64
+}
+
+// New creates a pool of size workers named after the instance, e.g. the
+// host name; worker IDs are "<name>-<n>". Submitted tasks wait in a queue of
+// queueSize (DefaultQueueSize if zero) until a worker is free. Every group
+// has a share of 1 until SetShares. Workers start with Start.
+func New(name string, size, queueSize int, logger *logrus.Logger) (*Pool, error) {
+	if size < 1 || size > MaxSize {
+		return nil, fmt.Errorf("pool size must be between 1 and %d", MaxSize)
+	}
+	if queueSize < 0 {
+		return nil, fmt.Errorf("queue size must not be negative")
+	}
+	if queueSize == 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resumed := make(chan struct{})
+	close(resumed)
+	p := &Pool{
+		name:    name,
+		logger:  logger,
+		queue:   make(chan Task),
+		space:   make(chan struct{}, queueSize),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		resumed: resumed,
+		groups:  make(map[string]*group),
+	}
+	for i := 0; i < size; i++ {
+		p.addWorker()
+	}
+	return p, nil
+}
+
+// NewFromConfig creates a pool sized by scraping.max_concurrent_tasks with
+// a queue of workers.queue_size, sharing workers by scraping.project_shares
+func NewFromConfig(name string, cfg *config.Config, logger *logrus.Logger) (*Pool, error) {
+	p, err := New(name, cfg.Scraping.Concurrency, cfg.Workers.QueueSize, logger)
+	if err != nil {
+		return nil, err
+	}
+	p.SetShares(cfg.Scraping.ProjectShares)
+	return p, nil
+}
+
+// Start starts the workers
+func (p *Pool) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.started {
+		return nil
+	}
+	p.started = true
+	for _, w := range p.workers {
+		p.run(w)
+	}
+	p.wg.Add(1)
+	go p.dispatch()
+	p.logger.WithFields(logrus.Fields{"pool": p.name, "size": len(p.workers)}).Info("Worker pool started")
+	return nil
+}
+
+// Stop stops the pool. Workers finish their current task and exit; tasks
+// still queued are dropped. If ctx ends first, running tasks are cancelled.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.done) })
+
+	finished := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		p.cancel()
+		p.logger.WithField("pool", p.name).Info("Worker pool stopped")
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-finished
+		return fmt.Errorf("worker pool stopped before running tasks finished: %w", ctx.Err())
+	}
+}
+
+// Submit queues a task of the group without a project, see SubmitFor
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	return p.SubmitFor(ctx, "", task)
+}
+
+// SubmitFor queues a task of a group, the project of the URL it scrapes.
+// It blocks while the queue is full, until ctx ends.
+func (p *Pool) SubmitFor(ctx context.Context, name string, task Task) error {
+	select {
+	case <-p.done:
+		return ErrStopped
+	default:
+	}
+
+	select {
+	case p.space <- struct{}{}:
+	case <-p.done:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	g := p.groups[name]
+	if g == nil {
+		g = &group{}
+		p.groups[name] = g
+	}
+	g.tasks = append(g.tasks, task)
+	p.mu.Unlock()
+	p.signal()
+	return nil
+}
+
+// Configure resizes the pool to scraping.max_concurrent_tasks and applies
+// scraping.project_shares, e.g. after a configuration reload. Invalid sizes
+// are logged and ignored.
+func (p *Pool) Configure(cfg config.ScrapingConfig) {
+	if err := p.Resize(cfg.Concurrency); err != nil {
+		p.logger.WithError(err).WithField("pool", p.name).Warn("Ignoring invalid worker pool size")
+	}
+	p.SetShares(cfg.ProjectShares)
+}
+
+// SetShares sets the shares of the workers groups get while they have tasks
+// waiting, which config.ProjectShares.Validate has checked. Groups not in
+// shares have a share of 1.
+func (p *Pool) SetShares(shares config.ProjectShares) {
+	p.mu.Lock()
+	p.shares = shares
+	p.mu.Unlock()
+	p.signal()
+}
+
+// Resize changes the number of workers. Added workers start taking tasks
+// right away; removed workers, the most recently started first, finish
+// their current task before they exit.
+func (p *Pool) Resize(size int) error {
+	if size < 1 || size > MaxSize {
+		return fmt.Errorf("pool size must be between 1 and %d", MaxSize)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	active := p.active()
+	if len(active) == size {
+		return nil
+	}
+	for len(active) < size {
+		w := p.addWorker()
+		if p.started {
+			p.run(w)
+		}
+		active = append(active, w)
+	}
+	for _, w := range active[size:] {
+		w.retiring.Store(true)
+		close(w.quit)
+	}
+	p.logger.WithFields(logrus.Fields{"pool": p.name, "size": size}).Info("Worker pool resized")
+	return nil
+}
+
+// Pause stops workers from taking new tasks. Running tasks finish, and
+// submitted tasks wait in the queue until Resume.
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused {
+		return
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	p.logger.WithField("pool", p.name).Info("Worker pool paused")
+}
+
+// Resume lets workers take tasks again after Pause
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return
+	}
+	p.paused = false
+	close(p.resumed)
+	p.logger.WithField("pool", p.name).Info("Worker pool resumed")
+}
+
+// Status reports the pool and its workers
+func (p *Pool) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := Status{
+		Size:    len(p.active()),
+		Paused:  p.paused,
+		Queued:  len(p.space),
+		Workers: make([]WorkerStatus, len(p.workers)),
+	}
+	for name, g := range p.groups {
+		status.Groups = append(status.Groups, GroupStatus{Name: name, Share: p.shares.Share(name), Queued: len(g.tasks), Running: g.running})
+	}
+	sort.Slice(status.Groups, func(i, j int) bool { return status.Groups[i].Name < status.Groups[j].Name })
+	for i, w := range p.workers {
+		busy := w.busy.Load()
+		if busy {
+			status.Busy++
+		}
+		status.Workers[i] = WorkerStatus{
+			ID:        w.id,
+			Busy:      busy,
+			Retiring:  w.retiring.Load(),
+			Processed: w.processed.Load(),
+			Failed:    w.failed.Load(),
+			StartedAt: w.startedAt,
+		}
+	}
+	return status
+}
+
+// active returns the workers that are not retiring. The caller holds p.mu.
+func (p *Pool) active() []*worker {
+	var active []*worker
+	for _, w := range p.workers {
+		if !w.retiring.Load() {
+			active = append(active, w)
+		}
+	}
+	return active
+}
+
+// addWorker creates a worker. The caller holds p.mu or owns the pool.
+func (p *Pool) addWorker() *worker {
+	p.nextID++
+	w := &worker{
+		id:        fmt.Sprintf("%s-%d", p.name, p.nextID),
+		quit:      make(chan struct{}),
+		startedAt: time.Now().UTC(),
+	}
+	p.workers = append(p.workers, w)
+	return w
+}
+
+// removeWorker forgets a worker that exited
+func (p *Pool) removeWorker(w *worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, other := range p.workers {
+		if other == w {
+			p.workers = append(p.workers[:i], p.workers[i+1:]...)
+			return
+		}
+	}
+}
+
+// run starts the goroutine of a worker. The caller holds p.mu.
+func (p *Pool) run(w *worker) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.removeWorker(w)
+
+		logger := p.logger.WithFields(logrus.Fields{"pool": p.name, "worker_id": w.id})
+		logger.Debug("Worker started")
+		for {
+			p.mu.Lock()
+			resumed := p.resumed
+			p.mu.Unlock()
+
+			select {
+			case <-w.quit:
+				logger.Debug("Worker retired")
+				return
+			case <-p.done:
+				return
+			case <-resumed:
+			}
+
+			select {
+			case <-w.quit:
+				logger.Debug("Worker retired")
+				return
+			case <-p.done:
+				return
+			case task := <-p.queue:
+				p.execute(w, task, logger)
+			}
+		}
+	}()
+}
+
+// signal wakes the dispatcher, if it is not already awake
+func (p *Pool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands queued tasks to free workers, picking each time the group
+// that is furthest below its share. A task waiting for a worker is taken
+// back when a task is queued or finishes, since a fairer choice may then
+// exist.
+func (p *Pool) dispatch() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		name, g := p.next()
+		var task Task
+		if g != nil {
+			task = p.wrap(name, g, g.tasks[0])
+			g.running++
+		}
+		p.mu.Unlock()
+
+		if g == nil {
+			select {
+			case <-p.wake:
+				continue
+			case <-p.done:
+				return
+			}
+		}
+
+		select {
+		case p.queue <- task:
+			p.mu.Lock()
+			g.tasks = g.tasks[1:]
+			p.served++
+			g.servedAt = p.served
+			p.mu.Unlock()
+			<-p.space
+		case <-p.wake:
+			p.mu.Lock()
+			p.finished(name, g)
+			p.mu.Unlock()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// next returns the group with queued tasks that has the fewest running
+// tasks for its share, the one served least recently among equals. The
+// caller holds p.mu.
+func (p *Pool) next() (string, *group) {
+	var bestName string
+	var best *group
+	for name, g := range p.groups {
+		if len(g.tasks) == 0 {
+			continue
+		}
+		if best == nil {
+			bestName, best = name, g
+			continue
+		}
+		// g.running/share(g) < best.running/share(best), without division
+		load, bestLoad := g.running*p.shares.Share(bestName), best.running*p.shares.Share(name)
+		if load < bestLoad || load == bestLoad && (g.servedAt < best.servedAt || g.servedAt == best.servedAt && name < bestName) {
+			bestName, best = name, g
+		}
+	}
+	return bestName, best
+}
+
+// wrap returns a task that counts as running for its group until it returns
+func (p *Pool) wrap(name string, g *group, task Task) Task {
+	return func(ctx context.Context, logger *logrus.Entry) error {
+		defer func() {
+			p.mu.Lock()
+			p.finished(name, g)
+			p.mu.Unlock()
+			p.signal()
+		}()
+		if name != "" {
+			logger = logger.WithField("project", name)
+		}
+		return task(ctx, logger)
+	}
+}
+
+// finished counts a task of a group as no longer running and forgets the
+// group once it has no tasks left. The caller holds p.mu.
+func (p *Pool) finished(name string, g *group) {
+	g.running--
+	if g.running == 0 && len(g.tasks) == 0 && p.groups[name] == g {
+		delete(p.groups, name)
+	}
+}
+
+// execute runs a task on a worker and records its outcome. A panicking task
+// is counted as failed and does not take the worker down.
+func (p *Pool) execute(w *worker, task Task, logger *logrus.Entry) {
+	w.busy.Store(true)
+	defer w.busy.Store(false)
+	defer w.processed.Add(1)
+	defer func() {
+		if r := recover(); r != nil {
+			w.failed.Add(1)
+			logger.WithField("panic", r).Error("Task panicked")
+		}
+	}()
+
+	if err := task(p.ctx, logger); err != nil {
+		w.failed.Add(1)
+		logger.WithError(err).Warn("Task failed")
+	}
+}
@@ -0,0 +1,256 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestPool(t *testing.T, size int) *Pool {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	pool, err := New("scraper", size, 10, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { pool.Stop(context.Background()) })
+	return pool
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPoolBoundsConcurrency(t *testing.T) {
+	pool := newTestPool(t, 3)
+
+	var running, peak, done atomic.Int32
+	release := make(chan struct{})
+	workerIDs := make(chan string, 6)
+	for i := 0; i < 6; i++ {
+		err := pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			workerIDs <- logger.Data["worker_id"].(string)
+			<-release
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	waitFor(t, "three busy workers", func() bool { return pool.Status().Busy == 3 })
+	if queued := pool.Status().Queued; queued != 3 {
+		t.Errorf("Queued = %d, want 3", queued)
+	}
+	close(release)
+	waitFor(t, "all tasks", func() bool { return done.Load() == 6 })
+
+	if peak.Load() != 3 {
+		t.Errorf("peak concurrency = %d, want 3", peak.Load())
+	}
+	close(workerIDs)
+	for id := range workerIDs {
+		if id != "scraper-1" && id != "scraper-2" && id != "scraper-3" {
+			t.Errorf("unexpected worker ID %q", id)
+		}
+	}
+}
+
+func TestPoolPauseAndResume(t *testing.T) {
+	pool := newTestPool(t, 2)
+	pool.Pause()
+
+	var done atomic.Int32
+	for i := 0; i < 4; i++ {
+		pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+			done.Add(1)
+			return nil
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if status := pool.Status(); !status.Paused || status.Queued != 4 || done.Load() != 0 {
+		t.Fatalf("paused pool ran tasks: status %+v, done %d", status, done.Load())
+	}
+
+	pool.Resume()
+	waitFor(t, "queued tasks", func() bool { return done.Load() == 4 })
+}
+
+func TestPoolScaleDownFinishesRunningTasks(t *testing.T) {
+	pool := newTestPool(t, 3)
+
+	release := make(chan struct{})
+	var finished atomic.Int32
+	for i := 0; i < 3; i++ {
+		pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+			<-release
+			if ctx.Err() == nil {
+				finished.Add(1)
+			}
+			return nil
+		})
+	}
+	waitFor(t, "three busy workers", func() bool { return pool.Status().Busy == 3 })
+
+	if err := pool.Resize(1); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	status := pool.Status()
+	if status.Size != 1 || len(status.Workers) != 3 {
+		t.Fatalf("status after resize = %+v, want size 1 with 3 workers", status)
+	}
+	if status.Workers[0].ID != "scraper-1" || status.Workers[0].Retiring || !status.Workers[2].Retiring {
+		t.Errorf("expected the most recent workers to retire: %+v", status.Workers)
+	}
+
+	close(release)
+	waitFor(t, "retired workers to exit", func() bool { return len(pool.Status().Workers) == 1 })
+	if finished.Load() != 3 {
+		t.Errorf("finished tasks = %d, want 3", finished.Load())
+	}
+
+	if err := pool.Resize(2); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	if workers := pool.Status().Workers; len(workers) != 2 || workers[1].ID != "scraper-4" {
+		t.Errorf("workers after growing = %+v, want scraper-1 and scraper-4", workers)
+	}
+	if err := pool.Resize(0); err == nil {
+		t.Error("Resize(0) expected an error")
+	}
+}
+
+func TestPoolStopWaitsForRunningTasks(t *testing.T) {
+	pool := newTestPool(t, 1)
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+	<-started
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop() returned before the running task finished")
+	}
+	if err := pool.Submit(context.Background(), func(context.Context, *logrus.Entry) error { return nil }); err != ErrStopped {
+		t.Errorf("Submit() after Stop error = %v, want ErrStopped", err)
+	}
+}
+
+func TestPoolStopCancelsTasksAfterDeadline(t *testing.T) {
+	pool := newTestPool(t, 1)
+
+	started := make(chan struct{})
+	pool.Submit(context.Background(), func(ctx context.Context, logger *logrus.Entry) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Stop(ctx); err == nil {
+		t.Error("Stop() expected an error when the deadline passes")
+	}
+}
+
+func TestPoolInterleavesGroups(t *testing.T) {
+	pool := newTestPool(t, 1)
+	pool.Pause()
+
+	order := make(chan string, 6)
+	submit := func(project string, n int) {
+		for i := 0; i < n; i++ {
+			pool.SubmitFor(context.Background(), project, func(ctx context.Context, logger *logrus.Entry) error {
+				order <- logger.Data["project"].(string)
+				return nil
+			})
+		}
+	}
+	submit("big", 4)
+	submit("small", 2)
+	pool.Resume()
+
+	var got []string
+	for len(got) < 6 {
+		select {
+		case project := <-order:
+			got = append(got, project)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after tasks %v", got)
+		}
+	}
+	want := []string{"big", "small", "big", "small", "big", "big"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	waitFor(t, "groups to empty", func() bool { return len(pool.Status().Groups) == 0 })
+}
+
+func TestPoolSharesWorkers(t *testing.T) {
+	pool := newTestPool(t, 3)
+	pool.SetShares(config.ProjectShares{"big": 2})
+	pool.Pause()
+
+	release := make(chan struct{})
+	defer close(release)
+	var big, small atomic.Int32
+	for _, project := range []string{"big", "big", "big", "big", "small", "small"} {
+		started := &small
+		if project == "big" {
+			started = &big
+		}
+		pool.SubmitFor(context.Background(), project, func(ctx context.Context, logger *logrus.Entry) error {
+			started.Add(1)
+			<-release
+			return nil
+		})
+	}
+	pool.Resume()
+	waitFor(t, "three busy workers", func() bool { return big.Load()+small.Load() == 3 })
+
+	if big.Load() != 2 || small.Load() != 1 {
+		t.Errorf("running tasks: big %d, small %d; want 2 and 1", big.Load(), small.Load())
+	}
+	status := pool.Status()
+	if len(status.Groups) != 2 || status.Groups[0].Name != "big" || status.Groups[0].Share != 2 || status.Queued != 3 {
+		t.Errorf("status = %+v, want groups big and small with 3 queued tasks", status)
+	}
+}
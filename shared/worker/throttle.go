@@ -0,0 +1,276 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+)
+
+// throttlePruneInterval is how often reservations that have passed are removed
+const throttlePruneInterval = time.Minute
+
+// Backoff of a host that asked the scraper to slow down, see Throttle.Backoff
+const (
+	backoffBase    = 5 * time.Second  // First delay without Retry-After, doubled per throttled response in a row
+	backoffMax     = 10 * time.Minute // Longest delay without Retry-After
+	maxRetryAfter  = time.Hour        // Longest Retry-After honoured
+	maxBackoffWait = 30 * time.Second // Longest a task waits for a backed off host
+)
+
+// BackoffError is returned by Throttle.Wait when the URL's host is backed off
+// for longer than a task should wait. The task should be reported as rate
+// limited with RetryAfter as its retry_after_ms, so the URL Manager
+// reschedules it rather than counting a failure.
+type BackoffError struct {
+	Host       string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *BackoffError) Error() string {
+	return fmt.Sprintf("host %s is backed off for %s", e.Host, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap classifies the error as rate limited, see models.ClassifyFailure
+func (e *BackoffError) Unwrap() error {
+	return models.ErrRateLimited
+}
+
+// ThrottleStats reports the time tasks spent waiting for rate limits since
+// the throttle was created
+type ThrottleStats struct {
+	DomainRateLimit int   `json:"domain_rate_limit"` // Requests per minute per host, 0 for no limit
+	Requests        int64 `json:"requests"`          // Requests that passed the throttle
+	Throttled       int64 `json:"throttled"`         // Requests that had to wait
+	ThrottledMs     int64 `json:"throttled_ms"`      // Total time spent waiting in milliseconds
+	Backoffs        int64 `json:"backoffs"`          // 429 and 503 responses that backed off their host
+	BackoffMs       int64 `json:"backoff_ms"`        // Total backoff delay in milliseconds
+	BackedOffTasks  int64 `json:"backed_off_tasks"`  // Tasks rejected with a BackoffError
+	BackedOffHosts  int   `json:"backed_off_hosts"`  // Hosts currently backed off
+}
+
+// Throttle enforces the rate limits of the URLs a scraper fetches: the
+// requests per minute of each URL (its rate_limit) and of each host across
+// all of its URLs (scraping.domain_rate_limit). Requests are spaced evenly,
+// so a limit of 60 allows one request per second. Tasks call Wait before
+// fetching and report the time waited as the result's throttled_ms, apart
+// from the fetch duration. Hosts that answer 429 or 503 are backed off with
+// Backoff until they recover.
+type Throttle struct {
+	mu          sync.Mutex
+	domainLimit int
+	next        map[string]time.Time // Earliest start of the next request per URL and host
+	backoff     map[string]time.Time // End of the backoff per host
+	strikes     map[string]int       // Throttled responses in a row per host
+	lastPrune   time.Time
+	stats       ThrottleStats
+	now         func() time.Time
+}
+
+// NewThrottle creates a throttle with the domain rate limit of cfg
+func NewThrottle(cfg config.ScrapingConfig) *Throttle {
+	t := &Throttle{
+		next:    make(map[string]time.Time),
+		backoff: make(map[string]time.Time),
+		strikes: make(map[string]int),
+		now:     time.Now,
+	}
+	t.Configure(cfg)
+	return t
+}
+
+// Configure applies a new domain rate limit. Requests already waiting keep
+// their slot.
+func (t *Throttle) Configure(cfg config.ScrapingConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.domainLimit = max(cfg.DomainRateLimit, 0)
+}
+
+// Wait blocks until a request to rawURL is allowed by the URL's rate limit,
+// in requests per minute (0 for no limit), and by its host's limit, and
+// returns the time waited. The request's slot is reserved when Wait is
+// called, so concurrent tasks for the same host are spaced out rather than
+// released together. If ctx ends first, its error is returned and the slot
+// stays used. If the host is backed off for longer than 30 seconds, a
+// *BackoffError is returned right away instead of waiting.
+func (t *Throttle) Wait(ctx context.Context, rawURL string, rateLimit int) (time.Duration, error) {
+	if err := t.checkBackoff(rawURL); err != nil {
+		return 0, err
+	}
+	wait := t.reserve(rawURL, rateLimit)
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
+
+// Stats returns the throttling totals
+func (t *Throttle) Stats() ThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	stats.DomainRateLimit = t.domainLimit
+	now := t.now()
+	for _, until := range t.backoff {
+		if until.After(now) {
+			stats.BackedOffHosts++
+		}
+	}
+	return stats
+}
+
+// Backoff delays every request to rawURL's host after it answered with 429
+// Too Many Requests or 503 Service Unavailable, and returns the delay.
+// retryAfter is the delay the server asked for (see ParseRetryAfter),
+// honoured up to an hour; without one the delay adapts to the host, starting
+// at 5 seconds and doubling with each throttled response in a row up to 10
+// minutes, until Recover is called.
+func (t *Throttle) Backoff(rawURL string, retryAfter time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	host := throttleHost(rawURL)
+	t.strikes[host]++
+	delay := min(retryAfter, maxRetryAfter)
+	if delay <= 0 {
+		delay = backoffBase
+		for i := 1; i < t.strikes[host] && delay < backoffMax; i++ {
+			delay *= 2
+		}
+		delay = min(delay, backoffMax)
+	}
+
+	if until := t.now().Add(delay); until.After(t.backoff[host]) {
+		t.backoff[host] = until
+	}
+	t.stats.Backoffs++
+	t.stats.BackoffMs += delay.Milliseconds()
+	return delay
+}
+
+// Recover resets the adaptive backoff of rawURL's host after a response that
+// was not throttled. A backoff already in effect is kept.
+func (t *Throttle) Recover(rawURL string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.strikes, throttleHost(rawURL))
+}
+
+// checkBackoff returns a *BackoffError when rawURL's host is backed off for
+// longer than a task should wait
+func (t *Throttle) checkBackoff(rawURL string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	host := throttleHost(rawURL)
+	if remaining := t.backoff[host].Sub(t.now()); remaining > maxBackoffWait {
+		t.stats.BackedOffTasks++
+		return &BackoffError{Host: host, RetryAfter: remaining}
+	}
+	return nil
+}
+
+// reserve books the next slot allowed by both limits and returns how long
+// until it starts
+func (t *Throttle) reserve(rawURL string, rateLimit int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.prune(now)
+
+	host := throttleHost(rawURL)
+	urlKey := "url:" + rawURL
+	domainKey := "domain:" + host
+	start := now
+	if t.backoff[host].After(start) {
+		start = t.backoff[host]
+	}
+	if rateLimit > 0 && t.next[urlKey].After(start) {
+		start = t.next[urlKey]
+	}
+	if t.domainLimit > 0 && t.next[domainKey].After(start) {
+		start = t.next[domainKey]
+	}
+	if rateLimit > 0 {
+		t.next[urlKey] = start.Add(time.Minute / time.Duration(rateLimit))
+	}
+	if t.domainLimit > 0 {
+		t.next[domainKey] = start.Add(time.Minute / time.Duration(t.domainLimit))
+	}
+
+	wait := start.Sub(now)
+	t.stats.Requests++
+	if wait > 0 {
+		t.stats.Throttled++
+		t.stats.ThrottledMs += wait.Milliseconds()
+	}
+	return wait
+}
+
+// prune removes slots that have passed, at most once per throttlePruneInterval
+func (t *Throttle) prune(now time.Time) {
+	if now.Sub(t.lastPrune) < throttlePruneInterval {
+		return
+	}
+	t.lastPrune = now
+	for key, next := range t.next {
+		if !next.After(now) {
+			delete(t.next, key)
+		}
+	}
+	for host, until := range t.backoff {
+		if !until.After(now) {
+			delete(t.backoff, host)
+		}
+	}
+}
+
+// ParseRetryAfter parses the Retry-After header of a response, either a
+// number of seconds or an HTTP date, into the delay from now. It returns
+// false when the header is missing or invalid; dates in the past give no
+// delay.
+func ParseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(min(seconds, int64(maxRetryAfter/time.Second))) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// throttleHost returns the lowercase host of rawURL, or rawURL itself if it
+// has none, so unparsable URLs are still limited on their own
+func throttleHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return rawURL
+	}
+	return strings.ToLower(parsed.Hostname())
+}
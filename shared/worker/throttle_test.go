@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go_scraping_project/shared/config"
+	"go_scraping_project/shared/models"
+)
+
+func TestThrottleSpacesRequests(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(config.ScrapingConfig{DomainRateLimit: 60})
+	throttle.now = func() time.Time { return now }
+
+	tests := []struct {
+		url       string
+		rateLimit int
+		want      time.Duration
+	}{
+		{"https://example.com/a", 0, 0},
+		{"https://EXAMPLE.com/b", 0, time.Second},      // Same host, one second apart
+		{"https://example.com/a", 30, 2 * time.Second}, // Host slot after /b
+		{"https://example.com/a", 30, 4 * time.Second}, // URL limit of 30/min is stricter
+		{"https://other.org/a", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := throttle.reserve(tt.url, tt.rateLimit); got != tt.want {
+			t.Errorf("reserve(%s, %d) = %v, want %v", tt.url, tt.rateLimit, got, tt.want)
+		}
+	}
+
+	stats := throttle.Stats()
+	if stats.Requests != 5 || stats.Throttled != 3 || stats.ThrottledMs != 7000 || stats.DomainRateLimit != 60 {
+		t.Errorf("Stats() = %+v, want 5 requests, 3 throttled for 7000ms", stats)
+	}
+
+	throttle.Configure(config.ScrapingConfig{})
+	now = now.Add(10 * time.Minute)
+	if got := throttle.reserve("https://example.com/a", 0); got != 0 {
+		t.Errorf("reserve() without limits = %v, want no wait", got)
+	}
+	if len(throttle.next) != 0 {
+		t.Errorf("%d slots kept after they passed, want them pruned", len(throttle.next))
+	}
+}
+
+func TestThrottleWaitHonoursContext(t *testing.T) {
+	throttle := NewThrottle(config.ScrapingConfig{DomainRateLimit: 1})
+	ctx := context.Background()
+	if waited, err := throttle.Wait(ctx, "https://example.com/", 0); err != nil || waited != 0 {
+		t.Fatalf("first Wait() = %v, %v, want no wait", waited, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := throttle.Wait(ctx, "https://example.com/", 0); err != context.DeadlineExceeded {
+		t.Errorf("Wait() past the deadline error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(config.ScrapingConfig{})
+	throttle.now = func() time.Time { return now }
+
+	// Without Retry-After the delay doubles per throttled response in a row
+	for i, want := range []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second} {
+		if got := throttle.Backoff("https://example.com/a", 0); got != want {
+			t.Errorf("Backoff() #%d = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := throttle.reserve("https://example.com/b", 0); got != 20*time.Second {
+		t.Errorf("reserve() of a backed off host = %v, want 20s", got)
+	}
+	if got := throttle.reserve("https://other.org/", 0); got != 0 {
+		t.Errorf("reserve() of another host = %v, want no wait", got)
+	}
+
+	// A long Retry-After rejects tasks instead of holding them
+	throttle.Recover("https://example.com/a")
+	if got := throttle.Backoff("https://example.com/a", 2*time.Minute); got != 2*time.Minute {
+		t.Errorf("Backoff() with Retry-After = %v, want 2m", got)
+	}
+	_, err := throttle.Wait(context.Background(), "https://example.com/c", 0)
+	var backoffErr *BackoffError
+	if !errors.As(err, &backoffErr) || backoffErr.RetryAfter != 2*time.Minute || !errors.Is(err, models.ErrRateLimited) {
+		t.Fatalf("Wait() error = %v, want a 2m BackoffError", err)
+	}
+
+	stats := throttle.Stats()
+	if stats.Backoffs != 4 || stats.BackoffMs != 155000 || stats.BackedOffTasks != 1 || stats.BackedOffHosts != 1 {
+		t.Errorf("Stats() = %+v, want 4 backoffs for 155000ms and 1 backed off host", stats)
+	}
+
+	// Recover resets the adaptive delay
+	if got := throttle.Backoff("https://example.com/a", 0); got != 10*time.Second {
+		t.Errorf("Backoff() after Recover and one strike = %v, want 10s", got)
+	}
+	throttle.Recover("https://example.com/a")
+	if got := throttle.Backoff("https://example.com/a", 0); got != 5*time.Second {
+		t.Errorf("Backoff() after Recover = %v, want 5s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"-5", 0, false},
+		{"999999", time.Hour, true},
+		{"Mon, 01 Jan 2024 12:05:00 GMT", 5 * time.Minute, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.value != "" {
+			header.Set("Retry-After", tt.value)
+		}
+		got, ok := ParseRetryAfter(header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
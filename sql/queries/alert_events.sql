@@ -0,0 +1,20 @@
+-- name: CreateAlertEvent :one
+INSERT INTO alert_events (
+    rule, kind, state, severity, value, threshold, message
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAlertEvents :many
+-- Lists alert events, newest first. An empty rule or state matches every
+-- rule or state.
+SELECT * FROM alert_events
+WHERE (sqlc.arg(rule)::text = '' OR rule = sqlc.arg(rule)::text)
+AND (sqlc.arg(state)::text = '' OR state = sqlc.arg(state)::text)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountAlertEvents :one
+SELECT COUNT(*) FROM alert_events
+WHERE (sqlc.arg(rule)::text = '' OR rule = sqlc.arg(rule)::text)
+AND (sqlc.arg(state)::text = '' OR state = sqlc.arg(state)::text);
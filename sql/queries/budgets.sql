@@ -0,0 +1,30 @@
+-- name: CountScrapingTasksForDomain :one
+-- Counts the scrape attempts published since a time for URLs on a host or its subdomains.
+SELECT COUNT(*)
+FROM scraping_tasks t
+JOIN urls u ON u.id = t.url_id
+WHERE t.created_at >= sqlc.arg(since)
+AND (lower(substring(u.url from '^[^:]+://([^/:?#]+)')) = lower(sqlc.arg(domain)::text)
+    OR lower(substring(u.url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower(sqlc.arg(domain)::text));
+
+-- name: CountScrapingTasksForProject :one
+-- Counts the scrape attempts published since a time for URLs of a project.
+SELECT COUNT(*)
+FROM scraping_tasks t
+JOIN urls u ON u.id = t.url_id
+WHERE t.created_at >= sqlc.arg(since)
+AND u.project = sqlc.arg(project);
+
+-- name: RecordScrapeBudgetExhausted :one
+-- Records a scrape deferred because a daily budget was used up. There is one
+-- event per budget and day; reports whether this was its first deferral.
+INSERT INTO scrape_budget_events (
+    scope, name, day, daily_limit
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (scope, name, day) DO UPDATE
+SET daily_limit = EXCLUDED.daily_limit,
+    deferred_scrapes = scrape_budget_events.deferred_scrapes + 1,
+    updated_at = NOW()
+RETURNING (xmax = 0)::bool AS inserted;
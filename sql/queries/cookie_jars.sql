@@ -0,0 +1,12 @@
+-- name: GetCookieJar :one
+SELECT * FROM cookie_jars WHERE domain = $1 AND expires_at > now();
+
+-- name: UpsertCookieJar :exec
+INSERT INTO cookie_jars (domain, data, cookie_count, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (domain) DO UPDATE
+SET data = EXCLUDED.data, cookie_count = EXCLUDED.cookie_count,
+    expires_at = EXCLUDED.expires_at, updated_at = now();
+
+-- name: DeleteCookieJar :execrows
+DELETE FROM cookie_jars WHERE domain = $1;
@@ -0,0 +1,26 @@
+-- name: ListURLCosts :many
+-- Sums the costs of the scrapes completed since a time per URL, the URLs
+-- with the most proxy egress, then render time, first.
+SELECT u.id, u.url, u.project,
+    COUNT(*) AS scrapes,
+    COALESCE(SUM(t.proxy_egress_bytes), 0)::bigint AS proxy_egress_bytes,
+    COALESCE(SUM(t.render_ms), 0)::bigint AS render_ms
+FROM scraping_tasks t
+JOIN urls u ON u.id = t.url_id
+WHERE t.completed_at >= sqlc.arg(since)
+GROUP BY u.id
+ORDER BY proxy_egress_bytes DESC, render_ms DESC, u.url
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: ListProjectCosts :many
+-- Sums the costs of the scrapes completed since a time per project, the
+-- projects with the most proxy egress, then render time, first.
+SELECT u.project,
+    COUNT(*) AS scrapes,
+    COALESCE(SUM(t.proxy_egress_bytes), 0)::bigint AS proxy_egress_bytes,
+    COALESCE(SUM(t.render_ms), 0)::bigint AS render_ms
+FROM scraping_tasks t
+JOIN urls u ON u.id = t.url_id
+WHERE t.completed_at >= sqlc.arg(since)
+GROUP BY u.project
+ORDER BY proxy_egress_bytes DESC, render_ms DESC, u.project;
@@ -0,0 +1,84 @@
+-- name: CreateDataExportJob :one
+INSERT INTO data_export_jobs (
+    project, schema, format, from_time, to_time, requested_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetDataExportJob :one
+SELECT * FROM data_export_jobs WHERE id = $1;
+
+-- name: ListDataExportJobs :many
+-- Lists the export jobs of a project, newest first
+SELECT * FROM data_export_jobs
+WHERE project = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: ScheduleDataExportJob :execrows
+-- Creates the next job of an export schedule unless one was created after
+-- due_after. The job exports the records created since the end of the
+-- schedule's previous job, or since due_after for its first job, until now.
+INSERT INTO data_export_jobs (project, schema, format, schedule, from_time, to_time)
+SELECT sqlc.arg(project)::text, sqlc.arg(schema)::text, sqlc.arg(format)::text, sqlc.arg(schedule)::text,
+    COALESCE(
+        (SELECT max(j.to_time) FROM data_export_jobs j WHERE j.schedule = sqlc.arg(schedule)::text),
+        sqlc.arg(due_after)::timestamptz
+    ),
+    sqlc.arg(now)::timestamptz
+WHERE NOT EXISTS (
+    SELECT 1 FROM data_export_jobs j
+    WHERE j.schedule = sqlc.arg(schedule)::text AND j.created_at > sqlc.arg(due_after)::timestamptz
+);
+
+-- name: ClaimDataExportJob :one
+-- Marks the oldest pending job as running, or a running job not updated
+-- since stale_before, whose export stopped with its process
+UPDATE data_export_jobs SET status = 'running', record_count = 0, updated_at = now()
+WHERE id = (
+    SELECT j.id FROM data_export_jobs j
+    WHERE j.status = 'pending'
+    OR (j.status = 'running' AND j.updated_at < sqlc.arg(stale_before)::timestamptz)
+    ORDER BY j.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordDataExportProgress :exec
+-- Records the records written so far to a job's file
+UPDATE data_export_jobs SET record_count = $2, updated_at = now()
+WHERE id = $1;
+
+-- name: FinishDataExportJob :exec
+UPDATE data_export_jobs SET
+    status = $2, error = $3, record_count = $4, size_bytes = $5, artifact = $6,
+    expires_at = $7, updated_at = now(), completed_at = now()
+WHERE id = $1;
+
+-- name: ListExpiredDataExportJobs :many
+-- Lists the completed jobs whose files expired before now
+SELECT * FROM data_export_jobs
+WHERE status = 'completed' AND expires_at < sqlc.arg(now)::timestamptz
+ORDER BY expires_at
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: ExpireDataExportJob :exec
+-- Marks a job whose file was deleted as expired
+UPDATE data_export_jobs SET status = 'expired', artifact = '', updated_at = now()
+WHERE id = $1;
+
+-- name: ListParsedDataForExport :many
+-- Lists up to batch_size parsed records of a project's URLs, oldest first,
+-- after the (created_at, id) of the last record of the previous batch
+SELECT p.id, p.url_id, p.url, p.schema, p.title, p.content, p.data, p.content_hash, p.encryption_key_id, p.created_at
+FROM parsed_data p
+JOIN urls u ON u.id = p.url_id
+WHERE u.project = sqlc.arg(project)::text
+AND (sqlc.arg(schema)::text = '' OR p.schema = sqlc.arg(schema)::text)
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR p.created_at >= sqlc.narg(from_time)::timestamptz)
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR p.created_at < sqlc.narg(to_time)::timestamptz)
+AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (p.created_at, p.id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid))
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg(batch_size)::int;
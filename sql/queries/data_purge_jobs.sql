@@ -0,0 +1,72 @@
+-- name: CreateDataPurgeJob :one
+INSERT INTO data_purge_jobs (
+    url_id, schema, from_time, to_time, requested_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetDataPurgeJob :one
+SELECT * FROM data_purge_jobs WHERE id = $1;
+
+-- name: ClaimDataPurgeJob :one
+-- Marks the oldest pending job as running, or a running job not updated
+-- since stale_before, whose purge stopped with its process
+UPDATE data_purge_jobs SET status = 'running', updated_at = now()
+WHERE id = (
+    SELECT j.id FROM data_purge_jobs j
+    WHERE j.status = 'pending'
+    OR (j.status = 'running' AND j.updated_at < sqlc.arg(stale_before)::timestamptz)
+    ORDER BY j.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordDataPurgeProgress :exec
+-- Adds the rows deleted by a batch to a job's counters
+UPDATE data_purge_jobs SET
+    parsed_data_deleted = parsed_data_deleted + sqlc.arg(parsed_data_deleted),
+    raw_html_deleted = raw_html_deleted + sqlc.arg(raw_html_deleted),
+    har_captures_deleted = har_captures_deleted + sqlc.arg(har_captures_deleted),
+    updated_at = now()
+WHERE id = sqlc.arg(id);
+
+-- name: FinishDataPurgeJob :exec
+UPDATE data_purge_jobs SET status = $2, error = $3, updated_at = now(), completed_at = now()
+WHERE id = $1;
+
+-- name: PurgeRawHTMLSnapshots :execrows
+-- Deletes up to batch_size raw HTML snapshots matching a purge filter, and
+-- with them their render sessions. A schema matches the URLs that have
+-- parsed data of the schema, so snapshots must be purged before parsed data.
+DELETE FROM raw_html_snapshots WHERE id IN (
+    SELECT s.id FROM raw_html_snapshots s
+    WHERE (sqlc.narg(url_id)::uuid IS NULL OR s.url_id = sqlc.narg(url_id)::uuid)
+    AND (sqlc.arg(schema)::text = '' OR s.url_id IN (
+        SELECT p.url_id FROM parsed_data p WHERE p.schema = sqlc.arg(schema)::text))
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR s.created_at >= sqlc.narg(from_time)::timestamptz)
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR s.created_at < sqlc.narg(to_time)::timestamptz)
+    LIMIT sqlc.arg(batch_size)::int
+);
+
+-- name: PurgeHARCaptures :execrows
+-- Deletes the HAR captures matching a purge filter, by when they were
+-- requested. A schema matches as for PurgeRawHTMLSnapshots.
+DELETE FROM har_captures h
+WHERE (sqlc.narg(url_id)::uuid IS NULL OR h.url_id = sqlc.narg(url_id)::uuid)
+AND (sqlc.arg(schema)::text = '' OR h.url_id IN (
+    SELECT p.url_id FROM parsed_data p WHERE p.schema = sqlc.arg(schema)::text))
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR h.requested_at >= sqlc.narg(from_time)::timestamptz)
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR h.requested_at < sqlc.narg(to_time)::timestamptz);
+
+-- name: PurgeParsedData :execrows
+-- Deletes up to batch_size parsed records matching a purge filter, and with
+-- them the candidate parses made next to them
+DELETE FROM parsed_data WHERE id IN (
+    SELECT p.id FROM parsed_data p
+    WHERE (sqlc.narg(url_id)::uuid IS NULL OR p.url_id = sqlc.narg(url_id)::uuid)
+    AND (sqlc.arg(schema)::text = '' OR p.schema = sqlc.arg(schema)::text)
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR p.created_at >= sqlc.narg(from_time)::timestamptz)
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR p.created_at < sqlc.narg(to_time)::timestamptz)
+    LIMIT sqlc.arg(batch_size)::int
+);
@@ -0,0 +1,21 @@
+-- name: GetDataView :one
+SELECT * FROM data_views WHERE name = $1;
+
+-- name: ListDataViews :many
+SELECT * FROM data_views ORDER BY name ASC;
+
+-- name: CreateDataView :one
+INSERT INTO data_views (
+    name, description, schema, url_ids, predicates
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: UpdateDataView :one
+UPDATE data_views
+SET description = $2, schema = $3, url_ids = $4, predicates = $5, updated_at = NOW()
+WHERE name = $1
+RETURNING *;
+
+-- name: DeleteDataView :execrows
+DELETE FROM data_views WHERE name = $1;
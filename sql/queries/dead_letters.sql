@@ -0,0 +1,47 @@
+-- name: CreateDeadLetter :execrows
+-- Stores a message that exhausted its retries. A message already stored for
+-- its topic, partition and offset is left as is.
+INSERT INTO dead_letters (
+    topic, kafka_partition, kafka_offset, key, value, message_type, error, retry_count, created_at, failed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) ON CONFLICT (topic, kafka_partition, kafka_offset) DO NOTHING;
+
+-- name: ListDeadLetters :many
+-- Lists dead letters, most recent failures first. An empty topic or
+-- message_type matches every topic or type, a null failed_before every age.
+SELECT * FROM dead_letters
+WHERE (sqlc.arg(topic)::text = '' OR topic = sqlc.arg(topic)::text)
+AND (sqlc.arg(message_type)::text = '' OR message_type = sqlc.arg(message_type)::text)
+AND (sqlc.narg(failed_before)::timestamptz IS NULL OR failed_at < sqlc.narg(failed_before)::timestamptz)
+ORDER BY failed_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountDeadLetters :one
+SELECT COUNT(*) FROM dead_letters
+WHERE (sqlc.arg(topic)::text = '' OR topic = sqlc.arg(topic)::text)
+AND (sqlc.arg(message_type)::text = '' OR message_type = sqlc.arg(message_type)::text)
+AND (sqlc.narg(failed_before)::timestamptz IS NULL OR failed_at < sqlc.narg(failed_before)::timestamptz);
+
+-- name: ListDeadLettersForExport :many
+-- Lists up to batch_size dead letters, oldest failures first, after the
+-- (failed_at, id) of the last dead letter of the previous batch
+SELECT * FROM dead_letters
+WHERE (sqlc.arg(topic)::text = '' OR topic = sqlc.arg(topic)::text)
+AND (sqlc.arg(message_type)::text = '' OR message_type = sqlc.arg(message_type)::text)
+AND (sqlc.narg(failed_before)::timestamptz IS NULL OR failed_at < sqlc.narg(failed_before)::timestamptz)
+AND (sqlc.narg(after_failed_at)::timestamptz IS NULL
+    OR (failed_at, id) > (sqlc.narg(after_failed_at)::timestamptz, sqlc.arg(after_id)::uuid))
+ORDER BY failed_at, id
+LIMIT sqlc.arg(batch_size)::int;
+
+-- name: DeleteDeadLetter :execrows
+DELETE FROM dead_letters WHERE id = $1;
+
+-- name: PurgeDeadLetters :execrows
+-- Deletes the dead letters of a topic and/or that failed before
+-- failed_before; an empty topic matches every topic, a null failed_before
+-- every age
+DELETE FROM dead_letters
+WHERE (sqlc.arg(topic)::text = '' OR topic = sqlc.arg(topic)::text)
+AND (sqlc.narg(failed_before)::timestamptz IS NULL OR failed_at < sqlc.narg(failed_before)::timestamptz);
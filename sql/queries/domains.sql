@@ -0,0 +1,20 @@
+-- name: ListDomainStats :many
+-- Summarizes each host: its URLs and the scrape attempts completed since $1.
+WITH url_domains AS (
+    SELECT id, COALESCE(lower(substring(url from '^[^:]+://([^/:?#]+)')), '')::text AS domain
+    FROM urls
+    WHERE deleted_at IS NULL
+)
+SELECT d.domain::text AS domain,
+    COUNT(DISTINCT d.id) AS url_count,
+    COUNT(t.id) AS attempts,
+    COUNT(t.id) FILTER (WHERE t.status = 'success') AS successes,
+    COALESCE(AVG(t.duration_ms), 0)::float8 AS avg_duration_ms,
+    COUNT(t.id) FILTER (WHERE t.error_code = 'blocked') AS blocked,
+    COUNT(t.id) FILTER (WHERE t.error_code = 'rate_limited') AS rate_limited,
+    COUNT(t.id) FILTER (WHERE t.error_code = 'robots_denied') AS robots_denied,
+    COALESCE(SUM(t.throttled_ms), 0)::bigint AS throttled_ms
+FROM url_domains d
+LEFT JOIN scraping_tasks t ON t.url_id = d.id AND t.completed_at >= $1
+GROUP BY d.domain
+ORDER BY url_count DESC, d.domain;
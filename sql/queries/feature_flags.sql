@@ -0,0 +1,35 @@
+-- name: ListFeatureFlags :many
+SELECT * FROM feature_flags ORDER BY name ASC;
+
+-- name: UpsertFeatureFlag :one
+INSERT INTO feature_flags (
+    name, description, enabled, rollout_percent
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (name) DO UPDATE
+SET description = EXCLUDED.description,
+    enabled = EXCLUDED.enabled,
+    rollout_percent = EXCLUDED.rollout_percent,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteFeatureFlag :execrows
+DELETE FROM feature_flags WHERE name = $1;
+
+-- name: ListFeatureFlagOverrides :many
+SELECT * FROM feature_flag_overrides ORDER BY flag_name ASC, tenant_id ASC;
+
+-- name: UpsertFeatureFlagOverride :one
+INSERT INTO feature_flag_overrides (
+    flag_name, tenant_id, enabled
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (flag_name, tenant_id) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteFeatureFlagOverride :execrows
+DELETE FROM feature_flag_overrides WHERE flag_name = $1 AND tenant_id = $2;
@@ -0,0 +1,35 @@
+-- name: RequestHARCapture :one
+-- Requests a HAR capture of the URL's next scrape, replacing any earlier capture
+INSERT INTO har_captures (url_id, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (url_id) DO UPDATE SET
+    task_id = NULL,
+    har = NULL,
+    requested_at = now(),
+    captured_at = NULL,
+    expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: ClaimHARCapture :execrows
+-- Assigns a pending capture request of the URL to the scrape about to be published
+UPDATE har_captures SET task_id = $2
+WHERE url_id = $1 AND task_id IS NULL AND expires_at > now();
+
+-- name: ReleaseHARCapture :exec
+-- Returns a claimed capture request to pending when its scrape was not published
+UPDATE har_captures SET task_id = NULL
+WHERE url_id = $1 AND task_id = $2 AND har IS NULL;
+
+-- name: SaveHARCapture :execrows
+-- Stores the HAR of the scrape a capture request was claimed for
+UPDATE har_captures SET har = $3, captured_at = now(), expires_at = $4
+WHERE url_id = $1 AND task_id = $2 AND har IS NULL;
+
+-- name: GetHARCapture :one
+SELECT * FROM har_captures WHERE url_id = $1 AND expires_at > now();
+
+-- name: DeleteHARCapture :execrows
+DELETE FROM har_captures WHERE url_id = $1;
+
+-- name: DeleteExpiredHARCaptures :execrows
+DELETE FROM har_captures WHERE expires_at <= now();
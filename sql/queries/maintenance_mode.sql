@@ -0,0 +1,9 @@
+-- name: GetMaintenanceMode :one
+SELECT * FROM maintenance_mode WHERE id;
+
+-- name: SetMaintenanceMode :one
+INSERT INTO maintenance_mode (id, enabled, message)
+VALUES (true, $1, $2)
+ON CONFLICT (id) DO UPDATE
+SET enabled = EXCLUDED.enabled, message = EXCLUDED.message, updated_at = NOW()
+RETURNING *;
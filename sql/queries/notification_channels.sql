@@ -0,0 +1,21 @@
+-- name: GetNotificationChannel :one
+SELECT * FROM notification_channels WHERE name = $1;
+
+-- name: ListNotificationChannels :many
+SELECT * FROM notification_channels ORDER BY name ASC;
+
+-- name: CreateNotificationChannel :one
+INSERT INTO notification_channels (
+    name, type, settings, enabled
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateNotificationChannel :one
+UPDATE notification_channels
+SET type = $2, settings = $3, enabled = $4, updated_at = NOW()
+WHERE name = $1
+RETURNING *;
+
+-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels WHERE name = $1;
@@ -0,0 +1,163 @@
+-- name: ListParsedDataChanges :many
+-- Lists the records inserted or updated after a change sequence number,
+-- oldest change first. Changes made at or after settled_before are left for
+-- a later call, so a transaction that commits after a later change is not
+-- skipped. An empty schema matches every schema.
+SELECT * FROM parsed_data
+WHERE change_seq > sqlc.arg(after_seq)::bigint
+AND updated_at < sqlc.arg(settled_before)::timestamptz
+AND (sqlc.arg(schema)::text = '' OR schema = sqlc.arg(schema)::text)
+ORDER BY change_seq
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: CreateParsedData :one
+INSERT INTO parsed_data (
+    url_id, url, schema, title, content, metadata, data, content_hash, normalized_text, simhash, field_errors, created_at, encryption_key_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetParsedData :one
+SELECT * FROM parsed_data WHERE id = $1;
+
+-- name: ListParsedDataVersions :many
+-- Lists the parses of a URL under one schema, newest first. Each parse of
+-- a URL is kept, so these are the versions of the data extracted from it.
+SELECT * FROM parsed_data
+WHERE url_id = $1 AND schema = $2
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetParsedDataFieldCompleteness :many
+-- Counts, per field, the latest parses of URLs that extracted the field and
+-- those whose selector or rule failed, with one of the errors. Title and
+-- content count when they are not empty, other fields when they are in the
+-- data. Parses made before field errors were recorded are left out. An
+-- empty schema matches every schema.
+WITH latest AS (
+    SELECT DISTINCT ON (url_id, schema) title, content, data, field_errors
+    FROM parsed_data
+    WHERE (sqlc.arg(schema)::text = '' OR schema = sqlc.arg(schema)::text)
+    ORDER BY url_id, schema, created_at DESC, id
+), fields AS (
+    SELECT jsonb_object_keys(data) AS field, false AS failed, '' AS error FROM latest WHERE field_errors IS NOT NULL
+    UNION ALL
+    SELECT 'title', false, '' FROM latest WHERE field_errors IS NOT NULL AND title <> ''
+    UNION ALL
+    SELECT 'content', false, '' FROM latest WHERE field_errors IS NOT NULL AND content <> ''
+    UNION ALL
+    SELECT e.key, true, e.value FROM latest, jsonb_each_text(latest.field_errors) e WHERE field_errors IS NOT NULL
+)
+SELECT
+    field::text AS field,
+    COUNT(*) FILTER (WHERE NOT failed) AS extracted,
+    COUNT(*) FILTER (WHERE failed) AS failed,
+    COALESCE(MIN(error) FILTER (WHERE failed), '')::text AS sample_error
+FROM fields
+GROUP BY field
+ORDER BY field;
+
+-- name: GetPreviousParsedData :one
+-- Gets the parse of a URL under one schema made before the given time,
+-- that is the version preceding a record.
+SELECT * FROM parsed_data
+WHERE url_id = $1 AND schema = $2 AND created_at < sqlc.arg(before)::timestamptz
+ORDER BY created_at DESC, id
+LIMIT 1;
+
+-- name: CountParsedDataVersions :one
+SELECT COUNT(*) FROM parsed_data WHERE url_id = $1 AND schema = $2;
+
+-- name: AggregateParsedData :many
+-- Groups the latest parse of each URL by the value at group_path in its data
+-- and aggregates the values at value_path. Records without the group field
+-- form the group with an empty value. Values that are not JSON numbers are
+-- left out of min, max and avg, which are 0 when value_count is 0. An empty
+-- schema matches every schema.
+WITH latest AS (
+    SELECT DISTINCT ON (url_id, schema)
+        COALESCE(data #>> sqlc.arg(group_path)::text[], '') AS group_value,
+        CASE WHEN jsonb_typeof(data #> sqlc.arg(value_path)::text[]) = 'number'
+            THEN (data #> sqlc.arg(value_path)::text[])::float8
+        END AS value
+    FROM parsed_data
+    WHERE (sqlc.arg(schema)::text = '' OR schema = sqlc.arg(schema)::text)
+    ORDER BY url_id, schema, created_at DESC, id
+)
+SELECT
+    group_value::text AS group_value,
+    COUNT(*) AS count,
+    COUNT(value) AS value_count,
+    COALESCE(MIN(value), 0)::float8 AS min_value,
+    COALESCE(MAX(value), 0)::float8 AS max_value,
+    COALESCE(AVG(value), 0)::float8 AS avg_value
+FROM latest
+GROUP BY group_value
+ORDER BY count DESC, group_value
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: ListParsedDataSimhashes :many
+-- Lists the simhash of the latest parse of each URL, for duplicate
+-- detection. Latest parses without a simhash are left out. An empty schema
+-- matches every schema.
+SELECT id, url_id, url, schema, simhash::bigint AS simhash FROM (
+    SELECT DISTINCT ON (url_id, schema) id, url_id, url, schema, simhash
+    FROM parsed_data
+    WHERE (sqlc.arg(schema)::text = '' OR schema = sqlc.arg(schema)::text)
+    ORDER BY url_id, schema, created_at DESC, id
+) latest
+WHERE simhash IS NOT NULL
+ORDER BY url, schema, id
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: ListParsedDataForView :many
+-- Lists the latest parse of each URL under the view's schema and URL set
+-- whose data matches the view's filter, a SQL/JSON path predicate (see
+-- models.PredicateFilter). An empty schema or URL set matches everything.
+SELECT * FROM (
+    SELECT DISTINCT ON (url_id, schema) *
+    FROM parsed_data
+    WHERE (sqlc.arg(schema)::text = '' OR schema = sqlc.arg(schema)::text)
+    AND (cardinality(sqlc.arg(url_ids)::uuid[]) = 0 OR url_id = ANY(sqlc.arg(url_ids)::uuid[]))
+    ORDER BY url_id, schema, created_at DESC, id
+) latest
+WHERE jsonb_path_match(data, sqlc.arg(filter)::text::jsonpath, '{}', true)
+ORDER BY url, schema, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListParsedDataToRewrap :many
+-- Lists records with encrypted fields whose data keys are wrapped by another
+-- key than the primary key, for rewrapping after a key rotation.
+SELECT id, data, encryption_key_id FROM parsed_data
+WHERE encryption_key_id <> '' AND encryption_key_id <> sqlc.arg(primary_key_id)::text
+ORDER BY id
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: UpdateParsedDataEncryption :exec
+-- Replaces the data of a record whose encrypted fields were rewrapped. The
+-- record's change_seq moves on, so incremental readers see it again.
+UPDATE parsed_data SET data = $2, encryption_key_id = $3 WHERE id = $1;
+
+-- name: ExportParsedData :many
+-- Lists parsed records for an export, oldest first. An empty URL set or
+-- schema matches everything. With changed_since, only records created at or
+-- after it whose content_hash differs from the previous parse of the same
+-- URL under the same schema are listed; a URL's first parse counts as changed.
+SELECT p.id, p.url_id, p.url, p.title, p.content, p.content_hash, p.created_at
+FROM parsed_data p
+WHERE (cardinality(sqlc.arg(url_ids)::uuid[]) = 0 OR p.url_id = ANY(sqlc.arg(url_ids)::uuid[]))
+AND (sqlc.arg(schema)::text = '' OR p.schema = sqlc.arg(schema)::text)
+AND (sqlc.narg(from_time)::timestamptz IS NULL OR p.created_at >= sqlc.narg(from_time)::timestamptz)
+AND (sqlc.narg(to_time)::timestamptz IS NULL OR p.created_at < sqlc.narg(to_time)::timestamptz)
+AND (sqlc.narg(changed_since)::timestamptz IS NULL OR (
+    p.created_at >= sqlc.narg(changed_since)::timestamptz
+    AND p.content_hash IS DISTINCT FROM (
+        SELECT prev.content_hash FROM parsed_data prev
+        WHERE prev.url_id = p.url_id AND prev.schema = p.schema
+        AND (prev.created_at, prev.id) < (p.created_at, p.id)
+        ORDER BY prev.created_at DESC, prev.id DESC
+        LIMIT 1
+    )
+))
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg(max_results)::int;
@@ -0,0 +1,42 @@
+-- name: UpsertParserCandidate :one
+INSERT INTO parser_candidates (url_id, config)
+VALUES ($1, $2)
+ON CONFLICT (url_id) DO UPDATE SET
+    config = EXCLUDED.config,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetParserCandidate :one
+SELECT * FROM parser_candidates WHERE url_id = $1;
+
+-- name: DeleteParserCandidate :execrows
+-- Deletes a URL's candidate along with its parses
+DELETE FROM parser_candidates WHERE url_id = $1;
+
+-- name: DeleteCandidateParsedData :exec
+DELETE FROM candidate_parsed_data WHERE url_id = $1;
+
+-- name: CreateCandidateParsedData :one
+INSERT INTO candidate_parsed_data (
+    url_id, parsed_data_id, title, content, metadata, data
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListCandidateComparisons :many
+-- Pairs a URL's candidate parses with the active parses of the same pages,
+-- most recent scrape first
+SELECT
+    p.id AS record_id,
+    p.created_at AS scraped_at,
+    p.title AS active_title,
+    p.content AS active_content,
+    p.data AS active_data,
+    c.title AS candidate_title,
+    c.content AS candidate_content,
+    c.data AS candidate_data
+FROM candidate_parsed_data c
+JOIN parsed_data p ON p.id = c.parsed_data_id
+WHERE c.url_id = $1
+ORDER BY p.created_at DESC, c.id
+LIMIT sqlc.arg(max_results)::int;
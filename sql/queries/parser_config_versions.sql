@@ -0,0 +1,32 @@
+-- name: RecordParserConfigVersion :execrows
+-- Records a URL's parser config as its next version, unless it equals the
+-- latest version. Nothing is recorded for a URL that never had a config.
+INSERT INTO parser_config_versions (url_id, version, config, author, reason)
+SELECT sqlc.arg(url_id), COALESCE(MAX(v.version), 0) + 1, sqlc.arg(config), sqlc.arg(author), sqlc.arg(reason)
+FROM parser_config_versions v
+WHERE v.url_id = sqlc.arg(url_id)
+HAVING (
+    SELECT latest.config FROM parser_config_versions latest
+    WHERE latest.url_id = sqlc.arg(url_id)
+    ORDER BY latest.version DESC
+    LIMIT 1
+) IS DISTINCT FROM sqlc.arg(config)::jsonb;
+
+-- name: GetParserConfigVersion :one
+SELECT * FROM parser_config_versions WHERE url_id = $1 AND version = $2;
+
+-- name: GetLatestParserConfigVersion :one
+SELECT * FROM parser_config_versions
+WHERE url_id = $1
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: ListParserConfigVersions :many
+-- Lists a URL's parser config versions, newest first
+SELECT * FROM parser_config_versions
+WHERE url_id = $1
+ORDER BY version DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountParserConfigVersions :one
+SELECT COUNT(*) FROM parser_config_versions WHERE url_id = $1;
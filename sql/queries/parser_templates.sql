@@ -0,0 +1,21 @@
+-- name: GetParserTemplateByName :one
+SELECT * FROM parser_templates WHERE name = $1;
+
+-- name: ListParserTemplates :many
+SELECT * FROM parser_templates ORDER BY name ASC;
+
+-- name: CreateParserTemplate :one
+INSERT INTO parser_templates (
+    name, description, page_type, config
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UpdateParserTemplate :one
+UPDATE parser_templates
+SET description = $2, page_type = $3, config = $4, updated_at = NOW()
+WHERE name = $1
+RETURNING *;
+
+-- name: DeleteParserTemplate :execrows
+DELETE FROM parser_templates WHERE name = $1;
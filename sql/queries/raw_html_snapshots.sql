@@ -0,0 +1,23 @@
+-- name: CreateRawHTMLSnapshot :one
+INSERT INTO raw_html_snapshots (
+    url_id, task_id, status_code, content_type, content, content_hash
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetLatestRawHTMLSnapshot :one
+-- The URL's most recent snapshot, without its content
+SELECT id, content_hash, created_at FROM raw_html_snapshots
+WHERE url_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListRawHTMLSnapshotsInRange :many
+-- Lists a URL's snapshots taken from from_time up to but excluding
+-- to_time, oldest first
+SELECT * FROM raw_html_snapshots
+WHERE url_id = sqlc.arg(url_id)
+AND created_at >= sqlc.arg(from_time)::timestamptz
+AND created_at < sqlc.arg(to_time)::timestamptz
+ORDER BY created_at, id
+LIMIT sqlc.arg(max_results)::int;
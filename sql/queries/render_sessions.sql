@@ -0,0 +1,12 @@
+-- name: CreateRenderSession :exec
+INSERT INTO render_sessions (snapshot_id, url_id, session)
+VALUES ($1, $2, $3);
+
+-- name: GetLatestRenderSession :one
+-- The URL's most recent render session, with the scrape it was recorded for
+SELECT s.snapshot_id, r.task_id, r.status_code, s.session, s.created_at
+FROM render_sessions s
+JOIN raw_html_snapshots r ON r.id = s.snapshot_id
+WHERE s.url_id = $1
+ORDER BY s.created_at DESC
+LIMIT 1;
@@ -0,0 +1,79 @@
+-- name: CreateScrapingTask :one
+INSERT INTO scraping_tasks (id, url_id, attempt, due_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetScrapingTask :one
+SELECT * FROM scraping_tasks WHERE id = $1;
+
+-- name: CompleteScrapingTask :exec
+UPDATE scraping_tasks
+SET status = $2, status_code = $3, error_code = $4, error_message = $5,
+    duration_ms = $6, completed_at = $7, proxy_egress_bytes = $8, render_ms = $9,
+    throttled_ms = $10, final_url = $11, redirect_chain = $12, response_headers = $13,
+    dns_ms = $14
+WHERE id = $1;
+
+-- name: CountScrapingTaskFailuresByErrorCode :many
+SELECT error_code::text AS error_code, COUNT(*) AS count
+FROM scraping_tasks
+WHERE error_code IS NOT NULL AND completed_at >= $1
+GROUP BY error_code
+ORDER BY count DESC, error_code;
+
+-- name: CountScrapingTaskSLI :one
+-- Scrapes of URLs (of one project, when given) that were due from
+-- sqlc.arg(since) until sqlc.arg(until): all of them, those completed, those
+-- completed within max_delay_ms of their due time and those that succeeded.
+SELECT COUNT(*) AS total,
+       COUNT(*) FILTER (WHERE t.completed_at IS NOT NULL) AS completed,
+       COUNT(*) FILTER (WHERE t.completed_at <= t.due_at + sqlc.arg(max_delay_ms)::bigint * interval '1 millisecond') AS on_time,
+       COUNT(*) FILTER (WHERE t.status = 'success') AS succeeded
+FROM scraping_tasks t
+JOIN urls u ON u.id = t.url_id
+WHERE t.due_at >= sqlc.arg(since) AND t.due_at < sqlc.arg(until)
+AND (sqlc.narg(project)::text IS NULL OR u.project = sqlc.narg(project)::text);
+
+-- name: CountScrapingTaskStatusCodes :many
+-- Completed scrapes per HTTP status code (0 for scrapes that got no
+-- response) since sqlc.arg(since), and in the period from
+-- sqlc.arg(previous_since) to since for comparison.
+SELECT COALESCE(status_code, 0)::int AS status_code,
+       COUNT(*) FILTER (WHERE completed_at >= sqlc.arg(since)) AS count,
+       COUNT(*) FILTER (WHERE completed_at < sqlc.arg(since)) AS previous_count
+FROM scraping_tasks
+WHERE completed_at >= sqlc.arg(previous_since)
+GROUP BY 1
+ORDER BY 1;
+
+-- name: CountScrapingTaskOutcomes :one
+SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE status = 'success') AS succeeded
+FROM scraping_tasks
+WHERE completed_at >= $1;
+
+-- name: GetLastScrapingTaskCompletedAt :one
+SELECT completed_at FROM scraping_tasks
+WHERE completed_at IS NOT NULL
+ORDER BY completed_at DESC
+LIMIT 1;
+
+-- name: CountURLScrapingTasksSince :one
+SELECT COUNT(*) FROM scraping_tasks
+WHERE url_id = $1 AND created_at > $2;
+
+-- name: ListScrapingTasks :many
+-- Lists scrapes, newest first, of one URL or of every URL when url_id is
+-- NULL. headers matches scrapes whose response headers contain all of its
+-- name/value pairs, header_names those that have all of the named headers.
+SELECT * FROM scraping_tasks t
+WHERE (sqlc.narg(url_id)::uuid IS NULL OR t.url_id = sqlc.narg(url_id)::uuid)
+AND (sqlc.arg(headers)::jsonb = '{}'::jsonb OR t.response_headers @> sqlc.arg(headers)::jsonb)
+AND (cardinality(sqlc.arg(header_names)::text[]) = 0 OR t.response_headers ?& sqlc.arg(header_names)::text[])
+ORDER BY t.created_at DESC, t.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountScrapingTasks :one
+SELECT COUNT(*) FROM scraping_tasks t
+WHERE (sqlc.narg(url_id)::uuid IS NULL OR t.url_id = sqlc.narg(url_id)::uuid)
+AND (sqlc.arg(headers)::jsonb = '{}'::jsonb OR t.response_headers @> sqlc.arg(headers)::jsonb)
+AND (cardinality(sqlc.arg(header_names)::text[]) = 0 OR t.response_headers ?& sqlc.arg(header_names)::text[]);
@@ -0,0 +1,47 @@
+-- name: RecordURLMove :one
+-- Records a permanent redirect seen when scraping a URL, counting the
+-- scrapes in a row that were moved to the same location
+INSERT INTO url_moves (url_id, from_url, to_url, first_seen_at, last_seen_at)
+VALUES (sqlc.arg(url_id), sqlc.arg(from_url), sqlc.arg(to_url), sqlc.arg(seen_at), sqlc.arg(seen_at))
+ON CONFLICT (url_id, from_url, to_url) DO UPDATE
+SET hits = url_moves.hits + 1, last_seen_at = EXCLUDED.last_seen_at
+RETURNING *;
+
+-- name: ClearPendingURLMoves :exec
+-- Forgets the unflagged moves of a URL other than to_url, once a scrape was
+-- not moved there; an empty to_url forgets all of them
+DELETE FROM url_moves
+WHERE url_id = sqlc.arg(url_id) AND flagged_at IS NULL AND to_url <> sqlc.arg(to_url);
+
+-- name: FlagURLMove :exec
+UPDATE url_moves SET flagged_at = sqlc.arg(flagged_at)::timestamptz
+WHERE url_id = sqlc.arg(url_id) AND from_url = sqlc.arg(from_url) AND to_url = sqlc.arg(to_url)
+AND flagged_at IS NULL;
+
+-- name: ApplyURLMove :execrows
+-- Moves a URL to the location it redirects to, unless another URL already
+-- has that address
+WITH moved AS (
+    UPDATE urls SET url = sqlc.arg(to_url), updated_at = now()
+    WHERE urls.id = sqlc.arg(url_id) AND urls.url = sqlc.arg(from_url)
+    AND NOT EXISTS (SELECT 1 FROM urls other WHERE other.url = sqlc.arg(to_url))
+    RETURNING urls.id
+)
+UPDATE url_moves SET applied_at = sqlc.arg(applied_at)::timestamptz
+FROM moved
+WHERE url_moves.url_id = moved.id AND url_moves.from_url = sqlc.arg(from_url) AND url_moves.to_url = sqlc.arg(to_url);
+
+-- name: ListURLMoves :many
+-- Lists flagged moves, most recently seen first. state is 'pending' for
+-- moves not applied to the stored URL, 'applied' for applied ones, or empty
+-- for both.
+SELECT * FROM url_moves
+WHERE flagged_at IS NOT NULL
+AND (sqlc.arg(state)::text = '' OR (applied_at IS NOT NULL) = (sqlc.arg(state)::text = 'applied'))
+ORDER BY last_seen_at DESC, url_id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountURLMoves :one
+SELECT COUNT(*) FROM url_moves
+WHERE flagged_at IS NOT NULL
+AND (sqlc.arg(state)::text = '' OR (applied_at IS NOT NULL) = (sqlc.arg(state)::text = 'applied'));
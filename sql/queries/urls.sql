@@ -2,26 +2,104 @@
 SELECT * FROM urls WHERE id = $1;
 
 -- name: ListURLs :many
-SELECT * FROM urls ORDER BY created_at DESC LIMIT $1 OFFSET $2;
+-- An empty pattern matches every URL, otherwise it is matched with ILIKE
+-- against the address and tags. URLs are sorted by sort_by (created_at,
+-- next_scrape_at, last_scraped_at, status or url), URLs that were never
+-- scheduled or scraped last.
+SELECT * FROM urls
+WHERE deleted_at IS NULL
+AND (sqlc.arg(pattern)::text = '' OR url_search_text(url, tags) ILIKE sqlc.arg(pattern)::text)
+ORDER BY
+    CASE WHEN NOT sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'created_at' THEN created_at
+            WHEN 'next_scrape_at' THEN next_scrape_at
+            WHEN 'last_scraped_at' THEN last_scraped_at
+        END
+    END ASC NULLS LAST,
+    CASE WHEN sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text
+            WHEN 'created_at' THEN created_at
+            WHEN 'next_scrape_at' THEN next_scrape_at
+            WHEN 'last_scraped_at' THEN last_scraped_at
+        END
+    END DESC NULLS LAST,
+    CASE WHEN NOT sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text WHEN 'status' THEN status WHEN 'url' THEN url END
+    END ASC,
+    CASE WHEN sqlc.arg(descending)::bool THEN
+        CASE sqlc.arg(sort_by)::text WHEN 'status' THEN status WHEN 'url' THEN url END
+    END DESC,
+    created_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: CountURLs :one
-SELECT COUNT(*) FROM urls;
+SELECT COUNT(*) FROM urls
+WHERE deleted_at IS NULL
+AND (sqlc.arg(pattern)::text = '' OR url_search_text(url, tags) ILIKE sqlc.arg(pattern)::text);
+
+-- name: CountURLsPerStatus :many
+SELECT status, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY status
+ORDER BY count DESC, status;
+
+-- name: CountURLsPerDomain :many
+-- Counts URLs per host, most used hosts first.
+SELECT COALESCE(lower(substring(url from '^[^:]+://([^/:?#]+)')), '')::text AS domain, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY domain
+ORDER BY count DESC, domain
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: CountURLsPerProject :many
+SELECT project, COUNT(*) AS count
+FROM urls
+WHERE deleted_at IS NULL
+GROUP BY project
+ORDER BY project;
 
 -- name: CreateURL :one
 INSERT INTO urls (
     url, frequency, status, max_retries, timeout, rate_limit, 
-    user_agent, parser_config, next_scrape_at
+    user_agent, parser_config, next_scrape_at, retry_policy, tags, project,
+    assertions, region, archive_policy
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 ) RETURNING *;
 
 -- name: GetURLsScheduledForScraping :many
 SELECT * FROM urls 
 WHERE next_scrape_at BETWEEN $1 AND $2 
-AND status IN ('pending', 'retry')
+AND status IN ('pending', 'retry', 'degraded')
+AND deleted_at IS NULL
 ORDER BY next_scrape_at ASC 
 LIMIT $3;
 
+-- name: GetURLsScheduledFairly :many
+-- Gets the URLs due for scraping in a time range like
+-- GetURLsScheduledForScraping, interleaving projects so one project's
+-- backlog cannot fill the batch: each project's due URLs are ranked by due
+-- time, and the batch takes the URLs of lowest rank divided by their
+-- project's share. Projects missing from share_projects have a share of 1.
+WITH shares AS (
+    SELECT * FROM unnest(sqlc.arg(share_projects)::text[], sqlc.arg(shares)::int[]) AS s(project, share)
+), due AS (
+    SELECT u.id,
+        (row_number() OVER (PARTITION BY u.project ORDER BY u.next_scrape_at, u.id))::float8 / COALESCE(s.share, 1) AS turn
+    FROM urls u
+    LEFT JOIN shares s ON s.project = u.project
+    WHERE u.next_scrape_at BETWEEN sqlc.arg(from_time)::timestamptz AND sqlc.arg(to_time)::timestamptz
+    AND u.status IN ('pending', 'retry', 'degraded')
+    AND u.deleted_at IS NULL
+)
+SELECT urls.* FROM urls
+JOIN due ON due.id = urls.id
+ORDER BY due.turn, urls.next_scrape_at, urls.id
+LIMIT sqlc.arg(max_results)::int;
+
 -- name: GetURLsByStatus :many
 SELECT * FROM urls 
 WHERE status = $1 
@@ -31,6 +109,19 @@ LIMIT $2 OFFSET $3;
 -- name: UpdateURLStatus :exec
 UPDATE urls SET status = $2, updated_at = NOW() WHERE id = $1;
 
+-- name: TransitionURLStatus :one
+-- Sets the status of a URL if its current status is one of from_statuses and
+-- returns the status it had. No row is returned if the URL is missing or in
+-- another status.
+UPDATE urls u SET status = sqlc.arg(status), updated_at = NOW()
+FROM (SELECT id, status FROM urls WHERE id = sqlc.arg(id) FOR UPDATE) previous
+WHERE u.id = previous.id
+AND previous.status = ANY(sqlc.arg(from_statuses)::text[])
+RETURNING previous.status::text AS previous_status;
+
+-- name: UpdateURLParserConfig :exec
+UPDATE urls SET parser_config = $2, updated_at = NOW() WHERE id = $1;
+
 -- name: UpdateNextScrapeTime :exec
 UPDATE urls SET next_scrape_at = $2, updated_at = NOW() WHERE id = $1;
 
@@ -46,7 +137,8 @@ UPDATE urls SET retry_count = 0, updated_at = NOW() WHERE id = $1;
 -- name: GetURLsForImmediateScraping :many
 SELECT * FROM urls 
 WHERE next_scrape_at <= $1 
-AND status IN ('pending', 'retry')
+AND status IN ('pending', 'retry', 'degraded')
+AND deleted_at IS NULL
 ORDER BY next_scrape_at ASC 
 LIMIT $2;
 
@@ -54,4 +146,104 @@ LIMIT $2;
 SELECT COUNT(*) FROM urls WHERE status = $1;
 
 -- name: GetURLsByIDs :many
-SELECT * FROM urls WHERE id = ANY($1::uuid[]);
\ No newline at end of file
+SELECT * FROM urls WHERE id = ANY($1::uuid[]);
+-- name: GetOverdueURLs :many
+SELECT * FROM urls
+WHERE next_scrape_at < $1
+AND status IN ('pending', 'retry')
+AND deleted_at IS NULL
+ORDER BY next_scrape_at ASC
+LIMIT $2;
+
+-- name: GetURLsWithConsecutiveFailures :many
+SELECT * FROM urls u
+WHERE u.status IN ('pending', 'retry', 'failed')
+AND u.deleted_at IS NULL
+AND (
+    SELECT COUNT(*) FROM (
+        SELECT t.status FROM scraping_tasks t
+        WHERE t.url_id = u.id AND t.completed_at IS NOT NULL
+        ORDER BY t.completed_at DESC
+        LIMIT sqlc.arg(failures)::int
+    ) recent
+    WHERE recent.status <> 'success'
+) >= sqlc.arg(failures)::int
+ORDER BY u.updated_at DESC
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: CountURLsForBulkAction :one
+-- Counts the URLs a bulk delete (deleted = false), restore (deleted = true)
+-- or reset (status = 'failed') would affect. Empty filters match everything.
+SELECT COUNT(*) FROM urls
+WHERE (deleted_at IS NOT NULL) = sqlc.arg(deleted)::bool
+AND (cardinality(sqlc.arg(ids)::uuid[]) = 0 OR id = ANY(sqlc.arg(ids)::uuid[]))
+AND (sqlc.arg(tag)::text = '' OR sqlc.arg(tag)::text = ANY(tags))
+AND (sqlc.arg(domain)::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower(sqlc.arg(domain)::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower(sqlc.arg(domain)::text))
+AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text);
+
+-- name: SoftDeleteURLs :many
+UPDATE urls SET deleted_at = NOW(), updated_at = NOW()
+WHERE deleted_at IS NULL
+AND (cardinality(sqlc.arg(ids)::uuid[]) = 0 OR id = ANY(sqlc.arg(ids)::uuid[]))
+AND (sqlc.arg(tag)::text = '' OR sqlc.arg(tag)::text = ANY(tags))
+AND (sqlc.arg(domain)::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower(sqlc.arg(domain)::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower(sqlc.arg(domain)::text))
+RETURNING id, url;
+
+-- name: RestoreURLs :many
+UPDATE urls SET deleted_at = NULL, updated_at = NOW()
+WHERE deleted_at IS NOT NULL
+AND (cardinality(sqlc.arg(ids)::uuid[]) = 0 OR id = ANY(sqlc.arg(ids)::uuid[]))
+AND (sqlc.arg(tag)::text = '' OR sqlc.arg(tag)::text = ANY(tags))
+AND (sqlc.arg(domain)::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower(sqlc.arg(domain)::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower(sqlc.arg(domain)::text))
+RETURNING id, url;
+
+-- name: ResetFailedURLs :many
+-- Moves failed URLs back to pending with a fresh retry budget and schedules
+-- them right away. Returns the URLs that were reset.
+UPDATE urls SET status = 'pending', retry_count = 0, next_scrape_at = NOW(), updated_at = NOW()
+WHERE status = 'failed' AND deleted_at IS NULL
+AND (cardinality(sqlc.arg(ids)::uuid[]) = 0 OR id = ANY(sqlc.arg(ids)::uuid[]))
+AND (sqlc.arg(tag)::text = '' OR sqlc.arg(tag)::text = ANY(tags))
+AND (sqlc.arg(domain)::text = ''
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) = lower(sqlc.arg(domain)::text)
+    OR lower(substring(url from '^[^:]+://([^/:?#]+)')) LIKE '%.' || lower(sqlc.arg(domain)::text))
+RETURNING id, url;
+
+-- name: ListURLsForExport :many
+SELECT * FROM urls WHERE deleted_at IS NULL ORDER BY url;
+
+-- name: UpsertURL :one
+-- Creates a URL or replaces the configuration of the URL with the same
+-- address, restoring it if it was deleted. Status and schedule are kept, and
+-- a URL managed by configuration sync stays managed.
+INSERT INTO urls (
+    url, frequency, status, max_retries, timeout, rate_limit,
+    user_agent, parser_config, next_scrape_at, retry_policy, tags,
+    project, managed, assertions, region, archive_policy
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT (url) DO UPDATE SET
+    frequency = EXCLUDED.frequency,
+    max_retries = EXCLUDED.max_retries,
+    timeout = EXCLUDED.timeout,
+    rate_limit = EXCLUDED.rate_limit,
+    user_agent = EXCLUDED.user_agent,
+    parser_config = EXCLUDED.parser_config,
+    retry_policy = EXCLUDED.retry_policy,
+    tags = EXCLUDED.tags,
+    project = EXCLUDED.project,
+    managed = urls.managed OR EXCLUDED.managed,
+    assertions = EXCLUDED.assertions,
+    region = EXCLUDED.region,
+    archive_policy = EXCLUDED.archive_policy,
+    next_scrape_at = COALESCE(urls.next_scrape_at, EXCLUDED.next_scrape_at),
+    deleted_at = NULL,
+    updated_at = NOW()
+RETURNING id, (xmax = 0)::bool AS inserted;
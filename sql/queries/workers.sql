@@ -0,0 +1,42 @@
+-- name: RecordWorkerHeartbeat :exec
+-- Registers an instance or refreshes its heartbeat and load.
+INSERT INTO workers (
+    id, kind, version, host, started_at, active_tasks, capacity, queued_tasks,
+    region, paused_topics
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT (id) DO UPDATE
+SET kind = EXCLUDED.kind,
+    version = EXCLUDED.version,
+    host = EXCLUDED.host,
+    started_at = EXCLUDED.started_at,
+    active_tasks = EXCLUDED.active_tasks,
+    capacity = EXCLUDED.capacity,
+    queued_tasks = EXCLUDED.queued_tasks,
+    region = EXCLUDED.region,
+    paused_topics = EXCLUDED.paused_topics,
+    last_heartbeat_at = NOW();
+
+-- name: ListWorkers :many
+-- Lists the registered instances, most recently seen first.
+SELECT * FROM workers
+ORDER BY last_heartbeat_at DESC, id;
+
+-- name: ListHealthyWorkerRegions :many
+-- Lists the regions with a scraper instance that sent a heartbeat since a time.
+SELECT DISTINCT region FROM workers
+WHERE kind = 'scraper'
+AND region <> ''
+AND last_heartbeat_at >= $1
+ORDER BY region;
+
+-- name: DeleteWorker :exec
+-- Removes an instance that shut down.
+DELETE FROM workers
+WHERE id = $1;
+
+-- name: DeleteStaleWorkers :execrows
+-- Removes instances whose last heartbeat is older than a time.
+DELETE FROM workers
+WHERE last_heartbeat_at < $1;
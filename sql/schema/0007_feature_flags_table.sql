@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name TEXT PRIMARY KEY,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    rollout_percent INTEGER NOT NULL DEFAULT 100 CHECK (rollout_percent BETWEEN 0 AND 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Per-tenant overrides take precedence over the flag's global state.
+-- Overrides may exist for flags that are only defined in configuration.
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    flag_name TEXT NOT NULL,
+    tenant_id TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (flag_name, tenant_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS feature_flag_overrides;
+DROP TABLE IF EXISTS feature_flags;
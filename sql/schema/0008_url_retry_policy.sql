@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-URL retry policy; NULL means the default policy derived from max_retries
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS retry_policy JSONB;
+
+-- +goose Down
+ALTER TABLE urls DROP COLUMN IF EXISTS retry_policy;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- One row per scrape attempt published to the scraping tasks topic.
+-- error_code holds the failure class (see shared/models/failure.go).
+CREATE TABLE IF NOT EXISTS scraping_tasks (
+    id UUID PRIMARY KEY,
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    attempt INT NOT NULL DEFAULT 1,
+    status TEXT NOT NULL DEFAULT 'pending',
+    status_code INT,
+    error_code TEXT,
+    error_message TEXT,
+    duration_ms BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_url_id ON scraping_tasks (url_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_error_code ON scraping_tasks (completed_at, error_code)
+    WHERE error_code IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS scraping_tasks;
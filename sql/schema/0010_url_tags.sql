@@ -0,0 +1,8 @@
+-- +goose Up
+-- Free-form labels used to group URLs, e.g. for bulk operations
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_urls_tags ON urls USING GIN (tags);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_tags;
+ALTER TABLE urls DROP COLUMN IF EXISTS tags;
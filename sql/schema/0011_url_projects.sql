@@ -0,0 +1,10 @@
+-- +goose Up
+-- Project a URL belongs to, and whether configuration-as-code sync manages it
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS project TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS managed BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_urls_project ON urls(project);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_project;
+ALTER TABLE urls DROP COLUMN IF EXISTS managed;
+ALTER TABLE urls DROP COLUMN IF EXISTS project;
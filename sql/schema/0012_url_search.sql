@@ -0,0 +1,16 @@
+-- +goose Up
+-- Trigram index for free-text search over URL addresses and tags
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION url_search_text(url TEXT, tags TEXT[]) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT url || ' ' || array_to_string(tags, ' ')
+$$;
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_urls_search ON urls USING GIN (url_search_text(url, tags) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_search;
+DROP FUNCTION IF EXISTS url_search_text(TEXT, TEXT[]);
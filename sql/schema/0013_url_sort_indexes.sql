@@ -0,0 +1,14 @@
+-- +goose Up
+-- Indexes for the sort orders of the URL list. ListURLs picks the order with
+-- CASE expressions on its parameters, which Postgres folds into a plain
+-- ORDER BY column when planning, so these indexes serve it.
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls (created_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_next_scrape_at ON urls (next_scrape_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_last_scraped_at ON urls (last_scraped_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_status ON urls (status) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_status;
+DROP INDEX IF EXISTS idx_urls_last_scraped_at;
+DROP INDEX IF EXISTS idx_urls_next_scrape_at;
+DROP INDEX IF EXISTS idx_urls_created_at;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-URL content assertions evaluated after each scrape; NULL means none
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS assertions JSONB;
+
+-- +goose Down
+ALTER TABLE urls DROP COLUMN IF EXISTS assertions;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- One row per daily scrape budget (see scheduler.budgets) and day on which
+-- the budget was used up. deferred_scrapes counts the scrapes the scheduler
+-- moved to the next day because of it.
+CREATE TABLE IF NOT EXISTS scrape_budget_events (
+    scope TEXT NOT NULL, -- 'domain' or 'project'
+    name TEXT NOT NULL,
+    day DATE NOT NULL,
+    daily_limit INTEGER NOT NULL,
+    deferred_scrapes INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, name, day)
+);
+
+-- Budgets are checked against the tasks published since the start of the day
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_created_at ON scraping_tasks (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scraping_tasks_created_at;
+DROP TABLE IF EXISTS scrape_budget_events;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Cost attributes reported with each scrape result: bytes sent through the
+-- proxy and time spent rendering the page in a headless browser.
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS proxy_egress_bytes BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS render_ms BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_completed_at ON scraping_tasks (completed_at)
+    WHERE completed_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scraping_tasks_completed_at;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS render_ms;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS proxy_egress_bytes;
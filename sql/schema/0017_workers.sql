@@ -0,0 +1,18 @@
+-- +goose Up
+-- One row per running scraper or parser instance, refreshed by its
+-- heartbeat. Instances remove their row when they shut down cleanly, so a
+-- row with an old last_heartbeat_at is an instance that died.
+CREATE TABLE IF NOT EXISTS workers (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL, -- 'scraper' or 'parser'
+    version TEXT NOT NULL DEFAULT '',
+    host TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    last_heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    active_tasks INTEGER NOT NULL DEFAULT 0,
+    capacity INTEGER NOT NULL DEFAULT 0,
+    queued_tasks INTEGER NOT NULL DEFAULT 0
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS workers;
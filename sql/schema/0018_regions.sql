@@ -0,0 +1,9 @@
+-- +goose Up
+-- Region a URL must be scraped from, e.g. for geo-restricted content, and
+-- the region a worker runs in. '' means any region.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE workers DROP COLUMN IF EXISTS region;
+ALTER TABLE urls DROP COLUMN IF EXISTS region;
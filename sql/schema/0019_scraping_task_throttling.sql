@@ -0,0 +1,7 @@
+-- +goose Up
+-- Time a scraper waited for the URL's and the domain's rate limits before
+-- fetching, reported with each scrape result. It is not part of duration_ms.
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS throttled_ms BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS throttled_ms;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Parsed records, one per parse of a URL's content under a schema. A new
+-- parse of the same URL and schema is a new row, so older rows are the
+-- record's history. content_hash is the SHA-256 of the parsed content and is
+-- equal for identical parses. change_seq is taken from a sequence whenever a
+-- row is inserted or updated, so incremental readers can resume after the
+-- last change they saw.
+CREATE SEQUENCE IF NOT EXISTS parsed_data_change_seq;
+
+CREATE TABLE IF NOT EXISTS parsed_data (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    schema TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    data JSONB NOT NULL DEFAULT '{}',
+    content_hash TEXT NOT NULL DEFAULT '',
+    change_seq BIGINT NOT NULL DEFAULT nextval('parsed_data_change_seq'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_parsed_data_change_seq ON parsed_data (change_seq);
+CREATE INDEX IF NOT EXISTS idx_parsed_data_url_schema ON parsed_data (url_id, schema, created_at DESC);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION parsed_data_touch() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.change_seq := nextval('parsed_data_change_seq');
+    NEW.updated_at := now();
+    RETURN NEW;
+END
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER parsed_data_touch BEFORE UPDATE ON parsed_data
+    FOR EACH ROW EXECUTE FUNCTION parsed_data_touch();
+
+-- +goose Down
+DROP TABLE IF EXISTS parsed_data;
+DROP FUNCTION IF EXISTS parsed_data_touch();
+DROP SEQUENCE IF EXISTS parsed_data_change_seq;
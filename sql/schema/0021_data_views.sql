@@ -0,0 +1,17 @@
+-- +goose Up
+-- Saved filters over parsed data, fetched by name. An empty schema or URL
+-- set matches every record; predicates are conditions on the parsed fields
+-- (see models.FieldPredicate).
+CREATE TABLE IF NOT EXISTS data_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    schema TEXT NOT NULL DEFAULT '',
+    url_ids UUID[] NOT NULL DEFAULT '{}',
+    predicates JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS data_views;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Notification channels managed through the API, used next to the channels
+-- of the notifications configuration section. settings holds the type's
+-- settings (URL, SMTP server, routing key, ...) as JSON, see
+-- notify.ChannelSettings.
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    settings JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_channels;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- History of the system alert rules: one row each time a rule starts firing
+-- and each time it resolves. value is the measurement that triggered the
+-- change, threshold the rule's threshold at the time.
+CREATE TABLE IF NOT EXISTS alert_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    state TEXT NOT NULL,
+    severity TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_created_at ON alert_events (created_at DESC);
+
+-- The no_scrapes and success_rate rules look at recently completed tasks
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_completed_at ON scraping_tasks (completed_at DESC)
+    WHERE completed_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scraping_tasks_completed_at;
+DROP TABLE IF EXISTS alert_events;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Maintenance mode switch, a single row. While enabled the URL Manager stops
+-- scheduling, workers finish their running tasks without taking new ones
+-- and the API Gateway rejects changes with message as the reason.
+CREATE TABLE IF NOT EXISTS maintenance_mode (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    message TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS maintenance_mode;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Raw HTML kept from scrapes, for debugging and re-parsing. A URL's
+-- archive_policy decides which scrapes are kept (see shared/archive); NULL
+-- keeps every scrape. content_hash is the SHA-256 of content.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS archive_policy JSONB;
+
+CREATE TABLE IF NOT EXISTS raw_html_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    task_id UUID,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_raw_html_snapshots_url_id ON raw_html_snapshots (url_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS raw_html_snapshots;
+ALTER TABLE urls DROP COLUMN IF EXISTS archive_policy;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Candidate parser configs run in shadow mode next to a URL's active
+-- parser_config. Each parse made with the candidate is stored in
+-- candidate_parsed_data next to the active parse of the same page, so the
+-- two can be compared field by field before the candidate is promoted.
+CREATE TABLE IF NOT EXISTS parser_candidates (
+    url_id UUID PRIMARY KEY REFERENCES urls(id) ON DELETE CASCADE,
+    config JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS candidate_parsed_data (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES parser_candidates(url_id) ON DELETE CASCADE,
+    parsed_data_id UUID NOT NULL REFERENCES parsed_data(id) ON DELETE CASCADE,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_candidate_parsed_data_url_id ON candidate_parsed_data (url_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS candidate_parsed_data;
+DROP TABLE IF EXISTS parser_candidates;
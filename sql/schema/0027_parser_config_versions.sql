@@ -0,0 +1,23 @@
+-- +goose Up
+-- Version history of each URL's parser config. A version is recorded
+-- whenever the config changes, with who changed it and why, so a change can
+-- be diffed against earlier versions and rolled back. config is NULL for a
+-- version where the URL had no parser config.
+CREATE TABLE IF NOT EXISTS parser_config_versions (
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    config JSONB,
+    author TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (url_id, version)
+);
+
+-- The configs in place before versioning become version 1
+INSERT INTO parser_config_versions (url_id, version, config, reason, created_at)
+SELECT id, 1, parser_config, 'initial', updated_at FROM urls
+WHERE parser_config IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS parser_config_versions;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Where each scrape ended up: the final URL after redirects and the
+-- redirects followed on the way, as a JSON array of {url, status_code} hops.
+-- Both are NULL when the page was not redirected.
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS final_url TEXT;
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS redirect_chain JSONB;
+
+-- Permanent redirects (301 and 308) seen when scraping a URL. hits counts the
+-- scrapes in a row that were moved to to_url; a move is flagged once hits
+-- reaches redirects.move_threshold and applied once the stored URL was
+-- updated to to_url.
+CREATE TABLE IF NOT EXISTS url_moves (
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    from_url TEXT NOT NULL,
+    to_url TEXT NOT NULL,
+    hits INT NOT NULL DEFAULT 1,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    flagged_at TIMESTAMPTZ,
+    applied_at TIMESTAMPTZ,
+    PRIMARY KEY (url_id, from_url, to_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_moves_flagged_at ON url_moves (flagged_at) WHERE flagged_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS url_moves;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS redirect_chain;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS final_url;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Response headers of each scrape named in scraping.capture_headers, as a
+-- JSON object of lowercase header names to values, for filtering the
+-- scrape history by header values such as server or x-robots-tag
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS response_headers JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_response_headers ON scraping_tasks USING GIN (response_headers);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scraping_tasks_response_headers;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS response_headers;
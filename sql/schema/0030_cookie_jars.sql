@@ -0,0 +1,15 @@
+-- +goose Up
+-- Cookies scrapers keep per registrable domain between scrapes, when
+-- scraping.cookies is enabled (see shared/cookies). data is the jar
+-- encrypted with AES-GCM; cookie_count and expires_at, when the last cookie
+-- expires, are kept in the clear for the API.
+CREATE TABLE IF NOT EXISTS cookie_jars (
+    domain TEXT PRIMARY KEY,
+    data BYTEA NOT NULL,
+    cookie_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS cookie_jars;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Debug captures of a URL's next scrape as a HAR document, requested through
+-- the API. A request is pending until the scheduler claims it for a scrape
+-- (task_id) and the scrape's HAR arrives with the result. expires_at bounds
+-- how long a request waits for a scrape and, once captured, how long the HAR
+-- is kept. A URL has at most one capture; a new request replaces it.
+CREATE TABLE IF NOT EXISTS har_captures (
+    url_id UUID PRIMARY KEY REFERENCES urls(id) ON DELETE CASCADE,
+    task_id UUID,
+    har JSONB,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    captured_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_har_captures_expires_at ON har_captures (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS har_captures;
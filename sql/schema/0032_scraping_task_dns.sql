@@ -0,0 +1,7 @@
+-- +goose Up
+-- Time each scrape spent resolving host names in milliseconds, 0 when the
+-- names came from the scrapers' DNS cache
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS dns_ms BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS dns_ms;
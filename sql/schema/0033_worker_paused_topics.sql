@@ -0,0 +1,7 @@
+-- +goose Up
+-- Topics an instance's Kafka consumer has paused through the consumer
+-- control topic, reported with each heartbeat; '*' means every topic
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS paused_topics TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE workers DROP COLUMN IF EXISTS paused_topics;
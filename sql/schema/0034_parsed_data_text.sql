@@ -0,0 +1,9 @@
+-- +goose Up
+-- Normalized visible text of the parsed page (see parser.NormalizedText),
+-- stored when the parser config sets extract_text. When set, content_hash
+-- covers the title and this text rather than the extracted fields, so
+-- markup-only changes to a page do not count as a change.
+ALTER TABLE parsed_data ADD COLUMN IF NOT EXISTS normalized_text TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE parsed_data DROP COLUMN IF EXISTS normalized_text;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- 64-bit simhash of the parsed content (see parser.Simhash), stored as the
+-- bits of a signed integer, for finding URLs that serve nearly the same
+-- content. NULL for parses without content and those made before it existed.
+ALTER TABLE parsed_data ADD COLUMN IF NOT EXISTS simhash BIGINT;
+
+-- +goose Down
+ALTER TABLE parsed_data DROP COLUMN IF EXISTS simhash;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Errors of the selectors and rules that extracted nothing, keyed by field
+-- name (see models.ParsedData.FieldErrors), so partial parses are stored
+-- and field completeness can be measured. NULL for parses made before it
+-- existed, which are left out of completeness.
+ALTER TABLE parsed_data ADD COLUMN IF NOT EXISTS field_errors JSONB;
+
+-- +goose Down
+ALTER TABLE parsed_data DROP COLUMN IF EXISTS field_errors;
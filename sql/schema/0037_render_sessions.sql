@@ -0,0 +1,15 @@
+-- +goose Up
+-- Console log and network requests of rendered scrapes whose URL's archive
+-- policy sets render_session (see shared/render), kept with the raw HTML
+-- snapshot of the scrape and deleted with it.
+CREATE TABLE IF NOT EXISTS render_sessions (
+    snapshot_id UUID PRIMARY KEY REFERENCES raw_html_snapshots(id) ON DELETE CASCADE,
+    url_id UUID NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    session JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_render_sessions_url_id ON render_sessions (url_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS render_sessions;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- When each scrape was due: the URL's next_scrape_at when the scheduler
+-- picked it up, or the time a manual trigger was received. Scheduling
+-- latency SLOs compare it with completed_at. NULL for tasks recorded
+-- before the column was added.
+ALTER TABLE scraping_tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_scraping_tasks_due_at ON scraping_tasks (due_at)
+    WHERE due_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_scraping_tasks_due_at;
+ALTER TABLE scraping_tasks DROP COLUMN IF EXISTS due_at;
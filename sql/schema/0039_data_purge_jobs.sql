@@ -0,0 +1,28 @@
+-- +goose Up
+-- Jobs deleting the parsed and raw data matching a filter, requested through
+-- DELETE /api/v1/data for data subject deletion requests. The URL Manager
+-- claims pending jobs and deletes in batches, adding the rows deleted to the
+-- counters and touching updated_at after each batch; a running job whose
+-- updated_at stopped moving is claimed again. url_id is not a foreign key
+-- so the job outlives the URL it purged.
+CREATE TABLE IF NOT EXISTS data_purge_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID,
+    schema TEXT NOT NULL DEFAULT '',
+    from_time TIMESTAMPTZ,
+    to_time TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL DEFAULT '',
+    parsed_data_deleted BIGINT NOT NULL DEFAULT 0,
+    raw_html_deleted BIGINT NOT NULL DEFAULT 0,
+    har_captures_deleted BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_purge_jobs_status ON data_purge_jobs (status, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS data_purge_jobs;
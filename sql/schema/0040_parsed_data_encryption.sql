@@ -0,0 +1,13 @@
+-- +goose Up
+-- ID of the key wrapping the data keys of a parsed record's encrypted fields,
+-- empty for records without encrypted fields. Each encrypted field is stored
+-- in data as an envelope naming its key as well; the column finds the
+-- records still wrapped by an earlier key after a key rotation.
+ALTER TABLE parsed_data ADD COLUMN IF NOT EXISTS encryption_key_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_parsed_data_encryption_key_id ON parsed_data (encryption_key_id)
+    WHERE encryption_key_id <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_parsed_data_encryption_key_id;
+ALTER TABLE parsed_data DROP COLUMN IF EXISTS encryption_key_id;
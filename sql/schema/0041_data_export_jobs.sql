@@ -0,0 +1,33 @@
+-- +goose Up
+-- Exports of the parsed records of a project to a file, requested through
+-- POST /api/v1/data/exports or created by the URL Manager for the schedules
+-- in exports.schedules. The URL Manager claims pending jobs and writes the
+-- file to exports.directory, touching updated_at as it goes; a running job
+-- whose updated_at stopped moving is claimed again. artifact is the file
+-- name in the directory, cleared when the file is deleted after expires_at.
+CREATE TABLE IF NOT EXISTS data_export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project TEXT NOT NULL,
+    schema TEXT NOT NULL DEFAULT '',
+    format TEXT NOT NULL,
+    schedule TEXT NOT NULL DEFAULT '',
+    from_time TIMESTAMPTZ,
+    to_time TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_by TEXT NOT NULL DEFAULT '',
+    record_count BIGINT NOT NULL DEFAULT 0,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    artifact TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_status ON data_export_jobs (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_project ON data_export_jobs (project, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_schedule ON data_export_jobs (schedule, created_at DESC) WHERE schedule <> '';
+
+-- +goose Down
+DROP TABLE IF EXISTS data_export_jobs;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Messages that exhausted their retries, stored by the Kafka consumers (see
+-- kafka.Consumer.SetDeadLetterStore) and listed, exported, replayed and
+-- purged through /api/v1/admin/dead-letter. value is the message as it was
+-- consumed, created_at when it was produced. A message is stored once per
+-- topic, partition and offset, however often it is redelivered.
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    topic TEXT NOT NULL,
+    kafka_partition INTEGER NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    value BYTEA NOT NULL,
+    message_type TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (topic, kafka_partition, kafka_offset)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_failed_at ON dead_letters (failed_at, id);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_topic ON dead_letters (topic, failed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS dead_letters;